@@ -0,0 +1,183 @@
+package apierror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"runtime"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apiresponse"
+)
+
+// RequestIDKey is the Gin context key holding the current request ID
+const RequestIDKey = "request_id"
+
+// RequestIDHeader is the header used to propagate request IDs
+const RequestIDHeader = "X-Request-ID"
+
+// Error is an API error rendered as the standard error envelope
+type Error struct {
+	Status  int         // HTTP status code
+	Code    string      // machine-readable error code (e.g. "ORDER_NOT_FOUND")
+	Message string      // human-readable message
+	Details interface{} // optional field errors or context
+	Err     error       // underlying cause, logged but never rendered
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+// Unwrap returns the underlying cause
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// WithDetails returns a copy of the error with details attached
+func (e *Error) WithDetails(details interface{}) *Error {
+	copied := *e
+	copied.Details = details
+	return &copied
+}
+
+// Wrap returns a copy of the error with an underlying cause attached
+func (e *Error) Wrap(err error) *Error {
+	copied := *e
+	copied.Err = err
+	return &copied
+}
+
+// New creates an API error with an explicit status code
+func New(status int, code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+// BadRequest creates a 400 error for malformed requests
+func BadRequest(code, message string) *Error {
+	return New(http.StatusBadRequest, code, message)
+}
+
+// Validation creates a 400 VALIDATION_ERROR with optional details
+func Validation(message string, details interface{}) *Error {
+	return &Error{Status: http.StatusBadRequest, Code: "VALIDATION_ERROR", Message: message, Details: details}
+}
+
+// Unauthorized creates a 401 error
+func Unauthorized(code, message string) *Error {
+	return New(http.StatusUnauthorized, code, message)
+}
+
+// Forbidden creates a 403 error
+func Forbidden(code, message string) *Error {
+	return New(http.StatusForbidden, code, message)
+}
+
+// NotFound creates a 404 error
+func NotFound(code, message string) *Error {
+	return New(http.StatusNotFound, code, message)
+}
+
+// Conflict creates a 409 error
+func Conflict(code, message string) *Error {
+	return New(http.StatusConflict, code, message)
+}
+
+// Unprocessable creates a 422 error for business rule violations
+func Unprocessable(code, message string) *Error {
+	return New(http.StatusUnprocessableEntity, code, message)
+}
+
+// Internal creates a 500 error
+func Internal(code, message string) *Error {
+	return New(http.StatusInternalServerError, code, message)
+}
+
+// As extracts an *Error from err, converting unknown errors to a generic 500
+func As(err error) *Error {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	return Internal("INTERNAL_ERROR", "An unexpected error occurred").Wrap(err)
+}
+
+// HasStatus reports whether err is an API error with the given status code
+func HasStatus(err error, status int) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.Status == status
+}
+
+// Object builds the error object for err without the surrounding envelope
+// It is used on its own for per-item failures inside successful batch responses
+func Object(err error) apiresponse.ErrorObject {
+	apiErr := As(err)
+	return apiresponse.ErrorObject{Code: apiErr.Code, Message: apiErr.Message, Details: apiErr.Details}
+}
+
+// Body builds the error envelope for err
+func Body(err error, requestID string) apiresponse.ErrorResponse {
+	object := Object(err)
+	object.RequestID = requestID
+	return apiresponse.ErrorResponse{Success: false, Error: object}
+}
+
+// Respond writes the error envelope for err and aborts the request
+// Server errors are logged with their underlying cause and attached to the Gin context (c.Errors),
+// together with the stack they were responded from, for tracing and error reporting
+func Respond(c *gin.Context, err error) {
+	apiErr := As(err)
+	requestID := c.GetString(RequestIDKey)
+
+	if apiErr.Status >= http.StatusInternalServerError {
+		log.Printf("request %s failed: %v", requestID, apiErr)
+		_ = c.Error(newServerError(apiErr))
+	}
+
+	c.AbortWithStatusPureJSON(apiErr.Status, Body(apiErr, requestID))
+}
+
+// serverError is a server error responded to a request, with the stack of the code responding
+type serverError struct {
+	err *Error
+	pcs []uintptr
+}
+
+// newServerError captures the stack of Respond's caller for err
+func newServerError(err *Error) *serverError {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(3, pcs) // skip runtime.Callers, newServerError and Respond
+	return &serverError{err: err, pcs: pcs[:n]}
+}
+
+func (e *serverError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the API error
+func (e *serverError) Unwrap() error {
+	return e.err
+}
+
+// StackTrace returns the program counters of the stack the error was responded from
+// Error reporters such as Sentry read it in place of the stack of the code reporting the error
+func (e *serverError) StackTrace() []uintptr {
+	return e.pcs
+}
+
+// WriteHTTP writes the error envelope to a plain http.ResponseWriter
+// It is used by net/http middleware that runs outside of a Gin handler
+func WriteHTTP(w http.ResponseWriter, err error) {
+	apiErr := As(err)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(apiErr.Status)
+	if encodeErr := json.NewEncoder(w).Encode(Body(apiErr, w.Header().Get(RequestIDHeader))); encodeErr != nil {
+		log.Printf("Failed to write error response: %v", encodeErr)
+	}
+}
@@ -0,0 +1,142 @@
+package apierror
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConstructors(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    *Error
+		status int
+		code   string
+	}{
+		{"bad request", BadRequest("INVALID_REQUEST", "bad"), http.StatusBadRequest, "INVALID_REQUEST"},
+		{"validation", Validation("invalid", nil), http.StatusBadRequest, "VALIDATION_ERROR"},
+		{"unauthorized", Unauthorized("UNAUTHORIZED", "no"), http.StatusUnauthorized, "UNAUTHORIZED"},
+		{"forbidden", Forbidden("FORBIDDEN", "no"), http.StatusForbidden, "FORBIDDEN"},
+		{"not found", NotFound("ORDER_NOT_FOUND", "missing"), http.StatusNotFound, "ORDER_NOT_FOUND"},
+		{"conflict", Conflict("USER_EXISTS", "dupe"), http.StatusConflict, "USER_EXISTS"},
+		{"unprocessable", Unprocessable("INVALID_STATE", "nope"), http.StatusUnprocessableEntity, "INVALID_STATE"},
+		{"internal", Internal("DATABASE_ERROR", "oops"), http.StatusInternalServerError, "DATABASE_ERROR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.status, tt.err.Status)
+			assert.Equal(t, tt.code, tt.err.Code)
+			assert.True(t, HasStatus(tt.err, tt.status))
+		})
+	}
+}
+
+func TestWithDetailsAndWrapDoNotMutate(t *testing.T) {
+	base := NotFound("ORDER_NOT_FOUND", "Order not found")
+	cause := errors.New("record not found")
+
+	detailed := base.WithDetails(map[string]string{"id": "1"})
+	wrapped := base.Wrap(cause)
+
+	assert.Nil(t, base.Details)
+	assert.Nil(t, base.Err)
+	assert.NotNil(t, detailed.Details)
+	assert.ErrorIs(t, wrapped, cause)
+	assert.Contains(t, wrapped.Error(), "record not found")
+}
+
+func TestAs_ConvertsUnknownErrors(t *testing.T) {
+	apiErr := As(errors.New("boom"))
+	assert.Equal(t, http.StatusInternalServerError, apiErr.Status)
+	assert.Equal(t, "INTERNAL_ERROR", apiErr.Code)
+
+	// Wrapped API errors are found through fmt.Errorf chains
+	original := Forbidden("FORBIDDEN", "no")
+	assert.Same(t, original, As(errors.Join(errors.New("context"), original)))
+}
+
+func TestRespond(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set(RequestIDKey, "req-123")
+
+	Respond(c, Unprocessable("INVALID_TRANSITION", "Invalid status transition").WithDetails(gin.H{"current_status": "accepted"}))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.True(t, c.IsAborted())
+
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, false, response["success"])
+
+	errorData := response["error"].(map[string]interface{})
+	assert.Equal(t, "INVALID_TRANSITION", errorData["code"])
+	assert.Equal(t, "Invalid status transition", errorData["message"])
+	assert.Equal(t, "req-123", errorData["request_id"])
+	assert.Equal(t, "accepted", errorData["details"].(map[string]interface{})["current_status"])
+}
+
+func TestRespond_OmitsEmptyFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	Respond(c, NotFound("ORDER_NOT_FOUND", "Order not found"))
+
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	errorData := response["error"].(map[string]interface{})
+	assert.NotContains(t, errorData, "details")
+	assert.NotContains(t, errorData, "request_id")
+}
+
+func TestRespond_AttachesServerErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	// Client errors are the caller's to fix and are not attached
+	Respond(c, NotFound("ORDER_NOT_FOUND", "Order not found"))
+	assert.Empty(t, c.Errors)
+
+	cause := errors.New("connection refused")
+	Respond(c, Internal("DATABASE_ERROR", "Failed to fetch order").Wrap(cause))
+	if assert.Len(t, c.Errors, 1) {
+		attached := c.Errors.Last().Err
+		assert.ErrorIs(t, attached, cause)
+		assert.Equal(t, "DATABASE_ERROR", As(attached).Code)
+
+		stack, ok := attached.(interface{ StackTrace() []uintptr })
+		if assert.True(t, ok) && assert.NotEmpty(t, stack.StackTrace()) {
+			frame, _ := runtime.CallersFrames(stack.StackTrace()).Next()
+			assert.Contains(t, frame.Function, "TestRespond_AttachesServerErrors", "the stack starts at the caller of Respond")
+		}
+	}
+}
+
+func TestWriteHTTP(t *testing.T) {
+	w := httptest.NewRecorder()
+	w.Header().Set(RequestIDHeader, "req-456")
+
+	WriteHTTP(w, Unauthorized("INVALID_TOKEN", "Failed to validate JWT."))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	errorData := response["error"].(map[string]interface{})
+	assert.Equal(t, "INVALID_TOKEN", errorData["code"])
+	assert.Equal(t, "req-456", errorData["request_id"])
+}
@@ -0,0 +1,131 @@
+// Package apiresponse defines the JSON envelopes every endpoint responds with
+// Handlers render through these types rather than ad-hoc maps, so each response has one schema
+package apiresponse
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apiversion"
+)
+
+// SuccessResponse is the envelope of a successful response carrying data
+// Success is false only for reports that describe a failure, such as the detailed health check
+type SuccessResponse[T any] struct {
+	Success bool   `json:"success"`
+	Message string `json:"message,omitempty"`
+	Data    T      `json:"data"`
+}
+
+// MessageResponse is the envelope of a successful response without data, such as a deletion
+type MessageResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+// Pagination describes a page of a list paginated by page number
+type Pagination struct {
+	Page       int   `json:"page"`
+	Limit      int   `json:"limit"`
+	Total      int64 `json:"total"`
+	TotalPages int64 `json:"totalPages"`
+}
+
+// NewPagination describes page of a list of total items shown limit at a time
+func NewPagination(page, limit int, total int64) Pagination {
+	return Pagination{Page: page, Limit: limit, Total: total, TotalPages: (total + int64(limit) - 1) / int64(limit)}
+}
+
+// PaginatedResponse is the envelope of a page of a list paginated by page number
+type PaginatedResponse[T any] struct {
+	Success    bool       `json:"success"`
+	Data       T          `json:"data"`
+	Pagination Pagination `json:"pagination"`
+}
+
+// CursorPagination describes a page of a list paginated by cursor
+// NextCursor is null on the last page
+type CursorPagination struct {
+	Limit      int     `json:"limit"`
+	NextCursor *string `json:"next_cursor"`
+}
+
+// CursorPaginatedResponse is the envelope of a page of a list paginated by cursor
+type CursorPaginatedResponse[T any] struct {
+	Success    bool             `json:"success"`
+	Data       T                `json:"data"`
+	Pagination CursorPagination `json:"pagination"`
+}
+
+// BatchSummary counts the outcomes of a batch whose items succeed or fail independently
+type BatchSummary struct {
+	Total     int `json:"total"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+}
+
+// BatchResponse is the envelope of a batch whose items succeed or fail independently
+// Data holds one outcome per item, in request order
+type BatchResponse[T any] struct {
+	Success bool         `json:"success"`
+	Data    T            `json:"data"`
+	Summary BatchSummary `json:"summary"`
+}
+
+// ErrorObject describes what went wrong; it is also used on its own for per-item failures in batch responses
+type ErrorObject struct {
+	Code      string      `json:"code"`
+	Message   string      `json:"message"`
+	Details   interface{} `json:"details,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
+}
+
+// ErrorResponse is the envelope of a failed response
+type ErrorResponse struct {
+	Success bool        `json:"success"`
+	Error   ErrorObject `json:"error"`
+}
+
+// Success wraps data in the success envelope
+func Success[T any](data T) SuccessResponse[T] {
+	return SuccessResponse[T]{Success: true, Data: data}
+}
+
+// Paginated wraps a page of a list in the success envelope
+func Paginated[T any](data T, pagination Pagination) PaginatedResponse[T] {
+	return PaginatedResponse[T]{Success: true, Data: data, Pagination: pagination}
+}
+
+// CursorPaginated wraps a page of a list in the success envelope; an empty next cursor marks the last page
+func CursorPaginated[T any](data T, limit int, nextCursor string) CursorPaginatedResponse[T] {
+	pagination := CursorPagination{Limit: limit}
+	if nextCursor != "" {
+		pagination.NextCursor = &nextCursor
+	}
+	return CursorPaginatedResponse[T]{Success: true, Data: data, Pagination: pagination}
+}
+
+// Batch wraps the outcomes of a batch in the success envelope with a summary of succeeded items
+func Batch[T any](data T, total, succeeded int) BatchResponse[T] {
+	return BatchResponse[T]{Success: true, Data: data, Summary: BatchSummary{Total: total, Succeeded: succeeded, Failed: total - succeeded}}
+}
+
+// JSON writes data in the success envelope with the given status, rendered for the request's API version
+func JSON[T any](c *gin.Context, status int, data T) {
+	c.PureJSON(status, Success(apiversion.Serialize(c, data)))
+}
+
+// OK writes data in the success envelope with 200 OK
+func OK[T any](c *gin.Context, data T) {
+	JSON(c, http.StatusOK, data)
+}
+
+// Created writes data in the success envelope with 201 Created
+func Created[T any](c *gin.Context, data T) {
+	JSON(c, http.StatusCreated, data)
+}
+
+// Message writes a success envelope carrying only a message with the given status
+func Message(c *gin.Context, status int, message string) {
+	c.PureJSON(status, MessageResponse{Success: true, Message: message})
+}
@@ -0,0 +1,76 @@
+package apiresponse
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// render writes a response with the given writer and returns its status and JSON body
+func render(t *testing.T, write func(c *gin.Context)) (int, string) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	write(c)
+	return w.Code, w.Body.String()
+}
+
+func TestRender(t *testing.T) {
+	code, body := render(t, func(c *gin.Context) { OK(c, map[string]int{"id": 1}) })
+	assert.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `{"success":true,"data":{"id":1}}`, body)
+
+	code, body = render(t, func(c *gin.Context) { Created(c, []string{}) })
+	assert.Equal(t, http.StatusCreated, code)
+	assert.JSONEq(t, `{"success":true,"data":[]}`, body)
+
+	code, body = render(t, func(c *gin.Context) { Message(c, http.StatusOK, "Order deleted") })
+	assert.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `{"success":true,"message":"Order deleted"}`, body)
+}
+
+func TestEnvelopes(t *testing.T) {
+	tests := []struct {
+		name     string
+		response interface{}
+		want     string
+	}{
+		{
+			name:     "page",
+			response: Paginated([]int{1, 2}, NewPagination(2, 2, 5)),
+			want:     `{"success":true,"data":[1,2],"pagination":{"page":2,"limit":2,"total":5,"totalPages":3}}`,
+		},
+		{
+			name:     "cursor page",
+			response: CursorPaginated([]int{1}, 1, "abc"),
+			want:     `{"success":true,"data":[1],"pagination":{"limit":1,"next_cursor":"abc"}}`,
+		},
+		{
+			name:     "last cursor page",
+			response: CursorPaginated([]int{}, 1, ""),
+			want:     `{"success":true,"data":[],"pagination":{"limit":1,"next_cursor":null}}`,
+		},
+		{
+			name:     "batch",
+			response: Batch([]string{"ok", "failed"}, 2, 1),
+			want:     `{"success":true,"data":["ok","failed"],"summary":{"total":2,"succeeded":1,"failed":1}}`,
+		},
+		{
+			name:     "error",
+			response: ErrorResponse{Error: ErrorObject{Code: "ORDER_NOT_FOUND", Message: "Order not found"}},
+			want:     `{"success":false,"error":{"code":"ORDER_NOT_FOUND","message":"Order not found"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := json.Marshal(tt.response)
+			assert.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(body))
+		})
+	}
+}
@@ -0,0 +1,176 @@
+// Package apiversion lets one set of controllers serve several API versions
+// Each version is a route group (/api/v1, /api/v2); handlers stay shared, and the responses of a newer
+// version differ only through serializers registered for the version that introduced the change
+package apiversion
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// API versions
+const (
+	V1     = 1
+	V2     = 2
+	Latest = V2
+)
+
+// contextKey is the Gin context key holding the API version of the request
+const contextKey = "api_version"
+
+// Use marks the requests of a route group as using the given API version
+func Use(version int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(contextKey, version)
+		c.Next()
+	}
+}
+
+// Get returns the API version of the request, V1 for handlers mounted outside a versioned group
+func Get(c *gin.Context) int {
+	if version, ok := c.Get(contextKey); ok {
+		if v, ok := version.(int); ok {
+			return v
+		}
+	}
+	return V1
+}
+
+// Select returns the value for the request's API version: the entry of the newest version not newer
+// than the request's. byVersion must have an entry for V1
+func Select[T any](c *gin.Context, byVersion map[int]T) T {
+	version := Get(c)
+	best := V1
+	for v := range byVersion {
+		if v <= version && v > best {
+			best = v
+		}
+	}
+	return byVersion[best]
+}
+
+// serializer renders a value as clients of a version and later expect it
+type serializer struct {
+	since     int
+	serialize func(value interface{}) interface{}
+}
+
+var (
+	serializersMu sync.RWMutex
+	serializers   = make(map[reflect.Type][]serializer)
+)
+
+// Register makes responses of API version since and later render values of type T with serialize
+// Register the pointer type (e.g. *models.Order); Serialize also applies it to values and slices of the type
+func Register[T any](since int, serialize func(T) interface{}) {
+	serializersMu.Lock()
+	defer serializersMu.Unlock()
+
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	entries := append(serializers[t], serializer{since: since, serialize: func(value interface{}) interface{} {
+		return serialize(value.(T))
+	}})
+	sort.Slice(entries, func(i, j int) bool { return entries[i].since < entries[j].since })
+	serializers[t] = entries
+}
+
+// Serialize returns data as clients of the request's API version expect it
+// Values without a serializer for that version, including those nested in other types, are returned unchanged
+func Serialize(c *gin.Context, data interface{}) interface{} {
+	return SerializeVersion(Get(c), data)
+}
+
+// SerializeVersion returns data as clients of the API version expect it
+// Serializers apply to values of the registered type, pointers to them, and slices of either
+func SerializeVersion(version int, data interface{}) interface{} {
+	if data == nil {
+		return nil
+	}
+	value := reflect.ValueOf(data)
+	if serialize := serializerOf(value.Type(), version); serialize != nil {
+		return serialize(value)
+	}
+	if value.Kind() != reflect.Slice || value.IsNil() {
+		return data
+	}
+
+	serialize := serializerOf(value.Type().Elem(), version)
+	if serialize == nil {
+		return data
+	}
+	serialized := make([]interface{}, value.Len())
+	for i := range serialized {
+		serialized[i] = serialize(value.Index(i))
+	}
+	return serialized
+}
+
+// serializerOf returns the serializer for values of the type, which may be the registered type or the
+// type it points to, or nil when the type renders as is
+func serializerOf(t reflect.Type, version int) func(reflect.Value) interface{} {
+	if serialize := lookup(t, version); serialize != nil {
+		return func(value reflect.Value) interface{} { return serialize(value.Interface()) }
+	}
+	if serialize := lookup(reflect.PointerTo(t), version); serialize != nil {
+		return func(value reflect.Value) interface{} { return serialize(addressable(value).Interface()) }
+	}
+	return nil
+}
+
+// lookup returns the serializer of the type for the version, or nil when the type renders as is
+func lookup(t reflect.Type, version int) func(interface{}) interface{} {
+	serializersMu.RLock()
+	defer serializersMu.RUnlock()
+
+	entries := serializers[t]
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].since <= version {
+			return entries[i].serialize
+		}
+	}
+	return nil
+}
+
+// addressable returns a pointer to the value, copying it when it cannot be addressed
+func addressable(value reflect.Value) reflect.Value {
+	if value.CanAddr() {
+		return value.Addr()
+	}
+	copied := reflect.New(value.Type())
+	copied.Elem().Set(value)
+	return copied
+}
+
+// Deprecation schedules the retirement of an API version
+// A zero DeprecatedAt or Sunset leaves out its header
+type Deprecation struct {
+	DeprecatedAt time.Time // when the version was deprecated
+	Sunset       time.Time // when the version stops being served
+	Prefix       string    // path prefix of the version, e.g. "/api/v1"
+	Successor    string    // path prefix of the version replacing it, e.g. "/api/v2"
+}
+
+// Deprecate announces the retirement of the route group's version on every response
+// It sets the Deprecation (RFC 9745) and Sunset (RFC 8594) headers, and links the same path in the successor version
+func Deprecate(deprecation Deprecation) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !deprecation.DeprecatedAt.IsZero() {
+			c.Header("Deprecation", "@"+strconv.FormatInt(deprecation.DeprecatedAt.Unix(), 10))
+		}
+		if !deprecation.Sunset.IsZero() {
+			c.Header("Sunset", deprecation.Sunset.UTC().Format(http.TimeFormat))
+		}
+		if deprecation.Successor != "" && strings.HasPrefix(c.Request.URL.Path, deprecation.Prefix) {
+			successor := deprecation.Successor + strings.TrimPrefix(c.Request.URL.Path, deprecation.Prefix)
+			c.Header("Link", "<"+successor+`>; rel="successor-version"`)
+		}
+		c.Next()
+	}
+}
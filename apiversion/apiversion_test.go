@@ -0,0 +1,87 @@
+package apiversion
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// widget is a type whose rendering changes in V2
+type widget struct {
+	Price float64
+}
+
+func init() {
+	Register(V2, func(w *widget) interface{} { return map[string]int64{"price_cents": int64(w.Price * 100)} })
+}
+
+// contextFor returns a test context for a request of the given API version
+func contextFor(version int) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	Use(version)(c)
+	return c
+}
+
+func TestGet(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	assert.Equal(t, V1, Get(c), "requests outside a versioned group use v1")
+	assert.Equal(t, V2, Get(contextFor(V2)))
+}
+
+func TestSelect(t *testing.T) {
+	byVersion := map[int]string{V1: "decimal"}
+	assert.Equal(t, "decimal", Select(contextFor(V2), byVersion), "versions without an entry use the newest older one")
+
+	byVersion[V2] = "cents"
+	assert.Equal(t, "decimal", Select(contextFor(V1), byVersion))
+	assert.Equal(t, "cents", Select(contextFor(V2), byVersion))
+}
+
+func TestSerialize(t *testing.T) {
+	v1, v2 := contextFor(V1), contextFor(V2)
+	item := widget{Price: 12.5}
+	cents := map[string]int64{"price_cents": 1250}
+
+	assert.Equal(t, &item, Serialize(v1, &item), "v1 renders values unchanged")
+	assert.Equal(t, cents, Serialize(v2, &item))
+	assert.Equal(t, cents, Serialize(v2, item))
+	assert.Equal(t, []interface{}{cents}, Serialize(v2, []widget{item}))
+	assert.Equal(t, []interface{}{cents}, Serialize(v2, []*widget{&item}))
+
+	assert.Equal(t, "unregistered", Serialize(v2, "unregistered"))
+	assert.Equal(t, []widget(nil), Serialize(v2, []widget(nil)))
+	assert.Nil(t, Serialize(v2, nil))
+}
+
+func TestDeprecate(t *testing.T) {
+	serve := func(deprecation Deprecation, path string) http.Header {
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.GET("/api/v1/orders/:id", Deprecate(deprecation), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w.Header()
+	}
+
+	header := serve(Deprecation{
+		DeprecatedAt: time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+		Sunset:       time.Date(2027, 5, 1, 0, 0, 0, 0, time.UTC),
+		Prefix:       "/api/v1",
+		Successor:    "/api/v2",
+	}, "/api/v1/orders/7")
+	assert.Equal(t, "@1793491200", header.Get("Deprecation"))
+	assert.Equal(t, "Sat, 01 May 2027 00:00:00 GMT", header.Get("Sunset"))
+	assert.Equal(t, `</api/v2/orders/7>; rel="successor-version"`, header.Get("Link"))
+
+	// Until dates are set, only the successor is announced
+	header = serve(Deprecation{Prefix: "/api/v1", Successor: "/api/v2"}, "/api/v1/orders/7")
+	assert.Empty(t, header.Get("Deprecation"))
+	assert.Empty(t, header.Get("Sunset"))
+	assert.NotEmpty(t, header.Get("Link"))
+}
@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"gopkg.in/go-jose/go-jose.v2"
+	"gopkg.in/go-jose/go-jose.v2/jwt"
+)
+
+// minKeyRefreshInterval limits how often keys are fetched outside the TTL, when a token names an
+// unknown key ID or a refresh failed, so that forged key IDs and outages don't hammer the issuer
+const minKeyRefreshInterval = 30 * time.Second
+
+// keyIDContextKey carries the token's key ID from ValidateToken to the key cache
+type keyIDContextKey struct{}
+
+// keyCache serves the issuer's signing keys (JWKS) from memory
+// Keys are refetched when the TTL runs out, and early when a token names a key ID the cache does not
+// hold, so a rotated key is picked up at once. When a refetch fails the cached keys keep being served
+// for the grace period, so a brief identity provider outage does not reject every token
+type keyCache struct {
+	fetch func(ctx context.Context) (interface{}, error)
+	ttl   time.Duration
+	grace time.Duration
+	now   func() time.Time
+
+	mu          sync.Mutex
+	keys        *jose.JSONWebKeySet
+	fetchedAt   time.Time
+	lastAttempt time.Time
+}
+
+func newKeyCache(fetch func(ctx context.Context) (interface{}, error), ttl, grace time.Duration) *keyCache {
+	return &keyCache{fetch: fetch, ttl: ttl, grace: grace, now: time.Now}
+}
+
+// KeyFunc returns the key that signed the token being validated, whose key ID is read from the context (see withKeyID)
+// The validator's go-jose version cannot pick a key from a set itself
+func (k *keyCache) KeyFunc(ctx context.Context) (interface{}, error) {
+	keyID, _ := ctx.Value(keyIDContextKey{}).(string)
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	now := k.now()
+	stale := k.keys == nil || !now.Before(k.fetchedAt.Add(k.ttl))
+	unknown := keyID != "" && k.keys != nil && len(k.keys.Key(keyID)) == 0
+	throttled := k.keys != nil && now.Before(k.lastAttempt.Add(minKeyRefreshInterval))
+	if (stale || unknown) && !throttled {
+		k.lastAttempt = now
+		keys, err := k.fetch(ctx)
+		if err == nil {
+			k.keys, k.fetchedAt = keys.(*jose.JSONWebKeySet), now
+			return selectKey(k.keys, keyID)
+		}
+		if k.keys == nil {
+			return nil, err
+		}
+		log.Printf("Failed to refresh signing keys, serving the cached keys: %v", err)
+	}
+
+	if !now.Before(k.fetchedAt.Add(k.ttl + k.grace)) {
+		return nil, fmt.Errorf("signing keys fetched at %s have expired and could not be refreshed", k.fetchedAt.Format(time.RFC3339))
+	}
+	return selectKey(k.keys, keyID)
+}
+
+// Check fetches the keys from the issuer regardless of the TTL and the refresh throttle, storing them on success
+// It backs the JWKS entry of the detailed health check
+func (k *keyCache) Check(ctx context.Context) error {
+	fetched, err := k.fetch(ctx)
+	if err != nil {
+		return err
+	}
+	keys := fetched.(*jose.JSONWebKeySet)
+	if len(keys.Keys) == 0 {
+		return fmt.Errorf("the issuer publishes no signing keys")
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys, k.fetchedAt = keys, k.now()
+	return nil
+}
+
+// selectKey returns the key with the given ID; a token without a key ID needs a set of exactly one key
+func selectKey(keys *jose.JSONWebKeySet, keyID string) (*jose.JSONWebKey, error) {
+	if keyID == "" {
+		if len(keys.Keys) != 1 {
+			return nil, fmt.Errorf("token has no key ID and the issuer publishes %d keys", len(keys.Keys))
+		}
+		return &keys.Keys[0], nil
+	}
+	matches := keys.Key(keyID)
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no signing key with ID %q", keyID)
+	}
+	return &matches[0], nil
+}
+
+// withKeyID returns a context carrying the key ID from the token's header, when it has one
+// Malformed tokens are left for the validator to reject
+func withKeyID(ctx context.Context, token string) context.Context {
+	parsed, err := jwt.ParseSigned(token)
+	if err != nil || len(parsed.Headers) == 0 || parsed.Headers[0].KeyID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, keyIDContextKey{}, parsed.Headers[0].KeyID)
+}
@@ -0,0 +1,180 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/auth0/go-jwt-middleware/v2/validator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/go-jose/go-jose.v2"
+	"gopkg.in/go-jose/go-jose.v2/jwt"
+)
+
+func TestKeyCache(t *testing.T) {
+	current := &jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{KeyID: "k1"}}}
+	var fetches int
+	var outage bool
+	cache := newKeyCache(func(ctx context.Context) (interface{}, error) {
+		fetches++
+		if outage {
+			return nil, errors.New("issuer unreachable")
+		}
+		return current, nil
+	}, 5*time.Minute, time.Hour)
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+	keysFor := func(keyID string) (*jose.JSONWebKey, error) {
+		key, err := cache.KeyFunc(context.WithValue(context.Background(), keyIDContextKey{}, keyID))
+		if err != nil {
+			return nil, err
+		}
+		return key.(*jose.JSONWebKey), nil
+	}
+
+	// Keys are fetched once and served from memory within the TTL
+	_, err := keysFor("k1")
+	require.NoError(t, err)
+	_, err = keysFor("k1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, fetches)
+
+	// A rotated key is fetched as soon as a token uses it
+	current = &jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{KeyID: "k1"}, {KeyID: "k2"}}}
+	now = now.Add(time.Minute)
+	key, err := keysFor("k2")
+	require.NoError(t, err)
+	assert.Equal(t, "k2", key.KeyID)
+	assert.Equal(t, 2, fetches)
+
+	// Unknown key IDs refetch at most every minKeyRefreshInterval
+	_, err = keysFor("forged")
+	assert.Error(t, err)
+	_, _ = keysFor("forged")
+	assert.Equal(t, 2, fetches)
+	now = now.Add(minKeyRefreshInterval)
+	_, _ = keysFor("forged")
+	assert.Equal(t, 3, fetches)
+
+	// During an outage the cached keys are served through the grace period
+	outage = true
+	now = now.Add(10 * time.Minute)
+	key, err = keysFor("k2")
+	require.NoError(t, err)
+	assert.Equal(t, "k2", key.KeyID)
+	assert.Equal(t, 4, fetches)
+	_, err = keysFor("k2")
+	require.NoError(t, err)
+	assert.Equal(t, 4, fetches, "a failed refresh is not retried at once")
+
+	now = now.Add(time.Hour)
+	_, err = keysFor("k2")
+	assert.Error(t, err)
+
+	// Service resumes once the issuer is back
+	outage = false
+	now = now.Add(minKeyRefreshInterval)
+	_, err = keysFor("k2")
+	assert.NoError(t, err)
+
+	// Health checks always reach the issuer and report outages the cache hides
+	fetches = 0
+	assert.NoError(t, cache.Check(context.Background()))
+	outage = true
+	assert.Error(t, cache.Check(context.Background()))
+	assert.Equal(t, 2, fetches)
+	_, err = keysFor("k2")
+	assert.NoError(t, err)
+}
+
+// testIssuer serves OIDC discovery and a JWKS that can be rotated
+type testIssuer struct {
+	server *httptest.Server
+	mu     sync.Mutex
+	keys   jose.JSONWebKeySet
+	down   bool
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	issuer := &testIssuer{}
+	issuer.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		issuer.mu.Lock()
+		defer issuer.mu.Unlock()
+		if issuer.down {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			_ = json.NewEncoder(w).Encode(map[string]string{"jwks_uri": issuer.server.URL + "/jwks"})
+		case "/jwks":
+			_ = json.NewEncoder(w).Encode(issuer.keys)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(issuer.server.Close)
+	return issuer
+}
+
+// rotate publishes a new signing key and returns a function signing tokens with it
+func (i *testIssuer) rotate(t *testing.T, keyID string) func() string {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	i.mu.Lock()
+	i.keys.Keys = append(i.keys.Keys, jose.JSONWebKey{Key: &key.PublicKey, KeyID: keyID, Algorithm: "RS256", Use: "sig"})
+	i.mu.Unlock()
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: jose.JSONWebKey{Key: key, KeyID: keyID}},
+		(&jose.SignerOptions{}).WithType("JWT"))
+	require.NoError(t, err)
+	return func() string {
+		token, err := jwt.Signed(signer).Claims(jwt.Claims{
+			Issuer:   i.server.URL + "/",
+			Subject:  "auth0|jane",
+			Audience: jwt.Audience{"kendalls-nails-api"},
+			Expiry:   jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		}).CompactSerialize()
+		require.NoError(t, err)
+		return token
+	}
+}
+
+func TestProvider_ValidateToken_KeyRotationAndOutage(t *testing.T) {
+	issuer := newTestIssuer(t)
+	signWithK1 := issuer.rotate(t, "k1")
+
+	provider, err := NewOIDCProvider(issuer.server.URL+"/", "kendalls-nails-api", WithKeyCache(time.Hour, time.Hour))
+	require.NoError(t, err)
+
+	claims, err := provider.ValidateToken(context.Background(), signWithK1())
+	require.NoError(t, err)
+	assert.Equal(t, "auth0|jane", claims.(*validator.ValidatedClaims).RegisteredClaims.Subject)
+
+	// Tokens signed with a new key validate before the cache expires
+	now := time.Now().Add(minKeyRefreshInterval)
+	provider.(*jwtProvider).keys.now = func() time.Time { return now }
+	signWithK2 := issuer.rotate(t, "k2")
+	_, err = provider.ValidateToken(context.Background(), signWithK2())
+	assert.NoError(t, err)
+
+	// Expired keys keep validating through the grace period while the issuer is down
+	issuer.mu.Lock()
+	issuer.down = true
+	issuer.mu.Unlock()
+	now = now.Add(90 * time.Minute)
+	_, err = provider.ValidateToken(context.Background(), signWithK1())
+	assert.NoError(t, err)
+
+	now = now.Add(time.Hour)
+	_, err = provider.ValidateToken(context.Background(), signWithK1())
+	assert.Error(t, err)
+}
@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewProvider(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        *config.Config
+		wantName   string
+		wantIssuer string
+		wantErr    bool
+	}{
+		{
+			name:       "defaults to auth0",
+			cfg:        &config.Config{Auth0Domain: "tenant.auth0.com", Auth0Audience: "https://api.test.com"},
+			wantName:   ProviderAuth0,
+			wantIssuer: "https://tenant.auth0.com/",
+		},
+		{
+			name:       "explicit auth0",
+			cfg:        &config.Config{AuthProvider: "Auth0", Auth0Domain: "tenant.auth0.com"},
+			wantName:   ProviderAuth0,
+			wantIssuer: "https://tenant.auth0.com/",
+		},
+		{
+			name: "generic oidc issuer",
+			cfg: &config.Config{
+				AuthProvider:  "oidc",
+				OIDCIssuerURL: "https://keycloak.example.com/realms/salon",
+				OIDCAudience:  "kendalls-nails-api",
+			},
+			wantName:   ProviderOIDC,
+			wantIssuer: "https://keycloak.example.com/realms/salon",
+		},
+		{
+			name:    "unsupported provider",
+			cfg:     &config.Config{AuthProvider: "saml"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := NewProvider(tt.cfg)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, provider)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantName, provider.Name())
+			assert.Equal(t, tt.wantIssuer, provider.Issuer())
+		})
+	}
+}
+
+func TestProvider_ValidateToken_RejectsMalformedToken(t *testing.T) {
+	provider, err := NewOIDCProvider("https://keycloak.example.com/realms/salon", "kendalls-nails-api")
+	assert.NoError(t, err)
+
+	claims, err := provider.ValidateToken(context.Background(), "not-a-jwt")
+	assert.Error(t, err)
+	assert.Nil(t, claims)
+}
+
+func TestCustomClaims_HasScope(t *testing.T) {
+	claims := CustomClaims{Scope: "read:orders write:orders"}
+	assert.True(t, claims.HasScope("write:orders"))
+	assert.False(t, claims.HasScope("write"))
+}
+
+func TestCustomClaims_Permissions(t *testing.T) {
+	var claims CustomClaims
+	err := json.Unmarshal([]byte(`{"gty":"client-credentials","permissions":["read:orders"]}`), &claims)
+	assert.NoError(t, err)
+	assert.True(t, claims.IsScoped())
+	assert.True(t, claims.HasPermission(ScopeReadOrders))
+	assert.False(t, claims.HasPermission(ScopeWriteOrders))
+
+	admin := CustomClaims{Permissions: []string{ScopeAdminAll}}
+	assert.True(t, admin.HasPermission(ScopeWriteOrders))
+
+	// User tokens without granted permissions are left to role checks
+	assert.False(t, CustomClaims{Role: "customer"}.IsScoped())
+	assert.True(t, CustomClaims{GrantType: GrantClientCredentials}.IsScoped())
+}
@@ -0,0 +1,140 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrMiss is returned by Store.Get when the key is not cached
+var ErrMiss = errors.New("cache miss")
+
+// Store is a key-value cache with expiring entries and atomic counters
+type Store interface {
+	// Get returns the cached value, or ErrMiss
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Set caches a value for the given time to live
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// Incr atomically increments a counter, creating it at zero first
+	Incr(ctx context.Context, key string) error
+}
+
+var (
+	defaultStore Store
+	defaultTTL   time.Duration
+)
+
+// Default returns the configured cache, or nil when caching is disabled
+func Default() Store {
+	return defaultStore
+}
+
+// DefaultTTL returns how long entries in the configured cache live
+func DefaultTTL() time.Duration {
+	return defaultTTL
+}
+
+// SetDefault sets the cache used by hot lookups and its entry lifetime (a nil store disables caching)
+func SetDefault(store Store, ttl time.Duration) {
+	defaultStore = store
+	defaultTTL = ttl
+}
+
+// RedisStore implements Store using Redis
+type RedisStore struct {
+	client *redis.Client
+}
+
+// NewRedisStore connects to the Redis server at a redis:// or rediss:// URL and checks that it responds
+func NewRedisStore(url string) (*RedisStore, error) {
+	options, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+	}
+	client := redis.NewClient(options)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("failed to reach Redis: %w", err)
+	}
+	return &RedisStore{client: client}, nil
+}
+
+// Get returns the cached value, or ErrMiss
+func (s *RedisStore) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := s.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrMiss
+	}
+	return value, err
+}
+
+// Set caches a value for the given time to live
+func (s *RedisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return s.client.Set(ctx, key, value, ttl).Err()
+}
+
+// Incr atomically increments a counter, creating it at zero first
+func (s *RedisStore) Incr(ctx context.Context, key string) error {
+	return s.client.Incr(ctx, key).Err()
+}
+
+// MemoryStore implements Store in process memory (primarily for testing)
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+type memoryEntry struct {
+	value   []byte
+	expires time.Time // zero for entries that never expire
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]memoryEntry)}
+}
+
+// Get returns the cached value, or ErrMiss
+func (s *MemoryStore) Get(ctx context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || (!entry.expires.IsZero() && time.Now().After(entry.expires)) {
+		return nil, ErrMiss
+	}
+	return entry.value, nil
+}
+
+// Set caches a value for the given time to live
+func (s *MemoryStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := memoryEntry{value: value}
+	if ttl > 0 {
+		entry.expires = time.Now().Add(ttl)
+	}
+	s.entries[key] = entry
+	return nil
+}
+
+// Incr atomically increments a counter, creating it at zero first
+func (s *MemoryStore) Incr(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count, _ := strconv.ParseInt(string(s.entries[key].value), 10, 64)
+	s.entries[key] = memoryEntry{value: []byte(strconv.FormatInt(count+1, 10))}
+	return nil
+}
@@ -0,0 +1,39 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	_, err := store.Get(ctx, "missing")
+	assert.ErrorIs(t, err, ErrMiss)
+
+	assert.NoError(t, store.Set(ctx, "key", []byte("value"), time.Minute))
+	value, err := store.Get(ctx, "key")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("value"), value)
+
+	// Expired entries are misses
+	assert.NoError(t, store.Set(ctx, "short", []byte("value"), time.Nanosecond))
+	time.Sleep(time.Millisecond)
+	_, err = store.Get(ctx, "short")
+	assert.ErrorIs(t, err, ErrMiss)
+
+	assert.NoError(t, store.Incr(ctx, "counter"))
+	assert.NoError(t, store.Incr(ctx, "counter"))
+	value, err = store.Get(ctx, "counter")
+	assert.NoError(t, err)
+	assert.Equal(t, "2", string(value))
+}
+
+func TestNewRedisStore_InvalidURL(t *testing.T) {
+	_, err := NewRedisStore("not-a-url")
+	assert.Error(t, err)
+}
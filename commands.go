@@ -0,0 +1,280 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/kendall-kelly/kendalls-nails-api/cache"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/dualwrite"
+	"github.com/kendall-kelly/kendalls-nails-api/flags"
+	"github.com/kendall-kelly/kendalls-nails-api/legacy"
+	"github.com/kendall-kelly/kendalls-nails-api/middleware"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"github.com/kendall-kelly/kendalls-nails-api/seed"
+	"github.com/kendall-kelly/kendalls-nails-api/utils"
+	"gorm.io/gorm"
+)
+
+// loadAndConnect loads configuration and opens the database connection
+func loadAndConnect() (*config.Config, error) {
+	// Load configuration first
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := applyConfig(cfg); err != nil {
+		return nil, err
+	}
+
+	// Connect to database
+	if err := config.ConnectDatabase(); err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	// The Redis cache is optional; without it every lookup goes to the database
+	if cfg.RedisURL != "" {
+		store, err := cache.NewRedisStore(cfg.RedisURL)
+		if err != nil {
+			log.Printf("Continuing without Redis cache: %v", err)
+		} else {
+			if err := repositories.RegisterCacheInvalidation(config.GetDB(), store); err != nil {
+				return nil, fmt.Errorf("failed to register cache invalidation: %w", err)
+			}
+			cache.SetDefault(store, cfg.GetRedisCacheTTL())
+			log.Printf("Redis cache enabled (ttl %s)", cfg.GetRedisCacheTTL())
+		}
+	}
+
+	return cfg, nil
+}
+
+// loadMock loads configuration for mock mode and fills an in-memory database with the demo fixtures
+// Nothing outside the process is contacted: no Postgres, Redis, Auth0, or S3
+func loadMock() (*config.Config, error) {
+	cfg, err := config.LoadMock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if cfg.IsProduction() {
+		return nil, fmt.Errorf("refusing to serve mock data in production")
+	}
+	if err := applyConfig(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := config.ConnectMockDatabase(); err != nil {
+		return nil, err
+	}
+	db := config.GetDB()
+	if err := models.MigrateUp(db); err != nil {
+		return nil, err
+	}
+	if err := seed.Run(db); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// applyConfig applies the process-wide settings shared by every command
+func applyConfig(cfg *config.Config) error {
+	// Apply column migration stages before any model is read or written; prices are dual-written
+	// to integer cents unless DUAL_WRITE_STAGES moves them on or switches them off
+	models.DualWritePrices()
+	if err := dualwrite.Configure(cfg.DualWriteStages); err != nil {
+		return fmt.Errorf("invalid DUAL_WRITE_STAGES: %w", err)
+	}
+
+	// Renamed response fields keep their old names unless switched off
+	if err := legacy.Configure(cfg.LegacyFields); err != nil {
+		return fmt.Errorf("invalid LEGACY_FIELDS: %w", err)
+	}
+
+	// Feature flags forced on or off here ignore the rollout stored in the database
+	if err := flags.Configure(cfg.FeatureFlags); err != nil {
+		return fmt.Errorf("invalid FEATURE_FLAGS: %w", err)
+	}
+
+	// Email normalization rules must match between writes, lookups, and backfills
+	utils.SetGmailDotFolding(cfg.GetEmailFoldGmailDots())
+
+	// Uploaded images are checked against the configured size and types before they reach S3
+	utils.SetUploadLimits(cfg.GetMaxUploadMB(), cfg.GetAllowedImageTypes())
+
+	// Caller profiles are reused briefly across requests; profile changes made elsewhere show up within the TTL
+	middleware.SetUserCacheTTL(cfg.GetUserCacheTTL())
+
+	// Admins impersonating a user only read as them unless writes are switched on
+	middleware.SetImpersonationWrites(cfg.ImpersonationWritesAllowed())
+
+	// User names and emails are encrypted at rest once a key is configured
+	if key := cfg.GetPIIEncryptionKey(); key != nil {
+		cipher, err := utils.NewFieldCipher(key)
+		if err != nil {
+			return fmt.Errorf("invalid PII_ENCRYPTION_KEY: %w", err)
+		}
+		models.SetPIICipher(cipher)
+	}
+
+	return nil
+}
+
+// runMigrate handles "migrate up", "migrate down", "migrate backfill", "migrate normalize-emails", and "migrate encrypt-pii"
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("migrate requires a direction: up, down, backfill, normalize-emails, or encrypt-pii\n\n%s", usage)
+	}
+	direction := args[0]
+
+	flags := flag.NewFlagSet("migrate "+direction, flag.ContinueOnError)
+	force := flags.Bool("force", false, "allow dropping tables in production")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	switch direction {
+	case "up", "down", "backfill", "normalize-emails", "encrypt-pii":
+	default:
+		return fmt.Errorf("unknown migrate direction %q (expected up, down, backfill, normalize-emails, or encrypt-pii)", direction)
+	}
+
+	cfg, err := loadAndConnect()
+	if err != nil {
+		return err
+	}
+	db := config.GetDB()
+
+	if direction == "backfill" {
+		for _, column := range models.PriceColumns {
+			updated, err := models.BackfillPriceCents(db, column)
+			if err != nil {
+				return fmt.Errorf("failed to backfill %s: %w", column.Name(), err)
+			}
+			log.Printf("Backfilled %s on %d rows", column.Name(), updated)
+		}
+		return nil
+	}
+
+	if direction == "normalize-emails" {
+		result, err := models.NormalizeUserEmails(db)
+		if err != nil {
+			return err
+		}
+		log.Printf("Normalized %d user emails", result.Updated)
+		for email, ids := range result.Conflicts {
+			log.Printf("Accounts %v share the email %s and must be merged by hand", ids, email)
+		}
+		if len(result.Conflicts) > 0 {
+			return fmt.Errorf("%d emails are shared by multiple accounts", len(result.Conflicts))
+		}
+		// With duplicates gone the case-insensitive unique index can be created
+		return models.MigrateUp(db)
+	}
+
+	if direction == "encrypt-pii" {
+		if cfg.GetPIIEncryptionKey() == nil {
+			return fmt.Errorf("migrate encrypt-pii requires PII_ENCRYPTION_KEY")
+		}
+		// The blind index column must exist before it is filled
+		if err := models.MigrateUp(db); err != nil {
+			return err
+		}
+		encrypted, err := models.EncryptUserPII(db)
+		if err != nil {
+			return err
+		}
+		log.Printf("Encrypted the names and emails of %d users", encrypted)
+		return nil
+	}
+
+	if direction == "up" {
+		if err := migrateUp(db); err != nil {
+			return err
+		}
+		log.Println("Database migration completed successfully")
+		return nil
+	}
+
+	// Dropping tables destroys data, so production requires an explicit -force
+	if cfg.IsProduction() && !*force {
+		return fmt.Errorf("refusing to drop tables in production without -force")
+	}
+	if err := models.MigrateDown(db); err != nil {
+		return err
+	}
+	log.Println("Database tables dropped successfully")
+	return nil
+}
+
+// migrateUp creates or updates the tables and fills the cents columns of prices written before dual-write began
+func migrateUp(db *gorm.DB) error {
+	if err := models.MigrateUp(db); err != nil {
+		return err
+	}
+	updated, err := models.BackfillWrittenPriceCents(db)
+	if err != nil {
+		return err
+	}
+	for column, rows := range updated {
+		if rows > 0 {
+			log.Printf("Backfilled %s on %d rows", column, rows)
+		}
+	}
+	return nil
+}
+
+// runConfig handles "config check": it loads and validates the configuration the way serve does, then exits
+func runConfig(args []string) error {
+	if len(args) == 0 || args[0] != "check" {
+		return fmt.Errorf("config requires a subcommand: check\n\n%s", usage)
+	}
+	flags := flag.NewFlagSet("config check", flag.ContinueOnError)
+	mock := flags.Bool("mock", false, "check the settings serve -mock needs")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	load := config.Load
+	if *mock {
+		load = config.LoadMock
+	}
+	cfg, err := load()
+	if err != nil {
+		return err
+	}
+	if err := applyConfig(cfg); err != nil {
+		return err
+	}
+	log.Printf("Configuration is valid (env: %s, storage: %s, auth provider: %s)", cfg.GoEnv, cfg.GetStorageBackend(), cfg.GetAuthProvider())
+	return nil
+}
+
+// runSeed creates demo data for local development
+func runSeed(args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := loadAndConnect()
+	if err != nil {
+		return err
+	}
+
+	// Demo accounts must never end up in a production database
+	if cfg.IsProduction() {
+		return fmt.Errorf("refusing to seed demo data in production")
+	}
+
+	db := config.GetDB()
+	if err := models.MigrateUp(db); err != nil {
+		return err
+	}
+	if err := seed.Run(db); err != nil {
+		return err
+	}
+	log.Println("Database seeded successfully")
+	return nil
+}
@@ -3,33 +3,181 @@ package config
 import (
 	"fmt"
 	"log"
+	"net"
+	"net/url"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/kendall-kelly/kendalls-nails-api/utils"
 )
 
 // Config holds all application configuration
 type Config struct {
-	DatabaseURL        string
-	Port               string
-	GoEnv              string
-	Auth0Domain        string
-	Auth0Audience      string
-	JWTSecret          string
-	AWSRegion          string
-	AWSS3Bucket        string
-	AWSAccessKeyID     string
-	AWSSecretAccessKey string
-	LogLevel           string
-	CORSAllowedOrigins string
+	DatabaseURL           string
+	ReplicaDatabaseURL    string
+	DBMaxOpenConns        string
+	DBMaxIdleConns        string
+	DBConnMaxLifetime     string
+	SlowQueryThreshold    string
+	Port                  string
+	GoEnv                 string
+	Auth0Domain           string
+	Auth0Audience         string
+	Auth0MgmtClientID     string
+	Auth0MgmtSecret       string
+	AuthProvider          string
+	OIDCIssuerURL         string
+	OIDCAudience          string
+	JWKSCacheTTL          string
+	JWKSGracePeriod       string
+	CookieAuth            string
+	HealthDetails         string
+	MetricsToken          string
+	DebugVars             string
+	ImpersonationWrites   string
+	JWTSecret             string
+	AWSRegion             string
+	AWSS3Bucket           string
+	AWSAccessKeyID        string
+	AWSSecretAccessKey    string
+	LogLevel              string
+	CORSAllowedOrigins    string
+	TrustedProxies        string
+	DualWriteStages       string
+	LegacyFields          string
+	FeatureFlags          string
+	RedisURL              string
+	EventBus              string
+	RedisCacheTTL         string
+	UserCacheTTL          string
+	RushSurcharge         string
+	RushSurchargePct      string
+	EmailFoldGmailDots    string
+	StaleOrderDays        string
+	OrphanImageDays       string
+	InvoiceTaxPct         string
+	Currency              string
+	PIIEncryptionKey      string
+	MaxJSONBodyBytes      string
+	MaxUploadMB           string
+	AllowedImageTypes     string
+	ImageModerationURL    string
+	OTLPEndpoint          string
+	SentryDSN             string
+	ImageModerationAPIKey string
+	MessageFilterWords    string
+	MessageFilterAction   string
+	MessageModerationURL  string
+	MessageModerationKey  string
+	StorageBackend        string
+	LocalStorageDir       string
+	UploadStagingDir      string
+	ImageProxy            string
+	ImageProxyURL         string
+	ImageProxyKey         string
+	ImageURLExpiryMinutes string
+	CloudFrontURL         string
+	CloudFrontKeyPairID   string
+	CloudFrontPrivateKey  string
+	CaptchaSecret         string
+	CaptchaVerifyURL      string
+	QuoteRateLimit        string
+	TermsVersion          string
+	TermsMinimumVersion   string
+	TermsURL              string
+	APIV1DeprecatedAt     string
+	APIV1Sunset           string
+	Mock                  bool // serving in-memory fixtures; the database, identity provider, and S3 settings are not needed
 }
 
+// Database connection pool defaults, used when DB_MAX_OPEN_CONNS, DB_MAX_IDLE_CONNS, and DB_CONN_MAX_LIFETIME are unset
+// The open connection limit matches the smallest Heroku Postgres plans; divide the plan's limit between dynos when scaling out
+const (
+	DefaultDBMaxOpenConns    = 20
+	DefaultDBMaxIdleConns    = 10
+	DefaultDBConnMaxLifetime = 30 * time.Minute
+)
+
+// DefaultSlowQueryThreshold is how long a query may take before it is logged when SLOW_QUERY_THRESHOLD is unset
+const DefaultSlowQueryThreshold = 200 * time.Millisecond
+
+// MinMetricsTokenLength is the shortest METRICS_TOKEN accepted, so that the token cannot be guessed
+const MinMetricsTokenLength = 16
+
+// DefaultRedisCacheTTL is how long cached lookups live when REDIS_CACHE_TTL is unset
+const DefaultRedisCacheTTL = time.Minute
+
+// DefaultJWKSCacheTTL is how long the identity provider's signing keys are cached when JWKS_CACHE_TTL is unset
+const DefaultJWKSCacheTTL = 5 * time.Minute
+
+// DefaultJWKSGracePeriod is how long cached signing keys outlive their TTL while the identity provider
+// cannot be reached, when JWKS_GRACE_PERIOD is unset
+const DefaultJWKSGracePeriod = time.Hour
+
+// DefaultUserCacheTTL is how long resolved caller profiles are reused when USER_CACHE_TTL is unset
+const DefaultUserCacheTTL = 30 * time.Second
+
+// DefaultRushSurcharge is the rush fee added to accepted rush orders when RUSH_SURCHARGE is unset
+const DefaultRushSurcharge = 15.00
+
+// DefaultMaxJSONBodyBytes is the largest request body accepted outside file uploads when MAX_JSON_BODY_BYTES is unset
+const DefaultMaxJSONBodyBytes = 1 << 20
+
+// Storage backends for uploaded files, selected by STORAGE_BACKEND
+const (
+	StorageS3    = "s3"
+	StorageLocal = "local" // files on the server's disk, for self-hosted deployments without AWS
+)
+
+// Transports for domain events, selected by EVENT_BUS
+const (
+	EventBusMemory = "memory" // handled by the instance that published them
+	EventBusRedis  = "redis"  // shared by all instances through a Redis stream at REDIS_URL
+)
+
+// What happens to a message that uses a word in MESSAGE_FILTER_WORDS, selected by MESSAGE_FILTER_ACTION
+const (
+	MessageFilterMask  = "mask"  // sent with the word replaced by asterisks
+	MessageFilterBlock = "block" // not sent
+)
+
+// DefaultLocalStorageDir is where uploads are kept with STORAGE_BACKEND=local when LOCAL_STORAGE_DIR is unset
+const DefaultLocalStorageDir = "./storage"
+
+// DefaultImageURLExpiry is how long signed image URLs stay valid when IMAGE_URL_EXPIRY_MINUTES is unset
+const DefaultImageURLExpiry = time.Hour
+
+// DefaultStaleOrderDays is how long an order may wait for review before it expires when STALE_ORDER_EXPIRY_DAYS is unset
+const DefaultStaleOrderDays = 30
+
+// DefaultOrphanImageDays is how long an upload nothing refers to is kept when ORPHAN_IMAGE_RETENTION_DAYS is unset
+const DefaultOrphanImageDays = 7
+
+// DefaultCaptchaVerifyURL is where CAPTCHA tokens are checked when CAPTCHA_VERIFY_URL is unset (Cloudflare Turnstile)
+const DefaultCaptchaVerifyURL = "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+
+// DefaultQuoteRateLimit is how many quote requests one client IP may submit per hour when QUOTE_RATE_LIMIT is unset
+const DefaultQuoteRateLimit = 5
+
 var appConfig *Config
 
 // Load loads the configuration from environment variables
 // It automatically determines which .env file to load based on GO_ENV
 func Load() (*Config, error) {
+	return load(false)
+}
+
+// LoadMock loads the configuration for mock mode (serve -mock)
+// Database, identity provider, and S3 settings are optional because mock mode uses none of them
+func LoadMock() (*Config, error) {
+	return load(true)
+}
+
+// load reads the environment and validates the configuration
+func load(mock bool) (*Config, error) {
 	// Determine which environment file to load
 	env := os.Getenv("GO_ENV")
 	if env == "" {
@@ -50,17 +198,80 @@ func Load() (*Config, error) {
 	}
 
 	config := &Config{
-		DatabaseURL:        getEnv("DATABASE_URL", ""),
-		Port:               getEnv("PORT", "8080"),
-		GoEnv:              getEnv("GO_ENV", "development"),
-		Auth0Domain:        getEnv("AUTH0_DOMAIN", ""),
-		Auth0Audience:      getEnv("AUTH0_AUDIENCE", ""),
-		AWSRegion:          getEnv("AWS_REGION", "us-east-1"),
-		AWSS3Bucket:        getEnv("AWS_S3_BUCKET", ""),
-		AWSAccessKeyID:     getEnv("AWS_ACCESS_KEY_ID", ""),
-		AWSSecretAccessKey: getEnv("AWS_SECRET_ACCESS_KEY", ""),
-		LogLevel:           getEnv("LOG_LEVEL", "info"),
-		CORSAllowedOrigins: getEnv("CORS_ALLOWED_ORIGINS", "http://localhost:3000,http://localhost:5173,http://localhost:5174"),
+		DatabaseURL:           getEnv("DATABASE_URL", ""),
+		ReplicaDatabaseURL:    getEnv("REPLICA_DATABASE_URL", ""),
+		DBMaxOpenConns:        getEnv("DB_MAX_OPEN_CONNS", ""),
+		DBMaxIdleConns:        getEnv("DB_MAX_IDLE_CONNS", ""),
+		DBConnMaxLifetime:     getEnv("DB_CONN_MAX_LIFETIME", ""),
+		SlowQueryThreshold:    getEnv("SLOW_QUERY_THRESHOLD", ""),
+		Port:                  getEnv("PORT", "8080"),
+		GoEnv:                 getEnv("GO_ENV", "development"),
+		Auth0Domain:           getEnv("AUTH0_DOMAIN", ""),
+		Auth0Audience:         getEnv("AUTH0_AUDIENCE", ""),
+		Auth0MgmtClientID:     getEnv("AUTH0_MGMT_CLIENT_ID", ""),
+		Auth0MgmtSecret:       getEnv("AUTH0_MGMT_CLIENT_SECRET", ""),
+		AuthProvider:          getEnv("AUTH_PROVIDER", "auth0"),
+		OIDCIssuerURL:         getEnv("OIDC_ISSUER_URL", ""),
+		OIDCAudience:          getEnv("OIDC_AUDIENCE", ""),
+		JWKSCacheTTL:          getEnv("JWKS_CACHE_TTL", ""),
+		JWKSGracePeriod:       getEnv("JWKS_GRACE_PERIOD", ""),
+		CookieAuth:            getEnv("COOKIE_AUTH", ""),
+		HealthDetails:         getEnv("HEALTH_DETAILS", ""),
+		MetricsToken:          getEnv("METRICS_TOKEN", ""),
+		DebugVars:             getEnv("DEBUG_VARS", ""),
+		ImpersonationWrites:   getEnv("IMPERSONATION_ALLOW_WRITES", ""),
+		AWSRegion:             getEnv("AWS_REGION", "us-east-1"),
+		AWSS3Bucket:           getEnv("AWS_S3_BUCKET", ""),
+		AWSAccessKeyID:        getEnv("AWS_ACCESS_KEY_ID", ""),
+		AWSSecretAccessKey:    getEnv("AWS_SECRET_ACCESS_KEY", ""),
+		LogLevel:              getEnv("LOG_LEVEL", "info"),
+		CORSAllowedOrigins:    getEnv("CORS_ALLOWED_ORIGINS", "http://localhost:3000,http://localhost:5173,http://localhost:5174"),
+		TrustedProxies:        getEnv("TRUSTED_PROXIES", ""),
+		DualWriteStages:       getEnv("DUAL_WRITE_STAGES", ""),
+		LegacyFields:          getEnv("LEGACY_FIELDS", ""),
+		FeatureFlags:          getEnv("FEATURE_FLAGS", ""),
+		RedisURL:              getEnv("REDIS_URL", ""),
+		EventBus:              getEnv("EVENT_BUS", EventBusMemory),
+		RedisCacheTTL:         getEnv("REDIS_CACHE_TTL", ""),
+		UserCacheTTL:          getEnv("USER_CACHE_TTL", ""),
+		RushSurcharge:         getEnv("RUSH_SURCHARGE", ""),
+		RushSurchargePct:      getEnv("RUSH_SURCHARGE_PERCENT", ""),
+		EmailFoldGmailDots:    getEnv("EMAIL_FOLD_GMAIL_DOTS", "false"),
+		StaleOrderDays:        getEnv("STALE_ORDER_EXPIRY_DAYS", ""),
+		OrphanImageDays:       getEnv("ORPHAN_IMAGE_RETENTION_DAYS", ""),
+		InvoiceTaxPct:         getEnv("INVOICE_TAX_PERCENT", ""),
+		Currency:              getEnv("CURRENCY", utils.DefaultCurrency),
+		PIIEncryptionKey:      getEnv("PII_ENCRYPTION_KEY", ""),
+		MaxJSONBodyBytes:      getEnv("MAX_JSON_BODY_BYTES", ""),
+		MaxUploadMB:           getEnv("MAX_UPLOAD_MB", ""),
+		AllowedImageTypes:     getEnv("ALLOWED_IMAGE_TYPES", ""),
+		ImageModerationURL:    getEnv("IMAGE_MODERATION_URL", ""),
+		OTLPEndpoint:          getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", ""),
+		SentryDSN:             getEnv("SENTRY_DSN", ""),
+		ImageModerationAPIKey: getEnv("IMAGE_MODERATION_API_KEY", ""),
+		MessageFilterWords:    getEnv("MESSAGE_FILTER_WORDS", ""),
+		MessageFilterAction:   getEnv("MESSAGE_FILTER_ACTION", MessageFilterMask),
+		MessageModerationURL:  getEnv("MESSAGE_MODERATION_URL", ""),
+		MessageModerationKey:  getEnv("MESSAGE_MODERATION_API_KEY", ""),
+		StorageBackend:        getEnv("STORAGE_BACKEND", StorageS3),
+		LocalStorageDir:       getEnv("LOCAL_STORAGE_DIR", DefaultLocalStorageDir),
+		UploadStagingDir:      getEnv("UPLOAD_STAGING_DIR", ""),
+		ImageProxy:            getEnv("IMAGE_PROXY", ""),
+		ImageProxyURL:         getEnv("IMAGE_PROXY_URL", ""),
+		ImageProxyKey:         getEnv("IMAGE_PROXY_SIGNING_KEY", ""),
+		ImageURLExpiryMinutes: getEnv("IMAGE_URL_EXPIRY_MINUTES", ""),
+		CloudFrontURL:         getEnv("CLOUDFRONT_URL", ""),
+		CloudFrontKeyPairID:   getEnv("CLOUDFRONT_KEY_PAIR_ID", ""),
+		CloudFrontPrivateKey:  getEnv("CLOUDFRONT_PRIVATE_KEY", ""),
+		CaptchaSecret:         getEnv("CAPTCHA_SECRET", ""),
+		CaptchaVerifyURL:      getEnv("CAPTCHA_VERIFY_URL", DefaultCaptchaVerifyURL),
+		QuoteRateLimit:        getEnv("QUOTE_RATE_LIMIT", ""),
+		TermsVersion:          getEnv("TERMS_VERSION", ""),
+		TermsMinimumVersion:   getEnv("TERMS_MINIMUM_VERSION", ""),
+		TermsURL:              getEnv("TERMS_URL", ""),
+		APIV1DeprecatedAt:     getEnv("API_V1_DEPRECATED_AT", ""),
+		APIV1Sunset:           getEnv("API_V1_SUNSET", ""),
+		Mock:                  mock,
 	}
 
 	// Validate required configuration
@@ -84,26 +295,348 @@ func SetConfig(cfg *Config) {
 	appConfig = cfg
 }
 
-// Validate checks that all required configuration values are set
+// ValidationError lists every problem found in the configuration, so that all of them can be fixed at once
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// problems collects configuration problems as Validate finds them
+type problems []string
+
+func (p *problems) add(format string, args ...interface{}) {
+	*p = append(*p, fmt.Sprintf(format, args...))
+}
+
+// Validate checks every setting's type and range and the rules between settings
+// It returns a *ValidationError listing all problems rather than stopping at the first
 func (c *Config) Validate() error {
-	if c.DatabaseURL == "" {
-		return fmt.Errorf("DATABASE_URL is required")
+	var p problems
+	if !c.Mock {
+		c.validateServices(&p)
 	}
-	if c.AWSRegion == "" {
-		return fmt.Errorf("AWS_REGION is required")
+	c.validateServer(&p)
+
+	c.validateDatabasePool(&p)
+	if c.RedisURL != "" {
+		if parsed, err := url.Parse(c.RedisURL); err != nil || (parsed.Scheme != "redis" && parsed.Scheme != "rediss") || parsed.Host == "" {
+			p.add("REDIS_URL must be a redis:// or rediss:// URL")
+		}
+	}
+	switch c.GetEventBus() {
+	case EventBusMemory:
+	case EventBusRedis:
+		if c.RedisURL == "" {
+			p.add("REDIS_URL is required when EVENT_BUS=redis")
+		}
+	default:
+		p.add("EVENT_BUS must be one of: memory, redis")
+	}
+	if c.RedisCacheTTL != "" {
+		if ttl, err := time.ParseDuration(c.RedisCacheTTL); err != nil || ttl <= 0 {
+			p.add("REDIS_CACHE_TTL must be a positive duration such as 30s")
+		}
+	}
+	if c.JWKSCacheTTL != "" {
+		if ttl, err := time.ParseDuration(c.JWKSCacheTTL); err != nil || ttl <= 0 {
+			p.add("JWKS_CACHE_TTL must be a positive duration such as 5m")
+		}
+	}
+	if c.JWKSGracePeriod != "" {
+		if grace, err := time.ParseDuration(c.JWKSGracePeriod); err != nil || grace < 0 {
+			p.add("JWKS_GRACE_PERIOD must be a duration such as 1h, or 0 to disable the grace period")
+		}
+	}
+	validateBool(&p, "COOKIE_AUTH", c.CookieAuth)
+	validateBool(&p, "HEALTH_DETAILS", c.HealthDetails)
+	if c.MetricsToken != "" && len(c.MetricsToken) < MinMetricsTokenLength {
+		p.add("METRICS_TOKEN must be at least %d characters", MinMetricsTokenLength)
+	}
+	validateBool(&p, "DEBUG_VARS", c.DebugVars)
+	if c.DebugVarsEnabled() && c.MetricsToken == "" {
+		p.add("DEBUG_VARS requires METRICS_TOKEN")
+	}
+	validateBool(&p, "IMPERSONATION_ALLOW_WRITES", c.ImpersonationWrites)
+	validateBool(&p, "EMAIL_FOLD_GMAIL_DOTS", c.EmailFoldGmailDots)
+	if c.UserCacheTTL != "" {
+		if ttl, err := time.ParseDuration(c.UserCacheTTL); err != nil || ttl < 0 {
+			p.add("USER_CACHE_TTL must be a duration such as 30s, or 0 to disable the cache")
+		}
+	}
+	if c.RushSurcharge != "" {
+		if surcharge, err := strconv.ParseFloat(c.RushSurcharge, 64); err != nil || surcharge < 0 {
+			p.add("RUSH_SURCHARGE must be a non-negative amount")
+		}
+	}
+	if c.RushSurchargePct != "" {
+		if percent, err := strconv.ParseFloat(c.RushSurchargePct, 64); err != nil || percent < 0 {
+			p.add("RUSH_SURCHARGE_PERCENT must be a non-negative percentage")
+		}
+	}
+	if c.StaleOrderDays != "" {
+		if days, err := strconv.Atoi(c.StaleOrderDays); err != nil || days < 0 {
+			p.add("STALE_ORDER_EXPIRY_DAYS must be a whole number of days, or 0 to disable expiry")
+		}
+	}
+	if c.OrphanImageDays != "" {
+		if days, err := strconv.Atoi(c.OrphanImageDays); err != nil || days < 0 {
+			p.add("ORPHAN_IMAGE_RETENTION_DAYS must be a whole number of days, or 0 to disable the cleanup")
+		}
+	}
+	if c.InvoiceTaxPct != "" {
+		if percent, err := strconv.ParseFloat(c.InvoiceTaxPct, 64); err != nil || percent < 0 || percent > 100 {
+			p.add("INVOICE_TAX_PERCENT must be a percentage between 0 and 100")
+		}
+	}
+	if c.Currency != "" {
+		if _, ok := utils.CanonicalCurrency(c.Currency); !ok {
+			p.add("CURRENCY must be an ISO 4217 code of a currency with cents, such as USD or EUR")
+		}
+	}
+	if c.MaxJSONBodyBytes != "" {
+		if limit, err := strconv.ParseInt(c.MaxJSONBodyBytes, 10, 64); err != nil || limit <= 0 {
+			p.add("MAX_JSON_BODY_BYTES must be a positive number of bytes")
+		}
+	}
+	if c.MaxUploadMB != "" {
+		if limit, err := strconv.ParseInt(c.MaxUploadMB, 10, 64); err != nil || limit <= 0 {
+			p.add("MAX_UPLOAD_MB must be a positive whole number of megabytes")
+		}
+	}
+	if c.AllowedImageTypes != "" {
+		for _, imageType := range splitList(c.AllowedImageTypes) {
+			if !utils.IsSupportedImageType(imageType) {
+				p.add("ALLOWED_IMAGE_TYPES has unsupported type %q; supported types are %s", imageType, strings.Join(utils.SupportedImageTypes, ", "))
+			}
+		}
 	}
-	if c.AWSS3Bucket == "" {
-		return fmt.Errorf("AWS_S3_BUCKET is required")
+	if c.ImageModerationURL != "" {
+		validateHTTPURL(&p, "IMAGE_MODERATION_URL", c.ImageModerationURL)
+	} else if c.ImageModerationAPIKey != "" {
+		p.add("IMAGE_MODERATION_API_KEY requires IMAGE_MODERATION_URL")
 	}
-	if c.AWSAccessKeyID == "" {
-		return fmt.Errorf("AWS_ACCESS_KEY_ID is required")
+	if action := c.GetMessageFilterAction(); action != MessageFilterMask && action != MessageFilterBlock {
+		p.add("MESSAGE_FILTER_ACTION must be %q or %q", MessageFilterMask, MessageFilterBlock)
 	}
-	if c.AWSSecretAccessKey == "" {
-		return fmt.Errorf("AWS_SECRET_ACCESS_KEY is required")
+	if c.MessageModerationURL != "" {
+		validateHTTPURL(&p, "MESSAGE_MODERATION_URL", c.MessageModerationURL)
+	} else if c.MessageModerationKey != "" {
+		p.add("MESSAGE_MODERATION_API_KEY requires MESSAGE_MODERATION_URL")
+	}
+	if c.OTLPEndpoint != "" {
+		validateHTTPURL(&p, "OTEL_EXPORTER_OTLP_ENDPOINT", c.OTLPEndpoint)
+	}
+	if c.SentryDSN != "" {
+		validateHTTPURL(&p, "SENTRY_DSN", c.SentryDSN)
+	}
+	validateBool(&p, "IMAGE_PROXY", c.ImageProxy)
+	if c.ImageProxyURL != "" {
+		validateHTTPURL(&p, "IMAGE_PROXY_URL", c.ImageProxyURL)
+	}
+	if c.ImageURLExpiryMinutes != "" {
+		if minutes, err := strconv.Atoi(c.ImageURLExpiryMinutes); err != nil || minutes <= 0 || minutes > 7*24*60 {
+			p.add("IMAGE_URL_EXPIRY_MINUTES must be a whole number of minutes between 1 and 10080 (7 days)")
+		}
+	}
+	if c.CaptchaSecret != "" {
+		validateHTTPURL(&p, "CAPTCHA_VERIFY_URL", c.CaptchaVerifyURL)
+	}
+	if c.QuoteRateLimit != "" {
+		if limit, err := strconv.Atoi(c.QuoteRateLimit); err != nil || limit <= 0 {
+			p.add("QUOTE_RATE_LIMIT must be a positive whole number of requests per hour")
+		}
+	}
+	if c.TermsVersion != "" {
+		if _, err := time.Parse(time.DateOnly, c.TermsVersion); err != nil {
+			p.add("TERMS_VERSION must be the effective date of the terms, such as 2026-05-01")
+		}
+	}
+	if c.TermsMinimumVersion != "" {
+		if c.TermsVersion == "" {
+			p.add("TERMS_MINIMUM_VERSION requires TERMS_VERSION")
+		} else if _, err := time.Parse(time.DateOnly, c.TermsMinimumVersion); err != nil || c.TermsMinimumVersion > c.TermsVersion {
+			p.add("TERMS_MINIMUM_VERSION must be a date such as 2026-05-01, no later than TERMS_VERSION")
+		}
+	}
+	if c.TermsURL != "" {
+		validateHTTPURL(&p, "TERMS_URL", c.TermsURL)
+	}
+	if c.APIV1DeprecatedAt != "" {
+		if _, err := time.Parse(time.DateOnly, c.APIV1DeprecatedAt); err != nil {
+			p.add("API_V1_DEPRECATED_AT must be a date such as 2026-05-01")
+		}
+	}
+	if c.APIV1Sunset != "" {
+		if _, err := time.Parse(time.DateOnly, c.APIV1Sunset); err != nil || (c.APIV1DeprecatedAt != "" && c.APIV1Sunset < c.APIV1DeprecatedAt) {
+			p.add("API_V1_SUNSET must be a date such as 2027-01-01, no earlier than API_V1_DEPRECATED_AT")
+		}
+	}
+	if c.PIIEncryptionKey != "" {
+		if _, err := utils.ParseFieldCipherKey(c.PIIEncryptionKey); err != nil {
+			p.add("PII_ENCRYPTION_KEY must be a base64 encoded 32 byte key: %v", err)
+		}
+	}
+
+	if len(p) > 0 {
+		return &ValidationError{Problems: p}
 	}
 	return nil
 }
 
+// validateDatabasePool checks the connection pool and slow query settings
+func (c *Config) validateDatabasePool(p *problems) {
+	if c.DBMaxOpenConns != "" {
+		if limit, err := strconv.Atoi(c.DBMaxOpenConns); err != nil || limit <= 0 {
+			p.add("DB_MAX_OPEN_CONNS must be a positive number of connections")
+		}
+	}
+	if c.DBMaxIdleConns != "" {
+		if limit, err := strconv.Atoi(c.DBMaxIdleConns); err != nil || limit < 0 {
+			p.add("DB_MAX_IDLE_CONNS must be a number of connections, or 0 to close connections once idle")
+		} else if limit > c.GetDBMaxOpenConns() {
+			p.add("DB_MAX_IDLE_CONNS must not exceed DB_MAX_OPEN_CONNS (%d)", c.GetDBMaxOpenConns())
+		}
+	}
+	if c.DBConnMaxLifetime != "" {
+		if lifetime, err := time.ParseDuration(c.DBConnMaxLifetime); err != nil || lifetime < 0 {
+			p.add("DB_CONN_MAX_LIFETIME must be a duration such as 30m, or 0 to keep connections open indefinitely")
+		}
+	}
+	if c.SlowQueryThreshold != "" {
+		if threshold, err := time.ParseDuration(c.SlowQueryThreshold); err != nil || threshold < 0 {
+			p.add("SLOW_QUERY_THRESHOLD must be a duration such as 200ms, or 0 to disable slow query logging")
+		}
+	}
+}
+
+// validateServer checks the settings of the HTTP server itself
+func (c *Config) validateServer(p *problems) {
+	if port, err := strconv.Atoi(c.Port); err != nil || port < 1 || port > 65535 {
+		p.add("PORT must be a port number between 1 and 65535")
+	}
+	switch c.GoEnv {
+	case "development", "test", "staging", "production":
+	default:
+		p.add("GO_ENV must be one of: development, test, staging, production")
+	}
+	switch strings.ToLower(c.LogLevel) {
+	case "", "debug", "info", "warn", "error":
+	default:
+		p.add("LOG_LEVEL must be one of: debug, info, warn, error")
+	}
+	for _, origin := range c.GetCORSOrigins() {
+		if parsed, err := url.Parse(origin); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" || strings.TrimSuffix(parsed.Path, "/") != "" {
+			p.add("CORS_ALLOWED_ORIGINS has %q, which is not an origin such as https://app.example.com", origin)
+		}
+	}
+	for _, proxy := range c.GetTrustedProxies() {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			p.add("TRUSTED_PROXIES has %q, which is not an IP address or CIDR range such as 10.0.0.0/8", proxy)
+		}
+	}
+}
+
+// validateServices checks the settings of the database, file storage, and identity provider connections
+// Each provider's settings are only required, and only allowed, when that provider is selected
+func (c *Config) validateServices(p *problems) {
+	if c.DatabaseURL == "" {
+		p.add("DATABASE_URL is required")
+	} else if parsed, err := url.Parse(c.DatabaseURL); err != nil || (parsed.Scheme != "postgres" && parsed.Scheme != "postgresql") {
+		p.add("DATABASE_URL must be a postgres:// or postgresql:// URL")
+	}
+	if c.ReplicaDatabaseURL != "" {
+		if parsed, err := url.Parse(c.ReplicaDatabaseURL); err != nil || (parsed.Scheme != "postgres" && parsed.Scheme != "postgresql") {
+			p.add("REPLICA_DATABASE_URL must be a postgres:// or postgresql:// URL")
+		}
+	}
+
+	cloudFront := c.CloudFrontURL != "" || c.CloudFrontKeyPairID != "" || c.CloudFrontPrivateKey != ""
+	switch c.GetStorageBackend() {
+	case StorageS3:
+		for _, setting := range [][2]string{
+			{"AWS_REGION", c.AWSRegion},
+			{"AWS_S3_BUCKET", c.AWSS3Bucket},
+			{"AWS_ACCESS_KEY_ID", c.AWSAccessKeyID},
+			{"AWS_SECRET_ACCESS_KEY", c.AWSSecretAccessKey},
+		} {
+			if setting[1] == "" {
+				p.add("%s is required when STORAGE_BACKEND=s3", setting[0])
+			}
+		}
+		if cloudFront {
+			if c.CloudFrontURL == "" || c.CloudFrontKeyPairID == "" || c.CloudFrontPrivateKey == "" {
+				p.add("CLOUDFRONT_URL, CLOUDFRONT_KEY_PAIR_ID, and CLOUDFRONT_PRIVATE_KEY must be set together")
+			} else if parsed, err := url.Parse(c.CloudFrontURL); err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+				p.add("CLOUDFRONT_URL must be an https URL")
+			}
+		}
+	case StorageLocal:
+		if c.LocalStorageDir == "" {
+			p.add("LOCAL_STORAGE_DIR is required when STORAGE_BACKEND=local")
+		}
+		if proxy, err := strconv.ParseBool(c.ImageProxy); err == nil && !proxy {
+			p.add("IMAGE_PROXY cannot be turned off when STORAGE_BACKEND=local; local files are only served through the API")
+		}
+		if cloudFront {
+			p.add("CLOUDFRONT_URL, CLOUDFRONT_KEY_PAIR_ID, and CLOUDFRONT_PRIVATE_KEY only apply when STORAGE_BACKEND=s3")
+		}
+		if c.UploadStagingDir != "" {
+			p.add("UPLOAD_STAGING_DIR only applies when STORAGE_BACKEND=s3")
+		}
+	default:
+		p.add("STORAGE_BACKEND must be one of: s3, local")
+	}
+
+	switch c.GetAuthProvider() {
+	case "auth0":
+		if c.Auth0Domain == "" {
+			p.add("AUTH0_DOMAIN is required when AUTH_PROVIDER=auth0")
+		} else if strings.Contains(c.Auth0Domain, "/") {
+			p.add("AUTH0_DOMAIN must be a bare domain such as your-tenant.auth0.com, without https:// or a path")
+		}
+		if c.Auth0Audience == "" {
+			p.add("AUTH0_AUDIENCE is required when AUTH_PROVIDER=auth0")
+		}
+		if (c.Auth0MgmtClientID == "") != (c.Auth0MgmtSecret == "") {
+			p.add("AUTH0_MGMT_CLIENT_ID and AUTH0_MGMT_CLIENT_SECRET must be set together")
+		}
+	case "oidc":
+		if c.OIDCIssuerURL == "" {
+			p.add("OIDC_ISSUER_URL is required when AUTH_PROVIDER=oidc")
+		} else {
+			validateHTTPURL(p, "OIDC_ISSUER_URL", c.OIDCIssuerURL)
+		}
+		if c.OIDCAudience == "" {
+			p.add("OIDC_AUDIENCE is required when AUTH_PROVIDER=oidc")
+		}
+		if c.Auth0MgmtClientID != "" || c.Auth0MgmtSecret != "" {
+			p.add("AUTH0_MGMT_CLIENT_ID and AUTH0_MGMT_CLIENT_SECRET only apply when AUTH_PROVIDER=auth0")
+		}
+	default:
+		p.add("AUTH_PROVIDER must be one of: auth0, oidc")
+	}
+}
+
+// validateBool records a problem when a set flag is not true or false
+func validateBool(p *problems, name, value string) {
+	if value == "" {
+		return
+	}
+	if _, err := strconv.ParseBool(value); err != nil {
+		p.add("%s must be true or false", name)
+	}
+}
+
+// validateHTTPURL records a problem when value is not an absolute http or https URL
+func validateHTTPURL(p *problems, name, value string) {
+	if parsed, err := url.Parse(value); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		p.add("%s must be an http or https URL", name)
+	}
+}
+
 // IsProduction returns true if the application is running in production mode
 func (c *Config) IsProduction() bool {
 	return c.GoEnv == "production"
@@ -124,6 +657,313 @@ func (c *Config) GetDatabaseURL() string {
 	return c.DatabaseURL
 }
 
+// GetAuthProvider returns the configured identity provider, defaulting to auth0
+func (c *Config) GetAuthProvider() string {
+	if c.AuthProvider == "" {
+		return "auth0"
+	}
+	return strings.ToLower(c.AuthProvider)
+}
+
+// GetRushSurcharge returns the fee added to accepted rush orders, defaulting to DefaultRushSurcharge
+func (c *Config) GetRushSurcharge() float64 {
+	if c.RushSurcharge == "" {
+		return DefaultRushSurcharge
+	}
+	surcharge, err := strconv.ParseFloat(c.RushSurcharge, 64)
+	if err != nil || surcharge < 0 {
+		return DefaultRushSurcharge
+	}
+	return surcharge
+}
+
+// GetRushSurchargePercent returns the rush fee as a percentage of the quoted price
+// The boolean is false when RUSH_SURCHARGE_PERCENT is unset and the flat RUSH_SURCHARGE applies instead
+func (c *Config) GetRushSurchargePercent() (float64, bool) {
+	if c.RushSurchargePct == "" {
+		return 0, false
+	}
+	percent, err := strconv.ParseFloat(c.RushSurchargePct, 64)
+	if err != nil || percent < 0 {
+		return 0, false
+	}
+	return percent, true
+}
+
+// GetDBMaxOpenConns returns the most connections the pool opens to the database, defaulting to DefaultDBMaxOpenConns
+func (c *Config) GetDBMaxOpenConns() int {
+	limit, err := strconv.Atoi(c.DBMaxOpenConns)
+	if err != nil || limit <= 0 {
+		return DefaultDBMaxOpenConns
+	}
+	return limit
+}
+
+// GetDBMaxIdleConns returns how many idle connections the pool keeps open, defaulting to DefaultDBMaxIdleConns
+// It never exceeds GetDBMaxOpenConns
+func (c *Config) GetDBMaxIdleConns() int {
+	limit, err := strconv.Atoi(c.DBMaxIdleConns)
+	if err != nil || limit < 0 {
+		limit = DefaultDBMaxIdleConns
+	}
+	return min(limit, c.GetDBMaxOpenConns())
+}
+
+// GetDBConnMaxLifetime returns how long a pooled connection is reused before it is replaced,
+// defaulting to DefaultDBConnMaxLifetime; zero keeps connections open indefinitely
+func (c *Config) GetDBConnMaxLifetime() time.Duration {
+	lifetime, err := time.ParseDuration(c.DBConnMaxLifetime)
+	if err != nil || lifetime < 0 {
+		return DefaultDBConnMaxLifetime
+	}
+	return lifetime
+}
+
+// GetSlowQueryThreshold returns how long a query may take before it is logged, defaulting to
+// DefaultSlowQueryThreshold; zero disables slow query logging
+func (c *Config) GetSlowQueryThreshold() time.Duration {
+	threshold, err := time.ParseDuration(c.SlowQueryThreshold)
+	if err != nil || threshold < 0 {
+		return DefaultSlowQueryThreshold
+	}
+	return threshold
+}
+
+// GetRedisCacheTTL returns how long cached lookups live, defaulting to DefaultRedisCacheTTL
+func (c *Config) GetRedisCacheTTL() time.Duration {
+	ttl, err := time.ParseDuration(c.RedisCacheTTL)
+	if err != nil || ttl <= 0 {
+		return DefaultRedisCacheTTL
+	}
+	return ttl
+}
+
+// Auth0ManagementEnabled reports whether Management API credentials are configured for role sync
+func (c *Config) Auth0ManagementEnabled() bool {
+	return c.Auth0MgmtClientID != "" && c.Auth0MgmtSecret != "" && c.Auth0Domain != ""
+}
+
+// ImageModerationEnabled reports whether uploaded design images are screened before technicians see them
+func (c *Config) ImageModerationEnabled() bool {
+	return c.ImageModerationURL != ""
+}
+
+// MessageFilterEnabled reports whether messages are checked against a word list or a moderation endpoint before they are sent
+func (c *Config) MessageFilterEnabled() bool {
+	return len(c.GetMessageFilterWords()) > 0 || c.MessageModerationURL != ""
+}
+
+// GetMessageFilterWords returns the lowercased words masked or blocked in messages
+func (c *Config) GetMessageFilterWords() []string {
+	return splitList(c.MessageFilterWords)
+}
+
+// GetMessageFilterAction returns what happens to a message using a filtered word, MessageFilterMask or MessageFilterBlock
+func (c *Config) GetMessageFilterAction() string {
+	action := strings.ToLower(strings.TrimSpace(c.MessageFilterAction))
+	if action == "" {
+		return MessageFilterMask
+	}
+	return action
+}
+
+// TracingEnabled reports whether request traces are exported to an OpenTelemetry collector
+func (c *Config) TracingEnabled() bool {
+	return c.OTLPEndpoint != ""
+}
+
+// ErrorReportingEnabled reports whether server errors and panics are reported to Sentry
+func (c *Config) ErrorReportingEnabled() bool {
+	return c.SentryDSN != ""
+}
+
+// CaptchaEnabled reports whether guest quote requests must pass a CAPTCHA check
+func (c *Config) CaptchaEnabled() bool {
+	return c.CaptchaSecret != ""
+}
+
+// TermsEnabled reports whether users must accept versioned terms of service before placing orders
+func (c *Config) TermsEnabled() bool {
+	return c.TermsVersion != ""
+}
+
+// GetTermsMinimumVersion returns the oldest terms version that still lets a customer place orders,
+// defaulting to TERMS_VERSION so that every new version must be accepted again
+func (c *Config) GetTermsMinimumVersion() string {
+	if c.TermsMinimumVersion == "" {
+		return c.TermsVersion
+	}
+	return c.TermsMinimumVersion
+}
+
+// GetAPIV1DeprecatedAt returns when /api/v1 was deprecated, or the zero time while it is not
+func (c *Config) GetAPIV1DeprecatedAt() time.Time {
+	deprecatedAt, _ := time.Parse(time.DateOnly, c.APIV1DeprecatedAt)
+	return deprecatedAt
+}
+
+// GetAPIV1Sunset returns when /api/v1 stops being served, or the zero time while no date is set
+func (c *Config) GetAPIV1Sunset() time.Time {
+	sunset, _ := time.Parse(time.DateOnly, c.APIV1Sunset)
+	return sunset
+}
+
+// GetJWKSCacheTTL returns how long the identity provider's signing keys are cached, defaulting to DefaultJWKSCacheTTL
+func (c *Config) GetJWKSCacheTTL() time.Duration {
+	ttl, err := time.ParseDuration(c.JWKSCacheTTL)
+	if err != nil || ttl <= 0 {
+		return DefaultJWKSCacheTTL
+	}
+	return ttl
+}
+
+// GetJWKSGracePeriod returns how long cached signing keys outlive their TTL while the identity provider
+// cannot be reached, defaulting to DefaultJWKSGracePeriod; zero disables the grace period
+func (c *Config) GetJWKSGracePeriod() time.Duration {
+	grace, err := time.ParseDuration(c.JWKSGracePeriod)
+	if err != nil || grace < 0 {
+		return DefaultJWKSGracePeriod
+	}
+	return grace
+}
+
+// CookieAuthEnabled reports whether browsers may exchange their token for an httpOnly session cookie
+// Cookie-authenticated requests that change state must carry the CSRF token
+func (c *Config) CookieAuthEnabled() bool {
+	enabled, err := strconv.ParseBool(c.CookieAuth)
+	return err == nil && enabled
+}
+
+// HealthDetailsEnabled reports whether /api/v1/health?detail=true reports each dependency's status
+// The report names internal systems and their errors, so it is meant for deployments where the
+// health endpoint is only reachable from inside the network
+func (c *Config) HealthDetailsEnabled() bool {
+	enabled, err := strconv.ParseBool(c.HealthDetails)
+	return err == nil && enabled
+}
+
+// MetricsEnabled reports whether /metrics is served, to scrapers presenting METRICS_TOKEN as a bearer token
+// The metrics include revenue and order volumes, so without a token they are not served at all
+func (c *Config) MetricsEnabled() bool {
+	return c.MetricsToken != ""
+}
+
+// DebugVarsEnabled reports whether runtime counters are served at /debug/vars, behind METRICS_TOKEN
+// They include the command line and memory statistics, so they are off by default
+func (c *Config) DebugVarsEnabled() bool {
+	enabled, err := strconv.ParseBool(c.DebugVars)
+	return err == nil && enabled
+}
+
+// ImpersonationWritesAllowed reports whether admins impersonating a user may change state as that user
+// Impersonated requests are read-only by default
+func (c *Config) ImpersonationWritesAllowed() bool {
+	enabled, err := strconv.ParseBool(c.ImpersonationWrites)
+	return err == nil && enabled
+}
+
+// GetUserCacheTTL returns how long resolved caller profiles are reused, defaulting to DefaultUserCacheTTL
+// Zero disables the cache
+func (c *Config) GetUserCacheTTL() time.Duration {
+	ttl, err := time.ParseDuration(c.UserCacheTTL)
+	if err != nil || ttl < 0 {
+		return DefaultUserCacheTTL
+	}
+	return ttl
+}
+
+// GetEmailFoldGmailDots reports whether dots in Gmail addresses are ignored when normalizing emails
+func (c *Config) GetEmailFoldGmailDots() bool {
+	enabled, err := strconv.ParseBool(c.EmailFoldGmailDots)
+	return err == nil && enabled
+}
+
+// GetStaleOrderExpiry returns how long an order may wait for review before it expires,
+// defaulting to DefaultStaleOrderDays; zero disables expiry
+func (c *Config) GetStaleOrderExpiry() time.Duration {
+	days, err := strconv.Atoi(c.StaleOrderDays)
+	if err != nil || days < 0 {
+		days = DefaultStaleOrderDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// GetOrphanImageRetention returns how long an upload nothing refers to is kept before it is deleted,
+// defaulting to DefaultOrphanImageDays; zero disables the cleanup
+func (c *Config) GetOrphanImageRetention() time.Duration {
+	days, err := strconv.Atoi(c.OrphanImageDays)
+	if err != nil || days < 0 {
+		days = DefaultOrphanImageDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// GetStorageBackend returns where uploaded files are kept: StorageS3 (the default) or StorageLocal
+func (c *Config) GetStorageBackend() string {
+	backend := strings.ToLower(strings.TrimSpace(c.StorageBackend))
+	if backend == "" {
+		return StorageS3
+	}
+	return backend
+}
+
+// GetEventBus returns how domain events reach their subscribers: EventBusMemory (the default) or EventBusRedis
+func (c *Config) GetEventBus() string {
+	transport := strings.ToLower(strings.TrimSpace(c.EventBus))
+	if transport == "" {
+		return EventBusMemory
+	}
+	return transport
+}
+
+// BackgroundUploadsEnabled reports whether order images are staged in UPLOAD_STAGING_DIR and moved to S3
+// in the background, rather than stored in S3 before the order is created
+func (c *Config) BackgroundUploadsEnabled() bool {
+	return c.UploadStagingDir != "" && c.GetStorageBackend() == StorageS3
+}
+
+// ImageProxyEnabled reports whether images are served through the API's signed /files links
+// rather than signed S3 or CloudFront URLs; local storage is always served through the API
+func (c *Config) ImageProxyEnabled() bool {
+	if c.GetStorageBackend() == StorageLocal {
+		return true
+	}
+	enabled, err := strconv.ParseBool(c.ImageProxy)
+	return err == nil && enabled
+}
+
+// GetImageProxyURL returns the base URL the API's signed /files links start with,
+// defaulting to the API on localhost
+func (c *Config) GetImageProxyURL() string {
+	if c.ImageProxyURL == "" {
+		return "http://localhost:" + c.Port
+	}
+	return strings.TrimRight(c.ImageProxyURL, "/")
+}
+
+// CloudFrontEnabled reports whether S3 images are delivered through signed CloudFront URLs
+func (c *Config) CloudFrontEnabled() bool {
+	return c.CloudFrontURL != "" && c.CloudFrontKeyPairID != "" && c.CloudFrontPrivateKey != ""
+}
+
+// GetImageURLExpiry returns how long signed image URLs stay valid, defaulting to DefaultImageURLExpiry
+func (c *Config) GetImageURLExpiry() time.Duration {
+	minutes, err := strconv.Atoi(c.ImageURLExpiryMinutes)
+	if err != nil || minutes <= 0 {
+		return DefaultImageURLExpiry
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// GetQuoteRateLimit returns how many quote requests one client IP may submit per hour, defaulting to DefaultQuoteRateLimit
+func (c *Config) GetQuoteRateLimit() int {
+	limit, err := strconv.Atoi(c.QuoteRateLimit)
+	if err != nil || limit <= 0 {
+		return DefaultQuoteRateLimit
+	}
+	return limit
+}
+
 // getEnv retrieves an environment variable or returns a default value
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -144,3 +984,81 @@ func (c *Config) GetCORSOrigins() []string {
 	}
 	return origins
 }
+
+// GetTrustedProxies returns the addresses of the proxies whose X-Forwarded-For header gives the client IP
+// It is nil by default, so the client IP is the address of the connection and cannot be forged with the header
+func (c *Config) GetTrustedProxies() []string {
+	if c.TrustedProxies == "" {
+		return nil
+	}
+	proxies := strings.Split(c.TrustedProxies, ",")
+	for i, proxy := range proxies {
+		proxies[i] = strings.TrimSpace(proxy)
+	}
+	return proxies
+}
+
+// GetInvoiceTaxPercent returns the sales tax rate printed on invoices as a percentage of the order price
+// It defaults to zero, for sellers who do not charge tax
+func (c *Config) GetInvoiceTaxPercent() float64 {
+	percent, err := strconv.ParseFloat(c.InvoiceTaxPct, 64)
+	if err != nil || percent < 0 || percent > 100 {
+		return 0
+	}
+	return percent
+}
+
+// GetCurrency returns the ISO 4217 code of the currency the studio prices new orders, add-ons, and catalog designs in
+func (c *Config) GetCurrency() string {
+	if code, ok := utils.CanonicalCurrency(c.Currency); ok {
+		return code
+	}
+	return utils.DefaultCurrency
+}
+
+// GetPIIEncryptionKey returns the key that encrypts user names and emails at rest, or nil when encryption is off
+func (c *Config) GetPIIEncryptionKey() []byte {
+	key, err := utils.ParseFieldCipherKey(c.PIIEncryptionKey)
+	if err != nil {
+		return nil
+	}
+	return key
+}
+
+// GetMaxJSONBodyBytes returns the largest request body accepted outside multipart file uploads
+func (c *Config) GetMaxJSONBodyBytes() int64 {
+	limit, err := strconv.ParseInt(c.MaxJSONBodyBytes, 10, 64)
+	if err != nil || limit <= 0 {
+		return DefaultMaxJSONBodyBytes
+	}
+	return limit
+}
+
+// GetMaxUploadMB returns the largest accepted image upload in megabytes
+func (c *Config) GetMaxUploadMB() int64 {
+	limit, err := strconv.ParseInt(c.MaxUploadMB, 10, 64)
+	if err != nil || limit <= 0 {
+		return utils.DefaultMaxUploadMB
+	}
+	return limit
+}
+
+// GetAllowedImageTypes returns the MIME types accepted for image uploads, detected from file content
+func (c *Config) GetAllowedImageTypes() []string {
+	imageTypes := splitList(c.AllowedImageTypes)
+	if len(imageTypes) == 0 {
+		return []string{utils.DefaultImageType}
+	}
+	return imageTypes
+}
+
+// splitList splits a comma-separated setting into its lowercased, non-empty entries
+func splitList(value string) []string {
+	var entries []string
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.ToLower(strings.TrimSpace(entry)); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
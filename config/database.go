@@ -1,17 +1,24 @@
 package config
 
 import (
+	"context"
 	"fmt"
 	"log"
 
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
 var DB *gorm.DB
 
+// ReplicaDB is the read replica connection, or nil when REPLICA_DATABASE_URL is unset
+var ReplicaDB *gorm.DB
+
 // ConnectDatabase establishes a connection to the PostgreSQL database
 // It uses the Config struct to get the appropriate database URL
+// When REPLICA_DATABASE_URL is set, a second connection to the read replica is opened too
 func ConnectDatabase() error {
 	// Load configuration
 	cfg, err := Load()
@@ -23,15 +30,73 @@ func ConnectDatabase() error {
 	databaseURL := cfg.GetDatabaseURL()
 
 	// Connect to database
-	DB, err = gorm.Open(postgres.Open(databaseURL), &gorm.Config{})
+	DB, err = openPostgres(cfg, databaseURL)
 	if err != nil {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
 
+	if cfg.ReplicaDatabaseURL != "" {
+		ReplicaDB, err = openPostgres(cfg, cfg.ReplicaDatabaseURL)
+		if err != nil {
+			return fmt.Errorf("failed to connect to read replica: %w", err)
+		}
+		log.Println("Read replica connection established successfully")
+	}
+
 	log.Printf("Database connection established successfully (env: %s)", cfg.GoEnv)
 	return nil
 }
 
+// openPostgres connects to a PostgreSQL database with the configured pool, query tracing and slow query logging
+func openPostgres(cfg *Config, databaseURL string) (*gorm.DB, error) {
+	db, err := gorm.Open(postgres.Open(databaseURL), &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database instance: %w", err)
+	}
+	sqlDB.SetMaxOpenConns(cfg.GetDBMaxOpenConns())
+	sqlDB.SetMaxIdleConns(cfg.GetDBMaxIdleConns())
+	sqlDB.SetConnMaxLifetime(cfg.GetDBConnMaxLifetime())
+
+	if err := db.Use(QueryTracer{}); err != nil {
+		return nil, fmt.Errorf("failed to register query tracing: %w", err)
+	}
+	if threshold := cfg.GetSlowQueryThreshold(); threshold > 0 {
+		if err := db.Use(NewSlowQueryLogger(threshold)); err != nil {
+			return nil, fmt.Errorf("failed to register slow query logging: %w", err)
+		}
+	}
+	return db, nil
+}
+
+// mockDatabaseDSN names a SQLite database that lives in memory and is shared by every pooled connection
+const mockDatabaseDSN = "file:kendalls-nails-mock?mode=memory&cache=shared&_busy_timeout=5000"
+
+// ConnectMockDatabase opens an empty in-memory SQLite database for mock mode (serve -mock)
+// Nothing is persisted; the data is gone when the process exits
+func ConnectMockDatabase() error {
+	db, err := gorm.Open(sqlite.Open(mockDatabaseDSN), &gorm.Config{})
+	if err != nil {
+		return fmt.Errorf("failed to open in-memory database: %w", err)
+	}
+
+	// The database lives only as long as one connection to it stays open
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database instance: %w", err)
+	}
+	sqlDB.SetConnMaxIdleTime(0)
+	sqlDB.SetConnMaxLifetime(0)
+
+	DB = db
+	log.Println("In-memory mock database ready")
+	return nil
+}
+
 // GetDB returns the database instance
 func GetDB() *gorm.DB {
 	return DB
@@ -41,3 +106,57 @@ func GetDB() *gorm.DB {
 func SetDB(db *gorm.DB) {
 	DB = db
 }
+
+// GetReplicaDB returns the read replica, or the database instance when no replica is configured
+// Reads from the replica may lag recent writes; only use it for lists and reports
+func GetReplicaDB() *gorm.DB {
+	if ReplicaDB != nil {
+		return ReplicaDB
+	}
+	return DB
+}
+
+// SetReplicaDB sets the read replica instance (primarily for testing)
+func SetReplicaDB(db *gorm.DB) {
+	ReplicaDB = db
+}
+
+// txContextKey is the context key of the request transaction
+type txContextKey struct{}
+
+// WithTx returns a copy of ctx carrying a transaction for repositories to run in
+func WithTx(ctx context.Context, tx *gorm.DB) context.Context {
+	return context.WithValue(ctx, txContextKey{}, tx)
+}
+
+// TxFromContext returns the transaction carried by ctx, if any
+func TxFromContext(ctx context.Context) (*gorm.DB, bool) {
+	tx, ok := ctx.Value(txContextKey{}).(*gorm.DB)
+	return tx, ok && tx != nil
+}
+
+// GetDBFor returns the transaction carried by ctx, or the database instance when there is none
+func GetDBFor(ctx context.Context) *gorm.DB {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx
+	}
+	return withQueryLabel(ctx, DB)
+}
+
+// GetReplicaDBFor returns the transaction carried by ctx, or the read replica when there is none
+// Inside a transaction the request reads its own writes, which the replica may not have yet
+func GetReplicaDBFor(ctx context.Context) *gorm.DB {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx
+	}
+	return withQueryLabel(ctx, GetReplicaDB())
+}
+
+// withQueryLabel attaches a ctx labelled with WithQueryLabel to db so slow queries name their route,
+// or a ctx carrying a request span so queries are traced as part of the request
+func withQueryLabel(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if _, ok := QueryLabelFromContext(ctx); (ok || trace.SpanContextFromContext(ctx).IsValid()) && db != nil {
+		return db.WithContext(ctx)
+	}
+	return db
+}
@@ -1,10 +1,12 @@
 package config
 
 import (
+	"context"
 	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
 )
 
 func TestGetDB(t *testing.T) {
@@ -17,6 +19,39 @@ func TestGetDB(t *testing.T) {
 	// Note: We don't actually connect in this unit test
 }
 
+func TestGetDBFor(t *testing.T) {
+	DB = &gorm.DB{}
+	defer func() { DB = nil }()
+
+	// Without a transaction in the context, the database instance is used
+	ctx := context.Background()
+	assert.Same(t, DB, GetDBFor(ctx))
+
+	tx := &gorm.DB{}
+	assert.Same(t, tx, GetDBFor(WithTx(ctx, tx)))
+
+	// A labelled context is attached so slow queries name their route
+	labelled := WithQueryLabel(ctx, QueryLabel{Route: "GET /api/v1/orders"})
+	assert.Same(t, tx, GetDBFor(WithTx(labelled, tx)))
+}
+
+func TestGetReplicaDBFor(t *testing.T) {
+	DB = &gorm.DB{}
+	defer func() { DB, ReplicaDB = nil, nil }()
+
+	// Without a replica, reads fall back to the database instance
+	ctx := context.Background()
+	assert.Same(t, DB, GetReplicaDB())
+	assert.Same(t, DB, GetReplicaDBFor(ctx))
+
+	ReplicaDB = &gorm.DB{}
+	assert.Same(t, ReplicaDB, GetReplicaDBFor(ctx))
+
+	// A transaction reads its own writes, which the replica may not have yet
+	tx := &gorm.DB{}
+	assert.Same(t, tx, GetReplicaDBFor(WithTx(ctx, tx)))
+}
+
 func TestConnectDatabaseWithEnvVar(t *testing.T) {
 	// Save original env var
 	originalURL := os.Getenv("DATABASE_URL")
@@ -68,3 +103,20 @@ func TestConnectDatabaseWithoutEnvVar(t *testing.T) {
 		assert.NotNil(t, err, "Error should be returned when connection fails")
 	}
 }
+
+func TestConnectMockDatabase(t *testing.T) {
+	originalDB := DB
+	defer func() { DB = originalDB }()
+
+	assert.NoError(t, ConnectMockDatabase())
+	assert.NoError(t, DB.Exec("CREATE TABLE IF NOT EXISTS mock_probe (id integer)").Error)
+	assert.NoError(t, DB.Exec("INSERT INTO mock_probe (id) VALUES (1)").Error)
+
+	// Every pooled connection sees the same in-memory database
+	var count int64
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		return DB.Table("mock_probe").Count(&count).Error
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
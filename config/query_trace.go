@@ -0,0 +1,91 @@
+package config
+
+import (
+	"errors"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// querySpanKey is the statement setting holding the span of a running query
+const querySpanKey = "query_trace:span"
+
+// queryTracerName names the tracer query spans are recorded with
+const queryTracerName = "github.com/kendall-kelly/kendalls-nails-api/config"
+
+// QueryTracer is a GORM plugin recording a span for each query made within a traced request
+// Queries without a span in their context (background jobs, connections not built with GetDBFor)
+// are not traced, so they don't start traces of their own. As in the slow query log, the SQL is
+// recorded with placeholders rather than bound values
+type QueryTracer struct{}
+
+// Name implements gorm.Plugin
+func (QueryTracer) Name() string {
+	return "query_tracer"
+}
+
+// Initialize implements gorm.Plugin by tracing every kind of statement
+func (t QueryTracer) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	hooks := []struct {
+		operation     string
+		start, finish callbackRegistrar
+	}{
+		{"create", callbacks.Create().Before("gorm:create"), callbacks.Create().After("gorm:create")},
+		{"query", callbacks.Query().Before("gorm:query"), callbacks.Query().After("gorm:query")},
+		{"update", callbacks.Update().Before("gorm:update"), callbacks.Update().After("gorm:update")},
+		{"delete", callbacks.Delete().Before("gorm:delete"), callbacks.Delete().After("gorm:delete")},
+		{"row", callbacks.Row().Before("gorm:row"), callbacks.Row().After("gorm:row")},
+		{"raw", callbacks.Raw().Before("gorm:raw"), callbacks.Raw().After("gorm:raw")},
+	}
+	for _, hook := range hooks {
+		if err := hook.start.Register("query_trace:start", startQuerySpan(hook.operation)); err != nil {
+			return err
+		}
+		if err := hook.finish.Register("query_trace:finish", finishQuerySpan); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// startQuerySpan returns a callback starting a span for the statement when its context is traced
+func startQuerySpan(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx := db.Statement.Context
+		if ctx == nil || !trace.SpanContextFromContext(ctx).IsValid() {
+			return
+		}
+		_, span := otel.Tracer(queryTracerName).Start(ctx, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(attribute.String("db.system.name", db.Dialector.Name())))
+		db.InstanceSet(querySpanKey, span)
+	}
+}
+
+// finishQuerySpan ends the statement's span with its table, SQL, row count and error
+func finishQuerySpan(db *gorm.DB) {
+	value, ok := db.InstanceGet(querySpanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("db.collection.name", db.Statement.Table),
+		attribute.String("db.query.text", strings.Join(strings.Fields(db.Statement.SQL.String()), " ")),
+		attribute.Int64("db.response.returned_rows", db.RowsAffected),
+	)
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestQueryTracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(QueryTracer{}))
+	require.NoError(t, db.Exec("CREATE TABLE probes (id integer, email text)").Error)
+
+	// Queries outside a traced request start no trace of their own
+	require.NoError(t, db.Exec("INSERT INTO probes (id, email) VALUES (?, ?)", 1, "client@example.com").Error)
+	assert.Empty(t, recorder.Ended())
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "GET /orders")
+	var count int64
+	require.NoError(t, db.WithContext(ctx).Table("probes").Where("email = ?", "client@example.com").Count(&count).Error)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	query := spans[0]
+	assert.Equal(t, "gorm.query", query.Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), query.Parent().SpanID())
+	assert.Contains(t, query.Attributes(), attribute.String("db.collection.name", "probes"))
+	assert.Contains(t, query.Attributes(), attribute.String("db.query.text", "SELECT count(*) FROM `probes` WHERE email = ?"))
+}
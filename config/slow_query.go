@@ -0,0 +1,112 @@
+package config
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// slowQueryStartKey is the statement setting holding when a query started
+const slowQueryStartKey = "slow_query:started_at"
+
+// QueryLabel identifies the request a query runs for in the slow query log
+type QueryLabel struct {
+	Route     string // method and route pattern, e.g. "GET /api/v1/orders"
+	RequestID string
+}
+
+// queryLabelContextKey is the context key of the QueryLabel
+type queryLabelContextKey struct{}
+
+// WithQueryLabel returns a copy of ctx carrying the request a query runs for
+func WithQueryLabel(ctx context.Context, label QueryLabel) context.Context {
+	return context.WithValue(ctx, queryLabelContextKey{}, label)
+}
+
+// QueryLabelFromContext returns the QueryLabel carried by ctx, if any
+func QueryLabelFromContext(ctx context.Context) (QueryLabel, bool) {
+	label, ok := ctx.Value(queryLabelContextKey{}).(QueryLabel)
+	return label, ok
+}
+
+// SlowQueryLogger is a GORM plugin logging queries that take longer than Threshold
+// The SQL is logged with placeholders rather than bound values, which may hold personal data
+type SlowQueryLogger struct {
+	Threshold time.Duration
+	// Logf writes a slow query; defaults to log.Printf
+	Logf func(format string, args ...any)
+}
+
+// NewSlowQueryLogger creates a plugin logging queries slower than threshold with log.Printf
+func NewSlowQueryLogger(threshold time.Duration) *SlowQueryLogger {
+	return &SlowQueryLogger{Threshold: threshold, Logf: log.Printf}
+}
+
+// Name implements gorm.Plugin
+func (l *SlowQueryLogger) Name() string {
+	return "slow_query_logger"
+}
+
+// Initialize implements gorm.Plugin by timing every kind of statement
+func (l *SlowQueryLogger) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	timers := []struct{ start, finish callbackRegistrar }{
+		{callbacks.Create().Before("gorm:create"), callbacks.Create().After("gorm:create")},
+		{callbacks.Query().Before("gorm:query"), callbacks.Query().After("gorm:query")},
+		{callbacks.Update().Before("gorm:update"), callbacks.Update().After("gorm:update")},
+		{callbacks.Delete().Before("gorm:delete"), callbacks.Delete().After("gorm:delete")},
+		{callbacks.Row().Before("gorm:row"), callbacks.Row().After("gorm:row")},
+		{callbacks.Raw().Before("gorm:raw"), callbacks.Raw().After("gorm:raw")},
+	}
+	for _, timer := range timers {
+		if err := timer.start.Register("slow_query:start", startQueryTimer); err != nil {
+			return err
+		}
+		if err := timer.finish.Register("slow_query:finish", l.finishQuery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// callbackRegistrar is the positioned GORM callback a timer is registered on
+type callbackRegistrar interface {
+	Register(name string, fn func(*gorm.DB)) error
+}
+
+// startQueryTimer records when the statement started
+func startQueryTimer(db *gorm.DB) {
+	db.InstanceSet(slowQueryStartKey, time.Now())
+}
+
+// finishQuery logs the statement when it took longer than the threshold
+func (l *SlowQueryLogger) finishQuery(db *gorm.DB) {
+	value, ok := db.InstanceGet(slowQueryStartKey)
+	if !ok {
+		return
+	}
+	started, ok := value.(time.Time)
+	if !ok {
+		return
+	}
+	elapsed := time.Since(started)
+	if elapsed < l.Threshold {
+		return
+	}
+
+	route, requestID := "-", "-"
+	if db.Statement.Context != nil {
+		if label, ok := QueryLabelFromContext(db.Statement.Context); ok {
+			route, requestID = label.Route, label.RequestID
+		}
+	}
+	logf := l.Logf
+	if logf == nil {
+		logf = log.Printf
+	}
+	logf("Slow query (%s, %d rows) route=%q request_id=%s: %s",
+		elapsed.Round(time.Microsecond), db.RowsAffected, route, requestID, strings.Join(strings.Fields(db.Statement.SQL.String()), " "))
+}
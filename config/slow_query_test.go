@@ -0,0 +1,43 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestSlowQueryLogger(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+
+	var logged []string
+	plugin := &SlowQueryLogger{Threshold: time.Hour, Logf: func(format string, args ...any) {
+		logged = append(logged, fmt.Sprintf(format, args...))
+	}}
+	require.NoError(t, db.Use(plugin))
+	require.NoError(t, db.Exec("CREATE TABLE probes (id integer, email text)").Error)
+
+	// Queries under the threshold are not logged
+	require.NoError(t, db.Exec("INSERT INTO probes (id, email) VALUES (?, ?)", 1, "client@example.com").Error)
+	assert.Empty(t, logged)
+
+	plugin.Threshold = 0
+	ctx := WithQueryLabel(context.Background(), QueryLabel{Route: "GET /api/v1/orders", RequestID: "req-1"})
+	var count int64
+	require.NoError(t, db.WithContext(ctx).Table("probes").Where("email = ?", "client@example.com").Count(&count).Error)
+	require.Len(t, logged, 1)
+	assert.Contains(t, logged[0], `route="GET /api/v1/orders" request_id=req-1`)
+	assert.Contains(t, logged[0], "SELECT count(*) FROM `probes` WHERE email = ?")
+	assert.NotContains(t, logged[0], "client@example.com", "bound values are left out")
+
+	// Queries without a labelled context are still logged
+	require.NoError(t, db.Exec("DELETE FROM probes").Error)
+	require.Len(t, logged, 2)
+	assert.Contains(t, logged[1], `route="-" request_id=-`)
+}
@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/apiresponse"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+)
+
+// AddOnRequest represents the request body for creating or updating an add-on
+type AddOnRequest struct {
+	Name        string   `json:"name" binding:"required"`
+	Description string   `json:"description"`
+	Price       *float64 `json:"price" binding:"required,gte=0"`
+}
+
+// input converts the request into service input
+func (r AddOnRequest) input() services.AddOnInput {
+	return services.AddOnInput{
+		Name:        r.Name,
+		Description: r.Description,
+		Price:       *r.Price,
+	}
+}
+
+// ListAddOns handles GET /api/v1/addons - lists the add-on catalog
+func ListAddOns(c *gin.Context) {
+	if _, ok := currentUser(c); !ok {
+		return
+	}
+
+	addOns, err := services.GetAddOnService().ListAddOns()
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, addOns)
+}
+
+// CreateAddOn handles POST /api/v1/addons - adds an item to the catalog (technicians only)
+func CreateAddOn(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req AddOnRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	addOn, err := services.GetAddOnService().CreateAddOn(user, req.input())
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.Created(c, addOn)
+}
+
+// UpdateAddOn handles PUT /api/v1/addons/:id - updates a catalog item (technicians only)
+func UpdateAddOn(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req AddOnRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	addOn, err := services.GetAddOnService().UpdateAddOn(user, c.Param("id"), req.input())
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, addOn)
+}
+
+// DeleteAddOn handles DELETE /api/v1/addons/:id - retires a catalog item (technicians only)
+func DeleteAddOn(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := services.GetAddOnService().DeleteAddOn(user, c.Param("id")); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.Message(c, http.StatusOK, "Add-on deleted")
+}
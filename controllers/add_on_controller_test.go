@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddOnCatalog_TechnicianManagesItems(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	technician := models.User{Auth0ID: "auth0|tech", Name: "Technician User", Email: "tech@example.com", Role: "technician"}
+	db.Create(&technician)
+
+	router := setupTestRouter()
+	auth := mockAuthMiddleware(technician.Auth0ID, "technician", "mock-token")
+	router.GET("/addons", auth, ListAddOns)
+	router.POST("/addons", auth, CreateAddOn)
+	router.PUT("/addons/:id", auth, UpdateAddOn)
+	router.DELETE("/addons/:id", auth, DeleteAddOn)
+
+	// Create
+	body, _ := json.Marshal(map[string]interface{}{"name": "Rhinestones", "price": 4.00})
+	req, _ := http.NewRequest(http.MethodPost, "/addons", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	// Update
+	body, _ = json.Marshal(map[string]interface{}{"name": "Rhinestones", "description": "Set of 10", "price": 5.00})
+	req, _ = http.NewRequest(http.MethodPut, "/addons/1", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// List
+	req, _ = http.NewRequest(http.MethodGet, "/addons", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	data := response["data"].([]interface{})
+	assert.Len(t, data, 1)
+	assert.Equal(t, 5.0, data[0].(map[string]interface{})["price"])
+
+	// Delete retires the item from the catalog
+	req, _ = http.NewRequest(http.MethodDelete, "/addons/1", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var count int64
+	db.Model(&models.AddOn{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestCreateAddOn_AsCustomer_Forbidden(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+
+	router := setupTestRouter()
+	router.POST("/addons", mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token"), CreateAddOn)
+
+	body, _ := json.Marshal(map[string]interface{}{"name": "Rhinestones", "price": 4.00})
+	req, _ := http.NewRequest(http.MethodPost, "/addons", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestCreateAddOn_MissingPrice_Fails(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	technician := models.User{Auth0ID: "auth0|tech", Name: "Technician User", Email: "tech@example.com", Role: "technician"}
+	db.Create(&technician)
+
+	router := setupTestRouter()
+	router.POST("/addons", mockAuthMiddleware(technician.Auth0ID, "technician", "mock-token"), CreateAddOn)
+
+	body, _ := json.Marshal(map[string]interface{}{"name": "Rhinestones"})
+	req, _ := http.NewRequest(http.MethodPost, "/addons", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/apiresponse"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+)
+
+// AddressRequest represents the request body for creating or updating an address
+type AddressRequest struct {
+	Label      string `json:"label"`
+	Recipient  string `json:"recipient" binding:"required"`
+	Line1      string `json:"line1" binding:"required"`
+	Line2      string `json:"line2"`
+	City       string `json:"city" binding:"required"`
+	Region     string `json:"region"`
+	PostalCode string `json:"postal_code" binding:"required"`
+	Country    string `json:"country" binding:"required"`
+}
+
+// input converts the request into service input
+func (r AddressRequest) input() services.AddressInput {
+	return services.AddressInput{
+		Label:      r.Label,
+		Recipient:  r.Recipient,
+		Line1:      r.Line1,
+		Line2:      r.Line2,
+		City:       r.City,
+		Region:     r.Region,
+		PostalCode: r.PostalCode,
+		Country:    r.Country,
+	}
+}
+
+// ListMyAddresses handles GET /api/v1/users/me/addresses - lists the current user's saved addresses
+func ListMyAddresses(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	addresses, err := services.GetAddressService().ListAddresses(user)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, addresses)
+}
+
+// CreateMyAddress handles POST /api/v1/users/me/addresses - saves an address to the current user's address book
+func CreateMyAddress(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req AddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	address, err := services.GetAddressService().CreateAddress(user, req.input())
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.Created(c, address)
+}
+
+// UpdateMyAddress handles PUT /api/v1/users/me/addresses/:id - updates one of the current user's addresses
+func UpdateMyAddress(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req AddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	address, err := services.GetAddressService().UpdateAddress(user, c.Param("id"), req.input())
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, address)
+}
+
+// DeleteMyAddress handles DELETE /api/v1/users/me/addresses/:id - removes one of the current user's addresses
+func DeleteMyAddress(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := services.GetAddressService().DeleteAddress(user, c.Param("id")); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.Message(c, http.StatusOK, "Address deleted")
+}
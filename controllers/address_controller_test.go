@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddresses(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	other := models.User{Auth0ID: "auth0|other", Name: "Other Customer", Email: "other@example.com", Role: "customer"}
+	db.Create(&other)
+	technician := models.User{Auth0ID: "auth0|tech", Name: "Technician User", Email: "tech@example.com", Role: "technician"}
+	db.Create(&technician)
+	order := models.Order{Description: "Ships later", Quantity: 1, Status: "submitted", CustomerID: customer.ID}
+	db.Create(&order)
+
+	router := setupTestRouter()
+	me := mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token")
+	router.GET("/users/me/addresses", me, ListMyAddresses)
+	router.POST("/users/me/addresses", me, CreateMyAddress)
+	router.PUT("/users/me/addresses/:id", me, UpdateMyAddress)
+	router.DELETE("/users/me/addresses/:id", me, DeleteMyAddress)
+	router.PUT("/orders/:id/shipping-address", me, SetShippingAddress)
+	router.GET("/other/addresses", mockAuthMiddleware(other.Auth0ID, "customer", "mock-token"), ListMyAddresses)
+	router.PUT("/other/addresses/:id", mockAuthMiddleware(other.Auth0ID, "customer", "mock-token"), UpdateMyAddress)
+	router.PUT("/tech/orders/:id/review", mockAuthMiddleware(technician.Auth0ID, "technician", "mock-token"), ReviewOrder)
+
+	request := func(method, path string, body interface{}) (int, map[string]interface{}) {
+		var buf bytes.Buffer
+		if body != nil {
+			json.NewEncoder(&buf).Encode(body)
+		}
+		req, _ := http.NewRequest(method, path, &buf)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", "*")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response
+	}
+	home := map[string]interface{}{"label": "Home", "recipient": "Customer User", "line1": "1 Main St", "city": "Portland", "region": "OR", "postal_code": "97201", "country": "us"}
+
+	// Required fields and the country code are checked
+	code, _ := request(http.MethodPost, "/users/me/addresses", map[string]interface{}{"recipient": "Customer User"})
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = request(http.MethodPost, "/users/me/addresses", map[string]interface{}{"recipient": "Customer User", "line1": "1 Main St", "city": "Portland", "postal_code": "97201", "country": "XX"})
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, response := request(http.MethodPost, "/users/me/addresses", home)
+	assert.Equal(t, http.StatusCreated, code)
+	address := response["data"].(map[string]interface{})
+	assert.Equal(t, "US", address["country"])
+	addressPath := fmt.Sprintf("/users/me/addresses/%v", address["id"])
+
+	code, response = request(http.MethodGet, "/users/me/addresses", nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, response["data"], 1)
+
+	// Address books are private
+	code, response = request(http.MethodGet, "/other/addresses", nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, response["data"], 0)
+	code, _ = request(http.MethodPut, fmt.Sprintf("/other/addresses/%v", address["id"]), home)
+	assert.Equal(t, http.StatusNotFound, code)
+
+	// Accepting needs a shipping address
+	review := map[string]interface{}{"action": "accept", "price": 40.0}
+	code, response = request(http.MethodPut, fmt.Sprintf("/tech/orders/%d/review", order.ID), review)
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+	assert.Equal(t, "SHIPPING_ADDRESS_REQUIRED", response["error"].(map[string]interface{})["code"])
+
+	code, response = request(http.MethodPut, fmt.Sprintf("/orders/%d/shipping-address", order.ID), map[string]interface{}{"shipping_address_id": address["id"]})
+	assert.Equal(t, http.StatusOK, code)
+	shipping := response["data"].(map[string]interface{})["shipping_address"].(map[string]interface{})
+	assert.Equal(t, "1 Main St", shipping["line1"])
+
+	// Editing the address book leaves the order's copy as it was
+	moved := map[string]interface{}{"recipient": "Customer User", "line1": "9 Elm St", "city": "Salem", "postal_code": "97301", "country": "US"}
+	code, response = request(http.MethodPut, addressPath, moved)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "9 Elm St", response["data"].(map[string]interface{})["line1"])
+
+	code, response = request(http.MethodPut, fmt.Sprintf("/tech/orders/%d/review", order.ID), review)
+	assert.Equal(t, http.StatusOK, code)
+	shipping = response["data"].(map[string]interface{})["shipping_address"].(map[string]interface{})
+	assert.Equal(t, "1 Main St", shipping["line1"])
+
+	// The address can no longer change once the order is accepted
+	code, _ = request(http.MethodPut, fmt.Sprintf("/orders/%d/shipping-address", order.ID), map[string]interface{}{"shipping_address_id": address["id"]})
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+
+	code, _ = request(http.MethodDelete, addressPath, nil)
+	assert.Equal(t, http.StatusOK, code)
+	code, _ = request(http.MethodDelete, addressPath, nil)
+	assert.Equal(t, http.StatusNotFound, code)
+}
@@ -0,0 +1,358 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/apiresponse"
+	"github.com/kendall-kelly/kendalls-nails-api/middleware"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+)
+
+// ChangeUserRoleRequest represents the request body for changing a user's role
+type ChangeUserRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// ChangeUserRole handles PUT /api/v1/admin/users/:id/role - changes a user's role and mirrors it to Auth0 (admins only)
+// The user's existing tokens keep their old role claim until they expire; the API uses the stored role
+func ChangeUserRole(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req ChangeUserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	changed, err := services.GetRoleService().ChangeRole(user, c.Param("id"), req.Role)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	middleware.ForgetUser(changed.Auth0ID)
+
+	apiresponse.OK(c, changed)
+}
+
+// BanUserRequest represents the request body for banning a user
+type BanUserRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// BanUser handles PUT /api/v1/admin/users/:id/ban - bans a user (admins only)
+// Requests with the user's existing tokens are rejected with 403 BANNED from then on
+func BanUser(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req BanUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	banned, err := services.GetRoleService().BanUser(user, c.Param("id"), req.Reason)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	middleware.ForgetUser(banned.Auth0ID)
+
+	apiresponse.OK(c, banned)
+}
+
+// UnbanUser handles DELETE /api/v1/admin/users/:id/ban - lifts a user's ban (admins only)
+func UnbanUser(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	unbanned, err := services.GetRoleService().UnbanUser(user, c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	middleware.ForgetUser(unbanned.Auth0ID)
+
+	apiresponse.OK(c, unbanned)
+}
+
+// CreateAPIKeyRequest represents the request body for creating an integration API key
+type CreateAPIKeyRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// CreateAPIKey handles POST /api/v1/admin/api-keys - issues an API key for a server-to-server integration (admins only)
+// The key is only included in this response
+func CreateAPIKey(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	key, err := services.GetAPIKeyService().CreateKey(user, req.Name)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.Created(c, key)
+}
+
+// ListAPIKeys handles GET /api/v1/admin/api-keys - lists integration API keys (admins only)
+func ListAPIKeys(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	keys, err := services.GetAPIKeyService().ListKeys(user)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, keys)
+}
+
+// RevokeAPIKey handles DELETE /api/v1/admin/api-keys/:id - stops an integration API key from being used (admins only)
+func RevokeAPIKey(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	key, err := services.GetAPIKeyService().RevokeKey(user, c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, key)
+}
+
+// ListAuditLogs handles GET /api/v1/admin/audit-logs - lists privileged changes, newest first (admins only)
+// Filters: actor_id, action, target_type, target_id, and an after/before range of RFC 3339 timestamps
+func ListAuditLogs(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	// Parse pagination parameters
+	page := 1
+	limit := 20
+	if pageStr := c.Query("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		}
+	}
+
+	opts := services.ListAuditLogsOptions{Page: page, Limit: limit}
+	opts.Action = c.Query("action")
+	opts.TargetType = c.Query("target_type")
+
+	for param, target := range map[string]**uint{
+		"actor_id":  &opts.ActorID,
+		"target_id": &opts.TargetID,
+	} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil || id == 0 {
+			apierror.Respond(c, apierror.Validation("Invalid ID filter", map[string]string{
+				param: "must be a positive integer",
+			}))
+			return
+		}
+		parsed := uint(id)
+		*target = &parsed
+	}
+
+	for param, target := range map[string]**time.Time{
+		"after":  &opts.After,
+		"before": &opts.Before,
+	} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			apierror.Respond(c, apierror.Validation("Invalid date filter", map[string]string{
+				param: "must be an RFC 3339 timestamp such as 2026-01-31T00:00:00Z",
+			}))
+			return
+		}
+		parsed = parsed.UTC()
+		*target = &parsed
+	}
+
+	entries, total, err := services.GetAuditLogService().ListAuditLogs(user, opts)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.PureJSON(http.StatusOK, apiresponse.Paginated(entries, apiresponse.NewPagination(page, limit, total)))
+}
+
+// ReviewImageRequest represents the request body for overriding an image moderation verdict
+type ReviewImageRequest struct {
+	Status string `json:"status" binding:"required,oneof=approved rejected"`
+}
+
+// ListImageModerations handles GET /api/v1/admin/image-moderations - lists screened design images, oldest first (admins only)
+// The status filter defaults to pending_review, the images waiting on an admin; "all" lists every status
+func ListImageModerations(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	// Parse pagination parameters
+	page := 1
+	limit := 20
+	if pageStr := c.Query("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		}
+	}
+
+	status := c.DefaultQuery("status", models.ImagePendingReview)
+	if status == "all" {
+		status = ""
+	}
+
+	moderations, total, err := services.GetImageModerationService().ListModerations(user, status, page, limit)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	// Reviewers need to see the image to judge it
+	imageService := services.GetImageService()
+	for i := range moderations {
+		if url, err := imageService.GetImageURL(moderations[i].ImageS3Key); err == nil {
+			moderations[i].ImageURL = &url
+		}
+	}
+
+	c.PureJSON(http.StatusOK, apiresponse.Paginated(moderations, apiresponse.NewPagination(page, limit, total)))
+}
+
+// ReviewImageModeration handles PUT /api/v1/admin/image-moderations/:id/review - approves or rejects a screened image (admins only)
+// Approving an image makes it visible to technicians; rejecting keeps it hidden
+func ReviewImageModeration(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req ReviewImageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	moderation, err := services.GetImageModerationService().ReviewImage(user, c.Param("id"), req.Status)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, moderation)
+}
+
+// ReviewMessageViolationRequest represents the request body for reviewing a filtered message
+type ReviewMessageViolationRequest struct {
+	Status string `json:"status" binding:"required,oneof=confirmed dismissed"`
+}
+
+// ListMessageViolations handles GET /api/v1/admin/message-violations - lists messages the content filter masked or
+// blocked, oldest first (admins only)
+func ListMessageViolations(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	// Parse pagination parameters
+	page := 1
+	limit := 20
+	if pageStr := c.Query("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		}
+	}
+
+	status := c.DefaultQuery("status", models.ViolationPendingReview)
+	if status == "all" {
+		status = ""
+	}
+
+	violations, total, err := services.GetMessageFilterService().ListViolations(user, status, page, limit)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.PureJSON(http.StatusOK, apiresponse.Paginated(violations, apiresponse.NewPagination(page, limit, total)))
+}
+
+// ReviewMessageViolation handles PUT /api/v1/admin/message-violations/:id/review - confirms or dismisses a filtered
+// message (admins only)
+func ReviewMessageViolation(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req ReviewMessageViolationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	violation, err := services.GetMessageFilterService().ReviewViolation(user, c.Param("id"), req.Status)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, violation)
+}
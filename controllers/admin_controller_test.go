@@ -0,0 +1,467 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/middleware"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChangeUserRole(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	admin := models.User{Auth0ID: "auth0|admin", Name: "Admin User", Email: "admin@example.com", Role: "admin"}
+	db.Create(&admin)
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+
+	router := setupTestRouter()
+	router.PUT("/admin/users/:id/role", mockAuthMiddleware(admin.Auth0ID, "admin", "mock-token"), ChangeUserRole)
+	router.PUT("/customer/admin/users/:id/role", mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token"), ChangeUserRole)
+
+	changeRole := func(path, role string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		body, _ := json.Marshal(map[string]string{"role": role})
+		req, _ := http.NewRequest(http.MethodPut, path, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w, response
+	}
+
+	// Only admins can change roles
+	w, _ := changeRole(fmt.Sprintf("/customer/admin/users/%d/role", customer.ID), "technician")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w, _ = changeRole(fmt.Sprintf("/admin/users/%d/role", customer.ID), "owner")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// The customer is promoted to technician
+	w, response := changeRole(fmt.Sprintf("/admin/users/%d/role", customer.ID), "technician")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "technician", response["data"].(map[string]interface{})["role"])
+
+	var updated models.User
+	db.First(&updated, customer.ID)
+	assert.Equal(t, "technician", updated.Role)
+}
+
+func TestBanUser(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	admin := models.User{Auth0ID: "auth0|admin", Name: "Admin User", Email: "admin@example.com", Role: "admin"}
+	db.Create(&admin)
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+
+	router := setupTestRouter()
+	asAdmin := mockAuthMiddleware(admin.Auth0ID, "admin", "mock-token")
+	router.PUT("/admin/users/:id/ban", asAdmin, BanUser)
+	router.DELETE("/admin/users/:id/ban", asAdmin, UnbanUser)
+	router.GET("/users/me", mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token"), middleware.CurrentUser(), GetMyProfile)
+
+	request := func(method, path string, payload interface{}) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		if payload != nil {
+			_ = json.NewEncoder(&body).Encode(payload)
+		}
+		req, _ := http.NewRequest(method, path, &body)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	banPath := fmt.Sprintf("/admin/users/%d/ban", customer.ID)
+
+	assert.Equal(t, http.StatusOK, request(http.MethodGet, "/users/me", nil).Code)
+
+	// A ban needs a reason
+	w := request(http.MethodPut, banPath, map[string]string{})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = request(http.MethodPut, banPath, map[string]string{"reason": "Chargeback fraud"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"ban_reason":"Chargeback fraud"`)
+
+	// The customer's still-valid token is rejected
+	w = request(http.MethodGet, "/users/me", nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"BANNED"`)
+
+	var audit models.AuditLog
+	assert.NoError(t, db.Where("action = ?", models.AuditUserBanned).First(&audit).Error)
+	assert.Equal(t, admin.ID, audit.ActorID)
+	assert.Equal(t, customer.ID, audit.TargetID)
+
+	// Lifting the ban lets the token through again
+	assert.Equal(t, http.StatusOK, request(http.MethodDelete, banPath, nil).Code)
+	assert.Equal(t, http.StatusOK, request(http.MethodGet, "/users/me", nil).Code)
+	assert.Equal(t, http.StatusConflict, request(http.MethodDelete, banPath, nil).Code)
+}
+
+func TestListAuditLogs(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	admin := models.User{Auth0ID: "auth0|admin", Name: "Admin User", Email: "admin@example.com", Role: "admin"}
+	db.Create(&admin)
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+
+	router := setupTestRouter()
+	auth := mockAuthMiddleware(admin.Auth0ID, "admin", "mock-token")
+	router.PUT("/admin/users/:id/role", auth, ChangeUserRole)
+	router.GET("/admin/audit-logs", auth, ListAuditLogs)
+	router.GET("/customer/admin/audit-logs", mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token"), ListAuditLogs)
+
+	request := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewReader(data))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request(http.MethodPut, fmt.Sprintf("/admin/users/%d/role", customer.ID), map[string]string{"role": "technician"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// The role change is recorded with its actor and the values before and after
+	w = request(http.MethodGet, fmt.Sprintf("/admin/audit-logs?action=user.role_changed&target_type=user&target_id=%d&actor_id=%d", customer.ID, admin.ID), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data       []models.AuditLog `json:"data"`
+		Pagination struct {
+			Total int64 `json:"total"`
+		} `json:"pagination"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, int64(1), response.Pagination.Total)
+	if assert.Len(t, response.Data, 1) {
+		assert.Equal(t, admin.ID, response.Data[0].ActorID)
+		assert.Equal(t, "admin", response.Data[0].ActorRole)
+		assert.Equal(t, map[string]interface{}{"role": "customer"}, response.Data[0].Before)
+		assert.Equal(t, map[string]interface{}{"role": "technician"}, response.Data[0].After)
+	}
+
+	// Filters exclude entries that do not match
+	w = request(http.MethodGet, "/admin/audit-logs?action=order.status_changed", nil)
+	assert.JSONEq(t, `{"success":true,"data":[],"pagination":{"page":1,"limit":20,"total":0,"totalPages":0}}`, w.Body.String())
+
+	w = request(http.MethodGet, "/admin/audit-logs?actor_id=me", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = request(http.MethodGet, "/admin/audit-logs?after=yesterday", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Only admins can read the audit log
+	w = request(http.MethodGet, "/customer/admin/audit-logs", nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestAPIKeys(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	admin := models.User{Auth0ID: "auth0|admin", Name: "Admin User", Email: "admin@example.com", Role: "admin"}
+	db.Create(&admin)
+	technician := models.User{Auth0ID: "auth0|tech", Name: "Technician User", Email: "tech@example.com", Role: "technician"}
+	db.Create(&technician)
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	order := models.Order{Description: "Assigned", Quantity: 1, Status: "accepted", CustomerID: customer.ID, TechnicianID: &technician.ID}
+	db.Create(&order)
+
+	// Requests without an API key fall through to JWT authentication
+	requireToken := func(c *gin.Context) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+	}
+
+	router := setupTestRouter()
+	router.POST("/admin/api-keys", mockAuthMiddleware(admin.Auth0ID, "admin", "mock-token"), CreateAPIKey)
+	router.GET("/admin/api-keys", mockAuthMiddleware(admin.Auth0ID, "admin", "mock-token"), ListAPIKeys)
+	router.DELETE("/admin/api-keys/:id", mockAuthMiddleware(admin.Auth0ID, "admin", "mock-token"), RevokeAPIKey)
+	router.POST("/tech/admin/api-keys", mockAuthMiddleware(technician.Auth0ID, "technician", "mock-token"), CreateAPIKey)
+	readable := router.Group("", middleware.EnsureValidTokenOrAPIKey(requireToken), middleware.CurrentUser())
+	readable.GET("/orders", ListOrders)
+	readable.GET("/orders/:id", GetOrder)
+
+	request := func(method, path, apiKey string, body interface{}) (*httptest.ResponseRecorder, map[string]interface{}) {
+		payload, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(payload))
+		req.Header.Set("Content-Type", "application/json")
+		if apiKey != "" {
+			req.Header.Set(middleware.APIKeyHeader, apiKey)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w, response
+	}
+
+	// Only admins can create keys
+	w, _ := request(http.MethodPost, "/tech/admin/api-keys", "", map[string]string{"name": "Zapier"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w, response := request(http.MethodPost, "/admin/api-keys", "", map[string]string{"name": "Zapier"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	created := response["data"].(map[string]interface{})
+	apiKey := created["key"].(string)
+	assert.True(t, strings.HasPrefix(apiKey, "nak_"))
+	assert.Equal(t, apiKey[:12], created["prefix"])
+
+	// The key is never shown again
+	w, response = request(http.MethodGet, "/admin/api-keys", "", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	listed := response["data"].([]interface{})
+	assert.Len(t, listed, 1)
+	assert.NotContains(t, listed[0].(map[string]interface{}), "key")
+
+	// The key reads every order
+	w, response = request(http.MethodGet, "/orders", apiKey, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, response["data"], 1)
+
+	w, _ = request(http.MethodGet, fmt.Sprintf("/orders/%d", order.ID), apiKey, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var stored models.APIKey
+	db.First(&stored, uint(created["id"].(float64)))
+	assert.NotNil(t, stored.LastUsedAt)
+
+	// Unknown keys are rejected, and requests without a key need a JWT
+	w, response = request(http.MethodGet, "/orders", "nak_unknown", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "INVALID_API_KEY", response["error"].(map[string]interface{})["code"])
+
+	w, _ = request(http.MethodGet, "/orders", "", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// A revoked key stops working
+	w, _ = request(http.MethodDelete, fmt.Sprintf("/admin/api-keys/%d", stored.ID), "", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w, _ = request(http.MethodGet, "/orders", apiKey, nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+// flaggingModerator flags every image with its labels
+type flaggingModerator struct {
+	labels []string
+}
+
+func (m *flaggingModerator) ModerateImage(content []byte, contentType string) ([]string, error) {
+	return m.labels, nil
+}
+
+func TestImageModeration(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	previous := services.GetImageService()
+	services.NewMockImageService().SetAsMockForTesting()
+	defer services.SetImageService(previous)
+	services.SetImageModerator(&flaggingModerator{labels: []string{"suggestive"}})
+	defer services.SetImageModerator(nil)
+
+	admin := models.User{Auth0ID: "auth0|admin", Name: "Admin User", Email: "admin@example.com", Role: "admin"}
+	db.Create(&admin)
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	technician := models.User{Auth0ID: "auth0|tech", Name: "Technician User", Email: "tech@example.com", Role: "technician"}
+	db.Create(&technician)
+
+	router := setupTestRouter()
+	adminAuth := mockAuthMiddleware(admin.Auth0ID, "admin", "mock-token")
+	router.POST("/orders", mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token"), CreateOrder)
+	router.GET("/orders/:id", mockAuthMiddleware(technician.Auth0ID, "technician", "mock-token"), GetOrder)
+	router.GET("/admin/image-moderations", adminAuth, ListImageModerations)
+	router.PUT("/admin/image-moderations/:id/review", adminAuth, ReviewImageModeration)
+	router.PUT("/tech/admin/image-moderations/:id/review", mockAuthMiddleware(technician.Auth0ID, "technician", "mock-token"), ReviewImageModeration)
+
+	request := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewReader(data))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	getOrder := func(id uint) models.Order {
+		w := request(http.MethodGet, fmt.Sprintf("/orders/%d", id), nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			Data models.Order `json:"data"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return response.Data
+	}
+
+	// The customer's design image is flagged on upload
+	form, contentType := newProgressUpdateForm(t, "design.png", map[string]string{"description": "Pink chrome", "quantity": "1"})
+	req, _ := http.NewRequest(http.MethodPost, "/orders", form)
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var created struct {
+		Data models.Order `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &created)
+
+	// Technicians see that the image is held, but not the image
+	order := getOrder(created.Data.ID)
+	assert.Nil(t, order.ImageURL)
+	if assert.NotNil(t, order.ImageStatus) {
+		assert.Equal(t, models.ImagePendingReview, *order.ImageStatus)
+	}
+
+	// Admins see the held image in the review queue
+	w = request(http.MethodGet, "/admin/image-moderations", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var queue struct {
+		Data []models.ImageModeration `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &queue)
+	if !assert.Len(t, queue.Data, 1) {
+		return
+	}
+	held := queue.Data[0]
+	assert.Equal(t, []string{"suggestive"}, held.Labels)
+	assert.Equal(t, &customer.ID, held.UploaderID)
+	assert.NotNil(t, held.ImageURL)
+
+	// Only admins can override the verdict, and only to approved or rejected
+	w = request(http.MethodPut, fmt.Sprintf("/tech/admin/image-moderations/%d/review", held.ID), map[string]string{"status": "approved"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = request(http.MethodPut, fmt.Sprintf("/admin/image-moderations/%d/review", held.ID), map[string]string{"status": "pending_review"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = request(http.MethodPut, "/admin/image-moderations/999/review", map[string]string{"status": "approved"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Approving the image shows it to technicians
+	w = request(http.MethodPut, fmt.Sprintf("/admin/image-moderations/%d/review", held.ID), map[string]string{"status": "approved"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	order = getOrder(created.Data.ID)
+	assert.Nil(t, order.ImageStatus)
+	assert.NotNil(t, order.ImageURL)
+
+	w = request(http.MethodGet, "/admin/image-moderations", nil)
+	assert.JSONEq(t, `{"success":true,"data":[],"pagination":{"page":1,"limit":20,"total":0,"totalPages":0}}`, w.Body.String())
+	var audit models.AuditLog
+	assert.NoError(t, db.Where("action = ?", models.AuditImageReviewed).First(&audit).Error)
+	assert.Equal(t, map[string]interface{}{"status": "approved"}, audit.After)
+}
+
+func TestMessageViolations(t *testing.T) {
+	// Setup
+	db := setupMessageTestDB(t)
+	config.SetDB(db)
+
+	services.SetMessageFilter(services.NewMessageFilter([]string{"darn"}, config.MessageFilterMask, nil))
+	defer services.SetMessageFilter(nil)
+
+	admin := models.User{Auth0ID: "auth0|admin", Name: "Admin User", Email: "admin@example.com", Role: "admin"}
+	db.Create(&admin)
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	technician := models.User{Auth0ID: "auth0|tech", Name: "Technician User", Email: "tech@example.com", Role: "technician"}
+	db.Create(&technician)
+	order := models.Order{Description: "Test order", Quantity: 1, Status: "accepted", CustomerID: customer.ID, TechnicianID: &technician.ID}
+	db.Create(&order)
+
+	router := setupTestRouter()
+	adminAuth := mockAuthMiddleware(admin.Auth0ID, "admin", "mock-token")
+	router.POST("/orders/:id/messages", mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token"), SendMessage)
+	router.GET("/admin/message-violations", adminAuth, ListMessageViolations)
+	router.PUT("/admin/message-violations/:id/review", adminAuth, ReviewMessageViolation)
+	router.GET("/tech/admin/message-violations", mockAuthMiddleware(technician.Auth0ID, "technician", "mock-token"), ListMessageViolations)
+
+	request := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewReader(data))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	messagesPath := fmt.Sprintf("/orders/%d/messages", order.ID)
+
+	// Clean messages are sent as written
+	w := request(http.MethodPost, messagesPath, map[string]string{"text": "Thanks, see you Friday"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	// Words on the list are masked before the message is stored
+	w = request(http.MethodPost, messagesPath, map[string]string{"text": "Darn, I chipped one"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var sent struct {
+		Data models.Message `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &sent)
+	assert.Equal(t, "****, I chipped one", sent.Data.Text)
+	var stored models.Message
+	assert.NoError(t, db.First(&stored, sent.Data.ID).Error)
+	assert.Equal(t, "****, I chipped one", stored.Text)
+
+	// In block mode the message is rejected
+	services.SetMessageFilter(services.NewMessageFilter([]string{"darn"}, config.MessageFilterBlock, nil))
+	w = request(http.MethodPost, messagesPath, map[string]string{"text": "darn it"})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "MESSAGE_BLOCKED")
+	var count int64
+	db.Model(&models.Message{}).Count(&count)
+	assert.Equal(t, int64(2), count)
+
+	// Only admins see the review queue
+	w = request(http.MethodGet, "/tech/admin/message-violations", nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = request(http.MethodGet, "/admin/message-violations", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var queue struct {
+		Data []models.MessageViolation `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &queue)
+	if !assert.Len(t, queue.Data, 2) {
+		return
+	}
+	assert.Equal(t, models.MessageMasked, queue.Data[0].Action)
+	assert.Equal(t, "Darn, I chipped one", queue.Data[0].Text)
+	assert.Equal(t, []string{"darn"}, queue.Data[0].Terms)
+	assert.Equal(t, models.MessageBlocked, queue.Data[1].Action)
+
+	// Reviewing takes a violation out of the queue
+	w = request(http.MethodPut, fmt.Sprintf("/admin/message-violations/%d/review", queue.Data[0].ID), map[string]string{"status": "pending_review"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = request(http.MethodPut, "/admin/message-violations/999/review", map[string]string{"status": "confirmed"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = request(http.MethodPut, fmt.Sprintf("/admin/message-violations/%d/review", queue.Data[0].ID), map[string]string{"status": "dismissed"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = request(http.MethodGet, "/admin/message-violations", nil)
+	json.Unmarshal(w.Body.Bytes(), &queue)
+	assert.Len(t, queue.Data, 1)
+	w = request(http.MethodGet, "/admin/message-violations?status=all", nil)
+	json.Unmarshal(w.Body.Bytes(), &queue)
+	assert.Len(t, queue.Data, 2)
+	var audit models.AuditLog
+	assert.NoError(t, db.Where("action = ?", models.AuditViolationReviewed).First(&audit).Error)
+	assert.Equal(t, map[string]interface{}{"status": "dismissed"}, audit.After)
+}
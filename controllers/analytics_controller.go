@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/apiresponse"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+)
+
+// GetAnalyticsSummary handles GET /api/v1/analytics/summary - order and revenue analytics (staff only)
+// The optional weeks query parameter controls how many weeks of order counts are returned
+func GetAnalyticsSummary(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	weeks := 0
+	if weeksStr := c.Query("weeks"); weeksStr != "" {
+		parsed, err := strconv.Atoi(weeksStr)
+		if err != nil || parsed <= 0 {
+			apierror.Respond(c, apierror.Validation("Weeks must be a positive integer", nil))
+			return
+		}
+		weeks = parsed
+	}
+
+	summary, err := services.GetAnalyticsService().Summary(user, weeks)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, summary)
+}
+
+// slaReportColumns is the header row of the CSV export of the SLA report
+var slaReportColumns = []string{
+	"technician_id", "technician_name", "orders_reviewed", "orders_rejected", "average_acceptance_hours",
+	"orders_shipped", "average_production_hours", "orders_shipped_on_time", "on_time_shipping_percent",
+}
+
+// GetSLAReport handles GET /api/v1/admin/reports/sla - per-technician fulfillment SLA report (admin only)
+// The optional from and to query parameters are inclusive dates (YYYY-MM-DD) and default to the last 30 days;
+// format=csv returns the report as a CSV download instead of JSON
+func GetSLAReport(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		apierror.Respond(c, apierror.Validation("Invalid request data", map[string]string{"format": "must be json or csv"}))
+		return
+	}
+
+	var from, to time.Time
+	details := make(map[string]string)
+	if fromStr := c.Query("from"); fromStr != "" {
+		parsed, err := time.Parse(time.DateOnly, fromStr)
+		if err != nil {
+			details["from"] = "must be a date (YYYY-MM-DD)"
+		}
+		from = parsed
+	}
+	if toStr := c.Query("to"); toStr != "" {
+		parsed, err := time.Parse(time.DateOnly, toStr)
+		if err != nil {
+			details["to"] = "must be a date (YYYY-MM-DD)"
+		}
+		to = parsed.AddDate(0, 0, 1) // include the whole last day
+	}
+	if len(details) > 0 {
+		apierror.Respond(c, apierror.Validation("Invalid request data", details))
+		return
+	}
+	if !from.IsZero() && to.IsZero() {
+		to = from.Add(services.DefaultSLAReportPeriod)
+	}
+
+	report, err := services.GetAnalyticsService().SLAReport(user, from, to)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	if format == "csv" {
+		writeSLAReportCSV(c, report)
+		return
+	}
+
+	apiresponse.OK(c, report)
+}
+
+// writeSLAReportCSV writes the report as a CSV attachment with one row per technician
+// Metrics without data are left empty rather than written as zero
+func writeSLAReportCSV(c *gin.Context, report *services.SLAReport) {
+	optional := func(value *float64) string {
+		if value == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*value, 'f', 2, 64)
+	}
+	count := func(value int64) string { return strconv.FormatInt(value, 10) }
+
+	filename := fmt.Sprintf("sla-report-%s-%s.csv", report.From.Format(time.DateOnly), report.To.AddDate(0, 0, -1).Format(time.DateOnly))
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write(slaReportColumns)
+	for _, row := range report.Technicians {
+		_ = w.Write([]string{
+			strconv.FormatUint(uint64(row.TechnicianID), 10),
+			row.TechnicianName,
+			count(row.OrdersReviewed),
+			count(row.OrdersRejected),
+			optional(row.AverageAcceptanceHours),
+			count(row.OrdersShipped),
+			optional(row.AverageProductionHours),
+			count(row.OrdersShippedOnTime),
+			optional(row.OnTimeShippingPercent),
+		})
+	}
+	w.Flush()
+}
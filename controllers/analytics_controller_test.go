@@ -0,0 +1,138 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAnalyticsSummary_AsTechnician(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	technician := models.User{Auth0ID: "auth0|tech", Name: "Technician User", Email: "tech@example.com", Role: "technician"}
+	db.Create(&technician)
+
+	price := 50.0
+	db.Create(&models.Order{Description: "Delivered", Quantity: 1, Status: "delivered", Price: &price, CustomerID: customer.ID})
+	db.Create(&models.Order{Description: "New", Quantity: 1, Status: "submitted", CustomerID: customer.ID})
+
+	router := setupTestRouter()
+	router.GET("/analytics/summary", mockAuthMiddleware(technician.Auth0ID, "technician", "mock-token"), GetAnalyticsSummary)
+
+	req, _ := http.NewRequest(http.MethodGet, "/analytics/summary?weeks=4", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, float64(2), data["total_orders"])
+	assert.Equal(t, 50.0, data["revenue"].(map[string]interface{})["delivered"])
+	assert.Len(t, data["orders_per_week"].([]interface{}), 1)
+	assert.Nil(t, data["average_acceptance_hours"])
+}
+
+func TestGetAnalyticsSummary_AsCustomer_Forbidden(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+
+	router := setupTestRouter()
+	router.GET("/analytics/summary", mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token"), GetAnalyticsSummary)
+
+	req, _ := http.NewRequest(http.MethodGet, "/analytics/summary", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestGetSLAReport(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	technician := models.User{Auth0ID: "auth0|tech", Name: "Technician User", Email: "tech@example.com", Role: "technician"}
+	db.Create(&technician)
+	admin := models.User{Auth0ID: "auth0|admin", Name: "Admin User", Email: "admin@example.com", Role: "admin"}
+	db.Create(&admin)
+
+	created := time.Date(2026, 9, 1, 9, 0, 0, 0, time.UTC)
+	reviewed := created.Add(3 * time.Hour)
+	shipped := reviewed.Add(48 * time.Hour)
+	db.Create(&models.Order{Description: "Shipped", Quantity: 1, Status: "shipped", CustomerID: customer.ID, TechnicianID: &technician.ID,
+		CreatedAt: created, ReviewedAt: &reviewed, ShippedAt: &shipped})
+
+	router := setupTestRouter()
+	router.GET("/admin/reports/sla", mockAuthMiddleware(admin.Auth0ID, "admin", "mock-token"), GetSLAReport)
+	router.GET("/tech/admin/reports/sla", mockAuthMiddleware(technician.Auth0ID, "technician", "mock-token"), GetSLAReport)
+
+	request := func(path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("JSON", func(t *testing.T) {
+		w := request("/admin/reports/sla?from=2026-09-01&to=2026-09-30")
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		data := response["data"].(map[string]interface{})
+		assert.Equal(t, "2026-10-01T00:00:00Z", data["to"])
+		rows := data["technicians"].([]interface{})
+		assert.Len(t, rows, 1)
+		row := rows[0].(map[string]interface{})
+		assert.Equal(t, "Technician User", row["technician_name"])
+		assert.Equal(t, 3.0, row["average_acceptance_hours"])
+		assert.Equal(t, float64(1), row["orders_shipped"])
+		assert.Equal(t, 100.0, row["on_time_shipping_percent"])
+		assert.Nil(t, row["average_production_hours"])
+	})
+
+	t.Run("CSV", func(t *testing.T) {
+		w := request("/admin/reports/sla?from=2026-09-01&to=2026-09-30&format=csv")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "sla-report-2026-09-01-2026-09-30.csv")
+
+		records, err := csv.NewReader(w.Body).ReadAll()
+		assert.NoError(t, err)
+		assert.Len(t, records, 2)
+		assert.Equal(t, "technician_id", records[0][0])
+		assert.Equal(t, []string{fmt.Sprintf("%d", technician.ID), "Technician User", "1", "0", "3.00", "1", "", "1", "100.00"}, records[1])
+	})
+
+	t.Run("Invalid period", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, request("/admin/reports/sla?from=september").Code)
+		assert.Equal(t, http.StatusBadRequest, request("/admin/reports/sla?from=2026-09-30&to=2026-09-01").Code)
+		assert.Equal(t, http.StatusBadRequest, request("/admin/reports/sla?format=xlsx").Code)
+	})
+
+	t.Run("Technician forbidden", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, request("/tech/admin/reports/sla").Code)
+	})
+}
@@ -0,0 +1,194 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/apiresponse"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+)
+
+// SlotRequest represents the request body for publishing an appointment slot
+type SlotRequest struct {
+	StartsAt time.Time `json:"starts_at" binding:"required"` // RFC 3339
+	EndsAt   time.Time `json:"ends_at" binding:"required"`   // RFC 3339
+}
+
+// BookAppointmentRequest represents the request body for booking a fitting
+type BookAppointmentRequest struct {
+	SlotID uint `json:"slot_id" binding:"required"`
+}
+
+// parseCalendarRange reads the optional from and to query parameters (RFC 3339 timestamps)
+// It responds with a validation error and returns false when either is malformed
+func parseCalendarRange(c *gin.Context) (services.CalendarRange, bool) {
+	var window services.CalendarRange
+	details := make(map[string]string)
+	if fromStr := c.Query("from"); fromStr != "" {
+		parsed, err := time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			details["from"] = "must be a timestamp such as 2026-01-31T09:00:00Z"
+		}
+		window.From = parsed
+	}
+	if toStr := c.Query("to"); toStr != "" {
+		parsed, err := time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			details["to"] = "must be a timestamp such as 2026-01-31T17:00:00Z"
+		}
+		window.To = parsed
+	}
+	if len(details) > 0 {
+		apierror.Respond(c, apierror.Validation("Invalid request data", details))
+		return window, false
+	}
+	return window, true
+}
+
+// CreateAppointmentSlot handles POST /api/v1/appointment-slots - publishes a time the current technician is available for fittings
+func CreateAppointmentSlot(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req SlotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	slot, err := services.GetAppointmentService().CreateSlot(user, services.SlotInput{StartsAt: req.StartsAt, EndsAt: req.EndsAt})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.Created(c, slot)
+}
+
+// ListAppointmentSlots handles GET /api/v1/appointment-slots - lists a technician's slots
+// Technicians get their own slots with bookings; customers pass technician_id and get the open slots
+// The optional from and to query parameters limit the range, which defaults to the next 30 days
+func ListAppointmentSlots(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var technicianID uint
+	if idStr := c.Query("technician_id"); idStr != "" {
+		parsed, err := strconv.ParseUint(idStr, 10, 64)
+		if err != nil || parsed == 0 {
+			apierror.Respond(c, apierror.Validation("Technician ID must be a positive integer", nil))
+			return
+		}
+		technicianID = uint(parsed)
+	}
+	window, ok := parseCalendarRange(c)
+	if !ok {
+		return
+	}
+
+	slots, err := services.GetAppointmentService().ListSlots(user, technicianID, window)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, slots)
+}
+
+// DeleteAppointmentSlot handles DELETE /api/v1/appointment-slots/:id - removes one of the current technician's unbooked slots
+func DeleteAppointmentSlot(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := services.GetAppointmentService().DeleteSlot(user, c.Param("id")); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.Message(c, http.StatusOK, "Appointment slot removed")
+}
+
+// BookAppointment handles POST /api/v1/orders/:id/appointments - books a fitting with the order's technician (customer only)
+func BookAppointment(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req BookAppointmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	appointment, err := services.GetAppointmentService().BookAppointment(user, c.Param("id"), req.SlotID)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.Created(c, appointment)
+}
+
+// ListOrderAppointments handles GET /api/v1/orders/:id/appointments - lists the fittings booked for an order, cancelled ones included
+func ListOrderAppointments(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	appointments, err := services.GetAppointmentService().ListOrderAppointments(user, c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, appointments)
+}
+
+// ListAppointments handles GET /api/v1/appointments - the current customer's or technician's calendar of booked fittings
+// The optional from and to query parameters limit the range, which defaults to the next 30 days
+func ListAppointments(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	window, ok := parseCalendarRange(c)
+	if !ok {
+		return
+	}
+
+	appointments, err := services.GetAppointmentService().ListAppointments(user, window)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, appointments)
+}
+
+// CancelAppointment handles DELETE /api/v1/appointments/:id - cancels a booked fitting, freeing its slot
+func CancelAppointment(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	appointment, err := services.GetAppointmentService().CancelAppointment(user, c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, appointment)
+}
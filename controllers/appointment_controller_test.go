@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppointments(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	otherCustomer := models.User{Auth0ID: "auth0|other", Name: "Other Customer", Email: "other@example.com", Role: "customer"}
+	db.Create(&otherCustomer)
+	technician := models.User{Auth0ID: "auth0|tech", Name: "Technician User", Email: "tech@example.com", Role: "technician"}
+	db.Create(&technician)
+	order := models.Order{Description: "Chrome coffin", Quantity: 1, Status: "accepted", CustomerID: customer.ID, TechnicianID: &technician.ID}
+	db.Create(&order)
+	otherOrder := models.Order{Description: "Pastel florals", Quantity: 1, Status: "in_production", CustomerID: otherCustomer.ID, TechnicianID: &technician.ID}
+	db.Create(&otherOrder)
+
+	router := setupTestRouter()
+	me := mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token")
+	tech := mockAuthMiddleware(technician.Auth0ID, "technician", "mock-token")
+	router.POST("/appointment-slots", tech, CreateAppointmentSlot)
+	router.GET("/appointment-slots", tech, ListAppointmentSlots)
+	router.DELETE("/appointment-slots/:id", tech, DeleteAppointmentSlot)
+	router.GET("/tech/appointments", tech, ListAppointments)
+	router.GET("/customer/appointment-slots", me, ListAppointmentSlots)
+	router.POST("/orders/:id/appointments", me, BookAppointment)
+	router.GET("/orders/:id/appointments", me, ListOrderAppointments)
+	router.GET("/appointments", me, ListAppointments)
+	router.DELETE("/appointments/:id", me, CancelAppointment)
+	router.POST("/other/orders/:id/appointments", mockAuthMiddleware(otherCustomer.Auth0ID, "customer", "mock-token"), BookAppointment)
+
+	request := func(method, path string, body interface{}) (int, map[string]interface{}) {
+		var buf bytes.Buffer
+		if body != nil {
+			json.NewEncoder(&buf).Encode(body)
+		}
+		req, _ := http.NewRequest(method, path, &buf)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response
+	}
+	errorCode := func(response map[string]interface{}) interface{} {
+		return response["error"].(map[string]interface{})["code"]
+	}
+
+	// The technician publishes two slots; an overlapping one is refused
+	start := time.Now().UTC().Add(48 * time.Hour).Truncate(time.Hour)
+	code, response := request(http.MethodPost, "/appointment-slots", map[string]interface{}{"starts_at": start, "ends_at": start.Add(time.Hour)})
+	assert.Equal(t, http.StatusCreated, code)
+	slot := response["data"].(map[string]interface{})
+	code, response = request(http.MethodPost, "/appointment-slots", map[string]interface{}{"starts_at": start.Add(time.Hour), "ends_at": start.Add(2 * time.Hour)})
+	assert.Equal(t, http.StatusCreated, code)
+	spare := response["data"].(map[string]interface{})
+	code, response = request(http.MethodPost, "/appointment-slots", map[string]interface{}{"starts_at": start.Add(30 * time.Minute), "ends_at": start.Add(90 * time.Minute)})
+	assert.Equal(t, http.StatusConflict, code)
+	assert.Equal(t, "SLOT_CONFLICT", errorCode(response))
+	code, _ = request(http.MethodPost, "/appointment-slots", map[string]interface{}{"starts_at": start})
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	// The customer books a fitting for their order
+	code, response = request(http.MethodGet, fmt.Sprintf("/customer/appointment-slots?technician_id=%d", technician.ID), nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, response["data"], 2)
+	code, response = request(http.MethodPost, fmt.Sprintf("/orders/%d/appointments", order.ID), map[string]interface{}{"slot_id": slot["id"]})
+	assert.Equal(t, http.StatusCreated, code)
+	appointment := response["data"].(map[string]interface{})
+	assert.Equal(t, "booked", appointment["status"])
+
+	code, response = request(http.MethodPost, fmt.Sprintf("/other/orders/%d/appointments", otherOrder.ID), map[string]interface{}{"slot_id": slot["id"]})
+	assert.Equal(t, http.StatusConflict, code)
+	assert.Equal(t, "SLOT_TAKEN", errorCode(response))
+
+	// Booked slots are hidden from customers and shown with their booking to the technician
+	code, response = request(http.MethodGet, fmt.Sprintf("/customer/appointment-slots?technician_id=%d", technician.ID), nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, response["data"], 1)
+	code, response = request(http.MethodGet, "/appointment-slots", nil)
+	assert.Equal(t, http.StatusOK, code)
+	slots := response["data"].([]interface{})
+	assert.Len(t, slots, 2)
+	assert.Equal(t, appointment["id"], slots[0].(map[string]interface{})["appointment"].(map[string]interface{})["id"])
+	code, response = request(http.MethodDelete, fmt.Sprintf("/appointment-slots/%v", slot["id"]), nil)
+	assert.Equal(t, http.StatusConflict, code)
+
+	// Both calendars list the fitting
+	for _, path := range []string{"/appointments", "/tech/appointments"} {
+		code, response = request(http.MethodGet, path, nil)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Len(t, response["data"], 1)
+	}
+	code, _ = request(http.MethodGet, "/appointments?from=tomorrow", nil)
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, response = request(http.MethodGet, "/appointments?from="+start.Add(time.Hour).Format(time.RFC3339), nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Empty(t, response["data"])
+
+	// Cancelling frees the slot and keeps the history on the order
+	code, response = request(http.MethodDelete, fmt.Sprintf("/appointments/%v", appointment["id"]), nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "cancelled", response["data"].(map[string]interface{})["status"])
+	code, response = request(http.MethodGet, fmt.Sprintf("/orders/%d/appointments", order.ID), nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, response["data"], 1)
+	code, _ = request(http.MethodPost, fmt.Sprintf("/other/orders/%d/appointments", otherOrder.ID), map[string]interface{}{"slot_id": slot["id"]})
+	assert.Equal(t, http.StatusCreated, code)
+
+	code, _ = request(http.MethodDelete, fmt.Sprintf("/appointment-slots/%v", spare["id"]), nil)
+	assert.Equal(t, http.StatusOK, code)
+}
@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/apiresponse"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+)
+
+// AvailabilityRequest represents the request body for updating a technician's capacity and calendar
+type AvailabilityRequest struct {
+	MaxConcurrentOrders *int              `json:"max_concurrent_orders" binding:"required,gte=0"` // 0 means no limit
+	WorkingDays         []string          `json:"working_days" binding:"required"`
+	Vacations           []VacationRequest `json:"vacations"`
+}
+
+// VacationRequest is a range of days off, both ends included
+type VacationRequest struct {
+	Start string `json:"start" binding:"required"` // YYYY-MM-DD
+	End   string `json:"end" binding:"required"`   // YYYY-MM-DD
+}
+
+// GetMyAvailability handles GET /api/v1/users/me/availability - returns the current technician's capacity and calendar
+func GetMyAvailability(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	settings, err := services.GetAvailabilityService().GetAvailability(user)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, settings)
+}
+
+// UpdateMyAvailability handles PUT /api/v1/users/me/availability - replaces the current technician's capacity and calendar
+func UpdateMyAvailability(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req AvailabilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	input := services.AvailabilityInput{
+		MaxConcurrentOrders: *req.MaxConcurrentOrders,
+		WorkingDays:         req.WorkingDays,
+	}
+	for _, vacation := range req.Vacations {
+		start, startErr := time.Parse(time.DateOnly, vacation.Start)
+		end, endErr := time.Parse(time.DateOnly, vacation.End)
+		if startErr != nil || endErr != nil {
+			apierror.Respond(c, apierror.Validation("Invalid vacation dates", map[string]string{
+				"vacations": "start and end must be dates such as 2026-01-31",
+			}))
+			return
+		}
+		input.Vacations = append(input.Vacations, models.Vacation{Start: start, End: end})
+	}
+
+	settings, err := services.GetAvailabilityService().UpdateAvailability(user, input)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, settings)
+}
+
+// ListAvailableTechnicians handles GET /api/v1/technicians - lists the technicians who can take an order today
+func ListAvailableTechnicians(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	technicians, err := services.GetAvailabilityService().ListAvailableTechnicians(user)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, technicians)
+}
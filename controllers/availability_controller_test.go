@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAvailability(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	technician := models.User{Auth0ID: "auth0|tech", Name: "Technician User", Email: "tech@example.com", Role: "technician"}
+	db.Create(&technician)
+	busy := models.Order{Description: "Chrome coffin", Quantity: 1, Status: "in_production", CustomerID: customer.ID, TechnicianID: &technician.ID}
+	db.Create(&busy)
+	waiting := models.Order{Description: "French tips", Quantity: 1, Status: "submitted", CustomerID: customer.ID}
+	db.Create(&waiting)
+
+	router := setupTestRouter()
+	tech := mockAuthMiddleware(technician.Auth0ID, "technician", "mock-token")
+	router.GET("/users/me/availability", tech, GetMyAvailability)
+	router.PUT("/users/me/availability", tech, UpdateMyAvailability)
+	router.PUT("/orders/:id/assign", tech, AssignOrder)
+	router.GET("/technicians", mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token"), ListAvailableTechnicians)
+	router.PUT("/customer/availability", mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token"), UpdateMyAvailability)
+
+	request := func(method, path string, body interface{}) (int, map[string]interface{}) {
+		var buf bytes.Buffer
+		if body != nil {
+			json.NewEncoder(&buf).Encode(body)
+		}
+		req, _ := http.NewRequest(method, path, &buf)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response
+	}
+
+	code, response := request(http.MethodGet, "/users/me/availability", nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, response["data"].(map[string]interface{})["working_days"], 7)
+
+	code, response = request(http.MethodGet, "/technicians", nil)
+	assert.Equal(t, http.StatusOK, code)
+	if assert.Len(t, response["data"], 1) {
+		listed := response["data"].([]interface{})[0].(map[string]interface{})
+		assert.Equal(t, "Technician User", listed["name"])
+		assert.Nil(t, listed["email"])
+	}
+
+	everyDay := []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"}
+	code, _ = request(http.MethodPut, "/customer/availability", map[string]interface{}{"max_concurrent_orders": 1, "working_days": everyDay})
+	assert.Equal(t, http.StatusForbidden, code)
+	code, _ = request(http.MethodPut, "/users/me/availability", map[string]interface{}{"max_concurrent_orders": 1, "working_days": everyDay, "vacations": []map[string]string{{"start": "July 1", "end": "2026-07-02"}}})
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, response = request(http.MethodPut, "/users/me/availability", map[string]interface{}{"max_concurrent_orders": 1, "working_days": everyDay, "vacations": []map[string]string{{"start": "2026-07-01", "end": "2026-07-02"}}})
+	assert.Equal(t, http.StatusOK, code)
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, float64(1), data["max_concurrent_orders"])
+	assert.Len(t, data["vacations"], 1)
+
+	// At capacity the technician can no longer claim orders and drops out of the picker
+	code, response = request(http.MethodPut, fmt.Sprintf("/orders/%d/assign", waiting.ID), nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+	assert.Equal(t, "TECHNICIAN_AT_CAPACITY", response["error"].(map[string]interface{})["code"])
+
+	code, response = request(http.MethodGet, "/technicians", nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, response["data"], 0)
+}
@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/apiresponse"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+)
+
+// ListBackfills handles GET /api/v1/admin/backfills - lists data backfills with their latest runs (admins only)
+func ListBackfills(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	backfills, err := services.GetBackfillService().ListBackfills(user)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, backfills)
+}
+
+// GetBackfill handles GET /api/v1/admin/backfills/:name - shows a backfill's latest run and progress (admins only)
+func GetBackfill(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	backfill, err := services.GetBackfillService().GetBackfill(user, c.Param("name"))
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, backfill)
+}
+
+// RunBackfill handles POST /api/v1/admin/backfills/:name/run - starts a backfill or resumes its failed run (admins only)
+// The run proceeds in the background; poll GET /admin/backfills/:name for progress
+func RunBackfill(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	run, err := services.GetBackfillService().RunBackfill(user, c.Param("name"))
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.JSON(c, http.StatusAccepted, run)
+}
@@ -0,0 +1,172 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+	"github.com/stretchr/testify/assert"
+)
+
+// flakyRoleDirectory records roles and fails for one identity while failFor is set
+type flakyRoleDirectory struct {
+	roles   map[string]string
+	failFor string
+}
+
+func (d *flakyRoleDirectory) GetRole(auth0ID string) (string, error) {
+	return d.roles[auth0ID], nil
+}
+
+func (d *flakyRoleDirectory) SetRole(auth0ID string, role string) error {
+	if auth0ID == d.failFor {
+		return errors.New("directory unavailable")
+	}
+	d.roles[auth0ID] = role
+	return nil
+}
+
+func TestBackfills(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	admin := models.User{Auth0ID: "auth0|admin", Name: "Admin User", Email: "admin@example.com", Role: "admin"}
+	db.Create(&admin)
+	technician := models.User{Auth0ID: "auth0|tech", Name: "Technician User", Email: "tech@example.com", Role: "technician"}
+	db.Create(&technician)
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+
+	// Rows written before normalization and before the price migration dual-wrote cents
+	db.Model(&models.User{}).Where("id = ?", customer.ID).UpdateColumn("email", " Customer@Example.com")
+	price := 42.5
+	order := models.Order{Description: "Priced", Quantity: 1, Status: "accepted", Price: &price, CustomerID: customer.ID}
+	db.Create(&order)
+	db.Model(&models.Order{}).Where("id = ?", order.ID).UpdateColumn("price_cents", nil)
+
+	router := setupTestRouter()
+	router.GET("/admin/backfills", mockAuthMiddleware(admin.Auth0ID, "admin", "mock-token"), ListBackfills)
+	router.GET("/admin/backfills/:name", mockAuthMiddleware(admin.Auth0ID, "admin", "mock-token"), GetBackfill)
+	router.POST("/admin/backfills/:name/run", mockAuthMiddleware(admin.Auth0ID, "admin", "mock-token"), RunBackfill)
+	router.POST("/tech/admin/backfills/:name/run", mockAuthMiddleware(technician.Auth0ID, "technician", "mock-token"), RunBackfill)
+
+	request := func(method, path string) (int, map[string]interface{}) {
+		req, _ := http.NewRequest(method, path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response
+	}
+	advance := func() {
+		assert.NoError(t, services.GetBackfillService().AdvanceRuns(context.Background(), time.Minute))
+	}
+	latestRun := func(name string) map[string]interface{} {
+		code, response := request(http.MethodGet, "/admin/backfills/"+name)
+		assert.Equal(t, http.StatusOK, code)
+		run, _ := response["data"].(map[string]interface{})["latest_run"].(map[string]interface{})
+		return run
+	}
+
+	t.Run("List", func(t *testing.T) {
+		code, response := request(http.MethodGet, "/admin/backfills")
+		assert.Equal(t, http.StatusOK, code)
+		backfills := response["data"].([]interface{})
+		assert.Len(t, backfills, len(services.Backfills))
+		first := backfills[0].(map[string]interface{})
+		assert.Equal(t, "order-price-cents", first["name"])
+		assert.Nil(t, first["latest_run"])
+	})
+
+	t.Run("Price cents", func(t *testing.T) {
+		code, response := request(http.MethodPost, "/admin/backfills/order-price-cents/run")
+		assert.Equal(t, http.StatusAccepted, code)
+		run := response["data"].(map[string]interface{})
+		assert.Equal(t, "running", run["status"])
+		assert.Equal(t, float64(1), run["total"])
+
+		// A second run cannot start while the first is in progress
+		code, response = request(http.MethodPost, "/admin/backfills/order-price-cents/run")
+		assert.Equal(t, http.StatusConflict, code)
+		assert.Equal(t, "BACKFILL_RUNNING", response["error"].(map[string]interface{})["code"])
+
+		advance()
+		run = latestRun("order-price-cents")
+		assert.Equal(t, "completed", run["status"])
+		assert.Equal(t, float64(1), run["processed"])
+		assert.Equal(t, float64(1), run["updated"])
+		assert.NotNil(t, run["finished_at"])
+
+		var backfilled models.Order
+		db.First(&backfilled, order.ID)
+		assert.Equal(t, int64(4250), *backfilled.PriceCents)
+	})
+
+	t.Run("Normalize emails", func(t *testing.T) {
+		code, _ := request(http.MethodPost, "/admin/backfills/normalize-emails/run")
+		assert.Equal(t, http.StatusAccepted, code)
+		advance()
+
+		run := latestRun("normalize-emails")
+		assert.Equal(t, "completed", run["status"])
+		assert.Equal(t, float64(3), run["processed"])
+		assert.Equal(t, float64(1), run["updated"])
+
+		var normalized models.User
+		db.First(&normalized, customer.ID)
+		assert.Equal(t, "customer@example.com", normalized.Email)
+	})
+
+	t.Run("Sync roles resumes after a failure", func(t *testing.T) {
+		code, response := request(http.MethodPost, "/admin/backfills/sync-roles/run")
+		assert.Equal(t, http.StatusUnprocessableEntity, code)
+		assert.Equal(t, "ROLE_SYNC_DISABLED", response["error"].(map[string]interface{})["code"])
+
+		directory := &flakyRoleDirectory{roles: make(map[string]string), failFor: technician.Auth0ID}
+		services.SetRoleDirectory(directory)
+		defer services.SetRoleDirectory(nil)
+
+		code, _ = request(http.MethodPost, "/admin/backfills/sync-roles/run")
+		assert.Equal(t, http.StatusAccepted, code)
+		advance()
+
+		run := latestRun("sync-roles")
+		assert.Equal(t, "failed", run["status"])
+		assert.Contains(t, run["last_error"], "directory unavailable")
+		runID := run["id"]
+
+		// Running it again resumes the same run
+		directory.failFor = ""
+		code, response = request(http.MethodPost, "/admin/backfills/sync-roles/run")
+		assert.Equal(t, http.StatusAccepted, code)
+		assert.Equal(t, runID, response["data"].(map[string]interface{})["id"])
+		advance()
+
+		run = latestRun("sync-roles")
+		assert.Equal(t, "completed", run["status"])
+		assert.Nil(t, run["last_error"])
+		assert.Equal(t, map[string]string{
+			admin.Auth0ID:      "admin",
+			technician.Auth0ID: "technician",
+			customer.Auth0ID:   "customer",
+		}, directory.roles)
+	})
+
+	t.Run("Unknown backfill", func(t *testing.T) {
+		code, _ := request(http.MethodPost, "/admin/backfills/compute-image-hashes/run")
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+
+	t.Run("Technician forbidden", func(t *testing.T) {
+		code, _ := request(http.MethodPost, "/tech/admin/backfills/order-price-cents/run")
+		assert.Equal(t, http.StatusForbidden, code)
+	})
+}
@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/apiresponse"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+)
+
+// calendarFeedPath is where calendar apps fetch a technician's feed
+const calendarFeedPath = "/api/v1/users/me/calendar.ics"
+
+// calendarFeedURL returns the absolute feed URL for the token, on the host the request was made to
+// Calendar apps subscribe by URL and cannot send a bearer token, so the token goes in the query string
+func calendarFeedURL(c *gin.Context, token string) string {
+	scheme := "https"
+	if forwarded := c.GetHeader("X-Forwarded-Proto"); forwarded != "" {
+		scheme = forwarded
+	} else if c.Request.TLS == nil {
+		scheme = "http"
+	}
+	feed := url.URL{Scheme: scheme, Host: c.Request.Host, Path: calendarFeedPath, RawQuery: url.Values{"token": {token}}.Encode()}
+	return feed.String()
+}
+
+// calendarTokenResponse is the data of an issued calendar feed token
+type calendarTokenResponse struct {
+	Token   string `json:"token"`
+	FeedURL string `json:"feed_url"`
+}
+
+// IssueCalendarToken handles POST /api/v1/users/me/calendar-token - creates the current technician's calendar feed URL
+// Any previous feed URL stops working; the token is only returned in this response
+func IssueCalendarToken(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	token, err := services.GetCalendarService().IssueFeedToken(user)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.Created(c, calendarTokenResponse{Token: token, FeedURL: calendarFeedURL(c, token)})
+}
+
+// RevokeCalendarToken handles DELETE /api/v1/users/me/calendar-token - turns the current technician's calendar feed off
+func RevokeCalendarToken(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := services.GetCalendarService().RevokeFeedToken(user); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.Message(c, http.StatusOK, "Calendar feed turned off")
+}
+
+// GetCalendarFeed handles GET /api/v1/users/me/calendar.ics - the technician's schedule as an iCalendar feed
+// Authenticated by the token query parameter instead of a JWT, so calendar apps can subscribe to it
+func GetCalendarFeed(c *gin.Context) {
+	feed, err := services.GetCalendarService().Feed(c.Query("token"))
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "private, no-cache")
+	c.Header("Content-Disposition", `inline; filename="schedule.ics"`)
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", feed)
+}
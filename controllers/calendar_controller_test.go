@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalendarFeed(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	technician := models.User{Auth0ID: "auth0|tech", Name: "Technician User", Email: "tech@example.com", Role: "technician"}
+	db.Create(&technician)
+	promised := time.Now().UTC().AddDate(0, 0, 7).Truncate(24 * time.Hour)
+	order := models.Order{Description: "Chrome coffin", Quantity: 1, Status: "accepted", CustomerID: customer.ID, TechnicianID: &technician.ID, PromisedBy: &promised}
+	db.Create(&order)
+
+	router := setupTestRouter()
+	tech := mockAuthMiddleware(technician.Auth0ID, "technician", "mock-token")
+	router.POST("/api/v1/users/me/calendar-token", tech, IssueCalendarToken)
+	router.DELETE("/api/v1/users/me/calendar-token", tech, RevokeCalendarToken)
+	router.POST("/customer/calendar-token", mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token"), IssueCalendarToken)
+	router.GET("/api/v1/users/me/calendar.ics", GetCalendarFeed)
+
+	request := func(method, path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request(http.MethodPost, "/customer/calendar-token")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = request(http.MethodGet, "/api/v1/users/me/calendar.ics?token=nope")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = request(http.MethodPost, "/api/v1/users/me/calendar-token")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	feedURL, err := url.Parse(response["data"].(map[string]interface{})["feed_url"].(string))
+	assert.NoError(t, err)
+	assert.Equal(t, "/api/v1/users/me/calendar.ics", feedURL.Path)
+	assert.Equal(t, response["data"].(map[string]interface{})["token"], feedURL.Query().Get("token"))
+
+	// The feed needs no JWT, only the token in its URL
+	w = request(http.MethodGet, feedURL.RequestURI())
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/calendar; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.True(t, strings.HasPrefix(w.Body.String(), "BEGIN:VCALENDAR\r\n"))
+	assert.Contains(t, w.Body.String(), "DTSTART;VALUE=DATE:"+promised.Format("20060102"))
+
+	w = request(http.MethodDelete, "/api/v1/users/me/calendar-token")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = request(http.MethodGet, feedURL.RequestURI())
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
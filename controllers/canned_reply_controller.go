@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/apiresponse"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+)
+
+// CannedReplyRequest represents the request body for creating or updating a canned reply
+type CannedReplyRequest struct {
+	Title string `json:"title" binding:"required"`
+	Text  string `json:"text" binding:"required"`
+}
+
+// input converts the request into service input
+func (r CannedReplyRequest) input() services.CannedReplyInput {
+	return services.CannedReplyInput{Title: r.Title, Text: r.Text}
+}
+
+// ListMyCannedReplies handles GET /api/v1/users/me/canned-replies - lists the current technician's canned replies
+func ListMyCannedReplies(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	replies, err := services.GetCannedReplyService().ListReplies(user)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, replies)
+}
+
+// CreateMyCannedReply handles POST /api/v1/users/me/canned-replies - saves a message template for the current technician
+// The text can use {customer_name} and {order_id}, filled in when it is sent with ?template_id= on an order's messages
+func CreateMyCannedReply(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req CannedReplyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	reply, err := services.GetCannedReplyService().CreateReply(user, req.input())
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.Created(c, reply)
+}
+
+// UpdateMyCannedReply handles PUT /api/v1/users/me/canned-replies/:id - updates one of the current technician's canned replies
+func UpdateMyCannedReply(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req CannedReplyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	reply, err := services.GetCannedReplyService().UpdateReply(user, c.Param("id"), req.input())
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, reply)
+}
+
+// DeleteMyCannedReply handles DELETE /api/v1/users/me/canned-replies/:id - removes one of the current technician's canned replies
+func DeleteMyCannedReply(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := services.GetCannedReplyService().DeleteReply(user, c.Param("id")); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.Message(c, http.StatusOK, "Canned reply deleted")
+}
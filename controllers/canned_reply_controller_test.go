@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCannedReplies(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Casey Customer", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	technician := models.User{Auth0ID: "auth0|tech", Name: "Technician User", Email: "tech@example.com", Role: "technician"}
+	db.Create(&technician)
+	order := models.Order{Description: "Chrome coffin", Quantity: 1, Status: "accepted", CustomerID: customer.ID, TechnicianID: &technician.ID}
+	db.Create(&order)
+
+	router := setupTestRouter()
+	me := mockAuthMiddleware(technician.Auth0ID, "technician", "mock-token")
+	router.GET("/users/me/canned-replies", me, ListMyCannedReplies)
+	router.POST("/users/me/canned-replies", me, CreateMyCannedReply)
+	router.PUT("/users/me/canned-replies/:id", me, UpdateMyCannedReply)
+	router.DELETE("/users/me/canned-replies/:id", me, DeleteMyCannedReply)
+	router.POST("/orders/:id/messages", me, SendMessage)
+	router.POST("/customer/orders/:id/messages", mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token"), SendMessage)
+
+	request := func(method, path string, body interface{}) (int, map[string]interface{}) {
+		var buf bytes.Buffer
+		if body != nil {
+			json.NewEncoder(&buf).Encode(body)
+		}
+		req, _ := http.NewRequest(method, path, &buf)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response
+	}
+
+	code, _ := request(http.MethodPost, "/users/me/canned-replies", map[string]string{"title": "Typo", "text": "Hi {name}"})
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, response := request(http.MethodPost, "/users/me/canned-replies", map[string]string{"title": "Shipping", "text": "Hi {customer_name}, order #{order_id} ships Friday!"})
+	assert.Equal(t, http.StatusCreated, code)
+	replyID := uint(response["data"].(map[string]interface{})["id"].(float64))
+
+	code, response = request(http.MethodGet, "/users/me/canned-replies", nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, response["data"].([]interface{}), 1)
+
+	// Sending the template fills in the order's details; no body is needed
+	code, response = request(http.MethodPost, fmt.Sprintf("/orders/%d/messages?template_id=%d", order.ID, replyID), nil)
+	assert.Equal(t, http.StatusCreated, code)
+	assert.Equal(t, fmt.Sprintf("Hi Casey Customer, order #%d ships Friday!", order.ID), response["data"].(map[string]interface{})["text"])
+
+	// Customers have no canned replies to send
+	code, _ = request(http.MethodPost, fmt.Sprintf("/customer/orders/%d/messages?template_id=%d", order.ID, replyID), nil)
+	assert.Equal(t, http.StatusForbidden, code)
+	code, _ = request(http.MethodPost, fmt.Sprintf("/orders/%d/messages?template_id=999", order.ID), nil)
+	assert.Equal(t, http.StatusNotFound, code)
+
+	// Editing the template leaves sent messages alone
+	code, _ = request(http.MethodPut, fmt.Sprintf("/users/me/canned-replies/%d", replyID), map[string]string{"title": "Shipping", "text": "Ships Monday"})
+	assert.Equal(t, http.StatusOK, code)
+	var sent models.Message
+	db.Where("order_id = ?", order.ID).First(&sent)
+	assert.Contains(t, sent.Text, "ships Friday")
+
+	code, _ = request(http.MethodDelete, fmt.Sprintf("/users/me/canned-replies/%d", replyID), nil)
+	assert.Equal(t, http.StatusOK, code)
+	code, response = request(http.MethodGet, "/users/me/canned-replies", nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Empty(t, response["data"])
+}
@@ -0,0 +1,189 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/apiresponse"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+)
+
+// CatalogDesignRequest represents the request body for creating or updating a catalog design
+type CatalogDesignRequest struct {
+	Name        string   `json:"name" binding:"required"`
+	Description string   `json:"description" binding:"required"`
+	BasePrice   *float64 `json:"base_price" binding:"required,gt=0"`
+	Sizes       []string `json:"sizes" binding:"required,min=1"`
+}
+
+// input converts the request into service input
+func (r CatalogDesignRequest) input() services.CatalogDesignInput {
+	return services.CatalogDesignInput{
+		Name:        r.Name,
+		Description: r.Description,
+		BasePrice:   *r.BasePrice,
+		Sizes:       r.Sizes,
+	}
+}
+
+// populateCatalogImageURLs generates presigned URLs for the photos of catalog designs
+func populateCatalogImageURLs(designs []models.CatalogDesign) {
+	for i := range designs {
+		populateCatalogDesignImageURLs(designs[i].Images)
+	}
+}
+
+// populateCatalogDesignImageURLs generates presigned URLs for catalog design photos
+func populateCatalogDesignImageURLs(images []models.CatalogDesignImage) {
+	imageService := services.GetImageService()
+	for i := range images {
+		if url, err := imageService.GetImageURL(images[i].ImageS3Key); err == nil {
+			images[i].ImageURL = &url
+		}
+	}
+}
+
+// ListCatalog handles GET /api/v1/catalog - lists the pre-designed nail sets (public)
+func ListCatalog(c *gin.Context) {
+	designs, err := services.GetCatalogService().ListDesigns()
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	populateCatalogImageURLs(designs)
+
+	apiresponse.OK(c, designs)
+}
+
+// GetCatalogDesign handles GET /api/v1/catalog/:id - gets one pre-designed nail set (public)
+func GetCatalogDesign(c *gin.Context) {
+	design, err := services.GetCatalogService().GetDesign(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	populateCatalogDesignImageURLs(design.Images)
+
+	apiresponse.OK(c, design)
+}
+
+// CreateCatalogDesign handles POST /api/v1/admin/catalog - adds a design to the catalog (admins only)
+func CreateCatalogDesign(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req CatalogDesignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	design, err := services.GetCatalogService().CreateDesign(user, req.input())
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.Created(c, design)
+}
+
+// UpdateCatalogDesign handles PUT /api/v1/admin/catalog/:id - updates a catalog design (admins only)
+func UpdateCatalogDesign(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req CatalogDesignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	design, err := services.GetCatalogService().UpdateDesign(user, c.Param("id"), req.input())
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	populateCatalogDesignImageURLs(design.Images)
+
+	apiresponse.OK(c, design)
+}
+
+// DeleteCatalogDesign handles DELETE /api/v1/admin/catalog/:id - retires a catalog design (admins only)
+func DeleteCatalogDesign(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := services.GetCatalogService().DeleteDesign(user, c.Param("id")); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.Message(c, http.StatusOK, "Catalog design deleted")
+}
+
+// AddCatalogDesignImage handles POST /api/v1/admin/catalog/:id/images - adds a photo to a catalog design (admins only)
+// Expects multipart form data with an "image" file
+func AddCatalogDesignImage(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	fileHeader, err := c.FormFile("image")
+	if err != nil {
+		apierror.Respond(c, apierror.Validation("Image is required", nil))
+		return
+	}
+	imageKey, ok := uploadImage(c, fileHeader)
+	if !ok {
+		return
+	}
+
+	image, err := services.GetCatalogService().AddImage(user, c.Param("id"), imageKey)
+	if err != nil {
+		// The photo was never attached, so don't leave it in storage
+		if deleteErr := services.GetImageService().DeleteImage(imageKey); deleteErr != nil {
+			log.Printf("Failed to delete orphaned catalog photo %s: %v", imageKey, deleteErr)
+		}
+		apierror.Respond(c, err)
+		return
+	}
+
+	images := []models.CatalogDesignImage{*image}
+	populateCatalogDesignImageURLs(images)
+
+	apiresponse.Created(c, images[0])
+}
+
+// DeleteCatalogDesignImage handles DELETE /api/v1/admin/catalog/:id/images/:imageId - removes a photo from a catalog design (admins only)
+func DeleteCatalogDesignImage(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	image, err := services.GetCatalogService().DeleteImage(user, c.Param("id"), c.Param("imageId"))
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	// The photo is already gone from the design, so a storage failure only leaves an orphaned file
+	if err := services.GetImageService().DeleteImage(image.ImageS3Key); err != nil {
+		log.Printf("Failed to delete catalog photo %s: %v", image.ImageS3Key, err)
+	}
+
+	apiresponse.Message(c, http.StatusOK, "Catalog image deleted")
+}
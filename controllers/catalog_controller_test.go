@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatalog_AdminManagesAndCustomerOrders(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	previous := services.GetImageService()
+	mockImage := services.NewMockImageService()
+	mockImage.SetAsMockForTesting()
+	defer services.SetImageService(previous)
+
+	admin := models.User{Auth0ID: "auth0|admin", Name: "Admin User", Email: "admin@example.com", Role: "admin"}
+	db.Create(&admin)
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+
+	router := setupTestRouter()
+	adminAuth := mockAuthMiddleware(admin.Auth0ID, "admin", "mock-token")
+	customerAuth := mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token")
+	router.GET("/catalog", ListCatalog)
+	router.GET("/catalog/:id", GetCatalogDesign)
+	router.POST("/admin/catalog", adminAuth, CreateCatalogDesign)
+	router.PUT("/admin/catalog/:id", adminAuth, UpdateCatalogDesign)
+	router.DELETE("/admin/catalog/:id", adminAuth, DeleteCatalogDesign)
+	router.POST("/admin/catalog/:id/images", adminAuth, AddCatalogDesignImage)
+	router.POST("/customer/admin/catalog", customerAuth, CreateCatalogDesign)
+	router.POST("/orders", customerAuth, CreateOrder)
+
+	sendJSON := func(method, path string, payload interface{}) (*httptest.ResponseRecorder, map[string]interface{}) {
+		body, _ := json.Marshal(payload)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w, response
+	}
+
+	design := map[string]interface{}{
+		"name":        "Chrome coffin",
+		"description": "Mirror chrome on long coffin tips",
+		"base_price":  55.0,
+		"sizes":       []string{"S", "M", "L"},
+	}
+
+	// Only admins manage the catalog
+	w, _ := sendJSON(http.MethodPost, "/customer/admin/catalog", design)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w, response := sendJSON(http.MethodPost, "/admin/catalog", design)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	designID := uint(response["data"].(map[string]interface{})["id"].(float64))
+
+	w, _ = sendJSON(http.MethodPost, "/admin/catalog", map[string]interface{}{"name": "No sizes", "description": "Set", "base_price": 10.0})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Photos are uploaded separately
+	body, contentType := newProgressUpdateForm(t, "chrome.png", nil)
+	req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/admin/catalog/%d/images", designID), body)
+	req.Header.Set("Content-Type", contentType)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	// The catalog is public
+	req, _ = http.NewRequest(http.MethodGet, "/catalog", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	designs := response["data"].([]interface{})
+	if assert.Len(t, designs, 1) {
+		listed := designs[0].(map[string]interface{})
+		assert.Equal(t, []interface{}{"S", "M", "L"}, listed["sizes"])
+		images := listed["images"].([]interface{})
+		if assert.Len(t, images, 1) {
+			assert.Contains(t, images[0].(map[string]interface{})["image_url"], "mock_chrome.png")
+		}
+	}
+
+	// Ordering a design pre-fills the description and price
+	w, response = sendJSON(http.MethodPost, "/orders", map[string]interface{}{"quantity": 1, "catalog_design_id": designID})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	order := response["data"].(map[string]interface{})
+	assert.Equal(t, "Mirror chrome on long coffin tips", order["description"])
+	assert.Equal(t, 55.0, order["price"])
+	assert.Equal(t, float64(designID), order["catalog_design_id"])
+
+	w, _ = sendJSON(http.MethodPost, "/orders", map[string]interface{}{"quantity": 1})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Retired designs leave the catalog and can no longer be ordered
+	w, _ = sendJSON(http.MethodDelete, fmt.Sprintf("/admin/catalog/%d", designID), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req, _ = http.NewRequest(http.MethodGet, fmt.Sprintf("/catalog/%d", designID), nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w, response = sendJSON(http.MethodPost, "/orders", map[string]interface{}{"quantity": 1, "catalog_design_id": designID})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "INVALID_CATALOG_DESIGN", response["error"].(map[string]interface{})["code"])
+}
@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/apiresponse"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+)
+
+// UpdateChecklistTemplateRequest represents the request body for replacing a checklist template
+type UpdateChecklistTemplateRequest struct {
+	Items []string `json:"items" binding:"required"`
+}
+
+// UpdateChecklistItemRequest represents the request body for checking off a checklist item
+type UpdateChecklistItemRequest struct {
+	Completed *bool `json:"completed" binding:"required"`
+}
+
+// GetChecklistTemplate handles GET /api/v1/checklist/template - returns the technician's checklist template
+func GetChecklistTemplate(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	items, err := services.GetChecklistService().GetTemplate(user)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, items)
+}
+
+// UpdateChecklistTemplate handles PUT /api/v1/checklist/template - replaces the technician's checklist template
+func UpdateChecklistTemplate(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateChecklistTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	items, err := services.GetChecklistService().ReplaceTemplate(user, req.Items)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, items)
+}
+
+// UpdateChecklistItem handles PUT /api/v1/orders/:id/checklist/:itemId - checks or unchecks a checklist item (assigned technician only)
+func UpdateChecklistItem(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateChecklistItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	order, err := services.GetChecklistService().SetItemCompleted(user, c.Param("id"), c.Param("itemId"), *req.Completed)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	// Generate image URL
+	populateOrderImageURL(order)
+
+	apiresponse.OK(c, order)
+}
@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecklist_ProductionFlow(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	technician := models.User{Auth0ID: "auth0|tech", Name: "Technician User", Email: "tech@example.com", Role: "technician"}
+	db.Create(&technician)
+
+	order := models.Order{Description: "Checklist order", Quantity: 1, Status: "accepted", CustomerID: customer.ID, TechnicianID: &technician.ID}
+	db.Create(&order)
+
+	router := setupTestRouter()
+	techAuth := mockAuthMiddleware(technician.Auth0ID, "technician", "mock-token")
+	router.PUT("/checklist/template", techAuth, UpdateChecklistTemplate)
+	router.PUT("/orders/:id/status", techAuth, UpdateOrderStatus)
+	router.PUT("/orders/:id/checklist/:itemId", techAuth, UpdateChecklistItem)
+	router.GET("/customer/orders/:id", mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token"), GetOrder)
+
+	send := func(method, path string, body interface{}) (*httptest.ResponseRecorder, map[string]interface{}) {
+		var buf bytes.Buffer
+		if body != nil {
+			_ = json.NewEncoder(&buf).Encode(body)
+		}
+		req, _ := http.NewRequest(method, path, &buf)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", "*")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w, response
+	}
+
+	// Define the template and start production
+	w, _ := send(http.MethodPut, "/checklist/template", map[string]interface{}{"items": []string{"Prep", "Paint"}})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w, response := send(http.MethodPut, fmt.Sprintf("/orders/%d/status", order.ID), map[string]string{"status": "in_production"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	data := response["data"].(map[string]interface{})
+	checklist := data["checklist"].([]interface{})
+	assert.Len(t, checklist, 2)
+	assert.Equal(t, float64(0), data["checklist_progress"])
+
+	// Shipping is blocked while items are unchecked
+	w, response = send(http.MethodPut, fmt.Sprintf("/orders/%d/status", order.ID), map[string]string{"status": "shipped"})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "CHECKLIST_INCOMPLETE", response["error"].(map[string]interface{})["code"])
+
+	// Check the first item; the customer sees progress
+	firstID := uint(checklist[0].(map[string]interface{})["id"].(float64))
+	w, _ = send(http.MethodPut, fmt.Sprintf("/orders/%d/checklist/%d", order.ID, firstID), map[string]bool{"completed": true})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w, response = send(http.MethodGet, fmt.Sprintf("/customer/orders/%d", order.ID), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, float64(50), response["data"].(map[string]interface{})["checklist_progress"])
+
+	// Check the rest and ship
+	secondID := uint(checklist[1].(map[string]interface{})["id"].(float64))
+	w, _ = send(http.MethodPut, fmt.Sprintf("/orders/%d/checklist/%d", order.ID, secondID), map[string]bool{"completed": true})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w, response = send(http.MethodPut, fmt.Sprintf("/orders/%d/status", order.ID), map[string]string{"status": "shipped"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, float64(100), response["data"].(map[string]interface{})["checklist_progress"])
+}
+
+func TestUpdateChecklistItem_MissingCompleted_Fails(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	technician := models.User{Auth0ID: "auth0|tech", Name: "Technician User", Email: "tech@example.com", Role: "technician"}
+	db.Create(&technician)
+
+	router := setupTestRouter()
+	router.PUT("/orders/:id/checklist/:itemId", mockAuthMiddleware(technician.Auth0ID, "technician", "mock-token"), UpdateChecklistItem)
+
+	req, _ := http.NewRequest(http.MethodPut, "/orders/1/checklist/1", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/apiresponse"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+)
+
+// AcceptTermsRequest represents the request body for accepting the terms of service
+type AcceptTermsRequest struct {
+	TermsVersion string `json:"terms_version" binding:"required"`
+}
+
+// GetTerms handles GET /api/v1/terms - returns the current terms of service version
+// Public, so clients can show the terms before the visitor signs up
+func GetTerms(c *gin.Context) {
+	terms, err := services.GetConsentService().CurrentTerms()
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, terms)
+}
+
+// AcceptTerms handles POST /api/v1/users/me/consents - records the caller's acceptance of the current terms of service
+// Accepting the same version again returns the original acceptance with 200
+func AcceptTerms(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req AcceptTermsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	consent, created, err := services.GetConsentService().AcceptTerms(user, services.ConsentInput{
+		TermsVersion: req.TermsVersion,
+		IPAddress:    c.ClientIP(),
+		UserAgent:    c.Request.UserAgent(),
+	})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	apiresponse.JSON(c, status, consent)
+}
+
+// GetMyConsents handles GET /api/v1/users/me/consents - lists the caller's terms acceptances and whether they must accept the current terms
+func GetMyConsents(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	status, err := services.GetConsentService().GetConsentStatus(user)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, status)
+}
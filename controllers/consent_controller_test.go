@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTermsConsent(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+	originalConfig := config.GetConfig()
+	defer config.SetConfig(originalConfig)
+	config.SetConfig(&config.Config{TermsVersion: "2026-05-01", TermsURL: "https://example.com/terms"})
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+
+	router := setupTestRouter()
+	auth := mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token")
+	router.GET("/terms", GetTerms)
+	router.GET("/users/me/consents", auth, GetMyConsents)
+	router.POST("/users/me/consents", auth, AcceptTerms)
+	router.POST("/orders", auth, CreateOrder)
+
+	send := func(method, path string, body interface{}) (int, map[string]interface{}) {
+		payload, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "nails-app/1.0")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response
+	}
+	order := map[string]interface{}{"description": "Pink", "quantity": 1}
+
+	code, response := send(http.MethodGet, "/terms", nil)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "2026-05-01", response["data"].(map[string]interface{})["version"])
+	assert.Equal(t, "https://example.com/terms", response["data"].(map[string]interface{})["url"])
+
+	code, response = send(http.MethodPost, "/orders", order)
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.Equal(t, "TERMS_ACCEPTANCE_REQUIRED", response["error"].(map[string]interface{})["code"])
+
+	code, _ = send(http.MethodPost, "/users/me/consents", map[string]interface{}{"terms_version": "2026-05-01"})
+	require.Equal(t, http.StatusCreated, code)
+	code, _ = send(http.MethodPost, "/users/me/consents", map[string]interface{}{"terms_version": "2026-05-01"})
+	assert.Equal(t, http.StatusOK, code)
+
+	code, response = send(http.MethodGet, "/users/me/consents", nil)
+	require.Equal(t, http.StatusOK, code)
+	status := response["data"].(map[string]interface{})
+	assert.Equal(t, false, status["acceptance_required"])
+	consents := status["consents"].([]interface{})
+	require.Len(t, consents, 1)
+	assert.Equal(t, "nails-app/1.0", consents[0].(map[string]interface{})["user_agent"])
+
+	code, _ = send(http.MethodPost, "/orders", order)
+	assert.Equal(t, http.StatusCreated, code)
+}
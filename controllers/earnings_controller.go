@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/apiresponse"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+)
+
+// GetMyEarnings handles GET /api/v1/users/me/earnings - the technician's delivered and pending revenue (technician only)
+// The optional period query parameter groups delivered revenue by week (default) or month; from and to are
+// inclusive dates (YYYY-MM-DD) and default to the last 12 periods
+func GetMyEarnings(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	query := services.EarningsQuery{Period: c.Query("period")}
+	details := make(map[string]string)
+	if fromStr := c.Query("from"); fromStr != "" {
+		parsed, err := time.Parse(time.DateOnly, fromStr)
+		if err != nil {
+			details["from"] = "must be a date (YYYY-MM-DD)"
+		}
+		query.From = parsed
+	}
+	if toStr := c.Query("to"); toStr != "" {
+		parsed, err := time.Parse(time.DateOnly, toStr)
+		if err != nil {
+			details["to"] = "must be a date (YYYY-MM-DD)"
+		}
+		query.To = parsed.AddDate(0, 0, 1) // include the whole last day
+	}
+	if len(details) > 0 {
+		apierror.Respond(c, apierror.Validation("Invalid request data", details))
+		return
+	}
+
+	earnings, err := services.GetEarningsService().Earnings(user, query)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, earnings)
+}
@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetMyEarnings(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	technician := models.User{Auth0ID: "auth0|tech", Name: "Technician User", Email: "tech@example.com", Role: "technician"}
+	db.Create(&technician)
+
+	price := func(amount float64) *float64 { return &amount }
+	yesterday := time.Now().UTC().AddDate(0, 0, -1)
+	lastYear := time.Now().UTC().AddDate(-1, 0, -1)
+	delivered := models.Order{Description: "Chrome coffin", Quantity: 1, Status: "delivered", Currency: "USD", Price: price(45), CustomerID: customer.ID, TechnicianID: &technician.ID, DeliveredAt: &yesterday}
+	db.Create(&delivered)
+	db.Create(&models.OrderLineItem{OrderID: delivered.ID, Kind: models.LineItemBase, Description: "Chrome coffin", Quantity: 1, UnitPrice: 45, Amount: 45})
+	old := models.Order{Description: "French tips", Quantity: 1, Status: "delivered", Currency: "USD", Price: price(30), CustomerID: customer.ID, TechnicianID: &technician.ID, DeliveredAt: &lastYear}
+	db.Create(&old)
+	inProduction := models.Order{Description: "Glitter ombre", Quantity: 1, Status: "in_production", Currency: "USD", Price: price(25.50), CustomerID: customer.ID, TechnicianID: &technician.ID}
+	db.Create(&inProduction)
+
+	router := setupTestRouter()
+	router.GET("/users/me/earnings", mockAuthMiddleware(technician.Auth0ID, "technician", "mock-token"), GetMyEarnings)
+	router.GET("/customer/earnings", mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token"), GetMyEarnings)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/users/me/earnings?period=month")
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data services.Earnings `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	earnings := response.Data
+
+	assert.Equal(t, "month", earnings.Period)
+	require.Len(t, earnings.Completed, 1)
+	assert.Equal(t, 1, earnings.Completed[0].Orders)
+	assert.Equal(t, 45.0, earnings.Completed[0].Amount)
+	assert.Equal(t, []services.EarningsTotal{{Currency: "USD", Orders: 1, Amount: 25.50}}, earnings.Pending)
+	require.Len(t, earnings.Orders, 2)
+	assert.Equal(t, inProduction.ID, earnings.Orders[0].OrderID)
+	assert.Equal(t, delivered.ID, earnings.Orders[1].OrderID)
+	assert.Len(t, earnings.Orders[1].LineItems, 1)
+
+	// The date range selects which deliveries count
+	w = get("/users/me/earnings?from=" + lastYear.Format(time.DateOnly) + "&to=" + lastYear.Format(time.DateOnly))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Data.Completed, 1)
+	assert.Equal(t, 30.0, response.Data.Completed[0].Amount)
+
+	assert.Equal(t, http.StatusBadRequest, get("/users/me/earnings?from=last-week").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/users/me/earnings?period=day").Code)
+	assert.Equal(t, http.StatusForbidden, get("/customer/earnings").Code)
+}
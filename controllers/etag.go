@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+)
+
+// imageURLRefreshInterval is how often the ETag of responses that carry signed image URLs rotates
+// It is half of IMAGE_URL_EXPIRY_MINUTES, so a revalidated response never holds an expired link
+func imageURLRefreshInterval() time.Duration {
+	expiry := config.DefaultImageURLExpiry
+	if cfg := config.GetConfig(); cfg != nil {
+		expiry = cfg.GetImageURLExpiry()
+	}
+	return expiry / 2
+}
+
+// etagClock is the clock used for image URL refresh windows (replaced in tests)
+var etagClock = time.Now
+
+// notModified sets an ETag for a JSON body and reports whether the client's copy is current
+// When it is, a 304 response has been written and the handler should return
+// The body must be hashed before image URLs are populated, since signed URLs differ on every request
+func notModified(c *gin.Context, body interface{}, hasImages bool) bool {
+	hash, ok := representationHash(body, hasImages)
+	if !ok {
+		// Serialization problems surface when the full response is written
+		return false
+	}
+	return revalidate(c, `"`+hash+`"`)
+}
+
+// orderNotModified sets the ETag of a single order, see orderETag, and reports whether the client's copy is current
+// It is notModified for responses that carry one order
+func orderNotModified(c *gin.Context, order *models.Order, body interface{}) bool {
+	return revalidate(c, orderETag(order, body))
+}
+
+// orderETag returns the ETag of a single order representation
+// It starts with the order's version, which If-Match on order writes compares, and ends with a hash of the
+// representation, which If-None-Match revalidation compares
+func orderETag(order *models.Order, body interface{}) string {
+	version := strconv.FormatUint(uint64(order.Version), 10)
+	hash, ok := representationHash(body, ordersHaveImages(*order))
+	if !ok {
+		return `"` + version + `"`
+	}
+	return `"` + version + "-" + hash + `"`
+}
+
+// representationHash hashes a JSON body, including the current image URL refresh window when it carries images
+func representationHash(body interface{}, hasImages bool) (string, bool) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return "", false
+	}
+
+	hash := sha256.New()
+	hash.Write(payload)
+	if hasImages {
+		window := etagClock().Unix() / int64(imageURLRefreshInterval()/time.Second)
+		hash.Write([]byte(strconv.FormatInt(window, 10)))
+	}
+	return hex.EncodeToString(hash.Sum(nil)[:16]), true
+}
+
+// revalidate sets the ETag and reports whether it matches the client's If-None-Match
+// When it does, a 304 response has been written
+func revalidate(c *gin.Context, etag string) bool {
+	// Responses are per user, and clients must revalidate before reusing them
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "private, no-cache")
+
+	if !etagMatches(c.GetHeader("If-None-Match"), etag) {
+		return false
+	}
+	c.Status(http.StatusNotModified)
+	c.Writer.WriteHeaderNow()
+	return true
+}
+
+// ifMatchVersion reads the order version from the If-Match header that changes to an order require,
+// so that a technician working from a stale copy cannot silently overwrite someone else's change
+// The header holds an ETag of the order, or just its version such as "3"; * matches any version and is read as 0
+// When the header is missing or holds no order version, an error response has been written and ok is false
+func ifMatchVersion(c *gin.Context) (version uint, ok bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		apierror.Respond(c, apierror.New(http.StatusPreconditionRequired, "PRECONDITION_REQUIRED",
+			"Send the order's ETag in the If-Match header"))
+		return 0, false
+	}
+	if header == "*" {
+		return 0, true
+	}
+
+	// Weak tags never match If-Match, and an order has a single current version to compare
+	tag := strings.TrimSuffix(strings.TrimPrefix(header, `"`), `"`)
+	if len(tag) != len(header)-2 {
+		tag = ""
+	}
+	versionPart, _, _ := strings.Cut(tag, "-")
+	parsed, err := strconv.ParseUint(versionPart, 10, 32)
+	if err != nil || parsed == 0 {
+		apierror.Respond(c, apierror.BadRequest("INVALID_IF_MATCH", "If-Match must be a single ETag of the order"))
+		return 0, false
+	}
+	return uint(parsed), true
+}
+
+// etagMatches reports whether an If-None-Match header matches the ETag
+// Comparison is weak, as RFC 9110 requires for If-None-Match
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// ordersHaveImages reports whether any order will carry a signed image URL
+func ordersHaveImages(orders ...models.Order) bool {
+	for _, order := range orders {
+		if order.ImageS3Key != nil && *order.ImageS3Key != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEtagMatches(t *testing.T) {
+	etag := `"abc123"`
+
+	assert.True(t, etagMatches(`"abc123"`, etag))
+	assert.True(t, etagMatches(`W/"abc123"`, etag))
+	assert.True(t, etagMatches(`"other", "abc123"`, etag))
+	assert.True(t, etagMatches(`*`, etag))
+	assert.False(t, etagMatches(``, etag))
+	assert.False(t, etagMatches(`"abc"`, etag))
+}
+
+func TestGetOrder_ETag(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	order := models.Order{Description: "Cached order", Quantity: 1, Status: "submitted", CustomerID: customer.ID}
+	db.Create(&order)
+
+	router := setupTestRouter()
+	router.GET("/orders/:id", mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token"), GetOrder)
+
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/orders/%d", order.ID), nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("")
+	assert.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+	assert.Equal(t, "private, no-cache", w.Header().Get("Cache-Control"))
+
+	// An unchanged order is not sent again
+	w = get(etag)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.Bytes())
+	assert.Equal(t, etag, w.Header().Get("ETag"))
+
+	// Any change to the order produces a new representation
+	db.Model(&models.Order{}).Where("id = ?", order.ID).Update("status", "accepted")
+	w = get(etag)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+}
+
+func TestListOrders_ETagRotatesForImages(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	previous := services.GetImageService()
+	mockImage := services.NewMockImageService()
+	mockImage.SetAsMockForTesting()
+	defer services.SetImageService(previous)
+
+	clock := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	etagClock = func() time.Time { return clock }
+	defer func() { etagClock = time.Now }()
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	imageKey := "uploads/design.png"
+	db.Create(&models.Order{Description: "Order with image", Quantity: 1, Status: "submitted", CustomerID: customer.ID, ImageS3Key: &imageKey})
+
+	router := setupTestRouter()
+	router.GET("/orders", mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token"), ListOrders)
+
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodGet, "/orders", nil)
+		req.Header.Set("If-None-Match", ifNoneMatch)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	etag := get("").Header().Get("ETag")
+	assert.Equal(t, http.StatusNotModified, get(etag).Code)
+
+	// Presigned URLs expire, so the cached list is refreshed once the window passes
+	clock = clock.Add(imageURLRefreshInterval())
+	w := get(etag)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+}
@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/apiresponse"
+	"github.com/kendall-kelly/kendalls-nails-api/middleware"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+)
+
+// TrackEventsRequest represents a batch of client analytics events
+type TrackEventsRequest struct {
+	Events []services.AnalyticsEventInput `json:"events" binding:"required"`
+}
+
+// TrackEvents handles POST /api/v1/events - records a batch of client analytics events
+// Events are queued for the warehouse export, so the response is 202 Accepted.
+// Callers who have not created a profile yet may report events, such as signup funnel steps
+func TrackEvents(c *gin.Context) {
+	user, err := middleware.GetCurrentUser(c)
+	if err != nil {
+		var apiErr *apierror.Error
+		if !errors.As(err, &apiErr) || apiErr.Code != "USER_NOT_FOUND" {
+			apierror.Respond(c, err)
+			return
+		}
+	}
+
+	var req TrackEventsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	result, err := services.GetAnalyticsEventService().Ingest(user, req.Events)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.JSON(c, http.StatusAccepted, result)
+}
@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrackEvents(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+
+	router := setupTestRouter()
+	router.POST("/customer/events", mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token"), TrackEvents)
+	router.POST("/guest/events", mockAuthMiddleware("auth0|new", "customer", "mock-token"), TrackEvents)
+
+	send := func(path string, events ...map[string]interface{}) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]interface{}{"events": events})
+		req, _ := http.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	occurredAt := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+	screenView := map[string]interface{}{"id": "evt-1", "type": "screen_view", "name": "orders/detail", "platform": "ios", "occurred_at": occurredAt, "properties": map[string]interface{}{"order_id": 12}}
+	funnelStep := map[string]interface{}{"id": "evt-2", "type": "funnel_step", "funnel": "checkout", "name": "pay", "occurred_at": occurredAt}
+
+	// A batch is recorded for the caller
+	w := send("/customer/events", screenView, funnelStep)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, float64(2), response["data"].(map[string]interface{})["recorded"])
+
+	var stored models.AnalyticsEvent
+	assert.NoError(t, db.Where("client_event_id = ?", "evt-1").First(&stored).Error)
+	assert.Equal(t, customer.ID, *stored.UserID)
+	assert.JSONEq(t, `{"order_id":12}`, stored.Properties)
+	assert.Nil(t, stored.ExportedAt)
+
+	// A retried batch is not recorded twice
+	w = send("/customer/events", screenView, funnelStep)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, float64(0), response["data"].(map[string]interface{})["recorded"])
+	assert.Equal(t, float64(2), response["data"].(map[string]interface{})["duplicates"])
+
+	// Callers without a profile may report signup funnel steps
+	w = send("/guest/events", map[string]interface{}{"id": "evt-3", "type": "funnel_step", "funnel": "signup", "name": "start", "occurred_at": occurredAt})
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	var guestEvent models.AnalyticsEvent
+	assert.NoError(t, db.Where("client_event_id = ?", "evt-3").First(&guestEvent).Error)
+	assert.Nil(t, guestEvent.UserID)
+
+	// An invalid event rejects the whole batch
+	w = send("/customer/events", map[string]interface{}{"id": "evt-4", "type": "screen_view", "name": "home", "occurred_at": occurredAt},
+		map[string]interface{}{"id": "evt-5", "type": "click", "name": "home", "occurred_at": occurredAt, "properties": map[string]interface{}{"nested": map[string]interface{}{}}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	details := response["error"].(map[string]interface{})["details"].(map[string]interface{})
+	assert.Contains(t, details, "events[1].type")
+	assert.Contains(t, details, "events[1].properties")
+
+	var count int64
+	db.Model(&models.AnalyticsEvent{}).Count(&count)
+	assert.Equal(t, int64(3), count)
+
+	// Batches are limited in size
+	events := make([]map[string]interface{}, 101)
+	for i := range events {
+		events[i] = map[string]interface{}{"id": fmt.Sprintf("bulk-%d", i), "type": "screen_view", "name": "home", "occurred_at": occurredAt}
+	}
+	w = send("/customer/events", events...)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
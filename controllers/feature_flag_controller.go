@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/apiresponse"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+)
+
+// FeatureFlagRequest represents the request body for creating or changing a feature flag
+// Fields left out of an update keep their values
+type FeatureFlagRequest struct {
+	Name        string  `json:"name"` // creation only
+	Description *string `json:"description" binding:"omitempty,max=500"`
+	Enabled     *bool   `json:"enabled"`
+	Percentage  *int    `json:"percentage"`
+	UserIDs     *[]uint `json:"user_ids"`
+}
+
+// input converts the request into service input
+func (r FeatureFlagRequest) input() services.FeatureFlagInput {
+	return services.FeatureFlagInput{
+		Name:        r.Name,
+		Description: r.Description,
+		Enabled:     r.Enabled,
+		Percentage:  r.Percentage,
+		UserIDs:     r.UserIDs,
+	}
+}
+
+// ListFeatureFlags handles GET /api/v1/admin/feature-flags - lists feature flags (admins only)
+func ListFeatureFlags(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	flags, err := services.GetFeatureFlagService().ListFlags(user)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, flags)
+}
+
+// CreateFeatureFlag handles POST /api/v1/admin/feature-flags - creates a feature flag (admins only)
+func CreateFeatureFlag(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req FeatureFlagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	flag, err := services.GetFeatureFlagService().CreateFlag(user, req.input())
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.Created(c, flag)
+}
+
+// UpdateFeatureFlag handles PUT /api/v1/admin/feature-flags/:name - changes who a feature flag is on for (admins only)
+func UpdateFeatureFlag(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req FeatureFlagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	flag, err := services.GetFeatureFlagService().UpdateFlag(user, c.Param("name"), req.input())
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, flag)
+}
+
+// DeleteFeatureFlag handles DELETE /api/v1/admin/feature-flags/:name - turns a feature flag off for everyone (admins only)
+func DeleteFeatureFlag(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := services.GetFeatureFlagService().DeleteFlag(user, c.Param("name")); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.Message(c, http.StatusOK, "Feature flag deleted")
+}
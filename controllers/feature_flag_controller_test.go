@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/flags"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeatureFlags(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+	flags.SetLoader(repositories.NewFeatureFlagRepository(db).List)
+	defer flags.SetLoader(nil)
+
+	admin := models.User{Auth0ID: "auth0|admin", Name: "Admin User", Email: "admin@example.com", Role: "admin"}
+	db.Create(&admin)
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+
+	router := setupTestRouter()
+	router.GET("/admin/feature-flags", mockAuthMiddleware(admin.Auth0ID, "admin", "mock-token"), ListFeatureFlags)
+	router.POST("/admin/feature-flags", mockAuthMiddleware(admin.Auth0ID, "admin", "mock-token"), CreateFeatureFlag)
+	router.PUT("/admin/feature-flags/:name", mockAuthMiddleware(admin.Auth0ID, "admin", "mock-token"), UpdateFeatureFlag)
+	router.DELETE("/admin/feature-flags/:name", mockAuthMiddleware(admin.Auth0ID, "admin", "mock-token"), DeleteFeatureFlag)
+	router.POST("/customer/admin/feature-flags", mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token"), CreateFeatureFlag)
+
+	request := func(method, path string, body interface{}) (*httptest.ResponseRecorder, map[string]interface{}) {
+		payload, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(payload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w, response
+	}
+	forCustomer := flags.WithUser(context.Background(), customer.ID)
+
+	// Only admins manage flags
+	w, _ := request(http.MethodPost, "/customer/admin/feature-flags", map[string]interface{}{"name": "negotiation"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// A flag rolled out to one customer is on for them only
+	w, response := request(http.MethodPost, "/admin/feature-flags", map[string]interface{}{
+		"name": "negotiation", "description": "Counter-offers on quotes", "user_ids": []uint{customer.ID},
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, "negotiation", data["name"])
+	assert.Equal(t, false, data["enabled"])
+	assert.Equal(t, []interface{}{float64(customer.ID)}, data["user_ids"])
+	assert.True(t, flags.Enabled(forCustomer, "negotiation"))
+	assert.False(t, flags.Enabled(context.Background(), "negotiation"))
+
+	w, response = request(http.MethodPost, "/admin/feature-flags", map[string]interface{}{"name": "negotiation"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "FEATURE_FLAG_EXISTS", response["error"].(map[string]interface{})["code"])
+
+	// Rolling out to everyone keeps the description
+	w, response = request(http.MethodPut, "/admin/feature-flags/negotiation", map[string]interface{}{"percentage": 100})
+	assert.Equal(t, http.StatusOK, w.Code)
+	data = response["data"].(map[string]interface{})
+	assert.Equal(t, float64(100), data["percentage"])
+	assert.Equal(t, "Counter-offers on quotes", data["description"])
+	assert.True(t, flags.Enabled(context.Background(), "negotiation"))
+
+	w, _ = request(http.MethodPut, "/admin/feature-flags/negotiation", map[string]interface{}{"percentage": -5})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w, _ = request(http.MethodPut, "/admin/feature-flags/payments", map[string]interface{}{"enabled": true})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w, response = request(http.MethodGet, "/admin/feature-flags", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, response["data"], 1)
+
+	// Deleting a flag turns it off and frees its name
+	w, _ = request(http.MethodDelete, "/admin/feature-flags/negotiation", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, flags.Enabled(forCustomer, "negotiation"))
+	w, _ = request(http.MethodPost, "/admin/feature-flags", map[string]interface{}{"name": "negotiation"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var entries int64
+	db.Model(&models.AuditLog{}).Where("action = ?", models.AuditFeatureFlagChanged).Count(&entries)
+	assert.Equal(t, int64(4), entries)
+}
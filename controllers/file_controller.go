@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+	"github.com/kendall-kelly/kendalls-nails-api/utils"
+)
+
+// ServeFile handles GET /files/*key - a stored file served through the API's signed image proxy links
+// Range requests are supported so clients can resume large downloads, and conditional requests get 304
+func ServeFile(c *gin.Context) {
+	proxy := services.GetFileProxy()
+	if proxy == nil {
+		apierror.Respond(c, apierror.NotFound("FILE_NOT_FOUND", "File not found"))
+		return
+	}
+
+	file, err := proxy.Open(strings.TrimPrefix(c.Param("key"), "/"), c.Query("expires"), c.Query("signature"))
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	// The file may be cached for as long as the link that fetched it is valid
+	maxAge := int64(time.Until(file.ExpiresAt) / time.Second)
+	if maxAge < 0 {
+		maxAge = 0
+	}
+	hash := sha256.Sum256(file.Content)
+	c.Header("Content-Type", servedContentType(file.Key, file.Content))
+	c.Header("Cache-Control", "private, max-age="+strconv.FormatInt(maxAge, 10))
+	c.Header("ETag", `"`+hex.EncodeToString(hash[:16])+`"`)
+	c.Header("X-Content-Type-Options", "nosniff")
+
+	// ServeContent answers Range, If-Range, If-None-Match, and If-Modified-Since from the headers set above
+	http.ServeContent(c.Writer, c.Request, "", file.LastModified, bytes.NewReader(file.Content))
+}
+
+// servedContentType returns the Content-Type of a stored file from its extension
+// Only image types uploads can have are trusted; anything else is detected from the content, so a
+// misleading extension such as .html cannot make the API serve markup
+func servedContentType(key string, content []byte) string {
+	contentType := mime.TypeByExtension(strings.ToLower(path.Ext(key)))
+	if utils.IsSupportedImageType(contentType) {
+		return contentType
+	}
+	if detected := http.DetectContentType(content); utils.IsSupportedImageType(detected) {
+		return detected
+	}
+	return "application/octet-stream"
+}
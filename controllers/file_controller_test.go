@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeFile(t *testing.T) {
+	storage := services.NewMockStorage()
+	uploadedAt := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	content := append([]byte("\x89PNG\r\n\x1a\n"), []byte("0123456789")...)
+	storage.PutFile("uploads/1_design.png", content, uploadedAt)
+	storage.PutFile("uploads/2_page.html", []byte("<html><script>alert(1)</script></html>"), uploadedAt)
+
+	proxy, err := services.NewFileProxy(storage, &config.Config{ImageProxyURL: "https://api.example.com", ImageProxyKey: "test-signing-key"})
+	require.NoError(t, err)
+	services.SetFileProxy(proxy)
+	defer services.SetFileProxy(nil)
+
+	router := setupTestRouter()
+	router.GET(services.FileProxyRoute+"/*key", ServeFile)
+
+	get := func(key string, headers map[string]string) *httptest.ResponseRecorder {
+		link, err := proxy.URL(key)
+		require.NoError(t, err)
+		parsed, err := url.Parse(link)
+		require.NoError(t, err)
+		req, _ := http.NewRequest(http.MethodGet, parsed.RequestURI(), nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("serves the file with cache headers", func(t *testing.T) {
+		w := get("uploads/1_design.png", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, content, w.Body.Bytes())
+		assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+		assert.Equal(t, "bytes", w.Header().Get("Accept-Ranges"))
+		assert.Equal(t, uploadedAt.Format(http.TimeFormat), w.Header().Get("Last-Modified"))
+		assert.NotEmpty(t, w.Header().Get("ETag"))
+		assert.Regexp(t, `^private, max-age=3[56]\d\d$`, w.Header().Get("Cache-Control"))
+	})
+
+	t.Run("serves byte ranges so downloads can resume", func(t *testing.T) {
+		w := get("uploads/1_design.png", map[string]string{"Range": "bytes=8-"})
+		assert.Equal(t, http.StatusPartialContent, w.Code)
+		assert.Equal(t, "0123456789", w.Body.String())
+		assert.Equal(t, "bytes 8-17/"+strconv.Itoa(len(content)), w.Header().Get("Content-Range"))
+
+		// A range of a file that has changed since is answered with the whole file
+		w = get("uploads/1_design.png", map[string]string{"Range": "bytes=8-", "If-Range": `"stale"`})
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, content, w.Body.Bytes())
+
+		w = get("uploads/1_design.png", map[string]string{"Range": "bytes=100-"})
+		assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, w.Code)
+	})
+
+	t.Run("answers conditional requests with 304", func(t *testing.T) {
+		etag := get("uploads/1_design.png", nil).Header().Get("ETag")
+		assert.Equal(t, http.StatusNotModified, get("uploads/1_design.png", map[string]string{"If-None-Match": etag}).Code)
+		assert.Equal(t, http.StatusNotModified, get("uploads/1_design.png", map[string]string{
+			"If-Modified-Since": uploadedAt.Add(time.Hour).Format(http.TimeFormat),
+		}).Code)
+	})
+
+	t.Run("does not trust extensions other than image types", func(t *testing.T) {
+		w := get("uploads/2_page.html", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/octet-stream", w.Header().Get("Content-Type"))
+		assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+	})
+
+	t.Run("rejects tampered links", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/files/uploads/1_design.png?expires=9999999999&signature=forged", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
@@ -0,0 +1,38 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/apiresponse"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+)
+
+// ClaimGiftRequest represents the request body for claiming a gift order
+type ClaimGiftRequest struct {
+	ClaimCode string `json:"claim_code" binding:"required"`
+}
+
+// ClaimGift handles POST /api/v1/gifts/claim - links a gift order to the caller with the claim code from its invitation
+// Like intake claims, a recipient who has just signed up with Auth0 gets a profile created as part of the claim
+func ClaimGift(c *gin.Context) {
+	var req ClaimGiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	user, ok := claimingUser(c)
+	if !ok {
+		return
+	}
+
+	order, err := services.GetGiftService().ClaimGift(user, req.ClaimCode)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	populateOrderImageURL(order)
+
+	apiresponse.OK(c, order)
+}
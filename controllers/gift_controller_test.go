@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGiftOrder_InviteAndClaim(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	giver := models.User{Auth0ID: "auth0|giver", Name: "Giver", Email: "giver@example.com", Role: "customer"}
+	db.Create(&giver)
+	other := models.User{Auth0ID: "auth0|other", Name: "Other Customer", Email: "other@example.com", Role: "customer"}
+	db.Create(&other)
+
+	// The recipient signs up with Auth0 after the gift is placed, and claims without creating a profile first
+	mockServer := setupMockAuth0Server(map[string]*services.Auth0UserInfo{
+		"recipient-token": {Sub: "auth0|recipient", Email: "bridesmaid@example.com", Name: "Bridesmaid"},
+	})
+	defer mockServer.Close()
+	originalConfig := config.GetConfig()
+	defer config.SetConfig(originalConfig)
+	config.SetConfig(&config.Config{Auth0Domain: mockServer.URL})
+
+	router := setupTestRouter()
+	giverAuth := mockAuthMiddleware(giver.Auth0ID, "customer", "mock-token")
+	recipientAuth := mockAuthMiddleware("auth0|recipient", "customer", "recipient-token")
+	router.POST("/orders", giverAuth, CreateOrder)
+	router.GET("/orders/:id", giverAuth, GetOrder)
+	router.POST("/gifts/claim", recipientAuth, ClaimGift)
+	router.GET("/recipient/orders", recipientAuth, ListOrders)
+	router.GET("/other/orders/:id", mockAuthMiddleware(other.Auth0ID, "customer", "mock-token"), GetOrder)
+
+	send := func(method, path string, body interface{}) (int, map[string]interface{}) {
+		payload, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(payload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response
+	}
+
+	// The recipient has no account yet, so the giver gets a claim code to pass on
+	code, response := send(http.MethodPost, "/orders", map[string]interface{}{
+		"description":     "Blush almond set",
+		"quantity":        1,
+		"recipient_email": "Bridesmaid@example.com",
+		"recipient_name":  "Jo",
+		"gift_message":    "Thank you for standing with me!",
+	})
+	require.Equal(t, http.StatusCreated, code)
+	order := response["data"].(map[string]interface{})
+	orderID := uint(order["id"].(float64))
+	assert.Equal(t, "bridesmaid@example.com", order["recipient_email"])
+	assert.Nil(t, order["recipient_id"])
+	claimCode, _ := order["gift_claim_code"].(string)
+	require.NotEmpty(t, claimCode)
+
+	// The claim code is only shown once
+	code, response = send(http.MethodGet, fmt.Sprintf("/orders/%d", orderID), nil)
+	require.Equal(t, http.StatusOK, code)
+	assert.Nil(t, response["data"].(map[string]interface{})["gift_claim_code"])
+
+	code, response = send(http.MethodPost, "/gifts/claim", map[string]interface{}{"claim_code": claimCode})
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, float64(orderID), response["data"].(map[string]interface{})["id"])
+
+	// The recipient now sees the gift among their orders; other customers still cannot
+	code, response = send(http.MethodGet, "/recipient/orders", nil)
+	require.Equal(t, http.StatusOK, code)
+	listed := response["data"].([]interface{})
+	require.Len(t, listed, 1)
+	assert.Equal(t, "Thank you for standing with me!", listed[0].(map[string]interface{})["gift_message"])
+
+	code, _ = send(http.MethodGet, fmt.Sprintf("/other/orders/%d", orderID), nil)
+	assert.Equal(t, http.StatusForbidden, code)
+
+	code, response = send(http.MethodPost, "/gifts/claim", map[string]interface{}{"claim_code": claimCode})
+	assert.Equal(t, http.StatusConflict, code)
+	assert.Equal(t, "GIFT_ALREADY_CLAIMED", response["error"].(map[string]interface{})["code"])
+
+	// Invalid recipients are reported by field
+	code, response = send(http.MethodPost, "/orders", map[string]interface{}{"description": "Pink", "quantity": 1, "recipient_email": "nope"})
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Contains(t, fmt.Sprint(response["error"]), "recipient_email")
+}
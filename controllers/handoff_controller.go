@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/apiresponse"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+)
+
+// HandoffRequest represents the request body for handing off an order
+type HandoffRequest struct {
+	TechnicianID uint   `json:"technician_id" binding:"required,gt=0"`
+	Note         string `json:"note" binding:"max=1000"`
+}
+
+// RespondHandoffRequest represents the request body for answering a hand-off
+type RespondHandoffRequest struct {
+	Action string `json:"action" binding:"required,oneof=accept decline"`
+}
+
+// RequestHandoff handles POST /api/v1/orders/:id/handoff - asks another technician to take over an order (assigned technician only)
+func RequestHandoff(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req HandoffRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	handoff, err := services.GetHandoffService().RequestHandoff(user, c.Param("id"), services.HandoffInput{
+		ToTechnicianID: req.TechnicianID,
+		Note:           req.Note,
+	})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.Created(c, handoff)
+}
+
+// RespondToHandoff handles PUT /api/v1/orders/:id/handoff/:handoffId - accepts or declines a hand-off (target technician only)
+func RespondToHandoff(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req RespondHandoffRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	handoff, err := services.GetHandoffService().RespondToHandoff(user, c.Param("id"), c.Param("handoffId"), req.Action == "accept")
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, handoff)
+}
+
+// ListHandoffs handles GET /api/v1/orders/:id/handoffs - returns an order's hand-off history
+func ListHandoffs(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	handoffs, err := services.GetHandoffService().ListHandoffs(user, c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, handoffs)
+}
+
+// ListIncomingHandoffs handles GET /api/v1/handoffs/incoming - returns hand-offs awaiting the technician's answer
+func ListIncomingHandoffs(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	handoffs, err := services.GetHandoffService().ListIncoming(user)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	for i := range handoffs {
+		if handoffs[i].Order != nil {
+			populateOrderImageURL(handoffs[i].Order)
+		}
+	}
+
+	apiresponse.OK(c, handoffs)
+}
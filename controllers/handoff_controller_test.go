@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandoff_AcceptFlow(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	first := models.User{Auth0ID: "auth0|tech1", Name: "First Tech", Email: "tech1@example.com", Role: "technician"}
+	db.Create(&first)
+	second := models.User{Auth0ID: "auth0|tech2", Name: "Second Tech", Email: "tech2@example.com", Role: "technician"}
+	db.Create(&second)
+
+	order := models.Order{Description: "Hand-off order", Quantity: 1, Status: "in_production", CustomerID: customer.ID, TechnicianID: &first.ID}
+	db.Create(&order)
+
+	router := setupTestRouter()
+	firstAuth := mockAuthMiddleware(first.Auth0ID, "technician", "mock-token")
+	secondAuth := mockAuthMiddleware(second.Auth0ID, "technician", "mock-token")
+	router.POST("/first/orders/:id/handoff", firstAuth, RequestHandoff)
+	router.GET("/second/handoffs/incoming", secondAuth, ListIncomingHandoffs)
+	router.PUT("/second/orders/:id/handoff/:handoffId", secondAuth, RespondToHandoff)
+	router.GET("/customer/orders/:id/handoffs", mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token"), ListHandoffs)
+
+	send := func(method, path string, body interface{}) (*httptest.ResponseRecorder, map[string]interface{}) {
+		var buf bytes.Buffer
+		if body != nil {
+			_ = json.NewEncoder(&buf).Encode(body)
+		}
+		req, _ := http.NewRequest(method, path, &buf)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w, response
+	}
+
+	// The assigned technician asks the second technician to take over
+	w, response := send(http.MethodPost, fmt.Sprintf("/first/orders/%d/handoff", order.ID), map[string]interface{}{
+		"technician_id": second.ID,
+		"note":          "Going on leave",
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	handoff := response["data"].(map[string]interface{})
+	assert.Equal(t, "pending", handoff["status"])
+	assert.Equal(t, "Going on leave", handoff["note"])
+	handoffID := uint(handoff["id"].(float64))
+
+	// A second request is refused while the first is pending
+	w, response = send(http.MethodPost, fmt.Sprintf("/first/orders/%d/handoff", order.ID), map[string]interface{}{"technician_id": second.ID})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "HANDOFF_PENDING", response["error"].(map[string]interface{})["code"])
+
+	// The target sees it in their inbox along with the order
+	w, response = send(http.MethodGet, "/second/handoffs/incoming", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	incoming := response["data"].([]interface{})
+	assert.Len(t, incoming, 1)
+	assert.Equal(t, "Hand-off order", incoming[0].(map[string]interface{})["order"].(map[string]interface{})["description"])
+
+	// Accepting reassigns the order
+	w, response = send(http.MethodPut, fmt.Sprintf("/second/orders/%d/handoff/%d", order.ID, handoffID), map[string]string{"action": "accept"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "accepted", response["data"].(map[string]interface{})["status"])
+	assert.NotNil(t, response["data"].(map[string]interface{})["responded_at"])
+
+	var reassigned models.Order
+	db.First(&reassigned, order.ID)
+	assert.Equal(t, second.ID, *reassigned.TechnicianID)
+
+	// The customer is told in the order conversation
+	var messages []models.Message
+	db.Where("order_id = ?", order.ID).Find(&messages)
+	assert.Len(t, messages, 1)
+	assert.Equal(t, second.ID, *messages[0].SenderID)
+	assert.Contains(t, messages[0].Text, "Second Tech")
+
+	// The hand-off stays in the order's history
+	w, response = send(http.MethodGet, fmt.Sprintf("/customer/orders/%d/handoffs", order.ID), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	history := response["data"].([]interface{})
+	assert.Len(t, history, 1)
+	assert.Equal(t, "First Tech", history[0].(map[string]interface{})["from_technician"].(map[string]interface{})["name"])
+
+	// An answered hand-off cannot be answered again
+	w, response = send(http.MethodPut, fmt.Sprintf("/second/orders/%d/handoff/%d", order.ID, handoffID), map[string]string{"action": "decline"})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "HANDOFF_NOT_PENDING", response["error"].(map[string]interface{})["code"])
+}
+
+func TestRespondToHandoff_InvalidAction(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	technician := models.User{Auth0ID: "auth0|tech", Name: "Technician User", Email: "tech@example.com", Role: "technician"}
+	db.Create(&technician)
+
+	router := setupTestRouter()
+	router.PUT("/orders/:id/handoff/:handoffId", mockAuthMiddleware(technician.Auth0ID, "technician", "mock-token"), RespondToHandoff)
+
+	req, _ := http.NewRequest(http.MethodPut, "/orders/1/handoff/1", bytes.NewBufferString(`{"action":"maybe"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
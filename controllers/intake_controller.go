@@ -0,0 +1,204 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/apiresponse"
+	"github.com/kendall-kelly/kendalls-nails-api/middleware"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+)
+
+// IntakeTokenHeader carries the intake token on requests from an embedded order widget
+const IntakeTokenHeader = "X-Intake-Token"
+
+// CreateIntakeTokenRequest represents the request body for creating an intake token
+type CreateIntakeTokenRequest struct {
+	Label          string   `json:"label" binding:"max=100"`
+	Scopes         []string `json:"scopes"`
+	Origin         string   `json:"origin"`
+	ExpiresInHours int      `json:"expires_in_hours" binding:"gte=0"`
+}
+
+// IntakeDraftRequest represents a draft order submitted through an intake widget
+// It is accepted as JSON, or as multipart form data with an optional "image" file
+type IntakeDraftRequest struct {
+	Name        string `form:"name" json:"name" binding:"required,max=200"`
+	Email       string `form:"email" json:"email" binding:"required,email"`
+	Description string `form:"description" json:"description" binding:"required"`
+	Quantity    int    `form:"quantity" json:"quantity" binding:"required,gt=0"`
+	Rush        bool   `form:"rush" json:"rush"`
+}
+
+// ClaimIntakeDraftRequest represents the request body for claiming a draft order
+type ClaimIntakeDraftRequest struct {
+	ClaimCode string `json:"claim_code" binding:"required"`
+}
+
+// CreateIntakeToken handles POST /api/v1/intake-tokens - issues a token for an embedded order widget (technicians only)
+// The token is only included in this response
+func CreateIntakeToken(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req CreateIntakeTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	token, err := services.GetIntakeService().CreateToken(user, services.IntakeTokenInput{
+		Label:  req.Label,
+		Scopes: req.Scopes,
+		Origin: req.Origin,
+		TTL:    time.Duration(req.ExpiresInHours) * time.Hour,
+	})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.Created(c, token)
+}
+
+// ListIntakeTokens handles GET /api/v1/intake-tokens - lists the technician's intake tokens
+func ListIntakeTokens(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	tokens, err := services.GetIntakeService().ListTokens(user)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, tokens)
+}
+
+// RevokeIntakeToken handles DELETE /api/v1/intake-tokens/:id - stops an intake token from being used
+func RevokeIntakeToken(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	token, err := services.GetIntakeService().RevokeToken(user, c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, token)
+}
+
+// SubmitIntakeDraft handles POST /api/v1/intake/drafts - records a draft order from an embedded widget
+// Authenticated by the X-Intake-Token header instead of a user JWT
+// The response carries the claim code the guest uses to turn the draft into an order after signing up
+func SubmitIntakeDraft(c *gin.Context) {
+	intakeService := services.GetIntakeService()
+	token, err := intakeService.Authenticate(c.GetHeader(IntakeTokenHeader), c.GetHeader("Origin"), services.IntakeScopeDrafts)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	var req IntakeDraftRequest
+	if err := c.ShouldBind(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	input := services.IntakeDraftInput{
+		GuestName:   req.Name,
+		GuestEmail:  req.Email,
+		Description: req.Description,
+		Quantity:    req.Quantity,
+		Rush:        req.Rush,
+	}
+
+	// Check the image scope before uploading so that a forbidden image is never stored
+	if fileHeader, err := c.FormFile("image"); err == nil {
+		if !token.HasScope(services.IntakeScopeImages) {
+			apierror.Respond(c, apierror.Forbidden("INSUFFICIENT_SCOPE", "Intake token does not allow this action").
+				WithDetails(map[string]string{"required_scope": services.IntakeScopeImages}))
+			return
+		}
+		imageKey, ok := uploadDesignImage(c, fileHeader, nil)
+		if !ok {
+			return
+		}
+		input.ImageS3Key = &imageKey
+	}
+
+	draft, err := intakeService.SubmitDraft(token, input)
+	if err != nil {
+		if input.ImageS3Key != nil {
+			if deleteErr := services.GetImageService().DeleteImage(*input.ImageS3Key); deleteErr != nil {
+				log.Printf("Failed to delete orphaned draft image %s: %v", *input.ImageS3Key, deleteErr)
+			}
+		}
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.Created(c, draft)
+}
+
+// ClaimIntakeDraft handles POST /api/v1/intake/claim - turns a widget draft into an order of the caller
+// A guest who has just signed up with Auth0 does not need to create a profile first;
+// one is created from their Auth0 userinfo as part of the claim
+func ClaimIntakeDraft(c *gin.Context) {
+	var req ClaimIntakeDraftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	user, ok := claimingUser(c)
+	if !ok {
+		return
+	}
+
+	order, err := services.GetIntakeServiceFor(c.Request.Context()).ClaimDraft(user, req.ClaimCode)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	populateOrderImageURL(order)
+
+	apiresponse.Created(c, order)
+}
+
+// claimingUser resolves the caller's profile, registering one from Auth0 when it does not exist yet
+// On failure the error response is written and false is returned
+func claimingUser(c *gin.Context) (*models.User, bool) {
+	user, err := middleware.GetCurrentUser(c)
+	if err == nil {
+		return user, true
+	}
+	var apiErr *apierror.Error
+	if !errors.As(err, &apiErr) || apiErr.Code != "USER_NOT_FOUND" {
+		apierror.Respond(c, err)
+		return nil, false
+	}
+
+	profile, ok := auth0Profile(c)
+	if !ok {
+		return nil, false
+	}
+	user, _, err = services.GetUserService().Register(profile)
+	if err != nil {
+		apierror.Respond(c, err)
+		return nil, false
+	}
+	return user, true
+}
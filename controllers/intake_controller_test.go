@@ -0,0 +1,146 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntakeWidget_SubmitAndClaim(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	previous := services.GetImageService()
+	mockImage := services.NewMockImageService()
+	mockImage.SetAsMockForTesting()
+	defer services.SetImageService(previous)
+
+	technician := models.User{Auth0ID: "auth0|tech", Name: "Technician User", Email: "tech@example.com", Role: "technician"}
+	db.Create(&technician)
+	otherTech := models.User{Auth0ID: "auth0|other-tech", Name: "Other Technician", Email: "other@example.com", Role: "technician"}
+	db.Create(&otherTech)
+
+	// The guest signs up with Auth0 after submitting, and claims without creating a profile first
+	mockServer := setupMockAuth0Server(map[string]*services.Auth0UserInfo{
+		"guest-token": {Sub: "auth0|guest", Email: "guest@example.com", Name: "Guest User"},
+	})
+	defer mockServer.Close()
+	originalConfig := config.GetConfig()
+	defer config.SetConfig(originalConfig)
+	config.SetConfig(&config.Config{Auth0Domain: mockServer.URL})
+
+	router := setupTestRouter()
+	techAuth := mockAuthMiddleware(technician.Auth0ID, "technician", "mock-token")
+	router.POST("/intake-tokens", techAuth, CreateIntakeToken)
+	router.DELETE("/intake-tokens/:id", techAuth, RevokeIntakeToken)
+	router.POST("/intake/drafts", SubmitIntakeDraft)
+	router.POST("/intake/claim", mockAuthMiddleware("auth0|guest", "customer", "guest-token"), ClaimIntakeDraft)
+	router.PUT("/other/orders/:id/review", mockAuthMiddleware(otherTech.Auth0ID, "technician", "mock-token"), ReviewOrder)
+
+	send := func(method, path string, body interface{}, header map[string]string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		payload, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(payload))
+		req.Header.Set("Content-Type", "application/json")
+		for name, value := range header {
+			req.Header.Set(name, value)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w, response
+	}
+
+	// The technician creates a drafts-only token bound to their site; the secret is shown once
+	w, response := send(http.MethodPost, "/intake-tokens", map[string]interface{}{
+		"label":  "Portfolio site",
+		"scopes": []string{services.IntakeScopeDrafts},
+		"origin": "https://nailsbytech.example/",
+	}, nil)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	tokenData := response["data"].(map[string]interface{})
+	secret := tokenData["token"].(string)
+	assert.Equal(t, "https://nailsbytech.example", tokenData["origin"])
+	assert.NotContains(t, w.Body.String(), "token_hash")
+
+	var stored models.IntakeToken
+	db.First(&stored)
+	assert.NotEqual(t, secret, stored.TokenHash)
+
+	draftBody := map[string]interface{}{
+		"name":        "Guest User",
+		"email":       "Guest@Example.com",
+		"description": "Pastel ombre almonds",
+		"quantity":    1,
+	}
+
+	// Requests need a valid token, from the token's site
+	w, _ = send(http.MethodPost, "/intake/drafts", draftBody, nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w, _ = send(http.MethodPost, "/intake/drafts", draftBody, map[string]string{
+		IntakeTokenHeader: secret,
+		"Origin":          "https://elsewhere.example",
+	})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Images need the images scope, and are rejected before upload
+	form, contentType := newProgressUpdateForm(t, "inspo.png", map[string]string{
+		"name": "Guest User", "email": "guest@example.com", "description": "Like this", "quantity": "1",
+	})
+	req, _ := http.NewRequest(http.MethodPost, "/intake/drafts", form)
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set(IntakeTokenHeader, secret)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "INSUFFICIENT_SCOPE")
+	assert.False(t, mockImage.ImageExists("uploads/mock_inspo.png"))
+
+	// A valid submission returns the claim code
+	w, response = send(http.MethodPost, "/intake/drafts", draftBody, map[string]string{
+		IntakeTokenHeader: secret,
+		"Origin":          "https://nailsbytech.example",
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	draftData := response["data"].(map[string]interface{})
+	claimCode := draftData["claim_code"].(string)
+	assert.Equal(t, "guest@example.com", draftData["guest_email"])
+
+	// Claiming creates the guest's account and a submitted order reserved for the technician
+	w, response = send(http.MethodPost, "/intake/claim", map[string]string{"claim_code": claimCode}, nil)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	order := response["data"].(map[string]interface{})
+	assert.Equal(t, "submitted", order["status"])
+	assert.Equal(t, "Pastel ombre almonds", order["description"])
+	assert.Equal(t, float64(technician.ID), order["technician_id"])
+	assert.Equal(t, "guest@example.com", order["customer"].(map[string]interface{})["email"])
+
+	var guest models.User
+	assert.NoError(t, db.Where("auth0_id = ?", "auth0|guest").First(&guest).Error)
+	assert.Equal(t, "customer", guest.Role)
+
+	// A claim code works once
+	w, _ = send(http.MethodPost, "/intake/claim", map[string]string{"claim_code": claimCode}, nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Other technicians cannot review the reserved order
+	w, _ = send(http.MethodPut, fmt.Sprintf("/other/orders/%v/review", order["id"]), map[string]interface{}{
+		"action": "accept", "price": 40.0,
+	}, map[string]string{"If-Match": "*"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// A revoked token stops working
+	w, _ = send(http.MethodDelete, fmt.Sprintf("/intake-tokens/%d", stored.ID), nil, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w, _ = send(http.MethodPost, "/intake/drafts", draftBody, map[string]string{IntakeTokenHeader: secret})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
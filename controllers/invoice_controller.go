@@ -0,0 +1,27 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+)
+
+// GetInvoice handles GET /api/v1/orders/:id/invoice - PDF invoice of an accepted order (customer and assigned technician only)
+func GetInvoice(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	pdf, err := services.GetInvoiceService().Invoice(user, c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	// The service has validated the ID, so it is safe in the header
+	c.Header("Content-Disposition", `inline; filename="invoice-`+c.Param("id")+`.pdf"`)
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetInvoice(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	technician := models.User{Auth0ID: "auth0|tech", Name: "Technician User", Email: "tech@example.com", Role: "technician"}
+	db.Create(&technician)
+	otherTechnician := models.User{Auth0ID: "auth0|other-tech", Name: "Other Technician", Email: "other@example.com", Role: "technician"}
+	db.Create(&otherTechnician)
+
+	price := 40.0
+	accepted := models.Order{Description: "Accepted set", Quantity: 1, Status: "accepted", Price: &price, CustomerID: customer.ID, TechnicianID: &technician.ID}
+	db.Create(&accepted)
+	db.Create(&models.OrderLineItem{OrderID: accepted.ID, Kind: models.LineItemBase, Description: "Custom nail set", Quantity: 1, UnitPrice: 40, Amount: 40})
+	submitted := models.Order{Description: "Waiting for review", Quantity: 1, Status: "submitted", CustomerID: customer.ID}
+	db.Create(&submitted)
+
+	router := setupTestRouter()
+	router.GET("/orders/:id/invoice", mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token"), GetInvoice)
+	router.GET("/other/orders/:id/invoice", mockAuthMiddleware(otherTechnician.Auth0ID, "technician", "mock-token"), GetInvoice)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Assert
+	w := get(fmt.Sprintf("/orders/%d/invoice", accepted.ID))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+	assert.Equal(t, fmt.Sprintf(`inline; filename="invoice-%d.pdf"`, accepted.ID), w.Header().Get("Content-Disposition"))
+	assert.True(t, bytes.HasPrefix(w.Body.Bytes(), []byte("%PDF-")))
+
+	w = get(fmt.Sprintf("/orders/%d/invoice", submitted.ID))
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	w = get(fmt.Sprintf("/other/orders/%d/invoice", accepted.ID))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
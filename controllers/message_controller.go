@@ -4,9 +4,11 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/apiresponse"
 	"github.com/kendall-kelly/kendalls-nails-api/config"
-	"github.com/kendall-kelly/kendalls-nails-api/middleware"
 	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
 )
 
 // SendMessageRequest represents the request body for sending a message
@@ -14,228 +16,229 @@ type SendMessageRequest struct {
 	Text string `json:"text" binding:"required"`
 }
 
+// cannedReplyText fills in the technician's canned reply named by the template_id query parameter for the order
+// It returns false when there is none; on failure the error response is written
+func cannedReplyText(c *gin.Context, user *models.User, order *models.Order) (string, bool) {
+	templateID := c.Query("template_id")
+	if templateID == "" {
+		return "", false
+	}
+
+	if err := config.GetDB().First(&order.Customer, order.CustomerID).Error; err != nil {
+		apierror.Respond(c, apierror.Internal("DATABASE_ERROR", "Failed to load customer").Wrap(err))
+		return "", true
+	}
+
+	text, err := services.GetCannedReplyService().ExpandReply(user, templateID, order)
+	if err != nil {
+		apierror.Respond(c, err)
+	}
+	return text, true
+}
+
 // SendMessage handles POST /api/v1/orders/:id/messages - sends a message on an order
+// Technicians can send a canned reply with ?template_id=<id> in place of a text body
 func SendMessage(c *gin.Context) {
-	// Extract Auth0 user ID from JWT token
-	auth0ID, err := middleware.GetUserID(c)
-	if err != nil {
-		c.PureJSON(http.StatusUnauthorized, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "UNAUTHORIZED",
-				"message": "Could not extract user information",
-			},
-		})
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
 
-	// Find the user in the database
 	db := config.GetDB()
-	var user models.User
-	if err := db.Where("auth0_id = ?", auth0ID).First(&user).Error; err != nil {
-		c.PureJSON(http.StatusNotFound, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "USER_NOT_FOUND",
-				"message": "User profile not found. Please create a profile first.",
-			},
-		})
-		return
-	}
 
 	// Get order ID from URL parameter
 	orderID := c.Param("id")
 	if orderID == "" {
-		c.PureJSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "INVALID_REQUEST",
-				"message": "Order ID is required",
-			},
-		})
+		apierror.Respond(c, apierror.BadRequest("INVALID_REQUEST", "Order ID is required"))
 		return
 	}
 
 	// Fetch the order
 	var order models.Order
 	if err := db.First(&order, orderID).Error; err != nil {
-		c.PureJSON(http.StatusNotFound, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "ORDER_NOT_FOUND",
-				"message": "Order not found",
-			},
-		})
+		apierror.Respond(c, apierror.NotFound("ORDER_NOT_FOUND", "Order not found"))
 		return
 	}
 
 	// Authorization check: Can user message on this order?
-	// Customers can only message on their own orders
-	// Technicians can only message on orders assigned to them
-	canMessage := false
-	switch user.Role {
-	case "customer":
-		canMessage = order.CustomerID == user.ID
-	case "technician":
-		canMessage = order.TechnicianID != nil && *order.TechnicianID == user.ID
+	// The route only admits customers and technicians; customers can only message on
+	// their own orders, and technicians on orders assigned to them
+	if order.CustomerID != user.ID && !services.IsAssignedTo(&order, user) {
+		apierror.Respond(c, apierror.Forbidden("FORBIDDEN", "You do not have permission to message on this order"))
+		return
 	}
 
-	if !canMessage {
-		c.PureJSON(http.StatusForbidden, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "FORBIDDEN",
-				"message": "You do not have permission to message on this order",
-			},
-		})
+	// With ?template_id= the text comes from one of the technician's canned replies and no body is needed
+	text, templated := cannedReplyText(c, user, &order)
+	if c.IsAborted() {
 		return
 	}
+	if !templated {
+		var req SendMessageRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		text = req.Text
+	}
 
-	// Parse request body
-	var req SendMessageRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.PureJSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "VALIDATION_ERROR",
-				"message": "Invalid request data",
-				"details": err.Error(),
-			},
-		})
+	// Offensive words are masked, or the message is blocked, when a content filter is configured
+	text, err := services.GetMessageFilterService().Screen(user, order.ID, text)
+	if err != nil {
+		apierror.Respond(c, err)
 		return
 	}
 
 	// Create the message
 	message := models.Message{
 		OrderID:  order.ID,
-		SenderID: user.ID,
-		Text:     req.Text,
+		SenderID: &user.ID,
+		Text:     text,
 	}
 
 	if err := db.Create(&message).Error; err != nil {
-		c.PureJSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "DATABASE_ERROR",
-				"message": "Failed to create message",
-			},
-		})
+		apierror.Respond(c, apierror.Internal("DATABASE_ERROR", "Failed to create message").Wrap(err))
 		return
 	}
 
 	// Load the sender relationship to return complete data
 	if err := db.Preload("Sender").First(&message, message.ID).Error; err != nil {
-		c.PureJSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "DATABASE_ERROR",
-				"message": "Failed to load message details",
-			},
-		})
+		apierror.Respond(c, apierror.Internal("DATABASE_ERROR", "Failed to load message details").Wrap(err))
 		return
 	}
+	services.PublishEvent(services.MessageSent{Message: &message})
 
-	c.PureJSON(http.StatusCreated, gin.H{
-		"success": true,
-		"data":    message,
-	})
+	apiresponse.Created(c, message)
 }
 
 // ListMessages handles GET /api/v1/orders/:id/messages - lists messages for an order
 func ListMessages(c *gin.Context) {
-	// Extract Auth0 user ID from JWT token
-	auth0ID, err := middleware.GetUserID(c)
-	if err != nil {
-		c.PureJSON(http.StatusUnauthorized, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "UNAUTHORIZED",
-				"message": "Could not extract user information",
-			},
-		})
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
 
-	// Find the user in the database
 	db := config.GetDB()
-	var user models.User
-	if err := db.Where("auth0_id = ?", auth0ID).First(&user).Error; err != nil {
-		c.PureJSON(http.StatusNotFound, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "USER_NOT_FOUND",
-				"message": "User profile not found. Please create a profile first.",
-			},
-		})
-		return
-	}
 
 	// Get order ID from URL parameter
 	orderID := c.Param("id")
 	if orderID == "" {
-		c.PureJSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "INVALID_REQUEST",
-				"message": "Order ID is required",
-			},
-		})
+		apierror.Respond(c, apierror.BadRequest("INVALID_REQUEST", "Order ID is required"))
 		return
 	}
 
 	// Fetch the order
 	var order models.Order
 	if err := db.First(&order, orderID).Error; err != nil {
-		c.PureJSON(http.StatusNotFound, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "ORDER_NOT_FOUND",
-				"message": "Order not found",
-			},
-		})
+		apierror.Respond(c, apierror.NotFound("ORDER_NOT_FOUND", "Order not found"))
 		return
 	}
 
 	// Authorization check: Can user view messages on this order?
-	// Customers can view messages on their own orders
-	// Technicians can view messages on orders assigned to them
-	canView := false
-	switch user.Role {
-	case "customer":
-		canView = order.CustomerID == user.ID
-	case "technician":
-		canView = order.TechnicianID != nil && *order.TechnicianID == user.ID
-	}
-
-	if !canView {
-		c.PureJSON(http.StatusForbidden, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "FORBIDDEN",
-				"message": "You do not have permission to view messages on this order",
-			},
-		})
+	// The route only admits customers and technicians; customers can view messages on
+	// their own orders, and technicians on orders assigned to them
+	if order.CustomerID != user.ID && !services.IsAssignedTo(&order, user) {
+		apierror.Respond(c, apierror.Forbidden("FORBIDDEN", "You do not have permission to view messages on this order"))
 		return
 	}
 
-	// Fetch messages for this order
+	// Fetch messages for this order from the read replica; the order itself came from the
+	// primary so that access follows the current assignment
 	var messages []models.Message
-	if err := db.Where("order_id = ?", order.ID).
+	if err := config.GetReplicaDBFor(c.Request.Context()).Where("order_id = ?", order.ID).
 		Preload("Sender").
+		Preload("Annotation").
 		Order("created_at ASC").
 		Find(&messages).Error; err != nil {
-		c.PureJSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "DATABASE_ERROR",
-				"message": "Failed to fetch messages",
-			},
-		})
+		apierror.Respond(c, apierror.Internal("DATABASE_ERROR", "Failed to fetch messages").Wrap(err))
+		return
+	}
+
+	// The listed messages now count as delivered to the user, and their own messages get a delivery status
+	if err := services.GetConversationService().Receive(user, &order, messages); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, messages)
+}
+
+// MarkMessagesSeenRequest represents the request body for marking an order's messages as read
+type MarkMessagesSeenRequest struct {
+	MessageID uint `json:"message_id" binding:"required"`
+}
+
+// MarkMessagesSeen handles PUT /api/v1/orders/:id/messages/seen - records that the user has read the
+// conversation up to a message; the other participants get a message.seen notification
+func MarkMessagesSeen(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
 
-	c.PureJSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    messages,
+	var req MarkMessagesSeenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	state, err := services.GetConversationService().MarkSeen(user, c.Param("id"), req.MessageID)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, state)
+}
+
+// SendTypingIndicator handles POST /api/v1/orders/:id/typing - tells the other participants that the user is typing
+// Clients call it every few seconds while the user types; the indicator lapses when the calls stop
+func SendTypingIndicator(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := services.GetConversationService().Typing(user, c.Param("id")); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.Message(c, http.StatusAccepted, "Typing indicator sent")
+}
+
+// AnnotateImageRequest represents the request body for annotating an order image
+// X and Y are fractions of the image's width and height, measured from its top-left corner
+type AnnotateImageRequest struct {
+	X    *float64 `json:"x" binding:"required"`
+	Y    *float64 `json:"y" binding:"required"`
+	Text string   `json:"text" binding:"required"`
+}
+
+// AnnotateImage handles POST /api/v1/orders/:id/images/:imageId/annotations - posts a message pointing at a spot
+// on the order's design image ("design") or a progress photo (its update ID) (assigned technician only)
+// The message joins the order conversation with its annotation attached
+func AnnotateImage(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req AnnotateImageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	message, err := services.GetImageAnnotationService().Annotate(user, c.Param("id"), c.Param("imageId"), services.AnnotationInput{
+		X:    *req.X,
+		Y:    *req.Y,
+		Text: req.Text,
 	})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.Created(c, message)
 }
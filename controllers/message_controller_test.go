@@ -7,9 +7,11 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/kendall-kelly/kendalls-nails-api/config"
 	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
@@ -22,7 +24,7 @@ func setupMessageTestDB(t *testing.T) *gorm.DB {
 	}
 
 	// Auto-migrate all models
-	if err := db.AutoMigrate(&models.User{}, &models.Order{}, &models.Message{}); err != nil {
+	if err := models.MigrateUp(db); err != nil {
 		t.Fatalf("Failed to migrate test database: %v", err)
 	}
 
@@ -288,21 +290,21 @@ func TestListMessages(t *testing.T) {
 	// Create messages for the order
 	msg1 := models.Message{
 		OrderID:  order.ID,
-		SenderID: customer.ID,
+		SenderID: &customer.ID,
 		Text:     "First message from customer",
 	}
 	db.Create(&msg1)
 
 	msg2 := models.Message{
 		OrderID:  order.ID,
-		SenderID: technician.ID,
+		SenderID: &technician.ID,
 		Text:     "Reply from technician",
 	}
 	db.Create(&msg2)
 
 	msg3 := models.Message{
 		OrderID:  order.ID,
-		SenderID: customer.ID,
+		SenderID: &customer.ID,
 		Text:     "Second message from customer",
 	}
 	db.Create(&msg3)
@@ -444,3 +446,119 @@ func TestListMessages(t *testing.T) {
 		})
 	}
 }
+
+func TestListMessages_StaleOrderExpiryNotice(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+
+	stale := models.Order{Description: "Forgotten", Quantity: 1, Status: "submitted", CustomerID: customer.ID, CreatedAt: time.Now().AddDate(0, 0, -40)}
+	db.Create(&stale)
+	fresh := models.Order{Description: "Recent", Quantity: 1, Status: "submitted", CustomerID: customer.ID}
+	db.Create(&fresh)
+
+	expired, err := services.GetOrderService().ExpireStaleOrders(30 * 24 * time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, expired)
+
+	var expiredOrder, freshOrder models.Order
+	db.First(&expiredOrder, stale.ID)
+	assert.Equal(t, "expired", expiredOrder.Status)
+	db.First(&freshOrder, fresh.ID)
+	assert.Equal(t, "submitted", freshOrder.Status)
+
+	// The customer sees the system notice, which has no sender
+	router := setupTestRouter()
+	router.GET("/orders/:id/messages", mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token"), ListMessages)
+
+	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/orders/%d/messages", stale.ID), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	messages := response["data"].([]interface{})
+	assert.Len(t, messages, 1)
+	notice := messages[0].(map[string]interface{})
+	assert.Nil(t, notice["sender_id"])
+	assert.Nil(t, notice["sender"])
+	assert.Contains(t, notice["text"], "has expired")
+}
+
+func TestAnnotateImage(t *testing.T) {
+	// Setup
+	db := setupMessageTestDB(t)
+	config.SetDB(db)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	technician := models.User{Auth0ID: "auth0|tech", Name: "Tech", Email: "tech@example.com", Role: "technician"}
+	db.Create(&technician)
+	otherTech := models.User{Auth0ID: "auth0|othertech", Name: "Other Tech", Email: "othertech@example.com", Role: "technician"}
+	db.Create(&otherTech)
+
+	imageKey := "uploads/design.png"
+	order := models.Order{Description: "Gems on the ring fingers", Quantity: 1, Status: "accepted", CustomerID: customer.ID, TechnicianID: &technician.ID, ImageS3Key: &imageKey}
+	db.Create(&order)
+	photo := models.ProgressUpdate{OrderID: order.ID, AuthorID: technician.ID, ImageS3Key: "uploads/progress.png"}
+	db.Create(&photo)
+
+	annotate := func(auth0ID, role, imageID string, body map[string]interface{}) *httptest.ResponseRecorder {
+		router := setupTestRouter()
+		router.POST("/orders/:id/images/:imageId/annotations", mockAuthMiddleware(auth0ID, role, "mock-token"), AnnotateImage)
+		payload, _ := json.Marshal(body)
+		req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/orders/%d/images/%s/annotations", order.ID, imageID), bytes.NewBuffer(payload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := annotate(technician.Auth0ID, "technician", "design", map[string]interface{}{"x": 0.42, "y": 0, "text": "Which finger gets this gem?"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, "Which finger gets this gem?", data["text"])
+	annotation := data["annotation"].(map[string]interface{})
+	assert.Equal(t, "design", annotation["image_id"])
+	assert.Equal(t, 0.42, annotation["x"])
+	assert.Equal(t, float64(0), annotation["y"])
+	assert.Nil(t, annotation["image_s3_key"])
+
+	w = annotate(technician.Auth0ID, "technician", fmt.Sprintf("%d", photo.ID), map[string]interface{}{"x": 0.5, "y": 0.5, "text": "Is this shade right?"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	// Coordinates are fractions of the image size and both are required
+	w = annotate(technician.Auth0ID, "technician", "design", map[string]interface{}{"x": 1.5, "y": 0.5, "text": "Off the image"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = annotate(technician.Auth0ID, "technician", "design", map[string]interface{}{"x": 0.5, "text": "No y"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = annotate(technician.Auth0ID, "technician", "999", map[string]interface{}{"x": 0.5, "y": 0.5, "text": "Missing photo"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "IMAGE_NOT_FOUND")
+
+	w = annotate(otherTech.Auth0ID, "technician", "design", map[string]interface{}{"x": 0.5, "y": 0.5, "text": "Not my order"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// The annotated messages are part of the conversation the customer sees
+	router := setupTestRouter()
+	router.GET("/orders/:id/messages", mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token"), ListMessages)
+	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/orders/%d/messages", order.ID), nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	messages := response["data"].([]interface{})
+	assert.Len(t, messages, 2)
+	first := messages[0].(map[string]interface{})["annotation"].(map[string]interface{})
+	assert.Equal(t, "design", first["image_id"])
+	second := messages[1].(map[string]interface{})["annotation"].(map[string]interface{})
+	assert.Equal(t, fmt.Sprintf("%d", photo.ID), second["image_id"])
+}
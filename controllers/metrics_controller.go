@@ -0,0 +1,26 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/metrics"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+)
+
+// metricsContentType is the content type of the Prometheus text exposition format
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Metrics handles GET /metrics - business gauges and counters in the Prometheus text format
+func Metrics(c *gin.Context) {
+	families, err := services.GetMetricsService().BusinessMetrics()
+	if err != nil {
+		apierror.Respond(c, apierror.Internal("METRICS_ERROR", "Failed to collect metrics").Wrap(err))
+		return
+	}
+
+	c.Header("Content-Type", metricsContentType)
+	c.Status(http.StatusOK)
+	_ = metrics.Write(c.Writer, families)
+}
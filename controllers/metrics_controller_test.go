@@ -0,0 +1,40 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	db.Create(&models.Order{Description: "New", Quantity: 1, Status: "submitted", CustomerID: customer.ID})
+	db.Create(&models.Order{Description: "Another", Quantity: 1, Status: "submitted", CustomerID: customer.ID})
+
+	router := setupTestRouter()
+	router.GET("/metrics", Metrics)
+
+	req, _ := http.NewRequest(http.MethodGet, "/metrics", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, metricsContentType, w.Header().Get("Content-Type"))
+	body := w.Body.String()
+	assert.Contains(t, body, "# TYPE kendalls_nails_orders gauge\n")
+	assert.Contains(t, body, "kendalls_nails_orders{status=\"submitted\"} 2\n")
+	assert.Contains(t, body, "kendalls_nails_orders{status=\"delivered\"} 0\n")
+	assert.Contains(t, body, "kendalls_nails_revenue_today{currency=\"USD\"} 0\n")
+	assert.Contains(t, body, "kendalls_nails_webhook_deliveries{status=\"pending\"} 0\n")
+	assert.Contains(t, body, "# TYPE kendalls_nails_order_transitions_total counter\n")
+}
@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/apiresponse"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+)
+
+// ChannelPreferenceRequest turns the email and push notifications of one event type on or off
+type ChannelPreferenceRequest struct {
+	Email *bool `json:"email"`
+	Push  *bool `json:"push"`
+}
+
+// NotificationPreferencesRequest represents the request body for updating notification preferences
+// Event types and channels left out keep their current setting
+type NotificationPreferencesRequest struct {
+	Message      *ChannelPreferenceRequest `json:"message"`
+	StatusChange *ChannelPreferenceRequest `json:"status_change"`
+	Promo        *ChannelPreferenceRequest `json:"promo"`
+}
+
+// PollNotifications handles GET /api/v1/notifications/poll - waits for new messages and status changes
+// The response comes as soon as the user has notifications after the since cursor, or empty once the wait is over.
+// Clients poll again from the returned cursor; the optional timeout query parameter is the wait in seconds (at most 30)
+func PollNotifications(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var wait time.Duration
+	if timeoutStr := c.Query("timeout"); timeoutStr != "" {
+		seconds, err := strconv.Atoi(timeoutStr)
+		if err != nil || seconds <= 0 {
+			apierror.Respond(c, apierror.Validation("Timeout must be a positive number of seconds", nil))
+			return
+		}
+		wait = time.Duration(seconds) * time.Second
+	}
+
+	poll, err := services.GetNotificationService().Poll(c.Request.Context(), user, c.Query("since"), wait)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	// The new messages have reached the user's client; their senders learn that from delivery receipts
+	if err := services.GetConversationService().MarkDelivered(user, poll.Notifications); err != nil {
+		log.Printf("Failed to record message delivery to user %d: %v", user.ID, err)
+	}
+
+	apiresponse.OK(c, poll)
+}
+
+// GetNotificationPreferences handles GET /api/v1/users/me/notification-preferences - returns the user's email and push settings
+func GetNotificationPreferences(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	preferences, err := services.GetNotificationService().GetPreferences(user)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, preferences)
+}
+
+// UpdateNotificationPreferences handles PUT /api/v1/users/me/notification-preferences - toggles email and push per event type
+func UpdateNotificationPreferences(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req NotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	preferences, err := services.GetNotificationService().UpdatePreferences(user, services.NotificationPreferencesInput{
+		Message:      req.Message.input(),
+		StatusChange: req.StatusChange.input(),
+		Promo:        req.Promo.input(),
+	})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, preferences)
+}
+
+// input converts the request to the service input, keeping nil as "unchanged"
+func (r *ChannelPreferenceRequest) input() *services.ChannelPreferenceInput {
+	if r == nil {
+		return nil
+	}
+	return &services.ChannelPreferenceInput{Email: r.Email, Push: r.Push}
+}
@@ -0,0 +1,211 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/events"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPollNotifications(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+	bus := events.NewBus(nil)
+	services.SubscribeNotifications(bus, services.GetNotificationService())
+	services.SetEventBus(bus)
+	defer services.SetEventBus(nil)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	technician := models.User{Auth0ID: "auth0|tech", Name: "Technician User", Email: "tech@example.com", Role: "technician"}
+	db.Create(&technician)
+	order := models.Order{Description: "Chrome coffin", Quantity: 1, Status: "accepted", CustomerID: customer.ID, TechnicianID: &technician.ID}
+	db.Create(&order)
+
+	router := setupTestRouter()
+	router.POST("/api/v1/orders/:id/messages", mockAuthMiddleware(technician.Auth0ID, "technician", "mock-token"), SendMessage)
+	router.GET("/api/v1/notifications/poll", mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token"), PollNotifications)
+
+	request := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewReader(data))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request(http.MethodPost, fmt.Sprintf("/api/v1/orders/%d/messages", order.ID), map[string]string{"text": "Your set is ready for a fitting"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	// The technician's message is waiting for the customer
+	w = request(http.MethodGet, "/api/v1/notifications/poll?since=0", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data services.NotificationPoll `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if assert.Len(t, response.Data.Notifications, 1) {
+		assert.Equal(t, models.NotificationMessageCreated, response.Data.Notifications[0].Event)
+		assert.Equal(t, order.ID, response.Data.Notifications[0].OrderID)
+	}
+
+	// Nothing new arrives before the timeout
+	w = request(http.MethodGet, fmt.Sprintf("/api/v1/notifications/poll?since=%d&timeout=1", response.Data.Cursor), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, fmt.Sprintf(`{"success":true,"data":{"notifications":[],"cursor":%d}}`, response.Data.Cursor), w.Body.String())
+
+	w = request(http.MethodGet, "/api/v1/notifications/poll?timeout=soon", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = request(http.MethodGet, "/api/v1/notifications/poll?since=abc", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestNotificationPreferences(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+
+	router := setupTestRouter()
+	auth := mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token")
+	router.GET("/api/v1/users/me/notification-preferences", auth, GetNotificationPreferences)
+	router.PUT("/api/v1/users/me/notification-preferences", auth, UpdateNotificationPreferences)
+
+	request := func(method string, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, "/api/v1/users/me/notification-preferences", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	preferences := func(w *httptest.ResponseRecorder) map[string]interface{} {
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		data := response["data"].(map[string]interface{})
+		delete(data, "updated_at")
+		return data
+	}
+
+	w := request(http.MethodGet, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, map[string]interface{}{
+		"message":       map[string]interface{}{"email": true, "push": true},
+		"status_change": map[string]interface{}{"email": true, "push": true},
+		"promo":         map[string]interface{}{"email": false, "push": false},
+	}, preferences(w))
+
+	// Only the settings sent change, and they are kept
+	w = request(http.MethodPut, `{"status_change": {"push": false}, "promo": {"email": true}}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = request(http.MethodGet, "")
+	assert.Equal(t, map[string]interface{}{
+		"message":       map[string]interface{}{"email": true, "push": true},
+		"status_change": map[string]interface{}{"email": true, "push": false},
+		"promo":         map[string]interface{}{"email": true, "push": false},
+	}, preferences(w))
+
+	// Invalid inputs are reported field by field
+	w = request(http.MethodPut, `{"message": {"email": "yes"}}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"success":false,"error":{"code":"VALIDATION_ERROR","message":"Invalid request data","details":[
+		{"field":"message.email","rule":"type","message":"must be a boolean"}
+	]}}`, w.Body.String())
+}
+
+func TestMessageReceiptsAndTyping(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+	bus := events.NewBus(nil)
+	services.SubscribeNotifications(bus, services.GetNotificationService())
+	services.SetEventBus(bus)
+	defer services.SetEventBus(nil)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	technician := models.User{Auth0ID: "auth0|tech", Name: "Technician User", Email: "tech@example.com", Role: "technician"}
+	db.Create(&technician)
+	order := models.Order{Description: "Chrome coffin", Quantity: 1, Status: "accepted", CustomerID: customer.ID, TechnicianID: &technician.ID}
+	db.Create(&order)
+
+	request := func(user models.User, method, path string, body interface{}) *httptest.ResponseRecorder {
+		router := setupTestRouter()
+		auth := mockAuthMiddleware(user.Auth0ID, user.Role, "mock-token")
+		router.POST("/api/v1/orders/:id/messages", auth, SendMessage)
+		router.GET("/api/v1/orders/:id/messages", auth, ListMessages)
+		router.PUT("/api/v1/orders/:id/messages/seen", auth, MarkMessagesSeen)
+		router.POST("/api/v1/orders/:id/typing", auth, SendTypingIndicator)
+		router.GET("/api/v1/notifications/poll", auth, PollNotifications)
+
+		data, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewReader(data))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	messagesPath := fmt.Sprintf("/api/v1/orders/%d/messages", order.ID)
+	statusOfFirst := func() string {
+		w := request(technician, http.MethodGet, messagesPath, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			Data []models.Message `json:"data"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		if !assert.NotEmpty(t, response.Data) {
+			return ""
+		}
+		return response.Data[0].Status
+	}
+
+	w := request(technician, http.MethodPost, messagesPath, map[string]string{"text": "Your set is ready for a fitting"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var created struct {
+		Data models.Message `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &created)
+	assert.Equal(t, models.MessageStatusSent, statusOfFirst())
+
+	// The customer's poll hands the message over, and then they read it
+	w = request(customer, http.MethodGet, "/api/v1/notifications/poll?since=0", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, models.MessageStatusDelivered, statusOfFirst())
+
+	w = request(customer, http.MethodPut, messagesPath+"/seen", map[string]uint{"message_id": created.Data.ID})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), fmt.Sprintf(`"last_seen_message_id":%d`, created.Data.ID))
+	assert.Equal(t, models.MessageStatusSeen, statusOfFirst())
+
+	w = request(customer, http.MethodPut, messagesPath+"/seen", map[string]uint{"message_id": 999})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// The customer starts typing a reply; the technician polls the receipts and the indicator
+	w = request(customer, http.MethodPost, fmt.Sprintf("/api/v1/orders/%d/typing", order.ID), nil)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	w = request(technician, http.MethodGet, "/api/v1/notifications/poll?since=0", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var poll struct {
+		Data services.NotificationPoll `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &poll)
+	var received []string
+	for _, notification := range poll.Data.Notifications {
+		received = append(received, notification.Event)
+		assert.Equal(t, &customer.ID, notification.ActorID)
+	}
+	assert.Equal(t, []string{models.NotificationMessageDelivered, models.NotificationMessageSeen, models.NotificationTyping}, received)
+}
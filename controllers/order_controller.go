@@ -1,249 +1,448 @@
 package controllers
 
 import (
+	"context"
+	"fmt"
+	"log"
+	"mime/multipart"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/apiresponse"
+	"github.com/kendall-kelly/kendalls-nails-api/apiversion"
 	"github.com/kendall-kelly/kendalls-nails-api/config"
-	"github.com/kendall-kelly/kendalls-nails-api/middleware"
 	"github.com/kendall-kelly/kendalls-nails-api/models"
 	"github.com/kendall-kelly/kendalls-nails-api/services"
 	"github.com/kendall-kelly/kendalls-nails-api/utils"
+	"gorm.io/gorm"
 )
 
 // CreateOrderRequest represents the request body for creating an order
 type CreateOrderRequest struct {
-	Description string `json:"description" binding:"required"`
-	Quantity    int    `json:"quantity" binding:"required,gt=0"`
+	Description       string `json:"description" binding:"required_without_all=CatalogDesignID DesignID"`
+	Quantity          int    `json:"quantity" binding:"required,gt=0"`
+	Rush              bool   `json:"rush"`
+	RequestedBy       string `json:"requested_by"`                              // optional, YYYY-MM-DD
+	CatalogDesignID   *uint  `json:"catalog_design_id"`                         // optional, pre-fills description and price from the catalog
+	DesignID          *uint  `json:"design_id"`                                 // optional, pre-fills description and image from a saved design
+	ShippingAddressID *uint  `json:"shipping_address_id"`                       // optional here, but required before the order can be accepted
+	UploadID          *uint  `json:"upload_id"`                                 // optional, the design image from a completed chunked upload
+	RecipientEmail    string `json:"recipient_email" binding:"omitempty,email"` // optional, makes the order a gift for this person
+	RecipientName     string `json:"recipient_name" binding:"max=100"`
+	GiftMessage       string `json:"gift_message" binding:"max=500"`
+}
+
+// SetShippingAddressRequest represents the request body for choosing an order's shipping address
+type SetShippingAddressRequest struct {
+	ShippingAddressID uint `json:"shipping_address_id" binding:"required,gt=0"`
 }
 
 // populateOrderImageURL generates presigned URLs for images
+// An image held for moderation gets its status instead of a URL, as does one still being moved to storage
 func populateOrderImageURL(order *models.Order) {
 	if order.ImageS3Key == nil || *order.ImageS3Key == "" {
+		setUploadingStatus(order, uploadingImages([]uint{order.ID}))
 		return
 	}
-
-	imageService := services.GetImageService()
-	if url, err := imageService.GetImageURL(*order.ImageS3Key); err == nil {
-		order.ImageURL = &url
-	}
+	setOrderImageURL(order, heldImages([]string{*order.ImageS3Key}))
 }
 
 // populateOrdersImageURLs populates image URLs for a slice of orders
 func populateOrdersImageURLs(orders []models.Order) {
+	var keys []string
+	var withoutImage []uint
+	for i := range orders {
+		if orders[i].ImageS3Key != nil && *orders[i].ImageS3Key != "" {
+			keys = append(keys, *orders[i].ImageS3Key)
+		} else {
+			withoutImage = append(withoutImage, orders[i].ID)
+		}
+	}
+
+	uploading := uploadingImages(withoutImage)
+	var held map[string]string
+	if len(keys) > 0 {
+		held = heldImages(keys)
+	}
 	for i := range orders {
-		populateOrderImageURL(&orders[i])
+		if orders[i].ImageS3Key != nil && *orders[i].ImageS3Key != "" {
+			setOrderImageURL(&orders[i], held)
+		} else {
+			setUploadingStatus(&orders[i], uploading)
+		}
 	}
 }
 
-// CreateOrder handles POST /api/v1/orders - creates a new order (customers only)
-func CreateOrder(c *gin.Context) {
-	// Extract Auth0 user ID from JWT token
-	auth0ID, err := middleware.GetUserID(c)
+// setUploadingStatus marks an order without an image as having one uploading, when it does
+func setUploadingStatus(order *models.Order, uploading map[uint]bool) {
+	if uploading[order.ID] {
+		status := models.ImageUploading
+		order.ImageStatus = &status
+	}
+}
+
+// uploadingImages returns which of the given orders have an image still being moved to storage
+// Nothing is looked up unless images are moved in the background; lookup failures are logged
+func uploadingImages(orderIDs []uint) map[uint]bool {
+	transfers := services.GetImageTransferService()
+	if transfers == nil || len(orderIDs) == 0 {
+		return nil
+	}
+	uploading, err := transfers.UploadingOrders(orderIDs)
 	if err != nil {
-		c.PureJSON(http.StatusUnauthorized, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "UNAUTHORIZED",
-				"message": "Could not extract user information",
-			},
-		})
+		log.Printf("Failed to check image transfers: %v", err)
+		return nil
+	}
+	return uploading
+}
+
+// setOrderImageURL sets an order's image URL, or its moderation status when the image is held
+func setOrderImageURL(order *models.Order, held map[string]string) {
+	if status, ok := held[*order.ImageS3Key]; ok {
+		order.ImageStatus = &status
 		return
 	}
+	if url, err := services.GetImageService().GetImageURL(*order.ImageS3Key); err == nil {
+		order.ImageURL = &url
+	}
+}
+
+// heldImages returns the moderation status of each given image that may not be shown yet
+// If moderation cannot be checked, every image is treated as pending review
+func heldImages(imageKeys []string) map[string]string {
+	held, err := services.GetImageModerationService().HeldImages(imageKeys)
+	if err != nil {
+		log.Printf("Failed to check image moderation: %v", err)
+		held = make(map[string]string, len(imageKeys))
+		for _, key := range imageKeys {
+			held[key] = models.ImagePendingReview
+		}
+	}
+	return held
+}
 
-	// Find the user in the database
-	db := config.GetDB()
-	var user models.User
-	if err := db.Where("auth0_id = ?", auth0ID).First(&user).Error; err != nil {
-		c.PureJSON(http.StatusNotFound, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "USER_NOT_FOUND",
-				"message": "User profile not found. Please create a profile first.",
-			},
-		})
+// uploadImage stores an uploaded image and returns its key
+// On failure the error response has been written and ok is false
+func uploadImage(c *gin.Context, fileHeader *multipart.FileHeader) (string, bool) {
+	imageKey, err := services.GetImageServiceFor(c.Request.Context()).UploadImage(fileHeader)
+	if err != nil {
+		// Check if it's a validation error
+		if fileErr, ok := err.(*utils.FileUploadError); ok {
+			apierror.Respond(c, apierror.BadRequest(fileErr.Code, fileErr.Message))
+			return "", false
+		}
+		// Generic upload error
+		apierror.Respond(c, apierror.Internal("IMAGE_UPLOAD_ERROR", "Failed to upload image").Wrap(err))
+		return "", false
+	}
+	return imageKey, true
+}
+
+// uploadDesignImage stores a customer's design image and screens it before technicians can see it
+// On failure the error response has been written, the upload removed, and ok is false
+func uploadDesignImage(c *gin.Context, fileHeader *multipart.FileHeader, uploaderID *uint) (string, bool) {
+	imageKey, ok := uploadImage(c, fileHeader)
+	if !ok {
+		return "", false
+	}
+	if _, err := services.GetImageModerationService().ScreenImage(uploaderID, imageKey, fileHeader); err != nil {
+		if deleteErr := services.GetImageServiceFor(c.Request.Context()).DeleteImage(imageKey); deleteErr != nil {
+			log.Printf("Failed to delete unscreened image %s: %v", imageKey, deleteErr)
+		}
+		apierror.Respond(c, err)
+		return "", false
+	}
+	return imageKey, true
+}
+
+// stageDesignImage stages a customer's design image to be moved to storage in the background, and screens it
+// On failure the error response has been written, the staged image discarded, and ok is false
+func stageDesignImage(c *gin.Context, transfers services.ImageTransferService, fileHeader *multipart.FileHeader, uploaderID *uint) (*models.ImageTransfer, bool) {
+	staged, err := transfers.Stage(fileHeader)
+	if err != nil {
+		apierror.Respond(c, err)
+		return nil, false
+	}
+	if _, err := services.GetImageModerationService().ScreenImage(uploaderID, staged.Key, fileHeader); err != nil {
+		transfers.Discard(staged)
+		apierror.Respond(c, err)
+		return nil, false
+	}
+	return staged, true
+}
+
+// parseDueDate parses an optional YYYY-MM-DD date field
+// On failure the error response has been written and ok is false
+func parseDueDate(c *gin.Context, field, value string) (*time.Time, bool) {
+	if value == "" {
+		return nil, true
+	}
+	parsed, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		apierror.Respond(c, apierror.Validation("Invalid due date", map[string]string{
+			field: "must be a date such as 2026-01-31",
+		}))
+		return nil, false
+	}
+	return &parsed, true
+}
+
+// CreateOrder handles POST /api/v1/orders - creates a new order (customers only)
+// An order with a recipient_email is a gift; the response carries a gift_claim_code when the recipient has no account yet
+func CreateOrder(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
 
-	// Check if user is a customer (only customers can create orders)
-	if user.Role != "customer" {
-		c.PureJSON(http.StatusForbidden, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "FORBIDDEN",
-				"message": "Only customers can create orders",
-			},
-		})
+	// The route only admits customers; terms acceptance is checked before parsing
+	// so that no image is uploaded for a refused request
+	orderService := services.GetOrderServiceFor(c.Request.Context())
+	if err := orderService.AuthorizeCreate(user); err != nil {
+		apierror.Respond(c, err)
 		return
 	}
 
 	// Check content type to determine if this is multipart form data or JSON
 	contentType := c.ContentType()
-	var description string
-	var quantity int
-	var imagePath *string
+	var input services.CreateOrderInput
+
+	// With background uploads an attached image is staged rather than stored during the request
+	transfers := services.GetImageTransferServiceFor(c.Request.Context())
+	var staged *models.ImageTransfer
 
 	if contentType == "application/json" {
 		// Parse JSON request (legacy support, no file upload)
 		var req CreateOrderRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
-			c.PureJSON(http.StatusBadRequest, gin.H{
-				"success": false,
-				"error": gin.H{
-					"code":    "VALIDATION_ERROR",
-					"message": "Invalid request data",
-					"details": err.Error(),
-				},
-			})
+			respondValidationError(c, err)
+			return
+		}
+		if input, ok = createOrderInput(c, user, req, ""); !ok {
 			return
 		}
-		description = req.Description
-		quantity = req.Quantity
 	} else {
 		// Parse multipart form data (with potential file upload)
-		description = c.PostForm("description")
+		input.Description = c.PostForm("description")
 		quantityStr := c.PostForm("quantity")
 
+		// Parse optional catalog design, which stands in for the description
+		if designStr := c.PostForm("catalog_design_id"); designStr != "" {
+			designID, err := strconv.ParseUint(designStr, 10, 64)
+			if err != nil || designID == 0 {
+				apierror.Respond(c, apierror.Validation("Catalog design ID must be a positive integer", nil))
+				return
+			}
+			id := uint(designID)
+			input.CatalogDesignID = &id
+		}
+
+		// Parse optional saved design, which stands in for the description and image
+		if designStr := c.PostForm("design_id"); designStr != "" {
+			designID, err := strconv.ParseUint(designStr, 10, 64)
+			if err != nil || designID == 0 {
+				apierror.Respond(c, apierror.Validation("Design ID must be a positive integer", nil))
+				return
+			}
+			id := uint(designID)
+			input.SavedDesignID = &id
+		}
+
+		// Parse optional shipping address from the customer's address book
+		if addressStr := c.PostForm("shipping_address_id"); addressStr != "" {
+			addressID, err := strconv.ParseUint(addressStr, 10, 64)
+			if err != nil || addressID == 0 {
+				apierror.Respond(c, apierror.Validation("Shipping address ID must be a positive integer", nil))
+				return
+			}
+			id := uint(addressID)
+			input.ShippingAddressID = &id
+		}
+
 		// Validate required fields
-		if description == "" {
-			c.PureJSON(http.StatusBadRequest, gin.H{
-				"success": false,
-				"error": gin.H{
-					"code":    "VALIDATION_ERROR",
-					"message": "Description is required",
-				},
-			})
+		if input.Description == "" && input.CatalogDesignID == nil && input.SavedDesignID == nil {
+			apierror.Respond(c, apierror.Validation("Description is required", nil))
 			return
 		}
 
 		if quantityStr == "" {
-			c.PureJSON(http.StatusBadRequest, gin.H{
-				"success": false,
-				"error": gin.H{
-					"code":    "VALIDATION_ERROR",
-					"message": "Quantity is required",
-				},
-			})
+			apierror.Respond(c, apierror.Validation("Quantity is required", nil))
 			return
 		}
 
 		// Parse quantity
 		parsedQuantity, err := strconv.Atoi(quantityStr)
 		if err != nil || parsedQuantity <= 0 {
-			c.PureJSON(http.StatusBadRequest, gin.H{
-				"success": false,
-				"error": gin.H{
-					"code":    "VALIDATION_ERROR",
-					"message": "Quantity must be a positive integer",
-				},
-			})
+			apierror.Respond(c, apierror.Validation("Quantity must be a positive integer", nil))
+			return
+		}
+		input.Quantity = parsedQuantity
+
+		// Parse optional rush flag
+		if rushStr := c.PostForm("rush"); rushStr != "" {
+			rush, err := strconv.ParseBool(rushStr)
+			if err != nil {
+				apierror.Respond(c, apierror.Validation("Rush must be true or false", nil))
+				return
+			}
+			input.Rush = rush
+		}
+
+		// Parse optional requested date
+		if input.RequestedBy, ok = parseDueDate(c, "requested_by", c.PostForm("requested_by")); !ok {
 			return
 		}
-		quantity = parsedQuantity
 
-		// Handle file upload if present
+		// Parse optional gift recipient
+		input.RecipientEmail = c.PostForm("recipient_email")
+		input.RecipientName = c.PostForm("recipient_name")
+		input.GiftMessage = c.PostForm("gift_message")
+
+		// Handle file upload if present, or an image uploaded earlier in chunks
+		uploadID, ok := parseUploadID(c)
+		if !ok {
+			return
+		}
 		fileHeader, err := c.FormFile("image")
-		if err == nil {
+		if err == nil && uploadID != nil {
+			apierror.Respond(c, apierror.Validation("Send either an image or an upload ID, not both", nil))
+			return
+		}
+		if err == nil && transfers != nil {
+			// File was provided; it is staged now and moved to storage once the order exists
+			if staged, ok = stageDesignImage(c, transfers, fileHeader, &user.ID); !ok {
+				return
+			}
+		} else if err == nil {
 			// File was provided, upload it using image service
-			imageService := services.GetImageService()
-			imageKey, uploadErr := imageService.UploadImage(fileHeader)
-			if uploadErr != nil {
-				// Check if it's a validation error
-				if fileErr, ok := uploadErr.(*utils.FileUploadError); ok {
-					c.PureJSON(http.StatusBadRequest, gin.H{
-						"success": false,
-						"error": gin.H{
-							"code":    fileErr.Code,
-							"message": fileErr.Message,
-						},
-					})
-					return
-				}
-				// Generic upload error
-				c.PureJSON(http.StatusInternalServerError, gin.H{
-					"success": false,
-					"error": gin.H{
-						"code":    "IMAGE_UPLOAD_ERROR",
-						"message": "Failed to upload image",
-					},
-				})
+			imageKey, ok := uploadDesignImage(c, fileHeader, &user.ID)
+			if !ok {
 				return
 			}
-			imagePath = &imageKey
+			input.ImageS3Key = &imageKey
+		} else if uploadID != nil {
+			imageKey, ok := claimUploadedImage(c, user, *uploadID)
+			if !ok {
+				return
+			}
+			input.ImageS3Key = &imageKey
 		}
-		// If err != nil, no file was provided, which is okay (image is optional)
-	}
-
-	// Create the order
-	order := models.Order{
-		Description: description,
-		Quantity:    quantity,
-		Status:      "submitted",
-		CustomerID:  user.ID,
-		ImageS3Key:  imagePath, // Store S3 key if image was uploaded
-	}
-
-	if err := db.Create(&order).Error; err != nil {
-		c.PureJSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "DATABASE_ERROR",
-				"message": "Failed to create order",
-			},
-		})
-		return
+		// Otherwise no image was provided, which is okay (image is optional)
 	}
 
-	// Load the customer relationship to return complete data
-	if err := db.Preload("Customer").First(&order, order.ID).Error; err != nil {
-		c.PureJSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "DATABASE_ERROR",
-				"message": "Failed to load order details",
-			},
-		})
+	order, err := orderService.CreateOrder(user, input)
+	if err != nil {
+		if staged != nil {
+			transfers.Discard(staged)
+		}
+		apierror.Respond(c, err)
 		return
 	}
+	if staged != nil {
+		if err := transfers.AttachOrder(staged, order.ID); err != nil {
+			transfers.Discard(staged)
+			apierror.Respond(c, err)
+			return
+		}
+	}
 
 	// Generate presigned URL for image if using S3
-	populateOrderImageURL(&order)
+	populateOrderImageURL(order)
 
-	c.PureJSON(http.StatusCreated, gin.H{
-		"success": true,
-		"data":    order,
-	})
+	apiresponse.Created(c, order)
+}
+
+// createOrderInput converts a JSON order request into service input
+// prefix is put before field names in error details, such as "orders[2]." for an entry of a batch
+// On failure the error response has been written and ok is false
+func createOrderInput(c *gin.Context, user *models.User, req CreateOrderRequest, prefix string) (services.CreateOrderInput, bool) {
+	input := services.CreateOrderInput{
+		Description:       req.Description,
+		Quantity:          req.Quantity,
+		Rush:              req.Rush,
+		CatalogDesignID:   req.CatalogDesignID,
+		SavedDesignID:     req.DesignID,
+		ShippingAddressID: req.ShippingAddressID,
+		RecipientEmail:    req.RecipientEmail,
+		RecipientName:     req.RecipientName,
+		GiftMessage:       req.GiftMessage,
+	}
+	var ok bool
+	if input.RequestedBy, ok = parseDueDate(c, prefix+"requested_by", req.RequestedBy); !ok {
+		return input, false
+	}
+	if req.UploadID != nil {
+		imageKey, ok := claimUploadedImage(c, user, *req.UploadID)
+		if !ok {
+			return input, false
+		}
+		input.ImageS3Key = &imageKey
+	}
+	return input, true
+}
+
+// CreateOrderBatchRequest represents the request body for creating several orders at once
+type CreateOrderBatchRequest struct {
+	Orders []CreateOrderRequest `json:"orders" binding:"required,min=1,max=25,dive"`
+}
+
+// CreateOrderBatch handles POST /api/v1/orders/batch - creates several orders in one request (customers only)
+// The batch is all or nothing: when any order is invalid none are created, and the error names its index
+func CreateOrderBatch(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	// The route only admits customers; terms acceptance is checked before uploads are claimed
+	orderService := services.GetOrderServiceFor(c.Request.Context())
+	if err := orderService.AuthorizeCreate(user); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	var req CreateOrderBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	inputs := make([]services.CreateOrderInput, len(req.Orders))
+	for i, orderReq := range req.Orders {
+		if inputs[i], ok = createOrderInput(c, user, orderReq, fmt.Sprintf("orders[%d].", i)); !ok {
+			return
+		}
+	}
+
+	orders, err := orderService.CreateOrders(user, inputs)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	populateOrdersImageURLs(orders)
+
+	apiresponse.Created(c, orders)
 }
 
 // ListOrders handles GET /api/v1/orders - lists orders with role-based filtering
 // Customers see only their orders
 // Technicians see orders assigned to them + unassigned orders
+// status filters by comma-separated statuses, and view applies one of the user's saved views
+// cursor switches from page numbers to keyset pagination: send it empty for the first page, then the
+// next_cursor of the previous page; cursor pages are sorted by created_at and carry no total
+// fields and include trim each order, see orderProjection; relations left out are not loaded at all
 func ListOrders(c *gin.Context) {
-	// Extract Auth0 user ID from JWT token
-	auth0ID, err := middleware.GetUserID(c)
-	if err != nil {
-		c.PureJSON(http.StatusUnauthorized, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "UNAUTHORIZED",
-				"message": "Could not extract user information",
-			},
-		})
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
-
-	// Find the user in the database
-	db := config.GetDB()
-	var user models.User
-	if err := db.Where("auth0_id = ?", auth0ID).First(&user).Error; err != nil {
-		c.PureJSON(http.StatusNotFound, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "USER_NOT_FOUND",
-				"message": "User profile not found. Please create a profile first.",
-			},
-		})
+	projection, ok := parseOrderProjection(c)
+	if !ok {
 		return
 	}
 
@@ -260,329 +459,190 @@ func ListOrders(c *gin.Context) {
 			limit = l
 		}
 	}
-	offset := (page - 1) * limit
 
-	// Build query based on user role
-	query := db.Model(&models.Order{})
+	// Sort column and direction are checked against the whitelist by the service
+	opts := services.ListOrdersOptions{
+		Page:  page,
+		Limit: limit,
+		Sort:  c.Query("sort"),
+		Order: strings.ToLower(c.Query("order")),
+	}
+	opts.Relations = projection.preload()
+
+	// Parse optional date-range filters (RFC 3339)
+	for param, target := range map[string]**time.Time{
+		"created_after":  &opts.CreatedAfter,
+		"created_before": &opts.CreatedBefore,
+		"updated_after":  &opts.UpdatedAfter,
+	} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			apierror.Respond(c, apierror.Validation("Invalid date filter", map[string]string{
+				param: "must be an RFC 3339 timestamp such as 2026-01-31T00:00:00Z",
+			}))
+			return
+		}
+		parsed = parsed.UTC()
+		*target = &parsed
+	}
 
-	switch user.Role {
-	case "customer":
-		// Customers see only their own orders
-		query = query.Where("customer_id = ?", user.ID)
-	case "technician":
-		// Technicians see orders assigned to them + unassigned orders
-		query = query.Where("technician_id = ? OR technician_id IS NULL", user.ID)
+	// Comma-separated statuses, checked against the known statuses by the service
+	for _, status := range strings.Split(c.Query("status"), ",") {
+		if status = strings.TrimSpace(status); status != "" {
+			opts.Statuses = append(opts.Statuses, status)
+		}
 	}
 
-	// Get total count for pagination info
+	// A saved view fills in whatever the query did not set
+	if viewID := c.Query("view"); viewID != "" {
+		view, err := services.GetOrderViewService().GetView(user, viewID)
+		if err != nil {
+			apierror.Respond(c, err)
+			return
+		}
+		opts = services.ApplyOrderView(view, opts, time.Now())
+	}
+
+	var orders []models.Order
+	var next string
 	var total int64
-	if err := query.Count(&total).Error; err != nil {
-		c.PureJSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "DATABASE_ERROR",
-				"message": "Failed to count orders",
-			},
-		})
+	var err error
+	cursor, byCursor := c.GetQuery("cursor")
+	if byCursor {
+		opts.Cursor = cursor
+		orders, next, err = services.GetOrderServiceFor(c.Request.Context()).ListOrdersByCursor(user, opts)
+	} else {
+		orders, total, err = services.GetOrderServiceFor(c.Request.Context()).ListOrders(user, opts)
+	}
+	if err != nil {
+		apierror.Respond(c, err)
 		return
 	}
 
-	// Fetch orders with pagination
-	var orders []models.Order
-	if err := query.Preload("Customer").Preload("Technician").
-		Order("created_at DESC").
-		Limit(limit).
-		Offset(offset).
-		Find(&orders).Error; err != nil {
-		c.PureJSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "DATABASE_ERROR",
-				"message": "Failed to fetch orders",
-			},
-		})
+	// The body is built again once image URLs are populated, since the ETag must not depend on them
+	body := func() interface{} {
+		if byCursor {
+			return apiresponse.CursorPaginated(projection.orders(orders), limit, next)
+		}
+		return apiresponse.Paginated(projection.orders(orders), apiresponse.NewPagination(page, limit, total))
+	}
+	if notModified(c, body(), ordersHaveImages(orders...)) {
 		return
 	}
 
 	// Generate image URLs for all orders
 	populateOrdersImageURLs(orders)
 
-	c.PureJSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    orders,
-		"pagination": gin.H{
-			"page":       page,
-			"limit":      limit,
-			"total":      total,
-			"totalPages": (total + int64(limit) - 1) / int64(limit),
-		},
-	})
+	c.PureJSON(http.StatusOK, body())
 }
 
 // GetOrder handles GET /api/v1/orders/:id - gets a single order with authorization
+// fields and include trim the order, see orderProjection
 func GetOrder(c *gin.Context) {
-	// Extract Auth0 user ID from JWT token
-	auth0ID, err := middleware.GetUserID(c)
-	if err != nil {
-		c.PureJSON(http.StatusUnauthorized, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "UNAUTHORIZED",
-				"message": "Could not extract user information",
-			},
-		})
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	projection, ok := parseOrderProjection(c)
+	if !ok {
 		return
 	}
 
-	// Find the user in the database
-	db := config.GetDB()
-	var user models.User
-	if err := db.Where("auth0_id = ?", auth0ID).First(&user).Error; err != nil {
-		c.PureJSON(http.StatusNotFound, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "USER_NOT_FOUND",
-				"message": "User profile not found. Please create a profile first.",
-			},
-		})
+	order, err := services.GetOrderService().GetOrder(user, c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, err)
 		return
 	}
 
-	// Get order ID from URL parameter
-	orderID := c.Param("id")
-	if orderID == "" {
-		c.PureJSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "INVALID_REQUEST",
-				"message": "Order ID is required",
-			},
-		})
+	if orderNotModified(c, order, apiresponse.Success(projection.order(order))) {
 		return
 	}
 
-	// Fetch the order
-	var order models.Order
-	if err := db.Preload("Customer").Preload("Technician").First(&order, orderID).Error; err != nil {
-		c.PureJSON(http.StatusNotFound, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "ORDER_NOT_FOUND",
-				"message": "Order not found",
-			},
-		})
+	// Generate image URL
+	populateOrderImageURL(order)
+
+	apiresponse.OK(c, projection.order(order))
+}
+
+// ListOverdueOrders handles GET /api/v1/orders/overdue - lists the technician's orders past their due date
+func ListOverdueOrders(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
 
-	// Authorization check: Can user access this order?
-	canAccess := false
-	switch user.Role {
-	case "customer":
-		// Customers can only access their own orders
-		canAccess = order.CustomerID == user.ID
-	case "technician":
-		// Technicians can access orders assigned to them or unassigned orders
-		canAccess = order.TechnicianID == nil || (order.TechnicianID != nil && *order.TechnicianID == user.ID)
-	}
-
-	if !canAccess {
-		c.PureJSON(http.StatusForbidden, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "FORBIDDEN",
-				"message": "You do not have permission to access this order",
-			},
-		})
+	orders, err := services.GetOrderService().ListOverdueOrders(user)
+	if err != nil {
+		apierror.Respond(c, err)
 		return
 	}
 
-	// Generate image URL
-	populateOrderImageURL(&order)
+	// Generate image URLs for all orders
+	populateOrdersImageURLs(orders)
 
-	c.PureJSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    order,
-	})
+	apiresponse.OK(c, orders)
 }
 
 // ReviewOrderRequest represents the request body for reviewing an order
+// When accepting, price is the base set price and add-ons and rush fee are itemized on top
 type ReviewOrderRequest struct {
-	Action   string   `json:"action" binding:"required,oneof=accept reject"`
-	Price    *float64 `json:"price"`
-	Feedback *string  `json:"feedback"`
+	Action     string                `json:"action" binding:"required,oneof=accept reject"`
+	Price      *float64              `json:"price"`
+	AddOns     []AddOnSelectionInput `json:"add_ons" binding:"omitempty,dive"`
+	RushFee    *float64              `json:"rush_fee"`
+	PromisedBy string                `json:"promised_by"` // optional when accepting, YYYY-MM-DD
+	Feedback   *string               `json:"feedback"`
+}
+
+// AddOnSelectionInput represents a catalog add-on included in a quote
+type AddOnSelectionInput struct {
+	AddOnID  uint `json:"add_on_id" binding:"required"`
+	Quantity int  `json:"quantity" binding:"omitempty,gt=0"`
 }
 
 // ReviewOrder handles PUT /api/v1/orders/:id/review - accepts or rejects an order (technicians only)
+// If-Match must carry the order's ETag; see respondOrderWrite
 func ReviewOrder(c *gin.Context) {
-	// Extract Auth0 user ID from JWT token
-	auth0ID, err := middleware.GetUserID(c)
-	if err != nil {
-		c.PureJSON(http.StatusUnauthorized, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "UNAUTHORIZED",
-				"message": "Could not extract user information",
-			},
-		})
-		return
-	}
-
-	// Find the user in the database
-	db := config.GetDB()
-	var user models.User
-	if err := db.Where("auth0_id = ?", auth0ID).First(&user).Error; err != nil {
-		c.PureJSON(http.StatusNotFound, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "USER_NOT_FOUND",
-				"message": "User profile not found. Please create a profile first.",
-			},
-		})
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
-
-	// Check if user is a technician (only technicians can review orders)
-	if user.Role != "technician" {
-		c.PureJSON(http.StatusForbidden, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "FORBIDDEN",
-				"message": "Only technicians can review orders",
-			},
-		})
-		return
-	}
-
-	// Get order ID from URL parameter
-	orderID := c.Param("id")
-	if orderID == "" {
-		c.PureJSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "INVALID_REQUEST",
-				"message": "Order ID is required",
-			},
-		})
-		return
-	}
-
-	// Fetch the order
-	var order models.Order
-	if err := db.First(&order, orderID).Error; err != nil {
-		c.PureJSON(http.StatusNotFound, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "ORDER_NOT_FOUND",
-				"message": "Order not found",
-			},
-		})
-		return
-	}
-
-	// Check if order has already been reviewed
-	if order.Status != "submitted" {
-		c.PureJSON(http.StatusUnprocessableEntity, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "INVALID_STATE",
-				"message": "Order has already been reviewed",
-			},
-		})
+	version, ok := ifMatchVersion(c)
+	if !ok {
 		return
 	}
 
 	// Parse request body
 	var req ReviewOrderRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.PureJSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "VALIDATION_ERROR",
-				"message": "Invalid request data",
-				"details": err.Error(),
-			},
-		})
+		respondValidationError(c, err)
 		return
 	}
 
-	// Validate action-specific requirements
-	switch req.Action {
-	case "accept":
-		if req.Price == nil {
-			c.PureJSON(http.StatusBadRequest, gin.H{
-				"success": false,
-				"error": gin.H{
-					"code":    "VALIDATION_ERROR",
-					"message": "Price is required when accepting an order",
-				},
-			})
-			return
-		}
-		if *req.Price <= 0 {
-			c.PureJSON(http.StatusBadRequest, gin.H{
-				"success": false,
-				"error": gin.H{
-					"code":    "VALIDATION_ERROR",
-					"message": "Price must be greater than zero",
-				},
-			})
-			return
-		}
-	case "reject":
-		if req.Feedback == nil || *req.Feedback == "" {
-			c.PureJSON(http.StatusBadRequest, gin.H{
-				"success": false,
-				"error": gin.H{
-					"code":    "VALIDATION_ERROR",
-					"message": "Feedback is required when rejecting an order",
-				},
-			})
-			return
-		}
-	}
-
-	// Update the order based on the action
-	if req.Action == "accept" {
-		order.Status = "accepted"
-		order.Price = req.Price
-		order.TechnicianID = &user.ID
-	} else {
-		order.Status = "rejected"
-		order.Feedback = req.Feedback
-		order.TechnicianID = &user.ID
-	}
-
-	// Save the changes
-	if err := db.Save(&order).Error; err != nil {
-		c.PureJSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "DATABASE_ERROR",
-				"message": "Failed to update order",
-			},
-		})
+	promisedBy, ok := parseDueDate(c, "promised_by", req.PromisedBy)
+	if !ok {
 		return
 	}
 
-	// Load relationships for complete response
-	if err := db.Preload("Customer").Preload("Technician").First(&order, order.ID).Error; err != nil {
-		c.PureJSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "DATABASE_ERROR",
-				"message": "Failed to load order details",
-			},
-		})
-		return
+	addOns := make([]services.AddOnSelection, len(req.AddOns))
+	for i, selection := range req.AddOns {
+		addOns[i] = services.AddOnSelection{AddOnID: selection.AddOnID, Quantity: selection.Quantity}
 	}
 
-	// Generate image URL
-	populateOrderImageURL(&order)
-
-	c.PureJSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    order,
+	order, err := services.GetOrderServiceFor(c.Request.Context()).ReviewOrder(user, c.Param("id"), services.ReviewOrderInput{
+		Action:     req.Action,
+		Price:      req.Price,
+		AddOns:     addOns,
+		RushFee:    req.RushFee,
+		PromisedBy: promisedBy,
+		Feedback:   req.Feedback,
+		Version:    version,
 	})
+	respondOrderWrite(c, user, order, err)
 }
 
 // UpdateOrderStatusRequest represents the request body for updating order status
@@ -591,178 +651,132 @@ type UpdateOrderStatusRequest struct {
 }
 
 // UpdateOrderStatus handles PUT /api/v1/orders/:id/status - updates order status (technicians only)
+// If-Match must carry the order's ETag; see respondOrderWrite
 func UpdateOrderStatus(c *gin.Context) {
-	// Extract Auth0 user ID from JWT token
-	auth0ID, err := middleware.GetUserID(c)
-	if err != nil {
-		c.PureJSON(http.StatusUnauthorized, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "UNAUTHORIZED",
-				"message": "Could not extract user information",
-			},
-		})
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
-
-	// Find the user in the database
-	db := config.GetDB()
-	var user models.User
-	if err := db.Where("auth0_id = ?", auth0ID).First(&user).Error; err != nil {
-		c.PureJSON(http.StatusNotFound, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "USER_NOT_FOUND",
-				"message": "User profile not found. Please create a profile first.",
-			},
-		})
+	version, ok := ifMatchVersion(c)
+	if !ok {
 		return
 	}
 
-	// Check if user is a technician (only technicians can update order status)
-	if user.Role != "technician" {
-		c.PureJSON(http.StatusForbidden, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "FORBIDDEN",
-				"message": "Only technicians can update order status",
-			},
-		})
+	// Parse request body
+	var req UpdateOrderStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
 		return
 	}
 
-	// Get order ID from URL parameter
-	orderID := c.Param("id")
-	if orderID == "" {
-		c.PureJSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "INVALID_REQUEST",
-				"message": "Order ID is required",
-			},
-		})
-		return
-	}
+	order, err := services.GetOrderServiceFor(c.Request.Context()).UpdateOrderStatus(user, c.Param("id"), req.Status, version)
+	respondOrderWrite(c, user, order, err)
+}
 
-	// Fetch the order
-	var order models.Order
-	if err := db.First(&order, orderID).Error; err != nil {
-		c.PureJSON(http.StatusNotFound, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "ORDER_NOT_FOUND",
-				"message": "Order not found",
-			},
-		})
-		return
+// respondOrderWrite responds to a change to the order in the :id parameter with the changed order and its ETag,
+// which the client sends in If-Match with its next change
+// When the order changed since the client loaded it, the 409 response carries the current order in its details
+// and its ETag in the header, so that the client can show what changed and decide whether to try again
+func respondOrderWrite(c *gin.Context, user *models.User, order *models.Order, err error) {
+	if err == nil {
+		c.Header("ETag", orderETag(order, apiversion.Serialize(c, order)))
+		populateOrderImageURL(order)
+		apiresponse.OK(c, order)
+		return
+	}
+
+	// A version mismatch is the only conflict order writes report
+	if apierror.HasStatus(err, http.StatusConflict) {
+		current, loadErr := services.GetOrderServiceFor(c.Request.Context()).GetOrder(user, c.Param("id"))
+		if loadErr == nil {
+			c.Header("ETag", orderETag(current, apiversion.Serialize(c, current)))
+			populateOrderImageURL(current)
+			err = apierror.As(err).WithDetails(map[string]interface{}{"order": apiversion.Serialize(c, current)})
+		}
 	}
+	apierror.Respond(c, err)
+}
+
+// BulkUpdateOrderStatusRequest represents the request body for updating the status of several orders
+type BulkUpdateOrderStatusRequest struct {
+	OrderIDs []uint `json:"order_ids" binding:"required,min=1,max=100,dive,gt=0"`
+	Status   string `json:"status" binding:"required,oneof=in_production shipped delivered"`
+}
 
-	// Check if order is assigned to this technician
-	if order.TechnicianID == nil || *order.TechnicianID != user.ID {
-		c.PureJSON(http.StatusForbidden, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "FORBIDDEN",
-				"message": "You can only update status of orders assigned to you",
-			},
-		})
+// bulkStatusItem is the outcome of one order in a bulk status update response
+type bulkStatusItem struct {
+	OrderID uint                     `json:"order_id"`
+	Success bool                     `json:"success"`
+	Order   interface{}              `json:"order,omitempty"` // the updated order, rendered for the request's API version
+	Error   *apiresponse.ErrorObject `json:"error,omitempty"`
+}
+
+// BulkUpdateOrderStatus handles PUT /api/v1/orders/status/bulk - updates the status of several orders (technicians only)
+// Each order succeeds or fails on its own in its own transaction, which commits the change with the events announcing it;
+// the response lists the outcome per order
+func BulkUpdateOrderStatus(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
 
 	// Parse request body
-	var req UpdateOrderStatusRequest
+	var req BulkUpdateOrderStatusRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.PureJSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "VALIDATION_ERROR",
-				"message": "Invalid request data",
-				"details": err.Error(),
-			},
-		})
-		return
-	}
-
-	// Define valid status transitions
-	validTransitions := map[string][]string{
-		"accepted":      {"in_production"},
-		"in_production": {"shipped"},
-		"shipped":       {"delivered"},
-		"delivered":     {}, // Terminal state
-	}
-
-	// Check if the current status allows the requested transition
-	allowedStatuses, exists := validTransitions[order.Status]
-	if !exists {
-		c.PureJSON(http.StatusUnprocessableEntity, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "INVALID_STATE",
-				"message": "Cannot update status from current order state",
-			},
-		})
+		respondValidationError(c, err)
 		return
 	}
 
-	// Check if the requested status is in the list of allowed transitions
-	isValid := false
-	for _, allowed := range allowedStatuses {
-		if allowed == req.Status {
-			isValid = true
-			break
+	results := make([]services.BulkStatusResult, len(req.OrderIDs))
+	for i, id := range req.OrderIDs {
+		result, err := updateOrderStatusInTx(c.Request.Context(), user, id, req.Status)
+		if err != nil {
+			apierror.Respond(c, err)
+			return
 		}
+		results[i] = result
 	}
 
-	if !isValid {
-		c.PureJSON(http.StatusUnprocessableEntity, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "INVALID_TRANSITION",
-				"message": "Invalid status transition",
-				"details": gin.H{
-					"current_status":   order.Status,
-					"requested_status": req.Status,
-					"allowed_statuses": allowedStatuses,
-				},
-			},
-		})
-		return
-	}
-
-	// Update the order status
-	order.Status = req.Status
-
-	// Save the changes
-	if err := db.Save(&order).Error; err != nil {
-		c.PureJSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "DATABASE_ERROR",
-				"message": "Failed to update order status",
-			},
-		})
-		return
-	}
-
-	// Load relationships for complete response
-	if err := db.Preload("Customer").Preload("Technician").First(&order, order.ID).Error; err != nil {
-		c.PureJSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "DATABASE_ERROR",
-				"message": "Failed to load order details",
-			},
-		})
-		return
+	data := make([]bulkStatusItem, len(results))
+	succeeded := 0
+	for i, result := range results {
+		data[i] = bulkStatusItem{OrderID: result.OrderID}
+		if result.Err != nil {
+			errObject := apierror.Object(result.Err)
+			data[i].Error = &errObject
+			continue
+		}
+		succeeded++
+		populateOrderImageURL(result.Order)
+		data[i].Success = true
+		data[i].Order = apiversion.Serialize(c, result.Order)
 	}
 
-	// Generate image URL
-	populateOrderImageURL(&order)
+	c.PureJSON(http.StatusOK, apiresponse.Batch(data, len(results), succeeded))
+}
 
-	c.PureJSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    order,
+// updateOrderStatusInTx updates one order of a bulk status update in a transaction of its own
+// The order's outcome is rolled back when it fails; the error returned is for the request as a whole
+func updateOrderStatusInTx(ctx context.Context, user *models.User, orderID uint, status string) (services.BulkStatusResult, error) {
+	var result services.BulkStatusResult
+	err := config.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		results, err := services.GetOrderServiceFor(config.WithTx(ctx, tx)).BulkUpdateOrderStatus(user, []uint{orderID}, status)
+		if err != nil {
+			return err
+		}
+		result = results[0]
+		return result.Err
 	})
+	switch {
+	case err == nil || result.Err != nil:
+		return result, nil
+	case result.OrderID == 0:
+		// The update never ran, because the user may not update statuses or the transaction did not start
+		return result, err
+	default:
+		// The update succeeded but did not commit
+		return services.BulkStatusResult{OrderID: orderID, Err: apierror.Internal("DATABASE_ERROR", "Failed to save changes").Wrap(err)}, nil
+	}
 }
 
 // ReorderRequest represents the request body for reordering an order
@@ -772,275 +786,103 @@ type ReorderRequest struct {
 
 // ReorderOrder handles POST /api/v1/orders/:id/reorder - creates a new order based on an existing order
 func ReorderOrder(c *gin.Context) {
-	// Extract Auth0 user ID from JWT token
-	auth0ID, err := middleware.GetUserID(c)
-	if err != nil {
-		c.PureJSON(http.StatusUnauthorized, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "UNAUTHORIZED",
-				"message": "Could not extract user information",
-			},
-		})
-		return
-	}
-
-	// Find the user in the database
-	db := config.GetDB()
-	var user models.User
-	if err := db.Where("auth0_id = ?", auth0ID).First(&user).Error; err != nil {
-		c.PureJSON(http.StatusNotFound, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "USER_NOT_FOUND",
-				"message": "User profile not found. Please create a profile first.",
-			},
-		})
-		return
-	}
-
-	// Check if user is a customer (only customers can reorder)
-	if user.Role != "customer" {
-		c.PureJSON(http.StatusForbidden, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "FORBIDDEN",
-				"message": "Only customers can reorder",
-			},
-		})
-		return
-	}
-
-	// Get order ID from URL parameter
-	orderID := c.Param("id")
-	if orderID == "" {
-		c.PureJSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "INVALID_REQUEST",
-				"message": "Order ID is required",
-			},
-		})
-		return
-	}
-
-	// Fetch the original order
-	var originalOrder models.Order
-	if err := db.First(&originalOrder, orderID).Error; err != nil {
-		c.PureJSON(http.StatusNotFound, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "ORDER_NOT_FOUND",
-				"message": "Order not found",
-			},
-		})
-		return
-	}
-
-	// Verify that the user owns this order (only the customer can reorder their own orders)
-	if originalOrder.CustomerID != user.ID {
-		c.PureJSON(http.StatusForbidden, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "FORBIDDEN",
-				"message": "You can only reorder your own orders",
-			},
-		})
-		return
-	}
-
-	// Verify that the order is in a completed state (delivered)
-	if originalOrder.Status != "delivered" {
-		c.PureJSON(http.StatusUnprocessableEntity, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "INVALID_ORDER_STATE",
-				"message": "Only completed (delivered) orders can be reordered",
-			},
-		})
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
 
 	// Parse request body for new quantity
 	var req ReorderRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.PureJSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "VALIDATION_ERROR",
-				"message": "Invalid request data",
-				"details": err.Error(),
-			},
-		})
+		respondValidationError(c, err)
 		return
 	}
 
-	// Create new order based on the original
-	newOrder := models.Order{
-		Description:     originalOrder.Description,
-		Quantity:        req.Quantity,
-		Status:          "submitted",
-		ImageS3Key:      originalOrder.ImageS3Key, // Copy the S3 key (same image)
-		CustomerID:      user.ID,
-		OriginalOrderID: &originalOrder.ID, // Link to original order
-	}
-
-	// Save the new order
-	if err := db.Create(&newOrder).Error; err != nil {
-		c.PureJSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "DATABASE_ERROR",
-				"message": "Failed to create reorder",
-			},
-		})
-		return
-	}
-
-	// Load the customer relationship to return complete data
-	if err := db.Preload("Customer").First(&newOrder, newOrder.ID).Error; err != nil {
-		c.PureJSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "DATABASE_ERROR",
-				"message": "Failed to load order details",
-			},
-		})
+	newOrder, err := services.GetOrderServiceFor(c.Request.Context()).Reorder(user, c.Param("id"), req.Quantity)
+	if err != nil {
+		apierror.Respond(c, err)
 		return
 	}
 
 	// Generate presigned URL for image
-	populateOrderImageURL(&newOrder)
+	populateOrderImageURL(newOrder)
 
-	c.PureJSON(http.StatusCreated, gin.H{
-		"success": true,
-		"data":    newOrder,
-	})
+	apiresponse.Created(c, newOrder)
 }
 
-// AssignOrder handles PUT /api/v1/orders/:id/assign - assigns an order to the current technician
-func AssignOrder(c *gin.Context) {
-	// Extract Auth0 user ID from JWT token
-	auth0ID, err := middleware.GetUserID(c)
-	if err != nil {
-		c.PureJSON(http.StatusUnauthorized, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "UNAUTHORIZED",
-				"message": "Could not extract user information",
-			},
-		})
+// DuplicateOrder handles POST /api/v1/orders/:id/duplicate - creates a new order copying any of the customer's orders
+func DuplicateOrder(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
 
-	// Find the user in the database
-	db := config.GetDB()
-	var user models.User
-	if err := db.Where("auth0_id = ?", auth0ID).First(&user).Error; err != nil {
-		c.PureJSON(http.StatusNotFound, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "USER_NOT_FOUND",
-				"message": "User profile not found. Please create a profile first.",
-			},
-		})
+	newOrder, err := services.GetOrderServiceFor(c.Request.Context()).DuplicateOrder(user, c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, err)
 		return
 	}
 
-	// Check if user is a technician (only technicians can assign orders)
-	if user.Role != "technician" {
-		c.PureJSON(http.StatusForbidden, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "FORBIDDEN",
-				"message": "Only technicians can assign orders",
-			},
-		})
+	populateOrderImageURL(newOrder)
+
+	apiresponse.Created(c, newOrder)
+}
+
+// GetOrderLineage handles GET /api/v1/orders/:id/lineage - lists the original of an order and every reorder or duplicate of it
+func GetOrderLineage(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
 
-	// Get order ID from URL parameter
-	orderID := c.Param("id")
-	if orderID == "" {
-		c.PureJSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "INVALID_REQUEST",
-				"message": "Order ID is required",
-			},
-		})
+	lineage, err := services.GetOrderService().OrderLineage(user, c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, err)
 		return
 	}
 
-	// Fetch the order
-	var order models.Order
-	if err := db.First(&order, orderID).Error; err != nil {
-		c.PureJSON(http.StatusNotFound, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "ORDER_NOT_FOUND",
-				"message": "Order not found",
-			},
-		})
+	apiresponse.OK(c, lineage)
+}
+
+// SetShippingAddress handles PUT /api/v1/orders/:id/shipping-address - chooses where a submitted order ships (order's customer only)
+func SetShippingAddress(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
 
-	// Check if order is already assigned to another technician
-	if order.TechnicianID != nil && *order.TechnicianID != user.ID {
-		c.PureJSON(http.StatusUnprocessableEntity, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "ALREADY_ASSIGNED",
-				"message": "Order is already assigned to another technician",
-			},
-		})
+	var req SetShippingAddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
 		return
 	}
 
-	// Check if order is already assigned to this technician
-	if order.TechnicianID != nil && *order.TechnicianID == user.ID {
-		c.PureJSON(http.StatusUnprocessableEntity, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "ALREADY_ASSIGNED",
-				"message": "Order is already assigned to you",
-			},
-		})
+	order, err := services.GetOrderService().SetShippingAddress(user, c.Param("id"), req.ShippingAddressID)
+	if err != nil {
+		apierror.Respond(c, err)
 		return
 	}
 
-	// Assign the order to the current technician
-	order.TechnicianID = &user.ID
+	populateOrderImageURL(order)
+
+	apiresponse.OK(c, order)
+}
 
-	// Save the changes
-	if err := db.Save(&order).Error; err != nil {
-		c.PureJSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "DATABASE_ERROR",
-				"message": "Failed to assign order",
-			},
-		})
+// AssignOrder handles PUT /api/v1/orders/:id/assign - assigns an order to the current technician
+func AssignOrder(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
 
-	// Load relationships for complete response
-	if err := db.Preload("Customer").Preload("Technician").First(&order, order.ID).Error; err != nil {
-		c.PureJSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "DATABASE_ERROR",
-				"message": "Failed to load order details",
-			},
-		})
+	order, err := services.GetOrderService().AssignOrder(user, c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, err)
 		return
 	}
 
 	// Generate image URL
-	populateOrderImageURL(&order)
+	populateOrderImageURL(order)
 
-	c.PureJSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    order,
-	})
+	apiresponse.OK(c, order)
 }
@@ -3,25 +3,38 @@ package controllers
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apiversion"
 	"github.com/kendall-kelly/kendalls-nails-api/config"
 	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
+// testShippingAddress is the shipping address of orders that get accepted in tests
+var testShippingAddress = &models.AddressSnapshot{Recipient: "Customer User", Line1: "1 Main St", City: "Portland", PostalCode: "97201", Country: "US"}
+
 func setupOrderTestDB(t *testing.T) *gorm.DB {
 	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
 	if err != nil {
 		t.Fatalf("Failed to connect to test database: %v", err)
 	}
 
-	// Auto-migrate the User and Order models
-	if err := db.AutoMigrate(&models.User{}, &models.Order{}); err != nil {
+	// Migrate every model so order relationships can be preloaded
+	if err := models.MigrateUp(db); err != nil {
 		t.Fatalf("Failed to migrate test database: %v", err)
 	}
 
@@ -85,6 +98,21 @@ func TestCreateOrder(t *testing.T) {
 				assert.Equal(t, customer.Email, customerData["email"])
 			},
 		},
+		{
+			name:    "Successfully create rush order",
+			auth0ID: customer.Auth0ID,
+			role:    "customer",
+			requestBody: map[string]interface{}{
+				"description": "Pink nails for Saturday",
+				"quantity":    1,
+				"rush":        true,
+			},
+			expectedStatus: http.StatusCreated,
+			checkResponse: func(t *testing.T, response map[string]interface{}) {
+				data := response["data"].(map[string]interface{})
+				assert.Equal(t, true, data["rush"])
+			},
+		},
 		{
 			name:    "Fail to create order as technician",
 			auth0ID: technician.Auth0ID,
@@ -554,6 +582,200 @@ func TestListOrders_Pagination(t *testing.T) {
 	}
 }
 
+func TestListOrders_Cursor(t *testing.T) {
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	technician := models.User{Auth0ID: "auth0|tech", Name: "Technician User", Email: "tech@example.com", Role: "technician"}
+	db.Create(&technician)
+
+	// Rush orders and orders created at the same instant must neither repeat nor go missing across pages
+	created := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	for i, rush := range []bool{false, true, false, true, false, false, true} {
+		db.Create(&models.Order{
+			Description: "Order " + strconv.Itoa(i),
+			Quantity:    1,
+			Status:      "submitted",
+			CustomerID:  customer.ID,
+			Rush:        rush,
+			CreatedAt:   created.Add(time.Duration(i/2) * time.Hour),
+		})
+	}
+
+	list := func(auth0ID, role, query string) (int, map[string]interface{}) {
+		router := setupTestRouter()
+		router.GET("/orders", mockAuthMiddleware(auth0ID, role, "mock-token"), ListOrders)
+		req, _ := http.NewRequest(http.MethodGet, "/orders"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w.Code, response
+	}
+	descriptions := func(response map[string]interface{}) []string {
+		var names []string
+		for _, order := range response["data"].([]interface{}) {
+			names = append(names, order.(map[string]interface{})["description"].(string))
+		}
+		return names
+	}
+
+	for _, tt := range []struct{ name, auth0ID, role, order string }{
+		{"customer, newest first", customer.Auth0ID, "customer", ""},
+		{"customer, oldest first", customer.Auth0ID, "customer", "&order=asc"},
+		{"technician, rush first", technician.Auth0ID, "technician", ""},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, all := list(tt.auth0ID, tt.role, "?limit=100"+tt.order)
+
+			var paged []string
+			cursor := ""
+			for pages := 0; pages < 10; pages++ {
+				code, response := list(tt.auth0ID, tt.role, "?limit=2&cursor="+url.QueryEscape(cursor)+tt.order)
+				assert.Equal(t, http.StatusOK, code)
+				paged = append(paged, descriptions(response)...)
+
+				pagination := response["pagination"].(map[string]interface{})
+				assert.Equal(t, float64(2), pagination["limit"])
+				assert.NotContains(t, pagination, "total", "cursor pages are not counted")
+				next, ok := pagination["next_cursor"].(string)
+				if !ok {
+					break
+				}
+				cursor = next
+			}
+			assert.Equal(t, descriptions(all), paged)
+		})
+	}
+
+	code, response := list(customer.Auth0ID, "customer", "?cursor=not-a-cursor")
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, "VALIDATION_ERROR", response["error"].(map[string]interface{})["code"])
+
+	code, _ = list(customer.Auth0ID, "customer", "?cursor=&sort=price")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestOrders_FieldsAndInclude(t *testing.T) {
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	price := 40.0
+	order := models.Order{Description: "Coffin set", Quantity: 1, Status: "accepted", Price: &price, CustomerID: customer.ID}
+	db.Create(&order)
+
+	get := func(path string) (int, map[string]interface{}) {
+		router := setupTestRouter()
+		auth := mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token")
+		router.GET("/orders", auth, ListOrders)
+		router.GET("/orders/:id", auth, GetOrder)
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w.Code, response
+	}
+	keys := func(object interface{}) []string {
+		var names []string
+		for name := range object.(map[string]interface{}) {
+			names = append(names, name)
+		}
+		return names
+	}
+
+	// Selected fields, and always the id
+	code, response := get("/orders?fields=status,price")
+	assert.Equal(t, http.StatusOK, code)
+	listed := response["data"].([]interface{})[0]
+	assert.ElementsMatch(t, []string{"id", "status", "price"}, keys(listed))
+	assert.Equal(t, 40.0, listed.(map[string]interface{})["price"])
+
+	code, response = get(fmt.Sprintf("/orders/%d?fields=status,customer", order.ID))
+	assert.Equal(t, http.StatusOK, code)
+	assert.ElementsMatch(t, []string{"id", "status", "customer"}, keys(response["data"]))
+
+	// Every field, without the relations left out
+	code, response = get("/orders?include=")
+	assert.Equal(t, http.StatusOK, code)
+	listed = response["data"].([]interface{})[0]
+	assert.NotContains(t, listed, "customer")
+	assert.Contains(t, listed, "description")
+
+	code, response = get(fmt.Sprintf("/orders/%d?include=technician", order.ID))
+	assert.Equal(t, http.StatusOK, code)
+	assert.NotContains(t, response["data"], "customer")
+
+	// Without either, nothing changes
+	_, response = get("/orders")
+	assert.Equal(t, "Customer User", response["data"].([]interface{})[0].(map[string]interface{})["customer"].(map[string]interface{})["name"])
+
+	code, _ = get("/orders?fields=id,secret")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = get(fmt.Sprintf("/orders/%d?include=payments", order.ID))
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestOrders_V2MoneyInCents(t *testing.T) {
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	price := 40.1
+	order := models.Order{Description: "Coffin set", Quantity: 1, Status: "accepted", Price: &price, CustomerID: customer.ID}
+	db.Create(&order)
+	db.Create(&models.OrderLineItem{OrderID: order.ID, Kind: "base", Description: "Coffin set", Quantity: 2, UnitPrice: 20.05, Amount: 40.1})
+
+	get := func(version int, path string) (int, map[string]interface{}) {
+		router := setupTestRouter()
+		group := router.Group("", apiversion.Use(version), mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token"))
+		group.GET("/orders", ListOrders)
+		group.GET("/orders/:id", GetOrder)
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w.Code, response
+	}
+
+	// v1 keeps decimal amounts
+	code, response := get(apiversion.V1, fmt.Sprintf("/orders/%d", order.ID))
+	assert.Equal(t, http.StatusOK, code)
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, 40.1, data["price"])
+	assert.NotContains(t, data, "price_cents")
+
+	// v2 reports integer cents in their place, on the order and its line items
+	code, response = get(apiversion.V2, fmt.Sprintf("/orders/%d", order.ID))
+	assert.Equal(t, http.StatusOK, code)
+	data = response["data"].(map[string]interface{})
+	assert.Equal(t, 4010.0, data["price_cents"])
+	assert.NotContains(t, data, "price")
+	assert.Equal(t, "Customer User", data["customer"].(map[string]interface{})["name"])
+	item := data["line_items"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, 2005.0, item["unit_price_cents"])
+	assert.Equal(t, 4010.0, item["amount_cents"])
+	assert.NotContains(t, item, "unit_price")
+	assert.NotContains(t, item, "amount")
+
+	code, response = get(apiversion.V2, "/orders?fields=price_cents")
+	assert.Equal(t, http.StatusOK, code)
+	listed := response["data"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"id": float64(order.ID), "price_cents": 4010.0}, listed)
+
+	// Fields are named as the version names them
+	code, _ = get(apiversion.V2, "/orders?fields=price")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = get(apiversion.V1, "/orders?fields=price_cents")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
 func TestListOrders_Sorting(t *testing.T) {
 	// Setup
 	db := setupOrderTestDB(t)
@@ -609,6 +831,299 @@ func TestListOrders_Sorting(t *testing.T) {
 	assert.Equal(t, "First order", lastOrder["description"])
 }
 
+func TestListOrders_SortParameters(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+
+	cheap, pricey := 20.0, 60.0
+	db.Create(&models.Order{Description: "Oldest", Quantity: 1, Status: "shipped", CustomerID: customer.ID, Price: &pricey})
+	db.Create(&models.Order{Description: "Middle", Quantity: 1, Status: "submitted", CustomerID: customer.ID})
+	db.Create(&models.Order{Description: "Newest", Quantity: 1, Status: "accepted", CustomerID: customer.ID, Price: &cheap})
+
+	router := setupTestRouter()
+	router.GET("/orders",
+		mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token"),
+		ListOrders,
+	)
+
+	tests := []struct {
+		name         string
+		queryParams  string
+		expectedCode int
+		expected     []string
+	}{
+		{"Oldest first", "?sort=created_at&order=asc", http.StatusOK, []string{"Oldest", "Middle", "Newest"}},
+		{"Order defaults to descending", "?sort=created_at", http.StatusOK, []string{"Newest", "Middle", "Oldest"}},
+		{"Price ascending keeps unpriced last", "?sort=price&order=ASC", http.StatusOK, []string{"Newest", "Oldest", "Middle"}},
+		{"Price descending keeps unpriced last", "?sort=price&order=desc", http.StatusOK, []string{"Oldest", "Newest", "Middle"}},
+		{"Status", "?sort=status&order=asc", http.StatusOK, []string{"Newest", "Oldest", "Middle"}},
+		{"Unknown column", "?sort=customer_id", http.StatusBadRequest, nil},
+		{"Unknown direction", "?sort=price&order=sideways", http.StatusBadRequest, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "/orders"+tt.queryParams, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+
+			var response map[string]interface{}
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			if tt.expectedCode != http.StatusOK {
+				errorObj := response["error"].(map[string]interface{})
+				assert.Equal(t, "VALIDATION_ERROR", errorObj["code"])
+				return
+			}
+
+			var descriptions []string
+			for _, item := range response["data"].([]interface{}) {
+				descriptions = append(descriptions, item.(map[string]interface{})["description"].(string))
+			}
+			assert.Equal(t, tt.expected, descriptions)
+		})
+	}
+}
+
+func TestListOrders_AsTechnician_RushOrdersFirst(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	technician := models.User{Auth0ID: "auth0|tech", Name: "Technician User", Email: "tech@example.com", Role: "technician"}
+	db.Create(&technician)
+
+	db.Create(&models.Order{Description: "Rush order", Quantity: 1, Status: "submitted", CustomerID: customer.ID, Rush: true})
+	db.Create(&models.Order{Description: "Regular order", Quantity: 1, Status: "submitted", CustomerID: customer.ID})
+
+	router := setupTestRouter()
+	router.GET("/orders",
+		mockAuthMiddleware(technician.Auth0ID, "technician", "mock-token"),
+		ListOrders,
+	)
+
+	req, _ := http.NewRequest(http.MethodGet, "/orders", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+	// The older rush order is queued ahead of the newer regular order
+	data := response["data"].([]interface{})
+	assert.Equal(t, 2, len(data))
+	firstOrder := data[0].(map[string]interface{})
+	assert.Equal(t, "Rush order", firstOrder["description"])
+	assert.Equal(t, true, firstOrder["rush"])
+	assert.Equal(t, "rush", firstOrder["priority"])
+	assert.Equal(t, "standard", data[1].(map[string]interface{})["priority"])
+}
+
+func TestCreateOrder_RequestedBy(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+
+	router := setupTestRouter()
+	router.POST("/orders", mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token"), CreateOrder)
+
+	create := func(requestedBy string) (int, map[string]interface{}) {
+		body, _ := json.Marshal(map[string]interface{}{"description": "Party nails", "quantity": 1, "requested_by": requestedBy})
+		req, _ := http.NewRequest(http.MethodPost, "/orders", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response
+	}
+
+	requestedBy := time.Now().UTC().AddDate(0, 0, 14).Format(time.DateOnly)
+	code, response := create(requestedBy)
+	assert.Equal(t, http.StatusCreated, code)
+	order := response["data"].(map[string]interface{})
+	assert.Equal(t, requestedBy+"T00:00:00Z", order["requested_by"])
+	assert.Nil(t, order["promised_by"])
+	assert.Equal(t, false, order["overdue"])
+
+	code, response = create("next friday")
+	assert.Equal(t, http.StatusBadRequest, code)
+	details := response["error"].(map[string]interface{})["details"].(map[string]interface{})
+	assert.Contains(t, details, "requested_by")
+
+	code, _ = create(time.Now().UTC().AddDate(0, 0, -2).Format(time.DateOnly))
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestListOverdueOrders(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	technician := models.User{Auth0ID: "auth0|tech", Name: "Technician User", Email: "tech@example.com", Role: "technician"}
+	db.Create(&technician)
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	daysAgo := func(days int) *time.Time {
+		d := today.AddDate(0, 0, -days)
+		return &d
+	}
+	late := models.Order{Description: "Promised last week", Quantity: 1, Status: "in_production", CustomerID: customer.ID, TechnicianID: &technician.ID, PromisedBy: daysAgo(7)}
+	db.Create(&late)
+	requested := models.Order{Description: "Requested yesterday", Quantity: 1, Status: "accepted", CustomerID: customer.ID, TechnicianID: &technician.ID, RequestedBy: daysAgo(1)}
+	db.Create(&requested)
+	db.Create(&models.Order{Description: "Promised next week", Quantity: 1, Status: "accepted", CustomerID: customer.ID, TechnicianID: &technician.ID, RequestedBy: daysAgo(3), PromisedBy: daysAgo(-7)})
+	db.Create(&models.Order{Description: "Due today", Quantity: 1, Status: "accepted", CustomerID: customer.ID, TechnicianID: &technician.ID, PromisedBy: daysAgo(0)})
+	db.Create(&models.Order{Description: "Shipped late", Quantity: 1, Status: "shipped", CustomerID: customer.ID, TechnicianID: &technician.ID, PromisedBy: daysAgo(7)})
+
+	router := setupTestRouter()
+	router.GET("/orders/overdue", mockAuthMiddleware(technician.Auth0ID, "technician", "mock-token"), ListOverdueOrders)
+	router.GET("/orders", mockAuthMiddleware(technician.Auth0ID, "technician", "mock-token"), ListOrders)
+	router.GET("/customer/orders/overdue", mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token"), ListOverdueOrders)
+
+	req, _ := http.NewRequest(http.MethodGet, "/orders/overdue", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+	// Earliest due first
+	data := response["data"].([]interface{})
+	if assert.Len(t, data, 2) {
+		assert.Equal(t, float64(late.ID), data[0].(map[string]interface{})["id"])
+		assert.Equal(t, float64(requested.ID), data[1].(map[string]interface{})["id"])
+		assert.Equal(t, true, data[0].(map[string]interface{})["overdue"])
+	}
+
+	// ListOrders flags the same orders
+	req, _ = http.NewRequest(http.MethodGet, "/orders", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	overdue := 0
+	for _, item := range response["data"].([]interface{}) {
+		if item.(map[string]interface{})["overdue"] == true {
+			overdue++
+		}
+	}
+	assert.Equal(t, 2, overdue)
+
+	// Customers cannot use the technician queue
+	req, _ = http.NewRequest(http.MethodGet, "/customer/orders/overdue", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestListOrders_DateRangeFilters(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+
+	march := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	april := time.Date(2026, 4, 15, 12, 0, 0, 0, time.UTC)
+	may := time.Date(2026, 5, 15, 12, 0, 0, 0, time.UTC)
+	db.Create(&models.Order{Description: "March order", Quantity: 1, Status: "submitted", CustomerID: customer.ID, CreatedAt: march, UpdatedAt: may})
+	db.Create(&models.Order{Description: "April order", Quantity: 1, Status: "submitted", CustomerID: customer.ID, CreatedAt: april, UpdatedAt: april})
+	db.Create(&models.Order{Description: "May order", Quantity: 1, Status: "submitted", CustomerID: customer.ID, CreatedAt: may, UpdatedAt: may})
+
+	router := setupTestRouter()
+	router.GET("/orders",
+		mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token"),
+		ListOrders,
+	)
+
+	tests := []struct {
+		name         string
+		queryParams  string
+		expectedCode int
+		expected     []string
+	}{
+		{
+			name:         "Orders from last month",
+			queryParams:  "?created_after=2026-04-01T00:00:00Z&created_before=2026-05-01T00:00:00Z",
+			expectedCode: http.StatusOK,
+			expected:     []string{"April order"},
+		},
+		{
+			name:         "Created after is inclusive",
+			queryParams:  "?created_after=2026-04-15T12:00:00Z",
+			expectedCode: http.StatusOK,
+			expected:     []string{"May order", "April order"},
+		},
+		{
+			name:         "Updated after",
+			queryParams:  "?updated_after=2026-05-01T00:00:00Z",
+			expectedCode: http.StatusOK,
+			expected:     []string{"May order", "March order"},
+		},
+		{
+			name:         "Offset timestamps",
+			queryParams:  "?created_before=2026-03-15T08:00:01-04:00",
+			expectedCode: http.StatusOK,
+			expected:     []string{"March order"},
+		},
+		{
+			name:         "Invalid timestamp",
+			queryParams:  "?created_after=2026-04-01",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Inverted range",
+			queryParams:  "?created_after=2026-05-01T00:00:00Z&created_before=2026-04-01T00:00:00Z",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "/orders"+tt.queryParams, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+
+			var response map[string]interface{}
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			if tt.expectedCode != http.StatusOK {
+				errorObj := response["error"].(map[string]interface{})
+				assert.Equal(t, "VALIDATION_ERROR", errorObj["code"])
+				return
+			}
+
+			var descriptions []string
+			for _, item := range response["data"].([]interface{}) {
+				descriptions = append(descriptions, item.(map[string]interface{})["description"].(string))
+			}
+			assert.Equal(t, tt.expected, descriptions)
+			pagination := response["pagination"].(map[string]interface{})
+			assert.Equal(t, float64(len(tt.expected)), pagination["total"])
+		})
+	}
+}
+
 func TestListOrders_WithoutAuth(t *testing.T) {
 	// Setup
 	db := setupOrderTestDB(t)
@@ -989,10 +1504,11 @@ func TestReviewOrder_Accept_Success(t *testing.T) {
 
 	// Create order
 	order := models.Order{
-		Description: "Test order to accept",
-		Quantity:    2,
-		Status:      "submitted",
-		CustomerID:  customer.ID,
+		Description:     "Test order to accept",
+		Quantity:        2,
+		Status:          "submitted",
+		CustomerID:      customer.ID,
+		ShippingAddress: testShippingAddress,
 	}
 	db.Create(&order)
 
@@ -1011,6 +1527,7 @@ func TestReviewOrder_Accept_Success(t *testing.T) {
 	}
 	body, _ := json.Marshal(requestBody)
 	req, _ := http.NewRequest(http.MethodPut, "/orders/1/review", bytes.NewBuffer(body))
+	req.Header.Set("If-Match", "*")
 	req.Header.Set("Content-Type", "application/json")
 
 	// Execute request
@@ -1045,6 +1562,105 @@ func TestReviewOrder_Accept_Success(t *testing.T) {
 	assert.Nil(t, updatedOrder.Feedback)
 }
 
+func TestReviewOrder_Accept_WithAddOnsAndRushFee(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	technician := models.User{Auth0ID: "auth0|tech", Name: "Technician User", Email: "tech@example.com", Role: "technician"}
+	db.Create(&technician)
+
+	charm := models.AddOn{Name: "Gold charm", Price: 2.5, CreatedByID: technician.ID}
+	db.Create(&charm)
+
+	order := models.Order{Description: "Test order with extras", Quantity: 1, Status: "submitted", CustomerID: customer.ID, ShippingAddress: testShippingAddress}
+	db.Create(&order)
+
+	router := setupTestRouter()
+	router.PUT("/orders/:id/review",
+		mockAuthMiddleware(technician.Auth0ID, "technician", "mock-token"),
+		ReviewOrder,
+	)
+
+	requestBody := map[string]interface{}{
+		"action":   "accept",
+		"price":    40.00,
+		"add_ons":  []map[string]interface{}{{"add_on_id": charm.ID, "quantity": 2}},
+		"rush_fee": 10.00,
+	}
+	body, _ := json.Marshal(requestBody)
+	req, _ := http.NewRequest(http.MethodPut, "/orders/1/review", bytes.NewBuffer(body))
+	req.Header.Set("If-Match", "*")
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, 55.0, data["price"])
+
+	lineItems := data["line_items"].([]interface{})
+	assert.Len(t, lineItems, 3)
+	addOnItem := lineItems[1].(map[string]interface{})
+	assert.Equal(t, "add_on", addOnItem["kind"])
+	assert.Equal(t, "Gold charm", addOnItem["description"])
+	assert.Equal(t, 5.0, addOnItem["amount"])
+
+	// Line items keep the quoted price after the catalog changes
+	db.Model(&charm).Update("price", 9.99)
+	var stored []models.OrderLineItem
+	db.Where("order_id = ?", order.ID).Order("id").Find(&stored)
+	assert.Len(t, stored, 3)
+	assert.Equal(t, 2.5, stored[1].UnitPrice)
+}
+
+func TestReviewOrder_Accept_WithUnknownAddOn_Fails(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	technician := models.User{Auth0ID: "auth0|tech", Name: "Technician User", Email: "tech@example.com", Role: "technician"}
+	db.Create(&technician)
+
+	order := models.Order{Description: "Test order", Quantity: 1, Status: "submitted", CustomerID: customer.ID}
+	db.Create(&order)
+
+	router := setupTestRouter()
+	router.PUT("/orders/:id/review",
+		mockAuthMiddleware(technician.Auth0ID, "technician", "mock-token"),
+		ReviewOrder,
+	)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"action":  "accept",
+		"price":   40.00,
+		"add_ons": []map[string]interface{}{{"add_on_id": 42}},
+	})
+	req, _ := http.NewRequest(http.MethodPut, "/orders/1/review", bytes.NewBuffer(body))
+	req.Header.Set("If-Match", "*")
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	var updatedOrder models.Order
+	db.First(&updatedOrder, order.ID)
+	assert.Equal(t, "submitted", updatedOrder.Status)
+	assert.Nil(t, updatedOrder.Price)
+}
+
 func TestReviewOrder_Reject_Success(t *testing.T) {
 	// Setup
 	db := setupOrderTestDB(t)
@@ -1091,6 +1707,7 @@ func TestReviewOrder_Reject_Success(t *testing.T) {
 	}
 	body, _ := json.Marshal(requestBody)
 	req, _ := http.NewRequest(http.MethodPut, "/orders/1/review", bytes.NewBuffer(body))
+	req.Header.Set("If-Match", "*")
 	req.Header.Set("Content-Type", "application/json")
 
 	// Execute request
@@ -1158,6 +1775,7 @@ func TestReviewOrder_AsCustomer_Forbidden(t *testing.T) {
 	}
 	body, _ := json.Marshal(requestBody)
 	req, _ := http.NewRequest(http.MethodPut, "/orders/1/review", bytes.NewBuffer(body))
+	req.Header.Set("If-Match", "*")
 	req.Header.Set("Content-Type", "application/json")
 
 	// Execute request
@@ -1221,6 +1839,7 @@ func TestReviewOrder_Accept_WithoutPrice_Fails(t *testing.T) {
 	}
 	body, _ := json.Marshal(requestBody)
 	req, _ := http.NewRequest(http.MethodPut, "/orders/1/review", bytes.NewBuffer(body))
+	req.Header.Set("If-Match", "*")
 	req.Header.Set("Content-Type", "application/json")
 
 	// Execute request
@@ -1285,6 +1904,7 @@ func TestReviewOrder_Accept_WithNegativePrice_Fails(t *testing.T) {
 	}
 	body, _ := json.Marshal(requestBody)
 	req, _ := http.NewRequest(http.MethodPut, "/orders/1/review", bytes.NewBuffer(body))
+	req.Header.Set("If-Match", "*")
 	req.Header.Set("Content-Type", "application/json")
 
 	// Execute request
@@ -1349,6 +1969,7 @@ func TestReviewOrder_Accept_WithZeroPrice_Fails(t *testing.T) {
 	}
 	body, _ := json.Marshal(requestBody)
 	req, _ := http.NewRequest(http.MethodPut, "/orders/1/review", bytes.NewBuffer(body))
+	req.Header.Set("If-Match", "*")
 	req.Header.Set("Content-Type", "application/json")
 
 	// Execute request
@@ -1412,6 +2033,7 @@ func TestReviewOrder_Reject_WithoutFeedback_Fails(t *testing.T) {
 	}
 	body, _ := json.Marshal(requestBody)
 	req, _ := http.NewRequest(http.MethodPut, "/orders/1/review", bytes.NewBuffer(body))
+	req.Header.Set("If-Match", "*")
 	req.Header.Set("Content-Type", "application/json")
 
 	// Execute request
@@ -1476,6 +2098,7 @@ func TestReviewOrder_Reject_WithEmptyFeedback_Fails(t *testing.T) {
 	}
 	body, _ := json.Marshal(requestBody)
 	req, _ := http.NewRequest(http.MethodPut, "/orders/1/review", bytes.NewBuffer(body))
+	req.Header.Set("If-Match", "*")
 	req.Header.Set("Content-Type", "application/json")
 
 	// Execute request
@@ -1543,6 +2166,7 @@ func TestReviewOrder_AlreadyReviewed_Fails(t *testing.T) {
 	}
 	body, _ := json.Marshal(requestBody)
 	req, _ := http.NewRequest(http.MethodPut, "/orders/1/review", bytes.NewBuffer(body))
+	req.Header.Set("If-Match", "*")
 	req.Header.Set("Content-Type", "application/json")
 
 	// Execute request
@@ -1607,6 +2231,7 @@ func TestReviewOrder_InvalidAction_Fails(t *testing.T) {
 	}
 	body, _ := json.Marshal(requestBody)
 	req, _ := http.NewRequest(http.MethodPut, "/orders/1/review", bytes.NewBuffer(body))
+	req.Header.Set("If-Match", "*")
 	req.Header.Set("Content-Type", "application/json")
 
 	// Execute request
@@ -1653,6 +2278,7 @@ func TestReviewOrder_OrderNotFound_Fails(t *testing.T) {
 	}
 	body, _ := json.Marshal(requestBody)
 	req, _ := http.NewRequest(http.MethodPut, "/orders/99999/review", bytes.NewBuffer(body))
+	req.Header.Set("If-Match", "*")
 	req.Header.Set("Content-Type", "application/json")
 
 	// Execute request
@@ -1706,6 +2332,7 @@ func TestReviewOrder_WithoutAuth_Fails(t *testing.T) {
 	}
 	body, _ := json.Marshal(requestBody)
 	req, _ := http.NewRequest(http.MethodPut, "/orders/1/review", bytes.NewBuffer(body))
+	req.Header.Set("If-Match", "*")
 	req.Header.Set("Content-Type", "application/json")
 
 	// Execute request
@@ -2012,6 +2639,111 @@ func TestAssignOrder_WithoutAuth_Fails(t *testing.T) {
 	assert.False(t, response["success"].(bool))
 }
 
+func TestBulkUpdateOrderStatus_PerOrderResults(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	technician := models.User{Auth0ID: "auth0|tech", Name: "Technician User", Email: "tech@example.com", Role: "technician"}
+	db.Create(&technician)
+	otherTech := models.User{Auth0ID: "auth0|tech2", Name: "Other Technician", Email: "tech2@example.com", Role: "technician"}
+	db.Create(&otherTech)
+
+	ready := models.Order{Description: "Ready", Quantity: 1, Status: "in_production", CustomerID: customer.ID, TechnicianID: &technician.ID}
+	db.Create(&ready)
+	notMine := models.Order{Description: "Not mine", Quantity: 1, Status: "in_production", CustomerID: customer.ID, TechnicianID: &otherTech.ID}
+	db.Create(&notMine)
+
+	// Register alongside the single-order route to make sure the paths do not clash
+	router := setupTestRouter()
+	auth := mockAuthMiddleware(technician.Auth0ID, "technician", "mock-token")
+	router.PUT("/orders/:id/status", auth, UpdateOrderStatus)
+	router.PUT("/orders/status/bulk", auth, BulkUpdateOrderStatus)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"order_ids": []uint{ready.ID, notMine.ID, 999},
+		"status":    "shipped",
+	})
+	req, _ := http.NewRequest(http.MethodPut, "/orders/status/bulk", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response["success"].(bool))
+
+	summary := response["summary"].(map[string]interface{})
+	assert.Equal(t, float64(3), summary["total"])
+	assert.Equal(t, float64(1), summary["succeeded"])
+	assert.Equal(t, float64(2), summary["failed"])
+
+	data := response["data"].([]interface{})
+	first := data[0].(map[string]interface{})
+	assert.True(t, first["success"].(bool))
+	assert.Equal(t, "shipped", first["order"].(map[string]interface{})["status"])
+
+	second := data[1].(map[string]interface{})
+	assert.False(t, second["success"].(bool))
+	assert.Equal(t, "FORBIDDEN", second["error"].(map[string]interface{})["code"])
+
+	third := data[2].(map[string]interface{})
+	assert.Equal(t, "ORDER_NOT_FOUND", third["error"].(map[string]interface{})["code"])
+
+	var shipped, untouched models.Order
+	db.First(&shipped, ready.ID)
+	assert.Equal(t, "shipped", shipped.Status)
+	db.First(&untouched, notMine.ID)
+	assert.Equal(t, "in_production", untouched.Status)
+
+	// Only the order that moved committed an event announcing it
+	var events []models.OutboxEvent
+	db.Find(&events)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, "order.status_changed", events[0].Name)
+	}
+}
+
+func TestBulkUpdateOrderStatus_InvalidRequest_Fails(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	technician := models.User{Auth0ID: "auth0|tech", Name: "Technician User", Email: "tech@example.com", Role: "technician"}
+	db.Create(&technician)
+
+	router := setupTestRouter()
+	router.PUT("/orders/status/bulk", mockAuthMiddleware(technician.Auth0ID, "technician", "mock-token"), BulkUpdateOrderStatus)
+
+	tests := []struct {
+		name string
+		body map[string]interface{}
+	}{
+		{"empty order list", map[string]interface{}{"order_ids": []uint{}, "status": "shipped"}},
+		{"invalid status", map[string]interface{}{"order_ids": []uint{1}, "status": "accepted"}},
+		{"zero order id", map[string]interface{}{"order_ids": []uint{0}, "status": "shipped"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(tt.body)
+			req, _ := http.NewRequest(http.MethodPut, "/orders/status/bulk", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
 func TestUpdateOrderStatus_ValidTransition_AcceptedToInProduction(t *testing.T) {
 	// Setup
 	db := setupOrderTestDB(t)
@@ -2059,6 +2791,7 @@ func TestUpdateOrderStatus_ValidTransition_AcceptedToInProduction(t *testing.T)
 	}
 	body, _ := json.Marshal(requestBody)
 	req, _ := http.NewRequest(http.MethodPut, "/orders/1/status", bytes.NewBuffer(body))
+	req.Header.Set("If-Match", "*")
 	req.Header.Set("Content-Type", "application/json")
 
 	// Execute request
@@ -2132,6 +2865,7 @@ func TestUpdateOrderStatus_ValidTransition_InProductionToShipped(t *testing.T) {
 	}
 	body, _ := json.Marshal(requestBody)
 	req, _ := http.NewRequest(http.MethodPut, "/orders/1/status", bytes.NewBuffer(body))
+	req.Header.Set("If-Match", "*")
 	req.Header.Set("Content-Type", "application/json")
 
 	// Execute request
@@ -2203,6 +2937,7 @@ func TestUpdateOrderStatus_ValidTransition_ShippedToDelivered(t *testing.T) {
 	}
 	body, _ := json.Marshal(requestBody)
 	req, _ := http.NewRequest(http.MethodPut, "/orders/1/status", bytes.NewBuffer(body))
+	req.Header.Set("If-Match", "*")
 	req.Header.Set("Content-Type", "application/json")
 
 	// Execute request
@@ -2274,6 +3009,7 @@ func TestUpdateOrderStatus_InvalidTransition_SkipStep(t *testing.T) {
 	}
 	body, _ := json.Marshal(requestBody)
 	req, _ := http.NewRequest(http.MethodPut, "/orders/1/status", bytes.NewBuffer(body))
+	req.Header.Set("If-Match", "*")
 	req.Header.Set("Content-Type", "application/json")
 
 	// Execute request
@@ -2350,6 +3086,7 @@ func TestUpdateOrderStatus_InvalidTransition_BackwardsTransition(t *testing.T) {
 	}
 	body, _ := json.Marshal(requestBody)
 	req, _ := http.NewRequest(http.MethodPut, "/orders/1/status", bytes.NewBuffer(body))
+	req.Header.Set("If-Match", "*")
 	req.Header.Set("Content-Type", "application/json")
 
 	// Execute request
@@ -2418,6 +3155,7 @@ func TestUpdateOrderStatus_FromSubmitted_Fails(t *testing.T) {
 	}
 	body, _ := json.Marshal(requestBody)
 	req, _ := http.NewRequest(http.MethodPut, "/orders/1/status", bytes.NewBuffer(body))
+	req.Header.Set("If-Match", "*")
 	req.Header.Set("Content-Type", "application/json")
 
 	// Execute request
@@ -2484,6 +3222,7 @@ func TestUpdateOrderStatus_FromRejected_Fails(t *testing.T) {
 	}
 	body, _ := json.Marshal(requestBody)
 	req, _ := http.NewRequest(http.MethodPut, "/orders/1/status", bytes.NewBuffer(body))
+	req.Header.Set("If-Match", "*")
 	req.Header.Set("Content-Type", "application/json")
 
 	// Execute request
@@ -2549,6 +3288,7 @@ func TestUpdateOrderStatus_FromDelivered_Fails(t *testing.T) {
 	}
 	body, _ := json.Marshal(requestBody)
 	req, _ := http.NewRequest(http.MethodPut, "/orders/1/status", bytes.NewBuffer(body))
+	req.Header.Set("If-Match", "*")
 	req.Header.Set("Content-Type", "application/json")
 
 	// Execute request
@@ -2614,6 +3354,7 @@ func TestUpdateOrderStatus_AsCustomer_Forbidden(t *testing.T) {
 	}
 	body, _ := json.Marshal(requestBody)
 	req, _ := http.NewRequest(http.MethodPut, "/orders/1/status", bytes.NewBuffer(body))
+	req.Header.Set("If-Match", "*")
 	req.Header.Set("Content-Type", "application/json")
 
 	// Execute request
@@ -2688,6 +3429,7 @@ func TestUpdateOrderStatus_NotAssignedToTechnician_Forbidden(t *testing.T) {
 	}
 	body, _ := json.Marshal(requestBody)
 	req, _ := http.NewRequest(http.MethodPut, "/orders/1/status", bytes.NewBuffer(body))
+	req.Header.Set("If-Match", "*")
 	req.Header.Set("Content-Type", "application/json")
 
 	// Execute request
@@ -2751,6 +3493,7 @@ func TestUpdateOrderStatus_UnassignedOrder_Forbidden(t *testing.T) {
 	}
 	body, _ := json.Marshal(requestBody)
 	req, _ := http.NewRequest(http.MethodPut, "/orders/1/status", bytes.NewBuffer(body))
+	req.Header.Set("If-Match", "*")
 	req.Header.Set("Content-Type", "application/json")
 
 	// Execute request
@@ -2816,6 +3559,7 @@ func TestUpdateOrderStatus_InvalidStatusValue_Fails(t *testing.T) {
 	}
 	body, _ := json.Marshal(requestBody)
 	req, _ := http.NewRequest(http.MethodPut, "/orders/1/status", bytes.NewBuffer(body))
+	req.Header.Set("If-Match", "*")
 	req.Header.Set("Content-Type", "application/json")
 
 	// Execute request
@@ -2879,6 +3623,7 @@ func TestUpdateOrderStatus_MissingStatus_Fails(t *testing.T) {
 	requestBody := map[string]interface{}{}
 	body, _ := json.Marshal(requestBody)
 	req, _ := http.NewRequest(http.MethodPut, "/orders/1/status", bytes.NewBuffer(body))
+	req.Header.Set("If-Match", "*")
 	req.Header.Set("Content-Type", "application/json")
 
 	// Execute request
@@ -2924,6 +3669,7 @@ func TestUpdateOrderStatus_OrderNotFound_Fails(t *testing.T) {
 	}
 	body, _ := json.Marshal(requestBody)
 	req, _ := http.NewRequest(http.MethodPut, "/orders/99999/status", bytes.NewBuffer(body))
+	req.Header.Set("If-Match", "*")
 	req.Header.Set("Content-Type", "application/json")
 
 	// Execute request
@@ -2958,6 +3704,7 @@ func TestUpdateOrderStatus_WithoutAuth_Fails(t *testing.T) {
 	}
 	body, _ := json.Marshal(requestBody)
 	req, _ := http.NewRequest(http.MethodPut, "/orders/1/status", bytes.NewBuffer(body))
+	req.Header.Set("If-Match", "*")
 	req.Header.Set("Content-Type", "application/json")
 
 	// Execute request
@@ -2972,3 +3719,298 @@ func TestUpdateOrderStatus_WithoutAuth_Fails(t *testing.T) {
 	assert.NoError(t, err)
 	assert.False(t, response["success"].(bool))
 }
+
+func TestCreateOrderBatch(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	technician := models.User{Auth0ID: "auth0|tech", Name: "Technician User", Email: "tech@example.com", Role: "technician"}
+	db.Create(&technician)
+
+	router := setupTestRouter()
+	router.POST("/orders/batch", mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token"), CreateOrderBatch)
+	router.POST("/tech/orders/batch", mockAuthMiddleware(technician.Auth0ID, "technician", "mock-token"), CreateOrderBatch)
+
+	post := func(path string, body interface{}) (int, map[string]interface{}) {
+		data, _ := json.Marshal(body)
+		req, _ := http.NewRequest(http.MethodPost, path, bytes.NewReader(data))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response
+	}
+	countOrders := func() int64 {
+		var count int64
+		db.Model(&models.Order{}).Count(&count)
+		return count
+	}
+
+	t.Run("creates every order in the batch", func(t *testing.T) {
+		code, response := post("/orders/batch", gin.H{"orders": []gin.H{
+			{"description": "Bride - white chrome", "quantity": 1, "rush": true},
+			{"description": "Bridesmaids - blush", "quantity": 5, "requested_by": time.Now().AddDate(0, 1, 0).Format(time.DateOnly)},
+		}})
+		require.Equal(t, http.StatusCreated, code)
+		data := response["data"].([]interface{})
+		require.Len(t, data, 2)
+		assert.Equal(t, "Bride - white chrome", data[0].(map[string]interface{})["description"])
+		assert.Equal(t, float64(5), data[1].(map[string]interface{})["quantity"])
+		assert.Equal(t, "submitted", data[1].(map[string]interface{})["status"])
+		assert.Equal(t, int64(2), countOrders())
+	})
+
+	t.Run("one invalid order creates none and names its index", func(t *testing.T) {
+		code, response := post("/orders/batch", gin.H{"orders": []gin.H{
+			{"description": "Flower girl", "quantity": 1},
+			{"quantity": 1, "catalog_design_id": 999},
+		}})
+		assert.Equal(t, http.StatusUnprocessableEntity, code)
+		errObj := response["error"].(map[string]interface{})
+		assert.Equal(t, "INVALID_CATALOG_DESIGN", errObj["code"])
+		assert.Equal(t, float64(1), errObj["details"].(map[string]interface{})["index"])
+		assert.Equal(t, int64(2), countOrders())
+	})
+
+	t.Run("invalid fields are reported by their path in the batch", func(t *testing.T) {
+		code, response := post("/orders/batch", gin.H{"orders": []gin.H{
+			{"description": "Mother of the bride", "quantity": 1},
+			{"description": "Maid of honor", "quantity": 0},
+		}})
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Contains(t, fmt.Sprint(response["error"]), "orders[1].quantity")
+
+		code, response = post("/orders/batch", gin.H{"orders": []gin.H{
+			{"description": "Maid of honor", "quantity": 1, "requested_by": "next week"},
+		}})
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Contains(t, fmt.Sprint(response["error"]), "orders[0].requested_by")
+		assert.Equal(t, int64(2), countOrders())
+	})
+
+	t.Run("empty and oversized batches are rejected", func(t *testing.T) {
+		code, _ := post("/orders/batch", gin.H{"orders": []gin.H{}})
+		assert.Equal(t, http.StatusBadRequest, code)
+
+		orders := make([]gin.H, services.MaxOrderBatchSize+1)
+		for i := range orders {
+			orders[i] = gin.H{"description": "Guest", "quantity": 1}
+		}
+		code, _ = post("/orders/batch", gin.H{"orders": orders})
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("only customers create orders", func(t *testing.T) {
+		code, _ := post("/tech/orders/batch", gin.H{"orders": []gin.H{{"description": "Pink", "quantity": 1}}})
+		assert.Equal(t, http.StatusForbidden, code)
+	})
+}
+
+func TestDuplicateOrder(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	previous := services.GetImageService()
+	mockImage := services.NewMockImageService()
+	mockImage.SetAsMockForTesting()
+	defer services.SetImageService(previous)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	other := models.User{Auth0ID: "auth0|other", Name: "Other Customer", Email: "other@example.com", Role: "customer"}
+	db.Create(&other)
+	imageKey := "orders/chrome.png"
+	order := models.Order{Description: "Chrome coffin", Quantity: 3, Status: "rejected", CustomerID: customer.ID, ImageS3Key: &imageKey}
+	db.Create(&order)
+
+	router := setupTestRouter()
+	router.POST("/orders/:id/duplicate", mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token"), DuplicateOrder)
+	router.POST("/other/orders/:id/duplicate", mockAuthMiddleware(other.Auth0ID, "customer", "mock-token"), DuplicateOrder)
+
+	post := func(path string) (int, map[string]interface{}) {
+		req, _ := http.NewRequest(http.MethodPost, path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response
+	}
+
+	code, _ := post(fmt.Sprintf("/other/orders/%d/duplicate", order.ID))
+	assert.Equal(t, http.StatusForbidden, code)
+
+	// Rejected orders cannot be reordered, but they can be duplicated
+	code, response := post(fmt.Sprintf("/orders/%d/duplicate", order.ID))
+	assert.Equal(t, http.StatusCreated, code)
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, "submitted", data["status"])
+	assert.Equal(t, "Chrome coffin", data["description"])
+	assert.Equal(t, float64(3), data["quantity"])
+	assert.Equal(t, imageKey, data["image_s3_key"])
+	assert.Equal(t, float64(order.ID), data["original_order_id"])
+}
+
+func TestGetOrderLineage(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	original := models.Order{Description: "Chrome coffin", Quantity: 1, Status: "delivered", CustomerID: customer.ID}
+	db.Create(&original)
+	reorder := models.Order{Description: "Chrome coffin", Quantity: 2, Status: "delivered", CustomerID: customer.ID, OriginalOrderID: &original.ID}
+	db.Create(&reorder)
+	again := models.Order{Description: "Chrome coffin", Quantity: 1, Status: "submitted", CustomerID: customer.ID, OriginalOrderID: &reorder.ID}
+	db.Create(&again)
+
+	router := setupTestRouter()
+	router.GET("/orders/:id/lineage", mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token"), GetOrderLineage)
+
+	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/orders/%d/lineage", reorder.ID), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, float64(original.ID), data["root_order_id"])
+	assert.Equal(t, float64(3), data["count"])
+	orders := data["orders"].([]interface{})
+	if assert.Len(t, orders, 3) {
+		assert.Equal(t, float64(again.ID), orders[2].(map[string]interface{})["id"])
+		assert.Equal(t, float64(reorder.ID), orders[2].(map[string]interface{})["original_order_id"])
+	}
+}
+
+func TestUpdateOrderStatus_IfMatch(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	technician := models.User{Auth0ID: "auth0|tech", Name: "Technician User", Email: "tech@example.com", Role: "technician"}
+	db.Create(&technician)
+	order := models.Order{Description: "Two tabs", Quantity: 1, Status: "in_production", CustomerID: customer.ID, TechnicianID: &technician.ID}
+	db.Create(&order)
+
+	router := setupTestRouter()
+	auth := mockAuthMiddleware(technician.Auth0ID, "technician", "mock-token")
+	router.GET("/orders/:id", auth, GetOrder)
+	router.PUT("/orders/:id/status", auth, UpdateOrderStatus)
+
+	path := fmt.Sprintf("/orders/%d", order.ID)
+	updateStatus := func(status, ifMatch string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		body, _ := json.Marshal(map[string]string{"status": status})
+		req, _ := http.NewRequest(http.MethodPut, path+"/status", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w, response
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, path, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	loaded := w.Header().Get("ETag")
+	assert.True(t, strings.HasPrefix(loaded, `"1-`), "the ETag starts with the order version, got %s", loaded)
+
+	// Status changes must say which version they apply to
+	w, response := updateStatus("shipped", "")
+	assert.Equal(t, http.StatusPreconditionRequired, w.Code)
+	assert.Equal(t, "PRECONDITION_REQUIRED", response["error"].(map[string]interface{})["code"])
+	w, _ = updateStatus("shipped", `W/"1"`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// The first tab ships the order and gets the new version back
+	w, _ = updateStatus("shipped", loaded)
+	assert.Equal(t, http.StatusOK, w.Code)
+	shipped := w.Header().Get("ETag")
+	assert.True(t, strings.HasPrefix(shipped, `"2-`))
+
+	// The second tab still holds the old version, so its change is refused with the current order
+	w, response = updateStatus("delivered", loaded)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	failure := response["error"].(map[string]interface{})
+	assert.Equal(t, "ORDER_CHANGED", failure["code"])
+	assert.Equal(t, "shipped", failure["details"].(map[string]interface{})["order"].(map[string]interface{})["status"])
+	assert.Equal(t, float64(2), failure["details"].(map[string]interface{})["order"].(map[string]interface{})["version"])
+	assert.Equal(t, shipped, w.Header().Get("ETag"))
+
+	var stored models.Order
+	db.First(&stored, order.ID)
+	assert.Equal(t, "shipped", stored.Status)
+
+	// Once it has seen the current order it can go ahead, here giving just the version
+	w, _ = updateStatus("delivered", `"2"`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	db.First(&stored, order.ID)
+	assert.Equal(t, "delivered", stored.Status)
+	assert.Equal(t, uint(3), stored.Version)
+}
+
+func TestCreateOrder_BackgroundImageUpload(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	storage := services.NewMockStorage()
+	previous := services.GetImageService()
+	services.InitImageService(storage)
+	defer services.SetImageService(previous)
+	transfers, err := services.NewImageTransferService(repositories.NewImageTransferRepository(db), storage, t.TempDir())
+	require.NoError(t, err)
+	services.SetImageTransferService(transfers)
+	defer services.SetImageTransferService(nil)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+
+	router := setupTestRouter()
+	auth := mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token")
+	router.POST("/orders", auth, CreateOrder)
+	router.GET("/orders/:id", auth, GetOrder)
+
+	// The order is created without waiting for storage; its image is reported as uploading
+	body, contentType := newProgressUpdateForm(t, "design.png", map[string]string{"description": "Floral set", "quantity": "1"})
+	req, _ := http.NewRequest(http.MethodPost, "/orders", body)
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	data := response["data"].(map[string]interface{})
+	assert.Nil(t, data["image_s3_key"])
+	assert.Equal(t, models.ImageUploading, data["image_status"])
+	assert.Empty(t, storage.GetUploadedFiles())
+
+	// Once the background transfer has run, the order has its image
+	attempted, err := transfers.TransferDue(10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, attempted)
+
+	req, _ = http.NewRequest(http.MethodGet, fmt.Sprintf("/orders/%v", data["id"]), nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	data = response["data"].(map[string]interface{})
+	key, _ := data["image_s3_key"].(string)
+	assert.Regexp(t, `^uploads/\d+_design\.png$`, key)
+	assert.True(t, storage.FileExists(key))
+	assert.Contains(t, data["image_url"], key)
+	assert.NotContains(t, data, "image_status")
+}
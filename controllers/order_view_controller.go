@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/apiresponse"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+)
+
+// OrderViewRequest represents the request body for creating or updating a saved order view
+// Dates are RFC 3339 timestamps, like the order list's own date filters
+type OrderViewRequest struct {
+	Name              string     `json:"name" binding:"required"`
+	Statuses          []string   `json:"statuses"`
+	CreatedAfter      *time.Time `json:"created_after"`
+	CreatedBefore     *time.Time `json:"created_before"`
+	CreatedWithinDays *int       `json:"created_within_days"`
+	Sort              string     `json:"sort"`
+	Order             string     `json:"order"`
+}
+
+// input converts the request into service input
+func (r OrderViewRequest) input() services.OrderViewInput {
+	utc := func(t *time.Time) *time.Time {
+		if t == nil {
+			return nil
+		}
+		converted := t.UTC()
+		return &converted
+	}
+	return services.OrderViewInput{
+		Name:              r.Name,
+		Statuses:          r.Statuses,
+		CreatedAfter:      utc(r.CreatedAfter),
+		CreatedBefore:     utc(r.CreatedBefore),
+		CreatedWithinDays: r.CreatedWithinDays,
+		Sort:              r.Sort,
+		Order:             strings.ToLower(r.Order),
+	}
+}
+
+// ListMyViews handles GET /api/v1/users/me/views - lists the current user's saved order views
+func ListMyViews(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	views, err := services.GetOrderViewService().ListViews(user)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, views)
+}
+
+// CreateMyView handles POST /api/v1/users/me/views - saves a named set of order list filters
+func CreateMyView(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req OrderViewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	view, err := services.GetOrderViewService().CreateView(user, req.input())
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.Created(c, view)
+}
+
+// UpdateMyView handles PUT /api/v1/users/me/views/:id - replaces one of the current user's saved views
+func UpdateMyView(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req OrderViewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	view, err := services.GetOrderViewService().UpdateView(user, c.Param("id"), req.input())
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, view)
+}
+
+// DeleteMyView handles DELETE /api/v1/users/me/views/:id - removes one of the current user's saved views
+func DeleteMyView(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := services.GetOrderViewService().DeleteView(user, c.Param("id")); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.Message(c, http.StatusOK, "View deleted")
+}
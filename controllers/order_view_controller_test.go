@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderViews(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	technician := models.User{Auth0ID: "auth0|tech", Name: "Technician User", Email: "tech@example.com", Role: "technician"}
+	db.Create(&technician)
+
+	price := func(amount float64) *float64 { return &amount }
+	for _, order := range []models.Order{
+		{Description: "Chrome coffin", Quantity: 1, Status: "in_production", Price: price(50), CustomerID: customer.ID, TechnicianID: &technician.ID},
+		{Description: "French tips", Quantity: 1, Status: "in_production", Price: price(30), CustomerID: customer.ID, TechnicianID: &technician.ID},
+		{Description: "Glitter ombre", Quantity: 1, Status: "accepted", Price: price(40), CustomerID: customer.ID, TechnicianID: &technician.ID},
+		{Description: "Matte black", Quantity: 1, Status: "submitted", CustomerID: customer.ID},
+	} {
+		db.Create(&order)
+	}
+
+	router := setupTestRouter()
+	techAuth := mockAuthMiddleware(technician.Auth0ID, "technician", "mock-token")
+	router.GET("/users/me/views", techAuth, ListMyViews)
+	router.POST("/users/me/views", techAuth, CreateMyView)
+	router.PUT("/users/me/views/:id", techAuth, UpdateMyView)
+	router.DELETE("/users/me/views/:id", techAuth, DeleteMyView)
+	router.GET("/orders", techAuth, ListOrders)
+	router.GET("/customer/orders", mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token"), ListOrders)
+
+	request := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			json.NewEncoder(&buf).Encode(body)
+		}
+		req, _ := http.NewRequest(method, path, &buf)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	descriptions := func(w *httptest.ResponseRecorder) []string {
+		var response struct {
+			Data []models.Order `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		var result []string
+		for _, order := range response.Data {
+			result = append(result, order.Description)
+		}
+		return result
+	}
+
+	production := gin.H{"name": "Today's production", "statuses": []string{"in_production"}, "created_within_days": 1, "sort": "price", "order": "asc"}
+
+	w := request(http.MethodPost, "/users/me/views", production)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created struct {
+		Data models.OrderView `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	view := created.Data
+	assert.Equal(t, []string{"in_production"}, view.Statuses)
+
+	t.Run("views are validated like the list filters", func(t *testing.T) {
+		assert.Equal(t, http.StatusConflict, request(http.MethodPost, "/users/me/views", production).Code)
+		assert.Equal(t, http.StatusBadRequest, request(http.MethodPost, "/users/me/views", gin.H{"name": "Bad", "statuses": []string{"lost"}}).Code)
+		assert.Equal(t, http.StatusBadRequest, request(http.MethodPost, "/users/me/views", gin.H{"name": "Bad", "sort": "customer_id"}).Code)
+		assert.Equal(t, http.StatusBadRequest, request(http.MethodPost, "/users/me/views", gin.H{"name": "Bad", "created_within_days": 0}).Code)
+		assert.Equal(t, http.StatusBadRequest, request(http.MethodPost, "/users/me/views", gin.H{"statuses": []string{"accepted"}}).Code)
+	})
+
+	t.Run("listing orders with a view applies its filters and sort", func(t *testing.T) {
+		w := request(http.MethodGet, fmt.Sprintf("/orders?view=%d", view.ID), nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, []string{"French tips", "Chrome coffin"}, descriptions(w))
+
+		// Query parameters override the view
+		w = request(http.MethodGet, fmt.Sprintf("/orders?view=%d&order=desc", view.ID), nil)
+		assert.Equal(t, []string{"Chrome coffin", "French tips"}, descriptions(w))
+		w = request(http.MethodGet, fmt.Sprintf("/orders?view=%d&status=accepted,submitted", view.ID), nil)
+		assert.ElementsMatch(t, []string{"Glitter ombre", "Matte black"}, descriptions(w))
+
+		// Views belong to the user who saved them
+		assert.Equal(t, http.StatusNotFound, request(http.MethodGet, fmt.Sprintf("/customer/orders?view=%d", view.ID), nil).Code)
+		assert.Equal(t, http.StatusBadRequest, request(http.MethodGet, "/orders?status=lost", nil).Code)
+	})
+
+	t.Run("views can be renamed, changed, and deleted", func(t *testing.T) {
+		w := request(http.MethodPut, fmt.Sprintf("/users/me/views/%d", view.ID), gin.H{"name": "Ready to start", "statuses": []string{"accepted"}})
+		require.Equal(t, http.StatusOK, w.Code)
+		w = request(http.MethodGet, fmt.Sprintf("/orders?view=%d", view.ID), nil)
+		assert.Equal(t, []string{"Glitter ombre"}, descriptions(w))
+
+		w = request(http.MethodGet, "/users/me/views", nil)
+		assert.Contains(t, w.Body.String(), "Ready to start")
+
+		assert.Equal(t, http.StatusOK, request(http.MethodDelete, fmt.Sprintf("/users/me/views/%d", view.ID), nil).Code)
+		assert.Equal(t, http.StatusNotFound, request(http.MethodGet, fmt.Sprintf("/orders?view=%d", view.ID), nil).Code)
+	})
+}
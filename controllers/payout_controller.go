@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/apiresponse"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+)
+
+// PayEarningsRequest represents the request body for marking technicians' earnings as paid
+type PayEarningsRequest struct {
+	TechnicianID *uint   `json:"technician_id"`                         // optional, pays every technician when omitted
+	From         string  `json:"from" binding:"required"`               // first delivery date paid, YYYY-MM-DD
+	To           string  `json:"to" binding:"required"`                 // last delivery date paid, YYYY-MM-DD, inclusive
+	Reference    *string `json:"reference" binding:"omitempty,max=255"` // e.g. a bank transfer ID
+}
+
+// PayEarnings handles POST /api/v1/admin/payouts - marks orders delivered between two dates as paid (admin only)
+// One payout is recorded per technician and currency
+func PayEarnings(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req PayEarningsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	from, fromErr := time.Parse(time.DateOnly, req.From)
+	to, toErr := time.Parse(time.DateOnly, req.To)
+	if fromErr != nil || toErr != nil {
+		apierror.Respond(c, apierror.Validation("Invalid payout period", map[string]string{
+			"from": "from and to must be dates such as 2026-01-31",
+		}))
+		return
+	}
+
+	payouts, err := services.GetPayoutService().PayEarnings(user, services.PayEarningsInput{
+		TechnicianID: req.TechnicianID,
+		From:         from,
+		To:           to.AddDate(0, 0, 1), // include the whole last day
+		Reference:    req.Reference,
+	})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.Created(c, payouts)
+}
+
+// ListUnpaidBalances handles GET /api/v1/admin/payouts/balances - what each technician is owed (admin only)
+func ListUnpaidBalances(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	balances, err := services.GetPayoutService().ListUnpaidBalances(user)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, balances)
+}
+
+// ListMyPayouts handles GET /api/v1/users/me/payouts - the technician's payout history and unpaid balance
+func ListMyPayouts(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	history, err := services.GetPayoutService().ListMyPayouts(user)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, history)
+}
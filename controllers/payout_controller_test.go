@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayouts(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	technician := models.User{Auth0ID: "auth0|tech", Name: "Technician User", Email: "tech@example.com", Role: "technician"}
+	db.Create(&technician)
+	otherTech := models.User{Auth0ID: "auth0|tech2", Name: "Another Technician", Email: "tech2@example.com", Role: "technician"}
+	db.Create(&otherTech)
+	admin := models.User{Auth0ID: "auth0|admin", Name: "Admin User", Email: "admin@example.com", Role: "admin"}
+	db.Create(&admin)
+
+	price := func(amount float64) *float64 { return &amount }
+	day := func(value string) *time.Time {
+		parsed, _ := time.Parse(time.DateTime, value)
+		return &parsed
+	}
+	deliver := func(technicianID uint, amount float64, currency string, deliveredAt *time.Time) models.Order {
+		order := models.Order{Description: "Chrome coffin", Quantity: 1, Status: "delivered", Currency: currency, Price: price(amount),
+			CustomerID: customer.ID, TechnicianID: &technicianID, DeliveredAt: deliveredAt}
+		db.Create(&order)
+		return order
+	}
+	first := deliver(technician.ID, 40.10, "USD", day("2026-05-04 10:00:00"))
+	second := deliver(technician.ID, 20.20, "USD", day("2026-05-10 23:30:00"))
+	euro := deliver(technician.ID, 25, "EUR", day("2026-05-06 09:00:00"))
+	others := deliver(otherTech.ID, 50, "USD", day("2026-05-05 09:00:00"))
+	later := deliver(technician.ID, 35, "USD", day("2026-05-11 09:00:00"))
+	db.Create(&models.Order{Description: "Glitter ombre", Quantity: 1, Status: "in_production", Currency: "USD", Price: price(15),
+		CustomerID: customer.ID, TechnicianID: &technician.ID})
+
+	router := setupTestRouter()
+	adminAuth := mockAuthMiddleware(admin.Auth0ID, "admin", "mock-token")
+	techAuth := mockAuthMiddleware(technician.Auth0ID, "technician", "mock-token")
+	router.POST("/admin/payouts", adminAuth, PayEarnings)
+	router.GET("/admin/payouts/balances", adminAuth, ListUnpaidBalances)
+	router.GET("/users/me/payouts", techAuth, ListMyPayouts)
+	router.POST("/tech/admin/payouts", techAuth, PayEarnings)
+	router.GET("/customer/payouts", mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token"), ListMyPayouts)
+
+	request := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewReader(data))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	decode := func(w *httptest.ResponseRecorder, data interface{}) {
+		var response struct {
+			Data json.RawMessage `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.NoError(t, json.Unmarshal(response.Data, data))
+	}
+
+	week := gin.H{"technician_id": technician.ID, "from": "2026-05-04", "to": "2026-05-10", "reference": "TRF-1042"}
+
+	// Only admins record payouts
+	assert.Equal(t, http.StatusForbidden, request(http.MethodPost, "/tech/admin/payouts", week).Code)
+	assert.Equal(t, http.StatusBadRequest, request(http.MethodPost, "/admin/payouts", gin.H{"from": "May 4", "to": "2026-05-10"}).Code)
+	assert.Equal(t, http.StatusForbidden, request(http.MethodGet, "/customer/payouts", nil).Code)
+
+	// The week's deliveries are paid, one payout per currency; the last day counts in full
+	w := request(http.MethodPost, "/admin/payouts", week)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var payouts []models.Payout
+	decode(w, &payouts)
+	require.Len(t, payouts, 2)
+	assert.Equal(t, "EUR", payouts[0].Currency)
+	assert.Equal(t, 25.0, payouts[0].Amount)
+	assert.Equal(t, "USD", payouts[1].Currency)
+	assert.Equal(t, 60.30, payouts[1].Amount)
+	assert.Equal(t, 2, payouts[1].OrderCount)
+	require.NotNil(t, payouts[1].Reference)
+	assert.Equal(t, "TRF-1042", *payouts[1].Reference)
+
+	for _, order := range []models.Order{first, second, euro} {
+		var paid models.Order
+		db.First(&paid, order.ID)
+		assert.NotNil(t, paid.PayoutID, "order %d should be paid", order.ID)
+	}
+	for _, order := range []models.Order{others, later} {
+		var unpaid models.Order
+		db.First(&unpaid, order.ID)
+		assert.Nil(t, unpaid.PayoutID, "order %d should not be paid", order.ID)
+	}
+
+	// Paying the same period again finds nothing to pay
+	w = request(http.MethodPost, "/admin/payouts", week)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "NO_UNPAID_EARNINGS")
+
+	// The technician sees the history and what is still owed
+	w = request(http.MethodGet, "/users/me/payouts", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var history services.PayoutHistory
+	decode(w, &history)
+	assert.Len(t, history.Payouts, 2)
+	assert.Equal(t, []services.EarningsTotal{{Currency: "USD", Orders: 1, Amount: 35}}, history.Unpaid)
+
+	// Admins see every technician's unpaid balance
+	w = request(http.MethodGet, "/admin/payouts/balances", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var balances []repositories.UnpaidBalance
+	decode(w, &balances)
+	assert.Equal(t, []repositories.UnpaidBalance{
+		{TechnicianID: otherTech.ID, TechnicianName: "Another Technician", Currency: "USD", Orders: 1, Amount: 50},
+		{TechnicianID: technician.ID, TechnicianName: "Technician User", Currency: "USD", Orders: 1, Amount: 35},
+	}, balances)
+
+	// Every payout is in the audit log
+	var audited int64
+	db.Model(&models.AuditLog{}).Where("action = ?", models.AuditPayoutRecorded).Count(&audited)
+	assert.Equal(t, int64(2), audited)
+}
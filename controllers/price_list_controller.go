@@ -0,0 +1,98 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/apiresponse"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+)
+
+// CreatePriceListRequest represents the request body for scheduling a price list
+// Without starts_at the price list starts immediately
+type CreatePriceListRequest struct {
+	Name              string     `json:"name" binding:"required,max=100"`
+	AdjustmentPercent *float64   `json:"adjustment_percent" binding:"required"`
+	StartsAt          *time.Time `json:"starts_at"`
+	EndsAt            time.Time  `json:"ends_at" binding:"required"`
+}
+
+// CreatePriceList handles POST /api/v1/price-lists - schedules a seasonal price list (technicians only)
+func CreatePriceList(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req CreatePriceListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	input := services.PriceListInput{
+		Name:              req.Name,
+		AdjustmentPercent: *req.AdjustmentPercent,
+		EndsAt:            req.EndsAt,
+	}
+	if req.StartsAt != nil {
+		input.StartsAt = *req.StartsAt
+	}
+
+	priceList, err := services.GetPriceListService().CreatePriceList(user, input)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.Created(c, priceList)
+}
+
+// ListPriceLists handles GET /api/v1/price-lists - lists the technician's past, current, and scheduled price lists
+func ListPriceLists(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	priceLists, err := services.GetPriceListService().ListPriceLists(user)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, priceLists)
+}
+
+// EndPriceList handles POST /api/v1/price-lists/:id/end - ends the price list in force early
+func EndPriceList(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	priceList, err := services.GetPriceListService().EndPriceList(user, c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, priceList)
+}
+
+// DeletePriceList handles DELETE /api/v1/price-lists/:id - removes a price list that has not started
+func DeletePriceList(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := services.GetPriceListService().DeletePriceList(user, c.Param("id")); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.Message(c, http.StatusOK, "Price list deleted")
+}
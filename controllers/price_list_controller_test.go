@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriceLists(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	technician := models.User{Auth0ID: "auth0|tech", Name: "Technician User", Email: "tech@example.com", Role: "technician"}
+	db.Create(&technician)
+	first := models.Order{Description: "Holiday set", Quantity: 1, Status: "submitted", CustomerID: customer.ID, Rush: true, ShippingAddress: testShippingAddress}
+	db.Create(&first)
+	second := models.Order{Description: "Regular set", Quantity: 1, Status: "submitted", CustomerID: customer.ID, ShippingAddress: testShippingAddress}
+	db.Create(&second)
+
+	router := setupTestRouter()
+	auth := mockAuthMiddleware(technician.Auth0ID, "technician", "mock-token")
+	router.POST("/price-lists", auth, CreatePriceList)
+	router.GET("/price-lists", auth, ListPriceLists)
+	router.POST("/price-lists/:id/end", auth, EndPriceList)
+	router.DELETE("/price-lists/:id", auth, DeletePriceList)
+	router.PUT("/orders/:id/review", auth, ReviewOrder)
+	router.POST("/customer/price-lists", mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token"), CreatePriceList)
+
+	request := func(method, path string, body interface{}) (*httptest.ResponseRecorder, map[string]interface{}) {
+		payload, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", "*")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w, response
+	}
+	now := time.Now().UTC()
+	holiday := map[string]interface{}{"name": "Holiday surcharge", "adjustment_percent": 20, "ends_at": now.Add(24 * time.Hour)}
+
+	// Only technicians schedule price lists, with a non-zero adjustment
+	w, _ := request(http.MethodPost, "/customer/price-lists", holiday)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w, _ = request(http.MethodPost, "/price-lists", map[string]interface{}{"name": "Nothing", "adjustment_percent": 0, "ends_at": now.Add(time.Hour)})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w, response := request(http.MethodPost, "/price-lists", holiday)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	holidayID := uint(response["data"].(map[string]interface{})["id"].(float64))
+
+	// Price lists of a technician cannot overlap
+	w, _ = request(http.MethodPost, "/price-lists", map[string]interface{}{"name": "Sale", "adjustment_percent": -10, "starts_at": now.Add(12 * time.Hour), "ends_at": now.Add(48 * time.Hour)})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w, response = request(http.MethodPost, "/price-lists", map[string]interface{}{"name": "Summer sale", "adjustment_percent": -15, "starts_at": now.Add(48 * time.Hour), "ends_at": now.Add(96 * time.Hour)})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	saleID := uint(response["data"].(map[string]interface{})["id"].(float64))
+
+	// The price list in force adjusts the base set and add-ons, but not the rush fee
+	w, response = request(http.MethodPut, fmt.Sprintf("/orders/%d/review", first.ID), map[string]interface{}{"action": "accept", "price": 40.0, "rush_fee": 10.0})
+	assert.Equal(t, http.StatusOK, w.Code)
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, 58.0, data["price"])
+	assert.Equal(t, float64(holidayID), data["price_list_id"])
+	lineItems := data["line_items"].([]interface{})
+	if assert.Len(t, lineItems, 3) {
+		adjustment := lineItems[1].(map[string]interface{})
+		assert.Equal(t, "price_adjustment", adjustment["kind"])
+		assert.Equal(t, "Holiday surcharge (+20%)", adjustment["description"])
+		assert.Equal(t, 8.0, adjustment["amount"])
+	}
+
+	// A started price list is ended rather than deleted, and no longer applies
+	w, _ = request(http.MethodDelete, fmt.Sprintf("/price-lists/%d", holidayID), nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	w, _ = request(http.MethodPost, fmt.Sprintf("/price-lists/%d/end", holidayID), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w, response = request(http.MethodPut, fmt.Sprintf("/orders/%d/review", second.ID), map[string]interface{}{"action": "accept", "price": 40.0})
+	assert.Equal(t, http.StatusOK, w.Code)
+	data = response["data"].(map[string]interface{})
+	assert.Equal(t, 40.0, data["price"])
+	assert.Nil(t, data["price_list_id"])
+
+	// The accepted order still references the ended price list
+	var stored models.Order
+	db.First(&stored, first.ID)
+	assert.Equal(t, holidayID, *stored.PriceListID)
+
+	// A scheduled price list can be deleted before it starts
+	w, _ = request(http.MethodPost, fmt.Sprintf("/price-lists/%d/end", saleID), nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	w, _ = request(http.MethodDelete, fmt.Sprintf("/price-lists/%d", saleID), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w, response = request(http.MethodGet, "/price-lists", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, response["data"], 1)
+}
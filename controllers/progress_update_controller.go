@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/apiresponse"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+)
+
+// populateProgressUpdateImageURLs generates presigned URLs for progress photos
+func populateProgressUpdateImageURLs(updates []models.ProgressUpdate) {
+	imageService := services.GetImageService()
+	for i := range updates {
+		if url, err := imageService.GetImageURL(updates[i].ImageS3Key); err == nil {
+			updates[i].ImageURL = &url
+		}
+	}
+}
+
+// PostProgressUpdate handles POST /api/v1/orders/:id/updates - posts a progress photo to the order timeline (assigned technician only)
+// Expects multipart form data with an "image" file, an optional "caption", and an optional "notify" flag
+func PostProgressUpdate(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	// Check permissions before parsing so that no image is uploaded for a forbidden request
+	updateService := services.GetProgressUpdateService()
+	if err := updateService.AuthorizePost(user, c.Param("id")); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	input := services.ProgressUpdateInput{Caption: c.PostForm("caption")}
+	if notifyStr := c.PostForm("notify"); notifyStr != "" {
+		notify, err := strconv.ParseBool(notifyStr)
+		if err != nil {
+			apierror.Respond(c, apierror.Validation("Notify must be true or false", nil))
+			return
+		}
+		input.Notify = notify
+	}
+
+	fileHeader, err := c.FormFile("image")
+	if err != nil {
+		apierror.Respond(c, apierror.Validation("Image is required", nil))
+		return
+	}
+	imageKey, ok := uploadImage(c, fileHeader)
+	if !ok {
+		return
+	}
+	input.ImageS3Key = imageKey
+
+	update, err := updateService.PostUpdate(user, c.Param("id"), input)
+	if err != nil {
+		// The photo was never attached, so don't leave it in storage
+		if deleteErr := services.GetImageService().DeleteImage(imageKey); deleteErr != nil {
+			log.Printf("Failed to delete orphaned progress photo %s: %v", imageKey, deleteErr)
+		}
+		apierror.Respond(c, err)
+		return
+	}
+
+	updates := []models.ProgressUpdate{*update}
+	populateProgressUpdateImageURLs(updates)
+
+	apiresponse.Created(c, updates[0])
+}
+
+// ListProgressUpdates handles GET /api/v1/orders/:id/updates - returns the order's progress photo timeline
+func ListProgressUpdates(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	updates, err := services.GetProgressUpdateService().ListUpdates(user, c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	populateProgressUpdateImageURLs(updates)
+
+	apiresponse.OK(c, updates)
+}
+
+// DeleteProgressUpdate handles DELETE /api/v1/orders/:id/updates/:updateId - removes a progress photo (assigned technician only)
+func DeleteProgressUpdate(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	update, err := services.GetProgressUpdateService().DeleteUpdate(user, c.Param("id"), c.Param("updateId"))
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	// The update is already gone from the timeline, so a storage failure only leaves an orphaned file
+	if err := services.GetImageService().DeleteImage(update.ImageS3Key); err != nil {
+		log.Printf("Failed to delete progress photo %s: %v", update.ImageS3Key, err)
+	}
+
+	apiresponse.Message(c, http.StatusOK, "Progress update deleted")
+}
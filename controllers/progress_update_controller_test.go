@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+	"github.com/stretchr/testify/assert"
+)
+
+// newProgressUpdateForm builds a multipart body with an image and the given fields
+func newProgressUpdateForm(t *testing.T, filename string, fields map[string]string) (*bytes.Buffer, string) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	if filename != "" {
+		part, err := writer.CreateFormFile("image", filename)
+		assert.NoError(t, err)
+		_, _ = part.Write([]byte("\x89PNG\r\n\x1a\nfake PNG content"))
+	}
+	for name, value := range fields {
+		assert.NoError(t, writer.WriteField(name, value))
+	}
+	assert.NoError(t, writer.Close())
+	return body, writer.FormDataContentType()
+}
+
+func TestProgressUpdates_Timeline(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	previous := services.GetImageService()
+	mockImage := services.NewMockImageService()
+	mockImage.SetAsMockForTesting()
+	defer services.SetImageService(previous)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	technician := models.User{Auth0ID: "auth0|tech", Name: "Technician User", Email: "tech@example.com", Role: "technician"}
+	db.Create(&technician)
+
+	order := models.Order{Description: "Photo order", Quantity: 1, Status: "in_production", CustomerID: customer.ID, TechnicianID: &technician.ID}
+	db.Create(&order)
+
+	router := setupTestRouter()
+	techAuth := mockAuthMiddleware(technician.Auth0ID, "technician", "mock-token")
+	customerAuth := mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token")
+	router.POST("/orders/:id/updates", techAuth, PostProgressUpdate)
+	router.DELETE("/orders/:id/updates/:updateId", techAuth, DeleteProgressUpdate)
+	router.POST("/customer/orders/:id/updates", customerAuth, PostProgressUpdate)
+	router.GET("/customer/orders/:id/updates", customerAuth, ListProgressUpdates)
+
+	post := func(path, filename string, fields map[string]string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		body, contentType := newProgressUpdateForm(t, filename, fields)
+		req, _ := http.NewRequest(http.MethodPost, path, body)
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w, response
+	}
+
+	// The technician posts a captioned photo and notifies the customer
+	w, response := post(fmt.Sprintf("/orders/%d/updates", order.ID), "base-coat.png", map[string]string{
+		"caption": "Base coat done",
+		"notify":  "true",
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, "Base coat done", data["caption"])
+	assert.Contains(t, data["image_url"], "mock_base-coat.png")
+	assert.True(t, mockImage.ImageExists(data["image_s3_key"].(string)))
+	firstID := uint(data["id"].(float64))
+
+	var messages []models.Message
+	db.Where("order_id = ?", order.ID).Find(&messages)
+	assert.Len(t, messages, 1)
+	assert.Equal(t, "New progress photo: Base coat done", messages[0].Text)
+
+	// A second photo without notification stays out of the conversation
+	w, _ = post(fmt.Sprintf("/orders/%d/updates", order.ID), "gloss.png", nil)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	db.Where("order_id = ?", order.ID).Find(&messages)
+	assert.Len(t, messages, 1)
+
+	// Customers cannot post, and an image is required
+	w, _ = post(fmt.Sprintf("/customer/orders/%d/updates", order.ID), "selfie.png", nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.False(t, mockImage.ImageExists("uploads/mock_selfie.png"))
+
+	w, response = post(fmt.Sprintf("/orders/%d/updates", order.ID), "", map[string]string{"caption": "No photo"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "VALIDATION_ERROR", response["error"].(map[string]interface{})["code"])
+
+	// The customer sees the timeline in order
+	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/customer/orders/%d/updates", order.ID), nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	timeline := response["data"].([]interface{})
+	assert.Len(t, timeline, 2)
+	assert.Equal(t, "Base coat done", timeline[0].(map[string]interface{})["caption"])
+
+	// The technician removes a photo from the timeline and from storage
+	req, _ = http.NewRequest(http.MethodDelete, fmt.Sprintf("/orders/%d/updates/%d", order.ID, firstID), nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, mockImage.ImageExists("uploads/mock_base-coat.png"))
+
+	var remaining int64
+	db.Model(&models.ProgressUpdate{}).Where("order_id = ?", order.ID).Count(&remaining)
+	assert.Equal(t, int64(1), remaining)
+}
@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/apiversion"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+)
+
+// orderFields lists the JSON fields of an order that ?fields= can select
+var orderFields = jsonFieldNames(reflect.TypeOf(models.Order{}))
+
+// orderProjection renders orders for the request's API version, trimmed to what the client asks for
+// ?fields=id,status,price keeps only those fields (and always id); ?include=customer,technician limits
+// the relations returned to those listed, and include= with no value returns none
+type orderProjection struct {
+	version   int
+	fields    map[string]bool // nil keeps every field
+	relations []string        // nil returns every relation
+}
+
+// parseOrderProjection reads ?fields= and ?include=
+// On failure the error response is written and false is returned
+func parseOrderProjection(c *gin.Context) (orderProjection, bool) {
+	projection := orderProjection{version: apiversion.Get(c)}
+	details := make(map[string]string)
+
+	if value, ok := c.GetQuery("fields"); ok {
+		fields := apiversion.Select(c, map[int][]string{apiversion.V1: orderFields, apiversion.V2: orderFieldsV2})
+		projection.fields = map[string]bool{"id": true}
+		for _, field := range splitList(value) {
+			if !slices.Contains(fields, field) {
+				details["fields"] = "unknown field " + field + "; must be fields of an order, such as id,status,price"
+				break
+			}
+			projection.fields[field] = true
+		}
+	}
+	if value, ok := c.GetQuery("include"); ok {
+		projection.relations = []string{}
+		for _, relation := range splitList(value) {
+			if !slices.Contains(repositories.OrderRelations, relation) {
+				details["include"] = "must be a comma-separated list of: " + strings.Join(repositories.OrderRelations, ", ")
+				break
+			}
+			projection.relations = append(projection.relations, relation)
+		}
+	}
+
+	if len(details) > 0 {
+		apierror.Respond(c, apierror.Validation("Invalid request data", details))
+		return orderProjection{}, false
+	}
+	return projection, true
+}
+
+// preload returns the relations the response needs loaded: those included or, without ?include=,
+// those among the selected fields; nil loads them all
+func (p orderProjection) preload() []string {
+	if p.relations != nil || p.fields == nil {
+		return p.relations
+	}
+	relations := []string{}
+	for _, relation := range repositories.OrderRelations {
+		if p.fields[relation] {
+			relations = append(relations, relation)
+		}
+	}
+	return relations
+}
+
+// order returns the order as the client asked for it
+func (p orderProjection) order(order *models.Order) interface{} {
+	rendered := apiversion.SerializeVersion(p.version, order)
+	if p.fields == nil && p.relations == nil {
+		return rendered
+	}
+	return p.project(rendered)
+}
+
+// orders returns the orders as the client asked for them
+func (p orderProjection) orders(orders []models.Order) interface{} {
+	if p.fields == nil && p.relations == nil {
+		return apiversion.SerializeVersion(p.version, orders)
+	}
+	projected := make([]interface{}, len(orders))
+	for i := range orders {
+		projected[i] = p.order(&orders[i])
+	}
+	return projected
+}
+
+// project drops the fields the client did not ask for from a rendered order
+// An order that cannot be re-read as a JSON object is returned whole, which is still a valid response
+func (p orderProjection) project(order interface{}) interface{} {
+	data, err := json.Marshal(order)
+	if err != nil {
+		return order
+	}
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return order
+	}
+
+	for field := range object {
+		if p.fields != nil && !p.fields[field] {
+			delete(object, field)
+		}
+	}
+	if p.relations != nil {
+		for _, relation := range repositories.OrderRelations {
+			if !slices.Contains(p.relations, relation) {
+				delete(object, relation)
+			}
+		}
+	}
+	return object
+}
+
+// jsonFieldNames returns the JSON names of a struct's fields, skipping those never serialized
+func jsonFieldNames(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// splitList splits a comma-separated query value, dropping blanks
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
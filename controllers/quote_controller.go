@@ -0,0 +1,180 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/apiresponse"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+)
+
+// SubmitQuoteRequest represents a quote request from a visitor without an account
+// It is accepted as JSON, or as multipart form data with an optional "image" file
+type SubmitQuoteRequest struct {
+	Name         string `form:"name" json:"name" binding:"required,max=200"`
+	Email        string `form:"email" json:"email" binding:"required,email"`
+	Description  string `form:"description" json:"description" binding:"required,max=2000"`
+	Quantity     int    `form:"quantity" json:"quantity" binding:"required,gt=0"`
+	CaptchaToken string `form:"captcha_token" json:"captcha_token"` // required when CAPTCHA_SECRET is set
+}
+
+// ReviewQuoteRequest represents the request body for quoting or declining a quote request
+type ReviewQuoteRequest struct {
+	Action string   `json:"action" binding:"required,oneof=quote decline"`
+	Price  *float64 `json:"price"` // required when quoting
+	Note   string   `json:"note" binding:"max=1000"`
+}
+
+// populateQuotesImageURLs generates presigned URLs for quote request images
+// Images held for moderation get their status instead of a URL
+func populateQuotesImageURLs(quotes []models.QuoteRequest) {
+	var keys []string
+	for i := range quotes {
+		if quotes[i].ImageS3Key != nil && *quotes[i].ImageS3Key != "" {
+			keys = append(keys, *quotes[i].ImageS3Key)
+		}
+	}
+	if len(keys) == 0 {
+		return
+	}
+
+	held := heldImages(keys)
+	for i := range quotes {
+		if quotes[i].ImageS3Key == nil || *quotes[i].ImageS3Key == "" {
+			continue
+		}
+		if status, ok := held[*quotes[i].ImageS3Key]; ok {
+			quotes[i].ImageStatus = &status
+			continue
+		}
+		if url, err := services.GetImageService().GetImageURL(*quotes[i].ImageS3Key); err == nil {
+			quotes[i].ImageURL = &url
+		}
+	}
+}
+
+// SubmitQuote handles POST /api/v1/quotes - records a quote request from a visitor without an account
+// Public, but rate limited per client IP and guarded by a CAPTCHA when CAPTCHA_SECRET is set
+func SubmitQuote(c *gin.Context) {
+	var req SubmitQuoteRequest
+	if err := c.ShouldBind(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	input := services.QuoteRequestInput{
+		GuestName:    req.Name,
+		GuestEmail:   req.Email,
+		Description:  req.Description,
+		Quantity:     req.Quantity,
+		CaptchaToken: req.CaptchaToken,
+		RemoteIP:     c.ClientIP(),
+	}
+	if fileHeader, err := c.FormFile("image"); err == nil {
+		imageKey, ok := uploadDesignImage(c, fileHeader, nil)
+		if !ok {
+			return
+		}
+		input.ImageS3Key = &imageKey
+	}
+
+	quote, err := services.GetQuoteService().SubmitQuote(input)
+	if err != nil {
+		if input.ImageS3Key != nil {
+			if deleteErr := services.GetImageService().DeleteImage(*input.ImageS3Key); deleteErr != nil {
+				log.Printf("Failed to delete orphaned quote image %s: %v", *input.ImageS3Key, deleteErr)
+			}
+		}
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.Created(c, quote)
+}
+
+// ListQuotes handles GET /api/v1/quotes - lists guest quote requests, oldest first (technicians and admins)
+// The status filter defaults to pending, the requests waiting on a technician; "all" lists every status
+func ListQuotes(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	// Parse pagination parameters
+	page := 1
+	limit := 20
+	if pageStr := c.Query("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		}
+	}
+
+	status := c.DefaultQuery("status", models.QuotePending)
+	if status == "all" {
+		status = ""
+	}
+
+	quotes, total, err := services.GetQuoteService().ListQuotes(user, status, page, limit)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	populateQuotesImageURLs(quotes)
+
+	c.PureJSON(http.StatusOK, apiresponse.Paginated(quotes, apiresponse.NewPagination(page, limit, total)))
+}
+
+// ReviewQuote handles PUT /api/v1/quotes/:id/review - quotes a price for a guest quote request or declines it (technicians only)
+func ReviewQuote(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req ReviewQuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	quote, err := services.GetQuoteService().ReviewQuote(user, c.Param("id"), services.QuoteReviewInput{
+		Action: req.Action,
+		Price:  req.Price,
+		Note:   req.Note,
+	})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, quote)
+}
+
+// ConvertQuote handles POST /api/v1/quotes/:id/convert - turns a quoted request into an order once the visitor has signed up (technicians only)
+// The order belongs to the account registered with the request's email and waits for the technician's review as usual
+func ConvertQuote(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	order, err := services.GetQuoteServiceFor(c.Request.Context()).ConvertQuote(user, c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	populateOrderImageURL(order)
+
+	apiresponse.Created(c, order)
+}
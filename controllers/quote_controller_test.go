@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuoteRequest_SubmitReviewAndConvert(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	technician := models.User{Auth0ID: "auth0|tech", Name: "Tech", Email: "tech@example.com", Role: "technician"}
+	db.Create(&technician)
+
+	// Only the token the visitor's browser got for a solved challenge passes
+	captcha := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"success": %t}`, r.FormValue("response") == "solved")
+	}))
+	defer captcha.Close()
+	services.SetCaptchaVerifier(services.NewHTTPCaptchaVerifier(&config.Config{CaptchaVerifyURL: captcha.URL, CaptchaSecret: "shh"}))
+	defer services.SetCaptchaVerifier(nil)
+
+	router := setupTestRouter()
+	techAuth := mockAuthMiddleware(technician.Auth0ID, "technician", "mock-token")
+	router.POST("/quotes", SubmitQuote)
+	router.GET("/quotes", techAuth, ListQuotes)
+	router.PUT("/quotes/:id/review", techAuth, ReviewQuote)
+	router.POST("/quotes/:id/convert", techAuth, ConvertQuote)
+
+	send := func(method, path string, body interface{}) (int, map[string]interface{}) {
+		payload, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(payload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response
+	}
+
+	request := map[string]interface{}{"name": "Ada", "email": "ada@example.com", "description": "Chrome almond", "quantity": 1}
+	code, response := send(http.MethodPost, "/quotes", request)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, "CAPTCHA_FAILED", response["error"].(map[string]interface{})["code"])
+
+	request["captcha_token"] = "solved"
+	code, response = send(http.MethodPost, "/quotes", request)
+	require.Equal(t, http.StatusCreated, code)
+	quoteID := uint(response["data"].(map[string]interface{})["id"].(float64))
+
+	code, response = send(http.MethodGet, "/quotes", nil)
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, response["data"].([]interface{}), 1)
+
+	code, response = send(http.MethodPut, fmt.Sprintf("/quotes/%d/review", quoteID), map[string]interface{}{"action": "quote", "price": 48.5})
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "quoted", response["data"].(map[string]interface{})["status"])
+
+	// The quote waits until the visitor signs up with the email they gave
+	code, response = send(http.MethodPost, fmt.Sprintf("/quotes/%d/convert", quoteID), nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+	assert.Equal(t, "GUEST_NOT_REGISTERED", response["error"].(map[string]interface{})["code"])
+
+	guest := models.User{Auth0ID: "auth0|ada", Name: "Ada", Email: "ada@example.com", Role: "customer"}
+	db.Create(&guest)
+	code, response = send(http.MethodPost, fmt.Sprintf("/quotes/%d/convert", quoteID), nil)
+	require.Equal(t, http.StatusCreated, code)
+	order := response["data"].(map[string]interface{})
+	assert.Equal(t, float64(guest.ID), order["customer_id"])
+	assert.Equal(t, float64(quoteID), order["quote_id"])
+	assert.Equal(t, 48.5, order["price"])
+
+	code, response = send(http.MethodGet, "/quotes?status=converted", nil)
+	require.Equal(t, http.StatusOK, code)
+	assert.Len(t, response["data"].([]interface{}), 1)
+
+	code, _ = send(http.MethodPost, fmt.Sprintf("/quotes/%d/convert", quoteID), nil)
+	assert.Equal(t, http.StatusConflict, code)
+}
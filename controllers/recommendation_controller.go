@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/apiresponse"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+)
+
+// ListRecommendations handles GET /api/v1/recommendations - suggests designs similar to the customer's delivered orders
+func ListRecommendations(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	designs, err := services.GetRecommendationService().ListRecommendations(user)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	// Generate presigned URLs for design images, leaving out any held for moderation
+	var keys []string
+	for i := range designs {
+		if designs[i].ImageS3Key != nil && *designs[i].ImageS3Key != "" {
+			keys = append(keys, *designs[i].ImageS3Key)
+		}
+	}
+	held := heldImages(keys)
+	imageService := services.GetImageService()
+	for i := range designs {
+		if designs[i].ImageS3Key == nil || *designs[i].ImageS3Key == "" {
+			continue
+		}
+		if _, ok := held[*designs[i].ImageS3Key]; ok {
+			continue
+		}
+		if url, err := imageService.GetImageURL(*designs[i].ImageS3Key); err == nil {
+			designs[i].ImageURL = &url
+		}
+	}
+
+	apiresponse.OK(c, designs)
+}
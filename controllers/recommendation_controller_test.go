@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListRecommendations(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	other := models.User{Auth0ID: "auth0|other", Name: "Other Customer", Email: "other@example.com", Role: "customer"}
+	db.Create(&other)
+	technician := models.User{Auth0ID: "auth0|tech", Name: "Technician User", Email: "tech@example.com", Role: "technician"}
+	db.Create(&technician)
+
+	db.Create(&models.Order{Description: "Pink almond nails with gold flakes", Quantity: 1, Status: "delivered", CustomerID: customer.ID})
+	similar := models.Order{Description: "Pink almond set with gold chrome", Quantity: 1, Status: "delivered", CustomerID: other.ID}
+	db.Create(&similar)
+	db.Create(&models.OrderLineItem{OrderID: similar.ID, Kind: models.LineItemAddOn, Description: "Chrome powder", Quantity: 1})
+	db.Create(&models.Order{Description: "Black coffin", Quantity: 1, Status: "delivered", CustomerID: other.ID})
+	deleted := models.Order{Description: "Pink almond with gold", Quantity: 1, Status: "delivered", CustomerID: other.ID}
+	db.Create(&deleted)
+
+	router := setupTestRouter()
+	router.GET("/recommendations", mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token"), ListRecommendations)
+	router.GET("/tech/recommendations", mockAuthMiddleware(technician.Auth0ID, "technician", "mock-token"), ListRecommendations)
+
+	list := func(path string) (int, []interface{}) {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		data, _ := response["data"].([]interface{})
+		return w.Code, data
+	}
+
+	// Nothing is suggested before the job has run
+	code, data := list("/recommendations")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Empty(t, data)
+
+	stored, err := services.GetRecommendationService().Precompute()
+	assert.NoError(t, err)
+	assert.NotZero(t, stored)
+
+	// Designs deleted after the job ran are not suggested
+	db.Delete(&deleted)
+
+	code, data = list("/recommendations")
+	assert.Equal(t, http.StatusOK, code)
+	if assert.Len(t, data, 1) {
+		design := data[0].(map[string]interface{})
+		assert.Equal(t, float64(similar.ID), design["order_id"])
+		assert.Equal(t, "Pink almond set with gold chrome", design["description"])
+		assert.Equal(t, []interface{}{"Chrome powder"}, design["add_ons"])
+		assert.NotContains(t, design, "customer_id")
+	}
+
+	// Running the job again replaces the stored suggestions instead of adding to them
+	_, err = services.GetRecommendationService().Precompute()
+	assert.NoError(t, err)
+	_, data = list("/recommendations")
+	assert.Len(t, data, 1)
+
+	code, _ = list("/tech/recommendations")
+	assert.Equal(t, http.StatusForbidden, code)
+}
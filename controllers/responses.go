@@ -0,0 +1,26 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/middleware"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+)
+
+// currentUser returns the authenticated user's profile, as resolved by middleware.CurrentUser
+// On failure the error response is written and false is returned
+func currentUser(c *gin.Context) (*models.User, bool) {
+	user, err := middleware.GetCurrentUser(c)
+	if err != nil {
+		apierror.Respond(c, err)
+		return nil, false
+	}
+
+	return user, true
+}
+
+// respondValidationError writes a VALIDATION_ERROR response for a request binding failure
+// The details list each invalid input as a FieldError, so clients can point at the fields to fix
+func respondValidationError(c *gin.Context, err error) {
+	apierror.Respond(c, apierror.Validation("Invalid request data", fieldErrors(err)))
+}
@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/apiresponse"
+	"github.com/kendall-kelly/kendalls-nails-api/middleware"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+)
+
+// CreateRoleRequestRequest represents the request body for applying to become a technician
+type CreateRoleRequestRequest struct {
+	Message       string   `json:"message" binding:"required,max=2000"`
+	PortfolioURLs []string `json:"portfolio_urls" binding:"required,min=1,max=10"`
+}
+
+// ReviewRoleRequestRequest represents the request body for deciding a role request
+type ReviewRoleRequestRequest struct {
+	Status string `json:"status" binding:"required,oneof=approved rejected"`
+	Note   string `json:"note" binding:"max=1000"` // required when rejecting
+}
+
+// CreateMyRoleRequest handles POST /api/v1/users/me/role-requests - applies to become a technician with a portfolio (customers only)
+func CreateMyRoleRequest(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req CreateRoleRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	request, err := services.GetRoleRequestService().SubmitRequest(user, services.RoleRequestInput{
+		Message:       req.Message,
+		PortfolioURLs: req.PortfolioURLs,
+	})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.Created(c, request)
+}
+
+// ListMyRoleRequests handles GET /api/v1/users/me/role-requests - lists the caller's role requests and their decisions
+func ListMyRoleRequests(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	requests, err := services.GetRoleRequestService().ListMyRequests(user)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, requests)
+}
+
+// ListRoleRequests handles GET /api/v1/admin/role-requests - lists role requests, oldest first (admins only)
+// The status filter defaults to pending, the requests waiting on an admin; "all" lists every status
+func ListRoleRequests(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	// Parse pagination parameters
+	page := 1
+	limit := 20
+	if pageStr := c.Query("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		}
+	}
+
+	status := c.DefaultQuery("status", models.RoleRequestPending)
+	if status == "all" {
+		status = ""
+	}
+
+	requests, total, err := services.GetRoleRequestService().ListRequests(user, status, page, limit)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.PureJSON(http.StatusOK, apiresponse.Paginated(requests, apiresponse.NewPagination(page, limit, total)))
+}
+
+// ReviewRoleRequest handles PUT /api/v1/admin/role-requests/:id/review - approves or rejects a role request (admins only)
+// Approving makes the applicant a technician, here and in Auth0; their existing tokens keep the old role claim until they expire
+func ReviewRoleRequest(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req ReviewRoleRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	request, err := services.GetRoleRequestService().ReviewRequest(user, c.Param("id"), req.Status, req.Note)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	if request.User != nil {
+		middleware.ForgetUser(request.User.Auth0ID)
+	}
+
+	apiresponse.OK(c, request)
+}
@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoleRequests(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+	directory := &flakyRoleDirectory{roles: make(map[string]string)}
+	services.SetRoleDirectory(directory)
+	defer services.SetRoleDirectory(nil)
+
+	admin := models.User{Auth0ID: "auth0|admin", Name: "Admin User", Email: "admin@example.com", Role: "admin"}
+	db.Create(&admin)
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+
+	router := setupTestRouter()
+	customerAuth := mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token")
+	adminAuth := mockAuthMiddleware(admin.Auth0ID, "admin", "mock-token")
+	router.POST("/users/me/role-requests", customerAuth, CreateMyRoleRequest)
+	router.GET("/users/me/role-requests", customerAuth, ListMyRoleRequests)
+	router.GET("/admin/role-requests", adminAuth, ListRoleRequests)
+	router.PUT("/admin/role-requests/:id/review", adminAuth, ReviewRoleRequest)
+
+	send := func(method, path string, body interface{}) (int, map[string]interface{}) {
+		payload, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(payload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response
+	}
+	application := map[string]interface{}{
+		"message":        "I have done nail art for five years",
+		"portfolio_urls": []string{"https://instagram.com/customernails"},
+	}
+
+	code, _ := send(http.MethodPost, "/users/me/role-requests", map[string]interface{}{"message": "Hire me"})
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, response := send(http.MethodPost, "/users/me/role-requests", application)
+	require.Equal(t, http.StatusCreated, code)
+	request := response["data"].(map[string]interface{})
+	assert.Equal(t, "pending", request["status"])
+	assert.Equal(t, "technician", request["requested_role"])
+	requestPath := fmt.Sprintf("/admin/role-requests/%.0f/review", request["id"])
+
+	code, response = send(http.MethodPost, "/users/me/role-requests", application)
+	assert.Equal(t, http.StatusConflict, code)
+	assert.Equal(t, "ROLE_REQUEST_PENDING", response["error"].(map[string]interface{})["code"])
+
+	code, response = send(http.MethodGet, "/admin/role-requests", nil)
+	require.Equal(t, http.StatusOK, code)
+	assert.Len(t, response["data"], 1)
+	assert.Equal(t, float64(1), response["pagination"].(map[string]interface{})["total"])
+
+	code, _ = send(http.MethodPut, requestPath, map[string]interface{}{"status": "rejected"})
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, response = send(http.MethodPut, requestPath, map[string]interface{}{"status": "approved"})
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "approved", response["data"].(map[string]interface{})["status"])
+
+	var promoted models.User
+	db.First(&promoted, customer.ID)
+	assert.Equal(t, "technician", promoted.Role)
+	assert.Equal(t, "technician", directory.roles[customer.Auth0ID])
+
+	code, response = send(http.MethodGet, "/admin/role-requests", nil)
+	require.Equal(t, http.StatusOK, code)
+	assert.Len(t, response["data"], 0)
+
+	code, response = send(http.MethodGet, "/users/me/role-requests", nil)
+	require.Equal(t, http.StatusOK, code)
+	mine := response["data"].([]interface{})
+	require.Len(t, mine, 1)
+	assert.Equal(t, "approved", mine[0].(map[string]interface{})["status"])
+}
@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/apiresponse"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+)
+
+// SaveDesignRequest represents the JSON request body for saving a design
+type SaveDesignRequest struct {
+	Description string `json:"description" binding:"required_without=OrderID"`
+	OrderID     *uint  `json:"order_id"`  // optional, saves the description and image of one of the customer's orders
+	UploadID    *uint  `json:"upload_id"` // optional, the image from a completed chunked upload
+}
+
+// populateDesignImageURL generates a presigned URL for a saved design's image
+func populateDesignImageURL(design *models.SavedDesign) {
+	if design.ImageS3Key == nil || *design.ImageS3Key == "" {
+		return
+	}
+	if url, err := services.GetImageService().GetImageURL(*design.ImageS3Key); err == nil {
+		design.ImageURL = &url
+	}
+}
+
+// ListDesigns handles GET /api/v1/designs - lists the current customer's saved designs
+func ListDesigns(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	designs, err := services.GetSavedDesignService().ListDesigns(user)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	for i := range designs {
+		populateDesignImageURL(&designs[i])
+	}
+
+	apiresponse.OK(c, designs)
+}
+
+// CreateDesign handles POST /api/v1/designs - saves a description and image as a reusable design (customers only)
+// Accepts JSON, or multipart form data with an optional image upload
+func CreateDesign(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var input services.SaveDesignInput
+	if c.ContentType() == "application/json" {
+		var req SaveDesignRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		input.Description = req.Description
+		input.OrderID = req.OrderID
+		if req.UploadID != nil {
+			imageKey, ok := claimUploadedImage(c, user, *req.UploadID)
+			if !ok {
+				return
+			}
+			input.ImageS3Key = &imageKey
+		}
+	} else {
+		input.Description = c.PostForm("description")
+		if orderStr := c.PostForm("order_id"); orderStr != "" {
+			orderID, err := strconv.ParseUint(orderStr, 10, 64)
+			if err != nil || orderID == 0 {
+				apierror.Respond(c, apierror.Validation("Order ID must be a positive integer", nil))
+				return
+			}
+			id := uint(orderID)
+			input.OrderID = &id
+		}
+
+		// The image is optional, and may have been uploaded earlier in chunks
+		uploadID, ok := parseUploadID(c)
+		if !ok {
+			return
+		}
+		fileHeader, err := c.FormFile("image")
+		if err == nil && uploadID != nil {
+			apierror.Respond(c, apierror.Validation("Send either an image or an upload ID, not both", nil))
+			return
+		}
+		if err == nil {
+			imageKey, ok := uploadDesignImage(c, fileHeader, &user.ID)
+			if !ok {
+				return
+			}
+			input.ImageS3Key = &imageKey
+		} else if uploadID != nil {
+			imageKey, ok := claimUploadedImage(c, user, *uploadID)
+			if !ok {
+				return
+			}
+			input.ImageS3Key = &imageKey
+		}
+	}
+
+	design, err := services.GetSavedDesignService().SaveDesign(user, input)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	populateDesignImageURL(design)
+
+	apiresponse.Created(c, design)
+}
+
+// DeleteDesign handles DELETE /api/v1/designs/:id - removes one of the current customer's saved designs
+func DeleteDesign(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := services.GetSavedDesignService().DeleteDesign(user, c.Param("id")); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.Message(c, http.StatusOK, "Design deleted")
+}
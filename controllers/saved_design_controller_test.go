@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSavedDesigns(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	previous := services.GetImageService()
+	mockImage := services.NewMockImageService()
+	mockImage.SetAsMockForTesting()
+	defer services.SetImageService(previous)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	technician := models.User{Auth0ID: "auth0|tech", Name: "Technician User", Email: "tech@example.com", Role: "technician"}
+	db.Create(&technician)
+	imageKey := "orders/chrome.png"
+	inProgress := models.Order{Description: "Chrome coffin", Quantity: 1, Status: "in_production", CustomerID: customer.ID, TechnicianID: &technician.ID, ImageS3Key: &imageKey}
+	db.Create(&inProgress)
+
+	router := setupTestRouter()
+	me := mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token")
+	router.GET("/designs", me, ListDesigns)
+	router.POST("/designs", me, CreateDesign)
+	router.DELETE("/designs/:id", me, DeleteDesign)
+	router.POST("/orders", me, CreateOrder)
+	router.POST("/tech/designs", mockAuthMiddleware(technician.Auth0ID, "technician", "mock-token"), CreateDesign)
+
+	request := func(method, path string, body interface{}) (int, map[string]interface{}) {
+		var buf bytes.Buffer
+		if body != nil {
+			json.NewEncoder(&buf).Encode(body)
+		}
+		req, _ := http.NewRequest(method, path, &buf)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response
+	}
+
+	code, _ := request(http.MethodPost, "/tech/designs", map[string]interface{}{"description": "Not for technicians"})
+	assert.Equal(t, http.StatusForbidden, code)
+	code, _ = request(http.MethodPost, "/designs", map[string]interface{}{})
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	// Orders that were never delivered can be saved too
+	code, response := request(http.MethodPost, "/designs", map[string]interface{}{"order_id": inProgress.ID})
+	assert.Equal(t, http.StatusCreated, code)
+	design := response["data"].(map[string]interface{})
+	assert.Equal(t, "Chrome coffin", design["description"])
+	assert.Equal(t, imageKey, design["image_s3_key"])
+
+	code, response = request(http.MethodPost, "/designs", map[string]interface{}{"description": "Pastel florals"})
+	assert.Equal(t, http.StatusCreated, code)
+	florals := response["data"].(map[string]interface{})
+
+	code, response = request(http.MethodGet, "/designs", nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, response["data"], 2)
+
+	// Ordering a saved design fills in its description and image
+	code, response = request(http.MethodPost, "/orders", map[string]interface{}{"design_id": design["id"], "quantity": 2})
+	assert.Equal(t, http.StatusCreated, code)
+	order := response["data"].(map[string]interface{})
+	assert.Equal(t, "Chrome coffin", order["description"])
+	assert.Equal(t, imageKey, order["image_s3_key"])
+	assert.Equal(t, design["id"], order["saved_design_id"])
+
+	code, response = request(http.MethodPost, "/orders", map[string]interface{}{"design_id": 999, "quantity": 1})
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+	assert.Equal(t, "INVALID_SAVED_DESIGN", response["error"].(map[string]interface{})["code"])
+
+	code, _ = request(http.MethodDelete, fmt.Sprintf("/designs/%v", florals["id"]), nil)
+	assert.Equal(t, http.StatusOK, code)
+	code, response = request(http.MethodGet, "/designs", nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, response["data"], 1)
+}
@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"slices"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apiversion"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/utils"
+)
+
+func init() {
+	// API v2 reports money as integer cents instead of decimal amounts
+	apiversion.Register(apiversion.V2, orderV2Of)
+}
+
+// omitted hides a field of an embedded model from JSON: a nil omitempty field shadows the model's field of the same name
+type omitted *struct{}
+
+// orderV2 is an order as API v2 renders it, with its price in cents
+type orderV2 struct {
+	*models.Order
+	Price      omitted      `json:"price,omitempty"`
+	PriceCents *int64       `json:"price_cents"` // nil until the order is priced
+	LineItems  []lineItemV2 `json:"line_items,omitempty"`
+}
+
+// lineItemV2 is an order line item as API v2 renders it, with its prices in cents
+type lineItemV2 struct {
+	*models.OrderLineItem
+	UnitPrice      omitted `json:"unit_price,omitempty"`
+	Amount         omitted `json:"amount,omitempty"`
+	UnitPriceCents int64   `json:"unit_price_cents"`
+	AmountCents    int64   `json:"amount_cents"`
+}
+
+// orderFieldsV2 lists the JSON fields of an API v2 order that ?fields= can select
+var orderFieldsV2 = append(slices.DeleteFunc(slices.Clone(orderFields), func(field string) bool { return field == "price" }), "price_cents")
+
+// orderV2Of renders an order for API v2
+// Cents are derived from the decimal amounts, which are read from the cents columns once those are authoritative
+func orderV2Of(order *models.Order) interface{} {
+	if order == nil {
+		return nil
+	}
+	rendered := orderV2{Order: order}
+	if order.Price != nil {
+		cents := utils.ToCents(*order.Price)
+		rendered.PriceCents = &cents
+	}
+	if order.LineItems != nil {
+		rendered.LineItems = make([]lineItemV2, len(order.LineItems))
+		for i := range order.LineItems {
+			item := &order.LineItems[i]
+			rendered.LineItems[i] = lineItemV2{
+				OrderLineItem:  item,
+				UnitPriceCents: utils.ToCents(item.UnitPrice),
+				AmountCents:    utils.ToCents(item.Amount),
+			}
+		}
+	}
+	return rendered
+}
@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/apiresponse"
+	"github.com/kendall-kelly/kendalls-nails-api/middleware"
+)
+
+// sessionResponse is the data of a started session; it has no expiry when the token has none
+type sessionResponse struct {
+	CSRFToken string     `json:"csrf_token"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// CreateSession handles POST /api/v1/auth/session - exchanges the bearer token for an httpOnly session cookie (COOKIE_AUTH)
+// The response carries the CSRF token that cookie-authenticated requests changing state send in X-CSRF-Token
+func CreateSession(c *gin.Context) {
+	accessToken, err := middleware.GetAccessToken(c)
+	if err != nil {
+		apierror.Respond(c, apierror.Unauthorized("UNAUTHORIZED", "Could not extract the access token"))
+		return
+	}
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		apierror.Respond(c, apierror.Unauthorized("UNAUTHORIZED", "Could not retrieve token claims"))
+		return
+	}
+
+	var expires time.Time
+	if claims.RegisteredClaims.Expiry != 0 {
+		expires = time.Unix(claims.RegisteredClaims.Expiry, 0).UTC()
+	}
+	csrfToken, err := middleware.SetSessionCookies(c, accessToken, expires)
+	if err != nil {
+		apierror.Respond(c, apierror.Internal("SESSION_ERROR", "Failed to start the session").Wrap(err))
+		return
+	}
+
+	data := sessionResponse{CSRFToken: csrfToken}
+	if !expires.IsZero() {
+		data.ExpiresAt = &expires
+	}
+	apiresponse.Created(c, data)
+}
+
+// DeleteSession handles DELETE /api/v1/auth/session - clears the session cookie (COOKIE_AUTH)
+// The access token itself stays valid until it expires
+func DeleteSession(c *gin.Context) {
+	middleware.ClearSessionCookies(c)
+
+	apiresponse.Message(c, http.StatusOK, "Signed out")
+}
@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/auth0/go-jwt-middleware/v2/validator"
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessions(t *testing.T) {
+	expiry := time.Now().Add(2 * time.Hour).Truncate(time.Second)
+
+	router := setupTestRouter()
+	router.POST("/auth/session", func(c *gin.Context) {
+		c.Set("user_id", "auth0|jane")
+		c.Set("access_token", "jane-token")
+		c.Set("validated_claims", &validator.ValidatedClaims{
+			RegisteredClaims: validator.RegisteredClaims{Subject: "auth0|jane", Expiry: expiry.Unix()},
+			CustomClaims:     &middleware.CustomClaims{Role: "customer"},
+		})
+		c.Next()
+	}, CreateSession)
+	router.DELETE("/auth/session", DeleteSession)
+
+	// The token is exchanged for cookies that expire with it
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/auth/session", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response struct {
+		Data struct {
+			CSRFToken string    `json:"csrf_token"`
+			ExpiresAt time.Time `json:"expires_at"`
+		} `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, expiry.Equal(response.Data.ExpiresAt))
+
+	cookies := map[string]*http.Cookie{}
+	for _, cookie := range w.Result().Cookies() {
+		cookies[cookie.Name] = cookie
+	}
+	if assert.Contains(t, cookies, middleware.SessionCookie) && assert.Contains(t, cookies, middleware.CSRFCookie) {
+		assert.Equal(t, "jane-token", cookies[middleware.SessionCookie].Value)
+		assert.Equal(t, response.Data.CSRFToken, cookies[middleware.CSRFCookie].Value)
+	}
+
+	// Signing out clears them
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodDelete, "/auth/session", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	for _, cookie := range w.Result().Cookies() {
+		assert.Empty(t, cookie.Value)
+		assert.Negative(t, cookie.MaxAge)
+	}
+	assert.Len(t, w.Result().Cookies(), 2)
+}
@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+	"github.com/kendall-kelly/kendalls-nails-api/sharecard"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetShareCard(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	previous := services.GetImageService()
+	services.NewMockImageService().SetAsMockForTesting()
+	defer services.SetImageService(previous)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	technician := models.User{Auth0ID: "auth0|tech", Name: "Technician User", Email: "tech@example.com", Role: "technician"}
+	db.Create(&technician)
+
+	delivered := models.Order{Description: "Delivered set", Quantity: 1, Status: "delivered", CustomerID: customer.ID, TechnicianID: &technician.ID}
+	db.Create(&delivered)
+	shipped := models.Order{Description: "On its way", Quantity: 1, Status: "shipped", CustomerID: customer.ID, TechnicianID: &technician.ID}
+	db.Create(&shipped)
+
+	router := setupTestRouter()
+	router.GET("/orders/:id/share-card.png", mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token"), GetShareCard)
+
+	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/orders/%d/share-card.png", delivered.ID), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+	card, err := png.Decode(bytes.NewReader(w.Body.Bytes()))
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, sharecard.Width, sharecard.Height), card.Bounds())
+
+	// Orders that have not been delivered have no card yet
+	req, _ = http.NewRequest(http.MethodGet, fmt.Sprintf("/orders/%d/share-card.png", shipped.ID), nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "INVALID_STATE")
+}
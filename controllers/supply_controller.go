@@ -0,0 +1,163 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/apiresponse"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+)
+
+// SupplyRequest represents the request body for creating or updating a supply
+type SupplyRequest struct {
+	Name              string   `json:"name" binding:"required"`
+	Unit              string   `json:"unit" binding:"required"`
+	Quantity          *float64 `json:"quantity" binding:"required,gte=0"`
+	LowStockThreshold float64  `json:"low_stock_threshold" binding:"gte=0"`
+}
+
+// input converts the request into service input
+func (r SupplyRequest) input() services.SupplyInput {
+	return services.SupplyInput{
+		Name:              r.Name,
+		Unit:              r.Unit,
+		Quantity:          *r.Quantity,
+		LowStockThreshold: r.LowStockThreshold,
+	}
+}
+
+// ListSupplies handles GET /api/v1/supplies - lists the supplies inventory (technicians and admins)
+func ListSupplies(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	supplies, err := services.GetSupplyService().ListSupplies(user)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, supplies)
+}
+
+// ListLowSupplies handles GET /api/v1/supplies/low - lists supplies at or below their low stock threshold (technicians and admins)
+func ListLowSupplies(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	supplies, err := services.GetSupplyService().ListLowSupplies(user)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, supplies)
+}
+
+// CreateSupply handles POST /api/v1/supplies - adds a supply to the inventory (technicians and admins)
+func CreateSupply(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req SupplyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	supply, err := services.GetSupplyService().CreateSupply(user, req.input())
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.Created(c, supply)
+}
+
+// UpdateSupply handles PUT /api/v1/supplies/:id - updates a supply and its counted stock (technicians and admins)
+func UpdateSupply(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req SupplyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	supply, err := services.GetSupplyService().UpdateSupply(user, c.Param("id"), req.input())
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, supply)
+}
+
+// DeleteSupply handles DELETE /api/v1/supplies/:id - retires a supply (technicians and admins)
+func DeleteSupply(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := services.GetSupplyService().DeleteSupply(user, c.Param("id")); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.Message(c, http.StatusOK, "Supply deleted")
+}
+
+// RecordSupplyUsageRequest represents the request body for recording supplies used on an order
+type RecordSupplyUsageRequest struct {
+	SupplyID uint    `json:"supply_id" binding:"required"`
+	Quantity float64 `json:"quantity" binding:"required,gt=0"`
+}
+
+// RecordSupplyUsage handles POST /api/v1/orders/:id/supplies - records a supply used on an order (assigned technician only)
+func RecordSupplyUsage(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req RecordSupplyUsageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	usage, err := services.GetSupplyService().RecordUsage(user, c.Param("id"), req.SupplyID, req.Quantity)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.Created(c, usage)
+}
+
+// ListSupplyUsage handles GET /api/v1/orders/:id/supplies - lists the supplies an order consumed
+func ListSupplyUsage(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	usage, err := services.GetSupplyService().ListUsage(user, c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, usage)
+}
@@ -0,0 +1,130 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSupplies(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	technician := models.User{Auth0ID: "auth0|tech", Name: "Technician User", Email: "tech@example.com", Role: "technician"}
+	db.Create(&technician)
+	admin := models.User{Auth0ID: "auth0|admin", Name: "Admin User", Email: "admin@example.com", Role: "admin"}
+	db.Create(&admin)
+	order := models.Order{Description: "French tips", Quantity: 1, Status: "in_production", CustomerID: customer.ID, TechnicianID: &technician.ID}
+	db.Create(&order)
+
+	router := setupTestRouter()
+	tech := mockAuthMiddleware(technician.Auth0ID, "technician", "mock-token")
+	router.GET("/supplies", tech, ListSupplies)
+	router.GET("/supplies/low", tech, ListLowSupplies)
+	router.POST("/supplies", tech, CreateSupply)
+	router.PUT("/supplies/:id", tech, UpdateSupply)
+	router.DELETE("/supplies/:id", tech, DeleteSupply)
+	router.POST("/orders/:id/supplies", tech, RecordSupplyUsage)
+	router.GET("/orders/:id/supplies", tech, ListSupplyUsage)
+	router.GET("/admin/orders/:id/supplies", mockAuthMiddleware(admin.Auth0ID, "admin", "mock-token"), ListSupplyUsage)
+	router.GET("/customer/supplies", mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token"), ListSupplies)
+	router.GET("/customer/orders/:id/supplies", mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token"), ListSupplyUsage)
+
+	request := func(method, path string, body interface{}) (int, map[string]interface{}) {
+		var buf bytes.Buffer
+		if body != nil {
+			json.NewEncoder(&buf).Encode(body)
+		}
+		req, _ := http.NewRequest(method, path, &buf)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response
+	}
+	usagePath := fmt.Sprintf("/orders/%d/supplies", order.ID)
+
+	code, response := request(http.MethodPost, "/supplies", map[string]interface{}{"name": "Builder gel", "unit": "ml", "quantity": 30, "low_stock_threshold": 10})
+	assert.Equal(t, http.StatusCreated, code)
+	gel := response["data"].(map[string]interface{})
+	assert.Equal(t, false, gel["low_stock"])
+	gelID := uint(gel["id"].(float64))
+
+	code, response = request(http.MethodPost, "/supplies", map[string]interface{}{"name": "Nail tips", "unit": "tips", "quantity": 0})
+	assert.Equal(t, http.StatusCreated, code)
+	tipsID := uint(response["data"].(map[string]interface{})["id"].(float64))
+
+	code, _ = request(http.MethodPost, "/supplies", map[string]interface{}{"name": "Glitter", "unit": "g", "quantity": -1})
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	t.Run("Low stock", func(t *testing.T) {
+		// Out of stock counts as low
+		code, response := request(http.MethodGet, "/supplies/low", nil)
+		assert.Equal(t, http.StatusOK, code)
+		low := response["data"].([]interface{})
+		if assert.Len(t, low, 1) {
+			assert.Equal(t, "Nail tips", low[0].(map[string]interface{})["name"])
+		}
+	})
+
+	t.Run("Record usage", func(t *testing.T) {
+		code, response := request(http.MethodPost, usagePath, map[string]interface{}{"supply_id": gelID, "quantity": 22.5})
+		assert.Equal(t, http.StatusCreated, code)
+		usage := response["data"].(map[string]interface{})
+		supply := usage["supply"].(map[string]interface{})
+		assert.Equal(t, 7.5, supply["quantity"])
+		assert.Equal(t, true, supply["low_stock"])
+
+		// The gel is now low as well
+		_, response = request(http.MethodGet, "/supplies/low", nil)
+		assert.Len(t, response["data"].([]interface{}), 2)
+
+		// Stock cannot go negative
+		code, response = request(http.MethodPost, usagePath, map[string]interface{}{"supply_id": gelID, "quantity": 8})
+		assert.Equal(t, http.StatusUnprocessableEntity, code)
+		assert.Equal(t, "INSUFFICIENT_STOCK", response["error"].(map[string]interface{})["code"])
+
+		code, _ = request(http.MethodPost, usagePath, map[string]interface{}{"supply_id": 999, "quantity": 1})
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+
+	t.Run("Restock", func(t *testing.T) {
+		code, response := request(http.MethodPut, fmt.Sprintf("/supplies/%d", tipsID), map[string]interface{}{"name": "Nail tips", "unit": "tips", "quantity": 500, "low_stock_threshold": 100})
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, false, response["data"].(map[string]interface{})["low_stock"])
+	})
+
+	t.Run("List usage", func(t *testing.T) {
+		// Usage stays listed after the supply is retired
+		code, _ := request(http.MethodDelete, fmt.Sprintf("/supplies/%d", gelID), nil)
+		assert.Equal(t, http.StatusOK, code)
+
+		code, response := request(http.MethodGet, usagePath, nil)
+		assert.Equal(t, http.StatusOK, code)
+		usage := response["data"].([]interface{})
+		if assert.Len(t, usage, 1) {
+			assert.Equal(t, "Builder gel", usage[0].(map[string]interface{})["supply"].(map[string]interface{})["name"])
+		}
+
+		code, _ = request(http.MethodGet, "/admin"+usagePath, nil)
+		assert.Equal(t, http.StatusOK, code)
+		code, _ = request(http.MethodGet, "/customer"+usagePath, nil)
+		assert.Equal(t, http.StatusForbidden, code)
+	})
+
+	t.Run("Customer forbidden", func(t *testing.T) {
+		code, _ := request(http.MethodGet, "/customer/supplies", nil)
+		assert.Equal(t, http.StatusForbidden, code)
+	})
+}
@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/apiresponse"
+	"github.com/kendall-kelly/kendalls-nails-api/middleware"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+)
+
+// uploadOffsetHeader carries the offset a chunk starts at, and in responses the offset to send from next
+const uploadOffsetHeader = "Upload-Offset"
+
+// StartUploadRequest represents the request body for starting a chunked upload
+type StartUploadRequest struct {
+	Filename string `json:"filename" binding:"required,max=255"`
+	Size     int64  `json:"size" binding:"required,gt=0"` // total bytes of the image
+}
+
+// StartChunkedUpload handles POST /api/v1/uploads/chunks - begins a resumable upload of a design image
+func StartChunkedUpload(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req StartUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	upload, err := services.GetChunkedUploadService().StartUpload(user, services.StartUploadInput{Filename: req.Filename, Size: req.Size})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	respondUpload(c, http.StatusCreated, upload)
+}
+
+// GetChunkedUpload handles GET /api/v1/uploads/chunks/:id - how much of an upload has arrived, to resume it
+func GetChunkedUpload(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	upload, err := services.GetChunkedUploadService().GetUpload(user, c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	respondUpload(c, http.StatusOK, upload)
+}
+
+// WriteUploadChunk handles PUT /api/v1/uploads/chunks/:id - appends the chunk in the body at the Upload-Offset header
+// The body is raw bytes sent as application/offset+octet-stream, up to services.MaxChunkBytes
+func WriteUploadChunk(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if c.ContentType() != middleware.MIMEUploadChunk {
+		apierror.Respond(c, apierror.New(http.StatusUnsupportedMediaType, "UNSUPPORTED_MEDIA_TYPE",
+			"Chunks must be sent as "+middleware.MIMEUploadChunk))
+		return
+	}
+	offset, err := strconv.ParseInt(c.GetHeader(uploadOffsetHeader), 10, 64)
+	if err != nil || offset < 0 {
+		apierror.Respond(c, apierror.BadRequest("INVALID_UPLOAD_OFFSET", "Upload-Offset header must be a non-negative number of bytes"))
+		return
+	}
+
+	chunk, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, services.MaxChunkBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			apierror.Respond(c, apierror.New(http.StatusRequestEntityTooLarge, "CHUNK_TOO_LARGE",
+				"Chunk exceeds the maximum size of "+strconv.Itoa(services.MaxChunkBytes)+" bytes"))
+			return
+		}
+		apierror.Respond(c, apierror.BadRequest("INVALID_BODY", "Failed to read request body").Wrap(err))
+		return
+	}
+
+	upload, err := services.GetChunkedUploadService().WriteChunk(user, c.Param("id"), offset, chunk)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	respondUpload(c, http.StatusOK, upload)
+}
+
+// CancelChunkedUpload handles DELETE /api/v1/uploads/chunks/:id - abandons an upload and deletes its chunks
+func CancelChunkedUpload(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := services.GetChunkedUploadService().CancelUpload(user, c.Param("id")); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.Message(c, http.StatusOK, "Upload cancelled")
+}
+
+// respondUpload writes an upload, with its offset also in the Upload-Offset header as tus clients expect
+func respondUpload(c *gin.Context, status int, upload *models.ChunkedUpload) {
+	c.Header(uploadOffsetHeader, strconv.FormatInt(upload.Offset, 10))
+	apiresponse.JSON(c, status, upload)
+}
+
+// claimUploadedImage returns the image key of a completed chunked upload named by an upload_id field
+// On failure the error response has been written and ok is false
+func claimUploadedImage(c *gin.Context, user *models.User, uploadID uint) (string, bool) {
+	imageKey, err := services.GetChunkedUploadService().ClaimUpload(user, uploadID)
+	if err != nil {
+		apierror.Respond(c, err)
+		return "", false
+	}
+	return imageKey, true
+}
+
+// parseUploadID parses an optional upload_id form field
+// On failure the error response has been written and ok is false
+func parseUploadID(c *gin.Context) (*uint, bool) {
+	value := c.PostForm("upload_id")
+	if value == "" {
+		return nil, true
+	}
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil || id == 0 {
+		apierror.Respond(c, apierror.Validation("Upload ID must be a positive integer", nil))
+		return nil, false
+	}
+	uploadID := uint(id)
+	return &uploadID, true
+}
@@ -0,0 +1,165 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/middleware"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChunkedUpload(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	previousImages := services.GetImageService()
+	services.NewMockImageService().SetAsMockForTesting()
+	defer services.SetImageService(previousImages)
+	previousStorage := services.GetStorage()
+	storage := services.NewMockStorage()
+	storage.SetAsMockForTesting()
+	defer services.SetStorage(previousStorage)
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	other := models.User{Auth0ID: "auth0|other", Name: "Other Customer", Email: "other@example.com", Role: "customer"}
+	db.Create(&other)
+	technician := models.User{Auth0ID: "auth0|tech", Name: "Technician User", Email: "tech@example.com", Role: "technician"}
+	db.Create(&technician)
+
+	router := setupTestRouter()
+	customerAuth := mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token")
+	router.POST("/uploads/chunks", customerAuth, StartChunkedUpload)
+	router.GET("/uploads/chunks/:id", customerAuth, GetChunkedUpload)
+	router.PUT("/uploads/chunks/:id", customerAuth, WriteUploadChunk)
+	router.DELETE("/uploads/chunks/:id", customerAuth, CancelChunkedUpload)
+	router.POST("/tech/uploads/chunks", mockAuthMiddleware(technician.Auth0ID, "technician", "mock-token"), StartChunkedUpload)
+	router.GET("/other/uploads/chunks/:id", mockAuthMiddleware(other.Auth0ID, "customer", "mock-token"), GetChunkedUpload)
+	router.POST("/orders", customerAuth, CreateOrder)
+
+	request := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewReader(data))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	putChunk := func(uploadID uint, offset int, chunk []byte) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodPut, fmt.Sprintf("/uploads/chunks/%d", uploadID), bytes.NewReader(chunk))
+		req.Header.Set("Content-Type", middleware.MIMEUploadChunk)
+		req.Header.Set("Upload-Offset", strconv.Itoa(offset))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	decode := func(w *httptest.ResponseRecorder) models.ChunkedUpload {
+		var response struct {
+			Data models.ChunkedUpload `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response.Data
+	}
+
+	image := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte("design"), 100)...)
+
+	// Only customers upload design images
+	w := request(http.MethodPost, "/tech/uploads/chunks", gin.H{"filename": "design.png", "size": len(image)})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Uploads larger than MAX_UPLOAD_MB are refused up front
+	w = request(http.MethodPost, "/uploads/chunks", gin.H{"filename": "huge.png", "size": 1 << 30})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "FILE_TOO_LARGE")
+
+	w = request(http.MethodPost, "/uploads/chunks", gin.H{"filename": "design.png", "size": len(image)})
+	require.Equal(t, http.StatusCreated, w.Code)
+	upload := decode(w)
+	assert.Equal(t, int64(0), upload.Offset)
+
+	t.Run("chunks must be sent as offset octet streams", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPut, fmt.Sprintf("/uploads/chunks/%d", upload.ID), bytes.NewReader(image[:100]))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Upload-Offset", "0")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	})
+
+	// The first chunk arrives, then the connection drops before the client sees the response
+	w = putChunk(upload.ID, 0, image[:100])
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "100", w.Header().Get("Upload-Offset"))
+
+	// Resending it is refused with the offset to resume from
+	w = putChunk(upload.ID, 0, image[:100])
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), `"offset":100`)
+
+	// The client can also ask where to resume; other customers cannot see the upload
+	w = request(http.MethodGet, fmt.Sprintf("/uploads/chunks/%d", upload.ID), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(100), decode(w).Offset)
+	w = request(http.MethodGet, fmt.Sprintf("/other/uploads/chunks/%d", upload.ID), nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// An unfinished upload cannot be attached to an order
+	w = request(http.MethodPost, "/orders", gin.H{"description": "Pink chrome", "quantity": 1, "upload_id": upload.ID})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "UPLOAD_INCOMPLETE")
+
+	// The last chunk completes the upload and the chunks are cleaned up
+	w = putChunk(upload.ID, 100, image[100:])
+	require.Equal(t, http.StatusOK, w.Code)
+	completed := decode(w)
+	assert.Equal(t, int64(len(image)), completed.Offset)
+	require.NotNil(t, completed.ImageS3Key)
+	chunks, _ := storage.List(services.ChunkPrefix)
+	assert.Empty(t, chunks)
+
+	// The assembled image is attached to an order by its upload ID
+	w = request(http.MethodPost, "/orders", gin.H{"description": "Pink chrome", "quantity": 1, "upload_id": upload.ID})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var order struct {
+		Data models.Order `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &order))
+	require.NotNil(t, order.Data.ImageS3Key)
+	assert.Equal(t, *completed.ImageS3Key, *order.Data.ImageS3Key)
+
+	t.Run("files that are not accepted images are refused with the first chunk", func(t *testing.T) {
+		w := request(http.MethodPost, "/uploads/chunks", gin.H{"filename": "notes.png", "size": 600})
+		require.Equal(t, http.StatusCreated, w.Code)
+		w = putChunk(decode(w).ID, 0, bytes.Repeat([]byte("plain text "), 10))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "INVALID_FILE_FORMAT")
+	})
+
+	t.Run("abandoned uploads are deleted once they expire", func(t *testing.T) {
+		w := request(http.MethodPost, "/uploads/chunks", gin.H{"filename": "design.png", "size": len(image)})
+		require.Equal(t, http.StatusCreated, w.Code)
+		abandoned := decode(w)
+		require.Equal(t, http.StatusOK, putChunk(abandoned.ID, 0, image[:100]).Code)
+
+		db.Model(&models.ChunkedUpload{}).Where("id = ?", abandoned.ID).Update("expires_at", time.Now().Add(-time.Minute))
+		deleted, err := services.GetChunkedUploadService().DeleteExpiredUploads(context.Background())
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, deleted, 1)
+		chunks, _ := storage.List(services.ChunkPrefix)
+		assert.Empty(t, chunks)
+		assert.Equal(t, http.StatusNotFound, request(http.MethodGet, fmt.Sprintf("/uploads/chunks/%d", abandoned.ID), nil).Code)
+	})
+}
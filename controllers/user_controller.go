@@ -3,85 +3,101 @@ package controllers
 import (
 	"net/http"
 	"strings"
+	"unicode"
 
 	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/apiresponse"
 	"github.com/kendall-kelly/kendalls-nails-api/config"
 	"github.com/kendall-kelly/kendalls-nails-api/middleware"
 	"github.com/kendall-kelly/kendalls-nails-api/models"
 	"github.com/kendall-kelly/kendalls-nails-api/services"
+	"github.com/kendall-kelly/kendalls-nails-api/utils"
 )
 
 // UpdateUserRequest represents the request body for updating a user profile
 type UpdateUserRequest struct {
-	Name  string `json:"name" binding:"omitempty"`
-	Email string `json:"email" binding:"omitempty,email"`
+	Name     string `json:"name" binding:"omitempty"`
+	Email    string `json:"email" binding:"omitempty,email"`
+	Locale   string `json:"locale" binding:"omitempty"`
+	SizeUnit string `json:"size_unit" binding:"omitempty,oneof=mm in"`
 }
 
-// CreateUser handles POST /api/v1/users - creates a new user from Auth0 userinfo
+// countryHeaders carry the client's IP-derived country when set by an edge proxy
+var countryHeaders = []string{"CF-IPCountry", "X-Country-Code"}
+
+// requestCountry returns the IP-derived country code for the request, if a proxy provided one
+func requestCountry(c *gin.Context) string {
+	for _, header := range countryHeaders {
+		if country := c.GetHeader(header); country != "" {
+			return country
+		}
+	}
+	return ""
+}
+
+// CreateUser handles POST /api/v1/users - creates a new user from Auth0 userinfo, or returns the caller's existing one
 // This endpoint requires authentication and fetches user data from Auth0's /userinfo endpoint
 func CreateUser(c *gin.Context) {
+	user, ok := auth0Profile(c)
+	if !ok {
+		return
+	}
+
+	// A retried registration gets the existing account and a returning identity its deleted one,
+	// both with 200 instead of 201
+	registered, created, err := services.GetUserService().Register(user)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	apiresponse.JSON(c, status, registered)
+}
+
+// auth0Profile builds the profile for a new account from the caller's token and Auth0 userinfo
+// On failure the error response is written and false is returned
+func auth0Profile(c *gin.Context) (models.User, bool) {
 	// Get the Auth0 user ID from the validated JWT
 	auth0ID, err := middleware.GetUserID(c)
 	if err != nil {
-		c.PureJSON(http.StatusUnauthorized, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "UNAUTHORIZED",
-				"message": "Could not extract user ID from token",
-			},
-		})
-		return
+		apierror.Respond(c, apierror.Unauthorized("UNAUTHORIZED", "Could not extract user ID from token"))
+		return models.User{}, false
 	}
 
 	// Get the access token to call Auth0's /userinfo endpoint
 	accessToken, err := middleware.GetAccessToken(c)
 	if err != nil {
-		c.PureJSON(http.StatusUnauthorized, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "MISSING_TOKEN",
-				"message": "Access token not found",
-			},
-		})
-		return
+		apierror.Respond(c, apierror.Unauthorized("MISSING_TOKEN", "Access token not found"))
+		return models.User{}, false
 	}
 
-	// Fetch user info from Auth0
+	// Fetch user info from Auth0; mock mode has no Auth0 to ask, so a profile is made up from the ID
 	cfg := config.GetConfig()
-	auth0Service := services.NewAuth0Service(cfg)
-	userInfo, err := auth0Service.GetUserInfo(accessToken)
-	if err != nil {
-		c.PureJSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "AUTH0_ERROR",
-				"message": "Failed to fetch user information from Auth0",
-			},
-		})
-		return
+	var userInfo *services.Auth0UserInfo
+	if cfg.Mock {
+		userInfo = mockUserInfo(auth0ID)
+	} else {
+		userInfo, err = services.NewAuth0Service(cfg).GetUserInfo(c.Request.Context(), accessToken)
+		if err != nil {
+			apierror.Respond(c, apierror.Internal("AUTH0_ERROR", "Failed to fetch user information from Auth0").Wrap(err))
+			return models.User{}, false
+		}
 	}
 
 	// Validate that required fields are present
 	if userInfo.Email == "" {
-		c.PureJSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "MISSING_EMAIL",
-				"message": "Email not provided by Auth0",
-			},
-		})
-		return
+		apierror.Respond(c, apierror.BadRequest("MISSING_EMAIL", "Email not provided by Auth0"))
+		return models.User{}, false
 	}
 
 	if userInfo.Name == "" {
-		c.PureJSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "MISSING_NAME",
-				"message": "Name not provided by Auth0",
-			},
-		})
-		return
+		apierror.Respond(c, apierror.BadRequest("MISSING_NAME", "Name not provided by Auth0"))
+		return models.User{}, false
 	}
 
 	// Get role from custom claims (if present)
@@ -93,122 +109,58 @@ func CreateUser(c *gin.Context) {
 		}
 	}
 
-	// Create user in database using data from Auth0
-	user := models.User{
-		Auth0ID: auth0ID,
-		Name:    userInfo.Name,
-		Email:   userInfo.Email,
-		Role:    role,
-	}
+	// Soft defaults for language and nail size units; the user can change them later
+	locale, sizeUnit := utils.DetectLocale(c.GetHeader("Accept-Language"), requestCountry(c))
 
-	db := config.GetDB()
-	if err := db.Create(&user).Error; err != nil {
-		// Check for duplicate Auth0ID or email (works with both PostgreSQL and SQLite)
-		errMsg := strings.ToLower(err.Error())
-		if strings.Contains(errMsg, "duplicate") ||
-		   strings.Contains(errMsg, "unique constraint") ||
-		   strings.Contains(errMsg, "unique") {
-			c.PureJSON(http.StatusConflict, gin.H{
-				"success": false,
-				"error": gin.H{
-					"code":    "USER_EXISTS",
-					"message": "A user with this Auth0 ID or email already exists",
-				},
-			})
-			return
-		}
+	return models.User{
+		Auth0ID:  auth0ID,
+		Name:     userInfo.Name,
+		Email:    utils.NormalizeEmail(userInfo.Email),
+		Role:     role,
+		Locale:   locale,
+		SizeUnit: sizeUnit,
+	}, true
+}
 
-		c.PureJSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "DATABASE_ERROR",
-				"message": "Failed to create user",
-			},
-		})
-		return
+// mockUserInfo makes up the userinfo of an identity for mock mode, e.g. "mock|dana" becomes dana@example.com
+func mockUserInfo(auth0ID string) *services.Auth0UserInfo {
+	_, name, found := strings.Cut(auth0ID, "|")
+	if !found || name == "" {
+		name = auth0ID
 	}
-
-	c.PureJSON(http.StatusCreated, gin.H{
-		"success": true,
-		"data":    user,
-	})
+	local := strings.Trim(strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return '-'
+	}, name), "-")
+	if local == "" {
+		local = "user"
+	}
+	return &services.Auth0UserInfo{Sub: auth0ID, Name: name, Email: local + "@example.com"}
 }
 
 // GetMyProfile handles GET /api/v1/users/me - gets current user's profile
 func GetMyProfile(c *gin.Context) {
-	// Extract Auth0 user ID from JWT token
-	auth0ID, err := middleware.GetUserID(c)
-	if err != nil {
-		c.PureJSON(http.StatusUnauthorized, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "UNAUTHORIZED",
-				"message": "Could not extract user information",
-			},
-		})
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
 
-	// Find user by Auth0ID
-	db := config.GetDB()
-	var user models.User
-	if err := db.Where("auth0_id = ?", auth0ID).First(&user).Error; err != nil {
-		c.PureJSON(http.StatusNotFound, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "USER_NOT_FOUND",
-				"message": "User profile not found. Please create a profile first.",
-			},
-		})
-		return
-	}
-
-	c.PureJSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    user,
-	})
+	apiresponse.OK(c, user)
 }
 
 // UpdateMyProfile handles PUT /api/v1/users/me - updates current user's profile
 func UpdateMyProfile(c *gin.Context) {
-	// Extract Auth0 user ID from JWT token
-	auth0ID, err := middleware.GetUserID(c)
-	if err != nil {
-		c.PureJSON(http.StatusUnauthorized, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "UNAUTHORIZED",
-				"message": "Could not extract user information",
-			},
-		})
-		return
-	}
-
 	// Parse request body
 	var req UpdateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.PureJSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "VALIDATION_ERROR",
-				"message": "Invalid request data",
-				"details": err.Error(),
-			},
-		})
+		respondValidationError(c, err)
 		return
 	}
 
-	// Find user by Auth0ID
-	db := config.GetDB()
-	var user models.User
-	if err := db.Where("auth0_id = ?", auth0ID).First(&user).Error; err != nil {
-		c.PureJSON(http.StatusNotFound, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "USER_NOT_FOUND",
-				"message": "User profile not found",
-			},
-		})
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
 
@@ -218,59 +170,60 @@ func UpdateMyProfile(c *gin.Context) {
 		updates["name"] = req.Name
 	}
 	if req.Email != "" {
-		updates["email"] = req.Email
+		email := utils.NormalizeEmail(req.Email)
+		inUse, err := services.GetUserService().EmailInUse(email, user.ID)
+		if err != nil {
+			apierror.Respond(c, err)
+			return
+		}
+		if inUse {
+			apierror.Respond(c, apierror.Conflict("EMAIL_EXISTS", "A user with this email already exists"))
+			return
+		}
+		updates["email"] = email
+	}
+	if req.Locale != "" {
+		locale, ok := utils.CanonicalLocale(req.Locale)
+		if !ok {
+			apierror.Respond(c, apierror.Validation("Locale must be a valid language tag such as en-US", nil))
+			return
+		}
+		updates["locale"] = locale
+	}
+	if req.SizeUnit != "" {
+		updates["size_unit"] = req.SizeUnit
 	}
 
 	// If no fields to update, return current user
 	if len(updates) == 0 {
-		c.PureJSON(http.StatusOK, gin.H{
-			"success": true,
-			"data":    user,
-		})
+		apiresponse.OK(c, user)
 		return
 	}
 
 	// Update user in database
-	if err := db.Model(&user).Updates(updates).Error; err != nil {
+	db := config.GetDB()
+	if err := db.Model(user).Updates(updates).Error; err != nil {
 		// Check for duplicate email (works with both PostgreSQL and SQLite)
 		errMsg := strings.ToLower(err.Error())
 		if strings.Contains(errMsg, "duplicate") ||
 		   strings.Contains(errMsg, "unique constraint") ||
 		   strings.Contains(errMsg, "unique") {
-			c.PureJSON(http.StatusConflict, gin.H{
-				"success": false,
-				"error": gin.H{
-					"code":    "EMAIL_EXISTS",
-					"message": "A user with this email already exists",
-				},
-			})
+			apierror.Respond(c, apierror.Conflict("EMAIL_EXISTS", "A user with this email already exists"))
 			return
 		}
 
-		c.PureJSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "DATABASE_ERROR",
-				"message": "Failed to update user profile",
-			},
-		})
+		apierror.Respond(c, apierror.Internal("DATABASE_ERROR", "Failed to update user profile").Wrap(err))
 		return
 	}
 
+	middleware.ForgetUser(user.Auth0ID)
+
 	// Fetch updated user to return
-	if err := db.Where("auth0_id = ?", auth0ID).First(&user).Error; err != nil {
-		c.PureJSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "DATABASE_ERROR",
-				"message": "Failed to fetch updated profile",
-			},
-		})
+	var updated models.User
+	if err := db.First(&updated, user.ID).Error; err != nil {
+		apierror.Respond(c, apierror.Internal("DATABASE_ERROR", "Failed to fetch updated profile").Wrap(err))
 		return
 	}
 
-	c.PureJSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    user,
-	})
+	apiresponse.OK(c, updated)
 }
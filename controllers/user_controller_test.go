@@ -24,8 +24,8 @@ func setupTestDB(t *testing.T) *gorm.DB {
 		t.Fatalf("Failed to connect to test database: %v", err)
 	}
 
-	// Auto-migrate the User model
-	if err := db.AutoMigrate(&models.User{}); err != nil {
+	// Migrate through MigrateUp so the unique indexes match production
+	if err := models.MigrateUp(db); err != nil {
 		t.Fatalf("Failed to migrate test database: %v", err)
 	}
 
@@ -229,6 +229,77 @@ func TestCreateUser(t *testing.T) {
 	}
 }
 
+func TestCreateUser_DetectsLocaleDefaults(t *testing.T) {
+	// Setup
+	db := setupTestDB(t)
+	config.SetDB(db)
+
+	accessToken := "token-locale"
+	userInfoMap := map[string]*services.Auth0UserInfo{
+		accessToken: {
+			Sub:   "auth0|locale",
+			Email: "locale@example.com",
+			Name:  "Locale User",
+		},
+	}
+	mockServer := setupMockAuth0Server(userInfoMap)
+	defer mockServer.Close()
+
+	originalConfig := config.GetConfig()
+	defer func() {
+		config.SetConfig(originalConfig)
+	}()
+	config.SetConfig(&config.Config{Auth0Domain: mockServer.URL})
+
+	router := setupTestRouter()
+	router.POST("/users", mockAuthMiddleware("auth0|locale", "customer", accessToken), CreateUser)
+
+	// Language without a region plus an IP country from the edge proxy
+	req := httptest.NewRequest(http.MethodPost, "/users", nil)
+	req.Header.Set("Accept-Language", "en;q=0.9")
+	req.Header.Set("CF-IPCountry", "AU")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, "en-AU", data["locale"])
+	assert.Equal(t, "mm", data["size_unit"])
+}
+
+func TestCreateUser_MockModeMakesUpProfile(t *testing.T) {
+	// Setup
+	db := setupTestDB(t)
+	config.SetDB(db)
+
+	originalConfig := config.GetConfig()
+	defer func() {
+		config.SetConfig(originalConfig)
+	}()
+	config.SetConfig(&config.Config{Mock: true})
+
+	// No Auth0 server is running; mock mode must not call it
+	router := setupTestRouter()
+	router.POST("/users", mockAuthMiddleware("mock|Dana Fox", "customer", "mock|Dana Fox"), CreateUser)
+
+	req := httptest.NewRequest(http.MethodPost, "/users", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, "Dana Fox", data["name"])
+	assert.Equal(t, "dana-fox@example.com", data["email"])
+}
+
 func TestCreateUser_DuplicateAuth0ID(t *testing.T) {
 	// Setup
 	db := setupTestDB(t)
@@ -265,7 +336,7 @@ func TestCreateUser_DuplicateAuth0ID(t *testing.T) {
 	}()
 	config.SetConfig(testConfig)
 
-	// Try to create user with duplicate Auth0ID
+	// Registering the same identity again returns the existing profile unchanged
 	router := setupTestRouter()
 	router.POST("/users", mockAuthMiddleware("auth0|duplicate", "customer", accessToken), CreateUser)
 
@@ -274,13 +345,19 @@ func TestCreateUser_DuplicateAuth0ID(t *testing.T) {
 
 	router.ServeHTTP(w, req)
 
-	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, http.StatusOK, w.Code)
 
 	var response map[string]interface{}
 	json.Unmarshal(w.Body.Bytes(), &response)
-	assert.False(t, response["success"].(bool))
-	errorData := response["error"].(map[string]interface{})
-	assert.Equal(t, "USER_EXISTS", errorData["code"])
+	assert.True(t, response["success"].(bool))
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, float64(user.ID), data["id"])
+	assert.Equal(t, "First User", data["name"])
+	assert.Equal(t, "first@example.com", data["email"])
+
+	var count int64
+	db.Model(&models.User{}).Where("auth0_id = ?", "auth0|duplicate").Count(&count)
+	assert.Equal(t, int64(1), count)
 }
 
 func TestCreateUser_DuplicateEmail(t *testing.T) {
@@ -337,6 +414,121 @@ func TestCreateUser_DuplicateEmail(t *testing.T) {
 	assert.Equal(t, "USER_EXISTS", errorData["code"])
 }
 
+func TestCreateUser_DuplicateEmailDifferentCase(t *testing.T) {
+	// Setup
+	db := setupTestDB(t)
+	config.SetDB(db)
+
+	// Existing account signed up as jane@
+	user := models.User{
+		Auth0ID: "auth0|first",
+		Name:    "Jane",
+		Email:   "jane@example.com",
+		Role:    "customer",
+	}
+	db.Create(&user)
+
+	// Auth0 reports the same mailbox with different casing
+	accessToken := "token-second"
+	userInfoMap := map[string]*services.Auth0UserInfo{
+		accessToken: {
+			Sub:   "auth0|second",
+			Email: " Jane@Example.com",
+			Name:  "Jane",
+		},
+	}
+	mockServer := setupMockAuth0Server(userInfoMap)
+	defer mockServer.Close()
+
+	originalConfig := config.GetConfig()
+	defer func() {
+		config.SetConfig(originalConfig)
+	}()
+	config.SetConfig(&config.Config{Auth0Domain: mockServer.URL})
+
+	router := setupTestRouter()
+	router.POST("/users", mockAuthMiddleware("auth0|second", "customer", accessToken), CreateUser)
+
+	req := httptest.NewRequest(http.MethodPost, "/users", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	var count int64
+	db.Model(&models.User{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestCreateUser_ReRegistration(t *testing.T) {
+	// Setup
+	db := setupTestDB(t)
+	config.SetDB(db)
+
+	// A returning identity whose account was deleted, and an unrelated deleted account holding an email
+	returning := models.User{Auth0ID: "auth0|returning", Name: "Old Name", Email: "old@example.com", Role: "customer"}
+	db.Create(&returning)
+	db.Delete(&returning)
+	departed := models.User{Auth0ID: "auth0|departed", Name: "Sam", Email: "sam@example.com", Role: "customer"}
+	db.Create(&departed)
+	db.Delete(&departed)
+
+	userInfoMap := map[string]*services.Auth0UserInfo{
+		"token-returning": {Sub: "auth0|returning", Email: "new@example.com", Name: "New Name"},
+		"token-new":       {Sub: "auth0|new", Email: "Sam@example.com", Name: "Sam"},
+	}
+	mockServer := setupMockAuth0Server(userInfoMap)
+	defer mockServer.Close()
+
+	originalConfig := config.GetConfig()
+	defer func() {
+		config.SetConfig(originalConfig)
+	}()
+	config.SetConfig(&config.Config{Auth0Domain: mockServer.URL})
+
+	router := setupTestRouter()
+	router.POST("/returning/users", mockAuthMiddleware("auth0|returning", "customer", "token-returning"), CreateUser)
+	router.POST("/new/users", mockAuthMiddleware("auth0|new", "customer", "token-new"), CreateUser)
+
+	// The returning identity gets its old account back with the new profile
+	req := httptest.NewRequest(http.MethodPost, "/returning/users", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, float64(returning.ID), data["id"])
+	assert.Equal(t, "New Name", data["name"])
+	assert.Equal(t, "new@example.com", data["email"])
+
+	var restored models.User
+	assert.NoError(t, db.First(&restored, returning.ID).Error)
+
+	// A new identity can take the email of a deleted account, which stays deleted
+	req = httptest.NewRequest(http.MethodPost, "/new/users", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	data = response["data"].(map[string]interface{})
+	assert.NotEqual(t, float64(departed.ID), data["id"])
+	assert.Equal(t, "sam@example.com", data["email"])
+
+	var stillDeleted models.User
+	assert.Error(t, db.First(&stillDeleted, departed.ID).Error)
+
+	// Registering again returns the now active account
+	req = httptest.NewRequest(http.MethodPost, "/returning/users", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, float64(returning.ID), response["data"].(map[string]interface{})["id"])
+}
+
 func TestGetMyProfile_Success(t *testing.T) {
 	// Setup
 	db := setupTestDB(t)
@@ -609,6 +801,85 @@ func TestUpdateMyProfile_DuplicateEmail(t *testing.T) {
 	assert.Equal(t, "EMAIL_EXISTS", errorData["code"])
 }
 
+func TestUpdateMyProfile_EmailNormalized(t *testing.T) {
+	// Setup
+	db := setupTestDB(t)
+	config.SetDB(db)
+	router := setupTestRouter()
+
+	router.PUT("/users/me", func(c *gin.Context) {
+		c.Set("user_id", "auth0|testuser")
+		UpdateMyProfile(c)
+	})
+
+	user1 := models.User{Auth0ID: "auth0|testuser", Name: "Test User 1", Email: "user1@example.com", Role: "customer"}
+	db.Create(&user1)
+	user2 := models.User{Auth0ID: "auth0|otheruser", Name: "Test User 2", Email: "user2@example.com", Role: "customer"}
+	db.Create(&user2)
+
+	// Another account's email in different case is rejected
+	body, _ := json.Marshal(UpdateUserRequest{Email: "User2@Example.com"})
+	req := httptest.NewRequest(http.MethodPut, "/users/me", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Re-casing your own email is allowed and stored normalized
+	body, _ = json.Marshal(UpdateUserRequest{Email: "New.Address@Example.com"})
+	req = httptest.NewRequest(http.MethodPut, "/users/me", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var updated models.User
+	db.First(&updated, user1.ID)
+	assert.Equal(t, "new.address@example.com", updated.Email)
+}
+
+func TestUpdateMyProfile_LocaleAndSizeUnit(t *testing.T) {
+	// Setup
+	db := setupTestDB(t)
+	config.SetDB(db)
+	router := setupTestRouter()
+
+	router.PUT("/users/me", func(c *gin.Context) {
+		c.Set("user_id", "auth0|testuser")
+		UpdateMyProfile(c)
+	})
+
+	user := models.User{Auth0ID: "auth0|testuser", Name: "Test User", Email: "test@example.com", Role: "customer"}
+	db.Create(&user)
+
+	tests := []struct {
+		name           string
+		payload        UpdateUserRequest
+		expectedStatus int
+	}{
+		{"valid locale and unit", UpdateUserRequest{Locale: "fr-ca", SizeUnit: "mm"}, http.StatusOK},
+		{"invalid locale", UpdateUserRequest{Locale: "not a locale!"}, http.StatusBadRequest},
+		{"invalid unit", UpdateUserRequest{SizeUnit: "cm"}, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(tt.payload)
+			req := httptest.NewRequest(http.MethodPut, "/users/me", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+
+	var updated models.User
+	db.First(&updated, user.ID)
+	assert.Equal(t, "fr-CA", updated.Locale)
+	assert.Equal(t, "mm", updated.SizeUnit)
+}
+
 func TestUpdateMyProfile_EmptyUpdate(t *testing.T) {
 	// Setup
 	db := setupTestDB(t)
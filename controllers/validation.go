@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError describes one invalid input of a request, for the details of a VALIDATION_ERROR
+type FieldError struct {
+	Field   string `json:"field"`   // JSON path of the input, e.g. "items[0].quantity"; empty when the whole body is invalid
+	Rule    string `json:"rule"`    // the failed validation tag, e.g. "required" or "max"; "type" for a value of the wrong JSON type
+	Message string `json:"message"` // human-readable explanation, without the field name
+}
+
+func init() {
+	// Report fields by the names clients send rather than the Go struct field names
+	if engine, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		engine.RegisterTagNameFunc(requestFieldName)
+	}
+}
+
+// requestFieldName returns the JSON name of a request struct field, falling back to its form name
+func requestFieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		name := strings.Split(field.Tag.Get(tag), ",")[0]
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// fieldErrors converts a request binding error into one FieldError per invalid input
+func fieldErrors(err error) []FieldError {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		fields := make([]FieldError, 0, len(validationErrors))
+		for _, fieldErr := range validationErrors {
+			fields = append(fields, FieldError{
+				Field:   fieldPath(fieldErr.Namespace()),
+				Rule:    fieldErr.Tag(),
+				Message: ruleMessage(fieldErr),
+			})
+		}
+		return fields
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return []FieldError{{Field: typeErr.Field, Rule: "type", Message: "must be " + jsonKind(typeErr.Type)}}
+	}
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return []FieldError{{Rule: "json", Message: "request body is not valid JSON"}}
+	}
+	if errors.Is(err, io.EOF) {
+		return []FieldError{{Rule: "required", Message: "request body is required"}}
+	}
+	return []FieldError{{Rule: "invalid", Message: err.Error()}}
+}
+
+// fieldPath drops the request struct's name from a validator namespace such as "CreateOrderRequest.items[0].quantity"
+func fieldPath(namespace string) string {
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+// ruleMessage explains a failed validation tag in words
+func ruleMessage(fieldErr validator.FieldError) string {
+	param := fieldErr.Param()
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "required_without":
+		return "is required when " + goFieldNames(param) + " is not given"
+	case "required_without_all":
+		return "is required when none of " + goFieldNames(param) + " are given"
+	case "email":
+		return "must be a valid email address"
+	case "oneof":
+		return "must be one of: " + strings.Join(strings.Fields(param), ", ")
+	case "min", "max", "len":
+		return sizeMessage(fieldErr.Tag(), fieldErr.Kind(), param)
+	case "gt":
+		return "must be greater than " + param
+	case "gte":
+		return "must be at least " + param
+	case "lt":
+		return "must be less than " + param
+	case "lte":
+		return "must be at most " + param
+	}
+	return fmt.Sprintf("failed the %s rule", fieldErr.Tag())
+}
+
+// sizeMessage explains a min, max, or len rule, which limits length for strings and collections and value for numbers
+func sizeMessage(tag string, kind reflect.Kind, param string) string {
+	bound := map[string]string{"min": "at least ", "max": "at most ", "len": "exactly "}[tag]
+	switch kind {
+	case reflect.String:
+		return "must be " + bound + param + " characters long"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return "must have " + bound + param + " items"
+	}
+	return "must be " + bound + param
+}
+
+// goFieldNames converts the space-separated Go field names in a cross-field rule's parameter to JSON names
+// Request fields are named after their Go fields in snake case, e.g. CatalogDesignID is catalog_design_id
+func goFieldNames(param string) string {
+	names := strings.Fields(param)
+	for i, name := range names {
+		names[i] = snakeCase(name)
+	}
+	return strings.Join(names, " or ")
+}
+
+// snakeCase converts a Go identifier such as "CatalogDesignID" to "catalog_design_id"
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			previousLower := unicode.IsLower(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if previousLower || (unicode.IsUpper(runes[i-1]) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// jsonKind names the JSON type a Go type is decoded from
+func jsonKind(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Struct, reflect.Map:
+		return "an object"
+	}
+	return "a " + t.Kind().String()
+}
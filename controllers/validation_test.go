@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFieldErrors(t *testing.T) {
+	type item struct {
+		Quantity int `json:"quantity" binding:"gt=0"`
+	}
+	type request struct {
+		Name            string   `json:"name" binding:"required,max=5"`
+		Email           string   `json:"email" binding:"omitempty,email"`
+		Unit            string   `json:"unit" binding:"omitempty,oneof=mm in"`
+		Tags            []string `json:"tags" binding:"max=2"`
+		Items           []item   `json:"items" binding:"dive"`
+		Description     string   `json:"description" binding:"required_without_all=CatalogDesignID DesignID"`
+		CatalogDesignID *uint    `json:"catalog_design_id"`
+		DesignID        *uint    `json:"design_id"`
+		Rush            bool     `json:"rush"`
+	}
+
+	bind := func(body string) []FieldError {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request, _ = http.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		var req request
+		err := c.ShouldBindJSON(&req)
+		if !assert.Error(t, err) {
+			return nil
+		}
+		return fieldErrors(err)
+	}
+
+	assert.Equal(t, []FieldError{
+		{Field: "name", Rule: "max", Message: "must be at most 5 characters long"},
+		{Field: "email", Rule: "email", Message: "must be a valid email address"},
+		{Field: "unit", Rule: "oneof", Message: "must be one of: mm, in"},
+		{Field: "tags", Rule: "max", Message: "must have at most 2 items"},
+		{Field: "items[1].quantity", Rule: "gt", Message: "must be greater than 0"},
+		{Field: "description", Rule: "required_without_all", Message: "is required when none of catalog_design_id or design_id are given"},
+	}, bind(`{"name": "Jane Doe", "email": "jane", "unit": "cm", "tags": ["a", "b", "c"], "items": [{"quantity": 1}, {"quantity": 0}]}`))
+
+	assert.Equal(t, []FieldError{{Field: "name", Rule: "required", Message: "is required"}}, bind(`{"design_id": 1}`))
+	assert.Equal(t, []FieldError{{Field: "rush", Rule: "type", Message: "must be a boolean"}}, bind(`{"name": "Jane", "rush": "yes"}`))
+	assert.Equal(t, []FieldError{{Rule: "json", Message: "request body is not valid JSON"}}, bind(`{"name": `))
+	assert.Equal(t, []FieldError{{Rule: "required", Message: "request body is required"}}, bind(``))
+}
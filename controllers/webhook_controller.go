@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/apiresponse"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+)
+
+// CreateWebhookRequest represents the request body for registering a webhook
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" binding:"required"`
+	Events []string `json:"events"`
+}
+
+// CreateWebhook handles POST /api/v1/admin/webhooks - registers a webhook for order events (admins only)
+// The signing secret is only included in this response
+func CreateWebhook(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	webhook, err := services.GetWebhookService().CreateWebhook(user, services.WebhookInput{
+		URL:    req.URL,
+		Events: req.Events,
+	})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.Created(c, webhook)
+}
+
+// ListWebhooks handles GET /api/v1/admin/webhooks - lists registered webhooks (admins only)
+func ListWebhooks(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	webhooks, err := services.GetWebhookService().ListWebhooks(user)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, webhooks)
+}
+
+// DeleteWebhook handles DELETE /api/v1/admin/webhooks/:id - stops sending events to a webhook (admins only)
+func DeleteWebhook(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := services.GetWebhookService().DeleteWebhook(user, c.Param("id")); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.Message(c, http.StatusOK, "Webhook deleted")
+}
+
+// ListWebhookDeliveries handles GET /api/v1/admin/webhooks/:id/deliveries - the webhook's delivery log (admins only)
+// The optional limit query parameter controls how many of the most recent deliveries are returned
+func ListWebhookDeliveries(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	limit := 0
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 {
+			apierror.Respond(c, apierror.Validation("Limit must be a positive integer", nil))
+			return
+		}
+		limit = parsed
+	}
+
+	deliveries, err := services.GetWebhookService().ListDeliveries(user, c.Param("id"), limit)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	apiresponse.OK(c, deliveries)
+}
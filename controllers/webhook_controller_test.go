@@ -0,0 +1,173 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/events"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+	"github.com/stretchr/testify/assert"
+)
+
+// webhookReceiver records webhook requests and answers with a configurable status
+type webhookReceiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+	w.WriteHeader(r.status)
+}
+
+func (r *webhookReceiver) setStatus(status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status = status
+}
+
+func TestWebhooks(t *testing.T) {
+	// Setup
+	db := setupOrderTestDB(t)
+	config.SetDB(db)
+
+	admin := models.User{Auth0ID: "auth0|admin", Name: "Admin User", Email: "admin@example.com", Role: "admin"}
+	db.Create(&admin)
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer User", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	order := models.Order{Description: "Order", Quantity: 1, Status: "submitted", CustomerID: customer.ID}
+	db.Create(&order)
+
+	receiver := &webhookReceiver{status: http.StatusOK}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	webhooks := services.NewWebhookService(repositories.NewWebhookRepository(db))
+	bus := events.NewBus(nil)
+	services.SubscribeWebhooks(bus, webhooks)
+	services.SetEventBus(bus)
+	defer services.SetEventBus(nil)
+
+	router := setupTestRouter()
+	router.POST("/admin/webhooks", mockAuthMiddleware(admin.Auth0ID, "admin", "mock-token"), CreateWebhook)
+	router.GET("/admin/webhooks", mockAuthMiddleware(admin.Auth0ID, "admin", "mock-token"), ListWebhooks)
+	router.DELETE("/admin/webhooks/:id", mockAuthMiddleware(admin.Auth0ID, "admin", "mock-token"), DeleteWebhook)
+	router.GET("/admin/webhooks/:id/deliveries", mockAuthMiddleware(admin.Auth0ID, "admin", "mock-token"), ListWebhookDeliveries)
+	router.POST("/customer/admin/webhooks", mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token"), CreateWebhook)
+	router.POST("/orders/:id/messages", mockAuthMiddleware(customer.Auth0ID, "customer", "mock-token"), SendMessage)
+
+	request := func(method, path string, body interface{}) (*httptest.ResponseRecorder, map[string]interface{}) {
+		payload, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(payload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w, response
+	}
+	sendMessage := func(text string) {
+		w, _ := request(http.MethodPost, fmt.Sprintf("/orders/%d/messages", order.ID), map[string]string{"text": text})
+		assert.Equal(t, http.StatusCreated, w.Code)
+	}
+
+	// Only admins can register webhooks, and only for known events
+	w, _ := request(http.MethodPost, "/customer/admin/webhooks", map[string]interface{}{"url": server.URL})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w, _ = request(http.MethodPost, "/admin/webhooks", map[string]interface{}{"url": server.URL, "events": []string{"order.deleted"}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w, _ = request(http.MethodPost, "/admin/webhooks", map[string]interface{}{"url": "ftp://example.com"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w, response := request(http.MethodPost, "/admin/webhooks", map[string]interface{}{"url": server.URL, "events": []string{"message.created"}})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	created := response["data"].(map[string]interface{})
+	secret := created["secret"].(string)
+	webhookID := uint(created["id"].(float64))
+	assert.True(t, strings.HasPrefix(secret, "whsec_"))
+
+	w, response = request(http.MethodGet, "/admin/webhooks", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, response["data"].([]interface{})[0].(map[string]interface{}), "secret")
+
+	// A new message is delivered with a verifiable signature
+	sendMessage("Hello")
+	attempted, err := webhooks.DeliverDue(10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, attempted)
+	if assert.Len(t, receiver.requests, 1) {
+		sent := receiver.requests[0]
+		assert.Equal(t, "message.created", sent.Header.Get(services.WebhookEventHeader))
+		var timestamp int64
+		_, err := fmt.Sscanf(sent.Header.Get(services.WebhookSignatureHeader), "t=%d,", &timestamp)
+		assert.NoError(t, err)
+		assert.Equal(t, services.SignWebhookPayload(secret, timestamp, receiver.bodies[0]), sent.Header.Get(services.WebhookSignatureHeader))
+
+		var payload map[string]interface{}
+		assert.NoError(t, json.Unmarshal(receiver.bodies[0], &payload))
+		assert.Equal(t, "message.created", payload["event"])
+		assert.Equal(t, "Hello", payload["data"].(map[string]interface{})["message"].(map[string]interface{})["text"])
+	}
+
+	// A failed delivery is retried later rather than immediately
+	receiver.setStatus(http.StatusInternalServerError)
+	sendMessage("Are you there?")
+	attempted, err = webhooks.DeliverDue(10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, attempted)
+	attempted, err = webhooks.DeliverDue(10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, attempted)
+
+	var failing models.WebhookDelivery
+	db.Order("id DESC").First(&failing)
+	assert.Equal(t, models.DeliveryPending, failing.Status)
+	assert.Equal(t, 1, failing.Attempts)
+	assert.Equal(t, http.StatusInternalServerError, *failing.LastStatusCode)
+	assert.True(t, failing.NextAttemptAt.After(time.Now()))
+
+	// The last attempt gives up on the delivery
+	db.Model(&failing).Updates(map[string]interface{}{"attempts": services.MaxWebhookAttempts - 1, "next_attempt_at": time.Now().Add(-time.Minute)})
+	attempted, err = webhooks.DeliverDue(10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, attempted)
+	db.First(&failing, failing.ID)
+	assert.Equal(t, models.DeliveryFailed, failing.Status)
+
+	// The delivery log lists the newest delivery first
+	w, response = request(http.MethodGet, fmt.Sprintf("/admin/webhooks/%d/deliveries", webhookID), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	deliveries := response["data"].([]interface{})
+	if assert.Len(t, deliveries, 2) {
+		assert.Equal(t, models.DeliveryFailed, deliveries[0].(map[string]interface{})["status"])
+		assert.Equal(t, models.DeliverySucceeded, deliveries[1].(map[string]interface{})["status"])
+	}
+
+	// Deleted webhooks receive nothing further
+	w, _ = request(http.MethodDelete, fmt.Sprintf("/admin/webhooks/%d", webhookID), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	sendMessage("Anyone?")
+	var queued int64
+	db.Model(&models.WebhookDelivery{}).Count(&queued)
+	assert.Equal(t, int64(2), queued)
+
+	w, _ = request(http.MethodGet, fmt.Sprintf("/admin/webhooks/%d/deliveries", webhookID), nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,186 @@
+package dualwrite
+
+import (
+	"expvar"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Stage is the rollout stage of a column migration
+// Stages only ever move forward: off -> dual_write -> shadow_read -> new
+type Stage int32
+
+const (
+	// StageOff reads and writes the legacy column only
+	StageOff Stage = iota
+	// StageDualWrite writes both columns and reads the legacy column
+	StageDualWrite
+	// StageShadowRead writes both columns, reads the legacy column, and compares it against the new one
+	StageShadowRead
+	// StageNew writes both columns and reads the new column
+	StageNew
+)
+
+var stageNames = map[Stage]string{
+	StageOff:        "off",
+	StageDualWrite:  "dual_write",
+	StageShadowRead: "shadow_read",
+	StageNew:        "new",
+}
+
+// String returns the configuration name of the stage
+func (s Stage) String() string {
+	if name, ok := stageNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("Stage(%d)", int32(s))
+}
+
+// ParseStage parses a stage name such as "shadow_read"
+func ParseStage(name string) (Stage, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for stage, stageName := range stageNames {
+		if stageName == name {
+			return stage, nil
+		}
+	}
+	return StageOff, fmt.Errorf("unknown dual-write stage %q", name)
+}
+
+// stats publishes per-field counters under /debug/vars "dual_write"
+// Keys are "<field>.writes", "<field>.compared", and "<field>.mismatches"
+var stats = expvar.NewMap("dual_write")
+
+// Field is a column being migrated from a legacy to a new representation
+type Field struct {
+	name  string
+	stage atomic.Int32
+}
+
+var (
+	registry   = make(map[string]*Field)
+	registryMu sync.Mutex
+)
+
+// Register returns the field with the given name, creating it in StageOff
+// Names follow the "<table>.<column>" convention (e.g. "orders.price")
+func Register(name string) *Field {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if field, ok := registry[name]; ok {
+		return field
+	}
+	field := &Field{name: name}
+	registry[name] = field
+	return field
+}
+
+// Lookup returns a registered field by name
+func Lookup(name string) (*Field, bool) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	field, ok := registry[name]
+	return field, ok
+}
+
+// Names returns the names of all registered fields in sorted order
+func Names() []string {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Name returns the field name
+func (f *Field) Name() string {
+	return f.name
+}
+
+// Stage returns the current rollout stage
+func (f *Field) Stage() Stage {
+	return Stage(f.stage.Load())
+}
+
+// SetStage changes the rollout stage
+func (f *Field) SetStage(stage Stage) {
+	f.stage.Store(int32(stage))
+}
+
+// WritesNew reports whether writes should populate the new column
+func (f *Field) WritesNew() bool {
+	return f.Stage() >= StageDualWrite
+}
+
+// ShadowReads reports whether reads should compare the legacy and new columns
+func (f *Field) ShadowReads() bool {
+	return f.Stage() == StageShadowRead
+}
+
+// ReadsNew reports whether reads should be served from the new column
+func (f *Field) ReadsNew() bool {
+	return f.Stage() == StageNew
+}
+
+// RecordWrite counts a write of the new representation
+func (f *Field) RecordWrite() {
+	stats.Add(f.name+".writes", 1)
+}
+
+// Observe records the result of a shadow read comparison for the row identified by key
+// Mismatches are counted and logged so they can be investigated before cutting over
+func (f *Field) Observe(key interface{}, legacy, current interface{}, match bool) {
+	stats.Add(f.name+".compared", 1)
+	if match {
+		return
+	}
+	stats.Add(f.name+".mismatches", 1)
+	log.Printf("dual-write mismatch on %s (key=%v): legacy=%v new=%v", f.name, key, legacy, current)
+}
+
+// Counter returns the current value of a counter for this field ("writes", "compared", "mismatches")
+func (f *Field) Counter(name string) int64 {
+	if v, ok := stats.Get(f.name + "." + name).(*expvar.Int); ok {
+		return v.Value()
+	}
+	return 0
+}
+
+// Configure sets field stages from a comma-separated spec such as
+// "orders.price=shadow_read". Unknown fields are rejected so typos don't go unnoticed.
+func Configure(spec string) error {
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, stageName, found := strings.Cut(entry, "=")
+		if !found {
+			return fmt.Errorf("invalid dual-write entry %q (expected field=stage)", entry)
+		}
+
+		field, ok := Lookup(strings.TrimSpace(name))
+		if !ok {
+			return fmt.Errorf("unknown dual-write field %q", name)
+		}
+
+		stage, err := ParseStage(stageName)
+		if err != nil {
+			return err
+		}
+		field.SetStage(stage)
+		log.Printf("Dual-write stage for %s set to %s", field.Name(), stage)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package dualwrite
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStage(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Stage
+		wantErr bool
+	}{
+		{"off", StageOff, false},
+		{"dual_write", StageDualWrite, false},
+		{" Shadow_Read ", StageShadowRead, false},
+		{"new", StageNew, false},
+		{"sideways", StageOff, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseStage(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.want, mustParse(t, got.String()))
+		})
+	}
+}
+
+func mustParse(t *testing.T, name string) Stage {
+	stage, err := ParseStage(name)
+	assert.NoError(t, err)
+	return stage
+}
+
+func TestField_StagePredicates(t *testing.T) {
+	field := Register("test.predicates")
+
+	field.SetStage(StageOff)
+	assert.False(t, field.WritesNew())
+	assert.False(t, field.ShadowReads())
+	assert.False(t, field.ReadsNew())
+
+	field.SetStage(StageDualWrite)
+	assert.True(t, field.WritesNew())
+	assert.False(t, field.ShadowReads())
+
+	field.SetStage(StageShadowRead)
+	assert.True(t, field.WritesNew())
+	assert.True(t, field.ShadowReads())
+	assert.False(t, field.ReadsNew())
+
+	field.SetStage(StageNew)
+	assert.True(t, field.WritesNew())
+	assert.True(t, field.ReadsNew())
+}
+
+func TestRegister_ReturnsSameField(t *testing.T) {
+	assert.Same(t, Register("test.same"), Register("test.same"))
+	assert.Contains(t, Names(), "test.same")
+}
+
+func TestField_Observe(t *testing.T) {
+	field := Register("test.observe")
+
+	field.Observe(1, 10, 10, true)
+	field.Observe(2, 10, 11, false)
+	field.RecordWrite()
+
+	assert.Equal(t, int64(2), field.Counter("compared"))
+	assert.Equal(t, int64(1), field.Counter("mismatches"))
+	assert.Equal(t, int64(1), field.Counter("writes"))
+}
+
+func TestConfigure(t *testing.T) {
+	field := Register("test.configure")
+
+	assert.NoError(t, Configure("test.configure=shadow_read"))
+	assert.Equal(t, StageShadowRead, field.Stage())
+
+	assert.NoError(t, Configure(""))
+	assert.Equal(t, StageShadowRead, field.Stage())
+
+	assert.Error(t, Configure("test.unknown=new"))
+	assert.Error(t, Configure("test.configure"))
+	assert.Error(t, Configure("test.configure=later"))
+}
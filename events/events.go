@@ -0,0 +1,181 @@
+// Package events is an internal bus for domain events such as an order being created
+// Publishers announce what happened once it has been saved, and subscribers (webhooks, notifications, metrics)
+// react to it without the publisher knowing about them. Events are handled in process by default; with a
+// Transport they go through a broker and are handled once by whichever API instance receives them
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+)
+
+// Event is something that happened in the domain
+// Events cross process boundaries as JSON, so they must encode and decode without loss for their subscribers
+type Event interface {
+	// EventName identifies the kind of event, e.g. "order.created"
+	EventName() string
+}
+
+// Message is an encoded event as carried by a Transport
+type Message struct {
+	ID      string // assigned by the transport, for acknowledging the message
+	Name    string
+	Payload []byte
+}
+
+// Transport carries events between API instances through a broker
+// Each message is delivered to one instance, and again to another if it is not acknowledged in time
+type Transport interface {
+	// Send hands an event to the broker
+	Send(ctx context.Context, message Message) error
+
+	// Receive returns up to max messages for this instance, waiting briefly for one when none is ready
+	Receive(ctx context.Context, max int) ([]Message, error)
+
+	// Ack marks a message as handled, so that no instance receives it again
+	Ack(ctx context.Context, message Message) error
+}
+
+// subscriber is one handler of an event
+type subscriber struct {
+	name   string
+	handle func(ctx context.Context, event Event) error
+}
+
+// Bus delivers published events to the subscribers of their name
+type Bus struct {
+	transport Transport // nil handles events in process
+
+	mu          sync.RWMutex
+	subscribers map[string][]subscriber
+	decoders    map[string]func(payload []byte) (Event, error)
+}
+
+// NewBus creates a bus without subscribers; a nil transport handles events in process, as they are published
+func NewBus(transport Transport) *Bus {
+	return &Bus{
+		transport:   transport,
+		subscribers: make(map[string][]subscriber),
+		decoders:    make(map[string]func(payload []byte) (Event, error)),
+	}
+}
+
+// Subscribe calls handle with every event of type T published on the bus
+// name identifies the subscriber in logs; subscribers are added once at startup, before events are published
+func Subscribe[T Event](bus *Bus, name string, handle func(ctx context.Context, event T) error) {
+	var zero T
+	eventName := zero.EventName()
+
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+	bus.subscribers[eventName] = append(bus.subscribers[eventName], subscriber{
+		name: name,
+		handle: func(ctx context.Context, event Event) error {
+			typed, ok := event.(T)
+			if !ok {
+				return fmt.Errorf("unexpected event type %T", event)
+			}
+			return handle(ctx, typed)
+		},
+	})
+	bus.decoders[eventName] = func(payload []byte) (Event, error) {
+		var event T
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return nil, err
+		}
+		return event, nil
+	}
+}
+
+// Publish announces an event that has already been saved
+// Subscriber failures are logged rather than returned, since the change itself succeeded; when the event
+// cannot be handed to the transport it is handled in process instead, so that it is not lost
+func (b *Bus) Publish(ctx context.Context, event Event) {
+	if b.transport != nil {
+		err := b.send(ctx, event)
+		if err == nil {
+			return
+		}
+		log.Printf("Failed to send %s event, handling it in process: %v", event.EventName(), err)
+	}
+	b.dispatch(ctx, event)
+}
+
+// PublishEncoded announces an event that was encoded earlier, e.g. by a transactional outbox
+// Unlike Publish it returns the failure to hand the event to the transport, so that the caller can retry;
+// without a transport the event is decoded and handled in process, and an event nobody subscribes to is skipped
+func (b *Bus) PublishEncoded(ctx context.Context, name string, payload []byte) error {
+	if b.transport != nil {
+		return b.transport.Send(ctx, Message{Name: name, Payload: payload})
+	}
+
+	b.mu.RLock()
+	decode, ok := b.decoders[name]
+	b.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+	event, err := decode(payload)
+	if err != nil {
+		return fmt.Errorf("failed to decode %s event: %w", name, err)
+	}
+	b.dispatch(ctx, event)
+	return nil
+}
+
+// send encodes the event and hands it to the transport
+func (b *Bus) send(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+	return b.transport.Send(ctx, Message{Name: event.EventName(), Payload: payload})
+}
+
+// Consume handles up to max events received from the transport and returns how many were received
+// Messages are acknowledged once their subscribers ran, whether or not they succeeded, and also when they
+// cannot be decoded, so that one bad message does not come back forever
+func (b *Bus) Consume(ctx context.Context, max int) (int, error) {
+	if b.transport == nil {
+		return 0, nil
+	}
+	messages, err := b.transport.Receive(ctx, max)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, message := range messages {
+		b.mu.RLock()
+		decode, ok := b.decoders[message.Name]
+		b.mu.RUnlock()
+
+		if ok {
+			event, err := decode(message.Payload)
+			if err != nil {
+				log.Printf("Dropping %s event %s that cannot be decoded: %v", message.Name, message.ID, err)
+			} else {
+				b.dispatch(ctx, event)
+			}
+		}
+		if err := b.transport.Ack(ctx, message); err != nil {
+			log.Printf("Failed to acknowledge %s event %s: %v", message.Name, message.ID, err)
+		}
+	}
+	return len(messages), nil
+}
+
+// dispatch calls the subscribers of the event one after another, logging their failures
+func (b *Bus) dispatch(ctx context.Context, event Event) {
+	b.mu.RLock()
+	subscribers := b.subscribers[event.EventName()]
+	b.mu.RUnlock()
+
+	for _, sub := range subscribers {
+		if err := sub.handle(ctx, event); err != nil {
+			log.Printf("%s subscriber failed to handle %s event: %v", sub.name, event.EventName(), err)
+		}
+	}
+}
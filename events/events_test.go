@@ -0,0 +1,109 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// orderPlaced is a test event
+type orderPlaced struct {
+	OrderID uint `json:"order_id"`
+}
+
+func (orderPlaced) EventName() string { return "order.placed" }
+
+// fakeTransport keeps sent messages in memory and hands them out on Receive
+type fakeTransport struct {
+	sendErr error
+	queued  []Message
+	acked   []string
+}
+
+func (t *fakeTransport) Send(ctx context.Context, message Message) error {
+	if t.sendErr != nil {
+		return t.sendErr
+	}
+	message.ID = string(rune('a' + len(t.queued)))
+	t.queued = append(t.queued, message)
+	return nil
+}
+
+func (t *fakeTransport) Receive(ctx context.Context, max int) ([]Message, error) {
+	n := min(max, len(t.queued))
+	received := t.queued[:n]
+	t.queued = t.queued[n:]
+	return received, nil
+}
+
+func (t *fakeTransport) Ack(ctx context.Context, message Message) error {
+	t.acked = append(t.acked, message.ID)
+	return nil
+}
+
+func TestBus_InProcess(t *testing.T) {
+	bus := NewBus(nil)
+	var handled []string
+	Subscribe(bus, "failing", func(ctx context.Context, event orderPlaced) error {
+		handled = append(handled, "failing")
+		return errors.New("boom")
+	})
+	Subscribe(bus, "webhooks", func(ctx context.Context, event orderPlaced) error {
+		handled = append(handled, "webhooks")
+		assert.Equal(t, uint(7), event.OrderID)
+		return nil
+	})
+
+	bus.Publish(context.Background(), orderPlaced{OrderID: 7})
+	assert.Equal(t, []string{"failing", "webhooks"}, handled, "a failing subscriber does not stop the others")
+}
+
+func TestBus_Transport(t *testing.T) {
+	transport := &fakeTransport{}
+	bus := NewBus(transport)
+	var handled []uint
+	Subscribe(bus, "webhooks", func(ctx context.Context, event orderPlaced) error {
+		handled = append(handled, event.OrderID)
+		return nil
+	})
+
+	bus.Publish(context.Background(), orderPlaced{OrderID: 7})
+	assert.Empty(t, handled, "events wait for the consumer")
+	assert.Equal(t, []Message{{ID: "a", Name: "order.placed", Payload: []byte(`{"order_id":7}`)}}, transport.queued)
+
+	transport.queued = append(transport.queued, Message{ID: "b", Name: "order.placed", Payload: []byte("not json")})
+	received, err := bus.Consume(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, received)
+	assert.Equal(t, []uint{7}, handled)
+	assert.Equal(t, []string{"a", "b"}, transport.acked, "undecodable messages are acknowledged too")
+
+	// Events the broker cannot take are handled in process
+	transport.sendErr = errors.New("unreachable")
+	bus.Publish(context.Background(), orderPlaced{OrderID: 8})
+	assert.Equal(t, []uint{7, 8}, handled)
+}
+
+func TestBus_PublishEncoded(t *testing.T) {
+	bus := NewBus(nil)
+	var handled []uint
+	Subscribe(bus, "webhooks", func(ctx context.Context, event orderPlaced) error {
+		handled = append(handled, event.OrderID)
+		return nil
+	})
+
+	assert.NoError(t, bus.PublishEncoded(context.Background(), "order.placed", []byte(`{"order_id":7}`)))
+	assert.Equal(t, []uint{7}, handled)
+	assert.NoError(t, bus.PublishEncoded(context.Background(), "order.archived", []byte(`{}`)), "events nobody subscribes to are skipped")
+	assert.Error(t, bus.PublishEncoded(context.Background(), "order.placed", []byte("not json")))
+
+	// With a transport the failure to send is returned, rather than handled in process
+	transport := &fakeTransport{sendErr: errors.New("unreachable")}
+	bus = NewBus(transport)
+	assert.Error(t, bus.PublishEncoded(context.Background(), "order.placed", []byte(`{"order_id":8}`)))
+	transport.sendErr = nil
+	assert.NoError(t, bus.PublishEncoded(context.Background(), "order.placed", []byte(`{"order_id":8}`)))
+	assert.Len(t, transport.queued, 1)
+}
@@ -0,0 +1,121 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis stream settings
+const (
+	RedisStream      = "events"
+	RedisGroup       = "api"
+	redisMaxLen      = 100000           // trimmed approximately, so the stream does not grow without bound
+	redisBlock       = 2 * time.Second  // how long Receive waits for a new message
+	redisReclaimIdle = 10 * time.Minute // a message unacknowledged this long belonged to an instance that stopped
+)
+
+// RedisTransport implements Transport on a Redis stream read by one consumer group
+// Every API instance is a consumer of the group, so each event is handled by one of them
+type RedisTransport struct {
+	client   *redis.Client
+	consumer string
+}
+
+// NewRedisTransport connects to the Redis server at a redis:// or rediss:// URL and joins the consumer group,
+// creating the stream and the group when they do not exist yet
+func NewRedisTransport(url string) (*RedisTransport, error) {
+	options, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+	}
+	client := redis.NewClient(options)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err = client.XGroupCreateMkStream(ctx, RedisStream, RedisGroup, "$").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		_ = client.Close()
+		return nil, fmt.Errorf("failed to set up the Redis event stream: %w", err)
+	}
+	return &RedisTransport{client: client, consumer: consumerName()}, nil
+}
+
+// Send hands an event to the broker
+func (t *RedisTransport) Send(ctx context.Context, message Message) error {
+	return t.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: RedisStream,
+		MaxLen: redisMaxLen,
+		Approx: true,
+		Values: map[string]interface{}{"name": message.Name, "payload": message.Payload},
+	}).Err()
+}
+
+// Receive returns up to max messages for this instance, waiting briefly for one when none is ready
+// Messages another instance received but never acknowledged are taken over first
+func (t *RedisTransport) Receive(ctx context.Context, max int) ([]Message, error) {
+	claimed, _, err := t.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   RedisStream,
+		Group:    RedisGroup,
+		Consumer: t.consumer,
+		MinIdle:  redisReclaimIdle,
+		Start:    "0-0",
+		Count:    int64(max),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(claimed) > 0 {
+		return redisMessages(claimed), nil
+	}
+
+	streams, err := t.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    RedisGroup,
+		Consumer: t.consumer,
+		Streams:  []string{RedisStream, ">"},
+		Count:    int64(max),
+		Block:    redisBlock,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var messages []Message
+	for _, stream := range streams {
+		messages = append(messages, redisMessages(stream.Messages)...)
+	}
+	return messages, nil
+}
+
+// Ack marks a message as handled, so that no instance receives it again
+func (t *RedisTransport) Ack(ctx context.Context, message Message) error {
+	return t.client.XAck(ctx, RedisStream, RedisGroup, message.ID).Err()
+}
+
+// redisMessages converts stream entries to messages; entries missing fields become messages no subscriber knows
+func redisMessages(entries []redis.XMessage) []Message {
+	messages := make([]Message, len(entries))
+	for i, entry := range entries {
+		name, _ := entry.Values["name"].(string)
+		payload, _ := entry.Values["payload"].(string)
+		messages[i] = Message{ID: entry.ID, Name: name, Payload: []byte(payload)}
+	}
+	return messages
+}
+
+// consumerName identifies this process within the consumer group
+func consumerName() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "api"
+	}
+	return host + "-" + strconv.Itoa(os.Getpid())
+}
@@ -0,0 +1,136 @@
+package flags
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+)
+
+// RefreshInterval is how long stored flags are reused before they are loaded again
+// A change made through the admin API applies at once on the instance that made it
+// and within this interval everywhere else
+const RefreshInterval = 10 * time.Second
+
+// Loader returns the stored flags
+type Loader func() ([]models.FeatureFlag, error)
+
+// userKey is the context key holding the ID of the user flags are checked for
+type userKey struct{}
+
+var (
+	mu        sync.Mutex
+	loader    Loader
+	stored    map[string]models.FeatureFlag
+	loadedAt  time.Time
+	overrides = make(map[string]bool)
+)
+
+// SetLoader sets where stored flags come from (nil leaves only the overrides)
+// It is configured once at startup
+func SetLoader(load Loader) {
+	mu.Lock()
+	defer mu.Unlock()
+	loader = load
+	stored = nil
+	loadedAt = time.Time{}
+}
+
+// Refresh drops the stored flags so that the next check loads them again
+func Refresh() {
+	mu.Lock()
+	defer mu.Unlock()
+	loadedAt = time.Time{}
+}
+
+// Configure forces flags on or off from a comma-separated spec such as
+// "negotiation=true,payments=false"; forced flags ignore their stored rollout
+func Configure(spec string) error {
+	parsed := make(map[string]bool)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, value, found := strings.Cut(entry, "=")
+		if !found {
+			return fmt.Errorf("invalid feature flag entry %q (expected flag=true|false)", entry)
+		}
+		name = strings.TrimSpace(name)
+		enabled, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("invalid feature flag setting %q for %s", value, name)
+		}
+		parsed[name] = enabled
+		log.Printf("Feature flag %s forced to %t", name, enabled)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	overrides = parsed
+	return nil
+}
+
+// Override reports whether the flag is forced on or off, and to which
+func Override(name string) (enabled bool, forced bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	enabled, forced = overrides[name]
+	return enabled, forced
+}
+
+// WithUser returns a copy of ctx in which flags are checked for the user
+func WithUser(ctx context.Context, userID uint) context.Context {
+	return context.WithValue(ctx, userKey{}, userID)
+}
+
+// UserFrom returns the user flags are checked for in ctx, or 0 when there is none
+func UserFrom(ctx context.Context) uint {
+	if c, ok := ctx.(*gin.Context); ok {
+		if c.Request == nil {
+			return 0
+		}
+		ctx = c.Request.Context()
+	}
+	userID, _ := ctx.Value(userKey{}).(uint)
+	return userID
+}
+
+// Enabled reports whether the flag is on for the user in ctx (see WithUser)
+// Unknown flags are off. A Gin context can be passed directly
+func Enabled(ctx context.Context, name string) bool {
+	if enabled, forced := Override(name); forced {
+		return enabled
+	}
+	flag, ok := lookup(name)
+	return ok && flag.EnabledFor(UserFrom(ctx))
+}
+
+// lookup returns a stored flag, loading the flags again when they are older than RefreshInterval
+// When loading fails the previously loaded flags are kept until the next attempt
+func lookup(name string) (models.FeatureFlag, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if loader != nil && time.Since(loadedAt) >= RefreshInterval {
+		loadedAt = time.Now()
+		if loaded, err := loader(); err != nil {
+			log.Printf("Failed to load feature flags: %v", err)
+		} else {
+			stored = make(map[string]models.FeatureFlag, len(loaded))
+			for _, flag := range loaded {
+				stored[flag.Name] = flag
+			}
+		}
+	}
+
+	flag, ok := stored[name]
+	return flag, ok
+}
@@ -0,0 +1,96 @@
+package flags
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnabled(t *testing.T) {
+	loads := 0
+	stored := []models.FeatureFlag{
+		{Name: "payments", Enabled: true},
+		{Name: "negotiation", UserIDs: []uint{7}},
+	}
+	SetLoader(func() ([]models.FeatureFlag, error) {
+		loads++
+		return stored, nil
+	})
+	defer SetLoader(nil)
+
+	anonymous := context.Background()
+	jane := WithUser(anonymous, 7)
+	sam := WithUser(anonymous, 8)
+
+	assert.True(t, Enabled(anonymous, "payments"))
+	assert.True(t, Enabled(jane, "negotiation"))
+	assert.False(t, Enabled(sam, "negotiation"))
+	assert.False(t, Enabled(anonymous, "negotiation"))
+	assert.False(t, Enabled(jane, "unknown"), "unknown flags are off")
+	assert.Equal(t, 1, loads, "stored flags are reused between checks")
+
+	// Changes are picked up after a refresh
+	stored = []models.FeatureFlag{{Name: "negotiation", Enabled: true}}
+	Refresh()
+	assert.True(t, Enabled(sam, "negotiation"))
+	assert.False(t, Enabled(anonymous, "payments"))
+	assert.Equal(t, 2, loads)
+
+	// A failed load keeps the flags loaded before
+	SetLoader(func() ([]models.FeatureFlag, error) { return nil, errors.New("connection refused") })
+	assert.False(t, Enabled(sam, "negotiation"))
+
+	// A Gin context checks flags for the user on its request
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/", nil).WithContext(jane)
+	assert.Equal(t, uint(7), UserFrom(c))
+	assert.Equal(t, uint(0), UserFrom(anonymous))
+}
+
+func TestConfigure(t *testing.T) {
+	SetLoader(func() ([]models.FeatureFlag, error) {
+		return []models.FeatureFlag{{Name: "payments", Enabled: true}}, nil
+	})
+	defer SetLoader(nil)
+	defer func() { _ = Configure("") }()
+
+	assert.NoError(t, Configure(" negotiation=true, payments=false "))
+	assert.True(t, Enabled(context.Background(), "negotiation"))
+	assert.False(t, Enabled(context.Background(), "payments"), "overrides win over stored flags")
+	enabled, forced := Override("payments")
+	assert.True(t, forced)
+	assert.False(t, enabled)
+	_, forced = Override("unknown")
+	assert.False(t, forced)
+
+	assert.Error(t, Configure("negotiation"))
+	assert.Error(t, Configure("negotiation=soon"))
+	assert.True(t, Enabled(context.Background(), "negotiation"), "invalid specs leave the overrides unchanged")
+}
+
+func TestFeatureFlag_EnabledFor(t *testing.T) {
+	listed := models.FeatureFlag{Name: "negotiation", UserIDs: []uint{3}}
+	assert.True(t, listed.EnabledFor(3))
+	assert.False(t, listed.EnabledFor(4))
+
+	everyone := models.FeatureFlag{Name: "negotiation", Percentage: 100}
+	assert.True(t, everyone.EnabledFor(0))
+
+	// A percentage rollout reaches roughly that share of users, and keeps them as it grows
+	quarter := models.FeatureFlag{Name: "negotiation", Percentage: 25}
+	half := models.FeatureFlag{Name: "negotiation", Percentage: 50}
+	enabled := 0
+	for id := uint(1); id <= 1000; id++ {
+		if quarter.EnabledFor(id) {
+			enabled++
+			assert.True(t, half.EnabledFor(id))
+		}
+	}
+	assert.InDelta(t, 250, enabled, 60)
+	assert.False(t, quarter.EnabledFor(0), "anonymous callers are not in percentage rollouts")
+}
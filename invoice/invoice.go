@@ -0,0 +1,162 @@
+// Package invoice renders order invoices as PDF documents
+package invoice
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/kendall-kelly/kendalls-nails-api/utils"
+)
+
+// sellerName heads every invoice
+const sellerName = "Kendall's Nails"
+
+// Party is a customer or technician named on an invoice
+type Party struct {
+	Name  string
+	Email string
+}
+
+// Line is one priced entry of an invoice
+type Line struct {
+	Description string
+	Quantity    int
+	UnitPrice   float64
+	Amount      float64
+}
+
+// Invoice is the content of an order invoice
+type Invoice struct {
+	Number      string
+	IssuedAt    time.Time
+	OrderID     uint
+	Description string
+	Status      string
+	Currency    string // ISO 4217 code of every amount; empty means the default currency
+	Customer    Party
+	Technician  Party
+	Lines       []Line
+	Subtotal    float64
+	TaxPercent  float64 // zero omits the tax line
+	Tax         float64
+	Total       float64
+}
+
+// Column widths of the line item table in millimetres; together they span the printable width of an A4 page
+const (
+	descriptionWidth = 100
+	quantityWidth    = 20
+	unitPriceWidth   = 30
+	amountWidth      = 30
+	rowHeight        = 8
+)
+
+// Render lays the invoice out on an A4 page and returns the PDF
+func Render(inv Invoice) ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle("Invoice "+inv.Number, true)
+	pdf.SetAuthor(sellerName, true)
+	// Fixed dates and a sorted catalog keep the document identical across downloads
+	pdf.SetCreationDate(inv.IssuedAt)
+	pdf.SetModificationDate(inv.IssuedAt)
+	pdf.SetCatalogSort(true)
+	pdf.AddPage()
+
+	// The core fonts are Latin-1, so text is translated from UTF-8 first
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	price := func(amount float64) string { return tr(money(amount, inv.Currency)) }
+
+	pdf.SetFont("Helvetica", "B", 20)
+	pdf.CellFormat(0, 10, tr(sellerName), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.CellFormat(0, 6, tr("Invoice "+inv.Number), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 6, "Issued "+inv.IssuedAt.Format("January 2, 2006"), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 6, fmt.Sprintf("Order #%d (%s)", inv.OrderID, inv.Status), "", 1, "L", false, 0, "")
+	pdf.Ln(6)
+
+	// Customer and technician side by side
+	top := pdf.GetY()
+	writeParty(pdf, tr, 10, top, "Bill to", inv.Customer)
+	writeParty(pdf, tr, 110, top, "Technician", inv.Technician)
+	pdf.SetXY(10, top+24)
+
+	if inv.Description != "" {
+		pdf.SetFont("Helvetica", "B", 11)
+		pdf.CellFormat(0, 6, "Order details", "", 1, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.MultiCell(0, 5, tr(inv.Description), "", "L", false)
+		pdf.Ln(4)
+	}
+
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.SetFillColor(247, 214, 224)
+	pdf.CellFormat(descriptionWidth, rowHeight, "Description", "1", 0, "L", true, 0, "")
+	pdf.CellFormat(quantityWidth, rowHeight, "Qty", "1", 0, "R", true, 0, "")
+	pdf.CellFormat(unitPriceWidth, rowHeight, "Unit price", "1", 0, "R", true, 0, "")
+	pdf.CellFormat(amountWidth, rowHeight, "Amount", "1", 1, "R", true, 0, "")
+
+	pdf.SetFont("Helvetica", "", 10)
+	for _, line := range inv.Lines {
+		pdf.CellFormat(descriptionWidth, rowHeight, tr(truncate(pdf, tr, line.Description, descriptionWidth-2)), "1", 0, "L", false, 0, "")
+		pdf.CellFormat(quantityWidth, rowHeight, fmt.Sprintf("%d", line.Quantity), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(unitPriceWidth, rowHeight, price(line.UnitPrice), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(amountWidth, rowHeight, price(line.Amount), "1", 1, "R", false, 0, "")
+	}
+
+	pdf.Ln(2)
+	writeTotal(pdf, "Subtotal", price(inv.Subtotal), false)
+	if inv.TaxPercent > 0 {
+		writeTotal(pdf, fmt.Sprintf("Tax (%g%%)", inv.TaxPercent), price(inv.Tax), false)
+	}
+	writeTotal(pdf, "Total", price(inv.Total), true)
+
+	var out bytes.Buffer
+	if err := pdf.Output(&out); err != nil {
+		return nil, fmt.Errorf("failed to render invoice: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+// writeParty prints a labelled name and email block at the given position
+func writeParty(pdf *gofpdf.Fpdf, tr func(string) string, x, y float64, label string, party Party) {
+	pdf.SetXY(x, y)
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(90, 6, label, "", 2, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(90, 5, tr(party.Name), "", 2, "L", false, 0, "")
+	pdf.CellFormat(90, 5, tr(party.Email), "", 2, "L", false, 0, "")
+}
+
+// writeTotal prints a right-aligned label and formatted amount under the amount column
+func writeTotal(pdf *gofpdf.Fpdf, label string, amount string, bold bool) {
+	style := ""
+	if bold {
+		style = "B"
+	}
+	pdf.SetFont("Helvetica", style, 10)
+	pdf.CellFormat(descriptionWidth+quantityWidth, rowHeight, "", "", 0, "L", false, 0, "")
+	pdf.CellFormat(unitPriceWidth, rowHeight, label, "", 0, "R", false, 0, "")
+	pdf.CellFormat(amountWidth, rowHeight, amount, "", 1, "R", false, 0, "")
+}
+
+// truncate shortens text with an ellipsis so that it fits the width at the current font
+func truncate(pdf *gofpdf.Fpdf, tr func(string) string, text string, width float64) string {
+	if pdf.GetStringWidth(tr(text)) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && pdf.GetStringWidth(tr(string(runes)+"...")) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
+
+// money formats an amount in the currency, with the sign ahead of the symbol for discounts
+func money(amount float64, currency string) string {
+	if currency == "" {
+		currency = utils.DefaultCurrency
+	}
+	return utils.FormatMoney(utils.ToCents(amount), currency)
+}
@@ -0,0 +1,47 @@
+package invoice
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	inv := Invoice{
+		Number:      "INV-000042",
+		IssuedAt:    time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC),
+		OrderID:     42,
+		Description: "Almond set with chrome tips",
+		Status:      "accepted",
+		Currency:    "EUR",
+		Customer:    Party{Name: "Zoë Customer", Email: "zoe@example.com"},
+		Technician:  Party{Name: "Alex Tech", Email: "alex@example.com"},
+		Lines: []Line{
+			{Description: "Custom nail set", Quantity: 1, UnitPrice: 40, Amount: 40},
+			{Description: strings.Repeat("Very long add-on name ", 10), Quantity: 2, UnitPrice: 5, Amount: 10},
+			{Description: "Autumn discount", Quantity: 1, UnitPrice: -5, Amount: -5},
+		},
+		Subtotal:   45,
+		TaxPercent: 8.25,
+		Tax:        3.71,
+		Total:      48.71,
+	}
+
+	data, err := Render(inv)
+	assert.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(data, []byte("%PDF-")))
+
+	// The same invoice renders to the same document
+	again, err := Render(inv)
+	assert.NoError(t, err)
+	assert.Equal(t, data, again)
+}
+
+func TestMoney(t *testing.T) {
+	assert.Equal(t, "$12.50", money(12.5, ""))
+	assert.Equal(t, "-$5.00", money(-5, "USD"))
+	assert.Equal(t, "€7.25", money(7.25, "EUR"))
+}
@@ -0,0 +1,177 @@
+// Package jobs runs periodic background tasks alongside the API server
+// Each job runs on its own interval in its own goroutine, so a slow job never delays another one,
+// and runs of the same job never overlap
+package jobs
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// stats publishes per-job counters under /debug/vars "jobs"
+// Keys are "<job>.runs", "<job>.failures", "<job>.last_duration_ms", and "<job>.last_success_unix"
+var stats = expvar.NewMap("jobs")
+
+// overdueRuns is how many intervals a job may go without a successful run before Check reports it
+const overdueRuns = 3
+
+// Job is a task run every Interval
+// Run receives a context that is canceled on shutdown and should return promptly once it is
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Runner schedules jobs until it is stopped
+type Runner struct {
+	jobs        []Job
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+	started     bool
+	stopped     bool
+	startedAt   time.Time
+	lastSuccess map[string]time.Time // job name => start of its last successful run
+	mu          sync.Mutex
+}
+
+// NewRunner creates a runner with no jobs
+func NewRunner() *Runner {
+	return &Runner{lastSuccess: make(map[string]time.Time)}
+}
+
+// Add registers a job; jobs must be added before Start
+func (r *Runner) Add(job Job) {
+	if job.Name == "" || job.Interval <= 0 || job.Run == nil {
+		panic(fmt.Sprintf("jobs: invalid job %q", job.Name))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.started {
+		panic("jobs: Add called after Start")
+	}
+	r.jobs = append(r.jobs, job)
+}
+
+// Start runs every job in the background, first after one interval and then every interval
+func (r *Runner) Start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.started {
+		return
+	}
+	r.started = true
+	r.startedAt = time.Now()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	for _, job := range r.jobs {
+		r.wg.Add(1)
+		go r.loop(ctx, job)
+	}
+}
+
+// Stop cancels the jobs and waits for runs in progress to return
+// It gives up when ctx is done, returning ctx's error
+func (r *Runner) Stop(ctx context.Context) error {
+	r.mu.Lock()
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.stopped = true
+	r.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// loop runs job every interval until ctx is canceled
+func (r *Runner) loop(ctx context.Context, job Job) {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			started := time.Now()
+			if runOnce(ctx, job) {
+				r.mu.Lock()
+				r.lastSuccess[job.Name] = started
+				r.mu.Unlock()
+			}
+		}
+	}
+}
+
+// Check reports an error when the runner is not running or a job has not succeeded in its last few intervals
+// It backs the job runner entry of the detailed health check
+func (r *Runner) Check(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.started || r.stopped {
+		return fmt.Errorf("job runner is not running")
+	}
+
+	now := time.Now()
+	var overdue []string
+	for _, job := range r.jobs {
+		since := r.startedAt
+		if last, ok := r.lastSuccess[job.Name]; ok {
+			since = last
+		}
+		if now.Sub(since) > overdueRuns*job.Interval {
+			overdue = append(overdue, job.Name)
+		}
+	}
+	if len(overdue) > 0 {
+		return fmt.Errorf("no successful run in %d intervals: %s", overdueRuns, strings.Join(overdue, ", "))
+	}
+	return nil
+}
+
+// runOnce runs the job, recording metrics and logging failures, and reports whether it succeeded
+// A panicking job counts as a failure instead of taking the server down
+func runOnce(ctx context.Context, job Job) bool {
+	started := time.Now()
+	err := func() (err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				err = fmt.Errorf("panic: %v", recovered)
+			}
+		}()
+		return job.Run(ctx)
+	}()
+
+	stats.Add(job.Name+".runs", 1)
+	duration := new(expvar.Int)
+	duration.Set(time.Since(started).Milliseconds())
+	stats.Set(job.Name+".last_duration_ms", duration)
+
+	if err != nil {
+		stats.Add(job.Name+".failures", 1)
+		log.Printf("Job %s failed: %v", job.Name, err)
+		return false
+	}
+	success := new(expvar.Int)
+	success.Set(started.Unix())
+	stats.Set(job.Name+".last_success_unix", success)
+	return true
+}
@@ -0,0 +1,130 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// counter returns the value of a per-job counter, or 0 when it has not been recorded
+func counter(name string) int64 {
+	if v, ok := stats.Get(name).(*expvar.Int); ok {
+		return v.Value()
+	}
+	return 0
+}
+
+func TestRunner_RunsJobsAndRecordsMetrics(t *testing.T) {
+	var ok, failing, panicking atomic.Int32
+
+	runner := NewRunner()
+	runner.Add(Job{Name: "test_ok", Interval: 5 * time.Millisecond, Run: func(ctx context.Context) error {
+		ok.Add(1)
+		return nil
+	}})
+	runner.Add(Job{Name: "test_failing", Interval: 5 * time.Millisecond, Run: func(ctx context.Context) error {
+		failing.Add(1)
+		return errors.New("boom")
+	}})
+	runner.Add(Job{Name: "test_panicking", Interval: 5 * time.Millisecond, Run: func(ctx context.Context) error {
+		panicking.Add(1)
+		panic("boom")
+	}})
+	runner.Start()
+
+	assert.Eventually(t, func() bool {
+		return ok.Load() >= 2 && failing.Load() >= 2 && panicking.Load() >= 2
+	}, time.Second, time.Millisecond)
+	assert.NoError(t, runner.Stop(context.Background()))
+
+	assert.GreaterOrEqual(t, counter("test_ok.runs"), int64(2))
+	assert.Equal(t, int64(0), counter("test_ok.failures"))
+	assert.NotZero(t, counter("test_ok.last_success_unix"))
+	assert.Equal(t, counter("test_failing.runs"), counter("test_failing.failures"))
+	assert.Zero(t, counter("test_failing.last_success_unix"))
+	assert.Equal(t, counter("test_panicking.runs"), counter("test_panicking.failures"))
+
+	// No run starts after Stop returns
+	runs := ok.Load()
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, runs, ok.Load())
+}
+
+func TestRunner_StopWaitsForRunInProgress(t *testing.T) {
+	started := make(chan struct{})
+	var finished atomic.Bool
+
+	runner := NewRunner()
+	runner.Add(Job{Name: "test_slow", Interval: time.Millisecond, Run: func(ctx context.Context) error {
+		if finished.Load() {
+			return nil
+		}
+		close(started)
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		finished.Store(true)
+		return nil
+	}})
+	runner.Start()
+	<-started
+
+	assert.NoError(t, runner.Stop(context.Background()))
+	assert.True(t, finished.Load())
+}
+
+func TestRunner_StopTimesOut(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	var once sync.Once
+
+	runner := NewRunner()
+	runner.Add(Job{Name: "test_stuck", Interval: time.Millisecond, Run: func(ctx context.Context) error {
+		once.Do(func() { close(started) })
+		<-release // ignores cancellation
+		return nil
+	}})
+	runner.Start()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, runner.Stop(ctx), context.DeadlineExceeded)
+}
+
+func TestRunner_AddValidatesJobs(t *testing.T) {
+	runner := NewRunner()
+	assert.Panics(t, func() { runner.Add(Job{Name: "no_run", Interval: time.Second}) })
+	assert.Panics(t, func() { runner.Add(Job{Name: "no_interval", Run: func(ctx context.Context) error { return nil }}) })
+
+	runner.Start()
+	defer runner.Stop(context.Background())
+	assert.Panics(t, func() {
+		runner.Add(Job{Name: "late", Interval: time.Second, Run: func(ctx context.Context) error { return nil }})
+	})
+}
+
+func TestRunner_Check(t *testing.T) {
+	runner := NewRunner()
+	runner.Add(Job{Name: "check_ok", Interval: 5 * time.Millisecond, Run: func(ctx context.Context) error { return nil }})
+	runner.Add(Job{Name: "check_failing", Interval: 5 * time.Millisecond, Run: func(ctx context.Context) error {
+		return errors.New("boom")
+	}})
+	assert.EqualError(t, runner.Check(context.Background()), "job runner is not running")
+
+	// A job that keeps failing is reported once it misses several intervals
+	runner.Start()
+	assert.Eventually(t, func() bool {
+		err := runner.Check(context.Background())
+		return err != nil && err.Error() == "no successful run in 3 intervals: check_failing"
+	}, time.Second, time.Millisecond)
+
+	assert.NoError(t, runner.Stop(context.Background()))
+	assert.EqualError(t, runner.Check(context.Background()), "job runner is not running")
+}
@@ -0,0 +1,171 @@
+package legacy
+
+import (
+	"expvar"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OptOutHeader lets migrated clients stop receiving legacy fields before they are removed
+// Clients send "X-Legacy-Fields: omit" once they read only the replacement fields
+const OptOutHeader = "X-Legacy-Fields"
+
+// maxTrackedClients bounds the memory used to count distinct clients per field
+const maxTrackedClients = 10000
+
+// stats publishes per-field counters under /debug/vars "legacy_fields"
+// Keys are "<field>.emitted", "<field>.omitted", and "<field>.clients"
+var stats = expvar.NewMap("legacy_fields")
+
+// Field is a renamed response field that is still emitted under its old name
+// for clients that have not moved to the replacement yet
+type Field struct {
+	name        string
+	replacement string
+	enabled     atomic.Bool
+
+	mu      sync.Mutex
+	clients map[string]struct{}
+}
+
+var (
+	registry   = make(map[string]*Field)
+	registryMu sync.Mutex
+)
+
+// Register returns the legacy field with the given name, creating it enabled
+// Names follow the "<table>.<column>" convention (e.g. "orders.image_path")
+func Register(name, replacement string) *Field {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if field, ok := registry[name]; ok {
+		return field
+	}
+	field := &Field{name: name, replacement: replacement, clients: make(map[string]struct{})}
+	field.enabled.Store(true)
+	registry[name] = field
+	return field
+}
+
+// Lookup returns a registered field by name
+func Lookup(name string) (*Field, bool) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	field, ok := registry[name]
+	return field, ok
+}
+
+// Names returns the names of all registered fields in sorted order
+func Names() []string {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Name returns the legacy field name
+func (f *Field) Name() string {
+	return f.name
+}
+
+// Replacement returns the name of the field that supersedes this one
+func (f *Field) Replacement() string {
+	return f.replacement
+}
+
+// Enabled reports whether the legacy field is still emitted
+func (f *Field) Enabled() bool {
+	return f.enabled.Load()
+}
+
+// SetEnabled turns emission of the legacy field on or off
+func (f *Field) SetEnabled(enabled bool) {
+	f.enabled.Store(enabled)
+}
+
+// Emit reports whether the legacy field should be written in a response to the given client
+// and records the decision so removal can wait until no clients rely on the field
+func (f *Field) Emit(clientID string, optedOut bool) bool {
+	if !f.Enabled() {
+		return false
+	}
+	if optedOut {
+		stats.Add(f.name+".omitted", 1)
+		return false
+	}
+
+	stats.Add(f.name+".emitted", 1)
+	if clientID == "" {
+		return true
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, seen := f.clients[clientID]; !seen && len(f.clients) < maxTrackedClients {
+		f.clients[clientID] = struct{}{}
+		stats.Add(f.name+".clients", 1)
+	}
+	return true
+}
+
+// Include is Emit for a request: the client is the authenticated subject (or the
+// User-Agent for anonymous requests), and the opt-out comes from OptOutHeader
+func (f *Field) Include(c *gin.Context) bool {
+	clientID := c.GetString("user_id")
+	if clientID == "" {
+		clientID = c.GetHeader("User-Agent")
+	}
+	optedOut := strings.EqualFold(strings.TrimSpace(c.GetHeader(OptOutHeader)), "omit")
+	return f.Emit(clientID, optedOut)
+}
+
+// Counter returns the current value of a counter for this field ("emitted", "omitted", "clients")
+func (f *Field) Counter(name string) int64 {
+	if v, ok := stats.Get(f.name + "." + name).(*expvar.Int); ok {
+		return v.Value()
+	}
+	return 0
+}
+
+// Configure enables or disables legacy fields from a comma-separated spec such as
+// "orders.image_path=false". Unknown fields are rejected so typos don't go unnoticed.
+func Configure(spec string) error {
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, value, found := strings.Cut(entry, "=")
+		if !found {
+			return fmt.Errorf("invalid legacy field entry %q (expected field=true|false)", entry)
+		}
+
+		field, ok := Lookup(strings.TrimSpace(name))
+		if !ok {
+			return fmt.Errorf("unknown legacy field %q", name)
+		}
+
+		enabled, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("invalid legacy field setting %q for %s", value, field.Name())
+		}
+		field.SetEnabled(enabled)
+		log.Printf("Legacy field %s emission set to %t (replacement: %s)", field.Name(), enabled, field.Replacement())
+	}
+	return nil
+}
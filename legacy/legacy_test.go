@@ -0,0 +1,79 @@
+package legacy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestField_EmitCountsClients(t *testing.T) {
+	field := Register("test.emit", "test.emit_v2")
+
+	assert.True(t, field.Emit("client-a", false))
+	assert.True(t, field.Emit("client-a", false))
+	assert.True(t, field.Emit("client-b", false))
+	assert.False(t, field.Emit("client-c", true))
+
+	assert.Equal(t, int64(3), field.Counter("emitted"))
+	assert.Equal(t, int64(1), field.Counter("omitted"))
+	assert.Equal(t, int64(2), field.Counter("clients"))
+
+	// Disabled fields are never emitted and no longer counted
+	field.SetEnabled(false)
+	assert.False(t, field.Emit("client-d", false))
+	assert.Equal(t, int64(3), field.Counter("emitted"))
+	assert.Equal(t, int64(2), field.Counter("clients"))
+}
+
+func TestField_Include(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	field := Register("test.include", "test.include_v2")
+
+	newContext := func(userID, optOut string) *gin.Context {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+		c.Request.Header.Set("User-Agent", "nails-ios/1.0")
+		if optOut != "" {
+			c.Request.Header.Set(OptOutHeader, optOut)
+		}
+		if userID != "" {
+			c.Set("user_id", userID)
+		}
+		return c
+	}
+
+	assert.True(t, field.Include(newContext("auth0|user1", "")))
+	assert.True(t, field.Include(newContext("", "")))
+	assert.False(t, field.Include(newContext("auth0|user2", " OMIT ")))
+
+	// The authenticated subject and the anonymous User-Agent are separate clients
+	assert.Equal(t, int64(2), field.Counter("clients"))
+	assert.Equal(t, int64(1), field.Counter("omitted"))
+}
+
+func TestConfigure(t *testing.T) {
+	field := Register("test.configure", "test.configure_v2")
+
+	assert.NoError(t, Configure(" test.configure=false ,"))
+	assert.False(t, field.Enabled())
+
+	assert.NoError(t, Configure("test.configure=true"))
+	assert.True(t, field.Enabled())
+
+	assert.Error(t, Configure("test.missing=false"))
+	assert.Error(t, Configure("test.configure"))
+	assert.Error(t, Configure("test.configure=sometimes"))
+}
+
+func TestNames(t *testing.T) {
+	Register("test.names_b", "")
+	Register("test.names_a", "")
+
+	names := Names()
+	assert.Contains(t, names, "test.names_a")
+	assert.Contains(t, names, "test.names_b")
+	assert.IsNonDecreasing(t, names)
+}
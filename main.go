@@ -1,114 +1,688 @@
 package main
 
 import (
+	"context"
+	"expvar"
+	"flag"
+	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/apiresponse"
+	"github.com/kendall-kelly/kendalls-nails-api/apiversion"
+	"github.com/kendall-kelly/kendalls-nails-api/auth"
 	"github.com/kendall-kelly/kendalls-nails-api/config"
 	"github.com/kendall-kelly/kendalls-nails-api/controllers"
+	"github.com/kendall-kelly/kendalls-nails-api/events"
+	featureflags "github.com/kendall-kelly/kendalls-nails-api/flags"
+	"github.com/kendall-kelly/kendalls-nails-api/jobs"
 	"github.com/kendall-kelly/kendalls-nails-api/middleware"
-	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/reporting"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
 	"github.com/kendall-kelly/kendalls-nails-api/services"
+	"github.com/kendall-kelly/kendalls-nails-api/tracing"
 )
 
+// usage describes the available CLI commands
+const usage = `Usage: kendalls-nails-api <command> [arguments]
+
+Commands:
+  serve                   Run the API server (default when no command is given)
+  serve -mock             Run the API server on in-memory demo data, without Postgres, Auth0, or S3;
+                          send the Auth0 ID of a demo user as the bearer token (e.g. "Bearer seed|customer-1")
+  migrate up              Create or update database tables
+  migrate down [-force]   Drop all database tables (-force is required in production)
+  migrate backfill        Populate new columns for in-progress dual-write migrations
+  migrate normalize-emails
+                          Lowercase and trim stored emails, reporting accounts that collide
+  migrate encrypt-pii     Encrypt the names and emails of existing users with PII_ENCRYPTION_KEY
+  config check [-mock]    Validate the configuration and list every problem found, without starting the server
+  seed                    Create demo users, catalog designs, and orders for local development
+  help                    Show this help message
+`
+
 func main() {
+	if err := run(os.Args[1:]); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
+
+// run dispatches to the requested subcommand
+func run(args []string) error {
+	command := "serve"
+	if len(args) > 0 {
+		command = args[0]
+		args = args[1:]
+	}
+
+	switch command {
+	case "serve":
+		return runServe(args)
+	case "migrate":
+		return runMigrate(args)
+	case "config":
+		return runConfig(args)
+	case "seed":
+		return runSeed(args)
+	case "help", "-h", "-help", "--help":
+		fmt.Print(usage)
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q\n\n%s", command, usage)
+	}
+}
+
+// runServe migrates the database and starts the HTTP server
+func runServe(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	skipMigrate := flags.Bool("skip-migrate", false, "do not auto-migrate the database on startup")
+	mock := flags.Bool("mock", false, "serve in-memory demo data without Postgres, Auth0, or S3")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
 	// Basic logging
 	log.Println("Starting Custom Nails API server...")
 
-	// Load configuration first
-	cfg, err := config.Load()
+	var cfg *config.Config
+	var err error
+	if *mock {
+		cfg, err = startMock()
+	} else {
+		cfg, err = start(*skipMigrate)
+	}
 	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+		return err
+	}
+
+	// Requests are traced through the database, S3 and Auth0 when a collector is configured
+	if cfg.TracingEnabled() {
+		shutdownTracing, err := tracing.Setup(context.Background())
+		if err != nil {
+			return err
+		}
+		defer flushTraces(shutdownTracing)
+		log.Println("Exporting traces over OTLP")
+	}
+
+	// Server errors and panics are reported to Sentry when a DSN is configured
+	if cfg.ErrorReportingEnabled() {
+		if err := reporting.Setup(cfg.SentryDSN, cfg.GoEnv); err != nil {
+			return err
+		}
+		defer flushErrorReports()
+		log.Println("Reporting errors to Sentry")
+	}
+
+	// Mirror role changes to Auth0 when Management API credentials are configured
+	if !cfg.Mock && cfg.Auth0ManagementEnabled() {
+		services.SetRoleDirectory(services.NewAuth0ManagementClient(cfg))
+		log.Println("Auth0 role sync enabled")
+	}
+
+	// Customers' design images are screened before technicians see them when a moderation endpoint is configured
+	if !cfg.Mock && cfg.ImageModerationEnabled() {
+		services.SetImageModerator(services.NewHTTPImageModerator(cfg))
+		log.Println("Image moderation enabled")
+	}
+
+	// Messages are checked for offensive content when a word list or moderation endpoint is configured
+	if cfg.MessageFilterEnabled() {
+		var moderator services.TextModerator
+		if !cfg.Mock && cfg.MessageModerationURL != "" {
+			moderator = services.NewHTTPTextModerator(cfg)
+		}
+		services.SetMessageFilter(services.NewMessageFilter(cfg.GetMessageFilterWords(), cfg.GetMessageFilterAction(), moderator))
+		log.Println("Message content filter enabled")
+	}
+
+	// Guest quote requests must pass a CAPTCHA check when a secret is configured
+	if !cfg.Mock && cfg.CaptchaEnabled() {
+		services.SetCaptchaVerifier(services.NewHTTPCaptchaVerifier(cfg))
+		log.Println("CAPTCHA check for quote requests enabled")
 	}
 
-	// Connect to database
-	if err := config.ConnectDatabase(); err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+	// Feature flags are read from the database and reloaded every few seconds
+	featureflags.SetLoader(repositories.NewFeatureFlagRepository(config.GetDB()).List)
+
+	// Periodic background work runs alongside the server and stops with it
+	runner := jobs.NewRunner()
+
+	// Domain events reach their subscribers in process, or through a Redis stream shared by every instance
+	var transport events.Transport
+	if cfg.GetEventBus() == config.EventBusRedis && !cfg.Mock {
+		redisTransport, err := events.NewRedisTransport(cfg.RedisURL)
+		if err != nil {
+			log.Printf("Handling domain events in process: %v", err)
+		} else {
+			transport = redisTransport
+			log.Println("Domain events are shared through Redis")
+		}
+	}
+	bus := events.NewBus(transport)
+	if transport != nil {
+		runner.Add(services.EventConsumerJob(bus))
+	}
+	services.SubscribeMetrics(bus)
+
+	// Order events are queued for registered webhooks and sent in the background with retries
+	webhooks := services.NewWebhookService(repositories.NewWebhookRepository(config.GetDB()))
+	services.SubscribeWebhooks(bus, webhooks)
+
+	// New messages and status changes are queued for the users involved and served by long polling
+	notifications := services.NewNotificationService(repositories.NewNotificationRepository(config.GetDB()), repositories.NewOrderRepository(config.GetDB()))
+	services.SubscribeNotifications(bus, notifications)
+	// One conversation service for the process so typing reports are throttled across requests
+	services.SetConversationService(services.NewConversationService(repositories.NewOrderRepository(config.GetDB()), repositories.NewConversationRepository(config.GetDB()), notifications))
+	runner.Add(services.NotificationPruneJob(notifications))
+	services.SetEventBus(bus)
+
+	// Order events are saved in the same transaction as the order and relayed to the bus from the outbox
+	outbox := services.NewOutboxService(repositories.NewOutboxRepository(config.GetDB()), bus)
+	runner.Add(services.OutboxRelayJob(outbox))
+	runner.Add(services.OutboxPruneJob(outbox))
+
+	// One metrics service for the process so scrapes share its cached snapshot
+	services.SetMetricsService(services.NewMetricsService(repositories.NewMetricsRepository(config.GetDB())))
+	runner.Add(services.WebhookDeliveryJob(webhooks))
+
+	// Backfills started by admins are worked through in chunks, resuming after restarts
+	runner.Add(services.BackfillJob(services.GetBackfillService()))
+
+	// Design recommendations are precomputed from delivered orders
+	runner.Add(services.RecommendationJob(services.GetRecommendationService()))
+
+	// Orders nobody reviewed in time expire and their customers are told
+	if maxAge := cfg.GetStaleOrderExpiry(); maxAge > 0 {
+		runner.Add(services.StaleOrderExpiryJob(services.GetOrderService(), maxAge))
+	}
+
+	// Uploads nothing refers to are deleted once they are old enough that no order creation can still claim them
+	if maxAge := cfg.GetOrphanImageRetention(); !cfg.Mock && maxAge > 0 {
+		runner.Add(services.OrphanImageCleanupJob(services.GetStorageCleanupService(), maxAge))
+	}
+
+	// Chunks of uploads that were never finished are deleted once the upload expires
+	runner.Add(services.ExpiredUploadCleanupJob(services.GetChunkedUploadService()))
+
+	// Order images are staged on disk and moved to S3 in the background, with retries, when a staging directory is set
+	if !cfg.Mock && cfg.BackgroundUploadsEnabled() {
+		transfers, err := services.NewImageTransferService(repositories.NewImageTransferRepository(config.GetDB()), services.GetStorage(), cfg.UploadStagingDir)
+		if err != nil {
+			return err
+		}
+		services.SetImageTransferService(transfers)
+		runner.Add(services.ImageTransferJob(transfers))
+		log.Printf("Order images are staged in %s and moved to S3 in the background", cfg.UploadStagingDir)
+	}
+
+	server := &http.Server{
+		Addr:    ":" + cfg.Port,
+		Handler: newRouter(cfg, runner),
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	runner.Start()
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Printf("Server is running on http://localhost%s (env: %s)", server.Addr, cfg.GoEnv)
+		serverErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		shutdownJobs(runner)
+		return fmt.Errorf("failed to start server: %w", err)
+	case <-ctx.Done():
+	}
+
+	// Stop accepting requests and let in-flight requests and job runs finish
+	log.Println("Shutting down...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Server did not shut down cleanly: %v", err)
+	}
+	shutdownJobs(runner)
+	log.Println("Server stopped")
+	return nil
+}
+
+// start connects to the database, migrates it unless skipMigrate is set, and sets up file storage
+func start(skipMigrate bool) (*config.Config, error) {
+	// Load configuration and connect to database
+	cfg, err := loadAndConnect()
+	if err != nil {
+		return nil, err
 	}
 
 	// Auto-migrate database models
-	db := config.GetDB()
-	if err := db.AutoMigrate(&models.User{}, &models.Order{}, &models.Message{}); err != nil {
-		log.Fatalf("Failed to migrate database: %v", err)
+	if !skipMigrate {
+		if err := migrateUp(config.GetDB()); err != nil {
+			return nil, err
+		}
+		log.Println("Database migration completed successfully")
 	}
-	log.Println("Database migration completed successfully")
 
-	// Initialize S3 service (required for file uploads)
-	s3Service, err := services.InitS3Service()
+	// Initialize file storage (required for file uploads)
+	storage, err := services.InitStorage(cfg)
 	if err != nil {
-		log.Fatalf("Failed to initialize S3 service: %v", err)
+		return nil, fmt.Errorf("failed to initialize %s storage: %w", cfg.GetStorageBackend(), err)
 	}
-	log.Println("S3 service initialized successfully")
+	log.Printf("File storage initialized successfully (backend: %s)", cfg.GetStorageBackend())
 
-	// Initialize Image service (wraps S3 with image-specific logic)
-	services.InitImageService(s3Service)
+	// Initialize Image service (wraps storage with image-specific logic)
+	services.InitImageService(storage)
 	log.Println("Image service initialized successfully")
 
+	return cfg, nil
+}
+
+// startMock sets up mock mode: demo data in an in-memory database and uploads kept in memory
+func startMock() (*config.Config, error) {
+	cfg, err := loadMock()
+	if err != nil {
+		return nil, err
+	}
+
+	storage := services.NewMockStorage()
+	services.SetStorage(storage)
+	services.InitImageService(storage)
+
+	log.Println("Mock mode: serving in-memory demo data; use a demo user's Auth0 ID (e.g. seed|customer-1) as the bearer token")
+	return cfg, nil
+}
+
+// shutdownTimeout bounds how long shutdown waits for in-flight requests and then for job runs
+const shutdownTimeout = 15 * time.Second
+
+// shutdownJobs stops the background jobs, waiting up to shutdownTimeout for runs in progress
+func shutdownJobs(runner *jobs.Runner) {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := runner.Stop(ctx); err != nil {
+		log.Printf("Background jobs did not stop in time: %v", err)
+	}
+}
+
+// flushTraces exports the spans still buffered, waiting up to shutdownTimeout
+func flushTraces(shutdown func(context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := shutdown(ctx); err != nil {
+		log.Printf("Traces were not flushed: %v", err)
+	}
+}
+
+// flushErrorReports sends the error reports still buffered, waiting up to shutdownTimeout
+func flushErrorReports() {
+	if !reporting.Flush(shutdownTimeout) {
+		log.Println("Error reports were not all sent")
+	}
+}
+
+// newRouter creates the Gin router with middleware and all API routes
+// The runner's jobs are reported by the detailed health check
+func newRouter(cfg *config.Config, runner *jobs.Runner) *gin.Engine {
 	// Initialize Gin router
 	router := gin.Default()
 
+	// Only the configured proxies may report the client IP in X-Forwarded-For; by default nobody can,
+	// so per-IP rate limits and the IPs recorded with CAPTCHA checks and consents cannot be forged
+	if err := router.SetTrustedProxies(cfg.GetTrustedProxies()); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+
+	// Assign request IDs first so every response and error envelope carries one
+	router.Use(middleware.RequestID())
+	router.Use(middleware.Trace())
+	router.Use(middleware.ReportErrors())
+	router.Use(middleware.LabelQueries())
+	router.Use(middleware.ErrorHandler())
+
 	// Configure CORS middleware
 	// Allows Single Page Apps to make API calls from different origins
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     cfg.GetCORSOrigins(),
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Request-ID", "X-Legacy-Fields", "If-None-Match", "If-Match", controllers.IntakeTokenHeader, middleware.CSRFHeader, middleware.ImpersonateHeader},
+		ExposeHeaders:    []string{"Content-Length", "X-Request-ID", "ETag", middleware.ImpersonateHeader},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
+		// Order widgets run on technicians' own sites; intake tokens, not origins, authorize them
+		AllowOriginWithContextFunc: func(c *gin.Context, origin string) bool {
+			path := c.Request.URL.Path
+			return path == "/api/v1/intake/drafts" || path == "/api/v2/intake/drafts"
+		},
 	}))
 	log.Printf("CORS configured for origins: %v", cfg.GetCORSOrigins())
 
-	// API v1 routes
-	v1 := router.Group("/api/v1")
-	{
-		// Health check endpoint
-		v1.GET("/health", healthCheck)
+	// Bound request bodies before any handler reads them; file uploads have their own limit
+	router.Use(middleware.LimitJSONBody(cfg.GetMaxJSONBodyBytes()))
+
+	// Business metrics (orders by status, revenue today, active technicians, webhook backlog) for Prometheus,
+	// and with DEBUG_VARS runtime counters (including dual-write mismatch counts) in expvar format,
+	// only to scrapers presenting METRICS_TOKEN
+	if cfg.MetricsEnabled() {
+		requireMetricsToken := middleware.RequireBearerToken(cfg.MetricsToken)
+		router.GET("/metrics", requireMetricsToken, controllers.Metrics)
+		if cfg.DebugVarsEnabled() {
+			router.GET("/debug/vars", requireMetricsToken, gin.WrapH(expvar.Handler()))
+		}
+	}
+
+	// Signed links to uploads served through the API (IMAGE_PROXY, and always for local storage);
+	// otherwise clients fetch images from S3 or CloudFront directly
+	if cfg.ImageProxyEnabled() && !cfg.Mock {
+		router.GET(services.FileProxyRoute+"/*key", controllers.ServeFile)
+	}
+
+	// Health check endpoint; with HEALTH_DETAILS, ?detail=true reports each of these dependencies
+	healthChecks := []services.HealthCheck{
+		services.DatabaseHealthCheck(config.GetDB()),
+		services.StorageHealthCheck(services.GetStorage()),
+		{Name: "jobs", Check: runner.Check},
+	}
+	health := healthCheck
+	if cfg.HealthDetailsEnabled() {
+		health = detailedHealthCheck
+	}
+
+	// Routes below require a valid JWT; the caller's profile is resolved once per request
+	var requireToken gin.HandlerFunc
+	if cfg.Mock {
+		requireToken = middleware.MockToken()
+	} else {
+		provider, err := auth.NewProvider(cfg)
+		if err != nil {
+			log.Fatalf("Failed to set up the auth provider: %v", err)
+		}
+		requireToken = middleware.EnsureValidTokenWithProvider(provider)
+		if keys, ok := provider.(auth.KeyChecker); ok {
+			healthChecks = append(healthChecks, services.HealthCheck{Name: "jwks", Check: keys.CheckKeys})
+		}
+	}
+	services.SetHealthService(services.NewHealthService(healthChecks...))
+	// Browsers may hold their token in an httpOnly session cookie instead; see POST /auth/session
+	if cfg.CookieAuthEnabled() {
+		requireToken = middleware.EnsureValidTokenOrSession(requireToken)
+	}
+
+	routes := apiRoutes{
+		health:       health,
+		requireToken: requireToken,
+		quoteLimit:   middleware.RateLimit(cfg.GetQuoteRateLimit(), time.Hour),
+	}
+
+	// API v1 routes; once API_V1_DEPRECATED_AT and API_V1_SUNSET are set, responses announce its retirement
+	registerRoutes(router.Group("/api/v1", apiversion.Use(apiversion.V1), apiversion.Deprecate(apiversion.Deprecation{
+		DeprecatedAt: cfg.GetAPIV1DeprecatedAt(),
+		Sunset:       cfg.GetAPIV1Sunset(),
+		Prefix:       "/api/v1",
+		Successor:    "/api/v2",
+	})), cfg, routes)
+
+	// API v2 routes: the same controllers, with responses rendered by the serializers registered for v2
+	registerRoutes(router.Group("/api/v2", apiversion.Use(apiversion.V2)), cfg, routes)
+
+	return router
+}
+
+// apiRoutes holds the handlers every API version shares, so that their state (such as rate limit counts) is shared too
+type apiRoutes struct {
+	health       gin.HandlerFunc
+	requireToken gin.HandlerFunc
+	quoteLimit   gin.HandlerFunc
+}
 
-		// Database status endpoint
-		v1.GET("/database/status", databaseStatus)
+// registerRoutes registers the API routes on the route group of one version
+func registerRoutes(api *gin.RouterGroup, cfg *config.Config, routes apiRoutes) {
+	api.GET("/health", routes.health)
 
-		// Protected endpoint - requires valid JWT token
-		v1.GET("/protected", middleware.EnsureValidToken(cfg), protectedEndpoint)
+	// Database status endpoint
+	api.GET("/database/status", databaseStatus)
 
-		// User management routes
-		v1.POST("/users", middleware.EnsureValidToken(cfg), controllers.CreateUser)
-		v1.GET("/users/me", middleware.EnsureValidToken(cfg), controllers.GetMyProfile)
-		v1.PUT("/users/me", middleware.EnsureValidToken(cfg), controllers.UpdateMyProfile)
+	// Public catalog of pre-designed nail sets
+	api.GET("/catalog", controllers.ListCatalog)
+	api.GET("/catalog/:id", controllers.GetCatalogDesign)
 
-		// Order management routes
-		v1.POST("/orders", middleware.EnsureValidToken(cfg), controllers.CreateOrder)
-		v1.GET("/orders", middleware.EnsureValidToken(cfg), controllers.ListOrders)
-		v1.GET("/orders/:id", middleware.EnsureValidToken(cfg), controllers.GetOrder)
-		v1.POST("/orders/:id/reorder", middleware.EnsureValidToken(cfg), controllers.ReorderOrder)
-		v1.PUT("/orders/:id/assign", middleware.EnsureValidToken(cfg), controllers.AssignOrder)
-		v1.PUT("/orders/:id/review", middleware.EnsureValidToken(cfg), controllers.ReviewOrder)
-		v1.PUT("/orders/:id/status", middleware.EnsureValidToken(cfg), controllers.UpdateOrderStatus)
+	// Current terms of service version, shown before visitors sign up
+	api.GET("/terms", controllers.GetTerms)
 
-		// Message routes
-		v1.POST("/orders/:id/messages", middleware.EnsureValidToken(cfg), controllers.SendMessage)
-		v1.GET("/orders/:id/messages", middleware.EnsureValidToken(cfg), controllers.ListMessages)
+	// Signing out of a cookie session needs no valid token
+	if cfg.CookieAuthEnabled() {
+		api.DELETE("/auth/session", controllers.DeleteSession)
 	}
 
-	// Start server
-	port := ":" + cfg.Port
-	log.Printf("Server is running on http://localhost%s (env: %s)", port, cfg.GoEnv)
-	if err := router.Run(port); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	// Scoped tokens (machine clients, and users granted Auth0 permissions) only reach the routes their
+	// permissions claim grants: read:orders or write:orders for order routes, admin:all for the rest
+	scopes := middleware.EnforceScopes(auth.ScopeAdminAll,
+		middleware.ScopePolicy{Prefix: api.BasePath() + "/orders", Read: auth.ScopeReadOrders, Write: auth.ScopeWriteOrders},
+		middleware.ScopePolicy{Prefix: api.BasePath() + "/handoffs", Read: auth.ScopeReadOrders, Write: auth.ScopeWriteOrders},
+	)
+
+	// Routes limited to some roles require a permission from the services permission matrix,
+	// or a role for the admin routes; handlers and services still check ownership
+	protected := api.Group("", routes.requireToken, scopes, middleware.CurrentUser())
+
+	// Read-only routes that integrations may also call with an X-API-Key header
+	readable := api.Group("", middleware.EnsureValidTokenOrAPIKey(routes.requireToken), scopes, middleware.CurrentUser())
+
+	// Protected endpoint - requires valid JWT token
+	protected.GET("/protected", protectedEndpoint)
+	if cfg.CookieAuthEnabled() {
+		protected.POST("/auth/session", controllers.CreateSession)
 	}
+
+	// User management routes
+	protected.POST("/users", controllers.CreateUser)
+	protected.GET("/users/me", controllers.GetMyProfile)
+	protected.PUT("/users/me", controllers.UpdateMyProfile)
+	protected.GET("/users/me/consents", controllers.GetMyConsents)
+	protected.POST("/users/me/consents", controllers.AcceptTerms)
+	protected.GET("/users/me/role-requests", controllers.ListMyRoleRequests)
+	protected.POST("/users/me/role-requests", middleware.RequirePermission(services.PermRolesRequest), controllers.CreateMyRoleRequest)
+	protected.GET("/users/me/addresses", controllers.ListMyAddresses)
+	protected.POST("/users/me/addresses", controllers.CreateMyAddress)
+	protected.PUT("/users/me/addresses/:id", controllers.UpdateMyAddress)
+	protected.DELETE("/users/me/addresses/:id", controllers.DeleteMyAddress)
+	protected.GET("/users/me/canned-replies", middleware.RequireRole(services.RoleTechnician), controllers.ListMyCannedReplies)
+	protected.POST("/users/me/canned-replies", middleware.RequireRole(services.RoleTechnician), controllers.CreateMyCannedReply)
+	protected.PUT("/users/me/canned-replies/:id", middleware.RequireRole(services.RoleTechnician), controllers.UpdateMyCannedReply)
+	protected.DELETE("/users/me/canned-replies/:id", middleware.RequireRole(services.RoleTechnician), controllers.DeleteMyCannedReply)
+	protected.GET("/users/me/availability", middleware.RequirePermission(services.PermStudioManage), controllers.GetMyAvailability)
+	protected.PUT("/users/me/availability", middleware.RequirePermission(services.PermStudioManage), controllers.UpdateMyAvailability)
+	protected.GET("/users/me/earnings", middleware.RequirePermission(services.PermEarningsRead), controllers.GetMyEarnings)
+	protected.GET("/users/me/payouts", middleware.RequirePermission(services.PermEarningsRead), controllers.ListMyPayouts)
+	protected.GET("/users/me/views", controllers.ListMyViews)
+	protected.POST("/users/me/views", controllers.CreateMyView)
+	protected.PUT("/users/me/views/:id", controllers.UpdateMyView)
+	protected.DELETE("/users/me/views/:id", controllers.DeleteMyView)
+	protected.GET("/technicians", controllers.ListAvailableTechnicians)
+	protected.POST("/users/me/calendar-token", middleware.RequirePermission(services.PermStudioManage), controllers.IssueCalendarToken)
+	protected.DELETE("/users/me/calendar-token", middleware.RequirePermission(services.PermStudioManage), controllers.RevokeCalendarToken)
+	// Calendar apps cannot send a JWT; the feed is authenticated by the token in its URL
+	api.GET("/users/me/calendar.ics", controllers.GetCalendarFeed)
+
+	// Saved design routes
+	protected.GET("/designs", middleware.RequirePermission(services.PermDesignsSave), controllers.ListDesigns)
+	protected.POST("/designs", middleware.RequirePermission(services.PermDesignsSave), controllers.CreateDesign)
+	protected.DELETE("/designs/:id", middleware.RequirePermission(services.PermDesignsSave), controllers.DeleteDesign)
+
+	// Resumable design image uploads, attached to orders and saved designs by upload_id
+	protected.POST("/uploads/chunks", middleware.RequirePermission(services.PermDesignsSave), controllers.StartChunkedUpload)
+	protected.GET("/uploads/chunks/:id", middleware.RequirePermission(services.PermDesignsSave), controllers.GetChunkedUpload)
+	protected.PUT("/uploads/chunks/:id", middleware.RequirePermission(services.PermDesignsSave), controllers.WriteUploadChunk)
+	protected.DELETE("/uploads/chunks/:id", middleware.RequirePermission(services.PermDesignsSave), controllers.CancelChunkedUpload)
+
+	// Fitting appointment routes
+	protected.POST("/appointment-slots", middleware.RequirePermission(services.PermStudioManage), controllers.CreateAppointmentSlot)
+	protected.GET("/appointment-slots", controllers.ListAppointmentSlots)
+	protected.DELETE("/appointment-slots/:id", middleware.RequirePermission(services.PermStudioManage), controllers.DeleteAppointmentSlot)
+	protected.POST("/orders/:id/appointments", middleware.RequirePermission(services.PermAppointmentsBook), controllers.BookAppointment)
+	protected.GET("/orders/:id/appointments", controllers.ListOrderAppointments)
+	protected.GET("/appointments", controllers.ListAppointments)
+	protected.DELETE("/appointments/:id", controllers.CancelAppointment)
+
+	// Order management routes; multi-step writes run in one request transaction
+	protected.POST("/orders", middleware.RequirePermission(services.PermOrdersCreate), middleware.Transactional(), controllers.CreateOrder)
+	protected.POST("/orders/batch", middleware.RequirePermission(services.PermOrdersCreate), middleware.Transactional(), controllers.CreateOrderBatch)
+	protected.POST("/gifts/claim", middleware.RequirePermission(services.PermOrdersCreate), controllers.ClaimGift)
+	readable.GET("/orders", controllers.ListOrders)
+	protected.GET("/orders/overdue", middleware.RequirePermission(services.PermOrdersReview), controllers.ListOverdueOrders)
+	readable.GET("/orders/:id", controllers.GetOrder)
+	readable.GET("/orders/:id/lineage", controllers.GetOrderLineage)
+	protected.PUT("/orders/status/bulk", middleware.RequirePermission(services.PermOrdersFulfil), controllers.BulkUpdateOrderStatus)
+	protected.POST("/orders/:id/reorder", middleware.RequirePermission(services.PermOrdersCreate), middleware.Transactional(), controllers.ReorderOrder)
+	protected.POST("/orders/:id/duplicate", middleware.RequirePermission(services.PermOrdersCreate), middleware.Transactional(), controllers.DuplicateOrder)
+	protected.PUT("/orders/:id/shipping-address", middleware.RequirePermission(services.PermOrdersCreate), controllers.SetShippingAddress)
+	protected.PUT("/orders/:id/assign", middleware.RequirePermission(services.PermOrdersReview), controllers.AssignOrder)
+	protected.PUT("/orders/:id/review", middleware.RequirePermission(services.PermOrdersReview), middleware.Transactional(), controllers.ReviewOrder)
+	protected.PUT("/orders/:id/status", middleware.RequirePermission(services.PermOrdersFulfil), middleware.Transactional(), controllers.UpdateOrderStatus)
+	protected.PUT("/orders/:id/checklist/:itemId", middleware.RequirePermission(services.PermOrdersFulfil), controllers.UpdateChecklistItem)
+	protected.POST("/orders/:id/handoff", middleware.RequirePermission(services.PermOrdersReview), controllers.RequestHandoff)
+	protected.PUT("/orders/:id/handoff/:handoffId", middleware.RequirePermission(services.PermOrdersReview), controllers.RespondToHandoff)
+	protected.GET("/orders/:id/handoffs", controllers.ListHandoffs)
+	protected.POST("/orders/:id/updates", middleware.RequirePermission(services.PermOrdersFulfil), controllers.PostProgressUpdate)
+	protected.GET("/orders/:id/updates", controllers.ListProgressUpdates)
+	protected.DELETE("/orders/:id/updates/:updateId", middleware.RequirePermission(services.PermOrdersFulfil), controllers.DeleteProgressUpdate)
+	readable.GET("/orders/:id/share-card.png", controllers.GetShareCard)
+	protected.GET("/orders/:id/invoice", controllers.GetInvoice)
+	protected.GET("/handoffs/incoming", middleware.RequirePermission(services.PermOrdersReview), controllers.ListIncomingHandoffs)
+
+	// Production checklist template routes
+	protected.GET("/checklist/template", middleware.RequirePermission(services.PermStudioManage), controllers.GetChecklistTemplate)
+	protected.PUT("/checklist/template", middleware.RequirePermission(services.PermStudioManage), controllers.UpdateChecklistTemplate)
+
+	// Add-on catalog routes
+	protected.GET("/addons", controllers.ListAddOns)
+	protected.POST("/addons", middleware.RequirePermission(services.PermStudioManage), controllers.CreateAddOn)
+	protected.PUT("/addons/:id", middleware.RequirePermission(services.PermStudioManage), controllers.UpdateAddOn)
+	protected.DELETE("/addons/:id", middleware.RequirePermission(services.PermStudioManage), controllers.DeleteAddOn)
+
+	// Supplies inventory routes
+	protected.GET("/supplies", middleware.RequirePermission(services.PermSuppliesManage), controllers.ListSupplies)
+	protected.GET("/supplies/low", middleware.RequirePermission(services.PermSuppliesManage), controllers.ListLowSupplies)
+	protected.POST("/supplies", middleware.RequirePermission(services.PermSuppliesManage), controllers.CreateSupply)
+	protected.PUT("/supplies/:id", middleware.RequirePermission(services.PermSuppliesManage), controllers.UpdateSupply)
+	protected.DELETE("/supplies/:id", middleware.RequirePermission(services.PermSuppliesManage), controllers.DeleteSupply)
+	protected.POST("/orders/:id/supplies", middleware.RequirePermission(services.PermOrdersFulfil), controllers.RecordSupplyUsage)
+	protected.GET("/orders/:id/supplies", controllers.ListSupplyUsage)
+
+	// Seasonal price list routes
+	protected.POST("/price-lists", middleware.RequirePermission(services.PermStudioManage), controllers.CreatePriceList)
+	protected.GET("/price-lists", middleware.RequirePermission(services.PermStudioManage), controllers.ListPriceLists)
+	protected.POST("/price-lists/:id/end", middleware.RequirePermission(services.PermStudioManage), controllers.EndPriceList)
+	protected.DELETE("/price-lists/:id", middleware.RequirePermission(services.PermStudioManage), controllers.DeletePriceList)
+
+	// Design recommendation routes
+	protected.GET("/recommendations", middleware.RequirePermission(services.PermDesignsSave), controllers.ListRecommendations)
+
+	// Analytics routes
+	protected.GET("/analytics/summary", middleware.RequirePermission(services.PermAnalyticsRead), controllers.GetAnalyticsSummary)
+	protected.POST("/events", controllers.TrackEvents)
+
+	// Intake widget routes; drafts are authenticated by an intake token instead of a user JWT
+	protected.POST("/intake-tokens", middleware.RequirePermission(services.PermStudioManage), controllers.CreateIntakeToken)
+	protected.GET("/intake-tokens", middleware.RequirePermission(services.PermStudioManage), controllers.ListIntakeTokens)
+	protected.DELETE("/intake-tokens/:id", middleware.RequirePermission(services.PermStudioManage), controllers.RevokeIntakeToken)
+	api.POST("/intake/drafts", controllers.SubmitIntakeDraft)
+	protected.POST("/intake/claim", middleware.RequirePermission(services.PermOrdersCreate), middleware.Transactional(), controllers.ClaimIntakeDraft)
+
+	// Quote requests from visitors without an account, behind a per-IP rate limit and a CAPTCHA
+	api.POST("/quotes", routes.quoteLimit, controllers.SubmitQuote)
+	protected.GET("/quotes", middleware.RequirePermission(services.PermQuotesRead), controllers.ListQuotes)
+	protected.PUT("/quotes/:id/review", middleware.RequirePermission(services.PermQuotesReview), controllers.ReviewQuote)
+	protected.POST("/quotes/:id/convert", middleware.RequirePermission(services.PermQuotesReview), middleware.Transactional(), controllers.ConvertQuote)
+
+	// Admin routes
+	admin := protected.Group("/admin", middleware.RequireRole(services.RoleAdmin))
+	admin.PUT("/users/:id/role", controllers.ChangeUserRole)
+	admin.PUT("/users/:id/ban", controllers.BanUser)
+	admin.DELETE("/users/:id/ban", controllers.UnbanUser)
+	admin.GET("/role-requests", controllers.ListRoleRequests)
+	admin.PUT("/role-requests/:id/review", controllers.ReviewRoleRequest)
+	admin.POST("/api-keys", controllers.CreateAPIKey)
+	admin.GET("/api-keys", controllers.ListAPIKeys)
+	admin.DELETE("/api-keys/:id", controllers.RevokeAPIKey)
+	admin.POST("/webhooks", controllers.CreateWebhook)
+	admin.GET("/webhooks", controllers.ListWebhooks)
+	admin.DELETE("/webhooks/:id", controllers.DeleteWebhook)
+	admin.GET("/webhooks/:id/deliveries", controllers.ListWebhookDeliveries)
+	admin.GET("/reports/sla", controllers.GetSLAReport)
+	admin.POST("/payouts", controllers.PayEarnings)
+	admin.GET("/payouts/balances", controllers.ListUnpaidBalances)
+	admin.GET("/audit-logs", controllers.ListAuditLogs)
+	admin.GET("/feature-flags", controllers.ListFeatureFlags)
+	admin.POST("/feature-flags", controllers.CreateFeatureFlag)
+	admin.PUT("/feature-flags/:name", controllers.UpdateFeatureFlag)
+	admin.DELETE("/feature-flags/:name", controllers.DeleteFeatureFlag)
+	admin.GET("/image-moderations", controllers.ListImageModerations)
+	admin.PUT("/image-moderations/:id/review", controllers.ReviewImageModeration)
+	admin.GET("/message-violations", controllers.ListMessageViolations)
+	admin.PUT("/message-violations/:id/review", controllers.ReviewMessageViolation)
+	admin.GET("/backfills", controllers.ListBackfills)
+	admin.GET("/backfills/:name", controllers.GetBackfill)
+	admin.POST("/backfills/:name/run", controllers.RunBackfill)
+	admin.POST("/catalog", controllers.CreateCatalogDesign)
+	admin.PUT("/catalog/:id", controllers.UpdateCatalogDesign)
+	admin.DELETE("/catalog/:id", controllers.DeleteCatalogDesign)
+	admin.POST("/catalog/:id/images", controllers.AddCatalogDesignImage)
+	admin.DELETE("/catalog/:id/images/:imageId", controllers.DeleteCatalogDesignImage)
+
+	// Message routes
+	protected.POST("/orders/:id/messages", middleware.RequireRole(services.RoleCustomer, services.RoleTechnician), controllers.SendMessage)
+	protected.GET("/orders/:id/messages", middleware.RequireRole(services.RoleCustomer, services.RoleTechnician), controllers.ListMessages)
+	protected.PUT("/orders/:id/messages/seen", middleware.RequireRole(services.RoleCustomer, services.RoleTechnician), controllers.MarkMessagesSeen)
+	protected.POST("/orders/:id/typing", middleware.RequireRole(services.RoleCustomer, services.RoleTechnician), controllers.SendTypingIndicator)
+	protected.POST("/orders/:id/images/:imageId/annotations", middleware.RequireRole(services.RoleTechnician), controllers.AnnotateImage)
+
+	// Notification routes
+	protected.GET("/notifications/poll", controllers.PollNotifications)
+	protected.GET("/users/me/notification-preferences", controllers.GetNotificationPreferences)
+	protected.PUT("/users/me/notification-preferences", controllers.UpdateNotificationPreferences)
 }
 
 // healthCheck handles the health check endpoint
 func healthCheck(c *gin.Context) {
-	c.PureJSON(http.StatusOK, gin.H{
-		"success": true,
-		"message": "Custom Nails API is running",
+	apiresponse.Message(c, http.StatusOK, "Custom Nails API is running")
+}
+
+// detailedHealthCheck reports each dependency's status and response time for ?detail=true (HEALTH_DETAILS)
+// It answers 503 when any dependency is down; without the parameter it is the plain health check
+func detailedHealthCheck(c *gin.Context) {
+	if c.Query("detail") != "true" {
+		healthCheck(c)
+		return
+	}
+
+	report := services.GetHealthService().Report(c.Request.Context())
+	status := http.StatusOK
+	if report.Status != services.HealthUp {
+		status = http.StatusServiceUnavailable
+	}
+	c.PureJSON(status, apiresponse.SuccessResponse[services.HealthReport]{
+		Success: report.Status == services.HealthUp,
+		Data:    report,
 	})
 }
 
+// databaseStatusResponse lists the tables of a reachable database
+type databaseStatusResponse struct {
+	Success bool     `json:"success"`
+	Message string   `json:"message"`
+	Tables  []string `json:"tables"`
+}
+
 // databaseStatus checks database connectivity and returns table information
 func databaseStatus(c *gin.Context) {
 	db := config.GetDB()
@@ -116,46 +690,35 @@ func databaseStatus(c *gin.Context) {
 	// Get the underlying SQL database to check connection
 	sqlDB, err := db.DB()
 	if err != nil {
-		c.PureJSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "DATABASE_ERROR",
-				"message": "Failed to get database instance",
-			},
-		})
+		apierror.Respond(c, apierror.Internal("DATABASE_ERROR", "Failed to get database instance").Wrap(err))
 		return
 	}
 
 	// Ping the database to verify connection
 	if err := sqlDB.Ping(); err != nil {
-		c.PureJSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "DATABASE_CONNECTION_ERROR",
-				"message": "Database connection failed",
-			},
-		})
+		apierror.Respond(c, apierror.Internal("DATABASE_CONNECTION_ERROR", "Database connection failed").Wrap(err))
 		return
 	}
 
-	// Get list of tables
+	// Get list of tables; mock mode runs on SQLite
+	query := "SELECT tablename FROM pg_tables WHERE schemaname = 'public'"
+	if db.Name() == "sqlite" {
+		query = "SELECT name FROM sqlite_master WHERE type = 'table'"
+	}
 	var tables []string
-	if err := db.Raw("SELECT tablename FROM pg_tables WHERE schemaname = 'public'").Scan(&tables).Error; err != nil {
-		c.PureJSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "DATABASE_QUERY_ERROR",
-				"message": "Failed to query tables",
-			},
-		})
+	if err := db.Raw(query).Scan(&tables).Error; err != nil {
+		apierror.Respond(c, apierror.Internal("DATABASE_QUERY_ERROR", "Failed to query tables").Wrap(err))
 		return
 	}
 
-	c.PureJSON(http.StatusOK, gin.H{
-		"success": true,
-		"message": "Database connected",
-		"tables":  tables,
-	})
+	c.PureJSON(http.StatusOK, databaseStatusResponse{Success: true, Message: "Database connected", Tables: tables})
+}
+
+// protectedEndpointResponse is the data of the protected endpoint: who the token was issued to, and by whom
+type protectedEndpointResponse struct {
+	UserID  string `json:"user_id"`
+	Issuer  string `json:"issuer"`
+	Subject string `json:"subject"`
 }
 
 // protectedEndpoint is an endpoint that requires valid JWT authentication
@@ -163,37 +726,25 @@ func protectedEndpoint(c *gin.Context) {
 	// Extract user ID from the authenticated token
 	userID, err := middleware.GetUserID(c)
 	if err != nil {
-		c.PureJSON(http.StatusUnauthorized, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "UNAUTHORIZED",
-				"message": "Could not extract user information",
-			},
-		})
+		apierror.Respond(c, apierror.Unauthorized("UNAUTHORIZED", "Could not extract user information"))
 		return
 	}
 
 	// Get the validated claims
 	claims, err := middleware.GetClaims(c)
 	if err != nil {
-		c.PureJSON(http.StatusUnauthorized, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "UNAUTHORIZED",
-				"message": "Could not retrieve claims",
-			},
-		})
+		apierror.Respond(c, apierror.Unauthorized("UNAUTHORIZED", "Could not retrieve claims"))
 		return
 	}
 
 	// Return success with user information
-	c.PureJSON(http.StatusOK, gin.H{
-		"success": true,
-		"message": "You have accessed a protected endpoint",
-		"data": gin.H{
-			"user_id": userID,
-			"issuer":  claims.RegisteredClaims.Issuer,
-			"subject": claims.RegisteredClaims.Subject,
+	c.PureJSON(http.StatusOK, apiresponse.SuccessResponse[protectedEndpointResponse]{
+		Success: true,
+		Message: "You have accessed a protected endpoint",
+		Data: protectedEndpointResponse{
+			UserID:  userID,
+			Issuer:  claims.RegisteredClaims.Issuer,
+			Subject: claims.RegisteredClaims.Subject,
 		},
 	})
 }
@@ -0,0 +1,173 @@
+// Package metrics writes metrics in the Prometheus text exposition format
+// It covers only what /metrics needs: gauges and counters with at most one label,
+// whose values are limited to a fixed set so that a series can never be created per order, user, or URL
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Metric types
+const (
+	TypeGauge   = "gauge"
+	TypeCounter = "counter"
+)
+
+// OtherLabelValue replaces label values outside a counter's allowed set
+const OtherLabelValue = "other"
+
+// Sample is one value of a metric family, optionally labelled
+type Sample struct {
+	LabelValue string // empty for a family without a label
+	Value      float64
+}
+
+// Family is a named metric and its samples
+type Family struct {
+	Name    string
+	Help    string
+	Type    string
+	Label   string // name of the single label, empty when the family has none
+	Samples []Sample
+}
+
+// Gauge returns an unlabelled gauge family
+func Gauge(name, help string, value float64) Family {
+	return Family{Name: name, Help: help, Type: TypeGauge, Samples: []Sample{{Value: value}}}
+}
+
+// LabelledGauge returns a gauge family with one sample per label value
+// Every allowed value gets a sample, zero when values lacks it, so that series do not disappear
+func LabelledGauge(name, help, label string, allowed []string, values map[string]float64) Family {
+	family := Family{Name: name, Help: help, Type: TypeGauge, Label: label}
+	for _, value := range allowed {
+		family.Samples = append(family.Samples, Sample{LabelValue: value, Value: values[value]})
+	}
+	return family
+}
+
+// Write writes the families in the text exposition format
+func Write(w io.Writer, families []Family) error {
+	var b strings.Builder
+	for _, family := range families {
+		fmt.Fprintf(&b, "# HELP %s %s\n", family.Name, escapeHelp(family.Help))
+		fmt.Fprintf(&b, "# TYPE %s %s\n", family.Name, family.Type)
+		for _, sample := range family.Samples {
+			b.WriteString(family.Name)
+			if family.Label != "" {
+				fmt.Fprintf(&b, "{%s=\"%s\"}", family.Label, escapeLabelValue(sample.LabelValue))
+			}
+			b.WriteByte(' ')
+			b.WriteString(formatValue(sample.Value))
+			b.WriteByte('\n')
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Counter is an unlabelled process-lifetime counter
+type Counter struct {
+	name  string
+	help  string
+	mu    sync.Mutex
+	value float64
+}
+
+// NewCounter creates a counter starting at zero
+func NewCounter(name, help string) *Counter {
+	return &Counter{name: name, help: help}
+}
+
+// Add adds a non-negative amount to the counter
+func (c *Counter) Add(amount float64) {
+	if amount < 0 {
+		return
+	}
+	c.mu.Lock()
+	c.value += amount
+	c.mu.Unlock()
+}
+
+// Family returns the current count
+func (c *Counter) Family() Family {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return Family{Name: c.name, Help: c.help, Type: TypeCounter, Samples: []Sample{{Value: c.value}}}
+}
+
+// CounterVec is a process-lifetime counter with one label restricted to a fixed set of values
+// Values outside the set are counted under OtherLabelValue
+type CounterVec struct {
+	name    string
+	help    string
+	label   string
+	allowed map[string]bool
+	mu      sync.Mutex
+	counts  map[string]float64
+}
+
+// NewCounterVec creates a counter whose label takes one of the allowed values
+func NewCounterVec(name, help, label string, allowed []string) *CounterVec {
+	c := &CounterVec{name: name, help: help, label: label, allowed: make(map[string]bool), counts: make(map[string]float64)}
+	for _, value := range allowed {
+		c.allowed[value] = true
+		c.counts[value] = 0
+	}
+	return c
+}
+
+// Inc adds one to the series for the label value
+func (c *CounterVec) Inc(labelValue string) {
+	if !c.allowed[labelValue] {
+		labelValue = OtherLabelValue
+	}
+	c.mu.Lock()
+	c.counts[labelValue]++
+	c.mu.Unlock()
+}
+
+// Family returns the current counts, ordered by label value
+func (c *CounterVec) Family() Family {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	family := Family{Name: c.name, Help: c.help, Type: TypeCounter, Label: c.label}
+	for value, count := range c.counts {
+		family.Samples = append(family.Samples, Sample{LabelValue: value, Value: count})
+	}
+	sort.Slice(family.Samples, func(i, j int) bool { return family.Samples[i].LabelValue < family.Samples[j].LabelValue })
+	return family
+}
+
+// formatValue renders a sample value, including the special values the format allows
+func formatValue(value float64) string {
+	switch {
+	case math.IsNaN(value):
+		return "NaN"
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+var (
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
+
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
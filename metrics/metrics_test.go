@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite(t *testing.T) {
+	families := []Family{
+		Gauge("shop_revenue_today_dollars", "Revenue booked today.\nIn dollars", 1234.5),
+		LabelledGauge("shop_orders", "Orders by status", "status", []string{"submitted", "shipped"}, map[string]float64{"submitted": 3}),
+		{Name: "shop_odd", Help: "Odd values", Type: TypeGauge, Label: "name", Samples: []Sample{{LabelValue: `say "hi"`, Value: math.Inf(1)}}},
+	}
+
+	var out bytes.Buffer
+	assert.NoError(t, Write(&out, families))
+	assert.Equal(t, `# HELP shop_revenue_today_dollars Revenue booked today.\nIn dollars
+# TYPE shop_revenue_today_dollars gauge
+shop_revenue_today_dollars 1234.5
+# HELP shop_orders Orders by status
+# TYPE shop_orders gauge
+shop_orders{status="submitted"} 3
+shop_orders{status="shipped"} 0
+# HELP shop_odd Odd values
+# TYPE shop_odd gauge
+shop_odd{name="say \"hi\""} +Inf
+`, out.String())
+}
+
+func TestCounterVec_BoundsLabelValues(t *testing.T) {
+	counter := NewCounterVec("shop_transitions_total", "Transitions", "status", []string{"accepted", "shipped"})
+	counter.Inc("accepted")
+	counter.Inc("accepted")
+	counter.Inc("order-12345")
+	counter.Inc("order-67890")
+
+	family := counter.Family()
+	assert.Equal(t, TypeCounter, family.Type)
+	assert.Equal(t, []Sample{
+		{LabelValue: "accepted", Value: 2},
+		{LabelValue: OtherLabelValue, Value: 2},
+		{LabelValue: "shipped", Value: 0},
+	}, family.Samples)
+}
+
+func TestCounter_IgnoresNegativeAmounts(t *testing.T) {
+	counter := NewCounter("shop_bytes_total", "Bytes")
+	counter.Add(1024)
+	counter.Add(-10)
+	counter.Add(512)
+
+	family := counter.Family()
+	assert.Equal(t, TypeCounter, family.Type)
+	assert.Equal(t, []Sample{{Value: 1536}}, family.Samples)
+}
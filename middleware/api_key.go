@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+)
+
+// APIKeyHeader carries the API key on requests from server-to-server integrations
+const APIKeyHeader = "X-API-Key"
+
+// EnsureValidTokenOrAPIKey accepts either an integration API key or, when no key is sent, a valid JWT
+// An API key caller acts as services.IntegrationPrincipal, which only the order read paths allow,
+// so the middleware must only guard read-only routes
+func EnsureValidTokenOrAPIKey(requireToken gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		secret := c.GetHeader(APIKeyHeader)
+		if secret == "" {
+			requireToken(c)
+			return
+		}
+
+		key, err := services.GetAPIKeyService().Authenticate(secret)
+		if err != nil {
+			apierror.Respond(c, err)
+			return
+		}
+		c.Set(currentUserKey, services.IntegrationPrincipal(key))
+		c.Next()
+	}
+}
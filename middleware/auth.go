@@ -1,81 +1,43 @@
 package middleware
 
 import (
-	"context"
 	"log"
 	"net/http"
-	"net/url"
 	"strings"
-	"time"
 
 	"github.com/auth0/go-jwt-middleware/v2"
-	"github.com/auth0/go-jwt-middleware/v2/jwks"
 	"github.com/auth0/go-jwt-middleware/v2/validator"
 	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/auth"
 	"github.com/kendall-kelly/kendalls-nails-api/config"
 )
 
 // CustomClaims contains custom data we want from the token.
-type CustomClaims struct {
-	Scope string `json:"scope"`
-	Role  string `json:"kendalls_nails_role"`
-}
-
-// Validate does nothing for this example, but we need
-// it to satisfy validator.CustomClaims interface.
-func (c CustomClaims) Validate(ctx context.Context) error {
-	return nil
-}
-
-// HasScope checks whether our claims have a specific scope.
-func (c CustomClaims) HasScope(expectedScope string) bool {
-	result := strings.Split(c.Scope, " ")
-	for i := range result {
-		if result[i] == expectedScope {
-			return true
-		}
-	}
-
-	return false
-}
+// It lives in the auth package so every provider decodes the same claims.
+type CustomClaims = auth.CustomClaims
 
 // EnsureValidToken is a middleware that will check the validity of our JWT.
+// The identity provider is selected via AUTH_PROVIDER (Auth0 by default).
 func EnsureValidToken(cfg *config.Config) gin.HandlerFunc {
-	issuerURL, err := url.Parse("https://" + cfg.Auth0Domain + "/")
+	provider, err := auth.NewProvider(cfg)
 	if err != nil {
-		log.Fatalf("Failed to parse the issuer url: %v", err)
+		log.Fatalf("Failed to set up the auth provider: %v", err)
 	}
 
-	provider := jwks.NewCachingProvider(issuerURL, 5*time.Minute)
-
-	jwtValidator, err := validator.New(
-		provider.KeyFunc,
-		validator.RS256,
-		issuerURL.String(),
-		[]string{cfg.Auth0Audience},
-		validator.WithCustomClaims(
-			func() validator.CustomClaims {
-				return &CustomClaims{}
-			},
-		),
-		validator.WithAllowedClockSkew(time.Minute),
-	)
-	if err != nil {
-		log.Fatalf("Failed to set up the jwt validator")
-	}
+	return EnsureValidTokenWithProvider(provider)
+}
 
+// EnsureValidTokenWithProvider checks the validity of our JWT using the given provider
+func EnsureValidTokenWithProvider(provider auth.Provider) gin.HandlerFunc {
 	errorHandler := func(w http.ResponseWriter, r *http.Request, err error) {
 		log.Printf("Encountered error while validating JWT: %v", err)
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusUnauthorized)
-		if _, writeErr := w.Write([]byte(`{"success":false,"error":{"code":"INVALID_TOKEN","message":"Failed to validate JWT."}}`)); writeErr != nil {
-			log.Printf("Failed to write error response: %v", writeErr)
-		}
+		apierror.WriteHTTP(w, apierror.Unauthorized("INVALID_TOKEN", "Failed to validate JWT."))
 	}
 
 	middleware := jwtmiddleware.New(
-		jwtValidator.ValidateToken,
+		provider.ValidateToken,
 		jwtmiddleware.WithErrorHandler(errorHandler),
 	)
 
@@ -160,26 +122,14 @@ func RequireScope(scope string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		claims, err := GetClaims(c)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"success": false,
-				"error": gin.H{
-					"code":    "MISSING_CLAIMS",
-					"message": "Could not retrieve token claims",
-				},
-			})
+			apierror.Respond(c, apierror.Unauthorized("MISSING_CLAIMS", "Could not retrieve token claims"))
 			c.Abort()
 			return
 		}
 
 		customClaims := claims.CustomClaims.(*CustomClaims)
 		if !customClaims.HasScope(scope) {
-			c.JSON(http.StatusForbidden, gin.H{
-				"success": false,
-				"error": gin.H{
-					"code":    "INSUFFICIENT_SCOPE",
-					"message": "Insufficient permissions to access this resource",
-				},
-			})
+			apierror.Respond(c, apierror.Forbidden("INSUFFICIENT_SCOPE", "Insufficient permissions to access this resource"))
 			c.Abort()
 			return
 		}
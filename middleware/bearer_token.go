@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+)
+
+// RequireBearerToken admits only requests presenting the given static token as "Authorization: Bearer <token>"
+// It guards internal endpoints, such as /metrics, that are scraped by machines rather than called by users
+func RequireBearerToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		presented, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(presented), []byte(token)) != 1 {
+			apierror.Respond(c, apierror.Unauthorized("INVALID_TOKEN", "A valid bearer token is required"))
+			return
+		}
+		c.Next()
+	}
+}
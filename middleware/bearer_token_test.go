@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireBearerToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/metrics", RequireBearerToken("scrape-secret-0123"), func(c *gin.Context) {
+		c.String(http.StatusOK, "kendalls_nails_orders 1\n")
+	})
+
+	get := func(authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Unauthenticated and wrongly authenticated requests see no metrics
+	for _, authorization := range []string{"", "Bearer wrong", "scrape-secret-0123", "Basic scrape-secret-0123"} {
+		w := get(authorization)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, authorization)
+		assert.NotContains(t, w.Body.String(), "kendalls_nails_orders")
+	}
+
+	w := get("Bearer scrape-secret-0123")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "kendalls_nails_orders 1\n", w.Body.String())
+}
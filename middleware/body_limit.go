@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+)
+
+// MaxJSONDepth is how deeply objects and arrays may nest in a request body
+// No endpoint takes more than a few levels, so anything deeper is rejected before it is decoded
+const MaxJSONDepth = 32
+
+// MIMEUploadChunk is the content type of a chunk of a resumable upload, as in the tus protocol
+const MIMEUploadChunk = "application/offset+octet-stream"
+
+// LimitJSONBody rejects request bodies over maxBytes with 413, and JSON bodies that nest deeper than
+// MaxJSONDepth or repeat a key within an object with 400
+// Multipart uploads and upload chunks are left to their own size limits; every other body is buffered and checked here,
+// since handlers bind JSON whatever the Content-Type says
+func LimitJSONBody(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Body == nil || c.Request.Body == http.NoBody || c.ContentType() == gin.MIMEMultipartPOSTForm || c.ContentType() == MIMEUploadChunk {
+			c.Next()
+			return
+		}
+		tooLarge := apierror.New(http.StatusRequestEntityTooLarge, "PAYLOAD_TOO_LARGE",
+			fmt.Sprintf("Request body exceeds the maximum size of %d bytes", maxBytes))
+		if c.Request.ContentLength > maxBytes {
+			apierror.Respond(c, tooLarge)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxBytes+1))
+		c.Request.Body.Close()
+		if err != nil {
+			apierror.Respond(c, apierror.BadRequest("INVALID_BODY", "Failed to read request body").Wrap(err))
+			return
+		}
+		if int64(len(body)) > maxBytes {
+			apierror.Respond(c, tooLarge)
+			return
+		}
+		if err := checkJSONStructure(body); err != nil {
+			apierror.Respond(c, err)
+			return
+		}
+
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Next()
+	}
+}
+
+// jsonFrame is an object or array being scanned by checkJSONStructure
+type jsonFrame struct {
+	keys    map[string]bool // keys seen so far; nil for arrays
+	wantKey bool            // the next string in an object is a key
+}
+
+// checkJSONStructure scans a JSON body for excessive nesting and duplicate keys without decoding it
+// Bodies that are not well-formed JSON pass, so that binding reports them as validation errors
+func checkJSONStructure(body []byte) error {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(trimmed))
+	decoder.UseNumber()
+	var stack []*jsonFrame
+	var path []string
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil
+		}
+
+		var top *jsonFrame
+		if len(stack) > 0 {
+			top = stack[len(stack)-1]
+		}
+		if key, ok := token.(string); ok && top != nil && top.keys != nil && top.wantKey {
+			if top.keys[key] {
+				return apierror.BadRequest("DUPLICATE_JSON_KEY", "Request body repeats a key within an object").WithDetails(map[string]string{
+					"key": strings.Join(append(path, key), "."),
+				})
+			}
+			top.keys[key] = true
+			top.wantKey = false
+			path = append(path, key)
+			continue
+		}
+
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			if len(stack) == MaxJSONDepth {
+				return apierror.BadRequest("JSON_TOO_DEEP", fmt.Sprintf("Request body nests deeper than %d levels", MaxJSONDepth))
+			}
+			frame := &jsonFrame{}
+			if token == json.Delim('{') {
+				frame.keys = make(map[string]bool)
+				frame.wantKey = true
+			}
+			stack = append(stack, frame)
+			continue
+		case json.Delim('}'), json.Delim(']'):
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				return nil
+			}
+			top = stack[len(stack)-1]
+		}
+
+		// A value finished; the enclosing object expects its next key
+		if top != nil && top.keys != nil {
+			top.wantKey = true
+			path = path[:len(path)-1]
+		}
+	}
+}
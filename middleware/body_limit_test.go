@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimitJSONBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(LimitJSONBody(64))
+	router.POST("/echo", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.Data(http.StatusOK, "application/json", body)
+	})
+
+	post := func(contentType string, body io.Reader) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/echo", body)
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	errorCode := func(w *httptest.ResponseRecorder) string {
+		var response struct {
+			Error struct {
+				Code    string            `json:"code"`
+				Details map[string]string `json:"details"`
+			} `json:"error"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return response.Error.Code
+	}
+
+	// Bodies within the limit reach the handler intact
+	w := post("application/json", strings.NewReader(`{"a": {"b": [1, {"b": 2}]}, "b": "x"}`))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"a": {"b": [1, {"b": 2}]}, "b": "x"}`, w.Body.String())
+
+	// Oversized bodies are rejected, whether or not they declare their length
+	w = post("application/json", strings.NewReader(`{"text": "`+strings.Repeat("x", 64)+`"}`))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Equal(t, "PAYLOAD_TOO_LARGE", errorCode(w))
+	w = post("text/plain", io.MultiReader(strings.NewReader(strings.Repeat("x", 65))))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	// Duplicate keys are reported with their path
+	w = post("application/json", strings.NewReader(`{"a": {"role": "customer", "role": "admin"}}`))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"success":false,"error":{"code":"DUPLICATE_JSON_KEY","message":"Request body repeats a key within an object","details":{"key":"a.role"}}}`, w.Body.String())
+
+	// Malformed JSON is left for binding to report
+	w = post("application/json", strings.NewReader(`{"a": `))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Multipart uploads are not limited here
+	var form bytes.Buffer
+	writer := multipart.NewWriter(&form)
+	part, _ := writer.CreateFormFile("image", "design.png")
+	part.Write(bytes.Repeat([]byte{1}, 128))
+	writer.Close()
+	w = post(writer.FormDataContentType(), &form)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Neither are upload chunks
+	w = post(MIMEUploadChunk, bytes.NewReader(bytes.Repeat([]byte{1}, 128)))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestCheckJSONStructure(t *testing.T) {
+	nested := func(depth int) string {
+		return strings.Repeat(`{"a":`, depth) + "1" + strings.Repeat("}", depth)
+	}
+	assert.NoError(t, checkJSONStructure([]byte(nested(MaxJSONDepth))))
+	err := checkJSONStructure([]byte(nested(MaxJSONDepth + 1)))
+	if assert.Error(t, err) {
+		assert.Equal(t, "JSON_TOO_DEEP", apierror.As(err).Code)
+		assert.Equal(t, http.StatusBadRequest, apierror.As(err).Status)
+	}
+	assert.Error(t, checkJSONStructure([]byte(strings.Repeat("[", MaxJSONDepth+1)+strings.Repeat("]", MaxJSONDepth+1))))
+
+	// The same key may appear in sibling objects
+	assert.NoError(t, checkJSONStructure([]byte(`[{"id": 1}, {"id": 2}]`)))
+	assert.NoError(t, checkJSONStructure([]byte(`{"items": [{"id": 1}], "id": 3}`)))
+	assert.Error(t, checkJSONStructure([]byte(`{"items": [{"id": 1, "id": 2}]}`)))
+	assert.NoError(t, checkJSONStructure([]byte(`"not an object"`)))
+}
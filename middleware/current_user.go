@@ -0,0 +1,171 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/auth"
+	"github.com/kendall-kelly/kendalls-nails-api/flags"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+)
+
+// Gin context keys holding the outcome of resolving the caller's profile
+const (
+	currentUserKey      = "current_user"
+	currentUserErrorKey = "current_user_error"
+)
+
+// bannedCode is the error code returned to banned users
+const bannedCode = "BANNED"
+
+// maxCachedUsers bounds the profile cache; it is emptied when full
+const maxCachedUsers = 10000
+
+// userCache keeps recently resolved profiles keyed by Auth0 ID
+// Profiles are copied in and out so that handlers cannot modify a shared entry
+type userCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration // zero disables caching
+	entries map[string]userCacheEntry
+}
+
+type userCacheEntry struct {
+	user    models.User
+	expires time.Time
+}
+
+var profiles = &userCache{entries: make(map[string]userCacheEntry)}
+
+// SetUserCacheTTL sets how long resolved profiles are reused across requests (zero disables the cache)
+// It is configured once at startup from USER_CACHE_TTL
+func SetUserCacheTTL(ttl time.Duration) {
+	profiles.mu.Lock()
+	defer profiles.mu.Unlock()
+	profiles.ttl = ttl
+	profiles.entries = make(map[string]userCacheEntry)
+}
+
+// ForgetUser drops the cached profile of an identity after the profile changes
+// Other API instances keep serving their copy until it expires
+func ForgetUser(auth0ID string) {
+	profiles.mu.Lock()
+	defer profiles.mu.Unlock()
+	delete(profiles.entries, auth0ID)
+}
+
+// resolve returns the profile for the identity, from the cache when possible
+// Lookup failures, including missing profiles, are not cached
+func (uc *userCache) resolve(auth0ID string) (*models.User, error) {
+	uc.mu.Lock()
+	entry, ok := uc.entries[auth0ID]
+	ttl := uc.ttl
+	uc.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		user := entry.user
+		return &user, nil
+	}
+
+	user, err := services.GetUserService().FindByAuth0ID(auth0ID)
+	if err != nil || ttl <= 0 {
+		return user, err
+	}
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	if len(uc.entries) >= maxCachedUsers {
+		uc.entries = make(map[string]userCacheEntry)
+	}
+	uc.entries[auth0ID] = userCacheEntry{user: *user, expires: time.Now().Add(ttl)}
+	return user, nil
+}
+
+// CurrentUser resolves the authenticated caller's profile once and stores it on the Gin context
+// It must run after EnsureValidToken. Banned users are rejected with 403 BANNED although their token
+// is still valid. Callers without a profile are not rejected here, since signup needs to reach its
+// handler; GetCurrentUser reports the lookup error instead. An admin sending ImpersonateHeader
+// becomes the named user for the rest of the request, and feature flags are checked for that user
+func CurrentUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, err := GetCurrentUser(c)
+		if err != nil && apierror.As(err).Code == bannedCode {
+			apierror.Respond(c, err)
+			return
+		}
+		if err == nil && c.GetHeader(ImpersonateHeader) != "" && !impersonate(c, user) {
+			return
+		}
+		if user, err := GetCurrentUser(c); err == nil {
+			// Feature flags rolled out to some users are checked for the user the request is made as
+			c.Request = c.Request.WithContext(flags.WithUser(c.Request.Context(), user.ID))
+		}
+		c.Next()
+	}
+}
+
+// GetCurrentUser returns the authenticated caller's profile
+// The profile is resolved on first use if CurrentUser has not run; errors are API errors
+func GetCurrentUser(c *gin.Context) (*models.User, error) {
+	if user, exists := c.Get(currentUserKey); exists {
+		return user.(*models.User), nil
+	}
+	if err, exists := c.Get(currentUserErrorKey); exists {
+		return nil, err.(error)
+	}
+
+	auth0ID, err := GetUserID(c)
+	if err != nil {
+		return nil, apierror.Unauthorized("UNAUTHORIZED", "Could not extract user information")
+	}
+
+	user, err := profiles.resolve(auth0ID)
+	if err != nil {
+		// Machine clients need no profile; without one they read like an API key integration
+		if isMachineClient(c) && apierror.HasStatus(err, http.StatusNotFound) {
+			principal := services.MachinePrincipal(auth0ID)
+			c.Set(currentUserKey, principal)
+			return principal, nil
+		}
+		c.Set(currentUserErrorKey, err)
+		return nil, err
+	}
+
+	if user.IsBanned() {
+		err := apierror.Forbidden(bannedCode, "This account has been banned")
+		c.Set(currentUserErrorKey, err)
+		return nil, err
+	}
+
+	// A role changed in Auth0 reaches the profile when the user logs in with a token carrying it
+	if synced, err := services.GetRoleService().SyncRole(user, claimedRole(c)); err != nil {
+		log.Printf("Failed to sync role of %s from Auth0: %v", auth0ID, err)
+	} else if synced.Role != user.Role {
+		ForgetUser(auth0ID)
+		user = synced
+	}
+
+	c.Set(currentUserKey, user)
+	return user, nil
+}
+
+// claimedRole returns the role claim of the validated token, or "" when it has none
+func claimedRole(c *gin.Context) string {
+	claims, err := GetClaims(c)
+	if err != nil {
+		return ""
+	}
+	if customClaims, ok := claims.CustomClaims.(*CustomClaims); ok {
+		return customClaims.Role
+	}
+	return ""
+}
+
+// isMachineClient reports whether the validated token was issued to a machine client
+func isMachineClient(c *gin.Context) bool {
+	claims, err := GetCustomClaims(c)
+	return err == nil && claims.GrantType == auth.GrantClientCredentials
+}
@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/auth0/go-jwt-middleware/v2/validator"
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/auth"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+	"github.com/stretchr/testify/assert"
+)
+
+// countingUserService serves fixed profiles and counts lookups
+type countingUserService struct {
+	services.UserService
+	users   map[string]models.User
+	lookups int
+}
+
+func (s *countingUserService) FindByAuth0ID(auth0ID string) (*models.User, error) {
+	s.lookups++
+	user, ok := s.users[auth0ID]
+	if !ok {
+		return nil, apierror.NotFound("USER_NOT_FOUND", "User profile not found. Please create a profile first.")
+	}
+	return &user, nil
+}
+
+func TestCurrentUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	userService := &countingUserService{users: map[string]models.User{
+		"auth0|jane": {ID: 7, Auth0ID: "auth0|jane", Name: "Jane", Role: "customer"},
+	}}
+	services.SetUserService(userService)
+	defer services.SetUserService(nil)
+	SetUserCacheTTL(time.Minute)
+	defer SetUserCacheTTL(0)
+
+	var seen []*models.User
+	var lastErr error
+	router := gin.New()
+	router.GET("/me", func(c *gin.Context) {
+		c.Set("user_id", c.GetHeader("X-Test-Sub"))
+		c.Next()
+	}, CurrentUser(), func(c *gin.Context) {
+		// Repeated calls within a request reuse the resolved profile
+		user, err := GetCurrentUser(c)
+		_, _ = GetCurrentUser(c)
+		lastErr = err
+		if err == nil {
+			user.Name = "Modified by handler"
+			seen = append(seen, user)
+		}
+		c.Status(http.StatusOK)
+	})
+
+	request := func(sub string) {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set("X-Test-Sub", sub)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	// The profile is looked up once and then served from the cache, unaffected by handler changes
+	request("auth0|jane")
+	request("auth0|jane")
+	assert.Equal(t, 1, userService.lookups)
+	assert.Len(t, seen, 2)
+	assert.Equal(t, uint(7), seen[1].ID)
+	assert.NotSame(t, seen[0], seen[1])
+
+	// Forgetting a profile makes the next request reload it
+	ForgetUser("auth0|jane")
+	request("auth0|jane")
+	assert.Equal(t, 2, userService.lookups)
+
+	// Missing profiles reach the handler as an error and are not cached
+	request("auth0|new")
+	request("auth0|new")
+	assert.Equal(t, 4, userService.lookups)
+	var apiErr *apierror.Error
+	assert.ErrorAs(t, lastErr, &apiErr)
+	assert.Equal(t, "USER_NOT_FOUND", apiErr.Code)
+
+	// With the cache disabled every request looks the profile up
+	SetUserCacheTTL(0)
+	request("auth0|jane")
+	request("auth0|jane")
+	assert.Equal(t, 6, userService.lookups)
+}
+
+func TestCurrentUser_Banned(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	bannedAt := time.Now()
+	services.SetUserService(&countingUserService{users: map[string]models.User{
+		"auth0|jane":   {ID: 7, Auth0ID: "auth0|jane", Role: "customer"},
+		"auth0|banned": {ID: 8, Auth0ID: "auth0|banned", Role: "customer", BannedAt: &bannedAt},
+	}})
+	defer services.SetUserService(nil)
+
+	router := gin.New()
+	router.GET("/me", func(c *gin.Context) {
+		c.Set("user_id", c.GetHeader("X-Test-Sub"))
+		c.Next()
+	}, CurrentUser(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	request := func(sub string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set("X-Test-Sub", sub)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, request("auth0|jane").Code)
+	// Callers without a profile still reach the handler
+	assert.Equal(t, http.StatusOK, request("auth0|new").Code)
+
+	// A banned user's valid token is rejected before the handler runs
+	w := request("auth0|banned")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"BANNED"`)
+}
+
+func TestGetCurrentUser_MachineClient(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	services.SetUserService(&countingUserService{users: map[string]models.User{
+		"registered@clients": {ID: 9, Auth0ID: "registered@clients", Role: "technician"},
+	}})
+	defer services.SetUserService(nil)
+
+	resolve := func(sub string, claims *CustomClaims) (*models.User, error) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Set("user_id", sub)
+		c.Set("validated_claims", &validator.ValidatedClaims{CustomClaims: claims})
+		return GetCurrentUser(c)
+	}
+	machine := &CustomClaims{GrantType: auth.GrantClientCredentials, Permissions: []string{auth.ScopeReadOrders}}
+
+	// A machine client without a profile acts as a read-only integration
+	user, err := resolve("reporting@clients", machine)
+	assert.NoError(t, err)
+	assert.Equal(t, services.RoleIntegration, user.Role)
+	assert.Equal(t, "reporting@clients", user.Name)
+
+	// One with a profile acts as that user
+	user, err = resolve("registered@clients", machine)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(9), user.ID)
+
+	// Users still need a profile
+	_, err = resolve("auth0|new", &CustomClaims{})
+	assert.True(t, apierror.HasStatus(err, http.StatusNotFound))
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ReportErrors recovers panics and reports them, with server errors, to Sentry
+// Reports carry the request, route, request ID, trace ID and the ID of the user the request was made
+// as. Server errors are those rendered with apierror.Respond, so 5xx responses written directly, such
+// as a failing health check, are not reported. Panics are answered with the standard 500 error envelope
+// whether or not reporting is configured. Register it after RequestID and Trace
+func ReportErrors() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				// Deliberately aborted responses are not errors
+				panic(recovered)
+			}
+
+			log.Printf("request %s panicked: %v\n%s", GetRequestID(c), recovered, debug.Stack())
+			if hub := reportingHub(c); hub != nil {
+				hub.RecoverWithContext(c.Request.Context(), recovered)
+			}
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			apierror.Respond(c, apierror.Internal("INTERNAL_ERROR", "An unexpected error occurred").Wrap(fmt.Errorf("panic: %v", recovered)))
+		}()
+
+		c.Next()
+
+		if c.Writer.Status() < http.StatusInternalServerError || len(c.Errors) == 0 {
+			return
+		}
+		if hub := reportingHub(c); hub != nil {
+			hub.CaptureException(c.Errors.Last().Err)
+		}
+	}
+}
+
+// reportingHub returns a Sentry hub scoped to the request, or nil when error reporting is not configured
+func reportingHub(c *gin.Context) *sentry.Hub {
+	if sentry.CurrentHub().Client() == nil {
+		return nil
+	}
+
+	hub := sentry.CurrentHub().Clone()
+	hub.ConfigureScope(func(scope *sentry.Scope) {
+		scope.SetRequest(c.Request)
+		scope.SetTag("request_id", GetRequestID(c))
+		if route := c.FullPath(); route != "" {
+			scope.SetTag("route", c.Request.Method+" "+route)
+		}
+		if span := trace.SpanContextFromContext(c.Request.Context()); span.IsValid() {
+			scope.SetTag("trace_id", span.TraceID().String())
+		}
+		// Users are identified by ID alone, never by name or email
+		if value, exists := c.Get(currentUserKey); exists {
+			user := value.(*models.User)
+			id := user.Name // API keys and machine clients have no profile; they go by their name
+			if user.ID != 0 {
+				id = strconv.FormatUint(uint64(user.ID), 10)
+			}
+			scope.SetUser(sentry.User{ID: id})
+			scope.SetTag("user.role", user.Role)
+		}
+		if admin := GetImpersonator(c); admin != nil {
+			scope.SetTag("impersonator_id", strconv.FormatUint(uint64(admin.ID), 10))
+		}
+	})
+	return hub
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(RequestID(), ReportErrors())
+	asUser := func(c *gin.Context) {
+		c.Set(currentUserKey, &models.User{ID: 7, Name: "Jane", Email: "jane@example.com", Role: "customer"})
+	}
+	router.GET("/orders/:id", asUser, func(c *gin.Context) {
+		apierror.Respond(c, apierror.Internal("DATABASE_ERROR", "Failed to fetch order").Wrap(errors.New("connection refused")))
+	})
+	router.GET("/missing", func(c *gin.Context) {
+		apierror.Respond(c, apierror.NotFound("ORDER_NOT_FOUND", "Order not found"))
+	})
+	router.GET("/panic", asUser, func(c *gin.Context) {
+		var order *models.Order
+		_ = order.Status // nil pointer dereference
+	})
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer secret-token")
+		req.Header.Set(apierror.RequestIDHeader, "req-1")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Panics are answered with the error envelope even when reporting is not configured
+	w := get("/panic")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "INTERNAL_ERROR", response["error"].(map[string]interface{})["code"])
+	assert.Equal(t, "req-1", response["error"].(map[string]interface{})["request_id"])
+
+	transport := &sentry.MockTransport{}
+	require.NoError(t, sentry.Init(sentry.ClientOptions{Dsn: "https://key@sentry.example.com/1", Transport: transport, AttachStacktrace: true}))
+	defer sentry.CurrentHub().BindClient(nil)
+
+	// Client errors are not reported
+	assert.Equal(t, http.StatusNotFound, get("/missing").Code)
+	assert.Empty(t, transport.Events())
+
+	// Server errors are reported with the request, the user and the stack they were responded from
+	assert.Equal(t, http.StatusInternalServerError, get("/orders/42").Code)
+	require.Len(t, transport.Events(), 1)
+	event := transport.Events()[0]
+	assert.Equal(t, "req-1", event.Tags["request_id"])
+	assert.Equal(t, "GET /orders/:id", event.Tags["route"])
+	assert.Equal(t, "7", event.User.ID)
+	assert.Empty(t, event.User.Email, "users are identified by ID alone")
+	assert.Equal(t, http.MethodGet, event.Request.Method)
+	assert.NotContains(t, event.Request.Headers, "Authorization")
+	require.NotEmpty(t, event.Exception)
+	exception := event.Exception[len(event.Exception)-1]
+	assert.Contains(t, exception.Value, "connection refused")
+	require.NotNil(t, exception.Stacktrace)
+	top := exception.Stacktrace.Frames[len(exception.Stacktrace.Frames)-1]
+	assert.True(t, strings.HasPrefix(top.Function, "TestReportErrors"), "the stack ends in the handler, not in the middleware: %s", top.Function)
+
+	// Panics are reported with their stack
+	assert.Equal(t, http.StatusInternalServerError, get("/panic").Code)
+	require.Len(t, transport.Events(), 2)
+	event = transport.Events()[1]
+	assert.Equal(t, "7", event.User.ID)
+	assert.Equal(t, sentry.LevelFatal, event.Level)
+}
@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/flags"
+)
+
+// RequireFlag hides a route behind a feature flag: callers the flag is off for get 404 NOT_FOUND,
+// as if the route did not exist yet. It must run after CurrentUser for per-user rollouts to apply
+func RequireFlag(name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !flags.Enabled(c, name) {
+			apierror.Respond(c, apierror.NotFound("NOT_FOUND", "Resource not found"))
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/flags"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireFlag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	services.SetUserService(&countingUserService{users: map[string]models.User{
+		"auth0|jane": {ID: 7, Auth0ID: "auth0|jane", Role: "customer"},
+		"auth0|sam":  {ID: 8, Auth0ID: "auth0|sam", Role: "customer"},
+	}})
+	defer services.SetUserService(nil)
+	flags.SetLoader(func() ([]models.FeatureFlag, error) {
+		return []models.FeatureFlag{{Name: "negotiation", UserIDs: []uint{7}}}, nil
+	})
+	defer flags.SetLoader(nil)
+
+	router := gin.New()
+	router.GET("/negotiations", func(c *gin.Context) {
+		c.Set("user_id", c.GetHeader("X-Test-Sub"))
+		c.Next()
+	}, CurrentUser(), RequireFlag("negotiation"), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	request := func(sub string) int {
+		req := httptest.NewRequest(http.MethodGet, "/negotiations", nil)
+		req.Header.Set("X-Test-Sub", sub)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// The route only exists for users the flag is on for
+	assert.Equal(t, http.StatusOK, request("auth0|jane"))
+	assert.Equal(t, http.StatusNotFound, request("auth0|sam"))
+}
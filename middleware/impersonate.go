@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+)
+
+// ImpersonateHeader names the user, by ID, an admin makes a request as
+// Responses to impersonated requests carry the same header with the impersonated user's ID
+const ImpersonateHeader = "X-Impersonate-User"
+
+// impersonatorKey is the Gin context key holding the admin behind an impersonated request
+const impersonatorKey = "impersonator"
+
+// impersonationWrites allows impersonated requests that change state
+var impersonationWrites bool
+
+// SetImpersonationWrites sets whether impersonated requests may change state (read-only when false)
+// It is configured once at startup from IMPERSONATION_ALLOW_WRITES
+func SetImpersonationWrites(allowed bool) {
+	impersonationWrites = allowed
+}
+
+// GetImpersonator returns the admin behind an impersonated request, or nil when the caller is acting as themselves
+func GetImpersonator(c *gin.Context) *models.User {
+	if admin, exists := c.Get(impersonatorKey); exists {
+		return admin.(*models.User)
+	}
+	return nil
+}
+
+// impersonate makes the user named in ImpersonateHeader the current user for the rest of the request
+// Only admins may impersonate, and only with safe methods unless writes are allowed. The target is
+// treated as if they had made the request themselves, so a banned target gets 403 BANNED.
+// On failure the error response is written and false is returned
+func impersonate(c *gin.Context, admin *models.User) bool {
+	if admin.Role != services.RoleAdmin {
+		apierror.Respond(c, apierror.Forbidden("FORBIDDEN", "Only admins can impersonate users"))
+		return false
+	}
+	if method := c.Request.Method; !impersonationWrites && method != http.MethodGet && method != http.MethodHead {
+		apierror.Respond(c, apierror.Forbidden("IMPERSONATION_READ_ONLY", "Impersonated requests are read-only"))
+		return false
+	}
+
+	user, err := services.GetRoleService().Impersonate(admin, c.GetHeader(ImpersonateHeader), c.Request.Method, c.Request.URL.Path)
+	if err != nil {
+		apierror.Respond(c, err)
+		return false
+	}
+	if user.IsBanned() {
+		apierror.Respond(c, apierror.Forbidden(bannedCode, "This account has been banned"))
+		return false
+	}
+
+	c.Set(impersonatorKey, admin)
+	c.Set(currentUserKey, user)
+	c.Header(ImpersonateHeader, strconv.FormatUint(uint64(user.ID), 10))
+	return true
+}
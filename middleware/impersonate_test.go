@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+	"github.com/stretchr/testify/assert"
+)
+
+// impersonationRoleService serves impersonation targets by ID and records the requests made as them
+type impersonationRoleService struct {
+	services.RoleService
+	users    map[uint]models.User
+	requests []string
+}
+
+func (s *impersonationRoleService) SyncRole(user *models.User, claimedRole string) (*models.User, error) {
+	return user, nil
+}
+
+func (s *impersonationRoleService) Impersonate(admin *models.User, userID string, method, path string) (*models.User, error) {
+	id, _ := strconv.ParseUint(userID, 10, 64)
+	user, ok := s.users[uint(id)]
+	if !ok {
+		return nil, apierror.NotFound("USER_NOT_FOUND", "User not found")
+	}
+	s.requests = append(s.requests, method+" "+path)
+	return &user, nil
+}
+
+func TestCurrentUser_Impersonation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	bannedAt := time.Now()
+	services.SetUserService(&countingUserService{users: map[string]models.User{
+		"auth0|admin": {ID: 1, Auth0ID: "auth0|admin", Role: "admin"},
+		"auth0|jane":  {ID: 7, Auth0ID: "auth0|jane", Role: "customer"},
+	}})
+	defer services.SetUserService(nil)
+	roles := &impersonationRoleService{users: map[uint]models.User{
+		7: {ID: 7, Auth0ID: "auth0|jane", Role: "customer"},
+		8: {ID: 8, Auth0ID: "auth0|banned", Role: "customer", BannedAt: &bannedAt},
+	}}
+	services.SetRoleService(roles)
+	defer services.SetRoleService(nil)
+
+	var seen, impersonator *models.User
+	handler := func(c *gin.Context) {
+		seen, _ = GetCurrentUser(c)
+		impersonator = GetImpersonator(c)
+		c.Status(http.StatusOK)
+	}
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", c.GetHeader("X-Test-Sub"))
+		c.Next()
+	}, CurrentUser())
+	router.GET("/orders", handler)
+	router.POST("/orders", handler)
+
+	request := func(method, sub, target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/orders", nil)
+		req.Header.Set("X-Test-Sub", sub)
+		if target != "" {
+			req.Header.Set(ImpersonateHeader, target)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Without the header the admin acts as themselves
+	w := request(http.MethodGet, "auth0|admin", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, uint(1), seen.ID)
+	assert.Nil(t, impersonator)
+	assert.Empty(t, w.Header().Get(ImpersonateHeader))
+
+	// An admin reads as the named user
+	w = request(http.MethodGet, "auth0|admin", "7")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, uint(7), seen.ID)
+	if assert.NotNil(t, impersonator) {
+		assert.Equal(t, uint(1), impersonator.ID)
+	}
+	assert.Equal(t, "7", w.Header().Get(ImpersonateHeader))
+	assert.Equal(t, []string{"GET /orders"}, roles.requests)
+
+	// Only admins may impersonate
+	w = request(http.MethodGet, "auth0|jane", "8")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"FORBIDDEN"`)
+
+	// Impersonated requests are read-only unless writes are allowed
+	w = request(http.MethodPost, "auth0|admin", "7")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"IMPERSONATION_READ_ONLY"`)
+	SetImpersonationWrites(true)
+	defer SetImpersonationWrites(false)
+	assert.Equal(t, http.StatusOK, request(http.MethodPost, "auth0|admin", "7").Code)
+
+	// Unknown and banned users answer as they would for the user
+	assert.Equal(t, http.StatusNotFound, request(http.MethodGet, "auth0|admin", "99").Code)
+	w = request(http.MethodGet, "auth0|admin", "8")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"BANNED"`)
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMockToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/me", MockToken(), func(c *gin.Context) {
+		userID, _ := GetUserID(c)
+		claims, err := GetClaims(c)
+		assert.NoError(t, err)
+		c.String(http.StatusOK, userID+" "+claims.RegisteredClaims.Issuer)
+	})
+
+	tests := []struct {
+		name       string
+		header     string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "fixture user", header: "Bearer seed|customer-1", wantStatus: http.StatusOK, wantBody: "seed|customer-1 mock"},
+		{name: "missing header", header: "", wantStatus: http.StatusUnauthorized},
+		{name: "empty token", header: "Bearer ", wantStatus: http.StatusUnauthorized},
+		{name: "not a bearer token", header: "Basic abc", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/me", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, w.Body.String())
+			} else {
+				assert.Contains(t, w.Body.String(), "INVALID_TOKEN")
+			}
+		})
+	}
+}
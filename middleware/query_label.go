@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+)
+
+// LabelQueries labels the request context with the route and request ID, which the slow query log reports
+// Register it after RequestID; only services built over config.GetDBFor(c.Request.Context()) carry the label
+func LabelQueries() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+		c.Request = c.Request.WithContext(config.WithQueryLabel(c.Request.Context(), config.QueryLabel{
+			Route:     c.Request.Method + " " + route,
+			RequestID: GetRequestID(c),
+		}))
+		c.Next()
+	}
+}
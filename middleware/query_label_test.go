@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLabelQueries(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(RequestID(), LabelQueries())
+	var label config.QueryLabel
+	var labelled bool
+	router.GET("/orders/:id", func(c *gin.Context) {
+		label, labelled = config.QueryLabelFromContext(c.Request.Context())
+		c.Status(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/orders/42", nil)
+	req.Header.Set(apierror.RequestIDHeader, "req-42")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.True(t, labelled)
+	assert.Equal(t, "GET /orders/:id", label.Route, "the route pattern keeps IDs out of the log")
+	assert.Equal(t, "req-42", label.RequestID)
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+)
+
+// RateLimit allows each client IP at most limit requests per window, answering 429 RATE_LIMITED beyond that
+// Counts are kept in memory, so every API instance enforces the limit on its own
+func RateLimit(limit int, window time.Duration) gin.HandlerFunc {
+	limiter := newWindowLimiter(limit, window, time.Now)
+	return func(c *gin.Context) {
+		if retryAfter, ok := limiter.allow(c.ClientIP()); !ok {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			apierror.Respond(c, apierror.New(http.StatusTooManyRequests, "RATE_LIMITED",
+				fmt.Sprintf("Too many requests; try again in %s", retryAfter.Round(time.Second))))
+			return
+		}
+		c.Next()
+	}
+}
+
+// windowLimiter counts requests per key in fixed windows
+type windowLimiter struct {
+	limit     int
+	window    time.Duration
+	now       func() time.Time
+	mu        sync.Mutex
+	windows   map[string]*rateWindow
+	lastSweep time.Time
+}
+
+// rateWindow is the request count of one key in the window that started at start
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+func newWindowLimiter(limit int, window time.Duration, now func() time.Time) *windowLimiter {
+	return &windowLimiter{limit: limit, window: window, now: now, windows: make(map[string]*rateWindow), lastSweep: now()}
+}
+
+// allow counts a request of the key and reports whether it is within the limit
+// When it is not, the returned duration is how long until the key's window ends
+func (l *windowLimiter) allow(key string) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	// Forget keys whose window has ended, so the map does not grow with every client ever seen
+	if now.Sub(l.lastSweep) >= l.window {
+		for k, w := range l.windows {
+			if now.Sub(w.start) >= l.window {
+				delete(l.windows, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	w, ok := l.windows[key]
+	if !ok || now.Sub(w.start) >= l.window {
+		w = &rateWindow{start: now}
+		l.windows[key] = w
+	}
+	if w.count >= l.limit {
+		return w.start.Add(l.window).Sub(now), false
+	}
+	w.count++
+	return 0, true
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/quotes", RateLimit(2, time.Hour), func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	post := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/quotes", nil)
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusCreated, post("203.0.113.7:1234").Code)
+	assert.Equal(t, http.StatusCreated, post("203.0.113.7:5678").Code)
+
+	w := post("203.0.113.7:1234")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Contains(t, w.Body.String(), "RATE_LIMITED")
+	assert.Equal(t, "3600", w.Header().Get("Retry-After"))
+
+	// Other clients have their own allowance
+	assert.Equal(t, http.StatusCreated, post("198.51.100.2:1234").Code)
+}
+
+func TestRateLimit_IgnoresForwardedForFromUntrustedClients(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	require.NoError(t, router.SetTrustedProxies(nil))
+	router.POST("/quotes", RateLimit(1, time.Hour), func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	post := func(forwardedFor string) int {
+		req := httptest.NewRequest(http.MethodPost, "/quotes", nil)
+		req.RemoteAddr = "203.0.113.7:1234"
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusCreated, post("198.51.100.1"))
+	// A forged header does not give the client a new allowance
+	assert.Equal(t, http.StatusTooManyRequests, post("198.51.100.2"))
+}
+
+func TestWindowLimiter(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	limiter := newWindowLimiter(1, time.Minute, func() time.Time { return now })
+
+	_, ok := limiter.allow("a")
+	assert.True(t, ok)
+	now = now.Add(20 * time.Second)
+	retryAfter, ok := limiter.allow("a")
+	assert.False(t, ok)
+	assert.Equal(t, 40*time.Second, retryAfter)
+
+	// A new window starts once the old one ends, and ended windows are swept
+	now = now.Add(40 * time.Second)
+	_, ok = limiter.allow("b")
+	assert.True(t, ok)
+	_, ok = limiter.allow("a")
+	assert.True(t, ok)
+	now = now.Add(2 * time.Minute)
+	_, ok = limiter.allow("c")
+	assert.True(t, ok)
+	assert.Len(t, limiter.windows, 1)
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+)
+
+// RequireRole rejects callers whose role is not one of the given roles with 403
+// It must run after EnsureValidToken; callers without a profile get the profile lookup error
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, err := GetCurrentUser(c)
+		if err != nil {
+			apierror.Respond(c, err)
+			return
+		}
+		for _, role := range roles {
+			if user.Role == role {
+				c.Next()
+				return
+			}
+		}
+		apierror.Respond(c, apierror.Forbidden("FORBIDDEN", "This endpoint requires the "+strings.Join(roles, " or ")+" role"))
+	}
+}
+
+// RequirePermission rejects callers whose role is not granted the permission with 403
+// Permissions are looked up in the services permission matrix; it must run after EnsureValidToken
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, err := GetCurrentUser(c)
+		if err != nil {
+			apierror.Respond(c, err)
+			return
+		}
+		if !services.HasPermission(user.Role, permission) {
+			apierror.Respond(c, apierror.Forbidden("FORBIDDEN", "You do not have permission to do this").
+				WithDetails(map[string]interface{}{"permission": permission}))
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireRoleAndPermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	services.SetUserService(&countingUserService{users: map[string]models.User{
+		"auth0|customer": {ID: 1, Auth0ID: "auth0|customer", Role: services.RoleCustomer},
+		"auth0|tech":     {ID: 2, Auth0ID: "auth0|tech", Role: services.RoleTechnician},
+		"auth0|admin":    {ID: 3, Auth0ID: "auth0|admin", Role: services.RoleAdmin},
+	}})
+	defer services.SetUserService(nil)
+
+	router := gin.New()
+	authenticated := router.Group("", func(c *gin.Context) {
+		c.Set("user_id", c.GetHeader("X-Test-Sub"))
+		c.Next()
+	})
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	authenticated.PUT("/orders/review", RequirePermission(services.PermOrdersReview), ok)
+	authenticated.GET("/supplies", RequirePermission(services.PermSuppliesManage), ok)
+	authenticated.GET("/admin", RequireRole(services.RoleAdmin), ok)
+
+	request := func(method, path, sub string) (int, map[string]interface{}) {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("X-Test-Sub", sub)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response
+	}
+
+	code, _ := request(http.MethodPut, "/orders/review", "auth0|tech")
+	assert.Equal(t, http.StatusOK, code)
+	code, response := request(http.MethodPut, "/orders/review", "auth0|customer")
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.Equal(t, "FORBIDDEN", response["error"].(map[string]interface{})["code"])
+	assert.Equal(t, services.PermOrdersReview, response["error"].(map[string]interface{})["details"].(map[string]interface{})["permission"])
+
+	// A permission can be granted to several roles
+	code, _ = request(http.MethodGet, "/supplies", "auth0|admin")
+	assert.Equal(t, http.StatusOK, code)
+	code, _ = request(http.MethodGet, "/supplies", "auth0|customer")
+	assert.Equal(t, http.StatusForbidden, code)
+
+	code, _ = request(http.MethodGet, "/admin", "auth0|admin")
+	assert.Equal(t, http.StatusOK, code)
+	code, _ = request(http.MethodGet, "/admin", "auth0|tech")
+	assert.Equal(t, http.StatusForbidden, code)
+
+	// Callers without a profile get the lookup error, not a permission error
+	code, response = request(http.MethodGet, "/admin", "auth0|stranger")
+	assert.Equal(t, http.StatusNotFound, code)
+	assert.Equal(t, "USER_NOT_FOUND", response["error"].(map[string]interface{})["code"])
+}
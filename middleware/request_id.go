@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+)
+
+// validRequestID limits client-supplied request IDs to safe, loggable values
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// RequestID assigns every request an ID, reusing a valid incoming X-Request-ID header
+// The ID is stored in the Gin context and echoed in the response header
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(apierror.RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = newRequestID()
+		}
+
+		c.Set(apierror.RequestIDKey, requestID)
+		c.Header(apierror.RequestIDHeader, requestID)
+
+		c.Next()
+	}
+}
+
+// GetRequestID returns the current request ID, or an empty string if none was assigned
+func GetRequestID(c *gin.Context) string {
+	return c.GetString(apierror.RequestIDKey)
+}
+
+// newRequestID generates a random 128-bit hex request ID
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
+
+// ErrorHandler renders errors attached with c.Error() when the handler did not write a response
+// The last attached error wins, matching Gin's own error ordering
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		apierror.Respond(c, c.Errors.Last().Err)
+	}
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		incoming string
+		reuse    bool
+	}{
+		{"generates an ID when none is sent", "", false},
+		{"reuses a valid incoming ID", "client-abc.123", true},
+		{"replaces an unsafe incoming ID", "bad id\nwith newline", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(RequestID())
+			var seen string
+			router.GET("/test", func(c *gin.Context) {
+				seen = GetRequestID(c)
+				c.Status(http.StatusNoContent)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			if tt.incoming != "" {
+				req.Header.Set(apierror.RequestIDHeader, tt.incoming)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.NotEmpty(t, seen)
+			assert.Equal(t, seen, w.Header().Get(apierror.RequestIDHeader))
+			if tt.reuse {
+				assert.Equal(t, tt.incoming, seen)
+			} else {
+				assert.NotEqual(t, tt.incoming, seen)
+				assert.Len(t, seen, 32)
+			}
+		})
+	}
+}
+
+func TestErrorHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(RequestID(), ErrorHandler())
+	router.GET("/attached", func(c *gin.Context) {
+		_ = c.Error(apierror.Conflict("USER_EXISTS", "A user with this email already exists"))
+	})
+	router.GET("/unknown", func(c *gin.Context) {
+		_ = c.Error(errors.New("database exploded"))
+	})
+	router.GET("/written", func(c *gin.Context) {
+		_ = c.Error(errors.New("ignored"))
+		c.PureJSON(http.StatusOK, gin.H{"success": true})
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/attached", nil))
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	errorData := response["error"].(map[string]interface{})
+	assert.Equal(t, "USER_EXISTS", errorData["code"])
+	assert.Equal(t, w.Header().Get(apierror.RequestIDHeader), errorData["request_id"])
+
+	// Unknown errors never leak their message
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/unknown", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.NotContains(t, w.Body.String(), "database exploded")
+
+	// Responses already written by the handler are left alone
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/written", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+)
+
+// ScopePolicy names the scopes a scoped token needs for routes under a path prefix
+type ScopePolicy struct {
+	Prefix string
+	Read   string // GET and HEAD requests
+	Write  string // every other method
+}
+
+// EnforceScopes limits scoped tokens (see CustomClaims.IsScoped) to the routes their permissions claim grants
+// Routes outside every policy need the fallback scope. Unscoped user tokens and API key callers pass through,
+// leaving them to the role checks; it must run after EnsureValidToken
+func EnforceScopes(fallback string, policies ...ScopePolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, err := GetCustomClaims(c)
+		if err != nil || !claims.IsScoped() {
+			c.Next()
+			return
+		}
+
+		scope := requiredScope(c.Request, fallback, policies)
+		if !claims.HasPermission(scope) {
+			apierror.Respond(c, apierror.Forbidden("INSUFFICIENT_SCOPE", "Insufficient permissions to access this resource").
+				WithDetails(map[string]interface{}{"required_scope": scope}))
+			return
+		}
+		c.Next()
+	}
+}
+
+// requiredScope returns the scope of the first policy covering the request's path
+func requiredScope(r *http.Request, fallback string, policies []ScopePolicy) string {
+	path := r.URL.Path
+	for _, policy := range policies {
+		if path != policy.Prefix && !strings.HasPrefix(path, policy.Prefix+"/") {
+			continue
+		}
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			return policy.Read
+		}
+		return policy.Write
+	}
+	return fallback
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/auth0/go-jwt-middleware/v2/validator"
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnforceScopes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// The test identifies the token by the X-Test-Claims header
+	tokens := map[string]*CustomClaims{
+		"user":   {Role: "customer"},
+		"reader": {GrantType: auth.GrantClientCredentials, Permissions: []string{auth.ScopeReadOrders}},
+		"writer": {GrantType: auth.GrantClientCredentials, Permissions: []string{auth.ScopeReadOrders, auth.ScopeWriteOrders}},
+		"admin":  {GrantType: auth.GrantClientCredentials, Permissions: []string{auth.ScopeAdminAll}},
+		"none":   {GrantType: auth.GrantClientCredentials},
+	}
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if claims, ok := tokens[c.GetHeader("X-Test-Claims")]; ok {
+			c.Set("validated_claims", &validator.ValidatedClaims{CustomClaims: claims})
+		}
+		c.Next()
+	}, EnforceScopes(auth.ScopeAdminAll, ScopePolicy{Prefix: "/api/v1/orders", Read: auth.ScopeReadOrders, Write: auth.ScopeWriteOrders}))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/api/v1/orders", ok)
+	router.GET("/api/v1/orders/:id", ok)
+	router.PUT("/api/v1/orders/:id/status", ok)
+	router.GET("/api/v1/ordersheet", ok)
+	router.GET("/api/v1/admin/audit-logs", ok)
+
+	request := func(method, path, token string) (int, map[string]interface{}) {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("X-Test-Claims", token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		token    string
+		wantCode int
+	}{
+		{"unscoped user token is left to role checks", http.MethodGet, "/api/v1/admin/audit-logs", "user", http.StatusOK},
+		{"API key caller without claims passes", http.MethodGet, "/api/v1/orders", "", http.StatusOK},
+		{"read scope reads orders", http.MethodGet, "/api/v1/orders/7", "reader", http.StatusOK},
+		{"read scope cannot write orders", http.MethodPut, "/api/v1/orders/7/status", "reader", http.StatusForbidden},
+		{"write scope writes orders", http.MethodPut, "/api/v1/orders/7/status", "writer", http.StatusOK},
+		{"order scopes do not reach other routes", http.MethodGet, "/api/v1/admin/audit-logs", "writer", http.StatusForbidden},
+		{"prefix matches whole path segments", http.MethodGet, "/api/v1/ordersheet", "reader", http.StatusForbidden},
+		{"admin:all grants every scope", http.MethodPut, "/api/v1/orders/7/status", "admin", http.StatusOK},
+		{"machine token without permissions is denied", http.MethodGet, "/api/v1/orders", "none", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, response := request(tt.method, tt.path, tt.token)
+			assert.Equal(t, tt.wantCode, code)
+			if tt.wantCode == http.StatusForbidden {
+				assert.Equal(t, "INSUFFICIENT_SCOPE", response["error"].(map[string]interface{})["code"])
+			}
+		})
+	}
+
+	_, response := request(http.MethodPut, "/api/v1/orders/7/status", "reader")
+	assert.Equal(t, auth.ScopeWriteOrders, response["error"].(map[string]interface{})["details"].(map[string]interface{})["required_scope"])
+}
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/utils"
+)
+
+// Cookies and header of cookie-auth mode (COOKIE_AUTH)
+// The __Host- prefix makes browsers refuse the cookies unless they are Secure, host-only and set for path /
+const (
+	SessionCookie = "__Host-session" // httpOnly; holds the access token
+	CSRFCookie    = "__Host-csrf"    // the CSRF token, which requests that change state echo in CSRFHeader
+	CSRFHeader    = "X-CSRF-Token"
+)
+
+// EnsureValidTokenOrSession accepts a bearer token or, when none is sent, the access token held in the session cookie
+// Cookie-authenticated requests other than GET, HEAD and OPTIONS must echo the CSRF cookie in the
+// X-CSRF-Token header (double-submit), since browsers attach the cookie to forged requests too
+func EnsureValidTokenOrSession(requireToken gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		session, err := c.Cookie(SessionCookie)
+		if err != nil || session == "" || c.GetHeader("Authorization") != "" {
+			requireToken(c)
+			return
+		}
+
+		if !isSafeMethod(c.Request.Method) && !validCSRFToken(c) {
+			apierror.Respond(c, apierror.Forbidden("CSRF_TOKEN_INVALID", "Missing or invalid "+CSRFHeader+" header"))
+			return
+		}
+		c.Request.Header.Set("Authorization", "Bearer "+session)
+		requireToken(c)
+	}
+}
+
+// SetSessionCookies stores the access token in the session cookie alongside a new CSRF token, which is returned
+// Both cookies expire with the token; a zero expiry makes them last until the browser closes
+func SetSessionCookies(c *gin.Context, accessToken string, expires time.Time) (string, error) {
+	csrfToken, err := utils.NewSecret("")
+	if err != nil {
+		return "", err
+	}
+
+	maxAge := 0
+	if !expires.IsZero() {
+		maxAge = int(time.Until(expires).Seconds())
+	}
+	setCookie(c, SessionCookie, accessToken, maxAge, true)
+	// Readable by scripts on the API's host; other origins use the token from the exchange response
+	setCookie(c, CSRFCookie, csrfToken, maxAge, false)
+	return csrfToken, nil
+}
+
+// ClearSessionCookies removes the session and CSRF cookies
+func ClearSessionCookies(c *gin.Context) {
+	setCookie(c, SessionCookie, "", -1, true)
+	setCookie(c, CSRFCookie, "", -1, false)
+}
+
+func setCookie(c *gin.Context, name, value string, maxAge int, httpOnly bool) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   maxAge,
+		Secure:   true,
+		HttpOnly: httpOnly,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// validCSRFToken reports whether the CSRF header matches the CSRF cookie
+func validCSRFToken(c *gin.Context) bool {
+	cookie, err := c.Cookie(CSRFCookie)
+	header := c.GetHeader(CSRFHeader)
+	if err != nil || cookie == "" || header == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(cookie), []byte(header)) == 1
+}
+
+// isSafeMethod reports whether the HTTP method does not change state
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
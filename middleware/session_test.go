@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnsureValidTokenOrSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// MockToken takes the bearer token as the caller's ID, which shows which token was used
+	router := gin.New()
+	router.Use(EnsureValidTokenOrSession(MockToken()))
+	handler := func(c *gin.Context) {
+		userID, _ := GetUserID(c)
+		c.String(http.StatusOK, userID)
+	}
+	router.GET("/orders", handler)
+	router.POST("/orders", handler)
+
+	request := func(method, session, csrfCookie, csrfHeader, bearer string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/orders", nil)
+		if session != "" {
+			req.AddCookie(&http.Cookie{Name: SessionCookie, Value: session})
+		}
+		if csrfCookie != "" {
+			req.AddCookie(&http.Cookie{Name: CSRFCookie, Value: csrfCookie})
+		}
+		if csrfHeader != "" {
+			req.Header.Set(CSRFHeader, csrfHeader)
+		}
+		if bearer != "" {
+			req.Header.Set("Authorization", "Bearer "+bearer)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		session    string
+		csrfCookie string
+		csrfHeader string
+		bearer     string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "no credentials", method: http.MethodGet, wantStatus: http.StatusUnauthorized},
+		{name: "bearer token", method: http.MethodPost, bearer: "auth0|bearer", wantStatus: http.StatusOK, wantBody: "auth0|bearer"},
+		{name: "session cookie on a safe method", method: http.MethodGet, session: "auth0|jane", wantStatus: http.StatusOK, wantBody: "auth0|jane"},
+		{name: "session cookie without the CSRF header", method: http.MethodPost, session: "auth0|jane", csrfCookie: "abc", wantStatus: http.StatusForbidden, wantBody: "CSRF_TOKEN_INVALID"},
+		{name: "session cookie with a mismatched CSRF header", method: http.MethodPost, session: "auth0|jane", csrfCookie: "abc", csrfHeader: "abd", wantStatus: http.StatusForbidden, wantBody: "CSRF_TOKEN_INVALID"},
+		{name: "session cookie without the CSRF cookie", method: http.MethodPost, session: "auth0|jane", csrfHeader: "abc", wantStatus: http.StatusForbidden, wantBody: "CSRF_TOKEN_INVALID"},
+		{name: "session cookie with the CSRF header", method: http.MethodPost, session: "auth0|jane", csrfCookie: "abc", csrfHeader: "abc", wantStatus: http.StatusOK, wantBody: "auth0|jane"},
+		{name: "bearer token takes precedence over the cookie", method: http.MethodPost, session: "auth0|jane", bearer: "auth0|bearer", wantStatus: http.StatusOK, wantBody: "auth0|bearer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := request(tt.method, tt.session, tt.csrfCookie, tt.csrfHeader, tt.bearer)
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantBody)
+		})
+	}
+}
+
+func TestSetSessionCookies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	csrfToken, err := SetSessionCookies(c, "token-value", time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, csrfToken)
+
+	cookies := map[string]*http.Cookie{}
+	for _, cookie := range w.Result().Cookies() {
+		cookies[cookie.Name] = cookie
+	}
+	session, csrf := cookies[SessionCookie], cookies[CSRFCookie]
+	if assert.NotNil(t, session) && assert.NotNil(t, csrf) {
+		assert.Equal(t, "token-value", session.Value)
+		assert.True(t, session.HttpOnly)
+		assert.True(t, session.Secure)
+		assert.Equal(t, http.SameSiteStrictMode, session.SameSite)
+		assert.InDelta(t, 3600, session.MaxAge, 2)
+		assert.Equal(t, csrfToken, csrf.Value)
+		assert.False(t, csrf.HttpOnly)
+	}
+
+	// Clearing expires both cookies
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	ClearSessionCookies(c)
+	header := strings.Join(w.Header().Values("Set-Cookie"), "\n")
+	assert.Contains(t, header, SessionCookie+"=; Path=/; Max-Age=0")
+	assert.Contains(t, header, CSRFCookie+"=; Path=/; Max-Age=0")
+}
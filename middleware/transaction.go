@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+)
+
+// Transactional runs the rest of the request in one database transaction, carried in the request context
+// Services built over config.GetDBFor(c.Request.Context()) write through it, so the request commits or rolls back as a whole
+// It rolls back when the handler responds with an error status, records an error, or panics
+// The response is held until the commit so that a failed commit is reported instead of the handler's success
+// Only use it on handlers that stop at their first database error, since PostgreSQL rejects the rest of a failed transaction
+func Transactional() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tx := config.GetDB().WithContext(c.Request.Context()).Begin()
+		if tx.Error != nil {
+			apierror.Respond(c, apierror.Internal("DATABASE_ERROR", "Failed to start transaction").Wrap(tx.Error))
+			return
+		}
+
+		original := c.Writer
+		buffered := &bufferedResponseWriter{ResponseWriter: original, status: http.StatusOK}
+		c.Writer = buffered
+		c.Request = c.Request.WithContext(config.WithTx(c.Request.Context(), tx))
+
+		defer func() {
+			c.Writer = original
+			if recovered := recover(); recovered != nil {
+				tx.Rollback()
+				panic(recovered)
+			}
+		}()
+
+		c.Next()
+
+		c.Writer = original
+		if buffered.status >= http.StatusBadRequest || len(c.Errors) > 0 {
+			tx.Rollback()
+			buffered.flush()
+			return
+		}
+		if err := tx.Commit().Error; err != nil {
+			apierror.Respond(c, apierror.Internal("DATABASE_ERROR", "Failed to save changes").Wrap(err))
+			return
+		}
+		buffered.flush()
+	}
+}
+
+// bufferedResponseWriter holds the status and body a handler writes until flush
+// Headers go straight to the underlying writer, which sends them with the status on flush
+type bufferedResponseWriter struct {
+	gin.ResponseWriter
+	status  int
+	body    bytes.Buffer
+	written bool
+}
+
+// WriteHeader records the status to send on flush
+func (w *bufferedResponseWriter) WriteHeader(code int) {
+	if code > 0 {
+		w.status = code
+	}
+}
+
+// WriteHeaderNow marks the response as written without sending anything yet
+func (w *bufferedResponseWriter) WriteHeaderNow() {
+	w.written = true
+}
+
+// Write buffers body bytes
+func (w *bufferedResponseWriter) Write(data []byte) (int, error) {
+	w.written = true
+	return w.body.Write(data)
+}
+
+// WriteString buffers body text
+func (w *bufferedResponseWriter) WriteString(s string) (int, error) {
+	w.written = true
+	return w.body.WriteString(s)
+}
+
+// Status returns the status to send on flush
+func (w *bufferedResponseWriter) Status() int {
+	return w.status
+}
+
+// Size returns the number of body bytes buffered, or -1 when nothing has been written
+func (w *bufferedResponseWriter) Size() int {
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+// Written reports whether the handler has written a response
+func (w *bufferedResponseWriter) Written() bool {
+	return w.written
+}
+
+// Flush does nothing until the transaction ends; the buffered response is sent as a whole
+func (w *bufferedResponseWriter) Flush() {}
+
+// flush sends the buffered status and body to the underlying writer
+func (w *bufferedResponseWriter) flush() {
+	if !w.written {
+		if w.status != http.StatusOK {
+			w.ResponseWriter.WriteHeader(w.status)
+		}
+		return
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	w.ResponseWriter.WriteHeaderNow()
+	if w.body.Len() > 0 {
+		w.ResponseWriter.Write(w.body.Bytes())
+	}
+}
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// note is a row the test handlers write
+type note struct {
+	ID   uint
+	Text string
+}
+
+func TestTransactional(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&note{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+	config.SetDB(db)
+	defer config.SetDB(nil)
+
+	// write inserts a note through the request transaction, like a service built with config.GetDBFor
+	write := func(c *gin.Context, text string) {
+		if err := config.GetDBFor(c.Request.Context()).Create(&note{Text: text}).Error; err != nil {
+			t.Fatalf("Failed to write note: %v", err)
+		}
+	}
+
+	router := gin.New()
+	router.Use(gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
+		c.AbortWithStatus(http.StatusInternalServerError)
+	}))
+	router.POST("/commit", Transactional(), func(c *gin.Context) {
+		write(c, "committed")
+		write(c, "committed too")
+		c.JSON(http.StatusCreated, gin.H{"success": true})
+	})
+	router.POST("/error", Transactional(), func(c *gin.Context) {
+		write(c, "rolled back on error")
+		apierror.Respond(c, apierror.Unprocessable("INVALID_STATE", "Second step failed"))
+	})
+	router.POST("/panic", Transactional(), func(c *gin.Context) {
+		write(c, "rolled back on panic")
+		panic("boom")
+	})
+	router.POST("/commit-fails", Transactional(), func(c *gin.Context) {
+		write(c, "written")
+		// Ending the transaction early makes the middleware's commit fail
+		tx, _ := config.TxFromContext(c.Request.Context())
+		tx.Rollback()
+		c.JSON(http.StatusOK, gin.H{"success": true})
+	})
+	router.POST("/untracked", func(c *gin.Context) {
+		_, inTx := config.TxFromContext(c.Request.Context())
+		assert.False(t, inTx)
+		write(c, "outside a transaction")
+		c.Status(http.StatusNoContent)
+	})
+
+	post := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, nil))
+		return w
+	}
+	texts := func() []string {
+		var texts []string
+		db.Model(&note{}).Order("id").Pluck("text", &texts)
+		return texts
+	}
+
+	w := post("/commit")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{"success":true}`, w.Body.String())
+	assert.Equal(t, []string{"committed", "committed too"}, texts())
+
+	w = post("/error")
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "INVALID_STATE")
+	assert.Len(t, texts(), 2)
+
+	w = post("/panic")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Len(t, texts(), 2)
+
+	// The handler's success is replaced by the commit failure
+	w = post("/commit-fails")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "DATABASE_ERROR")
+	assert.NotContains(t, w.Body.String(), `"success":true`)
+	assert.Len(t, texts(), 2)
+
+	w = post("/untracked")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Len(t, texts(), 3)
+}
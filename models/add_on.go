@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AddOn is an extra a technician can include in a quote (charms, gems, nail art, etc.)
+// Retired add-ons are soft deleted; line items keep their own copy of the name and price
+type AddOn struct {
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	Name        string         `gorm:"not null" json:"name"`
+	Description string         `json:"description"`
+	Price       float64        `gorm:"not null;check:price >= 0" json:"price"` // price per unit
+	PriceCents  *int64         `json:"-"`                                      // money representation of Price, populated via dual-write
+	Currency    string         `gorm:"size:3;not null;default:'USD'" json:"currency"`
+	CreatedByID uint           `gorm:"not null;index" json:"created_by_id"` // technician who added it to the catalog
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName specifies the table name for the AddOn model
+func (AddOn) TableName() string {
+	return "add_ons"
+}
+
+// BeforeSave writes the cents representation of Price while the price migration is dual-writing
+func (a *AddOn) BeforeSave(tx *gorm.DB) error {
+	a.PriceCents = writeCents(AddOnPriceField, a.Price, a.PriceCents)
+	return nil
+}
+
+// AfterFind compares or serves Price from the cents column depending on the price migration stage
+func (a *AddOn) AfterFind(tx *gorm.DB) error {
+	readCents(AddOnPriceField, a.ID, &a.Price, a.PriceCents)
+	return nil
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Address is a shipping address in a user's address book
+type Address struct {
+	ID         uint           `gorm:"primaryKey" json:"id"`
+	UserID     uint           `gorm:"not null;index" json:"user_id"`
+	Label      string         `gorm:"not null;default:''" json:"label"` // optional name such as "Home" or "Work"
+	Recipient  string         `gorm:"not null" json:"recipient"`
+	Line1      string         `gorm:"not null" json:"line1"`
+	Line2      string         `gorm:"not null;default:''" json:"line2"`
+	City       string         `gorm:"not null" json:"city"`
+	Region     string         `gorm:"not null;default:''" json:"region"` // state, province, or county where the country uses one
+	PostalCode string         `gorm:"not null" json:"postal_code"`
+	Country    string         `gorm:"not null" json:"country"` // ISO 3166 alpha-2 code
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName specifies the table name for the Address model
+func (Address) TableName() string {
+	return "addresses"
+}
+
+// AddressSnapshot is the copy of a shipping address kept on an order
+// Orders keep their snapshot when the customer later edits or removes the address
+type AddressSnapshot struct {
+	Recipient  string `json:"recipient"`
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2,omitempty"`
+	City       string `json:"city"`
+	Region     string `json:"region,omitempty"`
+	PostalCode string `json:"postal_code"`
+	Country    string `json:"country"`
+}
+
+// Snapshot copies the address for an order
+func (a *Address) Snapshot() *AddressSnapshot {
+	return &AddressSnapshot{
+		Recipient:  a.Recipient,
+		Line1:      a.Line1,
+		Line2:      a.Line2,
+		City:       a.City,
+		Region:     a.Region,
+		PostalCode: a.PostalCode,
+		Country:    a.Country,
+	}
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Client analytics event types
+const (
+	EventTypeScreenView = "screen_view"
+	EventTypeFunnelStep = "funnel_step"
+)
+
+// AnalyticsEvent is a product analytics event reported by a client app
+// Events wait in the table until the warehouse export picks them up and sets ExportedAt,
+// so that client events travel the same route to the warehouse as server data
+type AnalyticsEvent struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	ClientEventID string     `gorm:"not null;uniqueIndex" json:"client_event_id"` // generated by the client so that retried batches are not counted twice
+	UserID        *uint      `gorm:"index" json:"user_id"`                        // nullable, events sent before signup have no profile
+	Type          string     `gorm:"not null" json:"type"`
+	Name          string     `gorm:"not null" json:"name"` // screen name, or step name within the funnel
+	Funnel        *string    `json:"funnel"`               // nullable, only set for funnel steps
+	SessionID     string     `gorm:"not null;default:''" json:"session_id"`
+	Platform      string     `gorm:"not null;default:''" json:"platform"`
+	Properties    string     `gorm:"type:text;not null;default:'{}'" json:"properties"` // JSON object of scalar values
+	OccurredAt    time.Time  `gorm:"not null;index" json:"occurred_at"`
+	ExportedAt    *time.Time `gorm:"index" json:"exported_at"` // nullable, set once the event reached the warehouse
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// TableName specifies the table name for the AnalyticsEvent model
+func (AnalyticsEvent) TableName() string {
+	return "analytics_events"
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// APIKey lets a server-to-server integration read orders without a user login
+// Only a hash of the key is stored; the key itself is returned once, when it is created
+type APIKey struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	Name        string     `gorm:"not null" json:"name"`
+	Prefix      string     `gorm:"not null" json:"prefix"` // first characters of the key, to tell keys apart
+	KeyHash     string     `gorm:"not null;uniqueIndex" json:"-"`
+	Key         string     `gorm:"-" json:"key,omitempty"` // computed field, only set in the creation response
+	CreatedByID uint       `gorm:"not null;index" json:"created_by_id"`
+	LastUsedAt  *time.Time `json:"last_used_at"` // nullable, updated at most once a minute
+	RevokedAt   *time.Time `json:"revoked_at"`   // nullable, set when an admin revokes the key
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for the APIKey model
+func (APIKey) TableName() string {
+	return "api_keys"
+}
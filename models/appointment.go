@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Appointment statuses
+const (
+	AppointmentBooked    = "booked"
+	AppointmentCancelled = "cancelled"
+)
+
+// AppointmentSlot is a time a technician offers for fitting sessions
+type AppointmentSlot struct {
+	ID           uint           `gorm:"primaryKey" json:"id"`
+	TechnicianID uint           `gorm:"not null;index" json:"technician_id"`
+	StartsAt     time.Time      `gorm:"not null;index" json:"starts_at"`
+	EndsAt       time.Time      `gorm:"not null" json:"ends_at"`
+	Appointment  *Appointment   `gorm:"foreignKey:SlotID" json:"appointment,omitempty"` // the booking, loaded for the technician's own calendar
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName specifies the table name for the AppointmentSlot model
+func (AppointmentSlot) TableName() string {
+	return "appointment_slots"
+}
+
+// Appointment is a fitting session a customer booked in a technician's slot for one of their orders
+// Cancelled appointments are kept, and free the slot for another booking
+type Appointment struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	SlotID       uint       `gorm:"not null;uniqueIndex:idx_appointments_slot_booked,where:status = 'booked'" json:"slot_id"` // a slot holds one booked appointment
+	OrderID      uint       `gorm:"not null;index" json:"order_id"`
+	CustomerID   uint       `gorm:"not null;index" json:"customer_id"`
+	TechnicianID uint       `gorm:"not null;index" json:"technician_id"`
+	StartsAt     time.Time  `gorm:"not null;index" json:"starts_at"` // copied from the slot
+	EndsAt       time.Time  `gorm:"not null" json:"ends_at"`
+	Status       string     `gorm:"not null;default:'booked';index" json:"status"` // booked, cancelled
+	CancelledAt  *time.Time `json:"cancelled_at"`                                  // nullable, set when either side cancels
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for the Appointment model
+func (Appointment) TableName() string {
+	return "appointments"
+}
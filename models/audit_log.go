@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// Audited actions
+const (
+	AuditRoleChanged        = "user.role_changed"
+	AuditUserBanned         = "user.banned"
+	AuditUserUnbanned       = "user.unbanned"
+	AuditUserImpersonated   = "user.impersonated" // an admin made a request as the user
+	AuditOrderReviewed      = "order.reviewed"    // accepting sets the order's price
+	AuditOrderStatusChanged = "order.status_changed"
+	AuditAddOnPriceSet      = "add_on.price_set"
+	AuditCatalogPriceSet    = "catalog_design.price_set"
+	AuditImageReviewed      = "image.reviewed"             // an admin overrode a moderation verdict
+	AuditPayoutRecorded     = "payout.recorded"            // an admin marked a technician's earnings as paid
+	AuditFeatureFlagChanged = "feature_flag.changed"       // created, rolled out further or back, or deleted
+	AuditViolationReviewed  = "message_violation.reviewed" // an admin confirmed or dismissed a filtered message
+)
+
+// Kinds of record an audit log entry can target
+const (
+	AuditTargetUser          = "user"
+	AuditTargetOrder         = "order"
+	AuditTargetAddOn         = "add_on"
+	AuditTargetCatalogDesign = "catalog_design"
+	AuditTargetImage         = "image" // an image moderation record
+	AuditTargetPayout        = "payout"
+	AuditTargetFeatureFlag   = "feature_flag"
+	AuditTargetViolation     = "message_violation" // a message filter violation
+)
+
+// AuditLog records a change made by an admin or technician: who made it, to what, and the values before and after
+// Entries are written alongside the change and never updated
+type AuditLog struct {
+	ID         uint                   `gorm:"primaryKey" json:"id"`
+	ActorID    uint                   `gorm:"not null;index" json:"actor_id"`
+	ActorRole  string                 `gorm:"not null" json:"actor_role"` // the actor's role when the change was made
+	Action     string                 `gorm:"not null;index" json:"action"`
+	TargetType string                 `gorm:"not null;index:idx_audit_logs_target" json:"target_type"`
+	TargetID   uint                   `gorm:"not null;index:idx_audit_logs_target" json:"target_id"`
+	Before     map[string]interface{} `gorm:"type:text;serializer:json" json:"before"` // null when the target was created
+	After      map[string]interface{} `gorm:"type:text;serializer:json" json:"after"`
+	CreatedAt  time.Time              `gorm:"index" json:"created_at"`
+}
+
+// TableName specifies the table name for the AuditLog model
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
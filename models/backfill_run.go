@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Backfill run statuses
+const (
+	BackfillRunning   = "running"
+	BackfillCompleted = "completed"
+	BackfillFailed    = "failed"
+)
+
+// BackfillRun tracks one run of a named data backfill
+// Backfills visit rows in ID order in chunks, saving the last visited ID as the cursor after every chunk,
+// so a failed or interrupted run resumes where it stopped instead of starting over
+type BackfillRun struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	Name        string     `gorm:"not null;index" json:"name"`
+	Status      string     `gorm:"not null;index" json:"status"`
+	Cursor      uint       `gorm:"not null;default:0" json:"cursor"`    // ID of the last row visited
+	Total       int64      `gorm:"not null;default:0" json:"total"`     // rows to visit, estimated when the run starts or resumes
+	Processed   int64      `gorm:"not null;default:0" json:"processed"` // rows visited so far
+	Updated     int64      `gorm:"not null;default:0" json:"updated"`   // rows changed so far
+	Skipped     int64      `gorm:"not null;default:0" json:"skipped"`   // rows needing a change that could not be made safely
+	LastError   *string    `gorm:"type:text" json:"last_error"`         // nullable, why the run failed
+	StartedByID uint       `gorm:"not null" json:"started_by_id"`
+	LeaseUntil  *time.Time `json:"-"` // nullable, set while an instance is working on the run
+	FinishedAt  *time.Time `json:"finished_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for the BackfillRun model
+func (BackfillRun) TableName() string {
+	return "backfill_runs"
+}
+
+// BackfillBatch summarizes one chunk of a backfill
+type BackfillBatch struct {
+	LastID  uint // ID of the last row visited, 0 when no row was left
+	Visited int
+	Updated int
+	Skipped int
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CannedReply is a technician's saved message template, such as "Your set ships Friday, {customer_name}!"
+// Placeholders in braces are filled in from the order when the template is sent
+type CannedReply struct {
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	UserID    uint           `gorm:"not null;index" json:"user_id"`
+	Title     string         `gorm:"not null" json:"title"` // short name to pick the template by
+	Text      string         `gorm:"type:text;not null" json:"text"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName specifies the table name for the CannedReply model
+func (CannedReply) TableName() string {
+	return "canned_replies"
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CatalogDesign is a pre-designed nail set customers can order as-is
+// Ordering a design copies its description and base price onto the order, so later catalog edits
+// never change orders already placed. Retired designs are soft deleted
+type CatalogDesign struct {
+	ID             uint                 `gorm:"primaryKey" json:"id"`
+	Name           string               `gorm:"not null" json:"name"`
+	Description    string               `gorm:"not null" json:"description"`
+	BasePrice      float64              `gorm:"not null;check:base_price > 0" json:"base_price"` // price of one set before add-ons and fees
+	BasePriceCents *int64               `json:"-"`                                               // money representation of BasePrice, populated via dual-write
+	Currency       string               `gorm:"size:3;not null;default:'USD'" json:"currency"`
+	Sizes          []string             `gorm:"type:text;serializer:json" json:"sizes"` // sizes the set is offered in, e.g. ["S", "M", "L"]
+	Images         []CatalogDesignImage `gorm:"foreignKey:DesignID" json:"images"`      // ordered by position
+	CreatedByID    uint                 `gorm:"not null;index" json:"created_by_id"`    // admin who added it to the catalog
+	CreatedAt      time.Time            `json:"created_at"`
+	UpdatedAt      time.Time            `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt       `gorm:"index" json:"-"`
+}
+
+// TableName specifies the table name for the CatalogDesign model
+func (CatalogDesign) TableName() string {
+	return "catalog_designs"
+}
+
+// BeforeSave writes the cents representation of BasePrice while the price migration is dual-writing
+func (d *CatalogDesign) BeforeSave(tx *gorm.DB) error {
+	d.BasePriceCents = writeCents(CatalogBasePriceField, d.BasePrice, d.BasePriceCents)
+	return nil
+}
+
+// AfterFind compares or serves BasePrice from the cents column depending on the price migration stage
+func (d *CatalogDesign) AfterFind(tx *gorm.DB) error {
+	readCents(CatalogBasePriceField, d.ID, &d.BasePrice, d.BasePriceCents)
+	return nil
+}
+
+// CatalogDesignImage is a photo of a catalog design
+type CatalogDesignImage struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	DesignID   uint      `gorm:"not null;index" json:"design_id"`
+	ImageS3Key string    `gorm:"not null" json:"image_s3_key"`
+	ImageURL   *string   `gorm:"-" json:"image_url,omitempty"` // computed field, presigned URL for image
+	Position   int       `gorm:"not null;default:0" json:"position"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for the CatalogDesignImage model
+func (CatalogDesignImage) TableName() string {
+	return "catalog_design_images"
+}
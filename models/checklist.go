@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// ChecklistTemplateItem is one step of a technician's production checklist template
+// The template is copied onto each order the technician puts into production
+type ChecklistTemplateItem struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	TechnicianID uint      `gorm:"not null;index" json:"technician_id"`
+	Label        string    `gorm:"not null" json:"label"`
+	Position     int       `gorm:"not null" json:"position"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for the ChecklistTemplateItem model
+func (ChecklistTemplateItem) TableName() string {
+	return "checklist_template_items"
+}
+
+// OrderChecklistItem is one production step tracked on an order
+type OrderChecklistItem struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	OrderID       uint       `gorm:"not null;index" json:"order_id"`
+	Label         string     `gorm:"not null" json:"label"`
+	Position      int        `gorm:"not null" json:"position"`
+	Completed     bool       `gorm:"not null;default:false" json:"completed"`
+	CompletedAt   *time.Time `json:"completed_at"`
+	CompletedByID *uint      `json:"completed_by_id"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for the OrderChecklistItem model
+func (OrderChecklistItem) TableName() string {
+	return "order_checklist_items"
+}
+
+// ChecklistProgress returns the percentage of completed items, or nil when there is no checklist
+func ChecklistProgress(items []OrderChecklistItem) *int {
+	if len(items) == 0 {
+		return nil
+	}
+	completed := 0
+	for _, item := range items {
+		if item.Completed {
+			completed++
+		}
+	}
+	progress := completed * 100 / len(items)
+	return &progress
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecklistProgress(t *testing.T) {
+	assert.Nil(t, ChecklistProgress(nil))
+
+	progress := ChecklistProgress([]OrderChecklistItem{{Completed: true}, {}, {}})
+	assert.Equal(t, 33, *progress)
+}
+
+func TestOrderChecklistProgressOnLoad(t *testing.T) {
+	db, customer := setupOrderTestDB(t)
+
+	order := Order{Description: "Checklist order", Quantity: 1, CustomerID: customer.ID}
+	db.Create(&order)
+	db.Create(&[]OrderChecklistItem{
+		{OrderID: order.ID, Label: "Prep", Position: 1, Completed: true},
+		{OrderID: order.ID, Label: "Paint", Position: 2},
+	})
+
+	var loaded Order
+	assert.NoError(t, db.Preload("Checklist").First(&loaded, order.ID).Error)
+	if assert.NotNil(t, loaded.ChecklistProgress) {
+		assert.Equal(t, 50, *loaded.ChecklistProgress)
+	}
+
+	// Without a preloaded checklist no progress is reported
+	var bare Order
+	assert.NoError(t, db.First(&bare, order.ID).Error)
+	assert.Nil(t, bare.ChecklistProgress)
+}
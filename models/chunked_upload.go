@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// ChunkedUpload is a design image uploaded in pieces, so an interrupted upload resumes where it stopped
+// Chunks are kept in storage until the last one arrives; the assembled image is then stored and screened
+// like any other upload, and its key can be attached to an order or saved design
+type ChunkedUpload struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	UserID     uint      `gorm:"not null;index" json:"user_id"`
+	Filename   string    `gorm:"not null" json:"filename"`
+	Size       int64     `gorm:"not null" json:"size"`                                  // total bytes the client declared
+	Offset     int64     `gorm:"column:upload_offset;not null;default:0" json:"offset"` // bytes received so far; the next chunk starts here
+	ImageS3Key *string   `json:"image_s3_key"`                                          // nullable, set once the image is assembled and stored
+	ExpiresAt  time.Time `gorm:"not null;index" json:"expires_at"`                      // unfinished chunks are deleted after this
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for the ChunkedUpload model
+func (ChunkedUpload) TableName() string {
+	return "chunked_uploads"
+}
+
+// Complete reports whether every chunk has arrived and the image has been stored
+func (u *ChunkedUpload) Complete() bool {
+	return u.ImageS3Key != nil
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"hash/fnv"
+	"strconv"
+	"time"
+)
+
+// FeatureFlag turns a new flow on for everyone, for listed users, or for a share of users
+// A user in the percentage rollout stays in it as the percentage grows
+type FeatureFlag struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Name        string    `gorm:"not null;uniqueIndex" json:"name"` // e.g. "negotiation"
+	Description string    `json:"description"`
+	Enabled     bool      `gorm:"not null;default:false" json:"enabled"`     // on for everyone
+	Percentage  int       `gorm:"not null;default:0" json:"percentage"`      // share of users (0-100) the flag is on for
+	UserIDs     []uint    `gorm:"type:text;serializer:json" json:"user_ids"` // users the flag is always on for
+	Override    *bool     `gorm:"-" json:"override,omitempty"`               // computed field, set when FEATURE_FLAGS forces the flag
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for the FeatureFlag model
+func (FeatureFlag) TableName() string {
+	return "feature_flags"
+}
+
+// EnabledFor reports whether the flag is on for the user; pass 0 for anonymous callers,
+// who only see flags that are on for everyone
+func (f *FeatureFlag) EnabledFor(userID uint) bool {
+	if f.Enabled || f.Percentage >= 100 {
+		return true
+	}
+	if userID == 0 {
+		return false
+	}
+	for _, id := range f.UserIDs {
+		if id == userID {
+			return true
+		}
+	}
+	return f.Percentage > 0 && f.bucket(userID) < uint32(f.Percentage)
+}
+
+// bucket places the user in one of 100 buckets, differently for each flag
+// so that the same users are not always the first to get every new flow
+func (f *FeatureFlag) bucket(userID uint) uint32 {
+	hash := fnv.New32a()
+	hash.Write([]byte(f.Name + ":" + strconv.FormatUint(uint64(userID), 10)))
+	return hash.Sum32() % 100
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// GiftInvitation invites the recipient of a gift order who has no account yet
+// The recipient signs up and claims the gift with the claim code, which links the order to their account
+type GiftInvitation struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	OrderID        uint       `gorm:"not null;uniqueIndex" json:"order_id"`
+	GiverID        uint       `gorm:"not null;index" json:"giver_id"`
+	Email          string     `gorm:"not null;index" json:"email"`   // normalized email the invitation was sent to
+	ClaimCodeHash  string     `gorm:"not null;uniqueIndex" json:"-"` // hash of the code the recipient claims the gift with
+	ClaimExpiresAt time.Time  `gorm:"not null" json:"claim_expires_at"`
+	ClaimedByID    *uint      `gorm:"index" json:"claimed_by_id,omitempty"` // nullable, the account that claimed the gift
+	ClaimedAt      *time.Time `json:"claimed_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for the GiftInvitation model
+func (GiftInvitation) TableName() string {
+	return "gift_invitations"
+}
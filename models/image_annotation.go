@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// DesignImageID identifies an order's design image in image routes; progress photos are identified by their update ID
+const DesignImageID = "design"
+
+// ImageAnnotation anchors an order conversation message to a point on one of the order's images
+// X and Y are fractions of the image's width and height from its top-left corner, so the point
+// holds at any display size
+type ImageAnnotation struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	MessageID  uint      `gorm:"not null;uniqueIndex" json:"message_id"`
+	OrderID    uint      `gorm:"not null;index" json:"order_id"`
+	ImageID    string    `gorm:"not null" json:"image_id"` // "design" or a progress update ID
+	ImageS3Key string    `gorm:"not null" json:"-"`        // the image annotated, in case the order's design image is replaced
+	X          float64   `gorm:"not null" json:"x"`
+	Y          float64   `gorm:"not null" json:"y"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for the ImageAnnotation model
+func (ImageAnnotation) TableName() string {
+	return "image_annotations"
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// Image moderation statuses
+const (
+	ImageApproved      = "approved"
+	ImagePendingReview = "pending_review" // flagged by the moderation provider, or not screened because it failed
+	ImageRejected      = "rejected"
+)
+
+// ImageModeration is the screening verdict for an uploaded design image
+// Images without one were uploaded by staff or before moderation was switched on, and are shown as usual
+type ImageModeration struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	ImageS3Key   string     `gorm:"not null;uniqueIndex" json:"image_s3_key"`
+	UploaderID   *uint      `gorm:"index" json:"uploader_id"` // nullable, guests submitting intake drafts have no account
+	Status       string     `gorm:"not null;index" json:"status"`
+	Labels       []string   `gorm:"type:text;serializer:json" json:"labels"` // what the provider flagged the image for
+	ScreenError  string     `json:"screen_error,omitempty"`                  // why screening failed, when it did
+	ReviewedByID *uint      `json:"reviewed_by_id"`                          // nullable, the admin who overrode the verdict
+	ReviewedAt   *time.Time `json:"reviewed_at"`                             // nullable, set when an admin reviews the image
+	ImageURL     *string    `gorm:"-" json:"image_url,omitempty"`            // computed field, presigned URL for reviewers
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for the ImageModeration model
+func (ImageModeration) TableName() string {
+	return "image_moderations"
+}
+
+// Visible reports whether the image may be shown to technicians
+func (m *ImageModeration) Visible() bool {
+	return m.Status == ImageApproved
+}
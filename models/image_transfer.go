@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Image transfer statuses
+const (
+	TransferPending   = "pending"
+	TransferSucceeded = "succeeded"
+	TransferFailed    = "failed" // gave up after the last retry
+)
+
+// ImageUploading is the image status of an order whose image is still being moved to storage
+const ImageUploading = "uploading"
+
+// ImageTransfer is an order image staged on an API instance's disk and moved to storage in the background
+// Only the instance named by Host has the staged file, so only it makes the attempts. Pending transfers
+// are attempted once NextAttemptAt has passed and retried with backoff until they succeed or fail for good;
+// on success the order's image_s3_key is set to Key
+type ImageTransfer struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	Key           string     `gorm:"not null;uniqueIndex" json:"key"` // the storage key the image is moved to
+	OrderID       *uint      `gorm:"index" json:"order_id"`           // nullable, set once the order is created
+	Host          string     `gorm:"not null;index" json:"host"`      // the instance holding the staged file
+	StagedPath    string     `gorm:"not null" json:"-"`               // the staged file on Host's disk
+	ContentType   string     `gorm:"not null" json:"content_type"`    // detected from the file content
+	Status        string     `gorm:"not null;default:'pending';index" json:"status"`
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time  `gorm:"not null;index" json:"next_attempt_at"`
+	LastAttemptAt *time.Time `json:"last_attempt_at"` // nullable
+	LastError     *string    `json:"last_error"`      // nullable
+	CompletedAt   *time.Time `json:"completed_at"`    // nullable
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for the ImageTransfer model
+func (ImageTransfer) TableName() string {
+	return "image_transfers"
+}
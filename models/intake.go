@@ -0,0 +1,64 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// IntakeToken lets an order widget embedded on a technician's own website submit draft orders
+// Only a hash of the token is stored; the token itself is returned once, when it is created
+type IntakeToken struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	TechnicianID uint       `gorm:"not null;index" json:"technician_id"`
+	Label        string     `gorm:"not null;default:''" json:"label"`
+	TokenHash    string     `gorm:"not null;uniqueIndex" json:"-"`
+	Token        string     `gorm:"-" json:"token,omitempty"` // computed field, only set in the creation response
+	Scopes       string     `gorm:"not null" json:"scopes"`   // space-separated, e.g. "drafts:create images:upload"
+	Origin       *string    `json:"origin"`                   // nullable, the only site allowed to use the token from a browser
+	ExpiresAt    time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at"` // nullable, set when the technician revokes the token
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for the IntakeToken model
+func (IntakeToken) TableName() string {
+	return "intake_tokens"
+}
+
+// HasScope reports whether the token grants the scope
+func (t *IntakeToken) HasScope(scope string) bool {
+	for _, granted := range strings.Fields(t.Scopes) {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// IntakeDraft is an order request submitted through an intake widget by someone without an account
+// It becomes a real order when the guest signs up and claims it with the claim code
+type IntakeDraft struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	IntakeTokenID  uint       `gorm:"not null;index" json:"intake_token_id"`
+	TechnicianID   uint       `gorm:"not null;index" json:"technician_id"`
+	GuestName      string     `gorm:"not null" json:"guest_name"`
+	GuestEmail     string     `gorm:"not null" json:"guest_email"`
+	Description    string     `gorm:"not null" json:"description"`
+	Quantity       int        `gorm:"not null;check:quantity > 0" json:"quantity"`
+	Rush           bool       `gorm:"not null;default:false" json:"rush"`
+	ImageS3Key     *string    `json:"image_s3_key"`                  // nullable, S3 key for uploaded image
+	ImageURL       *string    `gorm:"-" json:"image_url,omitempty"`  // computed field, presigned URL for image
+	ClaimCodeHash  string     `gorm:"not null;uniqueIndex" json:"-"` // hash of the code the guest claims the draft with
+	ClaimCode      string     `gorm:"-" json:"claim_code,omitempty"` // computed field, only set in the submission response
+	ClaimExpiresAt time.Time  `gorm:"not null" json:"claim_expires_at"`
+	OrderID        *uint      `gorm:"index" json:"order_id,omitempty"` // nullable, the order created when the draft was claimed
+	ClaimedAt      *time.Time `json:"claimed_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for the IntakeDraft model
+func (IntakeDraft) TableName() string {
+	return "intake_drafts"
+}
@@ -11,9 +11,11 @@ type Message struct {
 	ID        uint           `gorm:"primaryKey" json:"id"`
 	OrderID   uint           `gorm:"not null;index" json:"order_id"` // foreign key to orders table
 	Order     Order          `gorm:"foreignKey:OrderID" json:"-"`    // don't include full order in JSON
-	SenderID  uint           `gorm:"not null;index" json:"sender_id"` // foreign key to users table
-	Sender    User           `gorm:"foreignKey:SenderID" json:"sender"`
+	SenderID  *uint          `gorm:"index" json:"sender_id"` // foreign key to users table, nil for notices sent by the system
+	Sender    *User          `gorm:"foreignKey:SenderID" json:"sender"`
 	Text      string         `gorm:"type:text;not null" json:"text"`
+	Annotation *ImageAnnotation `gorm:"foreignKey:MessageID" json:"annotation,omitempty"` // set when the message points at a spot on an order image
+	Status    string         `gorm:"-" json:"status,omitempty"` // computed for the viewer's own messages: sent, delivered or seen
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
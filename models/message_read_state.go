@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Delivery statuses of a message, reported to its sender
+const (
+	MessageStatusSent      = "sent"      // no other participant has received it yet
+	MessageStatusDelivered = "delivered" // fetched by another participant's client
+	MessageStatusSeen      = "seen"      // another participant has read it
+)
+
+// MessageReadState records how far one participant of an order conversation has received and read it
+// Message IDs only grow, so the last delivered and seen IDs cover every earlier message
+type MessageReadState struct {
+	ID                     uint      `gorm:"primaryKey" json:"-"`
+	OrderID                uint      `gorm:"not null;uniqueIndex:idx_message_read_states_order_user" json:"order_id"`
+	UserID                 uint      `gorm:"not null;uniqueIndex:idx_message_read_states_order_user" json:"user_id"`
+	LastDeliveredMessageID uint      `gorm:"not null;default:0" json:"last_delivered_message_id"`
+	LastSeenMessageID      uint      `gorm:"not null;default:0" json:"last_seen_message_id"`
+	UpdatedAt              time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for the MessageReadState model
+func (MessageReadState) TableName() string {
+	return "message_read_states"
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// What the message filter did with an offending message
+const (
+	MessageMasked  = "masked"  // sent with the filtered words replaced by asterisks
+	MessageBlocked = "blocked" // not sent
+)
+
+// Review statuses of a message violation
+const (
+	ViolationPendingReview = "pending_review"
+	ViolationConfirmed     = "confirmed" // an admin agreed the message was offensive
+	ViolationDismissed     = "dismissed" // an admin found nothing wrong with it
+)
+
+// MessageViolation records a message the content filter masked or blocked, for admins to review
+// It keeps the text as written, since a masked message was sent without the offending words and a blocked one not at all
+type MessageViolation struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	OrderID      uint       `gorm:"not null;index" json:"order_id"`
+	SenderID     uint       `gorm:"not null;index" json:"sender_id"`
+	Text         string     `gorm:"type:text;not null" json:"text"`
+	Action       string     `gorm:"not null" json:"action"`                  // MessageMasked or MessageBlocked
+	Terms        []string   `gorm:"type:text;serializer:json" json:"terms"`  // the filtered words the message used
+	Labels       []string   `gorm:"type:text;serializer:json" json:"labels"` // what the moderation endpoint flagged it for
+	Status       string     `gorm:"not null;index" json:"status"`
+	ReviewedByID *uint      `json:"reviewed_by_id"` // nullable, the admin who reviewed the violation
+	ReviewedAt   *time.Time `json:"reviewed_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for the MessageViolation model
+func (MessageViolation) TableName() string {
+	return "message_violations"
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// All returns every model managed by migrations, ordered so that
+// referenced tables are created before the tables that reference them
+func All() []interface{} {
+	return []interface{}{
+		&User{},
+		&TechnicianSettings{},
+		&UserConsent{},
+		&RoleRequest{},
+		&CatalogDesign{},
+		&CatalogDesignImage{},
+		&Address{},
+		&Payout{},
+		&Order{},
+		&SavedDesign{},
+		&AppointmentSlot{},
+		&Appointment{},
+		&Message{},
+		&AddOn{},
+		&PriceList{},
+		&OrderLineItem{},
+		&ChecklistTemplateItem{},
+		&OrderChecklistItem{},
+		&OrderHandoff{},
+		&ProgressUpdate{},
+		&IntakeToken{},
+		&IntakeDraft{},
+		&GiftInvitation{},
+		&QuoteRequest{},
+		&AnalyticsEvent{},
+		&APIKey{},
+		&Webhook{},
+		&WebhookDelivery{},
+		&Notification{},
+		&NotificationPreferences{},
+		&BackfillRun{},
+		&Recommendation{},
+		&Supply{},
+		&SupplyUsage{},
+		&AuditLog{},
+		&ImageModeration{},
+		&ChunkedUpload{},
+		&OrderView{},
+		&OutboxEvent{},
+		&FeatureFlag{},
+		&ImageTransfer{},
+		&ImageAnnotation{},
+		&MessageReadState{},
+		&CannedReply{},
+		&MessageViolation{},
+	}
+}
+
+// MigrateUp creates or updates the tables for all models
+func MigrateUp(db *gorm.DB) error {
+	if err := db.AutoMigrate(All()...); err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+	return ensureUserIndexes(db)
+}
+
+// MigrateDown drops the tables for all models in reverse dependency order
+func MigrateDown(db *gorm.DB) error {
+	all := All()
+	for i := len(all) - 1; i >= 0; i-- {
+		if err := db.Migrator().DropTable(all[i]); err != nil {
+			return fmt.Errorf("failed to drop table for %T: %w", all[i], err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestMigrateUpAndDown(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+
+	// Migrating up creates every table
+	assert.NoError(t, MigrateUp(db))
+	for _, model := range All() {
+		assert.True(t, db.Migrator().HasTable(model), "table for %T should exist", model)
+	}
+
+	// Migrating up again is a no-op
+	assert.NoError(t, MigrateUp(db))
+
+	// Migrating down drops every table
+	assert.NoError(t, MigrateDown(db))
+	for _, model := range All() {
+		assert.False(t, db.Migrator().HasTable(model), "table for %T should be dropped", model)
+	}
+}
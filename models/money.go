@@ -0,0 +1,166 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/kendall-kelly/kendalls-nails-api/dualwrite"
+	"github.com/kendall-kelly/kendalls-nails-api/utils"
+	"gorm.io/gorm"
+)
+
+// Fields tracking the staged migration of the remaining decimal prices to integer cents
+// They follow the same rollout as OrderPriceField: off -> dual_write -> (backfill) -> shadow_read -> new,
+// starting in dual_write (DualWritePrices) with the backfill run by every migration
+var (
+	AddOnPriceField        = dualwrite.Register("add_ons.price")
+	CatalogBasePriceField  = dualwrite.Register("catalog_designs.base_price")
+	LineItemUnitPriceField = dualwrite.Register("order_line_items.unit_price")
+	LineItemAmountField    = dualwrite.Register("order_line_items.amount")
+)
+
+// PriceColumn is a decimal price column and the integer cents column replacing it
+type PriceColumn struct {
+	Field *dualwrite.Field
+	Table string
+	Price string // legacy decimal column
+	Cents string // integer cents column
+}
+
+// Name returns the "<table>.<column>" name of the cents column
+func (c PriceColumn) Name() string {
+	return c.Table + "." + c.Cents
+}
+
+// Price columns migrating to integer cents
+var (
+	OrderPriceColumn        = PriceColumn{Field: OrderPriceField, Table: "orders", Price: "price", Cents: "price_cents"}
+	AddOnPriceColumn        = PriceColumn{Field: AddOnPriceField, Table: "add_ons", Price: "price", Cents: "price_cents"}
+	CatalogBasePriceColumn  = PriceColumn{Field: CatalogBasePriceField, Table: "catalog_designs", Price: "base_price", Cents: "base_price_cents"}
+	LineItemUnitPriceColumn = PriceColumn{Field: LineItemUnitPriceField, Table: "order_line_items", Price: "unit_price", Cents: "unit_price_cents"}
+	LineItemAmountColumn    = PriceColumn{Field: LineItemAmountField, Table: "order_line_items", Price: "amount", Cents: "amount_cents"}
+	PriceColumns            = []PriceColumn{OrderPriceColumn, AddOnPriceColumn, CatalogBasePriceColumn, LineItemUnitPriceColumn, LineItemAmountColumn}
+)
+
+// CentsSQL returns a SQL expression of the column's amounts in whole cents, for sums that must not drift
+// Once the migration serves reads from the cents column it is used, falling back to the decimal price for rows not yet backfilled
+func (c PriceColumn) CentsSQL() string {
+	price := "CAST(ROUND(" + c.Table + "." + c.Price + " * 100) AS BIGINT)"
+	if c.Field.ReadsNew() {
+		return "COALESCE(" + c.Table + "." + c.Cents + ", " + price + ")"
+	}
+	return price
+}
+
+// BackfillPriceCents populates a cents column for rows written before dual-write was enabled
+// Run it after switching the column to dual_write and before shadow_read so comparisons start clean
+func BackfillPriceCents(db *gorm.DB, column PriceColumn) (int64, error) {
+	result := db.Table(column.Table).
+		Where(column.Price+" IS NOT NULL AND "+column.Cents+" IS NULL").
+		UpdateColumn(column.Cents, gorm.Expr("ROUND("+column.Price+" * 100)"))
+	return result.RowsAffected, result.Error
+}
+
+// DualWritePrices moves every price column to dual_write, the stage deployments start in so that integer cents
+// are stored from the first write; DUAL_WRITE_STAGES, applied afterwards, moves them on (or back to off)
+func DualWritePrices() {
+	for _, column := range PriceColumns {
+		column.Field.SetStage(dualwrite.StageDualWrite)
+	}
+}
+
+// BackfillWrittenPriceCents populates the cents columns whose migration writes them, for rows written before it did,
+// and returns the number of rows updated in each; migrations run it so that no row is left without cents
+func BackfillWrittenPriceCents(db *gorm.DB) (map[string]int64, error) {
+	updated := make(map[string]int64)
+	for _, column := range PriceColumns {
+		if !column.Field.WritesNew() {
+			continue
+		}
+		rows, err := BackfillPriceCents(db, column)
+		if err != nil {
+			return updated, fmt.Errorf("failed to backfill %s: %w", column.Name(), err)
+		}
+		updated[column.Name()] = rows
+	}
+	return updated, nil
+}
+
+// BackfillPriceCentsBatch populates a cents column for up to limit rows with an ID above afterID
+// It is the resumable form of BackfillPriceCents, run chunk by chunk from the admin backfills
+func BackfillPriceCentsBatch(db *gorm.DB, column PriceColumn, afterID uint, limit int) (BackfillBatch, error) {
+	var batch BackfillBatch
+	var ids []uint
+	if err := liveRows(db, column.Table).Where("id > ?", afterID).Order("id").Limit(limit).Pluck("id", &ids).Error; err != nil {
+		return batch, err
+	}
+	if len(ids) == 0 {
+		return batch, nil
+	}
+	batch.LastID = ids[len(ids)-1]
+	batch.Visited = len(ids)
+
+	result := liveRows(db, column.Table).
+		Where("id IN ? AND "+column.Price+" IS NOT NULL AND "+column.Cents+" IS NULL", ids).
+		UpdateColumn(column.Cents, gorm.Expr("ROUND("+column.Price+" * 100)"))
+	batch.Updated = int(result.RowsAffected)
+	return batch, result.Error
+}
+
+// liveRows scopes a query to a table, leaving out soft-deleted rows when the table has them
+func liveRows(db *gorm.DB, table string) *gorm.DB {
+	query := db.Table(table)
+	if db.Migrator().HasColumn(table, "deleted_at") {
+		query = query.Where("deleted_at IS NULL")
+	}
+	return query
+}
+
+// writeCents returns the cents to store with a price: converted while its migration writes the new column, unchanged otherwise
+func writeCents(field *dualwrite.Field, price float64, cents *int64) *int64 {
+	if !field.WritesNew() {
+		return cents
+	}
+	field.RecordWrite()
+	converted := utils.ToCents(price)
+	return &converted
+}
+
+// readCents serves a price from its cents column, or compares the two, depending on the migration stage
+// Rows not yet backfilled keep their decimal price
+func readCents(field *dualwrite.Field, key interface{}, price *float64, cents *int64) {
+	switch {
+	case field.ReadsNew():
+		if cents != nil {
+			*price = utils.FromCents(*cents)
+		}
+	case field.ShadowReads():
+		legacy := utils.ToCents(*price)
+		field.Observe(key, legacy, formatCents(cents), cents != nil && *cents == legacy)
+	}
+}
+
+// priceToCents converts a decimal price to integer cents
+func priceToCents(price *float64) *int64 {
+	if price == nil {
+		return nil
+	}
+	cents := utils.ToCents(*price)
+	return &cents
+}
+
+// centsToPrice converts integer cents back to a decimal price
+func centsToPrice(cents *int64) *float64 {
+	if cents == nil {
+		return nil
+	}
+	price := utils.FromCents(*cents)
+	return &price
+}
+
+// formatCents renders nullable cents for mismatch logs
+func formatCents(cents *int64) interface{} {
+	if cents == nil {
+		return nil
+	}
+	return *cents
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/dualwrite"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddOnPriceDualWrite(t *testing.T) {
+	db, _ := setupOrderTestDB(t)
+	defer AddOnPriceField.SetStage(dualwrite.StageOff)
+
+	// Off: only the legacy column is written, and the currency defaults to USD
+	AddOnPriceField.SetStage(dualwrite.StageOff)
+	legacyOnly := AddOn{Name: "Legacy", Price: 2.5, CreatedByID: 1}
+	assert.NoError(t, db.Create(&legacyOnly).Error)
+	assert.Nil(t, legacyOnly.PriceCents)
+	assert.Equal(t, "USD", legacyOnly.Currency)
+
+	// Dual write: both columns are written
+	AddOnPriceField.SetStage(dualwrite.StageDualWrite)
+	dual := AddOn{Name: "Dual", Price: 0.29, Currency: "EUR", CreatedByID: 1}
+	assert.NoError(t, db.Create(&dual).Error)
+	if assert.NotNil(t, dual.PriceCents) {
+		assert.Equal(t, int64(29), *dual.PriceCents)
+	}
+
+	// New: price is served from the cents column, and rows not yet backfilled keep their decimal price
+	AddOnPriceField.SetStage(dualwrite.StageNew)
+	db.Model(&AddOn{}).Where("id = ?", dual.ID).UpdateColumn("price", 1.0)
+	var cutOver, unfilled AddOn
+	assert.NoError(t, db.First(&cutOver, dual.ID).Error)
+	assert.Equal(t, 0.29, cutOver.Price)
+	assert.Equal(t, "EUR", cutOver.Currency)
+	assert.NoError(t, db.First(&unfilled, legacyOnly.ID).Error)
+	assert.Equal(t, 2.5, unfilled.Price)
+}
+
+func TestBackfillWrittenPriceCents(t *testing.T) {
+	db, customer := setupOrderTestDB(t)
+	defer func() {
+		for _, column := range PriceColumns {
+			column.Field.SetStage(dualwrite.StageOff)
+		}
+	}()
+
+	// Rows written before dual-write have no cents
+	price := 19.99
+	order := Order{Description: "Legacy", Quantity: 1, CustomerID: customer.ID, Price: &price}
+	assert.NoError(t, db.Create(&order).Error)
+	addOn := AddOn{Name: "Charm", Price: 0.35, CreatedByID: 1}
+	assert.NoError(t, db.Create(&addOn).Error)
+
+	// Columns still off are left alone
+	AddOnPriceField.SetStage(dualwrite.StageOff)
+	OrderPriceField.SetStage(dualwrite.StageOff)
+	updated, err := BackfillWrittenPriceCents(db)
+	assert.NoError(t, err)
+	assert.Empty(t, updated)
+
+	// Deployments start dual-writing every price, and the backfill catches up the older rows
+	DualWritePrices()
+	assert.True(t, OrderPriceField.WritesNew())
+	assert.True(t, LineItemAmountField.WritesNew())
+	updated, err = BackfillWrittenPriceCents(db)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), updated[OrderPriceColumn.Name()])
+	assert.Equal(t, int64(1), updated[AddOnPriceColumn.Name()])
+
+	var cents int64
+	db.Model(&Order{}).Where("id = ?", order.ID).Select("price_cents").Scan(&cents)
+	assert.Equal(t, int64(1999), cents)
+	db.Model(&AddOn{}).Where("id = ?", addOn.ID).Select("price_cents").Scan(&cents)
+	assert.Equal(t, int64(35), cents)
+}
+
+func TestBackfillPriceCentsBatch(t *testing.T) {
+	db, customer := setupOrderTestDB(t)
+	LineItemAmountField.SetStage(dualwrite.StageOff)
+
+	order := Order{Description: "Quoted", Quantity: 1, CustomerID: customer.ID}
+	db.Create(&order)
+	items := []OrderLineItem{
+		{OrderID: order.ID, Kind: LineItemBase, Description: "Base set", Quantity: 1, UnitPrice: 40.1, Amount: 40.1},
+		{OrderID: order.ID, Kind: LineItemAddOn, Description: "Charm", Quantity: 3, UnitPrice: 0.35, Amount: 1.05},
+		{OrderID: order.ID, Kind: LineItemRushFee, Description: "Rush fee", Quantity: 1, UnitPrice: 15, Amount: 15},
+	}
+	for i := range items {
+		assert.NoError(t, db.Create(&items[i]).Error)
+	}
+
+	batch, err := BackfillPriceCentsBatch(db, LineItemAmountColumn, 0, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, BackfillBatch{LastID: items[1].ID, Visited: 2, Updated: 2}, batch)
+
+	batch, err = BackfillPriceCentsBatch(db, LineItemAmountColumn, batch.LastID, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, BackfillBatch{LastID: items[2].ID, Visited: 1, Updated: 1}, batch)
+
+	var cents []int64
+	db.Model(&OrderLineItem{}).Order("id").Pluck("amount_cents", &cents)
+	assert.Equal(t, []int64{4010, 105, 1500}, cents)
+
+	// Unit prices are a separate column and are left for their own backfill
+	var unfilled int64
+	db.Model(&OrderLineItem{}).Where("unit_price_cents IS NULL").Count(&unfilled)
+	assert.Equal(t, int64(3), unfilled)
+}
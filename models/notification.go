@@ -0,0 +1,98 @@
+package models
+
+import "time"
+
+// Notification is an event queued for one user, read by the long-poll notifications endpoint
+// IDs only grow, so the last one a client has seen is its cursor
+type Notification struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	UserID         uint      `gorm:"not null;index" json:"-"`
+	Event          string    `gorm:"not null" json:"event"` // one of the Notification event types
+	OrderID        uint      `gorm:"not null" json:"order_id"`
+	MessageID      *uint     `json:"message_id,omitempty"`      // set for message.created and the delivered and seen receipts
+	ActorID        *uint     `json:"actor_id,omitempty"`        // set for typing and receipts: the user typing, or who received or read the messages
+	Status         string    `json:"status,omitempty"`          // set for order.status_changed
+	PreviousStatus string    `json:"previous_status,omitempty"` // set for order.status_changed
+	CreatedAt      time.Time `gorm:"index" json:"created_at"`
+}
+
+// TableName specifies the table name for the Notification model
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+// Notification channels a user can turn on or off per event type
+const (
+	ChannelEmail = "email"
+	ChannelPush  = "push"
+)
+
+// Event types of notifications; promo is reserved for marketing messages and has no producer yet
+const (
+	NotificationMessageCreated     = "message.created"
+	NotificationOrderStatusChanged = "order.status_changed"
+	NotificationPromo              = "promo"
+)
+
+// Conversation signals, served by long polling only and never sent by email or push
+// Typing indicators are ephemeral: polls skip them once they are a few seconds old
+const (
+	NotificationTyping           = "typing"
+	NotificationMessageDelivered = "message.delivered" // messages up to message_id reached the actor
+	NotificationMessageSeen      = "message.seen"      // the actor read messages up to message_id
+)
+
+// ChannelPreference turns the delivery of one event type on or off per channel
+type ChannelPreference struct {
+	Email bool `gorm:"not null" json:"email"`
+	Push  bool `gorm:"not null" json:"push"`
+}
+
+// NotificationPreferences holds which notifications a user wants by email and push
+// Users without preferences get DefaultNotificationPreferences; in-app notifications are always kept
+type NotificationPreferences struct {
+	ID           uint              `gorm:"primaryKey" json:"-"`
+	UserID       uint              `gorm:"not null;uniqueIndex" json:"-"`
+	Message      ChannelPreference `gorm:"embedded;embeddedPrefix:message_" json:"message"`
+	StatusChange ChannelPreference `gorm:"embedded;embeddedPrefix:status_change_" json:"status_change"`
+	Promo        ChannelPreference `gorm:"embedded;embeddedPrefix:promo_" json:"promo"`
+	CreatedAt    time.Time         `json:"-"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+}
+
+// TableName specifies the table name for the NotificationPreferences model
+func (NotificationPreferences) TableName() string {
+	return "notification_preferences"
+}
+
+// DefaultNotificationPreferences sends order notifications on every channel and promotions on none
+func DefaultNotificationPreferences(userID uint) *NotificationPreferences {
+	return &NotificationPreferences{
+		UserID:       userID,
+		Message:      ChannelPreference{Email: true, Push: true},
+		StatusChange: ChannelPreference{Email: true, Push: true},
+	}
+}
+
+// Allows reports whether the user wants notifications of the event type on the channel
+func (p *NotificationPreferences) Allows(event, channel string) bool {
+	var preference ChannelPreference
+	switch event {
+	case NotificationMessageCreated:
+		preference = p.Message
+	case NotificationOrderStatusChanged:
+		preference = p.StatusChange
+	case NotificationPromo:
+		preference = p.Promo
+	default:
+		return false
+	}
+
+	switch channel {
+	case ChannelEmail:
+		return preference.Email
+	case ChannelPush:
+		return preference.Push
+	}
+	return false
+}
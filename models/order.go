@@ -3,24 +3,62 @@ package models
 import (
 	"time"
 
+	"github.com/kendall-kelly/kendalls-nails-api/dualwrite"
 	"gorm.io/gorm"
 )
 
+// OrderPriceField tracks the staged migration of orders.price to integer cents (orders.price_cents)
+var OrderPriceField = dualwrite.Register("orders.price")
+
+// Order priorities, derived from the rush flag
+const (
+	PriorityStandard = "standard"
+	PriorityRush     = "rush"
+)
+
 // Order represents a custom nail order in the system
 type Order struct {
 	ID           uint           `gorm:"primaryKey" json:"id"`
 	Description  string         `gorm:"not null" json:"description"`
 	Quantity     int            `gorm:"not null;check:quantity > 0" json:"quantity"`
-	Status       string         `gorm:"not null;default:'submitted'" json:"status"` // submitted, accepted, rejected, in_production, shipped, delivered
-	Price        *float64       `json:"price"`                                        // nullable, set when order is accepted
+	Status       string         `gorm:"not null;default:'submitted'" json:"status"` // submitted, accepted, rejected, in_production, shipped, delivered, expired
+	Rush         bool           `gorm:"not null;default:false;index" json:"rush"`    // expedited order, carries a surcharge and is queued first
+	Priority     string         `gorm:"-" json:"priority"`                            // computed field, "rush" or "standard"
+	Price        *float64       `json:"price"`                                        // nullable, set when order is accepted (sum of line items); pre-filled with the base price for catalog orders
+	PriceCents   *int64         `json:"-"`                                            // money representation of Price, populated via dual-write
+	Currency     string         `gorm:"size:3;not null;default:'USD'" json:"currency"` // ISO 4217 code of Price and the line items, set when the order is placed
 	Feedback     *string        `json:"feedback"`                                     // nullable, set when order is rejected
+	ReviewedAt   *time.Time     `json:"reviewed_at"`                                  // nullable, set when order is accepted or rejected
+	ProductionStartedAt *time.Time `json:"production_started_at"`                    // nullable, set when the order moves to in_production
+	ShippedAt    *time.Time     `json:"shipped_at"`                                   // nullable, set when the order moves to shipped
+	DeliveredAt  *time.Time     `json:"delivered_at"`                                 // nullable, set when the order moves to delivered
+	RequestedBy  *time.Time     `json:"requested_by"`                                 // nullable, date the customer would like the order by
+	PromisedBy   *time.Time     `json:"promised_by"`                                  // nullable, date the technician promised delivery by, set when accepting
+	Overdue      bool           `gorm:"-" json:"overdue"`                             // computed field, open order past its due date
+	PriceListID  *uint          `gorm:"index" json:"price_list_id"`                   // nullable, the price list in force when the order was accepted
+	PayoutID     *uint          `gorm:"index" json:"-"`                               // nullable, the payout that paid the technician for the delivered order
 	ImageS3Key      *string        `json:"image_s3_key"`                                 // nullable, S3 key for uploaded image
 	ImageURL        *string        `gorm:"-" json:"image_url,omitempty"`                 // computed field, presigned URL for image
+	ImageStatus     *string        `gorm:"-" json:"image_status,omitempty"`              // computed field, moderation status while the image is held back
 	OriginalOrderID *uint          `gorm:"index" json:"original_order_id,omitempty"`     // nullable, links to original order when reordered
+	CatalogDesignID *uint          `gorm:"index" json:"catalog_design_id,omitempty"`     // nullable, the catalog design the order was placed from
+	SavedDesignID   *uint          `gorm:"index" json:"saved_design_id,omitempty"`       // nullable, the customer's saved design the order was placed from
+	QuoteID         *uint          `gorm:"index" json:"quote_id,omitempty"`              // nullable, the guest quote request the order was converted from; pre-filled with the quoted price
+	ShippingAddressID *uint           `gorm:"index" json:"shipping_address_id"`            // nullable, the address book entry the order ships to; required before acceptance
+	ShippingAddress   *AddressSnapshot `gorm:"type:text;serializer:json" json:"shipping_address"` // copy of the address taken when it was chosen
 	CustomerID      uint           `gorm:"not null;index" json:"customer_id"`            // foreign key to users table
+	RecipientEmail  *string        `json:"recipient_email,omitempty"`                    // nullable, normalized email of the person a gift order is for
+	RecipientName   *string        `json:"recipient_name,omitempty"`                     // nullable, how the giver addresses the recipient
+	GiftMessage     *string        `json:"gift_message,omitempty"`                       // nullable, the giver's note to the recipient
+	RecipientID     *uint          `gorm:"index" json:"recipient_id,omitempty"`          // nullable, the recipient's account; set when the gift is placed or claimed
+	GiftClaimCode   string         `gorm:"-" json:"gift_claim_code,omitempty"`           // computed field, only set in the creation response of a gift to someone without an account
 	Customer     User           `gorm:"foreignKey:CustomerID" json:"customer"`
 	TechnicianID *uint          `gorm:"index" json:"technician_id"` // nullable, assigned when order is reviewed
 	Technician   *User          `gorm:"foreignKey:TechnicianID" json:"technician,omitempty"`
+	LineItems    []OrderLineItem `gorm:"foreignKey:OrderID" json:"line_items,omitempty"` // itemized quote, set when order is accepted
+	Checklist    []OrderChecklistItem `gorm:"foreignKey:OrderID" json:"checklist,omitempty"` // production steps, attached when production starts
+	ChecklistProgress *int        `gorm:"-" json:"checklist_progress,omitempty"`          // computed field, percentage of checklist completed
+	Version      uint           `gorm:"not null;default:1" json:"version"` // incremented on every change, for If-Match preconditions
 	CreatedAt    time.Time      `json:"created_at"`
 	UpdatedAt    time.Time      `json:"updated_at"`
 	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
@@ -30,3 +68,67 @@ type Order struct {
 func (Order) TableName() string {
 	return "orders"
 }
+
+// IsGift reports whether the order was placed for someone other than the customer
+func (o *Order) IsGift() bool {
+	return o.RecipientEmail != nil
+}
+
+// DueBy returns the date the order is due: the technician's promise, or else the customer's requested date
+func (o *Order) DueBy() *time.Time {
+	if o.PromisedBy != nil {
+		return o.PromisedBy
+	}
+	return o.RequestedBy
+}
+
+// DeliveredTime returns when a delivered order reached the customer
+// Orders delivered before delivery times were recorded fall back to their shipping time, then their last update
+func (o *Order) DeliveredTime() time.Time {
+	if o.DeliveredAt != nil {
+		return *o.DeliveredAt
+	}
+	if o.ShippedAt != nil {
+		return *o.ShippedAt
+	}
+	return o.UpdatedAt
+}
+
+// BeforeSave writes the cents representation of Price while the price migration is dual-writing
+func (o *Order) BeforeSave(tx *gorm.DB) error {
+	if OrderPriceField.WritesNew() {
+		o.PriceCents = priceToCents(o.Price)
+		OrderPriceField.RecordWrite()
+	}
+	return nil
+}
+
+// AfterFind compares or serves Price from the cents column depending on the price migration stage
+// and computes the priority, and checklist progress from a preloaded checklist
+func (o *Order) AfterFind(tx *gorm.DB) error {
+	o.Priority = PriorityStandard
+	if o.Rush {
+		o.Priority = PriorityRush
+	}
+	o.ChecklistProgress = ChecklistProgress(o.Checklist)
+
+	switch {
+	case OrderPriceField.ReadsNew():
+		// Rows not yet backfilled keep their decimal price
+		if o.PriceCents != nil {
+			o.Price = centsToPrice(o.PriceCents)
+		}
+	case OrderPriceField.ShadowReads():
+		legacy := priceToCents(o.Price)
+		match := (legacy == nil && o.PriceCents == nil) ||
+			(legacy != nil && o.PriceCents != nil && *legacy == *o.PriceCents)
+		OrderPriceField.Observe(o.ID, formatCents(legacy), formatCents(o.PriceCents), match)
+	}
+	return nil
+}
+
+// BackfillOrderPriceCents populates price_cents for rows written before dual-write was enabled
+// Run it after switching to dual_write and before shadow_read so comparisons start clean
+func BackfillOrderPriceCents(db *gorm.DB) (int64, error) {
+	return BackfillPriceCents(db, OrderPriceColumn)
+}
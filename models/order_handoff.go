@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Hand-off statuses
+const (
+	HandoffPending  = "pending"
+	HandoffAccepted = "accepted"
+	HandoffDeclined = "declined"
+)
+
+// OrderHandoff is a request to transfer an order from its assigned technician to another
+// Answered hand-offs are kept so the order's history shows every technician that worked on it
+type OrderHandoff struct {
+	ID               uint       `gorm:"primaryKey" json:"id"`
+	OrderID          uint       `gorm:"not null;index" json:"order_id"`
+	Order            *Order     `gorm:"foreignKey:OrderID" json:"order,omitempty"` // loaded for incoming hand-offs only
+	FromTechnicianID uint       `gorm:"not null;index" json:"from_technician_id"`
+	FromTechnician   User       `gorm:"foreignKey:FromTechnicianID" json:"from_technician"`
+	ToTechnicianID   uint       `gorm:"not null;index" json:"to_technician_id"`
+	ToTechnician     User       `gorm:"foreignKey:ToTechnicianID" json:"to_technician"`
+	Status           string     `gorm:"not null;default:'pending';index" json:"status"` // pending, accepted, declined
+	Note             *string    `gorm:"type:text" json:"note"`                          // nullable, why the order is being handed off
+	RespondedAt      *time.Time `json:"responded_at"`                                   // nullable, set when accepted or declined
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for the OrderHandoff model
+func (OrderHandoff) TableName() string {
+	return "order_handoffs"
+}
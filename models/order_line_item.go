@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Line item kinds
+const (
+	LineItemBase            = "base"             // the nail set itself
+	LineItemAddOn           = "add_on"           // an item from the add-on catalog
+	LineItemRushFee         = "rush_fee"         // surcharge for expedited production
+	LineItemPriceAdjustment = "price_adjustment" // seasonal surcharge or discount from a price list
+)
+
+// OrderLineItem is one priced entry of an order's quote
+// Description and UnitPrice are copied at quote time so later catalog edits do not change past orders
+type OrderLineItem struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	OrderID        uint      `gorm:"not null;index" json:"order_id"`
+	Kind           string    `gorm:"not null" json:"kind"`   // base, add_on, rush_fee, price_adjustment
+	AddOnID        *uint     `gorm:"index" json:"add_on_id"` // set for add_on items
+	Description    string    `gorm:"not null" json:"description"`
+	Quantity       int       `gorm:"not null;check:quantity > 0" json:"quantity"`
+	UnitPrice      float64   `gorm:"not null" json:"unit_price"`
+	Amount         float64   `gorm:"not null" json:"amount"` // UnitPrice * Quantity
+	UnitPriceCents *int64    `json:"-"`                      // money representations of UnitPrice and Amount, populated via dual-write
+	AmountCents    *int64    `json:"-"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for the OrderLineItem model
+func (OrderLineItem) TableName() string {
+	return "order_line_items"
+}
+
+// BeforeSave writes the cents representations of the prices while their migrations are dual-writing
+func (i *OrderLineItem) BeforeSave(tx *gorm.DB) error {
+	i.UnitPriceCents = writeCents(LineItemUnitPriceField, i.UnitPrice, i.UnitPriceCents)
+	i.AmountCents = writeCents(LineItemAmountField, i.Amount, i.AmountCents)
+	return nil
+}
+
+// AfterFind compares or serves the prices from the cents columns depending on their migration stages
+func (i *OrderLineItem) AfterFind(tx *gorm.DB) error {
+	readCents(LineItemUnitPriceField, i.ID, &i.UnitPrice, i.UnitPriceCents)
+	readCents(LineItemAmountField, i.ID, &i.Amount, i.AmountCents)
+	return nil
+}
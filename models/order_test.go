@@ -0,0 +1,98 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/dualwrite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupOrderTestDB(t *testing.T) (*gorm.DB, User) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := MigrateUp(db); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	customer := User{Auth0ID: "auth0|customer", Name: "Customer", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	return db, customer
+}
+
+func TestOrderTableName(t *testing.T) {
+	assert.Equal(t, "orders", Order{}.TableName())
+}
+
+func TestOrderPriceDualWrite(t *testing.T) {
+	db, customer := setupOrderTestDB(t)
+	defer OrderPriceField.SetStage(dualwrite.StageOff)
+
+	price := 49.99
+
+	// Off: only the legacy column is written
+	OrderPriceField.SetStage(dualwrite.StageOff)
+	legacyOnly := Order{Description: "Legacy", Quantity: 1, CustomerID: customer.ID, Price: &price}
+	assert.NoError(t, db.Create(&legacyOnly).Error)
+	assert.Nil(t, legacyOnly.PriceCents)
+
+	// Dual write: both columns are written
+	OrderPriceField.SetStage(dualwrite.StageDualWrite)
+	dual := Order{Description: "Dual", Quantity: 1, CustomerID: customer.ID, Price: &price}
+	assert.NoError(t, db.Create(&dual).Error)
+	if assert.NotNil(t, dual.PriceCents) {
+		assert.Equal(t, int64(4999), *dual.PriceCents)
+	}
+
+	// Shadow read: a matching row is compared without a mismatch
+	OrderPriceField.SetStage(dualwrite.StageShadowRead)
+	mismatches := OrderPriceField.Counter("mismatches")
+	var loaded Order
+	assert.NoError(t, db.First(&loaded, dual.ID).Error)
+	assert.Equal(t, mismatches, OrderPriceField.Counter("mismatches"))
+
+	// Shadow read: a row written before dual-write is reported as a mismatch
+	var legacyLoaded Order
+	assert.NoError(t, db.First(&legacyLoaded, legacyOnly.ID).Error)
+	assert.Equal(t, mismatches+1, OrderPriceField.Counter("mismatches"))
+
+	// New: price is served from the cents column
+	OrderPriceField.SetStage(dualwrite.StageNew)
+	db.Model(&Order{}).Where("id = ?", dual.ID).UpdateColumn("price", 1.0)
+	var cutOver Order
+	assert.NoError(t, db.First(&cutOver, dual.ID).Error)
+	if assert.NotNil(t, cutOver.Price) {
+		assert.Equal(t, 49.99, *cutOver.Price)
+	}
+
+	// New: a row the backfill has not reached keeps its decimal price
+	var notBackfilled Order
+	assert.NoError(t, db.First(&notBackfilled, legacyOnly.ID).Error)
+	if assert.NotNil(t, notBackfilled.Price) {
+		assert.Equal(t, 49.99, *notBackfilled.Price)
+	}
+}
+
+func TestBackfillOrderPriceCents(t *testing.T) {
+	db, customer := setupOrderTestDB(t)
+	OrderPriceField.SetStage(dualwrite.StageOff)
+
+	price := 12.5
+	priced := Order{Description: "Priced", Quantity: 1, CustomerID: customer.ID, Price: &price}
+	unpriced := Order{Description: "Unpriced", Quantity: 1, CustomerID: customer.ID}
+	db.Create(&priced)
+	db.Create(&unpriced)
+
+	updated, err := BackfillOrderPriceCents(db)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), updated)
+
+	var reloaded Order
+	db.First(&reloaded, priced.ID)
+	if assert.NotNil(t, reloaded.PriceCents) {
+		assert.Equal(t, int64(1250), *reloaded.PriceCents)
+	}
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// OrderView is a named set of order list filters a user saved, applied with GET /orders?view=<id>
+// Filters left empty match every order, and a sort left empty uses the list's default
+type OrderView struct {
+	ID                uint       `gorm:"primaryKey" json:"id"`
+	UserID            uint       `gorm:"not null;index" json:"user_id"`
+	Name              string     `gorm:"not null" json:"name"`                               // unique among the user's views
+	Statuses          []string   `gorm:"type:text;serializer:json" json:"statuses"`          // only orders in these statuses
+	CreatedAfter      *time.Time `json:"created_after"`                                      // nullable, inclusive
+	CreatedBefore     *time.Time `json:"created_before"`                                     // nullable, exclusive
+	CreatedWithinDays *int       `json:"created_within_days"`                                // nullable, orders created today or in the days before, counted when the view is applied
+	Sort              string     `gorm:"not null;default:''" json:"sort"`                    // column to sort by, see repositories.OrderSortColumns
+	SortOrder         string     `gorm:"column:sort_order;not null;default:''" json:"order"` // "asc" or "desc"
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for the OrderView model
+func (OrderView) TableName() string {
+	return "order_views"
+}
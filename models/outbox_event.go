@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// OutboxEvent is a domain event written in the same transaction as the change it announces
+// The outbox relay publishes it to the event bus afterwards and retries with backoff, so that an event
+// is not lost when the process stops between saving the change and publishing it
+type OutboxEvent struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	Name          string     `gorm:"not null" json:"name"`              // e.g. "order.created"
+	Payload       string     `gorm:"type:text;not null" json:"payload"` // the event encoded as JSON
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time  `gorm:"not null;index" json:"next_attempt_at"`
+	LastError     *string    `json:"last_error"`                // nullable
+	PublishedAt   *time.Time `gorm:"index" json:"published_at"` // nullable, unset until the relay published the event
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for the OutboxEvent model
+func (OutboxEvent) TableName() string {
+	return "outbox_events"
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/utils"
+	"gorm.io/gorm"
+)
+
+// Payout records an admin paying a technician for their orders delivered in a period, in one currency
+// The paid orders point back at it through orders.payout_id, so each order is paid at most once
+type Payout struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	TechnicianID uint      `gorm:"not null;index" json:"technician_id"`
+	Currency     string    `gorm:"size:3;not null" json:"currency"`
+	AmountCents  int64     `gorm:"not null" json:"-"`
+	Amount       float64   `gorm:"-" json:"amount"`              // computed field, AmountCents as a decimal amount
+	OrderCount   int       `gorm:"not null" json:"order_count"`  // orders paid by this payout
+	PeriodStart  time.Time `gorm:"not null" json:"period_start"` // the paid orders were delivered in [PeriodStart, PeriodEnd)
+	PeriodEnd    time.Time `gorm:"not null" json:"period_end"`
+	Reference    *string   `json:"reference"`                        // nullable, the admin's note such as a bank transfer ID
+	PaidByID     uint      `gorm:"not null;index" json:"paid_by_id"` // the admin who recorded the payout
+	CreatedAt    time.Time `json:"created_at"`                       // when the payout was recorded
+}
+
+// TableName specifies the table name for the Payout model
+func (Payout) TableName() string {
+	return "payouts"
+}
+
+// AfterFind computes Amount from the stored cents
+func (p *Payout) AfterFind(tx *gorm.DB) error {
+	p.Amount = utils.FromCents(p.AmountCents)
+	return nil
+}
@@ -0,0 +1,193 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/kendall-kelly/kendalls-nails-api/utils"
+	"gorm.io/gorm"
+)
+
+// Columns bound into the ciphertext of each encrypted user field
+const (
+	userNameColumn  = "users.name"
+	userEmailColumn = "users.email"
+)
+
+// piiCipher encrypts user names and emails at rest; nil leaves them in plaintext
+var piiCipher *utils.FieldCipher
+
+// SetPIICipher turns on encryption of user names and emails with the given cipher, or off with nil
+// Rows are always readable in either state as long as the cipher that wrote them is set
+func SetPIICipher(cipher *utils.FieldCipher) {
+	piiCipher = cipher
+}
+
+// errPIIKeyMissing is returned when an encrypted row is read without PII_ENCRYPTION_KEY
+var errPIIKeyMissing = errors.New("user fields are encrypted but PII_ENCRYPTION_KEY is not set")
+
+// WhereEmail scopes a user query to a normalized email
+// Encrypted rows are matched on their blind index, and rows not yet encrypted on the email itself
+func WhereEmail(email string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if piiCipher == nil {
+			return db.Where("LOWER(email) = ?", email)
+		}
+		return db.Where("(email_hash = ? OR (email_hash IS NULL AND LOWER(email) = ?))", piiCipher.BlindIndex(email), email)
+	}
+}
+
+// storedEmailColumns returns the column values that store an email, encrypted with its blind index when encryption is on
+// They are meant for UpdateColumns, which bypasses the User hooks
+func storedEmailColumns(email string) (map[string]interface{}, error) {
+	if piiCipher == nil {
+		return map[string]interface{}{"email": email}, nil
+	}
+	encrypted, err := piiCipher.Encrypt(userEmailColumn, email)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"email": encrypted, "email_hash": piiCipher.BlindIndex(email)}, nil
+}
+
+// encryptPII replaces the user's name and email with their ciphertext, ready to be written
+func (u *User) encryptPII() error {
+	if piiCipher == nil {
+		return nil
+	}
+	if u.Email != "" && !utils.IsEncrypted(u.Email) {
+		hash := piiCipher.BlindIndex(u.Email)
+		encrypted, err := piiCipher.Encrypt(userEmailColumn, u.Email)
+		if err != nil {
+			return err
+		}
+		u.Email, u.EmailHash = encrypted, &hash
+	}
+	if u.Name != "" && !utils.IsEncrypted(u.Name) {
+		encrypted, err := piiCipher.Encrypt(userNameColumn, u.Name)
+		if err != nil {
+			return err
+		}
+		u.Name = encrypted
+	}
+	return nil
+}
+
+// decryptPII restores the user's name and email to plaintext after a read or write
+func (u *User) decryptPII() error {
+	if !utils.IsEncrypted(u.Email) && !utils.IsEncrypted(u.Name) {
+		return nil
+	}
+	if piiCipher == nil {
+		return errPIIKeyMissing
+	}
+	email, err := piiCipher.Decrypt(userEmailColumn, u.Email)
+	if err != nil {
+		return fmt.Errorf("user %d: %w", u.ID, err)
+	}
+	name, err := piiCipher.Decrypt(userNameColumn, u.Name)
+	if err != nil {
+		return fmt.Errorf("user %d: %w", u.ID, err)
+	}
+	u.Email, u.Name = email, name
+	return nil
+}
+
+// encryptPIIUpdates encrypts the name and email in a map passed to Updates, which the struct hooks do not see
+func encryptPIIUpdates(updates map[string]interface{}) error {
+	if piiCipher == nil {
+		return nil
+	}
+	if email, ok := updates["email"].(string); ok && email != "" && !utils.IsEncrypted(email) {
+		columns, err := storedEmailColumns(utils.NormalizeEmail(email))
+		if err != nil {
+			return err
+		}
+		for column, value := range columns {
+			updates[column] = value
+		}
+	}
+	if name, ok := updates["name"].(string); ok && name != "" && !utils.IsEncrypted(name) {
+		encrypted, err := piiCipher.Encrypt(userNameColumn, name)
+		if err != nil {
+			return err
+		}
+		updates["name"] = encrypted
+	}
+	return nil
+}
+
+// rawUserPII is a user's name and email as stored, read without the User hooks
+type rawUserPII struct {
+	ID    uint
+	Name  string
+	Email string
+}
+
+// EncryptUserPII encrypts the names and emails of every user still stored in plaintext, including deleted accounts
+// It returns how many users were encrypted
+func EncryptUserPII(db *gorm.DB) (int, error) {
+	var encrypted int
+	var afterID uint
+	for {
+		batch, err := EncryptUserPIIBatch(db, afterID, 500)
+		if err != nil {
+			return encrypted, err
+		}
+		encrypted += batch.Updated
+		if batch.Visited == 0 {
+			return encrypted, nil
+		}
+		afterID = batch.LastID
+	}
+}
+
+// EncryptUserPIIBatch encrypts the names and emails of up to limit users with an ID above afterID
+// It is the resumable form of EncryptUserPII; users already encrypted are left as they are
+func EncryptUserPIIBatch(db *gorm.DB, afterID uint, limit int) (BackfillBatch, error) {
+	var batch BackfillBatch
+	if piiCipher == nil {
+		return batch, errors.New("PII_ENCRYPTION_KEY is not set")
+	}
+
+	// Read the stored values directly, since the User hooks would hand back plaintext either way
+	var users []rawUserPII
+	if err := db.Table("users").Select("id", "name", "email").Where("id > ?", afterID).Order("id").Limit(limit).Scan(&users).Error; err != nil {
+		return batch, fmt.Errorf("failed to load users: %w", err)
+	}
+	if len(users) == 0 {
+		return batch, nil
+	}
+	batch.LastID = users[len(users)-1].ID
+	batch.Visited = len(users)
+
+	for _, user := range users {
+		encryptEmail := user.Email != "" && !utils.IsEncrypted(user.Email)
+		encryptName := user.Name != "" && !utils.IsEncrypted(user.Name)
+		if !encryptEmail && !encryptName {
+			continue
+		}
+		columns := map[string]interface{}{}
+		if encryptEmail {
+			email, err := storedEmailColumns(user.Email)
+			if err != nil {
+				return batch, fmt.Errorf("failed to encrypt email for user %d: %w", user.ID, err)
+			}
+			columns = email
+		}
+		if encryptName {
+			name, err := piiCipher.Encrypt(userNameColumn, user.Name)
+			if err != nil {
+				return batch, fmt.Errorf("failed to encrypt name for user %d: %w", user.ID, err)
+			}
+			columns["name"] = name
+		}
+
+		// UpdateColumns skips hooks and timestamps; this is a data fix, not a profile edit
+		if err := db.Table("users").Where("id = ?", user.ID).UpdateColumns(columns).Error; err != nil {
+			return batch, fmt.Errorf("failed to encrypt user %d: %w", user.ID, err)
+		}
+		batch.Updated++
+	}
+	return batch, nil
+}
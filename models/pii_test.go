@@ -0,0 +1,104 @@
+package models
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/utils"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// enablePIIEncryption turns on PII encryption for the rest of the test
+func enablePIIEncryption(t *testing.T) {
+	cipher, err := utils.NewFieldCipher(bytes.Repeat([]byte{7}, 32))
+	assert.NoError(t, err)
+	SetPIICipher(cipher)
+	t.Cleanup(func() { SetPIICipher(nil) })
+}
+
+// storedUserPII reads a user's name and email as stored, bypassing the hooks
+func storedUserPII(db *gorm.DB, id uint) rawUserPII {
+	var row rawUserPII
+	db.Table("users").Select("id", "name", "email").Where("id = ?", id).Scan(&row)
+	return row
+}
+
+func TestUserPIIEncryption(t *testing.T) {
+	db, _ := setupOrderTestDB(t)
+	enablePIIEncryption(t)
+
+	user := User{Auth0ID: "auth0|jane", Name: "Jane Doe", Email: " Jane@Example.com", Role: "customer"}
+	assert.NoError(t, db.Create(&user).Error)
+	assert.Equal(t, "Jane Doe", user.Name)
+	assert.Equal(t, "jane@example.com", user.Email)
+
+	stored := storedUserPII(db, user.ID)
+	assert.True(t, utils.IsEncrypted(stored.Name))
+	assert.True(t, utils.IsEncrypted(stored.Email))
+	assert.NotContains(t, stored.Email, "jane")
+
+	// Reads decrypt, and emails are found by their blind index
+	var found User
+	assert.NoError(t, db.Scopes(WhereEmail("jane@example.com")).First(&found).Error)
+	assert.Equal(t, user.ID, found.ID)
+	assert.Equal(t, "Jane Doe", found.Name)
+
+	// Map updates are encrypted too, and move the blind index with the email
+	assert.NoError(t, db.Model(&found).Updates(map[string]interface{}{"name": "Jane Roe", "email": "jane.roe@example.com"}).Error)
+	assert.Equal(t, "Jane Roe", found.Name)
+	assert.True(t, utils.IsEncrypted(storedUserPII(db, user.ID).Name))
+	assert.Error(t, db.Scopes(WhereEmail("jane@example.com")).First(&User{}).Error)
+	assert.NoError(t, db.Scopes(WhereEmail("jane.roe@example.com")).First(&User{}).Error)
+
+	// So are updates limited to the email
+	found.Email = "jr@example.com"
+	assert.NoError(t, db.Model(&found).Select("email").Updates(&found).Error)
+	assert.NoError(t, db.Scopes(WhereEmail("jr@example.com")).First(&User{}).Error)
+
+	// Encrypted emails stay unique among active accounts
+	assert.Error(t, db.Create(&User{Auth0ID: "auth0|jane2", Name: "Jane", Email: "JR@example.com", Role: "customer"}).Error)
+
+	// Without the key encrypted rows cannot be read
+	SetPIICipher(nil)
+	assert.Error(t, db.First(&User{}, user.ID).Error)
+}
+
+func TestEncryptUserPIIBatch(t *testing.T) {
+	db, customer := setupOrderTestDB(t)
+
+	// Rows written before encryption was turned on
+	legacy := User{Auth0ID: "auth0|legacy", Name: "Sam", Email: "sam@example.com", Role: "technician"}
+	db.Create(&legacy)
+	deleted := User{Auth0ID: "auth0|deleted", Name: "Casey", Email: "casey@example.com", Role: "customer"}
+	db.Create(&deleted)
+	db.Delete(&deleted)
+
+	enablePIIEncryption(t)
+	batch, err := EncryptUserPIIBatch(db, 0, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, BackfillBatch{LastID: legacy.ID, Visited: 2, Updated: 2}, batch)
+
+	// Rows not yet encrypted are still found by email
+	var found User
+	assert.NoError(t, db.Unscoped().Scopes(WhereEmail("casey@example.com")).First(&found).Error)
+	assert.Equal(t, deleted.ID, found.ID)
+
+	encrypted, err := EncryptUserPII(db)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, encrypted)
+
+	for _, user := range []User{customer, legacy, deleted} {
+		stored := storedUserPII(db, user.ID)
+		assert.True(t, utils.IsEncrypted(stored.Name))
+		assert.True(t, utils.IsEncrypted(stored.Email))
+	}
+	var sam User
+	assert.NoError(t, db.Scopes(WhereEmail("sam@example.com")).First(&sam).Error)
+	assert.Equal(t, "Sam", sam.Name)
+
+	// Encrypted rows are left alone
+	encrypted, err = EncryptUserPII(db)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, encrypted)
+}
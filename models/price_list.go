@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PriceList is a technician's temporary price change, such as a holiday surcharge or a summer sale
+// The list in force when an order is accepted adds a price adjustment line item to the quote.
+// A list cannot be changed once it has started, so orders keep referencing the terms they were quoted under
+type PriceList struct {
+	ID                uint           `gorm:"primaryKey" json:"id"`
+	TechnicianID      uint           `gorm:"not null;index" json:"technician_id"`
+	Name              string         `gorm:"not null" json:"name"`
+	AdjustmentPercent float64        `gorm:"not null" json:"adjustment_percent"` // e.g. 20 for a 20% surcharge, -15 for 15% off
+	StartsAt          time.Time      `gorm:"not null;index" json:"starts_at"`
+	EndsAt            time.Time      `gorm:"not null;index" json:"ends_at"` // exclusive
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName specifies the table name for the PriceList model
+func (PriceList) TableName() string {
+	return "price_lists"
+}
+
+// InForce reports whether the price list applies at the given time
+func (p *PriceList) InForce(at time.Time) bool {
+	return !at.Before(p.StartsAt) && at.Before(p.EndsAt)
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ProgressUpdate is a captioned photo a technician posts to an order's customer timeline
+// Updates are kept apart from the order conversation so the timeline only holds curated photos
+type ProgressUpdate struct {
+	ID         uint           `gorm:"primaryKey" json:"id"`
+	OrderID    uint           `gorm:"not null;index" json:"order_id"`
+	AuthorID   uint           `gorm:"not null;index" json:"author_id"`
+	Author     User           `gorm:"foreignKey:AuthorID" json:"author"`
+	Caption    string         `gorm:"type:text;not null;default:''" json:"caption"`
+	ImageS3Key string         `gorm:"not null" json:"image_s3_key"`
+	ImageURL   *string        `gorm:"-" json:"image_url,omitempty"` // computed field, presigned URL for image
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName specifies the table name for the ProgressUpdate model
+func (ProgressUpdate) TableName() string {
+	return "progress_updates"
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Quote request statuses
+const (
+	QuotePending   = "pending"
+	QuoteQuoted    = "quoted"
+	QuoteDeclined  = "declined"
+	QuoteConverted = "converted" // the visitor signed up and the request became an order
+)
+
+// QuoteRequest is a design a visitor without an account asked to have priced
+// A technician quotes or declines it, and converts it to an order once the visitor signs up with the same email
+type QuoteRequest struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	GuestName      string     `gorm:"not null" json:"guest_name"`
+	GuestEmail     string     `gorm:"not null;index" json:"guest_email"` // normalized email the visitor will sign up with
+	Description    string     `gorm:"not null" json:"description"`
+	Quantity       int        `gorm:"not null;check:quantity > 0" json:"quantity"`
+	ImageS3Key     *string    `json:"image_s3_key"`                                   // nullable, S3 key for uploaded image
+	ImageURL       *string    `gorm:"-" json:"image_url,omitempty"`                   // computed field, presigned URL for image
+	ImageStatus    *string    `gorm:"-" json:"image_status,omitempty"`                // computed field, moderation status while the image is held back
+	Status         string     `gorm:"not null;default:'pending';index" json:"status"` // pending, quoted, declined, converted
+	TechnicianID   *uint      `gorm:"index" json:"technician_id"`                     // nullable, the technician who reviewed the request
+	QuotedPrice    *float64   `json:"quoted_price"`                                   // nullable, set when the request is quoted
+	Currency       string     `gorm:"size:3;not null;default:'USD'" json:"currency"`
+	TechnicianNote *string    `json:"technician_note"` // nullable, the technician's note on the quote or decline
+	ReviewedAt     *time.Time `json:"reviewed_at"`
+	OrderID        *uint      `gorm:"index" json:"order_id,omitempty"` // nullable, the order created when the request was converted
+	ConvertedAt    *time.Time `json:"converted_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for the QuoteRequest model
+func (QuoteRequest) TableName() string {
+	return "quote_requests"
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Recommendation is a delivered design suggested to a customer, precomputed by the recommendations job
+// A customer's recommendations are replaced as a whole every time the job runs
+type Recommendation struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	CustomerID uint      `gorm:"not null;index" json:"customer_id"`
+	OrderID    uint      `gorm:"not null;index" json:"order_id"` // the delivered order whose design is suggested
+	Order      Order     `gorm:"foreignKey:OrderID" json:"-"`
+	Score      float64   `gorm:"not null" json:"score"`    // similarity to the customer's own delivered orders, 0 to 1
+	Position   int       `gorm:"not null" json:"position"` // 1 is the best match
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for the Recommendation model
+func (Recommendation) TableName() string {
+	return "recommendations"
+}
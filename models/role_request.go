@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Role request statuses
+const (
+	RoleRequestPending  = "pending"
+	RoleRequestApproved = "approved"
+	RoleRequestRejected = "rejected"
+)
+
+// RoleRequest is a customer's application to become a nail technician, reviewed by an admin
+type RoleRequest struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	UserID        uint       `gorm:"not null;index" json:"user_id"`
+	User          *User      `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	RequestedRole string     `gorm:"not null" json:"requested_role"`
+	Message       string     `gorm:"type:text;not null" json:"message"`               // the applicant's experience and why they want to join
+	PortfolioURLs []string   `gorm:"type:text;serializer:json" json:"portfolio_urls"` // links to the applicant's work, such as Instagram or a website
+	Status        string     `gorm:"not null;default:'pending';index" json:"status"`  // pending, approved, rejected
+	ReviewerID    *uint      `gorm:"index" json:"reviewer_id,omitempty"`              // nullable, the admin who decided the request
+	ReviewNote    *string    `json:"review_note,omitempty"`                           // nullable, the admin's reason, required when rejecting
+	ReviewedAt    *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for the RoleRequest model
+func (RoleRequest) TableName() string {
+	return "role_requests"
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SavedDesign is a description and reference image a customer saved to order again
+type SavedDesign struct {
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	CustomerID  uint           `gorm:"not null;index" json:"customer_id"`
+	Description string         `gorm:"type:text;not null" json:"description"`
+	ImageS3Key  *string        `json:"image_s3_key"`                    // nullable, S3 key of the reference image
+	ImageURL    *string        `gorm:"-" json:"image_url,omitempty"`    // computed field, presigned URL for image
+	OrderID     *uint          `gorm:"index" json:"order_id,omitempty"` // nullable, the order the design was saved from
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName specifies the table name for the SavedDesign model
+func (SavedDesign) TableName() string {
+	return "saved_designs"
+}
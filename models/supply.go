@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Supply is a material the studio keeps in stock to make orders, such as gel polish or nail tips
+type Supply struct {
+	ID                uint           `gorm:"primaryKey" json:"id"`
+	Name              string         `gorm:"not null" json:"name"`
+	Unit              string         `gorm:"not null" json:"unit"`                                                         // what Quantity counts, e.g. "ml" or "tips"
+	Quantity          float64        `gorm:"not null;default:0;check:quantity >= 0" json:"quantity"`                       // in stock
+	LowStockThreshold float64        `gorm:"not null;default:0;check:low_stock_threshold >= 0" json:"low_stock_threshold"` // at or below this the supply is low
+	LowStock          bool           `gorm:"-" json:"low_stock"`                                                           // computed field, stock is at or below the threshold
+	CreatedByID       uint           `gorm:"not null;index" json:"created_by_id"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName specifies the table name for the Supply model
+func (Supply) TableName() string {
+	return "supplies"
+}
+
+// AfterFind computes whether a loaded supply is low
+func (s *Supply) AfterFind(tx *gorm.DB) error {
+	s.LowStock = s.Quantity <= s.LowStockThreshold
+	return nil
+}
+
+// AfterSave computes whether a saved supply is low
+func (s *Supply) AfterSave(tx *gorm.DB) error {
+	s.LowStock = s.Quantity <= s.LowStockThreshold
+	return nil
+}
+
+// SupplyUsage records an amount of a supply consumed making an order
+type SupplyUsage struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	OrderID      uint      `gorm:"not null;index" json:"order_id"`
+	SupplyID     uint      `gorm:"not null;index" json:"supply_id"`
+	Supply       Supply    `gorm:"foreignKey:SupplyID" json:"supply"`
+	Quantity     float64   `gorm:"not null;check:quantity > 0" json:"quantity"` // in the supply's unit
+	RecordedByID uint      `gorm:"not null;index" json:"recorded_by_id"`        // technician who used it
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for the SupplyUsage model
+func (SupplyUsage) TableName() string {
+	return "supply_usages"
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// TechnicianSettings holds a technician's capacity and availability
+// Technicians without settings take any number of orders and are available every day
+type TechnicianSettings struct {
+	ID                  uint       `gorm:"primaryKey" json:"-"`
+	UserID              uint       `gorm:"not null;uniqueIndex" json:"user_id"`
+	MaxConcurrentOrders int        `gorm:"not null;default:0" json:"max_concurrent_orders"` // open orders the technician takes at once; 0 means no limit
+	WorkingDays         []string   `gorm:"type:text;serializer:json" json:"working_days"`   // lowercase weekday names such as "monday"
+	Vacations           []Vacation `gorm:"type:text;serializer:json" json:"vacations"`      // days off, in addition to non-working days
+	CalendarTokenHash   *string    `gorm:"uniqueIndex" json:"-"`                            // nullable, hash of the secret in the technician's calendar feed URL
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for the TechnicianSettings model
+func (TechnicianSettings) TableName() string {
+	return "technician_settings"
+}
+
+// Vacation is a range of whole UTC days off, both ends included
+type Vacation struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Weekdays lists the names accepted as working days, Sunday first like time.Weekday
+var Weekdays = []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"}
+
+// WorksOn reports whether the day is one of the technician's working days
+func (s *TechnicianSettings) WorksOn(day time.Time) bool {
+	weekday := Weekdays[day.UTC().Weekday()]
+	for _, working := range s.WorkingDays {
+		if strings.EqualFold(working, weekday) {
+			return true
+		}
+	}
+	return false
+}
+
+// OnVacation reports whether the day falls in one of the technician's vacations
+func (s *TechnicianSettings) OnVacation(day time.Time) bool {
+	year, month, date := day.UTC().Date()
+	midnight := time.Date(year, month, date, 0, 0, 0, 0, time.UTC)
+	for _, vacation := range s.Vacations {
+		if !midnight.Before(vacation.Start) && !midnight.After(vacation.End) {
+			return true
+		}
+	}
+	return false
+}
+
+// AvailableOn reports whether the technician works on the day and is not on vacation
+func (s *TechnicianSettings) AvailableOn(day time.Time) bool {
+	return s.WorksOn(day) && !s.OnVacation(day)
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTechnicianSettings_AvailableOn(t *testing.T) {
+	settings := TechnicianSettings{
+		WorkingDays: []string{"monday", "Tuesday", "friday"},
+		Vacations: []Vacation{
+			{Start: time.Date(2026, 7, 6, 0, 0, 0, 0, time.UTC), End: time.Date(2026, 7, 10, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+
+	monday := time.Date(2026, 6, 29, 15, 0, 0, 0, time.UTC)
+	assert.True(t, settings.AvailableOn(monday))
+	assert.True(t, settings.AvailableOn(monday.AddDate(0, 0, 1)))
+	assert.False(t, settings.AvailableOn(monday.AddDate(0, 0, 2)), "wednesday is not a working day")
+
+	// Vacations include both their first and last day
+	assert.False(t, settings.AvailableOn(monday.AddDate(0, 0, 7)))
+	assert.False(t, settings.AvailableOn(time.Date(2026, 7, 10, 23, 59, 0, 0, time.UTC)))
+	assert.True(t, settings.AvailableOn(monday.AddDate(0, 0, 14)))
+}
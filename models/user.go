@@ -1,18 +1,40 @@
 package models
 
 import (
+	"fmt"
+	"log"
 	"time"
 
+	"github.com/kendall-kelly/kendalls-nails-api/utils"
 	"gorm.io/gorm"
 )
 
+// Unique indexes on users; both cover active accounts only, so a soft-deleted
+// account does not keep its email or identity from being registered again
+const (
+	userAuth0IDIndex   = "idx_users_auth0_id_active"
+	userEmailIndex     = "idx_users_email_active"      // case-insensitive
+	userEmailHashIndex = "idx_users_email_hash_active" // blind index of encrypted emails
+)
+
+// legacyUserEmailIndexes are earlier email indexes that also covered deleted accounts
+var legacyUserEmailIndexes = []string{"idx_users_email", "idx_users_email_lower"}
+
+// legacyUserAuth0IDIndex is the earlier identity index that also covered deleted accounts
+const legacyUserAuth0IDIndex = "idx_users_auth0_id"
+
 // User represents a user in the system (customer or technician)
 type User struct {
 	ID        uint           `gorm:"primaryKey" json:"id"`
-	Auth0ID   string         `gorm:"uniqueIndex;not null" json:"auth0_id"` // Auth0 user ID (from 'sub' claim)
+	Auth0ID   string         `gorm:"not null" json:"auth0_id"` // Auth0 user ID (from 'sub' claim), unique among active accounts
 	Name      string         `gorm:"not null" json:"name"`
-	Email     string         `gorm:"uniqueIndex;not null" json:"email"`
+	Email     string         `gorm:"not null" json:"email"`                   // stored normalized, see utils.NormalizeEmail; encrypted at rest with the name when PII_ENCRYPTION_KEY is set
+	EmailHash *string        `gorm:"size:64" json:"-"`                        // blind index of the encrypted email, for lookups; nil while stored in plaintext
 	Role      string         `gorm:"not null;default:'customer'" json:"role"` // "customer" or "technician"
+	Locale    string         `gorm:"not null;default:'en-US'" json:"locale"`  // BCP 47 tag, detected on signup
+	SizeUnit  string         `gorm:"not null;default:'in'" json:"size_unit"`  // "mm" or "in" for nail sizes, detected on signup
+	BannedAt  *time.Time     `json:"banned_at,omitempty"`                     // set while an admin has banned the account; its tokens are rejected
+	BanReason *string        `gorm:"size:500" json:"ban_reason,omitempty"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
@@ -22,3 +44,191 @@ type User struct {
 func (User) TableName() string {
 	return "users"
 }
+
+// IsBanned reports whether an admin has banned the account
+func (u *User) IsBanned() bool {
+	return u.BannedAt != nil
+}
+
+// BeforeSave normalizes the email so that differently cased addresses map to one account,
+// then encrypts the name and email when PII encryption is on
+func (u *User) BeforeSave(tx *gorm.DB) error {
+	if updates, ok := tx.Statement.Dest.(map[string]interface{}); ok {
+		return encryptPIIUpdates(updates)
+	}
+	if !utils.IsEncrypted(u.Email) {
+		u.Email = utils.NormalizeEmail(u.Email)
+	}
+	if err := u.encryptPII(); err != nil {
+		return err
+	}
+	// An update limited to the email must also write its blind index
+	for _, column := range tx.Statement.Selects {
+		if column == "email" || column == "Email" {
+			tx.Statement.Selects = append(tx.Statement.Selects, "email_hash")
+			break
+		}
+	}
+	return nil
+}
+
+// AfterSave hands the caller back the plaintext name and email that BeforeSave encrypted
+func (u *User) AfterSave(tx *gorm.DB) error {
+	return u.decryptPII()
+}
+
+// AfterFind decrypts the name and email of users stored encrypted
+func (u *User) AfterFind(tx *gorm.DB) error {
+	return u.decryptPII()
+}
+
+// EmailBackfillResult summarizes a run of NormalizeUserEmails
+type EmailBackfillResult struct {
+	Updated   int               // rows whose email was rewritten
+	Conflicts map[string][]uint // normalized email => IDs of the accounts that share it
+}
+
+// NormalizeUserEmails rewrites stored emails into their normalized form
+// Accounts whose emails normalize to the same address are left untouched and
+// reported as conflicts, since they need to be merged by hand
+func NormalizeUserEmails(db *gorm.DB) (EmailBackfillResult, error) {
+	result := EmailBackfillResult{Conflicts: make(map[string][]uint)}
+
+	var users []User
+	if err := db.Select("id", "email").Find(&users).Error; err != nil {
+		return result, fmt.Errorf("failed to load users: %w", err)
+	}
+
+	byEmail := make(map[string][]User)
+	for _, user := range users {
+		normalized := utils.NormalizeEmail(user.Email)
+		byEmail[normalized] = append(byEmail[normalized], user)
+	}
+
+	for normalized, group := range byEmail {
+		if len(group) > 1 {
+			for _, user := range group {
+				result.Conflicts[normalized] = append(result.Conflicts[normalized], user.ID)
+			}
+			continue
+		}
+		if group[0].Email == normalized {
+			continue
+		}
+		if err := updateStoredEmail(db, group[0].ID, normalized); err != nil {
+			return result, fmt.Errorf("failed to normalize email for user %d: %w", group[0].ID, err)
+		}
+		result.Updated++
+	}
+
+	return result, nil
+}
+
+// NormalizeUserEmailsBatch rewrites the emails of up to limit users with an ID above afterID into their normalized form
+// It is the resumable form of NormalizeUserEmails; an account whose normalized email is already
+// used by another account is skipped rather than merged
+func NormalizeUserEmailsBatch(db *gorm.DB, afterID uint, limit int) (BackfillBatch, error) {
+	var batch BackfillBatch
+	var users []User
+	if err := db.Select("id", "email").Where("id > ?", afterID).Order("id").Limit(limit).Find(&users).Error; err != nil {
+		return batch, fmt.Errorf("failed to load users: %w", err)
+	}
+	if len(users) == 0 {
+		return batch, nil
+	}
+	batch.LastID = users[len(users)-1].ID
+	batch.Visited = len(users)
+
+	for _, user := range users {
+		normalized := utils.NormalizeEmail(user.Email)
+		if user.Email == normalized {
+			continue
+		}
+
+		var others int64
+		if err := db.Model(&User{}).Scopes(WhereEmail(normalized)).Where("id <> ?", user.ID).Count(&others).Error; err != nil {
+			return batch, fmt.Errorf("failed to check email for user %d: %w", user.ID, err)
+		}
+		if others > 0 {
+			log.Printf("Skipping email normalization for user %d: %s is used by another account", user.ID, normalized)
+			batch.Skipped++
+			continue
+		}
+
+		if err := updateStoredEmail(db, user.ID, normalized); err != nil {
+			return batch, fmt.Errorf("failed to normalize email for user %d: %w", user.ID, err)
+		}
+		batch.Updated++
+	}
+	return batch, nil
+}
+
+// updateStoredEmail rewrites a user's email, encrypting it if PII encryption is on
+func updateStoredEmail(db *gorm.DB, id uint, email string) error {
+	columns, err := storedEmailColumns(email)
+	if err != nil {
+		return err
+	}
+	// UpdateColumns skips hooks and timestamps; this is a data fix, not a profile edit
+	return db.Model(&User{}).Where("id = ?", id).UpdateColumns(columns).Error
+}
+
+// ensureUserIndexes replaces the unique indexes on users with ones that ignore deleted accounts
+// Each legacy index is dropped only once its replacement exists, so uniqueness is never unenforced
+func ensureUserIndexes(db *gorm.DB) error {
+	if err := db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS " + userAuth0IDIndex + " ON users (auth0_id) WHERE deleted_at IS NULL").Error; err != nil {
+		return fmt.Errorf("failed to create %s: %w", userAuth0IDIndex, err)
+	}
+	if err := dropUserIndex(db, legacyUserAuth0IDIndex); err != nil {
+		return err
+	}
+
+	// Encrypted emails are unique by their blind index; the plaintext index below covers rows not yet encrypted
+	if err := db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS " + userEmailHashIndex + " ON users (email_hash) WHERE deleted_at IS NULL").Error; err != nil {
+		return fmt.Errorf("failed to create %s: %w", userEmailHashIndex, err)
+	}
+	if err := ensureUserEmailIndex(db); err != nil {
+		return err
+	}
+	if !db.Migrator().HasIndex(&User{}, userEmailIndex) {
+		return nil
+	}
+	for _, name := range legacyUserEmailIndexes {
+		if err := dropUserIndex(db, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropUserIndex drops an index on users if it exists
+func dropUserIndex(db *gorm.DB, name string) error {
+	if !db.Migrator().HasIndex(&User{}, name) {
+		return nil
+	}
+	if err := db.Migrator().DropIndex(&User{}, name); err != nil {
+		return fmt.Errorf("failed to drop %s: %w", name, err)
+	}
+	return nil
+}
+
+// ensureUserEmailIndex creates the case-insensitive unique index on the emails of active accounts
+// If existing rows would violate it, the index is skipped with a warning so that
+// startup is not blocked; run "migrate normalize-emails" to find the duplicates
+func ensureUserEmailIndex(db *gorm.DB) error {
+	var duplicates int64
+	if err := db.Raw(
+		"SELECT COUNT(*) FROM (SELECT LOWER(email) FROM users WHERE deleted_at IS NULL GROUP BY LOWER(email) HAVING COUNT(*) > 1) AS duplicates",
+	).Scan(&duplicates).Error; err != nil {
+		return fmt.Errorf("failed to check for duplicate emails: %w", err)
+	}
+	if duplicates > 0 {
+		log.Printf("WARNING: %d emails are shared by multiple accounts ignoring case; skipping %s until they are merged (see `migrate normalize-emails`)", duplicates, userEmailIndex)
+		return nil
+	}
+
+	if err := db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS " + userEmailIndex + " ON users (LOWER(email)) WHERE deleted_at IS NULL").Error; err != nil {
+		return fmt.Errorf("failed to create %s: %w", userEmailIndex, err)
+	}
+	return nil
+}
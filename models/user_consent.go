@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// UserConsent records that a user accepted a version of the terms of service
+// Rows are never changed, so they form the user's consent history
+type UserConsent struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	UserID       uint      `gorm:"not null;uniqueIndex:idx_user_consents_user_version" json:"user_id"`
+	TermsVersion string    `gorm:"size:10;not null;uniqueIndex:idx_user_consents_user_version" json:"terms_version"` // effective date of the accepted terms, YYYY-MM-DD
+	AcceptedAt   time.Time `gorm:"not null" json:"accepted_at"`
+	IPAddress    *string   `json:"ip_address,omitempty"` // nullable, where the acceptance came from
+	UserAgent    *string   `json:"user_agent,omitempty"` // nullable, the client the terms were accepted in
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for the UserConsent model
+func (UserConsent) TableName() string {
+	return "user_consents"
+}
@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
 )
 
 func TestUserTableName(t *testing.T) {
@@ -50,3 +52,78 @@ func TestUserRoleValues(t *testing.T) {
 		})
 	}
 }
+
+func TestUserEmailNormalizedOnSave(t *testing.T) {
+	db, _ := setupOrderTestDB(t)
+
+	user := User{Auth0ID: "auth0|jane", Name: "Jane", Email: "  Jane@Example.com ", Role: "customer"}
+	assert.NoError(t, db.Create(&user).Error)
+	assert.Equal(t, "jane@example.com", user.Email)
+
+	// The case-insensitive index rejects rows written around the hook
+	err := db.Exec("INSERT INTO users (auth0_id, name, email, role) VALUES (?, ?, ?, ?)",
+		"auth0|jane2", "Jane", "JANE@example.com", "customer").Error
+	assert.Error(t, err)
+}
+
+func TestNormalizeUserEmails(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.AutoMigrate(&User{}))
+
+	// Simulate rows written before normalization, bypassing the BeforeSave hook
+	for _, row := range []struct{ auth0ID, email string }{
+		{"auth0|1", "Jane@example.com"},
+		{"auth0|2", "jane@example.com"},
+		{"auth0|3", " Sam@Example.com"},
+		{"auth0|4", "casey@example.com"},
+	} {
+		assert.NoError(t, db.Exec("INSERT INTO users (auth0_id, name, email, role) VALUES (?, ?, ?, ?)",
+			row.auth0ID, "User", row.email, "customer").Error)
+	}
+
+	// The unique index is skipped while duplicates exist
+	assert.NoError(t, ensureUserEmailIndex(db))
+	assert.False(t, db.Migrator().HasIndex(&User{}, userEmailIndex))
+
+	result, err := NormalizeUserEmails(db)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Updated)
+	assert.Len(t, result.Conflicts, 1)
+	assert.ElementsMatch(t, []uint{1, 2}, result.Conflicts["jane@example.com"])
+
+	var sam User
+	db.Where("auth0_id = ?", "auth0|3").First(&sam)
+	assert.Equal(t, "sam@example.com", sam.Email)
+
+	// Once the duplicate account is removed the index can be created
+	db.Unscoped().Delete(&User{}, 1)
+	assert.NoError(t, ensureUserEmailIndex(db))
+	assert.True(t, db.Migrator().HasIndex(&User{}, userEmailIndex))
+}
+
+func TestUserIndexes_IgnoreDeletedAccounts(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.AutoMigrate(&User{}))
+
+	// Simulate the full-table unique indexes created by earlier versions
+	assert.NoError(t, db.Exec("CREATE UNIQUE INDEX "+legacyUserAuth0IDIndex+" ON users (auth0_id)").Error)
+	assert.NoError(t, db.Exec("CREATE UNIQUE INDEX idx_users_email_lower ON users (LOWER(email))").Error)
+
+	assert.NoError(t, MigrateUp(db))
+	assert.True(t, db.Migrator().HasIndex(&User{}, userAuth0IDIndex))
+	assert.True(t, db.Migrator().HasIndex(&User{}, userEmailIndex))
+	assert.False(t, db.Migrator().HasIndex(&User{}, legacyUserAuth0IDIndex))
+	assert.False(t, db.Migrator().HasIndex(&User{}, "idx_users_email_lower"))
+
+	// A deleted account frees its identity and email
+	deleted := User{Auth0ID: "auth0|jane", Name: "Jane", Email: "jane@example.com", Role: "customer"}
+	assert.NoError(t, db.Create(&deleted).Error)
+	assert.NoError(t, db.Delete(&deleted).Error)
+	assert.NoError(t, db.Create(&User{Auth0ID: "auth0|jane", Name: "Jane", Email: "jane@example.com", Role: "customer"}).Error)
+
+	// Active accounts are still unique
+	assert.Error(t, db.Create(&User{Auth0ID: "auth0|jane", Name: "Jane", Email: "other@example.com", Role: "customer"}).Error)
+	assert.Error(t, db.Create(&User{Auth0ID: "auth0|other", Name: "Jane", Email: "JANE@example.com", Role: "customer"}).Error)
+}
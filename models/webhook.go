@@ -0,0 +1,67 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Webhook delivery statuses
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed" // gave up after the last retry
+)
+
+// Webhook is an endpoint registered by an admin to receive signed order event notifications
+type Webhook struct {
+	ID            uint           `gorm:"primaryKey" json:"id"`
+	URL           string         `gorm:"not null" json:"url"`
+	Events        string         `gorm:"not null" json:"events"`    // space-separated, e.g. "order.created message.created"
+	Secret        string         `gorm:"not null" json:"-"`         // kept in plain text because every payload is signed with it
+	SigningSecret string         `gorm:"-" json:"secret,omitempty"` // computed field, only set in the creation response
+	CreatedByID   uint           `gorm:"not null;index" json:"created_by_id"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName specifies the table name for the Webhook model
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// Subscribes reports whether the webhook receives the event
+func (w *Webhook) Subscribes(event string) bool {
+	for _, subscribed := range strings.Fields(w.Events) {
+		if subscribed == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is one event queued for, or sent to, one webhook
+// Pending deliveries are sent once NextAttemptAt has passed and retried with backoff until they succeed or fail for good
+type WebhookDelivery struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	WebhookID      uint       `gorm:"not null;index" json:"webhook_id"`
+	EventID        string     `gorm:"not null;index" json:"event_id"` // shared by every delivery of the same event
+	Event          string     `gorm:"not null" json:"event"`
+	Payload        string     `gorm:"type:text;not null" json:"payload"` // the signed JSON body
+	Status         string     `gorm:"not null;default:'pending';index" json:"status"`
+	Attempts       int        `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  time.Time  `gorm:"not null;index" json:"next_attempt_at"`
+	LastAttemptAt  *time.Time `json:"last_attempt_at"`  // nullable
+	LastStatusCode *int       `json:"last_status_code"` // nullable, unset when the request did not get a response
+	LastError      *string    `json:"last_error"`       // nullable
+	DeliveredAt    *time.Time `json:"delivered_at"`     // nullable
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for the WebhookDelivery model
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
@@ -0,0 +1,36 @@
+package reporting
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// Setup sends reports of server errors and panics to the Sentry project named by dsn
+// Reports are tagged with environment; the SDK reads the release from SENTRY_RELEASE.
+// Until Setup runs nothing is reported, and Enabled is false
+func Setup(dsn, environment string) error {
+	err := sentry.Init(sentry.ClientOptions{
+		Dsn:              dsn,
+		Environment:      environment,
+		AttachStacktrace: true,
+		// Reports carry the request but not its cookies, credentials or the caller's IP address
+		SendDefaultPII: false,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set up error reporting: %w", err)
+	}
+	return nil
+}
+
+// Enabled reports whether errors are being reported
+func Enabled() bool {
+	return sentry.CurrentHub().Client() != nil
+}
+
+// Flush sends the reports still buffered, waiting up to timeout
+// It reports whether everything was sent
+func Flush(timeout time.Duration) bool {
+	return sentry.Flush(timeout)
+}
@@ -0,0 +1,20 @@
+package reporting
+
+import (
+	"testing"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetup(t *testing.T) {
+	defer sentry.CurrentHub().BindClient(nil)
+
+	assert.False(t, Enabled())
+	assert.Error(t, Setup("not a dsn", "test"))
+	assert.False(t, Enabled())
+
+	assert.NoError(t, Setup("https://key@sentry.example.com/1", "test"))
+	assert.True(t, Enabled())
+	assert.Equal(t, "test", sentry.CurrentHub().Client().Options().Environment)
+}
@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"gorm.io/gorm"
+)
+
+// AddOnRepository provides persistence for the add-on catalog
+type AddOnRepository interface {
+	// Create inserts a new add-on
+	Create(addOn *models.AddOn) error
+
+	// Save persists all fields of an existing add-on
+	Save(addOn *models.AddOn) error
+
+	// Delete retires an add-on from the catalog
+	Delete(addOn *models.AddOn) error
+
+	// FindByID loads an add-on that has not been retired
+	FindByID(id uint) (*models.AddOn, error)
+
+	// List returns every add-on that has not been retired, ordered by name
+	List() ([]models.AddOn, error)
+}
+
+// GormAddOnRepository implements AddOnRepository using GORM
+type GormAddOnRepository struct {
+	db *gorm.DB
+}
+
+// NewAddOnRepository creates an add-on repository backed by the given database
+func NewAddOnRepository(db *gorm.DB) *GormAddOnRepository {
+	return &GormAddOnRepository{db: db}
+}
+
+// Create inserts a new add-on
+func (r *GormAddOnRepository) Create(addOn *models.AddOn) error {
+	return r.db.Create(addOn).Error
+}
+
+// Save persists all fields of an existing add-on
+func (r *GormAddOnRepository) Save(addOn *models.AddOn) error {
+	return r.db.Save(addOn).Error
+}
+
+// Delete soft deletes an add-on so existing line items keep their reference
+func (r *GormAddOnRepository) Delete(addOn *models.AddOn) error {
+	return r.db.Delete(addOn).Error
+}
+
+// FindByID loads an add-on that has not been retired
+func (r *GormAddOnRepository) FindByID(id uint) (*models.AddOn, error) {
+	var addOn models.AddOn
+	if err := r.db.First(&addOn, id).Error; err != nil {
+		return nil, err
+	}
+	return &addOn, nil
+}
+
+// List returns every add-on that has not been retired, ordered by name
+func (r *GormAddOnRepository) List() ([]models.AddOn, error) {
+	var addOns []models.AddOn
+	if err := r.db.Order("name ASC").Find(&addOns).Error; err != nil {
+		return nil, err
+	}
+	return addOns, nil
+}
@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"gorm.io/gorm"
+)
+
+// AddressRepository provides persistence for users' address books
+type AddressRepository interface {
+	// Create inserts a new address
+	Create(address *models.Address) error
+
+	// Save persists all fields of an existing address
+	Save(address *models.Address) error
+
+	// Delete removes an address from its owner's address book
+	Delete(address *models.Address) error
+
+	// FindForUser loads an address belonging to the user
+	FindForUser(userID, id uint) (*models.Address, error)
+
+	// ListForUser returns the user's addresses, oldest first
+	ListForUser(userID uint) ([]models.Address, error)
+
+	// CountForUser returns how many addresses the user has
+	CountForUser(userID uint) (int64, error)
+}
+
+// GormAddressRepository implements AddressRepository using GORM
+type GormAddressRepository struct {
+	db *gorm.DB
+}
+
+// NewAddressRepository creates an address repository backed by the given database
+func NewAddressRepository(db *gorm.DB) *GormAddressRepository {
+	return &GormAddressRepository{db: db}
+}
+
+// Create inserts a new address
+func (r *GormAddressRepository) Create(address *models.Address) error {
+	return r.db.Create(address).Error
+}
+
+// Save persists all fields of an existing address
+func (r *GormAddressRepository) Save(address *models.Address) error {
+	return r.db.Save(address).Error
+}
+
+// Delete soft deletes an address; orders keep their own copy of where they ship
+func (r *GormAddressRepository) Delete(address *models.Address) error {
+	return r.db.Delete(address).Error
+}
+
+// FindForUser loads an address belonging to the user
+func (r *GormAddressRepository) FindForUser(userID, id uint) (*models.Address, error) {
+	var address models.Address
+	if err := r.db.Where("user_id = ?", userID).First(&address, id).Error; err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
+
+// ListForUser returns the user's addresses, oldest first
+func (r *GormAddressRepository) ListForUser(userID uint) ([]models.Address, error) {
+	var addresses []models.Address
+	if err := r.db.Where("user_id = ?", userID).Order("id ASC").Find(&addresses).Error; err != nil {
+		return nil, err
+	}
+	return addresses, nil
+}
+
+// CountForUser returns how many addresses the user has
+func (r *GormAddressRepository) CountForUser(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Address{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AnalyticsEventRepository buffers client analytics events until the warehouse export forwards them
+type AnalyticsEventRepository interface {
+	// CreateBatch inserts events in one statement and returns how many were new
+	// Events whose client event ID was already recorded are skipped
+	CreateBatch(events []models.AnalyticsEvent) (int64, error)
+
+	// ListUnexported returns up to limit events that have not been exported, oldest first
+	ListUnexported(limit int) ([]models.AnalyticsEvent, error)
+
+	// MarkExported records that the events reached the warehouse
+	MarkExported(ids []uint, exportedAt time.Time) error
+}
+
+// GormAnalyticsEventRepository implements AnalyticsEventRepository using GORM
+type GormAnalyticsEventRepository struct {
+	db *gorm.DB
+}
+
+// NewAnalyticsEventRepository creates an analytics event repository backed by the given database
+func NewAnalyticsEventRepository(db *gorm.DB) *GormAnalyticsEventRepository {
+	return &GormAnalyticsEventRepository{db: db}
+}
+
+// CreateBatch inserts events in one statement and returns how many were new
+func (r *GormAnalyticsEventRepository) CreateBatch(events []models.AnalyticsEvent) (int64, error) {
+	if len(events) == 0 {
+		return 0, nil
+	}
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "client_event_id"}},
+		DoNothing: true,
+	}).Create(&events)
+	return result.RowsAffected, result.Error
+}
+
+// ListUnexported returns up to limit events that have not been exported, oldest first
+func (r *GormAnalyticsEventRepository) ListUnexported(limit int) ([]models.AnalyticsEvent, error) {
+	var events []models.AnalyticsEvent
+	if err := r.db.Where("exported_at IS NULL").
+		Order("id ASC").
+		Limit(limit).
+		Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// MarkExported records that the events reached the warehouse
+func (r *GormAnalyticsEventRepository) MarkExported(ids []uint, exportedAt time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Model(&models.AnalyticsEvent{}).
+		Where("id IN ?", ids).
+		Update("exported_at", exportedAt).Error
+}
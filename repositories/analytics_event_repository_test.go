@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyticsEventRepository(t *testing.T) {
+	db, customer := setupAnalyticsTestDB(t)
+	repo := NewAnalyticsEventRepository(db)
+
+	occurredAt := time.Date(2026, 10, 5, 9, 0, 0, 0, time.UTC)
+	event := func(id string) models.AnalyticsEvent {
+		return models.AnalyticsEvent{ClientEventID: id, UserID: &customer.ID, Type: models.EventTypeScreenView, Name: "home", Properties: "{}", OccurredAt: occurredAt}
+	}
+
+	recorded, err := repo.CreateBatch([]models.AnalyticsEvent{event("a"), event("b")})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), recorded)
+
+	// Events already recorded are skipped rather than failing the batch
+	recorded, err = repo.CreateBatch([]models.AnalyticsEvent{event("b"), event("c")})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), recorded)
+
+	pending, err := repo.ListUnexported(2)
+	assert.NoError(t, err)
+	assert.Len(t, pending, 2)
+	assert.Equal(t, "a", pending[0].ClientEventID)
+
+	assert.NoError(t, repo.MarkExported([]uint{pending[0].ID, pending[1].ID}, occurredAt.Add(time.Hour)))
+	pending, err = repo.ListUnexported(10)
+	assert.NoError(t, err)
+	assert.Len(t, pending, 1)
+	assert.Equal(t, "c", pending[0].ClientEventID)
+}
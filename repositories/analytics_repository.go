@@ -0,0 +1,248 @@
+package repositories
+
+import (
+	"sort"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/utils"
+	"gorm.io/gorm"
+)
+
+// StatusCount is the number of orders in one status
+type StatusCount struct {
+	Status string `json:"status"`
+	Count  int64  `json:"count"`
+}
+
+// WeeklyCount is the number of orders created in the week starting on WeekStart (a Monday, YYYY-MM-DD)
+type WeeklyCount struct {
+	WeekStart string `json:"week_start"`
+	Count     int64  `json:"count"`
+}
+
+// RevenueTotals sums order prices in one currency
+type RevenueTotals struct {
+	Currency  string  `json:"currency"`
+	Booked    float64 `json:"booked"`    // accepted orders that have not been rejected
+	Delivered float64 `json:"delivered"` // orders that reached the customer
+	RushFees  float64 `json:"rush_fees"` // rush fee line items on booked orders
+}
+
+// TechnicianSLA holds one technician's fulfillment aggregates over a period
+// Orders count toward the technician currently assigned, so a handed-off order counts for whoever took it over
+type TechnicianSLA struct {
+	TechnicianID      uint
+	TechnicianName    string
+	Reviewed          int64    // orders accepted or rejected in the period
+	Rejected          int64    // reviewed orders that were rejected
+	AcceptanceSeconds *float64 // mean time from submission to review, nil when nothing was reviewed
+	Shipped           int64    // orders shipped in the period
+	ProductionSeconds *float64 // mean time from production start to shipping, nil when no shipped order has both times
+	ShippedOnTime     int64    // shipped orders that shipped within their target after review
+}
+
+// AnalyticsRepository runs aggregate queries over orders
+// Every method aggregates in the database rather than loading rows
+type AnalyticsRepository interface {
+	// CountByStatus returns the number of orders in each status
+	CountByStatus() ([]StatusCount, error)
+
+	// CountRush returns the number of rush orders
+	CountRush() (int64, error)
+
+	// Revenue returns revenue totals over orders in the booked statuses priced in the currency
+	Revenue(currency string, bookedStatuses []string, deliveredStatus string) (RevenueTotals, error)
+
+	// AverageReviewSeconds returns the mean time from submission to review for orders in the given statuses
+	// The boolean is false when no order qualifies
+	AverageReviewSeconds(statuses []string) (float64, bool, error)
+
+	// OrdersPerWeek returns weekly order counts for orders created at or after since, oldest week first
+	OrdersPerWeek(since time.Time) ([]WeeklyCount, error)
+
+	// TechnicianSLA returns fulfillment aggregates per technician for orders reviewed or shipped in [from, to), by technician name
+	// An order ships on time when it ships within rushTarget (rush orders) or standardTarget of its review
+	TechnicianSLA(from, to time.Time, rejectedStatus string, standardTarget, rushTarget time.Duration) ([]TechnicianSLA, error)
+}
+
+// GormAnalyticsRepository implements AnalyticsRepository using GORM
+type GormAnalyticsRepository struct {
+	db *gorm.DB
+}
+
+// NewAnalyticsRepository creates an analytics repository backed by the given database
+func NewAnalyticsRepository(db *gorm.DB) *GormAnalyticsRepository {
+	return &GormAnalyticsRepository{db: db}
+}
+
+// CountByStatus returns the number of orders in each status
+func (r *GormAnalyticsRepository) CountByStatus() ([]StatusCount, error) {
+	var counts []StatusCount
+	err := r.db.Model(&models.Order{}).
+		Select("status, COUNT(*) AS count").
+		Group("status").
+		Order("status").
+		Scan(&counts).Error
+	return counts, err
+}
+
+// CountRush returns the number of rush orders
+func (r *GormAnalyticsRepository) CountRush() (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Order{}).Where("rush = ?", true).Count(&count).Error
+	return count, err
+}
+
+// Revenue returns revenue totals over orders in the booked statuses priced in the currency
+// Amounts are summed in whole cents so large totals carry no floating point error
+func (r *GormAnalyticsRepository) Revenue(currency string, bookedStatuses []string, deliveredStatus string) (RevenueTotals, error) {
+	var cents struct {
+		Booked    int64
+		Delivered int64
+		RushFees  int64
+	}
+	price := models.OrderPriceColumn.CentsSQL()
+	if err := r.db.Model(&models.Order{}).
+		Select("COALESCE(SUM("+price+"), 0) AS booked, COALESCE(SUM(CASE WHEN status = ? THEN "+price+" ELSE 0 END), 0) AS delivered", deliveredStatus).
+		Where("status IN ? AND currency = ?", bookedStatuses, currency).
+		Scan(&cents).Error; err != nil {
+		return RevenueTotals{}, err
+	}
+
+	if err := r.db.Model(&models.OrderLineItem{}).
+		Select("COALESCE(SUM("+models.LineItemAmountColumn.CentsSQL()+"), 0)").
+		Joins("JOIN orders ON orders.id = order_line_items.order_id AND orders.deleted_at IS NULL").
+		Where("order_line_items.kind = ? AND orders.status IN ? AND orders.currency = ?", models.LineItemRushFee, bookedStatuses, currency).
+		Scan(&cents.RushFees).Error; err != nil {
+		return RevenueTotals{}, err
+	}
+
+	return RevenueTotals{
+		Currency:  currency,
+		Booked:    utils.FromCents(cents.Booked),
+		Delivered: utils.FromCents(cents.Delivered),
+		RushFees:  utils.FromCents(cents.RushFees),
+	}, nil
+}
+
+// AverageReviewSeconds returns the mean time from submission to review for orders in the given statuses
+func (r *GormAnalyticsRepository) AverageReviewSeconds(statuses []string) (float64, bool, error) {
+	var average *float64
+	err := r.db.Model(&models.Order{}).
+		Select("AVG("+r.secondsBetween("created_at", "reviewed_at")+")").
+		Where("status IN ? AND reviewed_at IS NOT NULL", statuses).
+		Scan(&average).Error
+	if err != nil || average == nil {
+		return 0, false, err
+	}
+	return *average, true, nil
+}
+
+// OrdersPerWeek returns weekly order counts for orders created at or after since, oldest week first
+func (r *GormAnalyticsRepository) OrdersPerWeek(since time.Time) ([]WeeklyCount, error) {
+	week := r.weekStart("created_at")
+
+	var counts []WeeklyCount
+	err := r.db.Model(&models.Order{}).
+		Select(week+" AS week_start, COUNT(*) AS count").
+		Where("created_at >= ?", since).
+		Group(week).
+		Order("week_start").
+		Scan(&counts).Error
+	return counts, err
+}
+
+// TechnicianSLA returns fulfillment aggregates per technician for orders reviewed or shipped in [from, to), by technician name
+func (r *GormAnalyticsRepository) TechnicianSLA(from, to time.Time, rejectedStatus string, standardTarget, rushTarget time.Duration) ([]TechnicianSLA, error) {
+	var reviews []struct {
+		TechnicianID      uint
+		Reviewed          int64
+		Rejected          int64
+		AcceptanceSeconds *float64
+	}
+	if err := r.db.Model(&models.Order{}).
+		Select("technician_id, COUNT(*) AS reviewed, "+
+			"SUM(CASE WHEN status = ? THEN 1 ELSE 0 END) AS rejected, "+
+			"AVG("+r.secondsBetween("created_at", "reviewed_at")+") AS acceptance_seconds", rejectedStatus).
+		Where("technician_id IS NOT NULL AND reviewed_at >= ? AND reviewed_at < ?", from, to).
+		Group("technician_id").
+		Scan(&reviews).Error; err != nil {
+		return nil, err
+	}
+
+	var shipments []struct {
+		TechnicianID      uint
+		Shipped           int64
+		ProductionSeconds *float64
+		ShippedOnTime     int64
+	}
+	if err := r.db.Model(&models.Order{}).
+		Select("technician_id, COUNT(*) AS shipped, "+
+			"AVG("+r.secondsBetween("production_started_at", "shipped_at")+") AS production_seconds, "+
+			"SUM(CASE WHEN "+r.secondsBetween("reviewed_at", "shipped_at")+" <= CASE WHEN rush THEN ? ELSE ? END THEN 1 ELSE 0 END) AS shipped_on_time",
+			rushTarget.Seconds(), standardTarget.Seconds()).
+		Where("technician_id IS NOT NULL AND shipped_at >= ? AND shipped_at < ?", from, to).
+		Group("technician_id").
+		Scan(&shipments).Error; err != nil {
+		return nil, err
+	}
+
+	byTechnician := make(map[uint]*TechnicianSLA)
+	row := func(technicianID uint) *TechnicianSLA {
+		if byTechnician[technicianID] == nil {
+			byTechnician[technicianID] = &TechnicianSLA{TechnicianID: technicianID}
+		}
+		return byTechnician[technicianID]
+	}
+	for _, review := range reviews {
+		sla := row(review.TechnicianID)
+		sla.Reviewed = review.Reviewed
+		sla.Rejected = review.Rejected
+		sla.AcceptanceSeconds = review.AcceptanceSeconds
+	}
+	for _, shipment := range shipments {
+		sla := row(shipment.TechnicianID)
+		sla.Shipped = shipment.Shipped
+		sla.ProductionSeconds = shipment.ProductionSeconds
+		sla.ShippedOnTime = shipment.ShippedOnTime
+	}
+	if len(byTechnician) == 0 {
+		return []TechnicianSLA{}, nil
+	}
+
+	ids := make([]uint, 0, len(byTechnician))
+	for id := range byTechnician {
+		ids = append(ids, id)
+	}
+	var technicians []models.User
+	if err := r.db.Unscoped().Select("id", "name").Where("id IN ?", ids).Order("id").Find(&technicians).Error; err != nil {
+		return nil, err
+	}
+	// Names may be encrypted at rest, so they are sorted once decrypted
+	sort.SliceStable(technicians, func(i, j int) bool { return technicians[i].Name < technicians[j].Name })
+
+	slas := make([]TechnicianSLA, 0, len(byTechnician))
+	for _, technician := range technicians {
+		sla := byTechnician[technician.ID]
+		sla.TechnicianName = technician.Name
+		slas = append(slas, *sla)
+	}
+	return slas, nil
+}
+
+// secondsBetween returns a SQL expression for the seconds elapsed between two timestamp columns
+func (r *GormAnalyticsRepository) secondsBetween(from, to string) string {
+	if r.db.Dialector.Name() == "sqlite" {
+		return "(julianday(" + to + ") - julianday(" + from + ")) * 86400"
+	}
+	return "EXTRACT(EPOCH FROM (" + to + " - " + from + "))"
+}
+
+// weekStart returns a SQL expression for the Monday starting the week of a timestamp column, as YYYY-MM-DD
+func (r *GormAnalyticsRepository) weekStart(column string) string {
+	if r.db.Dialector.Name() == "sqlite" {
+		return "date(" + column + ", 'weekday 0', '-6 days')"
+	}
+	return "to_char(date_trunc('week', " + column + "), 'YYYY-MM-DD')"
+}
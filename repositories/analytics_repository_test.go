@@ -0,0 +1,175 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/dualwrite"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupAnalyticsTestDB(t *testing.T) (*gorm.DB, models.User) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := models.MigrateUp(db); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	customer := models.User{Auth0ID: "auth0|customer", Name: "Customer", Email: "customer@example.com", Role: "customer"}
+	db.Create(&customer)
+	return db, customer
+}
+
+func TestAnalyticsRepository(t *testing.T) {
+	db, customer := setupAnalyticsTestDB(t)
+	repo := NewAnalyticsRepository(db)
+
+	monday := time.Date(2026, 10, 5, 9, 0, 0, 0, time.UTC)
+	price := func(v float64) *float64 { return &v }
+	at := func(t time.Time) *time.Time { return &t }
+
+	orders := []models.Order{
+		{Description: "a", Quantity: 1, Status: "submitted", CustomerID: customer.ID, CreatedAt: monday},
+		{Description: "b", Quantity: 1, Status: "accepted", CustomerID: customer.ID, Price: price(40), CreatedAt: monday.Add(24 * time.Hour), ReviewedAt: at(monday.Add(26 * time.Hour))},
+		{Description: "c", Quantity: 1, Status: "delivered", CustomerID: customer.ID, Price: price(60.5), Rush: true, CreatedAt: monday.AddDate(0, 0, 7), ReviewedAt: at(monday.AddDate(0, 0, 7).Add(4 * time.Hour))},
+		{Description: "d", Quantity: 1, Status: "rejected", CustomerID: customer.ID, CreatedAt: monday.AddDate(0, 0, 8), ReviewedAt: at(monday.AddDate(0, 0, 9))},
+		{Description: "e", Quantity: 1, Status: "accepted", CustomerID: customer.ID, Price: price(99), Currency: "EUR", CreatedAt: monday.AddDate(0, 0, 9), ReviewedAt: at(monday.AddDate(0, 0, 9).Add(3 * time.Hour))},
+	}
+	for i := range orders {
+		assert.NoError(t, db.Create(&orders[i]).Error)
+	}
+	db.Create(&models.OrderLineItem{OrderID: orders[2].ID, Kind: models.LineItemRushFee, Description: "Rush fee", Quantity: 1, UnitPrice: 15, Amount: 15})
+
+	counts, err := repo.CountByStatus()
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []StatusCount{
+		{Status: "accepted", Count: 2},
+		{Status: "delivered", Count: 1},
+		{Status: "rejected", Count: 1},
+		{Status: "submitted", Count: 1},
+	}, counts)
+
+	rush, err := repo.CountRush()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), rush)
+
+	booked := []string{"accepted", "in_production", "shipped", "delivered"}
+	// The EUR order is left out of USD totals
+	revenue, err := repo.Revenue("USD", booked, "delivered")
+	assert.NoError(t, err)
+	assert.Equal(t, "USD", revenue.Currency)
+	assert.InDelta(t, 100.5, revenue.Booked, 0.001)
+	assert.InDelta(t, 60.5, revenue.Delivered, 0.001)
+	assert.InDelta(t, 15, revenue.RushFees, 0.001)
+
+	// Accepted orders took 2h, 4h and 3h to review; the rejected order is ignored
+	average, ok, err := repo.AverageReviewSeconds(booked)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.InDelta(t, 3*3600, average, 1)
+
+	weekly, err := repo.OrdersPerWeek(monday)
+	assert.NoError(t, err)
+	assert.Equal(t, []WeeklyCount{
+		{WeekStart: "2026-10-05", Count: 2},
+		{WeekStart: "2026-10-12", Count: 3},
+	}, weekly)
+}
+
+func TestAnalyticsRepository_Empty(t *testing.T) {
+	db, _ := setupAnalyticsTestDB(t)
+	repo := NewAnalyticsRepository(db)
+
+	revenue, err := repo.Revenue("USD", []string{"accepted"}, "delivered")
+	assert.NoError(t, err)
+	assert.Equal(t, RevenueTotals{Currency: "USD"}, revenue)
+
+	_, ok, err := repo.AverageReviewSeconds([]string{"accepted"})
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestAnalyticsRepository_RevenueInCents(t *testing.T) {
+	db, customer := setupAnalyticsTestDB(t)
+	repo := NewAnalyticsRepository(db)
+	defer models.OrderPriceField.SetStage(dualwrite.StageOff)
+
+	// Summed as floats, 0.1 + 0.2 comes to 0.30000000000000004
+	models.OrderPriceField.SetStage(dualwrite.StageDualWrite)
+	for _, amount := range []float64{0.1, 0.2} {
+		price := amount
+		db.Create(&models.Order{Description: "Tip", Quantity: 1, Status: "accepted", CustomerID: customer.ID, Price: &price})
+	}
+	revenue, err := repo.Revenue("USD", []string{"accepted"}, "delivered")
+	assert.NoError(t, err)
+	assert.Equal(t, 0.3, revenue.Booked)
+
+	// Once reads are cut over the cents column is summed; rows not yet backfilled fall back to the decimal price
+	models.OrderPriceField.SetStage(dualwrite.StageNew)
+	db.Model(&models.Order{}).Where("price = ?", 0.1).UpdateColumn("price", 5.0)
+	unfilled := 1.25
+	db.Create(&models.Order{Description: "Legacy", Quantity: 1, Status: "accepted", CustomerID: customer.ID, Price: &unfilled})
+	db.Model(&models.Order{}).Where("price = ?", unfilled).UpdateColumn("price_cents", nil)
+	revenue, err = repo.Revenue("USD", []string{"accepted"}, "delivered")
+	assert.NoError(t, err)
+	assert.Equal(t, 1.55, revenue.Booked)
+}
+
+func TestAnalyticsRepository_TechnicianSLA(t *testing.T) {
+	db, customer := setupAnalyticsTestDB(t)
+	repo := NewAnalyticsRepository(db)
+
+	alex := models.User{Auth0ID: "auth0|alex", Name: "Alex", Email: "alex@example.com", Role: "technician"}
+	sam := models.User{Auth0ID: "auth0|sam", Name: "Sam", Email: "sam@example.com", Role: "technician"}
+	db.Create(&alex)
+	db.Create(&sam)
+
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	at := func(t time.Time) *time.Time { return &t }
+	day := 24 * time.Hour
+
+	orders := []models.Order{
+		// Alex: reviewed after 2h, produced for 2 days and shipped 4 days after review (on time)
+		{Description: "a", Quantity: 1, Status: "shipped", CustomerID: customer.ID, TechnicianID: &alex.ID, CreatedAt: start,
+			ReviewedAt: at(start.Add(2 * time.Hour)), ProductionStartedAt: at(start.Add(2*time.Hour + 2*day)), ShippedAt: at(start.Add(2*time.Hour + 4*day))},
+		// Alex: rush, reviewed after 4h and shipped 4 days after review (late), no recorded production start
+		{Description: "b", Quantity: 1, Status: "delivered", Rush: true, CustomerID: customer.ID, TechnicianID: &alex.ID, CreatedAt: start,
+			ReviewedAt: at(start.Add(4 * time.Hour)), ShippedAt: at(start.Add(4*time.Hour + 4*day))},
+		// Sam: rejected after 1h
+		{Description: "c", Quantity: 1, Status: "rejected", CustomerID: customer.ID, TechnicianID: &sam.ID, CreatedAt: start,
+			ReviewedAt: at(start.Add(time.Hour))},
+		// Sam: reviewed before the period
+		{Description: "d", Quantity: 1, Status: "accepted", CustomerID: customer.ID, TechnicianID: &sam.ID, CreatedAt: start.Add(-10 * day),
+			ReviewedAt: at(start.Add(-9 * day))},
+	}
+	for i := range orders {
+		assert.NoError(t, db.Create(&orders[i]).Error)
+	}
+
+	slas, err := repo.TechnicianSLA(start, start.AddDate(0, 1, 0), "rejected", 7*day, 3*day)
+	assert.NoError(t, err)
+	assert.Len(t, slas, 2)
+
+	assert.Equal(t, "Alex", slas[0].TechnicianName)
+	assert.Equal(t, int64(2), slas[0].Reviewed)
+	assert.Equal(t, int64(0), slas[0].Rejected)
+	assert.InDelta(t, 3*3600, *slas[0].AcceptanceSeconds, 1)
+	assert.Equal(t, int64(2), slas[0].Shipped)
+	assert.InDelta(t, 2*86400, *slas[0].ProductionSeconds, 1)
+	assert.Equal(t, int64(1), slas[0].ShippedOnTime)
+
+	assert.Equal(t, "Sam", slas[1].TechnicianName)
+	assert.Equal(t, int64(1), slas[1].Reviewed)
+	assert.Equal(t, int64(1), slas[1].Rejected)
+	assert.Equal(t, int64(0), slas[1].Shipped)
+	assert.Nil(t, slas[1].ProductionSeconds)
+
+	empty, err := repo.TechnicianSLA(start.AddDate(1, 0, 0), start.AddDate(1, 1, 0), "rejected", 7*day, 3*day)
+	assert.NoError(t, err)
+	assert.Empty(t, empty)
+}
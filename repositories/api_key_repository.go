@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"gorm.io/gorm"
+)
+
+// APIKeyRepository provides persistence for integration API keys
+type APIKeyRepository interface {
+	// Create inserts a new API key
+	Create(key *models.APIKey) error
+
+	// Save persists all fields of an existing API key
+	Save(key *models.APIKey) error
+
+	// FindByHash loads an API key by the hash of its secret
+	FindByHash(hash string) (*models.APIKey, error)
+
+	// FindByID loads an API key by ID
+	FindByID(id uint) (*models.APIKey, error)
+
+	// List returns every API key, newest first
+	List() ([]models.APIKey, error)
+
+	// TouchLastUsed records when the key was last used
+	TouchLastUsed(id uint, usedAt time.Time) error
+}
+
+// GormAPIKeyRepository implements APIKeyRepository using GORM
+type GormAPIKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyRepository creates an API key repository backed by the given database
+func NewAPIKeyRepository(db *gorm.DB) *GormAPIKeyRepository {
+	return &GormAPIKeyRepository{db: db}
+}
+
+// Create inserts a new API key
+func (r *GormAPIKeyRepository) Create(key *models.APIKey) error {
+	return r.db.Create(key).Error
+}
+
+// Save persists all fields of an existing API key
+func (r *GormAPIKeyRepository) Save(key *models.APIKey) error {
+	return r.db.Save(key).Error
+}
+
+// FindByHash loads an API key by the hash of its secret
+func (r *GormAPIKeyRepository) FindByHash(hash string) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := r.db.Where("key_hash = ?", hash).First(&key).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// FindByID loads an API key by ID
+func (r *GormAPIKeyRepository) FindByID(id uint) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := r.db.First(&key, id).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// List returns every API key, newest first
+func (r *GormAPIKeyRepository) List() ([]models.APIKey, error) {
+	var keys []models.APIKey
+	if err := r.db.Order("created_at DESC").Order("id DESC").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// TouchLastUsed records when the key was last used
+func (r *GormAPIKeyRepository) TouchLastUsed(id uint, usedAt time.Time) error {
+	return r.db.Model(&models.APIKey{}).Where("id = ?", id).UpdateColumn("last_used_at", usedAt).Error
+}
@@ -0,0 +1,164 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"gorm.io/gorm"
+)
+
+// AppointmentQuery filters appointments; zero values are ignored
+type AppointmentQuery struct {
+	CustomerID   uint
+	TechnicianID uint
+	OrderID      uint
+	Status       string
+	From         time.Time // appointments starting at or after
+	To           time.Time // appointments starting before
+}
+
+// AppointmentRepository provides persistence for technicians' slots and the fittings booked in them
+type AppointmentRepository interface {
+	// CreateSlot inserts a new slot
+	CreateSlot(slot *models.AppointmentSlot) error
+
+	// FindSlot loads a slot with its booked appointment, if any
+	FindSlot(id uint) (*models.AppointmentSlot, error)
+
+	// DeleteSlot removes a slot
+	DeleteSlot(slot *models.AppointmentSlot) error
+
+	// ListSlots returns the technician's slots starting in [from, to), earliest first
+	ListSlots(technicianID uint, from, to time.Time) ([]models.AppointmentSlot, error)
+
+	// CountOverlappingSlots counts the technician's slots that overlap [start, end)
+	CountOverlappingSlots(technicianID uint, start, end time.Time) (int64, error)
+
+	// CreateAppointment inserts a new appointment
+	CreateAppointment(appointment *models.Appointment) error
+
+	// FindAppointment loads an appointment by ID
+	FindAppointment(id uint) (*models.Appointment, error)
+
+	// SaveAppointment updates an existing appointment
+	SaveAppointment(appointment *models.Appointment) error
+
+	// ListAppointments returns the appointments matching the query, earliest first
+	ListAppointments(query AppointmentQuery) ([]models.Appointment, error)
+
+	// CountOverlappingAppointments counts the customer's booked appointments that overlap [start, end)
+	CountOverlappingAppointments(customerID uint, start, end time.Time) (int64, error)
+}
+
+// GormAppointmentRepository implements AppointmentRepository using GORM
+type GormAppointmentRepository struct {
+	db *gorm.DB
+}
+
+// NewAppointmentRepository creates an appointment repository backed by the given database
+func NewAppointmentRepository(db *gorm.DB) *GormAppointmentRepository {
+	return &GormAppointmentRepository{db: db}
+}
+
+// bookedAppointment preloads only the appointment currently holding a slot
+func bookedAppointment(tx *gorm.DB) *gorm.DB {
+	return tx.Where("status = ?", models.AppointmentBooked)
+}
+
+// CreateSlot inserts a new slot
+func (r *GormAppointmentRepository) CreateSlot(slot *models.AppointmentSlot) error {
+	return r.db.Create(slot).Error
+}
+
+// FindSlot loads a slot with its booked appointment, if any
+func (r *GormAppointmentRepository) FindSlot(id uint) (*models.AppointmentSlot, error) {
+	var slot models.AppointmentSlot
+	if err := r.db.Preload("Appointment", bookedAppointment).First(&slot, id).Error; err != nil {
+		return nil, err
+	}
+	return &slot, nil
+}
+
+// DeleteSlot soft deletes a slot
+func (r *GormAppointmentRepository) DeleteSlot(slot *models.AppointmentSlot) error {
+	return r.db.Delete(slot).Error
+}
+
+// ListSlots returns the technician's slots starting in [from, to), earliest first
+func (r *GormAppointmentRepository) ListSlots(technicianID uint, from, to time.Time) ([]models.AppointmentSlot, error) {
+	var slots []models.AppointmentSlot
+	if err := r.db.Preload("Appointment", bookedAppointment).
+		Where("technician_id = ? AND starts_at >= ? AND starts_at < ?", technicianID, from, to).
+		Order("starts_at ASC, id ASC").
+		Find(&slots).Error; err != nil {
+		return nil, err
+	}
+	return slots, nil
+}
+
+// CountOverlappingSlots counts the technician's slots that overlap [start, end)
+func (r *GormAppointmentRepository) CountOverlappingSlots(technicianID uint, start, end time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.AppointmentSlot{}).
+		Where("technician_id = ? AND starts_at < ? AND ends_at > ?", technicianID, end, start).
+		Count(&count).Error
+	return count, err
+}
+
+// CreateAppointment inserts a new appointment
+// The unique index on booked slots rejects a second booking of the same slot
+func (r *GormAppointmentRepository) CreateAppointment(appointment *models.Appointment) error {
+	return r.db.Create(appointment).Error
+}
+
+// FindAppointment loads an appointment by ID
+func (r *GormAppointmentRepository) FindAppointment(id uint) (*models.Appointment, error) {
+	var appointment models.Appointment
+	if err := r.db.First(&appointment, id).Error; err != nil {
+		return nil, err
+	}
+	return &appointment, nil
+}
+
+// SaveAppointment updates an existing appointment
+func (r *GormAppointmentRepository) SaveAppointment(appointment *models.Appointment) error {
+	return r.db.Save(appointment).Error
+}
+
+// ListAppointments returns the appointments matching the query, earliest first
+func (r *GormAppointmentRepository) ListAppointments(query AppointmentQuery) ([]models.Appointment, error) {
+	tx := r.db.Model(&models.Appointment{})
+	if query.CustomerID != 0 {
+		tx = tx.Where("customer_id = ?", query.CustomerID)
+	}
+	if query.TechnicianID != 0 {
+		tx = tx.Where("technician_id = ?", query.TechnicianID)
+	}
+	if query.OrderID != 0 {
+		tx = tx.Where("order_id = ?", query.OrderID)
+	}
+	if query.Status != "" {
+		tx = tx.Where("status = ?", query.Status)
+	}
+	if !query.From.IsZero() {
+		tx = tx.Where("starts_at >= ?", query.From)
+	}
+	if !query.To.IsZero() {
+		tx = tx.Where("starts_at < ?", query.To)
+	}
+
+	var appointments []models.Appointment
+	if err := tx.Order("starts_at ASC, id ASC").Find(&appointments).Error; err != nil {
+		return nil, err
+	}
+	return appointments, nil
+}
+
+// CountOverlappingAppointments counts the customer's booked appointments that overlap [start, end)
+func (r *GormAppointmentRepository) CountOverlappingAppointments(customerID uint, start, end time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Appointment{}).
+		Where("customer_id = ? AND status = ? AND starts_at < ? AND ends_at > ?", customerID, models.AppointmentBooked, end, start).
+		Count(&count).Error
+	return count, err
+}
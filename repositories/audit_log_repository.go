@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"gorm.io/gorm"
+)
+
+// AuditLogQuery holds filtering and pagination parameters for listing audit log entries
+type AuditLogQuery struct {
+	ActorID    *uint      // only changes made by this user
+	Action     string     // only this action, e.g. "order.status_changed"
+	TargetType string     // only changes to this kind of record
+	TargetID   *uint      // only changes to this record; meaningful with TargetType
+	After      *time.Time // only entries created at or after this time
+	Before     *time.Time // only entries created before this time
+	Limit      int
+	Offset     int
+}
+
+// AuditLogRepository provides persistence for the audit log
+type AuditLogRepository interface {
+	// Create inserts an audit log entry
+	Create(entry *models.AuditLog) error
+
+	// List returns a page of the entries matching the query, newest first, with the total number that match
+	List(query AuditLogQuery) ([]models.AuditLog, int64, error)
+}
+
+// GormAuditLogRepository implements AuditLogRepository using GORM
+type GormAuditLogRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditLogRepository creates an audit log repository backed by the given database
+func NewAuditLogRepository(db *gorm.DB) *GormAuditLogRepository {
+	return &GormAuditLogRepository{db: db}
+}
+
+// Create inserts an audit log entry
+func (r *GormAuditLogRepository) Create(entry *models.AuditLog) error {
+	return r.db.Create(entry).Error
+}
+
+// List returns a page of the entries matching the query, newest first, with the total number that match
+func (r *GormAuditLogRepository) List(query AuditLogQuery) ([]models.AuditLog, int64, error) {
+	scope := r.db.Model(&models.AuditLog{})
+	if query.ActorID != nil {
+		scope = scope.Where("actor_id = ?", *query.ActorID)
+	}
+	if query.Action != "" {
+		scope = scope.Where("action = ?", query.Action)
+	}
+	if query.TargetType != "" {
+		scope = scope.Where("target_type = ?", query.TargetType)
+	}
+	if query.TargetID != nil {
+		scope = scope.Where("target_id = ?", *query.TargetID)
+	}
+	if query.After != nil {
+		scope = scope.Where("created_at >= ?", *query.After)
+	}
+	if query.Before != nil {
+		scope = scope.Where("created_at < ?", *query.Before)
+	}
+
+	var total int64
+	if err := scope.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []models.AuditLog
+	if err := scope.Order("created_at DESC, id DESC").Limit(query.Limit).Offset(query.Offset).Find(&entries).Error; err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
@@ -0,0 +1,130 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"gorm.io/gorm"
+)
+
+// BackfillRepository persists backfill runs and runs the chunked queries behind each backfill
+type BackfillRepository interface {
+	// CreateRun inserts a new run
+	CreateRun(run *models.BackfillRun) error
+
+	// SaveRun persists all fields of an existing run
+	SaveRun(run *models.BackfillRun) error
+
+	// FindLatestRun loads the most recent run of the named backfill
+	FindLatestRun(name string) (*models.BackfillRun, error)
+
+	// ListRunningRuns returns the runs that still have rows to visit, oldest first
+	ListRunningRuns() ([]models.BackfillRun, error)
+
+	// ClaimRun leases a running run until leaseUntil, so that no other instance works on it meanwhile
+	// It returns false when another instance holds an unexpired lease
+	ClaimRun(run *models.BackfillRun, now, leaseUntil time.Time) (bool, error)
+
+	// CountAfter returns how many rows of a table have an ID above afterID, leaving out soft-deleted rows
+	CountAfter(table string, afterID uint) (int64, error)
+
+	// ListUsersAfter returns up to limit users with an ID above afterID, in ID order
+	ListUsersAfter(afterID uint, limit int) ([]models.User, error)
+
+	// BackfillPriceCents populates a price's cents column for up to limit rows with an ID above afterID
+	BackfillPriceCents(column models.PriceColumn, afterID uint, limit int) (models.BackfillBatch, error)
+
+	// NormalizeUserEmails normalizes the emails of up to limit users with an ID above afterID
+	NormalizeUserEmails(afterID uint, limit int) (models.BackfillBatch, error)
+
+	// EncryptUserPII encrypts the names and emails of up to limit users with an ID above afterID
+	EncryptUserPII(afterID uint, limit int) (models.BackfillBatch, error)
+}
+
+// GormBackfillRepository implements BackfillRepository using GORM
+type GormBackfillRepository struct {
+	db *gorm.DB
+}
+
+// NewBackfillRepository creates a backfill repository backed by the given database
+func NewBackfillRepository(db *gorm.DB) *GormBackfillRepository {
+	return &GormBackfillRepository{db: db}
+}
+
+// CreateRun inserts a new run
+func (r *GormBackfillRepository) CreateRun(run *models.BackfillRun) error {
+	return r.db.Create(run).Error
+}
+
+// SaveRun persists all fields of an existing run
+func (r *GormBackfillRepository) SaveRun(run *models.BackfillRun) error {
+	return r.db.Save(run).Error
+}
+
+// FindLatestRun loads the most recent run of the named backfill
+func (r *GormBackfillRepository) FindLatestRun(name string) (*models.BackfillRun, error) {
+	var run models.BackfillRun
+	if err := r.db.Where("name = ?", name).Order("id DESC").First(&run).Error; err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// ListRunningRuns returns the runs that still have rows to visit, oldest first
+func (r *GormBackfillRepository) ListRunningRuns() ([]models.BackfillRun, error) {
+	var runs []models.BackfillRun
+	if err := r.db.Where("status = ?", models.BackfillRunning).Order("id ASC").Find(&runs).Error; err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// ClaimRun leases a running run until leaseUntil, so that no other instance works on it meanwhile
+func (r *GormBackfillRepository) ClaimRun(run *models.BackfillRun, now, leaseUntil time.Time) (bool, error) {
+	result := r.db.Model(&models.BackfillRun{}).
+		Where("id = ? AND status = ? AND (lease_until IS NULL OR lease_until < ?)", run.ID, models.BackfillRunning, now).
+		UpdateColumn("lease_until", leaseUntil)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	run.LeaseUntil = &leaseUntil
+	return true, nil
+}
+
+// CountAfter returns how many rows of a table have an ID above afterID, leaving out soft-deleted rows
+func (r *GormBackfillRepository) CountAfter(table string, afterID uint) (int64, error) {
+	query := r.db.Table(table).Where("id > ?", afterID)
+	if r.db.Migrator().HasColumn(table, "deleted_at") {
+		query = query.Where("deleted_at IS NULL")
+	}
+	var count int64
+	err := query.Count(&count).Error
+	return count, err
+}
+
+// ListUsersAfter returns up to limit users with an ID above afterID, in ID order
+func (r *GormBackfillRepository) ListUsersAfter(afterID uint, limit int) ([]models.User, error) {
+	var users []models.User
+	if err := r.db.Where("id > ?", afterID).Order("id").Limit(limit).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// BackfillPriceCents populates a price's cents column for up to limit rows with an ID above afterID
+func (r *GormBackfillRepository) BackfillPriceCents(column models.PriceColumn, afterID uint, limit int) (models.BackfillBatch, error) {
+	return models.BackfillPriceCentsBatch(r.db, column, afterID, limit)
+}
+
+// NormalizeUserEmails normalizes the emails of up to limit users with an ID above afterID
+func (r *GormBackfillRepository) NormalizeUserEmails(afterID uint, limit int) (models.BackfillBatch, error) {
+	return models.NormalizeUserEmailsBatch(r.db, afterID, limit)
+}
+
+// EncryptUserPII encrypts the names and emails of up to limit users with an ID above afterID
+func (r *GormBackfillRepository) EncryptUserPII(afterID uint, limit int) (models.BackfillBatch, error) {
+	return models.EncryptUserPIIBatch(r.db, afterID, limit)
+}
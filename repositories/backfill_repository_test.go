@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestBackfillRepository_ClaimRun(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := models.MigrateUp(db); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+	repo := NewBackfillRepository(db)
+
+	run := models.BackfillRun{Name: "normalize-emails", Status: models.BackfillRunning, StartedByID: 1}
+	assert.NoError(t, repo.CreateRun(&run))
+
+	now := time.Now()
+	claimed, err := repo.ClaimRun(&run, now, now.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+	assert.NotNil(t, run.LeaseUntil)
+
+	// Another instance cannot claim the run while the lease holds
+	other := run
+	other.LeaseUntil = nil
+	claimed, err = repo.ClaimRun(&other, now.Add(30*time.Second), now.Add(2*time.Minute))
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+	assert.Nil(t, other.LeaseUntil)
+
+	// An expired lease can be taken over
+	claimed, err = repo.ClaimRun(&other, now.Add(2*time.Minute), now.Add(3*time.Minute))
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+
+	// Finished runs are never claimed
+	db.Model(&models.BackfillRun{}).Where("id = ?", run.ID).Updates(map[string]interface{}{"status": models.BackfillCompleted, "lease_until": nil})
+	claimed, err = repo.ClaimRun(&run, now.Add(time.Hour), now.Add(2*time.Hour))
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+}
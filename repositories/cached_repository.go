@@ -0,0 +1,152 @@
+package repositories
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/cache"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"gorm.io/gorm"
+)
+
+// Cache generations: bumping one makes every entry cached under it unreachable
+const (
+	ordersGeneration = "generation:orders"
+	usersGeneration  = "generation:users"
+)
+
+// cacheGenerations maps tables to the generations their writes invalidate
+// Orders embed their customer and technician, so user writes invalidate orders too
+var cacheGenerations = map[string][]string{
+	"orders":                {ordersGeneration},
+	"order_line_items":      {ordersGeneration},
+	"order_checklist_items": {ordersGeneration},
+	"users":                 {usersGeneration, ordersGeneration},
+}
+
+// RegisterCacheInvalidation bumps cache generations after every successful GORM write to a cached table
+// Writes inside a transaction invalidate before commit, so a read racing the commit can be cached
+// stale; such entries live at most one TTL
+func RegisterCacheInvalidation(db *gorm.DB, store cache.Store) error {
+	invalidate := func(tx *gorm.DB) {
+		if tx.Error != nil {
+			return
+		}
+		for _, generation := range cacheGenerations[tx.Statement.Table] {
+			if err := store.Incr(context.Background(), generation); err != nil {
+				log.Printf("Failed to invalidate %s cache: %v", generation, err)
+			}
+		}
+	}
+
+	callbacks := db.Callback()
+	if err := callbacks.Create().After("gorm:create").Register("cache:invalidate_create", invalidate); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("gorm:update").Register("cache:invalidate_update", invalidate); err != nil {
+		return err
+	}
+	return callbacks.Delete().After("gorm:delete").Register("cache:invalidate_delete", invalidate)
+}
+
+// readThrough returns the value cached under key in the given generation, or loads and caches it
+// Cache failures are logged and fall back to the loader, so an unavailable cache only costs speed
+func readThrough(store cache.Store, ttl time.Duration, generation, key string, dest interface{}, load func() error) error {
+	ctx := context.Background()
+
+	// Read the generation before loading so that a write during the load orphans this entry
+	current, err := store.Get(ctx, generation)
+	if errors.Is(err, cache.ErrMiss) {
+		current, err = []byte("0"), nil
+	}
+	if err != nil {
+		log.Printf("Cache unavailable, reading %s from the database: %v", key, err)
+		return load()
+	}
+	key = fmt.Sprintf("%s@%s", key, current)
+
+	if cached, err := store.Get(ctx, key); err == nil {
+		if err := gob.NewDecoder(bytes.NewReader(cached)).Decode(dest); err == nil {
+			return nil
+		}
+	} else if !errors.Is(err, cache.ErrMiss) {
+		log.Printf("Failed to read %s from cache: %v", key, err)
+	}
+
+	if err := load(); err != nil {
+		return err
+	}
+
+	var encoded bytes.Buffer
+	if err := gob.NewEncoder(&encoded).Encode(dest); err != nil {
+		log.Printf("Failed to encode %s for cache: %v", key, err)
+		return nil
+	}
+	if err := store.Set(ctx, key, encoded.Bytes(), ttl); err != nil {
+		log.Printf("Failed to write %s to cache: %v", key, err)
+	}
+	return nil
+}
+
+// CachedOrderRepository serves FindByIDWithRelations from a cache and delegates everything else
+type CachedOrderRepository struct {
+	OrderRepository
+	store cache.Store
+	ttl   time.Duration
+}
+
+// NewCachedOrderRepository puts the store in front of the repository's order lookups
+func NewCachedOrderRepository(orders OrderRepository, store cache.Store, ttl time.Duration) *CachedOrderRepository {
+	return &CachedOrderRepository{OrderRepository: orders, store: store, ttl: ttl}
+}
+
+// FindByIDWithRelations loads an order with its relationships, from the cache when possible
+func (r *CachedOrderRepository) FindByIDWithRelations(id uint) (*models.Order, error) {
+	var order models.Order
+	err := readThrough(r.store, r.ttl, ordersGeneration, fmt.Sprintf("order:%d", id), &order, func() error {
+		loaded, err := r.OrderRepository.FindByIDWithRelations(id)
+		if err != nil {
+			return err
+		}
+		order = *loaded
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// CachedUserRepository serves FindByAuth0ID from a cache and delegates everything else
+type CachedUserRepository struct {
+	UserRepository
+	store cache.Store
+	ttl   time.Duration
+}
+
+// NewCachedUserRepository puts the store in front of the repository's identity lookups
+func NewCachedUserRepository(users UserRepository, store cache.Store, ttl time.Duration) *CachedUserRepository {
+	return &CachedUserRepository{UserRepository: users, store: store, ttl: ttl}
+}
+
+// FindByAuth0ID loads a user by the identity provider subject, from the cache when possible
+func (r *CachedUserRepository) FindByAuth0ID(auth0ID string) (*models.User, error) {
+	var user models.User
+	err := readThrough(r.store, r.ttl, usersGeneration, "user:auth0:"+auth0ID, &user, func() error {
+		loaded, err := r.UserRepository.FindByAuth0ID(auth0ID)
+		if err != nil {
+			return err
+		}
+		user = *loaded
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/cache"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestCachedOrderRepository_InvalidatesOnWrite(t *testing.T) {
+	db, customer := setupAnalyticsTestDB(t)
+	store := cache.NewMemoryStore()
+	assert.NoError(t, RegisterCacheInvalidation(db, store))
+	repo := NewCachedOrderRepository(NewOrderRepository(db), store, time.Minute)
+
+	order := models.Order{Description: "Cached", Quantity: 1, Status: "submitted", CustomerID: customer.ID}
+	assert.NoError(t, db.Create(&order).Error)
+
+	loaded, err := repo.FindByIDWithRelations(order.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Cached", loaded.Description)
+	assert.Equal(t, "Customer", loaded.Customer.Name)
+
+	// Raw SQL bypasses GORM callbacks, so the cached copy is still served
+	assert.NoError(t, db.Exec("UPDATE orders SET description = ? WHERE id = ?", "Changed behind the cache", order.ID).Error)
+	loaded, err = repo.FindByIDWithRelations(order.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Cached", loaded.Description)
+
+	// Any GORM write to an order table invalidates, including checklist items
+	assert.NoError(t, db.Create(&models.OrderChecklistItem{OrderID: order.ID, Label: "Prep", Position: 1}).Error)
+	loaded, err = repo.FindByIDWithRelations(order.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Changed behind the cache", loaded.Description)
+	assert.Len(t, loaded.Checklist, 1)
+
+	// Orders embed their customer, so profile changes invalidate them too
+	assert.NoError(t, db.Model(&customer).Update("name", "Renamed").Error)
+	loaded, err = repo.FindByIDWithRelations(order.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Renamed", loaded.Customer.Name)
+
+	// Missing orders are not cached
+	_, err = repo.FindByIDWithRelations(999)
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+}
+
+func TestCachedUserRepository(t *testing.T) {
+	db, customer := setupAnalyticsTestDB(t)
+	store := cache.NewMemoryStore()
+	assert.NoError(t, RegisterCacheInvalidation(db, store))
+	repo := NewCachedUserRepository(NewUserRepository(db), store, time.Minute)
+
+	user, err := repo.FindByAuth0ID(customer.Auth0ID)
+	assert.NoError(t, err)
+	assert.Equal(t, customer.ID, user.ID)
+
+	assert.NoError(t, db.Exec("UPDATE users SET role = ? WHERE id = ?", "technician", customer.ID).Error)
+	user, err = repo.FindByAuth0ID(customer.Auth0ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "customer", user.Role)
+
+	assert.NoError(t, db.Model(&models.User{}).Where("id = ?", customer.ID).Update("name", "Renamed").Error)
+	user, err = repo.FindByAuth0ID(customer.Auth0ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "technician", user.Role)
+	assert.Equal(t, "Renamed", user.Name)
+}
+
+// failingStore is a cache.Store whose every operation fails
+type failingStore struct{}
+
+func (failingStore) Get(ctx context.Context, key string) ([]byte, error) {
+	return nil, errors.New("connection refused")
+}
+
+func (failingStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return errors.New("connection refused")
+}
+
+func (failingStore) Incr(ctx context.Context, key string) error {
+	return errors.New("connection refused")
+}
+
+func TestCachedOrderRepository_FallsBackWhenCacheFails(t *testing.T) {
+	db, customer := setupAnalyticsTestDB(t)
+	assert.NoError(t, RegisterCacheInvalidation(db, failingStore{}))
+	repo := NewCachedOrderRepository(NewOrderRepository(db), failingStore{}, time.Minute)
+
+	order := models.Order{Description: "Uncached", Quantity: 1, Status: "submitted", CustomerID: customer.ID}
+	assert.NoError(t, db.Create(&order).Error)
+
+	loaded, err := repo.FindByIDWithRelations(order.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Uncached", loaded.Description)
+}
@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"gorm.io/gorm"
+)
+
+// CannedReplyRepository provides persistence for technicians' message templates
+type CannedReplyRepository interface {
+	// Create inserts a new canned reply
+	Create(reply *models.CannedReply) error
+
+	// Save persists all fields of an existing canned reply
+	Save(reply *models.CannedReply) error
+
+	// Delete removes a canned reply
+	Delete(reply *models.CannedReply) error
+
+	// FindForUser loads a canned reply belonging to the user
+	FindForUser(userID, id uint) (*models.CannedReply, error)
+
+	// ListForUser returns the user's canned replies, by title
+	ListForUser(userID uint) ([]models.CannedReply, error)
+
+	// CountForUser returns how many canned replies the user has
+	CountForUser(userID uint) (int64, error)
+}
+
+// GormCannedReplyRepository implements CannedReplyRepository using GORM
+type GormCannedReplyRepository struct {
+	db *gorm.DB
+}
+
+// NewCannedReplyRepository creates a canned reply repository backed by the given database
+func NewCannedReplyRepository(db *gorm.DB) *GormCannedReplyRepository {
+	return &GormCannedReplyRepository{db: db}
+}
+
+// Create inserts a new canned reply
+func (r *GormCannedReplyRepository) Create(reply *models.CannedReply) error {
+	return r.db.Create(reply).Error
+}
+
+// Save persists all fields of an existing canned reply
+func (r *GormCannedReplyRepository) Save(reply *models.CannedReply) error {
+	return r.db.Save(reply).Error
+}
+
+// Delete soft deletes a canned reply; messages sent from it keep their expanded text
+func (r *GormCannedReplyRepository) Delete(reply *models.CannedReply) error {
+	return r.db.Delete(reply).Error
+}
+
+// FindForUser loads a canned reply belonging to the user
+func (r *GormCannedReplyRepository) FindForUser(userID, id uint) (*models.CannedReply, error) {
+	var reply models.CannedReply
+	if err := r.db.Where("user_id = ?", userID).First(&reply, id).Error; err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+// ListForUser returns the user's canned replies, by title
+func (r *GormCannedReplyRepository) ListForUser(userID uint) ([]models.CannedReply, error) {
+	var replies []models.CannedReply
+	if err := r.db.Where("user_id = ?", userID).Order("title ASC").Order("id ASC").Find(&replies).Error; err != nil {
+		return nil, err
+	}
+	return replies, nil
+}
+
+// CountForUser returns how many canned replies the user has
+func (r *GormCannedReplyRepository) CountForUser(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.CannedReply{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"gorm.io/gorm"
+)
+
+// CatalogRepository provides persistence for the catalog of pre-designed nail sets
+type CatalogRepository interface {
+	// Create inserts a new design
+	Create(design *models.CatalogDesign) error
+
+	// Save persists the fields of an existing design, leaving its images alone
+	Save(design *models.CatalogDesign) error
+
+	// Delete retires a design from the catalog
+	Delete(design *models.CatalogDesign) error
+
+	// FindByID loads a design that has not been retired, with its images
+	FindByID(id uint) (*models.CatalogDesign, error)
+
+	// List returns every design that has not been retired, with its images, ordered by name
+	List() ([]models.CatalogDesign, error)
+
+	// AddImage appends an image to a design, after its existing images
+	AddImage(image *models.CatalogDesignImage) error
+
+	// FindImage loads one image of a design
+	FindImage(designID, imageID uint) (*models.CatalogDesignImage, error)
+
+	// DeleteImage removes an image from a design
+	DeleteImage(image *models.CatalogDesignImage) error
+}
+
+// GormCatalogRepository implements CatalogRepository using GORM
+type GormCatalogRepository struct {
+	db *gorm.DB
+}
+
+// NewCatalogRepository creates a catalog repository backed by the given database
+func NewCatalogRepository(db *gorm.DB) *GormCatalogRepository {
+	return &GormCatalogRepository{db: db}
+}
+
+// Create inserts a new design
+func (r *GormCatalogRepository) Create(design *models.CatalogDesign) error {
+	return r.db.Omit("Images").Create(design).Error
+}
+
+// Save persists the fields of an existing design, leaving its images alone
+func (r *GormCatalogRepository) Save(design *models.CatalogDesign) error {
+	return r.db.Omit("Images").Save(design).Error
+}
+
+// Delete soft deletes a design so orders placed from it keep their reference
+func (r *GormCatalogRepository) Delete(design *models.CatalogDesign) error {
+	return r.db.Delete(design).Error
+}
+
+// FindByID loads a design that has not been retired, with its images
+func (r *GormCatalogRepository) FindByID(id uint) (*models.CatalogDesign, error) {
+	var design models.CatalogDesign
+	if err := r.db.Preload("Images", byImagePosition).First(&design, id).Error; err != nil {
+		return nil, err
+	}
+	return &design, nil
+}
+
+// List returns every design that has not been retired, with its images, ordered by name
+func (r *GormCatalogRepository) List() ([]models.CatalogDesign, error) {
+	var designs []models.CatalogDesign
+	if err := r.db.Preload("Images", byImagePosition).Order("name ASC, id ASC").Find(&designs).Error; err != nil {
+		return nil, err
+	}
+	return designs, nil
+}
+
+// AddImage appends an image to a design, after its existing images
+func (r *GormCatalogRepository) AddImage(image *models.CatalogDesignImage) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var last struct{ Position *int }
+		if err := tx.Model(&models.CatalogDesignImage{}).
+			Select("MAX(position) AS position").
+			Where("design_id = ?", image.DesignID).
+			Scan(&last).Error; err != nil {
+			return err
+		}
+		image.Position = 0
+		if last.Position != nil {
+			image.Position = *last.Position + 1
+		}
+		return tx.Create(image).Error
+	})
+}
+
+// FindImage loads one image of a design
+func (r *GormCatalogRepository) FindImage(designID, imageID uint) (*models.CatalogDesignImage, error) {
+	var image models.CatalogDesignImage
+	if err := r.db.Where("design_id = ?", designID).First(&image, imageID).Error; err != nil {
+		return nil, err
+	}
+	return &image, nil
+}
+
+// DeleteImage removes an image from a design
+func (r *GormCatalogRepository) DeleteImage(image *models.CatalogDesignImage) error {
+	return r.db.Delete(image).Error
+}
+
+// byImagePosition sorts preloaded design images by position
+func byImagePosition(db *gorm.DB) *gorm.DB {
+	return db.Order("position ASC, id ASC")
+}
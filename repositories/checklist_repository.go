@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"gorm.io/gorm"
+)
+
+// ChecklistRepository provides persistence for checklist templates and order checklists
+type ChecklistRepository interface {
+	// ListTemplate returns a technician's template items in order
+	ListTemplate(technicianID uint) ([]models.ChecklistTemplateItem, error)
+
+	// ReplaceTemplate swaps a technician's template for the given items
+	ReplaceTemplate(technicianID uint, items []models.ChecklistTemplateItem) error
+
+	// ListForOrder returns an order's checklist items in order
+	ListForOrder(orderID uint) ([]models.OrderChecklistItem, error)
+
+	// CreateForOrder inserts checklist items for an order
+	CreateForOrder(items []models.OrderChecklistItem) error
+
+	// FindItem loads a checklist item belonging to the order
+	FindItem(orderID, itemID uint) (*models.OrderChecklistItem, error)
+
+	// SaveItem persists all fields of an existing checklist item
+	SaveItem(item *models.OrderChecklistItem) error
+}
+
+// GormChecklistRepository implements ChecklistRepository using GORM
+type GormChecklistRepository struct {
+	db *gorm.DB
+}
+
+// NewChecklistRepository creates a checklist repository backed by the given database
+func NewChecklistRepository(db *gorm.DB) *GormChecklistRepository {
+	return &GormChecklistRepository{db: db}
+}
+
+// ListTemplate returns a technician's template items in order
+func (r *GormChecklistRepository) ListTemplate(technicianID uint) ([]models.ChecklistTemplateItem, error) {
+	var items []models.ChecklistTemplateItem
+	if err := r.db.Where("technician_id = ?", technicianID).Order("position ASC").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// ReplaceTemplate swaps a technician's template for the given items in a single transaction
+func (r *GormChecklistRepository) ReplaceTemplate(technicianID uint, items []models.ChecklistTemplateItem) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("technician_id = ?", technicianID).Delete(&models.ChecklistTemplateItem{}).Error; err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return nil
+		}
+		return tx.Create(&items).Error
+	})
+}
+
+// ListForOrder returns an order's checklist items in order
+func (r *GormChecklistRepository) ListForOrder(orderID uint) ([]models.OrderChecklistItem, error) {
+	var items []models.OrderChecklistItem
+	if err := r.db.Where("order_id = ?", orderID).Order("position ASC").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// CreateForOrder inserts checklist items for an order
+func (r *GormChecklistRepository) CreateForOrder(items []models.OrderChecklistItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	return r.db.Create(&items).Error
+}
+
+// FindItem loads a checklist item belonging to the order
+func (r *GormChecklistRepository) FindItem(orderID, itemID uint) (*models.OrderChecklistItem, error) {
+	var item models.OrderChecklistItem
+	if err := r.db.Where("order_id = ?", orderID).First(&item, itemID).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// SaveItem persists all fields of an existing checklist item
+func (r *GormChecklistRepository) SaveItem(item *models.OrderChecklistItem) error {
+	return r.db.Save(item).Error
+}
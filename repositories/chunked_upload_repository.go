@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"gorm.io/gorm"
+)
+
+// ChunkedUploadRepository provides persistence for uploads in progress
+type ChunkedUploadRepository interface {
+	// Create inserts an upload
+	Create(upload *models.ChunkedUpload) error
+
+	// Save updates an upload
+	Save(upload *models.ChunkedUpload) error
+
+	// FindByID returns the upload with the given ID
+	FindByID(id uint) (*models.ChunkedUpload, error)
+
+	// Advance moves an upload's offset from one value to another, reporting false if it was no longer at from
+	// The check and update are a single statement, so two requests sending the same chunk cannot both advance it
+	Advance(id uint, from, to int64) (bool, error)
+
+	// Delete removes an upload
+	Delete(id uint) error
+
+	// FindExpired returns up to limit uploads that expired before the given time
+	FindExpired(before time.Time, limit int) ([]models.ChunkedUpload, error)
+}
+
+// GormChunkedUploadRepository implements ChunkedUploadRepository using GORM
+type GormChunkedUploadRepository struct {
+	db *gorm.DB
+}
+
+// NewChunkedUploadRepository creates a chunked upload repository backed by the given database
+func NewChunkedUploadRepository(db *gorm.DB) *GormChunkedUploadRepository {
+	return &GormChunkedUploadRepository{db: db}
+}
+
+// Create inserts an upload
+func (r *GormChunkedUploadRepository) Create(upload *models.ChunkedUpload) error {
+	return r.db.Create(upload).Error
+}
+
+// Save updates an upload
+func (r *GormChunkedUploadRepository) Save(upload *models.ChunkedUpload) error {
+	return r.db.Save(upload).Error
+}
+
+// FindByID returns the upload with the given ID
+func (r *GormChunkedUploadRepository) FindByID(id uint) (*models.ChunkedUpload, error) {
+	var upload models.ChunkedUpload
+	if err := r.db.First(&upload, id).Error; err != nil {
+		return nil, err
+	}
+	return &upload, nil
+}
+
+// Advance moves an upload's offset from one value to another, reporting false if it was no longer at from
+// The check and update are a single statement, so two requests sending the same chunk cannot both advance it
+func (r *GormChunkedUploadRepository) Advance(id uint, from, to int64) (bool, error) {
+	result := r.db.Model(&models.ChunkedUpload{}).Where("id = ? AND upload_offset = ?", id, from).Update("upload_offset", to)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Delete removes an upload
+func (r *GormChunkedUploadRepository) Delete(id uint) error {
+	return r.db.Delete(&models.ChunkedUpload{}, id).Error
+}
+
+// FindExpired returns up to limit uploads that expired before the given time
+func (r *GormChunkedUploadRepository) FindExpired(before time.Time, limit int) ([]models.ChunkedUpload, error) {
+	var uploads []models.ChunkedUpload
+	if err := r.db.Where("expires_at < ?", before).Order("id").Limit(limit).Find(&uploads).Error; err != nil {
+		return nil, err
+	}
+	return uploads, nil
+}
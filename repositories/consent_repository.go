@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ConsentRepository provides persistence for users' acceptances of the terms of service
+type ConsentRepository interface {
+	// CreateIfAbsent records an acceptance unless the user already accepted that version,
+	// reporting whether it was inserted
+	CreateIfAbsent(consent *models.UserConsent) (bool, error)
+
+	// FindByUserAndVersion loads the user's acceptance of a terms version
+	FindByUserAndVersion(userID uint, version string) (*models.UserConsent, error)
+
+	// ListByUser returns the user's acceptances, newest version first
+	ListByUser(userID uint) ([]models.UserConsent, error)
+
+	// LatestVersion returns the newest terms version the user accepted, or "" when they accepted none
+	LatestVersion(userID uint) (string, error)
+}
+
+// GormConsentRepository implements ConsentRepository using GORM
+type GormConsentRepository struct {
+	db *gorm.DB
+}
+
+// NewConsentRepository creates a consent repository backed by the given database
+func NewConsentRepository(db *gorm.DB) *GormConsentRepository {
+	return &GormConsentRepository{db: db}
+}
+
+// CreateIfAbsent records an acceptance unless the user already accepted that version,
+// reporting whether it was inserted
+func (r *GormConsentRepository) CreateIfAbsent(consent *models.UserConsent) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "terms_version"}},
+		DoNothing: true,
+	}).Create(consent)
+	return result.RowsAffected > 0, result.Error
+}
+
+// FindByUserAndVersion loads the user's acceptance of a terms version
+func (r *GormConsentRepository) FindByUserAndVersion(userID uint, version string) (*models.UserConsent, error) {
+	var consent models.UserConsent
+	if err := r.db.Where("user_id = ? AND terms_version = ?", userID, version).First(&consent).Error; err != nil {
+		return nil, err
+	}
+	return &consent, nil
+}
+
+// ListByUser returns the user's acceptances, newest version first
+func (r *GormConsentRepository) ListByUser(userID uint) ([]models.UserConsent, error) {
+	var consents []models.UserConsent
+	if err := r.db.Where("user_id = ?", userID).Order("terms_version DESC").Find(&consents).Error; err != nil {
+		return nil, err
+	}
+	return consents, nil
+}
+
+// LatestVersion returns the newest terms version the user accepted, or "" when they accepted none
+// Versions are dates in YYYY-MM-DD form, so they sort as strings
+func (r *GormConsentRepository) LatestVersion(userID uint) (string, error) {
+	var versions []string
+	if err := r.db.Model(&models.UserConsent{}).Where("user_id = ?", userID).
+		Order("terms_version DESC").Limit(1).Pluck("terms_version", &versions).Error; err != nil {
+		return "", err
+	}
+	if len(versions) == 0 {
+		return "", nil
+	}
+	return versions[0], nil
+}
@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ConversationRepository provides persistence for the delivery and read state of order conversations
+type ConversationRepository interface {
+	// FindMessage loads a message belonging to the order
+	FindMessage(orderID, messageID uint) (*models.Message, error)
+
+	// ListReadStates returns the read state of every participant of the order's conversation
+	ListReadStates(orderID uint) ([]models.MessageReadState, error)
+
+	// AdvanceDelivered moves the user's last delivered message forward to messageID,
+	// reporting whether it moved; it never moves back
+	AdvanceDelivered(orderID, userID, messageID uint) (bool, error)
+
+	// AdvanceSeen moves the user's last seen message, and with it the last delivered one, forward to messageID,
+	// reporting whether the last seen message moved; neither ever moves back
+	AdvanceSeen(orderID, userID, messageID uint) (bool, error)
+}
+
+// GormConversationRepository implements ConversationRepository using GORM
+type GormConversationRepository struct {
+	db *gorm.DB
+}
+
+// NewConversationRepository creates a conversation repository backed by the given database
+func NewConversationRepository(db *gorm.DB) *GormConversationRepository {
+	return &GormConversationRepository{db: db}
+}
+
+// FindMessage loads a message belonging to the order
+func (r *GormConversationRepository) FindMessage(orderID, messageID uint) (*models.Message, error) {
+	var message models.Message
+	if err := r.db.Where("order_id = ?", orderID).First(&message, messageID).Error; err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+// ListReadStates returns the read state of every participant of the order's conversation
+func (r *GormConversationRepository) ListReadStates(orderID uint) ([]models.MessageReadState, error) {
+	var states []models.MessageReadState
+	if err := r.db.Where("order_id = ?", orderID).Order("user_id ASC").Find(&states).Error; err != nil {
+		return nil, err
+	}
+	return states, nil
+}
+
+// AdvanceDelivered moves the user's last delivered message forward to messageID
+// The update is conditional, so concurrent requests cannot move it back
+func (r *GormConversationRepository) AdvanceDelivered(orderID, userID, messageID uint) (bool, error) {
+	if err := r.ensureReadState(orderID, userID); err != nil {
+		return false, err
+	}
+	result := r.db.Model(&models.MessageReadState{}).
+		Where("order_id = ? AND user_id = ? AND last_delivered_message_id < ?", orderID, userID, messageID).
+		Update("last_delivered_message_id", messageID)
+	return result.RowsAffected > 0, result.Error
+}
+
+// AdvanceSeen moves the user's last seen message, and with it the last delivered one, forward to messageID
+func (r *GormConversationRepository) AdvanceSeen(orderID, userID, messageID uint) (bool, error) {
+	if err := r.ensureReadState(orderID, userID); err != nil {
+		return false, err
+	}
+	result := r.db.Model(&models.MessageReadState{}).
+		Where("order_id = ? AND user_id = ? AND last_seen_message_id < ?", orderID, userID, messageID).
+		Updates(map[string]interface{}{
+			"last_seen_message_id": messageID,
+			"last_delivered_message_id": gorm.Expr(
+				"CASE WHEN last_delivered_message_id < ? THEN ? ELSE last_delivered_message_id END", messageID, messageID),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ensureReadState creates the user's read state of the conversation unless it exists
+func (r *GormConversationRepository) ensureReadState(orderID, userID uint) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "order_id"}, {Name: "user_id"}},
+		DoNothing: true,
+	}).Create(&models.MessageReadState{OrderID: orderID, UserID: userID}).Error
+}
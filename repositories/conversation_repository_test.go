@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestConversationRepository_ReadState(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := models.MigrateUp(db); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+	repo := NewConversationRepository(db)
+
+	message := models.Message{OrderID: 1, Text: "Hello"}
+	assert.NoError(t, db.Omit("Order", "Sender", "Annotation").Create(&message).Error)
+	_, err = repo.FindMessage(1, message.ID)
+	assert.NoError(t, err)
+	_, err = repo.FindMessage(2, message.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	moved, err := repo.AdvanceDelivered(1, 7, 5)
+	assert.NoError(t, err)
+	assert.True(t, moved)
+
+	// Neither position moves back
+	moved, err = repo.AdvanceDelivered(1, 7, 3)
+	assert.NoError(t, err)
+	assert.False(t, moved)
+
+	moved, err = repo.AdvanceSeen(1, 7, 2)
+	assert.NoError(t, err)
+	assert.True(t, moved)
+
+	states, err := repo.ListReadStates(1)
+	assert.NoError(t, err)
+	if assert.Len(t, states, 1) {
+		assert.Equal(t, uint(5), states[0].LastDeliveredMessageID)
+		assert.Equal(t, uint(2), states[0].LastSeenMessageID)
+	}
+
+	// Seeing a later message delivers it too
+	moved, err = repo.AdvanceSeen(1, 7, 9)
+	assert.NoError(t, err)
+	assert.True(t, moved)
+	moved, err = repo.AdvanceSeen(1, 7, 9)
+	assert.NoError(t, err)
+	assert.False(t, moved)
+
+	states, err = repo.ListReadStates(1)
+	assert.NoError(t, err)
+	if assert.Len(t, states, 1) {
+		assert.Equal(t, uint(9), states[0].LastDeliveredMessageID)
+		assert.Equal(t, uint(9), states[0].LastSeenMessageID)
+	}
+}
@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"gorm.io/gorm"
+)
+
+// FeatureFlagRepository provides persistence for feature flags
+type FeatureFlagRepository interface {
+	// Create inserts a new flag
+	Create(flag *models.FeatureFlag) error
+
+	// FindByName loads a flag by name
+	FindByName(name string) (*models.FeatureFlag, error)
+
+	// List returns every flag, ordered by name
+	List() ([]models.FeatureFlag, error)
+
+	// Save persists all fields of an existing flag
+	Save(flag *models.FeatureFlag) error
+
+	// Delete removes a flag; its name can then be reused
+	Delete(flag *models.FeatureFlag) error
+}
+
+// GormFeatureFlagRepository implements FeatureFlagRepository using GORM
+type GormFeatureFlagRepository struct {
+	db *gorm.DB
+}
+
+// NewFeatureFlagRepository creates a feature flag repository backed by the given database
+func NewFeatureFlagRepository(db *gorm.DB) *GormFeatureFlagRepository {
+	return &GormFeatureFlagRepository{db: db}
+}
+
+// Create inserts a new flag
+func (r *GormFeatureFlagRepository) Create(flag *models.FeatureFlag) error {
+	return r.db.Create(flag).Error
+}
+
+// FindByName loads a flag by name
+func (r *GormFeatureFlagRepository) FindByName(name string) (*models.FeatureFlag, error) {
+	var flag models.FeatureFlag
+	if err := r.db.Where("name = ?", name).First(&flag).Error; err != nil {
+		return nil, err
+	}
+	return &flag, nil
+}
+
+// List returns every flag, ordered by name
+func (r *GormFeatureFlagRepository) List() ([]models.FeatureFlag, error) {
+	var flags []models.FeatureFlag
+	if err := r.db.Order("name ASC").Find(&flags).Error; err != nil {
+		return nil, err
+	}
+	return flags, nil
+}
+
+// Save persists all fields of an existing flag
+func (r *GormFeatureFlagRepository) Save(flag *models.FeatureFlag) error {
+	return r.db.Save(flag).Error
+}
+
+// Delete removes a flag; its name can then be reused
+func (r *GormFeatureFlagRepository) Delete(flag *models.FeatureFlag) error {
+	return r.db.Delete(flag).Error
+}
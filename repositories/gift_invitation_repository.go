@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"gorm.io/gorm"
+)
+
+// ErrGiftClaimed is returned by Claim when the gift was claimed concurrently
+var ErrGiftClaimed = errors.New("gift has already been claimed")
+
+// GiftInvitationRepository provides persistence for invitations to claim gift orders
+type GiftInvitationRepository interface {
+	// Create inserts a new gift invitation
+	Create(invitation *models.GiftInvitation) error
+
+	// FindByClaimHash loads a gift invitation by the hash of its claim code
+	FindByClaimHash(hash string) (*models.GiftInvitation, error)
+
+	// Claim marks the invitation claimed by the recipient and links its order to them in a single transaction
+	Claim(invitation *models.GiftInvitation, recipientID uint) error
+}
+
+// GormGiftInvitationRepository implements GiftInvitationRepository using GORM
+type GormGiftInvitationRepository struct {
+	db *gorm.DB
+}
+
+// NewGiftInvitationRepository creates a gift invitation repository backed by the given database
+func NewGiftInvitationRepository(db *gorm.DB) *GormGiftInvitationRepository {
+	return &GormGiftInvitationRepository{db: db}
+}
+
+// Create inserts a new gift invitation
+func (r *GormGiftInvitationRepository) Create(invitation *models.GiftInvitation) error {
+	return r.db.Create(invitation).Error
+}
+
+// FindByClaimHash loads a gift invitation by the hash of its claim code
+func (r *GormGiftInvitationRepository) FindByClaimHash(hash string) (*models.GiftInvitation, error) {
+	var invitation models.GiftInvitation
+	if err := r.db.Where("claim_code_hash = ?", hash).First(&invitation).Error; err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// Claim marks the invitation claimed by the recipient and links its order to them in a single transaction
+// The invitation is only claimed if no one else has claimed it first
+func (r *GormGiftInvitationRepository) Claim(invitation *models.GiftInvitation, recipientID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&models.GiftInvitation{}).
+			Where("id = ? AND claimed_at IS NULL", invitation.ID).
+			UpdateColumns(map[string]interface{}{
+				"claimed_by_id": recipientID,
+				"claimed_at":    now,
+				"updated_at":    now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrGiftClaimed
+		}
+
+		if err := tx.Model(&models.Order{}).Where("id = ?", invitation.OrderID).Updates(map[string]interface{}{
+			"recipient_id": recipientID,
+			"version":      gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return err
+		}
+
+		invitation.ClaimedByID = &recipientID
+		invitation.ClaimedAt = &now
+		return nil
+	})
+}
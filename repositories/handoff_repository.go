@@ -0,0 +1,130 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"gorm.io/gorm"
+)
+
+// ErrOrderReassigned is returned by CompleteHandoff when the order is no longer
+// assigned to the technician who requested the hand-off
+var ErrOrderReassigned = errors.New("order is no longer assigned to the handing-off technician")
+
+// HandoffRepository provides persistence for order hand-offs
+type HandoffRepository interface {
+	// Create inserts a new hand-off
+	Create(handoff *models.OrderHandoff) error
+
+	// Save persists all fields of an existing hand-off
+	Save(handoff *models.OrderHandoff) error
+
+	// FindByID loads a hand-off belonging to the order, with both technicians
+	FindByID(orderID, handoffID uint) (*models.OrderHandoff, error)
+
+	// FindPending returns the order's pending hand-off, or gorm.ErrRecordNotFound
+	FindPending(orderID uint) (*models.OrderHandoff, error)
+
+	// ListForOrder returns an order's hand-offs, oldest first
+	ListForOrder(orderID uint) ([]models.OrderHandoff, error)
+
+	// ListPendingFor returns the hand-offs awaiting the technician's answer, with their orders
+	ListPendingFor(technicianID uint) ([]models.OrderHandoff, error)
+
+	// CompleteHandoff saves an accepted hand-off, reassigns the order to its target,
+	// and records the customer notice in a single transaction
+	CompleteHandoff(handoff *models.OrderHandoff, notice *models.Message) error
+}
+
+// GormHandoffRepository implements HandoffRepository using GORM
+type GormHandoffRepository struct {
+	db *gorm.DB
+}
+
+// NewHandoffRepository creates a hand-off repository backed by the given database
+func NewHandoffRepository(db *gorm.DB) *GormHandoffRepository {
+	return &GormHandoffRepository{db: db}
+}
+
+// Create inserts a new hand-off
+func (r *GormHandoffRepository) Create(handoff *models.OrderHandoff) error {
+	return r.db.Create(handoff).Error
+}
+
+// Save persists all fields of an existing hand-off
+func (r *GormHandoffRepository) Save(handoff *models.OrderHandoff) error {
+	return r.db.Omit("Order", "FromTechnician", "ToTechnician").Save(handoff).Error
+}
+
+// FindByID loads a hand-off belonging to the order, with both technicians
+func (r *GormHandoffRepository) FindByID(orderID, handoffID uint) (*models.OrderHandoff, error) {
+	var handoff models.OrderHandoff
+	if err := r.db.Preload("FromTechnician").Preload("ToTechnician").
+		Where("order_id = ?", orderID).
+		First(&handoff, handoffID).Error; err != nil {
+		return nil, err
+	}
+	return &handoff, nil
+}
+
+// FindPending returns the order's pending hand-off, or gorm.ErrRecordNotFound
+func (r *GormHandoffRepository) FindPending(orderID uint) (*models.OrderHandoff, error) {
+	var handoff models.OrderHandoff
+	if err := r.db.Where("order_id = ? AND status = ?", orderID, models.HandoffPending).
+		First(&handoff).Error; err != nil {
+		return nil, err
+	}
+	return &handoff, nil
+}
+
+// ListForOrder returns an order's hand-offs, oldest first
+func (r *GormHandoffRepository) ListForOrder(orderID uint) ([]models.OrderHandoff, error) {
+	var handoffs []models.OrderHandoff
+	if err := r.db.Preload("FromTechnician").Preload("ToTechnician").
+		Where("order_id = ?", orderID).
+		Order("created_at ASC").Order("id ASC").
+		Find(&handoffs).Error; err != nil {
+		return nil, err
+	}
+	return handoffs, nil
+}
+
+// ListPendingFor returns the hand-offs awaiting the technician's answer, with their orders
+func (r *GormHandoffRepository) ListPendingFor(technicianID uint) ([]models.OrderHandoff, error) {
+	var handoffs []models.OrderHandoff
+	if err := r.db.Preload("FromTechnician").Preload("ToTechnician").
+		Preload("Order").Preload("Order.Customer").
+		Where("to_technician_id = ? AND status = ?", technicianID, models.HandoffPending).
+		Order("created_at ASC").Order("id ASC").
+		Find(&handoffs).Error; err != nil {
+		return nil, err
+	}
+	return handoffs, nil
+}
+
+// CompleteHandoff saves an accepted hand-off, reassigns the order to its target,
+// and records the customer notice in a single transaction
+// The order is only reassigned while it still belongs to the handing-off technician
+func (r *GormHandoffRepository) CompleteHandoff(handoff *models.OrderHandoff, notice *models.Message) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Order{}).
+			Where("id = ? AND technician_id = ?", handoff.OrderID, handoff.FromTechnicianID).
+			UpdateColumns(map[string]interface{}{
+				"technician_id": handoff.ToTechnicianID,
+				"version":       gorm.Expr("version + 1"),
+				"updated_at":    time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrOrderReassigned
+		}
+
+		if err := tx.Omit("Order", "FromTechnician", "ToTechnician").Save(handoff).Error; err != nil {
+			return err
+		}
+		return tx.Omit("Order", "Sender").Create(notice).Error
+	})
+}
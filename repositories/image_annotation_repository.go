@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"gorm.io/gorm"
+)
+
+// ImageAnnotationRepository provides persistence for annotated messages on order images
+type ImageAnnotationRepository interface {
+	// Create inserts a message and the annotation anchoring it to an image in a single transaction
+	Create(message *models.Message, annotation *models.ImageAnnotation) error
+
+	// FindMessage loads an annotated message with its sender and annotation
+	FindMessage(messageID uint) (*models.Message, error)
+}
+
+// GormImageAnnotationRepository implements ImageAnnotationRepository using GORM
+type GormImageAnnotationRepository struct {
+	db *gorm.DB
+}
+
+// NewImageAnnotationRepository creates an image annotation repository backed by the given database
+func NewImageAnnotationRepository(db *gorm.DB) *GormImageAnnotationRepository {
+	return &GormImageAnnotationRepository{db: db}
+}
+
+// Create inserts a message and the annotation anchoring it to an image in a single transaction
+func (r *GormImageAnnotationRepository) Create(message *models.Message, annotation *models.ImageAnnotation) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Order", "Sender", "Annotation").Create(message).Error; err != nil {
+			return err
+		}
+		annotation.MessageID = message.ID
+		return tx.Create(annotation).Error
+	})
+}
+
+// FindMessage loads an annotated message with its sender and annotation
+func (r *GormImageAnnotationRepository) FindMessage(messageID uint) (*models.Message, error) {
+	var message models.Message
+	if err := r.db.Preload("Sender").Preload("Annotation").First(&message, messageID).Error; err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
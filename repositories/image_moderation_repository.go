@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"gorm.io/gorm"
+)
+
+// ImageModerationRepository provides persistence for image screening verdicts
+type ImageModerationRepository interface {
+	// Create inserts a screening verdict
+	Create(moderation *models.ImageModeration) error
+
+	// Save updates a screening verdict
+	Save(moderation *models.ImageModeration) error
+
+	// FindByID returns the screening verdict with the given ID
+	FindByID(id uint) (*models.ImageModeration, error)
+
+	// FindHeld returns the status of each of the given images that is not approved, keyed by image key
+	// Images that were never screened are not included
+	FindHeld(imageKeys []string) (map[string]string, error)
+
+	// List returns a page of the verdicts with the given status, oldest first, with the total number that match
+	// An empty status lists every verdict
+	List(status string, limit, offset int) ([]models.ImageModeration, int64, error)
+
+	// DeleteByKeys removes the verdicts of images that have been deleted from storage
+	DeleteByKeys(imageKeys []string) error
+}
+
+// GormImageModerationRepository implements ImageModerationRepository using GORM
+type GormImageModerationRepository struct {
+	db *gorm.DB
+}
+
+// NewImageModerationRepository creates an image moderation repository backed by the given database
+func NewImageModerationRepository(db *gorm.DB) *GormImageModerationRepository {
+	return &GormImageModerationRepository{db: db}
+}
+
+// Create inserts a screening verdict
+func (r *GormImageModerationRepository) Create(moderation *models.ImageModeration) error {
+	return r.db.Create(moderation).Error
+}
+
+// Save updates a screening verdict
+func (r *GormImageModerationRepository) Save(moderation *models.ImageModeration) error {
+	return r.db.Save(moderation).Error
+}
+
+// FindByID returns the screening verdict with the given ID
+func (r *GormImageModerationRepository) FindByID(id uint) (*models.ImageModeration, error) {
+	var moderation models.ImageModeration
+	if err := r.db.First(&moderation, id).Error; err != nil {
+		return nil, err
+	}
+	return &moderation, nil
+}
+
+// FindHeld returns the status of each of the given images that is not approved, keyed by image key
+// Images that were never screened are not included
+func (r *GormImageModerationRepository) FindHeld(imageKeys []string) (map[string]string, error) {
+	held := make(map[string]string)
+	if len(imageKeys) == 0 {
+		return held, nil
+	}
+
+	var moderations []models.ImageModeration
+	if err := r.db.Select("image_s3_key", "status").
+		Where("image_s3_key IN ? AND status <> ?", imageKeys, models.ImageApproved).
+		Find(&moderations).Error; err != nil {
+		return nil, err
+	}
+	for _, moderation := range moderations {
+		held[moderation.ImageS3Key] = moderation.Status
+	}
+	return held, nil
+}
+
+// List returns a page of the verdicts with the given status, oldest first, with the total number that match
+// An empty status lists every verdict
+func (r *GormImageModerationRepository) List(status string, limit, offset int) ([]models.ImageModeration, int64, error) {
+	scope := r.db.Model(&models.ImageModeration{})
+	if status != "" {
+		scope = scope.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := scope.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var moderations []models.ImageModeration
+	if err := scope.Order("created_at, id").Limit(limit).Offset(offset).Find(&moderations).Error; err != nil {
+		return nil, 0, err
+	}
+	return moderations, total, nil
+}
+
+// DeleteByKeys removes the verdicts of images that have been deleted from storage
+func (r *GormImageModerationRepository) DeleteByKeys(imageKeys []string) error {
+	if len(imageKeys) == 0 {
+		return nil
+	}
+	return r.db.Where("image_s3_key IN ?", imageKeys).Delete(&models.ImageModeration{}).Error
+}
@@ -0,0 +1,41 @@
+package repositories
+
+import "gorm.io/gorm"
+
+// imageReferenceTables are the tables whose image_s3_key column refers to an uploaded image
+// Soft-deleted rows count, since restoring them brings their image back into use
+var imageReferenceTables = []string{"orders", "saved_designs", "intake_drafts", "quote_requests", "progress_updates", "catalog_design_images"}
+
+// ImageReferenceRepository finds which uploaded images are still in use
+type ImageReferenceRepository interface {
+	// FindReferenced returns the subset of the given image keys that any record refers to
+	FindReferenced(imageKeys []string) (map[string]bool, error)
+}
+
+// GormImageReferenceRepository implements ImageReferenceRepository using GORM
+type GormImageReferenceRepository struct {
+	db *gorm.DB
+}
+
+// NewImageReferenceRepository creates an image reference repository backed by the given database
+func NewImageReferenceRepository(db *gorm.DB) *GormImageReferenceRepository {
+	return &GormImageReferenceRepository{db: db}
+}
+
+// FindReferenced returns the subset of the given image keys that any record refers to
+func (r *GormImageReferenceRepository) FindReferenced(imageKeys []string) (map[string]bool, error) {
+	referenced := make(map[string]bool)
+	if len(imageKeys) == 0 {
+		return referenced, nil
+	}
+	for _, table := range imageReferenceTables {
+		var keys []string
+		if err := r.db.Table(table).Distinct("image_s3_key").Where("image_s3_key IN ?", imageKeys).Pluck("image_s3_key", &keys).Error; err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			referenced[key] = true
+		}
+	}
+	return referenced, nil
+}
@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestImageReferenceRepository_FindReferenced(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := models.MigrateUp(db); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+	repo := NewImageReferenceRepository(db)
+
+	orderKey, designKey, updateKey := "uploads/1_order.png", "uploads/2_design.png", "uploads/3_update.png"
+	order := models.Order{Description: "Chrome", Quantity: 1, Status: "submitted", CustomerID: 1, ImageS3Key: &orderKey}
+	assert.NoError(t, db.Create(&order).Error)
+	assert.NoError(t, db.Create(&models.SavedDesign{CustomerID: 1, Description: "Chrome", ImageS3Key: &designKey}).Error)
+	update := models.ProgressUpdate{OrderID: order.ID, AuthorID: 2, ImageS3Key: updateKey}
+	assert.NoError(t, db.Create(&update).Error)
+
+	// A soft-deleted record still holds on to its image
+	assert.NoError(t, db.Delete(&update).Error)
+
+	referenced, err := repo.FindReferenced([]string{orderKey, designKey, updateKey, "uploads/4_orphan.png"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{orderKey: true, designKey: true, updateKey: true}, referenced)
+}
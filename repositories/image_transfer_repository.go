@@ -0,0 +1,140 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"gorm.io/gorm"
+)
+
+// ImageTransferRepository provides persistence for order images waiting to be moved to storage
+type ImageTransferRepository interface {
+	// Create inserts a transfer
+	Create(transfer *models.ImageTransfer) error
+
+	// FindByID returns the transfer with the given ID
+	FindByID(id uint) (*models.ImageTransfer, error)
+
+	// Save records the progress of a transfer; the order it belongs to is only set by AttachOrder
+	Save(transfer *models.ImageTransfer) error
+
+	// Delete removes a transfer
+	Delete(id uint) error
+
+	// ListDue returns up to limit of the host's pending transfers whose next attempt is due, oldest first
+	ListDue(host string, now time.Time, limit int) ([]models.ImageTransfer, error)
+
+	// Claim counts an attempt on a due transfer and moves its next attempt to leaseUntil
+	// It reports false when another worker claimed the transfer first
+	Claim(transfer *models.ImageTransfer, leaseUntil time.Time) (bool, error)
+
+	// AttachOrder records the order a transfer's image belongs to
+	AttachOrder(id, orderID uint) error
+
+	// SetOrderImage sets the image key of an order
+	// It reports false when the order is not there, as when the request creating it has not committed yet
+	SetOrderImage(orderID uint, key string) (bool, error)
+
+	// PendingOrderIDs returns which of the given orders have a transfer still pending
+	PendingOrderIDs(orderIDs []uint) ([]uint, error)
+}
+
+// GormImageTransferRepository implements ImageTransferRepository using GORM
+type GormImageTransferRepository struct {
+	db *gorm.DB
+}
+
+// NewImageTransferRepository creates an image transfer repository backed by the given database
+func NewImageTransferRepository(db *gorm.DB) *GormImageTransferRepository {
+	return &GormImageTransferRepository{db: db}
+}
+
+// Create inserts a transfer
+func (r *GormImageTransferRepository) Create(transfer *models.ImageTransfer) error {
+	return r.db.Create(transfer).Error
+}
+
+// FindByID returns the transfer with the given ID
+func (r *GormImageTransferRepository) FindByID(id uint) (*models.ImageTransfer, error) {
+	var transfer models.ImageTransfer
+	if err := r.db.First(&transfer, id).Error; err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// Save records the progress of a transfer; the order it belongs to is only set by AttachOrder
+// A worker holding a copy loaded before the order was attached must not clear it
+func (r *GormImageTransferRepository) Save(transfer *models.ImageTransfer) error {
+	return r.db.Omit("OrderID").Save(transfer).Error
+}
+
+// Delete removes a transfer
+func (r *GormImageTransferRepository) Delete(id uint) error {
+	return r.db.Delete(&models.ImageTransfer{}, id).Error
+}
+
+// ListDue returns up to limit of the host's pending transfers whose next attempt is due, oldest first
+func (r *GormImageTransferRepository) ListDue(host string, now time.Time, limit int) ([]models.ImageTransfer, error) {
+	var transfers []models.ImageTransfer
+	if err := r.db.Where("host = ? AND status = ? AND next_attempt_at <= ?", host, models.TransferPending, now).
+		Order("next_attempt_at ASC").Order("id ASC").
+		Limit(limit).
+		Find(&transfers).Error; err != nil {
+		return nil, err
+	}
+	return transfers, nil
+}
+
+// Claim counts an attempt on a due transfer and moves its next attempt to leaseUntil
+func (r *GormImageTransferRepository) Claim(transfer *models.ImageTransfer, leaseUntil time.Time) (bool, error) {
+	result := r.db.Model(&models.ImageTransfer{}).
+		Where("id = ? AND status = ? AND attempts = ?", transfer.ID, models.TransferPending, transfer.Attempts).
+		UpdateColumns(map[string]interface{}{
+			"attempts":        gorm.Expr("attempts + 1"),
+			"next_attempt_at": leaseUntil,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	transfer.Attempts++
+	transfer.NextAttemptAt = leaseUntil
+	return true, nil
+}
+
+// AttachOrder records the order a transfer's image belongs to
+func (r *GormImageTransferRepository) AttachOrder(id, orderID uint) error {
+	return r.db.Model(&models.ImageTransfer{}).Where("id = ?", id).UpdateColumn("order_id", orderID).Error
+}
+
+// SetOrderImage sets the image key of an order, reporting false when the order is not there
+func (r *GormImageTransferRepository) SetOrderImage(orderID uint, key string) (bool, error) {
+	result := r.db.Model(&models.Order{}).
+		Where("id = ?", orderID).
+		UpdateColumns(map[string]interface{}{
+			"image_s3_key": key,
+			"version":      gorm.Expr("version + 1"),
+			"updated_at":   time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// PendingOrderIDs returns which of the given orders have a transfer still pending
+func (r *GormImageTransferRepository) PendingOrderIDs(orderIDs []uint) ([]uint, error) {
+	var ids []uint
+	if len(orderIDs) == 0 {
+		return ids, nil
+	}
+	if err := r.db.Model(&models.ImageTransfer{}).
+		Where("order_id IN ? AND status = ?", orderIDs, models.TransferPending).
+		Pluck("order_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
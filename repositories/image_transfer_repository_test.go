@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestImageTransferRepository(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := models.MigrateUp(db); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+	repo := NewImageTransferRepository(db)
+
+	order := models.Order{Description: "Order", Quantity: 1, Status: "submitted", CustomerID: 1}
+	db.Create(&order)
+
+	now := time.Now()
+	mine := models.ImageTransfer{Key: "uploads/1_mine.png", Host: "api-1", StagedPath: "/staging/a", ContentType: "image/png", Status: models.TransferPending, NextAttemptAt: now.Add(-time.Second)}
+	other := models.ImageTransfer{Key: "uploads/1_other.png", Host: "api-2", StagedPath: "/staging/b", ContentType: "image/png", Status: models.TransferPending, NextAttemptAt: now.Add(-time.Second)}
+	assert.NoError(t, repo.Create(&mine))
+	assert.NoError(t, repo.Create(&other))
+
+	// Each instance only sees the transfers it staged
+	due, err := repo.ListDue("api-1", now, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, due, 1) {
+		assert.Equal(t, mine.ID, due[0].ID)
+	}
+
+	// Only one worker claims a transfer
+	stale := due[0]
+	claimed, err := repo.Claim(&due[0], now.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+	assert.Equal(t, 1, due[0].Attempts)
+	claimed, err = repo.Claim(&stale, now.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+
+	// Saving a copy loaded before the order was attached keeps the order
+	assert.NoError(t, repo.AttachOrder(mine.ID, order.ID))
+	pending, err := repo.PendingOrderIDs([]uint{order.ID, order.ID + 1})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{order.ID}, pending)
+
+	due[0].Status = models.TransferSucceeded
+	assert.NoError(t, repo.Save(&due[0]))
+	saved, err := repo.FindByID(mine.ID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, saved.OrderID) {
+		assert.Equal(t, order.ID, *saved.OrderID)
+	}
+	pending, err = repo.PendingOrderIDs([]uint{order.ID})
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+
+	// Setting the order's image counts as a change to the order
+	linked, err := repo.SetOrderImage(order.ID, mine.Key)
+	assert.NoError(t, err)
+	assert.True(t, linked)
+	var updated models.Order
+	db.First(&updated, order.ID)
+	if assert.NotNil(t, updated.ImageS3Key) {
+		assert.Equal(t, mine.Key, *updated.ImageS3Key)
+	}
+	assert.Equal(t, order.Version+1, updated.Version)
+
+	// An order that is not there is reported rather than silently skipped
+	linked, err = repo.SetOrderImage(order.ID+1, mine.Key)
+	assert.NoError(t, err)
+	assert.False(t, linked)
+}
@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"gorm.io/gorm"
+)
+
+// ErrDraftClaimed is returned by ClaimDraft when the draft was claimed concurrently
+var ErrDraftClaimed = errors.New("intake draft has already been claimed")
+
+// IntakeRepository provides persistence for intake tokens and the drafts submitted with them
+type IntakeRepository interface {
+	// CreateToken inserts a new intake token
+	CreateToken(token *models.IntakeToken) error
+
+	// SaveToken persists all fields of an existing intake token
+	SaveToken(token *models.IntakeToken) error
+
+	// FindTokenByHash loads an intake token by the hash of its secret
+	FindTokenByHash(hash string) (*models.IntakeToken, error)
+
+	// FindToken loads an intake token belonging to the technician
+	FindToken(technicianID, tokenID uint) (*models.IntakeToken, error)
+
+	// ListTokens returns the technician's intake tokens, newest first
+	ListTokens(technicianID uint) ([]models.IntakeToken, error)
+
+	// CreateDraft inserts a new intake draft
+	CreateDraft(draft *models.IntakeDraft) error
+
+	// FindDraftByClaimHash loads an intake draft by the hash of its claim code
+	FindDraftByClaimHash(hash string) (*models.IntakeDraft, error)
+
+	// ClaimDraft creates the order for a draft and marks the draft claimed in a single transaction
+	ClaimDraft(draft *models.IntakeDraft, order *models.Order) error
+}
+
+// GormIntakeRepository implements IntakeRepository using GORM
+type GormIntakeRepository struct {
+	db *gorm.DB
+}
+
+// NewIntakeRepository creates an intake repository backed by the given database
+func NewIntakeRepository(db *gorm.DB) *GormIntakeRepository {
+	return &GormIntakeRepository{db: db}
+}
+
+// CreateToken inserts a new intake token
+func (r *GormIntakeRepository) CreateToken(token *models.IntakeToken) error {
+	return r.db.Create(token).Error
+}
+
+// SaveToken persists all fields of an existing intake token
+func (r *GormIntakeRepository) SaveToken(token *models.IntakeToken) error {
+	return r.db.Save(token).Error
+}
+
+// FindTokenByHash loads an intake token by the hash of its secret
+func (r *GormIntakeRepository) FindTokenByHash(hash string) (*models.IntakeToken, error) {
+	var token models.IntakeToken
+	if err := r.db.Where("token_hash = ?", hash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// FindToken loads an intake token belonging to the technician
+func (r *GormIntakeRepository) FindToken(technicianID, tokenID uint) (*models.IntakeToken, error) {
+	var token models.IntakeToken
+	if err := r.db.Where("technician_id = ?", technicianID).First(&token, tokenID).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// ListTokens returns the technician's intake tokens, newest first
+func (r *GormIntakeRepository) ListTokens(technicianID uint) ([]models.IntakeToken, error) {
+	var tokens []models.IntakeToken
+	if err := r.db.Where("technician_id = ?", technicianID).
+		Order("created_at DESC").Order("id DESC").
+		Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// CreateDraft inserts a new intake draft
+func (r *GormIntakeRepository) CreateDraft(draft *models.IntakeDraft) error {
+	return r.db.Create(draft).Error
+}
+
+// FindDraftByClaimHash loads an intake draft by the hash of its claim code
+func (r *GormIntakeRepository) FindDraftByClaimHash(hash string) (*models.IntakeDraft, error) {
+	var draft models.IntakeDraft
+	if err := r.db.Where("claim_code_hash = ?", hash).First(&draft).Error; err != nil {
+		return nil, err
+	}
+	return &draft, nil
+}
+
+// ClaimDraft creates the order for a draft and marks the draft claimed in a single transaction
+// The draft is only claimed if no one else has claimed it first
+func (r *GormIntakeRepository) ClaimDraft(draft *models.IntakeDraft, order *models.Order) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(order).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		result := tx.Model(&models.IntakeDraft{}).
+			Where("id = ? AND claimed_at IS NULL", draft.ID).
+			UpdateColumns(map[string]interface{}{
+				"order_id":   order.ID,
+				"claimed_at": now,
+				"updated_at": now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrDraftClaimed
+		}
+
+		draft.OrderID = &order.ID
+		draft.ClaimedAt = &now
+		return nil
+	})
+}
@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"gorm.io/gorm"
+)
+
+// MessageViolationRepository provides persistence for the messages the content filter masked or blocked
+type MessageViolationRepository interface {
+	// Create inserts a violation
+	Create(violation *models.MessageViolation) error
+
+	// Save updates a violation
+	Save(violation *models.MessageViolation) error
+
+	// FindByID returns the violation with the given ID
+	FindByID(id uint) (*models.MessageViolation, error)
+
+	// List returns a page of the violations with the given status, oldest first, with the total number that match
+	// An empty status lists every violation
+	List(status string, limit, offset int) ([]models.MessageViolation, int64, error)
+}
+
+// GormMessageViolationRepository implements MessageViolationRepository using GORM
+type GormMessageViolationRepository struct {
+	db *gorm.DB
+}
+
+// NewMessageViolationRepository creates a message violation repository backed by the given database
+func NewMessageViolationRepository(db *gorm.DB) *GormMessageViolationRepository {
+	return &GormMessageViolationRepository{db: db}
+}
+
+// Create inserts a violation
+func (r *GormMessageViolationRepository) Create(violation *models.MessageViolation) error {
+	return r.db.Create(violation).Error
+}
+
+// Save updates a violation
+func (r *GormMessageViolationRepository) Save(violation *models.MessageViolation) error {
+	return r.db.Save(violation).Error
+}
+
+// FindByID returns the violation with the given ID
+func (r *GormMessageViolationRepository) FindByID(id uint) (*models.MessageViolation, error) {
+	var violation models.MessageViolation
+	if err := r.db.First(&violation, id).Error; err != nil {
+		return nil, err
+	}
+	return &violation, nil
+}
+
+// List returns a page of the violations with the given status, oldest first, with the total number that match
+// An empty status lists every violation
+func (r *GormMessageViolationRepository) List(status string, limit, offset int) ([]models.MessageViolation, int64, error) {
+	scope := r.db.Model(&models.MessageViolation{})
+	if status != "" {
+		scope = scope.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := scope.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var violations []models.MessageViolation
+	if err := scope.Order("created_at, id").Limit(limit).Offset(offset).Find(&violations).Error; err != nil {
+		return nil, 0, err
+	}
+	return violations, total, nil
+}
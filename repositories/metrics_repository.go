@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/utils"
+	"gorm.io/gorm"
+)
+
+// MetricsRepository runs the aggregate queries behind the business metrics on /metrics
+// Every method aggregates in the database rather than loading rows
+type MetricsRepository interface {
+	// CountOrdersByStatus returns the number of orders in each status
+	CountOrdersByStatus() ([]StatusCount, error)
+
+	// RevenueReviewedSince sums the prices of orders in the currency and the booked statuses that were accepted
+	// at or after since
+	RevenueReviewedSince(currency string, bookedStatuses []string, since time.Time) (float64, error)
+
+	// CountActiveTechnicians returns how many technicians are assigned at least one order in the given statuses
+	CountActiveTechnicians(statuses []string) (int64, error)
+
+	// CountDeliveriesByStatus returns the number of webhook deliveries in each status
+	CountDeliveriesByStatus() ([]StatusCount, error)
+}
+
+// GormMetricsRepository implements MetricsRepository using GORM
+type GormMetricsRepository struct {
+	db *gorm.DB
+}
+
+// NewMetricsRepository creates a metrics repository backed by the given database
+func NewMetricsRepository(db *gorm.DB) *GormMetricsRepository {
+	return &GormMetricsRepository{db: db}
+}
+
+// CountOrdersByStatus returns the number of orders in each status
+func (r *GormMetricsRepository) CountOrdersByStatus() ([]StatusCount, error) {
+	var counts []StatusCount
+	err := r.db.Model(&models.Order{}).
+		Select("status, COUNT(*) AS count").
+		Group("status").
+		Scan(&counts).Error
+	return counts, err
+}
+
+// RevenueReviewedSince sums the prices of orders in the currency and the booked statuses that were accepted
+// at or after since
+// The sum is taken in whole cents so it carries no floating point error
+func (r *GormMetricsRepository) RevenueReviewedSince(currency string, bookedStatuses []string, since time.Time) (float64, error) {
+	var cents int64
+	err := r.db.Model(&models.Order{}).
+		Select("COALESCE(SUM("+models.OrderPriceColumn.CentsSQL()+"), 0)").
+		Where("status IN ? AND currency = ? AND reviewed_at >= ?", bookedStatuses, currency, since).
+		Scan(&cents).Error
+	return utils.FromCents(cents), err
+}
+
+// CountActiveTechnicians returns how many technicians are assigned at least one order in the given statuses
+func (r *GormMetricsRepository) CountActiveTechnicians(statuses []string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Order{}).
+		Where("technician_id IS NOT NULL AND status IN ?", statuses).
+		Distinct("technician_id").
+		Count(&count).Error
+	return count, err
+}
+
+// CountDeliveriesByStatus returns the number of webhook deliveries in each status
+func (r *GormMetricsRepository) CountDeliveriesByStatus() ([]StatusCount, error) {
+	var counts []StatusCount
+	err := r.db.Model(&models.WebhookDelivery{}).
+		Select("status, COUNT(*) AS count").
+		Group("status").
+		Scan(&counts).Error
+	return counts, err
+}
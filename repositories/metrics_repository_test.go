@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsRepository(t *testing.T) {
+	db, customer := setupAnalyticsTestDB(t)
+	repo := NewMetricsRepository(db)
+
+	techA := models.User{Auth0ID: "auth0|tech-a", Name: "Tech A", Email: "a@example.com", Role: "technician"}
+	techB := models.User{Auth0ID: "auth0|tech-b", Name: "Tech B", Email: "b@example.com", Role: "technician"}
+	db.Create(&techA)
+	db.Create(&techB)
+
+	today := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	price := func(v float64) *float64 { return &v }
+	at := func(t time.Time) *time.Time { return &t }
+
+	orders := []models.Order{
+		{Description: "a", Quantity: 1, Status: "submitted", CustomerID: customer.ID},
+		{Description: "b", Quantity: 1, Status: "accepted", CustomerID: customer.ID, TechnicianID: &techA.ID, Price: price(40), ReviewedAt: at(today.Add(2 * time.Hour))},
+		{Description: "c", Quantity: 1, Status: "in_production", CustomerID: customer.ID, TechnicianID: &techA.ID, Price: price(25), ReviewedAt: at(today.Add(-time.Hour))},
+		{Description: "d", Quantity: 1, Status: "delivered", CustomerID: customer.ID, TechnicianID: &techB.ID, Price: price(60), ReviewedAt: at(today.Add(time.Hour))},
+		{Description: "e", Quantity: 1, Status: "rejected", CustomerID: customer.ID, TechnicianID: &techB.ID, ReviewedAt: at(today.Add(time.Hour))},
+		{Description: "f", Quantity: 1, Status: "accepted", CustomerID: customer.ID, TechnicianID: &techA.ID, Price: price(70), Currency: "EUR", ReviewedAt: at(today.Add(time.Hour))},
+	}
+	for i := range orders {
+		assert.NoError(t, db.Create(&orders[i]).Error)
+	}
+
+	counts, err := repo.CountOrdersByStatus()
+	assert.NoError(t, err)
+	assert.Len(t, counts, 5)
+
+	booked := []string{"accepted", "in_production", "shipped", "delivered"}
+	revenue, err := repo.RevenueReviewedSince("USD", booked, today)
+	assert.NoError(t, err)
+	assert.Equal(t, 100.0, revenue)
+
+	// Orders priced in another currency are not added in
+	revenue, err = repo.RevenueReviewedSince("EUR", booked, today)
+	assert.NoError(t, err)
+	assert.Equal(t, 70.0, revenue)
+
+	// Only techA has an open order; techB's orders are finished
+	active, err := repo.CountActiveTechnicians([]string{"submitted", "accepted", "in_production"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), active)
+
+	db.Create(&models.WebhookDelivery{WebhookID: 1, EventID: "e1", Event: "order.created", Payload: "{}", Status: models.DeliveryPending, NextAttemptAt: today})
+	db.Create(&models.WebhookDelivery{WebhookID: 1, EventID: "e2", Event: "order.created", Payload: "{}", Status: models.DeliveryPending, NextAttemptAt: today})
+	db.Create(&models.WebhookDelivery{WebhookID: 1, EventID: "e3", Event: "order.created", Payload: "{}", Status: models.DeliveryFailed, NextAttemptAt: today})
+
+	deliveries, err := repo.CountDeliveriesByStatus()
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []StatusCount{
+		{Status: models.DeliveryPending, Count: 2},
+		{Status: models.DeliveryFailed, Count: 1},
+	}, deliveries)
+}
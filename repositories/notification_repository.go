@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"gorm.io/gorm"
+)
+
+// NotificationRepository provides persistence for the events queued for long-polling users
+type NotificationRepository interface {
+	// Create inserts notifications in a single statement
+	Create(notifications []models.Notification) error
+
+	// ListAfter returns up to limit of the user's notifications with an ID above afterID, oldest first
+	ListAfter(userID, afterID uint, limit int) ([]models.Notification, error)
+
+	// LatestID returns the highest notification ID of the user, or zero when they have none
+	LatestID(userID uint) (uint, error)
+
+	// DeleteBefore removes notifications created before the cutoff and returns how many were removed
+	DeleteBefore(cutoff time.Time) (int64, error)
+
+	// DeleteEventBefore removes notifications of the event type created before the cutoff and returns how many were removed
+	DeleteEventBefore(event string, cutoff time.Time) (int64, error)
+
+	// FindPreferences loads the user's notification preferences
+	FindPreferences(userID uint) (*models.NotificationPreferences, error)
+
+	// SavePreferences creates or updates a user's notification preferences
+	SavePreferences(preferences *models.NotificationPreferences) error
+}
+
+// GormNotificationRepository implements NotificationRepository using GORM
+type GormNotificationRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationRepository creates a notification repository backed by the given database
+func NewNotificationRepository(db *gorm.DB) *GormNotificationRepository {
+	return &GormNotificationRepository{db: db}
+}
+
+// Create inserts notifications in a single statement
+func (r *GormNotificationRepository) Create(notifications []models.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	return r.db.Create(&notifications).Error
+}
+
+// ListAfter returns up to limit of the user's notifications with an ID above afterID, oldest first
+func (r *GormNotificationRepository) ListAfter(userID, afterID uint, limit int) ([]models.Notification, error) {
+	var notifications []models.Notification
+	if err := r.db.Where("user_id = ? AND id > ?", userID, afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&notifications).Error; err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+// LatestID returns the highest notification ID of the user, or zero when they have none
+func (r *GormNotificationRepository) LatestID(userID uint) (uint, error) {
+	var latest uint
+	err := r.db.Model(&models.Notification{}).
+		Select("COALESCE(MAX(id), 0)").
+		Where("user_id = ?", userID).
+		Scan(&latest).Error
+	return latest, err
+}
+
+// DeleteBefore removes notifications created before the cutoff and returns how many were removed
+func (r *GormNotificationRepository) DeleteBefore(cutoff time.Time) (int64, error) {
+	result := r.db.Where("created_at < ?", cutoff).Delete(&models.Notification{})
+	return result.RowsAffected, result.Error
+}
+
+// DeleteEventBefore removes notifications of the event type created before the cutoff and returns how many were removed
+func (r *GormNotificationRepository) DeleteEventBefore(event string, cutoff time.Time) (int64, error) {
+	result := r.db.Where("event = ? AND created_at < ?", event, cutoff).Delete(&models.Notification{})
+	return result.RowsAffected, result.Error
+}
+
+// FindPreferences loads the user's notification preferences
+func (r *GormNotificationRepository) FindPreferences(userID uint) (*models.NotificationPreferences, error) {
+	var preferences models.NotificationPreferences
+	if err := r.db.Where("user_id = ?", userID).First(&preferences).Error; err != nil {
+		return nil, err
+	}
+	return &preferences, nil
+}
+
+// SavePreferences creates or updates a user's notification preferences
+func (r *GormNotificationRepository) SavePreferences(preferences *models.NotificationPreferences) error {
+	return r.db.Save(preferences).Error
+}
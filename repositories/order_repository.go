@@ -0,0 +1,359 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"gorm.io/gorm"
+)
+
+// ErrOrderChanged is returned when an order was saved by someone else since it was loaded
+var ErrOrderChanged = errors.New("order has changed since it was loaded")
+
+// OrderListQuery describes which orders to list and how to paginate them
+// Visibility fields are set by the service layer based on the caller's role
+type OrderListQuery struct {
+	CustomerID                   *uint      // only orders placed by this customer
+	CustomerOrRecipientID        *uint      // only orders placed by this customer or given to them as gifts
+	AssignedOrUnassignedToTechID *uint      // only orders assigned to this technician or not yet assigned
+	Statuses                     []string   // only orders in these statuses; empty matches every status
+	RushFirst                    bool       // list rush orders ahead of the rest
+	CreatedAfter                 *time.Time // only orders created at or after this time
+	CreatedBefore                *time.Time // only orders created before this time
+	UpdatedAfter                 *time.Time // only orders updated at or after this time
+	SortBy                       string     // one of OrderSortColumns, defaults to created_at
+	SortAscending                bool
+	Limit                        int
+	Offset                       int
+	Keyset                       bool         // page by After instead of Offset, sorted by created_at; the total is not counted
+	After                        *OrderCursor // with Keyset, only orders listed after this one; nil starts at the first
+	Relations                    []string     // relations to load, see OrderRelations; nil loads them all
+}
+
+// OrderCursor is the position of an order in a keyset-paginated list
+// Rush only matters when the list puts rush orders first
+type OrderCursor struct {
+	CreatedAt time.Time
+	ID        uint
+	Rush      bool
+}
+
+// OrderRepository provides persistence for orders
+type OrderRepository interface {
+	// Create inserts a new order
+	Create(order *models.Order) error
+
+	// Save persists all fields of an existing order and increments its version
+	// It returns ErrOrderChanged, saving nothing, when the order's version moved on since it was loaded
+	Save(order *models.Order) error
+
+	// FindByID loads an order without relationships
+	FindByID(id uint) (*models.Order, error)
+
+	// FindByIDWithRelations loads an order with its customer, technician, line items, and checklist
+	FindByIDWithRelations(id uint) (*models.Order, error)
+
+	// List returns a page of orders matching the query and the total number of matches
+	// With Keyset the total is not counted and is returned as 0
+	List(query OrderListQuery) ([]models.Order, int64, error)
+
+	// ListCreatedBefore returns up to limit orders in the status that were created before the cutoff, oldest first
+	ListCreatedBefore(status string, cutoff time.Time, limit int) ([]models.Order, error)
+
+	// ListCopiesOf returns the orders reordered or duplicated from any of the given orders, oldest first
+	ListCopiesOf(orderIDs []uint) ([]models.Order, error)
+
+	// ListDueBefore returns the technician's orders in the statuses whose due date is before the cutoff, earliest due first
+	// An order is due by its promised date, or by the requested date when nothing was promised
+	ListDueBefore(technicianID uint, statuses []string, cutoff time.Time) ([]models.Order, error)
+
+	// ListDeliveredBetween returns the technician's orders in the delivered status that were delivered in [from, to), newest first
+	// Orders are matched on models.Order.DeliveredTime and come with their line items
+	ListDeliveredBetween(technicianID uint, deliveredStatus string, from, to time.Time) ([]models.Order, error)
+
+	// ListAssignedTo returns the technician's orders in the statuses, newest first, with their line items
+	ListAssignedTo(technicianID uint, statuses []string) ([]models.Order, error)
+
+	// Transition moves an order from one status to another and posts the notice in one transaction
+	// It reports false, changing nothing, when the order is no longer in the from status
+	// A non-nil then runs in the transaction once the order has moved, and its error rolls the move back
+	Transition(orderID uint, from, to string, notice *models.Message, then TransitionHook) (bool, error)
+}
+
+// TransitionHook runs inside a transition's transaction with repositories over that transaction,
+// so that what it writes, such as the events announcing the move, commits together with the move
+type TransitionHook func(orders OrderRepository, outbox OutboxRepository) error
+
+// GormOrderRepository implements OrderRepository using GORM
+type GormOrderRepository struct {
+	db      *gorm.DB
+	replica *gorm.DB // List reads from it; everything else uses db
+}
+
+// NewOrderRepository creates an order repository backed by the given database
+func NewOrderRepository(db *gorm.DB) *GormOrderRepository {
+	return &GormOrderRepository{db: db, replica: db}
+}
+
+// NewOrderRepositoryWithReplica creates an order repository that lists orders from a read replica
+// and runs every other query, including single-order lookups that follow a write, on db
+func NewOrderRepositoryWithReplica(db, replica *gorm.DB) *GormOrderRepository {
+	return &GormOrderRepository{db: db, replica: replica}
+}
+
+// Create inserts a new order
+func (r *GormOrderRepository) Create(order *models.Order) error {
+	return r.db.Create(order).Error
+}
+
+// Save persists all fields of an existing order and increments its version
+// Selecting every column explicitly keeps GORM from inserting the order when the version no longer matches
+func (r *GormOrderRepository) Save(order *models.Order) error {
+	loaded := order.Version
+	order.Version++
+	result := r.db.Select("*").Where("version = ?", loaded).Save(order)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = ErrOrderChanged
+	}
+	if result.Error != nil {
+		order.Version = loaded
+	}
+	return result.Error
+}
+
+// FindByID loads an order without relationships
+func (r *GormOrderRepository) FindByID(id uint) (*models.Order, error) {
+	var order models.Order
+	if err := r.db.First(&order, id).Error; err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// FindByIDWithRelations loads an order with its customer, technician, line items, and checklist
+func (r *GormOrderRepository) FindByIDWithRelations(id uint) (*models.Order, error) {
+	var order models.Order
+	if err := withRelations(r.db, nil).First(&order, id).Error; err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// List returns a page of orders matching the query, newest first, and the total number of matches
+// With RushFirst, rush orders are listed before all others
+func (r *GormOrderRepository) List(query OrderListQuery) ([]models.Order, int64, error) {
+	scope := r.replica.Model(&models.Order{})
+
+	if query.CustomerID != nil {
+		scope = scope.Where("customer_id = ?", *query.CustomerID)
+	}
+	if query.CustomerOrRecipientID != nil {
+		scope = scope.Where("customer_id = ? OR recipient_id = ?", *query.CustomerOrRecipientID, *query.CustomerOrRecipientID)
+	}
+	if query.AssignedOrUnassignedToTechID != nil {
+		scope = scope.Where("technician_id = ? OR technician_id IS NULL", *query.AssignedOrUnassignedToTechID)
+	}
+	if len(query.Statuses) > 0 {
+		scope = scope.Where("status IN ?", query.Statuses)
+	}
+	if query.CreatedAfter != nil {
+		scope = scope.Where("created_at >= ?", *query.CreatedAfter)
+	}
+	if query.CreatedBefore != nil {
+		scope = scope.Where("created_at < ?", *query.CreatedBefore)
+	}
+	if query.UpdatedAfter != nil {
+		scope = scope.Where("updated_at >= ?", *query.UpdatedAfter)
+	}
+
+	// Get total count for pagination info; keyset pages skip it, since counting is what slows deep lists down
+	var total int64
+	if !query.Keyset {
+		if err := scope.Count(&total).Error; err != nil {
+			return nil, 0, err
+		}
+	}
+
+	if query.RushFirst {
+		scope = scope.Order("rush DESC")
+	}
+
+	if query.Keyset {
+		query.SortBy, query.Offset = "created_at", 0
+		if query.After != nil {
+			scope = afterCursor(scope, *query.After, query.SortAscending, query.RushFirst)
+		}
+	}
+
+	var orders []models.Order
+	if err := withRelations(orderBy(scope, query.SortBy, query.SortAscending), query.Relations).
+		Limit(query.Limit).
+		Offset(query.Offset).
+		Find(&orders).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return orders, total, nil
+}
+
+// afterCursor limits a list sorted by created_at and id, and by rush first when rushFirst, to the orders after the cursor
+func afterCursor(scope *gorm.DB, after OrderCursor, ascending, rushFirst bool) *gorm.DB {
+	comparison := "<"
+	if ascending {
+		comparison = ">"
+	}
+	later := scope.Session(&gorm.Session{NewDB: true}).
+		Where("created_at "+comparison+" ?", after.CreatedAt).
+		Or("created_at = ? AND id "+comparison+" ?", after.CreatedAt, after.ID)
+	if !rushFirst {
+		return scope.Where(later)
+	}
+
+	// Rush orders come first in both directions
+	sameRush := scope.Session(&gorm.Session{NewDB: true}).Where("rush = ?", after.Rush).Where(later)
+	if after.Rush {
+		return scope.Where(scope.Session(&gorm.Session{NewDB: true}).Where("rush = ?", false).Or(sameRush))
+	}
+	return scope.Where(sameRush)
+}
+
+// OrderSortColumns lists the columns orders can be sorted by
+var OrderSortColumns = []string{"created_at", "price", "status"}
+
+// IsOrderSortColumn reports whether column is in OrderSortColumns
+func IsOrderSortColumn(column string) bool {
+	for _, allowed := range OrderSortColumns {
+		if column == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// orderBy applies a whitelisted sort, breaking ties by id so pages stay stable
+func orderBy(scope *gorm.DB, column string, ascending bool) *gorm.DB {
+	if !IsOrderSortColumn(column) {
+		column = "created_at"
+	}
+	direction := "DESC"
+	if ascending {
+		direction = "ASC"
+	}
+
+	// Unpriced orders sort last in both directions on every database
+	if column == "price" {
+		scope = scope.Order("price IS NULL")
+	}
+	return scope.Order(column + " " + direction).Order("id " + direction)
+}
+
+// OrderRelations lists the relations returned alongside an order, by their JSON names
+var OrderRelations = []string{"customer", "technician", "line_items", "checklist"}
+
+// withRelations preloads the relations returned alongside an order; nil preloads all of OrderRelations
+func withRelations(db *gorm.DB, relations []string) *gorm.DB {
+	if relations == nil {
+		relations = OrderRelations
+	}
+	for _, relation := range relations {
+		switch relation {
+		case "customer":
+			db = db.Preload("Customer")
+		case "technician":
+			db = db.Preload("Technician")
+		case "line_items":
+			db = db.Preload("LineItems")
+		case "checklist":
+			db = db.Preload("Checklist", func(tx *gorm.DB) *gorm.DB { return tx.Order("position ASC") })
+		}
+	}
+	return db
+}
+
+// ListCreatedBefore returns up to limit orders in the status that were created before the cutoff, oldest first
+func (r *GormOrderRepository) ListCreatedBefore(status string, cutoff time.Time, limit int) ([]models.Order, error) {
+	var orders []models.Order
+	if err := r.db.Where("status = ? AND created_at < ?", status, cutoff).
+		Order("created_at ASC, id ASC").
+		Limit(limit).
+		Find(&orders).Error; err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// ListCopiesOf returns the orders reordered or duplicated from any of the given orders, oldest first
+func (r *GormOrderRepository) ListCopiesOf(orderIDs []uint) ([]models.Order, error) {
+	var orders []models.Order
+	if err := r.db.Where("original_order_id IN ?", orderIDs).Order("id ASC").Find(&orders).Error; err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// ListDueBefore returns the technician's orders in the statuses whose due date is before the cutoff, earliest due first
+func (r *GormOrderRepository) ListDueBefore(technicianID uint, statuses []string, cutoff time.Time) ([]models.Order, error) {
+	var orders []models.Order
+	if err := withRelations(r.db, nil).
+		Where("technician_id = ? AND status IN ? AND COALESCE(promised_by, requested_by) < ?", technicianID, statuses, cutoff).
+		Order("COALESCE(promised_by, requested_by) ASC, id ASC").
+		Find(&orders).Error; err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// deliveredTimeSQL is the SQL form of models.Order.DeliveredTime
+const deliveredTimeSQL = "COALESCE(delivered_at, shipped_at, updated_at)"
+
+// ListDeliveredBetween returns the technician's orders in the delivered status that were delivered in [from, to), newest first
+func (r *GormOrderRepository) ListDeliveredBetween(technicianID uint, deliveredStatus string, from, to time.Time) ([]models.Order, error) {
+	var orders []models.Order
+	if err := r.db.Preload("LineItems").
+		Where("technician_id = ? AND status = ? AND "+deliveredTimeSQL+" >= ? AND "+deliveredTimeSQL+" < ?", technicianID, deliveredStatus, from, to).
+		Order(deliveredTimeSQL + " DESC, id DESC").
+		Find(&orders).Error; err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// ListAssignedTo returns the technician's orders in the statuses, newest first, with their line items
+func (r *GormOrderRepository) ListAssignedTo(technicianID uint, statuses []string) ([]models.Order, error) {
+	var orders []models.Order
+	if err := r.db.Preload("LineItems").
+		Where("technician_id = ? AND status IN ?", technicianID, statuses).
+		Order("created_at DESC, id DESC").
+		Find(&orders).Error; err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// Transition moves an order from one status to another and posts the notice in one transaction
+// A non-nil then runs in the transaction once the order has moved, and its error rolls the move back
+func (r *GormOrderRepository) Transition(orderID uint, from, to string, notice *models.Message, then TransitionHook) (bool, error) {
+	moved := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Order{}).
+			Where("id = ? AND status = ?", orderID, from).
+			UpdateColumns(map[string]interface{}{
+				"status":     to,
+				"version":    gorm.Expr("version + 1"),
+				"updated_at": time.Now(),
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		moved = true
+		if err := tx.Omit("Order", "Sender").Create(notice).Error; err != nil {
+			return err
+		}
+		if then != nil {
+			return then(NewOrderRepository(tx), NewOutboxRepository(tx))
+		}
+		return nil
+	})
+	return moved && err == nil, err
+}
@@ -0,0 +1,125 @@
+package repositories
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderRepository_ListReadsReplica(t *testing.T) {
+	primary, customer := setupAnalyticsTestDB(t)
+	replica, _ := setupAnalyticsTestDB(t)
+
+	// The replica has not caught up with the second order yet
+	first := models.Order{Description: "replicated", Quantity: 1, Status: "submitted", CustomerID: customer.ID}
+	require.NoError(t, primary.Create(&first).Error)
+	require.NoError(t, replica.Create(&models.Order{Description: "replicated", Quantity: 1, Status: "submitted", CustomerID: customer.ID}).Error)
+	second := models.Order{Description: "new", Quantity: 1, Status: "submitted", CustomerID: customer.ID}
+
+	repo := NewOrderRepositoryWithReplica(primary, replica)
+	require.NoError(t, repo.Create(&second))
+
+	orders, total, err := repo.List(OrderListQuery{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total, "lists come from the replica")
+	assert.Len(t, orders, 1)
+
+	found, err := repo.FindByID(second.ID)
+	require.NoError(t, err, "lookups and writes stay on the primary")
+	assert.Equal(t, "new", found.Description)
+
+	// Without a replica, lists come from the primary
+	orders, total, err = NewOrderRepository(primary).List(OrderListQuery{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Len(t, orders, 2)
+}
+
+func TestOrderRepository_ListRelations(t *testing.T) {
+	db, customer := setupAnalyticsTestDB(t)
+	require.NoError(t, db.Create(&models.Order{Description: "a", Quantity: 1, Status: "submitted", CustomerID: customer.ID}).Error)
+	repo := NewOrderRepository(db)
+
+	orders, _, err := repo.List(OrderListQuery{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, customer.Name, orders[0].Customer.Name, "every relation is loaded by default")
+
+	orders, _, err = repo.List(OrderListQuery{Limit: 10, Relations: []string{}})
+	require.NoError(t, err)
+	assert.Zero(t, orders[0].Customer.ID, "relations left out are not loaded")
+}
+
+func TestOrderRepository_SaveChecksVersion(t *testing.T) {
+	db, customer := setupAnalyticsTestDB(t)
+	repo := NewOrderRepository(db)
+
+	order := models.Order{Description: "versioned", Quantity: 1, Status: "submitted", CustomerID: customer.ID}
+	require.NoError(t, repo.Create(&order))
+	assert.Equal(t, uint(1), order.Version)
+
+	first, err := repo.FindByID(order.ID)
+	require.NoError(t, err)
+	second, err := repo.FindByID(order.ID)
+	require.NoError(t, err)
+
+	first.Status = "accepted"
+	require.NoError(t, repo.Save(first))
+	assert.Equal(t, uint(2), first.Version)
+
+	// A copy loaded before that save cannot overwrite it
+	second.Status = "rejected"
+	assert.ErrorIs(t, repo.Save(second), ErrOrderChanged)
+	assert.Equal(t, uint(1), second.Version)
+
+	stored, err := repo.FindByID(order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "accepted", stored.Status)
+	assert.Equal(t, uint(2), stored.Version)
+
+	// Status transitions move the version on too
+	moved, err := repo.Transition(order.ID, "accepted", "expired", &models.Message{OrderID: order.ID, Text: "Expired"}, nil)
+	require.NoError(t, err)
+	assert.True(t, moved)
+	stored, err = repo.FindByID(order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, uint(3), stored.Version)
+}
+
+func TestGormOrderRepository_TransitionHook(t *testing.T) {
+	db, customer := setupAnalyticsTestDB(t)
+	repo := NewOrderRepository(db)
+	order := &models.Order{Description: "Pink", Quantity: 1, Status: "submitted", CustomerID: customer.ID}
+	require.NoError(t, repo.Create(order))
+
+	// A failing hook rolls the transition and its notice back
+	failed := errors.New("outbox unavailable")
+	_, err := repo.Transition(order.ID, "submitted", "expired", &models.Message{OrderID: order.ID, Text: "Expired"}, func(orders OrderRepository, outbox OutboxRepository) error {
+		return failed
+	})
+	assert.ErrorIs(t, err, failed)
+	stored, err := repo.FindByID(order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "submitted", stored.Status)
+	var notices int64
+	require.NoError(t, db.Model(&models.Message{}).Count(&notices).Error)
+	assert.Zero(t, notices)
+
+	// A passing hook sees the moved order and commits what it writes with it
+	moved, err := repo.Transition(order.ID, "submitted", "expired", &models.Message{OrderID: order.ID, Text: "Expired"}, func(orders OrderRepository, outbox OutboxRepository) error {
+		seen, err := orders.FindByID(order.ID)
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, "expired", seen.Status)
+		return outbox.Create(&models.OutboxEvent{Name: "order.status_changed", Payload: "{}", NextAttemptAt: time.Now()})
+	})
+	require.NoError(t, err)
+	assert.True(t, moved)
+	var saved int64
+	require.NoError(t, db.Model(&models.OutboxEvent{}).Count(&saved).Error)
+	assert.Equal(t, int64(1), saved)
+}
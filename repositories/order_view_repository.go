@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"gorm.io/gorm"
+)
+
+// OrderViewRepository provides persistence for users' saved order list views
+type OrderViewRepository interface {
+	// Create inserts a new view
+	Create(view *models.OrderView) error
+
+	// Save persists all fields of an existing view
+	Save(view *models.OrderView) error
+
+	// Delete removes a view
+	Delete(view *models.OrderView) error
+
+	// FindForUser loads a view belonging to the user
+	FindForUser(userID, id uint) (*models.OrderView, error)
+
+	// FindByName loads the user's view with the given name
+	FindByName(userID uint, name string) (*models.OrderView, error)
+
+	// ListForUser returns the user's views, oldest first
+	ListForUser(userID uint) ([]models.OrderView, error)
+
+	// CountForUser returns how many views the user has
+	CountForUser(userID uint) (int64, error)
+}
+
+// GormOrderViewRepository implements OrderViewRepository using GORM
+type GormOrderViewRepository struct {
+	db *gorm.DB
+}
+
+// NewOrderViewRepository creates an order view repository backed by the given database
+func NewOrderViewRepository(db *gorm.DB) *GormOrderViewRepository {
+	return &GormOrderViewRepository{db: db}
+}
+
+// Create inserts a new view
+func (r *GormOrderViewRepository) Create(view *models.OrderView) error {
+	return r.db.Create(view).Error
+}
+
+// Save persists all fields of an existing view
+func (r *GormOrderViewRepository) Save(view *models.OrderView) error {
+	return r.db.Save(view).Error
+}
+
+// Delete removes a view
+func (r *GormOrderViewRepository) Delete(view *models.OrderView) error {
+	return r.db.Delete(view).Error
+}
+
+// FindForUser loads a view belonging to the user
+func (r *GormOrderViewRepository) FindForUser(userID, id uint) (*models.OrderView, error) {
+	var view models.OrderView
+	if err := r.db.Where("user_id = ?", userID).First(&view, id).Error; err != nil {
+		return nil, err
+	}
+	return &view, nil
+}
+
+// FindByName loads the user's view with the given name
+func (r *GormOrderViewRepository) FindByName(userID uint, name string) (*models.OrderView, error) {
+	var view models.OrderView
+	if err := r.db.Where("user_id = ? AND name = ?", userID, name).First(&view).Error; err != nil {
+		return nil, err
+	}
+	return &view, nil
+}
+
+// ListForUser returns the user's views, oldest first
+func (r *GormOrderViewRepository) ListForUser(userID uint) ([]models.OrderView, error) {
+	var views []models.OrderView
+	if err := r.db.Where("user_id = ?", userID).Order("id ASC").Find(&views).Error; err != nil {
+		return nil, err
+	}
+	return views, nil
+}
+
+// CountForUser returns how many views the user has
+func (r *GormOrderViewRepository) CountForUser(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.OrderView{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"gorm.io/gorm"
+)
+
+// OutboxRepository provides persistence for events waiting to be published
+// Create it over the transaction that saves a change, so that the change and its events commit together
+type OutboxRepository interface {
+	// Create inserts an event to publish
+	Create(event *models.OutboxEvent) error
+
+	// ListDue returns up to limit unpublished events whose next attempt is due, in the order they were written
+	ListDue(now time.Time, limit int) ([]models.OutboxEvent, error)
+
+	// Claim counts an attempt on a due event and moves its next attempt to leaseUntil,
+	// so that no other instance publishes it meanwhile
+	// It returns false when another instance claimed the event first
+	Claim(event *models.OutboxEvent, leaseUntil time.Time) (bool, error)
+
+	// Save persists all fields of an existing event
+	Save(event *models.OutboxEvent) error
+
+	// DeletePublishedBefore removes events published before cutoff and returns how many were removed
+	DeletePublishedBefore(cutoff time.Time) (int64, error)
+}
+
+// GormOutboxRepository implements OutboxRepository using GORM
+type GormOutboxRepository struct {
+	db *gorm.DB
+}
+
+// NewOutboxRepository creates an outbox repository backed by the given database or transaction
+func NewOutboxRepository(db *gorm.DB) *GormOutboxRepository {
+	return &GormOutboxRepository{db: db}
+}
+
+// Create inserts an event to publish
+func (r *GormOutboxRepository) Create(event *models.OutboxEvent) error {
+	return r.db.Create(event).Error
+}
+
+// ListDue returns up to limit unpublished events whose next attempt is due, in the order they were written
+func (r *GormOutboxRepository) ListDue(now time.Time, limit int) ([]models.OutboxEvent, error) {
+	var events []models.OutboxEvent
+	if err := r.db.Where("published_at IS NULL AND next_attempt_at <= ?", now).
+		Order("id ASC").
+		Limit(limit).
+		Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// Claim counts an attempt on a due event and moves its next attempt to leaseUntil
+func (r *GormOutboxRepository) Claim(event *models.OutboxEvent, leaseUntil time.Time) (bool, error) {
+	result := r.db.Model(&models.OutboxEvent{}).
+		Where("id = ? AND published_at IS NULL AND attempts = ?", event.ID, event.Attempts).
+		UpdateColumns(map[string]interface{}{
+			"attempts":        gorm.Expr("attempts + 1"),
+			"next_attempt_at": leaseUntil,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	event.Attempts++
+	event.NextAttemptAt = leaseUntil
+	return true, nil
+}
+
+// Save persists all fields of an existing event
+func (r *GormOutboxRepository) Save(event *models.OutboxEvent) error {
+	return r.db.Save(event).Error
+}
+
+// DeletePublishedBefore removes events published before cutoff and returns how many were removed
+func (r *GormOutboxRepository) DeletePublishedBefore(cutoff time.Time) (int64, error) {
+	result := r.db.Where("published_at IS NOT NULL AND published_at < ?", cutoff).Delete(&models.OutboxEvent{})
+	return result.RowsAffected, result.Error
+}
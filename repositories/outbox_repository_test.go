@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestOutboxRepository(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := models.MigrateUp(db); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+	repo := NewOutboxRepository(db)
+
+	now := time.Now()
+	first := models.OutboxEvent{Name: "order.created", Payload: `{"order":{"id":1}}`, NextAttemptAt: now.Add(-time.Second)}
+	later := models.OutboxEvent{Name: "order.created", Payload: `{"order":{"id":2}}`, NextAttemptAt: now.Add(time.Minute)}
+	assert.NoError(t, repo.Create(&first))
+	assert.NoError(t, repo.Create(&later))
+
+	due, err := repo.ListDue(now, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, due, 1) {
+		assert.Equal(t, first.ID, due[0].ID)
+	}
+
+	// Only one instance claims an event
+	other := due[0]
+	claimed, err := repo.Claim(&due[0], now.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+	assert.Equal(t, 1, due[0].Attempts)
+	claimed, err = repo.Claim(&other, now.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+
+	due, err = repo.ListDue(now, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, due, "a claimed event waits for its lease")
+
+	// Published events are never due again, and are pruned after the cutoff
+	publishedAt := now.Add(-48 * time.Hour)
+	first.PublishedAt = &publishedAt
+	first.NextAttemptAt = now.Add(-time.Hour)
+	assert.NoError(t, repo.Save(&first))
+	due, err = repo.ListDue(now, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, due)
+
+	deleted, err := repo.DeletePublishedBefore(now.Add(-24 * time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	var remaining int64
+	db.Model(&models.OutboxEvent{}).Count(&remaining)
+	assert.Equal(t, int64(1), remaining, "unpublished events are kept")
+}
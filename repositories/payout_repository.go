@@ -0,0 +1,163 @@
+package repositories
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/utils"
+	"gorm.io/gorm"
+)
+
+// ErrOrdersAlreadyPaid is returned by CreatePayouts when another payout paid one of the orders first
+var ErrOrdersAlreadyPaid = errors.New("orders already paid")
+
+// PayoutBatch is a payout to record and the orders it pays
+type PayoutBatch struct {
+	Payout   *models.Payout
+	OrderIDs []uint
+}
+
+// UnpaidBalance is what a technician is owed, in one currency, for delivered orders no payout has paid
+type UnpaidBalance struct {
+	TechnicianID   uint    `json:"technician_id"`
+	TechnicianName string  `json:"technician_name"`
+	Currency       string  `json:"currency"`
+	Orders         int64   `json:"orders"`
+	Amount         float64 `json:"amount"`
+}
+
+// PayoutRepository provides persistence for technician payouts
+type PayoutRepository interface {
+	// CreatePayouts inserts the payouts and marks their orders paid in a single transaction
+	// It returns ErrOrdersAlreadyPaid, recording nothing, when any of the orders has been paid already
+	CreatePayouts(batches []PayoutBatch) error
+
+	// ListByTechnician returns the technician's payouts, newest first
+	ListByTechnician(technicianID uint) ([]models.Payout, error)
+
+	// ListUnpaidOrders returns the delivered orders no payout has paid that were delivered in [from, to), oldest first
+	// A nil technicianID lists every technician's orders
+	ListUnpaidOrders(technicianID *uint, deliveredStatus string, from, to time.Time) ([]models.Order, error)
+
+	// UnpaidBalances returns the unpaid totals of delivered orders per technician and currency, by technician name
+	// A nil technicianID returns every technician's balances
+	UnpaidBalances(technicianID *uint, deliveredStatus string) ([]UnpaidBalance, error)
+}
+
+// GormPayoutRepository implements PayoutRepository using GORM
+type GormPayoutRepository struct {
+	db *gorm.DB
+}
+
+// NewPayoutRepository creates a payout repository backed by the given database
+func NewPayoutRepository(db *gorm.DB) *GormPayoutRepository {
+	return &GormPayoutRepository{db: db}
+}
+
+// CreatePayouts inserts the payouts and marks their orders paid in a single transaction
+// Orders keep their updated_at, which dates deliveries recorded before delivered_at existed
+func (r *GormPayoutRepository) CreatePayouts(batches []PayoutBatch) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, batch := range batches {
+			if err := tx.Create(batch.Payout).Error; err != nil {
+				return err
+			}
+			result := tx.Model(&models.Order{}).
+				Where("id IN ? AND payout_id IS NULL", batch.OrderIDs).
+				UpdateColumn("payout_id", batch.Payout.ID)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected != int64(len(batch.OrderIDs)) {
+				return ErrOrdersAlreadyPaid
+			}
+		}
+		return nil
+	})
+}
+
+// ListByTechnician returns the technician's payouts, newest first
+func (r *GormPayoutRepository) ListByTechnician(technicianID uint) ([]models.Payout, error) {
+	var payouts []models.Payout
+	if err := r.db.Where("technician_id = ?", technicianID).
+		Order("created_at DESC, id DESC").
+		Find(&payouts).Error; err != nil {
+		return nil, err
+	}
+	return payouts, nil
+}
+
+// ListUnpaidOrders returns the delivered orders no payout has paid that were delivered in [from, to), oldest first
+func (r *GormPayoutRepository) ListUnpaidOrders(technicianID *uint, deliveredStatus string, from, to time.Time) ([]models.Order, error) {
+	scope := r.db.Where("technician_id IS NOT NULL AND payout_id IS NULL AND status = ? AND "+deliveredTimeSQL+" >= ? AND "+deliveredTimeSQL+" < ?",
+		deliveredStatus, from, to)
+	if technicianID != nil {
+		scope = scope.Where("technician_id = ?", *technicianID)
+	}
+
+	var orders []models.Order
+	if err := scope.Order(deliveredTimeSQL + " ASC, id ASC").Find(&orders).Error; err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// UnpaidBalances returns the unpaid totals of delivered orders per technician and currency, by technician name
+// Amounts are summed in whole cents so large totals carry no floating point error
+func (r *GormPayoutRepository) UnpaidBalances(technicianID *uint, deliveredStatus string) ([]UnpaidBalance, error) {
+	scope := r.db.Model(&models.Order{}).
+		Select("technician_id, currency, COUNT(*) AS orders, COALESCE(SUM("+models.OrderPriceColumn.CentsSQL()+"), 0) AS cents").
+		Where("technician_id IS NOT NULL AND payout_id IS NULL AND status = ?", deliveredStatus)
+	if technicianID != nil {
+		scope = scope.Where("technician_id = ?", *technicianID)
+	}
+
+	var rows []struct {
+		TechnicianID uint
+		Currency     string
+		Orders       int64
+		Cents        int64
+	}
+	if err := scope.Group("technician_id, currency").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return []UnpaidBalance{}, nil
+	}
+
+	ids := make([]uint, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.TechnicianID)
+	}
+	var technicians []models.User
+	if err := r.db.Where("id IN ?", ids).Find(&technicians).Error; err != nil {
+		return nil, err
+	}
+	names := make(map[uint]string, len(technicians))
+	for _, technician := range technicians {
+		names[technician.ID] = technician.Name
+	}
+
+	balances := make([]UnpaidBalance, 0, len(rows))
+	for _, row := range rows {
+		balances = append(balances, UnpaidBalance{
+			TechnicianID:   row.TechnicianID,
+			TechnicianName: names[row.TechnicianID],
+			Currency:       row.Currency,
+			Orders:         row.Orders,
+			Amount:         utils.FromCents(row.Cents),
+		})
+	}
+	sort.SliceStable(balances, func(i, j int) bool {
+		if balances[i].TechnicianName != balances[j].TechnicianName {
+			return balances[i].TechnicianName < balances[j].TechnicianName
+		}
+		if balances[i].TechnicianID != balances[j].TechnicianID {
+			return balances[i].TechnicianID < balances[j].TechnicianID
+		}
+		return balances[i].Currency < balances[j].Currency
+	})
+	return balances, nil
+}
@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"gorm.io/gorm"
+)
+
+// PriceListRepository provides persistence for technicians' scheduled price lists
+type PriceListRepository interface {
+	// Create inserts a new price list
+	Create(priceList *models.PriceList) error
+
+	// Save persists all fields of an existing price list
+	Save(priceList *models.PriceList) error
+
+	// Delete soft-deletes a price list
+	Delete(priceList *models.PriceList) error
+
+	// FindByID loads a price list belonging to the technician
+	FindByID(technicianID, priceListID uint) (*models.PriceList, error)
+
+	// ListForTechnician returns the technician's price lists, latest start first
+	ListForTechnician(technicianID uint) ([]models.PriceList, error)
+
+	// FindInForce loads the technician's price list in force at the given time
+	FindInForce(technicianID uint, at time.Time) (*models.PriceList, error)
+
+	// CountOverlapping counts the technician's price lists that share any time with [startsAt, endsAt)
+	CountOverlapping(technicianID uint, startsAt, endsAt time.Time) (int64, error)
+}
+
+// GormPriceListRepository implements PriceListRepository using GORM
+type GormPriceListRepository struct {
+	db *gorm.DB
+}
+
+// NewPriceListRepository creates a price list repository backed by the given database
+func NewPriceListRepository(db *gorm.DB) *GormPriceListRepository {
+	return &GormPriceListRepository{db: db}
+}
+
+// Create inserts a new price list
+func (r *GormPriceListRepository) Create(priceList *models.PriceList) error {
+	return r.db.Create(priceList).Error
+}
+
+// Save persists all fields of an existing price list
+func (r *GormPriceListRepository) Save(priceList *models.PriceList) error {
+	return r.db.Save(priceList).Error
+}
+
+// Delete soft-deletes a price list
+func (r *GormPriceListRepository) Delete(priceList *models.PriceList) error {
+	return r.db.Delete(priceList).Error
+}
+
+// FindByID loads a price list belonging to the technician
+func (r *GormPriceListRepository) FindByID(technicianID, priceListID uint) (*models.PriceList, error) {
+	var priceList models.PriceList
+	if err := r.db.Where("technician_id = ?", technicianID).First(&priceList, priceListID).Error; err != nil {
+		return nil, err
+	}
+	return &priceList, nil
+}
+
+// ListForTechnician returns the technician's price lists, latest start first
+func (r *GormPriceListRepository) ListForTechnician(technicianID uint) ([]models.PriceList, error) {
+	var priceLists []models.PriceList
+	if err := r.db.Where("technician_id = ?", technicianID).
+		Order("starts_at DESC").Order("id DESC").
+		Find(&priceLists).Error; err != nil {
+		return nil, err
+	}
+	return priceLists, nil
+}
+
+// FindInForce loads the technician's price list in force at the given time
+func (r *GormPriceListRepository) FindInForce(technicianID uint, at time.Time) (*models.PriceList, error) {
+	var priceList models.PriceList
+	if err := r.db.Where("technician_id = ? AND starts_at <= ? AND ends_at > ?", technicianID, at, at).
+		Order("starts_at DESC").
+		First(&priceList).Error; err != nil {
+		return nil, err
+	}
+	return &priceList, nil
+}
+
+// CountOverlapping counts the technician's price lists that share any time with [startsAt, endsAt)
+func (r *GormPriceListRepository) CountOverlapping(technicianID uint, startsAt, endsAt time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.PriceList{}).
+		Where("technician_id = ? AND starts_at < ? AND ends_at > ?", technicianID, endsAt, startsAt).
+		Count(&count).Error
+	return count, err
+}
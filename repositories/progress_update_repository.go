@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"gorm.io/gorm"
+)
+
+// ProgressUpdateRepository provides persistence for order progress photos
+type ProgressUpdateRepository interface {
+	// Create inserts a progress update, and the customer notice when one is given, in a single transaction
+	Create(update *models.ProgressUpdate, notice *models.Message) error
+
+	// FindByID loads a progress update belonging to the order, with its author
+	FindByID(orderID, updateID uint) (*models.ProgressUpdate, error)
+
+	// ListForOrder returns an order's progress updates, oldest first
+	ListForOrder(orderID uint) ([]models.ProgressUpdate, error)
+
+	// Delete soft-deletes a progress update
+	Delete(update *models.ProgressUpdate) error
+}
+
+// GormProgressUpdateRepository implements ProgressUpdateRepository using GORM
+type GormProgressUpdateRepository struct {
+	db *gorm.DB
+}
+
+// NewProgressUpdateRepository creates a progress update repository backed by the given database
+func NewProgressUpdateRepository(db *gorm.DB) *GormProgressUpdateRepository {
+	return &GormProgressUpdateRepository{db: db}
+}
+
+// Create inserts a progress update, and the customer notice when one is given, in a single transaction
+func (r *GormProgressUpdateRepository) Create(update *models.ProgressUpdate, notice *models.Message) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Author").Create(update).Error; err != nil {
+			return err
+		}
+		if notice == nil {
+			return nil
+		}
+		return tx.Omit("Order", "Sender").Create(notice).Error
+	})
+}
+
+// FindByID loads a progress update belonging to the order, with its author
+func (r *GormProgressUpdateRepository) FindByID(orderID, updateID uint) (*models.ProgressUpdate, error) {
+	var update models.ProgressUpdate
+	if err := r.db.Preload("Author").Where("order_id = ?", orderID).First(&update, updateID).Error; err != nil {
+		return nil, err
+	}
+	return &update, nil
+}
+
+// ListForOrder returns an order's progress updates, oldest first
+func (r *GormProgressUpdateRepository) ListForOrder(orderID uint) ([]models.ProgressUpdate, error) {
+	var updates []models.ProgressUpdate
+	if err := r.db.Preload("Author").
+		Where("order_id = ?", orderID).
+		Order("created_at ASC").Order("id ASC").
+		Find(&updates).Error; err != nil {
+		return nil, err
+	}
+	return updates, nil
+}
+
+// Delete soft-deletes a progress update
+func (r *GormProgressUpdateRepository) Delete(update *models.ProgressUpdate) error {
+	return r.db.Delete(update).Error
+}
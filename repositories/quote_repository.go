@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"gorm.io/gorm"
+)
+
+// ErrQuoteConverted is returned by Convert when the quote request was converted concurrently
+var ErrQuoteConverted = errors.New("quote request has already been converted")
+
+// QuoteRepository provides persistence for guest quote requests
+type QuoteRepository interface {
+	// Create inserts a new quote request
+	Create(quote *models.QuoteRequest) error
+
+	// Save persists all fields of an existing quote request
+	Save(quote *models.QuoteRequest) error
+
+	// FindByID loads a quote request
+	FindByID(id uint) (*models.QuoteRequest, error)
+
+	// List returns a page of the quote requests with the given status, oldest first, with the total number that match
+	// An empty status lists every request
+	List(status string, limit, offset int) ([]models.QuoteRequest, int64, error)
+
+	// Convert creates the order for a quote request and marks the request converted in a single transaction
+	Convert(quote *models.QuoteRequest, order *models.Order) error
+}
+
+// GormQuoteRepository implements QuoteRepository using GORM
+type GormQuoteRepository struct {
+	db *gorm.DB
+}
+
+// NewQuoteRepository creates a quote repository backed by the given database
+func NewQuoteRepository(db *gorm.DB) *GormQuoteRepository {
+	return &GormQuoteRepository{db: db}
+}
+
+// Create inserts a new quote request
+func (r *GormQuoteRepository) Create(quote *models.QuoteRequest) error {
+	return r.db.Create(quote).Error
+}
+
+// Save persists all fields of an existing quote request
+func (r *GormQuoteRepository) Save(quote *models.QuoteRequest) error {
+	return r.db.Save(quote).Error
+}
+
+// FindByID loads a quote request
+func (r *GormQuoteRepository) FindByID(id uint) (*models.QuoteRequest, error) {
+	var quote models.QuoteRequest
+	if err := r.db.First(&quote, id).Error; err != nil {
+		return nil, err
+	}
+	return &quote, nil
+}
+
+// List returns a page of the quote requests with the given status, oldest first, with the total number that match
+// An empty status lists every request
+func (r *GormQuoteRepository) List(status string, limit, offset int) ([]models.QuoteRequest, int64, error) {
+	scope := r.db.Model(&models.QuoteRequest{})
+	if status != "" {
+		scope = scope.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := scope.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var quotes []models.QuoteRequest
+	if err := scope.Order("created_at, id").Limit(limit).Offset(offset).Find(&quotes).Error; err != nil {
+		return nil, 0, err
+	}
+	return quotes, total, nil
+}
+
+// Convert creates the order for a quote request and marks the request converted in a single transaction
+// The request is only converted if no one else has converted it first
+func (r *GormQuoteRepository) Convert(quote *models.QuoteRequest, order *models.Order) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(order).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		result := tx.Model(&models.QuoteRequest{}).
+			Where("id = ? AND order_id IS NULL", quote.ID).
+			UpdateColumns(map[string]interface{}{
+				"status":       models.QuoteConverted,
+				"order_id":     order.ID,
+				"converted_at": now,
+				"updated_at":   now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrQuoteConverted
+		}
+
+		quote.Status = models.QuoteConverted
+		quote.OrderID = &order.ID
+		quote.ConvertedAt = &now
+		return nil
+	})
+}
@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"gorm.io/gorm"
+)
+
+// RecommendationRepository provides persistence for precomputed design recommendations
+type RecommendationRepository interface {
+	// ListByStatus returns up to limit orders in the status with their line items, newest first
+	ListByStatus(status string, limit int) ([]models.Order, error)
+
+	// ReplaceAll swaps every stored recommendation for the given ones in one transaction
+	ReplaceAll(recommendations []models.Recommendation) error
+
+	// ListForCustomer returns the customer's recommendations, best first, with the suggested orders and their line items
+	ListForCustomer(customerID uint) ([]models.Recommendation, error)
+}
+
+// GormRecommendationRepository implements RecommendationRepository using GORM
+type GormRecommendationRepository struct {
+	db *gorm.DB
+}
+
+// NewRecommendationRepository creates a recommendation repository backed by the given database
+func NewRecommendationRepository(db *gorm.DB) *GormRecommendationRepository {
+	return &GormRecommendationRepository{db: db}
+}
+
+// ListByStatus returns up to limit orders in the status with their line items, newest first
+func (r *GormRecommendationRepository) ListByStatus(status string, limit int) ([]models.Order, error) {
+	var orders []models.Order
+	if err := r.db.Preload("LineItems").
+		Where("status = ?", status).
+		Order("updated_at DESC, id DESC").
+		Limit(limit).
+		Find(&orders).Error; err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// ReplaceAll swaps every stored recommendation for the given ones in one transaction
+func (r *GormRecommendationRepository) ReplaceAll(recommendations []models.Recommendation) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.Recommendation{}).Error; err != nil {
+			return err
+		}
+		if len(recommendations) == 0 {
+			return nil
+		}
+		return tx.Omit("Order").CreateInBatches(recommendations, 500).Error
+	})
+}
+
+// ListForCustomer returns the customer's recommendations, best first, with the suggested orders and their line items
+func (r *GormRecommendationRepository) ListForCustomer(customerID uint) ([]models.Recommendation, error) {
+	var recommendations []models.Recommendation
+	if err := r.db.Preload("Order").
+		Preload("Order.LineItems").
+		Joins("JOIN orders ON orders.id = recommendations.order_id AND orders.deleted_at IS NULL").
+		Where("recommendations.customer_id = ?", customerID).
+		Order("recommendations.position ASC").
+		Find(&recommendations).Error; err != nil {
+		return nil, err
+	}
+	return recommendations, nil
+}
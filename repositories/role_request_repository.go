@@ -0,0 +1,145 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"gorm.io/gorm"
+)
+
+// ErrRoleRequestDecided is returned by Decide when the request was decided concurrently
+var ErrRoleRequestDecided = errors.New("role request has already been decided")
+
+// RoleRequestRepository provides persistence for customers' requests to change role
+type RoleRequestRepository interface {
+	// Create inserts a new role request
+	Create(request *models.RoleRequest) error
+
+	// FindByID loads a role request with its applicant
+	FindByID(id uint) (*models.RoleRequest, error)
+
+	// FindPending loads the user's pending role request
+	FindPending(userID uint) (*models.RoleRequest, error)
+
+	// ListByUser returns the user's role requests, newest first
+	ListByUser(userID uint) ([]models.RoleRequest, error)
+
+	// List returns a page of the role requests with the given status and their applicants, oldest first,
+	// with the total number that match; an empty status lists every request
+	List(status string, limit, offset int) ([]models.RoleRequest, int64, error)
+
+	// Decide records an admin's decision on a request that is still pending
+	Decide(request *models.RoleRequest, status string, reviewerID uint, note *string) error
+
+	// Reopen returns a decided request to pending, undoing Decide
+	Reopen(request *models.RoleRequest) error
+}
+
+// GormRoleRequestRepository implements RoleRequestRepository using GORM
+type GormRoleRequestRepository struct {
+	db *gorm.DB
+}
+
+// NewRoleRequestRepository creates a role request repository backed by the given database
+func NewRoleRequestRepository(db *gorm.DB) *GormRoleRequestRepository {
+	return &GormRoleRequestRepository{db: db}
+}
+
+// Create inserts a new role request
+func (r *GormRoleRequestRepository) Create(request *models.RoleRequest) error {
+	return r.db.Create(request).Error
+}
+
+// FindByID loads a role request with its applicant
+func (r *GormRoleRequestRepository) FindByID(id uint) (*models.RoleRequest, error) {
+	var request models.RoleRequest
+	if err := r.db.Preload("User").First(&request, id).Error; err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// FindPending loads the user's pending role request
+func (r *GormRoleRequestRepository) FindPending(userID uint) (*models.RoleRequest, error) {
+	var request models.RoleRequest
+	if err := r.db.Where("user_id = ? AND status = ?", userID, models.RoleRequestPending).First(&request).Error; err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// ListByUser returns the user's role requests, newest first
+func (r *GormRoleRequestRepository) ListByUser(userID uint) ([]models.RoleRequest, error) {
+	var requests []models.RoleRequest
+	if err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Order("id DESC").Find(&requests).Error; err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// List returns a page of the role requests with the given status and their applicants, oldest first,
+// with the total number that match; an empty status lists every request
+func (r *GormRoleRequestRepository) List(status string, limit, offset int) ([]models.RoleRequest, int64, error) {
+	scope := r.db.Model(&models.RoleRequest{})
+	if status != "" {
+		scope = scope.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := scope.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var requests []models.RoleRequest
+	if err := scope.Preload("User").Order("created_at, id").Limit(limit).Offset(offset).Find(&requests).Error; err != nil {
+		return nil, 0, err
+	}
+	return requests, total, nil
+}
+
+// Decide records an admin's decision on a request that is still pending
+func (r *GormRoleRequestRepository) Decide(request *models.RoleRequest, status string, reviewerID uint, note *string) error {
+	now := time.Now()
+	result := r.db.Model(&models.RoleRequest{}).
+		Where("id = ? AND status = ?", request.ID, models.RoleRequestPending).
+		UpdateColumns(map[string]interface{}{
+			"status":      status,
+			"reviewer_id": reviewerID,
+			"review_note": note,
+			"reviewed_at": now,
+			"updated_at":  now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRoleRequestDecided
+	}
+
+	request.Status = status
+	request.ReviewerID = &reviewerID
+	request.ReviewNote = note
+	request.ReviewedAt = &now
+	return nil
+}
+
+// Reopen returns a decided request to pending, undoing Decide
+func (r *GormRoleRequestRepository) Reopen(request *models.RoleRequest) error {
+	err := r.db.Model(&models.RoleRequest{}).Where("id = ?", request.ID).
+		UpdateColumns(map[string]interface{}{
+			"status":      models.RoleRequestPending,
+			"reviewer_id": nil,
+			"review_note": nil,
+			"reviewed_at": nil,
+			"updated_at":  time.Now(),
+		}).Error
+	if err != nil {
+		return err
+	}
+	request.Status = models.RoleRequestPending
+	request.ReviewerID = nil
+	request.ReviewNote = nil
+	request.ReviewedAt = nil
+	return nil
+}
@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"gorm.io/gorm"
+)
+
+// SavedDesignRepository provides persistence for customers' saved designs
+type SavedDesignRepository interface {
+	// Create inserts a new saved design
+	Create(design *models.SavedDesign) error
+
+	// Delete removes a saved design
+	Delete(design *models.SavedDesign) error
+
+	// FindForCustomer loads a saved design belonging to the customer
+	FindForCustomer(customerID, id uint) (*models.SavedDesign, error)
+
+	// ListForCustomer returns the customer's saved designs, newest first
+	ListForCustomer(customerID uint) ([]models.SavedDesign, error)
+}
+
+// GormSavedDesignRepository implements SavedDesignRepository using GORM
+type GormSavedDesignRepository struct {
+	db *gorm.DB
+}
+
+// NewSavedDesignRepository creates a saved design repository backed by the given database
+func NewSavedDesignRepository(db *gorm.DB) *GormSavedDesignRepository {
+	return &GormSavedDesignRepository{db: db}
+}
+
+// Create inserts a new saved design
+func (r *GormSavedDesignRepository) Create(design *models.SavedDesign) error {
+	return r.db.Create(design).Error
+}
+
+// Delete soft deletes a saved design; orders placed from it keep their own description and image
+func (r *GormSavedDesignRepository) Delete(design *models.SavedDesign) error {
+	return r.db.Delete(design).Error
+}
+
+// FindForCustomer loads a saved design belonging to the customer
+func (r *GormSavedDesignRepository) FindForCustomer(customerID, id uint) (*models.SavedDesign, error) {
+	var design models.SavedDesign
+	if err := r.db.Where("customer_id = ?", customerID).First(&design, id).Error; err != nil {
+		return nil, err
+	}
+	return &design, nil
+}
+
+// ListForCustomer returns the customer's saved designs, newest first
+func (r *GormSavedDesignRepository) ListForCustomer(customerID uint) ([]models.SavedDesign, error) {
+	var designs []models.SavedDesign
+	if err := r.db.Where("customer_id = ?", customerID).Order("id DESC").Find(&designs).Error; err != nil {
+		return nil, err
+	}
+	return designs, nil
+}
@@ -0,0 +1,118 @@
+package repositories
+
+import (
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"gorm.io/gorm"
+)
+
+// SupplyRepository provides persistence for the supplies inventory and what orders consume
+type SupplyRepository interface {
+	// Create inserts a new supply
+	Create(supply *models.Supply) error
+
+	// Save persists all fields of an existing supply
+	Save(supply *models.Supply) error
+
+	// Delete retires a supply from the inventory
+	Delete(supply *models.Supply) error
+
+	// FindByID loads a supply that has not been retired
+	FindByID(id uint) (*models.Supply, error)
+
+	// List returns every supply that has not been retired, ordered by name
+	List() ([]models.Supply, error)
+
+	// ListLow returns the supplies at or below their low stock threshold, ordered by name
+	ListLow() ([]models.Supply, error)
+
+	// RecordUsage takes the used quantity out of stock and records the usage in one transaction
+	// It reports false, changing nothing, when there is not enough in stock; usage.Supply is reloaded on success
+	RecordUsage(usage *models.SupplyUsage) (bool, error)
+
+	// ListUsageForOrder returns what an order consumed, oldest first, including retired supplies
+	ListUsageForOrder(orderID uint) ([]models.SupplyUsage, error)
+}
+
+// GormSupplyRepository implements SupplyRepository using GORM
+type GormSupplyRepository struct {
+	db *gorm.DB
+}
+
+// NewSupplyRepository creates a supply repository backed by the given database
+func NewSupplyRepository(db *gorm.DB) *GormSupplyRepository {
+	return &GormSupplyRepository{db: db}
+}
+
+// Create inserts a new supply
+func (r *GormSupplyRepository) Create(supply *models.Supply) error {
+	return r.db.Create(supply).Error
+}
+
+// Save persists all fields of an existing supply
+func (r *GormSupplyRepository) Save(supply *models.Supply) error {
+	return r.db.Save(supply).Error
+}
+
+// Delete soft deletes a supply so recorded usage keeps its reference
+func (r *GormSupplyRepository) Delete(supply *models.Supply) error {
+	return r.db.Delete(supply).Error
+}
+
+// FindByID loads a supply that has not been retired
+func (r *GormSupplyRepository) FindByID(id uint) (*models.Supply, error) {
+	var supply models.Supply
+	if err := r.db.First(&supply, id).Error; err != nil {
+		return nil, err
+	}
+	return &supply, nil
+}
+
+// List returns every supply that has not been retired, ordered by name
+func (r *GormSupplyRepository) List() ([]models.Supply, error) {
+	var supplies []models.Supply
+	if err := r.db.Order("name ASC").Find(&supplies).Error; err != nil {
+		return nil, err
+	}
+	return supplies, nil
+}
+
+// ListLow returns the supplies at or below their low stock threshold, ordered by name
+func (r *GormSupplyRepository) ListLow() ([]models.Supply, error) {
+	var supplies []models.Supply
+	if err := r.db.Where("quantity <= low_stock_threshold").Order("name ASC").Find(&supplies).Error; err != nil {
+		return nil, err
+	}
+	return supplies, nil
+}
+
+// RecordUsage takes the used quantity out of stock and records the usage in one transaction
+// The stock check and decrement are a single conditional update so concurrent usage cannot overdraw a supply
+func (r *GormSupplyRepository) RecordUsage(usage *models.SupplyUsage) (bool, error) {
+	recorded := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Supply{}).
+			Where("id = ? AND quantity >= ?", usage.SupplyID, usage.Quantity).
+			UpdateColumn("quantity", gorm.Expr("quantity - ?", usage.Quantity))
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		if err := tx.Omit("Supply").Create(usage).Error; err != nil {
+			return err
+		}
+		recorded = true
+		return tx.First(&usage.Supply, usage.SupplyID).Error
+	})
+	return recorded && err == nil, err
+}
+
+// ListUsageForOrder returns what an order consumed, oldest first, including retired supplies
+func (r *GormSupplyRepository) ListUsageForOrder(orderID uint) ([]models.SupplyUsage, error) {
+	var usage []models.SupplyUsage
+	if err := r.db.Preload("Supply", func(tx *gorm.DB) *gorm.DB { return tx.Unscoped() }).
+		Where("order_id = ?", orderID).
+		Order("created_at ASC, id ASC").
+		Find(&usage).Error; err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"sort"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"gorm.io/gorm"
+)
+
+// TechnicianSettingsRepository provides persistence for technicians' capacity and availability
+type TechnicianSettingsRepository interface {
+	// FindForTechnician loads the technician's settings
+	FindForTechnician(technicianID uint) (*models.TechnicianSettings, error)
+
+	// FindByCalendarTokenHash loads the settings whose calendar feed token has the given hash
+	FindByCalendarTokenHash(hash string) (*models.TechnicianSettings, error)
+
+	// Save creates or updates a technician's settings
+	Save(settings *models.TechnicianSettings) error
+
+	// ListTechnicians returns every technician with the settings of those who have any, by name
+	ListTechnicians() ([]models.User, map[uint]models.TechnicianSettings, error)
+
+	// CountAssigned returns how many orders in the statuses are assigned to the technician
+	CountAssigned(technicianID uint, statuses []string) (int64, error)
+}
+
+// GormTechnicianSettingsRepository implements TechnicianSettingsRepository using GORM
+type GormTechnicianSettingsRepository struct {
+	db *gorm.DB
+}
+
+// NewTechnicianSettingsRepository creates a technician settings repository backed by the given database
+func NewTechnicianSettingsRepository(db *gorm.DB) *GormTechnicianSettingsRepository {
+	return &GormTechnicianSettingsRepository{db: db}
+}
+
+// FindForTechnician loads the technician's settings
+func (r *GormTechnicianSettingsRepository) FindForTechnician(technicianID uint) (*models.TechnicianSettings, error) {
+	var settings models.TechnicianSettings
+	if err := r.db.Where("user_id = ?", technicianID).First(&settings).Error; err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// FindByCalendarTokenHash loads the settings whose calendar feed token has the given hash
+func (r *GormTechnicianSettingsRepository) FindByCalendarTokenHash(hash string) (*models.TechnicianSettings, error) {
+	var settings models.TechnicianSettings
+	if err := r.db.Where("calendar_token_hash = ?", hash).First(&settings).Error; err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// Save creates or updates a technician's settings
+func (r *GormTechnicianSettingsRepository) Save(settings *models.TechnicianSettings) error {
+	return r.db.Save(settings).Error
+}
+
+// ListTechnicians returns every technician with the settings of those who have any, by name
+func (r *GormTechnicianSettingsRepository) ListTechnicians() ([]models.User, map[uint]models.TechnicianSettings, error) {
+	var technicians []models.User
+	if err := r.db.Where("role = ?", "technician").Order("id ASC").Find(&technicians).Error; err != nil {
+		return nil, nil, err
+	}
+	// Names may be encrypted at rest, so they are sorted once decrypted
+	sort.SliceStable(technicians, func(i, j int) bool { return technicians[i].Name < technicians[j].Name })
+
+	var rows []models.TechnicianSettings
+	if err := r.db.Find(&rows).Error; err != nil {
+		return nil, nil, err
+	}
+	settings := make(map[uint]models.TechnicianSettings, len(rows))
+	for _, row := range rows {
+		settings[row.UserID] = row
+	}
+	return technicians, settings, nil
+}
+
+// CountAssigned returns how many orders in the statuses are assigned to the technician
+func (r *GormTechnicianSettingsRepository) CountAssigned(technicianID uint, statuses []string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Order{}).
+		Where("technician_id = ? AND status IN ?", technicianID, statuses).
+		Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,120 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UserRepository provides persistence for users
+type UserRepository interface {
+	// FindByID loads a user by primary key
+	FindByID(id uint) (*models.User, error)
+
+	// FindByAuth0ID loads a user by the identity provider subject
+	FindByAuth0ID(auth0ID string) (*models.User, error)
+
+	// FindByEmail loads a user by normalized email address
+	FindByEmail(email string) (*models.User, error)
+
+	// FindDeletedByAuth0ID loads the most recently deleted account of an identity
+	FindDeletedByAuth0ID(auth0ID string) (*models.User, error)
+
+	// CreateIfAbsent inserts a new user unless an active account of the same identity exists,
+	// reporting whether the user was inserted
+	// The check is made by the database, so concurrent signups of one identity create a single account
+	CreateIfAbsent(user *models.User) (bool, error)
+
+	// Restore undeletes a soft-deleted user and saves its profile fields
+	Restore(user *models.User) error
+
+	// UpdateRole changes a user's role
+	UpdateRole(id uint, role string) error
+
+	// UpdateBan bans a user at bannedAt for the reason, or lifts the ban when bannedAt is nil
+	UpdateBan(id uint, bannedAt *time.Time, reason *string) error
+}
+
+// GormUserRepository implements UserRepository using GORM
+type GormUserRepository struct {
+	db *gorm.DB
+}
+
+// NewUserRepository creates a user repository backed by the given database
+func NewUserRepository(db *gorm.DB) *GormUserRepository {
+	return &GormUserRepository{db: db}
+}
+
+// FindByID loads a user by primary key
+func (r *GormUserRepository) FindByID(id uint) (*models.User, error) {
+	var user models.User
+	if err := r.db.First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// FindByAuth0ID loads a user by the identity provider subject
+func (r *GormUserRepository) FindByAuth0ID(auth0ID string) (*models.User, error) {
+	var user models.User
+	if err := r.db.Where("auth0_id = ?", auth0ID).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// FindByEmail loads a user by normalized email address
+// The comparison ignores case so rows written before normalization still match,
+// and uses the blind index for rows whose email is encrypted
+func (r *GormUserRepository) FindByEmail(email string) (*models.User, error) {
+	var user models.User
+	if err := r.db.Scopes(models.WhereEmail(email)).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// FindDeletedByAuth0ID loads the most recently deleted account of an identity
+func (r *GormUserRepository) FindDeletedByAuth0ID(auth0ID string) (*models.User, error) {
+	var user models.User
+	if err := r.db.Unscoped().
+		Where("auth0_id = ? AND deleted_at IS NOT NULL", auth0ID).
+		Order("deleted_at DESC").
+		First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// CreateIfAbsent inserts a new user unless an active account of the same identity exists,
+// reporting whether the user was inserted
+func (r *GormUserRepository) CreateIfAbsent(user *models.User) (bool, error) {
+	// The conflict target names the partial unique index on active identities
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "auth0_id"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "deleted_at IS NULL"}}},
+		DoNothing:   true,
+	}).Create(user)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Restore undeletes a soft-deleted user and saves its profile fields
+func (r *GormUserRepository) Restore(user *models.User) error {
+	user.DeletedAt = gorm.DeletedAt{}
+	return r.db.Unscoped().Save(user).Error
+}
+
+// UpdateRole changes a user's role
+func (r *GormUserRepository) UpdateRole(id uint, role string) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).Update("role", role).Error
+}
+
+// UpdateBan bans a user at bannedAt for the reason, or lifts the ban when bannedAt is nil
+func (r *GormUserRepository) UpdateBan(id uint, bannedAt *time.Time, reason *string) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"banned_at":  bannedAt,
+		"ban_reason": reason,
+	}).Error
+}
@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestUserRepository_CreateIfAbsent(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := models.MigrateUp(db); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+	repo := NewUserRepository(db)
+
+	first := models.User{Auth0ID: "auth0|jane", Name: "Jane", Email: "jane@example.com", Role: "customer"}
+	created, err := repo.CreateIfAbsent(&first)
+	assert.NoError(t, err)
+	assert.True(t, created)
+	assert.NotZero(t, first.ID)
+
+	// A second signup of the same identity inserts nothing and does not fail
+	retry := models.User{Auth0ID: "auth0|jane", Name: "Jane Retry", Email: "jane.retry@example.com", Role: "customer"}
+	created, err = repo.CreateIfAbsent(&retry)
+	assert.NoError(t, err)
+	assert.False(t, created)
+
+	var count int64
+	db.Model(&models.User{}).Where("auth0_id = ?", "auth0|jane").Count(&count)
+	assert.Equal(t, int64(1), count)
+
+	// A deleted account does not hold the identity
+	db.Delete(&first)
+	again := models.User{Auth0ID: "auth0|jane", Name: "Jane", Email: "jane@example.com", Role: "customer"}
+	created, err = repo.CreateIfAbsent(&again)
+	assert.NoError(t, err)
+	assert.True(t, created)
+
+	// Other unique columns still fail the insert
+	taken := models.User{Auth0ID: "auth0|other", Name: "Other", Email: "jane@example.com", Role: "customer"}
+	_, err = repo.CreateIfAbsent(&taken)
+	assert.Error(t, err)
+}
@@ -0,0 +1,134 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"gorm.io/gorm"
+)
+
+// WebhookRepository provides persistence for webhooks and their delivery log
+type WebhookRepository interface {
+	// Create inserts a new webhook
+	Create(webhook *models.Webhook) error
+
+	// FindByID loads a webhook by ID
+	FindByID(id uint) (*models.Webhook, error)
+
+	// List returns every webhook, oldest first
+	List() ([]models.Webhook, error)
+
+	// Delete soft-deletes a webhook; its pending deliveries are abandoned
+	Delete(webhook *models.Webhook) error
+
+	// CreateDeliveries inserts queued deliveries in one statement
+	CreateDeliveries(deliveries []models.WebhookDelivery) error
+
+	// ListDueDeliveries returns up to limit pending deliveries whose next attempt is due, oldest first
+	ListDueDeliveries(now time.Time, limit int) ([]models.WebhookDelivery, error)
+
+	// ClaimDelivery counts an attempt on a due delivery and moves its next attempt to leaseUntil,
+	// so that no other instance sends it meanwhile
+	// It returns false when another instance claimed the delivery first
+	ClaimDelivery(delivery *models.WebhookDelivery, leaseUntil time.Time) (bool, error)
+
+	// SaveDelivery persists all fields of an existing delivery
+	SaveDelivery(delivery *models.WebhookDelivery) error
+
+	// ListDeliveries returns up to limit of the webhook's deliveries, newest first
+	ListDeliveries(webhookID uint, limit int) ([]models.WebhookDelivery, error)
+}
+
+// GormWebhookRepository implements WebhookRepository using GORM
+type GormWebhookRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookRepository creates a webhook repository backed by the given database
+func NewWebhookRepository(db *gorm.DB) *GormWebhookRepository {
+	return &GormWebhookRepository{db: db}
+}
+
+// Create inserts a new webhook
+func (r *GormWebhookRepository) Create(webhook *models.Webhook) error {
+	return r.db.Create(webhook).Error
+}
+
+// FindByID loads a webhook by ID
+func (r *GormWebhookRepository) FindByID(id uint) (*models.Webhook, error) {
+	var webhook models.Webhook
+	if err := r.db.First(&webhook, id).Error; err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// List returns every webhook, oldest first
+func (r *GormWebhookRepository) List() ([]models.Webhook, error) {
+	var webhooks []models.Webhook
+	if err := r.db.Order("id ASC").Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// Delete soft-deletes a webhook; its pending deliveries are abandoned
+func (r *GormWebhookRepository) Delete(webhook *models.Webhook) error {
+	return r.db.Delete(webhook).Error
+}
+
+// CreateDeliveries inserts queued deliveries in one statement
+func (r *GormWebhookRepository) CreateDeliveries(deliveries []models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return r.db.Create(&deliveries).Error
+}
+
+// ListDueDeliveries returns up to limit pending deliveries whose next attempt is due, oldest first
+func (r *GormWebhookRepository) ListDueDeliveries(now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	if err := r.db.Where("status = ? AND next_attempt_at <= ?", models.DeliveryPending, now).
+		Order("next_attempt_at ASC").Order("id ASC").
+		Limit(limit).
+		Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// ClaimDelivery counts an attempt on a due delivery and moves its next attempt to leaseUntil
+func (r *GormWebhookRepository) ClaimDelivery(delivery *models.WebhookDelivery, leaseUntil time.Time) (bool, error) {
+	result := r.db.Model(&models.WebhookDelivery{}).
+		Where("id = ? AND status = ? AND attempts = ?", delivery.ID, models.DeliveryPending, delivery.Attempts).
+		UpdateColumns(map[string]interface{}{
+			"attempts":        gorm.Expr("attempts + 1"),
+			"next_attempt_at": leaseUntil,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	delivery.Attempts++
+	delivery.NextAttemptAt = leaseUntil
+	return true, nil
+}
+
+// SaveDelivery persists all fields of an existing delivery
+func (r *GormWebhookRepository) SaveDelivery(delivery *models.WebhookDelivery) error {
+	return r.db.Save(delivery).Error
+}
+
+// ListDeliveries returns up to limit of the webhook's deliveries, newest first
+func (r *GormWebhookRepository) ListDeliveries(webhookID uint, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	if err := r.db.Where("webhook_id = ?", webhookID).
+		Order("id DESC").
+		Limit(limit).
+		Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
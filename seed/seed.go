@@ -0,0 +1,136 @@
+package seed
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"gorm.io/gorm"
+)
+
+// demoUsers are the customers, technicians, and admin created for local development
+// Auth0 IDs use the "seed|" prefix so they never collide with real accounts
+var demoUsers = []models.User{
+	{Auth0ID: "seed|technician-1", Name: "Kendall Kelly", Email: "kendall@example.com", Role: "technician"},
+	{Auth0ID: "seed|technician-2", Name: "Jordan Lee", Email: "jordan@example.com", Role: "technician"},
+	{Auth0ID: "seed|customer-1", Name: "Alex Rivera", Email: "alex@example.com", Role: "customer"},
+	{Auth0ID: "seed|customer-2", Name: "Sam Taylor", Email: "sam@example.com", Role: "customer"},
+	{Auth0ID: "seed|customer-3", Name: "Casey Morgan", Email: "casey@example.com", Role: "customer"},
+	{Auth0ID: "seed|admin-1", Name: "Riley Brooks", Email: "riley@example.com", Role: "admin"},
+}
+
+// demoAddresses are the demo customers' home addresses, keyed by Auth0 ID; their orders ship there
+var demoAddresses = map[string]models.Address{
+	"seed|customer-1": {Label: "Home", Recipient: "Alex Rivera", Line1: "12 Orchard Lane", City: "Portland", Region: "OR", PostalCode: "97201", Country: "US"},
+	"seed|customer-2": {Label: "Home", Recipient: "Sam Taylor", Line1: "48 Harbor Street", Line2: "Apt 3", City: "Seattle", Region: "WA", PostalCode: "98101", Country: "US"},
+	"seed|customer-3": {Label: "Home", Recipient: "Casey Morgan", Line1: "7 Maple Avenue", City: "Toronto", Region: "ON", PostalCode: "M5V 2T6", Country: "CA"},
+}
+
+// demoOrder describes an order to seed, referencing users by Auth0 ID
+type demoOrder struct {
+	CustomerAuth0ID   string
+	TechnicianAuth0ID string
+	Description       string
+	Quantity          int
+	Status            string
+	Price             float64
+	Feedback          string
+}
+
+// demoOrders cover every status in the order workflow
+var demoOrders = []demoOrder{
+	{CustomerAuth0ID: "seed|customer-1", Description: "Pink almond nails with glitter tips", Quantity: 1, Status: "submitted"},
+	{CustomerAuth0ID: "seed|customer-1", TechnicianAuth0ID: "seed|technician-1", Description: "French tips, short square", Quantity: 2, Status: "accepted", Price: 45},
+	{CustomerAuth0ID: "seed|customer-2", TechnicianAuth0ID: "seed|technician-1", Description: "Chrome coffin nails", Quantity: 1, Status: "in_production", Price: 60},
+	{CustomerAuth0ID: "seed|customer-2", TechnicianAuth0ID: "seed|technician-2", Description: "Floral design on nude base", Quantity: 1, Status: "shipped", Price: 55},
+	{CustomerAuth0ID: "seed|customer-3", TechnicianAuth0ID: "seed|technician-2", Description: "Matte black stiletto", Quantity: 3, Status: "delivered", Price: 120},
+	{CustomerAuth0ID: "seed|customer-3", TechnicianAuth0ID: "seed|technician-1", Description: "Full 3D sculpted set", Quantity: 1, Status: "rejected", Feedback: "3D sculpting is not currently offered"},
+}
+
+// demoDesigns are the pre-designed sets in the demo catalog, added by the demo admin
+var demoDesigns = []models.CatalogDesign{
+	{Name: "Classic French", Description: "Short square French tips", BasePrice: 35, Sizes: []string{"XS", "S", "M", "L"}},
+	{Name: "Chrome Coffin", Description: "Mirror chrome on long coffin tips", BasePrice: 55, Sizes: []string{"S", "M", "L"}},
+	{Name: "Pastel Florals", Description: "Hand-painted flowers on a pastel almond base", BasePrice: 65, Sizes: []string{"S", "M"}},
+}
+
+// Run creates demo customers, technicians, addresses, catalog designs, and orders for local development
+// It is idempotent: users are matched by Auth0 ID, addresses by customer and label, designs by name, and orders by customer and description
+func Run(db *gorm.DB) error {
+	users := make(map[string]models.User, len(demoUsers))
+	for _, demo := range demoUsers {
+		user := demo
+		if err := db.Where("auth0_id = ?", demo.Auth0ID).FirstOrCreate(&user).Error; err != nil {
+			return fmt.Errorf("failed to seed user %s: %w", demo.Auth0ID, err)
+		}
+		users[user.Auth0ID] = user
+	}
+	log.Printf("Seeded %d users", len(users))
+
+	addresses := make(map[string]models.Address, len(demoAddresses))
+	for auth0ID, demo := range demoAddresses {
+		address := demo
+		address.UserID = users[auth0ID].ID
+		if err := db.Where("user_id = ? AND label = ?", address.UserID, demo.Label).FirstOrCreate(&address).Error; err != nil {
+			return fmt.Errorf("failed to seed address of %s: %w", auth0ID, err)
+		}
+		addresses[auth0ID] = address
+	}
+	log.Printf("Seeded %d addresses", len(addresses))
+
+	admin := users["seed|admin-1"]
+	for _, demo := range demoDesigns {
+		design := demo
+		design.CreatedByID = admin.ID
+		if err := db.Omit("Images").Where("name = ?", demo.Name).FirstOrCreate(&design).Error; err != nil {
+			return fmt.Errorf("failed to seed catalog design %q: %w", demo.Name, err)
+		}
+	}
+	log.Printf("Seeded %d catalog designs", len(demoDesigns))
+
+	created := 0
+	for _, demo := range demoOrders {
+		customer := users[demo.CustomerAuth0ID]
+
+		var existing int64
+		if err := db.Model(&models.Order{}).
+			Where("customer_id = ? AND description = ?", customer.ID, demo.Description).
+			Count(&existing).Error; err != nil {
+			return fmt.Errorf("failed to check existing orders: %w", err)
+		}
+		if existing > 0 {
+			continue
+		}
+
+		order := models.Order{
+			Description: demo.Description,
+			Quantity:    demo.Quantity,
+			Status:      demo.Status,
+			CustomerID:  customer.ID,
+		}
+		if address, ok := addresses[demo.CustomerAuth0ID]; ok {
+			order.ShippingAddressID = &address.ID
+			order.ShippingAddress = address.Snapshot()
+		}
+		if demo.TechnicianAuth0ID != "" {
+			technician := users[demo.TechnicianAuth0ID]
+			order.TechnicianID = &technician.ID
+		}
+		if demo.Price > 0 {
+			price := demo.Price
+			order.Price = &price
+		}
+		if demo.Feedback != "" {
+			feedback := demo.Feedback
+			order.Feedback = &feedback
+		}
+
+		if err := db.Create(&order).Error; err != nil {
+			return fmt.Errorf("failed to seed order %q: %w", demo.Description, err)
+		}
+		created++
+	}
+	log.Printf("Seeded %d orders", created)
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package seed
+
+import (
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupSeedTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := models.MigrateUp(db); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+	return db
+}
+
+func TestRun(t *testing.T) {
+	db := setupSeedTestDB(t)
+
+	assert.NoError(t, Run(db))
+
+	var customers, technicians, orders int64
+	db.Model(&models.User{}).Where("role = ?", "customer").Count(&customers)
+	db.Model(&models.User{}).Where("role = ?", "technician").Count(&technicians)
+	db.Model(&models.Order{}).Count(&orders)
+
+	assert.Equal(t, int64(3), customers)
+	assert.Equal(t, int64(2), technicians)
+	assert.Equal(t, int64(len(demoOrders)), orders)
+
+	// Assigned orders reference a seeded technician
+	var order models.Order
+	assert.NoError(t, db.Where("status = ?", "in_production").First(&order).Error)
+	assert.NotNil(t, order.TechnicianID)
+	assert.NotNil(t, order.Price)
+
+	// Orders ship to their customer's seeded address
+	assert.NotNil(t, order.ShippingAddressID)
+	if assert.NotNil(t, order.ShippingAddress) {
+		assert.Equal(t, "Seattle", order.ShippingAddress.City)
+	}
+}
+
+func TestRun_Idempotent(t *testing.T) {
+	db := setupSeedTestDB(t)
+
+	assert.NoError(t, Run(db))
+	assert.NoError(t, Run(db))
+
+	var users, addresses, designs, orders int64
+	db.Model(&models.User{}).Count(&users)
+	db.Model(&models.Address{}).Count(&addresses)
+	db.Model(&models.CatalogDesign{}).Count(&designs)
+	db.Model(&models.Order{}).Count(&orders)
+
+	assert.Equal(t, int64(len(demoUsers)), users)
+	assert.Equal(t, int64(len(demoAddresses)), addresses)
+	assert.Equal(t, int64(len(demoDesigns)), designs)
+	assert.Equal(t, int64(len(demoOrders)), orders)
+}
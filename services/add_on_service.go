@@ -0,0 +1,180 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"gorm.io/gorm"
+)
+
+// AddOnInput holds the editable fields of a catalog add-on
+type AddOnInput struct {
+	Name        string
+	Description string
+	Price       float64
+}
+
+// AddOnService manages the technician-maintained add-on catalog
+type AddOnService interface {
+	// ListAddOns returns the current catalog (any authenticated user)
+	ListAddOns() ([]models.AddOn, error)
+
+	// CreateAddOn adds an item to the catalog (technicians only)
+	CreateAddOn(technician *models.User, input AddOnInput) (*models.AddOn, error)
+
+	// UpdateAddOn changes a catalog item (technicians only)
+	UpdateAddOn(technician *models.User, addOnID string, input AddOnInput) (*models.AddOn, error)
+
+	// DeleteAddOn retires a catalog item (technicians only)
+	DeleteAddOn(technician *models.User, addOnID string) error
+}
+
+// DefaultAddOnService implements AddOnService on top of an AddOnRepository
+type DefaultAddOnService struct {
+	addOns repositories.AddOnRepository
+	audit  repositories.AuditLogRepository // nil records nothing
+}
+
+var addOnServiceInstance AddOnService
+
+// NewAddOnService creates an add-on service using the given repository
+func NewAddOnService(addOns repositories.AddOnRepository) *DefaultAddOnService {
+	return &DefaultAddOnService{addOns: addOns}
+}
+
+// GetAddOnService returns the configured add-on service
+// When none has been set, a service over the current database connection is returned
+func GetAddOnService() AddOnService {
+	if addOnServiceInstance != nil {
+		return addOnServiceInstance
+	}
+	db := config.GetDB()
+	service := NewAddOnService(repositories.NewAddOnRepository(db))
+	service.audit = repositories.NewAuditLogRepository(db)
+	return service
+}
+
+// SetAddOnService sets the add-on service instance (primarily for testing)
+func SetAddOnService(service AddOnService) {
+	addOnServiceInstance = service
+}
+
+// ListAddOns returns the current catalog
+func (s *DefaultAddOnService) ListAddOns() ([]models.AddOn, error) {
+	addOns, err := s.addOns.List()
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to fetch add-ons").Wrap(err)
+	}
+	return addOns, nil
+}
+
+// CreateAddOn adds an item to the catalog
+func (s *DefaultAddOnService) CreateAddOn(technician *models.User, input AddOnInput) (*models.AddOn, error) {
+	if technician.Role != RoleTechnician {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only technicians can manage add-ons")
+	}
+	if err := validateAddOnInput(input); err != nil {
+		return nil, err
+	}
+
+	addOn := &models.AddOn{
+		Name:        input.Name,
+		Description: input.Description,
+		Price:       input.Price,
+		Currency:    studioCurrency(),
+		CreatedByID: technician.ID,
+	}
+	if err := s.addOns.Create(addOn); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to create add-on").Wrap(err)
+	}
+	if err := recordAudit(s.audit, technician, models.AuditAddOnPriceSet, models.AuditTargetAddOn, addOn.ID, nil, addOnPrice(addOn)); err != nil {
+		return nil, err
+	}
+	return addOn, nil
+}
+
+// UpdateAddOn changes a catalog item
+// Orders that were already quoted keep the name and price they were quoted with
+func (s *DefaultAddOnService) UpdateAddOn(technician *models.User, addOnID string, input AddOnInput) (*models.AddOn, error) {
+	if technician.Role != RoleTechnician {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only technicians can manage add-ons")
+	}
+	if err := validateAddOnInput(input); err != nil {
+		return nil, err
+	}
+
+	addOn, err := s.find(addOnID)
+	if err != nil {
+		return nil, err
+	}
+
+	before := addOnPrice(addOn)
+	addOn.Name = input.Name
+	addOn.Description = input.Description
+	addOn.Price = input.Price
+	addOn.Currency = studioCurrency()
+
+	if err := s.addOns.Save(addOn); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to update add-on").Wrap(err)
+	}
+	if after := addOnPrice(addOn); auditChanged(before, after) {
+		if err := recordAudit(s.audit, technician, models.AuditAddOnPriceSet, models.AuditTargetAddOn, addOn.ID, before, after); err != nil {
+			return nil, err
+		}
+	}
+	return addOn, nil
+}
+
+// DeleteAddOn retires a catalog item
+func (s *DefaultAddOnService) DeleteAddOn(technician *models.User, addOnID string) error {
+	if technician.Role != RoleTechnician {
+		return apierror.Forbidden("FORBIDDEN", "Only technicians can manage add-ons")
+	}
+
+	addOn, err := s.find(addOnID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.addOns.Delete(addOn); err != nil {
+		return apierror.Internal("DATABASE_ERROR", "Failed to delete add-on").Wrap(err)
+	}
+	return nil
+}
+
+// addOnPrice returns the audited price fields of an add-on
+func addOnPrice(addOn *models.AddOn) map[string]interface{} {
+	return map[string]interface{}{"price": addOn.Price, "currency": addOn.Currency}
+}
+
+// find loads an add-on by its path parameter
+func (s *DefaultAddOnService) find(addOnID string) (*models.AddOn, error) {
+	id, err := strconv.ParseUint(addOnID, 10, 64)
+	if err != nil || id == 0 {
+		return nil, apierror.NotFound("ADD_ON_NOT_FOUND", "Add-on not found")
+	}
+
+	addOn, err := s.addOns.FindByID(uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apierror.NotFound("ADD_ON_NOT_FOUND", "Add-on not found")
+		}
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load add-on").Wrap(err)
+	}
+	return addOn, nil
+}
+
+// validateAddOnInput checks the fields shared by create and update
+func validateAddOnInput(input AddOnInput) error {
+	if input.Name == "" {
+		return apierror.Validation("Name is required", nil)
+	}
+	if input.Price < 0 {
+		return apierror.Validation("Price cannot be negative", nil)
+	}
+	return requireWholeCents("Price", input.Price)
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"net/http"
+	"sort"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// fakeAddOnRepository is an in-memory AddOnRepository
+type fakeAddOnRepository struct {
+	addOns map[uint]*models.AddOn
+	nextID uint
+}
+
+func newFakeAddOnRepository(addOns ...models.AddOn) *fakeAddOnRepository {
+	repo := &fakeAddOnRepository{addOns: make(map[uint]*models.AddOn), nextID: 1}
+	for i := range addOns {
+		addOn := addOns[i]
+		if addOn.ID >= repo.nextID {
+			repo.nextID = addOn.ID + 1
+		}
+		repo.addOns[addOn.ID] = &addOn
+	}
+	return repo
+}
+
+func (r *fakeAddOnRepository) Create(addOn *models.AddOn) error {
+	addOn.ID = r.nextID
+	r.nextID++
+	stored := *addOn
+	r.addOns[addOn.ID] = &stored
+	return nil
+}
+
+func (r *fakeAddOnRepository) Save(addOn *models.AddOn) error {
+	stored := *addOn
+	r.addOns[addOn.ID] = &stored
+	return nil
+}
+
+func (r *fakeAddOnRepository) Delete(addOn *models.AddOn) error {
+	delete(r.addOns, addOn.ID)
+	return nil
+}
+
+func (r *fakeAddOnRepository) FindByID(id uint) (*models.AddOn, error) {
+	addOn, ok := r.addOns[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	found := *addOn
+	return &found, nil
+}
+
+func (r *fakeAddOnRepository) List() ([]models.AddOn, error) {
+	var addOns []models.AddOn
+	for _, addOn := range r.addOns {
+		addOns = append(addOns, *addOn)
+	}
+	sort.Slice(addOns, func(i, j int) bool { return addOns[i].Name < addOns[j].Name })
+	return addOns, nil
+}
+
+func TestAddOnService_Catalog(t *testing.T) {
+	service := NewAddOnService(newFakeAddOnRepository())
+
+	_, err := service.CreateAddOn(testCustomer, AddOnInput{Name: "Charm", Price: 2})
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+
+	_, err = service.CreateAddOn(testTechnician, AddOnInput{Name: "", Price: 2})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+
+	_, err = service.CreateAddOn(testTechnician, AddOnInput{Name: "Charm", Price: -1})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+
+	_, err = service.CreateAddOn(testTechnician, AddOnInput{Name: "Charm", Price: 2.005})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+
+	charm, err := service.CreateAddOn(testTechnician, AddOnInput{Name: "Charm", Price: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, testTechnician.ID, charm.CreatedByID)
+	assert.Equal(t, "USD", charm.Currency)
+
+	updated, err := service.UpdateAddOn(otherTech, "1", AddOnInput{Name: "Gold charm", Price: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, "Gold charm", updated.Name)
+	assert.Equal(t, 3.0, updated.Price)
+
+	_, err = service.UpdateAddOn(testTechnician, "abc", AddOnInput{Name: "X"})
+	assertAPIError(t, err, http.StatusNotFound, "ADD_ON_NOT_FOUND")
+
+	assertAPIError(t, service.DeleteAddOn(testCustomer, "1"), http.StatusForbidden, "FORBIDDEN")
+	assert.NoError(t, service.DeleteAddOn(testTechnician, "1"))
+	assertAPIError(t, service.DeleteAddOn(testTechnician, "1"), http.StatusNotFound, "ADD_ON_NOT_FOUND")
+
+	addOns, err := service.ListAddOns()
+	assert.NoError(t, err)
+	assert.Empty(t, addOns)
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"github.com/kendall-kelly/kendalls-nails-api/utils"
+	"gorm.io/gorm"
+)
+
+// MaxAddresses limits how many addresses one user can keep in their address book
+const MaxAddresses = 20
+
+// MaxAddressFieldLength limits the length of each address field in characters
+const MaxAddressFieldLength = 200
+
+// AddressInput holds the editable fields of an address
+type AddressInput struct {
+	Label      string
+	Recipient  string
+	Line1      string
+	Line2      string
+	City       string
+	Region     string
+	PostalCode string
+	Country    string
+}
+
+// AddressService manages users' own address books
+type AddressService interface {
+	// ListAddresses returns the user's addresses
+	ListAddresses(user *models.User) ([]models.Address, error)
+
+	// CreateAddress adds an address to the user's address book
+	CreateAddress(user *models.User, input AddressInput) (*models.Address, error)
+
+	// UpdateAddress changes one of the user's addresses
+	UpdateAddress(user *models.User, addressID string, input AddressInput) (*models.Address, error)
+
+	// DeleteAddress removes one of the user's addresses
+	DeleteAddress(user *models.User, addressID string) error
+}
+
+// DefaultAddressService implements AddressService on top of an AddressRepository
+type DefaultAddressService struct {
+	addresses repositories.AddressRepository
+}
+
+var addressServiceInstance AddressService
+
+// NewAddressService creates an address service using the given repository
+func NewAddressService(addresses repositories.AddressRepository) *DefaultAddressService {
+	return &DefaultAddressService{addresses: addresses}
+}
+
+// GetAddressService returns the configured address service
+// When none has been set, a service over the current database connection is returned
+func GetAddressService() AddressService {
+	if addressServiceInstance != nil {
+		return addressServiceInstance
+	}
+	return NewAddressService(repositories.NewAddressRepository(config.GetDB()))
+}
+
+// SetAddressService sets the address service instance (primarily for testing)
+func SetAddressService(service AddressService) {
+	addressServiceInstance = service
+}
+
+// ListAddresses returns the user's addresses, oldest first
+func (s *DefaultAddressService) ListAddresses(user *models.User) ([]models.Address, error) {
+	addresses, err := s.addresses.ListForUser(user.ID)
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to fetch addresses").Wrap(err)
+	}
+	return addresses, nil
+}
+
+// CreateAddress adds an address to the user's address book
+func (s *DefaultAddressService) CreateAddress(user *models.User, input AddressInput) (*models.Address, error) {
+	input, err := validateAddressInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.addresses.CountForUser(user.ID)
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to count addresses").Wrap(err)
+	}
+	if count >= MaxAddresses {
+		return nil, apierror.Unprocessable("TOO_MANY_ADDRESSES", "Address book is full").WithDetails(map[string]interface{}{
+			"max_addresses": MaxAddresses,
+		})
+	}
+
+	address := &models.Address{UserID: user.ID}
+	applyAddressInput(address, input)
+	if err := s.addresses.Create(address); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to create address").Wrap(err)
+	}
+	return address, nil
+}
+
+// UpdateAddress changes one of the user's addresses
+// Orders that already ship to it keep the copy taken when it was chosen
+func (s *DefaultAddressService) UpdateAddress(user *models.User, addressID string, input AddressInput) (*models.Address, error) {
+	input, err := validateAddressInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	address, err := s.find(user, addressID)
+	if err != nil {
+		return nil, err
+	}
+
+	applyAddressInput(address, input)
+	if err := s.addresses.Save(address); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to update address").Wrap(err)
+	}
+	return address, nil
+}
+
+// DeleteAddress removes one of the user's addresses
+func (s *DefaultAddressService) DeleteAddress(user *models.User, addressID string) error {
+	address, err := s.find(user, addressID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.addresses.Delete(address); err != nil {
+		return apierror.Internal("DATABASE_ERROR", "Failed to delete address").Wrap(err)
+	}
+	return nil
+}
+
+// find loads one of the user's addresses by its path parameter
+// Other users' addresses are reported as not found, so their IDs are not revealed
+func (s *DefaultAddressService) find(user *models.User, addressID string) (*models.Address, error) {
+	id, err := strconv.ParseUint(addressID, 10, 64)
+	if err != nil || id == 0 {
+		return nil, apierror.NotFound("ADDRESS_NOT_FOUND", "Address not found")
+	}
+	address, err := s.addresses.FindForUser(user.ID, uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apierror.NotFound("ADDRESS_NOT_FOUND", "Address not found")
+		}
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load address").Wrap(err)
+	}
+	return address, nil
+}
+
+// applyAddressInput copies validated input onto an address
+func applyAddressInput(address *models.Address, input AddressInput) {
+	address.Label = input.Label
+	address.Recipient = input.Recipient
+	address.Line1 = input.Line1
+	address.Line2 = input.Line2
+	address.City = input.City
+	address.Region = input.Region
+	address.PostalCode = input.PostalCode
+	address.Country = input.Country
+}
+
+// validateAddressInput trims and checks the fields shared by create and update
+func validateAddressInput(input AddressInput) (AddressInput, error) {
+	fields := []struct {
+		name     string
+		value    *string
+		required bool
+	}{
+		{"label", &input.Label, false},
+		{"recipient", &input.Recipient, true},
+		{"line1", &input.Line1, true},
+		{"line2", &input.Line2, false},
+		{"city", &input.City, true},
+		{"region", &input.Region, false},
+		{"postal_code", &input.PostalCode, true},
+	}
+	for _, field := range fields {
+		*field.value = strings.TrimSpace(*field.value)
+		if field.required && *field.value == "" {
+			return input, apierror.Validation("Address is incomplete", map[string]interface{}{
+				"field": field.name,
+			})
+		}
+		if utf8.RuneCountInString(*field.value) > MaxAddressFieldLength {
+			return input, apierror.Validation("Address field is too long", map[string]interface{}{
+				"field":      field.name,
+				"max_length": MaxAddressFieldLength,
+			})
+		}
+	}
+
+	country, ok := utils.CanonicalCountry(input.Country)
+	if !ok {
+		return input, apierror.Validation("Country must be an ISO 3166 two-letter country code", map[string]interface{}{
+			"field": "country",
+		})
+	}
+	input.Country = country
+	return input, nil
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// fakeAddressRepository is an in-memory AddressRepository
+type fakeAddressRepository struct {
+	addresses map[uint]*models.Address
+	nextID    uint
+}
+
+func newFakeAddressRepository(addresses ...models.Address) *fakeAddressRepository {
+	repo := &fakeAddressRepository{addresses: make(map[uint]*models.Address), nextID: 1}
+	for i := range addresses {
+		address := addresses[i]
+		if address.ID >= repo.nextID {
+			repo.nextID = address.ID + 1
+		}
+		repo.addresses[address.ID] = &address
+	}
+	return repo
+}
+
+func (r *fakeAddressRepository) Create(address *models.Address) error {
+	address.ID = r.nextID
+	r.nextID++
+	stored := *address
+	r.addresses[address.ID] = &stored
+	return nil
+}
+
+func (r *fakeAddressRepository) Save(address *models.Address) error {
+	stored := *address
+	r.addresses[address.ID] = &stored
+	return nil
+}
+
+func (r *fakeAddressRepository) Delete(address *models.Address) error {
+	delete(r.addresses, address.ID)
+	return nil
+}
+
+func (r *fakeAddressRepository) FindForUser(userID, id uint) (*models.Address, error) {
+	address, ok := r.addresses[id]
+	if !ok || address.UserID != userID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	found := *address
+	return &found, nil
+}
+
+func (r *fakeAddressRepository) ListForUser(userID uint) ([]models.Address, error) {
+	var addresses []models.Address
+	for id := uint(1); id < r.nextID; id++ {
+		if address, ok := r.addresses[id]; ok && address.UserID == userID {
+			addresses = append(addresses, *address)
+		}
+	}
+	return addresses, nil
+}
+
+func (r *fakeAddressRepository) CountForUser(userID uint) (int64, error) {
+	addresses, _ := r.ListForUser(userID)
+	return int64(len(addresses)), nil
+}
+
+func validAddressInput() AddressInput {
+	return AddressInput{Label: " Home ", Recipient: "Casey Customer", Line1: "1 Main St", City: "Springfield", PostalCode: "12345", Country: "us"}
+}
+
+func TestAddressService_CRUD(t *testing.T) {
+	service := NewAddressService(newFakeAddressRepository())
+
+	address, err := service.CreateAddress(testCustomer, validAddressInput())
+	assert.NoError(t, err)
+	assert.Equal(t, "Home", address.Label)
+	assert.Equal(t, "US", address.Country)
+	assert.Equal(t, testCustomer.ID, address.UserID)
+
+	input := validAddressInput()
+	input.City = "Shelbyville"
+	updated, err := service.UpdateAddress(testCustomer, uintString(address.ID), input)
+	assert.NoError(t, err)
+	assert.Equal(t, "Shelbyville", updated.City)
+
+	// Other users' addresses do not exist for them
+	_, err = service.UpdateAddress(otherCustomer, uintString(address.ID), input)
+	assertAPIError(t, err, http.StatusNotFound, "ADDRESS_NOT_FOUND")
+	err = service.DeleteAddress(otherCustomer, uintString(address.ID))
+	assertAPIError(t, err, http.StatusNotFound, "ADDRESS_NOT_FOUND")
+	others, err := service.ListAddresses(otherCustomer)
+	assert.NoError(t, err)
+	assert.Empty(t, others)
+
+	assert.NoError(t, service.DeleteAddress(testCustomer, uintString(address.ID)))
+	addresses, err := service.ListAddresses(testCustomer)
+	assert.NoError(t, err)
+	assert.Empty(t, addresses)
+}
+
+func TestAddressService_Validation(t *testing.T) {
+	service := NewAddressService(newFakeAddressRepository())
+
+	input := validAddressInput()
+	input.Line1 = "  "
+	_, err := service.CreateAddress(testCustomer, input)
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+
+	input = validAddressInput()
+	input.Country = "USA"
+	_, err = service.CreateAddress(testCustomer, input)
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+
+	input = validAddressInput()
+	input.Line2 = strings.Repeat("x", MaxAddressFieldLength+1)
+	_, err = service.CreateAddress(testCustomer, input)
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+}
+
+func TestAddressService_AddressBookLimit(t *testing.T) {
+	service := NewAddressService(newFakeAddressRepository())
+	for i := 0; i < MaxAddresses; i++ {
+		_, err := service.CreateAddress(testCustomer, validAddressInput())
+		assert.NoError(t, err)
+	}
+
+	_, err := service.CreateAddress(testCustomer, validAddressInput())
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "TOO_MANY_ADDRESSES")
+}
+
+func TestOrderService_ShippingAddress(t *testing.T) {
+	service := newTestOrderService(newFakeOrderRepository())
+	service.addresses = newFakeAddressRepository(
+		models.Address{ID: 1, UserID: testCustomer.ID, Recipient: "Casey", Line1: "1 Main St", City: "Springfield", PostalCode: "12345", Country: "US"},
+		models.Address{ID: 2, UserID: otherCustomer.ID, Recipient: "Robin", Line1: "2 Oak Ave", City: "Shelbyville", PostalCode: "67890", Country: "US"},
+	)
+
+	// Another customer's address cannot be used
+	_, err := service.CreateOrder(testCustomer, CreateOrderInput{Description: "Chrome", Quantity: 1, ShippingAddressID: uintPtr(2)})
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "INVALID_SHIPPING_ADDRESS")
+
+	// Orders can be placed before choosing an address, but not accepted
+	order, err := service.CreateOrder(testCustomer, CreateOrderInput{Description: "Chrome", Quantity: 1})
+	assert.NoError(t, err)
+	assert.Nil(t, order.ShippingAddress)
+	_, err = service.ReviewOrder(testTechnician, uintString(order.ID), ReviewOrderInput{Action: "accept", Price: float64Ptr(30)})
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "SHIPPING_ADDRESS_REQUIRED")
+
+	_, err = service.SetShippingAddress(otherCustomer, uintString(order.ID), 2)
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+
+	order, err = service.SetShippingAddress(testCustomer, uintString(order.ID), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), *order.ShippingAddressID)
+	assert.Equal(t, "1 Main St", order.ShippingAddress.Line1)
+
+	// Later edits to the address book leave the order's copy alone
+	_, err = NewAddressService(service.addresses).UpdateAddress(testCustomer, "1", validAddressInput())
+	assert.NoError(t, err)
+	accepted, err := service.ReviewOrder(testTechnician, uintString(order.ID), ReviewOrderInput{Action: "accept", Price: float64Ptr(30)})
+	assert.NoError(t, err)
+	assert.Equal(t, "Casey", accepted.ShippingAddress.Recipient)
+
+	_, err = service.SetShippingAddress(testCustomer, uintString(order.ID), 1)
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "INVALID_STATE")
+}
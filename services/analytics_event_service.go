@@ -0,0 +1,236 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+)
+
+// Limits on client analytics batches
+const (
+	MaxEventBatchSize      = 100
+	MaxEventProperties     = 20
+	MaxEventPropertyLength = 200 // characters in a string property value
+	MaxEventNameLength     = 100
+	MaxClientEventIDLength = 64
+	MaxEventAge            = 7 * 24 * time.Hour // clients buffer events while offline
+	MaxEventClockSkew      = 5 * time.Minute
+)
+
+// EventPlatforms are the client platforms that report analytics events
+var EventPlatforms = []string{"ios", "android", "web"}
+
+// eventNamePattern restricts screen, step and funnel names to identifier-like values such as "orders/detail"
+var eventNamePattern = regexp.MustCompile(`^[A-Za-z0-9_./:-]+$`)
+
+// AnalyticsEventInput is one client-side event in a batch
+type AnalyticsEventInput struct {
+	ID         string                 `json:"id"`
+	Type       string                 `json:"type"`
+	Name       string                 `json:"name"`
+	Funnel     string                 `json:"funnel"`
+	SessionID  string                 `json:"session_id"`
+	Platform   string                 `json:"platform"`
+	Properties map[string]interface{} `json:"properties"`
+	OccurredAt time.Time              `json:"occurred_at"`
+}
+
+// EventBatchResult reports what happened to a batch of events
+type EventBatchResult struct {
+	Received   int   `json:"received"`
+	Recorded   int64 `json:"recorded"`
+	Duplicates int64 `json:"duplicates"` // events already recorded from an earlier attempt
+}
+
+// AnalyticsEventService accepts product analytics events from client apps
+type AnalyticsEventService interface {
+	// Ingest validates a batch and queues it for the warehouse export
+	// The batch is rejected as a whole when any event is invalid; user is nil for callers without a profile
+	Ingest(user *models.User, events []AnalyticsEventInput) (*EventBatchResult, error)
+}
+
+// DefaultAnalyticsEventService implements AnalyticsEventService on top of an AnalyticsEventRepository
+type DefaultAnalyticsEventService struct {
+	events repositories.AnalyticsEventRepository
+	now    func() time.Time
+}
+
+var analyticsEventServiceInstance AnalyticsEventService
+
+// NewAnalyticsEventService creates an analytics event service using the given repository
+func NewAnalyticsEventService(events repositories.AnalyticsEventRepository) *DefaultAnalyticsEventService {
+	return &DefaultAnalyticsEventService{events: events, now: time.Now}
+}
+
+// GetAnalyticsEventService returns the configured analytics event service
+// When none has been set, a service over the current database connection is returned
+func GetAnalyticsEventService() AnalyticsEventService {
+	if analyticsEventServiceInstance != nil {
+		return analyticsEventServiceInstance
+	}
+	return NewAnalyticsEventService(repositories.NewAnalyticsEventRepository(config.GetDB()))
+}
+
+// SetAnalyticsEventService sets the analytics event service instance (primarily for testing)
+func SetAnalyticsEventService(service AnalyticsEventService) {
+	analyticsEventServiceInstance = service
+}
+
+// Ingest validates a batch and queues it for the warehouse export
+func (s *DefaultAnalyticsEventService) Ingest(user *models.User, inputs []AnalyticsEventInput) (*EventBatchResult, error) {
+	if len(inputs) == 0 {
+		return nil, apierror.Validation("Invalid request data", map[string]string{"events": "must contain at least one event"})
+	}
+	if len(inputs) > MaxEventBatchSize {
+		return nil, apierror.Validation("Invalid request data", map[string]string{
+			"events": fmt.Sprintf("must contain at most %d events", MaxEventBatchSize),
+		})
+	}
+
+	var userID *uint
+	if user != nil {
+		userID = &user.ID
+	}
+
+	now := s.now()
+	details := make(map[string]string)
+	seen := make(map[string]bool, len(inputs))
+	events := make([]models.AnalyticsEvent, 0, len(inputs))
+	for i, input := range inputs {
+		field := func(name string) string { return fmt.Sprintf("events[%d].%s", i, name) }
+
+		event := models.AnalyticsEvent{
+			ClientEventID: input.ID,
+			UserID:        userID,
+			Type:          input.Type,
+			Name:          input.Name,
+			SessionID:     input.SessionID,
+			Platform:      input.Platform,
+			OccurredAt:    input.OccurredAt.UTC(),
+		}
+
+		switch {
+		case input.ID == "":
+			details[field("id")] = "is required"
+		case len(input.ID) > MaxClientEventIDLength:
+			details[field("id")] = fmt.Sprintf("must be at most %d characters", MaxClientEventIDLength)
+		case seen[input.ID]:
+			details[field("id")] = "is repeated in this batch"
+		}
+		seen[input.ID] = true
+
+		if input.Type != models.EventTypeScreenView && input.Type != models.EventTypeFunnelStep {
+			details[field("type")] = "must be one of: " + models.EventTypeScreenView + ", " + models.EventTypeFunnelStep
+		}
+		if msg := validateEventName(input.Name); msg != "" {
+			details[field("name")] = msg
+		}
+		if input.Type == models.EventTypeFunnelStep {
+			if msg := validateEventName(input.Funnel); msg != "" {
+				details[field("funnel")] = msg
+			}
+			funnel := input.Funnel
+			event.Funnel = &funnel
+		} else if input.Funnel != "" {
+			details[field("funnel")] = "is only allowed on funnel_step events"
+		}
+
+		if len(input.SessionID) > MaxClientEventIDLength {
+			details[field("session_id")] = fmt.Sprintf("must be at most %d characters", MaxClientEventIDLength)
+		}
+		if input.Platform != "" && !isEventPlatform(input.Platform) {
+			details[field("platform")] = "must be one of: " + strings.Join(EventPlatforms, ", ")
+		}
+
+		switch {
+		case input.OccurredAt.IsZero():
+			details[field("occurred_at")] = "is required"
+		case input.OccurredAt.After(now.Add(MaxEventClockSkew)):
+			details[field("occurred_at")] = "must not be in the future"
+		case input.OccurredAt.Before(now.Add(-MaxEventAge)):
+			details[field("occurred_at")] = "must be within the last 7 days"
+		}
+
+		properties, msg := encodeEventProperties(input.Properties)
+		if msg != "" {
+			details[field("properties")] = msg
+		}
+		event.Properties = properties
+
+		events = append(events, event)
+	}
+	if len(details) > 0 {
+		return nil, apierror.Validation("Invalid request data", details)
+	}
+
+	recorded, err := s.events.CreateBatch(events)
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to record analytics events").Wrap(err)
+	}
+	return &EventBatchResult{
+		Received:   len(events),
+		Recorded:   recorded,
+		Duplicates: int64(len(events)) - recorded,
+	}, nil
+}
+
+// isEventPlatform reports whether platform is one of the known client platforms
+func isEventPlatform(platform string) bool {
+	for _, known := range EventPlatforms {
+		if platform == known {
+			return true
+		}
+	}
+	return false
+}
+
+// validateEventName returns why a screen, step or funnel name is invalid, or "" when it is valid
+func validateEventName(name string) string {
+	switch {
+	case name == "":
+		return "is required"
+	case len(name) > MaxEventNameLength:
+		return fmt.Sprintf("must be at most %d characters", MaxEventNameLength)
+	case !eventNamePattern.MatchString(name):
+		return "may only contain letters, digits and _ . / : -"
+	}
+	return ""
+}
+
+// encodeEventProperties returns the properties as a JSON object, or why they are invalid
+// Only scalar values are accepted so that the warehouse can load them without a nested schema
+func encodeEventProperties(properties map[string]interface{}) (string, string) {
+	if len(properties) == 0 {
+		return "{}", ""
+	}
+	if len(properties) > MaxEventProperties {
+		return "", fmt.Sprintf("must have at most %d keys", MaxEventProperties)
+	}
+	for key, value := range properties {
+		if key == "" || len(key) > MaxEventNameLength {
+			return "", "keys must be between 1 and 100 characters"
+		}
+		switch v := value.(type) {
+		case nil, bool, float64:
+		case string:
+			if utf8.RuneCountInString(v) > MaxEventPropertyLength {
+				return "", fmt.Sprintf("%q must be at most %d characters", key, MaxEventPropertyLength)
+			}
+		default:
+			return "", fmt.Sprintf("%q must be a string, number, boolean or null", key)
+		}
+	}
+	encoded, err := json.Marshal(properties)
+	if err != nil {
+		return "", "must be a JSON object"
+	}
+	return string(encoded), ""
+}
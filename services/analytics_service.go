@@ -0,0 +1,216 @@
+package services
+
+import (
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+)
+
+// Orders-per-week window bounds for the analytics summary
+const (
+	DefaultAnalyticsWeeks = 12
+	MaxAnalyticsWeeks     = 52
+)
+
+// AnalyticsSummary is the shop-wide overview returned by GET /analytics/summary
+type AnalyticsSummary struct {
+	OrdersByStatus         []repositories.StatusCount `json:"orders_by_status"`
+	TotalOrders            int64                      `json:"total_orders"`
+	RushOrders             int64                      `json:"rush_orders"`
+	Revenue                repositories.RevenueTotals `json:"revenue"`
+	AverageAcceptanceHours *float64                   `json:"average_acceptance_hours"` // nil until an order has been accepted
+	OrdersPerWeek          []repositories.WeeklyCount `json:"orders_per_week"`
+}
+
+// Fulfillment SLA report period bounds and shipping targets
+// An order ships on time when it ships within its target after the technician accepted it
+const (
+	DefaultSLAReportPeriod = 30 * 24 * time.Hour
+	MaxSLAReportPeriod     = 366 * 24 * time.Hour
+	StandardShippingTarget = 7 * 24 * time.Hour
+	RushShippingTarget     = 3 * 24 * time.Hour
+)
+
+// SLAReport is the per-technician fulfillment report returned by GET /admin/reports/sla
+type SLAReport struct {
+	From                        time.Time             `json:"from"`
+	To                          time.Time             `json:"to"` // exclusive
+	StandardShippingTargetHours float64               `json:"standard_shipping_target_hours"`
+	RushShippingTargetHours     float64               `json:"rush_shipping_target_hours"`
+	Technicians                 []TechnicianSLAReport `json:"technicians"`
+}
+
+// TechnicianSLAReport is one technician's row in the SLA report
+// Averages and the on-time rate are nil when no order in the period contributes to them
+type TechnicianSLAReport struct {
+	TechnicianID           uint     `json:"technician_id"`
+	TechnicianName         string   `json:"technician_name"`
+	OrdersReviewed         int64    `json:"orders_reviewed"`
+	OrdersRejected         int64    `json:"orders_rejected"`
+	AverageAcceptanceHours *float64 `json:"average_acceptance_hours"`
+	OrdersShipped          int64    `json:"orders_shipped"`
+	AverageProductionHours *float64 `json:"average_production_hours"`
+	OrdersShippedOnTime    int64    `json:"orders_shipped_on_time"`
+	OnTimeShippingPercent  *float64 `json:"on_time_shipping_percent"`
+}
+
+// AnalyticsService computes order and revenue analytics for staff
+type AnalyticsService interface {
+	// Summary returns the analytics summary with weekly counts for the last weeks weeks
+	Summary(user *models.User, weeks int) (*AnalyticsSummary, error)
+
+	// SLAReport returns per-technician fulfillment metrics for orders reviewed or shipped in [from, to) (admins only)
+	// A zero from or to selects the period of DefaultSLAReportPeriod ending now
+	SLAReport(user *models.User, from, to time.Time) (*SLAReport, error)
+}
+
+// DefaultAnalyticsService implements AnalyticsService on top of an AnalyticsRepository
+type DefaultAnalyticsService struct {
+	analytics repositories.AnalyticsRepository
+	now       func() time.Time
+}
+
+var analyticsServiceInstance AnalyticsService
+
+// NewAnalyticsService creates an analytics service using the given repository
+func NewAnalyticsService(analytics repositories.AnalyticsRepository) *DefaultAnalyticsService {
+	return &DefaultAnalyticsService{analytics: analytics, now: time.Now}
+}
+
+// GetAnalyticsService returns the configured analytics service
+// When none has been set, a service over the read replica (or the database when there is none) is returned
+func GetAnalyticsService() AnalyticsService {
+	if analyticsServiceInstance != nil {
+		return analyticsServiceInstance
+	}
+	return NewAnalyticsService(repositories.NewAnalyticsRepository(config.GetReplicaDB()))
+}
+
+// SetAnalyticsService sets the analytics service instance (primarily for testing)
+func SetAnalyticsService(service AnalyticsService) {
+	analyticsServiceInstance = service
+}
+
+// Summary returns the analytics summary with weekly counts for the last weeks weeks
+func (s *DefaultAnalyticsService) Summary(user *models.User, weeks int) (*AnalyticsSummary, error) {
+	if user.Role != RoleTechnician && user.Role != RoleAdmin {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only staff can view analytics")
+	}
+	if weeks <= 0 {
+		weeks = DefaultAnalyticsWeeks
+	}
+	if weeks > MaxAnalyticsWeeks {
+		weeks = MaxAnalyticsWeeks
+	}
+
+	summary := &AnalyticsSummary{}
+	var err error
+
+	if summary.OrdersByStatus, err = s.analytics.CountByStatus(); err != nil {
+		return nil, analyticsError(err)
+	}
+	for _, count := range summary.OrdersByStatus {
+		summary.TotalOrders += count.Count
+	}
+
+	if summary.RushOrders, err = s.analytics.CountRush(); err != nil {
+		return nil, analyticsError(err)
+	}
+
+	if summary.Revenue, err = s.analytics.Revenue(studioCurrency(), BookedStatuses, StatusDelivered); err != nil {
+		return nil, analyticsError(err)
+	}
+
+	seconds, ok, err := s.analytics.AverageReviewSeconds(BookedStatuses)
+	if err != nil {
+		return nil, analyticsError(err)
+	}
+	if ok {
+		hours := roundCents(seconds / 3600)
+		summary.AverageAcceptanceHours = &hours
+	}
+
+	// Start at the Monday of the earliest week so the oldest bucket is complete
+	since := startOfWeek(s.now()).AddDate(0, 0, -7*(weeks-1))
+	if summary.OrdersPerWeek, err = s.analytics.OrdersPerWeek(since); err != nil {
+		return nil, analyticsError(err)
+	}
+
+	return summary, nil
+}
+
+// SLAReport returns per-technician fulfillment metrics for orders reviewed or shipped in [from, to) (admins only)
+func (s *DefaultAnalyticsService) SLAReport(user *models.User, from, to time.Time) (*SLAReport, error) {
+	if user.Role != RoleAdmin {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only admins can view the SLA report")
+	}
+
+	if to.IsZero() {
+		to = s.now()
+	}
+	if from.IsZero() {
+		from = to.Add(-DefaultSLAReportPeriod)
+	}
+	from, to = from.UTC(), to.UTC()
+	if !to.After(from) {
+		return nil, apierror.Validation("Invalid report period", map[string]string{"to": "must be after from"})
+	}
+	if to.Sub(from) > MaxSLAReportPeriod {
+		return nil, apierror.Validation("Invalid report period", map[string]string{"from": "period must be at most 366 days"})
+	}
+
+	slas, err := s.analytics.TechnicianSLA(from, to, StatusRejected, StandardShippingTarget, RushShippingTarget)
+	if err != nil {
+		return nil, analyticsError(err)
+	}
+
+	report := &SLAReport{
+		From:                        from,
+		To:                          to,
+		StandardShippingTargetHours: StandardShippingTarget.Hours(),
+		RushShippingTargetHours:     RushShippingTarget.Hours(),
+		Technicians:                 make([]TechnicianSLAReport, 0, len(slas)),
+	}
+	for _, sla := range slas {
+		row := TechnicianSLAReport{
+			TechnicianID:           sla.TechnicianID,
+			TechnicianName:         sla.TechnicianName,
+			OrdersReviewed:         sla.Reviewed,
+			OrdersRejected:         sla.Rejected,
+			AverageAcceptanceHours: secondsToHours(sla.AcceptanceSeconds),
+			OrdersShipped:          sla.Shipped,
+			AverageProductionHours: secondsToHours(sla.ProductionSeconds),
+			OrdersShippedOnTime:    sla.ShippedOnTime,
+		}
+		if sla.Shipped > 0 {
+			percent := roundCents(float64(sla.ShippedOnTime) * 100 / float64(sla.Shipped))
+			row.OnTimeShippingPercent = &percent
+		}
+		report.Technicians = append(report.Technicians, row)
+	}
+	return report, nil
+}
+
+// secondsToHours converts an optional duration in seconds to hours rounded to two decimals
+func secondsToHours(seconds *float64) *float64 {
+	if seconds == nil {
+		return nil
+	}
+	hours := roundCents(*seconds / 3600)
+	return &hours
+}
+
+// startOfWeek returns midnight UTC on the Monday of t's week
+func startOfWeek(t time.Time) time.Time {
+	t = t.UTC()
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-daysSinceMonday, 0, 0, 0, 0, time.UTC)
+}
+
+// analyticsError wraps a failed aggregate query
+func analyticsError(err error) error {
+	return apierror.Internal("DATABASE_ERROR", "Failed to compute analytics").Wrap(err)
+}
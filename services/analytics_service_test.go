@@ -0,0 +1,106 @@
+package services
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeAnalyticsRepository returns canned aggregates and records the weekly window it was asked for
+type fakeAnalyticsRepository struct {
+	since    time.Time
+	from, to time.Time
+}
+
+func (r *fakeAnalyticsRepository) CountByStatus() ([]repositories.StatusCount, error) {
+	return []repositories.StatusCount{{Status: StatusSubmitted, Count: 3}, {Status: StatusDelivered, Count: 2}}, nil
+}
+
+func (r *fakeAnalyticsRepository) CountRush() (int64, error) {
+	return 1, nil
+}
+
+func (r *fakeAnalyticsRepository) Revenue(currency string, bookedStatuses []string, deliveredStatus string) (repositories.RevenueTotals, error) {
+	return repositories.RevenueTotals{Currency: currency, Booked: 100.46, Delivered: 80}, nil
+}
+
+func (r *fakeAnalyticsRepository) AverageReviewSeconds(statuses []string) (float64, bool, error) {
+	return 5400, true, nil
+}
+
+func (r *fakeAnalyticsRepository) OrdersPerWeek(since time.Time) ([]repositories.WeeklyCount, error) {
+	r.since = since
+	return nil, nil
+}
+
+func (r *fakeAnalyticsRepository) TechnicianSLA(from, to time.Time, rejectedStatus string, standardTarget, rushTarget time.Duration) ([]repositories.TechnicianSLA, error) {
+	r.from, r.to = from, to
+	acceptance := 9000.0
+	return []repositories.TechnicianSLA{
+		{TechnicianID: 3, TechnicianName: "Alex", Reviewed: 4, Rejected: 1, AcceptanceSeconds: &acceptance, Shipped: 3, ShippedOnTime: 2},
+		{TechnicianID: 4, TechnicianName: "Sam", Reviewed: 1},
+	}, nil
+}
+
+func TestAnalyticsService_Summary(t *testing.T) {
+	repo := &fakeAnalyticsRepository{}
+	service := NewAnalyticsService(repo)
+	service.now = func() time.Time { return time.Date(2026, 10, 15, 18, 0, 0, 0, time.UTC) } // a Thursday
+
+	_, err := service.Summary(testCustomer, 0)
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+
+	summary, err := service.Summary(testTechnician, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), summary.TotalOrders)
+	assert.Equal(t, int64(1), summary.RushOrders)
+	assert.Equal(t, 100.46, summary.Revenue.Booked)
+	assert.Equal(t, 1.5, *summary.AverageAcceptanceHours)
+	assert.Equal(t, time.Date(2026, 9, 21, 0, 0, 0, 0, time.UTC), repo.since)
+
+	_, err = service.Summary(testTechnician, 500)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC).AddDate(0, 0, -7*(MaxAnalyticsWeeks-1)), repo.since)
+}
+
+func TestStartOfWeek(t *testing.T) {
+	sunday := time.Date(2026, 10, 18, 23, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC), startOfWeek(sunday))
+
+	monday := time.Date(2026, 10, 12, 0, 30, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC), startOfWeek(monday))
+}
+
+func TestAnalyticsService_SLAReport(t *testing.T) {
+	repo := &fakeAnalyticsRepository{}
+	service := NewAnalyticsService(repo)
+	now := time.Date(2026, 10, 15, 18, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	admin := &models.User{ID: 9, Role: RoleAdmin}
+
+	_, err := service.SLAReport(testTechnician, time.Time{}, time.Time{})
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+
+	report, err := service.SLAReport(admin, time.Time{}, time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(-DefaultSLAReportPeriod), repo.from)
+	assert.Equal(t, now, repo.to)
+	assert.Equal(t, 168.0, report.StandardShippingTargetHours)
+	assert.Len(t, report.Technicians, 2)
+
+	alex := report.Technicians[0]
+	assert.Equal(t, 2.5, *alex.AverageAcceptanceHours)
+	assert.Nil(t, alex.AverageProductionHours)
+	assert.Equal(t, 66.67, *alex.OnTimeShippingPercent)
+	assert.Nil(t, report.Technicians[1].OnTimeShippingPercent)
+
+	_, err = service.SLAReport(admin, now, now)
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+
+	_, err = service.SLAReport(admin, now.AddDate(-2, 0, 0), now)
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+}
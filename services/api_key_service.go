@@ -0,0 +1,178 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"github.com/kendall-kelly/kendalls-nails-api/utils"
+	"gorm.io/gorm"
+)
+
+// apiKeyPrefix marks integration API keys so that leaked keys are recognizable
+const apiKeyPrefix = "nak_"
+
+// apiKeyDisplayLength is how much of a key is kept in Prefix to tell keys apart
+const apiKeyDisplayLength = 12
+
+// apiKeyTouchInterval limits how often a key's last use is written back
+const apiKeyTouchInterval = time.Minute
+
+// APIKeyService issues and checks API keys for server-to-server integrations
+type APIKeyService interface {
+	// CreateKey issues an API key (admins only)
+	// The returned key carries its secret in Key; only a hash is stored
+	CreateKey(admin *models.User, name string) (*models.APIKey, error)
+
+	// ListKeys returns every API key (admins only)
+	ListKeys(admin *models.User) ([]models.APIKey, error)
+
+	// RevokeKey stops an API key from being used (admins only)
+	RevokeKey(admin *models.User, keyID string) (*models.APIKey, error)
+
+	// Authenticate resolves the API key of an integration request
+	Authenticate(secret string) (*models.APIKey, error)
+}
+
+// DefaultAPIKeyService implements APIKeyService on top of an APIKeyRepository
+type DefaultAPIKeyService struct {
+	keys repositories.APIKeyRepository
+}
+
+var apiKeyServiceInstance APIKeyService
+
+// NewAPIKeyService creates an API key service using the given repository
+func NewAPIKeyService(keys repositories.APIKeyRepository) *DefaultAPIKeyService {
+	return &DefaultAPIKeyService{keys: keys}
+}
+
+// GetAPIKeyService returns the configured API key service
+// When none has been set, a service over the current database connection is returned
+func GetAPIKeyService() APIKeyService {
+	if apiKeyServiceInstance != nil {
+		return apiKeyServiceInstance
+	}
+	return NewAPIKeyService(repositories.NewAPIKeyRepository(config.GetDB()))
+}
+
+// SetAPIKeyService sets the API key service instance (primarily for testing)
+func SetAPIKeyService(service APIKeyService) {
+	apiKeyServiceInstance = service
+}
+
+// IntegrationPrincipal returns the caller an API key acts as in the order services
+// The principal has no ID and the RoleIntegration role, which only grants read access
+func IntegrationPrincipal(key *models.APIKey) *models.User {
+	return &models.User{Name: key.Name, Role: RoleIntegration}
+}
+
+// MachinePrincipal returns the caller a machine client without a user profile acts as
+// Like an API key it may only read, and only the orders its token's scopes allow
+func MachinePrincipal(clientID string) *models.User {
+	return &models.User{Name: clientID, Role: RoleIntegration}
+}
+
+// CreateKey issues an API key (admins only)
+func (s *DefaultAPIKeyService) CreateKey(admin *models.User, name string) (*models.APIKey, error) {
+	if admin.Role != RoleAdmin {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only admins can create API keys")
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, apierror.Validation("Invalid request data", map[string]string{"name": "is required"})
+	}
+
+	secret, err := utils.NewSecret(apiKeyPrefix)
+	if err != nil {
+		return nil, apierror.Internal("TOKEN_ERROR", "Failed to generate API key").Wrap(err)
+	}
+
+	key := &models.APIKey{
+		Name:        name,
+		Prefix:      secret[:apiKeyDisplayLength],
+		KeyHash:     utils.HashSecret(secret),
+		CreatedByID: admin.ID,
+	}
+	if err := s.keys.Create(key); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to create API key").Wrap(err)
+	}
+	key.Key = secret
+	return key, nil
+}
+
+// ListKeys returns every API key (admins only)
+func (s *DefaultAPIKeyService) ListKeys(admin *models.User) ([]models.APIKey, error) {
+	if admin.Role != RoleAdmin {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only admins can view API keys")
+	}
+
+	keys, err := s.keys.List()
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load API keys").Wrap(err)
+	}
+	return keys, nil
+}
+
+// RevokeKey stops an API key from being used (admins only)
+func (s *DefaultAPIKeyService) RevokeKey(admin *models.User, keyID string) (*models.APIKey, error) {
+	if admin.Role != RoleAdmin {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only admins can revoke API keys")
+	}
+
+	id, err := strconv.ParseUint(keyID, 10, 64)
+	if err != nil || id == 0 {
+		return nil, apierror.NotFound("API_KEY_NOT_FOUND", "API key not found")
+	}
+	key, err := s.keys.FindByID(uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apierror.NotFound("API_KEY_NOT_FOUND", "API key not found")
+		}
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load API key").Wrap(err)
+	}
+
+	if key.RevokedAt == nil {
+		now := time.Now()
+		key.RevokedAt = &now
+		if err := s.keys.Save(key); err != nil {
+			return nil, apierror.Internal("DATABASE_ERROR", "Failed to revoke API key").Wrap(err)
+		}
+	}
+	return key, nil
+}
+
+// Authenticate resolves the API key of an integration request
+// Recording the last use is best effort and never fails the request
+func (s *DefaultAPIKeyService) Authenticate(secret string) (*models.APIKey, error) {
+	invalid := apierror.Unauthorized("INVALID_API_KEY", "API key is missing or revoked")
+	if secret == "" {
+		return nil, invalid
+	}
+
+	key, err := s.keys.FindByHash(utils.HashSecret(secret))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, invalid
+		}
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load API key").Wrap(err)
+	}
+	if key.RevokedAt != nil {
+		return nil, invalid
+	}
+
+	now := time.Now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		if err := s.keys.TouchLastUsed(key.ID, now); err != nil {
+			log.Printf("Failed to record use of API key %d: %v", key.ID, err)
+		} else {
+			key.LastUsedAt = &now
+		}
+	}
+	return key, nil
+}
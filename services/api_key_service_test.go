@@ -0,0 +1,106 @@
+package services
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/utils"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// fakeAPIKeyRepository keeps API keys in memory and counts last-use writes
+type fakeAPIKeyRepository struct {
+	keys    []*models.APIKey
+	touches int
+}
+
+func (r *fakeAPIKeyRepository) Create(key *models.APIKey) error {
+	key.ID = uint(len(r.keys) + 1)
+	r.keys = append(r.keys, key)
+	return nil
+}
+
+func (r *fakeAPIKeyRepository) Save(key *models.APIKey) error {
+	return nil
+}
+
+func (r *fakeAPIKeyRepository) FindByHash(hash string) (*models.APIKey, error) {
+	for _, key := range r.keys {
+		if key.KeyHash == hash {
+			copied := *key
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeAPIKeyRepository) FindByID(id uint) (*models.APIKey, error) {
+	for _, key := range r.keys {
+		if key.ID == id {
+			return key, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeAPIKeyRepository) List() ([]models.APIKey, error) {
+	keys := make([]models.APIKey, 0, len(r.keys))
+	for _, key := range r.keys {
+		keys = append(keys, *key)
+	}
+	return keys, nil
+}
+
+func (r *fakeAPIKeyRepository) TouchLastUsed(id uint, usedAt time.Time) error {
+	r.touches++
+	for _, key := range r.keys {
+		if key.ID == id {
+			key.LastUsedAt = &usedAt
+		}
+	}
+	return nil
+}
+
+func TestAPIKeyService(t *testing.T) {
+	repo := &fakeAPIKeyRepository{}
+	service := NewAPIKeyService(repo)
+	admin := &models.User{ID: 9, Role: RoleAdmin}
+
+	_, err := service.CreateKey(testTechnician, "Zapier")
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+	_, err = service.CreateKey(admin, "  ")
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+
+	key, err := service.CreateKey(admin, "Zapier")
+	assert.NoError(t, err)
+	assert.Equal(t, utils.HashSecret(key.Key), repo.keys[0].KeyHash)
+	assert.Equal(t, admin.ID, key.CreatedByID)
+
+	// The last use is written back at most once a minute
+	authenticated, err := service.Authenticate(key.Key)
+	assert.NoError(t, err)
+	assert.Equal(t, key.ID, authenticated.ID)
+	_, err = service.Authenticate(key.Key)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, repo.touches)
+
+	principal := IntegrationPrincipal(authenticated)
+	assert.Equal(t, RoleIntegration, principal.Role)
+	assert.False(t, IsRole(principal.Role))
+	assert.True(t, CanViewOrder(principal, &models.Order{CustomerID: 1}))
+
+	_, err = service.Authenticate("nak_wrong")
+	assertAPIError(t, err, http.StatusUnauthorized, "INVALID_API_KEY")
+
+	_, err = service.RevokeKey(admin, "42")
+	assertAPIError(t, err, http.StatusNotFound, "API_KEY_NOT_FOUND")
+	revoked, err := service.RevokeKey(admin, uintString(key.ID))
+	assert.NoError(t, err)
+	assert.NotNil(t, revoked.RevokedAt)
+
+	_, err = service.Authenticate(key.Key)
+	assertAPIError(t, err, http.StatusUnauthorized, "INVALID_API_KEY")
+}
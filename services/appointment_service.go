@@ -0,0 +1,367 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"gorm.io/gorm"
+)
+
+// Bounds on fitting slots and calendar listings
+const (
+	MinSlotDuration       = 15 * time.Minute
+	MaxSlotDuration       = 4 * time.Hour
+	DefaultCalendarWindow = 30 * 24 * time.Hour
+	MaxCalendarWindow     = 92 * 24 * time.Hour
+)
+
+// SlotInput holds the time range of a new slot
+type SlotInput struct {
+	StartsAt time.Time
+	EndsAt   time.Time
+}
+
+// CalendarRange limits a listing to entries starting in [From, To)
+// A zero From means now, and a zero To means DefaultCalendarWindow after From
+type CalendarRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// AppointmentService manages technicians' fitting slots and customers' bookings
+type AppointmentService interface {
+	// CreateSlot publishes a time the technician is available for a fitting
+	CreateSlot(technician *models.User, input SlotInput) (*models.AppointmentSlot, error)
+
+	// ListSlots returns a technician's slots: all of their own, or the open ones for anyone else
+	ListSlots(user *models.User, technicianID uint, window CalendarRange) ([]models.AppointmentSlot, error)
+
+	// DeleteSlot removes one of the technician's unbooked slots
+	DeleteSlot(technician *models.User, slotID string) error
+
+	// BookAppointment books a slot for a fitting on one of the customer's orders
+	BookAppointment(customer *models.User, orderID string, slotID uint) (*models.Appointment, error)
+
+	// ListOrderAppointments returns every appointment booked for an order, cancelled ones included
+	ListOrderAppointments(user *models.User, orderID string) ([]models.Appointment, error)
+
+	// ListAppointments returns the user's booked appointments
+	ListAppointments(user *models.User, window CalendarRange) ([]models.Appointment, error)
+
+	// CancelAppointment cancels a booked appointment, freeing its slot
+	CancelAppointment(user *models.User, appointmentID string) (*models.Appointment, error)
+}
+
+// DefaultAppointmentService implements AppointmentService on top of the appointment and order repositories
+type DefaultAppointmentService struct {
+	appointments repositories.AppointmentRepository
+	orders       repositories.OrderRepository
+	now          func() time.Time
+}
+
+var appointmentServiceInstance AppointmentService
+
+// NewAppointmentService creates an appointment service using the given repositories
+func NewAppointmentService(appointments repositories.AppointmentRepository, orders repositories.OrderRepository) *DefaultAppointmentService {
+	return &DefaultAppointmentService{appointments: appointments, orders: orders, now: time.Now}
+}
+
+// GetAppointmentService returns the configured appointment service
+// When none has been set, a service over the current database connection is returned
+func GetAppointmentService() AppointmentService {
+	if appointmentServiceInstance != nil {
+		return appointmentServiceInstance
+	}
+	db := config.GetDB()
+	return NewAppointmentService(repositories.NewAppointmentRepository(db), repositories.NewOrderRepository(db))
+}
+
+// SetAppointmentService sets the appointment service instance (primarily for testing)
+func SetAppointmentService(service AppointmentService) {
+	appointmentServiceInstance = service
+}
+
+// CreateSlot publishes a time the technician is available for a fitting
+// Slots must start in the future, last between MinSlotDuration and MaxSlotDuration, and not overlap the technician's other slots
+func (s *DefaultAppointmentService) CreateSlot(technician *models.User, input SlotInput) (*models.AppointmentSlot, error) {
+	if technician.Role != RoleTechnician {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only technicians can publish appointment slots")
+	}
+
+	start, end := input.StartsAt.UTC(), input.EndsAt.UTC()
+	if !start.After(s.now()) {
+		return nil, apierror.Validation("Slots must start in the future", map[string]interface{}{
+			"field": "starts_at",
+		})
+	}
+	if duration := end.Sub(start); duration < MinSlotDuration || duration > MaxSlotDuration {
+		return nil, apierror.Validation("Slots must last between 15 minutes and 4 hours", map[string]interface{}{
+			"field":                "ends_at",
+			"min_duration_minutes": int(MinSlotDuration.Minutes()),
+			"max_duration_minutes": int(MaxSlotDuration.Minutes()),
+		})
+	}
+
+	overlapping, err := s.appointments.CountOverlappingSlots(technician.ID, start, end)
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to check for overlapping slots").Wrap(err)
+	}
+	if overlapping > 0 {
+		return nil, apierror.Conflict("SLOT_CONFLICT", "This slot overlaps another of your slots")
+	}
+
+	slot := &models.AppointmentSlot{TechnicianID: technician.ID, StartsAt: start, EndsAt: end}
+	if err := s.appointments.CreateSlot(slot); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to create slot").Wrap(err)
+	}
+	return slot, nil
+}
+
+// ListSlots returns a technician's slots in the window, earliest first
+// Technicians see all of their own slots with their bookings; everyone else sees the open slots that have not started
+func (s *DefaultAppointmentService) ListSlots(user *models.User, technicianID uint, window CalendarRange) ([]models.AppointmentSlot, error) {
+	own := user.Role == RoleTechnician && (technicianID == 0 || technicianID == user.ID)
+	if own {
+		technicianID = user.ID
+	}
+	if technicianID == 0 {
+		return nil, apierror.Validation("Technician ID is required", map[string]interface{}{
+			"field": "technician_id",
+		})
+	}
+	from, to, err := s.calendarRange(window)
+	if err != nil {
+		return nil, err
+	}
+
+	slots, err := s.appointments.ListSlots(technicianID, from, to)
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to fetch slots").Wrap(err)
+	}
+	if own {
+		return slots, nil
+	}
+
+	now := s.now()
+	open := []models.AppointmentSlot{}
+	for _, slot := range slots {
+		if slot.Appointment == nil && slot.StartsAt.After(now) {
+			open = append(open, slot)
+		}
+	}
+	return open, nil
+}
+
+// DeleteSlot removes one of the technician's slots; booked slots must be cancelled first
+func (s *DefaultAppointmentService) DeleteSlot(technician *models.User, slotID string) error {
+	if technician.Role != RoleTechnician {
+		return apierror.Forbidden("FORBIDDEN", "Only technicians can remove appointment slots")
+	}
+	id, err := strconv.ParseUint(slotID, 10, 64)
+	if err != nil || id == 0 {
+		return apierror.NotFound("SLOT_NOT_FOUND", "Appointment slot not found")
+	}
+	slot, err := s.findSlot(uint(id))
+	if err != nil {
+		return err
+	}
+	// Other technicians' slots are reported as not found
+	if slot.TechnicianID != technician.ID {
+		return apierror.NotFound("SLOT_NOT_FOUND", "Appointment slot not found")
+	}
+	if slot.Appointment != nil {
+		return apierror.Conflict("SLOT_BOOKED", "This slot is booked; cancel the appointment before removing it")
+	}
+
+	if err := s.appointments.DeleteSlot(slot); err != nil {
+		return apierror.Internal("DATABASE_ERROR", "Failed to remove slot").Wrap(err)
+	}
+	return nil
+}
+
+// BookAppointment books a slot for a fitting on one of the customer's orders
+// The order must be accepted or in production, and the slot must belong to its technician,
+// be open and in the future, and not overlap the customer's other appointments
+// An order has at most one booked appointment at a time
+func (s *DefaultAppointmentService) BookAppointment(customer *models.User, orderID string, slotID uint) (*models.Appointment, error) {
+	if customer.Role != RoleCustomer {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only customers can book appointments")
+	}
+	order, err := findOrder(s.orders, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.CustomerID != customer.ID {
+		return nil, apierror.Forbidden("FORBIDDEN", "You can only book appointments for your own orders")
+	}
+	if !CanBookFitting(order) {
+		return nil, apierror.Unprocessable("ORDER_NOT_READY_FOR_FITTING", "Fittings can only be booked for accepted orders that are not yet shipped").WithDetails(map[string]interface{}{
+			"status":           order.Status,
+			"allowed_statuses": FittingStatuses,
+		})
+	}
+
+	slot, err := s.findSlot(slotID)
+	if err != nil {
+		if apierror.HasStatus(err, http.StatusNotFound) {
+			return nil, apierror.Unprocessable("INVALID_SLOT", "Appointment slot not found")
+		}
+		return nil, err
+	}
+	if slot.TechnicianID != *order.TechnicianID {
+		return nil, apierror.Unprocessable("INVALID_SLOT", "Fittings must be booked with the order's technician")
+	}
+	if !slot.StartsAt.After(s.now()) {
+		return nil, apierror.Unprocessable("INVALID_SLOT", "This slot has already started")
+	}
+	if slot.Appointment != nil {
+		return nil, apierror.Conflict("SLOT_TAKEN", "This slot has already been booked")
+	}
+
+	booked, err := s.appointments.ListAppointments(repositories.AppointmentQuery{OrderID: order.ID, Status: models.AppointmentBooked})
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to fetch appointments").Wrap(err)
+	}
+	if len(booked) > 0 {
+		return nil, apierror.Conflict("APPOINTMENT_EXISTS", "This order already has a fitting booked; cancel it to book another").WithDetails(map[string]interface{}{
+			"appointment_id": booked[0].ID,
+		})
+	}
+	overlapping, err := s.appointments.CountOverlappingAppointments(customer.ID, slot.StartsAt, slot.EndsAt)
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to check for overlapping appointments").Wrap(err)
+	}
+	if overlapping > 0 {
+		return nil, apierror.Conflict("APPOINTMENT_CONFLICT", "You already have an appointment at this time")
+	}
+
+	appointment := &models.Appointment{
+		SlotID:       slot.ID,
+		OrderID:      order.ID,
+		CustomerID:   customer.ID,
+		TechnicianID: slot.TechnicianID,
+		StartsAt:     slot.StartsAt,
+		EndsAt:       slot.EndsAt,
+		Status:       models.AppointmentBooked,
+	}
+	if err := s.appointments.CreateAppointment(appointment); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to book appointment").Wrap(err)
+	}
+	return appointment, nil
+}
+
+// ListOrderAppointments returns every appointment booked for an order, cancelled ones included
+func (s *DefaultAppointmentService) ListOrderAppointments(user *models.User, orderID string) ([]models.Appointment, error) {
+	order, err := findOrder(s.orders, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if !CanViewOrder(user, order) {
+		return nil, apierror.Forbidden("FORBIDDEN", "You do not have permission to view this order")
+	}
+
+	appointments, err := s.appointments.ListAppointments(repositories.AppointmentQuery{OrderID: order.ID})
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to fetch appointments").Wrap(err)
+	}
+	return appointments, nil
+}
+
+// ListAppointments returns the customer's or technician's booked appointments in the window, earliest first
+func (s *DefaultAppointmentService) ListAppointments(user *models.User, window CalendarRange) ([]models.Appointment, error) {
+	query := repositories.AppointmentQuery{Status: models.AppointmentBooked}
+	switch user.Role {
+	case RoleCustomer:
+		query.CustomerID = user.ID
+	case RoleTechnician:
+		query.TechnicianID = user.ID
+	default:
+		return nil, apierror.Forbidden("FORBIDDEN", "Only customers and technicians have appointments")
+	}
+	from, to, err := s.calendarRange(window)
+	if err != nil {
+		return nil, err
+	}
+	query.From, query.To = from, to
+
+	appointments, err := s.appointments.ListAppointments(query)
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to fetch appointments").Wrap(err)
+	}
+	return appointments, nil
+}
+
+// CancelAppointment cancels a booked appointment, freeing its slot for another booking
+// Either the customer or the technician can cancel, until the appointment starts
+func (s *DefaultAppointmentService) CancelAppointment(user *models.User, appointmentID string) (*models.Appointment, error) {
+	id, err := strconv.ParseUint(appointmentID, 10, 64)
+	if err != nil || id == 0 {
+		return nil, apierror.NotFound("APPOINTMENT_NOT_FOUND", "Appointment not found")
+	}
+	appointment, err := s.appointments.FindAppointment(uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apierror.NotFound("APPOINTMENT_NOT_FOUND", "Appointment not found")
+		}
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load appointment").Wrap(err)
+	}
+	// Other people's appointments are reported as not found
+	if !(user.Role == RoleCustomer && appointment.CustomerID == user.ID) && !(user.Role == RoleTechnician && appointment.TechnicianID == user.ID) {
+		return nil, apierror.NotFound("APPOINTMENT_NOT_FOUND", "Appointment not found")
+	}
+	if appointment.Status != models.AppointmentBooked {
+		return nil, apierror.Conflict("APPOINTMENT_NOT_BOOKED", "This appointment has already been cancelled")
+	}
+	now := s.now()
+	if !appointment.StartsAt.After(now) {
+		return nil, apierror.Unprocessable("APPOINTMENT_STARTED", "Appointments cannot be cancelled once they have started")
+	}
+
+	appointment.Status = models.AppointmentCancelled
+	appointment.CancelledAt = &now
+	if err := s.appointments.SaveAppointment(appointment); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to cancel appointment").Wrap(err)
+	}
+	return appointment, nil
+}
+
+// findSlot loads a slot, mapping a missing one to SLOT_NOT_FOUND
+func (s *DefaultAppointmentService) findSlot(id uint) (*models.AppointmentSlot, error) {
+	slot, err := s.appointments.FindSlot(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apierror.NotFound("SLOT_NOT_FOUND", "Appointment slot not found")
+		}
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load slot").Wrap(err)
+	}
+	return slot, nil
+}
+
+// calendarRange fills in the defaults of a listing window and checks its bounds
+func (s *DefaultAppointmentService) calendarRange(window CalendarRange) (time.Time, time.Time, error) {
+	from, to := window.From, window.To
+	if from.IsZero() {
+		from = s.now()
+	}
+	if to.IsZero() {
+		to = from.Add(DefaultCalendarWindow)
+	}
+	if !to.After(from) {
+		return from, to, apierror.Validation("The end of the range must be after its start", map[string]interface{}{
+			"field": "to",
+		})
+	}
+	if to.Sub(from) > MaxCalendarWindow {
+		return from, to, apierror.Validation("The range cannot be longer than 92 days", map[string]interface{}{
+			"field":    "to",
+			"max_days": int(MaxCalendarWindow.Hours() / 24),
+		})
+	}
+	return from, to, nil
+}
@@ -0,0 +1,286 @@
+package services
+
+import (
+	"net/http"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// fakeAppointmentRepository is an in-memory AppointmentRepository
+type fakeAppointmentRepository struct {
+	slots        map[uint]*models.AppointmentSlot
+	appointments map[uint]*models.Appointment
+	nextID       uint
+}
+
+func newFakeAppointmentRepository() *fakeAppointmentRepository {
+	return &fakeAppointmentRepository{slots: make(map[uint]*models.AppointmentSlot), appointments: make(map[uint]*models.Appointment), nextID: 1}
+}
+
+func (r *fakeAppointmentRepository) CreateSlot(slot *models.AppointmentSlot) error {
+	slot.ID = r.nextID
+	r.nextID++
+	stored := *slot
+	r.slots[slot.ID] = &stored
+	return nil
+}
+
+func (r *fakeAppointmentRepository) FindSlot(id uint) (*models.AppointmentSlot, error) {
+	slot, ok := r.slots[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.withBooking(*slot), nil
+}
+
+func (r *fakeAppointmentRepository) withBooking(slot models.AppointmentSlot) *models.AppointmentSlot {
+	for _, appointment := range r.appointments {
+		if appointment.SlotID == slot.ID && appointment.Status == models.AppointmentBooked {
+			booked := *appointment
+			slot.Appointment = &booked
+		}
+	}
+	return &slot
+}
+
+func (r *fakeAppointmentRepository) DeleteSlot(slot *models.AppointmentSlot) error {
+	delete(r.slots, slot.ID)
+	return nil
+}
+
+func (r *fakeAppointmentRepository) ListSlots(technicianID uint, from, to time.Time) ([]models.AppointmentSlot, error) {
+	slots := []models.AppointmentSlot{}
+	for _, slot := range r.slots {
+		if slot.TechnicianID == technicianID && !slot.StartsAt.Before(from) && slot.StartsAt.Before(to) {
+			slots = append(slots, *r.withBooking(*slot))
+		}
+	}
+	sort.Slice(slots, func(i, j int) bool { return slots[i].StartsAt.Before(slots[j].StartsAt) })
+	return slots, nil
+}
+
+func (r *fakeAppointmentRepository) CountOverlappingSlots(technicianID uint, start, end time.Time) (int64, error) {
+	var count int64
+	for _, slot := range r.slots {
+		if slot.TechnicianID == technicianID && slot.StartsAt.Before(end) && slot.EndsAt.After(start) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *fakeAppointmentRepository) CreateAppointment(appointment *models.Appointment) error {
+	appointment.ID = r.nextID
+	r.nextID++
+	stored := *appointment
+	r.appointments[appointment.ID] = &stored
+	return nil
+}
+
+func (r *fakeAppointmentRepository) FindAppointment(id uint) (*models.Appointment, error) {
+	appointment, ok := r.appointments[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	found := *appointment
+	return &found, nil
+}
+
+func (r *fakeAppointmentRepository) SaveAppointment(appointment *models.Appointment) error {
+	stored := *appointment
+	r.appointments[appointment.ID] = &stored
+	return nil
+}
+
+func (r *fakeAppointmentRepository) ListAppointments(query repositories.AppointmentQuery) ([]models.Appointment, error) {
+	appointments := []models.Appointment{}
+	for _, appointment := range r.appointments {
+		if (query.CustomerID != 0 && appointment.CustomerID != query.CustomerID) ||
+			(query.TechnicianID != 0 && appointment.TechnicianID != query.TechnicianID) ||
+			(query.OrderID != 0 && appointment.OrderID != query.OrderID) ||
+			(query.Status != "" && appointment.Status != query.Status) ||
+			(!query.From.IsZero() && appointment.StartsAt.Before(query.From)) ||
+			(!query.To.IsZero() && !appointment.StartsAt.Before(query.To)) {
+			continue
+		}
+		appointments = append(appointments, *appointment)
+	}
+	sort.Slice(appointments, func(i, j int) bool { return appointments[i].ID < appointments[j].ID })
+	return appointments, nil
+}
+
+func (r *fakeAppointmentRepository) CountOverlappingAppointments(customerID uint, start, end time.Time) (int64, error) {
+	var count int64
+	for _, appointment := range r.appointments {
+		if appointment.CustomerID == customerID && appointment.Status == models.AppointmentBooked &&
+			appointment.StartsAt.Before(end) && appointment.EndsAt.After(start) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+var appointmentsNow = time.Date(2026, 7, 1, 9, 0, 0, 0, time.UTC)
+
+func newTestAppointmentService(orders ...models.Order) *DefaultAppointmentService {
+	service := NewAppointmentService(newFakeAppointmentRepository(), newFakeOrderRepository(orders...))
+	service.now = func() time.Time { return appointmentsNow }
+	return service
+}
+
+func TestAppointmentService_CreateSlot(t *testing.T) {
+	service := newTestAppointmentService()
+	tomorrow := appointmentsNow.Add(24 * time.Hour)
+
+	_, err := service.CreateSlot(testCustomer, SlotInput{StartsAt: tomorrow, EndsAt: tomorrow.Add(time.Hour)})
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+
+	slot, err := service.CreateSlot(testTechnician, SlotInput{StartsAt: tomorrow, EndsAt: tomorrow.Add(time.Hour)})
+	assert.NoError(t, err)
+	assert.Equal(t, testTechnician.ID, slot.TechnicianID)
+
+	// Past, too short, too long, and overlapping slots are refused
+	_, err = service.CreateSlot(testTechnician, SlotInput{StartsAt: appointmentsNow.Add(-time.Hour), EndsAt: appointmentsNow})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+	_, err = service.CreateSlot(testTechnician, SlotInput{StartsAt: tomorrow.Add(2 * time.Hour), EndsAt: tomorrow.Add(2*time.Hour + 10*time.Minute)})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+	_, err = service.CreateSlot(testTechnician, SlotInput{StartsAt: tomorrow.Add(2 * time.Hour), EndsAt: tomorrow.Add(7 * time.Hour)})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+	_, err = service.CreateSlot(testTechnician, SlotInput{StartsAt: tomorrow.Add(30 * time.Minute), EndsAt: tomorrow.Add(90 * time.Minute)})
+	assertAPIError(t, err, http.StatusConflict, "SLOT_CONFLICT")
+
+	// Back-to-back slots, and other technicians' slots at the same time, are fine
+	_, err = service.CreateSlot(testTechnician, SlotInput{StartsAt: tomorrow.Add(time.Hour), EndsAt: tomorrow.Add(2 * time.Hour)})
+	assert.NoError(t, err)
+	_, err = service.CreateSlot(otherTech, SlotInput{StartsAt: tomorrow, EndsAt: tomorrow.Add(time.Hour)})
+	assert.NoError(t, err)
+}
+
+func TestAppointmentService_BookAndCancel(t *testing.T) {
+	service := newTestAppointmentService(
+		models.Order{ID: 1, CustomerID: testCustomer.ID, Status: StatusInProduction, TechnicianID: uintPtr(testTechnician.ID)},
+		models.Order{ID: 3, CustomerID: testCustomer.ID, Status: StatusSubmitted},
+		models.Order{ID: 4, CustomerID: otherCustomer.ID, Status: StatusAccepted, TechnicianID: uintPtr(testTechnician.ID)},
+		models.Order{ID: 5, CustomerID: testCustomer.ID, Status: StatusAccepted, TechnicianID: uintPtr(otherTech.ID)},
+	)
+	tomorrow := appointmentsNow.Add(24 * time.Hour)
+	first, err := service.CreateSlot(testTechnician, SlotInput{StartsAt: tomorrow, EndsAt: tomorrow.Add(time.Hour)})
+	assert.NoError(t, err)
+	second, err := service.CreateSlot(testTechnician, SlotInput{StartsAt: tomorrow.Add(time.Hour), EndsAt: tomorrow.Add(2 * time.Hour)})
+	assert.NoError(t, err)
+	elsewhere, err := service.CreateSlot(otherTech, SlotInput{StartsAt: tomorrow, EndsAt: tomorrow.Add(time.Hour)})
+	assert.NoError(t, err)
+
+	_, err = service.BookAppointment(testTechnician, "1", first.ID)
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+	_, err = service.BookAppointment(testCustomer, "4", first.ID)
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+	_, err = service.BookAppointment(testCustomer, "3", first.ID)
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "ORDER_NOT_READY_FOR_FITTING")
+	_, err = service.BookAppointment(testCustomer, "1", elsewhere.ID)
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "INVALID_SLOT")
+	_, err = service.BookAppointment(testCustomer, "1", 999)
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "INVALID_SLOT")
+
+	appointment, err := service.BookAppointment(testCustomer, "1", first.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.AppointmentBooked, appointment.Status)
+	assert.Equal(t, first.StartsAt, appointment.StartsAt)
+	assert.Equal(t, testTechnician.ID, appointment.TechnicianID)
+
+	// Conflicts: the slot is taken, the order has a fitting, the customer is busy
+	_, err = service.BookAppointment(otherCustomer, "4", first.ID)
+	assertAPIError(t, err, http.StatusConflict, "SLOT_TAKEN")
+	_, err = service.BookAppointment(testCustomer, "1", second.ID)
+	assertAPIError(t, err, http.StatusConflict, "APPOINTMENT_EXISTS")
+	_, err = service.BookAppointment(testCustomer, "5", elsewhere.ID)
+	assertAPIError(t, err, http.StatusConflict, "APPOINTMENT_CONFLICT")
+
+	// Booked slots cannot be removed, and other people cannot cancel
+	assertAPIError(t, service.DeleteSlot(testTechnician, uintString(first.ID)), http.StatusConflict, "SLOT_BOOKED")
+	_, err = service.CancelAppointment(otherCustomer, uintString(appointment.ID))
+	assertAPIError(t, err, http.StatusNotFound, "APPOINTMENT_NOT_FOUND")
+	_, err = service.CancelAppointment(otherTech, uintString(appointment.ID))
+	assertAPIError(t, err, http.StatusNotFound, "APPOINTMENT_NOT_FOUND")
+
+	// The technician cancels, which frees the slot for another customer
+	cancelled, err := service.CancelAppointment(testTechnician, uintString(appointment.ID))
+	assert.NoError(t, err)
+	assert.Equal(t, models.AppointmentCancelled, cancelled.Status)
+	assert.Equal(t, appointmentsNow, *cancelled.CancelledAt)
+	_, err = service.CancelAppointment(testCustomer, uintString(appointment.ID))
+	assertAPIError(t, err, http.StatusConflict, "APPOINTMENT_NOT_BOOKED")
+
+	_, err = service.BookAppointment(otherCustomer, "4", first.ID)
+	assert.NoError(t, err)
+
+	history, err := service.ListOrderAppointments(testCustomer, "1")
+	assert.NoError(t, err)
+	assert.Len(t, history, 1)
+	_, err = service.ListOrderAppointments(otherCustomer, "1")
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+
+	// Appointments cannot be cancelled once started
+	service.now = func() time.Time { return tomorrow.Add(time.Minute) }
+	booked, err := service.ListAppointments(otherCustomer, CalendarRange{From: tomorrow})
+	assert.NoError(t, err)
+	assert.Len(t, booked, 1)
+	_, err = service.CancelAppointment(otherCustomer, uintString(booked[0].ID))
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "APPOINTMENT_STARTED")
+}
+
+func TestAppointmentService_Calendars(t *testing.T) {
+	service := newTestAppointmentService(
+		models.Order{ID: 1, CustomerID: testCustomer.ID, Status: StatusAccepted, TechnicianID: uintPtr(testTechnician.ID)},
+	)
+	tomorrow := appointmentsNow.Add(24 * time.Hour)
+	first, err := service.CreateSlot(testTechnician, SlotInput{StartsAt: tomorrow, EndsAt: tomorrow.Add(time.Hour)})
+	assert.NoError(t, err)
+	_, err = service.CreateSlot(testTechnician, SlotInput{StartsAt: tomorrow.Add(time.Hour), EndsAt: tomorrow.Add(2 * time.Hour)})
+	assert.NoError(t, err)
+	_, err = service.CreateSlot(testTechnician, SlotInput{StartsAt: appointmentsNow.Add(60 * 24 * time.Hour), EndsAt: appointmentsNow.Add(60*24*time.Hour + time.Hour)})
+	assert.NoError(t, err)
+	_, err = service.BookAppointment(testCustomer, "1", first.ID)
+	assert.NoError(t, err)
+
+	// The technician sees their slots in the default window with the booking; customers see open slots only
+	slots, err := service.ListSlots(testTechnician, 0, CalendarRange{})
+	assert.NoError(t, err)
+	assert.Len(t, slots, 2)
+	assert.NotNil(t, slots[0].Appointment)
+	slots, err = service.ListSlots(testCustomer, testTechnician.ID, CalendarRange{})
+	assert.NoError(t, err)
+	assert.Len(t, slots, 1)
+	assert.Nil(t, slots[0].Appointment)
+	slots, err = service.ListSlots(testCustomer, testTechnician.ID, CalendarRange{To: appointmentsNow.Add(90 * 24 * time.Hour)})
+	assert.NoError(t, err)
+	assert.Len(t, slots, 2)
+
+	_, err = service.ListSlots(testCustomer, 0, CalendarRange{})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+	_, err = service.ListSlots(testCustomer, testTechnician.ID, CalendarRange{To: appointmentsNow.Add(-time.Hour)})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+	_, err = service.ListSlots(testCustomer, testTechnician.ID, CalendarRange{To: appointmentsNow.Add(100 * 24 * time.Hour)})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+
+	for _, user := range []*models.User{testCustomer, testTechnician} {
+		appointments, err := service.ListAppointments(user, CalendarRange{})
+		assert.NoError(t, err)
+		assert.Len(t, appointments, 1)
+	}
+	appointments, err := service.ListAppointments(otherTech, CalendarRange{})
+	assert.NoError(t, err)
+	assert.Empty(t, appointments)
+	_, err = service.ListAppointments(&models.User{ID: 9, Role: RoleAdmin}, CalendarRange{})
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+
+	// Unbooked slots can be removed by their technician only
+	assertAPIError(t, service.DeleteSlot(otherTech, uintString(slots[1].ID)), http.StatusNotFound, "SLOT_NOT_FOUND")
+	assert.NoError(t, service.DeleteSlot(testTechnician, uintString(slots[1].ID)))
+}
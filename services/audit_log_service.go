@@ -0,0 +1,149 @@
+package services
+
+import (
+	"strings"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+)
+
+// AuditActions lists the actions recorded in the audit log
+var AuditActions = []string{
+	models.AuditRoleChanged,
+	models.AuditUserImpersonated,
+	models.AuditOrderReviewed,
+	models.AuditOrderStatusChanged,
+	models.AuditAddOnPriceSet,
+	models.AuditCatalogPriceSet,
+	models.AuditImageReviewed,
+	models.AuditPayoutRecorded,
+	models.AuditFeatureFlagChanged,
+	models.AuditViolationReviewed,
+}
+
+// AuditTargetTypes lists the kinds of record audit log entries target
+var AuditTargetTypes = []string{
+	models.AuditTargetUser,
+	models.AuditTargetOrder,
+	models.AuditTargetAddOn,
+	models.AuditTargetCatalogDesign,
+	models.AuditTargetImage,
+	models.AuditTargetPayout,
+	models.AuditTargetFeatureFlag,
+	models.AuditTargetViolation,
+}
+
+// ListAuditLogsOptions controls pagination and filtering for ListAuditLogs
+type ListAuditLogsOptions struct {
+	Page  int
+	Limit int
+	repositories.AuditLogQuery
+}
+
+// AuditLogService reads the audit log of privileged changes
+type AuditLogService interface {
+	// ListAuditLogs returns a page of audit log entries matching the filters, newest first, with the total (admins only)
+	ListAuditLogs(admin *models.User, opts ListAuditLogsOptions) ([]models.AuditLog, int64, error)
+}
+
+// DefaultAuditLogService implements AuditLogService on top of an AuditLogRepository
+type DefaultAuditLogService struct {
+	logs repositories.AuditLogRepository
+}
+
+var auditLogServiceInstance AuditLogService
+
+// NewAuditLogService creates an audit log service using the given repository
+func NewAuditLogService(logs repositories.AuditLogRepository) *DefaultAuditLogService {
+	return &DefaultAuditLogService{logs: logs}
+}
+
+// GetAuditLogService returns the configured audit log service
+// When none has been set, a service over the current database connection is returned
+func GetAuditLogService() AuditLogService {
+	if auditLogServiceInstance != nil {
+		return auditLogServiceInstance
+	}
+	return NewAuditLogService(repositories.NewAuditLogRepository(config.GetDB()))
+}
+
+// SetAuditLogService sets the audit log service instance (primarily for testing)
+func SetAuditLogService(service AuditLogService) {
+	auditLogServiceInstance = service
+}
+
+// ListAuditLogs returns a page of audit log entries matching the filters, newest first, with the total
+func (s *DefaultAuditLogService) ListAuditLogs(admin *models.User, opts ListAuditLogsOptions) ([]models.AuditLog, int64, error) {
+	if admin.Role != RoleAdmin {
+		return nil, 0, apierror.Forbidden("FORBIDDEN", "Only admins can view the audit log")
+	}
+	if opts.Action != "" && !oneOf(opts.Action, AuditActions) {
+		return nil, 0, apierror.Validation("Invalid action filter", map[string]string{
+			"action": "must be one of: " + strings.Join(AuditActions, ", "),
+		})
+	}
+	if opts.TargetType != "" && !oneOf(opts.TargetType, AuditTargetTypes) {
+		return nil, 0, apierror.Validation("Invalid target type filter", map[string]string{
+			"target_type": "must be one of: " + strings.Join(AuditTargetTypes, ", "),
+		})
+	}
+	if opts.TargetID != nil && opts.TargetType == "" {
+		return nil, 0, apierror.Validation("Invalid target filter", map[string]string{
+			"target_id": "requires target_type",
+		})
+	}
+
+	query := opts.AuditLogQuery
+	query.Limit = opts.Limit
+	query.Offset = (opts.Page - 1) * opts.Limit
+	entries, total, err := s.logs.List(query)
+	if err != nil {
+		return nil, 0, apierror.Internal("DATABASE_ERROR", "Failed to fetch audit log").Wrap(err)
+	}
+	return entries, total, nil
+}
+
+// oneOf reports whether value is in allowed
+func oneOf(value string, allowed []string) bool {
+	for _, candidate := range allowed {
+		if value == candidate {
+			return true
+		}
+	}
+	return false
+}
+
+// auditChanged reports whether any audited field differs between before and after
+// The fields hold comparable values such as prices and codes
+func auditChanged(before, after map[string]interface{}) bool {
+	for field, value := range after {
+		if before[field] != value {
+			return true
+		}
+	}
+	return false
+}
+
+// recordAudit writes an audit log entry for a change the actor made
+// It goes through the same connection as the change, so in a request transaction both commit or roll back together
+// A nil repository records nothing
+func recordAudit(logs repositories.AuditLogRepository, actor *models.User, action, targetType string, targetID uint, before, after map[string]interface{}) error {
+	if logs == nil {
+		return nil
+	}
+	entry := &models.AuditLog{
+		ActorID:    actor.ID,
+		ActorRole:  actor.Role,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Before:     before,
+		After:      after,
+	}
+	if err := logs.Create(entry); err != nil {
+		return apierror.Internal("DATABASE_ERROR", "Failed to record the change in the audit log").Wrap(err)
+	}
+	return nil
+}
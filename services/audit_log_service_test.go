@@ -0,0 +1,110 @@
+package services
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeAuditLogRepository is an in-memory AuditLogRepository that remembers the last list query
+type fakeAuditLogRepository struct {
+	entries   []models.AuditLog
+	lastQuery repositories.AuditLogQuery
+}
+
+func (r *fakeAuditLogRepository) Create(entry *models.AuditLog) error {
+	entry.ID = uint(len(r.entries) + 1)
+	r.entries = append(r.entries, *entry)
+	return nil
+}
+
+func (r *fakeAuditLogRepository) List(query repositories.AuditLogQuery) ([]models.AuditLog, int64, error) {
+	r.lastQuery = query
+	var entries []models.AuditLog
+	for _, entry := range r.entries {
+		if query.Action == "" || entry.Action == query.Action {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, int64(len(entries)), nil
+}
+
+func TestAuditLogService_ListAuditLogs(t *testing.T) {
+	admin := &models.User{ID: 10, Role: RoleAdmin}
+	repo := &fakeAuditLogRepository{}
+	service := NewAuditLogService(repo)
+
+	_, _, err := service.ListAuditLogs(testTechnician, ListAuditLogsOptions{Page: 1, Limit: 20})
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+
+	_, _, err = service.ListAuditLogs(admin, ListAuditLogsOptions{Page: 1, Limit: 20, AuditLogQuery: repositories.AuditLogQuery{Action: "order.deleted"}})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+	_, _, err = service.ListAuditLogs(admin, ListAuditLogsOptions{Page: 1, Limit: 20, AuditLogQuery: repositories.AuditLogQuery{TargetType: "invoice"}})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+	_, _, err = service.ListAuditLogs(admin, ListAuditLogsOptions{Page: 1, Limit: 20, AuditLogQuery: repositories.AuditLogQuery{TargetID: uintPtr(1)}})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+
+	_, _, err = service.ListAuditLogs(admin, ListAuditLogsOptions{Page: 3, Limit: 20, AuditLogQuery: repositories.AuditLogQuery{
+		Action:     models.AuditOrderStatusChanged,
+		TargetType: models.AuditTargetOrder,
+		TargetID:   uintPtr(5),
+	}})
+	assert.NoError(t, err)
+	assert.Equal(t, repositories.AuditLogQuery{
+		Action:     models.AuditOrderStatusChanged,
+		TargetType: models.AuditTargetOrder,
+		TargetID:   uintPtr(5),
+		Limit:      20,
+		Offset:     40,
+	}, repo.lastQuery)
+}
+
+func TestAuditLog_RecordsPrivilegedChanges(t *testing.T) {
+	audit := &fakeAuditLogRepository{}
+
+	// Role changes record the old and new role
+	admin := &models.User{ID: 10, Auth0ID: "auth0|admin", Role: RoleAdmin}
+	jane := &models.User{ID: 11, Auth0ID: "auth0|jane", Role: RoleCustomer}
+	roles := NewRoleService(newFakeUserRepository(admin, jane), nil)
+	roles.audit = audit
+	_, err := roles.ChangeRole(admin, "11", RoleTechnician)
+	assert.NoError(t, err)
+	_, err = roles.ChangeRole(admin, "11", RoleTechnician) // unchanged, nothing to record
+	assert.NoError(t, err)
+
+	// Status changes record the transition
+	orders := newTestOrderService(newFakeOrderRepository(
+		models.Order{ID: 1, CustomerID: testCustomer.ID, Status: StatusAccepted, TechnicianID: uintPtr(testTechnician.ID)},
+	))
+	orders.audit = audit
+	_, err = orders.UpdateOrderStatus(testTechnician, "1", StatusInProduction, 0)
+	assert.NoError(t, err)
+
+	// Add-on prices are recorded when set, not when other fields change
+	addOns := NewAddOnService(newFakeAddOnRepository())
+	addOns.audit = audit
+	addOn, err := addOns.CreateAddOn(testTechnician, AddOnInput{Name: "Charm", Price: 2.5})
+	assert.NoError(t, err)
+	_, err = addOns.UpdateAddOn(testTechnician, uintString(addOn.ID), AddOnInput{Name: "Gold charm", Price: 2.5})
+	assert.NoError(t, err)
+	_, err = addOns.UpdateAddOn(testTechnician, uintString(addOn.ID), AddOnInput{Name: "Gold charm", Price: 3})
+	assert.NoError(t, err)
+
+	if assert.Len(t, audit.entries, 4) {
+		assert.Equal(t, models.AuditLog{
+			ID: 1, ActorID: admin.ID, ActorRole: RoleAdmin, Action: models.AuditRoleChanged,
+			TargetType: models.AuditTargetUser, TargetID: jane.ID,
+			Before: map[string]interface{}{"role": RoleCustomer}, After: map[string]interface{}{"role": RoleTechnician},
+		}, audit.entries[0])
+		assert.Equal(t, models.AuditOrderStatusChanged, audit.entries[1].Action)
+		assert.Equal(t, testTechnician.ID, audit.entries[1].ActorID)
+		assert.Equal(t, map[string]interface{}{"status": StatusAccepted}, audit.entries[1].Before)
+		assert.Equal(t, map[string]interface{}{"status": StatusInProduction}, audit.entries[1].After)
+		assert.Nil(t, audit.entries[2].Before)
+		assert.Equal(t, 2.5, audit.entries[3].Before["price"])
+		assert.Equal(t, 3.0, audit.entries[3].After["price"])
+	}
+}
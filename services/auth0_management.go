@@ -0,0 +1,166 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+)
+
+// RoleDirectory stores user roles in the identity provider, where they become token claims
+type RoleDirectory interface {
+	// GetRole returns the role recorded for the identity, or "" when none is set
+	GetRole(auth0ID string) (string, error)
+
+	// SetRole records the identity's role
+	SetRole(auth0ID string, role string) error
+}
+
+// Auth0ManagementClient implements RoleDirectory with the Auth0 Management API
+// Roles are kept in app_metadata.role, which a login Action copies into the role claim
+type Auth0ManagementClient struct {
+	baseURL      string
+	clientID     string
+	clientSecret string
+	httpClient   *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// appMetadataRole is the shape of the app_metadata the role is stored in
+type appMetadataRole struct {
+	AppMetadata struct {
+		Role string `json:"role"`
+	} `json:"app_metadata"`
+}
+
+// NewAuth0ManagementClient creates a Management API client from the configured credentials
+func NewAuth0ManagementClient(cfg *config.Config) *Auth0ManagementClient {
+	baseURL := strings.TrimRight(cfg.Auth0Domain, "/")
+	if !strings.HasPrefix(baseURL, "http://") && !strings.HasPrefix(baseURL, "https://") {
+		baseURL = "https://" + baseURL
+	}
+	return &Auth0ManagementClient{
+		baseURL:      baseURL,
+		clientID:     cfg.Auth0MgmtClientID,
+		clientSecret: cfg.Auth0MgmtSecret,
+		httpClient:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// GetRole returns the role recorded in the identity's app_metadata, or "" when none is set
+func (m *Auth0ManagementClient) GetRole(auth0ID string) (string, error) {
+	var user appMetadataRole
+	if err := m.call(http.MethodGet, m.userURL(auth0ID)+"?fields=app_metadata&include_fields=true", nil, &user); err != nil {
+		return "", err
+	}
+	return user.AppMetadata.Role, nil
+}
+
+// SetRole records the identity's role in app_metadata; other app_metadata keys are preserved
+func (m *Auth0ManagementClient) SetRole(auth0ID string, role string) error {
+	var update appMetadataRole
+	update.AppMetadata.Role = role
+	return m.call(http.MethodPatch, m.userURL(auth0ID), update, nil)
+}
+
+// userURL returns the Management API URL of a user
+func (m *Auth0ManagementClient) userURL(auth0ID string) string {
+	return m.baseURL + "/api/v2/users/" + url.PathEscape(auth0ID)
+}
+
+// call sends an authenticated Management API request and decodes the response into out, if given
+func (m *Auth0ManagementClient) call(method, endpoint string, body, out interface{}) error {
+	token, err := m.token()
+	if err != nil {
+		return err
+	}
+
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequest(method, endpoint, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call Auth0 Management API: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("auth0 Management API returned status %d: %s", resp.StatusCode, string(detail))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode Auth0 Management API response: %w", err)
+	}
+	return nil
+}
+
+// token returns a Management API access token, requesting a new one shortly before the cached one expires
+func (m *Auth0ManagementClient) token() (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.accessToken != "" && time.Now().Before(m.expiresAt) {
+		return m.accessToken, nil
+	}
+
+	payload, err := json.Marshal(map[string]string{
+		"grant_type":    "client_credentials",
+		"client_id":     m.clientID,
+		"client_secret": m.clientSecret,
+		"audience":      m.baseURL + "/api/v2/",
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode token request: %w", err)
+	}
+
+	resp, err := m.httpClient.Post(m.baseURL+"/oauth/token", "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return "", fmt.Errorf("failed to request Management API token: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("auth0 token endpoint returned status %d: %s", resp.StatusCode, string(detail))
+	}
+
+	var grant struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&grant); err != nil {
+		return "", fmt.Errorf("failed to decode Management API token: %w", err)
+	}
+
+	m.accessToken = grant.AccessToken
+	m.expiresAt = time.Now().Add(time.Duration(grant.ExpiresIn)*time.Second - time.Minute)
+	return m.accessToken, nil
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuth0ManagementClient(t *testing.T) {
+	tokenRequests := 0
+	var patched map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/oauth/token":
+			tokenRequests++
+			var grant map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&grant)
+			assert.Equal(t, "client_credentials", grant["grant_type"])
+			assert.Equal(t, "mgmt-client", grant["client_id"])
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "mgmt-token", "expires_in": 86400})
+		case r.URL.EscapedPath() == "/api/v2/users/auth0%7Cjane":
+			assert.Equal(t, "Bearer mgmt-token", r.Header.Get("Authorization"))
+			if r.Method == http.MethodPatch {
+				_ = json.NewDecoder(r.Body).Decode(&patched)
+				_, _ = w.Write([]byte(`{}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"app_metadata":{"role":"technician","plan":"pro"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewAuth0ManagementClient(&config.Config{
+		Auth0Domain:       server.URL,
+		Auth0MgmtClientID: "mgmt-client",
+		Auth0MgmtSecret:   "mgmt-secret",
+	})
+
+	role, err := client.GetRole("auth0|jane")
+	assert.NoError(t, err)
+	assert.Equal(t, "technician", role)
+
+	assert.NoError(t, client.SetRole("auth0|jane", "admin"))
+	assert.Equal(t, map[string]interface{}{"app_metadata": map[string]interface{}{"role": "admin"}}, patched)
+
+	// The access token is reused until it nears expiry
+	assert.Equal(t, 1, tokenRequests)
+
+	_, err = client.GetRole("auth0|missing")
+	assert.Error(t, err)
+}
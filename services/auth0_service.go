@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -9,6 +10,7 @@ import (
 	"time"
 
 	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/tracing"
 )
 
 // Auth0UserInfo represents the user information returned from Auth0's /userinfo endpoint
@@ -29,14 +31,15 @@ func NewAuth0Service(cfg *config.Config) *Auth0Service {
 	return &Auth0Service{
 		domain: cfg.Auth0Domain,
 		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
+			Timeout:   10 * time.Second,
+			Transport: tracing.Transport(nil), // traced as part of the signup request
 		},
 	}
 }
 
 // GetUserInfo fetches user information from Auth0's /userinfo endpoint
-// accessToken is the JWT access token from the Authorization header
-func (s *Auth0Service) GetUserInfo(accessToken string) (*Auth0UserInfo, error) {
+// accessToken is the JWT access token from the Authorization header; ctx is the request the call is made for
+func (s *Auth0Service) GetUserInfo(ctx context.Context, accessToken string) (*Auth0UserInfo, error) {
 	// Construct the userinfo endpoint URL
 	// If domain already includes a protocol (for testing), use it as-is
 	var url string
@@ -47,7 +50,7 @@ func (s *Auth0Service) GetUserInfo(accessToken string) (*Auth0UserInfo, error) {
 	}
 
 	// Create the HTTP request
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"gorm.io/gorm"
+)
+
+// MaxVacations limits how many vacations a technician can have on their calendar
+const MaxVacations = 50
+
+// AvailabilityInput holds a technician's capacity and calendar; it replaces the previous settings
+type AvailabilityInput struct {
+	MaxConcurrentOrders int // 0 means no limit
+	WorkingDays         []string
+	Vacations           []models.Vacation // whole UTC days, both ends included
+}
+
+// AvailableTechnician is a technician customers can choose, without their contact details
+type AvailableTechnician struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+// AvailabilityService manages technicians' capacity and availability calendars
+type AvailabilityService interface {
+	// GetAvailability returns the technician's settings
+	GetAvailability(technician *models.User) (*models.TechnicianSettings, error)
+
+	// UpdateAvailability replaces the technician's settings
+	UpdateAvailability(technician *models.User, input AvailabilityInput) (*models.TechnicianSettings, error)
+
+	// ListAvailableTechnicians returns the technicians who can take an order today
+	ListAvailableTechnicians(user *models.User) ([]AvailableTechnician, error)
+}
+
+// DefaultAvailabilityService implements AvailabilityService on top of a TechnicianSettingsRepository
+type DefaultAvailabilityService struct {
+	settings repositories.TechnicianSettingsRepository
+	now      func() time.Time
+}
+
+var availabilityServiceInstance AvailabilityService
+
+// NewAvailabilityService creates an availability service using the given repository
+func NewAvailabilityService(settings repositories.TechnicianSettingsRepository) *DefaultAvailabilityService {
+	return &DefaultAvailabilityService{settings: settings, now: time.Now}
+}
+
+// GetAvailabilityService returns the configured availability service
+// When none has been set, a service over the current database connection is returned
+func GetAvailabilityService() AvailabilityService {
+	if availabilityServiceInstance != nil {
+		return availabilityServiceInstance
+	}
+	return NewAvailabilityService(repositories.NewTechnicianSettingsRepository(config.GetDB()))
+}
+
+// SetAvailabilityService sets the availability service instance (primarily for testing)
+func SetAvailabilityService(service AvailabilityService) {
+	availabilityServiceInstance = service
+}
+
+// GetAvailability returns the technician's settings, or the defaults when none have been saved
+func (s *DefaultAvailabilityService) GetAvailability(technician *models.User) (*models.TechnicianSettings, error) {
+	if technician.Role != RoleTechnician {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only technicians have an availability calendar")
+	}
+	return loadTechnicianSettings(s.settings, technician.ID)
+}
+
+// UpdateAvailability replaces the technician's settings
+func (s *DefaultAvailabilityService) UpdateAvailability(technician *models.User, input AvailabilityInput) (*models.TechnicianSettings, error) {
+	if technician.Role != RoleTechnician {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only technicians have an availability calendar")
+	}
+	input, err := validateAvailabilityInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := loadTechnicianSettings(s.settings, technician.ID)
+	if err != nil {
+		return nil, err
+	}
+	settings.MaxConcurrentOrders = input.MaxConcurrentOrders
+	settings.WorkingDays = input.WorkingDays
+	settings.Vacations = input.Vacations
+	if err := s.settings.Save(settings); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to save availability").Wrap(err)
+	}
+	return settings, nil
+}
+
+// ListAvailableTechnicians returns the technicians who can take an order today, by name
+// Technicians who do not work today, are on vacation, or are at capacity are left out
+func (s *DefaultAvailabilityService) ListAvailableTechnicians(user *models.User) ([]AvailableTechnician, error) {
+	technicians, settings, err := s.settings.ListTechnicians()
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to fetch technicians").Wrap(err)
+	}
+
+	today := s.now()
+	available := []AvailableTechnician{}
+	for _, technician := range technicians {
+		current, ok := settings[technician.ID]
+		if !ok {
+			current = *defaultTechnicianSettings(technician.ID)
+		}
+		if !current.AvailableOn(today) {
+			continue
+		}
+		full, err := atCapacity(s.settings, &current)
+		if err != nil {
+			return nil, err
+		}
+		if full {
+			continue
+		}
+		available = append(available, AvailableTechnician{ID: technician.ID, Name: technician.Name})
+	}
+	return available, nil
+}
+
+// checkTechnicianCapacity refuses another order for a technician whose open orders have reached their limit
+// A nil repository places no limit
+func checkTechnicianCapacity(settingsRepo repositories.TechnicianSettingsRepository, technician *models.User) error {
+	if settingsRepo == nil {
+		return nil
+	}
+	settings, err := loadTechnicianSettings(settingsRepo, technician.ID)
+	if err != nil {
+		return err
+	}
+	full, err := atCapacity(settingsRepo, settings)
+	if err != nil {
+		return err
+	}
+	if full {
+		return apierror.Unprocessable("TECHNICIAN_AT_CAPACITY", "You already have as many open orders as your capacity allows").WithDetails(map[string]interface{}{
+			"max_concurrent_orders": settings.MaxConcurrentOrders,
+		})
+	}
+	return nil
+}
+
+// atCapacity reports whether the technician's open orders have reached their limit
+func atCapacity(settingsRepo repositories.TechnicianSettingsRepository, settings *models.TechnicianSettings) (bool, error) {
+	if settings.MaxConcurrentOrders <= 0 {
+		return false, nil
+	}
+	open, err := settingsRepo.CountAssigned(settings.UserID, OpenStatuses)
+	if err != nil {
+		return false, apierror.Internal("DATABASE_ERROR", "Failed to count open orders").Wrap(err)
+	}
+	return open >= int64(settings.MaxConcurrentOrders), nil
+}
+
+// loadTechnicianSettings returns the technician's saved settings, or the defaults when there are none
+func loadTechnicianSettings(settingsRepo repositories.TechnicianSettingsRepository, technicianID uint) (*models.TechnicianSettings, error) {
+	settings, err := settingsRepo.FindForTechnician(technicianID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return defaultTechnicianSettings(technicianID), nil
+		}
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load availability").Wrap(err)
+	}
+	return settings, nil
+}
+
+// defaultTechnicianSettings are the settings of a technician who never saved any: no limit, every day, no vacations
+func defaultTechnicianSettings(technicianID uint) *models.TechnicianSettings {
+	return &models.TechnicianSettings{
+		UserID:      technicianID,
+		WorkingDays: append([]string(nil), models.Weekdays...),
+		Vacations:   []models.Vacation{},
+	}
+}
+
+// validateAvailabilityInput checks the calendar and puts it in canonical form:
+// working days lowercase in weekday order, vacations at midnight UTC sorted by start
+func validateAvailabilityInput(input AvailabilityInput) (AvailabilityInput, error) {
+	if input.MaxConcurrentOrders < 0 {
+		return input, apierror.Validation("Max concurrent orders cannot be negative", map[string]interface{}{
+			"field": "max_concurrent_orders",
+		})
+	}
+
+	requested := make(map[string]bool, len(input.WorkingDays))
+	for _, day := range input.WorkingDays {
+		requested[strings.ToLower(strings.TrimSpace(day))] = true
+	}
+	workingDays := []string{}
+	for _, weekday := range models.Weekdays {
+		if requested[weekday] {
+			workingDays = append(workingDays, weekday)
+			delete(requested, weekday)
+		}
+	}
+	if len(requested) > 0 {
+		return input, apierror.Validation("Working days must be weekday names such as monday", map[string]interface{}{
+			"field":   "working_days",
+			"allowed": models.Weekdays,
+		})
+	}
+	input.WorkingDays = workingDays
+
+	if len(input.Vacations) > MaxVacations {
+		return input, apierror.Validation("Too many vacations", map[string]interface{}{
+			"field":         "vacations",
+			"max_vacations": MaxVacations,
+		})
+	}
+	vacations := make([]models.Vacation, 0, len(input.Vacations))
+	for _, vacation := range input.Vacations {
+		vacation = models.Vacation{Start: startOfDay(vacation.Start), End: startOfDay(vacation.End)}
+		if vacation.End.Before(vacation.Start) {
+			return input, apierror.Validation("A vacation cannot end before it starts", map[string]interface{}{
+				"field": "vacations",
+			})
+		}
+		vacations = append(vacations, vacation)
+	}
+	sort.Slice(vacations, func(i, j int) bool { return vacations[i].Start.Before(vacations[j].Start) })
+	input.Vacations = vacations
+	return input, nil
+}
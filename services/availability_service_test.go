@@ -0,0 +1,182 @@
+package services
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// fakeTechnicianSettingsRepository is an in-memory TechnicianSettingsRepository
+// Open orders are counted from the order repository it is given
+type fakeTechnicianSettingsRepository struct {
+	settings    map[uint]*models.TechnicianSettings
+	technicians []models.User
+	orders      *fakeOrderRepository
+	nextID      uint
+}
+
+func newFakeTechnicianSettingsRepository(orders *fakeOrderRepository, technicians ...models.User) *fakeTechnicianSettingsRepository {
+	return &fakeTechnicianSettingsRepository{settings: make(map[uint]*models.TechnicianSettings), technicians: technicians, orders: orders, nextID: 1}
+}
+
+func (r *fakeTechnicianSettingsRepository) FindForTechnician(technicianID uint) (*models.TechnicianSettings, error) {
+	settings, ok := r.settings[technicianID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	found := *settings
+	return &found, nil
+}
+
+func (r *fakeTechnicianSettingsRepository) FindByCalendarTokenHash(hash string) (*models.TechnicianSettings, error) {
+	for _, settings := range r.settings {
+		if settings.CalendarTokenHash != nil && *settings.CalendarTokenHash == hash {
+			found := *settings
+			return &found, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeTechnicianSettingsRepository) Save(settings *models.TechnicianSettings) error {
+	if settings.ID == 0 {
+		settings.ID = r.nextID
+		r.nextID++
+	}
+	stored := *settings
+	r.settings[settings.UserID] = &stored
+	return nil
+}
+
+func (r *fakeTechnicianSettingsRepository) ListTechnicians() ([]models.User, map[uint]models.TechnicianSettings, error) {
+	settings := make(map[uint]models.TechnicianSettings, len(r.settings))
+	for userID, row := range r.settings {
+		settings[userID] = *row
+	}
+	return r.technicians, settings, nil
+}
+
+func (r *fakeTechnicianSettingsRepository) CountAssigned(technicianID uint, statuses []string) (int64, error) {
+	var count int64
+	for _, order := range r.orders.orders {
+		if order.TechnicianID == nil || *order.TechnicianID != technicianID {
+			continue
+		}
+		for _, status := range statuses {
+			if order.Status == status {
+				count++
+			}
+		}
+	}
+	return count, nil
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestAvailabilityService_GetAndUpdate(t *testing.T) {
+	service := NewAvailabilityService(newFakeTechnicianSettingsRepository(newFakeOrderRepository()))
+
+	_, err := service.GetAvailability(testCustomer)
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+
+	// Technicians start with no limit, every day, and no vacations
+	settings, err := service.GetAvailability(testTechnician)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, settings.MaxConcurrentOrders)
+	assert.Equal(t, models.Weekdays, settings.WorkingDays)
+	assert.Empty(t, settings.Vacations)
+
+	settings, err = service.UpdateAvailability(testTechnician, AvailabilityInput{
+		MaxConcurrentOrders: 3,
+		WorkingDays:         []string{"Friday", " monday ", "wednesday", "monday"},
+		Vacations: []models.Vacation{
+			{Start: time.Date(2026, 8, 10, 15, 0, 0, 0, time.UTC), End: date(2026, 8, 14)},
+			{Start: date(2026, 7, 1), End: date(2026, 7, 1)},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, settings.MaxConcurrentOrders)
+	assert.Equal(t, []string{"monday", "wednesday", "friday"}, settings.WorkingDays)
+	assert.Equal(t, []models.Vacation{
+		{Start: date(2026, 7, 1), End: date(2026, 7, 1)},
+		{Start: date(2026, 8, 10), End: date(2026, 8, 14)},
+	}, settings.Vacations)
+
+	saved, err := service.GetAvailability(testTechnician)
+	assert.NoError(t, err)
+	assert.Equal(t, settings, saved)
+
+	_, err = service.UpdateAvailability(testTechnician, AvailabilityInput{WorkingDays: []string{"someday"}})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+	_, err = service.UpdateAvailability(testTechnician, AvailabilityInput{MaxConcurrentOrders: -1})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+	_, err = service.UpdateAvailability(testTechnician, AvailabilityInput{Vacations: []models.Vacation{{Start: date(2026, 7, 2), End: date(2026, 7, 1)}}})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+}
+
+func TestAvailabilityService_ListAvailableTechnicians(t *testing.T) {
+	orders := newFakeOrderRepository(
+		models.Order{ID: 1, Status: StatusInProduction, TechnicianID: uintPtr(otherTech.ID)},
+		models.Order{ID: 2, Status: StatusDelivered, TechnicianID: uintPtr(testTechnician.ID)},
+	)
+	repo := newFakeTechnicianSettingsRepository(orders, *testTechnician, *otherTech)
+	service := NewAvailabilityService(repo)
+	monday := time.Date(2026, 6, 29, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return monday }
+
+	technicians, err := service.ListAvailableTechnicians(testCustomer)
+	assert.NoError(t, err)
+	assert.Len(t, technicians, 2)
+
+	// At capacity: delivered orders do not count, open ones do
+	assert.NoError(t, repo.Save(&models.TechnicianSettings{UserID: testTechnician.ID, MaxConcurrentOrders: 1, WorkingDays: models.Weekdays}))
+	assert.NoError(t, repo.Save(&models.TechnicianSettings{UserID: otherTech.ID, MaxConcurrentOrders: 1, WorkingDays: models.Weekdays}))
+	technicians, err = service.ListAvailableTechnicians(testCustomer)
+	assert.NoError(t, err)
+	assert.Equal(t, []AvailableTechnician{{ID: testTechnician.ID, Name: testTechnician.Name}}, technicians)
+
+	// Not working today, or on vacation
+	assert.NoError(t, repo.Save(&models.TechnicianSettings{UserID: testTechnician.ID, WorkingDays: []string{"tuesday"}}))
+	technicians, err = service.ListAvailableTechnicians(testCustomer)
+	assert.NoError(t, err)
+	assert.Empty(t, technicians)
+
+	assert.NoError(t, repo.Save(&models.TechnicianSettings{UserID: testTechnician.ID, WorkingDays: models.Weekdays, Vacations: []models.Vacation{{Start: date(2026, 6, 29), End: date(2026, 7, 3)}}}))
+	technicians, err = service.ListAvailableTechnicians(testCustomer)
+	assert.NoError(t, err)
+	assert.Empty(t, technicians)
+}
+
+func TestOrderService_TechnicianCapacity(t *testing.T) {
+	repo := newFakeOrderRepository(
+		models.Order{ID: 1, CustomerID: testCustomer.ID, Status: StatusInProduction, TechnicianID: uintPtr(testTechnician.ID)},
+		models.Order{ID: 2, CustomerID: testCustomer.ID, Status: StatusSubmitted, ShippingAddress: testAddress},
+		models.Order{ID: 3, CustomerID: testCustomer.ID, Status: StatusSubmitted, ShippingAddress: testAddress},
+	)
+	settings := newFakeTechnicianSettingsRepository(repo)
+	assert.NoError(t, settings.Save(&models.TechnicianSettings{UserID: testTechnician.ID, MaxConcurrentOrders: 2, WorkingDays: models.Weekdays}))
+	service := newTestOrderService(repo)
+	service.settings = settings
+
+	// Claiming the second open order reaches the limit
+	_, err := service.AssignOrder(testTechnician, "2")
+	assert.NoError(t, err)
+
+	_, err = service.AssignOrder(testTechnician, "3")
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "TECHNICIAN_AT_CAPACITY")
+	_, err = service.ReviewOrder(testTechnician, "3", ReviewOrderInput{Action: "accept", Price: float64Ptr(40)})
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "TECHNICIAN_AT_CAPACITY")
+
+	// An order already claimed can still be accepted, and rejecting is never limited
+	_, err = service.ReviewOrder(testTechnician, "2", ReviewOrderInput{Action: "accept", Price: float64Ptr(40)})
+	assert.NoError(t, err)
+	feedback := "Not this week"
+	_, err = service.ReviewOrder(testTechnician, "3", ReviewOrderInput{Action: "reject", Feedback: &feedback})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,375 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/jobs"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"gorm.io/gorm"
+)
+
+// Backfill processing settings
+const (
+	BackfillPollInterval = 5 * time.Second  // how often the backfill job looks for runs to advance
+	BackfillTickBudget   = 30 * time.Second // how long one job run works before yielding
+	backfillLease        = 2 * time.Minute  // how long a run stays claimed by the instance working on it
+)
+
+// Backfill describes a one-off data fix that admins run from the API
+type Backfill struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+
+	table         string // rows are visited in ID order
+	chunkSize     int
+	needsRoleSync bool
+	step          func(s *DefaultBackfillService, afterID uint, limit int) (models.BackfillBatch, error)
+}
+
+// Backfills lists the backfills that can be run, by name
+var Backfills = []Backfill{
+	{
+		Name:        "order-price-cents",
+		Description: "Populate orders.price_cents for orders priced before the price migration started dual-writing",
+		table:       "orders",
+		chunkSize:   500,
+		step:        priceCentsStep(models.OrderPriceColumn),
+	},
+	{
+		Name:        "add-on-price-cents",
+		Description: "Populate add_ons.price_cents for add-ons priced before the price migration started dual-writing",
+		table:       "add_ons",
+		chunkSize:   500,
+		step:        priceCentsStep(models.AddOnPriceColumn),
+	},
+	{
+		Name:        "catalog-price-cents",
+		Description: "Populate catalog_designs.base_price_cents for designs priced before the price migration started dual-writing",
+		table:       "catalog_designs",
+		chunkSize:   500,
+		step:        priceCentsStep(models.CatalogBasePriceColumn),
+	},
+	{
+		Name:        "line-item-unit-price-cents",
+		Description: "Populate order_line_items.unit_price_cents for line items quoted before the price migration started dual-writing",
+		table:       "order_line_items",
+		chunkSize:   500,
+		step:        priceCentsStep(models.LineItemUnitPriceColumn),
+	},
+	{
+		Name:        "line-item-amount-cents",
+		Description: "Populate order_line_items.amount_cents for line items quoted before the price migration started dual-writing",
+		table:       "order_line_items",
+		chunkSize:   500,
+		step:        priceCentsStep(models.LineItemAmountColumn),
+	},
+	{
+		Name:        "normalize-emails",
+		Description: "Rewrite stored emails into their normalized form; accounts that would collide are skipped",
+		table:       "users",
+		chunkSize:   200,
+		step: func(s *DefaultBackfillService, afterID uint, limit int) (models.BackfillBatch, error) {
+			return s.backfills.NormalizeUserEmails(afterID, limit)
+		},
+	},
+	{
+		Name:        "encrypt-user-pii",
+		Description: "Encrypt the names and emails of users stored before PII_ENCRYPTION_KEY was set",
+		table:       "users",
+		chunkSize:   200,
+		step: func(s *DefaultBackfillService, afterID uint, limit int) (models.BackfillBatch, error) {
+			return s.backfills.EncryptUserPII(afterID, limit)
+		},
+	},
+	{
+		Name:          "sync-roles",
+		Description:   "Mirror every user's stored role to the identity provider; requires role sync to be configured",
+		table:         "users",
+		chunkSize:     50,
+		needsRoleSync: true,
+		step:          syncRolesStep,
+	},
+}
+
+// BackfillStatus is a backfill with its most recent run
+type BackfillStatus struct {
+	Backfill
+	LatestRun *models.BackfillRun `json:"latest_run"` // nil when the backfill has never run
+}
+
+// BackfillService runs named data backfills in resumable chunks
+type BackfillService interface {
+	// ListBackfills returns every backfill with its most recent run (admins only)
+	ListBackfills(admin *models.User) ([]BackfillStatus, error)
+
+	// GetBackfill returns one backfill with its most recent run, for progress (admins only)
+	GetBackfill(admin *models.User, name string) (*BackfillStatus, error)
+
+	// RunBackfill starts the backfill, or resumes its failed run from the last chunk it finished (admins only)
+	// The run is advanced in the background by the backfill job
+	RunBackfill(admin *models.User, name string) (*models.BackfillRun, error)
+
+	// AdvanceRuns works through running backfills chunk by chunk until they finish, budget elapses, or ctx is done
+	AdvanceRuns(ctx context.Context, budget time.Duration) error
+}
+
+// DefaultBackfillService implements BackfillService on top of a BackfillRepository
+type DefaultBackfillService struct {
+	backfills repositories.BackfillRepository
+	directory RoleDirectory // nil when role sync is disabled
+	now       func() time.Time
+}
+
+var backfillServiceInstance BackfillService
+
+// NewBackfillService creates a backfill service; pass a nil directory when role sync is disabled
+func NewBackfillService(backfills repositories.BackfillRepository, directory RoleDirectory) *DefaultBackfillService {
+	return &DefaultBackfillService{backfills: backfills, directory: directory, now: time.Now}
+}
+
+// GetBackfillService returns the configured backfill service
+// When none has been set, a service over the current database connection is returned
+func GetBackfillService() BackfillService {
+	if backfillServiceInstance != nil {
+		return backfillServiceInstance
+	}
+	return NewBackfillService(repositories.NewBackfillRepository(config.GetDB()), roleDirectory)
+}
+
+// SetBackfillService sets the backfill service instance (primarily for testing)
+func SetBackfillService(service BackfillService) {
+	backfillServiceInstance = service
+}
+
+// BackfillJob returns the background job that advances running backfills
+func BackfillJob(service BackfillService) jobs.Job {
+	return jobs.Job{
+		Name:     "backfills",
+		Interval: BackfillPollInterval,
+		Run: func(ctx context.Context) error {
+			return service.AdvanceRuns(ctx, BackfillTickBudget)
+		},
+	}
+}
+
+// ListBackfills returns every backfill with its most recent run (admins only)
+func (s *DefaultBackfillService) ListBackfills(admin *models.User) ([]BackfillStatus, error) {
+	if admin.Role != RoleAdmin {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only admins can manage backfills")
+	}
+
+	statuses := make([]BackfillStatus, 0, len(Backfills))
+	for _, backfill := range Backfills {
+		run, err := s.latestRun(backfill.Name)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, BackfillStatus{Backfill: backfill, LatestRun: run})
+	}
+	return statuses, nil
+}
+
+// GetBackfill returns one backfill with its most recent run, for progress (admins only)
+func (s *DefaultBackfillService) GetBackfill(admin *models.User, name string) (*BackfillStatus, error) {
+	backfill, err := findBackfill(admin, name)
+	if err != nil {
+		return nil, err
+	}
+	run, err := s.latestRun(backfill.Name)
+	if err != nil {
+		return nil, err
+	}
+	return &BackfillStatus{Backfill: *backfill, LatestRun: run}, nil
+}
+
+// RunBackfill starts the backfill, or resumes its failed run from the last chunk it finished (admins only)
+func (s *DefaultBackfillService) RunBackfill(admin *models.User, name string) (*models.BackfillRun, error) {
+	backfill, err := findBackfill(admin, name)
+	if err != nil {
+		return nil, err
+	}
+	if backfill.needsRoleSync && s.directory == nil {
+		return nil, apierror.Unprocessable("ROLE_SYNC_DISABLED", "Role sync is not configured, so there is nowhere to mirror roles to")
+	}
+
+	run, err := s.latestRun(backfill.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case run != nil && run.Status == models.BackfillRunning:
+		return nil, apierror.Conflict("BACKFILL_RUNNING", "This backfill is already running")
+	case run != nil && run.Status == models.BackfillFailed:
+		// Resume from the cursor; counts so far are kept
+		run.Status = models.BackfillRunning
+		run.LastError = nil
+		run.StartedByID = admin.ID
+	default:
+		run = &models.BackfillRun{Name: backfill.Name, Status: models.BackfillRunning, StartedByID: admin.ID}
+	}
+
+	remaining, err := s.backfills.CountAfter(backfill.table, run.Cursor)
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to estimate backfill size").Wrap(err)
+	}
+	run.Total = run.Processed + remaining
+
+	if run.ID == 0 {
+		err = s.backfills.CreateRun(run)
+	} else {
+		err = s.backfills.SaveRun(run)
+	}
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to start backfill").Wrap(err)
+	}
+	return run, nil
+}
+
+// AdvanceRuns works through running backfills chunk by chunk until they finish, budget elapses, or ctx is done
+// A run left unfinished keeps its cursor and is picked up again on the next call, by this or another instance
+func (s *DefaultBackfillService) AdvanceRuns(ctx context.Context, budget time.Duration) error {
+	deadline := s.now().Add(budget)
+
+	runs, err := s.backfills.ListRunningRuns()
+	if err != nil {
+		return fmt.Errorf("failed to list backfill runs: %w", err)
+	}
+	for i := range runs {
+		if ctx.Err() != nil || !s.now().Before(deadline) {
+			return nil
+		}
+		if err := s.advance(ctx, &runs[i], deadline); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// advance claims the run and processes chunks until it finishes, the deadline passes, or ctx is done
+// Only failures to record progress are returned; a failing chunk marks the run failed instead
+func (s *DefaultBackfillService) advance(ctx context.Context, run *models.BackfillRun, deadline time.Time) error {
+	backfill := lookupBackfill(run.Name)
+	if backfill == nil {
+		message := "unknown backfill"
+		run.Status = models.BackfillFailed
+		run.LastError = &message
+		return s.backfills.SaveRun(run)
+	}
+
+	claimed, err := s.backfills.ClaimRun(run, s.now(), s.now().Add(backfillLease))
+	if err != nil || !claimed {
+		return err
+	}
+
+	for ctx.Err() == nil && s.now().Before(deadline) {
+		batch, err := backfill.step(s, run.Cursor, backfill.chunkSize)
+		if err != nil {
+			message := err.Error()
+			run.Status = models.BackfillFailed
+			run.LastError = &message
+			break
+		}
+
+		if batch.Visited > 0 {
+			run.Cursor = batch.LastID
+		}
+		run.Processed += int64(batch.Visited)
+		run.Updated += int64(batch.Updated)
+		run.Skipped += int64(batch.Skipped)
+		if run.Processed > run.Total {
+			run.Total = run.Processed
+		}
+
+		if batch.Visited < backfill.chunkSize {
+			finishedAt := s.now()
+			run.Status = models.BackfillCompleted
+			run.FinishedAt = &finishedAt
+			run.Total = run.Processed
+			break
+		}
+
+		// Extend the lease with every chunk so that a long run is not taken over while it progresses
+		leaseUntil := s.now().Add(backfillLease)
+		run.LeaseUntil = &leaseUntil
+		if err := s.backfills.SaveRun(run); err != nil {
+			return fmt.Errorf("failed to save backfill %s progress: %w", run.Name, err)
+		}
+	}
+
+	run.LeaseUntil = nil
+	if err := s.backfills.SaveRun(run); err != nil {
+		return fmt.Errorf("failed to save backfill %s progress: %w", run.Name, err)
+	}
+	return nil
+}
+
+// latestRun loads the backfill's most recent run, or nil when it has never run
+func (s *DefaultBackfillService) latestRun(name string) (*models.BackfillRun, error) {
+	run, err := s.backfills.FindLatestRun(name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load backfill runs").Wrap(err)
+	}
+	return run, nil
+}
+
+// priceCentsStep populates a price's cents column for a chunk of rows
+func priceCentsStep(column models.PriceColumn) func(s *DefaultBackfillService, afterID uint, limit int) (models.BackfillBatch, error) {
+	return func(s *DefaultBackfillService, afterID uint, limit int) (models.BackfillBatch, error) {
+		return s.backfills.BackfillPriceCents(column, afterID, limit)
+	}
+}
+
+// syncRolesStep mirrors the stored role of a chunk of users to the role directory
+func syncRolesStep(s *DefaultBackfillService, afterID uint, limit int) (models.BackfillBatch, error) {
+	var batch models.BackfillBatch
+	if s.directory == nil {
+		return batch, errors.New("role sync is not configured")
+	}
+
+	users, err := s.backfills.ListUsersAfter(afterID, limit)
+	if err != nil {
+		return batch, err
+	}
+	for _, user := range users {
+		// A failed chunk is retried from its start when the run resumes; setting a role again is harmless
+		if err := s.directory.SetRole(user.Auth0ID, user.Role); err != nil {
+			return batch, fmt.Errorf("failed to sync role of user %d: %w", user.ID, err)
+		}
+		batch.LastID = user.ID
+		batch.Visited++
+		batch.Updated++
+	}
+	return batch, nil
+}
+
+// findBackfill checks that the caller is an admin and looks up the backfill by name
+func findBackfill(admin *models.User, name string) (*Backfill, error) {
+	if admin.Role != RoleAdmin {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only admins can manage backfills")
+	}
+	backfill := lookupBackfill(name)
+	if backfill == nil {
+		return nil, apierror.NotFound("BACKFILL_NOT_FOUND", "Backfill not found")
+	}
+	return backfill, nil
+}
+
+// lookupBackfill returns the backfill with the given name, or nil
+func lookupBackfill(name string) *Backfill {
+	for i := range Backfills {
+		if Backfills[i].Name == name {
+			return &Backfills[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"github.com/kendall-kelly/kendalls-nails-api/utils"
+	"gorm.io/gorm"
+)
+
+// calendarTokenPrefix marks calendar feed tokens so that leaked ones are recognizable
+const calendarTokenPrefix = "ncal_"
+
+// CalendarFeedHistory is how far back the feed keeps past appointments
+const CalendarFeedHistory = 30 * 24 * time.Hour
+
+// CalendarService publishes technicians' schedules as subscribable iCalendar feeds
+type CalendarService interface {
+	// IssueFeedToken creates the secret for the technician's feed URL, replacing any previous one
+	IssueFeedToken(technician *models.User) (string, error)
+
+	// RevokeFeedToken turns the technician's feed off
+	RevokeFeedToken(technician *models.User) error
+
+	// Feed renders the schedule of the technician the token belongs to as an iCalendar document
+	Feed(secret string) ([]byte, error)
+}
+
+// DefaultCalendarService implements CalendarService on top of the settings, order, and appointment repositories
+type DefaultCalendarService struct {
+	settings     repositories.TechnicianSettingsRepository
+	orders       repositories.OrderRepository
+	appointments repositories.AppointmentRepository
+	now          func() time.Time
+}
+
+var calendarServiceInstance CalendarService
+
+// NewCalendarService creates a calendar service using the given repositories
+func NewCalendarService(settings repositories.TechnicianSettingsRepository, orders repositories.OrderRepository, appointments repositories.AppointmentRepository) *DefaultCalendarService {
+	return &DefaultCalendarService{settings: settings, orders: orders, appointments: appointments, now: time.Now}
+}
+
+// GetCalendarService returns the configured calendar service
+// When none has been set, a service over the current database connection is returned
+func GetCalendarService() CalendarService {
+	if calendarServiceInstance != nil {
+		return calendarServiceInstance
+	}
+	db := config.GetDB()
+	return NewCalendarService(repositories.NewTechnicianSettingsRepository(db), repositories.NewOrderRepository(db), repositories.NewAppointmentRepository(db))
+}
+
+// SetCalendarService sets the calendar service instance (primarily for testing)
+func SetCalendarService(service CalendarService) {
+	calendarServiceInstance = service
+}
+
+// IssueFeedToken creates the secret for the technician's feed URL, replacing any previous one
+// Only a hash is stored, so the token cannot be shown again; issuing a new one is how a lost or leaked URL is replaced
+func (s *DefaultCalendarService) IssueFeedToken(technician *models.User) (string, error) {
+	if technician.Role != RoleTechnician {
+		return "", apierror.Forbidden("FORBIDDEN", "Only technicians have a calendar feed")
+	}
+	secret, err := utils.NewSecret(calendarTokenPrefix)
+	if err != nil {
+		return "", apierror.Internal("TOKEN_ERROR", "Failed to generate calendar token").Wrap(err)
+	}
+
+	settings, err := loadTechnicianSettings(s.settings, technician.ID)
+	if err != nil {
+		return "", err
+	}
+	hash := utils.HashSecret(secret)
+	settings.CalendarTokenHash = &hash
+	if err := s.settings.Save(settings); err != nil {
+		return "", apierror.Internal("DATABASE_ERROR", "Failed to save calendar token").Wrap(err)
+	}
+	return secret, nil
+}
+
+// RevokeFeedToken turns the technician's feed off; subscribed calendars stop updating
+func (s *DefaultCalendarService) RevokeFeedToken(technician *models.User) error {
+	if technician.Role != RoleTechnician {
+		return apierror.Forbidden("FORBIDDEN", "Only technicians have a calendar feed")
+	}
+	settings, err := loadTechnicianSettings(s.settings, technician.ID)
+	if err != nil {
+		return err
+	}
+	if settings.CalendarTokenHash == nil {
+		return nil
+	}
+	settings.CalendarTokenHash = nil
+	if err := s.settings.Save(settings); err != nil {
+		return apierror.Internal("DATABASE_ERROR", "Failed to revoke calendar token").Wrap(err)
+	}
+	return nil
+}
+
+// Feed renders the technician's schedule: the promised-by dates of their open orders as all-day events,
+// and their booked fittings from the last CalendarFeedHistory onwards
+func (s *DefaultCalendarService) Feed(secret string) ([]byte, error) {
+	invalid := apierror.Unauthorized("INVALID_CALENDAR_TOKEN", "Calendar token is missing or has been replaced")
+	if secret == "" {
+		return nil, invalid
+	}
+	settings, err := s.settings.FindByCalendarTokenHash(utils.HashSecret(secret))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, invalid
+		}
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load calendar token").Wrap(err)
+	}
+
+	now := s.now()
+	// Due dates are at most MaxDueDateHorizon after the order was placed, so this cutoff includes every open order
+	orders, err := s.orders.ListDueBefore(settings.UserID, OpenStatuses, startOfDay(now).Add(MaxDueDateHorizon+24*time.Hour))
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to fetch orders").Wrap(err)
+	}
+	appointments, err := s.appointments.ListAppointments(repositories.AppointmentQuery{
+		TechnicianID: settings.UserID,
+		Status:       models.AppointmentBooked,
+		From:         now.Add(-CalendarFeedHistory),
+	})
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to fetch appointments").Wrap(err)
+	}
+
+	feed := newICalendar(now)
+	for _, order := range orders {
+		if order.PromisedBy == nil {
+			continue
+		}
+		feed.allDayEvent(
+			fmt.Sprintf("order-%d-promised-by", order.ID),
+			*order.PromisedBy,
+			fmt.Sprintf("Order #%d promised by", order.ID),
+			order.Description,
+		)
+	}
+	for _, appointment := range appointments {
+		feed.timedEvent(
+			fmt.Sprintf("appointment-%d", appointment.ID),
+			appointment.StartsAt, appointment.EndsAt,
+			fmt.Sprintf("Fitting for order #%d", appointment.OrderID),
+		)
+	}
+	return feed.bytes(), nil
+}
+
+// iCalendar builds an RFC 5545 document
+type iCalendar struct {
+	stamp string
+	lines []string
+}
+
+// iCalendarUIDDomain makes event UIDs globally unique, as RFC 5545 requires
+const iCalendarUIDDomain = "kendalls-nails-api"
+
+func newICalendar(now time.Time) *iCalendar {
+	return &iCalendar{
+		stamp: now.UTC().Format("20060102T150405Z"),
+		lines: []string{
+			"BEGIN:VCALENDAR",
+			"VERSION:2.0",
+			"PRODID:-//Kendall's Nails//Technician Schedule//EN",
+			"CALSCALE:GREGORIAN",
+			"METHOD:PUBLISH",
+			"X-WR-CALNAME:Kendall's Nails schedule",
+		},
+	}
+}
+
+// allDayEvent adds an event covering the whole UTC day of date
+func (c *iCalendar) allDayEvent(uid string, date time.Time, summary, description string) {
+	day := startOfDay(date)
+	c.lines = append(c.lines,
+		"BEGIN:VEVENT",
+		"UID:"+uid+"@"+iCalendarUIDDomain,
+		"DTSTAMP:"+c.stamp,
+		"DTSTART;VALUE=DATE:"+day.Format("20060102"),
+		"DTEND;VALUE=DATE:"+day.AddDate(0, 0, 1).Format("20060102"),
+		"SUMMARY:"+escapeICalendarText(summary),
+		"DESCRIPTION:"+escapeICalendarText(description),
+		"TRANSP:TRANSPARENT",
+		"END:VEVENT",
+	)
+}
+
+// timedEvent adds an event from start to end
+func (c *iCalendar) timedEvent(uid string, start, end time.Time, summary string) {
+	c.lines = append(c.lines,
+		"BEGIN:VEVENT",
+		"UID:"+uid+"@"+iCalendarUIDDomain,
+		"DTSTAMP:"+c.stamp,
+		"DTSTART:"+start.UTC().Format("20060102T150405Z"),
+		"DTEND:"+end.UTC().Format("20060102T150405Z"),
+		"SUMMARY:"+escapeICalendarText(summary),
+		"END:VEVENT",
+	)
+}
+
+// bytes closes the calendar and returns it with CRLF line endings and long lines folded
+func (c *iCalendar) bytes() []byte {
+	var b strings.Builder
+	for _, line := range append(c.lines, "END:VCALENDAR") {
+		b.WriteString(foldICalendarLine(line))
+		b.WriteString("\r\n")
+	}
+	return []byte(b.String())
+}
+
+// escapeICalendarText escapes a TEXT value
+func escapeICalendarText(text string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`).Replace(text)
+}
+
+// foldICalendarLine splits a line into 75-octet pieces joined by CRLF and a space, without splitting a UTF-8 character
+func foldICalendarLine(line string) string {
+	const limit = 75
+	var b strings.Builder
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > limit {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	return b.String()
+}
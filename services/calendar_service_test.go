@@ -0,0 +1,79 @@
+package services
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalendarService_FeedTokens(t *testing.T) {
+	orders := newFakeOrderRepository()
+	service := NewCalendarService(newFakeTechnicianSettingsRepository(orders), orders, newFakeAppointmentRepository())
+
+	_, err := service.IssueFeedToken(testCustomer)
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+	_, err = service.Feed("")
+	assertAPIError(t, err, http.StatusUnauthorized, "INVALID_CALENDAR_TOKEN")
+
+	first, err := service.IssueFeedToken(testTechnician)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(first, "ncal_"))
+	_, err = service.Feed(first)
+	assert.NoError(t, err)
+
+	// Issuing a new token replaces the old one, and revoking turns the feed off
+	second, err := service.IssueFeedToken(testTechnician)
+	assert.NoError(t, err)
+	_, err = service.Feed(first)
+	assertAPIError(t, err, http.StatusUnauthorized, "INVALID_CALENDAR_TOKEN")
+	_, err = service.Feed(second)
+	assert.NoError(t, err)
+
+	assert.NoError(t, service.RevokeFeedToken(testTechnician))
+	_, err = service.Feed(second)
+	assertAPIError(t, err, http.StatusUnauthorized, "INVALID_CALENDAR_TOKEN")
+}
+
+func TestCalendarService_Feed(t *testing.T) {
+	promised := date(2026, 7, 10)
+	orders := newFakeOrderRepository(
+		models.Order{ID: 1, Description: "Chrome, coffin; long", Status: StatusInProduction, TechnicianID: uintPtr(testTechnician.ID), PromisedBy: &promised},
+		models.Order{ID: 2, Description: "Requested only", Status: StatusAccepted, TechnicianID: uintPtr(testTechnician.ID), RequestedBy: &promised},
+		models.Order{ID: 3, Description: "Already shipped", Status: StatusShipped, TechnicianID: uintPtr(testTechnician.ID), PromisedBy: &promised},
+		models.Order{ID: 4, Description: "Someone else's", Status: StatusAccepted, TechnicianID: uintPtr(otherTech.ID), PromisedBy: &promised},
+	)
+	appointments := newFakeAppointmentRepository()
+	start := time.Date(2026, 7, 3, 14, 30, 0, 0, time.UTC)
+	assert.NoError(t, appointments.CreateAppointment(&models.Appointment{OrderID: 1, TechnicianID: testTechnician.ID, StartsAt: start, EndsAt: start.Add(time.Hour), Status: models.AppointmentBooked}))
+	assert.NoError(t, appointments.CreateAppointment(&models.Appointment{OrderID: 1, TechnicianID: testTechnician.ID, StartsAt: start, EndsAt: start.Add(time.Hour), Status: models.AppointmentCancelled}))
+	service := NewCalendarService(newFakeTechnicianSettingsRepository(orders), orders, appointments)
+	service.now = func() time.Time { return appointmentsNow }
+
+	token, err := service.IssueFeedToken(testTechnician)
+	assert.NoError(t, err)
+	body, err := service.Feed(token)
+	assert.NoError(t, err)
+	feed := string(body)
+
+	assert.True(t, strings.HasPrefix(feed, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(feed, "END:VCALENDAR\r\n"))
+	assert.Equal(t, 2, strings.Count(feed, "BEGIN:VEVENT"))
+	assert.Contains(t, feed, "UID:order-1-promised-by@kendalls-nails-api\r\n")
+	assert.Contains(t, feed, "DTSTART;VALUE=DATE:20260710\r\nDTEND;VALUE=DATE:20260711\r\n")
+	assert.Contains(t, feed, `DESCRIPTION:Chrome\, coffin\; long`)
+	assert.Contains(t, feed, "DTSTART:20260703T143000Z\r\nDTEND:20260703T153000Z\r\nSUMMARY:Fitting for order #1\r\n")
+}
+
+func TestFoldICalendarLine(t *testing.T) {
+	assert.Equal(t, "short", foldICalendarLine("short"))
+
+	folded := foldICalendarLine("DESCRIPTION:" + strings.Repeat("é", 60))
+	for _, line := range strings.Split(folded, "\r\n") {
+		assert.LessOrEqual(t, len(line), 75)
+	}
+	assert.Equal(t, "DESCRIPTION:"+strings.Repeat("é", 60), strings.ReplaceAll(folded, "\r\n ", ""))
+}
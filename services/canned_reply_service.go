@@ -0,0 +1,243 @@
+package services
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"gorm.io/gorm"
+)
+
+// Canned reply limits
+const (
+	MaxCannedReplies          = 50
+	MaxCannedReplyTitleLength = 100  // characters
+	MaxCannedReplyTextLength  = 2000 // characters
+)
+
+// Placeholders a canned reply can use, filled in from the order it is sent on
+const (
+	PlaceholderCustomerName = "customer_name"
+	PlaceholderOrderID      = "order_id"
+)
+
+// CannedReplyPlaceholders lists the placeholders a canned reply can use, written in braces such as {customer_name}
+var CannedReplyPlaceholders = []string{PlaceholderCustomerName, PlaceholderOrderID}
+
+// placeholderPattern matches a placeholder in braces
+var placeholderPattern = regexp.MustCompile(`\{([A-Za-z0-9_]+)\}`)
+
+// CannedReplyInput holds the editable fields of a canned reply
+type CannedReplyInput struct {
+	Title string
+	Text  string
+}
+
+// CannedReplyService manages technicians' message templates
+type CannedReplyService interface {
+	// ListReplies returns the technician's canned replies
+	ListReplies(technician *models.User) ([]models.CannedReply, error)
+
+	// CreateReply saves a new canned reply for the technician
+	CreateReply(technician *models.User, input CannedReplyInput) (*models.CannedReply, error)
+
+	// UpdateReply changes one of the technician's canned replies
+	UpdateReply(technician *models.User, replyID string, input CannedReplyInput) (*models.CannedReply, error)
+
+	// DeleteReply removes one of the technician's canned replies
+	DeleteReply(technician *models.User, replyID string) error
+
+	// ExpandReply returns the text of one of the technician's canned replies with its placeholders filled in
+	// from the order; the order's customer must be loaded
+	ExpandReply(technician *models.User, replyID string, order *models.Order) (string, error)
+}
+
+// DefaultCannedReplyService implements CannedReplyService on top of a CannedReplyRepository
+type DefaultCannedReplyService struct {
+	replies repositories.CannedReplyRepository
+}
+
+var cannedReplyServiceInstance CannedReplyService
+
+// NewCannedReplyService creates a canned reply service using the given repository
+func NewCannedReplyService(replies repositories.CannedReplyRepository) *DefaultCannedReplyService {
+	return &DefaultCannedReplyService{replies: replies}
+}
+
+// GetCannedReplyService returns the configured canned reply service
+// When none has been set, a service over the current database connection is returned
+func GetCannedReplyService() CannedReplyService {
+	if cannedReplyServiceInstance != nil {
+		return cannedReplyServiceInstance
+	}
+	return NewCannedReplyService(repositories.NewCannedReplyRepository(config.GetDB()))
+}
+
+// SetCannedReplyService sets the canned reply service instance (primarily for testing)
+func SetCannedReplyService(service CannedReplyService) {
+	cannedReplyServiceInstance = service
+}
+
+// ListReplies returns the technician's canned replies, by title
+func (s *DefaultCannedReplyService) ListReplies(technician *models.User) ([]models.CannedReply, error) {
+	if err := requireTechnician(technician); err != nil {
+		return nil, err
+	}
+	replies, err := s.replies.ListForUser(technician.ID)
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to fetch canned replies").Wrap(err)
+	}
+	return replies, nil
+}
+
+// CreateReply saves a new canned reply for the technician
+func (s *DefaultCannedReplyService) CreateReply(technician *models.User, input CannedReplyInput) (*models.CannedReply, error) {
+	if err := requireTechnician(technician); err != nil {
+		return nil, err
+	}
+	input, err := validateCannedReplyInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.replies.CountForUser(technician.ID)
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to count canned replies").Wrap(err)
+	}
+	if count >= MaxCannedReplies {
+		return nil, apierror.Unprocessable("TOO_MANY_CANNED_REPLIES", "Canned reply limit reached").WithDetails(map[string]interface{}{
+			"max_canned_replies": MaxCannedReplies,
+		})
+	}
+
+	reply := &models.CannedReply{UserID: technician.ID, Title: input.Title, Text: input.Text}
+	if err := s.replies.Create(reply); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to create canned reply").Wrap(err)
+	}
+	return reply, nil
+}
+
+// UpdateReply changes one of the technician's canned replies
+// Messages already sent from it keep the text they were sent with
+func (s *DefaultCannedReplyService) UpdateReply(technician *models.User, replyID string, input CannedReplyInput) (*models.CannedReply, error) {
+	input, err := validateCannedReplyInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := s.find(technician, replyID)
+	if err != nil {
+		return nil, err
+	}
+
+	reply.Title = input.Title
+	reply.Text = input.Text
+	if err := s.replies.Save(reply); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to update canned reply").Wrap(err)
+	}
+	return reply, nil
+}
+
+// DeleteReply removes one of the technician's canned replies
+func (s *DefaultCannedReplyService) DeleteReply(technician *models.User, replyID string) error {
+	reply, err := s.find(technician, replyID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.replies.Delete(reply); err != nil {
+		return apierror.Internal("DATABASE_ERROR", "Failed to delete canned reply").Wrap(err)
+	}
+	return nil
+}
+
+// ExpandReply returns the text of one of the technician's canned replies with its placeholders filled in from the order
+func (s *DefaultCannedReplyService) ExpandReply(technician *models.User, replyID string, order *models.Order) (string, error) {
+	reply, err := s.find(technician, replyID)
+	if err != nil {
+		return "", err
+	}
+
+	values := map[string]string{
+		PlaceholderCustomerName: order.Customer.Name,
+		PlaceholderOrderID:      strconv.FormatUint(uint64(order.ID), 10),
+	}
+	return placeholderPattern.ReplaceAllStringFunc(reply.Text, func(placeholder string) string {
+		if value, ok := values[placeholder[1:len(placeholder)-1]]; ok {
+			return value
+		}
+		return placeholder
+	}), nil
+}
+
+// find loads one of the technician's canned replies by its ID parameter
+// Other users' replies are reported as not found, so their IDs are not revealed
+func (s *DefaultCannedReplyService) find(technician *models.User, replyID string) (*models.CannedReply, error) {
+	if err := requireTechnician(technician); err != nil {
+		return nil, err
+	}
+
+	id, err := strconv.ParseUint(replyID, 10, 64)
+	if err != nil || id == 0 {
+		return nil, apierror.NotFound("CANNED_REPLY_NOT_FOUND", "Canned reply not found")
+	}
+	reply, err := s.replies.FindForUser(technician.ID, uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apierror.NotFound("CANNED_REPLY_NOT_FOUND", "Canned reply not found")
+		}
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load canned reply").Wrap(err)
+	}
+	return reply, nil
+}
+
+// requireTechnician checks that canned replies are managed by a technician
+func requireTechnician(user *models.User) error {
+	if user.Role != RoleTechnician {
+		return apierror.Forbidden("FORBIDDEN", "Only technicians have canned replies")
+	}
+	return nil
+}
+
+// validateCannedReplyInput trims and checks the fields shared by create and update
+// Unknown placeholders are rejected so that a typo doesn't reach a customer as "{custmer_name}"
+func validateCannedReplyInput(input CannedReplyInput) (CannedReplyInput, error) {
+	input.Title = strings.TrimSpace(input.Title)
+	input.Text = strings.TrimSpace(input.Text)
+
+	if input.Title == "" {
+		return input, apierror.Validation("Title is required", map[string]interface{}{"field": "title"})
+	}
+	if utf8.RuneCountInString(input.Title) > MaxCannedReplyTitleLength {
+		return input, apierror.Validation("Title is too long", map[string]interface{}{
+			"field":      "title",
+			"max_length": MaxCannedReplyTitleLength,
+		})
+	}
+	if input.Text == "" {
+		return input, apierror.Validation("Text is required", map[string]interface{}{"field": "text"})
+	}
+	if utf8.RuneCountInString(input.Text) > MaxCannedReplyTextLength {
+		return input, apierror.Validation("Text is too long", map[string]interface{}{
+			"field":      "text",
+			"max_length": MaxCannedReplyTextLength,
+		})
+	}
+
+	for _, match := range placeholderPattern.FindAllStringSubmatch(input.Text, -1) {
+		if !slices.Contains(CannedReplyPlaceholders, match[1]) {
+			return input, apierror.Validation("Text uses an unknown placeholder", map[string]interface{}{
+				"placeholder":  match[0],
+				"placeholders": CannedReplyPlaceholders,
+			})
+		}
+	}
+	return input, nil
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// fakeCannedReplyRepository is an in-memory CannedReplyRepository
+type fakeCannedReplyRepository struct {
+	replies map[uint]*models.CannedReply
+	nextID  uint
+}
+
+func newFakeCannedReplyRepository() *fakeCannedReplyRepository {
+	return &fakeCannedReplyRepository{replies: make(map[uint]*models.CannedReply), nextID: 1}
+}
+
+func (r *fakeCannedReplyRepository) Create(reply *models.CannedReply) error {
+	reply.ID = r.nextID
+	r.nextID++
+	stored := *reply
+	r.replies[reply.ID] = &stored
+	return nil
+}
+
+func (r *fakeCannedReplyRepository) Save(reply *models.CannedReply) error {
+	stored := *reply
+	r.replies[reply.ID] = &stored
+	return nil
+}
+
+func (r *fakeCannedReplyRepository) Delete(reply *models.CannedReply) error {
+	delete(r.replies, reply.ID)
+	return nil
+}
+
+func (r *fakeCannedReplyRepository) FindForUser(userID, id uint) (*models.CannedReply, error) {
+	reply, ok := r.replies[id]
+	if !ok || reply.UserID != userID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	found := *reply
+	return &found, nil
+}
+
+func (r *fakeCannedReplyRepository) ListForUser(userID uint) ([]models.CannedReply, error) {
+	var replies []models.CannedReply
+	for id := uint(1); id < r.nextID; id++ {
+		if reply, ok := r.replies[id]; ok && reply.UserID == userID {
+			replies = append(replies, *reply)
+		}
+	}
+	return replies, nil
+}
+
+func (r *fakeCannedReplyRepository) CountForUser(userID uint) (int64, error) {
+	replies, _ := r.ListForUser(userID)
+	return int64(len(replies)), nil
+}
+
+func TestCannedReplyService_CRUD(t *testing.T) {
+	service := NewCannedReplyService(newFakeCannedReplyRepository())
+
+	_, err := service.CreateReply(testCustomer, CannedReplyInput{Title: "Shipping", Text: "Your set ships Friday"})
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+
+	reply, err := service.CreateReply(testTechnician, CannedReplyInput{Title: " Shipping ", Text: "Your set ships Friday"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Shipping", reply.Title)
+	assert.Equal(t, testTechnician.ID, reply.UserID)
+
+	updated, err := service.UpdateReply(testTechnician, uintString(reply.ID), CannedReplyInput{Title: "Shipping", Text: "Your set ships Monday"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Your set ships Monday", updated.Text)
+
+	// Other technicians' replies do not exist for them
+	_, err = service.UpdateReply(otherTech, uintString(reply.ID), CannedReplyInput{Title: "Mine", Text: "Mine now"})
+	assertAPIError(t, err, http.StatusNotFound, "CANNED_REPLY_NOT_FOUND")
+	assertAPIError(t, service.DeleteReply(otherTech, uintString(reply.ID)), http.StatusNotFound, "CANNED_REPLY_NOT_FOUND")
+	others, err := service.ListReplies(otherTech)
+	assert.NoError(t, err)
+	assert.Empty(t, others)
+
+	assert.NoError(t, service.DeleteReply(testTechnician, uintString(reply.ID)))
+	replies, err := service.ListReplies(testTechnician)
+	assert.NoError(t, err)
+	assert.Empty(t, replies)
+}
+
+func TestCannedReplyService_Validation(t *testing.T) {
+	service := NewCannedReplyService(newFakeCannedReplyRepository())
+
+	for _, input := range []CannedReplyInput{
+		{Title: " ", Text: "Hello"},
+		{Title: "Hello", Text: " "},
+		{Title: strings.Repeat("a", MaxCannedReplyTitleLength+1), Text: "Hello"},
+		{Title: "Hello", Text: strings.Repeat("a", MaxCannedReplyTextLength+1)},
+		{Title: "Typo", Text: "Hi {custmer_name}"},
+	} {
+		_, err := service.CreateReply(testTechnician, input)
+		assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+	}
+
+	for i := 0; i < MaxCannedReplies; i++ {
+		_, err := service.CreateReply(testTechnician, CannedReplyInput{Title: "Reply", Text: "Hello"})
+		assert.NoError(t, err)
+	}
+	_, err := service.CreateReply(testTechnician, CannedReplyInput{Title: "One more", Text: "Hello"})
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "TOO_MANY_CANNED_REPLIES")
+}
+
+func TestCannedReplyService_ExpandReply(t *testing.T) {
+	service := NewCannedReplyService(newFakeCannedReplyRepository())
+	reply, err := service.CreateReply(testTechnician, CannedReplyInput{Title: "Shipping", Text: "Hi {customer_name}, order #{order_id} ships Friday!"})
+	assert.NoError(t, err)
+
+	order := &models.Order{ID: 42, CustomerID: testCustomer.ID, Customer: models.User{Name: "Casey"}}
+	text, err := service.ExpandReply(testTechnician, uintString(reply.ID), order)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hi Casey, order #42 ships Friday!", text)
+
+	_, err = service.ExpandReply(otherTech, uintString(reply.ID), order)
+	assertAPIError(t, err, http.StatusNotFound, "CANNED_REPLY_NOT_FOUND")
+	_, err = service.ExpandReply(testTechnician, "latest", order)
+	assertAPIError(t, err, http.StatusNotFound, "CANNED_REPLY_NOT_FOUND")
+}
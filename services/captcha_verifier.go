@@ -0,0 +1,71 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+)
+
+// CaptchaVerifier checks the CAPTCHA tokens visitors solve before submitting public forms
+type CaptchaVerifier interface {
+	// Verify reports whether a token was issued for a solved challenge; remoteIP may be empty
+	Verify(token, remoteIP string) (bool, error)
+}
+
+// HTTPCaptchaVerifier implements CaptchaVerifier against a siteverify endpoint
+// Cloudflare Turnstile, hCaptcha, and reCAPTCHA share the protocol: a form post of secret, response,
+// and remoteip answered with {"success": bool}
+type HTTPCaptchaVerifier struct {
+	url        string
+	secret     string
+	httpClient *http.Client
+}
+
+// captchaResponse is the answer of a siteverify endpoint
+type captchaResponse struct {
+	Success bool `json:"success"`
+}
+
+// NewHTTPCaptchaVerifier creates a verifier for the configured siteverify endpoint
+func NewHTTPCaptchaVerifier(cfg *config.Config) *HTTPCaptchaVerifier {
+	return &HTTPCaptchaVerifier{
+		url:        cfg.CaptchaVerifyURL,
+		secret:     cfg.CaptchaSecret,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Verify posts a token to the siteverify endpoint and reports whether it was accepted
+func (v *HTTPCaptchaVerifier) Verify(token, remoteIP string) (bool, error) {
+	form := url.Values{"secret": {v.secret}, "response": {token}}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+	req, err := http.NewRequest(http.MethodPost, v.url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to call CAPTCHA endpoint: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return false, fmt.Errorf("CAPTCHA endpoint returned status %d: %s", resp.StatusCode, string(detail))
+	}
+	var verdict captchaResponse
+	if err := json.NewDecoder(resp.Body).Decode(&verdict); err != nil {
+		return false, fmt.Errorf("failed to decode CAPTCHA response: %w", err)
+	}
+	return verdict.Success, nil
+}
@@ -0,0 +1,293 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"gorm.io/gorm"
+)
+
+// MaxCatalogDesignImages caps how many photos one catalog design can have
+const MaxCatalogDesignImages = 10
+
+// CatalogDesignInput holds the editable fields of a catalog design
+type CatalogDesignInput struct {
+	Name        string
+	Description string
+	BasePrice   float64
+	Sizes       []string
+}
+
+// CatalogService manages the admin-maintained catalog of pre-designed nail sets
+type CatalogService interface {
+	// ListDesigns returns the current catalog (public)
+	ListDesigns() ([]models.CatalogDesign, error)
+
+	// GetDesign returns one design from the current catalog (public)
+	GetDesign(designID string) (*models.CatalogDesign, error)
+
+	// CreateDesign adds a design to the catalog (admins only)
+	CreateDesign(admin *models.User, input CatalogDesignInput) (*models.CatalogDesign, error)
+
+	// UpdateDesign changes a design (admins only)
+	UpdateDesign(admin *models.User, designID string, input CatalogDesignInput) (*models.CatalogDesign, error)
+
+	// DeleteDesign retires a design (admins only)
+	DeleteDesign(admin *models.User, designID string) error
+
+	// AddImage attaches an uploaded photo to a design (admins only)
+	AddImage(admin *models.User, designID string, imageS3Key string) (*models.CatalogDesignImage, error)
+
+	// DeleteImage removes a photo from a design and returns it so its file can be deleted (admins only)
+	DeleteImage(admin *models.User, designID string, imageID string) (*models.CatalogDesignImage, error)
+}
+
+// DefaultCatalogService implements CatalogService on top of a CatalogRepository
+type DefaultCatalogService struct {
+	catalog repositories.CatalogRepository
+	audit   repositories.AuditLogRepository // nil records nothing
+}
+
+var catalogServiceInstance CatalogService
+
+// NewCatalogService creates a catalog service using the given repository
+func NewCatalogService(catalog repositories.CatalogRepository) *DefaultCatalogService {
+	return &DefaultCatalogService{catalog: catalog}
+}
+
+// GetCatalogService returns the configured catalog service
+// When none has been set, a service over the current database connection is returned
+func GetCatalogService() CatalogService {
+	if catalogServiceInstance != nil {
+		return catalogServiceInstance
+	}
+	db := config.GetDB()
+	service := NewCatalogService(repositories.NewCatalogRepository(db))
+	service.audit = repositories.NewAuditLogRepository(db)
+	return service
+}
+
+// SetCatalogService sets the catalog service instance (primarily for testing)
+func SetCatalogService(service CatalogService) {
+	catalogServiceInstance = service
+}
+
+// ListDesigns returns the current catalog
+func (s *DefaultCatalogService) ListDesigns() ([]models.CatalogDesign, error) {
+	designs, err := s.catalog.List()
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to fetch catalog").Wrap(err)
+	}
+	return designs, nil
+}
+
+// GetDesign returns one design from the current catalog
+func (s *DefaultCatalogService) GetDesign(designID string) (*models.CatalogDesign, error) {
+	return s.find(designID)
+}
+
+// authorizeManage checks that the user may change the catalog
+func (s *DefaultCatalogService) authorizeManage(user *models.User) error {
+	if user.Role != RoleAdmin {
+		return apierror.Forbidden("FORBIDDEN", "Only admins can manage the catalog")
+	}
+	return nil
+}
+
+// CreateDesign adds a design to the catalog
+func (s *DefaultCatalogService) CreateDesign(admin *models.User, input CatalogDesignInput) (*models.CatalogDesign, error) {
+	if err := s.authorizeManage(admin); err != nil {
+		return nil, err
+	}
+	input, err := normalizeCatalogDesignInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	design := &models.CatalogDesign{
+		Name:        input.Name,
+		Description: input.Description,
+		BasePrice:   input.BasePrice,
+		Currency:    studioCurrency(),
+		Sizes:       input.Sizes,
+		Images:      []models.CatalogDesignImage{},
+		CreatedByID: admin.ID,
+	}
+	if err := s.catalog.Create(design); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to create catalog design").Wrap(err)
+	}
+	if err := recordAudit(s.audit, admin, models.AuditCatalogPriceSet, models.AuditTargetCatalogDesign, design.ID, nil, catalogPrice(design)); err != nil {
+		return nil, err
+	}
+	return design, nil
+}
+
+// UpdateDesign changes a design
+// Orders already placed from it keep the description and price they were placed with
+func (s *DefaultCatalogService) UpdateDesign(admin *models.User, designID string, input CatalogDesignInput) (*models.CatalogDesign, error) {
+	if err := s.authorizeManage(admin); err != nil {
+		return nil, err
+	}
+	input, err := normalizeCatalogDesignInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	design, err := s.find(designID)
+	if err != nil {
+		return nil, err
+	}
+
+	before := catalogPrice(design)
+	design.Name = input.Name
+	design.Description = input.Description
+	design.BasePrice = input.BasePrice
+	design.Currency = studioCurrency()
+	design.Sizes = input.Sizes
+
+	if err := s.catalog.Save(design); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to update catalog design").Wrap(err)
+	}
+	if after := catalogPrice(design); auditChanged(before, after) {
+		if err := recordAudit(s.audit, admin, models.AuditCatalogPriceSet, models.AuditTargetCatalogDesign, design.ID, before, after); err != nil {
+			return nil, err
+		}
+	}
+	return design, nil
+}
+
+// DeleteDesign retires a design
+// Its photos are kept, since orders placed from it still reference the design
+func (s *DefaultCatalogService) DeleteDesign(admin *models.User, designID string) error {
+	if err := s.authorizeManage(admin); err != nil {
+		return err
+	}
+
+	design, err := s.find(designID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.catalog.Delete(design); err != nil {
+		return apierror.Internal("DATABASE_ERROR", "Failed to delete catalog design").Wrap(err)
+	}
+	return nil
+}
+
+// AddImage attaches an uploaded photo to a design, after its existing photos
+func (s *DefaultCatalogService) AddImage(admin *models.User, designID string, imageS3Key string) (*models.CatalogDesignImage, error) {
+	if err := s.authorizeManage(admin); err != nil {
+		return nil, err
+	}
+
+	design, err := s.find(designID)
+	if err != nil {
+		return nil, err
+	}
+	if len(design.Images) >= MaxCatalogDesignImages {
+		return nil, apierror.Unprocessable("TOO_MANY_IMAGES", "A catalog design can have at most "+strconv.Itoa(MaxCatalogDesignImages)+" images")
+	}
+
+	image := &models.CatalogDesignImage{DesignID: design.ID, ImageS3Key: imageS3Key}
+	if err := s.catalog.AddImage(image); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to add catalog image").Wrap(err)
+	}
+	return image, nil
+}
+
+// DeleteImage removes a photo from a design
+func (s *DefaultCatalogService) DeleteImage(admin *models.User, designID string, imageID string) (*models.CatalogDesignImage, error) {
+	if err := s.authorizeManage(admin); err != nil {
+		return nil, err
+	}
+
+	design, err := s.find(designID)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := strconv.ParseUint(imageID, 10, 64)
+	if err != nil || id == 0 {
+		return nil, apierror.NotFound("CATALOG_IMAGE_NOT_FOUND", "Catalog image not found")
+	}
+	image, err := s.catalog.FindImage(design.ID, uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apierror.NotFound("CATALOG_IMAGE_NOT_FOUND", "Catalog image not found")
+		}
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load catalog image").Wrap(err)
+	}
+
+	if err := s.catalog.DeleteImage(image); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to delete catalog image").Wrap(err)
+	}
+	return image, nil
+}
+
+// catalogPrice returns the audited price fields of a design
+func catalogPrice(design *models.CatalogDesign) map[string]interface{} {
+	return map[string]interface{}{"base_price": design.BasePrice, "currency": design.Currency}
+}
+
+// find loads a design by its path parameter
+func (s *DefaultCatalogService) find(designID string) (*models.CatalogDesign, error) {
+	id, err := strconv.ParseUint(designID, 10, 64)
+	if err != nil || id == 0 {
+		return nil, apierror.NotFound("CATALOG_DESIGN_NOT_FOUND", "Catalog design not found")
+	}
+	return findCatalogDesign(s.catalog, uint(id))
+}
+
+// findCatalogDesign loads a design that has not been retired
+func findCatalogDesign(catalog repositories.CatalogRepository, id uint) (*models.CatalogDesign, error) {
+	design, err := catalog.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apierror.NotFound("CATALOG_DESIGN_NOT_FOUND", "Catalog design not found")
+		}
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load catalog design").Wrap(err)
+	}
+	return design, nil
+}
+
+// normalizeCatalogDesignInput trims and checks the fields shared by create and update
+// Sizes are trimmed and deduplicated, keeping their order
+func normalizeCatalogDesignInput(input CatalogDesignInput) (CatalogDesignInput, error) {
+	input.Name = strings.TrimSpace(input.Name)
+	input.Description = strings.TrimSpace(input.Description)
+	if input.Name == "" {
+		return input, apierror.Validation("Name is required", nil)
+	}
+	if input.Description == "" {
+		return input, apierror.Validation("Description is required", nil)
+	}
+	if input.BasePrice <= 0 {
+		return input, apierror.Validation("Base price must be greater than zero", nil)
+	}
+	if err := requireWholeCents("Base price", input.BasePrice); err != nil {
+		return input, err
+	}
+
+	sizes := make([]string, 0, len(input.Sizes))
+	seen := make(map[string]bool, len(input.Sizes))
+	for _, size := range input.Sizes {
+		size = strings.TrimSpace(size)
+		if size == "" {
+			return input, apierror.Validation("Sizes cannot be blank", nil)
+		}
+		if !seen[size] {
+			seen[size] = true
+			sizes = append(sizes, size)
+		}
+	}
+	if len(sizes) == 0 {
+		return input, apierror.Validation("At least one size is required", nil)
+	}
+	input.Sizes = sizes
+	return input, nil
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"net/http"
+	"sort"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// fakeCatalogRepository is an in-memory CatalogRepository
+type fakeCatalogRepository struct {
+	designs map[uint]*models.CatalogDesign
+	nextID  uint
+	imageID uint
+}
+
+func newFakeCatalogRepository(designs ...models.CatalogDesign) *fakeCatalogRepository {
+	repo := &fakeCatalogRepository{designs: make(map[uint]*models.CatalogDesign), nextID: 1, imageID: 1}
+	for i := range designs {
+		design := designs[i]
+		if design.ID >= repo.nextID {
+			repo.nextID = design.ID + 1
+		}
+		repo.designs[design.ID] = &design
+	}
+	return repo
+}
+
+func (r *fakeCatalogRepository) Create(design *models.CatalogDesign) error {
+	design.ID = r.nextID
+	r.nextID++
+	stored := *design
+	r.designs[design.ID] = &stored
+	return nil
+}
+
+func (r *fakeCatalogRepository) Save(design *models.CatalogDesign) error {
+	stored := *design
+	stored.Images = r.designs[design.ID].Images
+	r.designs[design.ID] = &stored
+	return nil
+}
+
+func (r *fakeCatalogRepository) Delete(design *models.CatalogDesign) error {
+	delete(r.designs, design.ID)
+	return nil
+}
+
+func (r *fakeCatalogRepository) FindByID(id uint) (*models.CatalogDesign, error) {
+	design, ok := r.designs[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	found := *design
+	found.Images = append([]models.CatalogDesignImage(nil), design.Images...)
+	return &found, nil
+}
+
+func (r *fakeCatalogRepository) List() ([]models.CatalogDesign, error) {
+	var designs []models.CatalogDesign
+	for _, design := range r.designs {
+		designs = append(designs, *design)
+	}
+	sort.Slice(designs, func(i, j int) bool { return designs[i].Name < designs[j].Name })
+	return designs, nil
+}
+
+func (r *fakeCatalogRepository) AddImage(image *models.CatalogDesignImage) error {
+	design := r.designs[image.DesignID]
+	image.ID = r.imageID
+	r.imageID++
+	image.Position = len(design.Images)
+	design.Images = append(design.Images, *image)
+	return nil
+}
+
+func (r *fakeCatalogRepository) FindImage(designID, imageID uint) (*models.CatalogDesignImage, error) {
+	if design, ok := r.designs[designID]; ok {
+		for _, image := range design.Images {
+			if image.ID == imageID {
+				return &image, nil
+			}
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeCatalogRepository) DeleteImage(image *models.CatalogDesignImage) error {
+	design := r.designs[image.DesignID]
+	for i := range design.Images {
+		if design.Images[i].ID == image.ID {
+			design.Images = append(design.Images[:i], design.Images[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func TestCatalogService_AdminManagesDesigns(t *testing.T) {
+	admin := &models.User{ID: 9, Role: RoleAdmin}
+	service := NewCatalogService(newFakeCatalogRepository())
+	input := CatalogDesignInput{Name: "Chrome coffin", Description: "Mirror chrome on long coffin tips", BasePrice: 55, Sizes: []string{" S ", "M", "S"}}
+
+	_, err := service.CreateDesign(testTechnician, input)
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+
+	design, err := service.CreateDesign(admin, input)
+	assert.NoError(t, err)
+	assert.Equal(t, admin.ID, design.CreatedByID)
+	assert.Equal(t, []string{"S", "M"}, design.Sizes)
+
+	input.BasePrice = 60
+	updated, err := service.UpdateDesign(admin, uintString(design.ID), input)
+	assert.NoError(t, err)
+	assert.Equal(t, 60.0, updated.BasePrice)
+
+	_, err = service.UpdateDesign(admin, "abc", input)
+	assertAPIError(t, err, http.StatusNotFound, "CATALOG_DESIGN_NOT_FOUND")
+
+	designs, err := service.ListDesigns()
+	assert.NoError(t, err)
+	assert.Len(t, designs, 1)
+
+	assertAPIError(t, service.DeleteDesign(testCustomer, uintString(design.ID)), http.StatusForbidden, "FORBIDDEN")
+	assert.NoError(t, service.DeleteDesign(admin, uintString(design.ID)))
+	_, err = service.GetDesign(uintString(design.ID))
+	assertAPIError(t, err, http.StatusNotFound, "CATALOG_DESIGN_NOT_FOUND")
+}
+
+func TestCatalogService_ValidatesDesigns(t *testing.T) {
+	admin := &models.User{ID: 9, Role: RoleAdmin}
+	service := NewCatalogService(newFakeCatalogRepository())
+
+	invalid := []CatalogDesignInput{
+		{Name: " ", Description: "Set", BasePrice: 10, Sizes: []string{"M"}},
+		{Name: "Set", Description: "", BasePrice: 10, Sizes: []string{"M"}},
+		{Name: "Set", Description: "Set", BasePrice: 0, Sizes: []string{"M"}},
+		{Name: "Set", Description: "Set", BasePrice: 10},
+		{Name: "Set", Description: "Set", BasePrice: 10, Sizes: []string{"M", " "}},
+	}
+	for _, input := range invalid {
+		_, err := service.CreateDesign(admin, input)
+		assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+	}
+}
+
+func TestCatalogService_Images(t *testing.T) {
+	admin := &models.User{ID: 9, Role: RoleAdmin}
+	repo := newFakeCatalogRepository(models.CatalogDesign{ID: 1, Name: "French", Description: "Classic French tips", BasePrice: 40, Sizes: []string{"M"}})
+	service := NewCatalogService(repo)
+
+	first, err := service.AddImage(admin, "1", "designs/first.jpg")
+	assert.NoError(t, err)
+	second, err := service.AddImage(admin, "1", "designs/second.jpg")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, first.Position)
+	assert.Equal(t, 1, second.Position)
+
+	_, err = service.AddImage(testTechnician, "1", "designs/third.jpg")
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+
+	removed, err := service.DeleteImage(admin, "1", uintString(first.ID))
+	assert.NoError(t, err)
+	assert.Equal(t, "designs/first.jpg", removed.ImageS3Key)
+
+	_, err = service.DeleteImage(admin, "1", uintString(first.ID))
+	assertAPIError(t, err, http.StatusNotFound, "CATALOG_IMAGE_NOT_FOUND")
+
+	for i := len(repo.designs[1].Images); i < MaxCatalogDesignImages; i++ {
+		_, err := service.AddImage(admin, "1", "designs/more.jpg")
+		assert.NoError(t, err)
+	}
+	_, err = service.AddImage(admin, "1", "designs/one-too-many.jpg")
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "TOO_MANY_IMAGES")
+}
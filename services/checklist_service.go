@@ -0,0 +1,144 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"gorm.io/gorm"
+)
+
+// MaxChecklistItems limits the length of a checklist template
+const MaxChecklistItems = 50
+
+// ChecklistService manages production checklist templates and order checklists
+type ChecklistService interface {
+	// GetTemplate returns the technician's checklist template
+	GetTemplate(technician *models.User) ([]models.ChecklistTemplateItem, error)
+
+	// ReplaceTemplate sets the technician's checklist template to the given steps
+	// Orders already in production keep the checklist they started with
+	ReplaceTemplate(technician *models.User, labels []string) ([]models.ChecklistTemplateItem, error)
+
+	// SetItemCompleted checks or unchecks an item on an order the technician is producing
+	SetItemCompleted(technician *models.User, orderID string, itemID string, completed bool) (*models.Order, error)
+}
+
+// DefaultChecklistService implements ChecklistService on top of the order and checklist repositories
+type DefaultChecklistService struct {
+	orders     repositories.OrderRepository
+	checklists repositories.ChecklistRepository
+}
+
+var checklistServiceInstance ChecklistService
+
+// NewChecklistService creates a checklist service using the given repositories
+func NewChecklistService(orders repositories.OrderRepository, checklists repositories.ChecklistRepository) *DefaultChecklistService {
+	return &DefaultChecklistService{orders: orders, checklists: checklists}
+}
+
+// GetChecklistService returns the configured checklist service
+// When none has been set, a service over the current database connection is returned
+func GetChecklistService() ChecklistService {
+	if checklistServiceInstance != nil {
+		return checklistServiceInstance
+	}
+	db := config.GetDB()
+	return NewChecklistService(repositories.NewOrderRepository(db), repositories.NewChecklistRepository(db))
+}
+
+// SetChecklistService sets the checklist service instance (primarily for testing)
+func SetChecklistService(service ChecklistService) {
+	checklistServiceInstance = service
+}
+
+// GetTemplate returns the technician's checklist template
+func (s *DefaultChecklistService) GetTemplate(technician *models.User) ([]models.ChecklistTemplateItem, error) {
+	if technician.Role != RoleTechnician {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only technicians have checklist templates")
+	}
+
+	items, err := s.checklists.ListTemplate(technician.ID)
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load checklist template").Wrap(err)
+	}
+	return items, nil
+}
+
+// ReplaceTemplate sets the technician's checklist template to the given steps
+func (s *DefaultChecklistService) ReplaceTemplate(technician *models.User, labels []string) ([]models.ChecklistTemplateItem, error) {
+	if technician.Role != RoleTechnician {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only technicians have checklist templates")
+	}
+	if len(labels) > MaxChecklistItems {
+		return nil, apierror.Validation("Checklist template has too many items", map[string]interface{}{
+			"max_items": MaxChecklistItems,
+		})
+	}
+
+	items := make([]models.ChecklistTemplateItem, len(labels))
+	for i, label := range labels {
+		label = strings.TrimSpace(label)
+		if label == "" {
+			return nil, apierror.Validation("Checklist items cannot be blank", nil)
+		}
+		items[i] = models.ChecklistTemplateItem{TechnicianID: technician.ID, Label: label, Position: i + 1}
+	}
+
+	if err := s.checklists.ReplaceTemplate(technician.ID, items); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to save checklist template").Wrap(err)
+	}
+	return s.GetTemplate(technician)
+}
+
+// SetItemCompleted checks or unchecks an item on an order the technician is producing
+func (s *DefaultChecklistService) SetItemCompleted(technician *models.User, orderID string, itemID string, completed bool) (*models.Order, error) {
+	if technician.Role != RoleTechnician {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only technicians can update checklists")
+	}
+
+	order, err := findOrder(s.orders, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsAssignedTo(order, technician) {
+		return nil, apierror.Forbidden("FORBIDDEN", "You can only update checklists of orders assigned to you")
+	}
+	if order.Status != StatusInProduction {
+		return nil, apierror.Unprocessable("INVALID_STATE", "Checklists can only be updated while an order is in production")
+	}
+
+	id, err := strconv.ParseUint(itemID, 10, 64)
+	if err != nil || id == 0 {
+		return nil, apierror.NotFound("CHECKLIST_ITEM_NOT_FOUND", "Checklist item not found")
+	}
+	item, err := s.checklists.FindItem(order.ID, uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apierror.NotFound("CHECKLIST_ITEM_NOT_FOUND", "Checklist item not found")
+		}
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load checklist item").Wrap(err)
+	}
+
+	item.Completed = completed
+	if completed {
+		now := time.Now()
+		item.CompletedAt = &now
+		item.CompletedByID = &technician.ID
+	} else {
+		item.CompletedAt = nil
+		item.CompletedByID = nil
+	}
+
+	if err := s.checklists.SaveItem(item); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to update checklist item").Wrap(err)
+	}
+
+	return reloadOrder(s.orders, order.ID)
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"net/http"
+	"sort"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// fakeChecklistRepository is an in-memory ChecklistRepository
+type fakeChecklistRepository struct {
+	templates map[uint][]models.ChecklistTemplateItem
+	items     map[uint]*models.OrderChecklistItem
+	nextID    uint
+}
+
+func newFakeChecklistRepository() *fakeChecklistRepository {
+	return &fakeChecklistRepository{
+		templates: make(map[uint][]models.ChecklistTemplateItem),
+		items:     make(map[uint]*models.OrderChecklistItem),
+		nextID:    1,
+	}
+}
+
+func (r *fakeChecklistRepository) ListTemplate(technicianID uint) ([]models.ChecklistTemplateItem, error) {
+	return r.templates[technicianID], nil
+}
+
+func (r *fakeChecklistRepository) ReplaceTemplate(technicianID uint, items []models.ChecklistTemplateItem) error {
+	r.templates[technicianID] = items
+	return nil
+}
+
+func (r *fakeChecklistRepository) ListForOrder(orderID uint) ([]models.OrderChecklistItem, error) {
+	var items []models.OrderChecklistItem
+	for _, item := range r.items {
+		if item.OrderID == orderID {
+			items = append(items, *item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Position < items[j].Position })
+	return items, nil
+}
+
+func (r *fakeChecklistRepository) CreateForOrder(items []models.OrderChecklistItem) error {
+	for i := range items {
+		item := items[i]
+		item.ID = r.nextID
+		r.nextID++
+		r.items[item.ID] = &item
+	}
+	return nil
+}
+
+func (r *fakeChecklistRepository) FindItem(orderID, itemID uint) (*models.OrderChecklistItem, error) {
+	item, ok := r.items[itemID]
+	if !ok || item.OrderID != orderID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	found := *item
+	return &found, nil
+}
+
+func (r *fakeChecklistRepository) SaveItem(item *models.OrderChecklistItem) error {
+	stored := *item
+	r.items[item.ID] = &stored
+	return nil
+}
+
+func TestChecklistService_ReplaceTemplate(t *testing.T) {
+	service := NewChecklistService(newFakeOrderRepository(), newFakeChecklistRepository())
+
+	_, err := service.ReplaceTemplate(testCustomer, []string{"Prep"})
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+
+	_, err = service.ReplaceTemplate(testTechnician, []string{"Prep", "  "})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+
+	items, err := service.ReplaceTemplate(testTechnician, []string{" Prep ", "Paint", "Seal"})
+	assert.NoError(t, err)
+	if assert.Len(t, items, 3) {
+		assert.Equal(t, "Prep", items[0].Label)
+		assert.Equal(t, 3, items[2].Position)
+	}
+}
+
+func TestChecklistWorkflow(t *testing.T) {
+	orders := newFakeOrderRepository(
+		models.Order{ID: 1, CustomerID: testCustomer.ID, Status: StatusAccepted, TechnicianID: uintPtr(testTechnician.ID)},
+	)
+	checklists := newFakeChecklistRepository()
+	orderService := NewOrderService(orders, newFakeAddOnRepository(), checklists, nil, nil, nil, nil, nil)
+	checklistService := NewChecklistService(orders, checklists)
+
+	_, err := checklistService.ReplaceTemplate(testTechnician, []string{"Prep", "Paint"})
+	assert.NoError(t, err)
+
+	// Items cannot be checked before production starts
+	_, err = checklistService.SetItemCompleted(testTechnician, "1", "1", true)
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "INVALID_STATE")
+
+	// Starting production copies the template onto the order
+	_, err = orderService.UpdateOrderStatus(testTechnician, "1", StatusInProduction, 0)
+	assert.NoError(t, err)
+	items, _ := checklists.ListForOrder(1)
+	assert.Len(t, items, 2)
+
+	// Shipping is blocked until every item is checked
+	_, err = orderService.UpdateOrderStatus(testTechnician, "1", StatusShipped, 0)
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "CHECKLIST_INCOMPLETE")
+
+	_, err = checklistService.SetItemCompleted(otherTech, "1", "1", true)
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+	_, err = checklistService.SetItemCompleted(testTechnician, "1", "99", true)
+	assertAPIError(t, err, http.StatusNotFound, "CHECKLIST_ITEM_NOT_FOUND")
+
+	for _, item := range items {
+		_, err = checklistService.SetItemCompleted(testTechnician, "1", uintString(item.ID), true)
+		assert.NoError(t, err)
+	}
+	items, _ = checklists.ListForOrder(1)
+	assert.Equal(t, testTechnician.ID, *items[0].CompletedByID)
+	assert.NotNil(t, items[0].CompletedAt)
+
+	order, err := orderService.UpdateOrderStatus(testTechnician, "1", StatusShipped, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusShipped, order.Status)
+}
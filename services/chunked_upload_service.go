@@ -0,0 +1,352 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/jobs"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"github.com/kendall-kelly/kendalls-nails-api/utils"
+	"gorm.io/gorm"
+)
+
+// ChunkPrefix is the key prefix the chunks of unfinished uploads are stored under
+const ChunkPrefix = "chunks/"
+
+// MaxChunkBytes is the largest chunk accepted in one request
+const MaxChunkBytes = 5 << 20
+
+// ChunkedUploadExpiry is how long an upload may take to finish before its chunks are deleted
+const ChunkedUploadExpiry = 24 * time.Hour
+
+// ExpiredUploadCheckInterval is how often expired uploads are cleaned up
+const ExpiredUploadCheckInterval = time.Hour
+
+// expiredUploadBatchSize is how many expired uploads are removed per query
+const expiredUploadBatchSize = 100
+
+// StartUploadInput describes an image about to be uploaded in chunks
+type StartUploadInput struct {
+	Filename string
+	Size     int64 // total bytes
+}
+
+// ChunkedUploadService lets customers upload design images in pieces and resume interrupted uploads
+type ChunkedUploadService interface {
+	// StartUpload begins an upload of an image of the given size (customers only)
+	StartUpload(customer *models.User, input StartUploadInput) (*models.ChunkedUpload, error)
+
+	// GetUpload returns one of the customer's uploads, whose offset is where an interrupted upload resumes
+	GetUpload(customer *models.User, uploadID string) (*models.ChunkedUpload, error)
+
+	// WriteChunk stores the chunk starting at offset, which must be the upload's current offset
+	// With the last chunk the image is assembled, validated, stored, and screened like any other upload
+	WriteChunk(customer *models.User, uploadID string, offset int64, chunk []byte) (*models.ChunkedUpload, error)
+
+	// CancelUpload abandons an upload and deletes the chunks received
+	CancelUpload(customer *models.User, uploadID string) error
+
+	// ClaimUpload returns the image key of one of the customer's completed uploads, to attach it to a record
+	ClaimUpload(customer *models.User, uploadID uint) (string, error)
+
+	// DeleteExpiredUploads removes uploads past their expiry and their chunks, returning how many were removed
+	DeleteExpiredUploads(ctx context.Context) (int, error)
+}
+
+// DefaultChunkedUploadService implements ChunkedUploadService on top of a ChunkedUploadRepository and a Storage backend
+type DefaultChunkedUploadService struct {
+	uploads     repositories.ChunkedUploadRepository
+	storage     Storage
+	images      ImageService
+	moderations ImageModerationService
+	now         func() time.Time
+}
+
+var chunkedUploadServiceInstance ChunkedUploadService
+
+// NewChunkedUploadService creates a chunked upload service over the given repository, storage, and image services
+func NewChunkedUploadService(uploads repositories.ChunkedUploadRepository, storage Storage, images ImageService, moderations ImageModerationService) *DefaultChunkedUploadService {
+	return &DefaultChunkedUploadService{uploads: uploads, storage: storage, images: images, moderations: moderations, now: time.Now}
+}
+
+// GetChunkedUploadService returns the configured chunked upload service
+// When none has been set, a service over the current database connection and storage backend is returned
+func GetChunkedUploadService() ChunkedUploadService {
+	if chunkedUploadServiceInstance != nil {
+		return chunkedUploadServiceInstance
+	}
+	return NewChunkedUploadService(repositories.NewChunkedUploadRepository(config.GetDB()), GetStorage(), GetImageService(), GetImageModerationService())
+}
+
+// SetChunkedUploadService sets the chunked upload service instance (primarily for testing)
+func SetChunkedUploadService(service ChunkedUploadService) {
+	chunkedUploadServiceInstance = service
+}
+
+// StartUpload begins an upload of an image of the given size
+func (s *DefaultChunkedUploadService) StartUpload(customer *models.User, input StartUploadInput) (*models.ChunkedUpload, error) {
+	if customer.Role != RoleCustomer {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only customers can upload design images")
+	}
+	if err := utils.ValidateUploadSize(input.Size); err != nil {
+		return nil, uploadValidationError(err)
+	}
+
+	upload := &models.ChunkedUpload{
+		UserID:    customer.ID,
+		Filename:  filepath.Base(input.Filename),
+		Size:      input.Size,
+		ExpiresAt: s.now().Add(ChunkedUploadExpiry),
+	}
+	if err := s.uploads.Create(upload); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to start upload").Wrap(err)
+	}
+	return upload, nil
+}
+
+// GetUpload returns one of the customer's uploads
+func (s *DefaultChunkedUploadService) GetUpload(customer *models.User, uploadID string) (*models.ChunkedUpload, error) {
+	id, err := strconv.ParseUint(uploadID, 10, 64)
+	if err != nil || id == 0 {
+		return nil, apierror.NotFound("UPLOAD_NOT_FOUND", "Upload not found")
+	}
+	return s.findUpload(customer, uint(id))
+}
+
+// WriteChunk stores the chunk starting at offset and completes the upload with its last chunk
+func (s *DefaultChunkedUploadService) WriteChunk(customer *models.User, uploadID string, offset int64, chunk []byte) (*models.ChunkedUpload, error) {
+	upload, err := s.GetUpload(customer, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	if upload.Complete() {
+		return nil, apierror.Conflict("UPLOAD_COMPLETE", "Upload is already complete")
+	}
+	if upload.Offset == upload.Size {
+		// Every chunk arrived but storing the image failed; try again
+		return s.complete(customer, upload)
+	}
+
+	if offset != upload.Offset {
+		return nil, offsetMismatchError(upload.Offset)
+	}
+	if len(chunk) == 0 {
+		return nil, apierror.BadRequest("EMPTY_CHUNK", "Chunk is empty")
+	}
+	if offset+int64(len(chunk)) > upload.Size {
+		return nil, apierror.BadRequest("CHUNK_EXCEEDS_SIZE", "Chunk extends past the size declared for the upload")
+	}
+
+	// Reject files that are not accepted images before the rest of them is sent
+	if offset == 0 {
+		if err := utils.ValidateImageContent(upload.Size, chunk); err != nil {
+			return nil, uploadValidationError(err)
+		}
+	}
+
+	if err := s.storage.Put(chunkKey(upload.ID, offset), chunk, "application/octet-stream"); err != nil {
+		return nil, apierror.Internal("IMAGE_UPLOAD_ERROR", "Failed to store chunk").Wrap(err)
+	}
+	advanced, err := s.uploads.Advance(upload.ID, offset, offset+int64(len(chunk)))
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to record chunk").Wrap(err)
+	}
+	if !advanced {
+		// Another request delivered this chunk first
+		current, err := s.findUpload(customer, upload.ID)
+		if err != nil {
+			return nil, err
+		}
+		return nil, offsetMismatchError(current.Offset)
+	}
+	upload.Offset = offset + int64(len(chunk))
+
+	if upload.Offset < upload.Size {
+		return upload, nil
+	}
+	return s.complete(customer, upload)
+}
+
+// complete assembles an upload's chunks into the image and stores and screens it
+func (s *DefaultChunkedUploadService) complete(customer *models.User, upload *models.ChunkedUpload) (*models.ChunkedUpload, error) {
+	chunks, err := s.storage.List(chunkPrefix(upload.ID))
+	if err != nil {
+		return nil, apierror.Internal("IMAGE_UPLOAD_ERROR", "Failed to read chunks").Wrap(err)
+	}
+	// Chunk keys carry zero-padded offsets, so key order is byte order
+	sort.Slice(chunks, func(i, j int) bool { return chunks[i].Key < chunks[j].Key })
+
+	content := make([]byte, 0, upload.Size)
+	for _, chunk := range chunks {
+		data, err := s.storage.Get(chunk.Key)
+		if err != nil {
+			return nil, apierror.Internal("IMAGE_UPLOAD_ERROR", "Failed to read chunks").Wrap(err)
+		}
+		content = append(content, data...)
+	}
+	if int64(len(content)) != upload.Size {
+		return nil, apierror.Internal("IMAGE_UPLOAD_ERROR", "Received chunks do not add up to the upload").
+			Wrap(fmt.Errorf("upload %d: assembled %d of %d bytes", upload.ID, len(content), upload.Size))
+	}
+	if err := utils.ValidateImageContent(upload.Size, content); err != nil {
+		return nil, uploadValidationError(err)
+	}
+
+	imageKey, err := s.images.StoreImage(upload.Filename, content)
+	if err != nil {
+		return nil, apierror.Internal("IMAGE_UPLOAD_ERROR", "Failed to upload image").Wrap(err)
+	}
+	if _, err := s.moderations.ScreenImageContent(&customer.ID, imageKey, content); err != nil {
+		if deleteErr := s.images.DeleteImage(imageKey); deleteErr != nil {
+			log.Printf("Failed to delete unscreened image %s: %v", imageKey, deleteErr)
+		}
+		return nil, err
+	}
+
+	upload.ImageS3Key = &imageKey
+	if err := s.uploads.Save(upload); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to complete upload").Wrap(err)
+	}
+	s.deleteChunks(upload.ID)
+	return upload, nil
+}
+
+// CancelUpload abandons an upload and deletes the chunks received
+// An image already assembled is left for the orphan cleanup, in case a record already refers to it
+func (s *DefaultChunkedUploadService) CancelUpload(customer *models.User, uploadID string) error {
+	upload, err := s.GetUpload(customer, uploadID)
+	if err != nil {
+		return err
+	}
+	s.deleteChunks(upload.ID)
+	if err := s.uploads.Delete(upload.ID); err != nil {
+		return apierror.Internal("DATABASE_ERROR", "Failed to cancel upload").Wrap(err)
+	}
+	return nil
+}
+
+// ClaimUpload returns the image key of one of the customer's completed uploads
+// The upload stays claimable until it expires, so a failed order creation can be retried with it
+func (s *DefaultChunkedUploadService) ClaimUpload(customer *models.User, uploadID uint) (string, error) {
+	upload, err := s.findUpload(customer, uploadID)
+	if err != nil {
+		return "", err
+	}
+	if !upload.Complete() {
+		return "", apierror.Conflict("UPLOAD_INCOMPLETE", "Upload has not received all of its chunks").
+			WithDetails(map[string]int64{"offset": upload.Offset, "size": upload.Size})
+	}
+	return *upload.ImageS3Key, nil
+}
+
+// DeleteExpiredUploads removes uploads past their expiry and their chunks
+func (s *DefaultChunkedUploadService) DeleteExpiredUploads(ctx context.Context) (int, error) {
+	var deleted int
+	for {
+		if err := ctx.Err(); err != nil {
+			return deleted, err
+		}
+		uploads, err := s.uploads.FindExpired(s.now(), expiredUploadBatchSize)
+		if err != nil {
+			return deleted, fmt.Errorf("failed to load expired uploads: %w", err)
+		}
+		if len(uploads) == 0 {
+			return deleted, nil
+		}
+		for _, upload := range uploads {
+			if err := s.removeChunks(upload.ID); err != nil {
+				return deleted, err
+			}
+			if err := s.uploads.Delete(upload.ID); err != nil {
+				return deleted, fmt.Errorf("failed to delete upload %d: %w", upload.ID, err)
+			}
+			deleted++
+		}
+	}
+}
+
+// findUpload loads one of the customer's uploads that has not expired
+// Other customers' uploads are reported as not found, so their IDs are not revealed
+func (s *DefaultChunkedUploadService) findUpload(customer *models.User, id uint) (*models.ChunkedUpload, error) {
+	upload, err := s.uploads.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apierror.NotFound("UPLOAD_NOT_FOUND", "Upload not found")
+		}
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load upload").Wrap(err)
+	}
+	if upload.UserID != customer.ID || !s.now().Before(upload.ExpiresAt) {
+		return nil, apierror.NotFound("UPLOAD_NOT_FOUND", "Upload not found")
+	}
+	return upload, nil
+}
+
+// deleteChunks removes an upload's chunks, logging failures for the expiry cleanup to retry
+func (s *DefaultChunkedUploadService) deleteChunks(uploadID uint) {
+	if err := s.removeChunks(uploadID); err != nil {
+		log.Printf("Failed to delete chunks of upload %d: %v", uploadID, err)
+	}
+}
+
+// removeChunks removes an upload's chunks from storage
+func (s *DefaultChunkedUploadService) removeChunks(uploadID uint) error {
+	chunks, err := s.storage.List(chunkPrefix(uploadID))
+	if err != nil {
+		return err
+	}
+	for _, chunk := range chunks {
+		if err := s.storage.Delete(chunk.Key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// chunkPrefix is the key prefix of an upload's chunks
+func chunkPrefix(uploadID uint) string {
+	return fmt.Sprintf("%s%d/", ChunkPrefix, uploadID)
+}
+
+// chunkKey is the key of the chunk of an upload starting at offset
+func chunkKey(uploadID uint, offset int64) string {
+	return fmt.Sprintf("%s%012d", chunkPrefix(uploadID), offset)
+}
+
+// offsetMismatchError reports a chunk that does not start where the upload left off, with the offset to resume from
+func offsetMismatchError(offset int64) error {
+	return apierror.Conflict("UPLOAD_OFFSET_MISMATCH", "Chunk does not start at the upload's current offset").
+		WithDetails(map[string]int64{"offset": offset})
+}
+
+// uploadValidationError converts an upload validation failure into an API error
+func uploadValidationError(err error) error {
+	var fileErr *utils.FileUploadError
+	if errors.As(err, &fileErr) {
+		return apierror.BadRequest(fileErr.Code, fileErr.Message)
+	}
+	return apierror.Internal("IMAGE_UPLOAD_ERROR", "Failed to validate image").Wrap(err)
+}
+
+// ExpiredUploadCleanupJob deletes the chunks of uploads that were never finished, every hour
+func ExpiredUploadCleanupJob(service ChunkedUploadService) jobs.Job {
+	return jobs.Job{
+		Name:     "expired_upload_cleanup",
+		Interval: ExpiredUploadCheckInterval,
+		Run: func(ctx context.Context) error {
+			deleted, err := service.DeleteExpiredUploads(ctx)
+			if deleted > 0 {
+				log.Printf("Deleted %d expired chunked uploads", deleted)
+			}
+			return err
+		},
+	}
+}
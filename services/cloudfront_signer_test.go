@@ -0,0 +1,51 @@
+package services
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloudFrontSigner_SignedURL(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	// Keys are usually pasted into the environment with escaped newlines
+	signer, err := NewCloudFrontSigner("https://d111111abcdef8.cloudfront.net/", "K2JCJMDEHXQW5F",
+		strings.ReplaceAll(string(keyPEM), "\n", `\n`))
+	require.NoError(t, err)
+
+	expires := time.Date(2026, 3, 10, 13, 0, 0, 0, time.UTC)
+	signed, err := signer.SignedURL("uploads/1_my design.png", expires)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(signed, "https://d111111abcdef8.cloudfront.net/uploads/1_my%20design.png?Expires=1773147600&"), signed)
+
+	parsed, err := url.Parse(signed)
+	require.NoError(t, err)
+	assert.Equal(t, "K2JCJMDEHXQW5F", parsed.Query().Get("Key-Pair-Id"))
+
+	// The signature covers the canned policy for exactly this URL and expiry
+	encoded := strings.NewReplacer("-", "+", "_", "=", "~", "/").Replace(parsed.Query().Get("Signature"))
+	signature, err := base64.StdEncoding.DecodeString(encoded)
+	require.NoError(t, err)
+	policy := `{"Statement":[{"Resource":"https://d111111abcdef8.cloudfront.net/uploads/1_my%20design.png","Condition":{"DateLessThan":{"AWS:EpochTime":1773147600}}}]}`
+	digest := sha1.Sum([]byte(policy))
+	assert.NoError(t, rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA1, digest[:], signature))
+}
+
+func TestNewCloudFrontSigner_RejectsInvalidKeys(t *testing.T) {
+	_, err := NewCloudFrontSigner("https://cdn.example.com", "K2JCJMDEHXQW5F", "not a key")
+	assert.Error(t, err)
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"strings"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+)
+
+// Terms describes the current terms of service
+type Terms struct {
+	Version        string  `json:"version"`         // effective date, YYYY-MM-DD
+	MinimumVersion string  `json:"minimum_version"` // oldest accepted version that still allows placing orders
+	URL            *string `json:"url"`
+}
+
+// ConsentStatus is a user's standing against the current terms of service
+type ConsentStatus struct {
+	Terms              *Terms               `json:"terms"`            // nil when no terms are configured
+	AcceptedVersion    *string              `json:"accepted_version"` // newest version the user accepted
+	AcceptanceRequired bool                 `json:"acceptance_required"`
+	Consents           []models.UserConsent `json:"consents"` // every acceptance, newest version first
+}
+
+// ConsentInput holds a user's acceptance of a terms version
+type ConsentInput struct {
+	TermsVersion string
+	IPAddress    string
+	UserAgent    string
+}
+
+// ConsentService records which versions of the terms of service users accepted
+type ConsentService interface {
+	// CurrentTerms returns the terms of service users must accept
+	CurrentTerms() (*Terms, error)
+
+	// AcceptTerms records the user's acceptance of the current terms, reporting whether it is new
+	// Accepting a version again returns the original acceptance
+	AcceptTerms(user *models.User, input ConsentInput) (*models.UserConsent, bool, error)
+
+	// GetConsentStatus returns the user's consent history and whether they must accept the current terms
+	GetConsentStatus(user *models.User) (*ConsentStatus, error)
+}
+
+// DefaultConsentService implements ConsentService on top of a ConsentRepository
+type DefaultConsentService struct {
+	consents repositories.ConsentRepository
+	now      func() time.Time
+}
+
+var consentServiceInstance ConsentService
+
+// NewConsentService creates a consent service using the given repository
+func NewConsentService(consents repositories.ConsentRepository) *DefaultConsentService {
+	return &DefaultConsentService{consents: consents, now: time.Now}
+}
+
+// GetConsentService returns the configured consent service
+// When none has been set, a service over the current database connection is returned
+func GetConsentService() ConsentService {
+	if consentServiceInstance != nil {
+		return consentServiceInstance
+	}
+	return NewConsentService(repositories.NewConsentRepository(config.GetDB()))
+}
+
+// SetConsentService sets the consent service instance (primarily for testing)
+func SetConsentService(service ConsentService) {
+	consentServiceInstance = service
+}
+
+// CurrentTerms returns the terms of service users must accept
+func (s *DefaultConsentService) CurrentTerms() (*Terms, error) {
+	terms := currentTerms()
+	if terms == nil {
+		return nil, apierror.NotFound("TERMS_NOT_CONFIGURED", "No terms of service are configured")
+	}
+	return terms, nil
+}
+
+// AcceptTerms records the user's acceptance of the current terms, reporting whether it is new
+// Only the current version can be accepted, so a client showing outdated terms finds out
+func (s *DefaultConsentService) AcceptTerms(user *models.User, input ConsentInput) (*models.UserConsent, bool, error) {
+	terms, err := s.CurrentTerms()
+	if err != nil {
+		return nil, false, err
+	}
+	version := strings.TrimSpace(input.TermsVersion)
+	if version != terms.Version {
+		return nil, false, apierror.Unprocessable("TERMS_VERSION_MISMATCH", "Only the current terms of service can be accepted").
+			WithDetails(map[string]interface{}{"current_version": terms.Version, "terms_url": terms.URL})
+	}
+
+	consent := &models.UserConsent{
+		UserID:       user.ID,
+		TermsVersion: version,
+		AcceptedAt:   s.now(),
+		IPAddress:    optionalString(input.IPAddress),
+		UserAgent:    optionalString(input.UserAgent),
+	}
+	created, err := s.consents.CreateIfAbsent(consent)
+	if err != nil {
+		return nil, false, apierror.Internal("DATABASE_ERROR", "Failed to record terms acceptance").Wrap(err)
+	}
+	if created {
+		return consent, true, nil
+	}
+
+	existing, err := s.consents.FindByUserAndVersion(user.ID, version)
+	if err != nil {
+		return nil, false, apierror.Internal("DATABASE_ERROR", "Failed to load terms acceptance").Wrap(err)
+	}
+	return existing, false, nil
+}
+
+// GetConsentStatus returns the user's consent history and whether they must accept the current terms
+func (s *DefaultConsentService) GetConsentStatus(user *models.User) (*ConsentStatus, error) {
+	consents, err := s.consents.ListByUser(user.ID)
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load terms acceptances").Wrap(err)
+	}
+
+	status := &ConsentStatus{Terms: currentTerms(), Consents: consents}
+	if len(consents) > 0 {
+		status.AcceptedVersion = &consents[0].TermsVersion
+	}
+	status.AcceptanceRequired = status.Terms != nil && (status.AcceptedVersion == nil || *status.AcceptedVersion < status.Terms.MinimumVersion)
+	return status, nil
+}
+
+// currentTerms returns the configured terms of service, or nil when users need not accept any
+func currentTerms() *Terms {
+	cfg := config.GetConfig()
+	if cfg == nil || !cfg.TermsEnabled() {
+		return nil
+	}
+	return &Terms{Version: cfg.TermsVersion, MinimumVersion: cfg.GetTermsMinimumVersion(), URL: optionalString(cfg.TermsURL)}
+}
+
+// requireTermsAccepted checks that the customer accepted terms recent enough to place orders
+// A nil repository skips the check
+func requireTermsAccepted(consents repositories.ConsentRepository, customer *models.User) error {
+	terms := currentTerms()
+	if terms == nil || consents == nil {
+		return nil
+	}
+	accepted, err := consents.LatestVersion(customer.ID)
+	if err != nil {
+		return apierror.Internal("DATABASE_ERROR", "Failed to check terms acceptance").Wrap(err)
+	}
+	if accepted == "" || accepted < terms.MinimumVersion {
+		return apierror.Forbidden("TERMS_ACCEPTANCE_REQUIRED", "Please accept the current terms of service before placing orders").
+			WithDetails(map[string]interface{}{"terms_version": terms.Version, "terms_url": terms.URL})
+	}
+	return nil
+}
+
+// optionalString returns nil for an empty or blank string
+func optionalString(value string) *string {
+	if value = strings.TrimSpace(value); value == "" {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"net/http"
+	"sort"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// fakeConsentRepository is an in-memory ConsentRepository
+type fakeConsentRepository struct {
+	consents []models.UserConsent
+}
+
+func (r *fakeConsentRepository) CreateIfAbsent(consent *models.UserConsent) (bool, error) {
+	if _, err := r.FindByUserAndVersion(consent.UserID, consent.TermsVersion); err == nil {
+		return false, nil
+	}
+	consent.ID = uint(len(r.consents) + 1)
+	r.consents = append(r.consents, *consent)
+	return true, nil
+}
+
+func (r *fakeConsentRepository) FindByUserAndVersion(userID uint, version string) (*models.UserConsent, error) {
+	for i := range r.consents {
+		if r.consents[i].UserID == userID && r.consents[i].TermsVersion == version {
+			consent := r.consents[i]
+			return &consent, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeConsentRepository) ListByUser(userID uint) ([]models.UserConsent, error) {
+	var consents []models.UserConsent
+	for _, consent := range r.consents {
+		if consent.UserID == userID {
+			consents = append(consents, consent)
+		}
+	}
+	sort.Slice(consents, func(i, j int) bool { return consents[i].TermsVersion > consents[j].TermsVersion })
+	return consents, nil
+}
+
+func (r *fakeConsentRepository) LatestVersion(userID uint) (string, error) {
+	consents, _ := r.ListByUser(userID)
+	if len(consents) == 0 {
+		return "", nil
+	}
+	return consents[0].TermsVersion, nil
+}
+
+func TestConsentService(t *testing.T) {
+	originalConfig := config.GetConfig()
+	defer config.SetConfig(originalConfig)
+
+	customer := &models.User{ID: 1, Role: RoleCustomer}
+	consents := &fakeConsentRepository{}
+	service := NewConsentService(consents)
+	orders := newTestOrderService(newFakeOrderRepository())
+	orders.consents = consents
+
+	// Without configured terms nothing is required
+	config.SetConfig(&config.Config{})
+	_, err := service.CurrentTerms()
+	assertAPIError(t, err, http.StatusNotFound, "TERMS_NOT_CONFIGURED")
+	_, err = orders.CreateOrder(customer, CreateOrderInput{Description: "Pink", Quantity: 1})
+	require.NoError(t, err)
+
+	config.SetConfig(&config.Config{TermsVersion: "2026-01-01", TermsURL: "https://example.com/terms"})
+	terms, err := service.CurrentTerms()
+	require.NoError(t, err)
+	assert.Equal(t, "2026-01-01", terms.MinimumVersion)
+
+	// Customers who have not accepted the terms cannot order
+	_, err = orders.CreateOrder(customer, CreateOrderInput{Description: "Pink", Quantity: 1})
+	assertAPIError(t, err, http.StatusForbidden, "TERMS_ACCEPTANCE_REQUIRED")
+	status, err := service.GetConsentStatus(customer)
+	require.NoError(t, err)
+	assert.True(t, status.AcceptanceRequired)
+	assert.Nil(t, status.AcceptedVersion)
+
+	_, _, err = service.AcceptTerms(customer, ConsentInput{TermsVersion: "2025-06-01"})
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "TERMS_VERSION_MISMATCH")
+
+	consent, created, err := service.AcceptTerms(customer, ConsentInput{TermsVersion: "2026-01-01", IPAddress: "203.0.113.7", UserAgent: "Mozilla/5.0"})
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, "203.0.113.7", *consent.IPAddress)
+	again, created, err := service.AcceptTerms(customer, ConsentInput{TermsVersion: "2026-01-01"})
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, consent.ID, again.ID)
+
+	_, err = orders.CreateOrder(customer, CreateOrderInput{Description: "Pink", Quantity: 1})
+	require.NoError(t, err)
+
+	// A minor revision that keeps the minimum version does not block ordering
+	config.SetConfig(&config.Config{TermsVersion: "2026-03-01", TermsMinimumVersion: "2026-01-01"})
+	_, err = orders.CreateOrder(customer, CreateOrderInput{Description: "Pink", Quantity: 1})
+	require.NoError(t, err)
+
+	// A revision that needs re-acceptance blocks ordering until the customer accepts it
+	config.SetConfig(&config.Config{TermsVersion: "2026-05-01"})
+	_, err = orders.CreateOrder(customer, CreateOrderInput{Description: "Pink", Quantity: 1})
+	assertAPIError(t, err, http.StatusForbidden, "TERMS_ACCEPTANCE_REQUIRED")
+	_, err = orders.DuplicateOrder(customer, "1")
+	assertAPIError(t, err, http.StatusForbidden, "TERMS_ACCEPTANCE_REQUIRED")
+
+	_, _, err = service.AcceptTerms(customer, ConsentInput{TermsVersion: "2026-05-01"})
+	require.NoError(t, err)
+	status, err = service.GetConsentStatus(customer)
+	require.NoError(t, err)
+	assert.False(t, status.AcceptanceRequired)
+	assert.Equal(t, "2026-05-01", *status.AcceptedVersion)
+	assert.Len(t, status.Consents, 2)
+	_, err = orders.DuplicateOrder(customer, "1")
+	assert.NoError(t, err)
+}
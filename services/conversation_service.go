@@ -0,0 +1,248 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"gorm.io/gorm"
+)
+
+// Typing indicator settings
+// Clients report typing every few seconds while the user types; an indicator older than the TTL means they stopped
+const (
+	TypingIndicatorTTL    = 8 * time.Second
+	TypingRefreshInterval = 3 * time.Second // reports from the same user within this are not passed on
+)
+
+// ConversationService tracks typing, delivery and read state in order conversations and signals them to the
+// other participants over the long-poll notifications
+type ConversationService interface {
+	// Typing tells the order's other participants that the user is typing a message
+	Typing(user *models.User, orderID string) error
+
+	// MarkSeen records that the user has read the order's messages up to messageID and sends a message.seen receipt
+	MarkSeen(user *models.User, orderID string, messageID uint) (*models.MessageReadState, error)
+
+	// MarkDelivered records the messages handed to the user in message.created notifications as delivered,
+	// and sends message.delivered receipts to their senders
+	MarkDelivered(user *models.User, notifications []models.Notification) error
+
+	// Receive records the messages listed to the user as delivered, and sets the status of the user's own messages
+	Receive(user *models.User, order *models.Order, messages []models.Message) error
+}
+
+// DefaultConversationService implements ConversationService on top of the order and conversation repositories
+type DefaultConversationService struct {
+	orders        repositories.OrderRepository
+	conversations repositories.ConversationRepository
+	notifications NotificationService
+	now           func() time.Time
+
+	mu     sync.Mutex
+	typing map[typingKey]time.Time // when each typist's indicator was last passed on
+}
+
+// typingKey identifies a user typing in an order conversation
+type typingKey struct {
+	orderID uint
+	userID  uint
+}
+
+var conversationServiceInstance ConversationService
+
+// NewConversationService creates a conversation service that signals through the given notification service
+func NewConversationService(orders repositories.OrderRepository, conversations repositories.ConversationRepository, notifications NotificationService) *DefaultConversationService {
+	return &DefaultConversationService{
+		orders:        orders,
+		conversations: conversations,
+		notifications: notifications,
+		now:           time.Now,
+		typing:        make(map[typingKey]time.Time),
+	}
+}
+
+// GetConversationService returns the configured conversation service
+// When none has been set, a service over the current database connection is returned
+func GetConversationService() ConversationService {
+	if conversationServiceInstance != nil {
+		return conversationServiceInstance
+	}
+	db := config.GetDB()
+	return NewConversationService(repositories.NewOrderRepository(db), repositories.NewConversationRepository(db), GetNotificationService())
+}
+
+// SetConversationService sets the conversation service instance (primarily for testing)
+func SetConversationService(service ConversationService) {
+	conversationServiceInstance = service
+}
+
+// Typing tells the order's other participants that the user is typing a message
+// Repeated reports within TypingRefreshInterval are dropped, so fast clients don't flood the notifications
+func (s *DefaultConversationService) Typing(user *models.User, orderID string) error {
+	order, err := s.conversationOrder(user, orderID)
+	if err != nil {
+		return err
+	}
+
+	now := s.now()
+	key := typingKey{orderID: order.ID, userID: user.ID}
+	s.mu.Lock()
+	last, ok := s.typing[key]
+	if ok && now.Sub(last) < TypingRefreshInterval {
+		s.mu.Unlock()
+		return nil
+	}
+	s.typing[key] = now
+	for other, at := range s.typing {
+		if now.Sub(at) >= TypingIndicatorTTL {
+			delete(s.typing, other)
+		}
+	}
+	s.mu.Unlock()
+
+	if err := s.notifications.NotifyTyping(order, user.ID); err != nil {
+		return apierror.Internal("DATABASE_ERROR", "Failed to send typing indicator").Wrap(err)
+	}
+	return nil
+}
+
+// MarkSeen records that the user has read the order's messages up to messageID
+// Marking an earlier message than the last one seen changes nothing
+func (s *DefaultConversationService) MarkSeen(user *models.User, orderID string, messageID uint) (*models.MessageReadState, error) {
+	order, err := s.conversationOrder(user, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.conversations.FindMessage(order.ID, messageID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apierror.NotFound("MESSAGE_NOT_FOUND", "Message not found")
+		}
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load message").Wrap(err)
+	}
+
+	moved, err := s.conversations.AdvanceSeen(order.ID, user.ID, messageID)
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to save read state").Wrap(err)
+	}
+	if moved {
+		if err := s.notifications.NotifyReceipt(order, models.NotificationMessageSeen, user.ID, messageID); err != nil {
+			return nil, apierror.Internal("DATABASE_ERROR", "Failed to send read receipt").Wrap(err)
+		}
+	}
+
+	return s.readState(order.ID, user.ID)
+}
+
+// MarkDelivered records the messages handed to the user in message.created notifications as delivered
+func (s *DefaultConversationService) MarkDelivered(user *models.User, notifications []models.Notification) error {
+	latest := make(map[uint]uint) // order ID to the latest message delivered
+	var orderIDs []uint
+	for _, notification := range notifications {
+		if notification.Event != models.NotificationMessageCreated || notification.MessageID == nil {
+			continue
+		}
+		if _, ok := latest[notification.OrderID]; !ok {
+			orderIDs = append(orderIDs, notification.OrderID)
+		}
+		if *notification.MessageID > latest[notification.OrderID] {
+			latest[notification.OrderID] = *notification.MessageID
+		}
+	}
+
+	for _, orderID := range orderIDs {
+		order, err := s.orders.FindByID(orderID)
+		if err != nil {
+			return err
+		}
+		if err := s.deliver(user, order, latest[orderID]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Receive records the messages listed to the user as delivered, and sets the status of the user's own messages
+// A message the user sent is seen once another participant has read it, and delivered once their client fetched it
+func (s *DefaultConversationService) Receive(user *models.User, order *models.Order, messages []models.Message) error {
+	var latest uint
+	for _, message := range messages {
+		if (message.SenderID == nil || *message.SenderID != user.ID) && message.ID > latest {
+			latest = message.ID
+		}
+	}
+	if latest > 0 {
+		if err := s.deliver(user, order, latest); err != nil {
+			return apierror.Internal("DATABASE_ERROR", "Failed to save delivery state").Wrap(err)
+		}
+	}
+
+	states, err := s.conversations.ListReadStates(order.ID)
+	if err != nil {
+		return apierror.Internal("DATABASE_ERROR", "Failed to load read state").Wrap(err)
+	}
+	var delivered, seen uint
+	for _, state := range states {
+		if state.UserID == user.ID {
+			continue
+		}
+		delivered = max(delivered, state.LastDeliveredMessageID)
+		seen = max(seen, state.LastSeenMessageID)
+	}
+
+	for i := range messages {
+		if messages[i].SenderID == nil || *messages[i].SenderID != user.ID {
+			continue
+		}
+		switch {
+		case messages[i].ID <= seen:
+			messages[i].Status = models.MessageStatusSeen
+		case messages[i].ID <= delivered:
+			messages[i].Status = models.MessageStatusDelivered
+		default:
+			messages[i].Status = models.MessageStatusSent
+		}
+	}
+	return nil
+}
+
+// deliver moves the user's last delivered message forward and sends a receipt when it moved
+func (s *DefaultConversationService) deliver(user *models.User, order *models.Order, messageID uint) error {
+	moved, err := s.conversations.AdvanceDelivered(order.ID, user.ID, messageID)
+	if err != nil || !moved {
+		return err
+	}
+	return s.notifications.NotifyReceipt(order, models.NotificationMessageDelivered, user.ID, messageID)
+}
+
+// readState loads the user's read state of the order's conversation
+func (s *DefaultConversationService) readState(orderID, userID uint) (*models.MessageReadState, error) {
+	states, err := s.conversations.ListReadStates(orderID)
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load read state").Wrap(err)
+	}
+	for i := range states {
+		if states[i].UserID == userID {
+			return &states[i], nil
+		}
+	}
+	return &models.MessageReadState{OrderID: orderID, UserID: userID}, nil
+}
+
+// conversationOrder loads an order whose conversation the user takes part in
+// Like messaging, that is the order's customer and its assigned technician
+func (s *DefaultConversationService) conversationOrder(user *models.User, orderID string) (*models.Order, error) {
+	order, err := findOrder(s.orders, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.CustomerID != user.ID && !IsAssignedTo(order, user) {
+		return nil, apierror.Forbidden("FORBIDDEN", "You do not take part in this order's conversation")
+	}
+	return order, nil
+}
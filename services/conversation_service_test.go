@@ -0,0 +1,157 @@
+package services
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// fakeConversationRepository is an in-memory ConversationRepository
+type fakeConversationRepository struct {
+	messages []models.Message
+	states   map[uint]*models.MessageReadState // by user; the tests use one order
+}
+
+func newFakeConversationRepository(messages ...models.Message) *fakeConversationRepository {
+	return &fakeConversationRepository{messages: messages, states: make(map[uint]*models.MessageReadState)}
+}
+
+func (r *fakeConversationRepository) FindMessage(orderID, messageID uint) (*models.Message, error) {
+	for _, message := range r.messages {
+		if message.ID == messageID && message.OrderID == orderID {
+			found := message
+			return &found, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeConversationRepository) ListReadStates(orderID uint) ([]models.MessageReadState, error) {
+	var states []models.MessageReadState
+	for _, state := range r.states {
+		if state.OrderID == orderID {
+			states = append(states, *state)
+		}
+	}
+	return states, nil
+}
+
+func (r *fakeConversationRepository) state(orderID, userID uint) *models.MessageReadState {
+	if _, ok := r.states[userID]; !ok {
+		r.states[userID] = &models.MessageReadState{OrderID: orderID, UserID: userID}
+	}
+	return r.states[userID]
+}
+
+func (r *fakeConversationRepository) AdvanceDelivered(orderID, userID, messageID uint) (bool, error) {
+	state := r.state(orderID, userID)
+	if state.LastDeliveredMessageID >= messageID {
+		return false, nil
+	}
+	state.LastDeliveredMessageID = messageID
+	return true, nil
+}
+
+func (r *fakeConversationRepository) AdvanceSeen(orderID, userID, messageID uint) (bool, error) {
+	state := r.state(orderID, userID)
+	if state.LastSeenMessageID >= messageID {
+		return false, nil
+	}
+	state.LastSeenMessageID = messageID
+	state.LastDeliveredMessageID = max(state.LastDeliveredMessageID, messageID)
+	return true, nil
+}
+
+// notificationEvents returns the events queued for the user, in order
+func notificationEvents(repo *fakeNotificationRepository, userID uint) []string {
+	var events []string
+	for _, notification := range repo.notifications {
+		if notification.UserID == userID {
+			events = append(events, notification.Event)
+		}
+	}
+	return events
+}
+
+func TestConversationService_Typing(t *testing.T) {
+	orders := newFakeOrderRepository(
+		models.Order{ID: 1, CustomerID: testCustomer.ID, TechnicianID: uintPtr(testTechnician.ID)},
+	)
+	notificationRepo := &fakeNotificationRepository{}
+	service := NewConversationService(orders, newFakeConversationRepository(), NewNotificationService(notificationRepo, orders))
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	assertAPIError(t, service.Typing(otherCustomer, "1"), http.StatusForbidden, "FORBIDDEN")
+	assertAPIError(t, service.Typing(otherTech, "1"), http.StatusForbidden, "FORBIDDEN")
+
+	// Reports within the refresh interval are passed on once
+	assert.NoError(t, service.Typing(testCustomer, "1"))
+	now = now.Add(time.Second)
+	assert.NoError(t, service.Typing(testCustomer, "1"))
+	assert.Equal(t, []string{models.NotificationTyping}, notificationEvents(notificationRepo, testTechnician.ID))
+	assert.Empty(t, notificationEvents(notificationRepo, testCustomer.ID))
+
+	now = now.Add(TypingRefreshInterval)
+	assert.NoError(t, service.Typing(testCustomer, "1"))
+	assert.Len(t, notificationEvents(notificationRepo, testTechnician.ID), 2)
+}
+
+func TestConversationService_Receipts(t *testing.T) {
+	orders := newFakeOrderRepository(
+		models.Order{ID: 1, CustomerID: testCustomer.ID, TechnicianID: uintPtr(testTechnician.ID)},
+	)
+	messages := []models.Message{
+		{ID: 1, OrderID: 1, SenderID: uintPtr(testCustomer.ID), Text: "Which shade of pink?"},
+		{ID: 2, OrderID: 1, SenderID: uintPtr(testCustomer.ID), Text: "The lighter one please"},
+		{ID: 3, OrderID: 1, SenderID: uintPtr(testTechnician.ID), Text: "Got it"},
+	}
+	conversations := newFakeConversationRepository(messages...)
+	notificationRepo := &fakeNotificationRepository{}
+	service := NewConversationService(orders, conversations, NewNotificationService(notificationRepo, orders))
+	order, _ := orders.FindByID(1)
+
+	// Nothing has reached the technician yet
+	listed := append([]models.Message(nil), messages...)
+	assert.NoError(t, service.Receive(testCustomer, order, listed))
+	assert.Equal(t, models.MessageStatusSent, listed[0].Status)
+	assert.Equal(t, models.MessageStatusSent, listed[1].Status)
+	assert.Equal(t, "", listed[2].Status)
+
+	// The technician's poll hands over the first message
+	assert.NoError(t, service.MarkDelivered(testTechnician, []models.Notification{
+		{Event: models.NotificationMessageCreated, OrderID: 1, MessageID: uintPtr(1)},
+		{Event: models.NotificationOrderStatusChanged, OrderID: 1},
+	}))
+	assert.Equal(t, []string{models.NotificationMessageDelivered}, notificationEvents(notificationRepo, testCustomer.ID))
+
+	// The technician lists the conversation and reads the first message
+	assert.NoError(t, service.Receive(testTechnician, order, append([]models.Message(nil), messages...)))
+	state, err := service.MarkSeen(testTechnician, "1", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), state.LastSeenMessageID)
+	assert.Equal(t, uint(2), state.LastDeliveredMessageID)
+	assert.Equal(t, []string{
+		models.NotificationMessageDelivered,
+		models.NotificationMessageDelivered,
+		models.NotificationMessageSeen,
+	}, notificationEvents(notificationRepo, testCustomer.ID))
+
+	listed = append([]models.Message(nil), messages...)
+	assert.NoError(t, service.Receive(testCustomer, order, listed))
+	assert.Equal(t, models.MessageStatusSeen, listed[0].Status)
+	assert.Equal(t, models.MessageStatusDelivered, listed[1].Status)
+
+	// Going back sends no receipt, and messages of other orders can't be marked
+	_, err = service.MarkSeen(testTechnician, "1", 1)
+	assert.NoError(t, err)
+	assert.Len(t, notificationEvents(notificationRepo, testCustomer.ID), 3)
+	_, err = service.MarkSeen(testTechnician, "1", 99)
+	assertAPIError(t, err, http.StatusNotFound, "MESSAGE_NOT_FOUND")
+	_, err = service.MarkSeen(otherTech, "1", 1)
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+}
@@ -0,0 +1,243 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"github.com/kendall-kelly/kendalls-nails-api/utils"
+)
+
+// Periods technician earnings are grouped by
+const (
+	EarningsByWeek  = "week"
+	EarningsByMonth = "month"
+)
+
+// Technician earnings report bounds
+// Without dates the report covers DefaultEarningsPeriods periods ending with the current one
+const (
+	DefaultEarningsPeriods = 12
+	MaxEarningsRange       = 366 * 24 * time.Hour
+)
+
+// PendingEarningStatuses are the statuses of orders that have been accepted and not yet delivered
+var PendingEarningStatuses = []string{StatusAccepted, StatusInProduction, StatusShipped}
+
+// EarningsQuery selects the period grouping and date range of a technician's earnings
+// Zero From or To select the default range; To is exclusive
+type EarningsQuery struct {
+	Period string // EarningsByWeek or EarningsByMonth, defaults to EarningsByWeek
+	From   time.Time
+	To     time.Time
+}
+
+// Earnings is a technician's earnings report returned by GET /users/me/earnings
+// Amounts are summed per currency, since orders keep the currency they were placed in
+type Earnings struct {
+	Period    string           `json:"period"`
+	From      time.Time        `json:"from"`
+	To        time.Time        `json:"to"`        // exclusive
+	Completed []EarningsPeriod `json:"completed"` // delivered orders in the range by period, oldest first
+	Pending   []EarningsTotal  `json:"pending"`   // accepted orders that have not been delivered yet, whenever accepted
+	Orders    []EarningsOrder  `json:"orders"`    // the pending orders, then the delivered orders, newest first
+}
+
+// EarningsPeriod is the revenue of orders delivered in one period, in one currency
+type EarningsPeriod struct {
+	PeriodStart string  `json:"period_start"` // first day of the week (a Monday) or month, YYYY-MM-DD
+	Currency    string  `json:"currency"`
+	Orders      int     `json:"orders"`
+	Amount      float64 `json:"amount"`
+}
+
+// EarningsTotal is the revenue of a set of orders in one currency
+type EarningsTotal struct {
+	Currency string  `json:"currency"`
+	Orders   int     `json:"orders"`
+	Amount   float64 `json:"amount"`
+}
+
+// EarningsOrder is one order's contribution to a technician's earnings, with its quote for reconciliation
+type EarningsOrder struct {
+	OrderID     uint                   `json:"order_id"`
+	Description string                 `json:"description"`
+	Status      string                 `json:"status"`
+	Currency    string                 `json:"currency"`
+	Amount      float64                `json:"amount"`
+	DeliveredAt *time.Time             `json:"delivered_at"` // nil while pending
+	PayoutID    *uint                  `json:"payout_id"`    // the payout that paid the order, nil until paid
+	LineItems   []models.OrderLineItem `json:"line_items"`
+}
+
+// EarningsService reports technicians' revenue from the orders assigned to them
+type EarningsService interface {
+	// Earnings returns the technician's delivered revenue grouped by period, pending revenue, and the orders behind them
+	// Orders count toward the technician currently assigned, so a handed-off order counts for whoever took it over
+	Earnings(technician *models.User, query EarningsQuery) (*Earnings, error)
+}
+
+// DefaultEarningsService implements EarningsService on top of an OrderRepository
+type DefaultEarningsService struct {
+	orders repositories.OrderRepository
+	now    func() time.Time
+}
+
+var earningsServiceInstance EarningsService
+
+// NewEarningsService creates an earnings service using the given repository
+func NewEarningsService(orders repositories.OrderRepository) *DefaultEarningsService {
+	return &DefaultEarningsService{orders: orders, now: time.Now}
+}
+
+// GetEarningsService returns the configured earnings service
+// When none has been set, a service over the current database connection is returned
+func GetEarningsService() EarningsService {
+	if earningsServiceInstance != nil {
+		return earningsServiceInstance
+	}
+	return NewEarningsService(repositories.NewOrderRepository(config.GetDB()))
+}
+
+// SetEarningsService sets the earnings service instance (primarily for testing)
+func SetEarningsService(service EarningsService) {
+	earningsServiceInstance = service
+}
+
+// Earnings returns the technician's delivered revenue grouped by period, pending revenue, and the orders behind them
+func (s *DefaultEarningsService) Earnings(technician *models.User, query EarningsQuery) (*Earnings, error) {
+	if technician.Role != RoleTechnician {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only technicians can view earnings")
+	}
+
+	period := query.Period
+	if period == "" {
+		period = EarningsByWeek
+	}
+	if period != EarningsByWeek && period != EarningsByMonth {
+		return nil, apierror.Validation("Invalid request data", map[string]string{"period": "must be week or month"})
+	}
+
+	from, to := query.From.UTC(), query.To.UTC()
+	if to.IsZero() {
+		to = s.now().UTC()
+	}
+	if from.IsZero() {
+		from = startOfEarningsPeriod(period, to)
+		if period == EarningsByMonth {
+			from = from.AddDate(0, -(DefaultEarningsPeriods - 1), 0)
+		} else {
+			from = from.AddDate(0, 0, -7*(DefaultEarningsPeriods-1))
+		}
+	}
+	if !to.After(from) {
+		return nil, apierror.Validation("Invalid report period", map[string]string{"to": "must be after from"})
+	}
+	if to.Sub(from) > MaxEarningsRange {
+		return nil, apierror.Validation("Invalid report period", map[string]string{"from": "period must be at most 366 days"})
+	}
+
+	delivered, err := s.orders.ListDeliveredBetween(technician.ID, StatusDelivered, from, to)
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to compute earnings").Wrap(err)
+	}
+	pending, err := s.orders.ListAssignedTo(technician.ID, PendingEarningStatuses)
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to compute earnings").Wrap(err)
+	}
+
+	earnings := &Earnings{
+		Period:    period,
+		From:      from,
+		To:        to,
+		Completed: []EarningsPeriod{},
+		Pending:   []EarningsTotal{},
+		Orders:    make([]EarningsOrder, 0, len(pending)+len(delivered)),
+	}
+
+	// Sum in whole cents so totals match the line items exactly
+	type bucket struct {
+		periodStart string
+		currency    string
+	}
+	completedOrders := make(map[bucket]int)
+	completedCents := make(map[bucket]int64)
+	for i := range delivered {
+		order := &delivered[i]
+		deliveredAt := order.DeliveredTime()
+		key := bucket{startOfEarningsPeriod(period, deliveredAt).Format(time.DateOnly), order.Currency}
+		completedOrders[key]++
+		completedCents[key] += orderCents(order)
+		earnings.Orders = append(earnings.Orders, earningsOrder(order, &deliveredAt))
+	}
+	for key, cents := range completedCents {
+		earnings.Completed = append(earnings.Completed, EarningsPeriod{
+			PeriodStart: key.periodStart,
+			Currency:    key.currency,
+			Orders:      completedOrders[key],
+			Amount:      utils.FromCents(cents),
+		})
+	}
+	sort.Slice(earnings.Completed, func(i, j int) bool {
+		a, b := earnings.Completed[i], earnings.Completed[j]
+		if a.PeriodStart != b.PeriodStart {
+			return a.PeriodStart < b.PeriodStart
+		}
+		return a.Currency < b.Currency
+	})
+
+	pendingOrders := make(map[string]int)
+	pendingCents := make(map[string]int64)
+	pendingLines := make([]EarningsOrder, 0, len(pending))
+	for i := range pending {
+		order := &pending[i]
+		pendingOrders[order.Currency]++
+		pendingCents[order.Currency] += orderCents(order)
+		pendingLines = append(pendingLines, earningsOrder(order, nil))
+	}
+	for currency, cents := range pendingCents {
+		earnings.Pending = append(earnings.Pending, EarningsTotal{Currency: currency, Orders: pendingOrders[currency], Amount: utils.FromCents(cents)})
+	}
+	sort.Slice(earnings.Pending, func(i, j int) bool { return earnings.Pending[i].Currency < earnings.Pending[j].Currency })
+	earnings.Orders = append(pendingLines, earnings.Orders...)
+
+	return earnings, nil
+}
+
+// startOfEarningsPeriod returns midnight UTC on the first day of the week (Monday) or month containing t
+func startOfEarningsPeriod(period string, t time.Time) time.Time {
+	if period == EarningsByMonth {
+		t = t.UTC()
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return startOfWeek(t)
+}
+
+// orderCents returns the order's price in cents, zero for an unpriced order
+func orderCents(order *models.Order) int64 {
+	if order.Price == nil {
+		return 0
+	}
+	return utils.ToCents(*order.Price)
+}
+
+// earningsOrder builds the report line for an order
+func earningsOrder(order *models.Order, deliveredAt *time.Time) EarningsOrder {
+	lineItems := order.LineItems
+	if lineItems == nil {
+		lineItems = []models.OrderLineItem{}
+	}
+	return EarningsOrder{
+		OrderID:     order.ID,
+		Description: order.Description,
+		Status:      order.Status,
+		Currency:    order.Currency,
+		Amount:      utils.FromCents(orderCents(order)),
+		DeliveredAt: deliveredAt,
+		PayoutID:    order.PayoutID,
+		LineItems:   lineItems,
+	}
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEarningsService_Earnings(t *testing.T) {
+	at := func(value string) *time.Time {
+		parsed, _ := time.Parse(time.RFC3339, value)
+		return &parsed
+	}
+	repo := newFakeOrderRepository(
+		// Delivered in the week of Monday 2026-06-01
+		models.Order{ID: 1, Status: StatusDelivered, Currency: "USD", Price: float64Ptr(40.10), TechnicianID: uintPtr(testTechnician.ID), DeliveredAt: at("2026-06-02T10:00:00Z"),
+			LineItems: []models.OrderLineItem{{Kind: models.LineItemBase, Amount: 35.10}, {Kind: models.LineItemRushFee, Amount: 5}}},
+		models.Order{ID: 2, Status: StatusDelivered, Currency: "USD", Price: float64Ptr(20.20), TechnicianID: uintPtr(testTechnician.ID), DeliveredAt: at("2026-06-07T23:00:00Z")},
+		// Delivered before delivery times were recorded, dated by shipping
+		models.Order{ID: 3, Status: StatusDelivered, Currency: "USD", Price: float64Ptr(30), TechnicianID: uintPtr(testTechnician.ID), ShippedAt: at("2026-06-08T09:00:00Z")},
+		models.Order{ID: 4, Status: StatusDelivered, Currency: "EUR", Price: float64Ptr(25), TechnicianID: uintPtr(testTechnician.ID), DeliveredAt: at("2026-06-09T09:00:00Z")},
+		// Outside the range, another technician's, and not yet earned
+		models.Order{ID: 5, Status: StatusDelivered, Currency: "USD", Price: float64Ptr(99), TechnicianID: uintPtr(testTechnician.ID), DeliveredAt: at("2026-01-05T09:00:00Z")},
+		models.Order{ID: 6, Status: StatusDelivered, Currency: "USD", Price: float64Ptr(99), TechnicianID: uintPtr(otherTech.ID), DeliveredAt: at("2026-06-02T09:00:00Z")},
+		models.Order{ID: 7, Status: StatusSubmitted, Currency: "USD", TechnicianID: nil},
+		models.Order{ID: 8, Status: StatusInProduction, Currency: "USD", Price: float64Ptr(15.05), TechnicianID: uintPtr(testTechnician.ID)},
+		models.Order{ID: 9, Status: StatusShipped, Currency: "USD", Price: float64Ptr(10), TechnicianID: uintPtr(testTechnician.ID)},
+		models.Order{ID: 10, Status: StatusRejected, Currency: "USD", Price: float64Ptr(10), TechnicianID: uintPtr(testTechnician.ID)},
+	)
+	service := NewEarningsService(repo)
+	service.now = func() time.Time { return time.Date(2026, 6, 10, 12, 0, 0, 0, time.UTC) }
+
+	_, err := service.Earnings(testCustomer, EarningsQuery{})
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+
+	_, err = service.Earnings(testTechnician, EarningsQuery{Period: "day"})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+
+	_, err = service.Earnings(testTechnician, EarningsQuery{From: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+
+	t.Run("groups delivered revenue by week", func(t *testing.T) {
+		earnings, err := service.Earnings(testTechnician, EarningsQuery{From: time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)})
+		require.NoError(t, err)
+		assert.Equal(t, EarningsByWeek, earnings.Period)
+		assert.Equal(t, []EarningsPeriod{
+			{PeriodStart: "2026-06-01", Currency: "USD", Orders: 2, Amount: 60.30},
+			{PeriodStart: "2026-06-08", Currency: "EUR", Orders: 1, Amount: 25},
+			{PeriodStart: "2026-06-08", Currency: "USD", Orders: 1, Amount: 30},
+		}, earnings.Completed)
+		assert.Equal(t, []EarningsTotal{{Currency: "USD", Orders: 2, Amount: 25.05}}, earnings.Pending)
+
+		var ids []uint
+		for _, order := range earnings.Orders {
+			ids = append(ids, order.OrderID)
+		}
+		assert.Equal(t, []uint{9, 8, 4, 3, 2, 1}, ids)
+		assert.Nil(t, earnings.Orders[0].DeliveredAt)
+		assert.Len(t, earnings.Orders[5].LineItems, 2)
+	})
+
+	t.Run("groups delivered revenue by month over the last 12 months by default", func(t *testing.T) {
+		earnings, err := service.Earnings(testTechnician, EarningsQuery{Period: EarningsByMonth})
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), earnings.From)
+		assert.Equal(t, []EarningsPeriod{
+			{PeriodStart: "2026-01-01", Currency: "USD", Orders: 1, Amount: 99},
+			{PeriodStart: "2026-06-01", Currency: "EUR", Orders: 1, Amount: 25},
+			{PeriodStart: "2026-06-01", Currency: "USD", Orders: 3, Amount: 90.30},
+		}, earnings.Completed)
+	})
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/events"
+	"github.com/kendall-kelly/kendalls-nails-api/jobs"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+)
+
+// Event consumer settings, used when events go through a broker
+const (
+	EventConsumeInterval = time.Second
+	eventConsumeBatch    = 100
+)
+
+// OrderCreated is published when an order is placed, by a customer or converted from a quote or intake draft
+type OrderCreated struct {
+	Order *models.Order `json:"order"`
+}
+
+// EventName identifies the event; it is also the webhook event name
+func (OrderCreated) EventName() string { return WebhookOrderCreated }
+
+// OrderStatusChanged is published when an order moves from PreviousStatus to its current status
+type OrderStatusChanged struct {
+	Order          *models.Order `json:"order"`
+	PreviousStatus string        `json:"previous_status"`
+}
+
+// EventName identifies the event; it is also the webhook event name
+func (OrderStatusChanged) EventName() string { return WebhookOrderStatusChanged }
+
+// MessageSent is published when a message is added to an order conversation, by a user or as a system notice
+type MessageSent struct {
+	Message *models.Message `json:"message"`
+}
+
+// EventName identifies the event; it is also the webhook event name
+func (MessageSent) EventName() string { return WebhookMessageCreated }
+
+// GiftInvited is published for the recipient of a gift order who has no account
+// It carries the claim code, so a mail integration can send the recipient their invitation
+type GiftInvited struct {
+	OrderID        uint      `json:"order_id"`
+	RecipientEmail string    `json:"recipient_email"`
+	RecipientName  *string   `json:"recipient_name"`
+	GiftMessage    *string   `json:"gift_message"`
+	GiverName      string    `json:"giver_name"`
+	ClaimCode      string    `json:"claim_code"`
+	ClaimExpiresAt time.Time `json:"claim_expires_at"`
+}
+
+// EventName identifies the event; it is also the webhook event name
+func (GiftInvited) EventName() string { return WebhookGiftInvited }
+
+// newGiftInvited describes the invitation to claim a gift order from giver
+func newGiftInvited(order *models.Order, invitation *models.GiftInvitation, giver *models.User, claimCode string) GiftInvited {
+	return GiftInvited{
+		OrderID:        order.ID,
+		RecipientEmail: invitation.Email,
+		RecipientName:  order.RecipientName,
+		GiftMessage:    order.GiftMessage,
+		GiverName:      giver.Name,
+		ClaimCode:      claimCode,
+		ClaimExpiresAt: invitation.ClaimExpiresAt,
+	}
+}
+
+// QuoteReviewed is published when a technician quotes or declines a guest quote request,
+// so that a mail integration can tell the visitor
+type QuoteReviewed struct {
+	Quote *models.QuoteRequest `json:"quote"`
+}
+
+// EventName identifies the event; it is also the webhook event name
+func (QuoteReviewed) EventName() string { return WebhookQuoteReviewed }
+
+var eventBus *events.Bus
+
+// SetEventBus sets where domain events are published (nil drops them)
+// It is configured once at startup, with its subscribers
+func SetEventBus(bus *events.Bus) {
+	eventBus = bus
+}
+
+// PublishEvent announces a change that has already been saved to the subscribers of the event bus
+func PublishEvent(event events.Event) {
+	if eventBus == nil {
+		return
+	}
+	eventBus.Publish(context.Background(), event)
+}
+
+// SubscribeMetrics counts order status transitions for /metrics
+func SubscribeMetrics(bus *events.Bus) {
+	events.Subscribe(bus, "metrics", func(ctx context.Context, event OrderCreated) error {
+		recordOrderTransition(event.Order)
+		return nil
+	})
+	events.Subscribe(bus, "metrics", func(ctx context.Context, event OrderStatusChanged) error {
+		recordOrderTransition(event.Order)
+		return nil
+	})
+}
+
+// SubscribeWebhooks queues every event for the webhooks subscribed to it; the event is the payload's data
+func SubscribeWebhooks(bus *events.Bus, publisher WebhookPublisher) {
+	events.Subscribe(bus, "webhooks", func(ctx context.Context, event OrderCreated) error {
+		return publisher.Publish(event.EventName(), event)
+	})
+	events.Subscribe(bus, "webhooks", func(ctx context.Context, event OrderStatusChanged) error {
+		return publisher.Publish(event.EventName(), event)
+	})
+	events.Subscribe(bus, "webhooks", func(ctx context.Context, event MessageSent) error {
+		return publisher.Publish(event.EventName(), event)
+	})
+	events.Subscribe(bus, "webhooks", func(ctx context.Context, event GiftInvited) error {
+		return publisher.Publish(event.EventName(), event)
+	})
+	events.Subscribe(bus, "webhooks", func(ctx context.Context, event QuoteReviewed) error {
+		return publisher.Publish(event.EventName(), event)
+	})
+}
+
+// SubscribeNotifications queues status changes and new messages for the users involved,
+// for long polling and the email and push senders
+func SubscribeNotifications(bus *events.Bus, notifications NotificationService) {
+	events.Subscribe(bus, "notifications", func(ctx context.Context, event OrderStatusChanged) error {
+		return notifications.NotifyOrderStatusChanged(event.Order, event.PreviousStatus)
+	})
+	events.Subscribe(bus, "notifications", func(ctx context.Context, event MessageSent) error {
+		return notifications.NotifyMessageCreated(event.Message)
+	})
+}
+
+// EventConsumerJob handles the events the broker delivers to this instance, when events go through one
+func EventConsumerJob(bus *events.Bus) jobs.Job {
+	return jobs.Job{
+		Name:     "event_consumer",
+		Interval: EventConsumeInterval,
+		Run: func(ctx context.Context) error {
+			// Keep consuming while messages arrive in full batches, so a burst does not wait for the next interval
+			for {
+				received, err := bus.Consume(ctx, eventConsumeBatch)
+				if err != nil || received < eventConsumeBatch || ctx.Err() != nil {
+					return err
+				}
+			}
+		},
+	}
+}
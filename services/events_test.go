@@ -0,0 +1,66 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/events"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingWebhookPublisher records the webhook payloads it is asked to queue
+type recordingWebhookPublisher struct {
+	events   []string
+	payloads []string
+}
+
+func (p *recordingWebhookPublisher) Publish(event string, data interface{}) error {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	p.events = append(p.events, event)
+	p.payloads = append(p.payloads, string(body))
+	return nil
+}
+
+func TestPublishEvent_Subscribers(t *testing.T) {
+	publisher := &recordingWebhookPublisher{}
+	bus := events.NewBus(nil)
+	SubscribeMetrics(bus)
+	SubscribeWebhooks(bus, publisher)
+	SetEventBus(bus)
+	defer SetEventBus(nil)
+
+	before := transitionCount(StatusShipped)
+	PublishEvent(OrderStatusChanged{Order: &models.Order{ID: 3, Status: StatusShipped}, PreviousStatus: StatusInProduction})
+	assert.Equal(t, before+1, transitionCount(StatusShipped))
+
+	name := "Sam"
+	PublishEvent(GiftInvited{OrderID: 3, RecipientEmail: "sam@example.com", RecipientName: &name, GiverName: "Customer User", ClaimCode: "code"})
+
+	assert.Equal(t, []string{WebhookOrderStatusChanged, WebhookGiftInvited}, publisher.events)
+	var statusChanged, gift map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(publisher.payloads[0]), &statusChanged))
+	assert.NoError(t, json.Unmarshal([]byte(publisher.payloads[1]), &gift))
+	assert.Equal(t, StatusInProduction, statusChanged["previous_status"])
+	assert.Equal(t, float64(3), statusChanged["order"].(map[string]interface{})["id"])
+	assert.ElementsMatch(t, []string{"order_id", "recipient_email", "recipient_name", "gift_message", "giver_name", "claim_code", "claim_expires_at"}, keysOf(gift))
+}
+
+func TestPublishEvent_WithoutBus(t *testing.T) {
+	SetEventBus(nil)
+	before := transitionCount(StatusShipped)
+	PublishEvent(OrderStatusChanged{Order: &models.Order{ID: 3, Status: StatusShipped}})
+	assert.Equal(t, before, transitionCount(StatusShipped), "events are dropped without a bus")
+}
+
+// keysOf returns the keys of a JSON object
+func keysOf(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,233 @@
+package services
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/flags"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"gorm.io/gorm"
+)
+
+// featureFlagName matches valid flag names, e.g. "negotiation" or "payments-v2"
+var featureFlagName = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,63}$`)
+
+// FeatureFlagInput holds the fields for creating or changing a feature flag
+// Nil fields are left unchanged by UpdateFlag; on creation they default to off
+type FeatureFlagInput struct {
+	Name        string // creation only
+	Description *string
+	Enabled     *bool
+	Percentage  *int
+	UserIDs     *[]uint
+}
+
+// FeatureFlagService manages the feature flags new flows are rolled out behind
+type FeatureFlagService interface {
+	// ListFlags returns every stored flag with any override from FEATURE_FLAGS (admins only)
+	ListFlags(admin *models.User) ([]models.FeatureFlag, error)
+
+	// CreateFlag stores a new flag (admins only)
+	CreateFlag(admin *models.User, input FeatureFlagInput) (*models.FeatureFlag, error)
+
+	// UpdateFlag changes who a flag is on for (admins only)
+	UpdateFlag(admin *models.User, name string, input FeatureFlagInput) (*models.FeatureFlag, error)
+
+	// DeleteFlag removes a flag, which turns it off for everyone (admins only)
+	DeleteFlag(admin *models.User, name string) error
+}
+
+// DefaultFeatureFlagService implements FeatureFlagService on top of a FeatureFlagRepository
+type DefaultFeatureFlagService struct {
+	flags repositories.FeatureFlagRepository
+	audit repositories.AuditLogRepository // nil records nothing
+}
+
+var featureFlagServiceInstance FeatureFlagService
+
+// NewFeatureFlagService creates a feature flag service using the given repository
+func NewFeatureFlagService(flags repositories.FeatureFlagRepository) *DefaultFeatureFlagService {
+	return &DefaultFeatureFlagService{flags: flags}
+}
+
+// GetFeatureFlagService returns the configured feature flag service
+// When none has been set, a service over the current database connection is returned
+func GetFeatureFlagService() FeatureFlagService {
+	if featureFlagServiceInstance != nil {
+		return featureFlagServiceInstance
+	}
+	db := config.GetDB()
+	service := NewFeatureFlagService(repositories.NewFeatureFlagRepository(db))
+	service.audit = repositories.NewAuditLogRepository(db)
+	return service
+}
+
+// SetFeatureFlagService sets the feature flag service instance (primarily for testing)
+func SetFeatureFlagService(service FeatureFlagService) {
+	featureFlagServiceInstance = service
+}
+
+// ListFlags returns every stored flag with any override from FEATURE_FLAGS (admins only)
+func (s *DefaultFeatureFlagService) ListFlags(admin *models.User) ([]models.FeatureFlag, error) {
+	if admin.Role != RoleAdmin {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only admins can manage feature flags")
+	}
+
+	stored, err := s.flags.List()
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load feature flags").Wrap(err)
+	}
+	for i := range stored {
+		withOverride(&stored[i])
+	}
+	return stored, nil
+}
+
+// CreateFlag stores a new flag (admins only)
+func (s *DefaultFeatureFlagService) CreateFlag(admin *models.User, input FeatureFlagInput) (*models.FeatureFlag, error) {
+	if admin.Role != RoleAdmin {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only admins can manage feature flags")
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if !featureFlagName.MatchString(name) {
+		return nil, apierror.Validation("Invalid feature flag", map[string]string{
+			"name": "must be 1-64 lowercase letters, digits, dots, dashes or underscores",
+		})
+	}
+	flag := &models.FeatureFlag{Name: name, UserIDs: []uint{}}
+	if err := applyFeatureFlagInput(flag, input); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.flags.FindByName(name); err == nil {
+		return nil, apierror.Conflict("FEATURE_FLAG_EXISTS", "A feature flag with this name already exists")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load feature flag").Wrap(err)
+	}
+	if err := s.flags.Create(flag); err != nil {
+		if isUniqueViolation(err) {
+			return nil, apierror.Conflict("FEATURE_FLAG_EXISTS", "A feature flag with this name already exists")
+		}
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to create feature flag").Wrap(err)
+	}
+
+	if err := recordAudit(s.audit, admin, models.AuditFeatureFlagChanged, models.AuditTargetFeatureFlag, flag.ID, nil, featureFlagAudit(flag)); err != nil {
+		return nil, err
+	}
+	flags.Refresh()
+	return withOverride(flag), nil
+}
+
+// UpdateFlag changes who a flag is on for (admins only)
+func (s *DefaultFeatureFlagService) UpdateFlag(admin *models.User, name string, input FeatureFlagInput) (*models.FeatureFlag, error) {
+	flag, err := s.findFlag(admin, name)
+	if err != nil {
+		return nil, err
+	}
+
+	before := featureFlagAudit(flag)
+	if err := applyFeatureFlagInput(flag, input); err != nil {
+		return nil, err
+	}
+	if err := s.flags.Save(flag); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to update feature flag").Wrap(err)
+	}
+
+	if err := recordAudit(s.audit, admin, models.AuditFeatureFlagChanged, models.AuditTargetFeatureFlag, flag.ID, before, featureFlagAudit(flag)); err != nil {
+		return nil, err
+	}
+	flags.Refresh()
+	return withOverride(flag), nil
+}
+
+// DeleteFlag removes a flag, which turns it off for everyone (admins only)
+func (s *DefaultFeatureFlagService) DeleteFlag(admin *models.User, name string) error {
+	flag, err := s.findFlag(admin, name)
+	if err != nil {
+		return err
+	}
+	if err := s.flags.Delete(flag); err != nil {
+		return apierror.Internal("DATABASE_ERROR", "Failed to delete feature flag").Wrap(err)
+	}
+
+	if err := recordAudit(s.audit, admin, models.AuditFeatureFlagChanged, models.AuditTargetFeatureFlag, flag.ID, featureFlagAudit(flag), nil); err != nil {
+		return err
+	}
+	flags.Refresh()
+	return nil
+}
+
+// findFlag loads the flag with the name given in a request path
+func (s *DefaultFeatureFlagService) findFlag(admin *models.User, name string) (*models.FeatureFlag, error) {
+	if admin.Role != RoleAdmin {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only admins can manage feature flags")
+	}
+
+	flag, err := s.flags.FindByName(name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apierror.NotFound("FEATURE_FLAG_NOT_FOUND", "Feature flag not found")
+		}
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load feature flag").Wrap(err)
+	}
+	return flag, nil
+}
+
+// applyFeatureFlagInput validates the given fields and copies them onto the flag
+func applyFeatureFlagInput(flag *models.FeatureFlag, input FeatureFlagInput) error {
+	if input.Percentage != nil && (*input.Percentage < 0 || *input.Percentage > 100) {
+		return apierror.Validation("Invalid feature flag", map[string]string{
+			"percentage": "must be between 0 and 100",
+		})
+	}
+
+	if input.Description != nil {
+		flag.Description = strings.TrimSpace(*input.Description)
+	}
+	if input.Enabled != nil {
+		flag.Enabled = *input.Enabled
+	}
+	if input.Percentage != nil {
+		flag.Percentage = *input.Percentage
+	}
+	if input.UserIDs != nil {
+		flag.UserIDs = uniqueIDs(*input.UserIDs)
+	}
+	return nil
+}
+
+// uniqueIDs drops zero and repeated IDs, keeping their order
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	unique := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if id != 0 && !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// withOverride sets the flag's override from FEATURE_FLAGS, if there is one
+func withOverride(flag *models.FeatureFlag) *models.FeatureFlag {
+	if enabled, forced := flags.Override(flag.Name); forced {
+		flag.Override = &enabled
+	}
+	return flag
+}
+
+// featureFlagAudit returns the audited fields of a flag
+func featureFlagAudit(flag *models.FeatureFlag) map[string]interface{} {
+	return map[string]interface{}{
+		"name":       flag.Name,
+		"enabled":    flag.Enabled,
+		"percentage": flag.Percentage,
+		"user_ids":   flag.UserIDs,
+	}
+}
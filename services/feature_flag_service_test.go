@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/flags"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// fakeFeatureFlagRepository keeps feature flags in memory, keyed by name
+type fakeFeatureFlagRepository struct {
+	flags  map[string]models.FeatureFlag
+	nextID uint
+}
+
+func newFakeFeatureFlagRepository() *fakeFeatureFlagRepository {
+	return &fakeFeatureFlagRepository{flags: make(map[string]models.FeatureFlag)}
+}
+
+func (r *fakeFeatureFlagRepository) Create(flag *models.FeatureFlag) error {
+	r.nextID++
+	flag.ID = r.nextID
+	r.flags[flag.Name] = *flag
+	return nil
+}
+
+func (r *fakeFeatureFlagRepository) FindByName(name string) (*models.FeatureFlag, error) {
+	flag, ok := r.flags[name]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &flag, nil
+}
+
+func (r *fakeFeatureFlagRepository) List() ([]models.FeatureFlag, error) {
+	list := make([]models.FeatureFlag, 0, len(r.flags))
+	for _, flag := range r.flags {
+		list = append(list, flag)
+	}
+	return list, nil
+}
+
+func (r *fakeFeatureFlagRepository) Save(flag *models.FeatureFlag) error {
+	r.flags[flag.Name] = *flag
+	return nil
+}
+
+func (r *fakeFeatureFlagRepository) Delete(flag *models.FeatureFlag) error {
+	delete(r.flags, flag.Name)
+	return nil
+}
+
+func TestFeatureFlagService(t *testing.T) {
+	admin := &models.User{ID: 9, Role: RoleAdmin}
+	repo := newFakeFeatureFlagRepository()
+	audit := &fakeAuditLogRepository{}
+	service := NewFeatureFlagService(repo)
+	service.audit = audit
+	flags.SetLoader(repo.List)
+	defer flags.SetLoader(nil)
+	jane := flags.WithUser(context.Background(), 7)
+
+	enabled, percentage := true, 101
+	_, err := service.CreateFlag(testTechnician, FeatureFlagInput{Name: "negotiation"})
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+	_, err = service.CreateFlag(admin, FeatureFlagInput{Name: "Negotiation Flow"})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+	_, err = service.CreateFlag(admin, FeatureFlagInput{Name: "negotiation", Percentage: &percentage})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+
+	// New flags are off until rolled out
+	flag, err := service.CreateFlag(admin, FeatureFlagInput{Name: "negotiation", UserIDs: &[]uint{7, 7, 0}})
+	assert.NoError(t, err)
+	assert.False(t, flag.Enabled)
+	assert.Equal(t, []uint{7}, flag.UserIDs)
+	assert.True(t, flags.Enabled(jane, "negotiation"))
+	_, err = service.CreateFlag(admin, FeatureFlagInput{Name: "negotiation"})
+	assertAPIError(t, err, http.StatusConflict, "FEATURE_FLAG_EXISTS")
+
+	// Changes apply at once on this instance and leave other fields alone
+	flag, err = service.UpdateFlag(admin, "negotiation", FeatureFlagInput{Enabled: &enabled})
+	assert.NoError(t, err)
+	assert.True(t, flag.Enabled)
+	assert.Equal(t, []uint{7}, flag.UserIDs)
+	assert.True(t, flags.Enabled(context.Background(), "negotiation"))
+	_, err = service.UpdateFlag(admin, "payments", FeatureFlagInput{Enabled: &enabled})
+	assertAPIError(t, err, http.StatusNotFound, "FEATURE_FLAG_NOT_FOUND")
+
+	// Overrides from FEATURE_FLAGS are reported with the stored flags
+	assert.NoError(t, flags.Configure("negotiation=false"))
+	defer func() { _ = flags.Configure("") }()
+	list, err := service.ListFlags(admin)
+	assert.NoError(t, err)
+	if assert.Len(t, list, 1) && assert.NotNil(t, list[0].Override) {
+		assert.False(t, *list[0].Override)
+	}
+
+	assert.NoError(t, service.DeleteFlag(admin, "negotiation"))
+	assert.Empty(t, repo.flags)
+	assertAPIError(t, service.DeleteFlag(admin, "negotiation"), http.StatusNotFound, "FEATURE_FLAG_NOT_FOUND")
+
+	// Creation, the change and the deletion are audited
+	if assert.Len(t, audit.entries, 3) {
+		assert.Equal(t, models.AuditFeatureFlagChanged, audit.entries[1].Action)
+		assert.Equal(t, models.AuditTargetFeatureFlag, audit.entries[1].TargetType)
+		assert.Equal(t, false, audit.entries[1].Before["enabled"])
+		assert.Equal(t, true, audit.entries[1].After["enabled"])
+		assert.Nil(t, audit.entries[2].After)
+	}
+}
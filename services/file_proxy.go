@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+)
+
+// FileProxyRoute is the route the API serves proxied files from, e.g. /files/uploads/1700000000_design.png
+const FileProxyRoute = "/files"
+
+// FileProxy serves stored files through the API rather than from S3 or CloudFront
+// Its links carry an expiry and an HMAC of the key, so they work like presigned URLs; links are only
+// issued in responses to users allowed to see the image, and only uploads a stored record refers to are served
+type FileProxy struct {
+	storage    Storage
+	references repositories.ImageReferenceRepository // nil serves any upload with a valid link
+	baseURL    string
+	signingKey []byte
+	expiry     time.Duration
+	now        func() time.Time
+}
+
+// NewFileProxy creates a proxy in front of storage, signing links with IMAGE_PROXY_SIGNING_KEY
+func NewFileProxy(storage Storage, cfg *config.Config) (*FileProxy, error) {
+	signingKey := []byte(cfg.ImageProxyKey)
+	if len(signingKey) == 0 {
+		// Without a configured key, links are signed with a key of this process and stop working on restart
+		signingKey = make([]byte, 32)
+		if _, err := rand.Read(signingKey); err != nil {
+			return nil, fmt.Errorf("failed to generate signing key: %w", err)
+		}
+		log.Println("IMAGE_PROXY_SIGNING_KEY is not set; image links will not survive a restart")
+	}
+
+	return &FileProxy{
+		storage:    storage,
+		baseURL:    cfg.GetImageProxyURL(),
+		signingKey: signingKey,
+		expiry:     cfg.GetImageURLExpiry(),
+		now:        time.Now,
+	}, nil
+}
+
+// URL returns a link to the file on FileProxyRoute that expires after IMAGE_URL_EXPIRY_MINUTES
+func (p *FileProxy) URL(key string) (string, error) {
+	if key == "" {
+		return "", nil
+	}
+
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	expires := p.now().Add(p.expiry).Unix()
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", p.sign(key, expires))
+	return p.baseURL + FileProxyRoute + "/" + strings.Join(segments, "/") + "?" + query.Encode(), nil
+}
+
+// ProxiedFile is a stored file opened through a FileProxy link
+type ProxiedFile struct {
+	StoredFile
+	Content   []byte
+	ExpiresAt time.Time // when the link it was opened with stops working
+}
+
+// Open returns the file a proxy link points at, after checking its signature and expiry
+func (p *FileProxy) Open(key, expires, signature string) (*ProxiedFile, error) {
+	if validateStorageKey(key) != nil || !strings.HasPrefix(key, UploadPrefix) {
+		return nil, apierror.NotFound("FILE_NOT_FOUND", "File not found")
+	}
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || !hmac.Equal([]byte(signature), []byte(p.sign(key, expiresAt))) {
+		return nil, apierror.Forbidden("INVALID_SIGNATURE", "The file link is invalid")
+	}
+	if p.now().Unix() > expiresAt {
+		return nil, apierror.Forbidden("LINK_EXPIRED", "The file link has expired")
+	}
+
+	if p.references != nil {
+		referenced, err := p.references.FindReferenced([]string{key})
+		if err != nil {
+			return nil, apierror.Internal("FILE_READ_FAILED", "Failed to read file").Wrap(err)
+		}
+		if !referenced[key] {
+			return nil, apierror.NotFound("FILE_NOT_FOUND", "File not found")
+		}
+	}
+
+	info, err := p.storage.Stat(key)
+	if err != nil {
+		return nil, fileReadError(err)
+	}
+	content, err := p.storage.Get(key)
+	if err != nil {
+		return nil, fileReadError(err)
+	}
+	return &ProxiedFile{StoredFile: info, Content: content, ExpiresAt: time.Unix(expiresAt, 0)}, nil
+}
+
+// fileReadError converts a storage error into the API error for a proxied file
+func fileReadError(err error) error {
+	if errors.Is(err, ErrFileNotFound) {
+		return apierror.NotFound("FILE_NOT_FOUND", "File not found")
+	}
+	return apierror.Internal("FILE_READ_FAILED", "Failed to read file").Wrap(err)
+}
+
+// sign returns the hex HMAC-SHA256 of a key and its expiry
+func (p *FileProxy) sign(key string, expires int64) string {
+	mac := hmac.New(sha256.New, p.signingKey)
+	mac.Write([]byte(key + "\n" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// proxiedStorage is a Storage whose signed URLs are FileProxy links
+type proxiedStorage struct {
+	Storage
+	proxy *FileProxy
+}
+
+// WithContext returns a copy whose underlying storage makes its calls for ctx
+func (s *proxiedStorage) WithContext(ctx context.Context) Storage {
+	return &proxiedStorage{Storage: StorageFor(ctx, s.Storage), proxy: s.proxy}
+}
+
+// SignedURL returns a FileProxy link to the file
+func (s *proxiedStorage) SignedURL(key string) (string, error) {
+	return s.proxy.URL(key)
+}
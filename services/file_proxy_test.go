@@ -0,0 +1,88 @@
+package services
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileProxy_SignedLinks(t *testing.T) {
+	storage := NewMockStorage()
+	require.NoError(t, storage.Put("uploads/1_my design.png", []byte("png bytes"), "image/png"))
+
+	proxy, err := NewFileProxy(storage, &config.Config{
+		ImageProxyURL:         "https://api.example.com/",
+		ImageProxyKey:         "test-signing-key",
+		ImageURLExpiryMinutes: "15",
+	})
+	require.NoError(t, err)
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	proxy.now = func() time.Time { return now }
+
+	link, err := proxy.URL("uploads/1_my design.png")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(link, "https://api.example.com/files/uploads/1_my%20design.png?"), link)
+
+	parsed, err := url.Parse(link)
+	require.NoError(t, err)
+	key := strings.TrimPrefix(parsed.Path, FileProxyRoute+"/")
+	expires, signature := parsed.Query().Get("expires"), parsed.Query().Get("signature")
+	assert.Equal(t, "1773144900", expires, "links expire after IMAGE_URL_EXPIRY_MINUTES")
+
+	file, err := proxy.Open(key, expires, signature)
+	require.NoError(t, err)
+	assert.Equal(t, "png bytes", string(file.Content))
+	assert.Equal(t, int64(len("png bytes")), file.Size)
+	assert.Equal(t, now.Add(15*time.Minute), file.ExpiresAt.UTC())
+
+	// A signature only opens the key it was issued for
+	_, err = proxy.Open("uploads/2_other.png", expires, signature)
+	assert.True(t, apierror.HasStatus(err, http.StatusForbidden))
+
+	// Moving the expiry invalidates the signature
+	_, err = proxy.Open(key, "9999999999", signature)
+	assert.True(t, apierror.HasStatus(err, http.StatusForbidden))
+
+	// A deleted file is not found even with a valid link
+	require.NoError(t, storage.Delete("uploads/1_my design.png"))
+	_, err = proxy.Open(key, expires, signature)
+	assert.True(t, apierror.HasStatus(err, http.StatusNotFound))
+
+	// The link stops working once it expires
+	proxy.now = func() time.Time { return now.Add(16 * time.Minute) }
+	_, err = proxy.Open(key, expires, signature)
+	assert.Equal(t, "LINK_EXPIRED", apierror.As(err).Code)
+}
+
+func TestFileProxy_ServesOnlyReferencedUploads(t *testing.T) {
+	storage := NewMockStorage()
+	require.NoError(t, storage.Put("uploads/1_used.png", []byte("used"), "image/png"))
+	require.NoError(t, storage.Put("uploads/2_orphan.png", []byte("orphan"), "image/png"))
+	require.NoError(t, storage.Put("exports/report.csv", []byte("a,b"), "text/csv"))
+
+	proxy, err := NewFileProxy(storage, &config.Config{ImageProxyKey: "test-signing-key"})
+	require.NoError(t, err)
+	proxy.references = &fakeImageReferenceRepository{keys: map[string]bool{"uploads/1_used.png": true}}
+
+	open := func(key string) error {
+		// Sign every key, so the checks beyond the signature are what decide
+		expires := time.Now().Add(time.Minute).Unix()
+		_, err := proxy.Open(key, strconv.FormatInt(expires, 10), proxy.sign(key, expires))
+		return err
+	}
+
+	assert.NoError(t, open("uploads/1_used.png"))
+	assert.True(t, apierror.HasStatus(open("uploads/2_orphan.png"), http.StatusNotFound), "no record refers to it")
+	assert.True(t, apierror.HasStatus(open("exports/report.csv"), http.StatusNotFound), "not an upload")
+	for _, key := range []string{"uploads/../exports/report.csv", "uploads/./1_used.png", "/uploads/1_used.png", `uploads\1_used.png`} {
+		assert.True(t, apierror.HasStatus(open(key), http.StatusNotFound), key)
+	}
+}
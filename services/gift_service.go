@@ -0,0 +1,191 @@
+package services
+
+import (
+	"errors"
+	"net/mail"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"github.com/kendall-kelly/kendalls-nails-api/utils"
+	"gorm.io/gorm"
+)
+
+// Gift order limits
+const (
+	GiftClaimWindow            = 30 * 24 * time.Hour // how long a recipient has to sign up and claim a gift
+	MaxGiftRecipientNameLength = 100
+	MaxGiftMessageLength       = 500
+)
+
+// giftClaimCodePrefix makes leaked gift claim codes easy to recognize
+const giftClaimCodePrefix = "ngc_"
+
+// GiftService lets the recipients of gift orders claim them
+type GiftService interface {
+	// ClaimGift links the gift order of an invitation to the customer holding its claim code
+	ClaimGift(customer *models.User, claimCode string) (*models.Order, error)
+}
+
+// DefaultGiftService implements GiftService on top of the gift invitation and order repositories
+type DefaultGiftService struct {
+	gifts  repositories.GiftInvitationRepository
+	orders repositories.OrderRepository
+}
+
+var giftServiceInstance GiftService
+
+// NewGiftService creates a gift service using the given repositories
+func NewGiftService(gifts repositories.GiftInvitationRepository, orders repositories.OrderRepository) *DefaultGiftService {
+	return &DefaultGiftService{gifts: gifts, orders: orders}
+}
+
+// GetGiftService returns the configured gift service
+// When none has been set, a service over the current database connection is returned
+func GetGiftService() GiftService {
+	if giftServiceInstance != nil {
+		return giftServiceInstance
+	}
+	db := config.GetDB()
+	return NewGiftService(repositories.NewGiftInvitationRepository(db), repositories.NewOrderRepository(db))
+}
+
+// SetGiftService sets the gift service instance (primarily for testing)
+func SetGiftService(service GiftService) {
+	giftServiceInstance = service
+}
+
+// ClaimGift links the gift order of an invitation to the customer holding its claim code
+// The claim code proves the invitation reached the customer, so it may be claimed from an account with another email
+func (s *DefaultGiftService) ClaimGift(customer *models.User, claimCode string) (*models.Order, error) {
+	if customer.Role != RoleCustomer {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only customers can claim gifts")
+	}
+
+	notFound := apierror.NotFound("GIFT_NOT_FOUND", "No gift matches this claim code")
+	claimCode = strings.TrimSpace(claimCode)
+	if claimCode == "" {
+		return nil, notFound
+	}
+	invitation, err := s.gifts.FindByClaimHash(utils.HashSecret(claimCode))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, notFound
+		}
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load gift").Wrap(err)
+	}
+
+	claimed := apierror.Conflict("GIFT_ALREADY_CLAIMED", "This gift has already been claimed")
+	if invitation.ClaimedAt != nil {
+		return nil, claimed
+	}
+	if invitation.GiverID == customer.ID {
+		return nil, apierror.Unprocessable("OWN_GIFT", "You cannot claim a gift you gave")
+	}
+	if !time.Now().Before(invitation.ClaimExpiresAt) {
+		return nil, apierror.Unprocessable("CLAIM_EXPIRED", "This claim code has expired")
+	}
+
+	if err := s.gifts.Claim(invitation, customer.ID); err != nil {
+		if errors.Is(err, repositories.ErrGiftClaimed) {
+			return nil, claimed
+		}
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to claim gift").Wrap(err)
+	}
+
+	order, err := reloadOrder(s.orders, invitation.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	hideGiftPrice(customer, order)
+	return order, nil
+}
+
+// applyRecipient makes the order a gift when the input names a recipient
+// A recipient who already has an account is linked to the order straight away
+func (s *DefaultOrderService) applyRecipient(order *models.Order, customer *models.User, input CreateOrderInput) error {
+	email := utils.NormalizeEmail(input.RecipientEmail)
+	name := strings.TrimSpace(input.RecipientName)
+	message := strings.TrimSpace(input.GiftMessage)
+	if email == "" {
+		if name != "" || message != "" {
+			return apierror.Validation("Invalid gift recipient", map[string]string{"recipient_email": "is required for a gift"})
+		}
+		return nil
+	}
+
+	if address, err := mail.ParseAddress(email); err != nil || address.Address != email {
+		return apierror.Validation("Invalid gift recipient", map[string]string{"recipient_email": "must be an email address"})
+	}
+	if email == utils.NormalizeEmail(customer.Email) {
+		return apierror.Validation("Invalid gift recipient", map[string]string{"recipient_email": "must not be your own email"})
+	}
+	if utf8.RuneCountInString(name) > MaxGiftRecipientNameLength {
+		return apierror.Validation("Invalid gift recipient", map[string]string{"recipient_name": "must be at most 100 characters"})
+	}
+	if utf8.RuneCountInString(message) > MaxGiftMessageLength {
+		return apierror.Validation("Invalid gift recipient", map[string]string{"gift_message": "must be at most 500 characters"})
+	}
+	if s.users == nil || s.gifts == nil {
+		return apierror.Unprocessable("GIFTS_UNAVAILABLE", "Gift orders are not available")
+	}
+
+	order.RecipientEmail = &email
+	if name != "" {
+		order.RecipientName = &name
+	}
+	if message != "" {
+		order.GiftMessage = &message
+	}
+
+	recipient, err := s.users.FindByEmail(email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return apierror.Internal("DATABASE_ERROR", "Failed to look up gift recipient").Wrap(err)
+	}
+	if recipient.ID == customer.ID {
+		return apierror.Validation("Invalid gift recipient", map[string]string{"recipient_email": "must not be your own email"})
+	}
+	order.RecipientID = &recipient.ID
+	return nil
+}
+
+// inviteRecipient records the invitation of a saved gift order whose recipient has no account
+// It returns nil and no claim code for other orders
+func (s *DefaultOrderService) inviteRecipient(customer *models.User, order *models.Order) (*models.GiftInvitation, string, error) {
+	if !order.IsGift() || order.RecipientID != nil {
+		return nil, "", nil
+	}
+
+	code, err := utils.NewSecret(giftClaimCodePrefix)
+	if err != nil {
+		return nil, "", apierror.Internal("TOKEN_ERROR", "Failed to generate gift claim code").Wrap(err)
+	}
+	invitation := &models.GiftInvitation{
+		OrderID:        order.ID,
+		GiverID:        customer.ID,
+		Email:          *order.RecipientEmail,
+		ClaimCodeHash:  utils.HashSecret(code),
+		ClaimExpiresAt: s.now().Add(GiftClaimWindow),
+	}
+	if err := s.gifts.Create(invitation); err != nil {
+		return nil, "", apierror.Internal("DATABASE_ERROR", "Failed to create gift invitation").Wrap(err)
+	}
+	return invitation, code, nil
+}
+
+// hideGiftPrice removes what the giver paid from a gift order shown to its recipient
+func hideGiftPrice(user *models.User, order *models.Order) {
+	if order.CustomerID == user.ID || !IsGiftRecipient(order, user) {
+		return
+	}
+	order.Price = nil
+	order.PriceCents = nil
+	order.LineItems = nil
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// fakeGiftInvitationRepository is an in-memory GiftInvitationRepository that links orders in the fake order repository
+type fakeGiftInvitationRepository struct {
+	invitations []*models.GiftInvitation
+	orders      *fakeOrderRepository
+}
+
+func (r *fakeGiftInvitationRepository) Create(invitation *models.GiftInvitation) error {
+	invitation.ID = uint(len(r.invitations) + 1)
+	r.invitations = append(r.invitations, invitation)
+	return nil
+}
+
+func (r *fakeGiftInvitationRepository) FindByClaimHash(hash string) (*models.GiftInvitation, error) {
+	for _, invitation := range r.invitations {
+		if invitation.ClaimCodeHash == hash {
+			copied := *invitation
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeGiftInvitationRepository) Claim(invitation *models.GiftInvitation, recipientID uint) error {
+	stored := r.invitations[invitation.ID-1]
+	if stored.ClaimedAt != nil {
+		return repositories.ErrGiftClaimed
+	}
+	now := time.Now()
+	stored.ClaimedByID, stored.ClaimedAt = &recipientID, &now
+	r.orders.orders[invitation.OrderID].RecipientID = &recipientID
+	return nil
+}
+
+func TestGiftOrders(t *testing.T) {
+	giver := &models.User{ID: 1, Role: RoleCustomer, Name: "Casey", Email: "casey@example.com"}
+	friend := &models.User{ID: 5, Role: RoleCustomer, Name: "Robin", Email: "robin@example.com"}
+	newcomer := &models.User{ID: 6, Role: RoleCustomer, Name: "Sam", Email: "sam.new@example.com"}
+	stranger := &models.User{ID: 7, Role: RoleCustomer}
+
+	orders := newFakeOrderRepository()
+	gifts := &fakeGiftInvitationRepository{orders: orders}
+	service := newTestOrderService(orders)
+	service.users = newFakeUserRepository(giver, friend)
+	service.gifts = gifts
+	claims := NewGiftService(gifts, orders)
+
+	// A recipient with an account sees the gift straight away, without its price
+	order, err := service.CreateOrder(giver, CreateOrderInput{Description: "Birthday set", Quantity: 1, RecipientEmail: " Robin@Example.com ", GiftMessage: "Happy birthday!"})
+	require.NoError(t, err)
+	assert.Equal(t, friend.ID, *order.RecipientID)
+	assert.Equal(t, "robin@example.com", *order.RecipientEmail)
+	assert.Empty(t, order.GiftClaimCode)
+	assert.Empty(t, gifts.invitations)
+
+	price := 45.0
+	orders.orders[order.ID].Price = &price
+	seen, err := service.GetOrder(friend, uintString(order.ID))
+	require.NoError(t, err)
+	assert.Nil(t, seen.Price)
+	seen, err = service.GetOrder(giver, uintString(order.ID))
+	require.NoError(t, err)
+	assert.Equal(t, 45.0, *seen.Price)
+	_, err = service.GetOrder(stranger, uintString(order.ID))
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+
+	listed, total, err := service.ListOrders(friend, ListOrdersOptions{Page: 1, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Nil(t, listed[0].Price)
+
+	// A recipient without an account is invited with a claim code
+	gift, err := service.CreateOrder(giver, CreateOrderInput{Description: "Welcome set", Quantity: 1, RecipientEmail: newcomer.Email, RecipientName: "Sam"})
+	require.NoError(t, err)
+	assert.Nil(t, gift.RecipientID)
+	require.NotEmpty(t, gift.GiftClaimCode)
+	require.Len(t, gifts.invitations, 1)
+	assert.Equal(t, newcomer.Email, gifts.invitations[0].Email)
+
+	_, err = claims.ClaimGift(giver, gift.GiftClaimCode)
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "OWN_GIFT")
+	_, err = claims.ClaimGift(newcomer, "ngc_wrong")
+	assertAPIError(t, err, http.StatusNotFound, "GIFT_NOT_FOUND")
+
+	claimed, err := claims.ClaimGift(newcomer, gift.GiftClaimCode)
+	require.NoError(t, err)
+	assert.Equal(t, newcomer.ID, *claimed.RecipientID)
+	_, err = service.GetOrder(newcomer, uintString(gift.ID))
+	assert.NoError(t, err)
+
+	_, err = claims.ClaimGift(stranger, gift.GiftClaimCode)
+	assertAPIError(t, err, http.StatusConflict, "GIFT_ALREADY_CLAIMED")
+
+	t.Run("expired claim codes are refused", func(t *testing.T) {
+		expiring, err := service.CreateOrder(giver, CreateOrderInput{Description: "Late gift", Quantity: 1, RecipientEmail: "late@example.com"})
+		require.NoError(t, err)
+		gifts.invitations[len(gifts.invitations)-1].ClaimExpiresAt = time.Now().Add(-time.Minute)
+		_, err = claims.ClaimGift(stranger, expiring.GiftClaimCode)
+		assertAPIError(t, err, http.StatusUnprocessableEntity, "CLAIM_EXPIRED")
+	})
+
+	t.Run("recipients must be someone else with a valid email", func(t *testing.T) {
+		for _, input := range []CreateOrderInput{
+			{Description: "Pink", Quantity: 1, RecipientEmail: "not-an-email"},
+			{Description: "Pink", Quantity: 1, RecipientEmail: "CASEY@example.com"},
+			{Description: "Pink", Quantity: 1, RecipientName: "Robin"},
+		} {
+			_, err := service.CreateOrder(giver, input)
+			assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+		}
+	})
+}
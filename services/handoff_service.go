@@ -0,0 +1,245 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"gorm.io/gorm"
+)
+
+// HandoffInput holds the validated fields for a hand-off request
+type HandoffInput struct {
+	ToTechnicianID uint
+	Note           string
+}
+
+// HandoffService manages transfers of in-progress orders between technicians
+type HandoffService interface {
+	// RequestHandoff asks another technician to take over an order assigned to the caller
+	RequestHandoff(technician *models.User, orderID string, input HandoffInput) (*models.OrderHandoff, error)
+
+	// RespondToHandoff accepts or declines a hand-off addressed to the caller
+	// Accepting reassigns the order and notifies the customer in the order conversation
+	RespondToHandoff(technician *models.User, orderID string, handoffID string, accept bool) (*models.OrderHandoff, error)
+
+	// ListHandoffs returns an order's hand-off history
+	ListHandoffs(user *models.User, orderID string) ([]models.OrderHandoff, error)
+
+	// ListIncoming returns the hand-offs awaiting the technician's answer
+	ListIncoming(technician *models.User) ([]models.OrderHandoff, error)
+}
+
+// DefaultHandoffService implements HandoffService on top of the order, user, and hand-off repositories
+type DefaultHandoffService struct {
+	orders   repositories.OrderRepository
+	users    repositories.UserRepository
+	handoffs repositories.HandoffRepository
+}
+
+var handoffServiceInstance HandoffService
+
+// NewHandoffService creates a hand-off service using the given repositories
+func NewHandoffService(orders repositories.OrderRepository, users repositories.UserRepository, handoffs repositories.HandoffRepository) *DefaultHandoffService {
+	return &DefaultHandoffService{orders: orders, users: users, handoffs: handoffs}
+}
+
+// GetHandoffService returns the configured hand-off service
+// When none has been set, a service over the current database connection is returned
+func GetHandoffService() HandoffService {
+	if handoffServiceInstance != nil {
+		return handoffServiceInstance
+	}
+	db := config.GetDB()
+	return NewHandoffService(repositories.NewOrderRepository(db), repositories.NewUserRepository(db), repositories.NewHandoffRepository(db))
+}
+
+// SetHandoffService sets the hand-off service instance (primarily for testing)
+func SetHandoffService(service HandoffService) {
+	handoffServiceInstance = service
+}
+
+// isHandoffStatus reports whether an order in the given status is in progress and can change hands
+func isHandoffStatus(status string) bool {
+	return status == StatusAccepted || status == StatusInProduction
+}
+
+// RequestHandoff asks another technician to take over an order assigned to the caller
+func (s *DefaultHandoffService) RequestHandoff(technician *models.User, orderID string, input HandoffInput) (*models.OrderHandoff, error) {
+	if technician.Role != RoleTechnician {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only technicians can hand off orders")
+	}
+
+	order, err := findOrder(s.orders, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsAssignedTo(order, technician) {
+		return nil, apierror.Forbidden("FORBIDDEN", "You can only hand off orders assigned to you")
+	}
+	if !isHandoffStatus(order.Status) {
+		return nil, apierror.Unprocessable("INVALID_STATE", "Only accepted or in-production orders can be handed off")
+	}
+	if input.ToTechnicianID == technician.ID {
+		return nil, apierror.Unprocessable("INVALID_HANDOFF_TARGET", "You cannot hand off an order to yourself")
+	}
+
+	target, err := s.users.FindByID(input.ToTechnicianID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load technician").Wrap(err)
+	}
+	if err != nil || target.Role != RoleTechnician {
+		return nil, apierror.Unprocessable("INVALID_HANDOFF_TARGET", "Orders can only be handed off to another technician")
+	}
+
+	if _, err := s.handoffs.FindPending(order.ID); err == nil {
+		return nil, apierror.Conflict("HANDOFF_PENDING", "This order already has a pending hand-off")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to check pending hand-offs").Wrap(err)
+	}
+
+	handoff := &models.OrderHandoff{
+		OrderID:          order.ID,
+		FromTechnicianID: technician.ID,
+		ToTechnicianID:   target.ID,
+		Status:           models.HandoffPending,
+	}
+	if note := strings.TrimSpace(input.Note); note != "" {
+		handoff.Note = &note
+	}
+
+	if err := s.handoffs.Create(handoff); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to create hand-off").Wrap(err)
+	}
+	return s.reload(order.ID, handoff.ID)
+}
+
+// RespondToHandoff accepts or declines a hand-off addressed to the caller
+func (s *DefaultHandoffService) RespondToHandoff(technician *models.User, orderID string, handoffID string, accept bool) (*models.OrderHandoff, error) {
+	if technician.Role != RoleTechnician {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only technicians can respond to hand-offs")
+	}
+
+	order, err := findOrder(s.orders, orderID)
+	if err != nil {
+		return nil, err
+	}
+	handoff, err := s.find(order.ID, handoffID)
+	if err != nil {
+		return nil, err
+	}
+
+	if handoff.ToTechnicianID != technician.ID {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only the requested technician can respond to this hand-off")
+	}
+	if handoff.Status != models.HandoffPending {
+		return nil, apierror.Unprocessable("HANDOFF_NOT_PENDING", "This hand-off has already been answered")
+	}
+
+	now := time.Now()
+	handoff.RespondedAt = &now
+
+	if !accept {
+		handoff.Status = models.HandoffDeclined
+		if err := s.handoffs.Save(handoff); err != nil {
+			return nil, apierror.Internal("DATABASE_ERROR", "Failed to decline hand-off").Wrap(err)
+		}
+		return s.reload(order.ID, handoff.ID)
+	}
+
+	if !isHandoffStatus(order.Status) {
+		return nil, apierror.Unprocessable("INVALID_STATE", "This order can no longer be handed off")
+	}
+
+	handoff.Status = models.HandoffAccepted
+	notice := &models.Message{
+		OrderID:  order.ID,
+		SenderID: &technician.ID,
+		Text:     fmt.Sprintf("Your order has been handed off to %s, who will finish it from here.", technician.Name),
+	}
+	if err := s.handoffs.CompleteHandoff(handoff, notice); err != nil {
+		if errors.Is(err, repositories.ErrOrderReassigned) {
+			return nil, apierror.Conflict("ORDER_REASSIGNED", "This order is no longer assigned to the technician who handed it off")
+		}
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to accept hand-off").Wrap(err)
+	}
+	PublishEvent(MessageSent{Message: notice})
+	return s.reload(order.ID, handoff.ID)
+}
+
+// ListHandoffs returns an order's hand-off history
+// Anyone who can view the order, and any technician party to one of its hand-offs, may see it
+func (s *DefaultHandoffService) ListHandoffs(user *models.User, orderID string) ([]models.OrderHandoff, error) {
+	order, err := findOrder(s.orders, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	handoffs, err := s.handoffs.ListForOrder(order.ID)
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load hand-offs").Wrap(err)
+	}
+
+	if !CanViewOrder(user, order) && !isHandoffParty(user, handoffs) {
+		return nil, apierror.Forbidden("FORBIDDEN", "You do not have permission to view this order")
+	}
+	return handoffs, nil
+}
+
+// ListIncoming returns the hand-offs awaiting the technician's answer
+func (s *DefaultHandoffService) ListIncoming(technician *models.User) ([]models.OrderHandoff, error) {
+	if technician.Role != RoleTechnician {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only technicians receive hand-offs")
+	}
+
+	handoffs, err := s.handoffs.ListPendingFor(technician.ID)
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load hand-offs").Wrap(err)
+	}
+	return handoffs, nil
+}
+
+// find loads a hand-off of the order by its path parameter
+func (s *DefaultHandoffService) find(orderID uint, handoffID string) (*models.OrderHandoff, error) {
+	id, err := strconv.ParseUint(handoffID, 10, 64)
+	if err != nil || id == 0 {
+		return nil, apierror.NotFound("HANDOFF_NOT_FOUND", "Hand-off not found")
+	}
+	handoff, err := s.handoffs.FindByID(orderID, uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apierror.NotFound("HANDOFF_NOT_FOUND", "Hand-off not found")
+		}
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load hand-off").Wrap(err)
+	}
+	return handoff, nil
+}
+
+// reload fetches a hand-off with both technicians for a complete response
+func (s *DefaultHandoffService) reload(orderID, handoffID uint) (*models.OrderHandoff, error) {
+	handoff, err := s.handoffs.FindByID(orderID, handoffID)
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load hand-off details").Wrap(err)
+	}
+	return handoff, nil
+}
+
+// isHandoffParty reports whether the user requested or was asked to take any of the hand-offs
+func isHandoffParty(user *models.User, handoffs []models.OrderHandoff) bool {
+	if user.Role != RoleTechnician {
+		return false
+	}
+	for _, handoff := range handoffs {
+		if handoff.FromTechnicianID == user.ID || handoff.ToTechnicianID == user.ID {
+			return true
+		}
+	}
+	return false
+}
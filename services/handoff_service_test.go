@@ -0,0 +1,264 @@
+package services
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// fakeUserRepository is an in-memory UserRepository keyed by ID
+type fakeUserRepository struct {
+	users map[uint]*models.User
+}
+
+func newFakeUserRepository(users ...*models.User) *fakeUserRepository {
+	repo := &fakeUserRepository{users: make(map[uint]*models.User)}
+	for _, user := range users {
+		repo.users[user.ID] = user
+	}
+	return repo
+}
+
+func (r *fakeUserRepository) FindByID(id uint) (*models.User, error) {
+	if user, ok := r.users[id]; ok {
+		return user, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeUserRepository) FindByAuth0ID(auth0ID string) (*models.User, error) {
+	for _, user := range r.users {
+		if user.Auth0ID == auth0ID {
+			return user, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeUserRepository) FindByEmail(email string) (*models.User, error) {
+	for _, user := range r.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeUserRepository) FindDeletedByAuth0ID(auth0ID string) (*models.User, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeUserRepository) CreateIfAbsent(user *models.User) (bool, error) {
+	if _, err := r.FindByAuth0ID(user.Auth0ID); err == nil {
+		return false, nil
+	}
+	user.ID = uint(len(r.users) + 1)
+	r.users[user.ID] = user
+	return true, nil
+}
+
+func (r *fakeUserRepository) Restore(user *models.User) error {
+	r.users[user.ID] = user
+	return nil
+}
+
+func (r *fakeUserRepository) UpdateRole(id uint, role string) error {
+	user, ok := r.users[id]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	updated := *user
+	updated.Role = role
+	r.users[id] = &updated
+	return nil
+}
+
+func (r *fakeUserRepository) UpdateBan(id uint, bannedAt *time.Time, reason *string) error {
+	user, ok := r.users[id]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	updated := *user
+	updated.BannedAt, updated.BanReason = bannedAt, reason
+	r.users[id] = &updated
+	return nil
+}
+
+// fakeHandoffRepository is an in-memory HandoffRepository that reassigns orders in the fake order repository
+type fakeHandoffRepository struct {
+	orders   *fakeOrderRepository
+	handoffs map[uint]*models.OrderHandoff
+	notices  []models.Message
+	nextID   uint
+}
+
+func newFakeHandoffRepository(orders *fakeOrderRepository) *fakeHandoffRepository {
+	return &fakeHandoffRepository{orders: orders, handoffs: make(map[uint]*models.OrderHandoff), nextID: 1}
+}
+
+func (r *fakeHandoffRepository) Create(handoff *models.OrderHandoff) error {
+	handoff.ID = r.nextID
+	r.nextID++
+	stored := *handoff
+	r.handoffs[handoff.ID] = &stored
+	return nil
+}
+
+func (r *fakeHandoffRepository) Save(handoff *models.OrderHandoff) error {
+	stored := *handoff
+	r.handoffs[handoff.ID] = &stored
+	return nil
+}
+
+func (r *fakeHandoffRepository) FindByID(orderID, handoffID uint) (*models.OrderHandoff, error) {
+	handoff, ok := r.handoffs[handoffID]
+	if !ok || handoff.OrderID != orderID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	found := *handoff
+	return &found, nil
+}
+
+func (r *fakeHandoffRepository) FindPending(orderID uint) (*models.OrderHandoff, error) {
+	for _, handoff := range r.handoffs {
+		if handoff.OrderID == orderID && handoff.Status == models.HandoffPending {
+			found := *handoff
+			return &found, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeHandoffRepository) ListForOrder(orderID uint) ([]models.OrderHandoff, error) {
+	var handoffs []models.OrderHandoff
+	for id := uint(1); id < r.nextID; id++ {
+		if handoff, ok := r.handoffs[id]; ok && handoff.OrderID == orderID {
+			handoffs = append(handoffs, *handoff)
+		}
+	}
+	return handoffs, nil
+}
+
+func (r *fakeHandoffRepository) ListPendingFor(technicianID uint) ([]models.OrderHandoff, error) {
+	var handoffs []models.OrderHandoff
+	for id := uint(1); id < r.nextID; id++ {
+		if handoff, ok := r.handoffs[id]; ok && handoff.ToTechnicianID == technicianID && handoff.Status == models.HandoffPending {
+			handoffs = append(handoffs, *handoff)
+		}
+	}
+	return handoffs, nil
+}
+
+func (r *fakeHandoffRepository) CompleteHandoff(handoff *models.OrderHandoff, notice *models.Message) error {
+	order := r.orders.orders[handoff.OrderID]
+	if order.TechnicianID == nil || *order.TechnicianID != handoff.FromTechnicianID {
+		return repositories.ErrOrderReassigned
+	}
+	order.TechnicianID = uintPtr(handoff.ToTechnicianID)
+	r.notices = append(r.notices, *notice)
+	return r.Save(handoff)
+}
+
+func newTestHandoffService(orders *fakeOrderRepository) (*DefaultHandoffService, *fakeHandoffRepository) {
+	handoffs := newFakeHandoffRepository(orders)
+	users := newFakeUserRepository(testCustomer, otherCustomer, testTechnician, otherTech)
+	return NewHandoffService(orders, users, handoffs), handoffs
+}
+
+func TestHandoffService_RequestHandoff(t *testing.T) {
+	repo := newFakeOrderRepository(
+		models.Order{ID: 1, CustomerID: testCustomer.ID, Status: StatusInProduction, TechnicianID: uintPtr(testTechnician.ID)},
+		models.Order{ID: 2, CustomerID: testCustomer.ID, Status: StatusShipped, TechnicianID: uintPtr(testTechnician.ID)},
+	)
+	service, _ := newTestHandoffService(repo)
+
+	_, err := service.RequestHandoff(testCustomer, "1", HandoffInput{ToTechnicianID: otherTech.ID})
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+
+	_, err = service.RequestHandoff(otherTech, "1", HandoffInput{ToTechnicianID: testTechnician.ID})
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+
+	_, err = service.RequestHandoff(testTechnician, "2", HandoffInput{ToTechnicianID: otherTech.ID})
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "INVALID_STATE")
+
+	_, err = service.RequestHandoff(testTechnician, "1", HandoffInput{ToTechnicianID: testTechnician.ID})
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "INVALID_HANDOFF_TARGET")
+
+	_, err = service.RequestHandoff(testTechnician, "1", HandoffInput{ToTechnicianID: testCustomer.ID})
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "INVALID_HANDOFF_TARGET")
+
+	_, err = service.RequestHandoff(testTechnician, "1", HandoffInput{ToTechnicianID: 99})
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "INVALID_HANDOFF_TARGET")
+
+	handoff, err := service.RequestHandoff(testTechnician, "1", HandoffInput{ToTechnicianID: otherTech.ID, Note: "  "})
+	assert.NoError(t, err)
+	assert.Equal(t, models.HandoffPending, handoff.Status)
+	assert.Nil(t, handoff.Note)
+
+	_, err = service.RequestHandoff(testTechnician, "1", HandoffInput{ToTechnicianID: otherTech.ID})
+	assertAPIError(t, err, http.StatusConflict, "HANDOFF_PENDING")
+}
+
+func TestHandoffService_RespondToHandoff(t *testing.T) {
+	repo := newFakeOrderRepository(
+		models.Order{ID: 1, CustomerID: testCustomer.ID, Status: StatusAccepted, TechnicianID: uintPtr(testTechnician.ID)},
+	)
+	service, handoffs := newTestHandoffService(repo)
+
+	declined, err := service.RequestHandoff(testTechnician, "1", HandoffInput{ToTechnicianID: otherTech.ID})
+	assert.NoError(t, err)
+
+	// Only the target can answer
+	_, err = service.RespondToHandoff(testTechnician, "1", uintString(declined.ID), true)
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+
+	_, err = service.RespondToHandoff(otherTech, "1", "99", true)
+	assertAPIError(t, err, http.StatusNotFound, "HANDOFF_NOT_FOUND")
+
+	// Declining leaves the order where it was
+	declined, err = service.RespondToHandoff(otherTech, "1", uintString(declined.ID), false)
+	assert.NoError(t, err)
+	assert.Equal(t, models.HandoffDeclined, declined.Status)
+	assert.NotNil(t, declined.RespondedAt)
+	assert.Equal(t, testTechnician.ID, *repo.orders[1].TechnicianID)
+	assert.Empty(t, handoffs.notices)
+
+	// A new request can follow a declined one, and accepting reassigns the order
+	accepted, err := service.RequestHandoff(testTechnician, "1", HandoffInput{ToTechnicianID: otherTech.ID})
+	assert.NoError(t, err)
+	accepted, err = service.RespondToHandoff(otherTech, "1", uintString(accepted.ID), true)
+	assert.NoError(t, err)
+	assert.Equal(t, models.HandoffAccepted, accepted.Status)
+	assert.Equal(t, otherTech.ID, *repo.orders[1].TechnicianID)
+	assert.Len(t, handoffs.notices, 1)
+	assert.Equal(t, otherTech.ID, *handoffs.notices[0].SenderID)
+
+	// Both hand-offs remain in the history, and the original technician can still see it
+	history, err := service.ListHandoffs(testTechnician, "1")
+	assert.NoError(t, err)
+	assert.Len(t, history, 2)
+
+	_, err = service.ListHandoffs(otherCustomer, "1")
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+}
+
+func TestHandoffService_RespondToHandoff_OrderReassigned(t *testing.T) {
+	repo := newFakeOrderRepository(
+		models.Order{ID: 1, CustomerID: testCustomer.ID, Status: StatusInProduction, TechnicianID: uintPtr(testTechnician.ID)},
+	)
+	service, _ := newTestHandoffService(repo)
+
+	handoff, err := service.RequestHandoff(testTechnician, "1", HandoffInput{ToTechnicianID: otherTech.ID})
+	assert.NoError(t, err)
+
+	// The order changed hands after the request was made
+	repo.orders[1].TechnicianID = uintPtr(99)
+
+	_, err = service.RespondToHandoff(otherTech, "1", uintString(handoff.ID), true)
+	assertAPIError(t, err, http.StatusConflict, "ORDER_REASSIGNED")
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"gorm.io/gorm"
+)
+
+// HealthCheckTimeout bounds each dependency probe of the detailed health check
+const HealthCheckTimeout = 5 * time.Second
+
+// Health statuses of the report and of each dependency
+const (
+	HealthUp   = "up"
+	HealthDown = "down"
+)
+
+// healthProbeKey is looked up in storage to check it can be reached; nothing is stored under it
+const healthProbeKey = UploadPrefix + ".health-check"
+
+// HealthCheck probes one dependency; Check returns an error when the dependency is unavailable
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// DependencyHealth is the outcome of one HealthCheck
+type DependencyHealth struct {
+	Name           string `json:"name"`
+	Status         string `json:"status"` // HealthUp or HealthDown
+	ResponseTimeMS int64  `json:"response_time_ms"`
+	Error          string `json:"error,omitempty"`
+}
+
+// HealthReport is the detailed health check response; its status is down when any dependency is
+type HealthReport struct {
+	Status       string             `json:"status"`
+	CheckedAt    time.Time          `json:"checked_at"`
+	Dependencies []DependencyHealth `json:"dependencies"`
+}
+
+// HealthService checks the dependencies the API needs to serve requests
+type HealthService interface {
+	// Report runs every check concurrently, each bounded by HealthCheckTimeout
+	Report(ctx context.Context) HealthReport
+}
+
+// DefaultHealthService implements HealthService over a fixed list of checks
+type DefaultHealthService struct {
+	checks []HealthCheck
+}
+
+var healthServiceInstance HealthService
+
+// NewHealthService creates a health service running the given checks, reported in that order
+func NewHealthService(checks ...HealthCheck) *DefaultHealthService {
+	return &DefaultHealthService{checks: checks}
+}
+
+// GetHealthService returns the configured health service
+// When none has been set, a service checking the database and file storage is returned;
+// the server sets one at startup that also checks the signing keys and background jobs
+func GetHealthService() HealthService {
+	if healthServiceInstance != nil {
+		return healthServiceInstance
+	}
+	return NewHealthService(DatabaseHealthCheck(config.GetDB()), StorageHealthCheck(GetStorage()))
+}
+
+// SetHealthService sets the health service instance (primarily for testing)
+func SetHealthService(service HealthService) {
+	healthServiceInstance = service
+}
+
+// Report runs every check concurrently, each bounded by HealthCheckTimeout
+func (s *DefaultHealthService) Report(ctx context.Context) HealthReport {
+	report := HealthReport{
+		Status:       HealthUp,
+		CheckedAt:    time.Now().UTC(),
+		Dependencies: make([]DependencyHealth, len(s.checks)),
+	}
+
+	var wg sync.WaitGroup
+	for i, check := range s.checks {
+		wg.Add(1)
+		go func(i int, check HealthCheck) {
+			defer wg.Done()
+			report.Dependencies[i] = runHealthCheck(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	for _, dependency := range report.Dependencies {
+		if dependency.Status != HealthUp {
+			report.Status = HealthDown
+		}
+	}
+	return report
+}
+
+// runHealthCheck times one check, giving up on it after HealthCheckTimeout
+// Checks that ignore their context keep running in the background until they return
+func runHealthCheck(ctx context.Context, check HealthCheck) DependencyHealth {
+	ctx, cancel := context.WithTimeout(ctx, HealthCheckTimeout)
+	defer cancel()
+
+	started := time.Now()
+	result := make(chan error, 1)
+	go func() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				result <- fmt.Errorf("panic: %v", recovered)
+			}
+		}()
+		result <- check.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-result:
+	case <-ctx.Done():
+		err = fmt.Errorf("no response within %s", HealthCheckTimeout)
+	}
+
+	dependency := DependencyHealth{
+		Name:           check.Name,
+		Status:         HealthUp,
+		ResponseTimeMS: time.Since(started).Milliseconds(),
+	}
+	if err != nil {
+		dependency.Status = HealthDown
+		dependency.Error = err.Error()
+	}
+	return dependency
+}
+
+// DatabaseHealthCheck pings the database
+func DatabaseHealthCheck(db *gorm.DB) HealthCheck {
+	return HealthCheck{Name: "database", Check: func(ctx context.Context) error {
+		if db == nil {
+			return fmt.Errorf("database is not connected")
+		}
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	}}
+}
+
+// StorageHealthCheck looks up a key nothing is stored under, which only reaches file storage
+// S3 answers it without listing the bucket; a "not found" answer means storage is up
+func StorageHealthCheck(storage Storage) HealthCheck {
+	return HealthCheck{Name: "storage", Check: func(ctx context.Context) error {
+		if storage == nil {
+			return fmt.Errorf("storage is not initialized")
+		}
+		if _, err := storage.Stat(healthProbeKey); err != nil && !errors.Is(err, ErrFileNotFound) {
+			return err
+		}
+		return nil
+	}}
+}
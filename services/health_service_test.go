@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// failingStorage is a Storage whose lookups fail as if it could not be reached
+type failingStorage struct {
+	Storage
+}
+
+func (failingStorage) Stat(key string) (StoredFile, error) {
+	return StoredFile{}, errors.New("connection refused")
+}
+
+func TestHealthService_Report(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+
+	// A missing probe file means storage answered
+	healthy := NewHealthService(DatabaseHealthCheck(db), StorageHealthCheck(NewMockStorage()))
+	report := healthy.Report(context.Background())
+	assert.Equal(t, HealthUp, report.Status)
+	if assert.Len(t, report.Dependencies, 2) {
+		assert.Equal(t, DependencyHealth{Name: "database", Status: HealthUp, ResponseTimeMS: report.Dependencies[0].ResponseTimeMS}, report.Dependencies[0])
+		assert.Equal(t, "storage", report.Dependencies[1].Name)
+		assert.Equal(t, HealthUp, report.Dependencies[1].Status)
+	}
+
+	// One dependency down takes the report down; the others are still reported
+	unhealthy := NewHealthService(
+		DatabaseHealthCheck(db),
+		StorageHealthCheck(failingStorage{}),
+		HealthCheck{Name: "jobs", Check: func(ctx context.Context) error { panic("boom") }},
+	)
+	report = unhealthy.Report(context.Background())
+	assert.Equal(t, HealthDown, report.Status)
+	assert.Equal(t, HealthUp, report.Dependencies[0].Status)
+	assert.Equal(t, HealthDown, report.Dependencies[1].Status)
+	assert.Equal(t, "connection refused", report.Dependencies[1].Error)
+	assert.Equal(t, "panic: boom", report.Dependencies[2].Error)
+}
+
+func TestHealthService_Timeout(t *testing.T) {
+	// A check ignoring its context is abandoned after HealthCheckTimeout
+	release := make(chan struct{})
+	defer close(release)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	report := NewHealthService(HealthCheck{Name: "jwks", Check: func(context.Context) error {
+		<-release
+		return nil
+	}}).Report(ctx)
+	assert.Equal(t, HealthDown, report.Status)
+	assert.Contains(t, report.Dependencies[0].Error, "no response within")
+	assert.Less(t, report.Dependencies[0].ResponseTimeMS, int64(1000))
+}
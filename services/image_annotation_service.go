@@ -0,0 +1,137 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"gorm.io/gorm"
+)
+
+// AnnotationInput holds the fields of a new image annotation
+// X and Y are fractions of the image's width and height, measured from its top-left corner
+type AnnotationInput struct {
+	X    float64
+	Y    float64
+	Text string
+}
+
+// ImageAnnotationService lets technicians point at a spot on an order image in the order conversation
+type ImageAnnotationService interface {
+	// Annotate posts a message anchored to a point on the order's design image ("design") or on a progress photo
+	// (its update ID), and returns it with its annotation
+	Annotate(technician *models.User, orderID string, imageID string, input AnnotationInput) (*models.Message, error)
+}
+
+// DefaultImageAnnotationService implements ImageAnnotationService on top of the order, progress update and
+// annotation repositories; annotation text goes through the message content filter like any other message
+type DefaultImageAnnotationService struct {
+	orders      repositories.OrderRepository
+	updates     repositories.ProgressUpdateRepository
+	annotations repositories.ImageAnnotationRepository
+	filter      MessageFilterService
+}
+
+var imageAnnotationServiceInstance ImageAnnotationService
+
+// NewImageAnnotationService creates an image annotation service using the given repositories and message filter
+func NewImageAnnotationService(orders repositories.OrderRepository, updates repositories.ProgressUpdateRepository, annotations repositories.ImageAnnotationRepository, filter MessageFilterService) *DefaultImageAnnotationService {
+	return &DefaultImageAnnotationService{orders: orders, updates: updates, annotations: annotations, filter: filter}
+}
+
+// GetImageAnnotationService returns the configured image annotation service
+// When none has been set, a service over the current database connection is returned
+func GetImageAnnotationService() ImageAnnotationService {
+	if imageAnnotationServiceInstance != nil {
+		return imageAnnotationServiceInstance
+	}
+	db := config.GetDB()
+	return NewImageAnnotationService(repositories.NewOrderRepository(db), repositories.NewProgressUpdateRepository(db), repositories.NewImageAnnotationRepository(db), GetMessageFilterService())
+}
+
+// SetImageAnnotationService sets the image annotation service instance (primarily for testing)
+func SetImageAnnotationService(service ImageAnnotationService) {
+	imageAnnotationServiceInstance = service
+}
+
+// Annotate posts a message anchored to a point on one of the order's images
+func (s *DefaultImageAnnotationService) Annotate(technician *models.User, orderID string, imageID string, input AnnotationInput) (*models.Message, error) {
+	if technician.Role != RoleTechnician {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only technicians can annotate order images")
+	}
+
+	order, err := findOrder(s.orders, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if !IsAssignedTo(order, technician) {
+		return nil, apierror.Forbidden("FORBIDDEN", "You can only annotate images of orders assigned to you")
+	}
+
+	imageID, imageKey, err := s.findImage(order, imageID)
+	if err != nil {
+		return nil, err
+	}
+
+	text := strings.TrimSpace(input.Text)
+	if text == "" {
+		return nil, apierror.Validation("Text is required", nil)
+	}
+	if input.X < 0 || input.X > 1 || input.Y < 0 || input.Y > 1 {
+		return nil, apierror.Validation("Coordinates must be fractions of the image size between 0 and 1", map[string]interface{}{
+			"x": input.X,
+			"y": input.Y,
+		})
+	}
+	if text, err = s.filter.Screen(technician, order.ID, text); err != nil {
+		return nil, err
+	}
+
+	message := &models.Message{OrderID: order.ID, SenderID: &technician.ID, Text: text}
+	annotation := &models.ImageAnnotation{
+		OrderID:    order.ID,
+		ImageID:    imageID,
+		ImageS3Key: imageKey,
+		X:          input.X,
+		Y:          input.Y,
+	}
+	if err := s.annotations.Create(message, annotation); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to create annotation").Wrap(err)
+	}
+
+	created, err := s.annotations.FindMessage(message.ID)
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load annotation details").Wrap(err)
+	}
+	PublishEvent(MessageSent{Message: created})
+	return created, nil
+}
+
+// findImage resolves an image ID of the order to its canonical form and the storage key of the image
+func (s *DefaultImageAnnotationService) findImage(order *models.Order, imageID string) (string, string, error) {
+	notFound := apierror.NotFound("IMAGE_NOT_FOUND", "Image not found")
+
+	if imageID == models.DesignImageID {
+		if order.ImageS3Key == nil || *order.ImageS3Key == "" {
+			return "", "", notFound
+		}
+		return imageID, *order.ImageS3Key, nil
+	}
+
+	id, err := strconv.ParseUint(imageID, 10, 64)
+	if err != nil || id == 0 {
+		return "", "", notFound
+	}
+	update, err := s.updates.FindByID(order.ID, uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", "", notFound
+		}
+		return "", "", apierror.Internal("DATABASE_ERROR", "Failed to load progress update").Wrap(err)
+	}
+	return strconv.FormatUint(id, 10), update.ImageS3Key, nil
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// fakeImageAnnotationRepository is an in-memory ImageAnnotationRepository
+type fakeImageAnnotationRepository struct {
+	messages map[uint]*models.Message
+	nextID   uint
+}
+
+func newFakeImageAnnotationRepository() *fakeImageAnnotationRepository {
+	return &fakeImageAnnotationRepository{messages: make(map[uint]*models.Message), nextID: 1}
+}
+
+func (r *fakeImageAnnotationRepository) Create(message *models.Message, annotation *models.ImageAnnotation) error {
+	message.ID = r.nextID
+	annotation.ID = r.nextID
+	annotation.MessageID = message.ID
+	r.nextID++
+	stored := *message
+	storedAnnotation := *annotation
+	stored.Annotation = &storedAnnotation
+	r.messages[message.ID] = &stored
+	return nil
+}
+
+func (r *fakeImageAnnotationRepository) FindMessage(messageID uint) (*models.Message, error) {
+	message, ok := r.messages[messageID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	found := *message
+	return &found, nil
+}
+
+func TestImageAnnotationService_Annotate(t *testing.T) {
+	designKey := "uploads/design.png"
+	repo := newFakeOrderRepository(
+		models.Order{ID: 1, CustomerID: testCustomer.ID, Status: StatusInProduction, TechnicianID: uintPtr(testTechnician.ID), ImageS3Key: &designKey},
+		models.Order{ID: 2, CustomerID: testCustomer.ID, Status: StatusAccepted, TechnicianID: uintPtr(testTechnician.ID)},
+	)
+	updates := newFakeProgressUpdateRepository()
+	assert.NoError(t, updates.Create(&models.ProgressUpdate{OrderID: 1, AuthorID: testTechnician.ID, ImageS3Key: "uploads/progress.png"}, nil))
+	annotations := newFakeImageAnnotationRepository()
+	violations := &fakeMessageViolationRepository{}
+	filter := NewMessageFilterService(violations, NewMessageFilter([]string{"darn"}, config.MessageFilterBlock, nil))
+	service := NewImageAnnotationService(repo, updates, annotations, filter)
+
+	point := AnnotationInput{X: 0.25, Y: 0.75, Text: " Which finger gets this gem? "}
+
+	_, err := service.Annotate(testCustomer, "1", models.DesignImageID, point)
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+	_, err = service.Annotate(otherTech, "1", models.DesignImageID, point)
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+
+	// An order without a design image, and images that don't belong to the order, can't be annotated
+	_, err = service.Annotate(testTechnician, "2", models.DesignImageID, point)
+	assertAPIError(t, err, http.StatusNotFound, "IMAGE_NOT_FOUND")
+	_, err = service.Annotate(testTechnician, "2", "1", point)
+	assertAPIError(t, err, http.StatusNotFound, "IMAGE_NOT_FOUND")
+	_, err = service.Annotate(testTechnician, "1", "cover", point)
+	assertAPIError(t, err, http.StatusNotFound, "IMAGE_NOT_FOUND")
+
+	_, err = service.Annotate(testTechnician, "1", models.DesignImageID, AnnotationInput{X: -0.1, Y: 0.5, Text: "Off the image"})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+	_, err = service.Annotate(testTechnician, "1", models.DesignImageID, AnnotationInput{X: 0.5, Y: 0.5, Text: "  "})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+
+	message, err := service.Annotate(testTechnician, "1", models.DesignImageID, point)
+	assert.NoError(t, err)
+	assert.Equal(t, "Which finger gets this gem?", message.Text)
+	assert.Equal(t, testTechnician.ID, *message.SenderID)
+	assert.Equal(t, "uploads/design.png", message.Annotation.ImageS3Key)
+	assert.Equal(t, 0.25, message.Annotation.X)
+
+	// Progress photos are identified by their update ID, in canonical form
+	message, err = service.Annotate(testTechnician, "1", "01", point)
+	assert.NoError(t, err)
+	assert.Equal(t, "1", message.Annotation.ImageID)
+	assert.Equal(t, "uploads/progress.png", message.Annotation.ImageS3Key)
+
+	// Annotation text goes through the content filter like any other message
+	_, err = service.Annotate(testTechnician, "1", models.DesignImageID, AnnotationInput{X: 0.5, Y: 0.5, Text: "Darn chip here"})
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "MESSAGE_BLOCKED")
+	assert.Len(t, violations.violations, 1)
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"gorm.io/gorm"
+)
+
+// ImageReviewStatuses lists the verdicts an admin can give a held image
+var ImageReviewStatuses = []string{models.ImageApproved, models.ImageRejected}
+
+// ImageModerationStatuses lists every moderation status, for filtering the review queue
+var ImageModerationStatuses = []string{models.ImagePendingReview, models.ImageApproved, models.ImageRejected}
+
+var imageModerator ImageModerator
+
+// SetImageModerator sets the provider design images are screened with (nil switches moderation off)
+// It is configured once at startup when IMAGE_MODERATION_URL is set
+func SetImageModerator(moderator ImageModerator) {
+	imageModerator = moderator
+}
+
+// ImageModerationService screens customers' design images before technicians can see them
+type ImageModerationService interface {
+	// ScreenImage runs a newly uploaded design image past the moderation provider and records the verdict
+	// Nothing is recorded while moderation is off; images the provider flags, or fails to screen, are held for review
+	ScreenImage(uploaderID *uint, imageKey string, fileHeader *multipart.FileHeader) (*models.ImageModeration, error)
+
+	// ScreenImageContent screens a design image that did not arrive as a multipart file, such as a chunked upload
+	ScreenImageContent(uploaderID *uint, imageKey string, content []byte) (*models.ImageModeration, error)
+
+	// HeldImages returns the moderation status of each given image that may not be shown yet, keyed by image key
+	HeldImages(imageKeys []string) (map[string]string, error)
+
+	// ListModerations returns a page of screened images with the given status, oldest first, with the total (admins only)
+	ListModerations(admin *models.User, status string, page, limit int) ([]models.ImageModeration, int64, error)
+
+	// ReviewImage approves or rejects a screened image, overriding the provider's verdict (admins only)
+	ReviewImage(admin *models.User, moderationID string, status string) (*models.ImageModeration, error)
+}
+
+// DefaultImageModerationService implements ImageModerationService on top of an ImageModerationRepository and an ImageModerator
+type DefaultImageModerationService struct {
+	moderations repositories.ImageModerationRepository
+	moderator   ImageModerator                  // nil when moderation is off
+	audit       repositories.AuditLogRepository // nil records nothing
+	now         func() time.Time
+}
+
+var imageModerationServiceInstance ImageModerationService
+
+// NewImageModerationService creates an image moderation service; pass a nil moderator to show images unscreened
+func NewImageModerationService(moderations repositories.ImageModerationRepository, moderator ImageModerator) *DefaultImageModerationService {
+	return &DefaultImageModerationService{moderations: moderations, moderator: moderator, now: time.Now}
+}
+
+// GetImageModerationService returns the configured image moderation service
+// When none has been set, a service over the current database connection is returned
+func GetImageModerationService() ImageModerationService {
+	if imageModerationServiceInstance != nil {
+		return imageModerationServiceInstance
+	}
+	db := config.GetDB()
+	service := NewImageModerationService(repositories.NewImageModerationRepository(db), imageModerator)
+	service.audit = repositories.NewAuditLogRepository(db)
+	return service
+}
+
+// SetImageModerationService sets the image moderation service instance (primarily for testing)
+func SetImageModerationService(service ImageModerationService) {
+	imageModerationServiceInstance = service
+}
+
+// ScreenImage runs a newly uploaded design image past the moderation provider and records the verdict
+func (s *DefaultImageModerationService) ScreenImage(uploaderID *uint, imageKey string, fileHeader *multipart.FileHeader) (*models.ImageModeration, error) {
+	if s.moderator == nil {
+		return nil, nil
+	}
+
+	content, err := readUpload(fileHeader)
+	if err != nil {
+		return nil, apierror.Internal("UPLOAD_ERROR", "Failed to read uploaded image").Wrap(err)
+	}
+	return s.ScreenImageContent(uploaderID, imageKey, content)
+}
+
+// ScreenImageContent runs a design image's content past the moderation provider and records the verdict
+func (s *DefaultImageModerationService) ScreenImageContent(uploaderID *uint, imageKey string, content []byte) (*models.ImageModeration, error) {
+	if s.moderator == nil {
+		return nil, nil
+	}
+
+	moderation := &models.ImageModeration{ImageS3Key: imageKey, UploaderID: uploaderID, Status: models.ImageApproved}
+	labels, err := s.moderator.ModerateImage(content, http.DetectContentType(content))
+	switch {
+	case err != nil:
+		// Fail closed: an image nobody screened waits for an admin
+		log.Printf("Failed to screen image %s: %v", imageKey, err)
+		moderation.Status = models.ImagePendingReview
+		moderation.ScreenError = err.Error()
+	case len(labels) > 0:
+		moderation.Status = models.ImagePendingReview
+		moderation.Labels = labels
+	}
+
+	if err := s.moderations.Create(moderation); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to record image screening").Wrap(err)
+	}
+	return moderation, nil
+}
+
+// HeldImages returns the moderation status of each given image that may not be shown yet, keyed by image key
+func (s *DefaultImageModerationService) HeldImages(imageKeys []string) (map[string]string, error) {
+	held, err := s.moderations.FindHeld(imageKeys)
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to check image moderation").Wrap(err)
+	}
+	return held, nil
+}
+
+// ListModerations returns a page of screened images with the given status, oldest first, with the total
+func (s *DefaultImageModerationService) ListModerations(admin *models.User, status string, page, limit int) ([]models.ImageModeration, int64, error) {
+	if admin.Role != RoleAdmin {
+		return nil, 0, apierror.Forbidden("FORBIDDEN", "Only admins can review images")
+	}
+	if status != "" && !oneOf(status, ImageModerationStatuses) {
+		return nil, 0, apierror.Validation("Invalid status filter", map[string]string{
+			"status": "must be one of: " + strings.Join(ImageModerationStatuses, ", "),
+		})
+	}
+
+	moderations, total, err := s.moderations.List(status, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, apierror.Internal("DATABASE_ERROR", "Failed to fetch screened images").Wrap(err)
+	}
+	return moderations, total, nil
+}
+
+// ReviewImage approves or rejects a screened image, overriding the provider's verdict
+func (s *DefaultImageModerationService) ReviewImage(admin *models.User, moderationID string, status string) (*models.ImageModeration, error) {
+	if admin.Role != RoleAdmin {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only admins can review images")
+	}
+	if !oneOf(status, ImageReviewStatuses) {
+		return nil, apierror.Validation("Invalid request data", map[string]string{
+			"status": "must be one of: " + strings.Join(ImageReviewStatuses, ", "),
+		})
+	}
+
+	id, err := strconv.ParseUint(moderationID, 10, 64)
+	if err != nil || id == 0 {
+		return nil, apierror.NotFound("IMAGE_MODERATION_NOT_FOUND", "Screened image not found")
+	}
+	moderation, err := s.moderations.FindByID(uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apierror.NotFound("IMAGE_MODERATION_NOT_FOUND", "Screened image not found")
+		}
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load screened image").Wrap(err)
+	}
+
+	before := moderation.Status
+	now := s.now()
+	moderation.Status = status
+	moderation.ReviewedByID = &admin.ID
+	moderation.ReviewedAt = &now
+	if err := s.moderations.Save(moderation); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to review image").Wrap(err)
+	}
+
+	if err := recordAudit(s.audit, admin, models.AuditImageReviewed, models.AuditTargetImage, moderation.ID,
+		map[string]interface{}{"status": before}, map[string]interface{}{"status": status}); err != nil {
+		return nil, err
+	}
+	return moderation, nil
+}
+
+// readUpload reads the content of an uploaded file
+func readUpload(fileHeader *multipart.FileHeader) ([]byte, error) {
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// fakeImageModerationRepository is an in-memory ImageModerationRepository
+type fakeImageModerationRepository struct {
+	moderations []*models.ImageModeration
+}
+
+func (r *fakeImageModerationRepository) Create(moderation *models.ImageModeration) error {
+	moderation.ID = uint(len(r.moderations) + 1)
+	r.moderations = append(r.moderations, moderation)
+	return nil
+}
+
+func (r *fakeImageModerationRepository) Save(moderation *models.ImageModeration) error {
+	return nil
+}
+
+func (r *fakeImageModerationRepository) FindByID(id uint) (*models.ImageModeration, error) {
+	for _, moderation := range r.moderations {
+		if moderation.ID == id {
+			return moderation, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeImageModerationRepository) FindHeld(imageKeys []string) (map[string]string, error) {
+	held := make(map[string]string)
+	for _, moderation := range r.moderations {
+		if moderation.Status != models.ImageApproved && oneOf(moderation.ImageS3Key, imageKeys) {
+			held[moderation.ImageS3Key] = moderation.Status
+		}
+	}
+	return held, nil
+}
+
+func (r *fakeImageModerationRepository) List(status string, limit, offset int) ([]models.ImageModeration, int64, error) {
+	var moderations []models.ImageModeration
+	for _, moderation := range r.moderations {
+		if status == "" || moderation.Status == status {
+			moderations = append(moderations, *moderation)
+		}
+	}
+	return moderations, int64(len(moderations)), nil
+}
+
+func (r *fakeImageModerationRepository) DeleteByKeys(imageKeys []string) error {
+	var kept []*models.ImageModeration
+	for _, moderation := range r.moderations {
+		if !oneOf(moderation.ImageS3Key, imageKeys) {
+			kept = append(kept, moderation)
+		}
+	}
+	r.moderations = kept
+	return nil
+}
+
+// stubModerator answers every image with the same labels or error
+type stubModerator struct {
+	labels []string
+	err    error
+}
+
+func (m *stubModerator) ModerateImage(content []byte, contentType string) ([]string, error) {
+	return m.labels, m.err
+}
+
+// newUploadHeader builds the header of an uploaded PNG file
+func newUploadHeader(t *testing.T) *multipart.FileHeader {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("image", "design.png")
+	require.NoError(t, err)
+	_, _ = part.Write([]byte("\x89PNG\r\n\x1a\nfake PNG content"))
+	require.NoError(t, writer.Close())
+
+	form, err := multipart.NewReader(body, writer.Boundary()).ReadForm(1 << 20)
+	require.NoError(t, err)
+	return form.File["image"][0]
+}
+
+func TestImageModerationService_ScreenImage(t *testing.T) {
+	repo := &fakeImageModerationRepository{}
+	upload := newUploadHeader(t)
+
+	// With moderation off nothing is recorded, so the image is shown
+	moderation, err := NewImageModerationService(repo, nil).ScreenImage(uintPtr(testCustomer.ID), "uploads/off.png", upload)
+	assert.NoError(t, err)
+	assert.Nil(t, moderation)
+
+	// Clean images are approved
+	moderation, err = NewImageModerationService(repo, &stubModerator{}).ScreenImage(uintPtr(testCustomer.ID), "uploads/clean.png", upload)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ImageApproved, moderation.Status)
+
+	// Flagged images, and images the provider could not screen, are held
+	moderation, err = NewImageModerationService(repo, &stubModerator{labels: []string{"nudity"}}).ScreenImage(nil, "uploads/flagged.png", upload)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ImagePendingReview, moderation.Status)
+	assert.Equal(t, []string{"nudity"}, moderation.Labels)
+
+	moderation, err = NewImageModerationService(repo, &stubModerator{err: errors.New("timeout")}).ScreenImage(nil, "uploads/unscreened.png", upload)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ImagePendingReview, moderation.Status)
+	assert.Equal(t, "timeout", moderation.ScreenError)
+
+	held, err := NewImageModerationService(repo, nil).HeldImages([]string{"uploads/off.png", "uploads/clean.png", "uploads/flagged.png", "uploads/unscreened.png"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"uploads/flagged.png":    models.ImagePendingReview,
+		"uploads/unscreened.png": models.ImagePendingReview,
+	}, held)
+}
+
+func TestImageModerationService_ReviewImage(t *testing.T) {
+	admin := &models.User{ID: 10, Role: RoleAdmin}
+	repo := &fakeImageModerationRepository{}
+	audit := &fakeAuditLogRepository{}
+	service := NewImageModerationService(repo, nil)
+	service.audit = audit
+	_ = repo.Create(&models.ImageModeration{ImageS3Key: "uploads/flagged.png", Status: models.ImagePendingReview})
+
+	_, _, err := service.ListModerations(testTechnician, models.ImagePendingReview, 1, 20)
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+	_, _, err = service.ListModerations(admin, "flagged", 1, 20)
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+
+	_, err = service.ReviewImage(testTechnician, "1", models.ImageApproved)
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+	_, err = service.ReviewImage(admin, "1", models.ImagePendingReview)
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+	_, err = service.ReviewImage(admin, "2", models.ImageRejected)
+	assertAPIError(t, err, http.StatusNotFound, "IMAGE_MODERATION_NOT_FOUND")
+
+	moderation, err := service.ReviewImage(admin, "1", models.ImageRejected)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ImageRejected, moderation.Status)
+	assert.Equal(t, &admin.ID, moderation.ReviewedByID)
+	assert.NotNil(t, moderation.ReviewedAt)
+	if assert.Len(t, audit.entries, 1) {
+		assert.Equal(t, models.AuditImageReviewed, audit.entries[0].Action)
+		assert.Equal(t, map[string]interface{}{"status": models.ImagePendingReview}, audit.entries[0].Before)
+		assert.Equal(t, map[string]interface{}{"status": models.ImageRejected}, audit.entries[0].After)
+	}
+}
+
+func TestHTTPImageModerator(t *testing.T) {
+	var received []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		assert.Equal(t, "image/png", r.Header.Get("Content-Type"))
+		received, _ = io.ReadAll(r.Body)
+		switch r.URL.Path {
+		case "/clean":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"flagged": false})
+		case "/flagged":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"flagged": true, "labels": []string{"nudity"}})
+		case "/unlabeled":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"flagged": true})
+		default:
+			http.Error(w, "overloaded", http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	moderate := func(path string) ([]string, error) {
+		moderator := NewHTTPImageModerator(&config.Config{ImageModerationURL: server.URL + path, ImageModerationAPIKey: "secret"})
+		return moderator.ModerateImage([]byte("image"), "image/png")
+	}
+
+	labels, err := moderate("/clean")
+	assert.NoError(t, err)
+	assert.Empty(t, labels)
+	assert.Equal(t, []byte("image"), received)
+
+	labels, err = moderate("/flagged")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"nudity"}, labels)
+
+	labels, err = moderate("/unlabeled")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"flagged"}, labels)
+
+	_, err = moderate("/down")
+	assert.ErrorContains(t, err, "status 503")
+}
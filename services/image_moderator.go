@@ -0,0 +1,77 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+)
+
+// ImageModerator screens images for content that should not be shown, such as nudity or violence
+type ImageModerator interface {
+	// ModerateImage returns the labels an image was flagged for; none means it is safe to show
+	ModerateImage(content []byte, contentType string) ([]string, error)
+}
+
+// HTTPImageModerator implements ImageModerator by posting images to a moderation endpoint
+// The endpoint may be a hosted provider behind a small adapter or a locally run NSFW model
+// It receives the raw image as the request body and answers with {"flagged": bool, "labels": [...]}
+type HTTPImageModerator struct {
+	url        string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// moderationResponse is the answer of a moderation endpoint
+type moderationResponse struct {
+	Flagged bool     `json:"flagged"`
+	Labels  []string `json:"labels"`
+}
+
+// NewHTTPImageModerator creates a moderator for the configured moderation endpoint
+func NewHTTPImageModerator(cfg *config.Config) *HTTPImageModerator {
+	return &HTTPImageModerator{
+		url:        cfg.ImageModerationURL,
+		apiKey:     cfg.ImageModerationAPIKey,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// ModerateImage posts an image to the moderation endpoint and returns the labels it was flagged for
+// A flagged image without labels is reported as "flagged"
+func (m *HTTPImageModerator) ModerateImage(content []byte, contentType string) ([]string, error) {
+	req, err := http.NewRequest(http.MethodPost, m.url, bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	if m.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+m.apiKey)
+	}
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call moderation endpoint: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("moderation endpoint returned status %d: %s", resp.StatusCode, string(detail))
+	}
+	var verdict moderationResponse
+	if err := json.NewDecoder(resp.Body).Decode(&verdict); err != nil {
+		return nil, fmt.Errorf("failed to decode moderation response: %w", err)
+	}
+	if !verdict.Flagged {
+		return nil, nil
+	}
+	if len(verdict.Labels) == 0 {
+		return []string{"flagged"}, nil
+	}
+	return verdict.Labels, nil
+}
@@ -1,8 +1,14 @@
 package services
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"log"
 	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"time"
 
 	"github.com/kendall-kelly/kendalls-nails-api/utils"
 )
@@ -12,24 +18,30 @@ type ImageService interface {
 	// UploadImage validates and uploads an image file, returns the storage key
 	UploadImage(fileHeader *multipart.FileHeader) (string, error)
 
+	// StoreImage stores already validated image content under a new key derived from filename, returns the storage key
+	StoreImage(filename string, content []byte) (string, error)
+
 	// GetImageURL generates a URL for accessing an uploaded image
 	GetImageURL(imageKey string) (string, error)
 
+	// GetImage returns the content of an uploaded image
+	GetImage(imageKey string) ([]byte, error)
+
 	// DeleteImage removes an image from storage
 	DeleteImage(imageKey string) error
 }
 
-// S3ImageService implements ImageService using AWS S3 for storage
-type S3ImageService struct {
-	s3Service S3Interface
+// StorageImageService implements ImageService on top of a Storage backend
+type StorageImageService struct {
+	storage Storage
 }
 
 var imageServiceInstance ImageService
 
-// InitImageService initializes the image service with S3 backend
-func InitImageService(s3Service S3Interface) ImageService {
-	imageServiceInstance = &S3ImageService{
-		s3Service: s3Service,
+// InitImageService initializes the image service with the given storage backend
+func InitImageService(storage Storage) ImageService {
+	imageServiceInstance = &StorageImageService{
+		storage: storage,
 	}
 	return imageServiceInstance
 }
@@ -39,34 +51,70 @@ func GetImageService() ImageService {
 	return imageServiceInstance
 }
 
+// GetImageServiceFor returns the image service with its storage calls made for ctx,
+// so that uploads and deletions appear in the request's trace
+func GetImageServiceFor(ctx context.Context) ImageService {
+	if service, ok := imageServiceInstance.(*StorageImageService); ok {
+		return &StorageImageService{storage: StorageFor(ctx, service.storage)}
+	}
+	return imageServiceInstance
+}
+
 // SetImageService sets the image service instance (primarily for testing)
 func SetImageService(service ImageService) {
 	imageServiceInstance = service
 }
 
-// UploadImage validates and uploads an image file to S3
-func (s *S3ImageService) UploadImage(fileHeader *multipart.FileHeader) (string, error) {
+// UploadImage validates an image file and stores it under a new key
+func (s *StorageImageService) UploadImage(fileHeader *multipart.FileHeader) (string, error) {
 	// Validate the image file
 	if err := utils.ValidateImageFile(fileHeader); err != nil {
 		return "", err
 	}
 
-	// Upload to S3
-	s3Key, err := s.s3Service.UploadFile(fileHeader)
+	// Open and read the file
+	file, err := fileHeader.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil {
+			log.Printf("warning: failed to close file: %v", closeErr)
+		}
+	}()
+	content, err := io.ReadAll(file)
 	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return s.StoreImage(fileHeader.Filename, content)
+}
+
+// StoreImage stores image content under a new key
+func (s *StorageImageService) StoreImage(filename string, content []byte) (string, error) {
+	key := newUploadKey(filename)
+
+	// Content type comes from the file itself, as upload validation does
+	if err := s.storage.Put(key, content, http.DetectContentType(content)); err != nil {
 		return "", fmt.Errorf("failed to upload image: %w", err)
 	}
 
-	return s3Key, nil
+	return key, nil
+}
+
+// newUploadKey returns the storage key for a new upload of the named file
+// Format: uploads/{timestamp}_{filename}
+func newUploadKey(filename string) string {
+	return fmt.Sprintf("%s%d_%s", UploadPrefix, time.Now().Unix(), filepath.Base(filename))
 }
 
-// GetImageURL generates a presigned URL for accessing an image
-func (s *S3ImageService) GetImageURL(imageKey string) (string, error) {
+// GetImageURL generates a signed URL for accessing an image
+func (s *StorageImageService) GetImageURL(imageKey string) (string, error) {
 	if imageKey == "" {
 		return "", nil
 	}
 
-	url, err := s.s3Service.GetPresignedURL(imageKey)
+	url, err := s.storage.SignedURL(imageKey)
 	if err != nil {
 		return "", fmt.Errorf("failed to generate image URL: %w", err)
 	}
@@ -74,13 +122,22 @@ func (s *S3ImageService) GetImageURL(imageKey string) (string, error) {
 	return url, nil
 }
 
-// DeleteImage deletes an image from S3
-func (s *S3ImageService) DeleteImage(imageKey string) error {
+// GetImage reads an image from storage
+func (s *StorageImageService) GetImage(imageKey string) ([]byte, error) {
+	content, err := s.storage.Get(imageKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get image: %w", err)
+	}
+	return content, nil
+}
+
+// DeleteImage deletes an image from storage
+func (s *StorageImageService) DeleteImage(imageKey string) error {
 	if imageKey == "" {
 		return nil
 	}
 
-	if err := s.s3Service.DeleteFile(imageKey); err != nil {
+	if err := s.storage.Delete(imageKey); err != nil {
 		return fmt.Errorf("failed to delete image: %w", err)
 	}
 
@@ -49,8 +49,13 @@ func (m *MockImageService) UploadImage(fileHeader *multipart.FileHeader) (string
 		return "", fmt.Errorf("failed to read file: %w", err)
 	}
 
+	return m.StoreImage(fileHeader.Filename, content)
+}
+
+// StoreImage simulates storing image content
+func (m *MockImageService) StoreImage(filename string, content []byte) (string, error) {
 	// Generate mock image key
-	imageKey := fmt.Sprintf("uploads/mock_%s", fileHeader.Filename)
+	imageKey := fmt.Sprintf("uploads/mock_%s", filename)
 
 	// Store in mock storage
 	m.mu.Lock()
@@ -79,6 +84,18 @@ func (m *MockImageService) GetImageURL(imageKey string) (string, error) {
 	return fmt.Sprintf("https://test-bucket.s3.us-east-1.amazonaws.com/%s?mock=true", imageKey), nil
 }
 
+// GetImage returns the content of an image in mock storage
+func (m *MockImageService) GetImage(imageKey string) ([]byte, error) {
+	m.mu.RLock()
+	content, exists := m.uploadedImages[imageKey]
+	m.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("image not found in mock storage: %s", imageKey)
+	}
+	return content, nil
+}
+
 // DeleteImage simulates deleting an image
 func (m *MockImageService) DeleteImage(imageKey string) error {
 	if imageKey == "" {
@@ -0,0 +1,329 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/jobs"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"github.com/kendall-kelly/kendalls-nails-api/utils"
+)
+
+// Image transfer settings
+const (
+	MaxImageTransferAttempts  = 10
+	ImageTransferPollInterval = 2 * time.Second
+	imageTransferRetryBase    = 10 * time.Second // doubled after every failed attempt
+	imageTransferRetryMax     = 30 * time.Minute
+	imageTransferLease        = 5 * time.Minute // longer than an upload can take, so a claimed transfer is not made twice
+	imageTransferBatchSize    = 20
+	stagedFilePrefix          = "staged-"
+)
+
+// ImageTransferService stages order images on the API instance's disk and moves them to storage in the
+// background, so creating an order neither waits on storage nor fails when storage briefly does
+type ImageTransferService interface {
+	// Stage validates an uploaded image and saves it for this instance to move to storage
+	// The returned transfer carries the key the image will be stored under
+	Stage(fileHeader *multipart.FileHeader) (*models.ImageTransfer, error)
+
+	// AttachOrder makes the staged image the order's image once it is in storage, or right away if it already is
+	AttachOrder(transfer *models.ImageTransfer, orderID uint) error
+
+	// Discard abandons a staged image, as when it fails screening
+	Discard(transfer *models.ImageTransfer)
+
+	// UploadingOrders returns which of the given orders have an image still being moved to storage
+	UploadingOrders(orderIDs []uint) (map[uint]bool, error)
+
+	// TransferDue makes the transfers whose next attempt is due and returns how many were attempted
+	TransferDue(limit int) (int, error)
+}
+
+// DefaultImageTransferService implements ImageTransferService with files in a staging directory,
+// an ImageTransferRepository, and a Storage backend
+type DefaultImageTransferService struct {
+	transfers repositories.ImageTransferRepository
+	storage   Storage
+	dir       string
+	host      string
+	now       func() time.Time
+}
+
+// imageTransferServiceInstance is nil unless images are moved to storage in the background
+var imageTransferServiceInstance ImageTransferService
+
+// NewImageTransferService creates an image transfer service staging files in dir, creating it if it does not exist
+// Transfers are made by the instance that staged them, recognized by its hostname
+func NewImageTransferService(transfers repositories.ImageTransferRepository, storage Storage, dir string) (*DefaultImageTransferService, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve UPLOAD_STAGING_DIR: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create upload staging directory: %w", err)
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hostname: %w", err)
+	}
+	return &DefaultImageTransferService{transfers: transfers, storage: storage, dir: dir, host: host, now: time.Now}, nil
+}
+
+// GetImageTransferService returns the image transfer service, or nil when images are stored during the request
+func GetImageTransferService() ImageTransferService {
+	return imageTransferServiceInstance
+}
+
+// GetImageTransferServiceFor returns the image transfer service, running in the request transaction ctx carries, if any
+// Attaching an order in the transaction that creates it means the order is there to set the image of
+func GetImageTransferServiceFor(ctx context.Context) ImageTransferService {
+	service, ok := imageTransferServiceInstance.(*DefaultImageTransferService)
+	if !ok {
+		return imageTransferServiceInstance
+	}
+	if _, ok := config.TxFromContext(ctx); !ok {
+		return service
+	}
+	scoped := *service
+	scoped.transfers = repositories.NewImageTransferRepository(config.GetDBFor(ctx))
+	return &scoped
+}
+
+// SetImageTransferService sets the image transfer service, nil to store images during the request (primarily for testing)
+func SetImageTransferService(service ImageTransferService) {
+	imageTransferServiceInstance = service
+}
+
+// ImageTransferJob returns the background job that moves staged images to storage, including retries
+func ImageTransferJob(service ImageTransferService) jobs.Job {
+	return jobs.Job{
+		Name:     "image_transfer",
+		Interval: ImageTransferPollInterval,
+		Run: func(ctx context.Context) error {
+			_, err := service.TransferDue(imageTransferBatchSize)
+			return err
+		},
+	}
+}
+
+// Stage validates an uploaded image and saves it for this instance to move to storage
+func (s *DefaultImageTransferService) Stage(fileHeader *multipart.FileHeader) (*models.ImageTransfer, error) {
+	if err := utils.ValidateImageFile(fileHeader); err != nil {
+		return nil, uploadValidationError(err)
+	}
+	content, err := readUpload(fileHeader)
+	if err != nil {
+		return nil, apierror.Internal("IMAGE_UPLOAD_ERROR", "Failed to read image").Wrap(err)
+	}
+
+	staged, err := s.writeStagedFile(content)
+	if err != nil {
+		return nil, apierror.Internal("IMAGE_UPLOAD_ERROR", "Failed to stage image").Wrap(err)
+	}
+	transfer := &models.ImageTransfer{
+		Key:           newUploadKey(fileHeader.Filename),
+		Host:          s.host,
+		StagedPath:    staged,
+		ContentType:   http.DetectContentType(content),
+		Status:        models.TransferPending,
+		NextAttemptAt: s.now(),
+	}
+	if err := s.transfers.Create(transfer); err != nil {
+		removeStagedFile(staged)
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to stage image").Wrap(err)
+	}
+	return transfer, nil
+}
+
+// writeStagedFile saves content to a new file in the staging directory and returns its path
+func (s *DefaultImageTransferService) writeStagedFile(content []byte) (string, error) {
+	file, err := os.CreateTemp(s.dir, stagedFilePrefix+"*")
+	if err != nil {
+		return "", err
+	}
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		removeStagedFile(file.Name())
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		removeStagedFile(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
+}
+
+// AttachOrder makes the staged image the order's image once it is in storage, or right away if it already is
+// Whichever of this and the transfer finishes last sets the order's image, so the link is never missed
+func (s *DefaultImageTransferService) AttachOrder(transfer *models.ImageTransfer, orderID uint) error {
+	if err := s.transfers.AttachOrder(transfer.ID, orderID); err != nil {
+		return apierror.Internal("DATABASE_ERROR", "Failed to save order image").Wrap(err)
+	}
+	current, err := s.transfers.FindByID(transfer.ID)
+	if err != nil {
+		return apierror.Internal("DATABASE_ERROR", "Failed to save order image").Wrap(err)
+	}
+	if current.Status == models.TransferSucceeded {
+		linked, err := s.transfers.SetOrderImage(orderID, current.Key)
+		if err != nil {
+			return apierror.Internal("DATABASE_ERROR", "Failed to save order image").Wrap(err)
+		}
+		if !linked {
+			return apierror.Internal("DATABASE_ERROR", "Failed to save order image: order not found")
+		}
+	}
+	return nil
+}
+
+// Discard abandons a staged image, as when it fails screening
+// A copy already moved to storage is left for the orphaned image cleanup
+func (s *DefaultImageTransferService) Discard(transfer *models.ImageTransfer) {
+	if err := s.transfers.Delete(transfer.ID); err != nil {
+		log.Printf("Failed to discard image transfer %d: %v", transfer.ID, err)
+		return
+	}
+	removeStagedFile(transfer.StagedPath)
+}
+
+// UploadingOrders returns which of the given orders have an image still being moved to storage
+func (s *DefaultImageTransferService) UploadingOrders(orderIDs []uint) (map[uint]bool, error) {
+	ids, err := s.transfers.PendingOrderIDs(orderIDs)
+	if err != nil {
+		return nil, err
+	}
+	uploading := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		uploading[id] = true
+	}
+	return uploading, nil
+}
+
+// TransferDue makes the transfers whose next attempt is due and returns how many were attempted
+// Each transfer is claimed first, so a slow upload is not started again by the next run
+func (s *DefaultImageTransferService) TransferDue(limit int) (int, error) {
+	now := s.now()
+	due, err := s.transfers.ListDue(s.host, now, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	attempted := 0
+	for i := range due {
+		transfer := &due[i]
+		claimed, err := s.transfers.Claim(transfer, now.Add(imageTransferLease))
+		if err != nil {
+			return attempted, err
+		}
+		if !claimed {
+			continue
+		}
+		attempted++
+
+		s.transfer(transfer)
+		if err := s.transfers.Save(transfer); err != nil {
+			return attempted, err
+		}
+		if transfer.Status != models.TransferSucceeded {
+			continue
+		}
+		linked, err := s.linkOrder(transfer)
+		if err != nil {
+			return attempted, err
+		}
+		if !linked {
+			// The staged file is kept for the retry, which stores the image again and links it
+			s.retryLink(transfer)
+			if err := s.transfers.Save(transfer); err != nil {
+				return attempted, err
+			}
+			continue
+		}
+		removeStagedFile(transfer.StagedPath)
+	}
+	return attempted, nil
+}
+
+// transfer makes one attempt at moving the staged image to storage and records its outcome on the transfer
+// The staged file is kept until the order is linked, and when the transfer fails for good so that it can be recovered
+func (s *DefaultImageTransferService) transfer(transfer *models.ImageTransfer) {
+	now := s.now()
+	transfer.LastAttemptAt = &now
+	transfer.LastError = nil
+
+	content, err := os.ReadFile(transfer.StagedPath)
+	missing := errors.Is(err, fs.ErrNotExist) // nothing left to retry with
+	if err == nil {
+		err = s.storage.Put(transfer.Key, content, transfer.ContentType)
+	}
+	if err == nil {
+		transfer.Status = models.TransferSucceeded
+		transfer.CompletedAt = &now
+		return
+	}
+	s.fail(transfer, err, missing)
+}
+
+// retryLink records that a stored image's order could not be linked yet, so the transfer is made again later
+func (s *DefaultImageTransferService) retryLink(transfer *models.ImageTransfer) {
+	transfer.Status = models.TransferPending
+	transfer.CompletedAt = nil
+	s.fail(transfer, fmt.Errorf("order %d not found", *transfer.OrderID), false)
+}
+
+// fail records a failed attempt, scheduling a retry unless the transfer cannot or may no longer be retried
+func (s *DefaultImageTransferService) fail(transfer *models.ImageTransfer, err error, final bool) {
+	message := err.Error()
+	transfer.LastError = &message
+	if final || transfer.Attempts >= MaxImageTransferAttempts {
+		transfer.Status = models.TransferFailed
+		log.Printf("Gave up moving image %s to storage after %d attempts: %v", transfer.Key, transfer.Attempts, err)
+		return
+	}
+	transfer.NextAttemptAt = s.now().Add(imageTransferRetryDelay(transfer.Attempts))
+}
+
+// linkOrder sets the image of the order a stored transfer belongs to, if it has been attached yet
+// It reads the transfer again because the order may have been attached while the image was being stored,
+// and reports false when the attached order is not there to set the image of
+func (s *DefaultImageTransferService) linkOrder(transfer *models.ImageTransfer) (bool, error) {
+	current, err := s.transfers.FindByID(transfer.ID)
+	if err != nil {
+		return false, err
+	}
+	if current.OrderID == nil {
+		return true, nil
+	}
+	transfer.OrderID = current.OrderID
+	return s.transfers.SetOrderImage(*current.OrderID, current.Key)
+}
+
+// imageTransferRetryDelay returns how long to wait after the given number of failed attempts
+func imageTransferRetryDelay(attempts int) time.Duration {
+	delay := imageTransferRetryBase
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= imageTransferRetryMax {
+			return imageTransferRetryMax
+		}
+	}
+	return delay
+}
+
+// removeStagedFile deletes a staged file, logging failures other than the file already being gone
+func removeStagedFile(path string) {
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Printf("Failed to remove staged image %s: %v", path, err)
+	}
+}
@@ -0,0 +1,297 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type fakeImageTransferRepository struct {
+	transfers     []*models.ImageTransfer
+	orderImages   map[uint]string
+	missingOrders map[uint]bool // orders not committed yet
+}
+
+func (r *fakeImageTransferRepository) Create(transfer *models.ImageTransfer) error {
+	transfer.ID = uint(len(r.transfers) + 1)
+	stored := *transfer
+	r.transfers = append(r.transfers, &stored)
+	return nil
+}
+
+func (r *fakeImageTransferRepository) FindByID(id uint) (*models.ImageTransfer, error) {
+	stored := *r.transfers[id-1]
+	return &stored, nil
+}
+
+func (r *fakeImageTransferRepository) Save(transfer *models.ImageTransfer) error {
+	stored := *transfer
+	stored.OrderID = r.transfers[transfer.ID-1].OrderID
+	r.transfers[transfer.ID-1] = &stored
+	return nil
+}
+
+func (r *fakeImageTransferRepository) Delete(id uint) error {
+	r.transfers[id-1] = &models.ImageTransfer{ID: id, Status: "deleted"}
+	return nil
+}
+
+func (r *fakeImageTransferRepository) ListDue(host string, now time.Time, limit int) ([]models.ImageTransfer, error) {
+	var due []models.ImageTransfer
+	for _, transfer := range r.transfers {
+		if transfer.Host == host && transfer.Status == models.TransferPending && !transfer.NextAttemptAt.After(now) && len(due) < limit {
+			due = append(due, *transfer)
+		}
+	}
+	return due, nil
+}
+
+func (r *fakeImageTransferRepository) Claim(transfer *models.ImageTransfer, leaseUntil time.Time) (bool, error) {
+	stored := r.transfers[transfer.ID-1]
+	if stored.Status != models.TransferPending || stored.Attempts != transfer.Attempts {
+		return false, nil
+	}
+	stored.Attempts++
+	stored.NextAttemptAt = leaseUntil
+	transfer.Attempts, transfer.NextAttemptAt = stored.Attempts, leaseUntil
+	return true, nil
+}
+
+func (r *fakeImageTransferRepository) AttachOrder(id, orderID uint) error {
+	r.transfers[id-1].OrderID = &orderID
+	return nil
+}
+
+func (r *fakeImageTransferRepository) SetOrderImage(orderID uint, key string) (bool, error) {
+	if r.missingOrders[orderID] {
+		return false, nil
+	}
+	if r.orderImages == nil {
+		r.orderImages = make(map[uint]string)
+	}
+	r.orderImages[orderID] = key
+	return true, nil
+}
+
+func (r *fakeImageTransferRepository) PendingOrderIDs(orderIDs []uint) ([]uint, error) {
+	var pending []uint
+	for _, transfer := range r.transfers {
+		for _, id := range orderIDs {
+			if transfer.OrderID != nil && *transfer.OrderID == id && transfer.Status == models.TransferPending {
+				pending = append(pending, id)
+			}
+		}
+	}
+	return pending, nil
+}
+
+// flakyStorage fails the given number of Puts before storing files
+type flakyStorage struct {
+	*MockStorage
+	failures int
+}
+
+func (s *flakyStorage) Put(key string, content []byte, contentType string) error {
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("connection reset")
+	}
+	return s.MockStorage.Put(key, content, contentType)
+}
+
+func newTestImageTransferService(t *testing.T, storage Storage) (*DefaultImageTransferService, *fakeImageTransferRepository) {
+	repo := &fakeImageTransferRepository{}
+	service, err := NewImageTransferService(repo, storage, t.TempDir())
+	require.NoError(t, err)
+	return service, repo
+}
+
+func TestImageTransferService_Stage(t *testing.T) {
+	storage := NewMockStorage()
+	service, repo := newTestImageTransferService(t, storage)
+
+	transfer, err := service.Stage(newUploadHeader(t))
+	require.NoError(t, err)
+	assert.Regexp(t, `^uploads/\d+_design\.png$`, transfer.Key)
+	assert.Equal(t, "image/png", transfer.ContentType)
+	assert.Equal(t, models.TransferPending, transfer.Status)
+	assert.FileExists(t, transfer.StagedPath)
+	assert.False(t, storage.FileExists(transfer.Key), "nothing is stored during the request")
+
+	// Files that are not images are refused before anything is staged
+	_, err = service.Stage(newTextUploadHeader(t))
+	assert.True(t, apierror.HasStatus(err, http.StatusBadRequest))
+	assert.Len(t, repo.transfers, 1)
+
+	// A discarded image is never stored
+	service.Discard(transfer)
+	assert.NoFileExists(t, transfer.StagedPath)
+	attempted, err := service.TransferDue(10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, attempted)
+}
+
+func TestImageTransferService_TransferDue(t *testing.T) {
+	storage := &flakyStorage{MockStorage: NewMockStorage(), failures: 1}
+	service, repo := newTestImageTransferService(t, storage)
+	now := time.Now()
+	service.now = func() time.Time { return now }
+
+	transfer, err := service.Stage(newUploadHeader(t))
+	require.NoError(t, err)
+	require.NoError(t, service.AttachOrder(transfer, 42))
+	uploading, err := service.UploadingOrders([]uint{42, 43})
+	assert.NoError(t, err)
+	assert.Equal(t, map[uint]bool{42: true}, uploading)
+
+	// A failed attempt is retried later rather than immediately
+	attempted, err := service.TransferDue(10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, attempted)
+	stored := repo.transfers[0]
+	assert.Equal(t, models.TransferPending, stored.Status)
+	assert.Equal(t, "connection reset", *stored.LastError)
+	assert.Equal(t, now.Add(imageTransferRetryBase), stored.NextAttemptAt)
+	attempted, _ = service.TransferDue(10)
+	assert.Equal(t, 0, attempted)
+
+	// The retry stores the image, sets the order's image, and removes the staged file
+	now = now.Add(imageTransferRetryBase)
+	attempted, err = service.TransferDue(10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, attempted)
+	assert.Equal(t, models.TransferSucceeded, repo.transfers[0].Status)
+	assert.True(t, storage.FileExists(transfer.Key))
+	assert.Equal(t, transfer.Key, repo.orderImages[42])
+	assert.NoFileExists(t, transfer.StagedPath)
+
+	// An order attached after the image was stored gets it right away
+	second, err := service.Stage(newUploadHeader(t))
+	require.NoError(t, err)
+	_, err = service.TransferDue(10)
+	assert.NoError(t, err)
+	assert.NotContains(t, repo.orderImages, uint(43))
+	require.NoError(t, service.AttachOrder(second, 43))
+	assert.Equal(t, second.Key, repo.orderImages[43])
+}
+
+func TestImageTransferService_RetriesLinkingMissingOrder(t *testing.T) {
+	storage := NewMockStorage()
+	service, repo := newTestImageTransferService(t, storage)
+	now := time.Now()
+	service.now = func() time.Time { return now }
+
+	transfer, err := service.Stage(newUploadHeader(t))
+	require.NoError(t, err)
+	require.NoError(t, service.AttachOrder(transfer, 42))
+	repo.missingOrders = map[uint]bool{42: true}
+
+	// The image is stored but the order is not there yet, so the transfer is made again later
+	attempted, err := service.TransferDue(10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, attempted)
+	stored := repo.transfers[0]
+	assert.Equal(t, models.TransferPending, stored.Status)
+	assert.Equal(t, "order 42 not found", *stored.LastError)
+	assert.Equal(t, now.Add(imageTransferRetryBase), stored.NextAttemptAt)
+	assert.NotContains(t, repo.orderImages, uint(42))
+	assert.FileExists(t, transfer.StagedPath, "the staged file is kept for the retry")
+
+	// Once the order is there the retry links it
+	repo.missingOrders = nil
+	now = now.Add(imageTransferRetryBase)
+	attempted, err = service.TransferDue(10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, attempted)
+	assert.Equal(t, models.TransferSucceeded, repo.transfers[0].Status)
+	assert.Equal(t, transfer.Key, repo.orderImages[42])
+	assert.NoFileExists(t, transfer.StagedPath)
+}
+
+func TestGetImageTransferServiceFor(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, models.MigrateUp(db))
+	service, err := NewImageTransferService(repositories.NewImageTransferRepository(db), NewMockStorage(), t.TempDir())
+	require.NoError(t, err)
+	SetImageTransferService(service)
+	defer SetImageTransferService(nil)
+
+	assert.Same(t, service, GetImageTransferServiceFor(context.Background()))
+
+	// An image already stored is linked to an order the request transaction has not committed yet
+	tx := db.Begin()
+	defer tx.Rollback()
+	order := models.Order{Description: "Floral set", Quantity: 1, Status: StatusSubmitted, CustomerID: testCustomer.ID}
+	require.NoError(t, tx.Create(&order).Error)
+	transfer := models.ImageTransfer{Key: "uploads/1_design.png", Status: models.TransferSucceeded, NextAttemptAt: time.Now()}
+	require.NoError(t, tx.Create(&transfer).Error)
+
+	require.NoError(t, GetImageTransferServiceFor(config.WithTx(context.Background(), tx)).AttachOrder(&transfer, order.ID))
+	var linked models.Order
+	require.NoError(t, tx.First(&linked, order.ID).Error)
+	if assert.NotNil(t, linked.ImageS3Key) {
+		assert.Equal(t, transfer.Key, *linked.ImageS3Key)
+	}
+}
+
+func TestImageTransferService_GivesUp(t *testing.T) {
+	storage := &flakyStorage{MockStorage: NewMockStorage(), failures: MaxImageTransferAttempts}
+	service, repo := newTestImageTransferService(t, storage)
+	now := time.Now()
+	service.now = func() time.Time { return now }
+
+	transfer, err := service.Stage(newUploadHeader(t))
+	require.NoError(t, err)
+	for i := 0; i < MaxImageTransferAttempts; i++ {
+		_, err := service.TransferDue(10)
+		require.NoError(t, err)
+		now = now.Add(imageTransferRetryMax)
+	}
+	assert.Equal(t, models.TransferFailed, repo.transfers[0].Status)
+	assert.Equal(t, MaxImageTransferAttempts, repo.transfers[0].Attempts)
+	assert.FileExists(t, transfer.StagedPath, "the staged file is kept so the image can be recovered")
+
+	// A staged file that has gone missing is not retried
+	missing, err := service.Stage(newUploadHeader(t))
+	require.NoError(t, err)
+	require.NoError(t, os.Remove(missing.StagedPath))
+	_, err = service.TransferDue(10)
+	assert.NoError(t, err)
+	assert.Equal(t, models.TransferFailed, repo.transfers[1].Status)
+	assert.Equal(t, 1, repo.transfers[1].Attempts)
+}
+
+func TestImageTransferRetryDelay(t *testing.T) {
+	assert.Equal(t, imageTransferRetryBase, imageTransferRetryDelay(1))
+	assert.Equal(t, 4*imageTransferRetryBase, imageTransferRetryDelay(3))
+	assert.Equal(t, imageTransferRetryMax, imageTransferRetryDelay(MaxImageTransferAttempts))
+}
+
+// newTextUploadHeader builds the header of an uploaded file that is not an image
+func newTextUploadHeader(t *testing.T) *multipart.FileHeader {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("image", "notes.txt")
+	require.NoError(t, err)
+	_, _ = part.Write([]byte("not an image"))
+	require.NoError(t, writer.Close())
+
+	form, err := multipart.NewReader(body, writer.Boundary()).ReadForm(1 << 20)
+	require.NoError(t, err)
+	return form.File["image"][0]
+}
@@ -0,0 +1,350 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"github.com/kendall-kelly/kendalls-nails-api/utils"
+	"gorm.io/gorm"
+)
+
+// Intake token scopes
+const (
+	IntakeScopeDrafts = "drafts:create" // submit draft orders
+	IntakeScopeImages = "images:upload" // attach an inspiration image to a draft
+)
+
+// IntakeScopes lists every scope an intake token can be granted
+var IntakeScopes = []string{IntakeScopeDrafts, IntakeScopeImages}
+
+// Intake token and draft lifetimes
+const (
+	DefaultIntakeTokenTTL = 24 * time.Hour
+	MaxIntakeTokenTTL     = 7 * 24 * time.Hour
+	IntakeClaimWindow     = 30 * 24 * time.Hour // how long a guest has to sign up and claim a draft
+)
+
+// Secret prefixes make leaked intake secrets easy to recognize
+const (
+	intakeTokenPrefix = "nit_"
+	claimCodePrefix   = "ncc_"
+)
+
+// IntakeTokenInput holds the validated fields for a new intake token
+type IntakeTokenInput struct {
+	Label  string
+	Scopes []string      // defaults to every scope
+	Origin string        // optional, e.g. "https://nailsbyjane.com"
+	TTL    time.Duration // defaults to DefaultIntakeTokenTTL
+}
+
+// IntakeDraftInput holds the validated fields of a draft submitted through a widget
+type IntakeDraftInput struct {
+	GuestName   string
+	GuestEmail  string
+	Description string
+	Quantity    int
+	Rush        bool
+	ImageS3Key  *string
+}
+
+// IntakeService manages intake tokens for embedded order widgets and the drafts they submit
+type IntakeService interface {
+	// CreateToken issues an intake token for the technician's widget
+	// The returned token carries its secret in Token; only a hash is stored
+	CreateToken(technician *models.User, input IntakeTokenInput) (*models.IntakeToken, error)
+
+	// ListTokens returns the technician's intake tokens
+	ListTokens(technician *models.User) ([]models.IntakeToken, error)
+
+	// RevokeToken stops one of the technician's intake tokens from being used
+	RevokeToken(technician *models.User, tokenID string) (*models.IntakeToken, error)
+
+	// Authenticate resolves a widget request's intake token and checks that it grants the scope
+	// origin is the request's Origin header, empty for requests not made from a browser
+	Authenticate(secret, origin, scope string) (*models.IntakeToken, error)
+
+	// SubmitDraft records a draft order for the token's technician
+	// The returned draft carries its claim code in ClaimCode; only a hash is stored
+	SubmitDraft(token *models.IntakeToken, input IntakeDraftInput) (*models.IntakeDraft, error)
+
+	// ClaimDraft turns a draft into a submitted order of the customer, assigned to the draft's technician
+	ClaimDraft(customer *models.User, claimCode string) (*models.Order, error)
+}
+
+// DefaultIntakeService implements IntakeService on top of the intake and order repositories
+type DefaultIntakeService struct {
+	intake   repositories.IntakeRepository
+	orders   repositories.OrderRepository
+	consents repositories.ConsentRepository // nil skips the terms of service check
+	outbox   repositories.OutboxRepository  // nil publishes events as soon as they happen
+}
+
+var intakeServiceInstance IntakeService
+
+// NewIntakeService creates an intake service using the given repositories
+func NewIntakeService(intake repositories.IntakeRepository, orders repositories.OrderRepository) *DefaultIntakeService {
+	return &DefaultIntakeService{intake: intake, orders: orders}
+}
+
+// GetIntakeService returns the configured intake service
+// When none has been set, a service over the current database connection is returned
+func GetIntakeService() IntakeService {
+	return GetIntakeServiceFor(context.Background())
+}
+
+// GetIntakeServiceFor returns the configured intake service, running in the request transaction ctx carries, if any
+// The order a claimed draft becomes and the event announcing it are saved in that transaction
+func GetIntakeServiceFor(ctx context.Context) IntakeService {
+	if intakeServiceInstance != nil {
+		return intakeServiceInstance
+	}
+	db := config.GetDBFor(ctx)
+	service := NewIntakeService(repositories.NewIntakeRepository(db), repositories.NewOrderRepository(db))
+	service.consents = repositories.NewConsentRepository(db)
+	service.outbox = repositories.NewOutboxRepository(db)
+	return service
+}
+
+// SetIntakeService sets the intake service instance (primarily for testing)
+func SetIntakeService(service IntakeService) {
+	intakeServiceInstance = service
+}
+
+// CreateToken issues an intake token for the technician's widget
+func (s *DefaultIntakeService) CreateToken(technician *models.User, input IntakeTokenInput) (*models.IntakeToken, error) {
+	if technician.Role != RoleTechnician {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only technicians can create intake tokens")
+	}
+
+	scopes := input.Scopes
+	if len(scopes) == 0 {
+		scopes = IntakeScopes
+	}
+	for _, scope := range scopes {
+		if !isIntakeScope(scope) {
+			return nil, apierror.Validation("Invalid scope", map[string]string{
+				"scopes": "must be one of: " + strings.Join(IntakeScopes, ", "),
+			})
+		}
+	}
+
+	ttl := input.TTL
+	if ttl == 0 {
+		ttl = DefaultIntakeTokenTTL
+	}
+	if ttl < 0 || ttl > MaxIntakeTokenTTL {
+		return nil, apierror.Validation("Invalid token lifetime", map[string]string{
+			"expires_in_hours": "must be between 1 and " + strconv.Itoa(int(MaxIntakeTokenTTL/time.Hour)),
+		})
+	}
+
+	secret, err := utils.NewSecret(intakeTokenPrefix)
+	if err != nil {
+		return nil, apierror.Internal("TOKEN_ERROR", "Failed to generate intake token").Wrap(err)
+	}
+
+	token := &models.IntakeToken{
+		TechnicianID: technician.ID,
+		Label:        strings.TrimSpace(input.Label),
+		TokenHash:    utils.HashSecret(secret),
+		Scopes:       strings.Join(scopes, " "),
+		ExpiresAt:    time.Now().Add(ttl),
+	}
+	if origin := strings.TrimRight(strings.TrimSpace(input.Origin), "/"); origin != "" {
+		if !strings.HasPrefix(origin, "https://") && !strings.HasPrefix(origin, "http://") {
+			return nil, apierror.Validation("Invalid origin", map[string]string{
+				"origin": "must be a URL origin such as https://example.com",
+			})
+		}
+		token.Origin = &origin
+	}
+
+	if err := s.intake.CreateToken(token); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to create intake token").Wrap(err)
+	}
+	token.Token = secret
+	return token, nil
+}
+
+// ListTokens returns the technician's intake tokens
+func (s *DefaultIntakeService) ListTokens(technician *models.User) ([]models.IntakeToken, error) {
+	if technician.Role != RoleTechnician {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only technicians can view intake tokens")
+	}
+
+	tokens, err := s.intake.ListTokens(technician.ID)
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load intake tokens").Wrap(err)
+	}
+	return tokens, nil
+}
+
+// RevokeToken stops one of the technician's intake tokens from being used
+// Drafts already submitted with the token can still be claimed
+func (s *DefaultIntakeService) RevokeToken(technician *models.User, tokenID string) (*models.IntakeToken, error) {
+	if technician.Role != RoleTechnician {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only technicians can revoke intake tokens")
+	}
+
+	id, err := strconv.ParseUint(tokenID, 10, 64)
+	if err != nil || id == 0 {
+		return nil, apierror.NotFound("INTAKE_TOKEN_NOT_FOUND", "Intake token not found")
+	}
+	token, err := s.intake.FindToken(technician.ID, uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apierror.NotFound("INTAKE_TOKEN_NOT_FOUND", "Intake token not found")
+		}
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load intake token").Wrap(err)
+	}
+
+	if token.RevokedAt == nil {
+		now := time.Now()
+		token.RevokedAt = &now
+		if err := s.intake.SaveToken(token); err != nil {
+			return nil, apierror.Internal("DATABASE_ERROR", "Failed to revoke intake token").Wrap(err)
+		}
+	}
+	return token, nil
+}
+
+// Authenticate resolves a widget request's intake token and checks that it grants the scope
+// Browsers always send Origin on cross-site requests, so a token bound to a site cannot be
+// used from another; the binding does not stop non-browser clients holding the secret
+func (s *DefaultIntakeService) Authenticate(secret, origin, scope string) (*models.IntakeToken, error) {
+	invalid := apierror.Unauthorized("INVALID_INTAKE_TOKEN", "Intake token is missing, expired, or revoked")
+	if secret == "" {
+		return nil, invalid
+	}
+
+	token, err := s.intake.FindTokenByHash(utils.HashSecret(secret))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, invalid
+		}
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load intake token").Wrap(err)
+	}
+	if token.RevokedAt != nil || !time.Now().Before(token.ExpiresAt) {
+		return nil, invalid
+	}
+
+	if origin != "" && token.Origin != nil && !strings.EqualFold(origin, *token.Origin) {
+		return nil, apierror.Forbidden("ORIGIN_NOT_ALLOWED", "Intake token cannot be used from this site")
+	}
+	if !token.HasScope(scope) {
+		return nil, apierror.Forbidden("INSUFFICIENT_SCOPE", "Intake token does not allow this action").
+			WithDetails(map[string]string{"required_scope": scope})
+	}
+	return token, nil
+}
+
+// SubmitDraft records a draft order for the token's technician
+func (s *DefaultIntakeService) SubmitDraft(token *models.IntakeToken, input IntakeDraftInput) (*models.IntakeDraft, error) {
+	if !token.HasScope(IntakeScopeDrafts) {
+		return nil, apierror.Forbidden("INSUFFICIENT_SCOPE", "Intake token does not allow this action").
+			WithDetails(map[string]string{"required_scope": IntakeScopeDrafts})
+	}
+	if input.ImageS3Key != nil && !token.HasScope(IntakeScopeImages) {
+		return nil, apierror.Forbidden("INSUFFICIENT_SCOPE", "Intake token does not allow this action").
+			WithDetails(map[string]string{"required_scope": IntakeScopeImages})
+	}
+
+	code, err := utils.NewSecret(claimCodePrefix)
+	if err != nil {
+		return nil, apierror.Internal("TOKEN_ERROR", "Failed to generate claim code").Wrap(err)
+	}
+
+	draft := &models.IntakeDraft{
+		IntakeTokenID:  token.ID,
+		TechnicianID:   token.TechnicianID,
+		GuestName:      strings.TrimSpace(input.GuestName),
+		GuestEmail:     utils.NormalizeEmail(input.GuestEmail),
+		Description:    input.Description,
+		Quantity:       input.Quantity,
+		Rush:           input.Rush,
+		ImageS3Key:     input.ImageS3Key,
+		ClaimCodeHash:  utils.HashSecret(code),
+		ClaimExpiresAt: time.Now().Add(IntakeClaimWindow),
+	}
+	if err := s.intake.CreateDraft(draft); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to save draft order").Wrap(err)
+	}
+	draft.ClaimCode = code
+	return draft, nil
+}
+
+// ClaimDraft turns a draft into a submitted order of the customer, assigned to the draft's technician
+func (s *DefaultIntakeService) ClaimDraft(customer *models.User, claimCode string) (*models.Order, error) {
+	if customer.Role != RoleCustomer {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only customers can claim draft orders")
+	}
+	if err := requireTermsAccepted(s.consents, customer); err != nil {
+		return nil, err
+	}
+
+	notFound := apierror.NotFound("DRAFT_NOT_FOUND", "No draft order matches this claim code")
+	claimCode = strings.TrimSpace(claimCode)
+	if claimCode == "" {
+		return nil, notFound
+	}
+	draft, err := s.intake.FindDraftByClaimHash(utils.HashSecret(claimCode))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, notFound
+		}
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load draft order").Wrap(err)
+	}
+
+	claimed := apierror.Conflict("DRAFT_ALREADY_CLAIMED", "This draft order has already been claimed")
+	if draft.ClaimedAt != nil {
+		return nil, claimed
+	}
+	if !time.Now().Before(draft.ClaimExpiresAt) {
+		return nil, apierror.Unprocessable("CLAIM_EXPIRED", "This claim code has expired")
+	}
+
+	technicianID := draft.TechnicianID
+	order := &models.Order{
+		Description:  draft.Description,
+		Quantity:     draft.Quantity,
+		Status:       StatusSubmitted,
+		Rush:         draft.Rush,
+		ImageS3Key:   draft.ImageS3Key,
+		CustomerID:   customer.ID,
+		TechnicianID: &technicianID, // the guest chose this technician by ordering from their site
+		Currency:     studioCurrency(),
+	}
+	if err := s.intake.ClaimDraft(draft, order); err != nil {
+		if errors.Is(err, repositories.ErrDraftClaimed) {
+			return nil, claimed
+		}
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to claim draft order").Wrap(err)
+	}
+
+	created, err := reloadOrder(s.orders, order.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := publishThrough(s.outbox, OrderCreated{Order: created}, time.Now()); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// isIntakeScope reports whether the scope can be granted to an intake token
+func isIntakeScope(scope string) bool {
+	for _, known := range IntakeScopes {
+		if scope == known {
+			return true
+		}
+	}
+	return false
+}
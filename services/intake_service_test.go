@@ -0,0 +1,187 @@
+package services
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/utils"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// fakeIntakeRepository is an in-memory IntakeRepository that creates orders in the fake order repository
+type fakeIntakeRepository struct {
+	orders *fakeOrderRepository
+	tokens map[uint]*models.IntakeToken
+	drafts map[uint]*models.IntakeDraft
+	nextID uint
+}
+
+func newFakeIntakeRepository(orders *fakeOrderRepository) *fakeIntakeRepository {
+	return &fakeIntakeRepository{
+		orders: orders,
+		tokens: make(map[uint]*models.IntakeToken),
+		drafts: make(map[uint]*models.IntakeDraft),
+		nextID: 1,
+	}
+}
+
+func (r *fakeIntakeRepository) CreateToken(token *models.IntakeToken) error {
+	token.ID = r.nextID
+	r.nextID++
+	stored := *token
+	r.tokens[token.ID] = &stored
+	return nil
+}
+
+func (r *fakeIntakeRepository) SaveToken(token *models.IntakeToken) error {
+	stored := *token
+	r.tokens[token.ID] = &stored
+	return nil
+}
+
+func (r *fakeIntakeRepository) FindTokenByHash(hash string) (*models.IntakeToken, error) {
+	for _, token := range r.tokens {
+		if token.TokenHash == hash {
+			found := *token
+			return &found, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeIntakeRepository) FindToken(technicianID, tokenID uint) (*models.IntakeToken, error) {
+	token, ok := r.tokens[tokenID]
+	if !ok || token.TechnicianID != technicianID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	found := *token
+	return &found, nil
+}
+
+func (r *fakeIntakeRepository) ListTokens(technicianID uint) ([]models.IntakeToken, error) {
+	var tokens []models.IntakeToken
+	for _, token := range r.tokens {
+		if token.TechnicianID == technicianID {
+			tokens = append(tokens, *token)
+		}
+	}
+	return tokens, nil
+}
+
+func (r *fakeIntakeRepository) CreateDraft(draft *models.IntakeDraft) error {
+	draft.ID = r.nextID
+	r.nextID++
+	stored := *draft
+	r.drafts[draft.ID] = &stored
+	return nil
+}
+
+func (r *fakeIntakeRepository) FindDraftByClaimHash(hash string) (*models.IntakeDraft, error) {
+	for _, draft := range r.drafts {
+		if draft.ClaimCodeHash == hash {
+			found := *draft
+			return &found, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeIntakeRepository) ClaimDraft(draft *models.IntakeDraft, order *models.Order) error {
+	if err := r.orders.Create(order); err != nil {
+		return err
+	}
+	now := time.Now()
+	draft.OrderID = &order.ID
+	draft.ClaimedAt = &now
+	stored := *draft
+	r.drafts[draft.ID] = &stored
+	return nil
+}
+
+func TestIntakeService_CreateToken(t *testing.T) {
+	service := NewIntakeService(newFakeIntakeRepository(newFakeOrderRepository()), newFakeOrderRepository())
+
+	_, err := service.CreateToken(testCustomer, IntakeTokenInput{})
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+
+	_, err = service.CreateToken(testTechnician, IntakeTokenInput{Scopes: []string{"orders:read"}})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+
+	_, err = service.CreateToken(testTechnician, IntakeTokenInput{TTL: MaxIntakeTokenTTL + time.Hour})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+
+	_, err = service.CreateToken(testTechnician, IntakeTokenInput{Origin: "nailsbytech.example"})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+
+	// Defaults grant every scope for a day
+	token, err := service.CreateToken(testTechnician, IntakeTokenInput{})
+	assert.NoError(t, err)
+	assert.True(t, token.HasScope(IntakeScopeDrafts))
+	assert.True(t, token.HasScope(IntakeScopeImages))
+	assert.WithinDuration(t, time.Now().Add(DefaultIntakeTokenTTL), token.ExpiresAt, time.Minute)
+	assert.Equal(t, utils.HashSecret(token.Token), token.TokenHash)
+}
+
+func TestIntakeService_Authenticate(t *testing.T) {
+	intake := newFakeIntakeRepository(newFakeOrderRepository())
+	service := NewIntakeService(intake, newFakeOrderRepository())
+
+	token, err := service.CreateToken(testTechnician, IntakeTokenInput{Scopes: []string{IntakeScopeDrafts}})
+	assert.NoError(t, err)
+
+	authenticated, err := service.Authenticate(token.Token, "", IntakeScopeDrafts)
+	assert.NoError(t, err)
+	assert.Equal(t, token.ID, authenticated.ID)
+
+	_, err = service.Authenticate(token.Token, "", IntakeScopeImages)
+	assertAPIError(t, err, http.StatusForbidden, "INSUFFICIENT_SCOPE")
+
+	_, err = service.Authenticate("nit_unknown", "", IntakeScopeDrafts)
+	assertAPIError(t, err, http.StatusUnauthorized, "INVALID_INTAKE_TOKEN")
+
+	// Expired tokens are rejected like unknown ones
+	intake.tokens[token.ID].ExpiresAt = time.Now().Add(-time.Minute)
+	_, err = service.Authenticate(token.Token, "", IntakeScopeDrafts)
+	assertAPIError(t, err, http.StatusUnauthorized, "INVALID_INTAKE_TOKEN")
+}
+
+func TestIntakeService_ClaimDraft(t *testing.T) {
+	orders := newFakeOrderRepository()
+	intake := newFakeIntakeRepository(orders)
+	service := NewIntakeService(intake, orders)
+	outbox := &fakeOutboxRepository{}
+	service.outbox = outbox
+
+	token, err := service.CreateToken(testTechnician, IntakeTokenInput{})
+	assert.NoError(t, err)
+	draft, err := service.SubmitDraft(token, IntakeDraftInput{GuestName: "Guest", GuestEmail: "guest@example.com", Description: "Almonds", Quantity: 2})
+	assert.NoError(t, err)
+
+	_, err = service.ClaimDraft(testTechnician, draft.ClaimCode)
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+
+	_, err = service.ClaimDraft(testCustomer, "ncc_wrong")
+	assertAPIError(t, err, http.StatusNotFound, "DRAFT_NOT_FOUND")
+
+	// Claim codes expire
+	intake.drafts[draft.ID].ClaimExpiresAt = time.Now().Add(-time.Minute)
+	_, err = service.ClaimDraft(testCustomer, draft.ClaimCode)
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "CLAIM_EXPIRED")
+	intake.drafts[draft.ID].ClaimExpiresAt = time.Now().Add(time.Hour)
+
+	order, err := service.ClaimDraft(testCustomer, draft.ClaimCode)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSubmitted, order.Status)
+	assert.Equal(t, testCustomer.ID, order.CustomerID)
+	assert.True(t, IsAssignedTo(order, testTechnician))
+	assert.Equal(t, 2, order.Quantity)
+	if assert.Len(t, outbox.events, 1, "the new order is announced through the outbox") {
+		assert.Equal(t, WebhookOrderCreated, outbox.events[0].Name)
+	}
+
+	_, err = service.ClaimDraft(testCustomer, draft.ClaimCode)
+	assertAPIError(t, err, http.StatusConflict, "DRAFT_ALREADY_CLAIMED")
+}
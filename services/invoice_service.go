@@ -0,0 +1,121 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/invoice"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"github.com/kendall-kelly/kendalls-nails-api/utils"
+)
+
+// InvoiceService produces the PDF invoices of accepted orders
+type InvoiceService interface {
+	// Invoice returns the PDF invoice of an accepted order for its customer or assigned technician
+	Invoice(user *models.User, orderID string) ([]byte, error)
+}
+
+// DefaultInvoiceService implements InvoiceService on top of an OrderRepository
+type DefaultInvoiceService struct {
+	orders repositories.OrderRepository
+}
+
+var invoiceServiceInstance InvoiceService
+
+// NewInvoiceService creates an invoice service using the given repository
+func NewInvoiceService(orders repositories.OrderRepository) *DefaultInvoiceService {
+	return &DefaultInvoiceService{orders: orders}
+}
+
+// GetInvoiceService returns the configured invoice service
+// When none has been set, a service over the current database connection is returned
+func GetInvoiceService() InvoiceService {
+	if invoiceServiceInstance != nil {
+		return invoiceServiceInstance
+	}
+	return NewInvoiceService(repositories.NewOrderRepository(config.GetDB()))
+}
+
+// SetInvoiceService sets the invoice service instance (primarily for testing)
+func SetInvoiceService(service InvoiceService) {
+	invoiceServiceInstance = service
+}
+
+// Invoice returns the PDF invoice of an accepted order for its customer or assigned technician
+func (s *DefaultInvoiceService) Invoice(user *models.User, orderID string) ([]byte, error) {
+	id, err := parseOrderID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	order, err := s.orders.FindByIDWithRelations(id)
+	if err != nil {
+		return nil, orderLookupError(err)
+	}
+
+	isCustomer := user.Role == RoleCustomer && order.CustomerID == user.ID
+	isTechnician := user.Role == RoleTechnician && IsAssignedTo(order, user)
+	if !isCustomer && !isTechnician {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only the order's customer and assigned technician can view its invoice")
+	}
+	if !IsBooked(order) {
+		return nil, apierror.Unprocessable("INVALID_STATE", "Invoices are available once the order is accepted")
+	}
+
+	data, err := invoice.Render(buildInvoice(order, invoiceTaxPercent()))
+	if err != nil {
+		return nil, apierror.Internal("INVOICE_ERROR", "Failed to generate invoice").Wrap(err)
+	}
+	return data, nil
+}
+
+// buildInvoice lays out the invoice of a booked order loaded with its relations
+// Orders quoted before itemized line items existed are invoiced as a single line at their price
+func buildInvoice(order *models.Order, taxPercent float64) invoice.Invoice {
+	inv := invoice.Invoice{
+		Number:      fmt.Sprintf("INV-%06d", order.ID),
+		IssuedAt:    order.CreatedAt,
+		OrderID:     order.ID,
+		Description: order.Description,
+		Status:      order.Status,
+		Currency:    order.Currency,
+		Customer:    invoice.Party{Name: order.Customer.Name, Email: order.Customer.Email},
+		TaxPercent:  taxPercent,
+	}
+	if order.ReviewedAt != nil {
+		inv.IssuedAt = *order.ReviewedAt
+	}
+	if order.Technician != nil {
+		inv.Technician = invoice.Party{Name: order.Technician.Name, Email: order.Technician.Email}
+	}
+
+	for _, item := range order.LineItems {
+		inv.Lines = append(inv.Lines, invoice.Line{
+			Description: item.Description,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+			Amount:      item.Amount,
+		})
+	}
+	if order.Price != nil {
+		inv.Subtotal = *order.Price
+	}
+	if len(inv.Lines) == 0 {
+		inv.Lines = []invoice.Line{{Description: "Custom nail set", Quantity: 1, UnitPrice: inv.Subtotal, Amount: inv.Subtotal}}
+	}
+
+	subtotal := utils.ToCents(inv.Subtotal)
+	tax := utils.PercentOfCents(subtotal, taxPercent)
+	inv.Tax = utils.FromCents(tax)
+	inv.Total = utils.FromCents(subtotal + tax)
+	return inv
+}
+
+// invoiceTaxPercent returns the configured invoice tax rate, or zero when no configuration is loaded
+func invoiceTaxPercent() float64 {
+	if cfg := config.GetConfig(); cfg != nil {
+		return cfg.GetInvoiceTaxPercent()
+	}
+	return 0
+}
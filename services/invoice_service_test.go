@@ -0,0 +1,73 @@
+package services
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInvoiceService_Invoice(t *testing.T) {
+	price := 45.0
+	orders := newFakeOrderRepository(
+		models.Order{ID: 1, Status: StatusInProduction, CustomerID: testCustomer.ID, TechnicianID: uintPtr(testTechnician.ID), Price: &price},
+		models.Order{ID: 2, Status: StatusSubmitted, CustomerID: testCustomer.ID},
+	)
+	service := NewInvoiceService(orders)
+
+	data, err := service.Invoice(testCustomer, "1")
+	assert.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(data, []byte("%PDF-")))
+
+	_, err = service.Invoice(testTechnician, "1")
+	assert.NoError(t, err)
+
+	// Only the customer and the assigned technician see the invoice
+	_, err = service.Invoice(otherCustomer, "1")
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+	_, err = service.Invoice(otherTech, "1")
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+
+	_, err = service.Invoice(testCustomer, "2")
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "INVALID_STATE")
+}
+
+func TestBuildInvoice(t *testing.T) {
+	config.SetConfig(&config.Config{InvoiceTaxPct: "8.25"})
+	defer config.SetConfig(nil)
+
+	price := 45.0
+	reviewedAt := time.Date(2026, 10, 2, 12, 0, 0, 0, time.UTC)
+	order := &models.Order{
+		ID:         7,
+		Status:     StatusAccepted,
+		Price:      &price,
+		ReviewedAt: &reviewedAt,
+		Customer:   models.User{Name: "Casey", Email: "casey@example.com"},
+		Technician: &models.User{Name: "Alex", Email: "alex@example.com"},
+		LineItems: []models.OrderLineItem{
+			{Description: "Custom nail set", Quantity: 1, UnitPrice: 40, Amount: 40},
+			{Description: "Gems", Quantity: 2, UnitPrice: 2.5, Amount: 5},
+		},
+	}
+
+	inv := buildInvoice(order, invoiceTaxPercent())
+	assert.Equal(t, "INV-000007", inv.Number)
+	assert.Equal(t, reviewedAt, inv.IssuedAt)
+	assert.Len(t, inv.Lines, 2)
+	assert.Equal(t, "Alex", inv.Technician.Name)
+	assert.Equal(t, 45.0, inv.Subtotal)
+	assert.Equal(t, 3.71, inv.Tax)
+	assert.Equal(t, 48.71, inv.Total)
+
+	// Orders without line items are invoiced as one line at their price
+	order.LineItems = nil
+	inv = buildInvoice(order, 0)
+	assert.Equal(t, 45.0, inv.Lines[0].Amount)
+	assert.Equal(t, 0.0, inv.Tax)
+	assert.Equal(t, 45.0, inv.Total)
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+)
+
+// localTempPrefix names files being written by Put, which are renamed into place once complete
+const localTempPrefix = ".put-"
+
+// LocalStorage implements Storage with files in a directory on the server's disk
+// The files have no URL of their own; they are served by the API through a FileProxy
+type LocalStorage struct {
+	dir string
+}
+
+// NewLocalStorage creates a local storage backend, creating LOCAL_STORAGE_DIR if it does not exist
+func NewLocalStorage(cfg *config.Config) (*LocalStorage, error) {
+	dir, err := filepath.Abs(cfg.LocalStorageDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve LOCAL_STORAGE_DIR: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	return &LocalStorage{dir: dir}, nil
+}
+
+// filePath returns where the file for key lives, rejecting keys that would escape the storage directory
+func (s *LocalStorage) filePath(key string) (string, error) {
+	if err := validateStorageKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
+
+// Put writes content to the file for key
+// The file is written under a temporary name and renamed into place, so readers never see a partial file
+func (s *LocalStorage) Put(key string, content []byte, contentType string) error {
+	target, err := s.filePath(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o750); err != nil {
+		return fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(target), localTempPrefix+"*")
+	if err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	defer os.Remove(temp.Name())
+	if _, err := temp.Write(content); err != nil {
+		temp.Close()
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := os.Rename(temp.Name(), target); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+// Get reads the file stored under key
+func (s *LocalStorage) Get(key string) ([]byte, error) {
+	target, err := s.filePath(key)
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(target)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrFileNotFound, key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	return content, nil
+}
+
+// Stat describes the file stored under key
+func (s *LocalStorage) Stat(key string) (StoredFile, error) {
+	target, err := s.filePath(key)
+	if err != nil {
+		return StoredFile{}, err
+	}
+	info, err := os.Stat(target)
+	if errors.Is(err, fs.ErrNotExist) || (err == nil && info.IsDir()) {
+		return StoredFile{}, fmt.Errorf("%w: %s", ErrFileNotFound, key)
+	}
+	if err != nil {
+		return StoredFile{}, fmt.Errorf("failed to read file: %w", err)
+	}
+	return StoredFile{Key: key, Size: info.Size(), LastModified: info.ModTime()}, nil
+}
+
+// Delete removes the file stored under key
+func (s *LocalStorage) Delete(key string) error {
+	target, err := s.filePath(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(target); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	return nil
+}
+
+// List returns every stored file whose key starts with prefix
+func (s *LocalStorage) List(prefix string) ([]StoredFile, error) {
+	var files []StoredFile
+	err := filepath.WalkDir(s.dir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), localTempPrefix) {
+			return nil
+		}
+		rel, err := filepath.Rel(s.dir, filePath)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		files = append(files, StoredFile{Key: key, Size: info.Size(), LastModified: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+	return files, nil
+}
+
+// SignedURL always fails; InitStorage puts a FileProxy in front of local storage to sign links
+func (s *LocalStorage) SignedURL(key string) (string, error) {
+	return "", errLocalFilesProxied
+}
+
+// errLocalFilesProxied is returned when local storage is asked for a URL of its own
+var errLocalFilesProxied = errors.New("locally stored files are only served through the image proxy")
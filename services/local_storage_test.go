@@ -0,0 +1,67 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLocalStorage(t *testing.T) *LocalStorage {
+	storage, err := NewLocalStorage(&config.Config{LocalStorageDir: t.TempDir()})
+	require.NoError(t, err)
+	return storage
+}
+
+func TestLocalStorage_PutGetListDelete(t *testing.T) {
+	storage := newTestLocalStorage(t)
+
+	require.NoError(t, storage.Put("uploads/1_design.png", []byte("first"), "image/png"))
+	require.NoError(t, storage.Put("uploads/1_design.png", []byte("replaced"), "image/png"))
+	require.NoError(t, storage.Put("exports/report.csv", []byte("a,b"), "text/csv"))
+
+	content, err := storage.Get("uploads/1_design.png")
+	assert.NoError(t, err)
+	assert.Equal(t, "replaced", string(content))
+
+	files, err := storage.List(UploadPrefix)
+	assert.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, "uploads/1_design.png", files[0].Key)
+	assert.Equal(t, int64(len("replaced")), files[0].Size)
+
+	assert.NoError(t, storage.Delete("uploads/1_design.png"))
+	assert.NoError(t, storage.Delete("uploads/1_design.png"), "deleting a missing file is not an error")
+	_, err = storage.Get("uploads/1_design.png")
+	assert.ErrorIs(t, err, ErrFileNotFound)
+}
+
+func TestLocalStorage_RejectsKeysOutsideItsDirectory(t *testing.T) {
+	storage := newTestLocalStorage(t)
+
+	for _, key := range []string{"", "../secret", "uploads/../../secret", "/etc/passwd", "uploads//a.png", `uploads\a.png`} {
+		assert.Error(t, storage.Put(key, []byte("x"), "image/png"), key)
+		_, err := storage.Get(key)
+		assert.Error(t, err, key)
+	}
+}
+
+func TestInitStorage_LocalFilesAreServedThroughTheProxy(t *testing.T) {
+	defer SetStorage(nil)
+	defer SetFileProxy(nil)
+
+	storage, err := InitStorage(&config.Config{
+		StorageBackend:  config.StorageLocal,
+		LocalStorageDir: t.TempDir(),
+		ImageProxyURL:   "https://api.example.com",
+		ImageProxyKey:   "test-signing-key",
+	})
+	require.NoError(t, err)
+	require.NotNil(t, GetFileProxy())
+
+	signed, err := storage.SignedURL("uploads/1_design.png")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(signed, "https://api.example.com/files/uploads/1_design.png?"), signed)
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+)
+
+// TextModerator screens message text for offensive content such as harassment or hate speech
+type TextModerator interface {
+	// ModerateText returns the labels the text was flagged for; none means it is fine to send
+	ModerateText(text string) ([]string, error)
+}
+
+// HTTPTextModerator implements TextModerator by posting text to a moderation endpoint
+// It receives {"text": "..."} and answers with {"flagged": bool, "labels": [...]}, like the image moderation endpoint
+type HTTPTextModerator struct {
+	url        string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// NewHTTPTextModerator creates a moderator for the configured message moderation endpoint
+func NewHTTPTextModerator(cfg *config.Config) *HTTPTextModerator {
+	return &HTTPTextModerator{
+		url:        cfg.MessageModerationURL,
+		apiKey:     cfg.MessageModerationKey,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// ModerateText posts text to the moderation endpoint and returns the labels it was flagged for
+// Flagged text without labels is reported as "flagged"
+func (m *HTTPTextModerator) ModerateText(text string) ([]string, error) {
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, m.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if m.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+m.apiKey)
+	}
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call moderation endpoint: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("moderation endpoint returned status %d: %s", resp.StatusCode, string(detail))
+	}
+	var verdict moderationResponse
+	if err := json.NewDecoder(resp.Body).Decode(&verdict); err != nil {
+		return nil, fmt.Errorf("failed to decode moderation response: %w", err)
+	}
+	if !verdict.Flagged {
+		return nil, nil
+	}
+	if len(verdict.Labels) == 0 {
+		return []string{"flagged"}, nil
+	}
+	return verdict.Labels, nil
+}
+
+// MessageFilter checks message text against a word list and an optional moderation endpoint
+type MessageFilter struct {
+	words     *regexp.Regexp // nil without a word list
+	action    string         // config.MessageFilterMask or config.MessageFilterBlock, for words on the list
+	moderator TextModerator  // nil without a moderation endpoint
+}
+
+// MessageVerdict is the outcome of filtering a message
+type MessageVerdict struct {
+	Text   string   // the text to send, with filtered words masked
+	Action string   // models.MessageMasked or models.MessageBlocked, empty when the message is fine
+	Terms  []string // the filtered words the message used
+	Labels []string // what the moderation endpoint flagged it for
+}
+
+var messageFilter *MessageFilter
+
+// SetMessageFilter sets the filter messages are checked with (nil switches filtering off)
+// It is configured once at startup when MESSAGE_FILTER_WORDS or MESSAGE_MODERATION_URL is set
+func SetMessageFilter(filter *MessageFilter) {
+	messageFilter = filter
+}
+
+// NewMessageFilter creates a filter for the word list, whose words are masked or blocked according to action,
+// and the moderation endpoint, whose flagged messages are blocked; either may be empty
+func NewMessageFilter(words []string, action string, moderator TextModerator) *MessageFilter {
+	filter := &MessageFilter{action: action, moderator: moderator}
+	if len(words) > 0 {
+		quoted := make([]string, len(words))
+		for i, word := range words {
+			quoted[i] = regexp.QuoteMeta(word)
+		}
+		// Longest first, so that of two listed words starting at the same place the whole one is matched
+		slices.SortFunc(quoted, func(a, b string) int { return len(b) - len(a) })
+		filter.words = regexp.MustCompile(`(?i)(?:` + strings.Join(quoted, "|") + `)`)
+	}
+	return filter
+}
+
+// findWords returns the start and end of each listed word in text
+// Only whole words count, so a listed word inside an innocent one is left alone; the boundaries are checked
+// by hand because regexp's \b only knows ASCII letters
+func (f *MessageFilter) findWords(text string) [][2]int {
+	var found [][2]int
+	for pos := 0; pos < len(text); {
+		loc := f.words.FindStringIndex(text[pos:])
+		if loc == nil {
+			break
+		}
+		start, end := pos+loc[0], pos+loc[1]
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if start > 0 && isWordRune(before) || end < len(text) && isWordRune(after) {
+			_, size := utf8.DecodeRuneInString(text[start:])
+			pos = start + max(size, 1)
+			continue
+		}
+		found = append(found, [2]int{start, end})
+		pos = max(end, start+1)
+	}
+	return found
+}
+
+// isWordRune reports whether r can be part of a word
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.IsMark(r)
+}
+
+// Check filters a message
+// Words on the list are masked or blocked; a message the moderation endpoint flags is blocked, since it
+// does not say which words to mask. A failing endpoint lets messages through, so conversations don't stop with it
+func (f *MessageFilter) Check(text string) MessageVerdict {
+	verdict := MessageVerdict{Text: text}
+
+	if f.words != nil {
+		seen := make(map[string]bool)
+		var masked strings.Builder
+		last := 0
+		for _, span := range f.findWords(text) {
+			word := text[span[0]:span[1]]
+			if term := strings.ToLower(word); !seen[term] {
+				seen[term] = true
+				verdict.Terms = append(verdict.Terms, term)
+			}
+			masked.WriteString(text[last:span[0]])
+			masked.WriteString(strings.Repeat("*", utf8.RuneCountInString(word)))
+			last = span[1]
+		}
+		masked.WriteString(text[last:])
+		verdict.Text = masked.String()
+		if len(verdict.Terms) > 0 {
+			verdict.Action = models.MessageMasked
+			if f.action == config.MessageFilterBlock {
+				verdict.Action = models.MessageBlocked
+			}
+		}
+	}
+
+	if f.moderator != nil && verdict.Action != models.MessageBlocked {
+		labels, err := f.moderator.ModerateText(text)
+		switch {
+		case err != nil:
+			log.Printf("Failed to screen message: %v", err)
+		case len(labels) > 0:
+			verdict.Action = models.MessageBlocked
+			verdict.Labels = labels
+		}
+	}
+	return verdict
+}
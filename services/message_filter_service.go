@@ -0,0 +1,155 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"gorm.io/gorm"
+)
+
+// ViolationReviewStatuses lists the verdicts an admin can give a filtered message
+var ViolationReviewStatuses = []string{models.ViolationConfirmed, models.ViolationDismissed}
+
+// ViolationStatuses lists every violation status, for filtering the review queue
+var ViolationStatuses = []string{models.ViolationPendingReview, models.ViolationConfirmed, models.ViolationDismissed}
+
+// MessageFilterService applies the content filter to messages and keeps the violations for admin review
+type MessageFilterService interface {
+	// Screen filters a message the sender is about to send on the order and returns the text to send
+	// Masked and blocked messages are recorded for review; a blocked message returns a 422 MESSAGE_BLOCKED error
+	Screen(sender *models.User, orderID uint, text string) (string, error)
+
+	// ListViolations returns a page of violations with the given status, oldest first, with the total (admins only)
+	ListViolations(admin *models.User, status string, page, limit int) ([]models.MessageViolation, int64, error)
+
+	// ReviewViolation confirms or dismisses a violation (admins only)
+	ReviewViolation(admin *models.User, violationID string, status string) (*models.MessageViolation, error)
+}
+
+// DefaultMessageFilterService implements MessageFilterService on top of a MessageViolationRepository and a MessageFilter
+type DefaultMessageFilterService struct {
+	violations repositories.MessageViolationRepository
+	filter     *MessageFilter                  // nil when filtering is off
+	audit      repositories.AuditLogRepository // nil records nothing
+	now        func() time.Time
+}
+
+var messageFilterServiceInstance MessageFilterService
+
+// NewMessageFilterService creates a message filter service; pass a nil filter to send messages unfiltered
+func NewMessageFilterService(violations repositories.MessageViolationRepository, filter *MessageFilter) *DefaultMessageFilterService {
+	return &DefaultMessageFilterService{violations: violations, filter: filter, now: time.Now}
+}
+
+// GetMessageFilterService returns the configured message filter service
+// When none has been set, a service over the current database connection is returned
+func GetMessageFilterService() MessageFilterService {
+	if messageFilterServiceInstance != nil {
+		return messageFilterServiceInstance
+	}
+	db := config.GetDB()
+	service := NewMessageFilterService(repositories.NewMessageViolationRepository(db), messageFilter)
+	service.audit = repositories.NewAuditLogRepository(db)
+	return service
+}
+
+// SetMessageFilterService sets the message filter service instance (primarily for testing)
+func SetMessageFilterService(service MessageFilterService) {
+	messageFilterServiceInstance = service
+}
+
+// Screen filters a message the sender is about to send on the order and returns the text to send
+func (s *DefaultMessageFilterService) Screen(sender *models.User, orderID uint, text string) (string, error) {
+	if s.filter == nil {
+		return text, nil
+	}
+
+	verdict := s.filter.Check(text)
+	if verdict.Action == "" {
+		return text, nil
+	}
+
+	violation := &models.MessageViolation{
+		OrderID:  orderID,
+		SenderID: sender.ID,
+		Text:     text,
+		Action:   verdict.Action,
+		Terms:    verdict.Terms,
+		Labels:   verdict.Labels,
+		Status:   models.ViolationPendingReview,
+	}
+	if err := s.violations.Create(violation); err != nil {
+		return "", apierror.Internal("DATABASE_ERROR", "Failed to record message violation").Wrap(err)
+	}
+
+	if verdict.Action == models.MessageBlocked {
+		return "", apierror.Unprocessable("MESSAGE_BLOCKED", "Message contains content that is not allowed").WithDetails(map[string]interface{}{
+			"terms":  verdict.Terms,
+			"labels": verdict.Labels,
+		})
+	}
+	return verdict.Text, nil
+}
+
+// ListViolations returns a page of violations with the given status, oldest first, with the total
+func (s *DefaultMessageFilterService) ListViolations(admin *models.User, status string, page, limit int) ([]models.MessageViolation, int64, error) {
+	if admin.Role != RoleAdmin {
+		return nil, 0, apierror.Forbidden("FORBIDDEN", "Only admins can review messages")
+	}
+	if status != "" && !oneOf(status, ViolationStatuses) {
+		return nil, 0, apierror.Validation("Invalid status filter", map[string]string{
+			"status": "must be one of: " + strings.Join(ViolationStatuses, ", "),
+		})
+	}
+
+	violations, total, err := s.violations.List(status, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, apierror.Internal("DATABASE_ERROR", "Failed to fetch message violations").Wrap(err)
+	}
+	return violations, total, nil
+}
+
+// ReviewViolation confirms or dismisses a violation
+func (s *DefaultMessageFilterService) ReviewViolation(admin *models.User, violationID string, status string) (*models.MessageViolation, error) {
+	if admin.Role != RoleAdmin {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only admins can review messages")
+	}
+	if !oneOf(status, ViolationReviewStatuses) {
+		return nil, apierror.Validation("Invalid request data", map[string]string{
+			"status": "must be one of: " + strings.Join(ViolationReviewStatuses, ", "),
+		})
+	}
+
+	id, err := strconv.ParseUint(violationID, 10, 64)
+	if err != nil || id == 0 {
+		return nil, apierror.NotFound("MESSAGE_VIOLATION_NOT_FOUND", "Message violation not found")
+	}
+	violation, err := s.violations.FindByID(uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apierror.NotFound("MESSAGE_VIOLATION_NOT_FOUND", "Message violation not found")
+		}
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load message violation").Wrap(err)
+	}
+
+	before := violation.Status
+	now := s.now()
+	violation.Status = status
+	violation.ReviewedByID = &admin.ID
+	violation.ReviewedAt = &now
+	if err := s.violations.Save(violation); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to review message violation").Wrap(err)
+	}
+
+	if err := recordAudit(s.audit, admin, models.AuditViolationReviewed, models.AuditTargetViolation, violation.ID,
+		map[string]interface{}{"status": before}, map[string]interface{}{"status": status}); err != nil {
+		return nil, err
+	}
+	return violation, nil
+}
@@ -0,0 +1,236 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// fakeMessageViolationRepository is an in-memory MessageViolationRepository
+type fakeMessageViolationRepository struct {
+	violations []*models.MessageViolation
+}
+
+func (r *fakeMessageViolationRepository) Create(violation *models.MessageViolation) error {
+	violation.ID = uint(len(r.violations) + 1)
+	r.violations = append(r.violations, violation)
+	return nil
+}
+
+func (r *fakeMessageViolationRepository) Save(violation *models.MessageViolation) error {
+	return nil
+}
+
+func (r *fakeMessageViolationRepository) FindByID(id uint) (*models.MessageViolation, error) {
+	for _, violation := range r.violations {
+		if violation.ID == id {
+			return violation, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeMessageViolationRepository) List(status string, limit, offset int) ([]models.MessageViolation, int64, error) {
+	var violations []models.MessageViolation
+	for _, violation := range r.violations {
+		if status == "" || violation.Status == status {
+			violations = append(violations, *violation)
+		}
+	}
+	total := int64(len(violations))
+	if offset >= len(violations) {
+		return nil, total, nil
+	}
+	violations = violations[offset:]
+	if len(violations) > limit {
+		violations = violations[:limit]
+	}
+	return violations, total, nil
+}
+
+// fakeTextModerator flags text with fixed labels, or fails
+type fakeTextModerator struct {
+	labels []string
+	err    error
+	calls  int
+}
+
+func (m *fakeTextModerator) ModerateText(text string) ([]string, error) {
+	m.calls++
+	return m.labels, m.err
+}
+
+func TestMessageFilter_Check(t *testing.T) {
+	words := []string{"darn", "heck"}
+
+	t.Run("clean text passes", func(t *testing.T) {
+		verdict := NewMessageFilter(words, config.MessageFilterMask, nil).Check("Your nails are ready")
+		assert.Equal(t, MessageVerdict{Text: "Your nails are ready"}, verdict)
+	})
+
+	t.Run("listed words are masked as whole words, ignoring case", func(t *testing.T) {
+		verdict := NewMessageFilter(words, config.MessageFilterMask, nil).Check("Darn, the darned polish chipped. DARN! What the heck")
+		assert.Equal(t, "****, the darned polish chipped. ****! What the ****", verdict.Text)
+		assert.Equal(t, models.MessageMasked, verdict.Action)
+		assert.Equal(t, []string{"darn", "heck"}, verdict.Terms)
+	})
+
+	t.Run("words with non-ASCII letters are matched as whole words", func(t *testing.T) {
+		verdict := NewMessageFilter([]string{"mierdé", "ñoño", "darn"}, config.MessageFilterMask, nil).Check("¡Ñoño! mierdé, ñoñoña darn darn2 darné")
+		assert.Equal(t, "¡****! ******, ñoñoña **** darn2 darné", verdict.Text)
+		assert.Equal(t, []string{"ñoño", "mierdé", "darn"}, verdict.Terms)
+	})
+
+	t.Run("the longer of two listed words is masked whole", func(t *testing.T) {
+		verdict := NewMessageFilter([]string{"darn", "darnit"}, config.MessageFilterMask, nil).Check("darnit, darn it")
+		assert.Equal(t, "******, **** it", verdict.Text)
+	})
+
+	t.Run("listed words block in block mode", func(t *testing.T) {
+		moderator := &fakeTextModerator{}
+		verdict := NewMessageFilter(words, config.MessageFilterBlock, moderator).Check("oh heck")
+		assert.Equal(t, models.MessageBlocked, verdict.Action)
+		assert.Equal(t, []string{"heck"}, verdict.Terms)
+		assert.Zero(t, moderator.calls, "a blocked message needs no second opinion")
+	})
+
+	t.Run("flagged text is blocked", func(t *testing.T) {
+		moderator := &fakeTextModerator{labels: []string{"harassment"}}
+		verdict := NewMessageFilter(nil, config.MessageFilterMask, moderator).Check("something nasty")
+		assert.Equal(t, models.MessageBlocked, verdict.Action)
+		assert.Equal(t, []string{"harassment"}, verdict.Labels)
+	})
+
+	t.Run("failing moderator lets the message through", func(t *testing.T) {
+		moderator := &fakeTextModerator{err: errors.New("timeout")}
+		verdict := NewMessageFilter(words, config.MessageFilterMask, moderator).Check("darn it")
+		assert.Equal(t, "**** it", verdict.Text)
+		assert.Equal(t, models.MessageMasked, verdict.Action)
+		assert.Equal(t, 1, moderator.calls)
+	})
+}
+
+func TestMessageFilterService_Screen(t *testing.T) {
+	repo := &fakeMessageViolationRepository{}
+
+	t.Run("without a filter messages pass unchanged", func(t *testing.T) {
+		text, err := NewMessageFilterService(repo, nil).Screen(testCustomer, 1, "darn")
+		assert.NoError(t, err)
+		assert.Equal(t, "darn", text)
+		assert.Empty(t, repo.violations)
+	})
+
+	t.Run("clean messages record nothing", func(t *testing.T) {
+		service := NewMessageFilterService(repo, NewMessageFilter([]string{"darn"}, config.MessageFilterMask, nil))
+		text, err := service.Screen(testCustomer, 1, "thanks!")
+		assert.NoError(t, err)
+		assert.Equal(t, "thanks!", text)
+		assert.Empty(t, repo.violations)
+	})
+
+	t.Run("masked messages are sent masked and recorded", func(t *testing.T) {
+		service := NewMessageFilterService(repo, NewMessageFilter([]string{"darn"}, config.MessageFilterMask, nil))
+		text, err := service.Screen(testCustomer, 1, "darn it")
+		assert.NoError(t, err)
+		assert.Equal(t, "**** it", text)
+		if assert.Len(t, repo.violations, 1) {
+			violation := repo.violations[0]
+			assert.Equal(t, uint(1), violation.OrderID)
+			assert.Equal(t, testCustomer.ID, violation.SenderID)
+			assert.Equal(t, "darn it", violation.Text)
+			assert.Equal(t, models.MessageMasked, violation.Action)
+			assert.Equal(t, models.ViolationPendingReview, violation.Status)
+		}
+	})
+
+	t.Run("blocked messages are rejected and recorded", func(t *testing.T) {
+		moderator := &fakeTextModerator{labels: []string{"hate"}}
+		service := NewMessageFilterService(repo, NewMessageFilter(nil, config.MessageFilterMask, moderator))
+		_, err := service.Screen(testTechnician, 2, "something hateful")
+		assertAPIError(t, err, http.StatusUnprocessableEntity, "MESSAGE_BLOCKED")
+		if assert.Len(t, repo.violations, 2) {
+			assert.Equal(t, models.MessageBlocked, repo.violations[1].Action)
+			assert.Equal(t, []string{"hate"}, repo.violations[1].Labels)
+		}
+	})
+}
+
+func TestMessageFilterService_ReviewViolation(t *testing.T) {
+	admin := &models.User{ID: 10, Role: RoleAdmin}
+	repo := &fakeMessageViolationRepository{}
+	audit := &fakeAuditLogRepository{}
+	service := NewMessageFilterService(repo, nil)
+	service.audit = audit
+	_ = repo.Create(&models.MessageViolation{Text: "darn it", Action: models.MessageMasked, Status: models.ViolationPendingReview})
+
+	_, _, err := service.ListViolations(testTechnician, models.ViolationPendingReview, 1, 20)
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+	_, _, err = service.ListViolations(admin, "open", 1, 20)
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+
+	violations, total, err := service.ListViolations(admin, models.ViolationPendingReview, 1, 20)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Len(t, violations, 1)
+
+	_, err = service.ReviewViolation(testTechnician, "1", models.ViolationConfirmed)
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+	_, err = service.ReviewViolation(admin, "1", models.ViolationPendingReview)
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+	_, err = service.ReviewViolation(admin, "2", models.ViolationConfirmed)
+	assertAPIError(t, err, http.StatusNotFound, "MESSAGE_VIOLATION_NOT_FOUND")
+
+	violation, err := service.ReviewViolation(admin, "1", models.ViolationDismissed)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ViolationDismissed, violation.Status)
+	assert.Equal(t, &admin.ID, violation.ReviewedByID)
+	assert.NotNil(t, violation.ReviewedAt)
+	if assert.Len(t, audit.entries, 1) {
+		assert.Equal(t, models.AuditViolationReviewed, audit.entries[0].Action)
+		assert.Equal(t, map[string]interface{}{"status": models.ViolationPendingReview}, audit.entries[0].Before)
+		assert.Equal(t, map[string]interface{}{"status": models.ViolationDismissed}, audit.entries[0].After)
+	}
+}
+
+func TestHTTPTextModerator(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		var body map[string]string
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		switch body["text"] {
+		case "clean":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"flagged": false})
+		case "flagged":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"flagged": true, "labels": []string{"harassment"}})
+		case "unlabeled":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"flagged": true})
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	moderator := NewHTTPTextModerator(&config.Config{MessageModerationURL: server.URL, MessageModerationKey: "secret"})
+
+	labels, err := moderator.ModerateText("clean")
+	assert.NoError(t, err)
+	assert.Empty(t, labels)
+
+	labels, err = moderator.ModerateText("flagged")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"harassment"}, labels)
+
+	labels, err = moderator.ModerateText("unlabeled")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"flagged"}, labels)
+
+	_, err = moderator.ModerateText("broken")
+	assert.Error(t, err)
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/metrics"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+)
+
+// MetricsCacheTTL is how long one snapshot of the business metrics is served
+// Scrapes within it, from any number of Prometheus servers, share one round of queries
+const MetricsCacheTTL = 10 * time.Second
+
+// webhookDeliveryStatuses are the label values of the webhook delivery gauge
+var webhookDeliveryStatuses = []string{models.DeliveryPending, models.DeliverySucceeded, models.DeliveryFailed}
+
+// orderTransitions counts orders entering each status in this process, including creation as submitted
+var orderTransitions = metrics.NewCounterVec(
+	"kendalls_nails_order_transitions_total",
+	"Orders that entered each status since the process started",
+	"status", OrderStatuses,
+)
+
+// recordOrderTransition counts an order entering its current status
+func recordOrderTransition(order *models.Order) {
+	orderTransitions.Inc(order.Status)
+}
+
+// MetricsService reports marketplace health as Prometheus metrics
+type MetricsService interface {
+	// BusinessMetrics returns the business gauges and counters exposed on /metrics
+	BusinessMetrics() ([]metrics.Family, error)
+}
+
+// DefaultMetricsService implements MetricsService on top of a MetricsRepository
+type DefaultMetricsService struct {
+	repo repositories.MetricsRepository
+	now  func() time.Time
+
+	mu        sync.Mutex
+	gauges    []metrics.Family
+	fetchedAt time.Time
+}
+
+var metricsServiceInstance MetricsService
+
+// NewMetricsService creates a metrics service using the given repository
+func NewMetricsService(repo repositories.MetricsRepository) *DefaultMetricsService {
+	return &DefaultMetricsService{repo: repo, now: time.Now}
+}
+
+// GetMetricsService returns the configured metrics service
+// When none has been set, a service over the current database connection is returned;
+// the server sets one at startup so that its snapshot is shared between scrapes
+func GetMetricsService() MetricsService {
+	if metricsServiceInstance != nil {
+		return metricsServiceInstance
+	}
+	return NewMetricsService(repositories.NewMetricsRepository(config.GetDB()))
+}
+
+// SetMetricsService sets the metrics service instance (primarily for testing)
+func SetMetricsService(service MetricsService) {
+	metricsServiceInstance = service
+}
+
+// BusinessMetrics returns the business gauges, from a snapshot at most MetricsCacheTTL old, and the in-process counters
+func (s *DefaultMetricsService) BusinessMetrics() ([]metrics.Family, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if s.gauges == nil || now.Sub(s.fetchedAt) >= MetricsCacheTTL {
+		gauges, err := s.collectGauges(now)
+		if err != nil {
+			return nil, err
+		}
+		s.gauges = gauges
+		s.fetchedAt = now
+	}
+
+	families := append([]metrics.Family(nil), s.gauges...)
+	return append(families, orderTransitions.Family(), orphanImagesDeleted.Family(), orphanImageBytesReclaimed.Family()), nil
+}
+
+// collectGauges queries the database for the business gauges
+// Labels only ever take values from fixed lists, so the number of series stays constant
+func (s *DefaultMetricsService) collectGauges(now time.Time) ([]metrics.Family, error) {
+	orderCounts, err := s.repo.CountOrdersByStatus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to count orders: %w", err)
+	}
+
+	currency := studioCurrency()
+	revenue, err := s.repo.RevenueReviewedSince(currency, BookedStatuses, startOfDay(now))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum today's revenue: %w", err)
+	}
+
+	technicians, err := s.repo.CountActiveTechnicians(OpenStatuses)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count active technicians: %w", err)
+	}
+
+	deliveryCounts, err := s.repo.CountDeliveriesByStatus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to count webhook deliveries: %w", err)
+	}
+
+	return []metrics.Family{
+		metrics.LabelledGauge("kendalls_nails_orders", "Orders currently in each status", "status", OrderStatuses, countsByStatus(orderCounts)),
+		metrics.LabelledGauge("kendalls_nails_revenue_today", "Prices of orders in the studio currency accepted since midnight UTC that are still booked", "currency", []string{currency}, map[string]float64{currency: revenue}),
+		metrics.Gauge("kendalls_nails_active_technicians", "Technicians assigned at least one order that has not shipped", float64(technicians)),
+		metrics.LabelledGauge("kendalls_nails_webhook_deliveries", "Webhook deliveries in each status; pending is the backlog", "status", webhookDeliveryStatuses, countsByStatus(deliveryCounts)),
+	}, nil
+}
+
+// countsByStatus indexes status counts by status
+func countsByStatus(counts []repositories.StatusCount) map[string]float64 {
+	byStatus := make(map[string]float64, len(counts))
+	for _, count := range counts {
+		byStatus[count.Status] = float64(count.Count)
+	}
+	return byStatus
+}
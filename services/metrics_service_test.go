@@ -0,0 +1,103 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/metrics"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeMetricsRepository returns canned aggregates and counts how often the orders were queried
+type fakeMetricsRepository struct {
+	queries  int
+	currency string
+	since    time.Time
+}
+
+func (r *fakeMetricsRepository) CountOrdersByStatus() ([]repositories.StatusCount, error) {
+	r.queries++
+	return []repositories.StatusCount{{Status: StatusSubmitted, Count: 3}, {Status: StatusShipped, Count: 1}}, nil
+}
+
+func (r *fakeMetricsRepository) RevenueReviewedSince(currency string, bookedStatuses []string, since time.Time) (float64, error) {
+	r.currency = currency
+	r.since = since
+	return 85.5, nil
+}
+
+func (r *fakeMetricsRepository) CountActiveTechnicians(statuses []string) (int64, error) {
+	return 2, nil
+}
+
+func (r *fakeMetricsRepository) CountDeliveriesByStatus() ([]repositories.StatusCount, error) {
+	return []repositories.StatusCount{{Status: models.DeliveryPending, Count: 4}}, nil
+}
+
+// findFamily returns the named family from families, failing the test when it is missing
+func findFamily(t *testing.T, families []metrics.Family, name string) metrics.Family {
+	t.Helper()
+	for _, family := range families {
+		if family.Name == name {
+			return family
+		}
+	}
+	t.Fatalf("metric family %s not found", name)
+	return metrics.Family{}
+}
+
+func TestMetricsService_BusinessMetrics(t *testing.T) {
+	repo := &fakeMetricsRepository{}
+	service := NewMetricsService(repo)
+	now := time.Date(2026, 10, 17, 15, 30, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	families, err := service.BusinessMetrics()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC), repo.since)
+
+	orders := findFamily(t, families, "kendalls_nails_orders")
+	assert.Len(t, orders.Samples, len(OrderStatuses))
+	assert.Contains(t, orders.Samples, metrics.Sample{LabelValue: StatusSubmitted, Value: 3})
+	assert.Contains(t, orders.Samples, metrics.Sample{LabelValue: StatusExpired, Value: 0})
+
+	assert.Equal(t, "USD", repo.currency)
+	assert.Equal(t, []metrics.Sample{{LabelValue: "USD", Value: 85.5}}, findFamily(t, families, "kendalls_nails_revenue_today").Samples)
+	assert.Equal(t, 2.0, findFamily(t, families, "kendalls_nails_active_technicians").Samples[0].Value)
+	deliveries := findFamily(t, families, "kendalls_nails_webhook_deliveries")
+	assert.Equal(t, []metrics.Sample{
+		{LabelValue: models.DeliveryPending, Value: 4},
+		{LabelValue: models.DeliverySucceeded, Value: 0},
+		{LabelValue: models.DeliveryFailed, Value: 0},
+	}, deliveries.Samples)
+	findFamily(t, families, "kendalls_nails_order_transitions_total")
+
+	// Scrapes within the TTL are served from the snapshot
+	now = now.Add(MetricsCacheTTL - time.Second)
+	_, err = service.BusinessMetrics()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, repo.queries)
+
+	now = now.Add(time.Second)
+	_, err = service.BusinessMetrics()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, repo.queries)
+}
+
+func TestRecordOrderTransition_CountsByStatus(t *testing.T) {
+	before := transitionCount(StatusShipped)
+	recordOrderTransition(&models.Order{Status: StatusShipped})
+	assert.Equal(t, before+1, transitionCount(StatusShipped))
+}
+
+// transitionCount returns the in-process transition count for status
+func transitionCount(status string) float64 {
+	for _, sample := range orderTransitions.Family().Samples {
+		if sample.LabelValue == status {
+			return sample.Value
+		}
+	}
+	return 0
+}
@@ -0,0 +1,425 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/jobs"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"gorm.io/gorm"
+)
+
+// Long-poll settings
+const (
+	DefaultPollWait           = 25 * time.Second // under the 30 second limit of the Heroku router
+	MaxPollWait               = 30 * time.Second
+	MaxNotificationsPerPoll   = 100
+	NotificationRecheckEvery  = 2 * time.Second // catches notifications written by other API instances
+	NotificationRetention     = 7 * 24 * time.Hour
+	NotificationPruneInterval = time.Hour
+)
+
+// NotificationPoll is the result of a long poll: the new notifications and the cursor to poll from next
+type NotificationPoll struct {
+	Notifications []models.Notification `json:"notifications"`
+	Cursor        uint                  `json:"cursor"`
+}
+
+// ChannelPreferenceInput changes the channels of one event type; nil leaves a channel as it is
+type ChannelPreferenceInput struct {
+	Email *bool
+	Push  *bool
+}
+
+// NotificationPreferencesInput holds the preference changes of a user; nil leaves an event type as it is
+type NotificationPreferencesInput struct {
+	Message      *ChannelPreferenceInput
+	StatusChange *ChannelPreferenceInput
+	Promo        *ChannelPreferenceInput
+}
+
+// NotificationSender delivers notifications over one channel, such as email or push
+type NotificationSender interface {
+	// Channel returns the channel the sender delivers over, models.ChannelEmail or models.ChannelPush
+	Channel() string
+
+	// Send delivers a notification to its user
+	Send(notification models.Notification) error
+}
+
+// NotificationService queues order events for the users involved and serves them by long polling
+type NotificationService interface {
+	// NotifyOrderStatusChanged queues order.status_changed for the order's customer and technician
+	NotifyOrderStatusChanged(order *models.Order, previousStatus string) error
+
+	// NotifyMessageCreated queues message.created for the order's customer and technician, except its sender
+	NotifyMessageCreated(message *models.Message) error
+
+	// NotifyTyping queues a typing indicator for the order's customer and technician, except the typist
+	NotifyTyping(order *models.Order, typistID uint) error
+
+	// NotifyReceipt queues a message.delivered or message.seen receipt for messages up to messageID
+	// for the order's customer and technician, except the reader
+	NotifyReceipt(order *models.Order, event string, readerID, messageID uint) error
+
+	// Poll returns the user's notifications after the since cursor, waiting up to wait for one to arrive
+	// An empty since starts from the user's latest notification, so only new events are returned
+	Poll(ctx context.Context, user *models.User, since string, wait time.Duration) (*NotificationPoll, error)
+
+	// Prune removes notifications older than NotificationRetention, and typing indicators past TypingIndicatorTTL,
+	// and returns how many were removed
+	Prune() (int64, error)
+
+	// GetPreferences returns the user's notification preferences, or the defaults when they have not set any
+	GetPreferences(user *models.User) (*models.NotificationPreferences, error)
+
+	// UpdatePreferences turns email and push notifications on or off per event type
+	UpdatePreferences(user *models.User, input NotificationPreferencesInput) (*models.NotificationPreferences, error)
+}
+
+// DefaultNotificationService implements NotificationService on top of a NotificationRepository
+type DefaultNotificationService struct {
+	notifications repositories.NotificationRepository
+	orders        repositories.OrderRepository
+	senders       []NotificationSender
+	now           func() time.Time
+	recheck       time.Duration
+}
+
+var notificationServiceInstance NotificationService
+
+// NewNotificationService creates a notification service using the given repositories
+func NewNotificationService(notifications repositories.NotificationRepository, orders repositories.OrderRepository) *DefaultNotificationService {
+	return &DefaultNotificationService{
+		notifications: notifications,
+		orders:        orders,
+		now:           time.Now,
+		recheck:       NotificationRecheckEvery,
+	}
+}
+
+// GetNotificationService returns the configured notification service
+// When none has been set, a service over the current database connection is returned
+func GetNotificationService() NotificationService {
+	if notificationServiceInstance != nil {
+		return notificationServiceInstance
+	}
+	db := config.GetDB()
+	return NewNotificationService(repositories.NewNotificationRepository(db), repositories.NewOrderRepository(db))
+}
+
+// AddSender delivers notifications over the sender's channel to the users who have it turned on
+// Senders are added once at startup
+func (s *DefaultNotificationService) AddSender(sender NotificationSender) {
+	s.senders = append(s.senders, sender)
+}
+
+// SetNotificationService sets the notification service instance (primarily for testing)
+func SetNotificationService(service NotificationService) {
+	notificationServiceInstance = service
+}
+
+// notificationSignal wakes the polls waiting in this process whenever it queues notifications
+// The channel is closed and replaced on every broadcast, so each waiter is woken once
+type notificationSignal struct {
+	mu sync.Mutex
+	ch chan struct{}
+}
+
+var newNotifications notificationSignal
+
+// wait returns a channel that is closed at the next broadcast
+func (s *notificationSignal) wait() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ch == nil {
+		s.ch = make(chan struct{})
+	}
+	return s.ch
+}
+
+// broadcast wakes every waiter
+func (s *notificationSignal) broadcast() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ch != nil {
+		close(s.ch)
+		s.ch = nil
+	}
+}
+
+// NotificationPruneJob returns the background job that removes notifications past their retention
+func NotificationPruneJob(service NotificationService) jobs.Job {
+	return jobs.Job{
+		Name:     "notification_prune",
+		Interval: NotificationPruneInterval,
+		Run: func(ctx context.Context) error {
+			_, err := service.Prune()
+			return err
+		},
+	}
+}
+
+// NotifyOrderStatusChanged queues order.status_changed for the order's customer and technician
+func (s *DefaultNotificationService) NotifyOrderStatusChanged(order *models.Order, previousStatus string) error {
+	var notifications []models.Notification
+	for _, userID := range orderParticipants(order, nil) {
+		notifications = append(notifications, models.Notification{
+			UserID:         userID,
+			Event:          models.NotificationOrderStatusChanged,
+			OrderID:        order.ID,
+			Status:         order.Status,
+			PreviousStatus: previousStatus,
+		})
+	}
+	return s.queue(notifications)
+}
+
+// NotifyMessageCreated queues message.created for the order's customer and technician, except its sender
+func (s *DefaultNotificationService) NotifyMessageCreated(message *models.Message) error {
+	order, err := s.orders.FindByID(message.OrderID)
+	if err != nil {
+		return err
+	}
+
+	var notifications []models.Notification
+	for _, userID := range orderParticipants(order, message.SenderID) {
+		messageID := message.ID
+		notifications = append(notifications, models.Notification{
+			UserID:    userID,
+			Event:     models.NotificationMessageCreated,
+			OrderID:   order.ID,
+			MessageID: &messageID,
+		})
+	}
+	return s.queue(notifications)
+}
+
+// NotifyTyping queues a typing indicator for the order's customer and technician, except the typist
+func (s *DefaultNotificationService) NotifyTyping(order *models.Order, typistID uint) error {
+	var notifications []models.Notification
+	for _, userID := range orderParticipants(order, &typistID) {
+		actorID := typistID
+		notifications = append(notifications, models.Notification{
+			UserID:  userID,
+			Event:   models.NotificationTyping,
+			OrderID: order.ID,
+			ActorID: &actorID,
+		})
+	}
+	return s.queue(notifications)
+}
+
+// NotifyReceipt queues a message.delivered or message.seen receipt for the order's customer and technician, except the reader
+func (s *DefaultNotificationService) NotifyReceipt(order *models.Order, event string, readerID, messageID uint) error {
+	var notifications []models.Notification
+	for _, userID := range orderParticipants(order, &readerID) {
+		actorID, upTo := readerID, messageID
+		notifications = append(notifications, models.Notification{
+			UserID:    userID,
+			Event:     event,
+			OrderID:   order.ID,
+			MessageID: &upTo,
+			ActorID:   &actorID,
+		})
+	}
+	return s.queue(notifications)
+}
+
+// queue saves notifications, wakes the polls waiting in this process, and sends them over the channels users want
+func (s *DefaultNotificationService) queue(notifications []models.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	if err := s.notifications.Create(notifications); err != nil {
+		return err
+	}
+	newNotifications.broadcast()
+	s.dispatch(notifications)
+	return nil
+}
+
+// dispatch hands each notification to the senders of the channels its user has turned on for its event type
+// A failed delivery is logged; the notification stays available to long polls either way
+func (s *DefaultNotificationService) dispatch(notifications []models.Notification) {
+	if len(s.senders) == 0 {
+		return
+	}
+
+	preferences := make(map[uint]*models.NotificationPreferences)
+	for _, notification := range notifications {
+		userPreferences, ok := preferences[notification.UserID]
+		if !ok {
+			var err error
+			if userPreferences, err = s.preferences(notification.UserID); err != nil {
+				log.Printf("Failed to load notification preferences of user %d: %v", notification.UserID, err)
+				continue
+			}
+			preferences[notification.UserID] = userPreferences
+		}
+
+		for _, sender := range s.senders {
+			if !userPreferences.Allows(notification.Event, sender.Channel()) {
+				continue
+			}
+			if err := sender.Send(notification); err != nil {
+				log.Printf("Failed to send %s notification %d by %s: %v", notification.Event, notification.ID, sender.Channel(), err)
+			}
+		}
+	}
+}
+
+// preferences loads the user's notification preferences, falling back to the defaults
+func (s *DefaultNotificationService) preferences(userID uint) (*models.NotificationPreferences, error) {
+	preferences, err := s.notifications.FindPreferences(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.DefaultNotificationPreferences(userID), nil
+	}
+	return preferences, err
+}
+
+// orderParticipants returns the order's customer and assigned technician, leaving out the user who caused the event
+func orderParticipants(order *models.Order, except *uint) []uint {
+	var userIDs []uint
+	for _, userID := range []*uint{&order.CustomerID, order.TechnicianID} {
+		if userID != nil && (except == nil || *userID != *except) {
+			userIDs = append(userIDs, *userID)
+		}
+	}
+	return userIDs
+}
+
+// Poll returns the user's notifications after the since cursor, waiting up to wait for one to arrive
+// An empty since starts from the user's latest notification, so only new events are returned
+func (s *DefaultNotificationService) Poll(ctx context.Context, user *models.User, since string, wait time.Duration) (*NotificationPoll, error) {
+	cursor, err := s.cursor(user, since)
+	if err != nil {
+		return nil, err
+	}
+	if wait <= 0 {
+		wait = DefaultPollWait
+	}
+	if wait > MaxPollWait {
+		wait = MaxPollWait
+	}
+
+	deadline := time.NewTimer(wait)
+	defer deadline.Stop()
+	recheck := time.NewTicker(s.recheck)
+	defer recheck.Stop()
+
+	for {
+		// Taken before the query so a notification queued in between still wakes this poll
+		woken := newNotifications.wait()
+
+		notifications, err := s.notifications.ListAfter(user.ID, cursor, MaxNotificationsPerPoll)
+		if err != nil {
+			return nil, apierror.Internal("DATABASE_ERROR", "Failed to load notifications").Wrap(err)
+		}
+		if len(notifications) > 0 {
+			cursor = notifications[len(notifications)-1].ID
+			if current := s.withoutStaleTyping(notifications); len(current) > 0 {
+				return &NotificationPoll{Notifications: current, Cursor: cursor}, nil
+			}
+			// Only stale typing indicators; keep waiting from past them
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return &NotificationPoll{Notifications: []models.Notification{}, Cursor: cursor}, nil
+		case <-deadline.C:
+			return &NotificationPoll{Notifications: []models.Notification{}, Cursor: cursor}, nil
+		case <-woken:
+		case <-recheck.C:
+		}
+	}
+}
+
+// withoutStaleTyping leaves out typing indicators older than TypingIndicatorTTL, which no longer mean anyone is typing
+func (s *DefaultNotificationService) withoutStaleTyping(notifications []models.Notification) []models.Notification {
+	cutoff := s.now().Add(-TypingIndicatorTTL)
+	current := make([]models.Notification, 0, len(notifications))
+	for _, notification := range notifications {
+		if notification.Event == models.NotificationTyping && notification.CreatedAt.Before(cutoff) {
+			continue
+		}
+		current = append(current, notification)
+	}
+	return current
+}
+
+// cursor parses the since parameter, defaulting to the user's latest notification
+func (s *DefaultNotificationService) cursor(user *models.User, since string) (uint, error) {
+	if since == "" {
+		latest, err := s.notifications.LatestID(user.ID)
+		if err != nil {
+			return 0, apierror.Internal("DATABASE_ERROR", "Failed to load notifications").Wrap(err)
+		}
+		return latest, nil
+	}
+
+	cursor, err := strconv.ParseUint(since, 10, 64)
+	if err != nil {
+		return 0, apierror.Validation("since must be the cursor of a previous poll", map[string]interface{}{"since": since})
+	}
+	return uint(cursor), nil
+}
+
+// Prune removes notifications older than NotificationRetention, and typing indicators past TypingIndicatorTTL,
+// and returns how many were removed
+func (s *DefaultNotificationService) Prune() (int64, error) {
+	now := s.now()
+	removed, err := s.notifications.DeleteBefore(now.Add(-NotificationRetention))
+	if err != nil {
+		return removed, err
+	}
+	typing, err := s.notifications.DeleteEventBefore(models.NotificationTyping, now.Add(-TypingIndicatorTTL))
+	return removed + typing, err
+}
+
+// GetPreferences returns the user's notification preferences, or the defaults when they have not set any
+func (s *DefaultNotificationService) GetPreferences(user *models.User) (*models.NotificationPreferences, error) {
+	preferences, err := s.preferences(user.ID)
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load notification preferences").Wrap(err)
+	}
+	return preferences, nil
+}
+
+// UpdatePreferences turns email and push notifications on or off per event type
+func (s *DefaultNotificationService) UpdatePreferences(user *models.User, input NotificationPreferencesInput) (*models.NotificationPreferences, error) {
+	preferences, err := s.GetPreferences(user)
+	if err != nil {
+		return nil, err
+	}
+
+	input.Message.applyTo(&preferences.Message)
+	input.StatusChange.applyTo(&preferences.StatusChange)
+	input.Promo.applyTo(&preferences.Promo)
+
+	if err := s.notifications.SavePreferences(preferences); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to save notification preferences").Wrap(err)
+	}
+	return preferences, nil
+}
+
+// applyTo sets the channels given in the input
+func (in *ChannelPreferenceInput) applyTo(preference *models.ChannelPreference) {
+	if in == nil {
+		return
+	}
+	if in.Email != nil {
+		preference.Email = *in.Email
+	}
+	if in.Push != nil {
+		preference.Push = *in.Push
+	}
+}
@@ -0,0 +1,291 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// fakeNotificationRepository is an in-memory NotificationRepository, safe to use from a waiting poll
+type fakeNotificationRepository struct {
+	mu            sync.Mutex
+	notifications []models.Notification
+	preferences   map[uint]models.NotificationPreferences
+}
+
+func (r *fakeNotificationRepository) Create(notifications []models.Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range notifications {
+		notifications[i].ID = uint(len(r.notifications) + 1)
+		r.notifications = append(r.notifications, notifications[i])
+	}
+	return nil
+}
+
+func (r *fakeNotificationRepository) ListAfter(userID, afterID uint, limit int) ([]models.Notification, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var notifications []models.Notification
+	for _, notification := range r.notifications {
+		if notification.UserID == userID && notification.ID > afterID && len(notifications) < limit {
+			notifications = append(notifications, notification)
+		}
+	}
+	return notifications, nil
+}
+
+func (r *fakeNotificationRepository) LatestID(userID uint) (uint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var latest uint
+	for _, notification := range r.notifications {
+		if notification.UserID == userID {
+			latest = notification.ID
+		}
+	}
+	return latest, nil
+}
+
+func (r *fakeNotificationRepository) DeleteBefore(cutoff time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var kept []models.Notification
+	for _, notification := range r.notifications {
+		if !notification.CreatedAt.Before(cutoff) {
+			kept = append(kept, notification)
+		}
+	}
+	removed := int64(len(r.notifications) - len(kept))
+	r.notifications = kept
+	return removed, nil
+}
+
+func (r *fakeNotificationRepository) DeleteEventBefore(event string, cutoff time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var kept []models.Notification
+	for _, notification := range r.notifications {
+		if notification.Event != event || !notification.CreatedAt.Before(cutoff) {
+			kept = append(kept, notification)
+		}
+	}
+	removed := int64(len(r.notifications) - len(kept))
+	r.notifications = kept
+	return removed, nil
+}
+
+func (r *fakeNotificationRepository) FindPreferences(userID uint) (*models.NotificationPreferences, error) {
+	preferences, ok := r.preferences[userID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &preferences, nil
+}
+
+func (r *fakeNotificationRepository) SavePreferences(preferences *models.NotificationPreferences) error {
+	if r.preferences == nil {
+		r.preferences = make(map[uint]models.NotificationPreferences)
+	}
+	r.preferences[preferences.UserID] = *preferences
+	return nil
+}
+
+// fakeNotificationSender records what it was asked to send
+type fakeNotificationSender struct {
+	channel string
+	sent    []models.Notification
+}
+
+func (s *fakeNotificationSender) Channel() string {
+	return s.channel
+}
+
+func (s *fakeNotificationSender) Send(notification models.Notification) error {
+	s.sent = append(s.sent, notification)
+	return nil
+}
+
+func TestNotificationService_Notify(t *testing.T) {
+	repo := &fakeNotificationRepository{}
+	service := NewNotificationService(repo, newFakeOrderRepository(
+		models.Order{ID: 1, CustomerID: testCustomer.ID, TechnicianID: uintPtr(testTechnician.ID), Status: StatusAccepted},
+		models.Order{ID: 2, CustomerID: testCustomer.ID, Status: StatusSubmitted},
+	))
+
+	// Status changes reach both parties
+	assert.NoError(t, service.NotifyOrderStatusChanged(&models.Order{ID: 1, CustomerID: testCustomer.ID, TechnicianID: uintPtr(testTechnician.ID), Status: StatusAccepted}, StatusSubmitted))
+
+	// Messages reach everyone but their sender; system notices reach both parties
+	assert.NoError(t, service.NotifyMessageCreated(&models.Message{ID: 7, OrderID: 1, SenderID: uintPtr(testCustomer.ID)}))
+	assert.NoError(t, service.NotifyMessageCreated(&models.Message{ID: 8, OrderID: 1}))
+
+	// Unassigned orders only notify the customer
+	assert.NoError(t, service.NotifyMessageCreated(&models.Message{ID: 9, OrderID: 2}))
+
+	customer, _ := repo.ListAfter(testCustomer.ID, 0, MaxNotificationsPerPoll)
+	if assert.Len(t, customer, 3) {
+		assert.Equal(t, models.NotificationOrderStatusChanged, customer[0].Event)
+		assert.Equal(t, StatusSubmitted, customer[0].PreviousStatus)
+		assert.Equal(t, uintPtr(8), customer[1].MessageID)
+		assert.Equal(t, uint(2), customer[2].OrderID)
+	}
+	technician, _ := repo.ListAfter(testTechnician.ID, 0, MaxNotificationsPerPoll)
+	if assert.Len(t, technician, 3) {
+		assert.Equal(t, uintPtr(7), technician[1].MessageID)
+	}
+}
+
+func TestNotificationService_Poll(t *testing.T) {
+	repo := &fakeNotificationRepository{}
+	service := NewNotificationService(repo, newFakeOrderRepository(
+		models.Order{ID: 1, CustomerID: testCustomer.ID, TechnicianID: uintPtr(testTechnician.ID)},
+	))
+	ctx := context.Background()
+	assert.NoError(t, service.NotifyMessageCreated(&models.Message{ID: 1, OrderID: 1, SenderID: uintPtr(testTechnician.ID)}))
+
+	// Events after the cursor are returned at once
+	poll, err := service.Poll(ctx, testCustomer, "0", time.Minute)
+	assert.NoError(t, err)
+	assert.Len(t, poll.Notifications, 1)
+	assert.Equal(t, uint(1), poll.Cursor)
+
+	// Without a cursor only new events count, so the poll waits and times out empty
+	poll, err = service.Poll(ctx, testCustomer, "", 20*time.Millisecond)
+	assert.NoError(t, err)
+	assert.Empty(t, poll.Notifications)
+	assert.Equal(t, uint(1), poll.Cursor)
+
+	// A waiting poll is woken by an event queued in this process
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		service.NotifyMessageCreated(&models.Message{ID: 2, OrderID: 1, SenderID: uintPtr(testTechnician.ID)})
+	}()
+	started := time.Now()
+	poll, err = service.Poll(ctx, testCustomer, "1", time.Minute)
+	assert.NoError(t, err)
+	assert.Less(t, time.Since(started), NotificationRecheckEvery)
+	if assert.Len(t, poll.Notifications, 1) {
+		assert.Equal(t, uintPtr(2), poll.Notifications[0].MessageID)
+	}
+	assert.Equal(t, uint(2), poll.Cursor)
+
+	// Events queued by another instance are found on the next recheck
+	service.recheck = 10 * time.Millisecond
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		repo.Create([]models.Notification{{UserID: testCustomer.ID, Event: models.NotificationOrderStatusChanged, OrderID: 1}})
+	}()
+	poll, err = service.Poll(ctx, testCustomer, "2", time.Minute)
+	assert.NoError(t, err)
+	assert.Len(t, poll.Notifications, 1)
+
+	// A closed connection ends the wait
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	poll, err = service.Poll(canceled, testCustomer, "3", time.Minute)
+	assert.NoError(t, err)
+	assert.Empty(t, poll.Notifications)
+
+	_, err = service.Poll(ctx, testCustomer, "latest", time.Minute)
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+}
+
+func TestNotificationService_Prune(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	repo := &fakeNotificationRepository{notifications: []models.Notification{
+		{ID: 1, UserID: testCustomer.ID, CreatedAt: now.Add(-NotificationRetention - time.Hour)},
+		{ID: 2, UserID: testCustomer.ID, CreatedAt: now.Add(-time.Hour)},
+		{ID: 3, UserID: testCustomer.ID, Event: models.NotificationTyping, CreatedAt: now.Add(-time.Minute)},
+		{ID: 4, UserID: testCustomer.ID, Event: models.NotificationTyping, CreatedAt: now},
+	}}
+	service := NewNotificationService(repo, newFakeOrderRepository())
+	service.now = func() time.Time { return now }
+
+	removed, err := service.Prune()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), removed)
+	assert.Len(t, repo.notifications, 2)
+	assert.Equal(t, uint(2), repo.notifications[0].ID)
+	assert.Equal(t, uint(4), repo.notifications[1].ID)
+}
+
+func TestNotificationService_PollSkipsStaleTyping(t *testing.T) {
+	now := time.Now()
+	repo := &fakeNotificationRepository{}
+	repo.Create([]models.Notification{
+		{UserID: testCustomer.ID, Event: models.NotificationTyping, OrderID: 1, CreatedAt: now.Add(-time.Minute)},
+	})
+	service := NewNotificationService(repo, newFakeOrderRepository(
+		models.Order{ID: 1, CustomerID: testCustomer.ID, TechnicianID: uintPtr(testTechnician.ID)},
+	))
+
+	// A poll finding only a typing indicator from a minute ago waits on past it
+	poll, err := service.Poll(context.Background(), testCustomer, "0", 20*time.Millisecond)
+	assert.NoError(t, err)
+	assert.Empty(t, poll.Notifications)
+	assert.Equal(t, uint(1), poll.Cursor)
+
+	assert.NoError(t, service.NotifyTyping(&models.Order{ID: 1, CustomerID: testCustomer.ID, TechnicianID: uintPtr(testTechnician.ID)}, testTechnician.ID))
+	repo.notifications[1].CreatedAt = now
+	poll, err = service.Poll(context.Background(), testCustomer, "1", time.Minute)
+	assert.NoError(t, err)
+	if assert.Len(t, poll.Notifications, 1) {
+		assert.Equal(t, models.NotificationTyping, poll.Notifications[0].Event)
+		assert.Equal(t, uintPtr(testTechnician.ID), poll.Notifications[0].ActorID)
+	}
+}
+
+func TestNotificationService_Preferences(t *testing.T) {
+	repo := &fakeNotificationRepository{}
+	service := NewNotificationService(repo, newFakeOrderRepository(
+		models.Order{ID: 1, CustomerID: testCustomer.ID, TechnicianID: uintPtr(testTechnician.ID), Status: StatusAccepted},
+	))
+	email := &fakeNotificationSender{channel: models.ChannelEmail}
+	push := &fakeNotificationSender{channel: models.ChannelPush}
+	service.AddSender(email)
+	service.AddSender(push)
+
+	// Order notifications go out on every channel by default, promotions on none
+	preferences, err := service.GetPreferences(testCustomer)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ChannelPreference{Email: true, Push: true}, preferences.Message)
+	assert.Equal(t, models.ChannelPreference{}, preferences.Promo)
+
+	// The customer keeps message emails but stops status change emails and all push notifications
+	off, on := false, true
+	preferences, err = service.UpdatePreferences(testCustomer, NotificationPreferencesInput{
+		Message:      &ChannelPreferenceInput{Push: &off},
+		StatusChange: &ChannelPreferenceInput{Email: &off, Push: &off},
+		Promo:        &ChannelPreferenceInput{Email: &on},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, models.ChannelPreference{Email: true}, preferences.Message)
+	assert.Equal(t, models.ChannelPreference{}, preferences.StatusChange)
+	assert.Equal(t, models.ChannelPreference{Email: true}, preferences.Promo)
+
+	assert.NoError(t, service.NotifyOrderStatusChanged(&models.Order{ID: 1, CustomerID: testCustomer.ID, TechnicianID: uintPtr(testTechnician.ID), Status: StatusInProduction}, StatusAccepted))
+	assert.NoError(t, service.NotifyMessageCreated(&models.Message{ID: 5, OrderID: 1, SenderID: uintPtr(testTechnician.ID)}))
+
+	// The technician kept the defaults and gets the status change on both channels
+	sentTo := func(sender *fakeNotificationSender) []string {
+		var sent []string
+		for _, notification := range sender.sent {
+			sent = append(sent, fmt.Sprintf("%d %s", notification.UserID, notification.Event))
+		}
+		return sent
+	}
+	assert.Equal(t, []string{"3 order.status_changed", "1 message.created"}, sentTo(email))
+	assert.Equal(t, []string{"3 order.status_changed"}, sentTo(push))
+
+	// Notifications are still kept for long polls whatever the channel preferences
+	customer, _ := repo.ListAfter(testCustomer.ID, 0, MaxNotificationsPerPoll)
+	assert.Len(t, customer, 2)
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"gorm.io/gorm"
+)
+
+// OrderLineage is the family of an order: the original it was first placed as and every reorder or duplicate since
+type OrderLineage struct {
+	RootOrderID uint           `json:"root_order_id"`
+	Count       int            `json:"count"`  // how many times the design has been ordered, the original included
+	Orders      []LineageOrder `json:"orders"` // the original first, then its copies in order of creation
+}
+
+// LineageOrder summarizes one order of a lineage
+// Only the fields needed to follow the chain are shared, since copies may be assigned to other technicians
+type LineageOrder struct {
+	ID              uint      `json:"id"`
+	OriginalOrderID *uint     `json:"original_order_id"`
+	Status          string    `json:"status"`
+	Quantity        int       `json:"quantity"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// OrderLineage returns the lineage of an order the user may view
+// Ancestors are followed up to the original, then every generation of copies is collected from there
+func (s *DefaultOrderService) OrderLineage(user *models.User, orderID string) (*OrderLineage, error) {
+	order, err := s.find(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if !CanViewOrder(user, order) {
+		return nil, apierror.Forbidden("FORBIDDEN", "You do not have permission to view this order")
+	}
+
+	// The seen set guards against a malformed cycle of original_order_id links
+	seen := map[uint]bool{order.ID: true}
+	root := order
+	for root.OriginalOrderID != nil && !seen[*root.OriginalOrderID] {
+		parent, err := s.orders.FindByID(*root.OriginalOrderID)
+		if err != nil {
+			// A deleted original leaves the oldest order still on record as the root
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				break
+			}
+			return nil, orderLookupError(err)
+		}
+		seen[parent.ID] = true
+		root = parent
+	}
+
+	lineage := &OrderLineage{RootOrderID: root.ID, Orders: []LineageOrder{lineageOrder(root)}}
+	visited := map[uint]bool{root.ID: true}
+	generation := []uint{root.ID}
+	for len(generation) > 0 {
+		copies, err := s.orders.ListCopiesOf(generation)
+		if err != nil {
+			return nil, apierror.Internal("DATABASE_ERROR", "Failed to fetch reorders").Wrap(err)
+		}
+		generation = nil
+		for i := range copies {
+			if visited[copies[i].ID] {
+				continue
+			}
+			visited[copies[i].ID] = true
+			lineage.Orders = append(lineage.Orders, lineageOrder(&copies[i]))
+			generation = append(generation, copies[i].ID)
+		}
+	}
+	// Generations interleave in time, so the copies are put back in order of creation
+	copies := lineage.Orders[1:]
+	sort.Slice(copies, func(i, j int) bool { return copies[i].ID < copies[j].ID })
+	lineage.Count = len(lineage.Orders)
+	return lineage, nil
+}
+
+// lineageOrder summarizes an order for its lineage
+func lineageOrder(order *models.Order) LineageOrder {
+	return LineageOrder{
+		ID:              order.ID,
+		OriginalOrderID: order.OriginalOrderID,
+		Status:          order.Status,
+		Quantity:        order.Quantity,
+		CreatedAt:       order.CreatedAt,
+	}
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+)
+
+// Order statuses
+const (
+	StatusSubmitted    = "submitted"
+	StatusAccepted     = "accepted"
+	StatusRejected     = "rejected"
+	StatusInProduction = "in_production"
+	StatusShipped      = "shipped"
+	StatusDelivered    = "delivered"
+	StatusExpired      = "expired" // never reviewed in time, see ExpireStaleOrders
+)
+
+// User roles
+const (
+	RoleCustomer   = "customer"
+	RoleTechnician = "technician"
+	RoleAdmin      = "admin"
+)
+
+// RoleIntegration is the role of an API key caller; it is never stored on a user
+// Integrations may read every order and may not change anything
+const RoleIntegration = "integration"
+
+// Roles lists every user role
+var Roles = []string{RoleCustomer, RoleTechnician, RoleAdmin}
+
+// IsRole reports whether the value is a known user role
+func IsRole(role string) bool {
+	for _, known := range Roles {
+		if role == known {
+			return true
+		}
+	}
+	return false
+}
+
+// OrderStatuses lists every order status
+var OrderStatuses = []string{StatusSubmitted, StatusAccepted, StatusRejected, StatusInProduction, StatusShipped, StatusDelivered, StatusExpired}
+
+// BookedStatuses are the statuses of orders that have been accepted and carry revenue
+var BookedStatuses = []string{StatusAccepted, StatusInProduction, StatusShipped, StatusDelivered}
+
+// IsBooked reports whether the order has been accepted and not rejected or expired
+func IsBooked(order *models.Order) bool {
+	for _, status := range BookedStatuses {
+		if order.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
+// FittingStatuses are the statuses of orders a customer can book a fitting for: accepted and not yet shipped
+var FittingStatuses = []string{StatusAccepted, StatusInProduction}
+
+// CanBookFitting reports whether a fitting with the order's technician can be booked
+func CanBookFitting(order *models.Order) bool {
+	if order.TechnicianID == nil {
+		return false
+	}
+	for _, status := range FittingStatuses {
+		if order.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
+// OpenStatuses are the statuses of orders that still have to ship and so can run late
+var OpenStatuses = []string{StatusSubmitted, StatusAccepted, StatusInProduction}
+
+// IsOverdue reports whether the order is still open after the end of the day it was due
+// Orders without a due date are never overdue
+func IsOverdue(order *models.Order, now time.Time) bool {
+	due := order.DueBy()
+	if due == nil || !due.Before(startOfDay(now)) {
+		return false
+	}
+	for _, status := range OpenStatuses {
+		if order.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
+// startOfDay returns midnight UTC of the day containing t; due dates are whole UTC days
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// validTransitions defines the status workflow technicians can drive with UpdateOrderStatus
+// Orders reach "accepted" or "rejected" through review, which is handled separately,
+// and "expired" when nobody reviews them in time
+var validTransitions = map[string][]string{
+	StatusAccepted:     {StatusInProduction},
+	StatusInProduction: {StatusShipped},
+	StatusShipped:      {StatusDelivered},
+	StatusDelivered:    {}, // Terminal state
+}
+
+// AllowedTransitions returns the statuses an order may move to from the given status
+// The boolean is false when the status is not part of the production workflow
+func AllowedTransitions(status string) ([]string, bool) {
+	allowed, exists := validTransitions[status]
+	return allowed, exists
+}
+
+// CanTransition reports whether an order may move from one status to another
+func CanTransition(from, to string) bool {
+	allowed, _ := AllowedTransitions(from)
+	for _, status := range allowed {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// CanViewOrder reports whether the user may view the order
+// Customers can only access their own orders and gifts placed for them
+// Technicians can access orders assigned to them or unassigned orders
+// Integrations can access every order
+func CanViewOrder(user *models.User, order *models.Order) bool {
+	switch user.Role {
+	case RoleCustomer:
+		return order.CustomerID == user.ID || IsGiftRecipient(order, user)
+	case RoleTechnician:
+		return order.TechnicianID == nil || *order.TechnicianID == user.ID
+	case RoleIntegration:
+		return true
+	}
+	return false
+}
+
+// IsGiftRecipient reports whether the order is a gift claimed by or placed for the user's account
+func IsGiftRecipient(order *models.Order, user *models.User) bool {
+	return order.RecipientID != nil && *order.RecipientID == user.ID
+}
+
+// IsAssignedTo reports whether the order is assigned to the given technician
+func IsAssignedTo(order *models.Order, technician *models.User) bool {
+	return order.TechnicianID != nil && *order.TechnicianID == technician.ID
+}
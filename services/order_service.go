@@ -0,0 +1,1354 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/cache"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/events"
+	"github.com/kendall-kelly/kendalls-nails-api/jobs"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"github.com/kendall-kelly/kendalls-nails-api/utils"
+	"gorm.io/gorm"
+)
+
+// CreateOrderInput holds the validated fields for a new order
+type CreateOrderInput struct {
+	Description string
+	Quantity    int
+	ImageS3Key  *string
+	Rush        bool
+	RequestedBy *time.Time // optional date the customer would like the order by, midnight UTC
+
+	// CatalogDesignID orders a catalog design; its description is used when Description is empty
+	// and its base price is pre-filled as the order price
+	CatalogDesignID *uint
+
+	// SavedDesignID orders one of the customer's saved designs; its description and image are used
+	// when Description and ImageS3Key are empty
+	SavedDesignID *uint
+
+	// ShippingAddressID is an address from the customer's address book; it may also be chosen later,
+	// but is required before the order can be accepted
+	ShippingAddressID *uint
+
+	// RecipientEmail makes the order a gift for someone else; RecipientName and GiftMessage are optional
+	// A recipient without an account is invited to claim the gift once they sign up
+	RecipientEmail string
+	RecipientName  string
+	GiftMessage    string
+}
+
+// ListOrdersOptions controls pagination and filtering for ListOrders
+type ListOrdersOptions struct {
+	Page          int
+	Limit         int
+	Statuses      []string   // only orders in these statuses; empty lists every status
+	CreatedAfter  *time.Time // inclusive
+	CreatedBefore *time.Time // exclusive
+	UpdatedAfter  *time.Time // inclusive
+	Sort          string     // column to sort by, see repositories.OrderSortColumns
+	Order         string     // "asc" or "desc" (default)
+	Cursor        string     // ListOrdersByCursor only: the next_cursor of the previous page; empty starts at the first
+	Relations     []string   // relations to load with each order, see repositories.OrderRelations; nil loads them all
+}
+
+// ReviewOrderInput holds a technician's decision on a submitted order
+// When accepting, Price is the base set price; add-ons and a rush fee are itemized on top of it
+// Orders placed from the catalog default to the design's base price when Price is omitted
+// Rush orders get the configured rush surcharge, flat or a percentage of the quote, unless RushFee overrides it
+type ReviewOrderInput struct {
+	Action     string // "accept" or "reject"
+	Price      *float64
+	AddOns     []AddOnSelection
+	RushFee    *float64
+	PromisedBy *time.Time // optional delivery date promised when accepting, midnight UTC
+	Feedback   *string
+	Version    uint // the order version the technician reviewed; 0 skips the check
+}
+
+// BulkStatusResult is the outcome of one order in a bulk status update
+type BulkStatusResult struct {
+	OrderID uint
+	Order   *models.Order // set on success
+	Err     error         // set on failure
+}
+
+// AddOnSelection is a catalog add-on included in a quote
+type AddOnSelection struct {
+	AddOnID  uint
+	Quantity int // defaults to 1
+}
+
+// OrderService contains the order workflow and authorization rules
+type OrderService interface {
+	// AuthorizeCreate checks that the user may create orders (customers only)
+	AuthorizeCreate(user *models.User) error
+
+	// CreateOrder creates a submitted order for the customer
+	CreateOrder(customer *models.User, input CreateOrderInput) (*models.Order, error)
+
+	// CreateOrders creates several submitted orders for the customer, all or none
+	CreateOrders(customer *models.User, inputs []CreateOrderInput) ([]models.Order, error)
+
+	// ListOrders returns the page of orders visible to the user and the total count
+	ListOrders(user *models.User, opts ListOrdersOptions) ([]models.Order, int64, error)
+
+	// ListOrdersByCursor returns the orders visible to the user after opts.Cursor, sorted by created_at, and the
+	// cursor of the next page, empty on the last page; Page is ignored and the total is not counted
+	ListOrdersByCursor(user *models.User, opts ListOrdersOptions) ([]models.Order, string, error)
+
+	// GetOrder returns an order the user is allowed to view
+	GetOrder(user *models.User, orderID string) (*models.Order, error)
+
+	// ReviewOrder accepts or rejects a submitted order (technicians only)
+	ReviewOrder(technician *models.User, orderID string, input ReviewOrderInput) (*models.Order, error)
+
+	// UpdateOrderStatus advances an assigned order through the production workflow
+	// version is the order version the technician last saw, and 0 skips the check
+	UpdateOrderStatus(technician *models.User, orderID string, status string, version uint) (*models.Order, error)
+
+	// BulkUpdateOrderStatus applies the same status to several orders, reporting each outcome
+	BulkUpdateOrderStatus(technician *models.User, orderIDs []uint, status string) ([]BulkStatusResult, error)
+
+	// SetShippingAddress chooses where the customer's submitted order ships, from their address book
+	SetShippingAddress(customer *models.User, orderID string, addressID uint) (*models.Order, error)
+
+	// Reorder creates a new submitted order from a delivered one
+	Reorder(customer *models.User, orderID string, quantity int) (*models.Order, error)
+
+	// DuplicateOrder creates a new submitted order from any of the customer's orders
+	DuplicateOrder(customer *models.User, orderID string) (*models.Order, error)
+
+	// OrderLineage returns the original of an order and every reorder or duplicate made from it
+	OrderLineage(user *models.User, orderID string) (*OrderLineage, error)
+
+	// AssignOrder assigns an unassigned order to the technician
+	AssignOrder(technician *models.User, orderID string) (*models.Order, error)
+
+	// ListOverdueOrders returns the technician's open orders that are past their due date, earliest due first
+	ListOverdueOrders(technician *models.User) ([]models.Order, error)
+
+	// ExpireStaleOrders expires orders that have waited for review longer than maxAge and tells their customers
+	// It returns how many orders were expired
+	ExpireStaleOrders(maxAge time.Duration) (int, error)
+}
+
+// MaxDueDateHorizon is how far ahead a requested or promised date may be
+const MaxDueDateHorizon = 365 * 24 * time.Hour
+
+// StaleOrderCheckInterval is how often the stale order expiry job looks for orders to expire
+const StaleOrderCheckInterval = time.Hour
+
+// MaxOrderBatchSize is how many orders one batch may create
+const MaxOrderBatchSize = 25
+
+// staleOrderBatchSize is how many stale orders are loaded at a time
+const staleOrderBatchSize = 100
+
+// DefaultOrderService implements OrderService on top of an OrderRepository
+type DefaultOrderService struct {
+	orders     repositories.OrderRepository
+	addOns     repositories.AddOnRepository
+	checklists repositories.ChecklistRepository
+	priceLists repositories.PriceListRepository
+	catalog    repositories.CatalogRepository
+	addresses  repositories.AddressRepository
+	designs    repositories.SavedDesignRepository
+	settings   repositories.TechnicianSettingsRepository
+	audit      repositories.AuditLogRepository // nil records nothing
+	users      repositories.UserRepository     // nil rejects gift orders
+	gifts      repositories.GiftInvitationRepository
+	consents   repositories.ConsentRepository // nil skips the terms of service check
+	outbox     repositories.OutboxRepository  // nil publishes events as soon as they happen
+	now        func() time.Time
+}
+
+var orderServiceInstance OrderService
+
+// NewOrderService creates an order service using the given repositories
+// A nil price list repository quotes without seasonal pricing, a nil catalog rejects catalog designs,
+// a nil address repository rejects shipping addresses, a nil saved design repository rejects saved designs,
+// and a nil technician settings repository places no limit on technicians' capacity
+func NewOrderService(orders repositories.OrderRepository, addOns repositories.AddOnRepository, checklists repositories.ChecklistRepository, priceLists repositories.PriceListRepository, catalog repositories.CatalogRepository, addresses repositories.AddressRepository, designs repositories.SavedDesignRepository, settings repositories.TechnicianSettingsRepository) *DefaultOrderService {
+	return &DefaultOrderService{orders: orders, addOns: addOns, checklists: checklists, priceLists: priceLists, catalog: catalog, addresses: addresses, designs: designs, settings: settings, now: time.Now}
+}
+
+// GetOrderService returns the configured order service
+// When none has been set, a service over the current database connection is returned
+// so that swapping the database with config.SetDB is picked up immediately
+func GetOrderService() OrderService {
+	return GetOrderServiceFor(context.Background())
+}
+
+// GetOrderServiceFor returns the configured order service, running in the request transaction ctx carries, if any
+// Orders are read from the transaction rather than the cache, so uncommitted changes are never cached
+// Outside a transaction, order lists are read from the read replica when one is configured
+func GetOrderServiceFor(ctx context.Context) OrderService {
+	if orderServiceInstance != nil {
+		return orderServiceInstance
+	}
+	db := config.GetDBFor(ctx)
+	orders := repositories.OrderRepository(repositories.NewOrderRepositoryWithReplica(db, config.GetReplicaDBFor(ctx)))
+	if _, ok := config.TxFromContext(ctx); !ok {
+		orders = cachedOrders(orders)
+	}
+	service := NewOrderService(
+		orders,
+		repositories.NewAddOnRepository(db),
+		repositories.NewChecklistRepository(db),
+		repositories.NewPriceListRepository(db),
+		repositories.NewCatalogRepository(db),
+		repositories.NewAddressRepository(db),
+		repositories.NewSavedDesignRepository(db),
+		repositories.NewTechnicianSettingsRepository(db),
+	)
+	service.audit = repositories.NewAuditLogRepository(db)
+	service.users = repositories.NewUserRepository(db)
+	service.gifts = repositories.NewGiftInvitationRepository(db)
+	service.consents = repositories.NewConsentRepository(db)
+	service.outbox = repositories.NewOutboxRepository(db)
+	return service
+}
+
+// publish announces an event about a change the service saved, through the outbox when it has one
+func (s *DefaultOrderService) publish(event events.Event) error {
+	return publishThrough(s.outbox, event, s.now())
+}
+
+// cachedOrders puts the configured cache, if any, in front of order lookups
+func cachedOrders(orders repositories.OrderRepository) repositories.OrderRepository {
+	if store := cache.Default(); store != nil {
+		return repositories.NewCachedOrderRepository(orders, store, cache.DefaultTTL())
+	}
+	return orders
+}
+
+// SetOrderService sets the order service instance (primarily for testing)
+func SetOrderService(service OrderService) {
+	orderServiceInstance = service
+}
+
+// AuthorizeCreate checks that the user may create orders (customers only)
+// Customers must also have accepted terms of service recent enough, when terms are configured
+func (s *DefaultOrderService) AuthorizeCreate(user *models.User) error {
+	if user.Role != RoleCustomer {
+		return apierror.Forbidden("FORBIDDEN", "Only customers can create orders")
+	}
+	return requireTermsAccepted(s.consents, user)
+}
+
+// CreateOrder creates a submitted order for the customer
+func (s *DefaultOrderService) CreateOrder(customer *models.User, input CreateOrderInput) (*models.Order, error) {
+	if err := s.AuthorizeCreate(customer); err != nil {
+		return nil, err
+	}
+	order, err := s.buildOrder(customer, input)
+	if err != nil {
+		return nil, err
+	}
+
+	created, invitation, claimCode, err := s.insertOrder(customer, order)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.publish(OrderCreated{Order: created}); err != nil {
+		return nil, err
+	}
+	if invitation != nil {
+		if err := s.publish(newGiftInvited(created, invitation, customer, claimCode)); err != nil {
+			return nil, err
+		}
+		created.GiftClaimCode = claimCode
+	}
+	return created, nil
+}
+
+// CreateOrders creates several submitted orders for the customer, all or none
+// Every input is validated before any order is written, and a failure reports the input's index
+// The writes are atomic only when the service runs in a request transaction (see GetOrderServiceFor)
+func (s *DefaultOrderService) CreateOrders(customer *models.User, inputs []CreateOrderInput) ([]models.Order, error) {
+	if err := s.AuthorizeCreate(customer); err != nil {
+		return nil, err
+	}
+	if len(inputs) == 0 || len(inputs) > MaxOrderBatchSize {
+		return nil, apierror.Validation("Invalid order batch", map[string]string{
+			"orders": fmt.Sprintf("must contain between 1 and %d orders", MaxOrderBatchSize),
+		})
+	}
+
+	orders := make([]*models.Order, len(inputs))
+	for i, input := range inputs {
+		order, err := s.buildOrder(customer, input)
+		if err != nil {
+			failed := apierror.As(err)
+			return nil, apierror.New(failed.Status, failed.Code, fmt.Sprintf("Order %d: %s", i+1, failed.Message)).
+				WithDetails(map[string]interface{}{"index": i, "details": failed.Details}).
+				Wrap(failed.Err)
+		}
+		orders[i] = order
+	}
+
+	created := make([]models.Order, 0, len(orders))
+	invitations := make([]*models.GiftInvitation, 0, len(orders))
+	claimCodes := make([]string, 0, len(orders))
+	for _, order := range orders {
+		reloaded, invitation, claimCode, err := s.insertOrder(customer, order)
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, *reloaded)
+		invitations = append(invitations, invitation)
+		claimCodes = append(claimCodes, claimCode)
+	}
+
+	// Only announce the orders once every one of them has been written
+	for i := range created {
+		if err := s.publish(OrderCreated{Order: &created[i]}); err != nil {
+			return nil, err
+		}
+		if invitations[i] != nil {
+			if err := s.publish(newGiftInvited(&created[i], invitations[i], customer, claimCodes[i])); err != nil {
+				return nil, err
+			}
+			created[i].GiftClaimCode = claimCodes[i]
+		}
+	}
+	return created, nil
+}
+
+// insertOrder writes a built order, and the invitation of a gift to someone without an account, then reloads the order
+// The invitation's claim code is returned separately so it never reaches the order.created event
+func (s *DefaultOrderService) insertOrder(customer *models.User, order *models.Order) (*models.Order, *models.GiftInvitation, string, error) {
+	if err := s.orders.Create(order); err != nil {
+		return nil, nil, "", apierror.Internal("DATABASE_ERROR", "Failed to create order").Wrap(err)
+	}
+	invitation, claimCode, err := s.inviteRecipient(customer, order)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	created, err := s.reload(order.ID)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return created, invitation, claimCode, nil
+}
+
+// buildOrder validates the input and returns the unsaved order it describes
+func (s *DefaultOrderService) buildOrder(customer *models.User, input CreateOrderInput) (*models.Order, error) {
+	if err := s.validateDueDate("requested_by", input.RequestedBy); err != nil {
+		return nil, err
+	}
+
+	order := &models.Order{
+		Description: input.Description,
+		Quantity:    input.Quantity,
+		Status:      StatusSubmitted,
+		CustomerID:  customer.ID,
+		ImageS3Key:  input.ImageS3Key, // Store S3 key if image was uploaded
+		Rush:        input.Rush,
+		RequestedBy: input.RequestedBy,
+		Currency:    studioCurrency(),
+	}
+	if input.CatalogDesignID != nil && input.SavedDesignID != nil {
+		return nil, apierror.Validation("Choose either a catalog design or a saved design", nil)
+	}
+	if input.CatalogDesignID != nil {
+		if err := s.applyCatalogDesign(order, *input.CatalogDesignID); err != nil {
+			return nil, err
+		}
+	}
+	if input.SavedDesignID != nil {
+		if err := s.applySavedDesign(order, customer, *input.SavedDesignID); err != nil {
+			return nil, err
+		}
+	}
+	if strings.TrimSpace(order.Description) == "" {
+		return nil, apierror.Validation("Description is required", nil)
+	}
+	if input.ShippingAddressID != nil {
+		if err := s.applyShippingAddress(order, customer, *input.ShippingAddressID); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.applyRecipient(order, customer, input); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// applyCatalogDesign links the order to a catalog design and pre-fills its description and price
+func (s *DefaultOrderService) applyCatalogDesign(order *models.Order, designID uint) error {
+	if s.catalog == nil {
+		return apierror.Unprocessable("INVALID_CATALOG_DESIGN", "Catalog design not found").WithDetails(map[string]interface{}{
+			"catalog_design_id": designID,
+		})
+	}
+	design, err := s.catalog.FindByID(designID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apierror.Unprocessable("INVALID_CATALOG_DESIGN", "Catalog design not found").WithDetails(map[string]interface{}{
+				"catalog_design_id": designID,
+			})
+		}
+		return apierror.Internal("DATABASE_ERROR", "Failed to load catalog design").Wrap(err)
+	}
+
+	order.CatalogDesignID = &design.ID
+	if strings.TrimSpace(order.Description) == "" {
+		order.Description = design.Description
+	}
+	price := design.BasePrice
+	order.Price = &price
+	order.Currency = design.Currency
+	return nil
+}
+
+// applySavedDesign links the order to one of the customer's saved designs and fills in its description and image
+func (s *DefaultOrderService) applySavedDesign(order *models.Order, customer *models.User, designID uint) error {
+	invalid := apierror.Unprocessable("INVALID_SAVED_DESIGN", "Saved design not found").WithDetails(map[string]interface{}{
+		"design_id": designID,
+	})
+	if s.designs == nil {
+		return invalid
+	}
+	design, err := s.designs.FindForCustomer(customer.ID, designID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return invalid
+		}
+		return apierror.Internal("DATABASE_ERROR", "Failed to load saved design").Wrap(err)
+	}
+
+	order.SavedDesignID = &design.ID
+	if strings.TrimSpace(order.Description) == "" {
+		order.Description = design.Description
+	}
+	if order.ImageS3Key == nil {
+		order.ImageS3Key = design.ImageS3Key
+	}
+	return nil
+}
+
+// applyShippingAddress copies an address from the customer's address book onto the order
+func (s *DefaultOrderService) applyShippingAddress(order *models.Order, customer *models.User, addressID uint) error {
+	invalid := apierror.Unprocessable("INVALID_SHIPPING_ADDRESS", "Shipping address not found").WithDetails(map[string]interface{}{
+		"shipping_address_id": addressID,
+	})
+	if s.addresses == nil {
+		return invalid
+	}
+	address, err := s.addresses.FindForUser(customer.ID, addressID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return invalid
+		}
+		return apierror.Internal("DATABASE_ERROR", "Failed to load shipping address").Wrap(err)
+	}
+
+	order.ShippingAddressID = &address.ID
+	order.ShippingAddress = address.Snapshot()
+	return nil
+}
+
+// ListOrders returns the page of orders visible to the user and the total count
+// Customers see their orders and the gifts they have received
+// Technicians see orders assigned to them + unassigned orders, with rush orders first
+// Admins and integrations see every order
+func (s *DefaultOrderService) ListOrders(user *models.User, opts ListOrdersOptions) ([]models.Order, int64, error) {
+	query, err := listOrdersQuery(user, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	query.Offset = (opts.Page - 1) * opts.Limit
+
+	orders, total, err := s.orders.List(query)
+	if err != nil {
+		return nil, 0, apierror.Internal("DATABASE_ERROR", "Failed to fetch orders").Wrap(err)
+	}
+	s.prepareListed(user, orders)
+	return orders, total, nil
+}
+
+// ListOrdersByCursor returns the orders visible to the user after opts.Cursor, sorted by created_at, and the
+// cursor of the next page, empty on the last page; Page is ignored and the total is not counted
+// Unlike page numbers, cursors cost the same however deep the page, and orders created meanwhile never shift a page
+func (s *DefaultOrderService) ListOrdersByCursor(user *models.User, opts ListOrdersOptions) ([]models.Order, string, error) {
+	if opts.Sort != "" && opts.Sort != "created_at" {
+		return nil, "", apierror.Validation("Invalid sort column", map[string]string{
+			"sort": "must be created_at when paging by cursor",
+		})
+	}
+	query, err := listOrdersQuery(user, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	if opts.Cursor != "" {
+		after, err := decodeOrderCursor(opts.Cursor)
+		if err != nil {
+			return nil, "", apierror.Validation("Invalid cursor", map[string]string{
+				"cursor": "must be the next_cursor of a previous page",
+			})
+		}
+		query.After = &after
+	}
+	query.Keyset = true
+	query.Limit = opts.Limit + 1 // one more than the page shows whether another page follows
+
+	orders, _, err := s.orders.List(query)
+	if err != nil {
+		return nil, "", apierror.Internal("DATABASE_ERROR", "Failed to fetch orders").Wrap(err)
+	}
+
+	next := ""
+	if len(orders) > opts.Limit {
+		orders = orders[:opts.Limit]
+		last := orders[len(orders)-1]
+		next = encodeOrderCursor(repositories.OrderCursor{CreatedAt: last.CreatedAt, ID: last.ID, Rush: last.Rush})
+	}
+	s.prepareListed(user, orders)
+	return orders, next, nil
+}
+
+// listOrdersQuery checks the list options and limits the query to the orders the user may see
+func listOrdersQuery(user *models.User, opts ListOrdersOptions) (repositories.OrderListQuery, error) {
+	if opts.CreatedAfter != nil && opts.CreatedBefore != nil && !opts.CreatedAfter.Before(*opts.CreatedBefore) {
+		return repositories.OrderListQuery{}, apierror.Validation("created_after must be before created_before", nil)
+	}
+
+	for _, status := range opts.Statuses {
+		if !oneOf(status, OrderStatuses) {
+			return repositories.OrderListQuery{}, apierror.Validation("Invalid status filter", map[string]string{
+				"status": "must be one of: " + strings.Join(OrderStatuses, ", "),
+			})
+		}
+	}
+
+	if opts.Sort != "" && !repositories.IsOrderSortColumn(opts.Sort) {
+		return repositories.OrderListQuery{}, apierror.Validation("Invalid sort column", map[string]string{
+			"sort": "must be one of: " + strings.Join(repositories.OrderSortColumns, ", "),
+		})
+	}
+	if opts.Order != "" && opts.Order != "asc" && opts.Order != "desc" {
+		return repositories.OrderListQuery{}, apierror.Validation("Invalid sort order", map[string]string{
+			"order": "must be asc or desc",
+		})
+	}
+
+	query := repositories.OrderListQuery{
+		Limit:         opts.Limit,
+		Statuses:      opts.Statuses,
+		CreatedAfter:  opts.CreatedAfter,
+		CreatedBefore: opts.CreatedBefore,
+		UpdatedAfter:  opts.UpdatedAfter,
+		SortBy:        opts.Sort,
+		SortAscending: opts.Order == "asc",
+		Relations:     opts.Relations,
+	}
+
+	switch user.Role {
+	case RoleCustomer:
+		query.CustomerOrRecipientID = &user.ID
+	case RoleTechnician:
+		query.AssignedOrUnassignedToTechID = &user.ID
+		query.RushFirst = true
+	}
+	return query, nil
+}
+
+// prepareListed flags overdue orders and hides what the user may not see of them
+func (s *DefaultOrderService) prepareListed(user *models.User, orders []models.Order) {
+	s.flagOverdue(orders)
+	for i := range orders {
+		hideGiftPrice(user, &orders[i])
+	}
+}
+
+// orderCursor is the JSON form of a repositories.OrderCursor, encoded as unpadded base64url
+type orderCursor struct {
+	CreatedAt time.Time `json:"c"`
+	ID        uint      `json:"i"`
+	Rush      bool      `json:"r,omitempty"`
+}
+
+// encodeOrderCursor returns the opaque cursor clients send back to continue after the order
+func encodeOrderCursor(cursor repositories.OrderCursor) string {
+	data, _ := json.Marshal(orderCursor{CreatedAt: cursor.CreatedAt, ID: cursor.ID, Rush: cursor.Rush})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeOrderCursor parses a cursor made by encodeOrderCursor
+func decodeOrderCursor(value string) (repositories.OrderCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return repositories.OrderCursor{}, err
+	}
+	var cursor orderCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return repositories.OrderCursor{}, err
+	}
+	if cursor.ID == 0 || cursor.CreatedAt.IsZero() {
+		return repositories.OrderCursor{}, errors.New("cursor is missing its position")
+	}
+	return repositories.OrderCursor{CreatedAt: cursor.CreatedAt, ID: cursor.ID, Rush: cursor.Rush}, nil
+}
+
+// GetOrder returns an order the user is allowed to view
+func (s *DefaultOrderService) GetOrder(user *models.User, orderID string) (*models.Order, error) {
+	id, err := parseOrderID(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := s.orders.FindByIDWithRelations(id)
+	if err != nil {
+		return nil, orderLookupError(err)
+	}
+
+	if !CanViewOrder(user, order) {
+		return nil, apierror.Forbidden("FORBIDDEN", "You do not have permission to access this order")
+	}
+
+	order.Overdue = IsOverdue(order, s.now())
+	hideGiftPrice(user, order)
+	return order, nil
+}
+
+// ReviewOrder accepts or rejects a submitted order (technicians only)
+// The reviewing technician becomes the assigned technician
+func (s *DefaultOrderService) ReviewOrder(technician *models.User, orderID string, input ReviewOrderInput) (*models.Order, error) {
+	if technician.Role != RoleTechnician {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only technicians can review orders")
+	}
+
+	order, err := s.find(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(order, input.Version); err != nil {
+		return nil, err
+	}
+
+	// Check if order has already been reviewed
+	if order.Status == StatusExpired {
+		return nil, apierror.Unprocessable("INVALID_STATE", "Order expired before it was reviewed")
+	}
+	if order.Status != StatusSubmitted {
+		return nil, apierror.Unprocessable("INVALID_STATE", "Order has already been reviewed")
+	}
+
+	// Orders claimed or requested through a technician's intake widget are theirs to review
+	if order.TechnicianID != nil && !IsAssignedTo(order, technician) {
+		return nil, apierror.Forbidden("FORBIDDEN", "Order is assigned to another technician")
+	}
+
+	// Validate action-specific requirements and apply the decision
+	before := orderDecision(order)
+	reviewedAt := time.Now()
+	switch input.Action {
+	case "accept":
+		if input.Price == nil && (order.CatalogDesignID != nil || order.QuoteID != nil) {
+			input.Price = order.Price
+		}
+		if input.Price == nil {
+			return nil, apierror.Validation("Price is required when accepting an order", nil)
+		}
+		if *input.Price <= 0 {
+			return nil, apierror.Validation("Price must be greater than zero", nil)
+		}
+		if err := requireWholeCents("Price", *input.Price); err != nil {
+			return nil, err
+		}
+		if order.ShippingAddress == nil {
+			return nil, apierror.Unprocessable("SHIPPING_ADDRESS_REQUIRED", "The customer must choose a shipping address before the order can be accepted")
+		}
+		if err := s.validateDueDate("promised_by", input.PromisedBy); err != nil {
+			return nil, err
+		}
+		// An order the technician already claimed counts toward their capacity already
+		if order.TechnicianID == nil {
+			if err := checkTechnicianCapacity(s.settings, technician); err != nil {
+				return nil, err
+			}
+		}
+		lineItems, totalCents, err := s.quote(order, technician, reviewedAt, utils.ToCents(*input.Price), input)
+		if err != nil {
+			return nil, err
+		}
+		total := utils.FromCents(totalCents)
+		order.Status = StatusAccepted
+		order.LineItems = lineItems
+		order.Price = &total
+		order.PromisedBy = input.PromisedBy
+	case "reject":
+		if input.Feedback == nil || *input.Feedback == "" {
+			return nil, apierror.Validation("Feedback is required when rejecting an order", nil)
+		}
+		if input.PromisedBy != nil {
+			return nil, apierror.Validation("A delivery date can only be promised when accepting an order", nil)
+		}
+		order.Status = StatusRejected
+		order.Feedback = input.Feedback
+	default:
+		return nil, apierror.Validation("Action must be accept or reject", nil)
+	}
+	order.TechnicianID = &technician.ID
+	order.ReviewedAt = &reviewedAt
+
+	if err := s.save(order, "Failed to update order"); err != nil {
+		return nil, err
+	}
+	if err := recordAudit(s.audit, technician, models.AuditOrderReviewed, models.AuditTargetOrder, order.ID, before, orderDecision(order)); err != nil {
+		return nil, err
+	}
+
+	reviewed, err := s.reload(order.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.publish(OrderStatusChanged{Order: reviewed, PreviousStatus: StatusSubmitted}); err != nil {
+		return nil, err
+	}
+	return reviewed, nil
+}
+
+// UpdateOrderStatus advances an assigned order through the production workflow
+// Starting production attaches the technician's checklist, which must be complete before shipping
+func (s *DefaultOrderService) UpdateOrderStatus(technician *models.User, orderID string, status string, version uint) (*models.Order, error) {
+	if technician.Role != RoleTechnician {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only technicians can update order status")
+	}
+
+	order, err := s.find(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(order, version); err != nil {
+		return nil, err
+	}
+
+	// Check if order is assigned to this technician
+	if !IsAssignedTo(order, technician) {
+		return nil, apierror.Forbidden("FORBIDDEN", "You can only update status of orders assigned to you")
+	}
+
+	// Check if the current status allows the requested transition
+	allowedStatuses, exists := AllowedTransitions(order.Status)
+	if !exists {
+		return nil, apierror.Unprocessable("INVALID_STATE", "Cannot update status from current order state")
+	}
+
+	if !CanTransition(order.Status, status) {
+		return nil, apierror.Unprocessable("INVALID_TRANSITION", "Invalid status transition").WithDetails(map[string]interface{}{
+			"current_status":   order.Status,
+			"requested_status": status,
+			"allowed_statuses": allowedStatuses,
+		})
+	}
+
+	// Production start and shipping times feed the fulfillment SLA report, delivery times technician earnings
+	now := time.Now()
+	switch status {
+	case StatusInProduction:
+		if err := s.attachChecklist(order, technician); err != nil {
+			return nil, err
+		}
+		order.ProductionStartedAt = &now
+	case StatusShipped:
+		if err := s.requireChecklistComplete(order); err != nil {
+			return nil, err
+		}
+		order.ShippedAt = &now
+	case StatusDelivered:
+		order.DeliveredAt = &now
+	}
+
+	previousStatus := order.Status
+	order.Status = status
+
+	if err := s.save(order, "Failed to update order status"); err != nil {
+		return nil, err
+	}
+	if err := recordAudit(s.audit, technician, models.AuditOrderStatusChanged, models.AuditTargetOrder, order.ID,
+		map[string]interface{}{"status": previousStatus}, map[string]interface{}{"status": status}); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.reload(order.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.publish(OrderStatusChanged{Order: updated, PreviousStatus: previousStatus}); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// BulkUpdateOrderStatus applies the same status to several orders, reporting each outcome
+// Every order is checked and saved independently, so one invalid order does not block the rest
+func (s *DefaultOrderService) BulkUpdateOrderStatus(technician *models.User, orderIDs []uint, status string) ([]BulkStatusResult, error) {
+	if technician.Role != RoleTechnician {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only technicians can update order status")
+	}
+
+	results := make([]BulkStatusResult, len(orderIDs))
+	for i, id := range orderIDs {
+		order, err := s.UpdateOrderStatus(technician, strconv.FormatUint(uint64(id), 10), status, 0)
+		results[i] = BulkStatusResult{OrderID: id, Order: order, Err: err}
+	}
+	return results, nil
+}
+
+// ListOverdueOrders returns the technician's open orders that are past their due date, earliest due first
+func (s *DefaultOrderService) ListOverdueOrders(technician *models.User) ([]models.Order, error) {
+	if technician.Role != RoleTechnician {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only technicians can list overdue orders")
+	}
+
+	orders, err := s.orders.ListDueBefore(technician.ID, OpenStatuses, startOfDay(s.now()))
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to fetch overdue orders").Wrap(err)
+	}
+	s.flagOverdue(orders)
+	return orders, nil
+}
+
+// ExpireStaleOrders expires orders that have waited for review longer than maxAge and tells their customers
+// An order reviewed while the job runs keeps its review, because only orders still submitted are expired
+func (s *DefaultOrderService) ExpireStaleOrders(maxAge time.Duration) (int, error) {
+	cutoff := time.Now().Add(-maxAge)
+	days := int(maxAge.Hours() / 24)
+	text := fmt.Sprintf("Your order was not reviewed within %d days and has expired. You're welcome to submit it again.", days)
+
+	expired := 0
+	for {
+		stale, err := s.orders.ListCreatedBefore(StatusSubmitted, cutoff, staleOrderBatchSize)
+		if err != nil {
+			return expired, apierror.Internal("DATABASE_ERROR", "Failed to load stale orders").Wrap(err)
+		}
+
+		for _, order := range stale {
+			notice := &models.Message{OrderID: order.ID, Text: text}
+
+			// With an outbox the events are saved in the transaction that expires the order
+			var announce repositories.TransitionHook
+			if s.outbox != nil {
+				announce = func(orders repositories.OrderRepository, outbox repositories.OutboxRepository) error {
+					return s.saveExpiryEvents(orders, outbox, order.ID, notice)
+				}
+			}
+			moved, err := s.orders.Transition(order.ID, StatusSubmitted, StatusExpired, notice, announce)
+			if err != nil {
+				return expired, apierror.Internal("DATABASE_ERROR", "Failed to expire order").Wrap(err)
+			}
+			if !moved {
+				continue
+			}
+			expired++
+
+			if s.outbox == nil {
+				if updated, err := s.reload(order.ID); err == nil {
+					PublishEvent(OrderStatusChanged{Order: updated, PreviousStatus: StatusSubmitted})
+				}
+				PublishEvent(MessageSent{Message: notice})
+			}
+		}
+
+		// Every listed order has left the submitted status, so the next batch lists new ones
+		if len(stale) < staleOrderBatchSize {
+			return expired, nil
+		}
+	}
+}
+
+// saveExpiryEvents writes the events announcing an expired order and its notice to the outbox
+func (s *DefaultOrderService) saveExpiryEvents(orders repositories.OrderRepository, outbox repositories.OutboxRepository, orderID uint, notice *models.Message) error {
+	updated, err := reloadOrder(orders, orderID)
+	if err != nil {
+		return err
+	}
+	if err := saveEvent(outbox, OrderStatusChanged{Order: updated, PreviousStatus: StatusSubmitted}, s.now()); err != nil {
+		return err
+	}
+	return saveEvent(outbox, MessageSent{Message: notice}, s.now())
+}
+
+// StaleOrderExpiryJob returns the background job that expires orders nobody reviewed within maxAge
+func StaleOrderExpiryJob(service OrderService, maxAge time.Duration) jobs.Job {
+	return jobs.Job{
+		Name:     "stale_order_expiry",
+		Interval: StaleOrderCheckInterval,
+		Run: func(ctx context.Context) error {
+			expired, err := service.ExpireStaleOrders(maxAge)
+			if expired > 0 {
+				log.Printf("Expired %d stale orders", expired)
+			}
+			return err
+		},
+	}
+}
+
+// Reorder creates a new submitted order from a delivered one
+// The new order keeps the description and image and links back to the original
+func (s *DefaultOrderService) Reorder(customer *models.User, orderID string, quantity int) (*models.Order, error) {
+	if customer.Role != RoleCustomer {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only customers can reorder")
+	}
+	if err := requireTermsAccepted(s.consents, customer); err != nil {
+		return nil, err
+	}
+
+	originalOrder, err := s.find(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify that the user owns this order (only the customer can reorder their own orders)
+	if originalOrder.CustomerID != customer.ID {
+		return nil, apierror.Forbidden("FORBIDDEN", "You can only reorder your own orders")
+	}
+
+	// Verify that the order is in a completed state (delivered)
+	if originalOrder.Status != StatusDelivered {
+		return nil, apierror.Unprocessable("INVALID_ORDER_STATE", "Only completed (delivered) orders can be reordered")
+	}
+
+	return s.copyOrder(originalOrder, quantity, "Failed to create reorder")
+}
+
+// DuplicateOrder creates a new submitted order from any of the customer's orders, whatever its status
+// The copy keeps the description, quantity, and image, and links back to the original like a reorder
+func (s *DefaultOrderService) DuplicateOrder(customer *models.User, orderID string) (*models.Order, error) {
+	if customer.Role != RoleCustomer {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only customers can duplicate orders")
+	}
+	if err := requireTermsAccepted(s.consents, customer); err != nil {
+		return nil, err
+	}
+
+	originalOrder, err := s.find(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if originalOrder.CustomerID != customer.ID {
+		return nil, apierror.Forbidden("FORBIDDEN", "You can only duplicate your own orders")
+	}
+
+	return s.copyOrder(originalOrder, originalOrder.Quantity, "Failed to duplicate order")
+}
+
+// copyOrder creates a submitted copy of the order for its customer, linked back to it
+func (s *DefaultOrderService) copyOrder(originalOrder *models.Order, quantity int, failure string) (*models.Order, error) {
+	newOrder := &models.Order{
+		Description:     originalOrder.Description,
+		Quantity:        quantity,
+		Status:          StatusSubmitted,
+		ImageS3Key:      originalOrder.ImageS3Key, // Copy the S3 key (same image)
+		CustomerID:      originalOrder.CustomerID,
+		OriginalOrderID: &originalOrder.ID, // Link to original order
+		Currency:        studioCurrency(),  // the copy is quoted afresh at today's currency
+
+		// Ship to the same place unless the customer chooses another address before acceptance
+		ShippingAddressID: originalOrder.ShippingAddressID,
+		ShippingAddress:   originalOrder.ShippingAddress,
+	}
+
+	if err := s.orders.Create(newOrder); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", failure).Wrap(err)
+	}
+
+	created, err := s.reload(newOrder.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.publish(OrderCreated{Order: created}); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// SetShippingAddress chooses where the customer's submitted order ships, from their address book
+// The address can change until the order is reviewed; the order keeps a copy of it either way
+func (s *DefaultOrderService) SetShippingAddress(customer *models.User, orderID string, addressID uint) (*models.Order, error) {
+	if customer.Role != RoleCustomer {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only customers can choose a shipping address")
+	}
+
+	order, err := s.find(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.CustomerID != customer.ID {
+		return nil, apierror.Forbidden("FORBIDDEN", "You can only choose the shipping address of your own orders")
+	}
+	if order.Status != StatusSubmitted {
+		return nil, apierror.Unprocessable("INVALID_STATE", "The shipping address can only be changed before the order is reviewed")
+	}
+
+	if err := s.applyShippingAddress(order, customer, addressID); err != nil {
+		return nil, err
+	}
+	if err := s.save(order, "Failed to update order"); err != nil {
+		return nil, err
+	}
+	return s.reload(order.ID)
+}
+
+// AssignOrder assigns an unassigned order to the technician
+func (s *DefaultOrderService) AssignOrder(technician *models.User, orderID string) (*models.Order, error) {
+	if technician.Role != RoleTechnician {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only technicians can assign orders")
+	}
+
+	order, err := s.find(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if IsAssignedTo(order, technician) {
+		return nil, apierror.Unprocessable("ALREADY_ASSIGNED", "Order is already assigned to you")
+	}
+	if order.TechnicianID != nil {
+		return nil, apierror.Unprocessable("ALREADY_ASSIGNED", "Order is already assigned to another technician")
+	}
+	if err := checkTechnicianCapacity(s.settings, technician); err != nil {
+		return nil, err
+	}
+
+	order.TechnicianID = &technician.ID
+
+	if err := s.save(order, "Failed to assign order"); err != nil {
+		return nil, err
+	}
+
+	return s.reload(order.ID)
+}
+
+// attachChecklist copies the technician's checklist template onto the order
+// Orders that already have a checklist keep it
+func (s *DefaultOrderService) attachChecklist(order *models.Order, technician *models.User) error {
+	existing, err := s.checklists.ListForOrder(order.ID)
+	if err != nil {
+		return apierror.Internal("DATABASE_ERROR", "Failed to load order checklist").Wrap(err)
+	}
+	if len(existing) > 0 {
+		return nil
+	}
+
+	template, err := s.checklists.ListTemplate(technician.ID)
+	if err != nil {
+		return apierror.Internal("DATABASE_ERROR", "Failed to load checklist template").Wrap(err)
+	}
+
+	items := make([]models.OrderChecklistItem, len(template))
+	for i, step := range template {
+		items[i] = models.OrderChecklistItem{OrderID: order.ID, Label: step.Label, Position: step.Position}
+	}
+	if err := s.checklists.CreateForOrder(items); err != nil {
+		return apierror.Internal("DATABASE_ERROR", "Failed to create order checklist").Wrap(err)
+	}
+	return nil
+}
+
+// requireChecklistComplete rejects shipping an order with unchecked checklist items
+func (s *DefaultOrderService) requireChecklistComplete(order *models.Order) error {
+	items, err := s.checklists.ListForOrder(order.ID)
+	if err != nil {
+		return apierror.Internal("DATABASE_ERROR", "Failed to load order checklist").Wrap(err)
+	}
+
+	var incomplete []string
+	for _, item := range items {
+		if !item.Completed {
+			incomplete = append(incomplete, item.Label)
+		}
+	}
+	if len(incomplete) > 0 {
+		return apierror.Unprocessable("CHECKLIST_INCOMPLETE", "All checklist items must be completed before shipping").WithDetails(map[string]interface{}{
+			"incomplete_items": incomplete,
+		})
+	}
+	return nil
+}
+
+// quote itemizes an accepted order: the base set, any catalog add-ons, a price list adjustment, and a rush fee
+// Add-on names and prices are copied onto the line items; all arithmetic is in whole cents and so is the total
+// The technician's price list in force at acceptance adjusts the base set and add-ons, not the rush fee
+func (s *DefaultOrderService) quote(order *models.Order, technician *models.User, acceptedAt time.Time, baseCents int64, input ReviewOrderInput) ([]models.OrderLineItem, int64, error) {
+	lineItems := []models.OrderLineItem{newLineItem(models.LineItemBase, nil, "Base set", 1, baseCents)}
+
+	for _, selection := range input.AddOns {
+		quantity := selection.Quantity
+		if quantity == 0 {
+			quantity = 1
+		}
+		if quantity < 0 {
+			return nil, 0, apierror.Validation("Add-on quantity must be greater than zero", nil)
+		}
+
+		addOn, err := s.addOns.FindByID(selection.AddOnID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, 0, apierror.Unprocessable("INVALID_ADD_ON", "Add-on not found").WithDetails(map[string]interface{}{
+					"add_on_id": selection.AddOnID,
+				})
+			}
+			return nil, 0, apierror.Internal("DATABASE_ERROR", "Failed to load add-on").Wrap(err)
+		}
+		if addOn.Currency != order.Currency {
+			return nil, 0, apierror.Unprocessable("CURRENCY_MISMATCH", "Add-on is priced in a different currency than the order").WithDetails(map[string]interface{}{
+				"add_on_id":      addOn.ID,
+				"currency":       addOn.Currency,
+				"order_currency": order.Currency,
+			})
+		}
+		lineItems = append(lineItems, newLineItem(models.LineItemAddOn, &addOn.ID, addOn.Name, quantity, utils.ToCents(addOn.Price)))
+	}
+
+	adjustment, err := s.priceAdjustment(order, technician, acceptedAt, lineItems)
+	if err != nil {
+		return nil, 0, err
+	}
+	if adjustment != nil {
+		lineItems = append(lineItems, *adjustment)
+	}
+
+	rushFee := input.RushFee
+	if rushFee != nil && *rushFee <= 0 {
+		return nil, 0, apierror.Validation("Rush fee must be greater than zero", nil)
+	}
+	if rushFee != nil {
+		if err := requireWholeCents("Rush fee", *rushFee); err != nil {
+			return nil, 0, err
+		}
+	}
+	description := "Rush fee"
+	var rushCents int64
+	if rushFee != nil {
+		rushCents = utils.ToCents(*rushFee)
+	} else if order.Rush {
+		rushCents = utils.ToCents(rushSurcharge())
+		if percent, ok := rushSurchargePercent(); ok {
+			rushCents = utils.PercentOfCents(sumCents(lineItems), percent)
+			description = fmt.Sprintf("Rush fee (%g%%)", percent)
+		}
+	}
+	if rushCents > 0 {
+		lineItems = append(lineItems, newLineItem(models.LineItemRushFee, nil, description, 1, rushCents))
+	}
+
+	return lineItems, sumCents(lineItems), nil
+}
+
+// rushSurcharge returns the configured rush fee, or the default when no configuration is loaded
+func rushSurcharge() float64 {
+	if cfg := config.GetConfig(); cfg != nil {
+		return cfg.GetRushSurcharge()
+	}
+	return config.DefaultRushSurcharge
+}
+
+// studioCurrency returns the configured currency of new prices, or the default when no configuration is loaded
+func studioCurrency() string {
+	if cfg := config.GetConfig(); cfg != nil {
+		return cfg.GetCurrency()
+	}
+	return utils.DefaultCurrency
+}
+
+// rushSurchargePercent returns the configured percentage rush fee, if one is configured
+func rushSurchargePercent() (float64, bool) {
+	if cfg := config.GetConfig(); cfg != nil {
+		return cfg.GetRushSurchargePercent()
+	}
+	return 0, false
+}
+
+// priceAdjustment returns the line item of the technician's price list in force at acceptance, or nil when none is
+// The order is linked to the price list even when the adjustment rounds to nothing
+func (s *DefaultOrderService) priceAdjustment(order *models.Order, technician *models.User, acceptedAt time.Time, lineItems []models.OrderLineItem) (*models.OrderLineItem, error) {
+	order.PriceListID = nil
+	if s.priceLists == nil {
+		return nil, nil
+	}
+
+	priceList, err := s.priceLists.FindInForce(technician.ID, acceptedAt.UTC())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load price list").Wrap(err)
+	}
+	order.PriceListID = &priceList.ID
+
+	amount := utils.PercentOfCents(sumCents(lineItems), priceList.AdjustmentPercent)
+	if amount == 0 {
+		return nil, nil
+	}
+	description := fmt.Sprintf("%s (%+g%%)", priceList.Name, priceList.AdjustmentPercent)
+	item := newLineItem(models.LineItemPriceAdjustment, nil, description, 1, amount)
+	return &item, nil
+}
+
+// newLineItem builds a line item whose amount is the unit price in cents times the quantity
+func newLineItem(kind string, addOnID *uint, description string, quantity int, unitCents int64) models.OrderLineItem {
+	return models.OrderLineItem{
+		Kind:        kind,
+		AddOnID:     addOnID,
+		Description: description,
+		Quantity:    quantity,
+		UnitPrice:   utils.FromCents(unitCents),
+		Amount:      utils.FromCents(unitCents * int64(quantity)),
+	}
+}
+
+// sumCents totals line item amounts in whole cents so the sum carries no float drift
+func sumCents(lineItems []models.OrderLineItem) int64 {
+	var cents int64
+	for _, item := range lineItems {
+		cents += utils.ToCents(item.Amount)
+	}
+	return cents
+}
+
+// roundCents rounds a figure to two decimal places
+func roundCents(amount float64) float64 {
+	return utils.FromCents(utils.ToCents(amount))
+}
+
+// requireWholeCents rejects an amount with fractions of a cent, which cannot be stored
+func requireWholeCents(name string, amount float64) error {
+	if !utils.IsWholeCents(amount) {
+		return apierror.Validation(name+" cannot include fractions of a cent", nil)
+	}
+	return nil
+}
+
+// orderDecision returns the audited fields a review sets on an order
+func orderDecision(order *models.Order) map[string]interface{} {
+	return map[string]interface{}{
+		"status":        order.Status,
+		"price":         order.Price,
+		"feedback":      order.Feedback,
+		"technician_id": order.TechnicianID,
+	}
+}
+
+// find loads an order by its path parameter without relationships
+func (s *DefaultOrderService) find(orderID string) (*models.Order, error) {
+	return findOrder(s.orders, orderID)
+}
+
+// reload fetches an order with relationships for a complete response
+func (s *DefaultOrderService) reload(id uint) (*models.Order, error) {
+	order, err := reloadOrder(s.orders, id)
+	if err != nil {
+		return nil, err
+	}
+	order.Overdue = IsOverdue(order, s.now())
+	return order, nil
+}
+
+// save writes an order the service loaded and changed
+// Someone else saving the order in the meantime is reported as a conflict rather than overwritten
+func (s *DefaultOrderService) save(order *models.Order, failure string) error {
+	err := s.orders.Save(order)
+	if errors.Is(err, repositories.ErrOrderChanged) {
+		return errOrderChanged()
+	}
+	if err != nil {
+		return apierror.Internal("DATABASE_ERROR", failure).Wrap(err)
+	}
+	return nil
+}
+
+// checkVersion rejects a change made from a copy of the order older than the current one; version 0 skips the check
+func checkVersion(order *models.Order, version uint) error {
+	if version != 0 && version != order.Version {
+		return errOrderChanged()
+	}
+	return nil
+}
+
+// errOrderChanged reports that the order changed since it was loaded
+func errOrderChanged() error {
+	return apierror.Conflict("ORDER_CHANGED", "The order was changed by someone else; reload it and try again")
+}
+
+// flagOverdue sets the overdue flag of each order
+func (s *DefaultOrderService) flagOverdue(orders []models.Order) {
+	now := s.now()
+	for i := range orders {
+		orders[i].Overdue = IsOverdue(&orders[i], now)
+	}
+}
+
+// validateDueDate checks that an optional requested or promised date is between today and MaxDueDateHorizon from now
+func (s *DefaultOrderService) validateDueDate(field string, date *time.Time) error {
+	if date == nil {
+		return nil
+	}
+	now := s.now()
+	if date.Before(startOfDay(now)) {
+		return apierror.Validation("Invalid due date", map[string]string{field: "must not be in the past"})
+	}
+	if date.After(now.Add(MaxDueDateHorizon)) {
+		return apierror.Validation("Invalid due date", map[string]string{field: "must be within a year"})
+	}
+	return nil
+}
+
+// findOrder loads an order by its path parameter without relationships
+func findOrder(orders repositories.OrderRepository, orderID string) (*models.Order, error) {
+	id, err := parseOrderID(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := orders.FindByID(id)
+	if err != nil {
+		return nil, orderLookupError(err)
+	}
+	return order, nil
+}
+
+// reloadOrder fetches an order with relationships for a complete response
+func reloadOrder(orders repositories.OrderRepository, id uint) (*models.Order, error) {
+	order, err := orders.FindByIDWithRelations(id)
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load order details").Wrap(err)
+	}
+	return order, nil
+}
+
+// parseOrderID converts an order ID path parameter into a numeric ID
+// IDs that are empty or not numeric cannot match any order
+func parseOrderID(orderID string) (uint, error) {
+	if orderID == "" {
+		return 0, apierror.BadRequest("INVALID_REQUEST", "Order ID is required")
+	}
+	id, err := strconv.ParseUint(orderID, 10, 64)
+	if err != nil || id == 0 {
+		return 0, apierror.NotFound("ORDER_NOT_FOUND", "Order not found")
+	}
+	return uint(id), nil
+}
+
+// orderLookupError maps a repository lookup failure to a service error
+func orderLookupError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apierror.NotFound("ORDER_NOT_FOUND", "Order not found")
+	}
+	return apierror.Internal("DATABASE_ERROR", "Failed to load order").Wrap(err)
+}
@@ -0,0 +1,880 @@
+package services
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// fakeOrderRepository is an in-memory OrderRepository for exercising business rules
+type fakeOrderRepository struct {
+	orders  map[uint]*models.Order
+	nextID  uint
+	notices []*models.Message
+	outbox  repositories.OutboxRepository // handed to transition hooks
+}
+
+func newFakeOrderRepository(orders ...models.Order) *fakeOrderRepository {
+	repo := &fakeOrderRepository{orders: make(map[uint]*models.Order), nextID: 1}
+	for i := range orders {
+		order := orders[i]
+		if order.ID >= repo.nextID {
+			repo.nextID = order.ID + 1
+		}
+		repo.orders[order.ID] = &order
+	}
+	return repo
+}
+
+func (r *fakeOrderRepository) Create(order *models.Order) error {
+	order.ID = r.nextID
+	r.nextID++
+	stored := *order
+	r.orders[order.ID] = &stored
+	return nil
+}
+
+func (r *fakeOrderRepository) Save(order *models.Order) error {
+	stored := *order
+	r.orders[order.ID] = &stored
+	return nil
+}
+
+func (r *fakeOrderRepository) FindByID(id uint) (*models.Order, error) {
+	order, ok := r.orders[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	found := *order
+	return &found, nil
+}
+
+func (r *fakeOrderRepository) FindByIDWithRelations(id uint) (*models.Order, error) {
+	return r.FindByID(id)
+}
+
+func (r *fakeOrderRepository) List(query repositories.OrderListQuery) ([]models.Order, int64, error) {
+	var orders []models.Order
+	for _, order := range r.orders {
+		if query.CustomerID != nil && order.CustomerID != *query.CustomerID {
+			continue
+		}
+		if query.CustomerOrRecipientID != nil && order.CustomerID != *query.CustomerOrRecipientID &&
+			(order.RecipientID == nil || *order.RecipientID != *query.CustomerOrRecipientID) {
+			continue
+		}
+		if query.AssignedOrUnassignedToTechID != nil && order.TechnicianID != nil && *order.TechnicianID != *query.AssignedOrUnassignedToTechID {
+			continue
+		}
+		orders = append(orders, *order)
+	}
+	return orders, int64(len(orders)), nil
+}
+
+func (r *fakeOrderRepository) ListCreatedBefore(status string, cutoff time.Time, limit int) ([]models.Order, error) {
+	var orders []models.Order
+	for _, order := range r.orders {
+		if order.Status == status && order.CreatedAt.Before(cutoff) && len(orders) < limit {
+			orders = append(orders, *order)
+		}
+	}
+	return orders, nil
+}
+
+func (r *fakeOrderRepository) ListCopiesOf(orderIDs []uint) ([]models.Order, error) {
+	var orders []models.Order
+	for id := uint(1); id < r.nextID; id++ {
+		order, ok := r.orders[id]
+		if !ok || order.OriginalOrderID == nil {
+			continue
+		}
+		for _, originalID := range orderIDs {
+			if *order.OriginalOrderID == originalID {
+				orders = append(orders, *order)
+			}
+		}
+	}
+	return orders, nil
+}
+
+func (r *fakeOrderRepository) ListDueBefore(technicianID uint, statuses []string, cutoff time.Time) ([]models.Order, error) {
+	var orders []models.Order
+	for _, order := range r.orders {
+		due := order.DueBy()
+		if order.TechnicianID == nil || *order.TechnicianID != technicianID || due == nil || !due.Before(cutoff) {
+			continue
+		}
+		for _, status := range statuses {
+			if order.Status == status {
+				orders = append(orders, *order)
+			}
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].DueBy().Before(*orders[j].DueBy()) })
+	return orders, nil
+}
+
+func (r *fakeOrderRepository) ListDeliveredBetween(technicianID uint, deliveredStatus string, from, to time.Time) ([]models.Order, error) {
+	var orders []models.Order
+	for _, order := range r.orders {
+		deliveredAt := order.DeliveredTime()
+		if order.TechnicianID != nil && *order.TechnicianID == technicianID && order.Status == deliveredStatus &&
+			!deliveredAt.Before(from) && deliveredAt.Before(to) {
+			orders = append(orders, *order)
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].DeliveredTime().After(orders[j].DeliveredTime()) })
+	return orders, nil
+}
+
+func (r *fakeOrderRepository) ListAssignedTo(technicianID uint, statuses []string) ([]models.Order, error) {
+	var orders []models.Order
+	for _, order := range r.orders {
+		if order.TechnicianID == nil || *order.TechnicianID != technicianID {
+			continue
+		}
+		for _, status := range statuses {
+			if order.Status == status {
+				orders = append(orders, *order)
+			}
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].ID > orders[j].ID })
+	return orders, nil
+}
+
+func (r *fakeOrderRepository) Transition(orderID uint, from, to string, notice *models.Message, then repositories.TransitionHook) (bool, error) {
+	order, ok := r.orders[orderID]
+	if !ok || order.Status != from {
+		return false, nil
+	}
+	order.Status = to
+	r.notices = append(r.notices, notice)
+	if then != nil {
+		if err := then(r, r.outbox); err != nil {
+			order.Status = from
+			r.notices = r.notices[:len(r.notices)-1]
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// recordingOrderRepository remembers the last list query it received
+type recordingOrderRepository struct {
+	*fakeOrderRepository
+	lastQuery repositories.OrderListQuery
+}
+
+func (r *recordingOrderRepository) List(query repositories.OrderListQuery) ([]models.Order, int64, error) {
+	r.lastQuery = query
+	return r.fakeOrderRepository.List(query)
+}
+
+// newTestOrderService creates an order service over the fake repositories
+func newTestOrderService(orders *fakeOrderRepository, addOns ...models.AddOn) *DefaultOrderService {
+	return NewOrderService(orders, newFakeAddOnRepository(addOns...), newFakeChecklistRepository(), nil, nil, nil, nil, nil)
+}
+
+func uintPtr(v uint) *uint {
+	return &v
+}
+
+func uintString(v uint) string {
+	return strconv.FormatUint(uint64(v), 10)
+}
+
+func float64Ptr(v float64) *float64 {
+	return &v
+}
+
+func assertAPIError(t *testing.T, err error, status int, code string) {
+	t.Helper()
+	apiErr, ok := err.(*apierror.Error)
+	if assert.True(t, ok, "expected *apierror.Error, got %T", err) {
+		assert.Equal(t, status, apiErr.Status)
+		assert.Equal(t, code, apiErr.Code)
+	}
+}
+
+var (
+	testCustomer   = &models.User{ID: 1, Role: RoleCustomer}
+	otherCustomer  = &models.User{ID: 2, Role: RoleCustomer}
+	testTechnician = &models.User{ID: 3, Role: RoleTechnician}
+	otherTech      = &models.User{ID: 4, Role: RoleTechnician}
+
+	// testAddress is the shipping address of submitted orders that are ready to be accepted
+	testAddress = &models.AddressSnapshot{Recipient: "Casey Customer", Line1: "1 Main St", City: "Springfield", PostalCode: "12345", Country: "US"}
+)
+
+func TestOrderService_CreateOrder(t *testing.T) {
+	service := newTestOrderService(newFakeOrderRepository())
+
+	order, err := service.CreateOrder(testCustomer, CreateOrderInput{Description: "Pink", Quantity: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSubmitted, order.Status)
+	assert.Equal(t, testCustomer.ID, order.CustomerID)
+	assert.Equal(t, "USD", order.Currency)
+
+	_, err = service.CreateOrder(testTechnician, CreateOrderInput{Description: "Pink", Quantity: 2})
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+}
+
+func TestOrderService_CreateOrder_FromCatalog(t *testing.T) {
+	service := newTestOrderService(newFakeOrderRepository())
+	service.catalog = newFakeCatalogRepository(models.CatalogDesign{ID: 5, Name: "French", Description: "Classic French tips", BasePrice: 40, Sizes: []string{"M"}})
+	service.addresses = newFakeAddressRepository(models.Address{ID: 7, UserID: testCustomer.ID, Recipient: "Casey", Line1: "1 Main St", City: "Springfield", PostalCode: "12345", Country: "US"})
+
+	// The design pre-fills the description and the price
+	order, err := service.CreateOrder(testCustomer, CreateOrderInput{Quantity: 1, CatalogDesignID: uintPtr(5)})
+	assert.NoError(t, err)
+	assert.Equal(t, "Classic French tips", order.Description)
+	assert.Equal(t, uint(5), *order.CatalogDesignID)
+	assert.Equal(t, 40.0, *order.Price)
+
+	// A description of the customer's own is kept
+	order, err = service.CreateOrder(testCustomer, CreateOrderInput{Description: "French, but pink", Quantity: 1, CatalogDesignID: uintPtr(5), ShippingAddressID: uintPtr(7)})
+	assert.NoError(t, err)
+	assert.Equal(t, "French, but pink", order.Description)
+
+	_, err = service.CreateOrder(testCustomer, CreateOrderInput{Quantity: 1, CatalogDesignID: uintPtr(99)})
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "INVALID_CATALOG_DESIGN")
+
+	_, err = service.CreateOrder(testCustomer, CreateOrderInput{Quantity: 1})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+
+	// Accepting without a price quotes the design's base price
+	accepted, err := service.ReviewOrder(testTechnician, uintString(order.ID), ReviewOrderInput{Action: "accept"})
+	assert.NoError(t, err)
+	assert.Equal(t, 40.0, *accepted.Price)
+}
+
+func TestOrderService_CreateOrders(t *testing.T) {
+	orders := newFakeOrderRepository()
+	service := newTestOrderService(orders)
+	service.catalog = newFakeCatalogRepository(models.CatalogDesign{ID: 5, Name: "French", Description: "Classic French tips", BasePrice: 40, Sizes: []string{"M"}})
+
+	created, err := service.CreateOrders(testCustomer, []CreateOrderInput{
+		{Description: "Bride", Quantity: 1},
+		{Quantity: 4, CatalogDesignID: uintPtr(5)},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, created, 2)
+	assert.Equal(t, "Bride", created[0].Description)
+	assert.Equal(t, "Classic French tips", created[1].Description)
+	assert.Equal(t, StatusSubmitted, created[1].Status)
+	assert.Len(t, orders.orders, 2)
+
+	// One invalid order fails the batch before anything is written, naming its index
+	_, err = service.CreateOrders(testCustomer, []CreateOrderInput{
+		{Description: "Maid of honor", Quantity: 1},
+		{Quantity: 1, CatalogDesignID: uintPtr(99)},
+	})
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "INVALID_CATALOG_DESIGN")
+	assert.Contains(t, err.Error(), "Order 2")
+	assert.Equal(t, 1, apierror.As(err).Details.(map[string]interface{})["index"])
+	assert.Len(t, orders.orders, 2)
+
+	_, err = service.CreateOrders(testCustomer, nil)
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+
+	_, err = service.CreateOrders(testTechnician, []CreateOrderInput{{Description: "Pink", Quantity: 1}})
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+}
+
+func TestOrderService_GetOrder_Authorization(t *testing.T) {
+	service := newTestOrderService(newFakeOrderRepository(
+		models.Order{ID: 1, CustomerID: testCustomer.ID, Status: StatusSubmitted, ShippingAddress: testAddress},
+		models.Order{ID: 2, CustomerID: testCustomer.ID, Status: StatusAccepted, TechnicianID: uintPtr(otherTech.ID)},
+	))
+
+	tests := []struct {
+		name    string
+		user    *models.User
+		orderID string
+		status  int
+	}{
+		{"customer sees own order", testCustomer, "1", 0},
+		{"other customer is forbidden", otherCustomer, "1", http.StatusForbidden},
+		{"technician sees unassigned order", testTechnician, "1", 0},
+		{"technician cannot see order assigned to another", testTechnician, "2", http.StatusForbidden},
+		{"missing order", testCustomer, "99", http.StatusNotFound},
+		{"non-numeric id", testCustomer, "abc", http.StatusNotFound},
+		{"empty id", testCustomer, "", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order, err := service.GetOrder(tt.user, tt.orderID)
+			if tt.status == 0 {
+				assert.NoError(t, err)
+				assert.NotNil(t, order)
+				return
+			}
+			assert.True(t, apierror.HasStatus(err, tt.status))
+		})
+	}
+}
+
+func TestOrderService_ReviewOrder(t *testing.T) {
+	repo := newFakeOrderRepository(
+		models.Order{ID: 1, CustomerID: testCustomer.ID, Status: StatusSubmitted, ShippingAddress: testAddress},
+		models.Order{ID: 2, CustomerID: testCustomer.ID, Status: StatusAccepted},
+	)
+	service := newTestOrderService(repo)
+
+	_, err := service.ReviewOrder(testCustomer, "1", ReviewOrderInput{Action: "accept", Price: float64Ptr(10)})
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+
+	_, err = service.ReviewOrder(testTechnician, "1", ReviewOrderInput{Action: "accept"})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+
+	_, err = service.ReviewOrder(testTechnician, "1", ReviewOrderInput{Action: "reject", Feedback: new(string)})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+
+	_, err = service.ReviewOrder(testTechnician, "2", ReviewOrderInput{Action: "accept", Price: float64Ptr(10)})
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "INVALID_STATE")
+
+	order, err := service.ReviewOrder(testTechnician, "1", ReviewOrderInput{Action: "accept", Price: float64Ptr(45)})
+	assert.NoError(t, err)
+	assert.Equal(t, StatusAccepted, order.Status)
+	assert.Equal(t, 45.0, *order.Price)
+	assert.Equal(t, testTechnician.ID, *order.TechnicianID)
+	if assert.Len(t, order.LineItems, 1) {
+		assert.Equal(t, models.LineItemBase, order.LineItems[0].Kind)
+		assert.Equal(t, 45.0, order.LineItems[0].Amount)
+	}
+}
+
+func TestOrderService_ReviewOrder_LineItems(t *testing.T) {
+	repo := newFakeOrderRepository(
+		models.Order{ID: 1, CustomerID: testCustomer.ID, Status: StatusSubmitted, ShippingAddress: testAddress},
+		models.Order{ID: 2, CustomerID: testCustomer.ID, Status: StatusSubmitted, ShippingAddress: testAddress},
+		models.Order{ID: 3, CustomerID: testCustomer.ID, Status: StatusSubmitted, ShippingAddress: testAddress},
+	)
+	service := newTestOrderService(repo,
+		models.AddOn{ID: 1, Name: "Gold charm", Price: 2.5},
+		models.AddOn{ID: 2, Name: "Chrome finish", Price: 8.1},
+		models.AddOn{ID: 3, Name: "Crystal", Price: 4, Currency: "EUR"},
+	)
+
+	order, err := service.ReviewOrder(testTechnician, "1", ReviewOrderInput{
+		Action:  "accept",
+		Price:   float64Ptr(40),
+		AddOns:  []AddOnSelection{{AddOnID: 1, Quantity: 3}, {AddOnID: 2}},
+		RushFee: float64Ptr(15),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 70.6, *order.Price)
+	if assert.Len(t, order.LineItems, 4) {
+		assert.Equal(t, models.LineItemAddOn, order.LineItems[1].Kind)
+		assert.Equal(t, "Gold charm", order.LineItems[1].Description)
+		assert.Equal(t, uintPtr(1), order.LineItems[1].AddOnID)
+		assert.Equal(t, 7.5, order.LineItems[1].Amount)
+		assert.Equal(t, 1, order.LineItems[2].Quantity)
+		assert.Equal(t, models.LineItemRushFee, order.LineItems[3].Kind)
+	}
+
+	_, err = service.ReviewOrder(testTechnician, "2", ReviewOrderInput{
+		Action: "accept",
+		Price:  float64Ptr(40),
+		AddOns: []AddOnSelection{{AddOnID: 99}},
+	})
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "INVALID_ADD_ON")
+
+	// Add-ons priced in another currency cannot be added to the quote
+	_, err = service.ReviewOrder(testTechnician, "2", ReviewOrderInput{
+		Action: "accept",
+		Price:  float64Ptr(40),
+		AddOns: []AddOnSelection{{AddOnID: 3}},
+	})
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "CURRENCY_MISMATCH")
+
+	_, err = service.ReviewOrder(testTechnician, "2", ReviewOrderInput{
+		Action: "accept",
+		Price:  float64Ptr(40.125),
+	})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+
+	_, err = service.ReviewOrder(testTechnician, "3", ReviewOrderInput{
+		Action:  "accept",
+		Price:   float64Ptr(40),
+		RushFee: float64Ptr(0),
+	})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+}
+
+func TestOrderService_ReviewOrder_RushSurcharge(t *testing.T) {
+	config.SetConfig(&config.Config{RushSurcharge: "20"})
+	defer config.SetConfig(nil)
+
+	repo := newFakeOrderRepository(
+		models.Order{ID: 1, CustomerID: testCustomer.ID, Status: StatusSubmitted, Rush: true, ShippingAddress: testAddress},
+		models.Order{ID: 2, CustomerID: testCustomer.ID, Status: StatusSubmitted, Rush: true, ShippingAddress: testAddress},
+		models.Order{ID: 3, CustomerID: testCustomer.ID, Status: StatusSubmitted, ShippingAddress: testAddress},
+	)
+	service := newTestOrderService(repo)
+
+	// Rush orders pick up the configured surcharge
+	order, err := service.ReviewOrder(testTechnician, "1", ReviewOrderInput{Action: "accept", Price: float64Ptr(40)})
+	assert.NoError(t, err)
+	assert.Equal(t, 60.0, *order.Price)
+	if assert.Len(t, order.LineItems, 2) {
+		assert.Equal(t, models.LineItemRushFee, order.LineItems[1].Kind)
+	}
+
+	// The technician can override the surcharge
+	order, err = service.ReviewOrder(testTechnician, "2", ReviewOrderInput{Action: "accept", Price: float64Ptr(40), RushFee: float64Ptr(5)})
+	assert.NoError(t, err)
+	assert.Equal(t, 45.0, *order.Price)
+
+	// Regular orders have no rush fee
+	order, err = service.ReviewOrder(testTechnician, "3", ReviewOrderInput{Action: "accept", Price: float64Ptr(40)})
+	assert.NoError(t, err)
+	assert.Equal(t, 40.0, *order.Price)
+	assert.Len(t, order.LineItems, 1)
+}
+
+func TestOrderService_ReviewOrder_RushSurchargePercent(t *testing.T) {
+	config.SetConfig(&config.Config{RushSurcharge: "20", RushSurchargePct: "25"})
+	defer config.SetConfig(nil)
+
+	repo := newFakeOrderRepository(
+		models.Order{ID: 1, CustomerID: testCustomer.ID, Status: StatusSubmitted, Rush: true, ShippingAddress: testAddress},
+		models.Order{ID: 2, CustomerID: testCustomer.ID, Status: StatusSubmitted, Rush: true, ShippingAddress: testAddress},
+	)
+	service := newTestOrderService(repo, models.AddOn{ID: 1, Name: "Charm", Price: 2.5})
+
+	// The percentage applies to the base set and add-ons and replaces the flat surcharge
+	order, err := service.ReviewOrder(testTechnician, "1", ReviewOrderInput{
+		Action: "accept",
+		Price:  float64Ptr(35),
+		AddOns: []AddOnSelection{{AddOnID: 1, Quantity: 2}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 50.0, *order.Price)
+	if assert.Len(t, order.LineItems, 3) {
+		assert.Equal(t, models.LineItemRushFee, order.LineItems[2].Kind)
+		assert.Equal(t, "Rush fee (25%)", order.LineItems[2].Description)
+		assert.Equal(t, 10.0, order.LineItems[2].Amount)
+	}
+
+	// An explicit rush fee still overrides it
+	order, err = service.ReviewOrder(testTechnician, "2", ReviewOrderInput{Action: "accept", Price: float64Ptr(40), RushFee: float64Ptr(5)})
+	assert.NoError(t, err)
+	assert.Equal(t, 45.0, *order.Price)
+}
+
+func TestOrderService_ListOrders_RushFirstForTechnicians(t *testing.T) {
+	repo := &recordingOrderRepository{fakeOrderRepository: newFakeOrderRepository()}
+	service := newTestOrderService(repo.fakeOrderRepository)
+	service.orders = repo
+
+	_, _, err := service.ListOrders(testTechnician, ListOrdersOptions{Page: 1, Limit: 10})
+	assert.NoError(t, err)
+	assert.True(t, repo.lastQuery.RushFirst)
+
+	_, _, err = service.ListOrders(testCustomer, ListOrdersOptions{Page: 1, Limit: 10})
+	assert.NoError(t, err)
+	assert.False(t, repo.lastQuery.RushFirst)
+}
+
+func TestOrderService_ListOrders_DateRange(t *testing.T) {
+	repo := &recordingOrderRepository{fakeOrderRepository: newFakeOrderRepository()}
+	service := newTestOrderService(repo.fakeOrderRepository)
+	service.orders = repo
+
+	start := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+
+	_, _, err := service.ListOrders(testCustomer, ListOrdersOptions{Page: 1, Limit: 10, CreatedAfter: &start, CreatedBefore: &end})
+	assert.NoError(t, err)
+	assert.Equal(t, &start, repo.lastQuery.CreatedAfter)
+	assert.Equal(t, &end, repo.lastQuery.CreatedBefore)
+
+	// An empty or inverted range is rejected rather than silently returning nothing
+	_, _, err = service.ListOrders(testCustomer, ListOrdersOptions{Page: 1, Limit: 10, CreatedAfter: &end, CreatedBefore: &start})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+}
+
+func TestOrderService_ListOrdersByCursor(t *testing.T) {
+	created := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	repo := &recordingOrderRepository{fakeOrderRepository: newFakeOrderRepository(
+		models.Order{ID: 1, Status: "submitted", CustomerID: testCustomer.ID, CreatedAt: created},
+		models.Order{ID: 2, Status: "submitted", CustomerID: testCustomer.ID, CreatedAt: created},
+	)}
+	service := newTestOrderService(repo.fakeOrderRepository)
+	service.orders = repo
+
+	// One order more than the page asks for shows another page follows
+	orders, next, err := service.ListOrdersByCursor(testCustomer, ListOrdersOptions{Limit: 1})
+	assert.NoError(t, err)
+	assert.Len(t, orders, 1)
+	assert.NotEmpty(t, next)
+	assert.True(t, repo.lastQuery.Keyset)
+	assert.Nil(t, repo.lastQuery.After)
+	assert.Equal(t, 2, repo.lastQuery.Limit)
+
+	// The cursor continues after the last order of the page
+	_, _, err = service.ListOrdersByCursor(testCustomer, ListOrdersOptions{Limit: 1, Cursor: next})
+	assert.NoError(t, err)
+	assert.Equal(t, &repositories.OrderCursor{CreatedAt: created, ID: orders[0].ID}, repo.lastQuery.After)
+
+	_, next, err = service.ListOrdersByCursor(testCustomer, ListOrdersOptions{Limit: 2})
+	assert.NoError(t, err)
+	assert.Empty(t, next, "the last page has no next cursor")
+
+	_, _, err = service.ListOrdersByCursor(testCustomer, ListOrdersOptions{Limit: 1, Cursor: "bm90IGpzb24"})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+	_, _, err = service.ListOrdersByCursor(testCustomer, ListOrdersOptions{Limit: 1, Sort: "price"})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+}
+
+func TestOrderService_ListOrders_Sort(t *testing.T) {
+	repo := &recordingOrderRepository{fakeOrderRepository: newFakeOrderRepository()}
+	service := newTestOrderService(repo.fakeOrderRepository)
+	service.orders = repo
+
+	_, _, err := service.ListOrders(testTechnician, ListOrdersOptions{Page: 1, Limit: 10, Sort: "created_at", Order: "asc"})
+	assert.NoError(t, err)
+	assert.Equal(t, "created_at", repo.lastQuery.SortBy)
+	assert.True(t, repo.lastQuery.SortAscending)
+
+	// Technicians keep rush orders on top whatever the sort
+	assert.True(t, repo.lastQuery.RushFirst)
+
+	_, _, err = service.ListOrders(testCustomer, ListOrdersOptions{Page: 1, Limit: 10, Sort: "price"})
+	assert.NoError(t, err)
+	assert.False(t, repo.lastQuery.SortAscending)
+
+	_, _, err = service.ListOrders(testCustomer, ListOrdersOptions{Page: 1, Limit: 10, Sort: "description; DROP TABLE orders"})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+
+	_, _, err = service.ListOrders(testCustomer, ListOrdersOptions{Page: 1, Limit: 10, Order: "up"})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+}
+
+func TestOrderService_UpdateOrderStatus(t *testing.T) {
+	repo := newFakeOrderRepository(
+		models.Order{ID: 1, CustomerID: testCustomer.ID, Status: StatusAccepted, TechnicianID: uintPtr(testTechnician.ID)},
+		models.Order{ID: 2, CustomerID: testCustomer.ID, Status: StatusRejected, TechnicianID: uintPtr(testTechnician.ID)},
+	)
+	service := newTestOrderService(repo)
+
+	_, err := service.UpdateOrderStatus(otherTech, "1", StatusInProduction, 0)
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+
+	_, err = service.UpdateOrderStatus(testTechnician, "1", StatusShipped, 0)
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "INVALID_TRANSITION")
+
+	_, err = service.UpdateOrderStatus(testTechnician, "2", StatusInProduction, 0)
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "INVALID_STATE")
+
+	for _, status := range []string{StatusInProduction, StatusShipped, StatusDelivered} {
+		order, err := service.UpdateOrderStatus(testTechnician, "1", status, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, status, order.Status)
+	}
+
+	// Production start and shipping times are recorded for the SLA report, delivery times for earnings
+	assert.NotNil(t, repo.orders[1].ProductionStartedAt)
+	assert.NotNil(t, repo.orders[1].ShippedAt)
+	assert.NotNil(t, repo.orders[1].DeliveredAt)
+}
+
+func TestOrderService_UpdateOrderStatus_Version(t *testing.T) {
+	repo := newFakeOrderRepository(
+		models.Order{ID: 1, CustomerID: testCustomer.ID, Status: StatusAccepted, TechnicianID: uintPtr(testTechnician.ID), Version: 3},
+	)
+	service := newTestOrderService(repo)
+
+	_, err := service.UpdateOrderStatus(testTechnician, "1", StatusInProduction, 2)
+	assertAPIError(t, err, http.StatusConflict, "ORDER_CHANGED")
+	assert.Equal(t, StatusAccepted, repo.orders[1].Status)
+
+	feedback := "No"
+	_, err = service.ReviewOrder(testTechnician, "1", ReviewOrderInput{Action: "reject", Feedback: &feedback, Version: 2})
+	assertAPIError(t, err, http.StatusConflict, "ORDER_CHANGED")
+
+	order, err := service.UpdateOrderStatus(testTechnician, "1", StatusInProduction, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusInProduction, order.Status)
+}
+
+func TestOrderService_BulkUpdateOrderStatus(t *testing.T) {
+	repo := newFakeOrderRepository(
+		models.Order{ID: 1, CustomerID: testCustomer.ID, Status: StatusInProduction, TechnicianID: uintPtr(testTechnician.ID)},
+		models.Order{ID: 2, CustomerID: testCustomer.ID, Status: StatusInProduction, TechnicianID: uintPtr(otherTech.ID)},
+		models.Order{ID: 3, CustomerID: testCustomer.ID, Status: StatusAccepted, TechnicianID: uintPtr(testTechnician.ID)},
+		models.Order{ID: 4, CustomerID: testCustomer.ID, Status: StatusInProduction, TechnicianID: uintPtr(testTechnician.ID)},
+	)
+	service := newTestOrderService(repo)
+
+	_, err := service.BulkUpdateOrderStatus(testCustomer, []uint{1}, StatusShipped)
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+
+	results, err := service.BulkUpdateOrderStatus(testTechnician, []uint{1, 2, 3, 99, 4}, StatusShipped)
+	assert.NoError(t, err)
+	assert.Len(t, results, 5)
+
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, StatusShipped, results[0].Order.Status)
+	assertAPIError(t, results[1].Err, http.StatusForbidden, "FORBIDDEN")
+	assertAPIError(t, results[2].Err, http.StatusUnprocessableEntity, "INVALID_TRANSITION")
+	assertAPIError(t, results[3].Err, http.StatusNotFound, "ORDER_NOT_FOUND")
+	assert.NoError(t, results[4].Err)
+
+	// Failures do not roll back the orders that succeeded
+	assert.Equal(t, StatusShipped, repo.orders[1].Status)
+	assert.Equal(t, StatusInProduction, repo.orders[2].Status)
+	assert.Equal(t, StatusShipped, repo.orders[4].Status)
+}
+
+func TestOrderService_Reorder(t *testing.T) {
+	repo := newFakeOrderRepository(
+		models.Order{ID: 1, CustomerID: testCustomer.ID, Status: StatusDelivered, Description: "Chrome", ShippingAddressID: uintPtr(7), ShippingAddress: testAddress},
+		models.Order{ID: 2, CustomerID: testCustomer.ID, Status: StatusShipped},
+	)
+	service := newTestOrderService(repo)
+
+	_, err := service.Reorder(otherCustomer, "1", 1)
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+
+	_, err = service.Reorder(testCustomer, "2", 1)
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "INVALID_ORDER_STATE")
+
+	order, err := service.Reorder(testCustomer, "1", 3)
+	assert.NoError(t, err)
+	assert.Equal(t, "Chrome", order.Description)
+	assert.Equal(t, 3, order.Quantity)
+	assert.Equal(t, StatusSubmitted, order.Status)
+	assert.Equal(t, uint(1), *order.OriginalOrderID)
+	assert.Equal(t, testAddress, order.ShippingAddress)
+}
+
+func TestOrderService_DuplicateOrder(t *testing.T) {
+	imageKey := "orders/chrome.png"
+	repo := newFakeOrderRepository(
+		models.Order{ID: 1, CustomerID: testCustomer.ID, Status: StatusRejected, Description: "Chrome", Quantity: 2, ImageS3Key: &imageKey},
+		models.Order{ID: 2, CustomerID: testCustomer.ID, Status: StatusInProduction, Description: "French", Quantity: 1, ShippingAddressID: uintPtr(7), ShippingAddress: testAddress},
+	)
+	service := newTestOrderService(repo)
+
+	_, err := service.DuplicateOrder(otherCustomer, "1")
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+	_, err = service.DuplicateOrder(testTechnician, "1")
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+	_, err = service.DuplicateOrder(testCustomer, "99")
+	assertAPIError(t, err, http.StatusNotFound, "ORDER_NOT_FOUND")
+
+	// Orders in any status can be duplicated
+	order, err := service.DuplicateOrder(testCustomer, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, "Chrome", order.Description)
+	assert.Equal(t, 2, order.Quantity)
+	assert.Equal(t, &imageKey, order.ImageS3Key)
+	assert.Equal(t, StatusSubmitted, order.Status)
+	assert.Equal(t, uint(1), *order.OriginalOrderID)
+
+	order, err = service.DuplicateOrder(testCustomer, "2")
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSubmitted, order.Status)
+	assert.Nil(t, order.TechnicianID)
+	assert.Equal(t, testAddress, order.ShippingAddress)
+}
+
+func TestOrderService_OrderLineage(t *testing.T) {
+	repo := newFakeOrderRepository(
+		models.Order{ID: 1, CustomerID: testCustomer.ID, Status: StatusDelivered, Quantity: 1, TechnicianID: uintPtr(testTechnician.ID)},
+		models.Order{ID: 2, CustomerID: testCustomer.ID, Status: StatusDelivered, Quantity: 2, OriginalOrderID: uintPtr(1), TechnicianID: uintPtr(otherTech.ID)},
+		models.Order{ID: 3, CustomerID: testCustomer.ID, Status: StatusSubmitted, Quantity: 1},
+		models.Order{ID: 4, CustomerID: testCustomer.ID, Status: StatusSubmitted, Quantity: 3, OriginalOrderID: uintPtr(2)},
+		models.Order{ID: 5, CustomerID: testCustomer.ID, Status: StatusRejected, Quantity: 1, OriginalOrderID: uintPtr(1)},
+	)
+	service := newTestOrderService(repo)
+
+	// Any order of the family gives the whole lineage, from the original down
+	for _, id := range []string{"1", "4"} {
+		lineage, err := service.OrderLineage(testCustomer, id)
+		assert.NoError(t, err)
+		assert.Equal(t, uint(1), lineage.RootOrderID)
+		assert.Equal(t, 4, lineage.Count)
+		var ids []uint
+		for _, order := range lineage.Orders {
+			ids = append(ids, order.ID)
+		}
+		assert.Equal(t, []uint{1, 2, 4, 5}, ids)
+	}
+
+	lineage, err := service.OrderLineage(testCustomer, "3")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, lineage.Count)
+
+	// Technicians who can see the order see the lineage, including copies assigned to others
+	lineage, err = service.OrderLineage(testTechnician, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, 4, lineage.Count)
+	_, err = service.OrderLineage(testTechnician, "2")
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+	_, err = service.OrderLineage(otherCustomer, "1")
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+}
+
+func TestOrderService_AssignOrder(t *testing.T) {
+	repo := newFakeOrderRepository(
+		models.Order{ID: 1, CustomerID: testCustomer.ID, Status: StatusSubmitted, ShippingAddress: testAddress},
+		models.Order{ID: 2, CustomerID: testCustomer.ID, Status: StatusSubmitted, TechnicianID: uintPtr(otherTech.ID), ShippingAddress: testAddress},
+	)
+	service := newTestOrderService(repo)
+
+	_, err := service.AssignOrder(testCustomer, "1")
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+
+	_, err = service.AssignOrder(testTechnician, "2")
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "ALREADY_ASSIGNED")
+
+	order, err := service.AssignOrder(testTechnician, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, testTechnician.ID, *order.TechnicianID)
+
+	_, err = service.AssignOrder(testTechnician, "1")
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "ALREADY_ASSIGNED")
+}
+
+func TestCanTransition(t *testing.T) {
+	assert.True(t, CanTransition(StatusAccepted, StatusInProduction))
+	assert.True(t, CanTransition(StatusShipped, StatusDelivered))
+	assert.False(t, CanTransition(StatusAccepted, StatusShipped))
+	assert.False(t, CanTransition(StatusDelivered, StatusShipped))
+	assert.False(t, CanTransition(StatusSubmitted, StatusInProduction))
+}
+
+func TestOrderService_ExpireStaleOrders(t *testing.T) {
+	now := time.Now()
+	repo := newFakeOrderRepository(
+		models.Order{ID: 1, CustomerID: testCustomer.ID, Status: StatusSubmitted, CreatedAt: now.AddDate(0, 0, -31), ShippingAddress: testAddress},
+		models.Order{ID: 2, CustomerID: testCustomer.ID, Status: StatusSubmitted, CreatedAt: now.AddDate(0, 0, -29), ShippingAddress: testAddress},
+		models.Order{ID: 3, CustomerID: testCustomer.ID, Status: StatusAccepted, CreatedAt: now.AddDate(0, 0, -60)},
+		models.Order{ID: 4, CustomerID: testCustomer.ID, Status: StatusSubmitted, TechnicianID: uintPtr(testTechnician.ID), CreatedAt: now.AddDate(0, 0, -45), ShippingAddress: testAddress},
+	)
+	service := newTestOrderService(repo)
+
+	expired, err := service.ExpireStaleOrders(30 * 24 * time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, expired)
+
+	assert.Equal(t, StatusExpired, repo.orders[1].Status)
+	assert.Equal(t, StatusSubmitted, repo.orders[2].Status)
+	assert.Equal(t, StatusAccepted, repo.orders[3].Status)
+	assert.Equal(t, StatusExpired, repo.orders[4].Status)
+
+	// Each customer is told in the order conversation by a notice without a sender
+	assert.Len(t, repo.notices, 2)
+	assert.Nil(t, repo.notices[0].SenderID)
+	assert.Contains(t, repo.notices[0].Text, "not reviewed within 30 days")
+
+	// Expired orders cannot be reviewed or moved through production
+	_, err = service.ReviewOrder(testTechnician, "1", ReviewOrderInput{Action: "accept", Price: float64Ptr(30)})
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "INVALID_STATE")
+	_, err = service.UpdateOrderStatus(testTechnician, "4", StatusInProduction, 0)
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "INVALID_STATE")
+
+	expired, err = service.ExpireStaleOrders(30 * 24 * time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, expired)
+}
+
+func TestOrderService_DueDates(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+	date := func(year int, month time.Month, day int) *time.Time {
+		d := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+		return &d
+	}
+	repo := newFakeOrderRepository(
+		models.Order{ID: 1, CustomerID: testCustomer.ID, Status: StatusSubmitted, ShippingAddress: testAddress},
+		models.Order{ID: 2, CustomerID: testCustomer.ID, Status: StatusSubmitted, ShippingAddress: testAddress},
+	)
+	service := newTestOrderService(repo)
+	service.now = func() time.Time { return now }
+
+	t.Run("Requested date", func(t *testing.T) {
+		order, err := service.CreateOrder(testCustomer, CreateOrderInput{Description: "Today", Quantity: 1, RequestedBy: date(2026, 3, 10)})
+		assert.NoError(t, err)
+		assert.Equal(t, date(2026, 3, 10), order.RequestedBy)
+
+		_, err = service.CreateOrder(testCustomer, CreateOrderInput{Description: "Past", Quantity: 1, RequestedBy: date(2026, 3, 9)})
+		assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+		_, err = service.CreateOrder(testCustomer, CreateOrderInput{Description: "Far", Quantity: 1, RequestedBy: date(2027, 3, 11)})
+		assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+	})
+
+	t.Run("Promised date", func(t *testing.T) {
+		feedback := "Not this time"
+		_, err := service.ReviewOrder(testTechnician, "1", ReviewOrderInput{Action: "reject", Feedback: &feedback, PromisedBy: date(2026, 3, 20)})
+		assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+		_, err = service.ReviewOrder(testTechnician, "1", ReviewOrderInput{Action: "accept", Price: float64Ptr(30), PromisedBy: date(2026, 3, 1)})
+		assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+
+		order, err := service.ReviewOrder(testTechnician, "1", ReviewOrderInput{Action: "accept", Price: float64Ptr(30), PromisedBy: date(2026, 3, 20)})
+		assert.NoError(t, err)
+		assert.Equal(t, date(2026, 3, 20), order.PromisedBy)
+		assert.False(t, order.Overdue)
+
+		// Accepting without a promise is allowed
+		order, err = service.ReviewOrder(testTechnician, "2", ReviewOrderInput{Action: "accept", Price: float64Ptr(30)})
+		assert.NoError(t, err)
+		assert.Nil(t, order.PromisedBy)
+	})
+}
+
+func TestOrderService_ListOverdueOrders(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) *time.Time {
+		d := time.Date(2026, 3, 10-days, 0, 0, 0, 0, time.UTC)
+		return &d
+	}
+	repo := newFakeOrderRepository(
+		// Promised two days ago, still in production
+		models.Order{ID: 1, Status: StatusInProduction, TechnicianID: uintPtr(testTechnician.ID), PromisedBy: daysAgo(2)},
+		// Requested five days ago with no promise
+		models.Order{ID: 2, Status: StatusAccepted, TechnicianID: uintPtr(testTechnician.ID), RequestedBy: daysAgo(5)},
+		// The promise overrides an earlier requested date
+		models.Order{ID: 3, Status: StatusAccepted, TechnicianID: uintPtr(testTechnician.ID), RequestedBy: daysAgo(5), PromisedBy: daysAgo(-3)},
+		// Due today is not overdue yet
+		models.Order{ID: 4, Status: StatusAccepted, TechnicianID: uintPtr(testTechnician.ID), PromisedBy: daysAgo(0)},
+		// Shipped orders are no longer overdue
+		models.Order{ID: 5, Status: StatusShipped, TechnicianID: uintPtr(testTechnician.ID), PromisedBy: daysAgo(4)},
+		// Another technician's order
+		models.Order{ID: 6, Status: StatusAccepted, TechnicianID: uintPtr(otherTech.ID), PromisedBy: daysAgo(4)},
+	)
+	service := newTestOrderService(repo)
+	service.now = func() time.Time { return now }
+
+	orders, err := service.ListOverdueOrders(testTechnician)
+	assert.NoError(t, err)
+	if assert.Len(t, orders, 2) {
+		assert.Equal(t, uint(2), orders[0].ID)
+		assert.Equal(t, uint(1), orders[1].ID)
+		assert.True(t, orders[0].Overdue)
+		assert.True(t, orders[1].Overdue)
+	}
+
+	// The same flag is surfaced when listing
+	listed, _, err := service.ListOrders(testTechnician, ListOrdersOptions{Page: 1, Limit: 10})
+	assert.NoError(t, err)
+	for _, order := range listed {
+		assert.Equal(t, order.ID == 1 || order.ID == 2, order.Overdue, "order %d", order.ID)
+	}
+
+	_, err = service.ListOverdueOrders(testCustomer)
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+}
@@ -0,0 +1,269 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"gorm.io/gorm"
+)
+
+// Saved order view limits
+const (
+	MaxOrderViews          = 20
+	MaxOrderViewNameLength = 100
+	MaxOrderViewDays       = 366
+)
+
+// OrderViewInput holds the editable fields of a saved order view
+type OrderViewInput struct {
+	Name              string
+	Statuses          []string
+	CreatedAfter      *time.Time
+	CreatedBefore     *time.Time
+	CreatedWithinDays *int
+	Sort              string
+	Order             string
+}
+
+// OrderViewService manages users' saved order list views
+type OrderViewService interface {
+	// ListViews returns the user's views
+	ListViews(user *models.User) ([]models.OrderView, error)
+
+	// GetView returns one of the user's views
+	GetView(user *models.User, viewID string) (*models.OrderView, error)
+
+	// CreateView saves a new view for the user
+	CreateView(user *models.User, input OrderViewInput) (*models.OrderView, error)
+
+	// UpdateView replaces one of the user's views
+	UpdateView(user *models.User, viewID string, input OrderViewInput) (*models.OrderView, error)
+
+	// DeleteView removes one of the user's views
+	DeleteView(user *models.User, viewID string) error
+}
+
+// DefaultOrderViewService implements OrderViewService on top of an OrderViewRepository
+type DefaultOrderViewService struct {
+	views repositories.OrderViewRepository
+}
+
+var orderViewServiceInstance OrderViewService
+
+// NewOrderViewService creates an order view service using the given repository
+func NewOrderViewService(views repositories.OrderViewRepository) *DefaultOrderViewService {
+	return &DefaultOrderViewService{views: views}
+}
+
+// GetOrderViewService returns the configured order view service
+// When none has been set, a service over the current database connection is returned
+func GetOrderViewService() OrderViewService {
+	if orderViewServiceInstance != nil {
+		return orderViewServiceInstance
+	}
+	return NewOrderViewService(repositories.NewOrderViewRepository(config.GetDB()))
+}
+
+// SetOrderViewService sets the order view service instance (primarily for testing)
+func SetOrderViewService(service OrderViewService) {
+	orderViewServiceInstance = service
+}
+
+// ListViews returns the user's views, oldest first
+func (s *DefaultOrderViewService) ListViews(user *models.User) ([]models.OrderView, error) {
+	views, err := s.views.ListForUser(user.ID)
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to fetch views").Wrap(err)
+	}
+	return views, nil
+}
+
+// GetView returns one of the user's views
+func (s *DefaultOrderViewService) GetView(user *models.User, viewID string) (*models.OrderView, error) {
+	return s.find(user, viewID)
+}
+
+// CreateView saves a new view for the user
+func (s *DefaultOrderViewService) CreateView(user *models.User, input OrderViewInput) (*models.OrderView, error) {
+	input, err := validateOrderViewInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.views.CountForUser(user.ID)
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to count views").Wrap(err)
+	}
+	if count >= MaxOrderViews {
+		return nil, apierror.Unprocessable("TOO_MANY_VIEWS", "You have saved the maximum number of views").WithDetails(map[string]interface{}{
+			"max_views": MaxOrderViews,
+		})
+	}
+	if err := s.requireUniqueName(user, input.Name, 0); err != nil {
+		return nil, err
+	}
+
+	view := &models.OrderView{UserID: user.ID}
+	applyOrderViewInput(view, input)
+	if err := s.views.Create(view); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to create view").Wrap(err)
+	}
+	return view, nil
+}
+
+// UpdateView replaces one of the user's views
+func (s *DefaultOrderViewService) UpdateView(user *models.User, viewID string, input OrderViewInput) (*models.OrderView, error) {
+	input, err := validateOrderViewInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	view, err := s.find(user, viewID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requireUniqueName(user, input.Name, view.ID); err != nil {
+		return nil, err
+	}
+
+	applyOrderViewInput(view, input)
+	if err := s.views.Save(view); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to update view").Wrap(err)
+	}
+	return view, nil
+}
+
+// DeleteView removes one of the user's views
+func (s *DefaultOrderViewService) DeleteView(user *models.User, viewID string) error {
+	view, err := s.find(user, viewID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.views.Delete(view); err != nil {
+		return apierror.Internal("DATABASE_ERROR", "Failed to delete view").Wrap(err)
+	}
+	return nil
+}
+
+// find loads one of the user's views by its ID
+// Other users' views are reported as not found, so their IDs are not revealed
+func (s *DefaultOrderViewService) find(user *models.User, viewID string) (*models.OrderView, error) {
+	id, err := strconv.ParseUint(viewID, 10, 64)
+	if err != nil || id == 0 {
+		return nil, apierror.NotFound("VIEW_NOT_FOUND", "View not found")
+	}
+	view, err := s.views.FindForUser(user.ID, uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apierror.NotFound("VIEW_NOT_FOUND", "View not found")
+		}
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load view").Wrap(err)
+	}
+	return view, nil
+}
+
+// requireUniqueName rejects a name another of the user's views already has; exceptID is the view being renamed
+func (s *DefaultOrderViewService) requireUniqueName(user *models.User, name string, exceptID uint) error {
+	existing, err := s.views.FindByName(user.ID, name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return apierror.Internal("DATABASE_ERROR", "Failed to check view name").Wrap(err)
+	}
+	if existing.ID != exceptID {
+		return apierror.Conflict("VIEW_NAME_TAKEN", "You already have a view with this name")
+	}
+	return nil
+}
+
+// applyOrderViewInput copies validated input onto a view
+func applyOrderViewInput(view *models.OrderView, input OrderViewInput) {
+	view.Name = input.Name
+	view.Statuses = input.Statuses
+	view.CreatedAfter = input.CreatedAfter
+	view.CreatedBefore = input.CreatedBefore
+	view.CreatedWithinDays = input.CreatedWithinDays
+	view.Sort = input.Sort
+	view.SortOrder = input.Order
+}
+
+// validateOrderViewInput trims and checks the fields shared by create and update
+// Filters are checked the way ListOrders checks them, so a saved view always applies cleanly
+func validateOrderViewInput(input OrderViewInput) (OrderViewInput, error) {
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		return input, apierror.Validation("Invalid view", map[string]string{"name": "is required"})
+	}
+	if utf8.RuneCountInString(input.Name) > MaxOrderViewNameLength {
+		return input, apierror.Validation("Invalid view", map[string]string{"name": "must be at most 100 characters"})
+	}
+
+	statuses := make([]string, 0, len(input.Statuses))
+	for _, status := range input.Statuses {
+		if !oneOf(status, OrderStatuses) {
+			return input, apierror.Validation("Invalid view", map[string]string{
+				"statuses": "must be one of: " + strings.Join(OrderStatuses, ", "),
+			})
+		}
+		if !oneOf(status, statuses) {
+			statuses = append(statuses, status)
+		}
+	}
+	input.Statuses = statuses
+
+	if input.CreatedWithinDays != nil {
+		if *input.CreatedWithinDays < 1 || *input.CreatedWithinDays > MaxOrderViewDays {
+			return input, apierror.Validation("Invalid view", map[string]string{"created_within_days": "must be between 1 and 366"})
+		}
+		if input.CreatedAfter != nil {
+			return input, apierror.Validation("Invalid view", map[string]string{"created_within_days": "cannot be combined with created_after"})
+		}
+	}
+	if input.CreatedAfter != nil && input.CreatedBefore != nil && !input.CreatedAfter.Before(*input.CreatedBefore) {
+		return input, apierror.Validation("Invalid view", map[string]string{"created_after": "must be before created_before"})
+	}
+
+	if input.Sort != "" && !repositories.IsOrderSortColumn(input.Sort) {
+		return input, apierror.Validation("Invalid view", map[string]string{
+			"sort": "must be one of: " + strings.Join(repositories.OrderSortColumns, ", "),
+		})
+	}
+	if input.Order != "" && input.Order != "asc" && input.Order != "desc" {
+		return input, apierror.Validation("Invalid view", map[string]string{"order": "must be asc or desc"})
+	}
+	return input, nil
+}
+
+// ApplyOrderView fills the list options a request left unset from a saved view
+// A relative created_within_days range counts back from the start of today (UTC)
+func ApplyOrderView(view *models.OrderView, opts ListOrdersOptions, now time.Time) ListOrdersOptions {
+	if len(opts.Statuses) == 0 {
+		opts.Statuses = view.Statuses
+	}
+	if opts.CreatedAfter == nil {
+		opts.CreatedAfter = view.CreatedAfter
+		if view.CreatedWithinDays != nil {
+			since := startOfDay(now).AddDate(0, 0, -(*view.CreatedWithinDays - 1))
+			opts.CreatedAfter = &since
+		}
+	}
+	if opts.CreatedBefore == nil {
+		opts.CreatedBefore = view.CreatedBefore
+	}
+	if opts.Sort == "" {
+		opts.Sort = view.Sort
+	}
+	if opts.Order == "" {
+		opts.Order = view.SortOrder
+	}
+	return opts
+}
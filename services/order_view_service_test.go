@@ -0,0 +1,52 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyOrderView(t *testing.T) {
+	now := time.Date(2026, 6, 10, 15, 30, 0, 0, time.UTC)
+	days := 7
+	view := &models.OrderView{Statuses: []string{StatusInProduction}, CreatedWithinDays: &days, Sort: "price", SortOrder: "asc"}
+
+	opts := ApplyOrderView(view, ListOrdersOptions{Page: 2, Limit: 10}, now)
+	assert.Equal(t, []string{StatusInProduction}, opts.Statuses)
+	assert.Equal(t, time.Date(2026, 6, 4, 0, 0, 0, 0, time.UTC), *opts.CreatedAfter)
+	assert.Nil(t, opts.CreatedBefore)
+	assert.Equal(t, "price", opts.Sort)
+	assert.Equal(t, "asc", opts.Order)
+	assert.Equal(t, 2, opts.Page)
+
+	// Whatever the request set wins over the view
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	opts = ApplyOrderView(view, ListOrdersOptions{Statuses: []string{StatusShipped}, CreatedAfter: &since, Order: "desc"}, now)
+	assert.Equal(t, []string{StatusShipped}, opts.Statuses)
+	assert.Equal(t, since, *opts.CreatedAfter)
+	assert.Equal(t, "desc", opts.Order)
+}
+
+func TestValidateOrderViewInput(t *testing.T) {
+	days := 3
+	after := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	input, err := validateOrderViewInput(OrderViewInput{Name: "  Rush queue ", Statuses: []string{StatusAccepted, StatusAccepted, StatusInProduction}})
+	assert.NoError(t, err)
+	assert.Equal(t, "Rush queue", input.Name)
+	assert.Equal(t, []string{StatusAccepted, StatusInProduction}, input.Statuses)
+
+	for name, invalid := range map[string]OrderViewInput{
+		"blank name":       {Name: "   "},
+		"unknown status":   {Name: "View", Statuses: []string{"lost"}},
+		"both date styles": {Name: "View", CreatedWithinDays: &days, CreatedAfter: &after},
+		"empty range":      {Name: "View", CreatedAfter: &after, CreatedBefore: &after},
+		"unknown sort":     {Name: "View", Sort: "customer_id"},
+		"unknown order":    {Name: "View", Order: "up"},
+	} {
+		_, err := validateOrderViewInput(invalid)
+		assert.Error(t, err, name)
+	}
+}
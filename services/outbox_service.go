@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/events"
+	"github.com/kendall-kelly/kendalls-nails-api/jobs"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+)
+
+// Outbox relay settings
+const (
+	OutboxPollInterval  = time.Second
+	OutboxPruneInterval = time.Hour
+	OutboxRetention     = 7 * 24 * time.Hour // how long published events are kept, for investigating deliveries
+	outboxRetryBase     = 5 * time.Second    // doubled after every failed attempt
+	outboxRetryMax      = 10 * time.Minute
+	outboxLease         = time.Minute // longer than publishing can take, so a claimed event is not published twice
+	outboxBatchSize     = 100
+)
+
+// OutboxService publishes the events saved in the outbox to the event bus
+type OutboxService interface {
+	// Relay publishes the events whose next attempt is due and returns how many were attempted
+	Relay(ctx context.Context, limit int) (int, error)
+
+	// Prune removes events published longer ago than retention and returns how many were removed
+	Prune(retention time.Duration) (int64, error)
+}
+
+// DefaultOutboxService implements OutboxService on top of an OutboxRepository
+type DefaultOutboxService struct {
+	outbox repositories.OutboxRepository
+	bus    *events.Bus
+	now    func() time.Time
+}
+
+// NewOutboxService creates an outbox service that publishes to the given bus
+func NewOutboxService(outbox repositories.OutboxRepository, bus *events.Bus) *DefaultOutboxService {
+	return &DefaultOutboxService{outbox: outbox, bus: bus, now: time.Now}
+}
+
+// OutboxRelayJob returns the background job that publishes saved events, including retries
+func OutboxRelayJob(service OutboxService) jobs.Job {
+	return jobs.Job{
+		Name:     "outbox_relay",
+		Interval: OutboxPollInterval,
+		Run: func(ctx context.Context) error {
+			// Keep relaying while events are due in full batches, so a burst does not wait for the next interval
+			for {
+				attempted, err := service.Relay(ctx, outboxBatchSize)
+				if err != nil || attempted < outboxBatchSize || ctx.Err() != nil {
+					return err
+				}
+			}
+		},
+	}
+}
+
+// OutboxPruneJob returns the background job that removes events published longer ago than OutboxRetention
+func OutboxPruneJob(service OutboxService) jobs.Job {
+	return jobs.Job{
+		Name:     "outbox_prune",
+		Interval: OutboxPruneInterval,
+		Run: func(ctx context.Context) error {
+			_, err := service.Prune(OutboxRetention)
+			return err
+		},
+	}
+}
+
+// Relay publishes the events whose next attempt is due, in the order they were written
+// Each event is claimed first, so several API instances can run the relay side by side; an event is marked
+// published once the bus accepted it, and one that fails is retried with backoff until it is published
+func (s *DefaultOutboxService) Relay(ctx context.Context, limit int) (int, error) {
+	now := s.now()
+	due, err := s.outbox.ListDue(now, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	attempted := 0
+	for i := range due {
+		event := &due[i]
+		claimed, err := s.outbox.Claim(event, now.Add(outboxLease))
+		if err != nil {
+			return attempted, err
+		}
+		if !claimed {
+			continue
+		}
+		attempted++
+
+		if err := s.bus.PublishEncoded(ctx, event.Name, []byte(event.Payload)); err != nil {
+			message := err.Error()
+			event.LastError = &message
+			event.NextAttemptAt = s.now().Add(outboxRetryDelay(event.Attempts))
+		} else {
+			publishedAt := s.now()
+			event.PublishedAt = &publishedAt
+			event.LastError = nil
+		}
+		if err := s.outbox.Save(event); err != nil {
+			return attempted, err
+		}
+	}
+	return attempted, nil
+}
+
+// Prune removes events published longer ago than retention
+func (s *DefaultOutboxService) Prune(retention time.Duration) (int64, error) {
+	return s.outbox.DeletePublishedBefore(s.now().Add(-retention))
+}
+
+// publishThrough announces an event about a saved change
+// With an outbox the event is saved alongside the change, in the request transaction when there is one,
+// and the outbox relay publishes it once committed; without one it is published straight away
+func publishThrough(outbox repositories.OutboxRepository, event events.Event, now time.Time) error {
+	if outbox == nil {
+		PublishEvent(event)
+		return nil
+	}
+	return saveEvent(outbox, event, now)
+}
+
+// saveEvent writes an event to the outbox, to be published by the relay once the surrounding transaction commits
+func saveEvent(outbox repositories.OutboxRepository, event events.Event, now time.Time) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return apierror.Internal("EVENT_ENCODING_ERROR", "Failed to encode the "+event.EventName()+" event").Wrap(err)
+	}
+	saved := &models.OutboxEvent{Name: event.EventName(), Payload: string(payload), NextAttemptAt: now}
+	if err := outbox.Create(saved); err != nil {
+		return apierror.Internal("DATABASE_ERROR", "Failed to save the "+event.EventName()+" event").Wrap(err)
+	}
+	return nil
+}
+
+// outboxRetryDelay returns how long to wait after the given number of failed attempts
+func outboxRetryDelay(attempts int) time.Duration {
+	delay := outboxRetryBase
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= outboxRetryMax {
+			return outboxRetryMax
+		}
+	}
+	return delay
+}
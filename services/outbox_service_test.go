@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/events"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeOutboxRepository struct {
+	events []*models.OutboxEvent
+}
+
+func (r *fakeOutboxRepository) Create(event *models.OutboxEvent) error {
+	event.ID = uint(len(r.events) + 1)
+	stored := *event
+	r.events = append(r.events, &stored)
+	return nil
+}
+
+func (r *fakeOutboxRepository) ListDue(now time.Time, limit int) ([]models.OutboxEvent, error) {
+	var due []models.OutboxEvent
+	for _, event := range r.events {
+		if event.PublishedAt == nil && !event.NextAttemptAt.After(now) && len(due) < limit {
+			due = append(due, *event)
+		}
+	}
+	return due, nil
+}
+
+func (r *fakeOutboxRepository) Claim(event *models.OutboxEvent, leaseUntil time.Time) (bool, error) {
+	stored := r.events[event.ID-1]
+	if stored.PublishedAt != nil || stored.Attempts != event.Attempts {
+		return false, nil
+	}
+	stored.Attempts++
+	stored.NextAttemptAt = leaseUntil
+	event.Attempts, event.NextAttemptAt = stored.Attempts, leaseUntil
+	return true, nil
+}
+
+func (r *fakeOutboxRepository) Save(event *models.OutboxEvent) error {
+	stored := *event
+	r.events[event.ID-1] = &stored
+	return nil
+}
+
+func (r *fakeOutboxRepository) DeletePublishedBefore(cutoff time.Time) (int64, error) {
+	var kept []*models.OutboxEvent
+	for _, event := range r.events {
+		if event.PublishedAt == nil || !event.PublishedAt.Before(cutoff) {
+			kept = append(kept, event)
+		}
+	}
+	deleted := int64(len(r.events) - len(kept))
+	r.events = kept
+	return deleted, nil
+}
+
+// failingTransport refuses every event
+type failingTransport struct{}
+
+func (failingTransport) Send(ctx context.Context, message events.Message) error {
+	return errors.New("broker unreachable")
+}
+
+func (failingTransport) Receive(ctx context.Context, max int) ([]events.Message, error) {
+	return nil, nil
+}
+
+func (failingTransport) Ack(ctx context.Context, message events.Message) error {
+	return nil
+}
+
+func TestOrderService_PublishesThroughOutbox(t *testing.T) {
+	publisher := &recordingWebhookPublisher{}
+	bus := events.NewBus(nil)
+	SubscribeWebhooks(bus, publisher)
+	SetEventBus(bus)
+	defer SetEventBus(nil)
+
+	outbox := &fakeOutboxRepository{}
+	service := newTestOrderService(newFakeOrderRepository())
+	service.outbox = outbox
+
+	_, err := service.CreateOrder(testCustomer, CreateOrderInput{Description: "Pink", Quantity: 2})
+	assert.NoError(t, err)
+	assert.Empty(t, publisher.events, "the event waits in the outbox until the order is committed")
+	if assert.Len(t, outbox.events, 1) {
+		assert.Equal(t, WebhookOrderCreated, outbox.events[0].Name)
+	}
+
+	relay := NewOutboxService(outbox, bus)
+	attempted, err := relay.Relay(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, attempted)
+	assert.Equal(t, []string{WebhookOrderCreated}, publisher.events)
+	assert.Contains(t, publisher.payloads[0], `"description":"Pink"`)
+	assert.NotNil(t, outbox.events[0].PublishedAt)
+
+	// Published events are not relayed again
+	attempted, err = relay.Relay(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, attempted)
+}
+
+func TestOutboxService_Relay_RetriesFailures(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	outbox := &fakeOutboxRepository{}
+	assert.NoError(t, saveEvent(outbox, OrderCreated{Order: &models.Order{ID: 1}}, now))
+
+	relay := NewOutboxService(outbox, events.NewBus(failingTransport{}))
+	relay.now = func() time.Time { return now }
+
+	attempted, err := relay.Relay(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, attempted)
+	event := outbox.events[0]
+	assert.Nil(t, event.PublishedAt)
+	assert.Equal(t, "broker unreachable", *event.LastError)
+	assert.Equal(t, now.Add(outboxRetryBase), event.NextAttemptAt)
+
+	// The event is retried once its backoff has passed
+	attempted, err = relay.Relay(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, attempted)
+	relay.now = func() time.Time { return now.Add(outboxRetryBase) }
+	attempted, err = relay.Relay(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, attempted)
+	assert.Equal(t, 2, outbox.events[0].Attempts)
+}
+
+func TestOutboxService_Prune(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	old, recent := now.Add(-8*24*time.Hour), now.Add(-time.Hour)
+	outbox := &fakeOutboxRepository{events: []*models.OutboxEvent{
+		{ID: 1, PublishedAt: &old},
+		{ID: 2, PublishedAt: &recent},
+		{ID: 3},
+	}}
+	relay := NewOutboxService(outbox, events.NewBus(nil))
+	relay.now = func() time.Time { return now }
+
+	deleted, err := relay.Prune(OutboxRetention)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	assert.Len(t, outbox.events, 2)
+}
+
+func TestOutboxRetryDelay(t *testing.T) {
+	assert.Equal(t, outboxRetryBase, outboxRetryDelay(1))
+	assert.Equal(t, 2*outboxRetryBase, outboxRetryDelay(2))
+	assert.Equal(t, outboxRetryMax, outboxRetryDelay(20))
+}
+
+func TestOrderService_ExpireStaleOrdersThroughOutbox(t *testing.T) {
+	publisher := &recordingWebhookPublisher{}
+	bus := events.NewBus(nil)
+	SubscribeWebhooks(bus, publisher)
+	SetEventBus(bus)
+	defer SetEventBus(nil)
+
+	outbox := &fakeOutboxRepository{}
+	repo := newFakeOrderRepository(models.Order{ID: 1, CustomerID: testCustomer.ID, Status: StatusSubmitted, CreatedAt: time.Now().AddDate(0, 0, -31), ShippingAddress: testAddress})
+	repo.outbox = outbox
+	service := newTestOrderService(repo)
+	service.outbox = outbox
+
+	expired, err := service.ExpireStaleOrders(30 * 24 * time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, expired)
+	assert.Empty(t, publisher.events, "the events wait in the outbox until the expiry is committed")
+	if assert.Len(t, outbox.events, 2) {
+		assert.Equal(t, WebhookOrderStatusChanged, outbox.events[0].Name)
+		assert.Contains(t, outbox.events[0].Payload, `"status":"expired"`)
+	}
+}
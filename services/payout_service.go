@@ -0,0 +1,187 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"github.com/kendall-kelly/kendalls-nails-api/utils"
+)
+
+// PayEarningsInput selects the earnings an admin marks as paid: orders delivered in [From, To)
+type PayEarningsInput struct {
+	TechnicianID *uint // only this technician's earnings; nil pays every technician
+	From         time.Time
+	To           time.Time
+	Reference    *string // the admin's note, such as a bank transfer ID
+}
+
+// PayoutHistory is a technician's payouts and what they are still owed, returned by GET /users/me/payouts
+type PayoutHistory struct {
+	Unpaid  []EarningsTotal `json:"unpaid"`  // delivered orders no payout has paid yet, per currency
+	Payouts []models.Payout `json:"payouts"` // newest first
+}
+
+// PayoutService records payments of technicians' earnings and reports what they are owed
+type PayoutService interface {
+	// PayEarnings marks the unpaid delivered orders in the input's period as paid (admins only)
+	// It records one payout per technician and currency and returns them
+	PayEarnings(admin *models.User, input PayEarningsInput) ([]models.Payout, error)
+
+	// ListMyPayouts returns the technician's payout history and unpaid balance
+	ListMyPayouts(technician *models.User) (*PayoutHistory, error)
+
+	// ListUnpaidBalances returns every technician's unpaid balance (admins only)
+	ListUnpaidBalances(admin *models.User) ([]repositories.UnpaidBalance, error)
+}
+
+// DefaultPayoutService implements PayoutService on top of a PayoutRepository
+type DefaultPayoutService struct {
+	payouts repositories.PayoutRepository
+	audit   repositories.AuditLogRepository
+}
+
+var payoutServiceInstance PayoutService
+
+// NewPayoutService creates a payout service using the given repositories
+// A nil audit repository records nothing
+func NewPayoutService(payouts repositories.PayoutRepository, audit repositories.AuditLogRepository) *DefaultPayoutService {
+	return &DefaultPayoutService{payouts: payouts, audit: audit}
+}
+
+// GetPayoutService returns the configured payout service
+// When none has been set, a service over the current database connection is returned
+func GetPayoutService() PayoutService {
+	if payoutServiceInstance != nil {
+		return payoutServiceInstance
+	}
+	db := config.GetDB()
+	return NewPayoutService(repositories.NewPayoutRepository(db), repositories.NewAuditLogRepository(db))
+}
+
+// SetPayoutService sets the payout service instance (primarily for testing)
+func SetPayoutService(service PayoutService) {
+	payoutServiceInstance = service
+}
+
+// PayEarnings marks the unpaid delivered orders in the input's period as paid (admins only)
+func (s *DefaultPayoutService) PayEarnings(admin *models.User, input PayEarningsInput) ([]models.Payout, error) {
+	if admin.Role != RoleAdmin {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only admins can record payouts")
+	}
+	from, to := input.From.UTC(), input.To.UTC()
+	if !to.After(from) {
+		return nil, apierror.Validation("Invalid payout period", map[string]string{"to": "must not be before from"})
+	}
+
+	orders, err := s.payouts.ListUnpaidOrders(input.TechnicianID, StatusDelivered, from, to)
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to fetch unpaid orders").Wrap(err)
+	}
+	if len(orders) == 0 {
+		return nil, apierror.Unprocessable("NO_UNPAID_EARNINGS", "No unpaid orders were delivered in this period")
+	}
+
+	// One payout per technician and currency, summed in whole cents
+	type payee struct {
+		technicianID uint
+		currency     string
+	}
+	batches := make(map[payee]*repositories.PayoutBatch)
+	for i := range orders {
+		order := &orders[i]
+		key := payee{*order.TechnicianID, order.Currency}
+		batch, ok := batches[key]
+		if !ok {
+			batch = &repositories.PayoutBatch{Payout: &models.Payout{
+				TechnicianID: key.technicianID,
+				Currency:     key.currency,
+				PeriodStart:  from,
+				PeriodEnd:    to,
+				Reference:    input.Reference,
+				PaidByID:     admin.ID,
+			}}
+			batches[key] = batch
+		}
+		batch.Payout.AmountCents += orderCents(order)
+		batch.Payout.OrderCount++
+		batch.OrderIDs = append(batch.OrderIDs, order.ID)
+	}
+
+	ordered := make([]repositories.PayoutBatch, 0, len(batches))
+	for _, batch := range batches {
+		batch.Payout.Amount = utils.FromCents(batch.Payout.AmountCents)
+		ordered = append(ordered, *batch)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		a, b := ordered[i].Payout, ordered[j].Payout
+		if a.TechnicianID != b.TechnicianID {
+			return a.TechnicianID < b.TechnicianID
+		}
+		return a.Currency < b.Currency
+	})
+
+	if err := s.payouts.CreatePayouts(ordered); err != nil {
+		if errors.Is(err, repositories.ErrOrdersAlreadyPaid) {
+			return nil, apierror.Conflict("ORDERS_ALREADY_PAID", "Some of these orders were paid while the payout was being recorded; try again")
+		}
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to record payouts").Wrap(err)
+	}
+
+	payouts := make([]models.Payout, 0, len(ordered))
+	for _, batch := range ordered {
+		payout := batch.Payout
+		if err := recordAudit(s.audit, admin, models.AuditPayoutRecorded, models.AuditTargetPayout, payout.ID, nil, map[string]interface{}{
+			"technician_id": payout.TechnicianID,
+			"currency":      payout.Currency,
+			"amount":        payout.Amount,
+			"order_ids":     batch.OrderIDs,
+		}); err != nil {
+			return nil, err
+		}
+		payouts = append(payouts, *payout)
+	}
+	return payouts, nil
+}
+
+// ListMyPayouts returns the technician's payout history and unpaid balance
+func (s *DefaultPayoutService) ListMyPayouts(technician *models.User) (*PayoutHistory, error) {
+	if technician.Role != RoleTechnician {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only technicians have payouts")
+	}
+
+	payouts, err := s.payouts.ListByTechnician(technician.ID)
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to fetch payouts").Wrap(err)
+	}
+	balances, err := s.payouts.UnpaidBalances(&technician.ID, StatusDelivered)
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to compute unpaid balance").Wrap(err)
+	}
+
+	history := &PayoutHistory{Unpaid: make([]EarningsTotal, 0, len(balances)), Payouts: payouts}
+	if history.Payouts == nil {
+		history.Payouts = []models.Payout{}
+	}
+	for _, balance := range balances {
+		history.Unpaid = append(history.Unpaid, EarningsTotal{Currency: balance.Currency, Orders: int(balance.Orders), Amount: balance.Amount})
+	}
+	return history, nil
+}
+
+// ListUnpaidBalances returns every technician's unpaid balance (admins only)
+func (s *DefaultPayoutService) ListUnpaidBalances(admin *models.User) ([]repositories.UnpaidBalance, error) {
+	if admin.Role != RoleAdmin {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only admins can view unpaid balances")
+	}
+
+	balances, err := s.payouts.UnpaidBalances(nil, StatusDelivered)
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to compute unpaid balances").Wrap(err)
+	}
+	return balances, nil
+}
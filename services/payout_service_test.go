@@ -0,0 +1,106 @@
+package services
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePayoutRepository is an in-memory PayoutRepository over a fake order repository
+type fakePayoutRepository struct {
+	orders  *fakeOrderRepository
+	payouts []models.Payout
+}
+
+func (r *fakePayoutRepository) CreatePayouts(batches []repositories.PayoutBatch) error {
+	for _, batch := range batches {
+		for _, id := range batch.OrderIDs {
+			if r.orders.orders[id].PayoutID != nil {
+				return repositories.ErrOrdersAlreadyPaid
+			}
+		}
+	}
+	for _, batch := range batches {
+		batch.Payout.ID = uint(len(r.payouts) + 1)
+		r.payouts = append(r.payouts, *batch.Payout)
+		for _, id := range batch.OrderIDs {
+			r.orders.orders[id].PayoutID = &batch.Payout.ID
+		}
+	}
+	return nil
+}
+
+func (r *fakePayoutRepository) ListByTechnician(technicianID uint) ([]models.Payout, error) {
+	var payouts []models.Payout
+	for i := len(r.payouts) - 1; i >= 0; i-- {
+		if r.payouts[i].TechnicianID == technicianID {
+			payouts = append(payouts, r.payouts[i])
+		}
+	}
+	return payouts, nil
+}
+
+func (r *fakePayoutRepository) ListUnpaidOrders(technicianID *uint, deliveredStatus string, from, to time.Time) ([]models.Order, error) {
+	var orders []models.Order
+	for id := uint(1); id < r.orders.nextID; id++ {
+		order, ok := r.orders.orders[id]
+		if !ok || order.TechnicianID == nil || order.PayoutID != nil || order.Status != deliveredStatus ||
+			(technicianID != nil && *order.TechnicianID != *technicianID) ||
+			order.DeliveredTime().Before(from) || !order.DeliveredTime().Before(to) {
+			continue
+		}
+		orders = append(orders, *order)
+	}
+	return orders, nil
+}
+
+func (r *fakePayoutRepository) UnpaidBalances(technicianID *uint, deliveredStatus string) ([]repositories.UnpaidBalance, error) {
+	return []repositories.UnpaidBalance{}, nil
+}
+
+func TestPayoutService_PayEarnings(t *testing.T) {
+	admin := &models.User{ID: 9, Role: RoleAdmin}
+	delivered := time.Date(2026, 5, 5, 10, 0, 0, 0, time.UTC)
+	orders := newFakeOrderRepository(
+		models.Order{ID: 1, Status: StatusDelivered, Currency: "USD", Price: float64Ptr(10.10), TechnicianID: uintPtr(testTechnician.ID), DeliveredAt: &delivered},
+		models.Order{ID: 2, Status: StatusDelivered, Currency: "USD", Price: float64Ptr(20.20), TechnicianID: uintPtr(testTechnician.ID), DeliveredAt: &delivered},
+		models.Order{ID: 3, Status: StatusDelivered, Currency: "USD", Price: float64Ptr(30), TechnicianID: uintPtr(otherTech.ID), DeliveredAt: &delivered},
+		models.Order{ID: 4, Status: StatusShipped, Currency: "USD", Price: float64Ptr(40), TechnicianID: uintPtr(testTechnician.ID)},
+	)
+	repo := &fakePayoutRepository{orders: orders}
+	service := NewPayoutService(repo, nil)
+	may := PayEarningsInput{From: time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)}
+
+	_, err := service.PayEarnings(testTechnician, may)
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+
+	_, err = service.PayEarnings(admin, PayEarningsInput{From: may.To, To: may.From})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+
+	// Without a technician everyone is paid, one payout each
+	payouts, err := service.PayEarnings(admin, may)
+	require.NoError(t, err)
+	require.Len(t, payouts, 2)
+	assert.Equal(t, testTechnician.ID, payouts[0].TechnicianID)
+	assert.Equal(t, int64(3030), payouts[0].AmountCents)
+	assert.Equal(t, 30.30, payouts[0].Amount)
+	assert.Equal(t, 2, payouts[0].OrderCount)
+	assert.Equal(t, admin.ID, payouts[0].PaidByID)
+	assert.Equal(t, otherTech.ID, payouts[1].TechnicianID)
+	assert.Nil(t, orders.orders[4].PayoutID)
+
+	_, err = service.PayEarnings(admin, may)
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "NO_UNPAID_EARNINGS")
+
+	history, err := service.ListMyPayouts(testTechnician)
+	require.NoError(t, err)
+	assert.Len(t, history.Payouts, 1)
+
+	_, err = service.ListUnpaidBalances(testTechnician)
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+}
@@ -0,0 +1,54 @@
+package services
+
+// Permissions name the actions routes are guarded by (see middleware.RequirePermission)
+// Services still check the caller's role and ownership; a permission only decides who may reach a route
+const (
+	PermOrdersCreate     = "orders:create"     // place, reorder and duplicate orders; claim gifts and intake drafts
+	PermOrdersReview     = "orders:review"     // assign, review and hand off orders
+	PermOrdersFulfil     = "orders:fulfil"     // move orders through production and record checklists, updates and supply usage
+	PermDesignsSave      = "designs:save"      // saved designs, design image uploads and recommendations
+	PermAppointmentsBook = "appointments:book" // book fittings for orders
+	PermStudioManage     = "studio:manage"     // add-ons, price lists, checklist template, availability, slots, intake tokens, calendar feed
+	PermEarningsRead     = "earnings:read"     // own earnings and payouts
+	PermSuppliesManage   = "supplies:manage"   // supplies inventory
+	PermQuotesRead       = "quotes:read"       // list guest quote requests
+	PermQuotesReview     = "quotes:review"     // review and convert guest quote requests
+	PermAnalyticsRead    = "analytics:read"    // analytics summary
+	PermRolesRequest     = "roles:request"     // apply to become a technician
+)
+
+// rolePermissions is the permission matrix: the permissions granted to each role
+// Admin-only routes are guarded by role instead (see middleware.RequireRole)
+var rolePermissions = map[string][]string{
+	RoleCustomer: {
+		PermOrdersCreate,
+		PermDesignsSave,
+		PermAppointmentsBook,
+		PermRolesRequest,
+	},
+	RoleTechnician: {
+		PermOrdersReview,
+		PermOrdersFulfil,
+		PermStudioManage,
+		PermEarningsRead,
+		PermSuppliesManage,
+		PermQuotesRead,
+		PermQuotesReview,
+		PermAnalyticsRead,
+	},
+	RoleAdmin: {
+		PermSuppliesManage,
+		PermQuotesRead,
+		PermAnalyticsRead,
+	},
+}
+
+// HasPermission reports whether the role is granted the permission
+func HasPermission(role string, permission string) bool {
+	for _, granted := range rolePermissions[role] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
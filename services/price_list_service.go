@@ -0,0 +1,190 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"gorm.io/gorm"
+)
+
+// Bounds on a price list's adjustment, in percent of the base set and add-ons
+const (
+	MinPriceAdjustmentPercent = -90.0
+	MaxPriceAdjustmentPercent = 200.0
+)
+
+// PriceListInput holds the fields for a new price list
+type PriceListInput struct {
+	Name              string
+	AdjustmentPercent float64
+	StartsAt          time.Time // a start in the past means the list starts immediately
+	EndsAt            time.Time
+}
+
+// PriceListService manages technicians' scheduled price lists
+type PriceListService interface {
+	// CreatePriceList schedules a price list for the technician
+	// Price lists of one technician may not overlap, so at most one is in force at a time
+	CreatePriceList(technician *models.User, input PriceListInput) (*models.PriceList, error)
+
+	// ListPriceLists returns the technician's price lists, latest start first
+	ListPriceLists(technician *models.User) ([]models.PriceList, error)
+
+	// EndPriceList ends a price list that is in force now, keeping it for the orders quoted under it
+	EndPriceList(technician *models.User, priceListID string) (*models.PriceList, error)
+
+	// DeletePriceList removes a price list that has not started yet
+	DeletePriceList(technician *models.User, priceListID string) error
+}
+
+// DefaultPriceListService implements PriceListService on top of a PriceListRepository
+type DefaultPriceListService struct {
+	priceLists repositories.PriceListRepository
+	now        func() time.Time
+}
+
+var priceListServiceInstance PriceListService
+
+// NewPriceListService creates a price list service using the given repository
+func NewPriceListService(priceLists repositories.PriceListRepository) *DefaultPriceListService {
+	return &DefaultPriceListService{priceLists: priceLists, now: time.Now}
+}
+
+// GetPriceListService returns the configured price list service
+// When none has been set, a service over the current database connection is returned
+func GetPriceListService() PriceListService {
+	if priceListServiceInstance != nil {
+		return priceListServiceInstance
+	}
+	return NewPriceListService(repositories.NewPriceListRepository(config.GetDB()))
+}
+
+// SetPriceListService sets the price list service instance (primarily for testing)
+func SetPriceListService(service PriceListService) {
+	priceListServiceInstance = service
+}
+
+// CreatePriceList schedules a price list for the technician
+func (s *DefaultPriceListService) CreatePriceList(technician *models.User, input PriceListInput) (*models.PriceList, error) {
+	if technician.Role != RoleTechnician {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only technicians can manage price lists")
+	}
+
+	now := s.now().UTC()
+	startsAt := input.StartsAt.UTC()
+	if startsAt.Before(now) {
+		startsAt = now
+	}
+	endsAt := input.EndsAt.UTC()
+
+	details := make(map[string]string)
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		details["name"] = "is required"
+	}
+	if input.AdjustmentPercent == 0 || input.AdjustmentPercent < MinPriceAdjustmentPercent || input.AdjustmentPercent > MaxPriceAdjustmentPercent {
+		details["adjustment_percent"] = fmt.Sprintf("must be non-zero and between %g and %g", MinPriceAdjustmentPercent, MaxPriceAdjustmentPercent)
+	}
+	if !endsAt.After(startsAt) {
+		details["ends_at"] = "must be after starts_at and in the future"
+	}
+	if len(details) > 0 {
+		return nil, apierror.Validation("Invalid price list", details)
+	}
+
+	overlapping, err := s.priceLists.CountOverlapping(technician.ID, startsAt, endsAt)
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to check price lists").Wrap(err)
+	}
+	if overlapping > 0 {
+		return nil, apierror.Conflict("PRICE_LIST_OVERLAP", "Another of your price lists is in force during this period")
+	}
+
+	priceList := &models.PriceList{
+		TechnicianID:      technician.ID,
+		Name:              name,
+		AdjustmentPercent: input.AdjustmentPercent,
+		StartsAt:          startsAt,
+		EndsAt:            endsAt,
+	}
+	if err := s.priceLists.Create(priceList); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to create price list").Wrap(err)
+	}
+	return priceList, nil
+}
+
+// ListPriceLists returns the technician's price lists, latest start first
+func (s *DefaultPriceListService) ListPriceLists(technician *models.User) ([]models.PriceList, error) {
+	if technician.Role != RoleTechnician {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only technicians can manage price lists")
+	}
+
+	priceLists, err := s.priceLists.ListForTechnician(technician.ID)
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load price lists").Wrap(err)
+	}
+	return priceLists, nil
+}
+
+// EndPriceList ends a price list that is in force now, keeping it for the orders quoted under it
+func (s *DefaultPriceListService) EndPriceList(technician *models.User, priceListID string) (*models.PriceList, error) {
+	priceList, err := s.find(technician, priceListID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC()
+	if !priceList.InForce(now) {
+		return nil, apierror.Unprocessable("PRICE_LIST_NOT_IN_FORCE", "Only a price list in force can be ended; delete one that has not started")
+	}
+
+	priceList.EndsAt = now
+	if err := s.priceLists.Save(priceList); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to end price list").Wrap(err)
+	}
+	return priceList, nil
+}
+
+// DeletePriceList removes a price list that has not started yet
+func (s *DefaultPriceListService) DeletePriceList(technician *models.User, priceListID string) error {
+	priceList, err := s.find(technician, priceListID)
+	if err != nil {
+		return err
+	}
+
+	if !s.now().Before(priceList.StartsAt) {
+		return apierror.Unprocessable("PRICE_LIST_STARTED", "A price list that has started is kept for the orders quoted under it; end it instead")
+	}
+
+	if err := s.priceLists.Delete(priceList); err != nil {
+		return apierror.Internal("DATABASE_ERROR", "Failed to delete price list").Wrap(err)
+	}
+	return nil
+}
+
+// find loads one of the technician's price lists by its ID parameter
+func (s *DefaultPriceListService) find(technician *models.User, priceListID string) (*models.PriceList, error) {
+	if technician.Role != RoleTechnician {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only technicians can manage price lists")
+	}
+
+	id, err := strconv.ParseUint(priceListID, 10, 64)
+	if err != nil || id == 0 {
+		return nil, apierror.NotFound("PRICE_LIST_NOT_FOUND", "Price list not found")
+	}
+	priceList, err := s.priceLists.FindByID(technician.ID, uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apierror.NotFound("PRICE_LIST_NOT_FOUND", "Price list not found")
+		}
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load price list").Wrap(err)
+	}
+	return priceList, nil
+}
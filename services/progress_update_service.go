@@ -0,0 +1,197 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"gorm.io/gorm"
+)
+
+// MaxCaptionLength limits the length of a progress update caption in characters
+const MaxCaptionLength = 500
+
+// ProgressUpdateInput holds the validated fields for a new progress update
+type ProgressUpdateInput struct {
+	Caption    string
+	ImageS3Key string
+	Notify     bool // also tell the customer in the order conversation
+}
+
+// ProgressUpdateService manages the progress photos technicians post to an order's timeline
+type ProgressUpdateService interface {
+	// AuthorizePost checks that the technician may post updates to the order
+	// Controllers call it before uploading so that no image is stored for a forbidden request
+	AuthorizePost(technician *models.User, orderID string) error
+
+	// PostUpdate adds a progress photo to the order's timeline
+	PostUpdate(technician *models.User, orderID string, input ProgressUpdateInput) (*models.ProgressUpdate, error)
+
+	// ListUpdates returns an order's timeline, oldest first
+	ListUpdates(user *models.User, orderID string) ([]models.ProgressUpdate, error)
+
+	// DeleteUpdate removes a progress photo from the timeline and returns it so its image can be deleted
+	DeleteUpdate(technician *models.User, orderID string, updateID string) (*models.ProgressUpdate, error)
+}
+
+// DefaultProgressUpdateService implements ProgressUpdateService on top of the order and progress update repositories
+// Captions are shown to the customer, so they go through the message content filter
+type DefaultProgressUpdateService struct {
+	orders  repositories.OrderRepository
+	updates repositories.ProgressUpdateRepository
+	filter  MessageFilterService
+}
+
+var progressUpdateServiceInstance ProgressUpdateService
+
+// NewProgressUpdateService creates a progress update service using the given repositories and message filter
+func NewProgressUpdateService(orders repositories.OrderRepository, updates repositories.ProgressUpdateRepository, filter MessageFilterService) *DefaultProgressUpdateService {
+	return &DefaultProgressUpdateService{orders: orders, updates: updates, filter: filter}
+}
+
+// GetProgressUpdateService returns the configured progress update service
+// When none has been set, a service over the current database connection is returned
+func GetProgressUpdateService() ProgressUpdateService {
+	if progressUpdateServiceInstance != nil {
+		return progressUpdateServiceInstance
+	}
+	db := config.GetDB()
+	return NewProgressUpdateService(repositories.NewOrderRepository(db), repositories.NewProgressUpdateRepository(db), GetMessageFilterService())
+}
+
+// SetProgressUpdateService sets the progress update service instance (primarily for testing)
+func SetProgressUpdateService(service ProgressUpdateService) {
+	progressUpdateServiceInstance = service
+}
+
+// AuthorizePost checks that the technician may post updates to the order
+func (s *DefaultProgressUpdateService) AuthorizePost(technician *models.User, orderID string) error {
+	_, err := s.postableOrder(technician, orderID)
+	return err
+}
+
+// PostUpdate adds a progress photo to the order's timeline
+func (s *DefaultProgressUpdateService) PostUpdate(technician *models.User, orderID string, input ProgressUpdateInput) (*models.ProgressUpdate, error) {
+	order, err := s.postableOrder(technician, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	caption := strings.TrimSpace(input.Caption)
+	if utf8.RuneCountInString(caption) > MaxCaptionLength {
+		return nil, apierror.Validation("Caption is too long", map[string]interface{}{
+			"max_length": MaxCaptionLength,
+		})
+	}
+	if input.ImageS3Key == "" {
+		return nil, apierror.Validation("Image is required", nil)
+	}
+	if caption != "" {
+		if caption, err = s.filter.Screen(technician, order.ID, caption); err != nil {
+			return nil, err
+		}
+	}
+
+	update := &models.ProgressUpdate{
+		OrderID:    order.ID,
+		AuthorID:   technician.ID,
+		Caption:    caption,
+		ImageS3Key: input.ImageS3Key,
+	}
+
+	var notice *models.Message
+	if input.Notify {
+		text := "I posted a new progress photo of your order."
+		if caption != "" {
+			text = "New progress photo: " + caption
+		}
+		notice = &models.Message{OrderID: order.ID, SenderID: &technician.ID, Text: text}
+	}
+
+	if err := s.updates.Create(update, notice); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to post progress update").Wrap(err)
+	}
+	if notice != nil {
+		PublishEvent(MessageSent{Message: notice})
+	}
+
+	created, err := s.updates.FindByID(order.ID, update.ID)
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load progress update details").Wrap(err)
+	}
+	return created, nil
+}
+
+// ListUpdates returns an order's timeline, oldest first
+func (s *DefaultProgressUpdateService) ListUpdates(user *models.User, orderID string) ([]models.ProgressUpdate, error) {
+	order, err := findOrder(s.orders, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if !CanViewOrder(user, order) {
+		return nil, apierror.Forbidden("FORBIDDEN", "You do not have permission to view this order")
+	}
+
+	updates, err := s.updates.ListForOrder(order.ID)
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load progress updates").Wrap(err)
+	}
+	return updates, nil
+}
+
+// DeleteUpdate removes a progress photo from the timeline
+// The assigned technician curates the timeline, including photos posted before a hand-off
+func (s *DefaultProgressUpdateService) DeleteUpdate(technician *models.User, orderID string, updateID string) (*models.ProgressUpdate, error) {
+	order, err := findOrder(s.orders, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if technician.Role != RoleTechnician || !IsAssignedTo(order, technician) {
+		return nil, apierror.Forbidden("FORBIDDEN", "You can only remove progress updates from orders assigned to you")
+	}
+
+	id, err := strconv.ParseUint(updateID, 10, 64)
+	if err != nil || id == 0 {
+		return nil, apierror.NotFound("PROGRESS_UPDATE_NOT_FOUND", "Progress update not found")
+	}
+	update, err := s.updates.FindByID(order.ID, uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apierror.NotFound("PROGRESS_UPDATE_NOT_FOUND", "Progress update not found")
+		}
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load progress update").Wrap(err)
+	}
+
+	if err := s.updates.Delete(update); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to delete progress update").Wrap(err)
+	}
+	return update, nil
+}
+
+// postableOrder loads an order the technician may post progress updates to
+// Updates can be posted from acceptance until delivery
+func (s *DefaultProgressUpdateService) postableOrder(technician *models.User, orderID string) (*models.Order, error) {
+	if technician.Role != RoleTechnician {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only technicians can post progress updates")
+	}
+
+	order, err := findOrder(s.orders, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsAssignedTo(order, technician) {
+		return nil, apierror.Forbidden("FORBIDDEN", "You can only post progress updates to orders assigned to you")
+	}
+	for _, status := range BookedStatuses {
+		if order.Status == status {
+			return order, nil
+		}
+	}
+	return nil, apierror.Unprocessable("INVALID_STATE", "Progress updates can only be posted to accepted orders")
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// fakeProgressUpdateRepository is an in-memory ProgressUpdateRepository
+type fakeProgressUpdateRepository struct {
+	updates map[uint]*models.ProgressUpdate
+	notices []models.Message
+	nextID  uint
+}
+
+func newFakeProgressUpdateRepository() *fakeProgressUpdateRepository {
+	return &fakeProgressUpdateRepository{updates: make(map[uint]*models.ProgressUpdate), nextID: 1}
+}
+
+func (r *fakeProgressUpdateRepository) Create(update *models.ProgressUpdate, notice *models.Message) error {
+	update.ID = r.nextID
+	r.nextID++
+	stored := *update
+	r.updates[update.ID] = &stored
+	if notice != nil {
+		r.notices = append(r.notices, *notice)
+	}
+	return nil
+}
+
+func (r *fakeProgressUpdateRepository) FindByID(orderID, updateID uint) (*models.ProgressUpdate, error) {
+	update, ok := r.updates[updateID]
+	if !ok || update.OrderID != orderID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	found := *update
+	return &found, nil
+}
+
+func (r *fakeProgressUpdateRepository) ListForOrder(orderID uint) ([]models.ProgressUpdate, error) {
+	var updates []models.ProgressUpdate
+	for id := uint(1); id < r.nextID; id++ {
+		if update, ok := r.updates[id]; ok && update.OrderID == orderID {
+			updates = append(updates, *update)
+		}
+	}
+	return updates, nil
+}
+
+func (r *fakeProgressUpdateRepository) Delete(update *models.ProgressUpdate) error {
+	delete(r.updates, update.ID)
+	return nil
+}
+
+func TestProgressUpdateService_PostUpdate(t *testing.T) {
+	repo := newFakeOrderRepository(
+		models.Order{ID: 1, CustomerID: testCustomer.ID, Status: StatusInProduction, TechnicianID: uintPtr(testTechnician.ID)},
+		models.Order{ID: 2, CustomerID: testCustomer.ID, Status: StatusSubmitted},
+		models.Order{ID: 3, CustomerID: testCustomer.ID, Status: StatusRejected, TechnicianID: uintPtr(testTechnician.ID)},
+	)
+	updates := newFakeProgressUpdateRepository()
+	service := NewProgressUpdateService(repo, updates, NewMessageFilterService(&fakeMessageViolationRepository{}, nil))
+
+	assertAPIError(t, service.AuthorizePost(testCustomer, "1"), http.StatusForbidden, "FORBIDDEN")
+	assertAPIError(t, service.AuthorizePost(otherTech, "1"), http.StatusForbidden, "FORBIDDEN")
+	assertAPIError(t, service.AuthorizePost(testTechnician, "2"), http.StatusForbidden, "FORBIDDEN")
+	assertAPIError(t, service.AuthorizePost(testTechnician, "3"), http.StatusUnprocessableEntity, "INVALID_STATE")
+	assert.NoError(t, service.AuthorizePost(testTechnician, "1"))
+
+	_, err := service.PostUpdate(testTechnician, "1", ProgressUpdateInput{ImageS3Key: "uploads/a.png", Caption: strings.Repeat("a", MaxCaptionLength+1)})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+
+	_, err = service.PostUpdate(testTechnician, "1", ProgressUpdateInput{Caption: "No image"})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+
+	// An uncaptioned photo still gets a notice when requested
+	update, err := service.PostUpdate(testTechnician, "1", ProgressUpdateInput{ImageS3Key: "uploads/a.png", Caption: "  ", Notify: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "", update.Caption)
+	assert.Len(t, updates.notices, 1)
+	assert.Equal(t, "I posted a new progress photo of your order.", updates.notices[0].Text)
+
+	// Captions go through the content filter, on the timeline and in the notice
+	service.filter = NewMessageFilterService(&fakeMessageViolationRepository{}, NewMessageFilter([]string{"darn"}, config.MessageFilterMask, nil))
+	update, err = service.PostUpdate(testTechnician, "1", ProgressUpdateInput{ImageS3Key: "uploads/b.png", Caption: "Darn glitter everywhere", Notify: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "**** glitter everywhere", update.Caption)
+	assert.Equal(t, "New progress photo: **** glitter everywhere", updates.notices[1].Text)
+}
+
+func TestProgressUpdateService_ListAndDelete(t *testing.T) {
+	repo := newFakeOrderRepository(
+		models.Order{ID: 1, CustomerID: testCustomer.ID, Status: StatusShipped, TechnicianID: uintPtr(testTechnician.ID)},
+	)
+	updates := newFakeProgressUpdateRepository()
+	service := NewProgressUpdateService(repo, updates, NewMessageFilterService(&fakeMessageViolationRepository{}, nil))
+
+	update, err := service.PostUpdate(testTechnician, "1", ProgressUpdateInput{ImageS3Key: "uploads/a.png"})
+	assert.NoError(t, err)
+
+	timeline, err := service.ListUpdates(testCustomer, "1")
+	assert.NoError(t, err)
+	assert.Len(t, timeline, 1)
+
+	_, err = service.ListUpdates(otherCustomer, "1")
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+
+	_, err = service.DeleteUpdate(testCustomer, "1", uintString(update.ID))
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+
+	_, err = service.DeleteUpdate(testTechnician, "1", "99")
+	assertAPIError(t, err, http.StatusNotFound, "PROGRESS_UPDATE_NOT_FOUND")
+
+	deleted, err := service.DeleteUpdate(testTechnician, "1", uintString(update.ID))
+	assert.NoError(t, err)
+	assert.Equal(t, "uploads/a.png", deleted.ImageS3Key)
+	assert.Empty(t, updates.updates)
+}
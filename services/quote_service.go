@@ -0,0 +1,299 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"github.com/kendall-kelly/kendalls-nails-api/utils"
+	"gorm.io/gorm"
+)
+
+// QuoteStatuses lists every quote request status, for filtering the review queue
+var QuoteStatuses = []string{models.QuotePending, models.QuoteQuoted, models.QuoteDeclined, models.QuoteConverted}
+
+var captchaVerifier CaptchaVerifier
+
+// SetCaptchaVerifier sets the provider guest quote requests are checked with (nil switches the check off)
+// It is configured once at startup when CAPTCHA_SECRET is set
+func SetCaptchaVerifier(verifier CaptchaVerifier) {
+	captchaVerifier = verifier
+}
+
+// QuoteRequestInput holds the validated fields of a quote request submitted by a visitor
+type QuoteRequestInput struct {
+	GuestName    string
+	GuestEmail   string
+	Description  string
+	Quantity     int
+	ImageS3Key   *string
+	CaptchaToken string
+	RemoteIP     string
+}
+
+// QuoteReviewInput holds a technician's decision on a quote request
+type QuoteReviewInput struct {
+	Action string   // "quote" or "decline"
+	Price  *float64 // required when quoting
+	Note   string   // optional
+}
+
+// QuoteService handles quote requests from visitors without an account
+type QuoteService interface {
+	// SubmitQuote records a visitor's quote request once their CAPTCHA token checks out
+	SubmitQuote(input QuoteRequestInput) (*models.QuoteRequest, error)
+
+	// ListQuotes returns a page of quote requests with the given status, oldest first, with the total
+	ListQuotes(user *models.User, status string, page, limit int) ([]models.QuoteRequest, int64, error)
+
+	// ReviewQuote quotes a price for a request or declines it
+	ReviewQuote(technician *models.User, quoteID string, input QuoteReviewInput) (*models.QuoteRequest, error)
+
+	// ConvertQuote turns a quoted request into a submitted order of the visitor's new account, assigned to the technician
+	ConvertQuote(technician *models.User, quoteID string) (*models.Order, error)
+}
+
+// DefaultQuoteService implements QuoteService on top of the quote, user, and order repositories
+type DefaultQuoteService struct {
+	quotes   repositories.QuoteRepository
+	users    repositories.UserRepository
+	orders   repositories.OrderRepository
+	verifier CaptchaVerifier               // nil accepts requests without a CAPTCHA check
+	outbox   repositories.OutboxRepository // nil publishes events as soon as they happen
+	now      func() time.Time
+}
+
+var quoteServiceInstance QuoteService
+
+// NewQuoteService creates a quote service; pass a nil verifier to accept requests without a CAPTCHA check
+func NewQuoteService(quotes repositories.QuoteRepository, users repositories.UserRepository, orders repositories.OrderRepository, verifier CaptchaVerifier) *DefaultQuoteService {
+	return &DefaultQuoteService{quotes: quotes, users: users, orders: orders, verifier: verifier, now: time.Now}
+}
+
+// GetQuoteService returns the configured quote service
+// When none has been set, a service over the current database connection is returned
+func GetQuoteService() QuoteService {
+	return GetQuoteServiceFor(context.Background())
+}
+
+// GetQuoteServiceFor returns the configured quote service, running in the request transaction ctx carries, if any
+// The order a converted quote becomes and the event announcing it are saved in that transaction
+func GetQuoteServiceFor(ctx context.Context) QuoteService {
+	if quoteServiceInstance != nil {
+		return quoteServiceInstance
+	}
+	db := config.GetDBFor(ctx)
+	service := NewQuoteService(repositories.NewQuoteRepository(db), repositories.NewUserRepository(db), repositories.NewOrderRepository(db), captchaVerifier)
+	service.outbox = repositories.NewOutboxRepository(db)
+	return service
+}
+
+// SetQuoteService sets the quote service instance (primarily for testing)
+func SetQuoteService(service QuoteService) {
+	quoteServiceInstance = service
+}
+
+// SubmitQuote records a visitor's quote request once their CAPTCHA token checks out
+func (s *DefaultQuoteService) SubmitQuote(input QuoteRequestInput) (*models.QuoteRequest, error) {
+	if err := s.checkCaptcha(input.CaptchaToken, input.RemoteIP); err != nil {
+		return nil, err
+	}
+
+	quote := &models.QuoteRequest{
+		GuestName:   strings.TrimSpace(input.GuestName),
+		GuestEmail:  utils.NormalizeEmail(input.GuestEmail),
+		Description: input.Description,
+		Quantity:    input.Quantity,
+		ImageS3Key:  input.ImageS3Key,
+		Status:      models.QuotePending,
+		Currency:    studioCurrency(),
+	}
+	if err := s.quotes.Create(quote); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to save quote request").Wrap(err)
+	}
+	return quote, nil
+}
+
+// checkCaptcha verifies a visitor's CAPTCHA token, when a verifier is configured
+func (s *DefaultQuoteService) checkCaptcha(token, remoteIP string) error {
+	if s.verifier == nil {
+		return nil
+	}
+	failed := apierror.BadRequest("CAPTCHA_FAILED", "CAPTCHA verification failed; please try again")
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return failed
+	}
+	ok, err := s.verifier.Verify(token, remoteIP)
+	if err != nil {
+		log.Printf("Failed to verify CAPTCHA: %v", err)
+		return apierror.New(http.StatusServiceUnavailable, "CAPTCHA_UNAVAILABLE", "CAPTCHA verification is unavailable; please try again later").Wrap(err)
+	}
+	if !ok {
+		return failed
+	}
+	return nil
+}
+
+// ListQuotes returns a page of quote requests with the given status, oldest first, with the total
+func (s *DefaultQuoteService) ListQuotes(user *models.User, status string, page, limit int) ([]models.QuoteRequest, int64, error) {
+	if user.Role != RoleTechnician && user.Role != RoleAdmin {
+		return nil, 0, apierror.Forbidden("FORBIDDEN", "Only technicians can view quote requests")
+	}
+	if status != "" && !oneOf(status, QuoteStatuses) {
+		return nil, 0, apierror.Validation("Invalid status filter", map[string]string{
+			"status": "must be one of: " + strings.Join(QuoteStatuses, ", "),
+		})
+	}
+
+	quotes, total, err := s.quotes.List(status, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, apierror.Internal("DATABASE_ERROR", "Failed to fetch quote requests").Wrap(err)
+	}
+	return quotes, total, nil
+}
+
+// ReviewQuote quotes a price for a request or declines it
+// A quoted request may be re-quoted by the same technician until it is converted
+func (s *DefaultQuoteService) ReviewQuote(technician *models.User, quoteID string, input QuoteReviewInput) (*models.QuoteRequest, error) {
+	if technician.Role != RoleTechnician {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only technicians can review quote requests")
+	}
+
+	quote, err := s.find(quoteID)
+	if err != nil {
+		return nil, err
+	}
+	if quote.Status != models.QuotePending && quote.Status != models.QuoteQuoted {
+		return nil, apierror.Unprocessable("INVALID_STATE", "Quote request has already been "+quote.Status)
+	}
+	if quote.TechnicianID != nil && *quote.TechnicianID != technician.ID {
+		return nil, apierror.Forbidden("FORBIDDEN", "Quote request is being handled by another technician")
+	}
+
+	note := strings.TrimSpace(input.Note)
+	switch input.Action {
+	case "quote":
+		if input.Price == nil {
+			return nil, apierror.Validation("Price is required when quoting", nil)
+		}
+		if *input.Price <= 0 {
+			return nil, apierror.Validation("Price must be greater than zero", nil)
+		}
+		if err := requireWholeCents("Price", *input.Price); err != nil {
+			return nil, err
+		}
+		price := *input.Price
+		quote.Status = models.QuoteQuoted
+		quote.QuotedPrice = &price
+	case "decline":
+		if note == "" {
+			return nil, apierror.Validation("A note is required when declining a quote request", nil)
+		}
+		quote.Status = models.QuoteDeclined
+		quote.QuotedPrice = nil
+	default:
+		return nil, apierror.Validation("Action must be 'quote' or 'decline'", nil)
+	}
+
+	reviewedAt := s.now()
+	quote.TechnicianID = &technician.ID
+	quote.ReviewedAt = &reviewedAt
+	quote.TechnicianNote = nil
+	if note != "" {
+		quote.TechnicianNote = &note
+	}
+	if err := s.quotes.Save(quote); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to save quote request").Wrap(err)
+	}
+	PublishEvent(QuoteReviewed{Quote: quote})
+	return quote, nil
+}
+
+// ConvertQuote turns a quoted request into a submitted order of the visitor's new account, assigned to the technician
+// The order carries the quoted price, which the technician confirms when accepting it as usual
+func (s *DefaultQuoteService) ConvertQuote(technician *models.User, quoteID string) (*models.Order, error) {
+	if technician.Role != RoleTechnician {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only technicians can convert quote requests")
+	}
+
+	quote, err := s.find(quoteID)
+	if err != nil {
+		return nil, err
+	}
+	converted := apierror.Conflict("QUOTE_ALREADY_CONVERTED", "Quote request has already been converted to an order")
+	if quote.Status == models.QuoteConverted {
+		return nil, converted
+	}
+	if quote.Status != models.QuoteQuoted {
+		return nil, apierror.Unprocessable("INVALID_STATE", "Only quoted requests can be converted to orders")
+	}
+	if quote.TechnicianID == nil || *quote.TechnicianID != technician.ID {
+		return nil, apierror.Forbidden("FORBIDDEN", "Quote request is being handled by another technician")
+	}
+
+	customer, err := s.users.FindByEmail(quote.GuestEmail)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apierror.Unprocessable("GUEST_NOT_REGISTERED", "The visitor has not signed up with "+quote.GuestEmail+" yet")
+		}
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to look up the visitor's account").Wrap(err)
+	}
+	if customer.Role != RoleCustomer {
+		return nil, apierror.Unprocessable("GUEST_NOT_REGISTERED", "The account for "+quote.GuestEmail+" is not a customer account")
+	}
+
+	price := *quote.QuotedPrice
+	technicianID := technician.ID
+	order := &models.Order{
+		Description:  quote.Description,
+		Quantity:     quote.Quantity,
+		Status:       StatusSubmitted,
+		Price:        &price,
+		Currency:     quote.Currency,
+		ImageS3Key:   quote.ImageS3Key,
+		QuoteID:      &quote.ID,
+		CustomerID:   customer.ID,
+		TechnicianID: &technicianID,
+	}
+	if err := s.quotes.Convert(quote, order); err != nil {
+		if errors.Is(err, repositories.ErrQuoteConverted) {
+			return nil, converted
+		}
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to convert quote request").Wrap(err)
+	}
+
+	created, err := reloadOrder(s.orders, order.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := publishThrough(s.outbox, OrderCreated{Order: created}, s.now()); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// find loads a quote request by its ID path parameter
+func (s *DefaultQuoteService) find(quoteID string) (*models.QuoteRequest, error) {
+	notFound := apierror.NotFound("QUOTE_NOT_FOUND", "Quote request not found")
+	id, err := strconv.ParseUint(quoteID, 10, 64)
+	if err != nil || id == 0 {
+		return nil, notFound
+	}
+	quote, err := s.quotes.FindByID(uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, notFound
+		}
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load quote request").Wrap(err)
+	}
+	return quote, nil
+}
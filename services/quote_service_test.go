@@ -0,0 +1,203 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// fakeQuoteRepository is an in-memory QuoteRepository that creates orders in the fake order repository
+type fakeQuoteRepository struct {
+	quotes []*models.QuoteRequest
+	orders *fakeOrderRepository
+}
+
+func (r *fakeQuoteRepository) Create(quote *models.QuoteRequest) error {
+	quote.ID = uint(len(r.quotes) + 1)
+	stored := *quote
+	r.quotes = append(r.quotes, &stored)
+	return nil
+}
+
+func (r *fakeQuoteRepository) Save(quote *models.QuoteRequest) error {
+	stored := *quote
+	r.quotes[quote.ID-1] = &stored
+	return nil
+}
+
+func (r *fakeQuoteRepository) FindByID(id uint) (*models.QuoteRequest, error) {
+	if id == 0 || int(id) > len(r.quotes) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *r.quotes[id-1]
+	return &copied, nil
+}
+
+func (r *fakeQuoteRepository) List(status string, limit, offset int) ([]models.QuoteRequest, int64, error) {
+	var quotes []models.QuoteRequest
+	for _, quote := range r.quotes {
+		if status == "" || quote.Status == status {
+			quotes = append(quotes, *quote)
+		}
+	}
+	return quotes, int64(len(quotes)), nil
+}
+
+func (r *fakeQuoteRepository) Convert(quote *models.QuoteRequest, order *models.Order) error {
+	if r.quotes[quote.ID-1].OrderID != nil {
+		return repositories.ErrQuoteConverted
+	}
+	if err := r.orders.Create(order); err != nil {
+		return err
+	}
+	quote.Status = models.QuoteConverted
+	quote.OrderID = &order.ID
+	return r.Save(quote)
+}
+
+// fakeCaptchaVerifier accepts one token, or fails every check with err
+type fakeCaptchaVerifier struct {
+	valid string
+	err   error
+}
+
+func (v *fakeCaptchaVerifier) Verify(token, remoteIP string) (bool, error) {
+	if v.err != nil {
+		return false, v.err
+	}
+	return token == v.valid, nil
+}
+
+func TestQuoteService(t *testing.T) {
+	technician := &models.User{ID: 2, Role: RoleTechnician}
+	otherTechnician := &models.User{ID: 3, Role: RoleTechnician}
+	customer := &models.User{ID: 4, Role: RoleCustomer, Email: "guest@example.com"}
+
+	orders := newFakeOrderRepository()
+	quotes := &fakeQuoteRepository{orders: orders}
+	users := newFakeUserRepository(technician, otherTechnician)
+	verifier := &fakeCaptchaVerifier{valid: "solved"}
+	service := NewQuoteService(quotes, users, orders, verifier)
+
+	input := QuoteRequestInput{GuestName: " Ada ", GuestEmail: "Guest@Example.com", Description: "Ombre coffin", Quantity: 2, CaptchaToken: "solved"}
+
+	// Requests without a solved CAPTCHA are refused
+	_, err := service.SubmitQuote(QuoteRequestInput{GuestEmail: "guest@example.com", Description: "Pink", Quantity: 1})
+	assertAPIError(t, err, http.StatusBadRequest, "CAPTCHA_FAILED")
+	_, err = service.SubmitQuote(QuoteRequestInput{GuestEmail: "guest@example.com", Description: "Pink", Quantity: 1, CaptchaToken: "bot"})
+	assertAPIError(t, err, http.StatusBadRequest, "CAPTCHA_FAILED")
+
+	quote, err := service.SubmitQuote(input)
+	require.NoError(t, err)
+	assert.Equal(t, models.QuotePending, quote.Status)
+	assert.Equal(t, "Ada", quote.GuestName)
+	assert.Equal(t, "guest@example.com", quote.GuestEmail)
+
+	_, _, err = service.ListQuotes(customer, "", 1, 20)
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+	_, _, err = service.ListQuotes(technician, "sent", 1, 20)
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+	pending, total, err := service.ListQuotes(technician, models.QuotePending, 1, 20)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, quote.ID, pending[0].ID)
+
+	// A quote can only be converted once it is priced
+	_, err = service.ConvertQuote(technician, uintString(quote.ID))
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "INVALID_STATE")
+
+	_, err = service.ReviewQuote(technician, uintString(quote.ID), QuoteReviewInput{Action: "quote"})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+	_, err = service.ReviewQuote(technician, uintString(quote.ID), QuoteReviewInput{Action: "quote", Price: float64Ptr(40.005)})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+	quoted, err := service.ReviewQuote(technician, uintString(quote.ID), QuoteReviewInput{Action: "quote", Price: float64Ptr(55), Note: "Includes a top coat"})
+	require.NoError(t, err)
+	assert.Equal(t, models.QuoteQuoted, quoted.Status)
+	assert.Equal(t, 55.0, *quoted.QuotedPrice)
+	assert.Equal(t, technician.ID, *quoted.TechnicianID)
+
+	_, err = service.ReviewQuote(otherTechnician, uintString(quote.ID), QuoteReviewInput{Action: "decline", Note: "Too busy"})
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+	_, err = service.ConvertQuote(otherTechnician, uintString(quote.ID))
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+
+	// The visitor has to sign up before the quote becomes their order
+	_, err = service.ConvertQuote(technician, uintString(quote.ID))
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "GUEST_NOT_REGISTERED")
+
+	users.users[customer.ID] = customer
+	order, err := service.ConvertQuote(technician, uintString(quote.ID))
+	require.NoError(t, err)
+	assert.Equal(t, customer.ID, order.CustomerID)
+	assert.Equal(t, technician.ID, *order.TechnicianID)
+	assert.Equal(t, StatusSubmitted, order.Status)
+	assert.Equal(t, 55.0, *order.Price)
+	assert.Equal(t, quote.ID, *order.QuoteID)
+
+	_, err = service.ConvertQuote(technician, uintString(quote.ID))
+	assertAPIError(t, err, http.StatusConflict, "QUOTE_ALREADY_CONVERTED")
+	_, err = service.ReviewQuote(technician, uintString(quote.ID), QuoteReviewInput{Action: "quote", Price: float64Ptr(60)})
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "INVALID_STATE")
+	_, err = service.ConvertQuote(technician, "999")
+	assertAPIError(t, err, http.StatusNotFound, "QUOTE_NOT_FOUND")
+
+	t.Run("declines need a note", func(t *testing.T) {
+		declining, err := service.SubmitQuote(input)
+		require.NoError(t, err)
+		_, err = service.ReviewQuote(technician, uintString(declining.ID), QuoteReviewInput{Action: "decline"})
+		assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+		declined, err := service.ReviewQuote(technician, uintString(declining.ID), QuoteReviewInput{Action: "decline", Note: "We don't do 3D art"})
+		require.NoError(t, err)
+		assert.Equal(t, models.QuoteDeclined, declined.Status)
+		assert.Nil(t, declined.QuotedPrice)
+	})
+
+	t.Run("an unreachable CAPTCHA provider is reported as unavailable", func(t *testing.T) {
+		verifier.err = errors.New("connection refused")
+		defer func() { verifier.err = nil }()
+		_, err := service.SubmitQuote(input)
+		assertAPIError(t, err, http.StatusServiceUnavailable, "CAPTCHA_UNAVAILABLE")
+	})
+
+	t.Run("without a verifier no CAPTCHA is needed", func(t *testing.T) {
+		_, err := NewQuoteService(quotes, users, orders, nil).SubmitQuote(QuoteRequestInput{GuestEmail: "guest@example.com", Description: "Pink", Quantity: 1})
+		assert.NoError(t, err)
+	})
+}
+
+func TestHTTPCaptchaVerifier(t *testing.T) {
+	var form map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		form = map[string]string{"secret": r.PostForm.Get("secret"), "response": r.PostForm.Get("response"), "remoteip": r.PostForm.Get("remoteip")}
+		if r.PostForm.Get("response") == "down" {
+			http.Error(w, "overloaded", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"success": %t}`, r.PostForm.Get("response") == "solved")
+	}))
+	defer server.Close()
+
+	verifier := NewHTTPCaptchaVerifier(&config.Config{CaptchaVerifyURL: server.URL, CaptchaSecret: "shh"})
+
+	ok, err := verifier.Verify("solved", "203.0.113.7")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, map[string]string{"secret": "shh", "response": "solved", "remoteip": "203.0.113.7"}, form)
+
+	ok, err = verifier.Verify("bot", "")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = verifier.Verify("down", "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,232 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/jobs"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+)
+
+// Recommendation settings
+const (
+	RecommendationRefreshInterval = 6 * time.Hour // how often the recommendations job recomputes every customer's suggestions
+	MaxRecommendations            = 10            // suggestions kept per customer
+	recommendationCandidateLimit  = 1000          // most recently delivered orders considered, including the customer's own
+	minRecommendationScore        = 0.1           // designs less similar than this are never suggested
+)
+
+// RecommendedDesign is a delivered design suggested to a customer
+// Only the design is shared, never who ordered it
+type RecommendedDesign struct {
+	OrderID     uint      `json:"order_id"`
+	Description string    `json:"description"`
+	AddOns      []string  `json:"add_ons"`
+	ImageS3Key  *string   `json:"-"`
+	ImageURL    *string   `json:"image_url,omitempty"` // computed by the controller, presigned URL for the image
+	Score       float64   `json:"score"`               // similarity to the customer's delivered orders, 0 to 1
+	ComputedAt  time.Time `json:"computed_at"`
+}
+
+// RecommendationService suggests designs similar to what customers have ordered before
+type RecommendationService interface {
+	// ListRecommendations returns the customer's precomputed suggestions, best first (customers only)
+	ListRecommendations(customer *models.User) ([]RecommendedDesign, error)
+
+	// Precompute recalculates every customer's suggestions from the delivered orders and replaces the stored ones
+	// It returns how many suggestions were stored
+	Precompute() (int, error)
+}
+
+// DefaultRecommendationService implements RecommendationService on top of a RecommendationRepository
+// Designs are compared by the words of their descriptions and the add-ons they were quoted with
+type DefaultRecommendationService struct {
+	recommendations repositories.RecommendationRepository
+}
+
+var recommendationServiceInstance RecommendationService
+
+// NewRecommendationService creates a recommendation service using the given repository
+func NewRecommendationService(recommendations repositories.RecommendationRepository) *DefaultRecommendationService {
+	return &DefaultRecommendationService{recommendations: recommendations}
+}
+
+// GetRecommendationService returns the configured recommendation service
+// When none has been set, a service over the current database connection is returned
+func GetRecommendationService() RecommendationService {
+	if recommendationServiceInstance != nil {
+		return recommendationServiceInstance
+	}
+	return NewRecommendationService(repositories.NewRecommendationRepository(config.GetDB()))
+}
+
+// SetRecommendationService sets the recommendation service instance (primarily for testing)
+func SetRecommendationService(service RecommendationService) {
+	recommendationServiceInstance = service
+}
+
+// RecommendationJob returns the background job that precomputes recommendations
+func RecommendationJob(service RecommendationService) jobs.Job {
+	return jobs.Job{
+		Name:     "recommendations",
+		Interval: RecommendationRefreshInterval,
+		Run: func(ctx context.Context) error {
+			stored, err := service.Precompute()
+			if err == nil {
+				log.Printf("Precomputed %d design recommendations", stored)
+			}
+			return err
+		},
+	}
+}
+
+// ListRecommendations returns the customer's precomputed suggestions, best first (customers only)
+// Customers with no delivered orders, or before the job first runs, get an empty list
+func (s *DefaultRecommendationService) ListRecommendations(customer *models.User) ([]RecommendedDesign, error) {
+	if customer.Role != RoleCustomer {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only customers can get design recommendations")
+	}
+
+	recommendations, err := s.recommendations.ListForCustomer(customer.ID)
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load recommendations").Wrap(err)
+	}
+
+	designs := make([]RecommendedDesign, len(recommendations))
+	for i, recommendation := range recommendations {
+		order := recommendation.Order
+		designs[i] = RecommendedDesign{
+			OrderID:     order.ID,
+			Description: order.Description,
+			AddOns:      addOnNames(order.LineItems),
+			ImageS3Key:  order.ImageS3Key,
+			Score:       recommendation.Score,
+			ComputedAt:  recommendation.CreatedAt,
+		}
+	}
+	return designs, nil
+}
+
+// Precompute recalculates every customer's suggestions from the delivered orders and replaces the stored ones
+// A design scores by its best Jaccard similarity to any of the customer's own delivered orders
+func (s *DefaultRecommendationService) Precompute() (int, error) {
+	delivered, err := s.recommendations.ListByStatus(StatusDelivered, recommendationCandidateLimit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load delivered orders: %w", err)
+	}
+
+	features := make(map[uint]map[string]bool, len(delivered))
+	byCustomer := make(map[uint][]models.Order)
+	for _, order := range delivered {
+		features[order.ID] = designFeatures(order)
+		byCustomer[order.CustomerID] = append(byCustomer[order.CustomerID], order)
+	}
+
+	var recommendations []models.Recommendation
+	for customerID, own := range byCustomer {
+		// Designs the customer already has, directly or by reordering, are not suggested again
+		owned := make(map[uint]bool, len(own))
+		for _, order := range own {
+			owned[order.ID] = true
+			if order.OriginalOrderID != nil {
+				owned[*order.OriginalOrderID] = true
+			}
+		}
+
+		var scored []models.Recommendation
+		for _, candidate := range delivered {
+			// Reorders repeat the design of their original, which is a candidate of its own
+			if owned[candidate.ID] || candidate.CustomerID == customerID || candidate.OriginalOrderID != nil {
+				continue
+			}
+			var best float64
+			for _, order := range own {
+				best = max(best, jaccard(features[order.ID], features[candidate.ID]))
+			}
+			if best >= minRecommendationScore {
+				scored = append(scored, models.Recommendation{CustomerID: customerID, OrderID: candidate.ID, Score: roundScore(best)})
+			}
+		}
+
+		// Ties go to the more recently delivered design, which comes first in delivered
+		sort.SliceStable(scored, func(i, j int) bool { return scored[i].Score > scored[j].Score })
+		if len(scored) > MaxRecommendations {
+			scored = scored[:MaxRecommendations]
+		}
+		for i := range scored {
+			scored[i].Position = i + 1
+		}
+		recommendations = append(recommendations, scored...)
+	}
+
+	if err := s.recommendations.ReplaceAll(recommendations); err != nil {
+		return 0, fmt.Errorf("failed to store recommendations: %w", err)
+	}
+	return len(recommendations), nil
+}
+
+// descriptionStopWords are common words that say nothing about a design
+var descriptionStopWords = map[string]bool{
+	"and": true, "the": true, "with": true, "for": true, "please": true, "want": true, "would": true, "like": true,
+	"some": true, "each": true, "all": true, "nail": true, "nails": true, "set": true, "sets": true, "design": true,
+	"designs": true,
+}
+
+// designFeatures describes an order's design as a set of description words and add-on IDs
+// Words of two letters or fewer are ignored along with the stop words
+func designFeatures(order models.Order) map[string]bool {
+	features := make(map[string]bool)
+	words := strings.FieldsFunc(strings.ToLower(order.Description), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for _, word := range words {
+		if len(word) > 2 && !descriptionStopWords[word] {
+			features["word:"+word] = true
+		}
+	}
+	for _, item := range order.LineItems {
+		if item.Kind == models.LineItemAddOn && item.AddOnID != nil {
+			features[fmt.Sprintf("add_on:%d", *item.AddOnID)] = true
+		}
+	}
+	return features
+}
+
+// jaccard returns the size of the intersection of two sets over the size of their union
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for feature := range a {
+		if b[feature] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// roundScore keeps three decimal places of a similarity score
+func roundScore(score float64) float64 {
+	return math.Round(score*1000) / 1000
+}
+
+// addOnNames lists the add-ons an order was quoted with
+func addOnNames(lineItems []models.OrderLineItem) []string {
+	names := []string{}
+	for _, item := range lineItems {
+		if item.Kind == models.LineItemAddOn {
+			names = append(names, item.Description)
+		}
+	}
+	return names
+}
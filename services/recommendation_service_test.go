@@ -0,0 +1,92 @@
+package services
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRecommendationRepository is an in-memory RecommendationRepository
+type fakeRecommendationRepository struct {
+	orders          []models.Order // newest first
+	recommendations []models.Recommendation
+}
+
+func (r *fakeRecommendationRepository) ListByStatus(status string, limit int) ([]models.Order, error) {
+	var orders []models.Order
+	for _, order := range r.orders {
+		if order.Status == status && len(orders) < limit {
+			orders = append(orders, order)
+		}
+	}
+	return orders, nil
+}
+
+func (r *fakeRecommendationRepository) ReplaceAll(recommendations []models.Recommendation) error {
+	r.recommendations = recommendations
+	return nil
+}
+
+func (r *fakeRecommendationRepository) ListForCustomer(customerID uint) ([]models.Recommendation, error) {
+	var recommendations []models.Recommendation
+	for _, recommendation := range r.recommendations {
+		if recommendation.CustomerID != customerID {
+			continue
+		}
+		for _, order := range r.orders {
+			if order.ID == recommendation.OrderID {
+				recommendation.Order = order
+			}
+		}
+		recommendations = append(recommendations, recommendation)
+	}
+	return recommendations, nil
+}
+
+func TestRecommendationService_Precompute(t *testing.T) {
+	charm := uint(7)
+	withCharm := []models.OrderLineItem{{Kind: models.LineItemAddOn, AddOnID: &charm, Description: "Gold charm"}}
+	repo := &fakeRecommendationRepository{orders: []models.Order{
+		{ID: 1, CustomerID: testCustomer.ID, Status: StatusDelivered, Description: "Pink almond nails with gold flakes", LineItems: withCharm},
+		{ID: 2, CustomerID: otherCustomer.ID, Status: StatusDelivered, Description: "Pink almond set with gold charm", LineItems: withCharm},
+		{ID: 3, CustomerID: otherCustomer.ID, Status: StatusDelivered, Description: "Black coffin nails with chrome"},
+		{ID: 4, CustomerID: otherCustomer.ID, Status: StatusDelivered, Description: "Pink almond nails with gold flakes", OriginalOrderID: uintPtr(1)},
+		{ID: 5, CustomerID: otherCustomer.ID, Status: StatusShipped, Description: "Pink almond nails with gold flakes"},
+		{ID: 6, CustomerID: 5, Status: StatusDelivered, Description: "Short pink almond"},
+	}}
+	service := NewRecommendationService(repo)
+
+	stored, err := service.Precompute()
+	assert.NoError(t, err)
+	assert.Equal(t, len(repo.recommendations), stored)
+
+	// Dissimilar designs, reorders, undelivered orders, and the customer's own orders are left out
+	designs, err := service.ListRecommendations(testCustomer)
+	assert.NoError(t, err)
+	if assert.Len(t, designs, 2) {
+		assert.Equal(t, uint(2), designs[0].OrderID)
+		assert.Equal(t, []string{"Gold charm"}, designs[0].AddOns)
+		assert.Equal(t, uint(6), designs[1].OrderID)
+		assert.Greater(t, designs[0].Score, designs[1].Score)
+	}
+
+	// The other customer is not offered the design they reordered
+	designs, err = service.ListRecommendations(otherCustomer)
+	assert.NoError(t, err)
+	for _, design := range designs {
+		assert.NotEqual(t, uint(1), design.OrderID)
+	}
+
+	_, err = service.ListRecommendations(testTechnician)
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+}
+
+func TestJaccard(t *testing.T) {
+	a := map[string]bool{"word:pink": true, "word:almond": true}
+	b := map[string]bool{"word:pink": true, "word:coffin": true}
+	assert.InDelta(t, 1.0/3, jaccard(a, b), 0.0001)
+	assert.Equal(t, 1.0, jaccard(a, a))
+	assert.Equal(t, 0.0, jaccard(a, map[string]bool{}))
+}
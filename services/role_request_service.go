@@ -0,0 +1,209 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"gorm.io/gorm"
+)
+
+// Role request limits
+const (
+	MaxRoleRequestMessageLength = 2000
+	MaxPortfolioURLs            = 10
+)
+
+// RoleRequestStatuses lists every role request status, for filtering the review queue
+var RoleRequestStatuses = []string{models.RoleRequestPending, models.RoleRequestApproved, models.RoleRequestRejected}
+
+// RoleRequestDecisions lists the statuses an admin can decide a role request with
+var RoleRequestDecisions = []string{models.RoleRequestApproved, models.RoleRequestRejected}
+
+// RoleRequestInput holds a customer's application to become a technician
+type RoleRequestInput struct {
+	Message       string
+	PortfolioURLs []string
+}
+
+// RoleRequestService lets customers apply to become technicians and admins decide the applications
+type RoleRequestService interface {
+	// SubmitRequest records a customer's application to become a technician
+	SubmitRequest(customer *models.User, input RoleRequestInput) (*models.RoleRequest, error)
+
+	// ListMyRequests returns the user's role requests, newest first
+	ListMyRequests(user *models.User) ([]models.RoleRequest, error)
+
+	// ListRequests returns a page of role requests with the given status, oldest first, with the total (admins only)
+	ListRequests(admin *models.User, status string, page, limit int) ([]models.RoleRequest, int64, error)
+
+	// ReviewRequest approves or rejects a pending role request (admins only)
+	// Approving changes the applicant's role locally and in Auth0; if that fails the request stays pending
+	ReviewRequest(admin *models.User, requestID string, status string, note string) (*models.RoleRequest, error)
+}
+
+// DefaultRoleRequestService implements RoleRequestService on top of a RoleRequestRepository and the RoleService
+type DefaultRoleRequestService struct {
+	requests repositories.RoleRequestRepository
+	roles    RoleService
+}
+
+var roleRequestServiceInstance RoleRequestService
+
+// NewRoleRequestService creates a role request service that changes roles through the given role service
+func NewRoleRequestService(requests repositories.RoleRequestRepository, roles RoleService) *DefaultRoleRequestService {
+	return &DefaultRoleRequestService{requests: requests, roles: roles}
+}
+
+// GetRoleRequestService returns the configured role request service
+// When none has been set, a service over the current database connection is returned
+func GetRoleRequestService() RoleRequestService {
+	if roleRequestServiceInstance != nil {
+		return roleRequestServiceInstance
+	}
+	return NewRoleRequestService(repositories.NewRoleRequestRepository(config.GetDB()), GetRoleService())
+}
+
+// SetRoleRequestService sets the role request service instance (primarily for testing)
+func SetRoleRequestService(service RoleRequestService) {
+	roleRequestServiceInstance = service
+}
+
+// SubmitRequest records a customer's application to become a technician
+// A customer can have one pending request at a time; after a rejection they may apply again
+func (s *DefaultRoleRequestService) SubmitRequest(customer *models.User, input RoleRequestInput) (*models.RoleRequest, error) {
+	if customer.Role != RoleCustomer {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only customers can apply to become technicians")
+	}
+
+	message := strings.TrimSpace(input.Message)
+	if message == "" || utf8.RuneCountInString(message) > MaxRoleRequestMessageLength {
+		return nil, apierror.Validation("Invalid role request", map[string]string{
+			"message": fmt.Sprintf("is required and must be at most %d characters", MaxRoleRequestMessageLength),
+		})
+	}
+	if len(input.PortfolioURLs) == 0 || len(input.PortfolioURLs) > MaxPortfolioURLs {
+		return nil, apierror.Validation("Invalid role request", map[string]string{
+			"portfolio_urls": fmt.Sprintf("must contain between 1 and %d links", MaxPortfolioURLs),
+		})
+	}
+	links := make([]string, len(input.PortfolioURLs))
+	for i, link := range input.PortfolioURLs {
+		link = strings.TrimSpace(link)
+		if parsed, err := url.Parse(link); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, apierror.Validation("Invalid role request", map[string]string{
+				fmt.Sprintf("portfolio_urls[%d]", i): "must be an http or https URL",
+			})
+		}
+		links[i] = link
+	}
+
+	if _, err := s.requests.FindPending(customer.ID); err == nil {
+		return nil, apierror.Conflict("ROLE_REQUEST_PENDING", "You already have a request waiting for review")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to check role requests").Wrap(err)
+	}
+
+	request := &models.RoleRequest{
+		UserID:        customer.ID,
+		RequestedRole: RoleTechnician,
+		Message:       message,
+		PortfolioURLs: links,
+		Status:        models.RoleRequestPending,
+	}
+	if err := s.requests.Create(request); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to save role request").Wrap(err)
+	}
+	return request, nil
+}
+
+// ListMyRequests returns the user's role requests, newest first
+func (s *DefaultRoleRequestService) ListMyRequests(user *models.User) ([]models.RoleRequest, error) {
+	requests, err := s.requests.ListByUser(user.ID)
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to fetch role requests").Wrap(err)
+	}
+	return requests, nil
+}
+
+// ListRequests returns a page of role requests with the given status, oldest first, with the total (admins only)
+func (s *DefaultRoleRequestService) ListRequests(admin *models.User, status string, page, limit int) ([]models.RoleRequest, int64, error) {
+	if admin.Role != RoleAdmin {
+		return nil, 0, apierror.Forbidden("FORBIDDEN", "Only admins can review role requests")
+	}
+	if status != "" && !oneOf(status, RoleRequestStatuses) {
+		return nil, 0, apierror.Validation("Invalid status filter", map[string]string{
+			"status": "must be one of: " + strings.Join(RoleRequestStatuses, ", "),
+		})
+	}
+
+	requests, total, err := s.requests.List(status, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, apierror.Internal("DATABASE_ERROR", "Failed to fetch role requests").Wrap(err)
+	}
+	return requests, total, nil
+}
+
+// ReviewRequest approves or rejects a pending role request (admins only)
+// The request is decided before the role changes, so two admins cannot both act on it
+func (s *DefaultRoleRequestService) ReviewRequest(admin *models.User, requestID string, status string, note string) (*models.RoleRequest, error) {
+	if admin.Role != RoleAdmin {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only admins can review role requests")
+	}
+	if !oneOf(status, RoleRequestDecisions) {
+		return nil, apierror.Validation("Invalid request data", map[string]string{
+			"status": "must be one of: " + strings.Join(RoleRequestDecisions, ", "),
+		})
+	}
+	reviewNote := optionalString(note)
+	if status == models.RoleRequestRejected && reviewNote == nil {
+		return nil, apierror.Validation("A note is required when rejecting a role request", map[string]string{
+			"note": "is required when rejecting",
+		})
+	}
+
+	notFound := apierror.NotFound("ROLE_REQUEST_NOT_FOUND", "Role request not found")
+	id, err := strconv.ParseUint(requestID, 10, 64)
+	if err != nil || id == 0 {
+		return nil, notFound
+	}
+	request, err := s.requests.FindByID(uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, notFound
+		}
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load role request").Wrap(err)
+	}
+
+	decided := apierror.Conflict("ROLE_REQUEST_DECIDED", "Role request has already been "+request.Status)
+	if request.Status != models.RoleRequestPending {
+		return nil, decided
+	}
+	if err := s.requests.Decide(request, status, admin.ID, reviewNote); err != nil {
+		if errors.Is(err, repositories.ErrRoleRequestDecided) {
+			return nil, apierror.Conflict("ROLE_REQUEST_DECIDED", "Role request has already been decided")
+		}
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to save role request").Wrap(err)
+	}
+	if status == models.RoleRequestRejected {
+		return request, nil
+	}
+
+	applicant, err := s.roles.ChangeRole(admin, strconv.FormatUint(uint64(request.UserID), 10), request.RequestedRole)
+	if err != nil {
+		if reopenErr := s.requests.Reopen(request); reopenErr != nil {
+			log.Printf("Failed to reopen role request %d after the role change failed: %v", request.ID, reopenErr)
+		}
+		return nil, err
+	}
+	request.User = applicant
+	return request, nil
+}
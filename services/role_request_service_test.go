@@ -0,0 +1,150 @@
+package services
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// fakeRoleRequestRepository is an in-memory RoleRequestRepository
+type fakeRoleRequestRepository struct {
+	requests []*models.RoleRequest
+}
+
+func (r *fakeRoleRequestRepository) Create(request *models.RoleRequest) error {
+	request.ID = uint(len(r.requests) + 1)
+	stored := *request
+	r.requests = append(r.requests, &stored)
+	return nil
+}
+
+func (r *fakeRoleRequestRepository) FindByID(id uint) (*models.RoleRequest, error) {
+	if id == 0 || int(id) > len(r.requests) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *r.requests[id-1]
+	return &copied, nil
+}
+
+func (r *fakeRoleRequestRepository) FindPending(userID uint) (*models.RoleRequest, error) {
+	for _, request := range r.requests {
+		if request.UserID == userID && request.Status == models.RoleRequestPending {
+			copied := *request
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeRoleRequestRepository) ListByUser(userID uint) ([]models.RoleRequest, error) {
+	var requests []models.RoleRequest
+	for i := len(r.requests) - 1; i >= 0; i-- {
+		if r.requests[i].UserID == userID {
+			requests = append(requests, *r.requests[i])
+		}
+	}
+	return requests, nil
+}
+
+func (r *fakeRoleRequestRepository) List(status string, limit, offset int) ([]models.RoleRequest, int64, error) {
+	var requests []models.RoleRequest
+	for _, request := range r.requests {
+		if status == "" || request.Status == status {
+			requests = append(requests, *request)
+		}
+	}
+	return requests, int64(len(requests)), nil
+}
+
+func (r *fakeRoleRequestRepository) Decide(request *models.RoleRequest, status string, reviewerID uint, note *string) error {
+	stored := r.requests[request.ID-1]
+	if stored.Status != models.RoleRequestPending {
+		return repositories.ErrRoleRequestDecided
+	}
+	stored.Status, stored.ReviewerID, stored.ReviewNote = status, &reviewerID, note
+	request.Status, request.ReviewerID, request.ReviewNote = status, &reviewerID, note
+	return nil
+}
+
+func (r *fakeRoleRequestRepository) Reopen(request *models.RoleRequest) error {
+	stored := r.requests[request.ID-1]
+	stored.Status, stored.ReviewerID, stored.ReviewNote = models.RoleRequestPending, nil, nil
+	request.Status, request.ReviewerID, request.ReviewNote = models.RoleRequestPending, nil, nil
+	return nil
+}
+
+func TestRoleRequestService(t *testing.T) {
+	admin := &models.User{ID: 10, Auth0ID: "auth0|admin", Role: RoleAdmin}
+	jane := &models.User{ID: 11, Auth0ID: "auth0|jane", Role: RoleCustomer}
+	sam := &models.User{ID: 12, Auth0ID: "auth0|sam", Role: RoleCustomer}
+	users := newFakeUserRepository(admin, jane, sam)
+	directory := &fakeRoleDirectory{roles: map[string]string{}}
+	requests := &fakeRoleRequestRepository{}
+	service := NewRoleRequestService(requests, NewRoleService(users, directory))
+
+	application := RoleRequestInput{Message: "Five years of gel art", PortfolioURLs: []string{"https://instagram.com/janenails"}}
+
+	_, err := service.SubmitRequest(testTechnician, application)
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+	_, err = service.SubmitRequest(jane, RoleRequestInput{Message: "Hire me"})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+	_, err = service.SubmitRequest(jane, RoleRequestInput{Message: "Hire me", PortfolioURLs: []string{"instagram.com/janenails"}})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+
+	request, err := service.SubmitRequest(jane, application)
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleRequestPending, request.Status)
+	assert.Equal(t, RoleTechnician, request.RequestedRole)
+	_, err = service.SubmitRequest(jane, application)
+	assertAPIError(t, err, http.StatusConflict, "ROLE_REQUEST_PENDING")
+
+	_, _, err = service.ListRequests(jane, "", 1, 20)
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+	pending, total, err := service.ListRequests(admin, models.RoleRequestPending, 1, 20)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, request.ID, pending[0].ID)
+
+	// A failed Auth0 update leaves the request pending and the role unchanged
+	directory.failSet = true
+	_, err = service.ReviewRequest(admin, uintString(request.ID), models.RoleRequestApproved, "")
+	assertAPIError(t, err, http.StatusBadGateway, "ROLE_SYNC_FAILED")
+	assert.Equal(t, models.RoleRequestPending, requests.requests[0].Status)
+	assert.Equal(t, RoleCustomer, users.users[jane.ID].Role)
+
+	directory.failSet = false
+	approved, err := service.ReviewRequest(admin, uintString(request.ID), models.RoleRequestApproved, "Welcome aboard")
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleRequestApproved, approved.Status)
+	assert.Equal(t, RoleTechnician, approved.User.Role)
+	assert.Equal(t, RoleTechnician, users.users[jane.ID].Role)
+	assert.Equal(t, RoleTechnician, directory.roles[jane.Auth0ID])
+
+	_, err = service.ReviewRequest(admin, uintString(request.ID), models.RoleRequestRejected, "Changed our mind")
+	assertAPIError(t, err, http.StatusConflict, "ROLE_REQUEST_DECIDED")
+	_, err = service.ReviewRequest(admin, "99", models.RoleRequestApproved, "")
+	assertAPIError(t, err, http.StatusNotFound, "ROLE_REQUEST_NOT_FOUND")
+
+	t.Run("rejections need a note and allow a new application", func(t *testing.T) {
+		request, err := service.SubmitRequest(sam, application)
+		require.NoError(t, err)
+		_, err = service.ReviewRequest(admin, uintString(request.ID), models.RoleRequestRejected, " ")
+		assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+		rejected, err := service.ReviewRequest(admin, uintString(request.ID), models.RoleRequestRejected, "Please add photos of your work")
+		require.NoError(t, err)
+		assert.Equal(t, "Please add photos of your work", *rejected.ReviewNote)
+		assert.Equal(t, RoleCustomer, users.users[sam.ID].Role)
+
+		_, err = service.SubmitRequest(sam, application)
+		require.NoError(t, err)
+		mine, err := service.ListMyRequests(sam)
+		require.NoError(t, err)
+		assert.Len(t, mine, 2)
+		assert.Equal(t, models.RoleRequestPending, mine[0].Status)
+	})
+}
@@ -0,0 +1,264 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"gorm.io/gorm"
+)
+
+// rolePullInterval limits how often one identity's role is looked up in the role directory
+// A token issued before a role change keeps its old claim until it expires, and should not
+// cause a directory call on every request
+const rolePullInterval = 5 * time.Minute
+
+// maxRolePulls bounds the record of recent lookups; it is emptied when full
+const maxRolePulls = 10000
+
+var (
+	roleDirectory RoleDirectory
+
+	rolePullsMu sync.Mutex
+	rolePulls   = make(map[string]time.Time) // Auth0 ID => last directory lookup
+)
+
+// SetRoleDirectory sets where roles are mirrored (nil disables role sync)
+// It is configured once at startup when Auth0 Management API credentials are present
+func SetRoleDirectory(directory RoleDirectory) {
+	roleDirectory = directory
+}
+
+// RoleService changes user roles and keeps them in sync with the identity provider
+type RoleService interface {
+	// ChangeRole sets a user's role and mirrors it to the role directory (admins only)
+	// The directory is updated first, so a failed sync leaves both sides unchanged
+	ChangeRole(admin *models.User, userID string, role string) (*models.User, error)
+
+	// SyncRole adopts the directory's role when the token's role claim disagrees with the stored role
+	// The user is returned unchanged when sync is disabled, the roles agree, or the identity was checked recently
+	SyncRole(user *models.User, claimedRole string) (*models.User, error)
+
+	// BanUser bans a user, after which their still-valid tokens are rejected (admins only)
+	BanUser(admin *models.User, userID string, reason string) (*models.User, error)
+
+	// UnbanUser lifts a user's ban (admins only)
+	UnbanUser(admin *models.User, userID string) (*models.User, error)
+
+	// Impersonate returns the user an admin is acting as for one request and records the request in the audit log (admins only)
+	Impersonate(admin *models.User, userID string, method, path string) (*models.User, error)
+}
+
+// DefaultRoleService implements RoleService on top of a UserRepository and a RoleDirectory
+type DefaultRoleService struct {
+	users     repositories.UserRepository
+	directory RoleDirectory                   // nil when role sync is disabled
+	audit     repositories.AuditLogRepository // nil records nothing
+}
+
+var roleServiceInstance RoleService
+
+// NewRoleService creates a role service; pass a nil directory to change roles locally only
+func NewRoleService(users repositories.UserRepository, directory RoleDirectory) *DefaultRoleService {
+	return &DefaultRoleService{users: users, directory: directory}
+}
+
+// GetRoleService returns the configured role service
+// When none has been set, a service over the current database connection is returned
+func GetRoleService() RoleService {
+	if roleServiceInstance != nil {
+		return roleServiceInstance
+	}
+	db := config.GetDB()
+	service := NewRoleService(repositories.NewUserRepository(db), roleDirectory)
+	service.audit = repositories.NewAuditLogRepository(db)
+	return service
+}
+
+// SetRoleService sets the role service instance (primarily for testing)
+func SetRoleService(service RoleService) {
+	roleServiceInstance = service
+}
+
+// ChangeRole sets a user's role and mirrors it to the role directory (admins only)
+func (s *DefaultRoleService) ChangeRole(admin *models.User, userID string, role string) (*models.User, error) {
+	if admin.Role != RoleAdmin {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only admins can change user roles")
+	}
+	if !IsRole(role) {
+		return nil, apierror.Validation("Invalid role", map[string]string{
+			"role": "must be one of: " + strings.Join(Roles, ", "),
+		})
+	}
+
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	// Keeps an admin from locking the last admin account out by accident
+	if user.ID == admin.ID {
+		return nil, apierror.Unprocessable("CANNOT_CHANGE_OWN_ROLE", "Admins cannot change their own role")
+	}
+
+	// Mirrored even when unchanged locally, which repairs a directory that drifted
+	if s.directory != nil {
+		if err := s.directory.SetRole(user.Auth0ID, role); err != nil {
+			return nil, apierror.New(http.StatusBadGateway, "ROLE_SYNC_FAILED", "Failed to update the role in Auth0; no changes were made").Wrap(err)
+		}
+	}
+
+	if user.Role != role {
+		if err := s.users.UpdateRole(user.ID, role); err != nil {
+			return nil, apierror.Internal("DATABASE_ERROR", "Failed to update role").Wrap(err)
+		}
+		before := map[string]interface{}{"role": user.Role}
+		user.Role = role
+		if err := recordAudit(s.audit, admin, models.AuditRoleChanged, models.AuditTargetUser, user.ID, before, map[string]interface{}{"role": role}); err != nil {
+			return nil, err
+		}
+	}
+	return user, nil
+}
+
+// BanUser bans a user, after which their still-valid tokens are rejected (admins only)
+// Admins cannot be banned; change their role first
+func (s *DefaultRoleService) BanUser(admin *models.User, userID string, reason string) (*models.User, error) {
+	if admin.Role != RoleAdmin {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only admins can ban users")
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, apierror.Validation("Invalid ban", map[string]string{
+			"reason": "is required",
+		})
+	}
+
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == RoleAdmin {
+		return nil, apierror.Unprocessable("CANNOT_BAN_ADMIN", "Admins cannot be banned; change their role first")
+	}
+	if user.IsBanned() {
+		return nil, apierror.Conflict("USER_ALREADY_BANNED", "User is already banned")
+	}
+
+	bannedAt := time.Now().UTC()
+	if err := s.users.UpdateBan(user.ID, &bannedAt, &reason); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to ban user").Wrap(err)
+	}
+	user.BannedAt, user.BanReason = &bannedAt, &reason
+	after := map[string]interface{}{"banned_at": bannedAt, "reason": reason}
+	if err := recordAudit(s.audit, admin, models.AuditUserBanned, models.AuditTargetUser, user.ID, nil, after); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// UnbanUser lifts a user's ban (admins only)
+func (s *DefaultRoleService) UnbanUser(admin *models.User, userID string) (*models.User, error) {
+	if admin.Role != RoleAdmin {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only admins can unban users")
+	}
+
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsBanned() {
+		return nil, apierror.Conflict("USER_NOT_BANNED", "User is not banned")
+	}
+
+	if err := s.users.UpdateBan(user.ID, nil, nil); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to unban user").Wrap(err)
+	}
+	before := map[string]interface{}{"banned_at": *user.BannedAt, "reason": user.BanReason}
+	user.BannedAt, user.BanReason = nil, nil
+	after := map[string]interface{}{"banned_at": nil}
+	if err := recordAudit(s.audit, admin, models.AuditUserUnbanned, models.AuditTargetUser, user.ID, before, after); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Impersonate returns the user an admin is acting as for one request and records the request in the audit log
+// Every impersonated request gets its own entry naming the method and path. Admins cannot be impersonated,
+// so impersonation never grants more than the admin already has
+func (s *DefaultRoleService) Impersonate(admin *models.User, userID string, method, path string) (*models.User, error) {
+	if admin.Role != RoleAdmin {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only admins can impersonate users")
+	}
+
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == RoleAdmin {
+		return nil, apierror.Unprocessable("CANNOT_IMPERSONATE_ADMIN", "Admins cannot be impersonated")
+	}
+
+	after := map[string]interface{}{"method": method, "path": path}
+	if err := recordAudit(s.audit, admin, models.AuditUserImpersonated, models.AuditTargetUser, user.ID, nil, after); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// findUser loads the user with the ID given in a request path
+func (s *DefaultRoleService) findUser(userID string) (*models.User, error) {
+	id, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil || id == 0 {
+		return nil, apierror.NotFound("USER_NOT_FOUND", "User not found")
+	}
+	user, err := s.users.FindByID(uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apierror.NotFound("USER_NOT_FOUND", "User not found")
+		}
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load user").Wrap(err)
+	}
+	return user, nil
+}
+
+// SyncRole adopts the directory's role when the token's role claim disagrees with the stored role
+// The claim alone is not trusted: it reflects the directory when the token was issued, which may
+// predate a change made here, so the directory is asked for the current role
+func (s *DefaultRoleService) SyncRole(user *models.User, claimedRole string) (*models.User, error) {
+	if s.directory == nil || claimedRole == "" || claimedRole == user.Role {
+		return user, nil
+	}
+
+	rolePullsMu.Lock()
+	if last, ok := rolePulls[user.Auth0ID]; ok && time.Since(last) < rolePullInterval {
+		rolePullsMu.Unlock()
+		return user, nil
+	}
+	if len(rolePulls) >= maxRolePulls {
+		rolePulls = make(map[string]time.Time)
+	}
+	rolePulls[user.Auth0ID] = time.Now()
+	rolePullsMu.Unlock()
+
+	role, err := s.directory.GetRole(user.Auth0ID)
+	if err != nil {
+		return user, err
+	}
+	if !IsRole(role) || role == user.Role {
+		return user, nil
+	}
+
+	if err := s.users.UpdateRole(user.ID, role); err != nil {
+		return user, err
+	}
+	synced := *user
+	synced.Role = role
+	return &synced, nil
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRoleDirectory is an in-memory RoleDirectory that counts lookups
+type fakeRoleDirectory struct {
+	roles   map[string]string
+	lookups int
+	failSet bool
+}
+
+func (d *fakeRoleDirectory) GetRole(auth0ID string) (string, error) {
+	d.lookups++
+	return d.roles[auth0ID], nil
+}
+
+func (d *fakeRoleDirectory) SetRole(auth0ID string, role string) error {
+	if d.failSet {
+		return errors.New("management API unavailable")
+	}
+	d.roles[auth0ID] = role
+	return nil
+}
+
+func TestRoleService_ChangeRole(t *testing.T) {
+	admin := &models.User{ID: 10, Auth0ID: "auth0|admin", Role: RoleAdmin}
+	jane := &models.User{ID: 11, Auth0ID: "auth0|jane", Role: RoleCustomer}
+	users := newFakeUserRepository(admin, jane)
+	directory := &fakeRoleDirectory{roles: map[string]string{}}
+	service := NewRoleService(users, directory)
+
+	_, err := service.ChangeRole(testTechnician, "11", RoleTechnician)
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+
+	_, err = service.ChangeRole(admin, "11", "owner")
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+
+	_, err = service.ChangeRole(admin, "99", RoleTechnician)
+	assertAPIError(t, err, http.StatusNotFound, "USER_NOT_FOUND")
+
+	_, err = service.ChangeRole(admin, "10", RoleCustomer)
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "CANNOT_CHANGE_OWN_ROLE")
+
+	// A failed sync changes nothing
+	directory.failSet = true
+	_, err = service.ChangeRole(admin, "11", RoleTechnician)
+	assertAPIError(t, err, http.StatusBadGateway, "ROLE_SYNC_FAILED")
+	assert.Equal(t, RoleCustomer, users.users[11].Role)
+
+	directory.failSet = false
+	changed, err := service.ChangeRole(admin, "11", RoleTechnician)
+	assert.NoError(t, err)
+	assert.Equal(t, RoleTechnician, changed.Role)
+	assert.Equal(t, RoleTechnician, users.users[11].Role)
+	assert.Equal(t, RoleTechnician, directory.roles["auth0|jane"])
+
+	// Without a directory roles change locally only
+	changed, err = NewRoleService(users, nil).ChangeRole(admin, "11", RoleCustomer)
+	assert.NoError(t, err)
+	assert.Equal(t, RoleCustomer, changed.Role)
+	assert.Equal(t, RoleTechnician, directory.roles["auth0|jane"])
+}
+
+func TestRoleService_BanUser(t *testing.T) {
+	admin := &models.User{ID: 10, Auth0ID: "auth0|admin", Role: RoleAdmin}
+	other := &models.User{ID: 11, Auth0ID: "auth0|other-admin", Role: RoleAdmin}
+	jane := &models.User{ID: 12, Auth0ID: "auth0|jane", Role: RoleCustomer}
+	users := newFakeUserRepository(admin, other, jane)
+	audit := &fakeAuditLogRepository{}
+	service := NewRoleService(users, nil)
+	service.audit = audit
+
+	_, err := service.BanUser(testTechnician, "12", "Chargeback fraud")
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+	_, err = service.BanUser(admin, "12", " ")
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+	_, err = service.BanUser(admin, "99", "Chargeback fraud")
+	assertAPIError(t, err, http.StatusNotFound, "USER_NOT_FOUND")
+	_, err = service.BanUser(admin, "11", "Chargeback fraud")
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "CANNOT_BAN_ADMIN")
+	_, err = service.UnbanUser(admin, "12")
+	assertAPIError(t, err, http.StatusConflict, "USER_NOT_BANNED")
+
+	banned, err := service.BanUser(admin, "12", "Chargeback fraud")
+	assert.NoError(t, err)
+	assert.True(t, banned.IsBanned())
+	assert.True(t, users.users[12].IsBanned())
+	assert.Equal(t, "Chargeback fraud", *users.users[12].BanReason)
+	_, err = service.BanUser(admin, "12", "Again")
+	assertAPIError(t, err, http.StatusConflict, "USER_ALREADY_BANNED")
+
+	unbanned, err := service.UnbanUser(admin, "12")
+	assert.NoError(t, err)
+	assert.False(t, unbanned.IsBanned())
+	assert.False(t, users.users[12].IsBanned())
+	assert.Nil(t, users.users[12].BanReason)
+
+	// Both changes are audited against the user
+	if assert.Len(t, audit.entries, 2) {
+		assert.Equal(t, models.AuditUserBanned, audit.entries[0].Action)
+		assert.Equal(t, "Chargeback fraud", audit.entries[0].After["reason"])
+		assert.Equal(t, models.AuditUserUnbanned, audit.entries[1].Action)
+		assert.Equal(t, uint(12), audit.entries[1].TargetID)
+	}
+}
+
+func TestRoleService_SyncRole(t *testing.T) {
+	sam := &models.User{ID: 12, Auth0ID: "auth0|sam-sync", Role: RoleCustomer}
+	users := newFakeUserRepository(sam)
+	directory := &fakeRoleDirectory{roles: map[string]string{"auth0|sam-sync": RoleTechnician}}
+	service := NewRoleService(users, directory)
+
+	// Matching claims and disabled sync never reach the directory
+	synced, err := service.SyncRole(sam, RoleCustomer)
+	assert.NoError(t, err)
+	assert.Same(t, sam, synced)
+	synced, err = NewRoleService(users, nil).SyncRole(sam, RoleTechnician)
+	assert.NoError(t, err)
+	assert.Same(t, sam, synced)
+	assert.Equal(t, 0, directory.lookups)
+
+	// A token carrying a different role adopts the directory's role
+	synced, err = service.SyncRole(sam, RoleTechnician)
+	assert.NoError(t, err)
+	assert.Equal(t, RoleTechnician, synced.Role)
+	assert.Equal(t, RoleTechnician, users.users[12].Role)
+	assert.Equal(t, RoleCustomer, sam.Role)
+
+	// Further mismatches within the pull interval are not looked up again
+	_, err = service.SyncRole(sam, RoleAdmin)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, directory.lookups)
+}
+
+func TestRoleService_Impersonate(t *testing.T) {
+	admin := &models.User{ID: 10, Auth0ID: "auth0|admin", Role: RoleAdmin}
+	other := &models.User{ID: 11, Auth0ID: "auth0|other-admin", Role: RoleAdmin}
+	jane := &models.User{ID: 12, Auth0ID: "auth0|jane", Role: RoleCustomer}
+	audit := &fakeAuditLogRepository{}
+	service := NewRoleService(newFakeUserRepository(admin, other, jane), nil)
+	service.audit = audit
+
+	_, err := service.Impersonate(testTechnician, "12", "GET", "/api/v1/orders")
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+	_, err = service.Impersonate(admin, "jane", "GET", "/api/v1/orders")
+	assertAPIError(t, err, http.StatusNotFound, "USER_NOT_FOUND")
+	_, err = service.Impersonate(admin, "11", "GET", "/api/v1/orders")
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "CANNOT_IMPERSONATE_ADMIN")
+	assert.Empty(t, audit.entries)
+
+	user, err := service.Impersonate(admin, "12", "GET", "/api/v1/orders/5")
+	assert.NoError(t, err)
+	assert.Equal(t, uint(12), user.ID)
+
+	// Each impersonated request is audited against the user with what was requested
+	if assert.Len(t, audit.entries, 1) {
+		entry := audit.entries[0]
+		assert.Equal(t, models.AuditUserImpersonated, entry.Action)
+		assert.Equal(t, uint(10), entry.ActorID)
+		assert.Equal(t, uint(12), entry.TargetID)
+		assert.Equal(t, map[string]interface{}{"method": "GET", "path": "/api/v1/orders/5"}, entry.After)
+	}
+}
@@ -3,42 +3,42 @@ package services
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
-	"mime/multipart"
-	"path/filepath"
+	"net/http"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	appConfig "github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/tracing"
 )
 
-// S3Interface defines the interface for S3 operations
-type S3Interface interface {
-	UploadFile(fileHeader *multipart.FileHeader) (string, error)
-	GetPresignedURL(s3Key string) (string, error)
-	DeleteFile(s3Key string) error
-}
-
-// S3Service handles all S3-related operations
+// S3Service implements Storage with an AWS S3 bucket
+// Signed URLs are S3 presigned URLs, or CloudFront signed URLs when a distribution is configured
 type S3Service struct {
+	ctx    context.Context // the request calls are made for, see WithContext
 	client *s3.Client
 	bucket string
+	expiry time.Duration
+	cdn    *CloudFrontSigner
+	now    func() time.Time
 }
 
-var s3ServiceInstance S3Interface
-
-// InitS3Service initializes the S3 service with AWS credentials
-func InitS3Service() (S3Interface, error) {
-	cfg := appConfig.GetConfig()
-
+// NewS3Service creates an S3 storage backend from the configured AWS credentials
+func NewS3Service(cfg *appConfig.Config) (*S3Service, error) {
 	// Load AWS configuration with explicit options
+	// Calls to S3 are traced as part of the request they are made for
+	httpClient := &http.Client{Transport: tracing.Transport(awshttp.NewBuildableClient().GetTransport())}
 	awsConfig, err := config.LoadDefaultConfig(context.TODO(),
 		config.WithRegion(cfg.AWSRegion),
+		config.WithHTTPClient(httpClient),
 		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
 			cfg.AWSAccessKeyID,
 			cfg.AWSSecretAccessKey,
@@ -56,84 +56,65 @@ func InitS3Service() (S3Interface, error) {
 		o.UsePathStyle = false
 	})
 
-	s3ServiceInstance = &S3Service{
-		client: client,
-		bucket: cfg.AWSS3Bucket,
+	var cdn *CloudFrontSigner
+	if cfg.CloudFrontEnabled() {
+		cdn, err = NewCloudFrontSigner(cfg.CloudFrontURL, cfg.CloudFrontKeyPairID, cfg.CloudFrontPrivateKey)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	return s3ServiceInstance, nil
-}
-
-// GetS3Service returns the initialized S3 service instance
-func GetS3Service() S3Interface {
-	return s3ServiceInstance
+	return &S3Service{
+		ctx:    context.Background(),
+		client: client,
+		bucket: cfg.AWSS3Bucket,
+		expiry: cfg.GetImageURLExpiry(),
+		cdn:    cdn,
+		now:    time.Now,
+	}, nil
 }
 
-// SetS3Service sets the S3 service instance (primarily for testing)
-func SetS3Service(service S3Interface) {
-	s3ServiceInstance = service
+// WithContext returns a copy of the service whose calls are made for ctx, so they appear in its trace
+func (s *S3Service) WithContext(ctx context.Context) Storage {
+	scoped := *s
+	scoped.ctx = ctx
+	return &scoped
 }
 
-// UploadFile uploads a file to S3 and returns the S3 key
-func (s *S3Service) UploadFile(fileHeader *multipart.FileHeader) (string, error) {
-	// Open the uploaded file
-	file, err := fileHeader.Open()
-	if err != nil {
-		return "", fmt.Errorf("failed to open file: %w", err)
-	}
-	defer func() {
-		if closeErr := file.Close(); closeErr != nil {
-			log.Printf("warning: failed to close file: %v", closeErr)
-		}
-	}()
-
-	// Read file content
-	content, err := io.ReadAll(file)
-	if err != nil {
-		return "", fmt.Errorf("failed to read file: %w", err)
-	}
-
-	// Generate unique S3 key (path in bucket)
-	// Format: uploads/{timestamp}_{filename}
-	timestamp := time.Now().Unix()
-	filename := filepath.Base(fileHeader.Filename)
-	s3Key := fmt.Sprintf("uploads/%d_%s", timestamp, filename)
-
-	// Determine content type
-	contentType := "image/png" // Since we only allow PNG files
-
-	// Upload to S3 with proper settings
-	_, err = s.client.PutObject(context.TODO(), &s3.PutObjectInput{
+// Put uploads content to S3 under key
+func (s *S3Service) Put(key string, content []byte, contentType string) error {
+	_, err := s.client.PutObject(s.ctx, &s3.PutObjectInput{
 		Bucket:      aws.String(s.bucket),
-		Key:         aws.String(s3Key),
+		Key:         aws.String(key),
 		Body:        bytes.NewReader(content),
 		ContentType: aws.String(contentType),
 		// Note: ACL is not set here - bucket permissions should handle access
 	})
 	if err != nil {
-		return "", fmt.Errorf("failed to upload to S3: %w", err)
+		return fmt.Errorf("failed to upload to S3: %w", err)
 	}
-
-	return s3Key, nil
+	return nil
 }
 
-// GetPresignedURL generates a presigned URL for accessing a private S3 object
-// The URL expires after 1 hour
-func (s *S3Service) GetPresignedURL(s3Key string) (string, error) {
+// SignedURL generates a presigned URL for accessing a private S3 object, through CloudFront when configured
+// The URL expires after IMAGE_URL_EXPIRY_MINUTES
+func (s *S3Service) SignedURL(s3Key string) (string, error) {
 	if s3Key == "" {
 		return "", nil
 	}
+	if s.cdn != nil {
+		return s.cdn.SignedURL(s3Key, s.now().Add(s.expiry))
+	}
 
 	// Create a presign client
 	presignClient := s3.NewPresignClient(s.client)
 
-	// Generate presigned URL valid for 1 hour using PresignGetObject
-	ctx := context.TODO()
-	request, err := presignClient.PresignGetObject(ctx, &s3.GetObjectInput{
+	// Generate presigned URL using PresignGetObject
+	request, err := presignClient.PresignGetObject(s.ctx, &s3.GetObjectInput{
 		Bucket: aws.String(s.bucket),
 		Key:    aws.String(s3Key),
 	}, func(opts *s3.PresignOptions) {
-		opts.Expires = time.Hour
+		opts.Expires = s.expiry
 	})
 
 	if err != nil {
@@ -144,13 +125,60 @@ func (s *S3Service) GetPresignedURL(s3Key string) (string, error) {
 	return request.URL, nil
 }
 
-// DeleteFile deletes a file from S3
-func (s *S3Service) DeleteFile(s3Key string) error {
+// Get reads the content of a file in S3
+func (s *S3Service) Get(s3Key string) ([]byte, error) {
+	output, err := s.client.GetObject(s.ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s3Key),
+	})
+	var noSuchKey *types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		return nil, fmt.Errorf("%w in S3: %s", ErrFileNotFound, s3Key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file from S3: %w", err)
+	}
+	defer func() {
+		if closeErr := output.Body.Close(); closeErr != nil {
+			log.Printf("warning: failed to close S3 object body: %v", closeErr)
+		}
+	}()
+
+	content, err := io.ReadAll(output.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file from S3: %w", err)
+	}
+	return content, nil
+}
+
+// Stat reads the size and modification time of a file in S3
+func (s *S3Service) Stat(s3Key string) (StoredFile, error) {
+	output, err := s.client.HeadObject(s.ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s3Key),
+	})
+	var notFound *types.NotFound
+	if errors.As(err, &notFound) {
+		return StoredFile{}, fmt.Errorf("%w in S3: %s", ErrFileNotFound, s3Key)
+	}
+	if err != nil {
+		return StoredFile{}, fmt.Errorf("failed to read file metadata from S3: %w", err)
+	}
+
+	file := StoredFile{Key: s3Key, Size: aws.ToInt64(output.ContentLength)}
+	if output.LastModified != nil {
+		file.LastModified = *output.LastModified
+	}
+	return file, nil
+}
+
+// Delete deletes a file from S3
+func (s *S3Service) Delete(s3Key string) error {
 	if s3Key == "" {
 		return nil
 	}
 
-	_, err := s.client.DeleteObject(context.TODO(), &s3.DeleteObjectInput{
+	_, err := s.client.DeleteObject(s.ctx, &s3.DeleteObjectInput{
 		Bucket: aws.String(s.bucket),
 		Key:    aws.String(s3Key),
 	})
@@ -160,3 +188,26 @@ func (s *S3Service) DeleteFile(s3Key string) error {
 
 	return nil
 }
+
+// List returns every file in the bucket whose key starts with prefix, following pagination
+func (s *S3Service) List(prefix string) ([]StoredFile, error) {
+	var files []StoredFile
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(s.ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list files in S3: %w", err)
+		}
+		for _, object := range page.Contents {
+			file := StoredFile{Key: aws.ToString(object.Key), Size: aws.ToInt64(object.Size)}
+			if object.LastModified != nil {
+				file.LastModified = *object.LastModified
+			}
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"gorm.io/gorm"
+)
+
+// SaveDesignInput holds the fields of a design to save
+// A design saved from an order takes the order's description and image unless they are given
+type SaveDesignInput struct {
+	Description string
+	ImageS3Key  *string
+	OrderID     *uint
+}
+
+// SavedDesignService manages the designs customers save to order again
+type SavedDesignService interface {
+	// ListDesigns returns the customer's saved designs
+	ListDesigns(customer *models.User) ([]models.SavedDesign, error)
+
+	// SaveDesign saves a description and image as a reusable design
+	SaveDesign(customer *models.User, input SaveDesignInput) (*models.SavedDesign, error)
+
+	// DeleteDesign removes one of the customer's saved designs
+	DeleteDesign(customer *models.User, designID string) error
+}
+
+// DefaultSavedDesignService implements SavedDesignService on top of the saved design and order repositories
+type DefaultSavedDesignService struct {
+	designs repositories.SavedDesignRepository
+	orders  repositories.OrderRepository
+}
+
+var savedDesignServiceInstance SavedDesignService
+
+// NewSavedDesignService creates a saved design service using the given repositories
+func NewSavedDesignService(designs repositories.SavedDesignRepository, orders repositories.OrderRepository) *DefaultSavedDesignService {
+	return &DefaultSavedDesignService{designs: designs, orders: orders}
+}
+
+// GetSavedDesignService returns the configured saved design service
+// When none has been set, a service over the current database connection is returned
+func GetSavedDesignService() SavedDesignService {
+	if savedDesignServiceInstance != nil {
+		return savedDesignServiceInstance
+	}
+	db := config.GetDB()
+	return NewSavedDesignService(repositories.NewSavedDesignRepository(db), repositories.NewOrderRepository(db))
+}
+
+// SetSavedDesignService sets the saved design service instance (primarily for testing)
+func SetSavedDesignService(service SavedDesignService) {
+	savedDesignServiceInstance = service
+}
+
+// ListDesigns returns the customer's saved designs, newest first
+func (s *DefaultSavedDesignService) ListDesigns(customer *models.User) ([]models.SavedDesign, error) {
+	if customer.Role != RoleCustomer {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only customers have saved designs")
+	}
+	designs, err := s.designs.ListForCustomer(customer.ID)
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to fetch saved designs").Wrap(err)
+	}
+	return designs, nil
+}
+
+// authorizeSave checks that the user may save designs (customers only)
+func (s *DefaultSavedDesignService) authorizeSave(user *models.User) error {
+	if user.Role != RoleCustomer {
+		return apierror.Forbidden("FORBIDDEN", "Only customers can save designs")
+	}
+	return nil
+}
+
+// SaveDesign saves a description and image as a reusable design
+// Any of the customer's orders can be saved, whatever its status
+func (s *DefaultSavedDesignService) SaveDesign(customer *models.User, input SaveDesignInput) (*models.SavedDesign, error) {
+	if err := s.authorizeSave(customer); err != nil {
+		return nil, err
+	}
+
+	design := &models.SavedDesign{
+		CustomerID:  customer.ID,
+		Description: strings.TrimSpace(input.Description),
+		ImageS3Key:  input.ImageS3Key,
+	}
+	if input.OrderID != nil {
+		order, err := s.orders.FindByID(*input.OrderID)
+		if err != nil {
+			return nil, orderLookupError(err)
+		}
+		if order.CustomerID != customer.ID {
+			return nil, apierror.Forbidden("FORBIDDEN", "You can only save designs from your own orders")
+		}
+		design.OrderID = &order.ID
+		if design.Description == "" {
+			design.Description = order.Description
+		}
+		if design.ImageS3Key == nil {
+			design.ImageS3Key = order.ImageS3Key
+		}
+	}
+	if design.Description == "" {
+		return nil, apierror.Validation("Description is required", nil)
+	}
+
+	if err := s.designs.Create(design); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to save design").Wrap(err)
+	}
+	return design, nil
+}
+
+// DeleteDesign removes one of the customer's saved designs
+// Other customers' designs are reported as not found, so their IDs are not revealed
+func (s *DefaultSavedDesignService) DeleteDesign(customer *models.User, designID string) error {
+	if customer.Role != RoleCustomer {
+		return apierror.Forbidden("FORBIDDEN", "Only customers have saved designs")
+	}
+
+	id, err := strconv.ParseUint(designID, 10, 64)
+	if err != nil || id == 0 {
+		return apierror.NotFound("DESIGN_NOT_FOUND", "Saved design not found")
+	}
+	design, err := s.designs.FindForCustomer(customer.ID, uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apierror.NotFound("DESIGN_NOT_FOUND", "Saved design not found")
+		}
+		return apierror.Internal("DATABASE_ERROR", "Failed to load saved design").Wrap(err)
+	}
+
+	if err := s.designs.Delete(design); err != nil {
+		return apierror.Internal("DATABASE_ERROR", "Failed to delete saved design").Wrap(err)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// fakeSavedDesignRepository is an in-memory SavedDesignRepository
+type fakeSavedDesignRepository struct {
+	designs map[uint]*models.SavedDesign
+	nextID  uint
+}
+
+func newFakeSavedDesignRepository() *fakeSavedDesignRepository {
+	return &fakeSavedDesignRepository{designs: make(map[uint]*models.SavedDesign), nextID: 1}
+}
+
+func (r *fakeSavedDesignRepository) Create(design *models.SavedDesign) error {
+	design.ID = r.nextID
+	r.nextID++
+	stored := *design
+	r.designs[design.ID] = &stored
+	return nil
+}
+
+func (r *fakeSavedDesignRepository) Delete(design *models.SavedDesign) error {
+	delete(r.designs, design.ID)
+	return nil
+}
+
+func (r *fakeSavedDesignRepository) FindForCustomer(customerID, id uint) (*models.SavedDesign, error) {
+	design, ok := r.designs[id]
+	if !ok || design.CustomerID != customerID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	found := *design
+	return &found, nil
+}
+
+func (r *fakeSavedDesignRepository) ListForCustomer(customerID uint) ([]models.SavedDesign, error) {
+	var designs []models.SavedDesign
+	for id := r.nextID - 1; id > 0; id-- {
+		if design, ok := r.designs[id]; ok && design.CustomerID == customerID {
+			designs = append(designs, *design)
+		}
+	}
+	return designs, nil
+}
+
+func TestSavedDesignService_SaveListDelete(t *testing.T) {
+	imageKey := "designs/abc.png"
+	orders := newFakeOrderRepository(
+		models.Order{ID: 1, CustomerID: testCustomer.ID, Description: "Chrome coffin", Status: StatusInProduction, ImageS3Key: &imageKey},
+		models.Order{ID: 2, CustomerID: otherCustomer.ID, Description: "Not mine", Status: StatusDelivered},
+	)
+	service := NewSavedDesignService(newFakeSavedDesignRepository(), orders)
+
+	_, err := service.SaveDesign(testTechnician, SaveDesignInput{Description: "Tech design"})
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+	_, err = service.SaveDesign(testCustomer, SaveDesignInput{Description: "  "})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+
+	fresh, err := service.SaveDesign(testCustomer, SaveDesignInput{Description: " Pastel florals "})
+	assert.NoError(t, err)
+	assert.Equal(t, "Pastel florals", fresh.Description)
+	assert.Nil(t, fresh.ImageS3Key)
+
+	// Orders in any status can be saved; their description and image are copied
+	fromOrder, err := service.SaveDesign(testCustomer, SaveDesignInput{OrderID: uintPtr(1)})
+	assert.NoError(t, err)
+	assert.Equal(t, "Chrome coffin", fromOrder.Description)
+	assert.Equal(t, &imageKey, fromOrder.ImageS3Key)
+	assert.Equal(t, uintPtr(1), fromOrder.OrderID)
+
+	_, err = service.SaveDesign(testCustomer, SaveDesignInput{OrderID: uintPtr(2)})
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+	_, err = service.SaveDesign(testCustomer, SaveDesignInput{OrderID: uintPtr(99)})
+	assertAPIError(t, err, http.StatusNotFound, "ORDER_NOT_FOUND")
+
+	designs, err := service.ListDesigns(testCustomer)
+	assert.NoError(t, err)
+	if assert.Len(t, designs, 2) {
+		assert.Equal(t, fromOrder.ID, designs[0].ID)
+	}
+	designs, err = service.ListDesigns(otherCustomer)
+	assert.NoError(t, err)
+	assert.Empty(t, designs)
+
+	assertAPIError(t, service.DeleteDesign(otherCustomer, uintString(fresh.ID)), http.StatusNotFound, "DESIGN_NOT_FOUND")
+	assert.NoError(t, service.DeleteDesign(testCustomer, uintString(fresh.ID)))
+	assertAPIError(t, service.DeleteDesign(testCustomer, uintString(fresh.ID)), http.StatusNotFound, "DESIGN_NOT_FOUND")
+	assertAPIError(t, service.DeleteDesign(testCustomer, "abc"), http.StatusNotFound, "DESIGN_NOT_FOUND")
+}
+
+func TestOrderService_CreateOrderFromSavedDesign(t *testing.T) {
+	imageKey := "designs/abc.png"
+	designs := newFakeSavedDesignRepository()
+	saved := &models.SavedDesign{CustomerID: testCustomer.ID, Description: "Chrome coffin", ImageS3Key: &imageKey}
+	assert.NoError(t, designs.Create(saved))
+
+	service := newTestOrderService(newFakeOrderRepository())
+	service.designs = designs
+
+	order, err := service.CreateOrder(testCustomer, CreateOrderInput{Quantity: 2, SavedDesignID: &saved.ID})
+	assert.NoError(t, err)
+	assert.Equal(t, "Chrome coffin", order.Description)
+	assert.Equal(t, &imageKey, order.ImageS3Key)
+	assert.Equal(t, &saved.ID, order.SavedDesignID)
+
+	// The customer's own description and image take precedence
+	otherKey := "orders/new.png"
+	order, err = service.CreateOrder(testCustomer, CreateOrderInput{Description: "Chrome coffin, but shorter", ImageS3Key: &otherKey, Quantity: 1, SavedDesignID: &saved.ID})
+	assert.NoError(t, err)
+	assert.Equal(t, "Chrome coffin, but shorter", order.Description)
+	assert.Equal(t, &otherKey, order.ImageS3Key)
+
+	_, err = service.CreateOrder(otherCustomer, CreateOrderInput{Quantity: 1, SavedDesignID: &saved.ID})
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "INVALID_SAVED_DESIGN")
+	_, err = service.CreateOrder(testCustomer, CreateOrderInput{Quantity: 1, SavedDesignID: &saved.ID, CatalogDesignID: uintPtr(1)})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"bytes"
+	"image"
+	_ "image/gif"  // decode GIF uploads
+	_ "image/jpeg" // decode JPEG uploads
+	_ "image/png"  // decode PNG uploads
+	"log"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"github.com/kendall-kelly/kendalls-nails-api/sharecard"
+)
+
+// ShareCardService composes the shareable "my custom set" image of a delivered order
+type ShareCardService interface {
+	// ShareCard returns the PNG share card of a delivered order the user may view
+	ShareCard(user *models.User, orderID string) ([]byte, error)
+}
+
+// DefaultShareCardService implements ShareCardService on top of the order, progress update, and catalog repositories
+type DefaultShareCardService struct {
+	orders  repositories.OrderRepository
+	updates repositories.ProgressUpdateRepository
+	catalog repositories.CatalogRepository
+	images  ImageService
+
+	moderations repositories.ImageModerationRepository // nil shows the customer's image without checking moderation
+}
+
+var shareCardServiceInstance ShareCardService
+
+// NewShareCardService creates a share card service using the given repositories and image storage
+// A nil image service draws every card without a photo
+func NewShareCardService(orders repositories.OrderRepository, updates repositories.ProgressUpdateRepository, catalog repositories.CatalogRepository, images ImageService) *DefaultShareCardService {
+	return &DefaultShareCardService{orders: orders, updates: updates, catalog: catalog, images: images}
+}
+
+// GetShareCardService returns the configured share card service
+// When none has been set, a service over the current database connection and image service is returned
+func GetShareCardService() ShareCardService {
+	if shareCardServiceInstance != nil {
+		return shareCardServiceInstance
+	}
+	db := config.GetDB()
+	service := NewShareCardService(
+		repositories.NewOrderRepository(db),
+		repositories.NewProgressUpdateRepository(db),
+		repositories.NewCatalogRepository(db),
+		GetImageService(),
+	)
+	service.moderations = repositories.NewImageModerationRepository(db)
+	return service
+}
+
+// SetShareCardService sets the share card service instance (primarily for testing)
+func SetShareCardService(service ShareCardService) {
+	shareCardServiceInstance = service
+}
+
+// ShareCard returns the PNG share card of a delivered order the user may view
+func (s *DefaultShareCardService) ShareCard(user *models.User, orderID string) ([]byte, error) {
+	id, err := parseOrderID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	order, err := s.orders.FindByIDWithRelations(id)
+	if err != nil {
+		return nil, orderLookupError(err)
+	}
+	if !CanViewOrder(user, order) {
+		return nil, apierror.Forbidden("FORBIDDEN", "You do not have permission to view this order")
+	}
+	if order.Status != StatusDelivered {
+		return nil, apierror.Unprocessable("INVALID_STATE", "Share cards are available once the order is delivered")
+	}
+
+	card := sharecard.Card{Photo: s.designPhoto(order), OrderedAt: order.CreatedAt}
+	if order.Technician != nil {
+		card.Technician = order.Technician.Name
+	}
+
+	data, err := sharecard.Render(card)
+	if err != nil {
+		return nil, apierror.Internal("SHARE_CARD_ERROR", "Failed to generate share card").Wrap(err)
+	}
+	return data, nil
+}
+
+// designPhoto loads the photo that best shows the finished set: the technician's latest progress photo,
+// then the customer's own image, then the catalog design's first image
+// Photos that cannot be loaded are skipped, so a storage problem costs the card its photo rather than failing it
+func (s *DefaultShareCardService) designPhoto(order *models.Order) image.Image {
+	if s.images == nil {
+		return nil
+	}
+
+	var keys []string
+	updates, err := s.updates.ListForOrder(order.ID)
+	if err != nil {
+		log.Printf("Failed to load progress updates of order %d for its share card: %v", order.ID, err)
+	}
+	for i := len(updates) - 1; i >= 0; i-- {
+		keys = append(keys, updates[i].ImageS3Key)
+	}
+	if order.ImageS3Key != nil && *order.ImageS3Key != "" && s.imageVisible(*order.ImageS3Key) {
+		keys = append(keys, *order.ImageS3Key)
+	}
+	if order.CatalogDesignID != nil && s.catalog != nil {
+		if design, err := s.catalog.FindByID(*order.CatalogDesignID); err == nil && len(design.Images) > 0 {
+			keys = append(keys, design.Images[0].ImageS3Key)
+		}
+	}
+
+	for _, key := range keys {
+		data, err := s.images.GetImage(key)
+		if err != nil {
+			log.Printf("Failed to load image %s for the share card of order %d: %v", key, order.ID, err)
+			continue
+		}
+		photo, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			log.Printf("Failed to decode image %s for the share card of order %d: %v", key, order.ID, err)
+			continue
+		}
+		return photo
+	}
+	return nil
+}
+
+// imageVisible reports whether the customer's image has cleared moderation, treating a failed check as held
+func (s *DefaultShareCardService) imageVisible(imageKey string) bool {
+	if s.moderations == nil {
+		return true
+	}
+	held, err := s.moderations.FindHeld([]string{imageKey})
+	if err != nil {
+		log.Printf("Failed to check moderation of image %s: %v", imageKey, err)
+		return false
+	}
+	_, isHeld := held[imageKey]
+	return !isHeld
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/sharecard"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeImageStore is an ImageService over stored image contents
+type fakeImageStore map[string][]byte
+
+func (s fakeImageStore) UploadImage(fileHeader *multipart.FileHeader) (string, error) {
+	return "", fmt.Errorf("not supported")
+}
+
+func (s fakeImageStore) StoreImage(filename string, content []byte) (string, error) {
+	return "", fmt.Errorf("not supported")
+}
+
+func (s fakeImageStore) GetImageURL(imageKey string) (string, error) {
+	return "https://images.example.com/" + imageKey, nil
+}
+
+func (s fakeImageStore) GetImage(imageKey string) ([]byte, error) {
+	content, ok := s[imageKey]
+	if !ok {
+		return nil, fmt.Errorf("image not found: %s", imageKey)
+	}
+	return content, nil
+}
+
+func (s fakeImageStore) DeleteImage(imageKey string) error {
+	delete(s, imageKey)
+	return nil
+}
+
+// solidPNG encodes a small single-colour PNG
+func solidPNG(t *testing.T, c color.RGBA) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	var out bytes.Buffer
+	assert.NoError(t, png.Encode(&out, img))
+	return out.Bytes()
+}
+
+// cardPhotoColor decodes a share card and returns the colour at the centre of its photo
+func cardPhotoColor(t *testing.T, data []byte) color.RGBA {
+	t.Helper()
+	card, err := png.Decode(bytes.NewReader(data))
+	assert.NoError(t, err)
+	return color.RGBAModel.Convert(card.At(sharecard.Width/2, sharecard.Width/2)).(color.RGBA)
+}
+
+func TestShareCardService_ShareCard(t *testing.T) {
+	red := color.RGBA{R: 0xFF, A: 0xFF}
+	blue := color.RGBA{B: 0xFF, A: 0xFF}
+	inspiration := "uploads/inspiration.png"
+	orders := newFakeOrderRepository(
+		models.Order{ID: 1, Status: StatusDelivered, CustomerID: testCustomer.ID, TechnicianID: uintPtr(testTechnician.ID), Technician: testTechnician, ImageS3Key: &inspiration},
+		models.Order{ID: 2, Status: StatusShipped, CustomerID: testCustomer.ID},
+	)
+	updates := newFakeProgressUpdateRepository()
+	assert.NoError(t, updates.Create(&models.ProgressUpdate{OrderID: 1, AuthorID: testTechnician.ID, ImageS3Key: "uploads/finished.png"}, nil))
+	images := fakeImageStore{
+		"uploads/inspiration.png": solidPNG(t, blue),
+		"uploads/finished.png":    solidPNG(t, red),
+	}
+	service := NewShareCardService(orders, updates, nil, images)
+
+	// The latest progress photo shows the finished set
+	data, err := service.ShareCard(testCustomer, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, red, cardPhotoColor(t, data))
+
+	// A photo that cannot be decoded is passed over for the customer's own image
+	images["uploads/finished.png"] = []byte("not a png")
+	data, err = service.ShareCard(testTechnician, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, blue, cardPhotoColor(t, data))
+
+	_, err = service.ShareCard(otherCustomer, "1")
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+
+	_, err = service.ShareCard(testCustomer, "2")
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "INVALID_STATE")
+
+	_, err = service.ShareCard(testCustomer, "99")
+	assertAPIError(t, err, http.StatusNotFound, "ORDER_NOT_FOUND")
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+)
+
+// UploadPrefix is the key prefix every uploaded file is stored under
+const UploadPrefix = "uploads/"
+
+// StoredFile describes a file in storage
+type StoredFile struct {
+	Key          string
+	Size         int64 // bytes
+	LastModified time.Time
+}
+
+// Storage keeps uploaded files under slash-separated keys such as "uploads/1700000000_design.png"
+// S3 and the local filesystem implement it; STORAGE_BACKEND picks one at startup
+type Storage interface {
+	// Put stores content under key, replacing any file already there
+	Put(key string, content []byte, contentType string) error
+
+	// Get returns the content stored under key, or an error wrapping ErrFileNotFound
+	Get(key string) ([]byte, error)
+
+	// Stat describes the file stored under key, or returns an error wrapping ErrFileNotFound
+	Stat(key string) (StoredFile, error)
+
+	// Delete removes the file stored under key; deleting a missing file is not an error
+	Delete(key string) error
+
+	// SignedURL returns a URL the file can be downloaded from for IMAGE_URL_EXPIRY_MINUTES
+	SignedURL(key string) (string, error)
+
+	// List returns every file whose key starts with prefix
+	List(prefix string) ([]StoredFile, error)
+}
+
+// ErrFileNotFound is returned by Storage.Get and Storage.Stat when nothing is stored under the key
+var ErrFileNotFound = errors.New("file not found")
+
+var (
+	storageInstance Storage
+	fileProxy       *FileProxy
+)
+
+// InitStorage creates the storage backend selected by STORAGE_BACKEND and makes it the current one
+// With IMAGE_PROXY, and always for local storage, its signed URLs are links to the API's FileProxy
+func InitStorage(cfg *config.Config) (Storage, error) {
+	var storage Storage
+	var err error
+	switch cfg.GetStorageBackend() {
+	case config.StorageLocal:
+		storage, err = NewLocalStorage(cfg)
+	default:
+		storage, err = NewS3Service(cfg)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	fileProxy = nil
+	if cfg.ImageProxyEnabled() {
+		proxy, err := NewFileProxy(storage, cfg)
+		if err != nil {
+			return nil, err
+		}
+		if db := config.GetDB(); db != nil {
+			proxy.references = repositories.NewImageReferenceRepository(db)
+		}
+		storage = &proxiedStorage{Storage: storage, proxy: proxy}
+		fileProxy = proxy
+	}
+	storageInstance = storage
+	return storage, nil
+}
+
+// GetFileProxy returns the proxy serving stored files through the API, or nil when images are served directly
+func GetFileProxy() *FileProxy {
+	return fileProxy
+}
+
+// SetFileProxy sets the file proxy (primarily for testing)
+func SetFileProxy(proxy *FileProxy) {
+	fileProxy = proxy
+}
+
+// GetStorage returns the initialized storage backend
+func GetStorage() Storage {
+	return storageInstance
+}
+
+// SetStorage sets the storage backend (primarily for testing)
+func SetStorage(storage Storage) {
+	storageInstance = storage
+}
+
+// contextStorage is a Storage that can make its calls for a request, so that they appear in the request's trace
+type contextStorage interface {
+	WithContext(ctx context.Context) Storage
+}
+
+// StorageFor returns the storage with its calls made for ctx
+// Backends that make no remote calls are returned unchanged
+func StorageFor(ctx context.Context, storage Storage) Storage {
+	if scoped, ok := storage.(contextStorage); ok {
+		return scoped.WithContext(ctx)
+	}
+	return storage
+}
+
+// validateStorageKey rejects keys that are empty, absolute, not in clean slash-separated form, or that climb
+// out of the storage root with ".."; keys come from stored records, but links carry them back from clients
+func validateStorageKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") || strings.ContainsRune(key, 0) ||
+		path.Clean(key) != key || key == ".." || strings.HasPrefix(key, "../") {
+		return fmt.Errorf("invalid storage key %q", key)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/jobs"
+	"github.com/kendall-kelly/kendalls-nails-api/metrics"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+)
+
+// OrphanImageCheckInterval is how often uploaded images are checked for ones nothing refers to
+const OrphanImageCheckInterval = 24 * time.Hour
+
+// orphanImageBatchSize is how many image keys are cross-referenced with the database per query
+const orphanImageBatchSize = 500
+
+// Storage reclaimed by the orphan cleanup in this process, exposed on /metrics
+var (
+	orphanImagesDeleted = metrics.NewCounter(
+		"kendalls_nails_orphan_images_deleted_total",
+		"Uploaded images deleted because no record referred to them, since the process started",
+	)
+	orphanImageBytesReclaimed = metrics.NewCounter(
+		"kendalls_nails_orphan_image_bytes_reclaimed_total",
+		"Storage freed by deleting orphaned uploads, in bytes, since the process started",
+	)
+)
+
+// OrphanCleanupResult summarizes one pass of the orphaned image cleanup
+type OrphanCleanupResult struct {
+	Scanned        int   // uploads listed
+	Deleted        int   // orphans deleted
+	ReclaimedBytes int64 // total size of the deleted orphans
+}
+
+// StorageCleanupService removes uploaded images that nothing refers to, such as those of failed order creations
+type StorageCleanupService interface {
+	// DeleteOrphanImages deletes uploads older than maxAge that no record refers to
+	// Younger uploads are kept, since the record that will refer to them may still be being created
+	DeleteOrphanImages(ctx context.Context, maxAge time.Duration) (OrphanCleanupResult, error)
+}
+
+// DefaultStorageCleanupService implements StorageCleanupService on top of a Storage backend and the image reference repository
+type DefaultStorageCleanupService struct {
+	storage     Storage
+	references  repositories.ImageReferenceRepository
+	moderations repositories.ImageModerationRepository // nil leaves screening verdicts of deleted images in place
+	now         func() time.Time
+}
+
+// NewStorageCleanupService creates a storage cleanup service over the given storage and repositories
+func NewStorageCleanupService(storage Storage, references repositories.ImageReferenceRepository, moderations repositories.ImageModerationRepository) *DefaultStorageCleanupService {
+	return &DefaultStorageCleanupService{storage: storage, references: references, moderations: moderations, now: time.Now}
+}
+
+// GetStorageCleanupService returns a storage cleanup service over the current storage backend and database connection
+func GetStorageCleanupService() StorageCleanupService {
+	db := config.GetDB()
+	return NewStorageCleanupService(GetStorage(), repositories.NewImageReferenceRepository(db), repositories.NewImageModerationRepository(db))
+}
+
+// DeleteOrphanImages deletes uploads older than maxAge that no record refers to
+// Deletion stops at the first failure; orphans left behind are retried on the next pass
+func (s *DefaultStorageCleanupService) DeleteOrphanImages(ctx context.Context, maxAge time.Duration) (OrphanCleanupResult, error) {
+	var result OrphanCleanupResult
+	files, err := s.storage.List(UploadPrefix)
+	if err != nil {
+		return result, err
+	}
+	result.Scanned = len(files)
+
+	cutoff := s.now().Add(-maxAge)
+	var candidates []StoredFile
+	for _, file := range files {
+		if file.LastModified.Before(cutoff) {
+			candidates = append(candidates, file)
+		}
+	}
+
+	for start := 0; start < len(candidates); start += orphanImageBatchSize {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		batch := candidates[start:min(start+orphanImageBatchSize, len(candidates))]
+		keys := make([]string, len(batch))
+		for i, file := range batch {
+			keys[i] = file.Key
+		}
+		referenced, err := s.references.FindReferenced(keys)
+		if err != nil {
+			return result, fmt.Errorf("failed to check image references: %w", err)
+		}
+
+		var deleted []string
+		for _, file := range batch {
+			if referenced[file.Key] {
+				continue
+			}
+			if err := s.storage.Delete(file.Key); err != nil {
+				return result, err
+			}
+			deleted = append(deleted, file.Key)
+			result.Deleted++
+			result.ReclaimedBytes += file.Size
+			orphanImagesDeleted.Add(1)
+			orphanImageBytesReclaimed.Add(float64(file.Size))
+		}
+		if s.moderations != nil {
+			if err := s.moderations.DeleteByKeys(deleted); err != nil {
+				return result, fmt.Errorf("failed to remove screening of deleted images: %w", err)
+			}
+		}
+	}
+	return result, nil
+}
+
+// OrphanImageCleanupJob deletes uploaded images nothing has referred to for maxAge, once a day
+func OrphanImageCleanupJob(service StorageCleanupService, maxAge time.Duration) jobs.Job {
+	return jobs.Job{
+		Name:     "orphan_image_cleanup",
+		Interval: OrphanImageCheckInterval,
+		Run: func(ctx context.Context) error {
+			result, err := service.DeleteOrphanImages(ctx, maxAge)
+			if result.Deleted > 0 {
+				log.Printf("Deleted %d orphaned images of %d uploads, reclaiming %d bytes", result.Deleted, result.Scanned, result.ReclaimedBytes)
+			}
+			return err
+		},
+	}
+}
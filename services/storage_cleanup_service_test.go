@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeImageReferenceRepository treats a fixed set of image keys as referenced
+type fakeImageReferenceRepository struct {
+	keys map[string]bool
+}
+
+func (r *fakeImageReferenceRepository) FindReferenced(imageKeys []string) (map[string]bool, error) {
+	referenced := make(map[string]bool)
+	for _, key := range imageKeys {
+		if r.keys[key] {
+			referenced[key] = true
+		}
+	}
+	return referenced, nil
+}
+
+func TestStorageCleanupService_DeleteOrphanImages(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	old := now.Add(-10 * 24 * time.Hour)
+
+	storage := NewMockStorage()
+	storage.PutFile("uploads/1_used.png", []byte("used"), old)
+	storage.PutFile("uploads/2_orphan.png", []byte("orphaned"), old)
+	storage.PutFile("uploads/3_fresh.png", []byte("fresh"), now.Add(-time.Hour))
+	storage.PutFile("exports/4_other.csv", []byte("not an upload"), old)
+
+	moderations := &fakeImageModerationRepository{}
+	_ = moderations.Create(&models.ImageModeration{ImageS3Key: "uploads/2_orphan.png", Status: models.ImagePendingReview})
+
+	service := NewStorageCleanupService(storage, &fakeImageReferenceRepository{keys: map[string]bool{"uploads/1_used.png": true}}, moderations)
+	service.now = func() time.Time { return now }
+
+	deletedBefore := orphanImagesDeleted.Family().Samples[0].Value
+	reclaimedBefore := orphanImageBytesReclaimed.Family().Samples[0].Value
+
+	result, err := service.DeleteOrphanImages(context.Background(), 7*24*time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, OrphanCleanupResult{Scanned: 3, Deleted: 1, ReclaimedBytes: 8}, result)
+
+	// Only the old, unreferenced upload is removed, along with its screening verdict
+	assert.True(t, storage.FileExists("uploads/1_used.png"))
+	assert.False(t, storage.FileExists("uploads/2_orphan.png"))
+	assert.True(t, storage.FileExists("uploads/3_fresh.png"))
+	assert.True(t, storage.FileExists("exports/4_other.csv"))
+	assert.Empty(t, moderations.moderations)
+
+	// Reclaimed storage is counted for /metrics
+	assert.Equal(t, deletedBefore+1, orphanImagesDeleted.Family().Samples[0].Value)
+	assert.Equal(t, reclaimedBefore+8, orphanImageBytesReclaimed.Family().Samples[0].Value)
+
+	// A canceled pass deletes nothing
+	storage.PutFile("uploads/5_orphan.png", []byte("orphaned"), old)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = service.DeleteOrphanImages(ctx, 7*24*time.Hour)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.True(t, storage.FileExists("uploads/5_orphan.png"))
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MockStorage is an in-memory implementation of Storage for testing and mock mode
+type MockStorage struct {
+	uploadedFiles map[string][]byte    // map of key to file content
+	modifiedAt    map[string]time.Time // map of key to upload time
+	mu            sync.RWMutex
+}
+
+// NewMockStorage creates a new in-memory storage
+func NewMockStorage() *MockStorage {
+	return &MockStorage{
+		uploadedFiles: make(map[string][]byte),
+		modifiedAt:    make(map[string]time.Time),
+	}
+}
+
+// SetAsMockForTesting sets this mock as the global storage backend for testing
+func (m *MockStorage) SetAsMockForTesting() {
+	SetStorage(m)
+}
+
+// Put stores a file in memory
+func (m *MockStorage) Put(key string, content []byte, contentType string) error {
+	m.PutFile(key, content, time.Now())
+	return nil
+}
+
+// SignedURL simulates generating a presigned URL
+func (m *MockStorage) SignedURL(key string) (string, error) {
+	if key == "" {
+		return "", nil
+	}
+
+	// Check if file exists in mock storage
+	m.mu.RLock()
+	_, exists := m.uploadedFiles[key]
+	m.mu.RUnlock()
+
+	if !exists {
+		return "", fmt.Errorf("file not found in mock storage: %s", key)
+	}
+
+	// Return a mock presigned URL
+	return fmt.Sprintf("https://test-bucket.s3.us-east-1.amazonaws.com/%s?mock=true", key), nil
+}
+
+// Get returns the content of a file in mock storage
+func (m *MockStorage) Get(key string) ([]byte, error) {
+	m.mu.RLock()
+	content, exists := m.uploadedFiles[key]
+	m.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("%w in mock storage: %s", ErrFileNotFound, key)
+	}
+	return content, nil
+}
+
+// Stat describes a file in mock storage
+func (m *MockStorage) Stat(key string) (StoredFile, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	content, exists := m.uploadedFiles[key]
+	if !exists {
+		return StoredFile{}, fmt.Errorf("%w in mock storage: %s", ErrFileNotFound, key)
+	}
+	return StoredFile{Key: key, Size: int64(len(content)), LastModified: m.modifiedAt[key]}, nil
+}
+
+// Delete removes a file from mock storage
+func (m *MockStorage) Delete(key string) error {
+	if key == "" {
+		return nil
+	}
+
+	m.mu.Lock()
+	delete(m.uploadedFiles, key)
+	delete(m.modifiedAt, key)
+	m.mu.Unlock()
+
+	return nil
+}
+
+// List returns the files in mock storage whose key starts with prefix, ordered by key
+func (m *MockStorage) List(prefix string) ([]StoredFile, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var files []StoredFile
+	for key, content := range m.uploadedFiles {
+		if strings.HasPrefix(key, prefix) {
+			files = append(files, StoredFile{Key: key, Size: int64(len(content)), LastModified: m.modifiedAt[key]})
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Key < files[j].Key })
+	return files, nil
+}
+
+// PutFile stores a file in mock storage as if it was uploaded at modifiedAt (for testing)
+func (m *MockStorage) PutFile(key string, content []byte, modifiedAt time.Time) {
+	m.mu.Lock()
+	m.uploadedFiles[key] = content
+	m.modifiedAt[key] = modifiedAt
+	m.mu.Unlock()
+}
+
+// GetUploadedFiles returns all uploaded files (for testing assertions)
+func (m *MockStorage) GetUploadedFiles() map[string][]byte {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	// Return a copy to prevent race conditions
+	files := make(map[string][]byte, len(m.uploadedFiles))
+	for k, v := range m.uploadedFiles {
+		files[k] = v
+	}
+	return files
+}
+
+// FileExists checks if a file exists in mock storage
+func (m *MockStorage) FileExists(key string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, exists := m.uploadedFiles[key]
+	return exists
+}
+
+// Clear removes all files from mock storage
+func (m *MockStorage) Clear() {
+	m.mu.Lock()
+	m.uploadedFiles = make(map[string][]byte)
+	m.modifiedAt = make(map[string]time.Time)
+	m.mu.Unlock()
+}
@@ -0,0 +1,269 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"gorm.io/gorm"
+)
+
+// SupplyInput holds the editable fields of a supply
+type SupplyInput struct {
+	Name              string
+	Unit              string
+	Quantity          float64 // counted stock; updating a supply replaces it
+	LowStockThreshold float64
+}
+
+// SupplyService manages the studio's supplies inventory (technicians and admins)
+type SupplyService interface {
+	// ListSupplies returns the inventory
+	ListSupplies(user *models.User) ([]models.Supply, error)
+
+	// ListLowSupplies returns the supplies that are at or below their low stock threshold
+	ListLowSupplies(user *models.User) ([]models.Supply, error)
+
+	// CreateSupply adds a supply to the inventory
+	CreateSupply(user *models.User, input SupplyInput) (*models.Supply, error)
+
+	// UpdateSupply changes a supply, including its counted stock
+	UpdateSupply(user *models.User, supplyID string, input SupplyInput) (*models.Supply, error)
+
+	// DeleteSupply retires a supply
+	DeleteSupply(user *models.User, supplyID string) error
+
+	// RecordUsage takes an amount of a supply used making an order out of stock (assigned technician only)
+	RecordUsage(technician *models.User, orderID string, supplyID uint, quantity float64) (*models.SupplyUsage, error)
+
+	// ListUsage returns what an order consumed (assigned technician and admins)
+	ListUsage(user *models.User, orderID string) ([]models.SupplyUsage, error)
+}
+
+// DefaultSupplyService implements SupplyService on top of the order and supply repositories
+type DefaultSupplyService struct {
+	orders   repositories.OrderRepository
+	supplies repositories.SupplyRepository
+}
+
+var supplyServiceInstance SupplyService
+
+// NewSupplyService creates a supply service using the given repositories
+func NewSupplyService(orders repositories.OrderRepository, supplies repositories.SupplyRepository) *DefaultSupplyService {
+	return &DefaultSupplyService{orders: orders, supplies: supplies}
+}
+
+// GetSupplyService returns the configured supply service
+// When none has been set, a service over the current database connection is returned
+func GetSupplyService() SupplyService {
+	if supplyServiceInstance != nil {
+		return supplyServiceInstance
+	}
+	db := config.GetDB()
+	return NewSupplyService(repositories.NewOrderRepository(db), repositories.NewSupplyRepository(db))
+}
+
+// SetSupplyService sets the supply service instance (primarily for testing)
+func SetSupplyService(service SupplyService) {
+	supplyServiceInstance = service
+}
+
+// ListSupplies returns the inventory
+func (s *DefaultSupplyService) ListSupplies(user *models.User) ([]models.Supply, error) {
+	if err := authorizeSupplies(user); err != nil {
+		return nil, err
+	}
+	supplies, err := s.supplies.List()
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to fetch supplies").Wrap(err)
+	}
+	return supplies, nil
+}
+
+// ListLowSupplies returns the supplies that are at or below their low stock threshold
+func (s *DefaultSupplyService) ListLowSupplies(user *models.User) ([]models.Supply, error) {
+	if err := authorizeSupplies(user); err != nil {
+		return nil, err
+	}
+	supplies, err := s.supplies.ListLow()
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to fetch low supplies").Wrap(err)
+	}
+	return supplies, nil
+}
+
+// CreateSupply adds a supply to the inventory
+func (s *DefaultSupplyService) CreateSupply(user *models.User, input SupplyInput) (*models.Supply, error) {
+	if err := authorizeSupplies(user); err != nil {
+		return nil, err
+	}
+	input, err := validateSupplyInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	supply := &models.Supply{
+		Name:              input.Name,
+		Unit:              input.Unit,
+		Quantity:          input.Quantity,
+		LowStockThreshold: input.LowStockThreshold,
+		CreatedByID:       user.ID,
+	}
+	if err := s.supplies.Create(supply); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to create supply").Wrap(err)
+	}
+	return supply, nil
+}
+
+// UpdateSupply changes a supply, including its counted stock
+// Usage already recorded against orders is unaffected
+func (s *DefaultSupplyService) UpdateSupply(user *models.User, supplyID string, input SupplyInput) (*models.Supply, error) {
+	if err := authorizeSupplies(user); err != nil {
+		return nil, err
+	}
+	input, err := validateSupplyInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	supply, err := s.find(supplyID)
+	if err != nil {
+		return nil, err
+	}
+
+	supply.Name = input.Name
+	supply.Unit = input.Unit
+	supply.Quantity = input.Quantity
+	supply.LowStockThreshold = input.LowStockThreshold
+
+	if err := s.supplies.Save(supply); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to update supply").Wrap(err)
+	}
+	return supply, nil
+}
+
+// DeleteSupply retires a supply
+func (s *DefaultSupplyService) DeleteSupply(user *models.User, supplyID string) error {
+	if err := authorizeSupplies(user); err != nil {
+		return err
+	}
+
+	supply, err := s.find(supplyID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.supplies.Delete(supply); err != nil {
+		return apierror.Internal("DATABASE_ERROR", "Failed to delete supply").Wrap(err)
+	}
+	return nil
+}
+
+// RecordUsage takes an amount of a supply used making an order out of stock
+// Usage can be recorded from acceptance until delivery, like progress updates
+func (s *DefaultSupplyService) RecordUsage(technician *models.User, orderID string, supplyID uint, quantity float64) (*models.SupplyUsage, error) {
+	if technician.Role != RoleTechnician {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only technicians can record supply usage")
+	}
+
+	order, err := findOrder(s.orders, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if !IsAssignedTo(order, technician) {
+		return nil, apierror.Forbidden("FORBIDDEN", "You can only record supply usage on orders assigned to you")
+	}
+	if !IsBooked(order) {
+		return nil, apierror.Unprocessable("INVALID_STATE", "Supply usage can only be recorded on accepted orders")
+	}
+
+	if quantity <= 0 {
+		return nil, apierror.Validation("Quantity must be greater than zero", nil)
+	}
+	supply, err := s.findByID(supplyID)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &models.SupplyUsage{OrderID: order.ID, SupplyID: supply.ID, Quantity: quantity, RecordedByID: technician.ID}
+	recorded, err := s.supplies.RecordUsage(usage)
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to record supply usage").Wrap(err)
+	}
+	if !recorded {
+		return nil, apierror.Unprocessable("INSUFFICIENT_STOCK", "Not enough of this supply is in stock").WithDetails(map[string]interface{}{
+			"in_stock": supply.Quantity,
+			"unit":     supply.Unit,
+		})
+	}
+	return usage, nil
+}
+
+// ListUsage returns what an order consumed
+func (s *DefaultSupplyService) ListUsage(user *models.User, orderID string) ([]models.SupplyUsage, error) {
+	order, err := findOrder(s.orders, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role != RoleAdmin && !(user.Role == RoleTechnician && IsAssignedTo(order, user)) {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only the assigned technician and admins can view supply usage")
+	}
+
+	usage, err := s.supplies.ListUsageForOrder(order.ID)
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load supply usage").Wrap(err)
+	}
+	return usage, nil
+}
+
+// find loads a supply by its path parameter
+func (s *DefaultSupplyService) find(supplyID string) (*models.Supply, error) {
+	id, err := strconv.ParseUint(supplyID, 10, 64)
+	if err != nil || id == 0 {
+		return nil, apierror.NotFound("SUPPLY_NOT_FOUND", "Supply not found")
+	}
+	return s.findByID(uint(id))
+}
+
+// findByID loads a supply that has not been retired
+func (s *DefaultSupplyService) findByID(id uint) (*models.Supply, error) {
+	supply, err := s.supplies.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apierror.NotFound("SUPPLY_NOT_FOUND", "Supply not found")
+		}
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load supply").Wrap(err)
+	}
+	return supply, nil
+}
+
+// authorizeSupplies checks that the user may manage the inventory (technicians and admins)
+func authorizeSupplies(user *models.User) error {
+	if user.Role != RoleTechnician && user.Role != RoleAdmin {
+		return apierror.Forbidden("FORBIDDEN", "Only technicians and admins can manage supplies")
+	}
+	return nil
+}
+
+// validateSupplyInput trims and checks the fields shared by create and update
+func validateSupplyInput(input SupplyInput) (SupplyInput, error) {
+	input.Name = strings.TrimSpace(input.Name)
+	input.Unit = strings.TrimSpace(input.Unit)
+	if input.Name == "" {
+		return input, apierror.Validation("Name is required", nil)
+	}
+	if input.Unit == "" {
+		return input, apierror.Validation("Unit is required", nil)
+	}
+	if input.Quantity < 0 {
+		return input, apierror.Validation("Quantity cannot be negative", nil)
+	}
+	if input.LowStockThreshold < 0 {
+		return input, apierror.Validation("Low stock threshold cannot be negative", nil)
+	}
+	return input, nil
+}
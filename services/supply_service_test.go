@@ -0,0 +1,151 @@
+package services
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// fakeSupplyRepository is an in-memory SupplyRepository
+type fakeSupplyRepository struct {
+	supplies map[uint]*models.Supply
+	usage    []models.SupplyUsage
+	nextID   uint
+}
+
+func newFakeSupplyRepository(supplies ...models.Supply) *fakeSupplyRepository {
+	repo := &fakeSupplyRepository{supplies: make(map[uint]*models.Supply), nextID: 1}
+	for i := range supplies {
+		supply := supplies[i]
+		if supply.ID >= repo.nextID {
+			repo.nextID = supply.ID + 1
+		}
+		repo.supplies[supply.ID] = &supply
+	}
+	return repo
+}
+
+func (r *fakeSupplyRepository) Create(supply *models.Supply) error {
+	supply.ID = r.nextID
+	r.nextID++
+	stored := *supply
+	r.supplies[supply.ID] = &stored
+	return nil
+}
+
+func (r *fakeSupplyRepository) Save(supply *models.Supply) error {
+	stored := *supply
+	r.supplies[supply.ID] = &stored
+	return nil
+}
+
+func (r *fakeSupplyRepository) Delete(supply *models.Supply) error {
+	delete(r.supplies, supply.ID)
+	return nil
+}
+
+func (r *fakeSupplyRepository) FindByID(id uint) (*models.Supply, error) {
+	supply, ok := r.supplies[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	found := *supply
+	return &found, nil
+}
+
+func (r *fakeSupplyRepository) List() ([]models.Supply, error) {
+	var supplies []models.Supply
+	for _, supply := range r.supplies {
+		supplies = append(supplies, *supply)
+	}
+	return supplies, nil
+}
+
+func (r *fakeSupplyRepository) ListLow() ([]models.Supply, error) {
+	var supplies []models.Supply
+	for _, supply := range r.supplies {
+		if supply.Quantity <= supply.LowStockThreshold {
+			supplies = append(supplies, *supply)
+		}
+	}
+	return supplies, nil
+}
+
+func (r *fakeSupplyRepository) RecordUsage(usage *models.SupplyUsage) (bool, error) {
+	supply, ok := r.supplies[usage.SupplyID]
+	if !ok || supply.Quantity < usage.Quantity {
+		return false, nil
+	}
+	supply.Quantity -= usage.Quantity
+	usage.Supply = *supply
+	r.usage = append(r.usage, *usage)
+	return true, nil
+}
+
+func (r *fakeSupplyRepository) ListUsageForOrder(orderID uint) ([]models.SupplyUsage, error) {
+	var usage []models.SupplyUsage
+	for _, u := range r.usage {
+		if u.OrderID == orderID {
+			usage = append(usage, u)
+		}
+	}
+	return usage, nil
+}
+
+func TestSupplyService_RecordUsage(t *testing.T) {
+	orders := newFakeOrderRepository(
+		models.Order{ID: 1, CustomerID: testCustomer.ID, Status: StatusInProduction, TechnicianID: uintPtr(testTechnician.ID)},
+		models.Order{ID: 2, CustomerID: testCustomer.ID, Status: StatusSubmitted, TechnicianID: uintPtr(testTechnician.ID)},
+		models.Order{ID: 3, CustomerID: testCustomer.ID, Status: StatusInProduction, TechnicianID: uintPtr(otherTech.ID)},
+	)
+	supplies := newFakeSupplyRepository(models.Supply{ID: 1, Name: "Top coat", Unit: "ml", Quantity: 10, LowStockThreshold: 2})
+	service := NewSupplyService(orders, supplies)
+
+	usage, err := service.RecordUsage(testTechnician, "1", 1, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, 6.0, usage.Supply.Quantity)
+	assert.Equal(t, testTechnician.ID, usage.RecordedByID)
+
+	_, err = service.RecordUsage(testTechnician, "1", 1, 7)
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "INSUFFICIENT_STOCK")
+	_, err = service.RecordUsage(testTechnician, "1", 1, 0)
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+	_, err = service.RecordUsage(testTechnician, "1", 9, 1)
+	assertAPIError(t, err, http.StatusNotFound, "SUPPLY_NOT_FOUND")
+
+	// Only booked orders assigned to the technician
+	_, err = service.RecordUsage(testTechnician, "2", 1, 1)
+	assertAPIError(t, err, http.StatusUnprocessableEntity, "INVALID_STATE")
+	_, err = service.RecordUsage(testTechnician, "3", 1, 1)
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+	_, err = service.RecordUsage(&models.User{ID: 9, Role: RoleAdmin}, "1", 1, 1)
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+
+	// Nothing failed was taken out of stock
+	assert.Equal(t, 6.0, supplies.supplies[1].Quantity)
+	assert.Len(t, supplies.usage, 1)
+}
+
+func TestSupplyService_ManageInventory(t *testing.T) {
+	service := NewSupplyService(newFakeOrderRepository(), newFakeSupplyRepository())
+	admin := &models.User{ID: 9, Role: RoleAdmin}
+
+	supply, err := service.CreateSupply(admin, SupplyInput{Name: "  Acetone ", Unit: "ml", Quantity: 1000, LowStockThreshold: 250})
+	assert.NoError(t, err)
+	assert.Equal(t, "Acetone", supply.Name)
+
+	_, err = service.CreateSupply(testTechnician, SupplyInput{Name: "Acetone", Unit: " "})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+	_, err = service.CreateSupply(testTechnician, SupplyInput{Name: "Acetone", Unit: "ml", LowStockThreshold: -1})
+	assertAPIError(t, err, http.StatusBadRequest, "VALIDATION_ERROR")
+	_, err = service.CreateSupply(testCustomer, SupplyInput{Name: "Acetone", Unit: "ml"})
+	assertAPIError(t, err, http.StatusForbidden, "FORBIDDEN")
+
+	_, err = service.UpdateSupply(testTechnician, "abc", SupplyInput{Name: "Acetone", Unit: "ml"})
+	assertAPIError(t, err, http.StatusNotFound, "SUPPLY_NOT_FOUND")
+	assert.NoError(t, service.DeleteSupply(testTechnician, uintString(supply.ID)))
+	assertAPIError(t, service.DeleteSupply(testTechnician, uintString(supply.ID)), http.StatusNotFound, "SUPPLY_NOT_FOUND")
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/cache"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"github.com/kendall-kelly/kendalls-nails-api/utils"
+	"gorm.io/gorm"
+)
+
+// UserService resolves the application user behind an authenticated request
+type UserService interface {
+	// FindByAuth0ID returns the user profile for the identity provider subject
+	FindByAuth0ID(auth0ID string) (*models.User, error)
+
+	// EmailInUse reports whether an account other than exceptUserID already uses the email
+	// The email is normalized before comparing; pass 0 to check against every account
+	EmailInUse(email string, exceptUserID uint) (bool, error)
+
+	// Register returns the account of a signed-up identity and reports whether a new account was created
+	// Registration is safe to retry: an identity that already has an account gets it back unchanged.
+	// An identity whose account was deleted gets that account back, with its ID and order history,
+	// updated with the new profile; a deleted account that only shares the email stays deleted
+	Register(profile models.User) (*models.User, bool, error)
+}
+
+// DefaultUserService implements UserService on top of a UserRepository
+type DefaultUserService struct {
+	users repositories.UserRepository
+}
+
+var userServiceInstance UserService
+
+// NewUserService creates a user service using the given repository
+func NewUserService(users repositories.UserRepository) *DefaultUserService {
+	return &DefaultUserService{users: users}
+}
+
+// GetUserService returns the configured user service
+// When none has been set, a service over the current database connection is returned
+func GetUserService() UserService {
+	if userServiceInstance != nil {
+		return userServiceInstance
+	}
+	return NewUserService(cachedUsers(repositories.NewUserRepository(config.GetDB())))
+}
+
+// cachedUsers puts the configured cache, if any, in front of identity lookups
+func cachedUsers(users repositories.UserRepository) repositories.UserRepository {
+	if store := cache.Default(); store != nil {
+		return repositories.NewCachedUserRepository(users, store, cache.DefaultTTL())
+	}
+	return users
+}
+
+// SetUserService sets the user service instance (primarily for testing)
+func SetUserService(service UserService) {
+	userServiceInstance = service
+}
+
+// FindByAuth0ID returns the user profile for the identity provider subject
+func (s *DefaultUserService) FindByAuth0ID(auth0ID string) (*models.User, error) {
+	user, err := s.users.FindByAuth0ID(auth0ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apierror.NotFound("USER_NOT_FOUND", "User profile not found. Please create a profile first.")
+		}
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load user profile").Wrap(err)
+	}
+	return user, nil
+}
+
+// EmailInUse reports whether an account other than exceptUserID already uses the email
+func (s *DefaultUserService) EmailInUse(email string, exceptUserID uint) (bool, error) {
+	user, err := s.users.FindByEmail(utils.NormalizeEmail(email))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, apierror.Internal("DATABASE_ERROR", "Failed to check email").Wrap(err)
+	}
+	return user.ID != exceptUserID, nil
+}
+
+// Register returns the account of a signed-up identity and reports whether a new account was created
+func (s *DefaultUserService) Register(profile models.User) (*models.User, bool, error) {
+	exists := apierror.Conflict("USER_EXISTS", "A user with this email already exists")
+
+	if existing, err := s.users.FindByAuth0ID(profile.Auth0ID); err == nil {
+		return existing, false, nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, apierror.Internal("DATABASE_ERROR", "Failed to load user profile").Wrap(err)
+	}
+
+	// Reject emails that differ from another identity's active account only by case or formatting
+	inUse, err := s.EmailInUse(profile.Email, 0)
+	if err != nil {
+		return nil, false, err
+	}
+	if inUse {
+		return nil, false, exists
+	}
+
+	deleted, err := s.users.FindDeletedByAuth0ID(profile.Auth0ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, apierror.Internal("DATABASE_ERROR", "Failed to load user profile").Wrap(err)
+	}
+	if deleted != nil {
+		deleted.Name = profile.Name
+		deleted.Email = profile.Email
+		deleted.Role = profile.Role
+		deleted.Locale = profile.Locale
+		deleted.SizeUnit = profile.SizeUnit
+		if err := s.users.Restore(deleted); err != nil {
+			if isUniqueViolation(err) {
+				return s.registeredConcurrently(profile.Auth0ID, exists)
+			}
+			return nil, false, apierror.Internal("DATABASE_ERROR", "Failed to restore user").Wrap(err)
+		}
+		return deleted, false, nil
+	}
+
+	user := profile
+	user.ID = 0
+	created, err := s.users.CreateIfAbsent(&user)
+	if err != nil {
+		// Another identity can take the email between the check above and the insert
+		if isUniqueViolation(err) {
+			return nil, false, exists
+		}
+		return nil, false, apierror.Internal("DATABASE_ERROR", "Failed to create user").Wrap(err)
+	}
+	if !created {
+		// A concurrent request of the same identity created the account first
+		return s.registeredConcurrently(profile.Auth0ID, exists)
+	}
+	return &user, true, nil
+}
+
+// registeredConcurrently returns the account that a concurrent registration of the identity created
+// When the identity still has no active account, the conflict was on the email and conflict is returned
+func (s *DefaultUserService) registeredConcurrently(auth0ID string, conflict error) (*models.User, bool, error) {
+	existing, err := s.users.FindByAuth0ID(auth0ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, false, conflict
+		}
+		return nil, false, apierror.Internal("DATABASE_ERROR", "Failed to load user profile").Wrap(err)
+	}
+	return existing, false, nil
+}
+
+// isUniqueViolation reports whether a write failed on a unique index (works with both PostgreSQL and SQLite)
+func isUniqueViolation(err error) bool {
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "duplicate") || strings.Contains(message, "unique")
+}
@@ -0,0 +1,414 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kendall-kelly/kendalls-nails-api/apierror"
+	"github.com/kendall-kelly/kendalls-nails-api/config"
+	"github.com/kendall-kelly/kendalls-nails-api/jobs"
+	"github.com/kendall-kelly/kendalls-nails-api/models"
+	"github.com/kendall-kelly/kendalls-nails-api/repositories"
+	"github.com/kendall-kelly/kendalls-nails-api/utils"
+	"gorm.io/gorm"
+)
+
+// Webhook events
+const (
+	WebhookOrderCreated       = "order.created"
+	WebhookOrderStatusChanged = "order.status_changed"
+	WebhookMessageCreated     = "message.created"
+	WebhookGiftInvited        = "gift.invited"
+	WebhookQuoteReviewed      = "quote.reviewed"
+)
+
+// WebhookEvents lists every event a webhook can subscribe to
+var WebhookEvents = []string{WebhookOrderCreated, WebhookOrderStatusChanged, WebhookMessageCreated, WebhookGiftInvited, WebhookQuoteReviewed}
+
+// Headers sent with every webhook request
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature" // "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>">"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+)
+
+// Webhook delivery settings
+const (
+	MaxWebhookAttempts    = 8
+	WebhookPollInterval   = 5 * time.Second
+	DefaultDeliveryLog    = 50
+	MaxDeliveryLog        = 100
+	webhookRetryBase      = 30 * time.Second // doubled after every failed attempt
+	webhookRetryMax       = 6 * time.Hour
+	webhookLease          = 2 * time.Minute // longer than a request can take, so a claimed delivery is not sent twice
+	webhookRequestTimeout = 10 * time.Second
+	webhookBatchSize      = 50
+	webhookSecretPrefix   = "whsec_"
+	webhookEventPrefix    = "evt_"
+)
+
+// WebhookInput holds the fields for registering a webhook
+type WebhookInput struct {
+	URL    string
+	Events []string // all events when empty
+}
+
+// webhookPayload is the JSON body posted to webhooks
+type webhookPayload struct {
+	ID        string      `json:"id"` // identical across the webhooks an event is sent to, for deduplication
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// WebhookPublisher queues event notifications for the webhooks subscribed to them
+type WebhookPublisher interface {
+	// Publish queues a delivery of the event to every subscribed webhook
+	Publish(event string, data interface{}) error
+}
+
+// WebhookService manages webhooks and delivers the events queued for them
+type WebhookService interface {
+	WebhookPublisher
+
+	// CreateWebhook registers a webhook (admins only)
+	// The returned webhook carries its signing secret in SigningSecret; it is not shown again
+	CreateWebhook(admin *models.User, input WebhookInput) (*models.Webhook, error)
+
+	// ListWebhooks returns every webhook (admins only)
+	ListWebhooks(admin *models.User) ([]models.Webhook, error)
+
+	// DeleteWebhook removes a webhook; deliveries still queued for it are abandoned (admins only)
+	DeleteWebhook(admin *models.User, webhookID string) error
+
+	// ListDeliveries returns the webhook's most recent deliveries, newest first (admins only)
+	ListDeliveries(admin *models.User, webhookID string, limit int) ([]models.WebhookDelivery, error)
+
+	// DeliverDue sends the deliveries whose next attempt is due and returns how many were attempted
+	DeliverDue(limit int) (int, error)
+}
+
+// DefaultWebhookService implements WebhookService on top of a WebhookRepository
+type DefaultWebhookService struct {
+	webhooks   repositories.WebhookRepository
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+var webhookServiceInstance WebhookService
+
+// NewWebhookService creates a webhook service using the given repository
+func NewWebhookService(webhooks repositories.WebhookRepository) *DefaultWebhookService {
+	return &DefaultWebhookService{
+		webhooks:   webhooks,
+		httpClient: &http.Client{Timeout: webhookRequestTimeout},
+		now:        time.Now,
+	}
+}
+
+// GetWebhookService returns the configured webhook service
+// When none has been set, a service over the current database connection is returned
+func GetWebhookService() WebhookService {
+	if webhookServiceInstance != nil {
+		return webhookServiceInstance
+	}
+	return NewWebhookService(repositories.NewWebhookRepository(config.GetDB()))
+}
+
+// SetWebhookService sets the webhook service instance (primarily for testing)
+func SetWebhookService(service WebhookService) {
+	webhookServiceInstance = service
+}
+
+// WebhookDeliveryJob returns the background job that sends due deliveries, including retries
+func WebhookDeliveryJob(service WebhookService) jobs.Job {
+	return jobs.Job{
+		Name:     "webhook_delivery",
+		Interval: WebhookPollInterval,
+		Run: func(ctx context.Context) error {
+			_, err := service.DeliverDue(webhookBatchSize)
+			return err
+		},
+	}
+}
+
+// SignWebhookPayload returns the signature header value for a payload sent at the given time
+// Receivers recompute the HMAC with their secret and reject stale timestamps to prevent replays
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, hex.EncodeToString(mac.Sum(nil)))
+}
+
+// CreateWebhook registers a webhook (admins only)
+func (s *DefaultWebhookService) CreateWebhook(admin *models.User, input WebhookInput) (*models.Webhook, error) {
+	if admin.Role != RoleAdmin {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only admins can manage webhooks")
+	}
+
+	endpoint, err := url.Parse(strings.TrimSpace(input.URL))
+	if err != nil || (endpoint.Scheme != "https" && endpoint.Scheme != "http") || endpoint.Host == "" {
+		return nil, apierror.Validation("Invalid webhook URL", map[string]string{
+			"url": "must be an absolute http or https URL",
+		})
+	}
+
+	events := input.Events
+	if len(events) == 0 {
+		events = WebhookEvents
+	}
+	for _, event := range events {
+		if !isWebhookEvent(event) {
+			return nil, apierror.Validation("Invalid event", map[string]string{
+				"events": "must be one of: " + strings.Join(WebhookEvents, ", "),
+			})
+		}
+	}
+
+	secret, err := utils.NewSecret(webhookSecretPrefix)
+	if err != nil {
+		return nil, apierror.Internal("TOKEN_ERROR", "Failed to generate webhook secret").Wrap(err)
+	}
+
+	webhook := &models.Webhook{
+		URL:         endpoint.String(),
+		Events:      strings.Join(events, " "),
+		Secret:      secret,
+		CreatedByID: admin.ID,
+	}
+	if err := s.webhooks.Create(webhook); err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to create webhook").Wrap(err)
+	}
+	webhook.SigningSecret = secret
+	return webhook, nil
+}
+
+// ListWebhooks returns every webhook (admins only)
+func (s *DefaultWebhookService) ListWebhooks(admin *models.User) ([]models.Webhook, error) {
+	if admin.Role != RoleAdmin {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only admins can manage webhooks")
+	}
+
+	webhooks, err := s.webhooks.List()
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load webhooks").Wrap(err)
+	}
+	return webhooks, nil
+}
+
+// DeleteWebhook removes a webhook; deliveries still queued for it are abandoned (admins only)
+func (s *DefaultWebhookService) DeleteWebhook(admin *models.User, webhookID string) error {
+	webhook, err := s.findWebhook(admin, webhookID)
+	if err != nil {
+		return err
+	}
+	if err := s.webhooks.Delete(webhook); err != nil {
+		return apierror.Internal("DATABASE_ERROR", "Failed to delete webhook").Wrap(err)
+	}
+	return nil
+}
+
+// ListDeliveries returns the webhook's most recent deliveries, newest first (admins only)
+func (s *DefaultWebhookService) ListDeliveries(admin *models.User, webhookID string, limit int) ([]models.WebhookDelivery, error) {
+	webhook, err := s.findWebhook(admin, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = DefaultDeliveryLog
+	}
+	if limit > MaxDeliveryLog {
+		limit = MaxDeliveryLog
+	}
+	deliveries, err := s.webhooks.ListDeliveries(webhook.ID, limit)
+	if err != nil {
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load webhook deliveries").Wrap(err)
+	}
+	return deliveries, nil
+}
+
+// Publish queues a delivery of the event to every subscribed webhook
+func (s *DefaultWebhookService) Publish(event string, data interface{}) error {
+	webhooks, err := s.webhooks.List()
+	if err != nil {
+		return err
+	}
+	var subscribed []models.Webhook
+	for _, webhook := range webhooks {
+		if webhook.Subscribes(event) {
+			subscribed = append(subscribed, webhook)
+		}
+	}
+	if len(subscribed) == 0 {
+		return nil
+	}
+
+	eventID, err := utils.NewSecret(webhookEventPrefix)
+	if err != nil {
+		return err
+	}
+	now := s.now()
+	body, err := json.Marshal(webhookPayload{ID: eventID, Event: event, CreatedAt: now.UTC(), Data: data})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	deliveries := make([]models.WebhookDelivery, 0, len(subscribed))
+	for _, webhook := range subscribed {
+		deliveries = append(deliveries, models.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventID:       eventID,
+			Event:         event,
+			Payload:       string(body),
+			Status:        models.DeliveryPending,
+			NextAttemptAt: now,
+		})
+	}
+	return s.webhooks.CreateDeliveries(deliveries)
+}
+
+// DeliverDue sends the deliveries whose next attempt is due and returns how many were attempted
+// Each delivery is claimed first, so several API instances can run the dispatcher side by side
+func (s *DefaultWebhookService) DeliverDue(limit int) (int, error) {
+	now := s.now()
+	due, err := s.webhooks.ListDueDeliveries(now, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	attempted := 0
+	for i := range due {
+		delivery := &due[i]
+		claimed, err := s.webhooks.ClaimDelivery(delivery, now.Add(webhookLease))
+		if err != nil {
+			return attempted, err
+		}
+		if !claimed {
+			continue
+		}
+		attempted++
+
+		webhook, err := s.webhooks.FindByID(delivery.WebhookID)
+		if err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return attempted, err
+			}
+			message := "webhook was deleted"
+			delivery.Status = models.DeliveryFailed
+			delivery.LastError = &message
+		} else {
+			s.send(webhook, delivery)
+		}
+
+		if err := s.webhooks.SaveDelivery(delivery); err != nil {
+			return attempted, err
+		}
+	}
+	return attempted, nil
+}
+
+// send makes one delivery attempt and records its outcome on the delivery
+func (s *DefaultWebhookService) send(webhook *models.Webhook, delivery *models.WebhookDelivery) {
+	now := s.now()
+	delivery.LastAttemptAt = &now
+	delivery.LastStatusCode = nil
+	delivery.LastError = nil
+
+	err := s.post(webhook, delivery, now)
+	if err == nil {
+		delivery.Status = models.DeliverySucceeded
+		delivery.DeliveredAt = &now
+		return
+	}
+
+	message := err.Error()
+	delivery.LastError = &message
+	if delivery.Attempts >= MaxWebhookAttempts {
+		delivery.Status = models.DeliveryFailed
+		return
+	}
+	delivery.NextAttemptAt = now.Add(webhookRetryDelay(delivery.Attempts))
+}
+
+// post sends the signed payload and returns an error unless the webhook answered with a 2xx status
+func (s *DefaultWebhookService) post(webhook *models.Webhook, delivery *models.WebhookDelivery, now time.Time) error {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, delivery.Event)
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(webhook.Secret, now.Unix(), body))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	status := resp.StatusCode
+	delivery.LastStatusCode = &status
+	if status < 200 || status >= 300 {
+		return fmt.Errorf("webhook returned status %d", status)
+	}
+	return nil
+}
+
+// findWebhook loads a webhook by its ID parameter for an admin
+func (s *DefaultWebhookService) findWebhook(admin *models.User, webhookID string) (*models.Webhook, error) {
+	if admin.Role != RoleAdmin {
+		return nil, apierror.Forbidden("FORBIDDEN", "Only admins can manage webhooks")
+	}
+
+	id, err := strconv.ParseUint(webhookID, 10, 64)
+	if err != nil || id == 0 {
+		return nil, apierror.NotFound("WEBHOOK_NOT_FOUND", "Webhook not found")
+	}
+	webhook, err := s.webhooks.FindByID(uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apierror.NotFound("WEBHOOK_NOT_FOUND", "Webhook not found")
+		}
+		return nil, apierror.Internal("DATABASE_ERROR", "Failed to load webhook").Wrap(err)
+	}
+	return webhook, nil
+}
+
+// webhookRetryDelay returns how long to wait after the given number of failed attempts
+func webhookRetryDelay(attempts int) time.Duration {
+	delay := webhookRetryBase
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= webhookRetryMax {
+			return webhookRetryMax
+		}
+	}
+	return delay
+}
+
+// isWebhookEvent reports whether the value is a known webhook event
+func isWebhookEvent(event string) bool {
+	for _, known := range WebhookEvents {
+		if event == known {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,25 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookRetryDelay(t *testing.T) {
+	assert.Equal(t, 30*time.Second, webhookRetryDelay(1))
+	assert.Equal(t, time.Minute, webhookRetryDelay(2))
+	assert.Equal(t, 8*time.Minute, webhookRetryDelay(5))
+	assert.Equal(t, webhookRetryMax, webhookRetryDelay(MaxWebhookAttempts+10))
+}
+
+func TestSignWebhookPayload(t *testing.T) {
+	body := []byte(`{"event":"order.created"}`)
+	signature := SignWebhookPayload("whsec_test", 1700000000, body)
+
+	assert.Equal(t, signature, SignWebhookPayload("whsec_test", 1700000000, body))
+	assert.Regexp(t, `^t=1700000000,v1=[0-9a-f]{64}$`, signature)
+	assert.NotEqual(t, signature, SignWebhookPayload("whsec_other", 1700000000, body))
+	assert.NotEqual(t, signature, SignWebhookPayload("whsec_test", 1700000001, body))
+}
@@ -0,0 +1,152 @@
+// Package sharecard composes the branded image customers share of a delivered set
+// A card shows the design photo with the technician's credit and the order date beneath it;
+// text is drawn with a built-in bitmap font so that rendering needs no font files
+package sharecard
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Card dimensions in pixels, a 4:5 portrait that social apps show uncropped
+const (
+	Width  = 1080
+	Height = 1350
+)
+
+const (
+	margin    = 60
+	photoSize = Width - 2*margin
+	brandName = "Kendall's Nails"
+)
+
+var (
+	backgroundColor  = color.RGBA{R: 0xF7, G: 0xD6, B: 0xE0, A: 0xFF}
+	placeholderColor = color.RGBA{R: 0xFB, G: 0xEA, B: 0xEF, A: 0xFF}
+	inkColor         = color.RGBA{R: 0x3D, G: 0x1F, B: 0x2B, A: 0xFF}
+	accentColor      = color.RGBA{R: 0xB0, G: 0x4A, B: 0x6E, A: 0xFF}
+)
+
+// Card is the content of a share card
+type Card struct {
+	Photo      image.Image // the design photo; nil draws a plain panel in its place
+	Technician string      // name credited for the set; empty omits the credit
+	OrderedAt  time.Time
+}
+
+// Render draws the card and returns it PNG-encoded
+func Render(card Card) ([]byte, error) {
+	canvas := image.NewRGBA(image.Rect(0, 0, Width, Height))
+	draw.Draw(canvas, canvas.Bounds(), image.NewUniform(backgroundColor), image.Point{}, draw.Src)
+
+	photoArea := image.Rect(margin, margin, margin+photoSize, margin+photoSize)
+	if card.Photo != nil && !card.Photo.Bounds().Empty() {
+		drawCover(canvas, photoArea, card.Photo)
+	} else {
+		draw.Draw(canvas, photoArea, image.NewUniform(placeholderColor), image.Point{}, draw.Src)
+	}
+
+	y := photoArea.Max.Y + 40
+	drawText(canvas, margin, y, 8, inkColor, "My custom set")
+	y += glyphHeight*8 + 34
+	if card.Technician != "" {
+		drawText(canvas, margin, y, 5, accentColor, "By "+card.Technician)
+	}
+	y += glyphHeight*5 + 24
+	if !card.OrderedAt.IsZero() {
+		drawText(canvas, margin, y, 4, inkColor, "Ordered "+card.OrderedAt.Format("Jan 2, 2006"))
+	}
+
+	brandScale := 3
+	brandWidth := textWidth(brandName, brandScale)
+	drawText(canvas, Width-margin-brandWidth, Height-margin/2-glyphHeight*brandScale, brandScale, accentColor, brandName)
+
+	var out bytes.Buffer
+	if err := png.Encode(&out, canvas); err != nil {
+		return nil, fmt.Errorf("failed to encode share card: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+// drawCover scales the photo to fill the area, cropping whatever overflows, centred
+// Nearest-neighbour sampling keeps this dependency-free; photos are scaled down far more often than up
+func drawCover(dst *image.RGBA, area image.Rectangle, photo image.Image) {
+	src := photo.Bounds()
+	// Scale by the smaller of the two ratios so that the photo covers the area in both directions
+	scale := float64(src.Dx()) / float64(area.Dx())
+	if ratio := float64(src.Dy()) / float64(area.Dy()); ratio < scale {
+		scale = ratio
+	}
+	offsetX := (float64(src.Dx()) - float64(area.Dx())*scale) / 2
+	offsetY := (float64(src.Dy()) - float64(area.Dy())*scale) / 2
+
+	for y := 0; y < area.Dy(); y++ {
+		sy := src.Min.Y + int(offsetY+(float64(y)+0.5)*scale)
+		for x := 0; x < area.Dx(); x++ {
+			sx := src.Min.X + int(offsetX+(float64(x)+0.5)*scale)
+			dst.Set(area.Min.X+x, area.Min.Y+y, photo.At(sx, sy))
+		}
+	}
+}
+
+// drawText draws a line of text with its top-left corner at (x, y), each font pixel scale pixels square
+// Text that would run past the right margin is cut short with an ellipsis
+func drawText(dst *image.RGBA, x, y, scale int, ink color.Color, text string) {
+	runes := []rune(printable(text))
+	advance := (glyphWidth + 1) * scale
+	if maxRunes := (Width - margin - x) / advance; len(runes) > maxRunes {
+		runes = append(runes[:maxRunes-3], '.', '.', '.')
+	}
+
+	fill := image.NewUniform(ink)
+	for i, r := range runes {
+		glyph := glyphs[r]
+		left := x + i*advance
+		for row, bits := range glyph {
+			for col := 0; col < glyphWidth; col++ {
+				if bits&(1<<(glyphWidth-1-col)) == 0 {
+					continue
+				}
+				pixel := image.Rect(left+col*scale, y+row*scale, left+(col+1)*scale, y+(row+1)*scale)
+				draw.Draw(dst, pixel, fill, image.Point{}, draw.Src)
+			}
+		}
+	}
+}
+
+// textWidth returns how wide text draws at the scale, without the trailing letter gap
+func textWidth(text string, scale int) int {
+	count := len([]rune(printable(text)))
+	if count == 0 {
+		return 0
+	}
+	return count*(glyphWidth+1)*scale - scale
+}
+
+// printable maps text onto the font: capitals, accents removed, and '?' for anything else
+func printable(text string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(strings.TrimSpace(text)) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		r = unicode.ToUpper(r)
+		if unicode.IsSpace(r) {
+			r = ' '
+		}
+		if _, ok := glyphs[r]; !ok {
+			r = '?'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,59 @@
+package sharecard
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	// A wide red photo is cropped to fill the square photo area
+	photo := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 40; x++ {
+			photo.Set(x, y, color.RGBA{R: 0xFF, A: 0xFF})
+		}
+	}
+
+	data, err := Render(Card{Photo: photo, Technician: "José Alvarez", OrderedAt: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)})
+	assert.NoError(t, err)
+
+	card, err := png.Decode(bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, Width, Height), card.Bounds())
+	assert.Equal(t, color.RGBA{R: 0xFF, A: 0xFF}, color.RGBAModel.Convert(card.At(margin, margin)))
+	assert.Equal(t, color.RGBA{R: 0xFF, A: 0xFF}, color.RGBAModel.Convert(card.At(margin+photoSize-1, margin+photoSize-1)))
+	assert.Equal(t, backgroundColor, color.RGBAModel.Convert(card.At(margin-1, margin)))
+}
+
+func TestRender_WithoutPhoto(t *testing.T) {
+	data, err := Render(Card{})
+	assert.NoError(t, err)
+
+	card, err := png.Decode(bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, placeholderColor, color.RGBAModel.Convert(card.At(Width/2, Width/2)))
+}
+
+func TestPrintable(t *testing.T) {
+	assert.Equal(t, "BY JOSE", printable(" By José "))
+	assert.Equal(t, "NAILS ?", printable("Nails 💅"))
+}
+
+func TestDrawText_TruncatesLongText(t *testing.T) {
+	canvas := image.NewRGBA(image.Rect(0, 0, Width, Height))
+	drawText(canvas, margin, margin, 5, inkColor, strings.Repeat("W", 100))
+
+	// Nothing is drawn past the right margin
+	for y := margin; y < margin+glyphHeight*5; y++ {
+		for x := Width - margin; x < Width; x++ {
+			assert.Equal(t, color.RGBA{}, canvas.RGBAAt(x, y))
+		}
+	}
+}
@@ -42,7 +42,7 @@ func (suite *FileUploadAcceptanceTestSuite) SetupSuite() {
 	suite.NoError(err)
 	suite.db = db
 
-	err = db.AutoMigrate(&models.User{}, &models.Order{})
+	err = models.MigrateUp(db)
 	suite.NoError(err)
 
 	config.SetDB(db)
@@ -149,7 +149,7 @@ func (suite *FileUploadAcceptanceTestSuite) TestCompleteFileUploadWorkflow_Accep
 	suite.NoError(err)
 
 	// Step 2: Customer creates an order with a PNG image
-	imageContent := []byte("This is a fake PNG image content for testing purposes")
+	imageContent := []byte("\x89PNG\r\n\x1a\nThis is a fake PNG image content for testing purposes")
 	req, err := suite.createMultipartRequest(
 		suite.server.URL+"/api/v1/orders",
 		"my-nail-design.png",
@@ -322,7 +322,7 @@ func (suite *FileUploadAcceptanceTestSuite) TestFileUploadValidation_Acceptance(
 	assert.False(suite.T(), errorResponse["success"].(bool))
 	errorData := errorResponse["error"].(map[string]interface{})
 	assert.Equal(suite.T(), "INVALID_FILE_FORMAT", errorData["code"])
-	assert.Contains(suite.T(), errorData["message"], "Only .png files are allowed")
+	assert.Contains(suite.T(), errorData["message"], "Only PNG images are allowed")
 
 	// Verify no order was created
 	var count int64
@@ -342,7 +342,7 @@ func (suite *FileUploadAcceptanceTestSuite) TestMultipleOrdersWithImages_Accepta
 	suite.db.Create(&customer)
 
 	// Create first order with image
-	image1Content := []byte("First design image content")
+	image1Content := []byte("\x89PNG\r\n\x1a\nFirst design image content")
 	req1, err := suite.createMultipartRequest(
 		suite.server.URL+"/api/v1/orders",
 		"design1.png",
@@ -364,7 +364,7 @@ func (suite *FileUploadAcceptanceTestSuite) TestMultipleOrdersWithImages_Accepta
 	s3Key1 := order1Data["image_s3_key"].(string)
 
 	// Create second order with different image
-	image2Content := []byte("Second design image content - different content")
+	image2Content := []byte("\x89PNG\r\n\x1a\nSecond design image content - different content")
 	req2, err := suite.createMultipartRequest(
 		suite.server.URL+"/api/v1/orders",
 		"design2.png",
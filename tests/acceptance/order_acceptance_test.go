@@ -52,7 +52,7 @@ func (suite *OrderAcceptanceTestSuite) SetupSuite() {
 	suite.NoError(err)
 	suite.db = db
 
-	err = db.AutoMigrate(&models.User{}, &models.Order{})
+	err = models.MigrateUp(db)
 	suite.NoError(err)
 
 	config.SetDB(db)
@@ -77,6 +77,7 @@ func (suite *OrderAcceptanceTestSuite) TearDownSuite() {
 func (suite *OrderAcceptanceTestSuite) SetupTest() {
 	// Clean up database before each test
 	suite.db.Exec("DELETE FROM orders")
+	suite.db.Exec("DELETE FROM addresses")
 	suite.db.Exec("DELETE FROM users")
 }
 
@@ -91,6 +92,7 @@ func (suite *OrderAcceptanceTestSuite) createRouter() *gin.Engine {
 		v1.POST("/orders", suite.mockAuthMiddleware("auth0|customer", "customer"), controllers.CreateOrder)
 		v1.GET("/orders", suite.mockAuthMiddleware("auth0|customer", "customer"), controllers.ListOrders)
 		v1.GET("/orders/:id", suite.mockAuthMiddleware("auth0|customer", "customer"), controllers.GetOrder)
+		v1.POST("/users/me/addresses", suite.mockAuthMiddleware("auth0|customer", "customer"), controllers.CreateMyAddress)
 
 		// Routes for technician scenarios
 		v1.GET("/orders-tech", suite.mockAuthMiddleware("auth0|tech", "technician"), controllers.ListOrders)
@@ -134,6 +136,10 @@ func (suite *OrderAcceptanceTestSuite) makeRequest(method, path string, body int
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
+	// Order reviews and status changes require a precondition; these tests make no concurrent changes
+	if method == http.MethodPut {
+		req.Header.Set("If-Match", "*")
+	}
 
 	resp, err := http.DefaultClient.Do(req)
 	suite.NoError(err)
@@ -458,10 +464,11 @@ func (suite *OrderAcceptanceTestSuite) TestOrderReview_CompleteAcceptWorkflow_Ac
 
 	// Step 1: Customer creates an order
 	order := models.Order{
-		Description: "Order for acceptance workflow",
-		Quantity:    2,
-		Status:      "submitted",
-		CustomerID:  customer.ID,
+		Description:     "Order for acceptance workflow",
+		Quantity:        2,
+		Status:          "submitted",
+		CustomerID:      customer.ID,
+		ShippingAddress: &models.AddressSnapshot{Recipient: "Test Customer", Line1: "1 Main St", City: "Portland", PostalCode: "97201", Country: "US"},
 	}
 	suite.db.Create(&order)
 
@@ -663,10 +670,11 @@ func (suite *OrderAcceptanceTestSuite) TestOrderReview_AlreadyReviewed_Acceptanc
 
 	// Create an order
 	order := models.Order{
-		Description: "Order to test double review",
-		Quantity:    2,
-		Status:      "submitted",
-		CustomerID:  customer.ID,
+		Description:     "Order to test double review",
+		Quantity:        2,
+		Status:          "submitted",
+		CustomerID:      customer.ID,
+		ShippingAddress: &models.AddressSnapshot{Recipient: "Test Customer", Line1: "1 Main St", City: "Portland", PostalCode: "97201", Country: "US"},
 	}
 	suite.db.Create(&order)
 
@@ -824,19 +832,32 @@ func (suite *OrderAcceptanceTestSuite) TestOrderStatusUpdate_CompleteWorkflow_Ac
 	}
 	suite.db.Create(&technician)
 
-	// Step 2: Customer creates an order
+	// Step 2: Customer saves a shipping address and creates an order shipping to it
+	addressBody := map[string]interface{}{
+		"recipient":   "Test Customer",
+		"line1":       "1 Main St",
+		"city":        "Portland",
+		"postal_code": "97201",
+		"country":     "us",
+	}
+	resp, respData := suite.makeRequest("POST", "/api/v1/users/me/addresses", addressBody)
+	assert.Equal(suite.T(), http.StatusCreated, resp.StatusCode)
+	addressID := respData["data"].(map[string]interface{})["id"]
+
 	createBody := map[string]interface{}{
-		"description": "Nail design for status workflow",
-		"quantity":    2,
+		"description":         "Nail design for status workflow",
+		"quantity":            2,
+		"shipping_address_id": addressID,
 	}
 
-	resp, respData := suite.makeRequest("POST", "/api/v1/orders", createBody)
+	resp, respData = suite.makeRequest("POST", "/api/v1/orders", createBody)
 	assert.Equal(suite.T(), http.StatusCreated, resp.StatusCode)
 	assert.True(suite.T(), respData["success"].(bool))
 
 	orderData := respData["data"].(map[string]interface{})
 	orderID := int(orderData["id"].(float64))
 	assert.Equal(suite.T(), "submitted", orderData["status"])
+	assert.Equal(suite.T(), "US", orderData["shipping_address"].(map[string]interface{})["country"])
 
 	// Step 3: Technician assigns the order
 	resp, respData = suite.makeRequest("PUT", fmt.Sprintf("/api/v1/orders-tech/%d/assign", orderID), nil)
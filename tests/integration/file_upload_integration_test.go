@@ -48,7 +48,7 @@ func (suite *FileUploadIntegrationTestSuite) SetupSuite() {
 	suite.NoError(err)
 	suite.db = db
 
-	err = db.AutoMigrate(&models.User{}, &models.Order{})
+	err = models.MigrateUp(db)
 	suite.NoError(err)
 
 	config.SetDB(db)
@@ -123,7 +123,7 @@ func (suite *FileUploadIntegrationTestSuite) TestCreateOrder_WithValidPNGFile()
 	writer := multipart.NewWriter(body)
 
 	// Add image file
-	imageContent := []byte("fake PNG content")
+	imageContent := []byte("\x89PNG\r\n\x1a\nfake PNG content")
 	part, err := writer.CreateFormFile("image", "design.png")
 	suite.NoError(err)
 	_, err = part.Write(imageContent)
@@ -59,18 +59,18 @@ func (suite *OrderIntegrationTestSuite) SetupTest() {
 	suite.db = db
 
 	// Auto-migrate models
-	err = db.AutoMigrate(&models.User{}, &models.Order{})
+	err = models.MigrateUp(db)
 	suite.NoError(err)
 
 	// Set the database in config
 	config.SetDB(db)
 
-	// Initialize mock S3 service for testing
-	mockS3 := services.NewMockS3Service()
-	mockS3.SetAsMockForTesting()
+	// Initialize in-memory storage for testing
+	storage := services.NewMockStorage()
+	storage.SetAsMockForTesting()
 
-	// Initialize image service with mock S3
-	services.InitImageService(mockS3)
+	// Initialize image service with the in-memory storage
+	services.InitImageService(storage)
 
 	// Create a new router for each test
 	suite.router = gin.New()
@@ -499,10 +499,11 @@ func (suite *OrderIntegrationTestSuite) TestOrderReviewWorkflow_AcceptOrder() {
 
 	// Step 1: Customer creates an order
 	order := models.Order{
-		Description: "Order to be accepted",
-		Quantity:    2,
-		Status:      "submitted",
-		CustomerID:  customer.ID,
+		Description:     "Order to be accepted",
+		Quantity:        2,
+		Status:          "submitted",
+		CustomerID:      customer.ID,
+		ShippingAddress: &models.AddressSnapshot{Recipient: "Test Customer", Line1: "1 Main St", City: "Portland", PostalCode: "97201", Country: "US"},
 	}
 	err := suite.db.Create(&order).Error
 	suite.NoError(err)
@@ -528,6 +529,7 @@ func (suite *OrderIntegrationTestSuite) TestOrderReviewWorkflow_AcceptOrder() {
 
 	w := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodPut, "/api/v1/orders/1/review", bytes.NewBuffer(reviewBodyJSON))
+	req.Header.Set("If-Match", "*")
 	req.Header.Set("Content-Type", "application/json")
 	router.ServeHTTP(w, req)
 
@@ -615,6 +617,7 @@ func (suite *OrderIntegrationTestSuite) TestOrderReviewWorkflow_RejectOrder() {
 
 	w := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodPut, "/api/v1/orders/1/review", bytes.NewBuffer(reviewBodyJSON))
+	req.Header.Set("If-Match", "*")
 	req.Header.Set("Content-Type", "application/json")
 	router.ServeHTTP(w, req)
 
@@ -670,10 +673,11 @@ func (suite *OrderIntegrationTestSuite) TestOrderReviewWorkflow_MultipleTechnici
 
 	// Create order
 	order := models.Order{
-		Description: "Order for review",
-		Quantity:    2,
-		Status:      "submitted",
-		CustomerID:  customer.ID,
+		Description:     "Order for review",
+		Quantity:        2,
+		Status:          "submitted",
+		CustomerID:      customer.ID,
+		ShippingAddress: &models.AddressSnapshot{Recipient: "Test Customer", Line1: "1 Main St", City: "Portland", PostalCode: "97201", Country: "US"},
 	}
 	err := suite.db.Create(&order).Error
 	suite.NoError(err)
@@ -693,6 +697,7 @@ func (suite *OrderIntegrationTestSuite) TestOrderReviewWorkflow_MultipleTechnici
 
 	w := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodPut, "/api/v1/orders/1/review", bytes.NewBuffer(reviewBodyJSON))
+	req.Header.Set("If-Match", "*")
 	req.Header.Set("Content-Type", "application/json")
 	router1.ServeHTTP(w, req)
 
@@ -707,6 +712,7 @@ func (suite *OrderIntegrationTestSuite) TestOrderReviewWorkflow_MultipleTechnici
 
 	w = httptest.NewRecorder()
 	req = httptest.NewRequest(http.MethodPut, "/api/v1/orders/1/review", bytes.NewBuffer(reviewBodyJSON))
+	req.Header.Set("If-Match", "*")
 	req.Header.Set("Content-Type", "application/json")
 	router2.ServeHTTP(w, req)
 
@@ -741,10 +747,11 @@ func (suite *OrderIntegrationTestSuite) TestOrderReviewWorkflow_CustomerCannotRe
 
 	// Create order
 	order := models.Order{
-		Description: "Order for review",
-		Quantity:    2,
-		Status:      "submitted",
-		CustomerID:  customer.ID,
+		Description:     "Order for review",
+		Quantity:        2,
+		Status:          "submitted",
+		CustomerID:      customer.ID,
+		ShippingAddress: &models.AddressSnapshot{Recipient: "Test Customer", Line1: "1 Main St", City: "Portland", PostalCode: "97201", Country: "US"},
 	}
 	err := suite.db.Create(&order).Error
 	suite.NoError(err)
@@ -764,6 +771,7 @@ func (suite *OrderIntegrationTestSuite) TestOrderReviewWorkflow_CustomerCannotRe
 
 	w := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodPut, "/api/v1/orders/1/review", bytes.NewBuffer(reviewBodyJSON))
+	req.Header.Set("If-Match", "*")
 	req.Header.Set("Content-Type", "application/json")
 	router.ServeHTTP(w, req)
 
@@ -894,6 +902,7 @@ func (suite *OrderIntegrationTestSuite) TestOrderStatusUpdateWorkflow_CompleteHa
 
 	w := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodPut, "/api/v1/orders/1/status", bytes.NewBuffer(updateBodyJSON))
+	req.Header.Set("If-Match", "*")
 	req.Header.Set("Content-Type", "application/json")
 	router.ServeHTTP(w, req)
 
@@ -922,6 +931,7 @@ func (suite *OrderIntegrationTestSuite) TestOrderStatusUpdateWorkflow_CompleteHa
 
 	w = httptest.NewRecorder()
 	req = httptest.NewRequest(http.MethodPut, "/api/v1/orders/1/status", bytes.NewBuffer(updateBodyJSON))
+	req.Header.Set("If-Match", "*")
 	req.Header.Set("Content-Type", "application/json")
 	router.ServeHTTP(w, req)
 
@@ -948,6 +958,7 @@ func (suite *OrderIntegrationTestSuite) TestOrderStatusUpdateWorkflow_CompleteHa
 
 	w = httptest.NewRecorder()
 	req = httptest.NewRequest(http.MethodPut, "/api/v1/orders/1/status", bytes.NewBuffer(updateBodyJSON))
+	req.Header.Set("If-Match", "*")
 	req.Header.Set("Content-Type", "application/json")
 	router.ServeHTTP(w, req)
 
@@ -1038,6 +1049,7 @@ func (suite *OrderIntegrationTestSuite) TestOrderStatusUpdateWorkflow_InvalidTra
 
 	w := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodPut, "/api/v1/orders/1/status", bytes.NewBuffer(updateBodyJSON))
+	req.Header.Set("If-Match", "*")
 	req.Header.Set("Content-Type", "application/json")
 	router.ServeHTTP(w, req)
 
@@ -1115,6 +1127,7 @@ func (suite *OrderIntegrationTestSuite) TestOrderStatusUpdateWorkflow_CustomerCa
 
 	w := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodPut, "/api/v1/orders/1/status", bytes.NewBuffer(updateBodyJSON))
+	req.Header.Set("If-Match", "*")
 	req.Header.Set("Content-Type", "application/json")
 	router.ServeHTTP(w, req)
 
@@ -0,0 +1,64 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ServiceName names the API in traces unless OTEL_SERVICE_NAME is set
+const ServiceName = "kendalls-nails-api"
+
+// InstrumentationName names the tracer spans created by this module are recorded with
+const InstrumentationName = "github.com/kendall-kelly/kendalls-nails-api"
+
+// Setup exports spans to the OTLP/HTTP collector named by OTEL_EXPORTER_OTLP_ENDPOINT and returns
+// a function flushing the spans still buffered, to call on shutdown
+// The SDK reads the collector settings, OTEL_SERVICE_NAME and OTEL_TRACES_SAMPLER from the environment.
+// Until Setup runs spans are not recorded, so the instrumentation costs next to nothing
+func Setup(ctx context.Context) (func(context.Context) error, error) {
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", ServiceName)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe the service for traces: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		log.Printf("Tracing: %v", err)
+	}))
+	return provider.Shutdown, nil
+}
+
+// Tracer returns the tracer for spans around the API's own operations
+func Tracer() trace.Tracer {
+	return otel.Tracer(InstrumentationName)
+}
+
+// Transport wraps an HTTP transport so that outbound requests are recorded as spans of the
+// trace in their context and carry it to the server in traceparent; nil wraps http.DefaultTransport
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return otelhttp.NewTransport(base)
+}
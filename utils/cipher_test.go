@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFieldCipher(t *testing.T) {
+	cipher, err := NewFieldCipher(bytes.Repeat([]byte{7}, 32))
+	assert.NoError(t, err)
+
+	first, err := cipher.Encrypt("users.email", "jane@example.com")
+	assert.NoError(t, err)
+	second, _ := cipher.Encrypt("users.email", "jane@example.com")
+	assert.True(t, IsEncrypted(first))
+	assert.NotContains(t, first, "jane")
+	assert.NotEqual(t, first, second)
+
+	plaintext, err := cipher.Decrypt("users.email", first)
+	assert.NoError(t, err)
+	assert.Equal(t, "jane@example.com", plaintext)
+
+	// Ciphertext moved to another column does not decrypt
+	_, err = cipher.Decrypt("users.name", first)
+	assert.Error(t, err)
+
+	// Values stored before encryption pass through
+	plaintext, err = cipher.Decrypt("users.email", "legacy@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "legacy@example.com", plaintext)
+
+	// Another key cannot read the value
+	other, _ := NewFieldCipher(bytes.Repeat([]byte{8}, 32))
+	_, err = other.Decrypt("users.email", first)
+	assert.Error(t, err)
+	_, err = cipher.Decrypt("users.email", encryptedPrefix+"!!")
+	assert.Error(t, err)
+
+	assert.Equal(t, cipher.BlindIndex("jane@example.com"), cipher.BlindIndex("jane@example.com"))
+	assert.NotEqual(t, cipher.BlindIndex("jane@example.com"), other.BlindIndex("jane@example.com"))
+	assert.Len(t, cipher.BlindIndex("jane@example.com"), 64)
+
+	_, err = NewFieldCipher([]byte("short"))
+	assert.Error(t, err)
+}
+
+func TestParseFieldCipherKey(t *testing.T) {
+	key, err := ParseFieldCipherKey(base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32)))
+	assert.NoError(t, err)
+	assert.Len(t, key, 32)
+
+	_, err = ParseFieldCipherKey("not base64!")
+	assert.Error(t, err)
+	_, err = ParseFieldCipherKey(base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 16))))
+	assert.Error(t, err)
+}
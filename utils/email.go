@@ -0,0 +1,38 @@
+package utils
+
+import "strings"
+
+// gmailDomains are the domains where dots in the local part are ignored by the mail provider
+var gmailDomains = map[string]bool{
+	"gmail.com":      true,
+	"googlemail.com": true,
+}
+
+var foldGmailDots bool
+
+// SetGmailDotFolding enables or disables removing dots from Gmail local parts during normalization
+// It is configured once at startup from EMAIL_FOLD_GMAIL_DOTS
+func SetGmailDotFolding(enabled bool) {
+	foldGmailDots = enabled
+}
+
+// NormalizeEmail returns the canonical form of an email address used for storage and lookups
+// Addresses are trimmed and lowercased; with Gmail dot folding enabled,
+// "Jane.Doe@gmail.com" and "janedoe@gmail.com" normalize to the same address
+func NormalizeEmail(email string) string {
+	normalized := strings.ToLower(strings.TrimSpace(email))
+
+	if !foldGmailDots {
+		return normalized
+	}
+
+	at := strings.LastIndex(normalized, "@")
+	if at <= 0 {
+		return normalized
+	}
+	local, domain := normalized[:at], normalized[at+1:]
+	if gmailDomains[domain] {
+		local = strings.ReplaceAll(local, ".", "")
+	}
+	return local + "@" + domain
+}
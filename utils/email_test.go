@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeEmail(t *testing.T) {
+	tests := []struct {
+		name     string
+		email    string
+		foldDots bool
+		expected string
+	}{
+		{"lowercases", "Jane@Example.com", false, "jane@example.com"},
+		{"trims whitespace", "  jane@example.com\t", false, "jane@example.com"},
+		{"keeps gmail dots by default", "Jane.Doe@Gmail.com", false, "jane.doe@gmail.com"},
+		{"folds gmail dots", "Jane.Doe@Gmail.com", true, "janedoe@gmail.com"},
+		{"folds googlemail dots", "j.a.n.e@googlemail.com", true, "jane@googlemail.com"},
+		{"keeps dots for other domains", "jane.doe@example.com", true, "jane.doe@example.com"},
+		{"leaves malformed input alone", "@gmail.com", true, "@gmail.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetGmailDotFolding(tt.foldDots)
+			defer SetGmailDotFolding(false)
+
+			assert.Equal(t, tt.expected, NormalizeEmail(tt.email))
+		})
+	}
+}
@@ -2,18 +2,46 @@ package utils
 
 import (
 	"fmt"
+	"io"
 	"mime/multipart"
-	"path/filepath"
+	"net/http"
 	"strings"
 )
 
 const (
-	// MaxFileSize is 10MB in bytes
-	MaxFileSize = 10 * 1024 * 1024
-	// AllowedImageFormat is PNG
-	AllowedImageFormat = ".png"
+	// DefaultMaxUploadMB is the largest accepted upload when MAX_UPLOAD_MB is unset
+	DefaultMaxUploadMB = 10
+	// DefaultImageType is the only image type accepted when ALLOWED_IMAGE_TYPES is unset
+	DefaultImageType = "image/png"
+	// sniffLength is how much of a file is read to detect its type, the most http.DetectContentType considers
+	sniffLength = 512
 )
 
+// SupportedImageTypes are the image types that can be recognized from their content and decoded for share cards
+var SupportedImageTypes = []string{"image/png", "image/jpeg", "image/gif"}
+
+var (
+	maxUploadMB       int64 = DefaultMaxUploadMB
+	allowedImageTypes       = []string{DefaultImageType}
+)
+
+// SetUploadLimits sets the largest accepted upload in megabytes and the image types accepted
+// It is configured once at startup from MAX_UPLOAD_MB and ALLOWED_IMAGE_TYPES
+func SetUploadLimits(maxMB int64, imageTypes []string) {
+	maxUploadMB = maxMB
+	allowedImageTypes = imageTypes
+}
+
+// IsSupportedImageType reports whether uploads can be configured to accept an image type
+func IsSupportedImageType(imageType string) bool {
+	for _, supported := range SupportedImageTypes {
+		if imageType == supported {
+			return true
+		}
+	}
+	return false
+}
+
 // FileUploadError represents a file upload validation error
 type FileUploadError struct {
 	Code    string
@@ -25,23 +53,86 @@ func (e *FileUploadError) Error() string {
 }
 
 // ValidateImageFile validates the uploaded file format and size
+// The format is detected from the file's leading bytes; the filename and declared Content-Type are not trusted
 func ValidateImageFile(fileHeader *multipart.FileHeader) error {
 	// Check file size
-	if fileHeader.Size > MaxFileSize {
+	if err := ValidateUploadSize(fileHeader.Size); err != nil {
+		return err
+	}
+
+	// Check file content
+	contentType, err := SniffFileType(fileHeader)
+	if err != nil {
 		return &FileUploadError{
-			Code:    "FILE_TOO_LARGE",
-			Message: fmt.Sprintf("File size exceeds maximum allowed size of %d MB", MaxFileSize/(1024*1024)),
+			Code:    "INVALID_FILE",
+			Message: "Failed to read uploaded file",
 		}
 	}
+	return validateImageType(contentType)
+}
+
+// ValidateImageContent validates an image that did not arrive as a multipart file, such as a chunked upload
+// Only the leading bytes of the content are needed to detect its type
+func ValidateImageContent(size int64, head []byte) error {
+	if err := ValidateUploadSize(size); err != nil {
+		return err
+	}
+	return validateImageType(http.DetectContentType(head))
+}
 
-	// Check file extension
-	ext := strings.ToLower(filepath.Ext(fileHeader.Filename))
-	if ext != AllowedImageFormat {
+// ValidateUploadSize checks an upload's size in bytes against MAX_UPLOAD_MB
+func ValidateUploadSize(size int64) error {
+	if size > MaxUploadBytes() {
 		return &FileUploadError{
-			Code:    "INVALID_FILE_FORMAT",
-			Message: fmt.Sprintf("Only %s files are allowed", AllowedImageFormat),
+			Code:    "FILE_TOO_LARGE",
+			Message: fmt.Sprintf("File size exceeds maximum allowed size of %d MB", maxUploadMB),
 		}
 	}
-
 	return nil
 }
+
+// MaxUploadBytes returns the largest accepted upload in bytes
+func MaxUploadBytes() int64 {
+	return maxUploadMB * 1024 * 1024
+}
+
+// validateImageType checks a detected MIME type against ALLOWED_IMAGE_TYPES
+func validateImageType(contentType string) error {
+	for _, allowed := range allowedImageTypes {
+		if contentType == allowed {
+			return nil
+		}
+	}
+	return &FileUploadError{
+		Code:    "INVALID_FILE_FORMAT",
+		Message: fmt.Sprintf("Only %s images are allowed", imageTypeNames(allowedImageTypes)),
+	}
+}
+
+// SniffFileType detects the MIME type of an uploaded file from its first 512 bytes
+func SniffFileType(fileHeader *multipart.FileHeader) (string, error) {
+	file, err := fileHeader.Open()
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	return http.DetectContentType(head[:n]), nil
+}
+
+// imageTypeNames lists image types by their short names for error messages, e.g. "PNG or JPEG"
+func imageTypeNames(imageTypes []string) string {
+	names := make([]string, len(imageTypes))
+	for i, imageType := range imageTypes {
+		names[i] = strings.ToUpper(strings.TrimPrefix(imageType, "image/"))
+	}
+	if len(names) <= 1 {
+		return strings.Join(names, "")
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " or " + names[len(names)-1]
+}
@@ -39,10 +39,15 @@ func createTestFileHeader(filename string, size int64, content []byte) *multipar
 	return nil
 }
 
+// pngContent starts with the PNG signature, so it is detected as a PNG image
+var pngContent = []byte("\x89PNG\r\n\x1a\nfake png content")
+
+// jpegContent starts with the JPEG signature, so it is detected as a JPEG image
+var jpegContent = []byte("\xff\xd8\xfffake jpeg content")
+
 func TestValidateImageFile_Success(t *testing.T) {
 	// Test with valid PNG file under size limit
-	content := []byte("fake png content")
-	fileHeader := createTestFileHeader("test.png", int64(len(content)), content)
+	fileHeader := createTestFileHeader("test.png", int64(len(pngContent)), pngContent)
 	require.NotNil(t, fileHeader)
 
 	err := ValidateImageFile(fileHeader)
@@ -51,8 +56,7 @@ func TestValidateImageFile_Success(t *testing.T) {
 
 func TestValidateImageFile_FileTooLarge(t *testing.T) {
 	// Test with file exceeding size limit (11MB)
-	content := []byte("fake png content")
-	fileHeader := createTestFileHeader("large.png", 11*1024*1024, content)
+	fileHeader := createTestFileHeader("large.png", 11*1024*1024, pngContent)
 	require.NotNil(t, fileHeader)
 
 	err := ValidateImageFile(fileHeader)
@@ -61,13 +65,12 @@ func TestValidateImageFile_FileTooLarge(t *testing.T) {
 	fileErr, ok := err.(*FileUploadError)
 	require.True(t, ok, "Error should be of type FileUploadError")
 	assert.Equal(t, "FILE_TOO_LARGE", fileErr.Code)
-	assert.Contains(t, fileErr.Message, "File size exceeds maximum allowed size")
+	assert.Contains(t, fileErr.Message, "File size exceeds maximum allowed size of 10 MB")
 }
 
-func TestValidateImageFile_InvalidFormat_JPG(t *testing.T) {
-	// Test with JPG file (not allowed)
-	content := []byte("fake jpg content")
-	fileHeader := createTestFileHeader("test.jpg", int64(len(content)), content)
+func TestValidateImageFile_InvalidFormat_JPEG(t *testing.T) {
+	// Test with JPEG file (not allowed by default)
+	fileHeader := createTestFileHeader("test.jpeg", int64(len(jpegContent)), jpegContent)
 	require.NotNil(t, fileHeader)
 
 	err := ValidateImageFile(fileHeader)
@@ -76,13 +79,13 @@ func TestValidateImageFile_InvalidFormat_JPG(t *testing.T) {
 	fileErr, ok := err.(*FileUploadError)
 	require.True(t, ok, "Error should be of type FileUploadError")
 	assert.Equal(t, "INVALID_FILE_FORMAT", fileErr.Code)
-	assert.Contains(t, fileErr.Message, "Only .png files are allowed")
+	assert.Equal(t, "Only PNG images are allowed", fileErr.Message)
 }
 
-func TestValidateImageFile_InvalidFormat_JPEG(t *testing.T) {
-	// Test with JPEG file (not allowed)
-	content := []byte("fake jpeg content")
-	fileHeader := createTestFileHeader("test.jpeg", int64(len(content)), content)
+func TestValidateImageFile_InvalidFormat_RenamedFile(t *testing.T) {
+	// Test with a non-image file given a .png name
+	content := []byte("#!/bin/sh\necho not an image\n")
+	fileHeader := createTestFileHeader("test.png", int64(len(content)), content)
 	require.NotNil(t, fileHeader)
 
 	err := ValidateImageFile(fileHeader)
@@ -91,45 +94,59 @@ func TestValidateImageFile_InvalidFormat_JPEG(t *testing.T) {
 	fileErr, ok := err.(*FileUploadError)
 	require.True(t, ok, "Error should be of type FileUploadError")
 	assert.Equal(t, "INVALID_FILE_FORMAT", fileErr.Code)
-	assert.Contains(t, fileErr.Message, "Only .png files are allowed")
 }
 
-func TestValidateImageFile_InvalidFormat_GIF(t *testing.T) {
-	// Test with GIF file (not allowed)
-	content := []byte("fake gif content")
-	fileHeader := createTestFileHeader("test.gif", int64(len(content)), content)
+func TestValidateImageFile_IgnoresExtension(t *testing.T) {
+	// Test with a PNG file whose name has another or no extension
+	for _, filename := range []string{"test.jpg", "test.PNG", "testfile"} {
+		fileHeader := createTestFileHeader(filename, int64(len(pngContent)), pngContent)
+		require.NotNil(t, fileHeader)
+
+		err := ValidateImageFile(fileHeader)
+		assert.NoError(t, err, "Validation should use the file content for %s", filename)
+	}
+}
+
+func TestValidateImageFile_ConfiguredLimits(t *testing.T) {
+	SetUploadLimits(1, []string{"image/png", "image/jpeg"})
+	defer SetUploadLimits(DefaultMaxUploadMB, []string{DefaultImageType})
+
+	// JPEG is accepted once configured
+	fileHeader := createTestFileHeader("test.jpg", int64(len(jpegContent)), jpegContent)
 	require.NotNil(t, fileHeader)
+	assert.NoError(t, ValidateImageFile(fileHeader))
 
+	// GIF is still rejected, naming the accepted types
+	gifContent := []byte("GIF89afake gif content")
+	fileHeader = createTestFileHeader("test.gif", int64(len(gifContent)), gifContent)
+	require.NotNil(t, fileHeader)
 	err := ValidateImageFile(fileHeader)
-	assert.Error(t, err)
-
 	fileErr, ok := err.(*FileUploadError)
 	require.True(t, ok, "Error should be of type FileUploadError")
-	assert.Equal(t, "INVALID_FILE_FORMAT", fileErr.Code)
-}
+	assert.Equal(t, "Only PNG or JPEG images are allowed", fileErr.Message)
 
-func TestValidateImageFile_InvalidFormat_NoExtension(t *testing.T) {
-	// Test with file without extension
-	content := []byte("fake content")
-	fileHeader := createTestFileHeader("testfile", int64(len(content)), content)
+	// The size limit follows MAX_UPLOAD_MB
+	fileHeader = createTestFileHeader("large.png", 2*1024*1024, pngContent)
 	require.NotNil(t, fileHeader)
+	err = ValidateImageFile(fileHeader)
+	fileErr, ok = err.(*FileUploadError)
+	require.True(t, ok, "Error should be of type FileUploadError")
+	assert.Equal(t, "FILE_TOO_LARGE", fileErr.Code)
+	assert.Contains(t, fileErr.Message, "1 MB")
+}
 
-	err := ValidateImageFile(fileHeader)
-	assert.Error(t, err)
+func TestValidateImageContent(t *testing.T) {
+	assert.NoError(t, ValidateImageContent(9*1024*1024, pngContent))
 
+	err := ValidateImageContent(9*1024*1024, jpegContent)
 	fileErr, ok := err.(*FileUploadError)
 	require.True(t, ok, "Error should be of type FileUploadError")
 	assert.Equal(t, "INVALID_FILE_FORMAT", fileErr.Code)
-}
-
-func TestValidateImageFile_CaseInsensitive(t *testing.T) {
-	// Test with uppercase extension
-	content := []byte("fake png content")
-	fileHeader := createTestFileHeader("test.PNG", int64(len(content)), content)
-	require.NotNil(t, fileHeader)
 
-	err := ValidateImageFile(fileHeader)
-	assert.NoError(t, err, "Validation should be case-insensitive")
+	err = ValidateImageContent(MaxUploadBytes()+1, pngContent)
+	fileErr, ok = err.(*FileUploadError)
+	require.True(t, ok, "Error should be of type FileUploadError")
+	assert.Equal(t, "FILE_TOO_LARGE", fileErr.Code)
 }
 
 func TestFileUploadError_Error(t *testing.T) {
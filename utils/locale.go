@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// Measurement units for nail sizes
+const (
+	UnitMillimeters = "mm"
+	UnitInches      = "in"
+)
+
+// DefaultLocale is used when a request carries no usable language or country hint
+const DefaultLocale = "en-US"
+
+// inchCountries are the regions where nail sizes are customarily given in inches
+var inchCountries = map[string]bool{
+	"US": true,
+	"LR": true,
+	"MM": true,
+}
+
+// DetectLocale derives soft locale and measurement-unit defaults for a new profile
+// acceptLanguage is the Accept-Language header; country is an ISO 3166 code from an
+// IP geolocation header set by the edge proxy (e.g. CF-IPCountry), or empty.
+// An explicit region in Accept-Language wins, then the IP country, then the region
+// most commonly associated with the language.
+func DetectLocale(acceptLanguage, country string) (locale string, unit string) {
+	tag := language.Make(DefaultLocale)
+	if tags, _, err := language.ParseAcceptLanguage(acceptLanguage); err == nil && len(tags) > 0 {
+		tag = tags[0]
+	}
+
+	base, _ := tag.Base()
+	region, confidence := tag.Region()
+	if confidence != language.Exact {
+		if ipRegion, err := language.ParseRegion(strings.TrimSpace(country)); err == nil && ipRegion.IsCountry() {
+			region = ipRegion
+		}
+	}
+
+	if resolved, err := language.Compose(base, region); err == nil {
+		locale = resolved.String()
+	} else {
+		locale = DefaultLocale
+	}
+
+	return locale, UnitForRegion(region.String())
+}
+
+// UnitForRegion returns the customary nail size unit for an ISO 3166 region code
+func UnitForRegion(region string) string {
+	if inchCountries[strings.ToUpper(region)] {
+		return UnitInches
+	}
+	return UnitMillimeters
+}
+
+// CanonicalLocale validates a BCP 47 locale and returns its canonical form
+func CanonicalLocale(locale string) (string, bool) {
+	tag, err := language.Parse(strings.TrimSpace(locale))
+	if err != nil {
+		return "", false
+	}
+	return tag.String(), true
+}
+
+// CanonicalCountry validates an ISO 3166 alpha-2 country code and returns it in upper case
+func CanonicalCountry(code string) (string, bool) {
+	code = strings.TrimSpace(code)
+	if len(code) != 2 {
+		return "", false
+	}
+	region, err := language.ParseRegion(code)
+	if err != nil || !region.IsCountry() {
+		return "", false
+	}
+	return region.String(), true
+}
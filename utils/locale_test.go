@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectLocale(t *testing.T) {
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		country        string
+		wantLocale     string
+		wantUnit       string
+	}{
+		{"no hints", "", "", "en-US", UnitInches},
+		{"explicit region", "en-GB,en;q=0.8", "", "en-GB", UnitMillimeters},
+		{"highest quality wins", "fr;q=0.5, de-DE;q=0.9", "", "de-DE", UnitMillimeters},
+		{"IP country fills missing region", "en", "CA", "en-CA", UnitMillimeters},
+		{"explicit region beats IP country", "en-US", "GB", "en-US", UnitInches},
+		{"language alone implies region", "fr", "", "fr-FR", UnitMillimeters},
+		{"invalid country ignored", "en", "XX-bad", "en-US", UnitInches},
+		{"garbage header falls back", ";;;", "", "en-US", UnitInches},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			locale, unit := DetectLocale(tt.acceptLanguage, tt.country)
+			assert.Equal(t, tt.wantLocale, locale)
+			assert.Equal(t, tt.wantUnit, unit)
+		})
+	}
+}
+
+func TestCanonicalLocale(t *testing.T) {
+	locale, ok := CanonicalLocale("en-gb")
+	assert.True(t, ok)
+	assert.Equal(t, "en-GB", locale)
+
+	_, ok = CanonicalLocale("not a locale!")
+	assert.False(t, ok)
+}
+
+func TestCanonicalCountry(t *testing.T) {
+	country, ok := CanonicalCountry(" gb ")
+	assert.True(t, ok)
+	assert.Equal(t, "GB", country)
+
+	for _, code := range []string{"", "USA", "840", "ZZ", "1A"} {
+		_, ok = CanonicalCountry(code)
+		assert.False(t, ok, code)
+	}
+}
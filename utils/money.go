@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"golang.org/x/text/currency"
+)
+
+// DefaultCurrency is the ISO 4217 currency prices are in when none is configured
+const DefaultCurrency = "USD"
+
+// centsEpsilon absorbs binary floating point error when checking that an amount is in whole cents
+const centsEpsilon = 1e-6
+
+// currencySymbols are the symbols printed ahead of amounts; other currencies are printed with their code
+var currencySymbols = map[string]string{
+	"USD": "$",
+	"GBP": "£",
+	"EUR": "€",
+}
+
+// CanonicalCurrency validates an ISO 4217 currency code and returns it in upper case
+// Only currencies with two decimal places are accepted, since amounts are stored as integer cents
+func CanonicalCurrency(code string) (string, bool) {
+	unit, err := currency.ParseISO(strings.TrimSpace(code))
+	if err != nil {
+		return "", false
+	}
+	if scale, _ := currency.Standard.Rounding(unit); scale != 2 {
+		return "", false
+	}
+	return unit.String(), true
+}
+
+// ToCents converts a decimal amount to integer cents, rounding to the nearest cent
+func ToCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+// FromCents converts integer cents to a decimal amount
+func FromCents(cents int64) float64 {
+	return float64(cents) / 100
+}
+
+// PercentOfCents returns a percentage of an amount in cents, rounded half away from zero to the nearest cent
+func PercentOfCents(cents int64, percent float64) int64 {
+	return int64(math.Round(float64(cents) * percent / 100))
+}
+
+// IsWholeCents reports whether an amount has no more than two decimal places
+func IsWholeCents(amount float64) bool {
+	return math.Abs(amount*100-math.Round(amount*100)) < centsEpsilon
+}
+
+// FormatMoney formats an amount in cents with its currency symbol, or its code when it has no symbol
+// The sign goes ahead of the symbol, as in -$5.00
+func FormatMoney(cents int64, currencyCode string) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	amount := fmt.Sprintf("%d.%02d", cents/100, cents%100)
+	if symbol, ok := currencySymbols[currencyCode]; ok {
+		return sign + symbol + amount
+	}
+	return sign + amount + " " + currencyCode
+}
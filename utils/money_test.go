@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonicalCurrency(t *testing.T) {
+	code, ok := CanonicalCurrency(" eur ")
+	assert.True(t, ok)
+	assert.Equal(t, "EUR", code)
+
+	// Unknown codes, and currencies without cents such as the yen, are rejected
+	for _, code := range []string{"", "US", "DOLLAR", "XYZ", "JPY"} {
+		_, ok = CanonicalCurrency(code)
+		assert.False(t, ok, code)
+	}
+}
+
+func TestCents(t *testing.T) {
+	assert.Equal(t, int64(1999), ToCents(19.99))
+	assert.Equal(t, int64(30), ToCents(0.1+0.2))
+	assert.Equal(t, int64(-500), ToCents(-5))
+	assert.Equal(t, 19.99, FromCents(1999))
+
+	assert.True(t, IsWholeCents(19.99))
+	assert.True(t, IsWholeCents(0.1+0.2))
+	assert.False(t, IsWholeCents(19.999))
+}
+
+func TestPercentOfCents(t *testing.T) {
+	assert.Equal(t, int64(371), PercentOfCents(4500, 8.25))
+	assert.Equal(t, int64(-250), PercentOfCents(2500, -10))
+	assert.Equal(t, int64(1), PercentOfCents(5, 10)) // half a cent rounds away from zero
+}
+
+func TestFormatMoney(t *testing.T) {
+	assert.Equal(t, "$42.05", FormatMoney(4205, "USD"))
+	assert.Equal(t, "-$5.00", FormatMoney(-500, "USD"))
+	assert.Equal(t, "€0.99", FormatMoney(99, "EUR"))
+	assert.Equal(t, "12.50 CAD", FormatMoney(1250, "CAD"))
+}
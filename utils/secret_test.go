@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSecret(t *testing.T) {
+	first, err := NewSecret("nit_")
+	assert.NoError(t, err)
+	second, err := NewSecret("nit_")
+	assert.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(first, "nit_"))
+	assert.Len(t, first, len("nit_")+43)
+	assert.NotEqual(t, first, second)
+}
+
+func TestHashSecret(t *testing.T) {
+	assert.Equal(t, HashSecret("nit_abc"), HashSecret("nit_abc"))
+	assert.NotEqual(t, HashSecret("nit_abc"), HashSecret("nit_abd"))
+	assert.Len(t, HashSecret("nit_abc"), 64)
+}